@@ -5,10 +5,15 @@ import (
 	"time"
 
 	"trade-machine/models"
+	"trade-machine/observability"
+	"trade-machine/services"
 
 	"github.com/shopspring/decimal"
 )
 
+// logger is the agents module's logger
+var logger = observability.Module(observability.ModuleAgents)
+
 // AgentMetadata provides information about an agent's capabilities
 type AgentMetadata struct {
 	Description      string   // Human-readable description of what the agent does
@@ -27,6 +32,59 @@ type Agent interface {
 	GetMetadata() AgentMetadata           // Get agent capabilities and requirements
 }
 
+// DegradationAware is implemented by agents whose providers sit behind circuit
+// breakers, so analyses made while a provider is degraded can be discounted
+type DegradationAware interface {
+	Degradation() services.DegradationLevel
+}
+
+// degradedConfidenceFactor scales the confidence of analyses made while a provider is degraded
+const degradedConfidenceFactor = 0.7
+
+// providerLevel returns the worst degradation level among the named breakers
+func providerLevel(breakers ...string) services.DegradationLevel {
+	level := services.DegradationNone
+	for _, name := range breakers {
+		level = max(level, services.BreakerLevel(name))
+	}
+	return level
+}
+
+// llmLevel returns the degradation of the LLM provider an agent's calls go to. Services
+// that route agents between providers report it themselves; otherwise it is OpenAI's.
+func llmLevel(llm LLMService, agentType models.AgentType) services.DegradationLevel {
+	if leveler, ok := llm.(services.LLMLeveler); ok {
+		return leveler.LLMLevel(string(agentType))
+	}
+	return services.BreakerLevel(services.BreakerOpenAI)
+}
+
+// breakerAvailability maps a provider's degradation level to agent availability.
+// Open breakers make the agent unavailable without a live check; degraded ones keep
+// it available so it keeps analyzing on the probe trickle. decided is false when
+// the provider is healthy and a live check should be made.
+func breakerAvailability(level services.DegradationLevel) (available, decided bool) {
+	switch level {
+	case services.DegradationOpen:
+		return false, true
+	case services.DegradationSoft:
+		return true, true
+	}
+	return false, false
+}
+
+// applyDegradation lowers an analysis' confidence when the agent's provider is degraded.
+// Returns true if the analysis was discounted.
+func applyDegradation(agent Agent, analysis *Analysis) bool {
+	aware, ok := agent.(DegradationAware)
+	if !ok || analysis == nil || aware.Degradation() != services.DegradationSoft {
+		return false
+	}
+	analysis.Confidence = NormalizeConfidence(analysis.Confidence * degradedConfidenceFactor)
+	analysis.Reasoning += " (Confidence reduced: data provider degraded.)"
+	return true
+}
+
 // Analysis result from an agent
 type Analysis struct {
 	Symbol     string
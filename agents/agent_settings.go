@@ -0,0 +1,68 @@
+package agents
+
+import (
+	"context"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/services"
+)
+
+// agentSettings holds the resolved run settings for one agent type
+type agentSettings struct {
+	Timeout time.Duration
+	Retries int
+	Model   string
+}
+
+// settingsFor resolves the timeout, retry count and model for an agent type,
+// falling back to the global agent timeout when no override is configured
+func (m *PortfolioManager) settingsFor(agentType models.AgentType) agentSettings {
+	settings := agentSettings{Timeout: time.Duration(m.cfg.Agent.TimeoutSeconds) * time.Second}
+	override, ok := m.cfg.Agent.TypeOverrides[string(agentType)]
+	if !ok {
+		return settings
+	}
+	if override.TimeoutSeconds > 0 {
+		settings.Timeout = time.Duration(override.TimeoutSeconds) * time.Second
+	}
+	settings.Retries = override.Retries
+	settings.Model = override.Model
+	return settings
+}
+
+// metadata describes the settings for storage on an agent run
+func (s agentSettings) metadata() map[string]interface{} {
+	meta := map[string]interface{}{
+		"timeout_seconds": int(s.Timeout / time.Second),
+		"max_retries":     s.Retries,
+	}
+	if s.Model != "" {
+		meta["model"] = s.Model
+	}
+	return meta
+}
+
+// analyzeWithRetries runs the agent up to Retries+1 times, giving each attempt its
+// own timeout. It returns the number of attempts made alongside the final result.
+func analyzeWithRetries(ctx context.Context, ag Agent, symbol string, settings agentSettings) (*Analysis, int, error) {
+	var (
+		analysis *Analysis
+		err      error
+	)
+	attempts := 0
+	for attempts <= settings.Retries {
+		attempts++
+		attemptCtx, cancel := context.WithTimeout(ctx, settings.Timeout)
+		attemptCtx = services.WithLLMAgent(attemptCtx, string(ag.Type()))
+		if settings.Model != "" {
+			attemptCtx = services.WithModel(attemptCtx, settings.Model)
+		}
+		analysis, err = ag.Analyze(attemptCtx, symbol)
+		cancel()
+		if err == nil || ctx.Err() != nil {
+			break
+		}
+	}
+	return analysis, attempts, err
+}
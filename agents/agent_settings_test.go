@@ -0,0 +1,106 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+	"trade-machine/services"
+)
+
+// flakyAgent fails a fixed number of times before succeeding and records each attempt
+type flakyAgent struct {
+	mockAgent
+	failures  int
+	calls     int
+	models    []string
+	deadlines []time.Duration
+}
+
+func (f *flakyAgent) Analyze(ctx context.Context, symbol string) (*Analysis, error) {
+	f.calls++
+	f.models = append(f.models, services.ModelFromContext(ctx, "default"))
+	if deadline, ok := ctx.Deadline(); ok {
+		f.deadlines = append(f.deadlines, time.Until(deadline))
+	}
+	if f.calls <= f.failures {
+		return nil, errors.New("provider error")
+	}
+	return &Analysis{Symbol: symbol, AgentType: f.agentType, Score: 40, Confidence: 70}, nil
+}
+
+func TestPortfolioManager_SettingsFor(t *testing.T) {
+	cfg := config.NewTestConfig()
+	cfg.Agent.TimeoutSeconds = 30
+	cfg.Agent.TypeOverrides = map[string]config.AgentOverride{
+		"news":        {TimeoutSeconds: 10},
+		"fundamental": {Retries: 2, Model: "gpt-4o"},
+	}
+	manager := NewPortfolioManager(nil, cfg, nil)
+
+	tests := []struct {
+		agentType models.AgentType
+		want      agentSettings
+	}{
+		{models.AgentTypeNews, agentSettings{Timeout: 10 * time.Second}},
+		{models.AgentTypeFundamental, agentSettings{Timeout: 30 * time.Second, Retries: 2, Model: "gpt-4o"}},
+		{models.AgentTypeTechnical, agentSettings{Timeout: 30 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.agentType), func(t *testing.T) {
+			if got := manager.settingsFor(tt.agentType); got != tt.want {
+				t.Errorf("settingsFor(%s) = %+v, want %+v", tt.agentType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeWithRetries(t *testing.T) {
+	settings := agentSettings{Timeout: 5 * time.Second, Retries: 2, Model: "gpt-4o-mini"}
+
+	t.Run("succeeds after retries", func(t *testing.T) {
+		agent := &flakyAgent{failures: 2}
+		analysis, attempts, err := analyzeWithRetries(context.Background(), agent, "AAPL", settings)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if analysis == nil || attempts != 3 {
+			t.Errorf("expected analysis after 3 attempts, got %v after %d", analysis, attempts)
+		}
+		for i, model := range agent.models {
+			if model != "gpt-4o-mini" {
+				t.Errorf("attempt %d used model %q, want gpt-4o-mini", i+1, model)
+			}
+		}
+		for i, remaining := range agent.deadlines {
+			if remaining <= 0 || remaining > settings.Timeout {
+				t.Errorf("attempt %d deadline %v, want within %v", i+1, remaining, settings.Timeout)
+			}
+		}
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		agent := &flakyAgent{failures: 5}
+		_, attempts, err := analyzeWithRetries(context.Background(), agent, "AAPL", settings)
+		if err == nil {
+			t.Fatal("expected error after exhausting retries")
+		}
+		if attempts != 3 {
+			t.Errorf("expected 3 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("stops when parent context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		agent := &flakyAgent{failures: 5}
+		_, attempts, _ := analyzeWithRetries(ctx, agent, "AAPL", settings)
+		if attempts != 1 {
+			t.Errorf("expected 1 attempt with cancelled context, got %d", attempts)
+		}
+	})
+}
@@ -1,9 +1,11 @@
 package agents
 
 import (
+	"strings"
 	"testing"
 
 	"trade-machine/models"
+	"trade-machine/services"
 )
 
 func TestScoreToAction(t *testing.T) {
@@ -163,3 +165,78 @@ func TestAgentMetadata_Fields(t *testing.T) {
 		t.Errorf("RequiredServices[0] = %v, want 'service1'", metadata.RequiredServices[0])
 	}
 }
+
+type degradableAgent struct {
+	testMockAgent
+	level services.DegradationLevel
+}
+
+func (d *degradableAgent) Degradation() services.DegradationLevel {
+	return d.level
+}
+
+func TestApplyDegradation(t *testing.T) {
+	t.Run("degraded provider lowers confidence", func(t *testing.T) {
+		analysis := &Analysis{Confidence: 80, Reasoning: "Solid"}
+		if !applyDegradation(&degradableAgent{level: services.DegradationSoft}, analysis) {
+			t.Fatal("expected the analysis to be discounted")
+		}
+		if analysis.Confidence != 56 {
+			t.Errorf("Confidence = %v, want 56", analysis.Confidence)
+		}
+		if !strings.Contains(analysis.Reasoning, "degraded") {
+			t.Errorf("Reasoning = %q, should mention the degraded provider", analysis.Reasoning)
+		}
+	})
+
+	t.Run("healthy provider and unaware agents are untouched", func(t *testing.T) {
+		for _, agent := range []Agent{&degradableAgent{}, &testMockAgent{}} {
+			analysis := &Analysis{Confidence: 80}
+			if applyDegradation(agent, analysis) || analysis.Confidence != 80 {
+				t.Errorf("%T: confidence changed to %v", agent, analysis.Confidence)
+			}
+		}
+	})
+}
+
+func TestBreakerAvailability(t *testing.T) {
+	tests := []struct {
+		level         services.DegradationLevel
+		wantAvailable bool
+		wantDecided   bool
+	}{
+		{services.DegradationNone, false, false},
+		{services.DegradationSoft, true, true},
+		{services.DegradationOpen, false, true},
+	}
+	for _, tt := range tests {
+		available, decided := breakerAvailability(tt.level)
+		if available != tt.wantAvailable || decided != tt.wantDecided {
+			t.Errorf("%s: got (%v, %v), want (%v, %v)", tt.level, available, decided, tt.wantAvailable, tt.wantDecided)
+		}
+	}
+}
+
+// leveledLLM reports a fixed degradation level per agent, as the LLM router does
+type leveledLLM struct {
+	mockLLMService
+	levels map[string]services.DegradationLevel
+}
+
+func (l *leveledLLM) LLMLevel(agent string) services.DegradationLevel {
+	return l.levels[agent]
+}
+
+func TestDegradation_RoutedLLM(t *testing.T) {
+	llm := &leveledLLM{levels: map[string]services.DegradationLevel{"news": services.DegradationOpen}}
+
+	if level := NewNewsAnalyst(llm, &mockNewsAPIService{}, testConfig()).Degradation(); level != services.DegradationOpen {
+		t.Errorf("news Degradation() = %s, want its routed provider's level", level)
+	}
+	if level := NewNewsAnalyst(WithLanguage(llm, "es"), &mockNewsAPIService{}, testConfig()).Degradation(); level != services.DegradationOpen {
+		t.Errorf("localized news Degradation() = %s, want the level forwarded", level)
+	}
+	if level := NewFundamentalAnalyst(llm, &mockAlphaVantageService{}).Degradation(); level != services.DegradationNone {
+		t.Errorf("fundamental Degradation() = %s, want none", level)
+	}
+}
@@ -0,0 +1,66 @@
+package agents
+
+import (
+	"fmt"
+	"strings"
+
+	"trade-machine/models"
+)
+
+const chatSystemPrompt = `You are a financial research assistant answering questions about the stock %s.
+
+Ground your answers in the analysis below, made by this system's fundamental, news, technical
+and social sentiment agents. Say which agent a point comes from, say when the analysis is old
+or doesn't cover the question, and don't invent figures it doesn't contain. Answer in plain
+prose, not JSON. This is not personalized financial advice.`
+
+// chatReasoningLimit caps how much of each agent's reasoning goes into the chat context
+const chatReasoningLimit = 1500
+
+// ChatSystemPrompt returns the system prompt for a chat about symbol, with the latest
+// recommendation and the agents' recent runs as context. Either may be missing.
+func ChatSystemPrompt(symbol string, rec *models.Recommendation, runs []models.AgentRun) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, chatSystemPrompt, symbol)
+
+	if rec == nil && len(runs) == 0 {
+		sb.WriteString("\n\nThe agents haven't analyzed this stock yet; say so, and answer from general knowledge only with that caveat.")
+		return sb.String()
+	}
+
+	if rec != nil {
+		fmt.Fprintf(&sb, "\n\nLatest recommendation (%s): %s with %.0f%% confidence.\n",
+			rec.CreatedAt.Format("2006-01-02 15:04 MST"), strings.ToUpper(string(rec.Action)), rec.Confidence)
+		fmt.Fprintf(&sb, "Scores from -100 to 100: fundamental %.0f, news sentiment %.0f, technical %.0f",
+			rec.FundamentalScore, rec.SentimentScore, rec.TechnicalScore)
+		if rec.SocialScore != 0 {
+			fmt.Fprintf(&sb, ", social %.0f", rec.SocialScore)
+		}
+		sb.WriteString(".\n")
+		if !rec.TargetPrice.IsZero() || !rec.StopPrice.IsZero() {
+			fmt.Fprintf(&sb, "Entry %s, target %s, stop %s.\n", rec.EntryPrice.StringFixed(2), rec.TargetPrice.StringFixed(2), rec.StopPrice.StringFixed(2))
+		}
+		fmt.Fprintf(&sb, "Reasoning: %s\n", truncateChatText(rec.Reasoning))
+	}
+
+	// Only each agent's most recent completed run; runs are newest first
+	seen := make(map[models.AgentType]bool)
+	for _, run := range runs {
+		if run.Status != models.AgentRunStatusCompleted || seen[run.AgentType] {
+			continue
+		}
+		seen[run.AgentType] = true
+		reasoning, _ := run.OutputData["reasoning"].(string)
+		fmt.Fprintf(&sb, "\n%s agent (%s): score %v, confidence %v. %s\n",
+			run.AgentType, run.StartedAt.Format("2006-01-02"), run.OutputData["score"], run.OutputData["confidence"], truncateChatText(reasoning))
+	}
+	return sb.String()
+}
+
+// truncateChatText shortens text to chatReasoningLimit runes
+func truncateChatText(text string) string {
+	if runes := []rune(text); len(runes) > chatReasoningLimit {
+		return string(runes[:chatReasoningLimit]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,37 @@
+package agents
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+)
+
+func TestChatSystemPrompt(t *testing.T) {
+	t.Run("no analysis", func(t *testing.T) {
+		prompt := ChatSystemPrompt("AAPL", nil, nil)
+		if !strings.Contains(prompt, "AAPL") || !strings.Contains(prompt, "haven't analyzed") {
+			t.Errorf("prompt = %q, want the symbol and a note that there is no analysis", prompt)
+		}
+	})
+
+	t.Run("recommendation and latest run per agent", func(t *testing.T) {
+		rec := &models.Recommendation{Symbol: "AAPL", Action: models.RecommendationActionBuy, Confidence: 72, FundamentalScore: 55, Reasoning: "Strong services growth", CreatedAt: time.Now()}
+		runs := []models.AgentRun{
+			{AgentType: models.AgentTypeNews, Status: models.AgentRunStatusCompleted, OutputData: map[string]interface{}{"score": 30.0, "reasoning": "Upbeat launch coverage"}, StartedAt: time.Now()},
+			{AgentType: models.AgentTypeNews, Status: models.AgentRunStatusCompleted, OutputData: map[string]interface{}{"score": -10.0, "reasoning": "Older supply worries"}, StartedAt: time.Now().Add(-48 * time.Hour)},
+			{AgentType: models.AgentTypeTechnical, Status: models.AgentRunStatusFailed, ErrorMessage: "timeout", StartedAt: time.Now()},
+		}
+
+		prompt := ChatSystemPrompt("AAPL", rec, runs)
+		for _, want := range []string{"BUY with 72% confidence", "fundamental 55", "Strong services growth", "Upbeat launch coverage"} {
+			if !strings.Contains(prompt, want) {
+				t.Errorf("prompt is missing %q:\n%s", want, prompt)
+			}
+		}
+		if strings.Contains(prompt, "Older supply worries") || strings.Contains(prompt, "technical agent") {
+			t.Errorf("prompt should hold only each agent's latest completed run:\n%s", prompt)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package agents
+
+import (
+	"context"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+)
+
+// DemoSymbol is the placeholder symbol demo analyses are made for
+const DemoSymbol = "DEMO"
+
+// demoAnalyses returns canned agent analyses for DemoSymbol: solid fundamentals, mildly
+// positive news and a moderate uptrend
+func demoAnalyses() []*Analysis {
+	now := time.Now()
+	return []*Analysis{
+		{
+			Symbol:     DemoSymbol,
+			AgentType:  models.AgentTypeFundamental,
+			Score:      38,
+			Confidence: 72,
+			Reasoning:  "Sample data: P/E of 18 below the sector average, 12% revenue growth and low debt.",
+			Timestamp:  now,
+		},
+		{
+			Symbol:     DemoSymbol,
+			AgentType:  models.AgentTypeNews,
+			Score:      15,
+			Confidence: 58,
+			Reasoning:  "Sample data: recent coverage is mostly positive, led by a product launch.",
+			Timestamp:  now,
+		},
+		{
+			Symbol:     DemoSymbol,
+			AgentType:  models.AgentTypeTechnical,
+			Score:      27,
+			Confidence: 66,
+			Reasoning:  "Sample data: price above its 50-day average with RSI at 58.",
+			Timestamp:  now,
+		},
+	}
+}
+
+// DemoAnalysis synthesizes a recommendation for DemoSymbol from canned agent analyses
+// using strategy, so a first run can show a full analysis before any API key works.
+// No external service is called and nothing is saved.
+func DemoAnalysis(cfg *config.Config, strategy ActionStrategy) *models.Recommendation {
+	m := NewPortfolioManager(nil, cfg, nil)
+	m.SetStrategy(strategy)
+	rec := m.synthesizeRecommendation(context.Background(), DemoSymbol, demoAnalyses(), nil)
+	rec.Reasoning = "Demo analysis on sample data using the " + strategy.Name() + " strategy. " + rec.Reasoning
+	return rec
+}
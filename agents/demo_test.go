@@ -0,0 +1,25 @@
+package agents
+
+import (
+	"testing"
+
+	"trade-machine/config"
+	"trade-machine/models"
+)
+
+func TestDemoAnalysis(t *testing.T) {
+	cfg := config.NewTestConfig()
+
+	rec := DemoAnalysis(cfg, NewDefaultStrategy())
+	if rec.Symbol != DemoSymbol || rec.Action != models.RecommendationActionBuy {
+		t.Errorf("default strategy: got %s %s, want buy %s", rec.Action, rec.Symbol, DemoSymbol)
+	}
+	if !rec.Quantity.IsZero() {
+		t.Errorf("demo analysis should be signal-only, got quantity %s", rec.Quantity)
+	}
+
+	// The sample scores sit between the default and conservative buy thresholds
+	if rec := DemoAnalysis(cfg, NewConservativeStrategy()); rec.Action != models.RecommendationActionHold {
+		t.Errorf("conservative strategy: got %s, want hold", rec.Action)
+	}
+}
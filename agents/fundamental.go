@@ -2,11 +2,13 @@ package agents
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
 	"trade-machine/models"
+	"trade-machine/services"
+
+	"github.com/shopspring/decimal"
 )
 
 const fundamentalSystemPrompt = `You are a financial analyst specializing in fundamental analysis. 
@@ -17,6 +19,10 @@ You will be given fundamental data for a stock including:
 - 52-week high/low
 - Beta (volatility measure)
 - Dividend yield
+- Revenue, margins, and debt where reported
+
+When the stock has been analyzed before, you will also be given what changed since then
+(revenue revisions, margin changes, new debt). Focus your analysis on those changes.
 
 Based on this data, provide your analysis in the following JSON format:
 {
@@ -36,17 +42,29 @@ type FundamentalAnalystResponse struct {
 	KeyFactors []string `json:"key_factors"`
 }
 
+// FundamentalsSnapshotStore stores the fundamentals each analysis was based on
+type FundamentalsSnapshotStore interface {
+	SaveFundamentalsSnapshot(ctx context.Context, snapshot *models.FundamentalsSnapshot) error
+	GetLatestFundamentalsSnapshot(ctx context.Context, symbol string) (*models.FundamentalsSnapshot, error)
+}
+
+// DebtProvider is implemented by fundamentals providers that can report a company's total debt
+type DebtProvider interface {
+	GetTotalDebt(ctx context.Context, symbol string) (decimal.Decimal, error)
+}
+
 // FundamentalAnalyst analyzes company fundamentals
 type FundamentalAnalyst struct {
-	llm LLMService
+	llm          LLMService
 	alphaVantage AlphaVantageServiceInterface
 	healthCache  *HealthCache
+	snapshots    FundamentalsSnapshotStore
 }
 
 // NewFundamentalAnalyst creates a new FundamentalAnalyst
 func NewFundamentalAnalyst(llm LLMService, alphaVantage AlphaVantageServiceInterface) *FundamentalAnalyst {
 	return &FundamentalAnalyst{
-		llm:     llm,
+		llm:          llm,
 		alphaVantage: alphaVantage,
 		healthCache:  NewHealthCache(DefaultHealthCacheTTL),
 	}
@@ -55,12 +73,18 @@ func NewFundamentalAnalyst(llm LLMService, alphaVantage AlphaVantageServiceInter
 // NewFundamentalAnalystWithCacheTTL creates a new FundamentalAnalyst with a custom health cache TTL
 func NewFundamentalAnalystWithCacheTTL(llm LLMService, alphaVantage AlphaVantageServiceInterface, cacheTTL time.Duration) *FundamentalAnalyst {
 	return &FundamentalAnalyst{
-		llm:     llm,
+		llm:          llm,
 		alphaVantage: alphaVantage,
 		healthCache:  NewHealthCache(cacheTTL),
 	}
 }
 
+// SetSnapshotStore enables fundamentals snapshots: each analysis stores the fundamentals it
+// used, and re-analyses are given the delta since the previous snapshot
+func (a *FundamentalAnalyst) SetSnapshotStore(store FundamentalsSnapshotStore) {
+	a.snapshots = store
+}
+
 // Analyze performs fundamental analysis on a stock
 func (a *FundamentalAnalyst) Analyze(ctx context.Context, symbol string) (*Analysis, error) {
 	fundamentals, err := a.alphaVantage.GetFundamentals(ctx, symbol)
@@ -68,6 +92,8 @@ func (a *FundamentalAnalyst) Analyze(ctx context.Context, symbol string) (*Analy
 		return nil, fmt.Errorf("failed to fetch fundamentals: %w", err)
 	}
 
+	delta := a.fundamentalsDelta(ctx, symbol, fundamentals)
+
 	userPrompt := fmt.Sprintf(`Analyze the following fundamental data for %s:
 
 P/E Ratio: %.2f
@@ -77,7 +103,10 @@ Market Cap: %s
 52-Week Low: %s
 Beta: %.2f
 Dividend Yield: %.2f%%
-
+Revenue (TTM): %s
+Profit Margin: %.2f%%
+Total Debt: %s
+%s
 Provide your analysis.`,
 		symbol,
 		fundamentals.PERatio,
@@ -87,17 +116,24 @@ Provide your analysis.`,
 		fundamentals.Week52Low.String(),
 		fundamentals.Beta,
 		fundamentals.DividendYield*100,
+		fundamentals.Revenue.String(),
+		fundamentals.ProfitMargin*100,
+		fundamentals.TotalDebt.String(),
+		formatFundamentalsDelta(delta),
 	)
 
-	response, err := a.llm.InvokeWithPrompt(ctx, fundamentalSystemPrompt, userPrompt)
+	response, err := invokeLLM(ctx, a.llm, fundamentalSystemPrompt, userPrompt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to invoke bedrock: %w", err)
 	}
 
+	a.saveSnapshot(ctx, fundamentals)
+
+	var analysis *Analysis
 	var result FundamentalAnalystResponse
-	if err := json.Unmarshal([]byte(response), &result); err != nil {
+	if err := services.ParseStructuredOutput(response, &result); err != nil {
 		// If parsing fails, return a basic analysis
-		return &Analysis{
+		analysis = &Analysis{
 			Symbol:     symbol,
 			AgentType:  models.AgentTypeFundamental,
 			Score:      0,
@@ -108,21 +144,103 @@ Provide your analysis.`,
 				"fundamentals": fundamentals,
 			},
 			Timestamp: time.Now(),
-		}, nil
+		}
+	} else {
+		analysis = &Analysis{
+			Symbol:     symbol,
+			AgentType:  models.AgentTypeFundamental,
+			Score:      NormalizeScore(result.Score),
+			Confidence: NormalizeConfidence(result.Confidence),
+			Reasoning:  result.Reasoning,
+			Data: map[string]interface{}{
+				"key_factors":  result.KeyFactors,
+				"fundamentals": fundamentals,
+			},
+			Timestamp: time.Now(),
+		}
 	}
 
-	return &Analysis{
-		Symbol:     symbol,
-		AgentType:  models.AgentTypeFundamental,
-		Score:      NormalizeScore(result.Score),
-		Confidence: NormalizeConfidence(result.Confidence),
-		Reasoning:  result.Reasoning,
-		Data: map[string]interface{}{
-			"key_factors":  result.KeyFactors,
-			"fundamentals": fundamentals,
-		},
-		Timestamp: time.Now(),
-	}, nil
+	if delta != nil {
+		analysis.Data["fundamentals_delta"] = *delta
+	}
+	return analysis, nil
+}
+
+// fundamentalsDelta fills in total debt when the provider reports it and diffs the
+// fundamentals against the previous snapshot. When the balance sheet can't be fetched the
+// previous snapshot's debt is carried over, so a failed fetch neither shows as new debt
+// nor is saved as none. It returns nil when snapshots are disabled or the symbol has not
+// been analyzed before.
+func (a *FundamentalAnalyst) fundamentalsDelta(ctx context.Context, symbol string, fundamentals *models.Fundamentals) *models.FundamentalsDelta {
+	if a.snapshots == nil {
+		return nil
+	}
+
+	previous, err := a.snapshots.GetLatestFundamentalsSnapshot(ctx, symbol)
+	if err != nil {
+		logger.Warn("failed to load fundamentals snapshot", "symbol", symbol, "error", err)
+	}
+
+	if provider, ok := a.alphaVantage.(DebtProvider); ok {
+		debt, err := provider.GetTotalDebt(ctx, symbol)
+		switch {
+		case err == nil:
+			fundamentals.TotalDebt = debt
+		case previous != nil:
+			logger.Warn("failed to get total debt, keeping the previous snapshot's", "symbol", symbol, "error", err)
+			fundamentals.TotalDebt = previous.Fundamentals.TotalDebt
+		default:
+			logger.Warn("failed to get total debt", "symbol", symbol, "error", err)
+		}
+	}
+
+	if previous == nil {
+		return nil
+	}
+	return models.DiffFundamentals(previous, fundamentals)
+}
+
+// saveSnapshot records the fundamentals used so the next analysis can diff against them
+func (a *FundamentalAnalyst) saveSnapshot(ctx context.Context, fundamentals *models.Fundamentals) {
+	if a.snapshots == nil {
+		return
+	}
+	if err := a.snapshots.SaveFundamentalsSnapshot(ctx, models.NewFundamentalsSnapshot(fundamentals)); err != nil {
+		logger.Warn("failed to save fundamentals snapshot", "symbol", fundamentals.Symbol, "error", err)
+	}
+}
+
+// formatFundamentalsDelta lists the changes since the previous analysis for the prompt
+func formatFundamentalsDelta(delta *models.FundamentalsDelta) string {
+	if delta == nil {
+		return ""
+	}
+	since := delta.Since.Format("2006-01-02")
+	if !delta.HasChanges() {
+		return fmt.Sprintf("\nNo material changes since the previous analysis on %s.\n", since)
+	}
+	out := fmt.Sprintf("\nChanges since the previous analysis on %s:\n", since)
+	for _, c := range delta.Changes {
+		out += "- " + c.String() + "\n"
+	}
+	return out
+}
+
+// fundamentalsDeltaOf returns the fundamentals delta carried by a fundamental analysis, if any
+func fundamentalsDeltaOf(analysis *Analysis) *models.FundamentalsDelta {
+	if analysis == nil || analysis.AgentType != models.AgentTypeFundamental {
+		return nil
+	}
+	delta, ok := analysis.Data["fundamentals_delta"].(models.FundamentalsDelta)
+	if !ok {
+		return nil
+	}
+	return &delta
+}
+
+// ReplayPrompt sends a past analysis' user prompt with the current system prompt
+func (a *FundamentalAnalyst) ReplayPrompt(ctx context.Context, symbol, userPrompt string) (*Analysis, error) {
+	return replayPrompt(ctx, a.llm, a.Type(), symbol, fundamentalSystemPrompt, userPrompt)
 }
 
 // Name returns the agent name
@@ -138,6 +256,9 @@ func (a *FundamentalAnalyst) Type() models.AgentType {
 // IsAvailable checks if the agent's dependencies are healthy.
 // Results are cached to reduce API calls during frequent availability checks.
 func (a *FundamentalAnalyst) IsAvailable(ctx context.Context) bool {
+	if available, decided := breakerAvailability(a.Degradation()); decided {
+		return available
+	}
 	if available, valid := a.healthCache.Get(); valid {
 		return available
 	}
@@ -148,6 +269,11 @@ func (a *FundamentalAnalyst) IsAvailable(ctx context.Context) bool {
 	return available
 }
 
+// Degradation returns the worst degradation level of the agent's data and LLM providers
+func (a *FundamentalAnalyst) Degradation() services.DegradationLevel {
+	return max(providerLevel(services.BreakerAlphaVantage), llmLevel(a.llm, models.AgentTypeFundamental))
+}
+
 // InvalidateHealthCache clears the health cache, forcing the next check to make a live call.
 func (a *FundamentalAnalyst) InvalidateHealthCache() {
 	a.healthCache.Invalidate()
@@ -3,6 +3,7 @@ package agents
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -274,3 +275,113 @@ func TestFundamentalAnalyst_GetMetadata(t *testing.T) {
 		t.Error("RequiredServices should include llm")
 	}
 }
+
+type mockSnapshotStore struct {
+	latest *models.FundamentalsSnapshot
+	saved  []*models.FundamentalsSnapshot
+}
+
+func (m *mockSnapshotStore) SaveFundamentalsSnapshot(ctx context.Context, snapshot *models.FundamentalsSnapshot) error {
+	m.saved = append(m.saved, snapshot)
+	return nil
+}
+
+func (m *mockSnapshotStore) GetLatestFundamentalsSnapshot(ctx context.Context, symbol string) (*models.FundamentalsSnapshot, error) {
+	return m.latest, nil
+}
+
+type mockDebtAlphaVantage struct {
+	mockAlphaVantageService
+	debt    decimal.Decimal
+	debtErr error
+}
+
+func (m *mockDebtAlphaVantage) GetTotalDebt(ctx context.Context, symbol string) (decimal.Decimal, error) {
+	return m.debt, m.debtErr
+}
+
+func TestFundamentalAnalyst_Analyze_FundamentalsDelta(t *testing.T) {
+	llm := &promptCapturingLLM{}
+	av := &mockDebtAlphaVantage{
+		mockAlphaVantageService: mockAlphaVantageService{
+			fundamentals: &models.Fundamentals{Symbol: "AAPL", Revenue: decimal.NewFromInt(120_000_000_000)},
+		},
+		debt: decimal.NewFromInt(10_000_000_000),
+	}
+	store := &mockSnapshotStore{latest: &models.FundamentalsSnapshot{
+		Symbol:       "AAPL",
+		Fundamentals: models.Fundamentals{Symbol: "AAPL", Revenue: decimal.NewFromInt(100_000_000_000)},
+		CapturedAt:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}}
+
+	analyst := NewFundamentalAnalyst(llm, av)
+	analyst.SetSnapshotStore(store)
+
+	analysis, err := analyst.Analyze(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if !strings.Contains(llm.userPrompt, "Changes since the previous analysis on 2024-03-01") ||
+		!strings.Contains(llm.userPrompt, "Revenue (TTM) 100.00B -> 120.00B (+20.0%)") ||
+		!strings.Contains(llm.userPrompt, "Total debt none -> 10.00B (new debt)") {
+		t.Errorf("prompt missing the delta:\n%s", llm.userPrompt)
+	}
+	if delta := fundamentalsDeltaOf(analysis); !delta.HasChanges() {
+		t.Error("expected the delta in the analysis data")
+	}
+	if len(store.saved) != 1 || !store.saved[0].Fundamentals.TotalDebt.Equal(av.debt) {
+		t.Errorf("saved = %+v, want one snapshot with the debt", store.saved)
+	}
+}
+
+func TestFundamentalAnalyst_Analyze_DebtUnavailable(t *testing.T) {
+	llm := &promptCapturingLLM{}
+	av := &mockDebtAlphaVantage{
+		mockAlphaVantageService: mockAlphaVantageService{
+			fundamentals: &models.Fundamentals{Symbol: "AAPL", Revenue: decimal.NewFromInt(100_000_000_000)},
+		},
+		debtErr: errors.New("rate limit reached: Alpha Vantage: daily quota"),
+	}
+	store := &mockSnapshotStore{latest: &models.FundamentalsSnapshot{
+		Symbol:       "AAPL",
+		Fundamentals: models.Fundamentals{Symbol: "AAPL", Revenue: decimal.NewFromInt(100_000_000_000), TotalDebt: decimal.NewFromInt(10_000_000_000)},
+		CapturedAt:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}}
+
+	analyst := NewFundamentalAnalyst(llm, av)
+	analyst.SetSnapshotStore(store)
+
+	if _, err := analyst.Analyze(context.Background(), "AAPL"); err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if strings.Contains(llm.userPrompt, "Total debt 10.00B ->") {
+		t.Errorf("prompt reports a debt change after a failed fetch:\n%s", llm.userPrompt)
+	}
+	if len(store.saved) != 1 || !store.saved[0].Fundamentals.TotalDebt.Equal(decimal.NewFromInt(10_000_000_000)) {
+		t.Errorf("saved = %+v, want the previous snapshot's debt carried over", store.saved)
+	}
+}
+
+func TestFundamentalAnalyst_Analyze_FirstSnapshot(t *testing.T) {
+	llm := &promptCapturingLLM{}
+	av := &mockAlphaVantageService{fundamentals: &models.Fundamentals{Symbol: "AAPL"}}
+	store := &mockSnapshotStore{}
+
+	analyst := NewFundamentalAnalyst(llm, av)
+	analyst.SetSnapshotStore(store)
+
+	analysis, err := analyst.Analyze(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if strings.Contains(llm.userPrompt, "previous analysis") {
+		t.Errorf("first analysis should have no delta:\n%s", llm.userPrompt)
+	}
+	if fundamentalsDeltaOf(analysis) != nil {
+		t.Error("expected no delta in the analysis data")
+	}
+	if len(store.saved) != 1 {
+		t.Errorf("saved %d snapshots, want 1", len(store.saved))
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"trade-machine/config"
 	"trade-machine/models"
 
 	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
@@ -316,7 +317,7 @@ func TestNewsAnalyst_HealthCache_Integration(t *testing.T) {
 		callCount: &callCount,
 	}
 
-	analyst := NewNewsAnalystWithCacheTTL(nil, mockNewsAPI, 50*time.Millisecond)
+	analyst := NewNewsAnalystWithCacheTTL(nil, mockNewsAPI, config.NewTestConfig(), 50*time.Millisecond)
 	ctx := context.Background()
 
 	// First call should hit the API
@@ -380,6 +381,10 @@ func (m *mockAlpacaServiceWithCounter) GetDailyBars(ctx context.Context, symbol
 	return nil, nil
 }
 
+func (m *mockAlpacaServiceWithCounter) GetAverageDailyVolume(ctx context.Context, symbol string, days int) (int64, error) {
+	return 0, nil
+}
+
 func (m *mockAlpacaServiceWithCounter) GetQuote(ctx context.Context, symbol string) (*models.Quote, error) {
 	return nil, nil
 }
@@ -407,10 +412,18 @@ func (m *mockAlpacaServiceWithCounter) GetPosition(ctx context.Context, symbol s
 	return nil, nil
 }
 
-func (m *mockAlpacaServiceWithCounter) PlaceOrder(ctx context.Context, symbol string, qty decimal.Decimal, side models.TradeSide, orderType string) (string, error) {
+func (m *mockAlpacaServiceWithCounter) PlaceOrder(ctx context.Context, req models.OrderRequest) (string, error) {
 	return "", nil
 }
 
+func (m *mockAlpacaServiceWithCounter) GetOrder(ctx context.Context, orderID string) (*models.BrokerOrder, error) {
+	return nil, nil
+}
+
+func (m *mockAlpacaServiceWithCounter) GetShortAvailability(ctx context.Context, symbol string) (*models.ShortAvailability, error) {
+	return nil, nil
+}
+
 type mockNewsAPIServiceWithCounter struct {
 	callCount *int
 	err       error
@@ -424,6 +437,10 @@ func (m *mockNewsAPIServiceWithCounter) GetNews(ctx context.Context, query strin
 	return []models.NewsArticle{{Title: "Test"}}, nil
 }
 
+func (m *mockNewsAPIServiceWithCounter) GetNewsSince(ctx context.Context, query string, limit int, from time.Time) ([]models.NewsArticle, error) {
+	return m.GetNews(ctx, query, limit)
+}
+
 func (m *mockNewsAPIServiceWithCounter) GetHeadlines(ctx context.Context, query string, limit int) ([]models.NewsArticle, error) {
 	return nil, nil
 }
@@ -0,0 +1,62 @@
+package agents
+
+import (
+	"fmt"
+
+	"trade-machine/models"
+)
+
+// horizon returns the configured analysis horizon. AGENT_HORIZON=overall and unrecognized
+// values use the overall technical score.
+func (m *PortfolioManager) horizon() models.AnalysisHorizon {
+	if m.cfg.Agent.Horizon == "overall" {
+		return models.HorizonOverall
+	}
+	h, err := models.ParseAnalysisHorizon(m.cfg.Agent.Horizon)
+	if err != nil {
+		return models.HorizonOverall
+	}
+	return h
+}
+
+// timeframeScoresOf returns the timeframe sub-scores a technical analysis carries, or nil.
+// Sub-scores that aren't models.TimeframeScores or fall outside -100 to 100 are logged and
+// ignored, leaving the analysis's overall score in effect.
+func timeframeScoresOf(analysis *Analysis) *models.TimeframeScores {
+	if analysis == nil || analysis.AgentType != models.AgentTypeTechnical {
+		return nil
+	}
+	raw, present := analysis.Data["timeframe_scores"]
+	if !present {
+		return nil
+	}
+	scores, ok := raw.(models.TimeframeScores)
+	if !ok {
+		logger.Warn("ignoring malformed timeframe scores",
+			"symbol", analysis.Symbol,
+			"type", fmt.Sprintf("%T", raw))
+		return nil
+	}
+	if err := scores.Validate(); err != nil {
+		logger.Warn("ignoring invalid timeframe scores",
+			"symbol", analysis.Symbol,
+			"error", err)
+		return nil
+	}
+	if scores.IsZero() {
+		return nil
+	}
+	return &scores
+}
+
+// horizonScore is the score an analysis contributes to the recommendation. Technical
+// analyses are re-weighted across their timeframes when a horizon is configured; everything
+// else uses the agent's own score.
+func (m *PortfolioManager) horizonScore(analysis *Analysis) (float64, bool) {
+	if scores := timeframeScoresOf(analysis); scores != nil {
+		if weighted, ok := scores.Weighted(m.horizon()); ok {
+			return NormalizeScore(weighted), true
+		}
+	}
+	return analysis.Score, false
+}
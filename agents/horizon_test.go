@@ -0,0 +1,102 @@
+package agents
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"trade-machine/models"
+)
+
+func TestPortfolioManager_SynthesizeRecommendation_Horizon(t *testing.T) {
+	short, medium, long := 80.0, 20.0, -40.0
+	technical := &Analysis{
+		Symbol:     "AAPL",
+		AgentType:  models.AgentTypeTechnical,
+		Score:      30,
+		Confidence: 80,
+		Data: map[string]interface{}{
+			"timeframe_scores": models.TimeframeScores{Short: &short, Medium: &medium, Long: &long},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		horizon   string
+		wantScore float64
+		wantNote  bool
+	}{
+		{"overall uses agent score", "overall", 30, false},
+		{"medium horizon", "medium", 0.25*80 + 0.5*20 + 0.25*-40, true},
+		{"short horizon", "short", 0.6*80 + 0.3*20 + 0.1*-40, true},
+		{"long horizon", "long", 0.1*80 + 0.3*20 + 0.6*-40, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Agent.Horizon = tt.horizon
+			cfg.Agent.SignalOnly = true
+			manager := NewPortfolioManager(nil, cfg, newMockAccountProvider())
+
+			rec := manager.synthesizeRecommendation(context.Background(), "AAPL", []*Analysis{technical}, nil)
+			if !floatNearlyEqual(rec.TechnicalScore, tt.wantScore, 0.01) {
+				t.Errorf("TechnicalScore = %v, want %v", rec.TechnicalScore, tt.wantScore)
+			}
+			if rec.TimeframeScores == nil || *rec.TimeframeScores.Long != long {
+				t.Errorf("TimeframeScores = %+v, want the analysis sub-scores", rec.TimeframeScores)
+			}
+			if got := strings.Contains(rec.Reasoning, "horizon"); got != tt.wantNote {
+				t.Errorf("horizon note present = %v, want %v: %q", got, tt.wantNote, rec.Reasoning)
+			}
+		})
+	}
+}
+
+func TestPortfolioManager_CombineScores_Horizon(t *testing.T) {
+	short := -60.0
+	technical := &Analysis{
+		AgentType:  models.AgentTypeTechnical,
+		Score:      40,
+		Confidence: 100,
+		Data:       map[string]interface{}{"timeframe_scores": models.TimeframeScores{Short: &short}},
+	}
+
+	cfg := testConfig()
+	cfg.Agent.Horizon = "long"
+	manager := NewPortfolioManager(nil, cfg, newMockAccountProvider())
+
+	// Only the short timeframe was scored, so it carries the whole long-horizon weight
+	score, _, _ := manager.combineScores([]*Analysis{technical}, nil)
+	if !floatNearlyEqual(score, -60, 0.01) {
+		t.Errorf("score = %v, want -60", score)
+	}
+}
+
+func TestTimeframeScoresOf_Invalid(t *testing.T) {
+	outOfRange := 250.0
+	tests := []struct {
+		name string
+		data interface{}
+	}{
+		{"out of range", models.TimeframeScores{Short: &outOfRange}},
+		{"wrong type", map[string]interface{}{"short": 10.0}},
+	}
+	for _, tt := range tests {
+		analysis := &Analysis{
+			AgentType: models.AgentTypeTechnical,
+			Score:     30,
+			Data:      map[string]interface{}{"timeframe_scores": tt.data},
+		}
+		if scores := timeframeScoresOf(analysis); scores != nil {
+			t.Errorf("%s: timeframeScoresOf() = %+v, want nil", tt.name, scores)
+		}
+
+		cfg := testConfig()
+		cfg.Agent.Horizon = "short"
+		manager := NewPortfolioManager(nil, cfg, newMockAccountProvider())
+		if score, weighted := manager.horizonScore(analysis); weighted || score != 30 {
+			t.Errorf("%s: horizonScore() = %v, %v; want the overall score", tt.name, score, weighted)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/services"
+)
+
+// insiderTradeLimit is the number of recent Form 4 filings fetched per analysis
+const insiderTradeLimit = 100
+
+// insiderClusterBuyers is the number of distinct insiders buying within the lookback that
+// counts as cluster buying, the strongest insider signal
+const insiderClusterBuyers = 3
+
+// InsiderTradesProvider supplies the insider transactions filed for a symbol
+type InsiderTradesProvider interface {
+	GetInsiderTrades(ctx context.Context, symbol string, limit int) ([]models.InsiderTrade, error)
+}
+
+// InsiderActivityAnalyst scores open-market insider buying and selling. Insiders buy with
+// their own money for one reason, so purchases count more than sales, and several insiders
+// buying together raises confidence.
+type InsiderActivityAnalyst struct {
+	trades       InsiderTradesProvider
+	lookbackDays int
+	healthCache  *HealthCache
+}
+
+// NewInsiderActivityAnalyst creates a new InsiderActivityAnalyst counting trades made within
+// lookbackDays
+func NewInsiderActivityAnalyst(trades InsiderTradesProvider, lookbackDays int) *InsiderActivityAnalyst {
+	return &InsiderActivityAnalyst{
+		trades:       trades,
+		lookbackDays: lookbackDays,
+		healthCache:  NewHealthCache(DefaultHealthCacheTTL),
+	}
+}
+
+// Analyze scores recent insider activity in a stock
+func (a *InsiderActivityAnalyst) Analyze(ctx context.Context, symbol string) (*Analysis, error) {
+	trades, err := a.trades.GetInsiderTrades(ctx, symbol, insiderTradeLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch insider trades: %w", err)
+	}
+
+	activity := models.SummarizeInsiderTrades(trades, time.Now().AddDate(0, 0, -a.lookbackDays))
+	data := map[string]interface{}{
+		"purchases":      activity.Purchases,
+		"sales":          activity.Sales,
+		"purchase_value": activity.PurchaseValue,
+		"sale_value":     activity.SaleValue,
+		"buyers":         activity.Buyers,
+		"sellers":        activity.Sellers,
+		"lookback_days":  a.lookbackDays,
+	}
+
+	if activity.Purchases+activity.Sales == 0 {
+		return &Analysis{
+			Symbol:     symbol,
+			AgentType:  models.AgentTypeInsider,
+			Score:      0,
+			Confidence: 20,
+			Reasoning:  fmt.Sprintf("No open-market insider trades in the last %d days", a.lookbackDays),
+			Data:       data,
+			Timestamp:  time.Now(),
+		}, nil
+	}
+
+	// Each insider trading is an independent view; more of them make the signal firmer
+	confidence := min(40+10*float64(activity.Buyers+activity.Sellers), 85)
+	reasoning := fmt.Sprintf("In the last %d days %d insider(s) bought $%.0f and %d sold $%.0f on the open market.",
+		a.lookbackDays, activity.Buyers, activity.PurchaseValue, activity.Sellers, activity.SaleValue)
+	if activity.Buyers >= insiderClusterBuyers {
+		confidence = 85
+		reasoning += " Cluster buying by several insiders is a strong bullish signal."
+	}
+
+	return &Analysis{
+		Symbol:     symbol,
+		AgentType:  models.AgentTypeInsider,
+		Score:      NormalizeScore(activity.Score()),
+		Confidence: NormalizeConfidence(confidence),
+		Reasoning:  reasoning,
+		Data:       data,
+		Timestamp:  time.Now(),
+	}, nil
+}
+
+// Name returns the agent name
+func (a *InsiderActivityAnalyst) Name() string {
+	return "Insider Activity Analyst"
+}
+
+// Type returns the agent type
+func (a *InsiderActivityAnalyst) Type() models.AgentType {
+	return models.AgentTypeInsider
+}
+
+// IsAvailable checks if the agent's dependencies are healthy.
+// Results are cached to reduce API calls during frequent availability checks.
+func (a *InsiderActivityAnalyst) IsAvailable(ctx context.Context) bool {
+	if available, decided := breakerAvailability(a.Degradation()); decided {
+		return available
+	}
+	if available, valid := a.healthCache.Get(); valid {
+		return available
+	}
+
+	_, err := a.trades.GetInsiderTrades(ctx, "AAPL", 1)
+	available := err == nil
+	a.healthCache.Set(available)
+	return available
+}
+
+// Degradation returns the degradation level of the agent's FMP provider
+func (a *InsiderActivityAnalyst) Degradation() services.DegradationLevel {
+	return providerLevel(services.BreakerFMP)
+}
+
+// InvalidateHealthCache clears the health cache, forcing the next check to make a live call.
+func (a *InsiderActivityAnalyst) InvalidateHealthCache() {
+	a.healthCache.Invalidate()
+}
+
+// GetMetadata returns information about this agent's capabilities
+func (a *InsiderActivityAnalyst) GetMetadata() AgentMetadata {
+	return AgentMetadata{
+		Description:      "Scores open-market insider buying and selling from SEC Form 4 filings",
+		Version:          "1.0.0",
+		RequiredServices: []string{"fmp"},
+	}
+}
@@ -0,0 +1,142 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+)
+
+func insiderTrade(insider, transactionType string, shares, price float64, daysAgo int) models.InsiderTrade {
+	return models.InsiderTrade{
+		Symbol:          "AAPL",
+		Insider:         insider,
+		TransactionType: transactionType,
+		Shares:          shares,
+		Price:           price,
+		TradedAt:        time.Now().AddDate(0, 0, -daysAgo),
+	}
+}
+
+func TestInsiderActivityAnalyst_Type(t *testing.T) {
+	analyst := &InsiderActivityAnalyst{}
+	if analyst.Type() != models.AgentTypeInsider {
+		t.Errorf("Type() = %v, want AgentTypeInsider", analyst.Type())
+	}
+}
+
+func TestInsiderActivityAnalyst_Analyze(t *testing.T) {
+	tests := []struct {
+		name           string
+		trades         []models.InsiderTrade
+		wantScore      float64
+		wantConfidence float64
+		wantReasoning  string
+	}{
+		{
+			name:           "no trades",
+			wantScore:      0,
+			wantConfidence: 20,
+			wantReasoning:  "No open-market insider trades",
+		},
+		{
+			name: "awards and old trades are ignored",
+			trades: []models.InsiderTrade{
+				insiderTrade("Cook Tim", "A-Award", 1000, 0, 5),
+				insiderTrade("Cook Tim", "P-Purchase", 1000, 100, 200),
+			},
+			wantScore:      0,
+			wantConfidence: 20,
+			wantReasoning:  "No open-market insider trades",
+		},
+		{
+			name: "purchase outweighs a discounted sale of the same value",
+			trades: []models.InsiderTrade{
+				insiderTrade("Cook Tim", "P-Purchase", 1000, 100, 5),
+				insiderTrade("Maestri Luca", "S-Sale", 1000, 100, 10),
+			},
+			wantScore:      100.0 / 3,
+			wantConfidence: 60,
+			wantReasoning:  "1 insider(s) bought $100000 and 1 sold $100000",
+		},
+		{
+			name: "only selling",
+			trades: []models.InsiderTrade{
+				insiderTrade("Maestri Luca", "S-Sale", 500, 100, 5),
+			},
+			wantScore:      -100,
+			wantConfidence: 50,
+		},
+		{
+			name: "cluster buying",
+			trades: []models.InsiderTrade{
+				insiderTrade("Cook Tim", "P-Purchase", 100, 100, 1),
+				insiderTrade("Maestri Luca", "P-Purchase", 100, 100, 2),
+				insiderTrade("Williams Jeff", "P-Purchase", 100, 100, 3),
+			},
+			wantScore:      100,
+			wantConfidence: 85,
+			wantReasoning:  "Cluster buying",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyst := NewInsiderActivityAnalyst(&mockInsiderTrades{trades: tt.trades}, 90)
+
+			analysis, err := analyst.Analyze(context.Background(), "AAPL")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if analysis.AgentType != models.AgentTypeInsider {
+				t.Errorf("AgentType = %v, want AgentTypeInsider", analysis.AgentType)
+			}
+			if diff := analysis.Score - tt.wantScore; diff > 0.01 || diff < -0.01 {
+				t.Errorf("Score = %v, want %v", analysis.Score, tt.wantScore)
+			}
+			if analysis.Confidence != tt.wantConfidence {
+				t.Errorf("Confidence = %v, want %v", analysis.Confidence, tt.wantConfidence)
+			}
+			if !strings.Contains(analysis.Reasoning, tt.wantReasoning) {
+				t.Errorf("Reasoning = %q, want it to contain %q", analysis.Reasoning, tt.wantReasoning)
+			}
+		})
+	}
+}
+
+func TestInsiderActivityAnalyst_Analyze_Error(t *testing.T) {
+	analyst := NewInsiderActivityAnalyst(&mockInsiderTrades{err: errors.New("fmp down")}, 90)
+	if _, err := analyst.Analyze(context.Background(), "AAPL"); err == nil {
+		t.Error("expected an error when insider trades can't be fetched")
+	}
+}
+
+func TestPortfolioManager_SynthesizeRecommendation_Insider(t *testing.T) {
+	cfg := testConfig()
+	cfg.Agent.WeightFundamental = 0.3
+	cfg.Agent.WeightNews = 0.2
+	cfg.Agent.WeightInsider = 0.2
+	manager := NewPortfolioManager(nil, cfg, newMockAccountProvider())
+	manager.RegisterAgent(NewInsiderActivityAnalyst(&mockInsiderTrades{}, 90))
+
+	analyses := []*Analysis{
+		{AgentType: models.AgentTypeFundamental, Score: 40, Confidence: 80},
+		{AgentType: models.AgentTypeNews, Score: 40, Confidence: 80},
+		{AgentType: models.AgentTypeTechnical, Score: 40, Confidence: 80},
+		{AgentType: models.AgentTypeInsider, Score: 90, Confidence: 80},
+	}
+	rec := manager.synthesizeRecommendation(context.Background(), "AAPL", analyses, nil)
+
+	if rec.InsiderScore != 90 {
+		t.Errorf("InsiderScore = %v, want 90", rec.InsiderScore)
+	}
+	if rec.DataCompleteness != 100 {
+		t.Errorf("DataCompleteness = %v, want 100 with all four agents", rec.DataCompleteness)
+	}
+	if !strings.Contains(rec.Reasoning, "Technical: 40, Insider: 90. Overall score: 50.0") {
+		t.Errorf("expected the insider score to lift the overall score to 50, got %q", rec.Reasoning)
+	}
+}
@@ -9,4 +9,7 @@ import (
 type LLMService = services.LLMService
 type AlphaVantageServiceInterface = services.AlphaVantageServiceInterface
 type NewsAPIServiceInterface = services.NewsAPIServiceInterface
+type SocialSentimentServiceInterface = services.SocialSentimentServiceInterface
+type FREDServiceInterface = services.FREDServiceInterface
 type AlpacaServiceInterface = services.AlpacaServiceInterface
+type ChatMessage = services.ChatMessage
@@ -0,0 +1,88 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"trade-machine/services"
+)
+
+// DefaultLanguage is the language agents respond in when none is configured
+const DefaultLanguage = "en"
+
+// languageNames maps supported ISO 639-1 codes to the name used in prompts
+var languageNames = map[string]string{
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"de": "German",
+	"pt": "Portuguese",
+	"it": "Italian",
+	"ja": "Japanese",
+	"zh": "Chinese",
+}
+
+// LanguageName returns the English name for a language code, or the code itself if unknown
+func LanguageName(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// LanguageInstruction returns the system prompt suffix asking the model to
+// write free-text fields in the given language. Returns an empty string for English.
+func LanguageInstruction(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" || code == DefaultLanguage {
+		return ""
+	}
+	return fmt.Sprintf("\n\nWrite the \"reasoning\" and any other free-text fields in %s. "+
+		"Keep JSON keys, numbers, and stock symbols unchanged.", LanguageName(code))
+}
+
+// localizedLLM wraps an LLMService and appends a language instruction to every system prompt
+type localizedLLM struct {
+	LLMService
+	instruction string
+}
+
+// WithLanguage returns an LLMService that instructs the model to respond in the given language.
+// The original service is returned unchanged for English or an empty code.
+func WithLanguage(llm LLMService, code string) LLMService {
+	instruction := LanguageInstruction(code)
+	if llm == nil || instruction == "" {
+		return llm
+	}
+	return &localizedLLM{LLMService: llm, instruction: instruction}
+}
+
+// InvokeWithPrompt calls the wrapped service with the language instruction appended
+func (l *localizedLLM) InvokeWithPrompt(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return l.LLMService.InvokeWithPrompt(ctx, systemPrompt+l.instruction, userPrompt)
+}
+
+// InvokeStructured calls the wrapped service with the language instruction appended
+func (l *localizedLLM) InvokeStructured(ctx context.Context, systemPrompt, userPrompt string, result interface{}) error {
+	return l.LLMService.InvokeStructured(ctx, systemPrompt+l.instruction, userPrompt, result)
+}
+
+// Chat calls the wrapped service with the language instruction appended
+func (l *localizedLLM) Chat(ctx context.Context, systemPrompt string, messages []ChatMessage) (string, error) {
+	return l.LLMService.Chat(ctx, systemPrompt+l.instruction, messages)
+}
+
+// ChatStream calls the wrapped service with the language instruction appended
+func (l *localizedLLM) ChatStream(ctx context.Context, systemPrompt string, messages []ChatMessage, onDelta func(delta string) error) (string, error) {
+	return l.LLMService.ChatStream(ctx, systemPrompt+l.instruction, messages, onDelta)
+}
+
+// LLMLevel forwards to the wrapped service when it routes agents between providers
+func (l *localizedLLM) LLMLevel(agent string) services.DegradationLevel {
+	if leveler, ok := l.LLMService.(services.LLMLeveler); ok {
+		return leveler.LLMLevel(agent)
+	}
+	return services.BreakerLevel(services.BreakerOpenAI)
+}
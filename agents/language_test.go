@@ -0,0 +1,114 @@
+package agents
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"trade-machine/services"
+)
+
+type promptCapturingLLM struct {
+	systemPrompt string
+	userPrompt   string
+}
+
+func (m *promptCapturingLLM) InvokeWithPrompt(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	m.systemPrompt = systemPrompt
+	m.userPrompt = userPrompt
+	return "ok", nil
+}
+
+func (m *promptCapturingLLM) InvokeStructured(ctx context.Context, systemPrompt, userPrompt string, result interface{}) error {
+	m.systemPrompt = systemPrompt
+	return nil
+}
+
+func (m *promptCapturingLLM) Chat(ctx context.Context, systemPrompt string, messages []services.ChatMessage) (string, error) {
+	m.systemPrompt = systemPrompt
+	return "ok", nil
+}
+
+func (m *promptCapturingLLM) ChatStream(ctx context.Context, systemPrompt string, messages []services.ChatMessage, onDelta func(delta string) error) (string, error) {
+	m.systemPrompt = systemPrompt
+	return "ok", onDelta("ok")
+}
+
+func TestLanguageName(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"en", "English"},
+		{"ES", "Spanish"},
+		{" fr ", "French"},
+		{"xx", "xx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := LanguageName(tt.code); got != tt.want {
+				t.Errorf("LanguageName(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLanguageInstruction(t *testing.T) {
+	if got := LanguageInstruction(""); got != "" {
+		t.Errorf("LanguageInstruction(\"\") = %q, want empty", got)
+	}
+	if got := LanguageInstruction("en"); got != "" {
+		t.Errorf("LanguageInstruction(\"en\") = %q, want empty", got)
+	}
+	if got := LanguageInstruction("de"); !strings.Contains(got, "German") {
+		t.Errorf("LanguageInstruction(\"de\") = %q, want it to mention German", got)
+	}
+}
+
+func TestWithLanguage_English(t *testing.T) {
+	llm := &promptCapturingLLM{}
+	if got := WithLanguage(llm, "en"); got != LLMService(llm) {
+		t.Error("WithLanguage should return the original service for English")
+	}
+	if got := WithLanguage(nil, "es"); got != nil {
+		t.Error("WithLanguage should return nil for a nil service")
+	}
+}
+
+func TestWithLanguage_AppendsInstruction(t *testing.T) {
+	llm := &promptCapturingLLM{}
+	localized := WithLanguage(llm, "es")
+	ctx := context.Background()
+
+	if _, err := localized.InvokeWithPrompt(ctx, "system", "user"); err != nil {
+		t.Fatalf("InvokeWithPrompt() error = %v", err)
+	}
+	if !strings.HasPrefix(llm.systemPrompt, "system") || !strings.Contains(llm.systemPrompt, "Spanish") {
+		t.Errorf("InvokeWithPrompt system prompt = %q", llm.systemPrompt)
+	}
+
+	llm.systemPrompt = ""
+	if err := localized.InvokeStructured(ctx, "system", "user", nil); err != nil {
+		t.Fatalf("InvokeStructured() error = %v", err)
+	}
+	if !strings.Contains(llm.systemPrompt, "Spanish") {
+		t.Errorf("InvokeStructured system prompt = %q", llm.systemPrompt)
+	}
+
+	llm.systemPrompt = ""
+	if _, err := localized.Chat(ctx, "system", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if !strings.Contains(llm.systemPrompt, "Spanish") {
+		t.Errorf("Chat system prompt = %q", llm.systemPrompt)
+	}
+
+	llm.systemPrompt = ""
+	if _, err := localized.ChatStream(ctx, "system", nil, func(string) error { return nil }); err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if !strings.Contains(llm.systemPrompt, "Spanish") {
+		t.Errorf("ChatStream system prompt = %q", llm.systemPrompt)
+	}
+}
@@ -0,0 +1,44 @@
+package agents
+
+import (
+	"context"
+
+	"trade-machine/models"
+)
+
+// VolumeProvider is implemented by account providers that can report a symbol's average
+// daily volume
+type VolumeProvider interface {
+	GetAverageDailyVolume(ctx context.Context, symbol string, days int) (int64, error)
+}
+
+// noteLiquidity adds a warning to the reasoning when the recommended quantity is a large
+// share of the symbol's average daily volume. With POSITION_ADV_MODE=reject such orders are
+// refused at approval, which the warning says so the quantity can be edited first.
+func (m *PortfolioManager) noteLiquidity(ctx context.Context, rec *models.Recommendation) {
+	sizing := m.cfg.PositionSizing
+	if sizing.MaxADVPercent <= 0 || rec.Action == models.RecommendationActionHold || !rec.Quantity.IsPositive() {
+		return
+	}
+	provider, ok := m.accountProvider.(VolumeProvider)
+	if !ok {
+		return
+	}
+
+	adv, err := provider.GetAverageDailyVolume(ctx, rec.Symbol, sizing.ADVLookbackDays)
+	if err != nil {
+		logger.Warn("failed to get average daily volume",
+			"symbol", rec.Symbol,
+			"error", err)
+		return
+	}
+
+	warning, _ := models.LiquidityLimit{MaxADVPercent: sizing.MaxADVPercent}.Check(rec.Symbol, rec.Quantity, adv)
+	if warning == "" {
+		return
+	}
+	rec.Reasoning += "Liquidity warning: " + warning + ". "
+	if sizing.ADVMode == "reject" {
+		rec.Reasoning += "Reduce the quantity before approving; larger orders are rejected. "
+	}
+}
@@ -0,0 +1,60 @@
+package agents
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// volumeAccountProvider adds average daily volume to mockAccountProvider
+type volumeAccountProvider struct {
+	*mockAccountProvider
+	adv int64
+}
+
+func (p *volumeAccountProvider) GetAverageDailyVolume(ctx context.Context, symbol string, days int) (int64, error) {
+	return p.adv, nil
+}
+
+func TestPortfolioManager_NoteLiquidity(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		adv        int64
+		wantNote   bool
+		wantReject bool
+	}{
+		{"liquid", "warn", 1_000_000, false, false},
+		{"thin volume warns", "warn", 10_000, true, false},
+		{"thin volume in reject mode", "reject", 10_000, true, true},
+		{"unknown volume", "warn", 0, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.PositionSizing.MaxADVPercent = 0.01
+			cfg.PositionSizing.ADVMode = tt.mode
+			provider := &volumeAccountProvider{mockAccountProvider: newMockAccountProvider(), adv: tt.adv}
+			manager := NewPortfolioManager(nil, cfg, provider)
+
+			rec := models.NewRecommendation("THIN", models.RecommendationActionBuy, "")
+			rec.Quantity = decimal.NewFromInt(500)
+			manager.noteLiquidity(context.Background(), rec)
+
+			if got := strings.Contains(rec.Reasoning, "average daily volume"); got != tt.wantNote {
+				t.Errorf("liquidity note = %v, want %v (reasoning %q)", got, tt.wantNote, rec.Reasoning)
+			}
+			if got := strings.Contains(rec.Reasoning, "rejected"); got != tt.wantReject {
+				t.Errorf("reject note = %v, want %v (reasoning %q)", got, tt.wantReject, rec.Reasoning)
+			}
+			if rec.Action != models.RecommendationActionBuy || !rec.Quantity.Equal(decimal.NewFromInt(500)) {
+				t.Errorf("recommendation changed to %s x %s, want only a note", rec.Action, rec.Quantity)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/services"
+)
+
+// LLMUsageStore records the LLM usage of agent runs and totals the spend checked against
+// the monthly budget
+type LLMUsageStore interface {
+	SaveLLMUsage(ctx context.Context, usage []models.LLMUsage) error
+	GetLLMCostSince(ctx context.Context, since time.Time) (float64, error)
+}
+
+// SetLLMUsageStore enables recording LLM usage and enforcing LLM_MONTHLY_BUDGET (optional
+// dependency). Without it token counts are still added to each agent run's output.
+func (m *PortfolioManager) SetLLMUsageStore(store LLMUsageStore) {
+	m.llmUsage = store
+}
+
+// checkLLMBudget returns ErrLLMBudgetExceeded once the month's estimated LLM spend reaches
+// the budget and LLM_BUDGET_MODE is block; in warn mode it only logs. A failed lookup
+// doesn't stop the analysis.
+func (m *PortfolioManager) checkLLMBudget(ctx context.Context) error {
+	budget := m.cfg.LLM.MonthlyBudget
+	if budget <= 0 || m.llmUsage == nil {
+		return nil
+	}
+	since, _ := models.LLMUsagePeriodStart("month", time.Now())
+	spent, err := m.llmUsage.GetLLMCostSince(ctx, since)
+	if err != nil {
+		logger.Warn("failed to check LLM budget", "error", err)
+		return nil
+	}
+	if spent < budget {
+		return nil
+	}
+	if m.cfg.LLM.BudgetMode == "block" {
+		return fmt.Errorf("%w: $%.2f of $%.2f spent this month", models.ErrLLMBudgetExceeded, spent, budget)
+	}
+	logger.Warn("monthly LLM budget exceeded", "spent_usd", spent, "budget_usd", budget)
+	return nil
+}
+
+// recordLLMUsage saves the LLM calls tallied while an agent ran and adds their totals to
+// the run's output. run is nil for a dry-run replay, whose calls are saved all the same.
+func (m *PortfolioManager) recordLLMUsage(ctx context.Context, agentType models.AgentType, symbol string, run *models.AgentRun, tally *services.LLMUsageTally) {
+	calls := tally.Calls()
+	if len(calls) == 0 {
+		return
+	}
+
+	usage := make([]models.LLMUsage, len(calls))
+	var totals models.LLMUsageTotals
+	for i, call := range calls {
+		usage[i] = models.NewLLMUsage(agentType, symbol, call.Provider, call.Model, call.InputTokens, call.OutputTokens)
+		if run != nil {
+			usage[i].AgentRunID = &run.ID
+		}
+		totals.InputTokens += usage[i].InputTokens
+		totals.OutputTokens += usage[i].OutputTokens
+		totals.CostUSD += usage[i].CostUSD
+	}
+	if run != nil {
+		run.OutputData["input_tokens"] = totals.InputTokens
+		run.OutputData["output_tokens"] = totals.OutputTokens
+		run.OutputData["cost_usd"] = totals.CostUSD
+	}
+
+	if m.llmUsage == nil {
+		return
+	}
+	if err := m.llmUsage.SaveLLMUsage(ctx, usage); err != nil {
+		logger.Warn("failed to save LLM usage", "agent", agentType, "symbol", symbol, "error", err)
+	}
+}
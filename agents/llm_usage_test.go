@@ -0,0 +1,93 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/services"
+)
+
+// meteredLLMService reports token usage for every call, as the real LLM services do
+type meteredLLMService struct {
+	mockLLMService
+}
+
+func (m *meteredLLMService) InvokeWithPrompt(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	services.RecordLLMUsage(ctx, "openai", "gpt-4o", 1000, 200)
+	return m.mockLLMService.InvokeWithPrompt(ctx, systemPrompt, userPrompt)
+}
+
+// usageStore keeps saved LLM usage and reports a fixed monthly spend
+type usageStore struct {
+	saved []models.LLMUsage
+	spent float64
+}
+
+func (s *usageStore) SaveLLMUsage(ctx context.Context, usage []models.LLMUsage) error {
+	s.saved = append(s.saved, usage...)
+	return nil
+}
+
+func (s *usageStore) GetLLMCostSince(ctx context.Context, since time.Time) (float64, error) {
+	return s.spent, nil
+}
+
+func TestPortfolioManager_RecordsLLMUsage(t *testing.T) {
+	ctx := context.Background()
+	repo := &runRecordingRepo{}
+	store := &usageStore{}
+	manager := NewPortfolioManager(repo, testConfig(), newMockAccountProvider())
+	manager.SetLLMUsageStore(store)
+	llm := &meteredLLMService{mockLLMService{response: `{"score": 40, "confidence": 70, "reasoning": "Strong launch", "article_scores": [40]}`}}
+	news := &mockNewsAPIService{articles: []models.NewsArticle{{Title: "Apple launches a new phone", Source: "Reuters", PublishedAt: time.Now().Add(-time.Hour)}}}
+	manager.RegisterAgent(NewNewsAnalyst(llm, news, testConfig()))
+
+	manager.runAgent(ctx, 0, manager.agents[0], "AAPL")
+	if len(repo.runs) != 1 || len(store.saved) != 1 {
+		t.Fatalf("got %d runs and %d usage records, want one of each", len(repo.runs), len(store.saved))
+	}
+	run, usage := repo.runs[0], store.saved[0]
+	if usage.AgentRunID == nil || *usage.AgentRunID != run.ID || usage.AgentType != models.AgentTypeNews || usage.Symbol != "AAPL" {
+		t.Errorf("usage = %+v, want it linked to the news run", usage)
+	}
+	wantCost, _ := models.EstimateLLMCost("openai", "gpt-4o", 1000, 200)
+	if usage.CostUSD != wantCost || run.OutputData["input_tokens"] != int64(1000) || run.OutputData["cost_usd"] != wantCost {
+		t.Errorf("run output = %v, usage = %+v, want 1000 input tokens costing %v", run.OutputData, usage, wantCost)
+	}
+
+	// Dry-run replays are charged without a run to link to
+	if _, err := manager.ReplayAgentRun(ctx, run, true); err != nil {
+		t.Fatalf("ReplayAgentRun error = %v", err)
+	}
+	if len(store.saved) != 2 || store.saved[1].AgentRunID != nil {
+		t.Errorf("usage = %+v, want the dry run recorded without a run", store.saved)
+	}
+}
+
+func TestPortfolioManager_LLMBudget(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		mode    string
+		spent   float64
+		blocked bool
+	}{
+		{mode: "block", spent: 50, blocked: true},
+		{mode: "block", spent: 49.99, blocked: false},
+		{mode: "warn", spent: 80, blocked: false},
+	} {
+		cfg := testConfig()
+		cfg.LLM.MonthlyBudget = 50
+		cfg.LLM.BudgetMode = tt.mode
+		manager := NewPortfolioManager(&completingRepo{}, cfg, newMockAccountProvider())
+		manager.SetLLMUsageStore(&usageStore{spent: tt.spent})
+		manager.RegisterAgent(&testMockAgent{name: "Fundamental", agentType: models.AgentTypeFundamental, isAvailable: true})
+
+		_, err := manager.AnalyzeSymbol(ctx, "AAPL")
+		if blocked := errors.Is(err, models.ErrLLMBudgetExceeded); blocked != tt.blocked {
+			t.Errorf("mode %s with $%.2f spent: error = %v, want blocked = %v", tt.mode, tt.spent, err, tt.blocked)
+		}
+	}
+}
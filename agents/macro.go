@@ -0,0 +1,150 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/services"
+)
+
+// macroStaleAfter is the snapshot age past which its readings are treated as stale.
+// Monthly series are published two to six weeks after the month ends.
+const macroStaleAfter = 75 * 24 * time.Hour
+
+// MacroAnalyst scores the economic backdrop from interest rates, inflation and
+// unemployment. The score is the same for every symbol: it tilts all recommendations
+// toward buying when conditions are easing and away from it when they are tightening.
+type MacroAnalyst struct {
+	fred        FREDServiceInterface
+	healthCache *HealthCache
+}
+
+// NewMacroAnalyst creates a new MacroAnalyst
+func NewMacroAnalyst(fred FREDServiceInterface) *MacroAnalyst {
+	return &MacroAnalyst{
+		fred:        fred,
+		healthCache: NewHealthCache(DefaultHealthCacheTTL),
+	}
+}
+
+// Analyze scores the current macro environment. The symbol only labels the analysis.
+func (a *MacroAnalyst) Analyze(ctx context.Context, symbol string) (*Analysis, error) {
+	snapshot, err := a.fred.GetMacroSnapshot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch macro indicators: %w", err)
+	}
+
+	score, signals := scoreMacro(snapshot)
+	confidence := 50.0
+	if time.Since(snapshot.AsOf) > macroStaleAfter {
+		confidence = 30
+		signals = append(signals, fmt.Sprintf("readings are stale (as of %s)", snapshot.AsOf.Format("Jan 2006")))
+	}
+
+	return &Analysis{
+		Symbol:     symbol,
+		AgentType:  models.AgentTypeMacro,
+		Score:      NormalizeScore(score),
+		Confidence: confidence,
+		Reasoning:  "Macro environment: " + strings.Join(signals, "; ") + ".",
+		Data: map[string]interface{}{
+			"fed_funds_rate":    snapshot.FedFundsRate,
+			"fed_funds_change":  snapshot.FedFundsChange,
+			"yield_curve":       snapshot.YieldCurve,
+			"inflation":         snapshot.Inflation,
+			"unemployment_rate": snapshot.UnemploymentRate,
+			"unemployment_rise": snapshot.UnemploymentRise,
+			"as_of":             snapshot.AsOf,
+		},
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// scoreMacro adds up the contribution of each indicator and explains it. Rate cuts, inflation
+// near the Fed's 2% target, steady unemployment and an upward-sloping yield curve score
+// positively; hikes, high inflation, a Sahm rule trigger and an inverted curve negatively.
+func scoreMacro(s *models.MacroSnapshot) (float64, []string) {
+	var score float64
+	var signals []string
+
+	// Each point of cuts over the past year is worth 20, up to 30 either way
+	score += min(max(-s.FedFundsChange*20, -30), 30)
+	switch {
+	case s.FedFundsChange <= -0.25:
+		signals = append(signals, fmt.Sprintf("the Fed has cut rates %.2f points in the past year to %.2f%%", -s.FedFundsChange, s.FedFundsRate))
+	case s.FedFundsChange >= 0.25:
+		signals = append(signals, fmt.Sprintf("the Fed has raised rates %.2f points in the past year to %.2f%%", s.FedFundsChange, s.FedFundsRate))
+	default:
+		signals = append(signals, fmt.Sprintf("rates are steady at %.2f%%", s.FedFundsRate))
+	}
+
+	// Inflation at 3% is neutral; each point below adds 10 (capped at 10), each above costs 10
+	score += min(max((3-s.Inflation)*10, -30), 10)
+	signals = append(signals, fmt.Sprintf("inflation is %.1f%% year over year", s.Inflation))
+
+	if s.SahmRecession() {
+		score -= 40
+		signals = append(signals, fmt.Sprintf("unemployment at %.1f%% is %.1f points above its low, tripping the Sahm recession rule", s.UnemploymentRate, s.UnemploymentRise))
+	} else {
+		score += min(max((0.25-s.UnemploymentRise)*40, -10), 10)
+		signals = append(signals, fmt.Sprintf("unemployment is %.1f%%", s.UnemploymentRate))
+	}
+
+	if s.YieldCurveInverted() {
+		score -= 15
+		signals = append(signals, fmt.Sprintf("the yield curve is inverted (10y-2y %.2f)", s.YieldCurve))
+	} else {
+		score += min(s.YieldCurve*10, 10)
+		signals = append(signals, fmt.Sprintf("the yield curve slopes upward (10y-2y %.2f)", s.YieldCurve))
+	}
+
+	return score, signals
+}
+
+// Name returns the agent name
+func (a *MacroAnalyst) Name() string {
+	return "Macro Analyst"
+}
+
+// Type returns the agent type
+func (a *MacroAnalyst) Type() models.AgentType {
+	return models.AgentTypeMacro
+}
+
+// IsAvailable checks if the agent's dependencies are healthy.
+// Results are cached to reduce API calls during frequent availability checks.
+func (a *MacroAnalyst) IsAvailable(ctx context.Context) bool {
+	if available, decided := breakerAvailability(a.Degradation()); decided {
+		return available
+	}
+	if available, valid := a.healthCache.Get(); valid {
+		return available
+	}
+
+	_, err := a.fred.GetMacroSnapshot(ctx)
+	available := err == nil
+	a.healthCache.Set(available)
+	return available
+}
+
+// Degradation returns the degradation level of the agent's FRED provider
+func (a *MacroAnalyst) Degradation() services.DegradationLevel {
+	return providerLevel(services.BreakerFRED)
+}
+
+// InvalidateHealthCache clears the health cache, forcing the next check to make a live call.
+func (a *MacroAnalyst) InvalidateHealthCache() {
+	a.healthCache.Invalidate()
+}
+
+// GetMetadata returns information about this agent's capabilities
+func (a *MacroAnalyst) GetMetadata() AgentMetadata {
+	return AgentMetadata{
+		Description:      "Scores the macro environment from FRED interest rate, inflation and unemployment data",
+		Version:          "1.0.0",
+		RequiredServices: []string{"fred"},
+	}
+}
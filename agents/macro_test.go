@@ -0,0 +1,116 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+)
+
+func TestMacroAnalyst_Type(t *testing.T) {
+	analyst := &MacroAnalyst{}
+	if analyst.Type() != models.AgentTypeMacro {
+		t.Errorf("Type() = %v, want AgentTypeMacro", analyst.Type())
+	}
+}
+
+func TestMacroAnalyst_Analyze(t *testing.T) {
+	recent := time.Now().AddDate(0, -1, 0)
+	tests := []struct {
+		name           string
+		snapshot       models.MacroSnapshot
+		wantScore      float64
+		wantConfidence float64
+		wantReasoning  string
+	}{
+		{
+			name: "easing",
+			snapshot: models.MacroSnapshot{
+				FedFundsRate: 4.33, FedFundsChange: -1.0, YieldCurve: 0.5,
+				Inflation: 2.5, UnemploymentRate: 4.0, UnemploymentRise: 0.1, AsOf: recent,
+			},
+			// cuts +20, inflation +5, unemployment +6, curve +5
+			wantScore:      36,
+			wantConfidence: 50,
+			wantReasoning:  "the Fed has cut rates 1.00 points",
+		},
+		{
+			name: "tightening into a recession signal",
+			snapshot: models.MacroSnapshot{
+				FedFundsRate: 5.33, FedFundsChange: 2.0, YieldCurve: -0.5,
+				Inflation: 5.0, UnemploymentRate: 4.3, UnemploymentRise: 0.6, AsOf: recent,
+			},
+			// hikes -30, inflation -20, Sahm rule -40, inverted curve -15
+			wantScore:      -100,
+			wantConfidence: 50,
+			wantReasoning:  "tripping the Sahm recession rule",
+		},
+		{
+			name: "stale readings",
+			snapshot: models.MacroSnapshot{
+				FedFundsRate: 5.33, YieldCurve: 1.0, Inflation: 3.0, UnemploymentRate: 4.0,
+				UnemploymentRise: 0.25, AsOf: time.Now().AddDate(0, -6, 0),
+			},
+			wantScore:      10,
+			wantConfidence: 30,
+			wantReasoning:  "readings are stale",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyst := NewMacroAnalyst(&mockFREDService{snapshot: &tt.snapshot})
+
+			analysis, err := analyst.Analyze(context.Background(), "AAPL")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if analysis.AgentType != models.AgentTypeMacro || analysis.Symbol != "AAPL" {
+				t.Errorf("unexpected analysis %+v", analysis)
+			}
+			if math.Abs(analysis.Score-tt.wantScore) > 0.01 {
+				t.Errorf("Score = %v, want %v", analysis.Score, tt.wantScore)
+			}
+			if analysis.Confidence != tt.wantConfidence {
+				t.Errorf("Confidence = %v, want %v", analysis.Confidence, tt.wantConfidence)
+			}
+			if !strings.Contains(analysis.Reasoning, tt.wantReasoning) {
+				t.Errorf("Reasoning = %q, want it to contain %q", analysis.Reasoning, tt.wantReasoning)
+			}
+		})
+	}
+}
+
+func TestMacroAnalyst_Analyze_Error(t *testing.T) {
+	analyst := NewMacroAnalyst(&mockFREDService{err: errors.New("fred down")})
+	if _, err := analyst.Analyze(context.Background(), "AAPL"); err == nil {
+		t.Error("expected an error when macro indicators can't be fetched")
+	}
+}
+
+func TestPortfolioManager_SynthesizeRecommendation_Macro(t *testing.T) {
+	cfg := testConfig()
+	cfg.Agent.WeightFundamental = 0.3
+	cfg.Agent.WeightMacro = 0.1
+	manager := NewPortfolioManager(nil, cfg, newMockAccountProvider())
+	manager.RegisterAgent(NewMacroAnalyst(&mockFREDService{}))
+
+	analyses := []*Analysis{
+		{AgentType: models.AgentTypeFundamental, Score: 40, Confidence: 80},
+		{AgentType: models.AgentTypeNews, Score: 40, Confidence: 80},
+		{AgentType: models.AgentTypeTechnical, Score: 40, Confidence: 80},
+		{AgentType: models.AgentTypeMacro, Score: -60, Confidence: 80},
+	}
+	rec := manager.synthesizeRecommendation(context.Background(), "AAPL", analyses, nil)
+
+	if rec.MacroScore != -60 {
+		t.Errorf("MacroScore = %v, want -60", rec.MacroScore)
+	}
+	if !strings.Contains(rec.Reasoning, "Technical: 40, Macro: -60. Overall score: 30.0") {
+		t.Errorf("expected the macro score to pull the overall score to 30, got %q", rec.Reasoning)
+	}
+}
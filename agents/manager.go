@@ -3,12 +3,16 @@ package agents
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 
 	"trade-machine/config"
+	"trade-machine/events"
 	"trade-machine/models"
 	"trade-machine/observability"
+	"trade-machine/services"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -19,6 +23,8 @@ type PortfolioManagerRepository interface {
 	CreateAgentRun(ctx context.Context, run *models.AgentRun) error
 	UpdateAgentRun(ctx context.Context, run *models.AgentRun) error
 	CreateRecommendation(ctx context.Context, rec *models.Recommendation) error
+	GetRecommendation(ctx context.Context, id uuid.UUID) (*models.Recommendation, error)
+	CompleteRecommendation(ctx context.Context, rec *models.Recommendation) error
 }
 
 // AccountProvider provides account and position information for position sizing
@@ -35,18 +41,25 @@ type PortfolioManager struct {
 	cfg             *config.Config
 	positionSizer   PositionSizer
 	accountProvider AccountProvider
+	riskStats       RiskStatsProvider
+	riskManager     *RiskManager
+	prompts         PromptStore
+	llmUsage        LLMUsageStore
+	strategyMu      sync.RWMutex
 	strategy        ActionStrategy
 }
 
-// NewPortfolioManager creates a new PortfolioManager
+// NewPortfolioManager creates a new PortfolioManager. A nil accountProvider, or
+// AGENT_SIGNAL_ONLY, produces signal-only recommendations without price levels or quantity.
 func NewPortfolioManager(repo PortfolioManagerRepository, cfg *config.Config, accountProvider AccountProvider) *PortfolioManager {
 	// Create position sizer from config
 	sizingConfig := PositionSizingConfig{
-		MaxPositionPercent:   cfg.PositionSizing.MaxPositionPercent,
-		RiskPercent:          cfg.PositionSizing.RiskPercent,
-		MinShares:            cfg.PositionSizing.MinShares,
-		MaxShares:            cfg.PositionSizing.MaxShares,
-		UseConfidenceScaling: cfg.PositionSizing.UseConfidenceScaling,
+		MaxPositionPercent:     cfg.PositionSizing.MaxPositionPercent,
+		RiskPercent:            cfg.PositionSizing.RiskPercent,
+		MinShares:              cfg.PositionSizing.MinShares,
+		MaxShares:              cfg.PositionSizing.MaxShares,
+		UseConfidenceScaling:   cfg.PositionSizing.UseConfidenceScaling,
+		ShortMarginRequirement: cfg.PositionSizing.ShortMarginRequirement,
 	}
 
 	strategy := createStrategyFromConfig(cfg)
@@ -84,6 +97,28 @@ func (m *PortfolioManager) RegisterAgent(agent Agent) {
 	m.agents = append(m.agents, agent)
 }
 
+// optionalAgentTypes add a score to recommendations only while an agent of the type is
+// registered, in the order the scores are listed
+var optionalAgentTypes = []models.AgentType{models.AgentTypeSocial, models.AgentTypeInsider, models.AgentTypeMacro}
+
+// optionalAgentLabels name the optional scores in the combined reasoning
+var optionalAgentLabels = map[models.AgentType]string{
+	models.AgentTypeSocial:  "Social",
+	models.AgentTypeInsider: "Insider",
+	models.AgentTypeMacro:   "Macro",
+}
+
+// registeredOptionalAgents returns the optional agent types with a registered agent
+func (m *PortfolioManager) registeredOptionalAgents() []models.AgentType {
+	var registered []models.AgentType
+	for _, agentType := range optionalAgentTypes {
+		if slices.ContainsFunc(m.agents, func(a Agent) bool { return a.Type() == agentType }) {
+			registered = append(registered, agentType)
+		}
+	}
+	return registered
+}
+
 // getAvailableAgents returns agents whose dependencies are healthy
 func (m *PortfolioManager) getAvailableAgents(ctx context.Context) []Agent {
 	available := make([]Agent, 0, len(m.agents))
@@ -91,7 +126,7 @@ func (m *PortfolioManager) getAvailableAgents(ctx context.Context) []Agent {
 		if agent.IsAvailable(ctx) {
 			available = append(available, agent)
 		} else {
-			observability.Warn("agent unavailable, skipping",
+			logger.Warn("agent unavailable, skipping",
 				"agent", agent.Name(),
 				"required_services", agent.GetMetadata().RequiredServices)
 		}
@@ -101,6 +136,7 @@ func (m *PortfolioManager) getAvailableAgents(ctx context.Context) []Agent {
 
 // agentResult holds the result of an agent analysis attempt
 type agentResult struct {
+	index    int // Position in the available agents, for stable ordering
 	agent    Agent
 	analysis *Analysis
 	err      error
@@ -108,10 +144,19 @@ type agentResult struct {
 
 // AnalyzeSymbol runs all agents and generates a recommendation
 func (m *PortfolioManager) AnalyzeSymbol(ctx context.Context, symbol string) (*models.Recommendation, error) {
+	ctx, span := observability.StartSpan(ctx, "PortfolioManager.AnalyzeSymbol", "symbol", symbol)
+	defer span.End()
+
 	metrics := observability.GetMetrics()
 	metrics.RecordAnalysisRequest(symbol)
 	analysisTimer := metrics.NewTimer()
 
+	if err := m.checkLLMBudget(ctx); err != nil {
+		metrics.RecordAnalysisError(symbol, "llm_budget_exceeded")
+		span.RecordError(err)
+		return nil, err
+	}
+
 	var unavailableAgents []models.MissingAgentInfo
 	availableAgents := make([]Agent, 0, len(m.agents))
 	for _, agent := range m.agents {
@@ -122,7 +167,7 @@ func (m *PortfolioManager) AnalyzeSymbol(ctx context.Context, symbol string) (*m
 				AgentType: agent.Type(),
 				Reason:    fmt.Sprintf("%s unavailable: dependencies not healthy (%v)", agent.Name(), agent.GetMetadata().RequiredServices),
 			})
-			observability.Warn("agent unavailable, skipping",
+			logger.Warn("agent unavailable, skipping",
 				"agent", agent.Name(),
 				"required_services", agent.GetMetadata().RequiredServices)
 		}
@@ -131,47 +176,168 @@ func (m *PortfolioManager) AnalyzeSymbol(ctx context.Context, symbol string) (*m
 	if len(availableAgents) == 0 {
 		analysisTimer.ObserveAnalysis(symbol, "error")
 		metrics.RecordAnalysisError(symbol, "no_agents_available")
-		return nil, fmt.Errorf("no agents available to analyze %s", symbol)
+		err := fmt.Errorf("no agents available to analyze %s", symbol)
+		span.RecordError(err)
+		return nil, err
 	}
 
-	var wg sync.WaitGroup
-	results := make([]agentResult, len(availableAgents))
+	// Agents still running when the latency budget runs out finish in the background,
+	// possibly after the caller's context is done
+	budget := m.latencyBudget()
+	agentCtx := ctx
+	if budget > 0 {
+		agentCtx = context.WithoutCancel(ctx)
+	}
 
+	resultCh := make(chan agentResult, len(availableAgents))
 	for i, agent := range availableAgents {
-		wg.Add(1)
 		go func(idx int, ag Agent) {
-			defer wg.Done()
+			resultCh <- m.runAgent(agentCtx, idx, ag, symbol)
+		}(i, agent)
+	}
 
-			agentCtx, cancel := context.WithTimeout(ctx, time.Duration(m.cfg.Agent.TimeoutSeconds)*time.Second)
-			defer cancel()
+	results := collectResults(resultCh, len(availableAgents), budget)
+	validAnalyses, failedAgents := splitResults(symbol, results)
 
-			run := models.NewAgentRun(ag.Type(), symbol)
-			m.repo.CreateAgentRun(agentCtx, run)
+	if len(validAnalyses) == 0 {
+		analysisTimer.ObserveAnalysis(symbol, "error")
+		metrics.RecordAnalysisError(symbol, "all_agents_failed")
+		err := fmt.Errorf("all agents failed to analyze %s", symbol)
+		span.RecordError(err)
+		return nil, err
+	}
 
-			agentTimer := metrics.NewTimer()
-			analysis, err := ag.Analyze(agentCtx, symbol)
-			agentTimer.ObserveAgent(string(ag.Type()))
+	pendingAgents := pendingAgentsInfo(availableAgents, results, budget)
+	allMissingAgents := slices.Concat(unavailableAgents, failedAgents, pendingAgents)
+	rec := m.synthesizeRecommendation(ctx, symbol, validAnalyses, allMissingAgents)
+	m.reviewRisk(ctx, rec)
+	if len(pendingAgents) > 0 {
+		rec.Partial = true
+		rec.Reasoning = fmt.Sprintf("Partial result: latency budget of %s exceeded, %d agent(s) still running. ", budget, len(pendingAgents)) + rec.Reasoning
+	}
 
-			results[idx] = agentResult{agent: ag, analysis: analysis, err: err}
+	if err := m.repo.CreateRecommendation(ctx, rec); err != nil {
+		analysisTimer.ObserveAnalysis(symbol, "error")
+		metrics.RecordAnalysisError(symbol, "db_save_failed")
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to save recommendation: %w", err)
+	}
 
-			if err != nil {
-				run.Fail(err)
-				metrics.RecordAgentError(string(ag.Type()), categorizeError(err))
-			} else {
-				run.Complete(map[string]interface{}{
-					"score":      analysis.Score,
-					"confidence": analysis.Confidence,
-					"reasoning":  analysis.Reasoning,
-				})
-				metrics.RecordAgentScore(string(ag.Type()), analysis.Score)
-			}
+	if rec.Partial {
+		analysisTimer.ObserveAnalysis(symbol, "partial")
+		go m.completePartial(agentCtx, rec, resultCh, results, len(pendingAgents), unavailableAgents)
+	} else {
+		analysisTimer.ObserveAnalysis(symbol, "success")
+	}
+	events.Publish(events.RecommendationCreated, rec)
+	span.SetAttributes("action", string(rec.Action), "confidence", rec.Confidence,
+		"agents", len(validAnalyses), "missing_agents", len(allMissingAgents), "partial", rec.Partial)
 
-			m.repo.UpdateAgentRun(agentCtx, run)
-		}(i, agent)
+	return rec, nil
+}
+
+// runAgent runs one agent with its timeout and retry settings, recording the attempt as an agent run
+func (m *PortfolioManager) runAgent(ctx context.Context, idx int, ag Agent, symbol string) agentResult {
+	ctx, span := observability.StartSpan(ctx, "agent."+string(ag.Type()), "agent", string(ag.Type()), "symbol", symbol)
+	defer span.End()
+
+	metrics := observability.GetMetrics()
+	settings := m.settingsFor(ag.Type())
+
+	ctx, promptVersion := m.withSystemPrompt(ctx, ag.Type())
+	run := models.NewAgentRun(ag.Type(), symbol)
+	run.InputData = settings.metadata()
+	if promptVersion > 0 {
+		run.InputData[models.AgentRunPromptVersionKey] = promptVersion
+	}
+	if jobID, ok := models.AnalysisJobFromContext(ctx); ok {
+		run.JobID = &jobID
+	}
+	m.repo.CreateAgentRun(ctx, run)
+	started := *run // The run is completed in place below
+	events.Publish(events.AgentRunStarted, &started)
+
+	promptCtx, prompt := withPromptCapture(ctx)
+	promptCtx, usage := services.WithLLMUsage(promptCtx)
+	agentTimer := metrics.NewTimer()
+	analysis, attempts, err := analyzeWithRetries(promptCtx, ag, symbol, settings)
+	agentTimer.ObserveAgent(string(ag.Type()))
+	span.SetAttributes("attempts", attempts)
+	if *prompt != "" {
+		// Copied, as the started event shares the map
+		run.InputData = maps.Clone(run.InputData)
+		run.InputData[models.AgentRunPromptKey] = *prompt
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		run.Fail(err)
+		run.OutputData = map[string]interface{}{"attempts": attempts}
+		metrics.RecordAgentError(string(ag.Type()), categorizeError(err))
+	} else {
+		degraded := applyDegradation(ag, analysis)
+		output := map[string]interface{}{
+			"score":      analysis.Score,
+			"confidence": analysis.Confidence,
+			"reasoning":  analysis.Reasoning,
+			"attempts":   attempts,
+			"degraded":   degraded,
+		}
+		if timeframes := timeframeScoresOf(analysis); timeframes != nil {
+			output["timeframe_scores"] = timeframes
+		}
+		run.Complete(output)
+		metrics.RecordAgentScore(string(ag.Type()), analysis.Score)
+		span.SetAttributes("score", analysis.Score, "confidence", analysis.Confidence, "degraded", degraded)
+	}
+
+	m.recordLLMUsage(ctx, ag.Type(), symbol, run, usage)
+	m.repo.UpdateAgentRun(ctx, run)
+	events.Publish(events.AgentRunCompleted, run)
+	return agentResult{index: idx, agent: ag, analysis: analysis, err: err}
+}
+
+// latencyBudget returns the overall time allowed for an analysis, or zero for no budget
+func (m *PortfolioManager) latencyBudget() time.Duration {
+	return time.Duration(m.cfg.Agent.LatencyBudgetSeconds) * time.Second
+}
+
+// collectResults gathers agent results until all n have arrived or, once budget has
+// elapsed, at least one agent has produced an analysis. A zero budget waits for every
+// agent. Results are returned in agent registration order.
+func collectResults(ch <-chan agentResult, n int, budget time.Duration) []agentResult {
+	results := make([]agentResult, 0, n)
+	var expired <-chan time.Time
+	if budget > 0 {
+		timer := time.NewTimer(budget)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	overBudget := false
+	for len(results) < n {
+		if overBudget && slices.ContainsFunc(results, func(r agentResult) bool { return r.analysis != nil }) {
+			break
+		}
+		select {
+		case result := <-ch:
+			results = append(results, result)
+		case <-expired:
+			overBudget = true
+			expired = nil
+		}
 	}
 
-	wg.Wait()
+	sortResults(results)
+	return results
+}
+
+func sortResults(results []agentResult) {
+	slices.SortFunc(results, func(a, b agentResult) int { return a.index - b.index })
+}
 
+// splitResults separates successful analyses from failed agents
+func splitResults(symbol string, results []agentResult) ([]*Analysis, []models.MissingAgentInfo) {
 	var validAnalyses []*Analysis
 	var failedAgents []models.MissingAgentInfo
 	for _, result := range results {
@@ -182,32 +348,76 @@ func (m *PortfolioManager) AnalyzeSymbol(ctx context.Context, symbol string) (*m
 				AgentType: result.agent.Type(),
 				Reason:    fmt.Sprintf("%s failed: %v", result.agent.Name(), result.err),
 			})
-			observability.Warn("agent analysis failed",
+			logger.Warn("agent analysis failed",
 				"agent", result.agent.Name(),
 				"symbol", symbol,
 				"error", result.err)
 		}
 	}
+	return validAnalyses, failedAgents
+}
 
-	if len(validAnalyses) == 0 {
-		analysisTimer.ObserveAnalysis(symbol, "error")
-		metrics.RecordAnalysisError(symbol, "all_agents_failed")
-		return nil, fmt.Errorf("all agents failed to analyze %s", symbol)
+// pendingAgentsInfo lists the agents that had not reported when the latency budget ran out
+func pendingAgentsInfo(agents []Agent, results []agentResult, budget time.Duration) []models.MissingAgentInfo {
+	var pending []models.MissingAgentInfo
+	for i, agent := range agents {
+		if slices.ContainsFunc(results, func(r agentResult) bool { return r.index == i }) {
+			continue
+		}
+		pending = append(pending, models.MissingAgentInfo{
+			AgentType: agent.Type(),
+			Reason:    fmt.Sprintf("%s still running after the %s latency budget", agent.Name(), budget),
+		})
 	}
+	return pending
+}
 
-	allMissingAgents := append(unavailableAgents, failedAgents...)
-	rec := m.synthesizeRecommendation(ctx, symbol, validAnalyses, allMissingAgents)
+// completePartial waits for the agents still running when a partial recommendation was
+// returned, signalling the context's agents-done callback once they have, then
+// re-synthesizes it from every result. Nothing is synthesized or stored unless the
+// recommendation is still a pending partial result, so decisions made on it stand.
+func (m *PortfolioManager) completePartial(ctx context.Context, partial *models.Recommendation, ch <-chan agentResult, results []agentResult, remaining int, unavailableAgents []models.MissingAgentInfo) {
+	all := slices.Clone(results)
+	for i := 0; i < remaining; i++ {
+		all = append(all, <-ch)
+	}
+	models.AgentsDoneFromContext(ctx)()
+	sortResults(all)
 
-	if err := m.repo.CreateRecommendation(ctx, rec); err != nil {
-		analysisTimer.ObserveAnalysis(symbol, "error")
-		metrics.RecordAnalysisError(symbol, "db_save_failed")
-		return nil, fmt.Errorf("failed to save recommendation: %w", err)
+	current, err := m.repo.GetRecommendation(ctx, partial.ID)
+	if err != nil {
+		logger.Warn("failed to load partial recommendation",
+			"symbol", partial.Symbol,
+			"recommendation_id", partial.ID,
+			"error", err)
+		return
+	}
+	if current == nil || current.Status != models.RecommendationStatusPending || !current.Partial {
+		logger.Info("partial recommendation no longer pending, leaving it as decided",
+			"symbol", partial.Symbol,
+			"recommendation_id", partial.ID)
+		return
 	}
 
-	analysisTimer.ObserveAnalysis(symbol, "success")
-	metrics.RecordRecommendation(string(rec.Action), calculateFinalScore(rec), rec.Confidence)
+	validAnalyses, failedAgents := splitResults(partial.Symbol, all)
+	rec := m.synthesizeRecommendation(ctx, partial.Symbol, validAnalyses, slices.Concat(unavailableAgents, failedAgents))
+	m.reviewRisk(ctx, rec)
+	rec.ID = partial.ID
+	rec.CreatedAt = partial.CreatedAt
 
-	return rec, nil
+	if err := m.repo.CompleteRecommendation(ctx, rec); err != nil {
+		logger.Warn("failed to complete partial recommendation",
+			"symbol", partial.Symbol,
+			"recommendation_id", partial.ID,
+			"error", err)
+		return
+	}
+
+	logger.Info("partial recommendation completed",
+		"symbol", rec.Symbol,
+		"recommendation_id", rec.ID,
+		"action", rec.Action,
+		"confidence", rec.Confidence)
 }
 
 // categorizeError categorizes an error for metrics labeling
@@ -261,49 +471,36 @@ func matchLower(a, b string) bool {
 	return true
 }
 
-// calculateFinalScore calculates the weighted final score from a recommendation
-func calculateFinalScore(rec *models.Recommendation) float64 {
-	// Use simple average since we don't have access to weights here
-	return (rec.FundamentalScore + rec.SentimentScore + rec.TechnicalScore) / 3
-}
-
 // synthesizeRecommendation combines agent analyses into a recommendation
 func (m *PortfolioManager) synthesizeRecommendation(ctx context.Context, symbol string, analyses []*Analysis, missingAgents []models.MissingAgentInfo) *models.Recommendation {
-	var fundamentalScore, sentimentScore, technicalScore float64
-	var totalWeight float64 = 0
-	var weightedScore float64 = 0
+	var fundamentalScore, sentimentScore, technicalScore, socialScore, insiderScore, macroScore float64
+	var timeframes *models.TimeframeScores
+	var fundamentalsDelta *models.FundamentalsDelta
+	var horizonWeighted bool
 	var reasonings []string
 
-	weights := map[models.AgentType]float64{
-		models.AgentTypeFundamental: m.cfg.Agent.WeightFundamental,
-		models.AgentTypeNews:        m.cfg.Agent.WeightNews,
-		models.AgentTypeTechnical:   m.cfg.Agent.WeightTechnical,
-	}
-
-	providedAnalysis := make(map[models.AgentType]bool)
-
 	for _, analysis := range analyses {
-		weight := weights[analysis.AgentType]
-		weightedScore += analysis.Score * weight * (analysis.Confidence / 100)
-		totalWeight += weight * (analysis.Confidence / 100)
-		providedAnalysis[analysis.AgentType] = true
-
 		switch analysis.AgentType {
 		case models.AgentTypeFundamental:
 			fundamentalScore = analysis.Score
+			fundamentalsDelta = fundamentalsDeltaOf(analysis)
 		case models.AgentTypeNews:
 			sentimentScore = analysis.Score
 		case models.AgentTypeTechnical:
-			technicalScore = analysis.Score
+			technicalScore, horizonWeighted = m.horizonScore(analysis)
+			timeframes = timeframeScoresOf(analysis)
+		case models.AgentTypeSocial:
+			socialScore = analysis.Score
+		case models.AgentTypeInsider:
+			insiderScore = analysis.Score
+		case models.AgentTypeMacro:
+			macroScore = analysis.Score
 		}
 
 		reasonings = append(reasonings, fmt.Sprintf("[%s] %s", analysis.AgentType, analysis.Reasoning))
 	}
 
-	var finalScore float64
-	if totalWeight > 0 {
-		finalScore = weightedScore / totalWeight
-	}
+	finalScore, weightPolicy, abstained := m.combineScores(analyses, missingAgents)
 
 	avgConfidence := 0.0
 	for _, analysis := range analyses {
@@ -311,7 +508,8 @@ func (m *PortfolioManager) synthesizeRecommendation(ctx context.Context, symbol
 	}
 	avgConfidence /= float64(len(analyses))
 
-	totalExpectedAgents := 3
+	optionalAgents := m.registeredOptionalAgents()
+	totalExpectedAgents := 3 + len(optionalAgents)
 	dataCompleteness := float64(len(analyses)) / float64(totalExpectedAgents) * 100
 
 	if len(missingAgents) > 0 {
@@ -322,7 +520,12 @@ func (m *PortfolioManager) synthesizeRecommendation(ctx context.Context, symbol
 		avgConfidence = avgConfidence * (1 - confidencePenalty/100)
 	}
 
-	action := m.strategy.DetermineAction(finalScore, avgConfidence)
+	symbolClass := classifySymbol(symbol, analyses)
+	strategy, classOverride := m.strategyFor(symbolClass)
+	action := strategy.DetermineAction(finalScore, avgConfidence)
+	if abstained {
+		action = models.RecommendationActionHold
+	}
 
 	var combinedReasoning string
 	if len(missingAgents) > 0 {
@@ -342,14 +545,39 @@ func (m *PortfolioManager) synthesizeRecommendation(ctx context.Context, symbol
 	}
 
 	combinedReasoning += fmt.Sprintf(
-		"Scores - Fundamental: %.0f, Sentiment: %.0f, Technical: %.0f. Overall score: %.1f. ",
-		fundamentalScore, sentimentScore, technicalScore, finalScore,
+		"Scores - Fundamental: %.0f, Sentiment: %.0f, Technical: %.0f",
+		fundamentalScore, sentimentScore, technicalScore,
 	)
+	optionalScores := map[models.AgentType]float64{
+		models.AgentTypeSocial:  socialScore,
+		models.AgentTypeInsider: insiderScore,
+		models.AgentTypeMacro:   macroScore,
+	}
+	for _, agentType := range optionalAgents {
+		combinedReasoning += fmt.Sprintf(", %s: %.0f", optionalAgentLabels[agentType], optionalScores[agentType])
+	}
+	combinedReasoning += fmt.Sprintf(". Overall score: %.1f. ", finalScore)
 
 	if len(missingAgents) > 0 {
 		combinedReasoning += "Note: Confidence reduced due to incomplete data. "
 	}
 
+	if horizonWeighted {
+		combinedReasoning += fmt.Sprintf("Technical score weighted for a %s horizon. ", m.horizon())
+	}
+
+	if classOverride {
+		combinedReasoning += fmt.Sprintf("Applied %s thresholds. ", symbolClass)
+	}
+
+	if fundamentalsDelta.HasChanges() {
+		combinedReasoning += fmt.Sprintf("Fundamentals changed since %s: %s. ", fundamentalsDelta.Since.Format("2006-01-02"), fundamentalsDelta.Summary())
+	}
+
+	if abstained {
+		combinedReasoning += "Abstained: fundamental analysis unavailable (weight policy: abstain). "
+	}
+
 	for _, r := range reasonings {
 		combinedReasoning += r + " "
 	}
@@ -363,17 +591,51 @@ func (m *PortfolioManager) synthesizeRecommendation(ctx context.Context, symbol
 		FundamentalScore: fundamentalScore,
 		SentimentScore:   sentimentScore,
 		TechnicalScore:   technicalScore,
+		SocialScore:      socialScore,
+		InsiderScore:     insiderScore,
+		MacroScore:       macroScore,
+		TimeframeScores:  timeframes,
+		AgentWeights:     m.weightsSnapshot(),
 		DataCompleteness: dataCompleteness,
 		MissingAgents:    missingAgents,
+		WeightPolicy:     weightPolicy,
+		TriggerReason:    models.TriggerReasonFromContext(ctx),
+		PreviousID:       models.PreviousRecommendationFromContext(ctx),
 		Status:           models.RecommendationStatusPending,
 		CreatedAt:        time.Now(),
 	}
 
-	rec.Quantity = m.calculatePositionSize(ctx, symbol, action, avgConfidence)
+	if m.SignalOnly() {
+		if rec.Action != models.RecommendationActionHold {
+			rec.Reasoning += "Signal only: no quote lookup or position sizing. "
+		}
+		return rec
+	}
+
+	if err := m.resolveShortAction(ctx, rec); err != nil {
+		// Without the position a buy could add to a short or a sell open one unintended
+		logger.Warn("position unavailable, downgrading to hold", "symbol", symbol, "error", err)
+		rec.Action = models.RecommendationActionHold
+		rec.Reasoning += "Downgraded to hold: the current position could not be checked. "
+	}
+	entryPrice := m.currentPrice(ctx, symbol)
+	m.applyPriceLevels(rec, entryPrice, analyses)
+	m.enforceMinRiskReward(rec)
+
+	rec.Quantity = m.calculatePositionSize(ctx, symbol, rec.Action, avgConfidence, entryPrice)
+	m.scaleForVolatility(ctx, rec)
+	m.noteLiquidity(ctx, rec)
 
 	return rec
 }
 
+// SignalOnly reports whether recommendations skip account and quote lookups and position
+// sizing, carrying only the action and scores. This is the case when signal-only mode is
+// configured or no broker is connected.
+func (m *PortfolioManager) SignalOnly() bool {
+	return m.cfg.Agent.SignalOnly || m.accountProvider == nil
+}
+
 // formatMissingAgents formats a list of missing agent types for display
 func formatMissingAgents(types []string) string {
 	if len(types) == 0 {
@@ -388,35 +650,40 @@ func formatMissingAgents(types []string) string {
 	return types[0] + ", " + types[1] + ", and " + types[2]
 }
 
-func (m *PortfolioManager) calculatePositionSize(ctx context.Context, symbol string, action models.RecommendationAction, confidence float64) decimal.Decimal {
-	account, err := m.accountProvider.GetAccount(ctx)
-	if err != nil {
-		observability.Warn("failed to get account for position sizing, using minimum",
-			"symbol", symbol,
-			"error", err)
-		return decimal.NewFromInt(m.cfg.PositionSizing.MinShares)
-	}
-
+// currentPrice returns the latest trade price for a symbol, falling back to the
+// bid/ask midpoint. Returns zero if no quote is available.
+func (m *PortfolioManager) currentPrice(ctx context.Context, symbol string) decimal.Decimal {
 	quote, err := m.accountProvider.GetQuote(ctx, symbol)
 	if err != nil {
-		observability.Warn("failed to get quote for position sizing, using minimum",
+		logger.Warn("failed to get quote for symbol",
 			"symbol", symbol,
 			"error", err)
-		return decimal.NewFromInt(m.cfg.PositionSizing.MinShares)
+		return decimal.Zero
 	}
 
-	currentPrice := quote.Last
-	if currentPrice.IsZero() {
+	price := quote.Last
+	if price.IsZero() {
 		// Fall back to bid/ask midpoint
 		if !quote.Bid.IsZero() && !quote.Ask.IsZero() {
-			currentPrice = quote.Bid.Add(quote.Ask).Div(decimal.NewFromInt(2))
+			price = quote.Bid.Add(quote.Ask).Div(decimal.NewFromInt(2))
 		}
 	}
+	return price
+}
+
+func (m *PortfolioManager) calculatePositionSize(ctx context.Context, symbol string, action models.RecommendationAction, confidence float64, currentPrice decimal.Decimal) decimal.Decimal {
+	account, err := m.accountProvider.GetAccount(ctx)
+	if err != nil {
+		logger.Warn("failed to get account for position sizing, using minimum",
+			"symbol", symbol,
+			"error", err)
+		return decimal.NewFromInt(m.cfg.PositionSizing.MinShares)
+	}
 
 	existingPosition, _ := m.accountProvider.GetPosition(ctx, symbol)
 	quantity, err := m.positionSizer.CalculateQuantity(ctx, account, currentPrice, action, confidence, existingPosition)
 	if err != nil {
-		observability.Warn("position sizer error, using minimum",
+		logger.Warn("position sizer error, using minimum",
 			"symbol", symbol,
 			"error", err)
 		return decimal.NewFromInt(m.cfg.PositionSizing.MinShares)
@@ -442,10 +709,15 @@ func (m *PortfolioManager) GetAgents() []Agent {
 
 // GetStrategy returns the current action strategy
 func (m *PortfolioManager) GetStrategy() ActionStrategy {
+	m.strategyMu.RLock()
+	defer m.strategyMu.RUnlock()
 	return m.strategy
 }
 
-// SetStrategy sets a new action strategy
+// SetStrategy sets a new action strategy, taking effect for analyses that have not yet
+// been synthesized
 func (m *PortfolioManager) SetStrategy(strategy ActionStrategy) {
+	m.strategyMu.Lock()
+	defer m.strategyMu.Unlock()
 	m.strategy = strategy
 }
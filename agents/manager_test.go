@@ -2,11 +2,15 @@ package agents
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"trade-machine/config"
+	"trade-machine/events"
 	"trade-machine/models"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
@@ -141,6 +145,9 @@ func TestPortfolioManager_SynthesizeRecommendation(t *testing.T) {
 	if rec.TechnicalScore != 40.0 {
 		t.Errorf("TechnicalScore = %v, want 40.0", rec.TechnicalScore)
 	}
+	if w := rec.AgentWeights; w == nil || w.Fundamental != 0.4 || w.News != 0.3 || w.Technical != 0.3 {
+		t.Errorf("AgentWeights = %+v, want the configured weights recorded", w)
+	}
 
 	// Reasoning should mention all agents
 	if rec.Reasoning == "" {
@@ -158,6 +165,43 @@ func TestPortfolioManager_SynthesizeRecommendation(t *testing.T) {
 	}
 }
 
+func TestPortfolioManager_SynthesizeRecommendation_SignalOnly(t *testing.T) {
+	analyses := []*Analysis{
+		{Symbol: "AAPL", AgentType: models.AgentTypeFundamental, Score: 60.0, Confidence: 80.0, Reasoning: "Strong fundamentals"},
+		{Symbol: "AAPL", AgentType: models.AgentTypeNews, Score: 50.0, Confidence: 70.0, Reasoning: "Positive sentiment"},
+		{Symbol: "AAPL", AgentType: models.AgentTypeTechnical, Score: 40.0, Confidence: 75.0, Reasoning: "Bullish signals"},
+	}
+
+	t.Run("no broker connected", func(t *testing.T) {
+		manager := NewPortfolioManager(nil, testConfig(), nil)
+		if !manager.SignalOnly() {
+			t.Fatal("expected signal-only mode without an account provider")
+		}
+
+		rec := manager.synthesizeRecommendation(context.Background(), "AAPL", analyses, nil)
+		if rec.Action != models.RecommendationActionBuy {
+			t.Errorf("Action = %v, want Buy", rec.Action)
+		}
+		if !rec.Quantity.IsZero() || !rec.EntryPrice.IsZero() {
+			t.Errorf("expected no quantity or entry price, got %s at %s", rec.Quantity, rec.EntryPrice)
+		}
+		if rec.Executable() {
+			t.Error("signal-only recommendations should not be executable")
+		}
+	})
+
+	t.Run("configured with a broker", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.Agent.SignalOnly = true
+		manager := NewPortfolioManager(nil, cfg, newMockAccountProvider())
+
+		rec := manager.synthesizeRecommendation(context.Background(), "AAPL", analyses, nil)
+		if !rec.Quantity.IsZero() {
+			t.Errorf("Quantity = %s, want 0 in signal-only mode", rec.Quantity)
+		}
+	})
+}
+
 func TestPortfolioManager_SynthesizeRecommendation_Hold(t *testing.T) {
 	manager := NewPortfolioManager(nil, testConfig(), newMockAccountProvider())
 
@@ -466,6 +510,128 @@ func TestPortfolioManager_GetAgents(t *testing.T) {
 	}
 }
 
+// completingRepo records recommendations completed after a partial result. The stored
+// recommendation is a pending partial result unless status says otherwise.
+type completingRepo struct {
+	completed chan *models.Recommendation
+	status    models.RecommendationStatus
+}
+
+func (r *completingRepo) CreateAgentRun(ctx context.Context, run *models.AgentRun) error { return nil }
+func (r *completingRepo) UpdateAgentRun(ctx context.Context, run *models.AgentRun) error { return nil }
+func (r *completingRepo) CreateRecommendation(ctx context.Context, rec *models.Recommendation) error {
+	return nil
+}
+func (r *completingRepo) GetRecommendation(ctx context.Context, id uuid.UUID) (*models.Recommendation, error) {
+	status := r.status
+	if status == "" {
+		status = models.RecommendationStatusPending
+	}
+	return &models.Recommendation{ID: id, Status: status, Partial: true}, nil
+}
+func (r *completingRepo) CompleteRecommendation(ctx context.Context, rec *models.Recommendation) error {
+	r.completed <- rec
+	return nil
+}
+
+func TestCollectResults_LatencyBudget(t *testing.T) {
+	fundamental := &testMockAgent{name: "Fundamental", agentType: models.AgentTypeFundamental}
+	news := &testMockAgent{name: "News", agentType: models.AgentTypeNews}
+	agents := []Agent{fundamental, news}
+
+	ch := make(chan agentResult, 2)
+	ch <- agentResult{index: 1, agent: news, analysis: &Analysis{AgentType: models.AgentTypeNews, Score: 40, Confidence: 80}}
+
+	results := collectResults(ch, 2, 10*time.Millisecond)
+	if len(results) != 1 || results[0].agent != news {
+		t.Fatalf("collectResults() = %+v, want only the finished news result", results)
+	}
+
+	pending := pendingAgentsInfo(agents, results, 10*time.Millisecond)
+	if len(pending) != 1 || pending[0].AgentType != models.AgentTypeFundamental {
+		t.Fatalf("pendingAgentsInfo() = %+v, want the fundamental agent", pending)
+	}
+
+	// Without a budget every agent is waited for, and results keep registration order
+	ch <- agentResult{index: 1, agent: news, analysis: &Analysis{AgentType: models.AgentTypeNews}}
+	ch <- agentResult{index: 0, agent: fundamental, analysis: &Analysis{AgentType: models.AgentTypeFundamental}}
+	results = collectResults(ch, 2, 0)
+	if len(results) != 2 || results[0].agent != fundamental {
+		t.Errorf("collectResults() without a budget = %+v, want both results in order", results)
+	}
+}
+
+func TestPortfolioManager_CompletePartial(t *testing.T) {
+	repo := &completingRepo{completed: make(chan *models.Recommendation, 1)}
+	manager := NewPortfolioManager(repo, testConfig(), newMockAccountProvider())
+	fundamental := &testMockAgent{name: "Fundamental", agentType: models.AgentTypeFundamental}
+	news := &testMockAgent{name: "News", agentType: models.AgentTypeNews}
+	ctx := context.Background()
+
+	finished := []agentResult{{index: 1, agent: news, analysis: &Analysis{AgentType: models.AgentTypeNews, Score: 60, Confidence: 80}}}
+	pending := pendingAgentsInfo([]Agent{fundamental, news}, finished, time.Second)
+	partial := manager.synthesizeRecommendation(ctx, "AAPL", []*Analysis{finished[0].analysis}, pending)
+	partial.Partial = true
+
+	ch := make(chan agentResult, 1)
+	ch <- agentResult{index: 0, agent: fundamental, analysis: &Analysis{AgentType: models.AgentTypeFundamental, Score: 60, Confidence: 80}}
+	agentsDone := make(chan struct{})
+	go manager.completePartial(models.WithAgentsDone(ctx, func() { close(agentsDone) }), partial, ch, finished, 1, nil)
+
+	select {
+	case rec := <-repo.completed:
+		if rec.ID != partial.ID {
+			t.Errorf("completed ID = %s, want %s", rec.ID, partial.ID)
+		}
+		if len(rec.MissingAgents) != 0 || rec.Confidence <= partial.Confidence {
+			t.Errorf("completed recommendation missing %v with confidence %.1f, want no missing agents and more than %.1f",
+				rec.MissingAgents, rec.Confidence, partial.Confidence)
+		}
+		if !strings.HasPrefix(rec.Reasoning, "Based on analysis from 2 agents") {
+			t.Errorf("Reasoning = %q", rec.Reasoning)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("partial recommendation was not completed")
+	}
+	select {
+	case <-agentsDone:
+	default:
+		t.Error("agents-done callback not called once the remaining agents returned")
+	}
+
+	// A partial recommendation already decided on is left alone
+	repo.status = models.RecommendationStatusApproved
+	ch <- agentResult{index: 0, agent: fundamental, analysis: &Analysis{AgentType: models.AgentTypeFundamental, Score: 60, Confidence: 80}}
+	manager.completePartial(ctx, partial, ch, finished, 1, nil)
+	select {
+	case rec := <-repo.completed:
+		t.Errorf("completed %+v after approval, want it left as decided", rec)
+	default:
+	}
+}
+
+func TestPortfolioManager_RunAgent_PublishesEvents(t *testing.T) {
+	bus := events.NewBus()
+	var got []events.Event
+	bus.Subscribe("agent_runs", func(e events.Event) { got = append(got, e) }, events.AgentRunStarted, events.AgentRunCompleted)
+	events.SetDefault(bus)
+	defer events.SetDefault(nil)
+
+	manager := NewPortfolioManager(&completingRepo{}, testConfig(), newMockAccountProvider())
+	manager.runAgent(context.Background(), 0, &testMockAgent{name: "Technical", agentType: models.AgentTypeTechnical}, "AAPL")
+	bus.Close()
+
+	if len(got) != 2 || got[0].Type != events.AgentRunStarted || got[1].Type != events.AgentRunCompleted {
+		t.Fatalf("events = %+v, want started then completed", got)
+	}
+	if started := got[0].AgentRun(); started.Status != models.AgentRunStatusRunning || started.Symbol != "AAPL" {
+		t.Errorf("started run = %+v, want the running run", started)
+	}
+	if completed := got[1].AgentRun(); completed.Status != models.AgentRunStatusCompleted || completed.ID != got[0].AgentRun().ID {
+		t.Errorf("completed run = %+v, want the same run completed", completed)
+	}
+}
+
 // Mock agent for testing
 type testMockAgent struct {
 	name        string
@@ -34,6 +34,13 @@ func (m *mockLLMService) Chat(ctx context.Context, systemPrompt string, messages
 	return m.response, nil
 }
 
+func (m *mockLLMService) ChatStream(ctx context.Context, systemPrompt string, messages []services.ChatMessage, onDelta func(delta string) error) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	return m.response, onDelta(m.response)
+}
+
 type mockAlphaVantageService struct {
 	fundamentals *models.Fundamentals
 	news         []models.NewsArticle
@@ -62,9 +69,46 @@ func (m *mockAlphaVantageService) GetQuote(ctx context.Context, symbol string) (
 	return m.quote, nil
 }
 
+type mockSocialService struct {
+	posts []models.SocialPost
+	err   error
+}
+
+func (m *mockSocialService) GetPosts(ctx context.Context, symbol string, limit int) ([]models.SocialPost, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.posts, nil
+}
+
+type mockInsiderTrades struct {
+	trades []models.InsiderTrade
+	err    error
+}
+
+func (m *mockInsiderTrades) GetInsiderTrades(ctx context.Context, symbol string, limit int) ([]models.InsiderTrade, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.trades, nil
+}
+
+type mockFREDService struct {
+	snapshot *models.MacroSnapshot
+	err      error
+}
+
+func (m *mockFREDService) GetMacroSnapshot(ctx context.Context) (*models.MacroSnapshot, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.snapshot, nil
+}
+
 type mockNewsAPIService struct {
 	articles []models.NewsArticle
 	err      error
+	from     time.Time
 }
 
 func (m *mockNewsAPIService) GetNews(ctx context.Context, query string, limit int) ([]models.NewsArticle, error) {
@@ -74,6 +118,11 @@ func (m *mockNewsAPIService) GetNews(ctx context.Context, query string, limit in
 	return m.articles, nil
 }
 
+func (m *mockNewsAPIService) GetNewsSince(ctx context.Context, query string, limit int, from time.Time) ([]models.NewsArticle, error) {
+	m.from = from
+	return m.GetNews(ctx, query, limit)
+}
+
 func (m *mockNewsAPIService) GetHeadlines(ctx context.Context, query string, limit int) ([]models.NewsArticle, error) {
 	if m.err != nil {
 		return nil, m.err
@@ -100,6 +149,10 @@ func (m *mockAlpacaService) GetDailyBars(ctx context.Context, symbol string, day
 	return m.bars, nil
 }
 
+func (m *mockAlpacaService) GetAverageDailyVolume(ctx context.Context, symbol string, days int) (int64, error) {
+	return 0, m.err
+}
+
 func (m *mockAlpacaService) GetQuote(ctx context.Context, symbol string) (*models.Quote, error) {
 	return nil, nil
 }
@@ -119,10 +172,18 @@ func (m *mockAlpacaService) GetAccount(ctx context.Context) (*models.Account, er
 	}, nil
 }
 
-func (m *mockAlpacaService) PlaceOrder(ctx context.Context, symbol string, qty decimal.Decimal, side models.TradeSide, orderType string) (string, error) {
+func (m *mockAlpacaService) PlaceOrder(ctx context.Context, req models.OrderRequest) (string, error) {
 	return "", nil
 }
 
+func (m *mockAlpacaService) GetOrder(ctx context.Context, orderID string) (*models.BrokerOrder, error) {
+	return nil, nil
+}
+
+func (m *mockAlpacaService) GetShortAvailability(ctx context.Context, symbol string) (*models.ShortAvailability, error) {
+	return &models.ShortAvailability{Symbol: symbol, Shortable: true, EasyToBorrow: true}, nil
+}
+
 func (m *mockAlpacaService) GetPositions(ctx context.Context) ([]models.Position, error) {
 	return nil, nil
 }
@@ -2,24 +2,27 @@ package agents
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
+	"trade-machine/config"
 	"trade-machine/models"
+	"trade-machine/services"
 )
 
 const newsSystemPrompt = `You are a financial analyst specializing in news sentiment analysis.
 Your job is to analyze recent news articles about a stock and determine market sentiment.
 
-You will be given a list of recent news headlines and descriptions.
+You will be given a list of recent news headlines and descriptions. Each article carries a
+recency weight between 0 and 1; fresher articles have higher weights and should count more.
 
 Based on this news, provide your analysis in the following JSON format:
 {
   "score": <number from -100 to 100, negative=bearish/negative sentiment, positive=bullish/positive sentiment>,
   "confidence": <number from 0 to 100>,
   "reasoning": "<brief explanation of the overall sentiment>",
+  "article_scores": [<sentiment from -100 to 100 for each article, in the order given>],
   "key_themes": ["<theme1>", "<theme2>", "<theme3>"],
   "notable_articles": ["<headline1>", "<headline2>"]
 }
@@ -33,44 +36,58 @@ Be objective and focus on how the news might impact stock price.`
 
 // NewsAnalystResponse is the expected response from Claude
 type NewsAnalystResponse struct {
-	Score           float64  `json:"score"`
-	Confidence      float64  `json:"confidence"`
-	Reasoning       string   `json:"reasoning"`
-	KeyThemes       []string `json:"key_themes"`
-	NotableArticles []string `json:"notable_articles"`
+	Score           float64   `json:"score"`
+	Confidence      float64   `json:"confidence"`
+	Reasoning       string    `json:"reasoning"`
+	ArticleScores   []float64 `json:"article_scores"`
+	KeyThemes       []string  `json:"key_themes"`
+	NotableArticles []string  `json:"notable_articles"`
 }
 
 // NewsAnalyst analyzes news sentiment
 type NewsAnalyst struct {
-	llm LLMService
-	newsAPI     NewsAPIServiceInterface
-	healthCache *HealthCache
+	llm          LLMService
+	newsAPI      NewsAPIServiceInterface
+	lookbackDays int
+	halfLife     time.Duration
+	healthCache  *HealthCache
 }
 
 // NewNewsAnalyst creates a new NewsAnalyst
-func NewNewsAnalyst(llm LLMService, newsAPI NewsAPIServiceInterface) *NewsAnalyst {
+func NewNewsAnalyst(llm LLMService, newsAPI NewsAPIServiceInterface, cfg *config.Config) *NewsAnalyst {
 	return &NewsAnalyst{
-		llm:     llm,
-		newsAPI:     newsAPI,
-		healthCache: NewHealthCache(DefaultHealthCacheTTL),
+		llm:          llm,
+		newsAPI:      newsAPI,
+		lookbackDays: cfg.Agent.NewsLookbackDays,
+		halfLife:     time.Duration(cfg.Agent.NewsHalfLifeHours) * time.Hour,
+		healthCache:  NewHealthCache(DefaultHealthCacheTTL),
 	}
 }
 
 // NewNewsAnalystWithCacheTTL creates a new NewsAnalyst with a custom health cache TTL
-func NewNewsAnalystWithCacheTTL(llm LLMService, newsAPI NewsAPIServiceInterface, cacheTTL time.Duration) *NewsAnalyst {
+func NewNewsAnalystWithCacheTTL(llm LLMService, newsAPI NewsAPIServiceInterface, cfg *config.Config, cacheTTL time.Duration) *NewsAnalyst {
 	return &NewsAnalyst{
-		llm:     llm,
-		newsAPI:     newsAPI,
-		healthCache: NewHealthCache(cacheTTL),
+		llm:          llm,
+		newsAPI:      newsAPI,
+		lookbackDays: cfg.Agent.NewsLookbackDays,
+		halfLife:     time.Duration(cfg.Agent.NewsHalfLifeHours) * time.Hour,
+		healthCache:  NewHealthCache(cacheTTL),
 	}
 }
 
 // Analyze performs news sentiment analysis on a stock
 func (a *NewsAnalyst) Analyze(ctx context.Context, symbol string) (*Analysis, error) {
-	articles, err := a.newsAPI.GetNews(ctx, symbol, 15)
+	now := time.Now()
+	var from time.Time
+	if a.lookbackDays > 0 {
+		from = now.AddDate(0, 0, -a.lookbackDays)
+	}
+
+	articles, err := a.newsAPI.GetNewsSince(ctx, symbol, 15, from)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch news: %w", err)
 	}
+	articles = recentArticles(articles, from)
 
 	if len(articles) == 0 {
 		return &Analysis{
@@ -79,7 +96,7 @@ func (a *NewsAnalyst) Analyze(ctx context.Context, symbol string) (*Analysis, er
 			Score:      0,
 			Confidence: 20,
 			Reasoning:  "No recent news found for this symbol",
-			Data:       map[string]interface{}{"articles_count": 0},
+			Data:       map[string]interface{}{"articles_count": 0, "lookback_days": a.lookbackDays},
 			Timestamp:  time.Now(),
 		}, nil
 	}
@@ -87,24 +104,26 @@ func (a *NewsAnalyst) Analyze(ctx context.Context, symbol string) (*Analysis, er
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("Analyze the following recent news about %s:\n\n", symbol))
 
+	weights := make([]float64, len(articles))
 	for i, article := range articles {
+		weights[i] = recencyWeight(article.PublishedAt, now, a.halfLife)
 		sb.WriteString(fmt.Sprintf("%d. **%s**\n", i+1, article.Title))
 		if article.Description != "" {
 			sb.WriteString(fmt.Sprintf("   %s\n", article.Description))
 		}
-		sb.WriteString(fmt.Sprintf("   Source: %s | Published: %s\n\n",
-			article.Source, article.PublishedAt.Format("Jan 2, 2006")))
+		sb.WriteString(fmt.Sprintf("   Source: %s | Published: %s | Weight: %.2f\n\n",
+			article.Source, article.PublishedAt.Format("Jan 2, 2006 15:04"), weights[i]))
 	}
 
 	sb.WriteString("Provide your sentiment analysis.")
 
-	response, err := a.llm.InvokeWithPrompt(ctx, newsSystemPrompt, sb.String())
+	response, err := invokeLLM(ctx, a.llm, newsSystemPrompt, sb.String())
 	if err != nil {
 		return nil, fmt.Errorf("failed to invoke bedrock: %w", err)
 	}
 
 	var result NewsAnalystResponse
-	if err := json.Unmarshal([]byte(response), &result); err != nil {
+	if err := services.ParseStructuredOutput(response, &result); err != nil {
 		return &Analysis{
 			Symbol:     symbol,
 			AgentType:  models.AgentTypeNews,
@@ -119,21 +138,35 @@ func (a *NewsAnalyst) Analyze(ctx context.Context, symbol string) (*Analysis, er
 		}, nil
 	}
 
+	// Prefer our own recency-weighted average of the per-article sentiment so stale
+	// coverage cannot drown out a fresh catalyst
+	score, weighted := weightedSentiment(result.ArticleScores, weights)
+	if !weighted {
+		score = result.Score
+	}
+
 	return &Analysis{
 		Symbol:     symbol,
 		AgentType:  models.AgentTypeNews,
-		Score:      NormalizeScore(result.Score),
+		Score:      NormalizeScore(score),
 		Confidence: NormalizeConfidence(result.Confidence),
 		Reasoning:  result.Reasoning,
 		Data: map[string]interface{}{
 			"key_themes":       result.KeyThemes,
 			"notable_articles": result.NotableArticles,
 			"articles_count":   len(articles),
+			"lookback_days":    a.lookbackDays,
+			"recency_weighted": weighted,
 		},
 		Timestamp: time.Now(),
 	}, nil
 }
 
+// ReplayPrompt sends a past analysis' user prompt with the current system prompt
+func (a *NewsAnalyst) ReplayPrompt(ctx context.Context, symbol, userPrompt string) (*Analysis, error) {
+	return replayPrompt(ctx, a.llm, a.Type(), symbol, newsSystemPrompt, userPrompt)
+}
+
 // Name returns the agent name
 func (a *NewsAnalyst) Name() string {
 	return "News Sentiment Analyst"
@@ -147,6 +180,9 @@ func (a *NewsAnalyst) Type() models.AgentType {
 // IsAvailable checks if the agent's dependencies are healthy.
 // Results are cached to reduce API calls during frequent availability checks.
 func (a *NewsAnalyst) IsAvailable(ctx context.Context) bool {
+	if available, decided := breakerAvailability(a.Degradation()); decided {
+		return available
+	}
 	if available, valid := a.healthCache.Get(); valid {
 		return available
 	}
@@ -157,6 +193,11 @@ func (a *NewsAnalyst) IsAvailable(ctx context.Context) bool {
 	return available
 }
 
+// Degradation returns the worst degradation level of the agent's data and LLM providers
+func (a *NewsAnalyst) Degradation() services.DegradationLevel {
+	return max(providerLevel(services.BreakerNewsAPI), llmLevel(a.llm, models.AgentTypeNews))
+}
+
 // InvalidateHealthCache clears the health cache, forcing the next check to make a live call.
 func (a *NewsAnalyst) InvalidateHealthCache() {
 	a.healthCache.Invalidate()
@@ -0,0 +1,52 @@
+package agents
+
+import (
+	"math"
+	"time"
+
+	"trade-machine/models"
+)
+
+// recencyWeight returns the sentiment weight for an article: 1 for an article published
+// now, halving every halfLife. Unknown or future timestamps and a non-positive halfLife
+// weigh 1.
+func recencyWeight(publishedAt, now time.Time, halfLife time.Duration) float64 {
+	age := now.Sub(publishedAt)
+	if publishedAt.IsZero() || age <= 0 || halfLife <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(halfLife))
+}
+
+// recentArticles drops articles published before cutoff, in case the provider ignores
+// the requested window. Articles without a publish date are kept, and a zero cutoff
+// keeps every article.
+func recentArticles(articles []models.NewsArticle, cutoff time.Time) []models.NewsArticle {
+	if cutoff.IsZero() {
+		return articles
+	}
+	recent := make([]models.NewsArticle, 0, len(articles))
+	for _, article := range articles {
+		if article.PublishedAt.IsZero() || !article.PublishedAt.Before(cutoff) {
+			recent = append(recent, article)
+		}
+	}
+	return recent
+}
+
+// weightedSentiment averages per-article sentiment scores by their recency weights.
+// It reports false when the scores don't line up one-to-one with the weights.
+func weightedSentiment(scores, weights []float64) (float64, bool) {
+	if len(scores) == 0 || len(scores) != len(weights) {
+		return 0, false
+	}
+	var sum, total float64
+	for i, score := range scores {
+		sum += score * weights[i]
+		total += weights[i]
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return sum / total, true
+}
@@ -0,0 +1,63 @@
+package agents
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+)
+
+func TestRecencyWeight(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		publishedAt time.Time
+		halfLife    time.Duration
+		want        float64
+	}{
+		{"just published", now, 24 * time.Hour, 1},
+		{"one half-life old", now.Add(-24 * time.Hour), 24 * time.Hour, 0.5},
+		{"two half-lives old", now.Add(-48 * time.Hour), 24 * time.Hour, 0.25},
+		{"future timestamp", now.Add(time.Hour), 24 * time.Hour, 1},
+		{"unknown date", time.Time{}, 24 * time.Hour, 1},
+		{"no decay", now.Add(-48 * time.Hour), 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recencyWeight(tt.publishedAt, now, tt.halfLife); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("recencyWeight() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecentArticles(t *testing.T) {
+	cutoff := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	articles := []models.NewsArticle{
+		{Title: "fresh", PublishedAt: cutoff.Add(time.Hour)},
+		{Title: "stale", PublishedAt: cutoff.Add(-time.Hour)},
+		{Title: "undated"},
+	}
+
+	got := recentArticles(articles, cutoff)
+	if len(got) != 2 || got[0].Title != "fresh" || got[1].Title != "undated" {
+		t.Errorf("recentArticles() = %v, want fresh and undated", got)
+	}
+	if len(recentArticles(articles, time.Time{})) != 3 {
+		t.Error("expected zero cutoff to keep every article")
+	}
+}
+
+func TestWeightedSentiment(t *testing.T) {
+	if got, ok := weightedSentiment([]float64{60, -20}, []float64{1, 0.25}); !ok || math.Abs(got-44) > 1e-9 {
+		t.Errorf("weightedSentiment() = %v, %v, want 44, true", got, ok)
+	}
+	if _, ok := weightedSentiment([]float64{60}, []float64{1, 0.5}); ok {
+		t.Error("expected mismatched lengths to be rejected")
+	}
+	if _, ok := weightedSentiment(nil, nil); ok {
+		t.Error("expected empty scores to be rejected")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"trade-machine/config"
 	"trade-machine/models"
 )
 
@@ -24,7 +25,7 @@ func TestNewsAnalyst_Type(t *testing.T) {
 }
 
 func TestNewNewsAnalyst(t *testing.T) {
-	analyst := NewNewsAnalyst(nil, nil)
+	analyst := NewNewsAnalyst(nil, nil, config.NewTestConfig())
 	if analyst == nil {
 		t.Error("NewNewsAnalyst should not return nil")
 	}
@@ -85,7 +86,7 @@ func TestNewsAnalyst_Analyze_Success(t *testing.T) {
 		},
 	}
 
-	analyst := NewNewsAnalyst(mockLLM, mockNewsAPI)
+	analyst := NewNewsAnalyst(mockLLM, mockNewsAPI, config.NewTestConfig())
 	ctx := context.Background()
 
 	analysis, err := analyst.Analyze(ctx, "AAPL")
@@ -124,7 +125,7 @@ func TestNewsAnalyst_Analyze_NoArticles(t *testing.T) {
 		articles: []models.NewsArticle{},
 	}
 
-	analyst := NewNewsAnalyst(mockLLM, mockNewsAPI)
+	analyst := NewNewsAnalyst(mockLLM, mockNewsAPI, config.NewTestConfig())
 	ctx := context.Background()
 
 	analysis, err := analyst.Analyze(ctx, "UNKNOWN")
@@ -152,7 +153,7 @@ func TestNewsAnalyst_Analyze_NewsAPIError(t *testing.T) {
 		err: errors.New("API rate limit exceeded"),
 	}
 
-	analyst := NewNewsAnalyst(mockLLM, mockNewsAPI)
+	analyst := NewNewsAnalyst(mockLLM, mockNewsAPI, config.NewTestConfig())
 	ctx := context.Background()
 
 	_, err := analyst.Analyze(ctx, "AAPL")
@@ -172,7 +173,7 @@ func TestNewsAnalyst_Analyze_LLMError(t *testing.T) {
 		},
 	}
 
-	analyst := NewNewsAnalyst(mockLLM, mockNewsAPI)
+	analyst := NewNewsAnalyst(mockLLM, mockNewsAPI, config.NewTestConfig())
 	ctx := context.Background()
 
 	_, err := analyst.Analyze(ctx, "AAPL")
@@ -192,7 +193,7 @@ func TestNewsAnalyst_Analyze_InvalidJSON(t *testing.T) {
 		},
 	}
 
-	analyst := NewNewsAnalyst(mockLLM, mockNewsAPI)
+	analyst := NewNewsAnalyst(mockLLM, mockNewsAPI, config.NewTestConfig())
 	ctx := context.Background()
 
 	analysis, err := analyst.Analyze(ctx, "AAPL")
@@ -215,7 +216,7 @@ func TestNewsAnalyst_IsAvailable_Success(t *testing.T) {
 		},
 	}
 
-	analyst := NewNewsAnalyst(nil, mockNewsAPI)
+	analyst := NewNewsAnalyst(nil, mockNewsAPI, config.NewTestConfig())
 	ctx := context.Background()
 
 	if !analyst.IsAvailable(ctx) {
@@ -228,7 +229,7 @@ func TestNewsAnalyst_IsAvailable_Failure(t *testing.T) {
 		err: errors.New("service unavailable"),
 	}
 
-	analyst := NewNewsAnalyst(nil, mockNewsAPI)
+	analyst := NewNewsAnalyst(nil, mockNewsAPI, config.NewTestConfig())
 	ctx := context.Background()
 
 	if analyst.IsAvailable(ctx) {
@@ -268,3 +269,38 @@ func TestNewsAnalyst_GetMetadata(t *testing.T) {
 		t.Error("RequiredServices should include llm")
 	}
 }
+
+func TestNewsAnalyst_Analyze_RecencyWeighting(t *testing.T) {
+	mockLLM := &mockLLMService{
+		response: `{"score": 10, "confidence": 70, "reasoning": "Fresh upgrade", "article_scores": [80, -40], "key_themes": [], "notable_articles": []}`,
+	}
+
+	now := time.Now()
+	mockNewsAPI := &mockNewsAPIService{
+		articles: []models.NewsArticle{
+			{Title: "Analyst upgrade", Source: "Test", PublishedAt: now.Add(-time.Minute)},
+			{Title: "Old lawsuit", Source: "Test", PublishedAt: now.Add(-24 * time.Hour)},
+			{Title: "Month-old recap", Source: "Test", PublishedAt: now.AddDate(0, 0, -30)},
+		},
+	}
+
+	analyst := NewNewsAnalyst(mockLLM, mockNewsAPI, config.NewTestConfig())
+	analysis, err := analyst.Analyze(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if age := now.Sub(mockNewsAPI.from); age < 7*24*time.Hour-time.Minute || age > 7*24*time.Hour+time.Minute {
+		t.Errorf("expected 7-day lookback, got from %v", mockNewsAPI.from)
+	}
+	if count := analysis.Data["articles_count"]; count != 2 {
+		t.Errorf("articles_count = %v, want 2 after dropping the stale article", count)
+	}
+	if weighted, _ := analysis.Data["recency_weighted"].(bool); !weighted {
+		t.Error("expected recency-weighted score")
+	}
+	// Yesterday's article weighs about half of today's: (80*1 - 40*0.5) / 1.5 = 40
+	if analysis.Score < 39.5 || analysis.Score > 40.5 {
+		t.Errorf("Score = %v, want ~40", analysis.Score)
+	}
+}
@@ -38,16 +38,20 @@ type PositionSizingConfig struct {
 
 	// UseConfidenceScaling whether to scale position size by confidence
 	UseConfidenceScaling bool
+
+	// ShortMarginRequirement is the margin held per dollar of short exposure (e.g. 1.5 = 150%)
+	ShortMarginRequirement float64
 }
 
 // DefaultPositionSizingConfig returns sensible defaults for position sizing
 func DefaultPositionSizingConfig() PositionSizingConfig {
 	return PositionSizingConfig{
-		MaxPositionPercent:   0.10, // Max 10% of portfolio in single position
-		RiskPercent:          0.02, // Risk 2% of portfolio per trade
-		MinShares:            1,
-		MaxShares:            0, // Unlimited
-		UseConfidenceScaling: true,
+		MaxPositionPercent:     0.10, // Max 10% of portfolio in single position
+		RiskPercent:            0.02, // Risk 2% of portfolio per trade
+		MinShares:              1,
+		MaxShares:              0, // Unlimited
+		UseConfidenceScaling:   true,
+		ShortMarginRequirement: 1.5,
 	}
 }
 
@@ -68,6 +72,10 @@ func NewDefaultPositionSizer(config PositionSizingConfig) *DefaultPositionSizer
 // - Maximum position size as percentage of portfolio
 // - Confidence level (optionally scales the position)
 // - Existing position in the symbol
+//
+// Sells and covers close the existing long or short position. Shorts are sized like
+// buys, with buying power reduced by the short margin requirement. Buys and shorts never
+// cost more than the buying power, even when that leaves them below MinShares.
 func (ps *DefaultPositionSizer) CalculateQuantity(
 	ctx context.Context,
 	account *models.Account,
@@ -84,19 +92,29 @@ func (ps *DefaultPositionSizer) CalculateQuantity(
 		return decimal.NewFromInt(ps.config.MinShares), nil
 	}
 
-	if action == models.RecommendationActionSell {
-		if existingPosition != nil && existingPosition.Quantity.GreaterThan(decimal.Zero) {
+	if action == models.RecommendationActionSell || action == models.RecommendationActionCover {
+		wantSide := models.PositionSideLong
+		if action == models.RecommendationActionCover {
+			wantSide = models.PositionSideShort
+		}
+		if existingPosition != nil && positionSide(existingPosition) == wantSide && existingPosition.Quantity.GreaterThan(decimal.Zero) {
 			return existingPosition.Quantity, nil
 		}
 		return decimal.NewFromInt(ps.config.MinShares), nil
 	}
 
+	buyingPower := account.BuyingPower
+	if action == models.RecommendationActionShort && ps.config.ShortMarginRequirement > 0 {
+		buyingPower = buyingPower.Div(decimal.NewFromFloat(ps.config.ShortMarginRequirement))
+	}
+	affordable := decimal.Max(buyingPower.Div(currentPrice).Floor(), decimal.Zero)
+
 	portfolioValue := account.PortfolioValue
 	if portfolioValue.IsZero() || portfolioValue.IsNegative() {
 		portfolioValue = account.Equity
 	}
 	if portfolioValue.IsZero() || portfolioValue.IsNegative() {
-		return decimal.NewFromInt(ps.config.MinShares), nil
+		return decimal.Min(decimal.NewFromInt(ps.config.MinShares), affordable), nil
 	}
 
 	maxPositionPercent := decimal.NewFromFloat(ps.config.MaxPositionPercent)
@@ -110,8 +128,8 @@ func (ps *DefaultPositionSizer) CalculateQuantity(
 		maxPositionValue = maxPositionValue.Mul(decimal.NewFromFloat(confidenceFactor))
 	}
 
-	if account.BuyingPower.LessThan(maxPositionValue) {
-		maxPositionValue = account.BuyingPower
+	if buyingPower.LessThan(maxPositionValue) {
+		maxPositionValue = buyingPower
 	}
 
 	shares := maxPositionValue.Div(currentPrice).Floor()
@@ -128,5 +146,13 @@ func (ps *DefaultPositionSizer) CalculateQuantity(
 		}
 	}
 
-	return shares, nil
+	return decimal.Max(decimal.Min(shares, affordable), decimal.Zero), nil
+}
+
+// positionSide returns a position's side, treating an unset side as long
+func positionSide(p *models.Position) models.PositionSide {
+	if p.Side == models.PositionSideShort {
+		return models.PositionSideShort
+	}
+	return models.PositionSideLong
 }
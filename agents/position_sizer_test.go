@@ -2,6 +2,7 @@ package agents
 
 import (
 	"context"
+	"math"
 	"testing"
 
 	"trade-machine/models"
@@ -81,6 +82,24 @@ func TestDefaultPositionSizer_CalculateQuantity_Buy(t *testing.T) {
 			wantMin:      decimal.NewFromInt(50),
 			wantMax:      decimal.NewFromInt(50), // Limited to $5000 / $100 = 50 shares
 		},
+		{
+			name: "buy never exceeds buying power to reach minimum shares",
+			config: PositionSizingConfig{
+				MaxPositionPercent:   0.10,
+				MinShares:            10,
+				UseConfidenceScaling: false,
+			},
+			account: &models.Account{
+				PortfolioValue: decimal.NewFromInt(100000),
+				BuyingPower:    decimal.NewFromInt(550),
+				Equity:         decimal.NewFromInt(100000),
+			},
+			currentPrice: decimal.NewFromInt(100),
+			action:       models.RecommendationActionBuy,
+			confidence:   80,
+			wantMin:      decimal.NewFromInt(5),
+			wantMax:      decimal.NewFromInt(5), // $550 / $100 = 5 shares, below the minimum of 10
+		},
 		{
 			name: "buy with max shares limit",
 			config: PositionSizingConfig{
@@ -183,6 +202,47 @@ func TestDefaultPositionSizer_CalculateQuantity_Sell(t *testing.T) {
 	})
 }
 
+func TestDefaultPositionSizer_CalculateQuantity_Short(t *testing.T) {
+	ps := NewDefaultPositionSizer(DefaultPositionSizingConfig())
+	ctx := context.Background()
+	account := &models.Account{
+		PortfolioValue: decimal.NewFromInt(100000),
+		BuyingPower:    decimal.NewFromInt(6000),
+	}
+	short := &models.Position{Symbol: "AAPL", Quantity: decimal.NewFromInt(30), Side: models.PositionSideShort}
+
+	t.Run("short is capped by margin requirement", func(t *testing.T) {
+		// 6000 buying power / 1.5 margin = 4000 of exposure = 40 shares at $100
+		got, err := ps.CalculateQuantity(ctx, account, decimal.NewFromInt(100), models.RecommendationActionShort, 100, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !got.Equal(decimal.NewFromInt(40)) {
+			t.Errorf("quantity = %s, want 40", got.String())
+		}
+	})
+
+	t.Run("cover closes the short", func(t *testing.T) {
+		got, err := ps.CalculateQuantity(ctx, account, decimal.NewFromInt(100), models.RecommendationActionCover, 80, short)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !got.Equal(decimal.NewFromInt(30)) {
+			t.Errorf("quantity = %s, want 30 (full short)", got.String())
+		}
+	})
+
+	t.Run("sell does not size off a short", func(t *testing.T) {
+		got, err := ps.CalculateQuantity(ctx, account, decimal.NewFromInt(100), models.RecommendationActionSell, 80, short)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !got.Equal(decimal.NewFromInt(1)) {
+			t.Errorf("quantity = %s, want 1 (minimum)", got.String())
+		}
+	})
+}
+
 func TestDefaultPositionSizer_CalculateQuantity_Hold(t *testing.T) {
 	ps := NewDefaultPositionSizer(DefaultPositionSizingConfig())
 	ctx := context.Background()
@@ -357,3 +417,78 @@ func TestNewDefaultPositionSizer(t *testing.T) {
 		t.Errorf("config.MinShares = %v, want 5", ps.config.MinShares)
 	}
 }
+
+// FuzzDefaultPositionSizer checks the sizing invariants over arbitrary accounts, prices
+// and configurations: quantities are never negative, and buys and shorts never cost more
+// than the buying power available to them
+func FuzzDefaultPositionSizer(f *testing.F) {
+	f.Add(int64(10000000), int64(10000000), int64(10000), 80.0, uint8(0), uint8(10), uint8(1), uint8(0), true, int64(0))
+	f.Add(int64(10000000), int64(500000), int64(10000), 50.0, uint8(0), uint8(10), uint8(1), uint8(25), false, int64(0))
+	f.Add(int64(0), int64(50), int64(500000), 100.0, uint8(2), uint8(100), uint8(5), uint8(0), true, int64(0))
+	f.Add(int64(10000000), int64(-1000), int64(10000), 0.0, uint8(0), uint8(10), uint8(1), uint8(0), true, int64(0))
+	f.Add(int64(10000000), int64(10000000), int64(10000), 80.0, uint8(1), uint8(10), uint8(1), uint8(0), true, int64(30))
+
+	actions := []models.RecommendationAction{
+		models.RecommendationActionBuy,
+		models.RecommendationActionSell,
+		models.RecommendationActionShort,
+		models.RecommendationActionCover,
+		models.RecommendationActionHold,
+	}
+
+	f.Fuzz(func(t *testing.T, portfolioCents, buyingPowerCents, priceCents int64, confidence float64, actionIndex, maxPercent, minShares, maxShares uint8, scaling bool, existing int64) {
+		if math.IsNaN(confidence) || confidence < 0 || confidence > 100 {
+			t.Skip("confidence is normalized to 0-100 before sizing")
+		}
+		const maxCents = 1e15
+		if priceCents > maxCents || portfolioCents > maxCents || portfolioCents < -maxCents || buyingPowerCents > maxCents || buyingPowerCents < -maxCents {
+			t.Skip("beyond any real account or share price")
+		}
+		config := PositionSizingConfig{
+			MaxPositionPercent:     float64(maxPercent%101) / 100,
+			MinShares:              int64(minShares),
+			MaxShares:              int64(maxShares),
+			UseConfidenceScaling:   scaling,
+			ShortMarginRequirement: 1.5,
+		}
+		account := &models.Account{
+			PortfolioValue: decimal.New(portfolioCents, -2),
+			Equity:         decimal.New(portfolioCents, -2),
+			BuyingPower:    decimal.New(buyingPowerCents, -2),
+		}
+		price := decimal.New(priceCents, -2)
+		action := actions[int(actionIndex)%len(actions)]
+		var position *models.Position
+		if existing != 0 {
+			side := models.PositionSideLong
+			if existing < 0 {
+				side = models.PositionSideShort
+			}
+			position = &models.Position{Symbol: "AAPL", Quantity: decimal.NewFromInt(existing).Abs(), Side: side}
+		}
+
+		got, err := NewDefaultPositionSizer(config).CalculateQuantity(context.Background(), account, price, action, confidence, position)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got.IsNegative() {
+			t.Fatalf("quantity = %s, want non-negative", got)
+		}
+		if action == models.RecommendationActionHold && !got.IsZero() {
+			t.Fatalf("hold quantity = %s, want 0", got)
+		}
+		if (action == models.RecommendationActionBuy || action == models.RecommendationActionShort) && price.IsPositive() {
+			buyingPower := account.BuyingPower
+			if action == models.RecommendationActionShort {
+				buyingPower = buyingPower.Div(decimal.NewFromFloat(config.ShortMarginRequirement))
+			}
+			if cost := got.Mul(price); cost.GreaterThan(decimal.Max(buyingPower, decimal.Zero)) {
+				t.Fatalf("%s of %s shares at %s costs %s, more than buying power %s", action, got, price, cost, buyingPower)
+			}
+			if config.MaxShares >= config.MinShares && config.MaxShares > 0 && got.GreaterThan(decimal.NewFromInt(config.MaxShares)) {
+				t.Fatalf("quantity = %s, want at most MaxShares %d", got, config.MaxShares)
+			}
+		}
+	})
+}
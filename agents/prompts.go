@@ -0,0 +1,58 @@
+package agents
+
+import (
+	"context"
+
+	"trade-machine/models"
+)
+
+// PromptStore supplies system prompts customized in settings. ActivePrompt returns the
+// prompt an agent type should use and its version, or "" when it should use its own.
+type PromptStore interface {
+	ActivePrompt(agent string) (string, int)
+}
+
+// defaultSystemPrompts are the built-in system prompts of the agents that prompt an LLM
+var defaultSystemPrompts = map[models.AgentType]string{
+	models.AgentTypeFundamental: fundamentalSystemPrompt,
+	models.AgentTypeNews:        newsSystemPrompt,
+	models.AgentTypeTechnical:   technicalSystemPrompt,
+	models.AgentTypeSocial:      socialSystemPrompt,
+}
+
+// DefaultSystemPrompt returns an agent type's built-in system prompt, or false for agents
+// that don't prompt an LLM
+func DefaultSystemPrompt(agentType models.AgentType) (string, bool) {
+	prompt, ok := defaultSystemPrompts[agentType]
+	return prompt, ok
+}
+
+// SetPromptStore enables system prompts customized in settings (optional dependency). The
+// store is read for every agent run, so changes apply to the next analysis.
+func (m *PortfolioManager) SetPromptStore(store PromptStore) {
+	m.prompts = store
+}
+
+// systemPromptKey carries a customized system prompt for an agent's LLM calls
+type systemPromptKey struct{}
+
+// withSystemPrompt returns a context whose LLM calls use the system prompt customized for
+// the agent type, if any, with the version in use (0 for the built-in prompt)
+func (m *PortfolioManager) withSystemPrompt(ctx context.Context, agentType models.AgentType) (context.Context, int) {
+	if m.prompts == nil {
+		return ctx, 0
+	}
+	prompt, version := m.prompts.ActivePrompt(string(agentType))
+	if prompt == "" {
+		return ctx, 0
+	}
+	return context.WithValue(ctx, systemPromptKey{}, prompt), version
+}
+
+// systemPromptFor returns the customized system prompt carried by ctx, or fallback
+func systemPromptFor(ctx context.Context, fallback string) string {
+	if prompt, ok := ctx.Value(systemPromptKey{}).(string); ok {
+		return prompt
+	}
+	return fallback
+}
@@ -0,0 +1,63 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+)
+
+// fixedPromptStore customizes one agent's system prompt
+type fixedPromptStore struct {
+	agent, prompt string
+}
+
+func (s fixedPromptStore) ActivePrompt(agent string) (string, int) {
+	if agent != s.agent {
+		return "", 0
+	}
+	return s.prompt, 3
+}
+
+// systemPromptLLM answers with the system prompt it was given as the reasoning
+type systemPromptLLM struct {
+	mockLLMService
+}
+
+func (l *systemPromptLLM) InvokeWithPrompt(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return fmt.Sprintf(`{"score": 10, "confidence": 60, "reasoning": %q}`, systemPrompt), nil
+}
+
+func TestPortfolioManager_CustomSystemPrompt(t *testing.T) {
+	ctx := context.Background()
+	repo := &runRecordingRepo{}
+	manager := NewPortfolioManager(repo, testConfig(), newMockAccountProvider())
+	manager.SetPromptStore(fixedPromptStore{agent: "news", prompt: "You are a skeptical news analyst."})
+	news := &mockNewsAPIService{articles: []models.NewsArticle{{Title: "Apple launches a new phone", Source: "Reuters", PublishedAt: time.Now()}}}
+	manager.RegisterAgent(NewNewsAnalyst(&systemPromptLLM{}, news, testConfig()))
+
+	result := manager.runAgent(ctx, 0, manager.agents[0], "AAPL")
+	if result.analysis == nil || result.analysis.Reasoning != "You are a skeptical news analyst." {
+		t.Fatalf("analysis = %+v, want the customized system prompt sent", result.analysis)
+	}
+	if version := repo.runs[0].InputData[models.AgentRunPromptVersionKey]; version != 3 {
+		t.Errorf("prompt_version = %v, want 3", version)
+	}
+
+	replay, err := manager.ReplayAgentRun(ctx, repo.runs[0], true)
+	if err != nil {
+		t.Fatalf("ReplayAgentRun error = %v", err)
+	}
+	if replay.PromptVersion != 3 || replay.Replayed.Reasoning != "You are a skeptical news analyst." {
+		t.Errorf("replay = %+v, want the customized system prompt", replay)
+	}
+
+	if prompt, ok := DefaultSystemPrompt(models.AgentTypeNews); !ok || prompt != newsSystemPrompt {
+		t.Error("DefaultSystemPrompt(news) should return the built-in prompt")
+	}
+	if _, ok := DefaultSystemPrompt(models.AgentTypeMacro); ok {
+		t.Error("DefaultSystemPrompt(macro) should report no prompt")
+	}
+}
@@ -0,0 +1,157 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/services"
+)
+
+// PromptReplayer is implemented by agents that analyze by prompting an LLM, so a past
+// run's user prompt can be sent again with the agent's current system prompt
+type PromptReplayer interface {
+	ReplayPrompt(ctx context.Context, symbol, userPrompt string) (*Analysis, error)
+}
+
+// promptCaptureKey carries where the user prompt of an agent's LLM call is recorded
+type promptCaptureKey struct{}
+
+// withPromptCapture returns a context whose LLM calls made through invokeLLM record their
+// user prompt in the returned string
+func withPromptCapture(ctx context.Context) (context.Context, *string) {
+	prompt := new(string)
+	return context.WithValue(ctx, promptCaptureKey{}, prompt), prompt
+}
+
+// invokeLLM prompts the LLM with the system prompt customized in ctx, if any, recording
+// the user prompt when ctx asks for it so the run can be replayed
+func invokeLLM(ctx context.Context, llm LLMService, systemPrompt, userPrompt string) (string, error) {
+	if prompt, ok := ctx.Value(promptCaptureKey{}).(*string); ok {
+		*prompt = userPrompt
+	}
+	return llm.InvokeWithPrompt(ctx, systemPromptFor(ctx, systemPrompt), userPrompt)
+}
+
+// replayResponse is the part of every analyst's response a replay compares
+type replayResponse struct {
+	Score      float64 `json:"score"`
+	Confidence float64 `json:"confidence"`
+	Reasoning  string  `json:"reasoning"`
+}
+
+// replayPrompt sends userPrompt with systemPrompt, or the one customized in ctx, and reads
+// the score, confidence and reasoning from the response. Agent-specific adjustments, such
+// as the news analyst's recency weighting, are not applied, so the result reflects the
+// prompt alone.
+func replayPrompt(ctx context.Context, llm LLMService, agentType models.AgentType, symbol, systemPrompt, userPrompt string) (*Analysis, error) {
+	response, err := llm.InvokeWithPrompt(ctx, systemPromptFor(ctx, systemPrompt), userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to invoke LLM: %w", err)
+	}
+
+	analysis := &Analysis{
+		Symbol:     symbol,
+		AgentType:  agentType,
+		Confidence: 50,
+		Reasoning:  response,
+		Data:       map[string]interface{}{"raw_response": response},
+		Timestamp:  time.Now(),
+	}
+	var result replayResponse
+	if err := services.ParseStructuredOutput(response, &result); err == nil {
+		analysis.Score = NormalizeScore(result.Score)
+		analysis.Confidence = NormalizeConfidence(result.Confidence)
+		analysis.Reasoning = result.Reasoning
+		analysis.Data = map[string]interface{}{}
+	}
+	return analysis, nil
+}
+
+// replayingAgent analyzes by replaying a stored prompt, so a replay gets the agent's
+// timeout, retries and model override through analyzeWithRetries
+type replayingAgent struct {
+	Agent
+	replayer PromptReplayer
+	prompt   string
+}
+
+// Analyze replays the stored prompt
+func (r replayingAgent) Analyze(ctx context.Context, symbol string) (*Analysis, error) {
+	return r.replayer.ReplayPrompt(ctx, symbol, r.prompt)
+}
+
+// ReplayAgentRun sends a past run's stored prompt to its agent again with the current
+// system prompt and model, and compares the output with the run's. Unless dryRun is set
+// the replay is recorded as a new agent run; no recommendation is made either way.
+func (m *PortfolioManager) ReplayAgentRun(ctx context.Context, run *models.AgentRun, dryRun bool) (*models.AgentRunReplay, error) {
+	prompt := run.Prompt()
+	if prompt == "" {
+		return nil, fmt.Errorf("%w: no prompt was stored for run %s", models.ErrAgentRunNotReplayable, run.ID)
+	}
+	var agent replayingAgent
+	for _, ag := range m.agents {
+		if replayer, ok := ag.(PromptReplayer); ok && ag.Type() == run.AgentType {
+			agent = replayingAgent{Agent: ag, replayer: replayer, prompt: prompt}
+			break
+		}
+	}
+	if agent.Agent == nil {
+		return nil, fmt.Errorf("%w: no %s agent that prompts an LLM is registered", models.ErrAgentRunNotReplayable, run.AgentType)
+	}
+
+	if err := m.checkLLMBudget(ctx); err != nil {
+		return nil, err
+	}
+
+	settings := m.settingsFor(run.AgentType)
+	ctx, promptVersion := m.withSystemPrompt(ctx, run.AgentType)
+	var replayRun *models.AgentRun
+	if !dryRun {
+		replayRun = models.NewAgentRun(run.AgentType, run.Symbol)
+		replayRun.InputData = settings.metadata()
+		replayRun.InputData[models.AgentRunPromptKey] = prompt
+		replayRun.InputData["replay_of"] = run.ID.String()
+		if promptVersion > 0 {
+			replayRun.InputData[models.AgentRunPromptVersionKey] = promptVersion
+		}
+		m.repo.CreateAgentRun(ctx, replayRun)
+	}
+
+	usageCtx, usage := services.WithLLMUsage(ctx)
+	analysis, attempts, err := analyzeWithRetries(usageCtx, agent, run.Symbol, settings)
+	if replayRun != nil {
+		if err != nil {
+			replayRun.Fail(err)
+			replayRun.OutputData = map[string]interface{}{"attempts": attempts}
+		} else {
+			replayRun.Complete(map[string]interface{}{
+				"score":      analysis.Score,
+				"confidence": analysis.Confidence,
+				"reasoning":  analysis.Reasoning,
+				"attempts":   attempts,
+			})
+		}
+		m.recordLLMUsage(ctx, run.AgentType, run.Symbol, replayRun, usage)
+		m.repo.UpdateAgentRun(ctx, replayRun)
+	} else {
+		m.recordLLMUsage(ctx, run.AgentType, run.Symbol, nil, usage)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay agent run %s: %w", run.ID, err)
+	}
+
+	replay := models.NewAgentRunReplay(run, models.AgentRunOutput{
+		Score:      analysis.Score,
+		Confidence: analysis.Confidence,
+		Reasoning:  analysis.Reasoning,
+	})
+	replay.Model = settings.Model
+	replay.PromptVersion = promptVersion
+	replay.DryRun = dryRun
+	if replayRun != nil {
+		replay.ReplayRunID = &replayRun.ID
+	}
+	return replay, nil
+}
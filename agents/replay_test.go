@@ -0,0 +1,77 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+)
+
+// runRecordingRepo keeps the agent runs it is given
+type runRecordingRepo struct {
+	completingRepo
+	runs []*models.AgentRun
+}
+
+func (r *runRecordingRepo) CreateAgentRun(ctx context.Context, run *models.AgentRun) error {
+	r.runs = append(r.runs, run)
+	return nil
+}
+
+func TestPortfolioManager_ReplayAgentRun(t *testing.T) {
+	ctx := context.Background()
+	repo := &runRecordingRepo{}
+	manager := NewPortfolioManager(repo, testConfig(), newMockAccountProvider())
+	llm := &mockLLMService{response: `{"score": 40, "confidence": 70, "reasoning": "Strong launch", "article_scores": [40]}`}
+	news := &mockNewsAPIService{articles: []models.NewsArticle{{Title: "Apple launches a new phone", Source: "Reuters", PublishedAt: time.Now().Add(-time.Hour)}}}
+	manager.RegisterAgent(NewNewsAnalyst(llm, news, testConfig()))
+	manager.RegisterAgent(&testMockAgent{name: "Fundamental", agentType: models.AgentTypeFundamental})
+
+	manager.runAgent(ctx, 0, manager.agents[0], "AAPL")
+	if len(repo.runs) != 1 || !strings.Contains(repo.runs[0].Prompt(), "Apple launches a new phone") {
+		t.Fatalf("runs = %+v, want one run with the news prompt stored", repo.runs)
+	}
+	original := repo.runs[0]
+
+	t.Run("dry run", func(t *testing.T) {
+		llm.response = `{"score": 10, "confidence": 60, "reasoning": "Launch already priced in"}`
+		replay, err := manager.ReplayAgentRun(ctx, original, true)
+		if err != nil {
+			t.Fatalf("ReplayAgentRun error = %v", err)
+		}
+		if !replay.DryRun || replay.ReplayRunID != nil || len(repo.runs) != 1 {
+			t.Errorf("replay = %+v with %d runs, want nothing recorded", replay, len(repo.runs))
+		}
+		if replay.Original == nil || replay.Replayed.Score != 10 || replay.ScoreDelta != replay.Replayed.Score-replay.Original.Score || !replay.ReasoningChanged {
+			t.Errorf("replay = %+v, want the new output compared with the original", replay)
+		}
+	})
+
+	t.Run("recorded", func(t *testing.T) {
+		replay, err := manager.ReplayAgentRun(ctx, original, false)
+		if err != nil {
+			t.Fatalf("ReplayAgentRun error = %v", err)
+		}
+		if len(repo.runs) != 2 || replay.ReplayRunID == nil || *replay.ReplayRunID != repo.runs[1].ID {
+			t.Fatalf("replay = %+v, want it recorded as a new agent run", replay)
+		}
+		if run := repo.runs[1]; run.InputData["replay_of"] != original.ID.String() || run.Status != models.AgentRunStatusCompleted {
+			t.Errorf("replay run = %+v, want a completed run pointing at the original", run)
+		}
+	})
+
+	t.Run("not replayable", func(t *testing.T) {
+		noPrompt := models.NewAgentRun(models.AgentTypeNews, "AAPL")
+		if _, err := manager.ReplayAgentRun(ctx, noPrompt, true); !errors.Is(err, models.ErrAgentRunNotReplayable) {
+			t.Errorf("replay without a prompt error = %v, want ErrAgentRunNotReplayable", err)
+		}
+		noLLM := models.NewAgentRun(models.AgentTypeFundamental, "AAPL")
+		noLLM.InputData = map[string]interface{}{models.AgentRunPromptKey: "Analyze AAPL"}
+		if _, err := manager.ReplayAgentRun(ctx, noLLM, true); !errors.Is(err, models.ErrAgentRunNotReplayable) {
+			t.Errorf("replay for an agent without an LLM error = %v, want ErrAgentRunNotReplayable", err)
+		}
+	})
+}
@@ -0,0 +1,271 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+// RiskPortfolio supplies the account, open positions and price history a RiskManager
+// checks recommendations against
+type RiskPortfolio interface {
+	GetAccount(ctx context.Context) (*models.Account, error)
+	GetPositions(ctx context.Context) ([]models.Position, error)
+	GetDailyBars(ctx context.Context, symbol string, days int) ([]marketdata.Bar, error)
+	GetEquityHistory(ctx context.Context, days int) ([]models.DailyClose, error)
+}
+
+// SectorProvider supplies the fundamentals whose sector groups positions for the sector limit
+type SectorProvider interface {
+	GetFundamentals(ctx context.Context, symbol string) (*models.Fundamentals, error)
+}
+
+// RiskManager reviews synthesized buys and shorts against the whole portfolio. A
+// recommendation that would push a symbol or sector past its share of equity is downgraded
+// to the quantity that fits, and vetoed to a hold when nothing fits or the portfolio is
+// already in a drawdown past the limit. Sells and covers reduce exposure and are never
+// touched. Checks whose data can't be fetched are skipped rather than blocking analysis.
+type RiskManager struct {
+	cfg       config.RiskManagerConfig
+	portfolio RiskPortfolio
+	sectors   SectorProvider
+}
+
+// NewRiskManager creates a RiskManager. A nil sectors skips the sector limit.
+func NewRiskManager(cfg config.RiskManagerConfig, portfolio RiskPortfolio, sectors SectorProvider) *RiskManager {
+	return &RiskManager{cfg: cfg, portfolio: portfolio, sectors: sectors}
+}
+
+// SetRiskManager sets the risk manager that reviews recommendations after synthesis
+// (optional dependency)
+func (m *PortfolioManager) SetRiskManager(rm *RiskManager) {
+	m.riskManager = rm
+}
+
+// reviewRisk runs the risk manager over a synthesized recommendation, if one is set
+func (m *PortfolioManager) reviewRisk(ctx context.Context, rec *models.Recommendation) {
+	if m.riskManager == nil || m.SignalOnly() {
+		return
+	}
+	m.riskManager.Review(ctx, rec)
+}
+
+// exposureLimit is one cap on the equity a new position may add to
+type exposureLimit struct {
+	reason   string
+	headroom decimal.Decimal
+}
+
+// Review downgrades or vetoes rec in place when it breaks a portfolio limit, explaining
+// the change in its reasoning
+func (r *RiskManager) Review(ctx context.Context, rec *models.Recommendation) {
+	if rec.Action != models.RecommendationActionBuy && rec.Action != models.RecommendationActionShort {
+		return
+	}
+	if !rec.Quantity.IsPositive() || !rec.EntryPrice.IsPositive() {
+		return
+	}
+
+	account, err := r.portfolio.GetAccount(ctx)
+	if err != nil {
+		logger.Warn("account unavailable, risk limits not checked",
+			"symbol", rec.Symbol,
+			"error", err)
+		return
+	}
+	equity := account.Equity
+	if !equity.IsPositive() {
+		return
+	}
+
+	if reason := r.checkDrawdown(ctx, equity.InexactFloat64()); reason != "" {
+		veto(rec, reason)
+		return
+	}
+
+	positions, err := r.portfolio.GetPositions(ctx)
+	if err != nil {
+		logger.Warn("positions unavailable, exposure limits not checked",
+			"symbol", rec.Symbol,
+			"error", err)
+		return
+	}
+
+	var limits []exposureLimit
+	if l, ok := r.symbolLimit(ctx, rec, positions, equity); ok {
+		limits = append(limits, l)
+	}
+	if l, ok := r.sectorLimit(ctx, rec.Symbol, positions, equity); ok {
+		limits = append(limits, l)
+	}
+
+	orderValue := rec.Quantity.Mul(rec.EntryPrice)
+	var tightest *exposureLimit
+	for i := range limits {
+		if limits[i].headroom.LessThan(orderValue) && (tightest == nil || limits[i].headroom.LessThan(tightest.headroom)) {
+			tightest = &limits[i]
+		}
+	}
+	if tightest == nil {
+		return
+	}
+
+	fits := decimal.Max(tightest.headroom, decimal.Zero).Div(rec.EntryPrice).Floor()
+	if fits.LessThan(decimal.NewFromInt(1)) {
+		veto(rec, tightest.reason)
+		return
+	}
+	rec.Reasoning += fmt.Sprintf("Reduced from %s to %s shares by the risk manager: %s. ", rec.Quantity, fits, tightest.reason)
+	rec.Quantity = fits
+}
+
+// veto turns rec into a hold with no quantity
+func veto(rec *models.Recommendation, reason string) {
+	rec.Reasoning += fmt.Sprintf("Vetoed by the risk manager (%s would have been %s shares): %s. ", rec.Action, rec.Quantity, reason)
+	rec.Action = models.RecommendationActionHold
+	rec.Quantity = decimal.Zero
+}
+
+// checkDrawdown returns why new positions are blocked when current equity is further below
+// its peak over the lookback than the limit allows, or "" when they are not
+func (r *RiskManager) checkDrawdown(ctx context.Context, equity float64) string {
+	if r.cfg.MaxDrawdown <= 0 {
+		return ""
+	}
+	history, err := r.portfolio.GetEquityHistory(ctx, r.cfg.LookbackDays)
+	if err != nil {
+		logger.Warn("equity history unavailable, drawdown limit not checked", "error", err)
+		return ""
+	}
+	drawdown := models.CurrentDrawdown(append(history, models.DailyClose{Date: time.Now(), Close: equity}))
+	if drawdown < r.cfg.MaxDrawdown {
+		return ""
+	}
+	return fmt.Sprintf("portfolio is %.1f%% below its peak equity, past the %.0f%% drawdown limit", drawdown*100, r.cfg.MaxDrawdown*100)
+}
+
+// symbolLimit caps the symbol's exposure on the recommendation's side, counting positions on
+// that side whose returns move with the symbol's as more of the same bet. A position held on
+// the other side is offset by the order rather than added to.
+func (r *RiskManager) symbolLimit(ctx context.Context, rec *models.Recommendation, positions []models.Position, equity decimal.Decimal) (exposureLimit, bool) {
+	if r.cfg.MaxSymbolPercent <= 0 {
+		return exposureLimit{}, false
+	}
+	side := models.PositionSideLong
+	if rec.Action == models.RecommendationActionShort {
+		side = models.PositionSideShort
+	}
+
+	maxCorrelation := r.cfg.MaxCorrelation
+	exposure := decimal.Zero
+	var correlated []string
+	var closes []models.DailyClose
+	for _, p := range positions {
+		if p.EffectiveSide() != side {
+			continue
+		}
+		if p.Symbol == rec.Symbol {
+			exposure = exposure.Add(marketValue(p))
+			continue
+		}
+		if maxCorrelation <= 0 {
+			continue
+		}
+		if closes == nil {
+			var err error
+			if closes, err = r.dailyCloses(ctx, rec.Symbol); err != nil {
+				logger.Warn("price history unavailable, correlated positions not counted",
+					"symbol", rec.Symbol,
+					"error", err)
+				maxCorrelation = 0
+				continue
+			}
+		}
+		other, err := r.dailyCloses(ctx, p.Symbol)
+		if err != nil {
+			logger.Warn("price history unavailable, position not checked for correlation",
+				"symbol", p.Symbol,
+				"error", err)
+			continue
+		}
+		if corr, ok := models.ReturnCorrelation(closes, other); ok && corr >= maxCorrelation {
+			exposure = exposure.Add(marketValue(p))
+			correlated = append(correlated, fmt.Sprintf("%s (%.2f)", p.Symbol, corr))
+		}
+	}
+
+	limit := equity.Mul(decimal.NewFromFloat(r.cfg.MaxSymbolPercent))
+	reason := fmt.Sprintf("%s exposure would exceed %.0f%% of equity", rec.Symbol, r.cfg.MaxSymbolPercent*100)
+	if len(correlated) > 0 {
+		reason += " counting correlated " + strings.Join(correlated, ", ")
+	}
+	return exposureLimit{reason: reason, headroom: limit.Sub(exposure)}, true
+}
+
+// sectorLimit caps the exposure of the symbol's sector. Symbols without a known sector,
+// and positions whose sector can't be looked up, are left out.
+func (r *RiskManager) sectorLimit(ctx context.Context, symbol string, positions []models.Position, equity decimal.Decimal) (exposureLimit, bool) {
+	if r.cfg.MaxSectorPercent <= 0 || r.sectors == nil {
+		return exposureLimit{}, false
+	}
+	sector := r.sectorOf(ctx, symbol)
+	if sector == "" {
+		return exposureLimit{}, false
+	}
+
+	exposure := decimal.Zero
+	for _, p := range positions {
+		if p.Symbol == symbol || r.sectorOf(ctx, p.Symbol) == sector {
+			exposure = exposure.Add(marketValue(p))
+		}
+	}
+
+	limit := equity.Mul(decimal.NewFromFloat(r.cfg.MaxSectorPercent))
+	return exposureLimit{
+		reason:   fmt.Sprintf("%s exposure would exceed %.0f%% of equity", sector, r.cfg.MaxSectorPercent*100),
+		headroom: limit.Sub(exposure),
+	}, true
+}
+
+func (r *RiskManager) sectorOf(ctx context.Context, symbol string) string {
+	fundamentals, err := r.sectors.GetFundamentals(ctx, symbol)
+	if err != nil {
+		logger.Warn("fundamentals unavailable, sector unknown",
+			"symbol", symbol,
+			"error", err)
+		return ""
+	}
+	if fundamentals == nil {
+		return ""
+	}
+	return models.NormalizeSector(fundamentals.Sector)
+}
+
+func (r *RiskManager) dailyCloses(ctx context.Context, symbol string) ([]models.DailyClose, error) {
+	bars, err := r.portfolio.GetDailyBars(ctx, symbol, r.cfg.LookbackDays)
+	if err != nil {
+		return nil, err
+	}
+	closes := make([]models.DailyClose, len(bars))
+	for i, bar := range bars {
+		closes[i] = models.DailyClose{Date: bar.Timestamp, Close: bar.Close}
+	}
+	return closes, nil
+}
+
+// marketValue is the gross value of a position at its current price, or its entry price
+// when no current price is known
+func marketValue(p models.Position) decimal.Decimal {
+	price := p.CurrentPrice
+	if !price.IsPositive() {
+		price = p.AvgEntryPrice
+	}
+	return p.Quantity.Abs().Mul(price)
+}
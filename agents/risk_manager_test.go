@@ -0,0 +1,224 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+
+	marketdata "github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+type mockRiskPortfolio struct {
+	equity    float64
+	positions []models.Position
+	bars      map[string][]marketdata.Bar
+	history   []models.DailyClose
+	err       error
+}
+
+func (m *mockRiskPortfolio) GetAccount(ctx context.Context) (*models.Account, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.Account{Equity: decimal.NewFromFloat(m.equity)}, nil
+}
+
+func (m *mockRiskPortfolio) GetPositions(ctx context.Context) ([]models.Position, error) {
+	return m.positions, nil
+}
+
+func (m *mockRiskPortfolio) GetDailyBars(ctx context.Context, symbol string, days int) ([]marketdata.Bar, error) {
+	return m.bars[symbol], nil
+}
+
+func (m *mockRiskPortfolio) GetEquityHistory(ctx context.Context, days int) ([]models.DailyClose, error) {
+	return m.history, nil
+}
+
+type mockSectors map[string]string
+
+func (m mockSectors) GetFundamentals(ctx context.Context, symbol string) (*models.Fundamentals, error) {
+	return &models.Fundamentals{Symbol: symbol, Sector: m[symbol]}, nil
+}
+
+// wavyBars returns 60 daily bars oscillating around 100; a negative amplitude moves against
+// a positive one
+func wavyBars(amplitude float64) []marketdata.Bar {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bars := make([]marketdata.Bar, 60)
+	for i := range bars {
+		bars[i] = marketdata.Bar{
+			Timestamp: start.AddDate(0, 0, i),
+			Close:     100 + amplitude*math.Sin(float64(i)),
+		}
+	}
+	return bars
+}
+
+func heldPosition(symbol string, side models.PositionSide, value int64) models.Position {
+	return models.Position{
+		Symbol:       symbol,
+		Quantity:     decimal.NewFromInt(value / 100),
+		CurrentPrice: decimal.NewFromInt(100),
+		Side:         side,
+	}
+}
+
+func riskManagerConfig() config.RiskManagerConfig {
+	return config.RiskManagerConfig{
+		Enabled:          true,
+		MaxSectorPercent: 0.30,
+		MaxSymbolPercent: 0.15,
+		MaxCorrelation:   0.80,
+		MaxDrawdown:      0.15,
+		LookbackDays:     90,
+	}
+}
+
+func TestRiskManager_Review(t *testing.T) {
+	tests := []struct {
+		name      string
+		action    models.RecommendationAction
+		portfolio *mockRiskPortfolio
+		want      int64
+		wantHold  bool
+		note      string
+	}{
+		{
+			name:      "fits every limit",
+			action:    models.RecommendationActionBuy,
+			portfolio: &mockRiskPortfolio{equity: 100000},
+			want:      100,
+		},
+		{
+			name:      "existing exposure downgrades",
+			action:    models.RecommendationActionBuy,
+			portfolio: &mockRiskPortfolio{equity: 100000, positions: []models.Position{heldPosition("AAPL", models.PositionSideLong, 10000)}},
+			want:      50,
+			note:      "Reduced from 100 to 50 shares",
+		},
+		{
+			name:      "full symbol vetoes",
+			action:    models.RecommendationActionBuy,
+			portfolio: &mockRiskPortfolio{equity: 100000, positions: []models.Position{heldPosition("AAPL", models.PositionSideLong, 15000)}},
+			wantHold:  true,
+			note:      "AAPL exposure would exceed 15%",
+		},
+		{
+			name:      "opposite side of the symbol is not counted",
+			action:    models.RecommendationActionBuy,
+			portfolio: &mockRiskPortfolio{equity: 100000, positions: []models.Position{heldPosition("AAPL", models.PositionSideShort, 15000)}},
+			want:      100,
+		},
+		{
+			name:   "sector concentration downgrades",
+			action: models.RecommendationActionBuy,
+			portfolio: &mockRiskPortfolio{equity: 100000, positions: []models.Position{
+				heldPosition("MSFT", models.PositionSideLong, 14000),
+				heldPosition("NVDA", models.PositionSideLong, 14000),
+			}},
+			want: 20,
+			note: "Information Technology exposure would exceed 30%",
+		},
+		{
+			name:   "correlated position counts toward the symbol",
+			action: models.RecommendationActionBuy,
+			portfolio: &mockRiskPortfolio{
+				equity:    100000,
+				positions: []models.Position{heldPosition("XOM", models.PositionSideLong, 10000)},
+				bars:      map[string][]marketdata.Bar{"AAPL": wavyBars(2), "XOM": wavyBars(3)},
+			},
+			want: 50,
+			note: "counting correlated XOM",
+		},
+		{
+			name:   "uncorrelated position is ignored",
+			action: models.RecommendationActionBuy,
+			portfolio: &mockRiskPortfolio{
+				equity:    100000,
+				positions: []models.Position{heldPosition("XOM", models.PositionSideLong, 10000)},
+				bars:      map[string][]marketdata.Bar{"AAPL": wavyBars(2), "XOM": wavyBars(-3)},
+			},
+			want: 100,
+		},
+		{
+			name:   "drawdown past the limit vetoes",
+			action: models.RecommendationActionShort,
+			portfolio: &mockRiskPortfolio{equity: 80000, history: []models.DailyClose{
+				{Date: time.Now().AddDate(0, 0, -10), Close: 100000},
+			}},
+			wantHold: true,
+			note:     "20.0% below its peak equity",
+		},
+		{
+			name:      "sells are left alone",
+			action:    models.RecommendationActionSell,
+			portfolio: &mockRiskPortfolio{equity: 100000, positions: []models.Position{heldPosition("AAPL", models.PositionSideLong, 50000)}},
+			want:      100,
+		},
+		{
+			name:      "account unavailable skips the checks",
+			action:    models.RecommendationActionBuy,
+			portfolio: &mockRiskPortfolio{err: errors.New("broker down")},
+			want:      100,
+		},
+	}
+
+	// Provider sector names are normalized before positions are grouped
+	sectors := mockSectors{"AAPL": models.SectorInformationTechnology, "MSFT": "Technology", "NVDA": " technology ", "XOM": models.SectorEnergy}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm := NewRiskManager(riskManagerConfig(), tt.portfolio, sectors)
+
+			rec := models.NewRecommendation("AAPL", tt.action, "")
+			rec.Quantity = decimal.NewFromInt(100)
+			rec.EntryPrice = decimal.NewFromInt(100)
+			rm.Review(context.Background(), rec)
+
+			if tt.wantHold {
+				if rec.Action != models.RecommendationActionHold || !rec.Quantity.IsZero() {
+					t.Fatalf("expected a veto to hold, got %s x %s", rec.Action, rec.Quantity)
+				}
+			} else {
+				if rec.Action != tt.action {
+					t.Errorf("Action = %s, want %s", rec.Action, tt.action)
+				}
+				if !rec.Quantity.Equal(decimal.NewFromInt(tt.want)) {
+					t.Errorf("Quantity = %s, want %d", rec.Quantity, tt.want)
+				}
+			}
+			if tt.note != "" && !strings.Contains(rec.Reasoning, tt.note) {
+				t.Errorf("reasoning %q, want it to contain %q", rec.Reasoning, tt.note)
+			}
+			if tt.note == "" && rec.Reasoning != "" {
+				t.Errorf("expected no risk note, got %q", rec.Reasoning)
+			}
+		})
+	}
+}
+
+func TestRiskManager_DisabledLimits(t *testing.T) {
+	cfg := config.RiskManagerConfig{LookbackDays: 90}
+	portfolio := &mockRiskPortfolio{
+		equity:    80000,
+		positions: []models.Position{heldPosition("AAPL", models.PositionSideLong, 50000)},
+		history:   []models.DailyClose{{Date: time.Now().AddDate(0, 0, -10), Close: 100000}},
+	}
+	rm := NewRiskManager(cfg, portfolio, nil)
+
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "")
+	rec.Quantity = decimal.NewFromInt(100)
+	rec.EntryPrice = decimal.NewFromInt(100)
+	rm.Review(context.Background(), rec)
+
+	if rec.Action != models.RecommendationActionBuy || !rec.Quantity.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("expected zero limits to leave the buy alone, got %s x %s", rec.Action, rec.Quantity)
+	}
+}
@@ -0,0 +1,98 @@
+package agents
+
+import (
+	"fmt"
+
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// applyPriceLevels sets the entry, target, and stop prices on a recommendation and
+// computes its risk/reward ratio. Levels suggested by an agent (via the "target_price"
+// and "stop_price" data keys) are preferred. Agent levels on the wrong side of the entry
+// are logged and noted in the reasoning. Without usable agent levels the configured
+// percentages set protective levels, but the ratio is left unknown (0) because it would
+// only restate the configuration and say nothing about the trade.
+func (m *PortfolioManager) applyPriceLevels(rec *models.Recommendation, entry decimal.Decimal, analyses []*Analysis) {
+	if !entry.IsPositive() {
+		return
+	}
+	rec.EntryPrice = entry
+
+	if rec.Action == models.RecommendationActionHold {
+		return
+	}
+
+	for _, analysis := range analyses {
+		target, okTarget := dataFloat(analysis.Data, "target_price")
+		stop, okStop := dataFloat(analysis.Data, "stop_price")
+		if !okTarget || !okStop {
+			continue
+		}
+		rec.TargetPrice = decimal.NewFromFloat(target).Round(2)
+		rec.StopPrice = decimal.NewFromFloat(stop).Round(2)
+		if rr := rec.CalculateRiskReward(); rr > 0 {
+			rec.RiskReward = rr
+			return
+		}
+		logger.Warn("ignoring inconsistent agent price levels",
+			"symbol", rec.Symbol,
+			"agent", analysis.AgentType,
+			"action", rec.Action,
+			"entry", entry.String(),
+			"target", rec.TargetPrice.String(),
+			"stop", rec.StopPrice.String())
+		rec.Reasoning += fmt.Sprintf("Ignored %s price levels: target %s and stop %s are inconsistent with a %s from %s. ",
+			analysis.AgentType, rec.TargetPrice, rec.StopPrice, rec.Action, entry)
+	}
+
+	// Fall back to fixed percentage levels
+	one := decimal.NewFromInt(1)
+	takeProfit := decimal.NewFromFloat(m.cfg.Agent.TakeProfitPercent)
+	stopLoss := decimal.NewFromFloat(m.cfg.Agent.StopLossPercent)
+	if rec.Action.Bearish() {
+		rec.TargetPrice = entry.Mul(one.Sub(takeProfit)).Round(2)
+		rec.StopPrice = entry.Mul(one.Add(stopLoss)).Round(2)
+	} else {
+		rec.TargetPrice = entry.Mul(one.Add(takeProfit)).Round(2)
+		rec.StopPrice = entry.Mul(one.Sub(stopLoss)).Round(2)
+	}
+	rec.RiskReward = 0
+}
+
+// enforceMinRiskReward downgrades a buy or short to hold when its risk/reward ratio is
+// below the configured minimum. Returns true if the recommendation was downgraded.
+func (m *PortfolioManager) enforceMinRiskReward(rec *models.Recommendation) bool {
+	minRR := m.cfg.Agent.MinRiskReward
+	opening := rec.Action == models.RecommendationActionBuy || rec.Action == models.RecommendationActionShort
+	if minRR <= 0 || !opening || rec.RiskReward <= 0 {
+		return false
+	}
+	if rec.RiskReward >= minRR {
+		return false
+	}
+
+	label := "Buy"
+	if rec.Action == models.RecommendationActionShort {
+		label = "Short"
+	}
+	rec.Action = models.RecommendationActionHold
+	rec.Reasoning += fmt.Sprintf("%s downgraded to hold: risk/reward %.2f is below the minimum of %.2f. ", label, rec.RiskReward, minRR)
+	return true
+}
+
+// dataFloat reads a positive numeric value from an analysis data map
+func dataFloat(data map[string]interface{}, key string) (float64, bool) {
+	if data == nil {
+		return 0, false
+	}
+	switch v := data[key].(type) {
+	case float64:
+		return v, v > 0
+	case int:
+		return float64(v), v > 0
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,196 @@
+package agents
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestApplyPriceLevels_FallbackPercentages(t *testing.T) {
+	manager := NewPortfolioManager(nil, testConfig(), newMockAccountProvider())
+
+	tests := []struct {
+		name       string
+		action     models.RecommendationAction
+		wantTarget string
+		wantStop   string
+		wantRR     float64
+	}{
+		{"buy", models.RecommendationActionBuy, "110", "95", 0},
+		{"sell", models.RecommendationActionSell, "90", "105", 0},
+		{"hold", models.RecommendationActionHold, "0", "0", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := models.NewRecommendation("AAPL", tt.action, "")
+			manager.applyPriceLevels(rec, decimal.NewFromInt(100), nil)
+
+			if !rec.EntryPrice.Equal(decimal.NewFromInt(100)) {
+				t.Errorf("EntryPrice = %s, want 100", rec.EntryPrice)
+			}
+			if !rec.TargetPrice.Equal(decimal.RequireFromString(tt.wantTarget)) {
+				t.Errorf("TargetPrice = %s, want %s", rec.TargetPrice, tt.wantTarget)
+			}
+			if !rec.StopPrice.Equal(decimal.RequireFromString(tt.wantStop)) {
+				t.Errorf("StopPrice = %s, want %s", rec.StopPrice, tt.wantStop)
+			}
+			if rec.RiskReward != tt.wantRR {
+				t.Errorf("RiskReward = %v, want %v", rec.RiskReward, tt.wantRR)
+			}
+		})
+	}
+}
+
+func TestApplyPriceLevels_AgentLevels(t *testing.T) {
+	manager := NewPortfolioManager(nil, testConfig(), newMockAccountProvider())
+
+	analyses := []*Analysis{
+		{AgentType: models.AgentTypeFundamental},
+		{
+			AgentType: models.AgentTypeTechnical,
+			Data: map[string]interface{}{
+				"target_price": 130.0,
+				"stop_price":   90.0,
+			},
+		},
+	}
+
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "")
+	manager.applyPriceLevels(rec, decimal.NewFromInt(100), analyses)
+
+	if !rec.TargetPrice.Equal(decimal.NewFromInt(130)) {
+		t.Errorf("TargetPrice = %s, want 130", rec.TargetPrice)
+	}
+	if rec.RiskReward != 3.0 {
+		t.Errorf("RiskReward = %v, want 3", rec.RiskReward)
+	}
+}
+
+func TestApplyPriceLevels_InconsistentAgentLevelsAreReported(t *testing.T) {
+	manager := NewPortfolioManager(nil, testConfig(), newMockAccountProvider())
+
+	// Bearish levels on a buy should be ignored
+	analyses := []*Analysis{
+		{
+			AgentType: models.AgentTypeTechnical,
+			Data: map[string]interface{}{
+				"target_price": 80.0,
+				"stop_price":   110.0,
+			},
+		},
+	}
+
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "")
+	manager.applyPriceLevels(rec, decimal.NewFromInt(100), analyses)
+
+	if rec.RiskReward != 0 {
+		t.Errorf("RiskReward = %v, want unknown (0) with only fallback levels", rec.RiskReward)
+	}
+	if !strings.Contains(rec.Reasoning, "Ignored technical price levels") {
+		t.Errorf("expected reasoning to report the ignored levels, got %q", rec.Reasoning)
+	}
+}
+
+func TestSynthesizeRecommendation_FallbackLevelsNotGated(t *testing.T) {
+	cfg := testConfig()
+	cfg.Agent.MinRiskReward = 3.0
+	manager := NewPortfolioManager(nil, cfg, newMockAccountProvider())
+
+	analyses := []*Analysis{
+		{Symbol: "AAPL", AgentType: models.AgentTypeFundamental, Score: 80, Confidence: 90, Reasoning: "Strong"},
+		{Symbol: "AAPL", AgentType: models.AgentTypeTechnical, Score: 60, Confidence: 80, Reasoning: "Bullish"},
+	}
+
+	rec := manager.synthesizeRecommendation(context.Background(), "AAPL", analyses, nil)
+
+	if rec.Action != models.RecommendationActionBuy {
+		t.Errorf("Action = %v, want buy when no agent supplied levels", rec.Action)
+	}
+	if rec.RiskReward != 0 {
+		t.Errorf("RiskReward = %v, want unknown (0)", rec.RiskReward)
+	}
+}
+
+func TestApplyPriceLevels_NoEntryPrice(t *testing.T) {
+	manager := NewPortfolioManager(nil, testConfig(), newMockAccountProvider())
+
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "")
+	manager.applyPriceLevels(rec, decimal.Zero, nil)
+
+	if !rec.EntryPrice.IsZero() || rec.RiskReward != 0 {
+		t.Errorf("expected no levels without an entry price, got entry=%s rr=%v", rec.EntryPrice, rec.RiskReward)
+	}
+}
+
+func TestEnforceMinRiskReward(t *testing.T) {
+	tests := []struct {
+		name          string
+		action        models.RecommendationAction
+		riskReward    float64
+		minRR         float64
+		wantDowngrade bool
+	}{
+		{"buy above minimum", models.RecommendationActionBuy, 2.0, 1.5, false},
+		{"buy below minimum", models.RecommendationActionBuy, 1.2, 1.5, true},
+		{"buy without ratio", models.RecommendationActionBuy, 0, 1.5, false},
+		{"gate disabled", models.RecommendationActionBuy, 1.2, 0, false},
+		{"sell not gated", models.RecommendationActionSell, 0.5, 1.5, false},
+		{"short below minimum", models.RecommendationActionShort, 1.2, 1.5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Agent.MinRiskReward = tt.minRR
+			manager := NewPortfolioManager(nil, cfg, newMockAccountProvider())
+
+			rec := models.NewRecommendation("AAPL", tt.action, "")
+			rec.RiskReward = tt.riskReward
+
+			got := manager.enforceMinRiskReward(rec)
+			if got != tt.wantDowngrade {
+				t.Errorf("enforceMinRiskReward() = %v, want %v", got, tt.wantDowngrade)
+			}
+			if tt.wantDowngrade {
+				if rec.Action != models.RecommendationActionHold {
+					t.Errorf("Action = %v, want hold", rec.Action)
+				}
+				if !strings.Contains(rec.Reasoning, "risk/reward") {
+					t.Errorf("expected reasoning to explain downgrade, got %q", rec.Reasoning)
+				}
+			}
+		})
+	}
+}
+
+func TestSynthesizeRecommendation_GatesLowRiskReward(t *testing.T) {
+	cfg := testConfig()
+	cfg.Agent.MinRiskReward = 3.0
+	manager := NewPortfolioManager(nil, cfg, newMockAccountProvider())
+
+	analyses := []*Analysis{
+		{Symbol: "AAPL", AgentType: models.AgentTypeFundamental, Score: 80, Confidence: 90, Reasoning: "Strong"},
+		{Symbol: "AAPL", AgentType: models.AgentTypeNews, Score: 70, Confidence: 85, Reasoning: "Positive"},
+		{
+			Symbol: "AAPL", AgentType: models.AgentTypeTechnical, Score: 60, Confidence: 80, Reasoning: "Bullish",
+			Data: map[string]interface{}{"target_price": 110.0, "stop_price": 95.0},
+		},
+	}
+
+	rec := manager.synthesizeRecommendation(context.Background(), "AAPL", analyses, nil)
+
+	if rec.Action != models.RecommendationActionHold {
+		t.Errorf("Action = %v, want hold when risk/reward %.2f is below 3.0", rec.Action, rec.RiskReward)
+	}
+	if !rec.Quantity.IsZero() {
+		t.Errorf("Quantity = %s, want 0 for downgraded buy", rec.Quantity)
+	}
+	if rec.RiskReward != 2.0 {
+		t.Errorf("RiskReward = %v, want 2 from the technical levels", rec.RiskReward)
+	}
+}
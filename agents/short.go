@@ -0,0 +1,84 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"trade-machine/models"
+)
+
+// ShortAvailabilityProvider is implemented by account providers that can report whether
+// a symbol's shares can be borrowed for a short sale
+type ShortAvailabilityProvider interface {
+	GetShortAvailability(ctx context.Context, symbol string) (*models.ShortAvailability, error)
+}
+
+// resolveShortAction adjusts a strategy's buy or sell for the account's position in the
+// symbol. A buy against an open short becomes a cover. A sell with no long position becomes
+// a short when shorts are allowed, or a hold if the broker cannot borrow the shares. An
+// error is returned, with the action unchanged, when the position can't be looked up for a
+// reason other than the symbol not being held.
+func (m *PortfolioManager) resolveShortAction(ctx context.Context, rec *models.Recommendation) error {
+	if rec.Action != models.RecommendationActionBuy && rec.Action != models.RecommendationActionSell {
+		return nil
+	}
+
+	// Brokers report a symbol not held as ErrPositionNotFound, which means flat
+	position, err := m.accountProvider.GetPosition(ctx, rec.Symbol)
+	if err != nil && !errors.Is(err, models.ErrPositionNotFound) {
+		return fmt.Errorf("failed to look up the %s position: %w", rec.Symbol, err)
+	}
+	if err != nil || position == nil || !position.Quantity.IsPositive() {
+		position = nil
+	}
+
+	if rec.Action == models.RecommendationActionBuy {
+		if position != nil && positionSide(position) == models.PositionSideShort {
+			rec.Action = models.RecommendationActionCover
+			rec.Reasoning += "Covers the existing short position. "
+		}
+		return nil
+	}
+
+	if !m.cfg.PositionSizing.AllowShorts || (position != nil && positionSide(position) == models.PositionSideLong) {
+		return nil
+	}
+
+	if reason := m.shortBlocked(ctx, rec.Symbol); reason != "" {
+		rec.Action = models.RecommendationActionHold
+		rec.Reasoning += "Short downgraded to hold: " + reason + ". "
+		return nil
+	}
+	rec.Action = models.RecommendationActionShort
+	rec.Reasoning += "No long position to sell; recommending a short. "
+	return nil
+}
+
+// shortBlocked returns why a symbol cannot be shorted, or "" if it can. Providers that
+// cannot report borrow availability are assumed to allow the short; the broker has the
+// final say when the order is placed.
+func (m *PortfolioManager) shortBlocked(ctx context.Context, symbol string) string {
+	provider, ok := m.accountProvider.(ShortAvailabilityProvider)
+	if !ok {
+		return ""
+	}
+
+	availability, err := provider.GetShortAvailability(ctx, symbol)
+	if err != nil {
+		logger.Warn("failed to check short availability",
+			"symbol", symbol,
+			"error", err)
+		return "borrow availability unknown"
+	}
+	if availability == nil {
+		return ""
+	}
+	if err := availability.Check(m.cfg.PositionSizing.AllowHardToBorrow); err != nil {
+		if !availability.Shortable {
+			return "shares are not available to borrow"
+		}
+		return "shares are hard to borrow"
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// shortAccountProvider adds borrow availability to mockAccountProvider
+type shortAccountProvider struct {
+	*mockAccountProvider
+	availability *models.ShortAvailability
+	err          error
+}
+
+func (p *shortAccountProvider) GetShortAvailability(ctx context.Context, symbol string) (*models.ShortAvailability, error) {
+	return p.availability, p.err
+}
+
+func TestPortfolioManager_ResolveShortAction(t *testing.T) {
+	long := &models.Position{Symbol: "TSLA", Quantity: decimal.NewFromInt(10), Side: models.PositionSideLong}
+	short := &models.Position{Symbol: "TSLA", Quantity: decimal.NewFromInt(10), Side: models.PositionSideShort}
+	easy := &models.ShortAvailability{Symbol: "TSLA", Shortable: true, EasyToBorrow: true}
+	hard := &models.ShortAvailability{Symbol: "TSLA", Shortable: true}
+
+	tests := []struct {
+		name         string
+		action       models.RecommendationAction
+		allowShorts  bool
+		allowHTB     bool
+		position     *models.Position
+		availability *models.ShortAvailability
+		err          error
+		want         models.RecommendationAction
+	}{
+		{"sell with long position", models.RecommendationActionSell, true, false, long, easy, nil, models.RecommendationActionSell},
+		{"sell when shorts disabled", models.RecommendationActionSell, false, false, nil, easy, nil, models.RecommendationActionSell},
+		{"sell while flat", models.RecommendationActionSell, true, false, nil, easy, nil, models.RecommendationActionShort},
+		{"sell adds to short", models.RecommendationActionSell, true, false, short, easy, nil, models.RecommendationActionShort},
+		{"hard to borrow", models.RecommendationActionSell, true, false, nil, hard, nil, models.RecommendationActionHold},
+		{"hard to borrow allowed", models.RecommendationActionSell, true, true, nil, hard, nil, models.RecommendationActionShort},
+		{"not shortable", models.RecommendationActionSell, true, true, nil, &models.ShortAvailability{Symbol: "TSLA"}, nil, models.RecommendationActionHold},
+		{"availability lookup fails", models.RecommendationActionSell, true, false, nil, nil, errors.New("broker down"), models.RecommendationActionHold},
+		{"buy covers short", models.RecommendationActionBuy, false, false, short, easy, nil, models.RecommendationActionCover},
+		{"buy with long position", models.RecommendationActionBuy, true, false, long, easy, nil, models.RecommendationActionBuy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.PositionSizing.AllowShorts = tt.allowShorts
+			cfg.PositionSizing.AllowHardToBorrow = tt.allowHTB
+			accounts := newMockAccountProvider()
+			accounts.position = tt.position
+			provider := &shortAccountProvider{mockAccountProvider: accounts, availability: tt.availability, err: tt.err}
+			manager := NewPortfolioManager(nil, cfg, provider)
+
+			rec := models.NewRecommendation("TSLA", tt.action, "")
+			if err := manager.resolveShortAction(context.Background(), rec); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Action != tt.want {
+				t.Errorf("Action = %v, want %v (reasoning %q)", rec.Action, tt.want, rec.Reasoning)
+			}
+		})
+	}
+}
+
+func TestPortfolioManager_ResolveShortAction_WithoutAvailabilityProvider(t *testing.T) {
+	cfg := testConfig()
+	cfg.PositionSizing.AllowShorts = true
+	manager := NewPortfolioManager(nil, cfg, newMockAccountProvider())
+
+	rec := models.NewRecommendation("TSLA", models.RecommendationActionSell, "")
+	manager.resolveShortAction(context.Background(), rec)
+	if rec.Action != models.RecommendationActionShort {
+		t.Errorf("Action = %v, want short", rec.Action)
+	}
+}
+
+// positionErrProvider fails position lookups with err
+type positionErrProvider struct {
+	*mockAccountProvider
+	err error
+}
+
+func (p *positionErrProvider) GetPosition(ctx context.Context, symbol string) (*models.Position, error) {
+	return nil, p.err
+}
+
+func TestPortfolioManager_ResolveShortAction_PositionLookup(t *testing.T) {
+	cfg := testConfig()
+	cfg.PositionSizing.AllowShorts = true
+
+	notHeld := NewPortfolioManager(nil, cfg, &positionErrProvider{newMockAccountProvider(), fmt.Errorf("failed to get position for TSLA: %w", models.ErrPositionNotFound)})
+	rec := models.NewRecommendation("TSLA", models.RecommendationActionSell, "")
+	if err := notHeld.resolveShortAction(context.Background(), rec); err != nil || rec.Action != models.RecommendationActionShort {
+		t.Errorf("Action = %v, error = %v, want a short while flat", rec.Action, err)
+	}
+
+	down := NewPortfolioManager(nil, cfg, &positionErrProvider{newMockAccountProvider(), errors.New("broker down")})
+	rec = models.NewRecommendation("TSLA", models.RecommendationActionSell, "")
+	if err := down.resolveShortAction(context.Background(), rec); err == nil || rec.Action != models.RecommendationActionSell {
+		t.Errorf("Action = %v, error = %v, want an error and the sell unchanged", rec.Action, err)
+	}
+}
@@ -0,0 +1,188 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/services"
+)
+
+const socialSystemPrompt = `You are a market analyst who reads retail investor chatter about a stock.
+Your job is to judge the crowd's sentiment from recent Reddit posts and StockTwits messages.
+
+Each post shows its source, its upvotes or likes, and, for StockTwits, the bullish or bearish
+label its author chose. Posts with more engagement reflect more of the crowd and should count more.
+
+Provide your analysis in the following JSON format:
+{
+  "score": <number from -100 to 100, negative=bearish crowd, positive=bullish crowd>,
+  "confidence": <number from 0 to 100>,
+  "reasoning": "<brief explanation of the crowd's mood and what drives it>",
+  "key_themes": ["<theme1>", "<theme2>", "<theme3>"]
+}
+
+Consider:
+- Sarcasm, memes and hype are common; judge what the author expects the price to do
+- Many posts repeating one rumor are one signal, not many
+- Few posts or mixed opinions mean low confidence
+
+Retail sentiment is a crowd signal, not a fundamental view; score the mood, not the company.`
+
+// socialPostLimit is the number of posts fetched from each source per analysis
+const socialPostLimit = 25
+
+// socialMinPosts is the number of posts below which the crowd is too thin to score
+const socialMinPosts = 3
+
+// SocialAnalystResponse is the expected response from the LLM
+type SocialAnalystResponse struct {
+	Score      float64  `json:"score"`
+	Confidence float64  `json:"confidence"`
+	Reasoning  string   `json:"reasoning"`
+	KeyThemes  []string `json:"key_themes"`
+}
+
+// SocialSentimentAnalyst scores retail sentiment from Reddit and StockTwits
+type SocialSentimentAnalyst struct {
+	llm         LLMService
+	social      SocialSentimentServiceInterface
+	healthCache *HealthCache
+}
+
+// NewSocialSentimentAnalyst creates a new SocialSentimentAnalyst
+func NewSocialSentimentAnalyst(llm LLMService, social SocialSentimentServiceInterface) *SocialSentimentAnalyst {
+	return &SocialSentimentAnalyst{
+		llm:         llm,
+		social:      social,
+		healthCache: NewHealthCache(DefaultHealthCacheTTL),
+	}
+}
+
+// Analyze scores the crowd's sentiment on a stock. When the LLM's answer can't be parsed,
+// the share of StockTwits messages labeled bullish versus bearish is used instead.
+func (a *SocialSentimentAnalyst) Analyze(ctx context.Context, symbol string) (*Analysis, error) {
+	posts, err := a.social.GetPosts(ctx, symbol, socialPostLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch social posts: %w", err)
+	}
+
+	labelScore, labeled := models.SocialLabelScore(posts)
+	data := map[string]interface{}{
+		"posts_count":   len(posts),
+		"labeled_count": labeled,
+		"label_score":   labelScore,
+	}
+
+	if len(posts) < socialMinPosts {
+		return &Analysis{
+			Symbol:     symbol,
+			AgentType:  models.AgentTypeSocial,
+			Score:      0,
+			Confidence: 20,
+			Reasoning:  fmt.Sprintf("Too little social chatter to judge sentiment (%d posts)", len(posts)),
+			Data:       data,
+			Timestamp:  time.Now(),
+		}, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Judge the crowd's sentiment on %s from these recent posts:\n\n", symbol))
+	for i, post := range posts {
+		label := ""
+		if post.Sentiment != "" {
+			label = " | Label: " + string(post.Sentiment)
+		}
+		sb.WriteString(fmt.Sprintf("%d. [%s | Engagement: %d%s | %s]\n   %s\n\n",
+			i+1, post.Source, post.Score, label, post.CreatedAt.Format("Jan 2 15:04"), post.Text))
+	}
+	sb.WriteString("Provide your sentiment analysis.")
+
+	response, err := invokeLLM(ctx, a.llm, socialSystemPrompt, sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to invoke LLM: %w", err)
+	}
+
+	var result SocialAnalystResponse
+	if err := services.ParseStructuredOutput(response, &result); err != nil {
+		data["raw_response"] = response
+		confidence := 20.0
+		if labeled >= socialMinPosts {
+			confidence = 40
+		}
+		return &Analysis{
+			Symbol:     symbol,
+			AgentType:  models.AgentTypeSocial,
+			Score:      NormalizeScore(labelScore),
+			Confidence: confidence,
+			Reasoning:  fmt.Sprintf("Scored from StockTwits labels: %d of %d posts marked bullish or bearish", labeled, len(posts)),
+			Data:       data,
+			Timestamp:  time.Now(),
+		}, nil
+	}
+
+	data["key_themes"] = result.KeyThemes
+	return &Analysis{
+		Symbol:     symbol,
+		AgentType:  models.AgentTypeSocial,
+		Score:      NormalizeScore(result.Score),
+		Confidence: NormalizeConfidence(result.Confidence),
+		Reasoning:  result.Reasoning,
+		Data:       data,
+		Timestamp:  time.Now(),
+	}, nil
+}
+
+// ReplayPrompt sends a past analysis' user prompt with the current system prompt
+func (a *SocialSentimentAnalyst) ReplayPrompt(ctx context.Context, symbol, userPrompt string) (*Analysis, error) {
+	return replayPrompt(ctx, a.llm, a.Type(), symbol, socialSystemPrompt, userPrompt)
+}
+
+// Name returns the agent name
+func (a *SocialSentimentAnalyst) Name() string {
+	return "Social Sentiment Analyst"
+}
+
+// Type returns the agent type
+func (a *SocialSentimentAnalyst) Type() models.AgentType {
+	return models.AgentTypeSocial
+}
+
+// IsAvailable checks if the agent's dependencies are healthy.
+// Results are cached to reduce API calls during frequent availability checks.
+func (a *SocialSentimentAnalyst) IsAvailable(ctx context.Context) bool {
+	if available, decided := breakerAvailability(a.Degradation()); decided {
+		return available
+	}
+	if available, valid := a.healthCache.Get(); valid {
+		return available
+	}
+
+	_, err := a.social.GetPosts(ctx, "AAPL", 1)
+	available := err == nil
+	a.healthCache.Set(available)
+	return available
+}
+
+// Degradation returns the degradation level of the agent's LLM provider, or of its
+// social sources when both are degraded; one source alone still yields posts
+func (a *SocialSentimentAnalyst) Degradation() services.DegradationLevel {
+	sources := min(services.BreakerLevel(services.BreakerReddit), services.BreakerLevel(services.BreakerStockTwits))
+	return max(sources, llmLevel(a.llm, models.AgentTypeSocial))
+}
+
+// InvalidateHealthCache clears the health cache, forcing the next check to make a live call.
+func (a *SocialSentimentAnalyst) InvalidateHealthCache() {
+	a.healthCache.Invalidate()
+}
+
+// GetMetadata returns information about this agent's capabilities
+func (a *SocialSentimentAnalyst) GetMetadata() AgentMetadata {
+	return AgentMetadata{
+		Description:      "Scores retail investor sentiment from Reddit posts and StockTwits messages",
+		Version:          "1.0.0",
+		RequiredServices: []string{"llm", "reddit", "stocktwits"},
+	}
+}
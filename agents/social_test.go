@@ -0,0 +1,109 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+)
+
+func socialPosts(bullish, bearish, unlabeled int) []models.SocialPost {
+	var posts []models.SocialPost
+	add := func(n int, sentiment models.SocialSentiment) {
+		for i := 0; i < n; i++ {
+			posts = append(posts, models.SocialPost{Source: "stocktwits", Text: "$AAPL", Sentiment: sentiment, CreatedAt: time.Now()})
+		}
+	}
+	add(bullish, models.SocialSentimentBullish)
+	add(bearish, models.SocialSentimentBearish)
+	add(unlabeled, "")
+	return posts
+}
+
+func TestSocialSentimentAnalyst_Type(t *testing.T) {
+	analyst := &SocialSentimentAnalyst{}
+	if analyst.Type() != models.AgentTypeSocial {
+		t.Errorf("Type() = %v, want AgentTypeSocial", analyst.Type())
+	}
+}
+
+func TestSocialSentimentAnalyst_Analyze(t *testing.T) {
+	llm := &mockLLMService{response: `{"score": 55, "confidence": 65, "reasoning": "Crowd expects an earnings beat", "key_themes": ["earnings"]}`}
+	analyst := NewSocialSentimentAnalyst(llm, &mockSocialService{posts: socialPosts(3, 1, 2)})
+
+	analysis, err := analyst.Analyze(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if analysis.AgentType != models.AgentTypeSocial || analysis.Score != 55 || analysis.Confidence != 65 {
+		t.Errorf("unexpected analysis %+v", analysis)
+	}
+	if analysis.Data["labeled_count"] != 4 || analysis.Data["label_score"] != 50.0 {
+		t.Errorf("unexpected label data %v", analysis.Data)
+	}
+}
+
+func TestSocialSentimentAnalyst_Analyze_LabelFallback(t *testing.T) {
+	llm := &mockLLMService{response: "the crowd is excited"}
+	analyst := NewSocialSentimentAnalyst(llm, &mockSocialService{posts: socialPosts(1, 3, 0)})
+
+	analysis, err := analyst.Analyze(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if analysis.Score != -50 {
+		t.Errorf("Score = %v, want -50 from one bullish and three bearish labels", analysis.Score)
+	}
+	if !strings.Contains(analysis.Reasoning, "StockTwits labels") {
+		t.Errorf("expected the fallback to be explained, got %q", analysis.Reasoning)
+	}
+}
+
+func TestSocialSentimentAnalyst_Analyze_ThinChatter(t *testing.T) {
+	analyst := NewSocialSentimentAnalyst(&mockLLMService{err: errors.New("should not be called")}, &mockSocialService{posts: socialPosts(1, 0, 0)})
+
+	analysis, err := analyst.Analyze(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if analysis.Score != 0 || analysis.Confidence != 20 {
+		t.Errorf("expected a neutral low-confidence score, got %v at %v", analysis.Score, analysis.Confidence)
+	}
+}
+
+func TestSocialSentimentAnalyst_Analyze_SourcesDown(t *testing.T) {
+	analyst := NewSocialSentimentAnalyst(&mockLLMService{}, &mockSocialService{err: errors.New("both down")})
+	if _, err := analyst.Analyze(context.Background(), "AAPL"); err == nil {
+		t.Error("expected an error when no posts can be fetched")
+	}
+}
+
+func TestPortfolioManager_SynthesizeRecommendation_Social(t *testing.T) {
+	cfg := testConfig()
+	cfg.Agent.WeightFundamental = 0.3
+	cfg.Agent.WeightNews = 0.2
+	cfg.Agent.WeightSocial = 0.2
+	manager := NewPortfolioManager(nil, cfg, newMockAccountProvider())
+	manager.RegisterAgent(NewSocialSentimentAnalyst(&mockLLMService{}, &mockSocialService{}))
+
+	analyses := []*Analysis{
+		{AgentType: models.AgentTypeFundamental, Score: 40, Confidence: 80},
+		{AgentType: models.AgentTypeNews, Score: 40, Confidence: 80},
+		{AgentType: models.AgentTypeTechnical, Score: 40, Confidence: 80},
+		{AgentType: models.AgentTypeSocial, Score: -60, Confidence: 80},
+	}
+	rec := manager.synthesizeRecommendation(context.Background(), "AAPL", analyses, nil)
+
+	if rec.SocialScore != -60 {
+		t.Errorf("SocialScore = %v, want -60", rec.SocialScore)
+	}
+	if rec.DataCompleteness != 100 {
+		t.Errorf("DataCompleteness = %v, want 100 with all four agents", rec.DataCompleteness)
+	}
+	if !strings.Contains(rec.Reasoning, "Social: -60. Overall score: 20.0") {
+		t.Errorf("expected the social score to pull the overall score to 20, got %q", rec.Reasoning)
+	}
+}
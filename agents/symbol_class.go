@@ -0,0 +1,32 @@
+package agents
+
+import (
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// classifySymbol resolves the symbol class for an analysis, taking market cap from the
+// fundamental agent's data. Returns an empty class when an equity's market cap is unknown.
+func classifySymbol(symbol string, analyses []*Analysis) models.SymbolClass {
+	marketCap := decimal.Zero
+	for _, analysis := range analyses {
+		if analysis.AgentType != models.AgentTypeFundamental {
+			continue
+		}
+		if f, ok := analysis.Data["fundamentals"].(*models.Fundamentals); ok && f != nil {
+			marketCap = f.MarketCap
+		}
+	}
+	return models.ClassifySymbol(symbol, marketCap)
+}
+
+// strategyFor returns the strategy configured for a symbol class, falling back to the
+// global strategy when the class has no thresholds of its own
+func (m *PortfolioManager) strategyFor(class models.SymbolClass) (ActionStrategy, bool) {
+	t, ok := m.cfg.Agent.ClassThresholds[string(class)]
+	if class == "" || !ok {
+		return m.GetStrategy(), false
+	}
+	return NewCustomStrategy(t.BuyThreshold, t.SellThreshold, t.MinConfidence), true
+}
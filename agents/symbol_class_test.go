@@ -0,0 +1,72 @@
+package agents
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"trade-machine/config"
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func fundamentalWithMarketCap(score float64, marketCap int64) *Analysis {
+	return &Analysis{
+		AgentType:  models.AgentTypeFundamental,
+		Score:      score,
+		Confidence: 80,
+		Data: map[string]interface{}{
+			"fundamentals": &models.Fundamentals{MarketCap: decimal.NewFromInt(marketCap)},
+		},
+	}
+}
+
+func TestClassifySymbol(t *testing.T) {
+	tests := []struct {
+		name     string
+		symbol   string
+		analyses []*Analysis
+		want     models.SymbolClass
+	}{
+		{"crypto pair", "BTC/USD", nil, models.SymbolClassCrypto},
+		{"mega cap", "AAPL", []*Analysis{fundamentalWithMarketCap(0, 3_000_000_000_000)}, models.SymbolClassMegaCap},
+		{"small cap", "TINY", []*Analysis{fundamentalWithMarketCap(0, 500_000_000)}, models.SymbolClassSmallCap},
+		{"no fundamentals", "AAPL", []*Analysis{{AgentType: models.AgentTypeNews}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifySymbol(tt.symbol, tt.analyses); got != tt.want {
+				t.Errorf("classifySymbol() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPortfolioManager_SynthesizeRecommendation_ClassThresholds(t *testing.T) {
+	cfg := testConfig()
+	cfg.Agent.ClassThresholds = map[string]config.ClassThreshold{
+		"small_cap": {BuyThreshold: 60, SellThreshold: -60},
+	}
+	manager := NewPortfolioManager(nil, cfg, newMockAccountProvider())
+
+	// A score of 40 clears the global +25 buy threshold but not the small-cap one
+	small := manager.synthesizeRecommendation(context.Background(), "TINY",
+		[]*Analysis{fundamentalWithMarketCap(40, 500_000_000)}, nil)
+	if small.Action != models.RecommendationActionHold {
+		t.Errorf("small cap action = %s, want hold", small.Action)
+	}
+	if !strings.Contains(small.Reasoning, "small_cap thresholds") {
+		t.Errorf("expected reasoning to mention small_cap thresholds, got %q", small.Reasoning)
+	}
+
+	mega := manager.synthesizeRecommendation(context.Background(), "AAPL",
+		[]*Analysis{fundamentalWithMarketCap(40, 3_000_000_000_000)}, nil)
+	if mega.Action != models.RecommendationActionBuy {
+		t.Errorf("mega cap action = %s, want buy", mega.Action)
+	}
+	if strings.Contains(mega.Reasoning, "thresholds") {
+		t.Errorf("expected global strategy for mega cap, got %q", mega.Reasoning)
+	}
+}
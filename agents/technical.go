@@ -2,31 +2,40 @@ package agents
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
 	"trade-machine/config"
+	"trade-machine/indicators"
 	"trade-machine/models"
+	"trade-machine/services"
 
 	marketdata "github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
 )
 
 const technicalSystemPrompt = `You are a financial analyst specializing in technical analysis.
 Your job is to analyze price action and technical indicators to predict short-term price movements.
 
-You will be given technical indicators including:
+You will be given technical indicators computed from daily bars, which you should use as
+given rather than estimate:
 - RSI (Relative Strength Index): <30 oversold, >70 overbought
 - MACD (Moving Average Convergence Divergence) and Signal line
-- SMA (Simple Moving Averages): 20-day and 50-day
+- SMA (Simple Moving Averages): 20, 50 and 200-day, and 12 and 26-day EMAs
+- Bollinger Bands: price near the upper or lower band is stretched; narrow bands precede breakouts
+- ATR (Average True Range): typical daily move, useful for sizing the distance to the stop
 - Recent price action
+- Short (2-week), medium (3-month), and long (1-year) timeframe scores computed from trend and
+  return over each window
 
 Based on these indicators, provide your analysis in the following JSON format:
 {
   "score": <number from -100 to 100, negative=bearish, positive=bullish>,
   "confidence": <number from 0 to 100>,
   "reasoning": "<brief explanation of your technical analysis>",
-  "signals": ["<signal1>", "<signal2>", "<signal3>"]
+  "signals": ["<signal1>", "<signal2>", "<signal3>"],
+  "target_price": <price target in the direction of your score, based on resistance or support>,
+  "stop_price": <price at which your view is invalidated, on the opposite side of the current price>
 }
 
 Consider:
@@ -39,12 +48,30 @@ Be objective and focus on actionable technical signals.`
 
 // TechnicalAnalystResponse is the expected response from Claude
 type TechnicalAnalystResponse struct {
-	Score      float64  `json:"score"`
-	Confidence float64  `json:"confidence"`
-	Reasoning  string   `json:"reasoning"`
-	Signals    []string `json:"signals"`
+	Score       float64  `json:"score"`
+	Confidence  float64  `json:"confidence"`
+	Reasoning   string   `json:"reasoning"`
+	Signals     []string `json:"signals"`
+	TargetPrice float64  `json:"target_price,omitempty"`
+	StopPrice   float64  `json:"stop_price,omitempty"`
 }
 
+// timeframeBarCalendarDays is the minimum history fetched so the long timeframe has a full
+// year of daily bars
+const timeframeBarCalendarDays = 380
+
+// timeframeSpec is the bar window for one timeframe and the return that scores ±100 over it
+type timeframeSpec struct {
+	bars  int
+	scale float64
+}
+
+var (
+	shortTimeframe  = timeframeSpec{bars: 10, scale: 0.05}
+	mediumTimeframe = timeframeSpec{bars: 63, scale: 0.15}
+	longTimeframe   = timeframeSpec{bars: 250, scale: 0.30}
+)
+
 // TechnicalAnalyst analyzes price action and technical indicators
 type TechnicalAnalyst struct {
 	llm LLMService
@@ -76,7 +103,7 @@ func NewTechnicalAnalystWithCacheTTL(llm LLMService, alpaca AlpacaServiceInterfa
 // Analyze performs technical analysis on a stock
 func (a *TechnicalAnalyst) Analyze(ctx context.Context, symbol string) (*Analysis, error) {
 	end := time.Now()
-	start := end.AddDate(0, 0, -a.lookbackDays)
+	start := end.AddDate(0, 0, -max(a.lookbackDays, timeframeBarCalendarDays))
 
 	bars, err := a.alpaca.GetBars(ctx, symbol, start, end, marketdata.OneDay)
 	if err != nil {
@@ -95,52 +122,73 @@ func (a *TechnicalAnalyst) Analyze(ctx context.Context, symbol string) (*Analysi
 		}, nil
 	}
 
-	closePrices := make([]float64, len(bars))
-	for i, bar := range bars {
-		closePrices[i] = bar.Close
-	}
-
-	indicators := a.calculateIndicators(closePrices)
+	closePrices := indicators.Closes(bars)
+	computed := indicators.Compute(symbol, bars)
+	high, low := priceRange(closePrices)
+	timeframes := calculateTimeframeScores(closePrices)
 	latestBar := bars[len(bars)-1]
-	userPrompt := fmt.Sprintf(`Analyze the following technical indicators for %s:
+	sma20, sma50 := computed.SMA20.InexactFloat64(), computed.SMA50.InexactFloat64()
+	atr := computed.ATR.InexactFloat64()
+	userPrompt := fmt.Sprintf(`Analyze the following technical indicators for %s, computed from daily bars:
 
 Current Price: $%.2f
-52-Day High: $%.2f
-52-Day Low: $%.2f
+52-Week High: $%.2f
+52-Week Low: $%.2f
 
 RSI (14-period): %.2f
-MACD: %.4f
-MACD Signal: %.4f
+MACD (12, 26): %.4f
+MACD Signal (9): %.4f
 MACD Histogram: %.4f
 
 SMA 20: $%.2f
 SMA 50: $%.2f
+SMA 200: %s
+EMA 12: $%.2f
+EMA 26: $%.2f
 
 Price vs SMA20: %.2f%%
 Price vs SMA50: %.2f%%
 
+Bollinger Bands (20, 2 std dev): upper $%.2f, lower $%.2f
+ATR (14-period): $%.2f (%.2f%% of price)
+
+Timeframe Scores (-100 to 100):
+Short (2 weeks): %s
+Medium (3 months): %s
+Long (1 year): %s
+
 Provide your technical analysis.`,
 		symbol,
 		latestBar.Close,
-		indicators["high"].(float64),
-		indicators["low"].(float64),
-		indicators["rsi"].(float64),
-		indicators["macd"].(float64),
-		indicators["macd_signal"].(float64),
-		indicators["macd_histogram"].(float64),
-		indicators["sma20"].(float64),
-		indicators["sma50"].(float64),
-		(latestBar.Close/indicators["sma20"].(float64)-1)*100,
-		(latestBar.Close/indicators["sma50"].(float64)-1)*100,
+		high,
+		low,
+		computed.RSI,
+		computed.MACD,
+		computed.MACDSignal,
+		computed.MACDHistogram,
+		sma20,
+		sma50,
+		formatIndicatorPrice(computed.SMA200),
+		computed.EMA12.InexactFloat64(),
+		computed.EMA26.InexactFloat64(),
+		(latestBar.Close/sma20-1)*100,
+		(latestBar.Close/sma50-1)*100,
+		computed.BollingerUpper.InexactFloat64(),
+		computed.BollingerLower.InexactFloat64(),
+		atr,
+		atr/latestBar.Close*100,
+		formatTimeframeScore(timeframes.Short),
+		formatTimeframeScore(timeframes.Medium),
+		formatTimeframeScore(timeframes.Long),
 	)
 
-	response, err := a.llm.InvokeWithPrompt(ctx, technicalSystemPrompt, userPrompt)
+	response, err := invokeLLM(ctx, a.llm, technicalSystemPrompt, userPrompt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to invoke bedrock: %w", err)
 	}
 
 	var result TechnicalAnalystResponse
-	if err := json.Unmarshal([]byte(response), &result); err != nil {
+	if err := services.ParseStructuredOutput(response, &result); err != nil {
 		return &Analysis{
 			Symbol:     symbol,
 			AgentType:  models.AgentTypeTechnical,
@@ -148,8 +196,10 @@ Provide your technical analysis.`,
 			Confidence: 50,
 			Reasoning:  response,
 			Data: map[string]interface{}{
-				"raw_response": response,
-				"indicators":   indicators,
+				"raw_response":     response,
+				"indicators":       computed,
+				"price_range":      map[string]float64{"high": high, "low": low},
+				"timeframe_scores": timeframes,
 			},
 			Timestamp: time.Now(),
 		}, nil
@@ -162,120 +212,107 @@ Provide your technical analysis.`,
 		Confidence: NormalizeConfidence(result.Confidence),
 		Reasoning:  result.Reasoning,
 		Data: map[string]interface{}{
-			"signals":    result.Signals,
-			"indicators": indicators,
+			"signals":          result.Signals,
+			"indicators":       computed,
+			"price_range":      map[string]float64{"high": high, "low": low},
+			"target_price":     result.TargetPrice,
+			"stop_price":       result.StopPrice,
+			"timeframe_scores": timeframes,
 		},
 		Timestamp: time.Now(),
 	}, nil
 }
 
-func (a *TechnicalAnalyst) calculateIndicators(prices []float64) map[string]interface{} {
-	result := make(map[string]interface{})
-
-	result["rsi"] = a.calculateRSI(prices, 14)
-
-	sma20 := a.calculateSMA(prices, 20)
-	sma50 := a.calculateSMA(prices, 50)
-	result["sma20"] = sma20
-	result["sma50"] = sma50
-
-	ema12 := a.calculateEMA(prices, 12)
-	ema26 := a.calculateEMA(prices, 26)
+// priceRange returns the highest and lowest close
+func priceRange(prices []float64) (high, low float64) {
+	high, low = prices[0], prices[0]
+	for _, p := range prices {
+		high = max(high, p)
+		low = min(low, p)
+	}
+	return high, low
+}
 
-	macdLine := make([]float64, len(prices))
-	for i := range prices {
-		macdLine[i] = ema12[i] - ema26[i]
+// calculateTimeframeScores scores the short, medium, and long timeframes from the most recent
+// bars of each window. Timeframes without a full window of history are left unscored.
+func calculateTimeframeScores(prices []float64) models.TimeframeScores {
+	return models.TimeframeScores{
+		Short:  scoreTimeframe(prices, shortTimeframe),
+		Medium: scoreTimeframe(prices, mediumTimeframe),
+		Long:   scoreTimeframe(prices, longTimeframe),
 	}
+}
 
-	signalLine := a.calculateEMA(macdLine, 9)
-
-	if len(macdLine) > 0 && len(signalLine) > 0 {
-		macd := macdLine[len(macdLine)-1]
-		signal := signalLine[len(signalLine)-1]
-		result["macd"] = macd
-		result["macd_signal"] = signal
-		result["macd_histogram"] = macd - signal
-	} else {
-		result["macd"] = 0.0
-		result["macd_signal"] = 0.0
-		result["macd_histogram"] = 0.0
+// TechnicalSignal scores a close series the way the technical analyst's timeframe scores
+// do, without the LLM, so past days can be replayed. The confidence (0-100) is the share
+// of scored timeframes pointing the same way as the combined score. Returns false until
+// there is enough history to score a timeframe the horizon weights.
+func TechnicalSignal(prices []float64, horizon models.AnalysisHorizon) (score, confidence float64, ok bool) {
+	timeframes := calculateTimeframeScores(prices)
+	score, ok = timeframes.Weighted(horizon)
+	if !ok {
+		return 0, 0, false
 	}
 
-	high, low := prices[0], prices[0]
-	for _, p := range prices {
-		if p > high {
-			high = p
+	var scored, agreeing int
+	for _, tf := range []*float64{timeframes.Short, timeframes.Medium, timeframes.Long} {
+		if tf == nil {
+			continue
 		}
-		if p < low {
-			low = p
+		scored++
+		if (*tf >= 0) == (score >= 0) {
+			agreeing++
 		}
 	}
-	result["high"] = high
-	result["low"] = low
-
-	return result
+	return score, 100 * float64(agreeing) / float64(scored), true
 }
 
-func (a *TechnicalAnalyst) calculateRSI(prices []float64, period int) float64 {
-	if len(prices) < period+1 {
-		return 50.0 // neutral
+// scoreTimeframe blends the return over the window with the price's distance from the
+// window's average, each contributing up to ±50
+func scoreTimeframe(prices []float64, spec timeframeSpec) *float64 {
+	if len(prices) < spec.bars+1 {
+		return nil
 	}
-
-	var gains, losses float64
-	for i := 1; i <= period; i++ {
-		change := prices[len(prices)-i] - prices[len(prices)-i-1]
-		if change > 0 {
-			gains += change
-		} else {
-			losses -= change
-		}
+	window := prices[len(prices)-spec.bars-1:]
+	first, last := window[0], window[len(window)-1]
+	if first <= 0 {
+		return nil
 	}
 
-	avgGain := gains / float64(period)
-	avgLoss := losses / float64(period)
-
-	if avgLoss == 0 {
-		return 100.0
+	mean := 0.0
+	for _, p := range window[1:] {
+		mean += p
 	}
+	mean /= float64(spec.bars)
 
-	rs := avgGain / avgLoss
-	rsi := 100 - (100 / (1 + rs))
-	return rsi
+	momentum := clampUnit((last/first - 1) / spec.scale)
+	trend := clampUnit((last/mean - 1) / (spec.scale / 2))
+	score := NormalizeScore(50*momentum + 50*trend)
+	return &score
 }
 
-func (a *TechnicalAnalyst) calculateSMA(prices []float64, period int) float64 {
-	if len(prices) < period {
-		return 0
-	}
-	sum := 0.0
-	for i := len(prices) - period; i < len(prices); i++ {
-		sum += prices[i]
-	}
-	return sum / float64(period)
+func clampUnit(v float64) float64 {
+	return max(-1, min(1, v))
 }
 
-func (a *TechnicalAnalyst) calculateEMA(prices []float64, period int) []float64 {
-	if len(prices) < period {
-		return prices
-	}
-
-	ema := make([]float64, len(prices))
-	multiplier := 2.0 / float64(period+1)
-
-	// Start with SMA for first EMA value
-	sum := 0.0
-	for i := 0; i < period; i++ {
-		sum += prices[i]
-		ema[i] = prices[i] // placeholder
+// formatIndicatorPrice formats an indicator that is zero without enough history
+func formatIndicatorPrice(v decimal.Decimal) string {
+	if v.IsZero() {
+		return "n/a (insufficient history)"
 	}
-	ema[period-1] = sum / float64(period)
+	return "$" + v.StringFixed(2)
+}
 
-	// Calculate EMA for rest
-	for i := period; i < len(prices); i++ {
-		ema[i] = (prices[i]-ema[i-1])*multiplier + ema[i-1]
+func formatTimeframeScore(score *float64) string {
+	if score == nil {
+		return "n/a (insufficient history)"
 	}
+	return fmt.Sprintf("%.1f", *score)
+}
 
-	return ema
+// ReplayPrompt sends a past analysis' user prompt with the current system prompt
+func (a *TechnicalAnalyst) ReplayPrompt(ctx context.Context, symbol, userPrompt string) (*Analysis, error) {
+	return replayPrompt(ctx, a.llm, a.Type(), symbol, technicalSystemPrompt, userPrompt)
 }
 
 // Name returns the agent name
@@ -291,6 +328,9 @@ func (a *TechnicalAnalyst) Type() models.AgentType {
 // IsAvailable checks if the agent's dependencies are healthy.
 // Results are cached to reduce API calls during frequent availability checks.
 func (a *TechnicalAnalyst) IsAvailable(ctx context.Context) bool {
+	if available, decided := breakerAvailability(a.Degradation()); decided {
+		return available
+	}
 	if available, valid := a.healthCache.Get(); valid {
 		return available
 	}
@@ -303,6 +343,11 @@ func (a *TechnicalAnalyst) IsAvailable(ctx context.Context) bool {
 	return available
 }
 
+// Degradation returns the worst degradation level of the agent's data and LLM providers
+func (a *TechnicalAnalyst) Degradation() services.DegradationLevel {
+	return max(providerLevel(services.BreakerAlpaca), llmLevel(a.llm, models.AgentTypeTechnical))
+}
+
 // InvalidateHealthCache clears the health cache, forcing the next check to make a live call.
 func (a *TechnicalAnalyst) InvalidateHealthCache() {
 	a.healthCache.Invalidate()
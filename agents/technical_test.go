@@ -3,6 +3,8 @@ package agents
 import (
 	"context"
 	"errors"
+	"math"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,187 +14,10 @@ import (
 	marketdata "github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
 )
 
-func TestTechnicalAnalyst_CalculateSMA(t *testing.T) {
-	analyst := &TechnicalAnalyst{}
-
-	tests := []struct {
-		name   string
-		prices []float64
-		period int
-		want   float64
-	}{
-		{
-			name:   "simple 5-day SMA",
-			prices: []float64{10, 20, 30, 40, 50},
-			period: 5,
-			want:   30.0, // (10+20+30+40+50)/5
-		},
-		{
-			name:   "3-day SMA from longer series",
-			prices: []float64{10, 20, 30, 40, 50},
-			period: 3,
-			want:   40.0, // (30+40+50)/3
-		},
-		{
-			name:   "period equals length",
-			prices: []float64{100, 200, 300},
-			period: 3,
-			want:   200.0, // (100+200+300)/3
-		},
-		{
-			name:   "period too long returns zero",
-			prices: []float64{10, 20},
-			period: 5,
-			want:   0.0,
-		},
-		{
-			name:   "single value",
-			prices: []float64{100},
-			period: 1,
-			want:   100.0,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := analyst.calculateSMA(tt.prices, tt.period)
-			if got != tt.want {
-				t.Errorf("calculateSMA() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestTechnicalAnalyst_CalculateRSI(t *testing.T) {
-	analyst := &TechnicalAnalyst{}
-
-	tests := []struct {
-		name    string
-		prices  []float64
-		period  int
-		wantMin float64
-		wantMax float64
-	}{
-		{
-			name:    "uptrending prices - high RSI",
-			prices:  []float64{40, 41, 42, 43, 44, 45, 46, 47, 48, 49, 50, 51, 52, 53, 54, 55},
-			period:  14,
-			wantMin: 70.0, // Should be high (bullish)
-			wantMax: 100.0,
-		},
-		{
-			name:    "downtrending prices - low RSI",
-			prices:  []float64{55, 54, 53, 52, 51, 50, 49, 48, 47, 46, 45, 44, 43, 42, 41, 40},
-			period:  14,
-			wantMin: 0.0,
-			wantMax: 30.0, // Should be low (bearish)
-		},
-		{
-			name:    "insufficient data returns neutral",
-			prices:  []float64{100, 101, 102},
-			period:  14,
-			wantMin: 50.0,
-			wantMax: 50.0,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := analyst.calculateRSI(tt.prices, tt.period)
-			if got < tt.wantMin || got > tt.wantMax {
-				t.Errorf("calculateRSI() = %v, want between %v and %v", got, tt.wantMin, tt.wantMax)
-			}
-		})
-	}
-}
-
-func TestTechnicalAnalyst_CalculateEMA(t *testing.T) {
-	analyst := &TechnicalAnalyst{}
-
-	tests := []struct {
-		name   string
-		prices []float64
-		period int
-	}{
-		{
-			name:   "simple EMA calculation",
-			prices: []float64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100},
-			period: 5,
-		},
-		{
-			name:   "short series",
-			prices: []float64{100, 110, 120},
-			period: 3,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := analyst.calculateEMA(tt.prices, tt.period)
-
-			// EMA should have same length as input
-			if len(got) != len(tt.prices) {
-				t.Errorf("EMA length = %v, want %v", len(got), len(tt.prices))
-			}
-
-			// Last EMA value should be reasonable (close to recent prices)
-			if len(got) > 0 {
-				lastEMA := got[len(got)-1]
-				lastPrice := tt.prices[len(tt.prices)-1]
-				// EMA should be within 50% of last price for trending data
-				if lastEMA < lastPrice*0.5 || lastEMA > lastPrice*1.5 {
-					t.Errorf("Last EMA %v seems unreasonable for last price %v", lastEMA, lastPrice)
-				}
-			}
-		})
-	}
-}
-
-func TestTechnicalAnalyst_CalculateIndicators(t *testing.T) {
-	analyst := &TechnicalAnalyst{}
-
-	// Generate test price data (100 days)
-	prices := make([]float64, 100)
-	for i := 0; i < 100; i++ {
-		prices[i] = 100 + float64(i)*0.5 // Uptrending
-	}
-
-	indicators := analyst.calculateIndicators(prices)
-
-	// Check that all expected keys exist
-	expectedKeys := []string{"rsi", "sma20", "sma50", "macd", "macd_signal", "macd_histogram", "high", "low"}
-	for _, key := range expectedKeys {
-		if _, ok := indicators[key]; !ok {
-			t.Errorf("Missing indicator key: %s", key)
-		}
-	}
-
-	// RSI should be between 0 and 100
-	rsi := indicators["rsi"].(float64)
-	if rsi < 0 || rsi > 100 {
-		t.Errorf("RSI = %v, should be between 0 and 100", rsi)
-	}
-
-	// SMAs should be positive
-	sma20 := indicators["sma20"].(float64)
-	sma50 := indicators["sma50"].(float64)
-	if sma20 <= 0 {
-		t.Errorf("SMA20 = %v, should be positive", sma20)
-	}
-	if sma50 <= 0 {
-		t.Errorf("SMA50 = %v, should be positive", sma50)
-	}
-
-	// For uptrending data, SMA20 should be > SMA50
-	if sma20 <= sma50 {
-		t.Errorf("For uptrend, SMA20 (%v) should be > SMA50 (%v)", sma20, sma50)
-	}
-
-	// High should be >= Low
-	high := indicators["high"].(float64)
-	low := indicators["low"].(float64)
-	if high < low {
-		t.Errorf("High (%v) should be >= Low (%v)", high, low)
+func TestPriceRange(t *testing.T) {
+	high, low := priceRange([]float64{101, 98.5, 110, 104})
+	if high != 110 || low != 98.5 {
+		t.Errorf("priceRange() = %v, %v, want 110, 98.5", high, low)
 	}
 }
 
@@ -448,3 +273,114 @@ func TestTechnicalAnalyst_GetMetadata(t *testing.T) {
 		t.Error("RequiredServices should include llm")
 	}
 }
+
+func TestCalculateTimeframeScores(t *testing.T) {
+	rising := make([]float64, 300)
+	for i := range rising {
+		rising[i] = 100 + float64(i)*0.5
+	}
+
+	scores := calculateTimeframeScores(rising)
+	for name, score := range map[string]*float64{"short": scores.Short, "medium": scores.Medium, "long": scores.Long} {
+		if score == nil {
+			t.Fatalf("%s timeframe should be scored with 300 bars", name)
+		}
+		if *score <= 0 || *score > 100 {
+			t.Errorf("%s score = %v, want bullish within 100", name, *score)
+		}
+	}
+
+	falling := make([]float64, 100)
+	for i := range falling {
+		falling[i] = 200 - float64(i)
+	}
+	scores = calculateTimeframeScores(falling)
+	if scores.Short == nil || *scores.Short >= 0 {
+		t.Errorf("short score = %v, want bearish", scores.Short)
+	}
+	if scores.Medium == nil || *scores.Medium >= 0 {
+		t.Errorf("medium score = %v, want bearish", scores.Medium)
+	}
+	if scores.Long != nil {
+		t.Errorf("long score = %v, want nil without a year of bars", *scores.Long)
+	}
+}
+
+func TestTechnicalSignal(t *testing.T) {
+	// A long rise with a one-week pullback: short timeframe bearish, medium and long bullish
+	prices := make([]float64, 300)
+	for i := range prices {
+		prices[i] = 100 + float64(i)*0.5
+	}
+	for i := 295; i < len(prices); i++ {
+		prices[i] = prices[i-1] * 0.99
+	}
+
+	score, confidence, ok := TechnicalSignal(prices, models.HorizonLong)
+	if !ok || score <= 0 {
+		t.Fatalf("TechnicalSignal() = %v, %v, %v; want a bullish long-horizon score", score, confidence, ok)
+	}
+	if want := 100 * 2.0 / 3; math.Abs(confidence-want) > 1e-9 {
+		t.Errorf("confidence = %v, want %v with two of three timeframes agreeing", confidence, want)
+	}
+
+	if _, _, ok := TechnicalSignal(prices[:5], models.HorizonShort); ok {
+		t.Error("expected no signal without a full short window")
+	}
+}
+
+func TestTechnicalAnalyst_Analyze_TimeframeScores(t *testing.T) {
+	mockLLM := &mockLLMService{
+		response: `{"score": 40, "confidence": 70, "reasoning": "uptrend", "signals": []}`,
+	}
+
+	bars := make([]marketdata.Bar, 100)
+	for i := range bars {
+		bars[i] = marketdata.Bar{Close: 100 + float64(i)*0.5, Volume: 1000000}
+	}
+
+	analyst := NewTechnicalAnalyst(mockLLM, &mockAlpacaService{bars: bars}, config.NewTestConfig())
+	analysis, err := analyst.Analyze(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	scores, ok := analysis.Data["timeframe_scores"].(models.TimeframeScores)
+	if !ok {
+		t.Fatalf("timeframe_scores should be models.TimeframeScores, got %T", analysis.Data["timeframe_scores"])
+	}
+	if scores.Short == nil || scores.Medium == nil {
+		t.Error("short and medium timeframes should be scored with 100 bars")
+	}
+	if scores.Long != nil {
+		t.Error("long timeframe should be unscored with 100 bars")
+	}
+}
+
+func TestTechnicalAnalyst_Analyze_Indicators(t *testing.T) {
+	llm := &promptCapturingLLM{}
+	bars := make([]marketdata.Bar, 100)
+	for i := range bars {
+		c := 100 + float64(i)*0.5
+		bars[i] = marketdata.Bar{Open: c - 0.5, High: c + 1, Low: c - 1, Close: c, Volume: 1000000}
+	}
+
+	analyst := NewTechnicalAnalyst(llm, &mockAlpacaService{bars: bars}, config.NewTestConfig())
+	analysis, err := analyst.Analyze(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	computed, ok := analysis.Data["indicators"].(models.TechnicalIndicators)
+	if !ok {
+		t.Fatalf("indicators should be models.TechnicalIndicators, got %T", analysis.Data["indicators"])
+	}
+	if computed.RSI < 70 || !computed.ATR.IsPositive() || !computed.SMA200.IsZero() {
+		t.Errorf("indicators = %+v, want an overbought RSI, an ATR and no SMA 200 with 100 bars", computed)
+	}
+	for _, want := range []string{"ATR (14-period): $2.00", "Bollinger Bands (20, 2 std dev): upper $", "SMA 200: n/a (insufficient history)"} {
+		if !strings.Contains(llm.userPrompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, llm.userPrompt)
+		}
+	}
+}
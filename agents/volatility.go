@@ -0,0 +1,58 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// RiskStatsProvider supplies a symbol's trailing volatility, beta and drawdown
+type RiskStatsProvider interface {
+	Get(ctx context.Context, symbol string) (*models.RiskStats, error)
+}
+
+// SetRiskStatsProvider sets the source of risk stats used to scale position sizes by
+// volatility (optional dependency)
+func (m *PortfolioManager) SetRiskStatsProvider(p RiskStatsProvider) {
+	m.riskStats = p
+}
+
+// scaleForVolatility shrinks the quantity of a new position in a symbol more volatile than
+// POSITION_TARGET_VOLATILITY, so every full-size position carries about the same risk.
+// Sells and covers close existing exposure and are left alone, as are symbols whose stats
+// can't be computed.
+func (m *PortfolioManager) scaleForVolatility(ctx context.Context, rec *models.Recommendation) {
+	target := m.cfg.PositionSizing.TargetVolatility
+	if target <= 0 || m.riskStats == nil || !rec.Quantity.IsPositive() {
+		return
+	}
+	if rec.Action != models.RecommendationActionBuy && rec.Action != models.RecommendationActionShort {
+		return
+	}
+
+	stats, err := m.riskStats.Get(ctx, rec.Symbol)
+	if err != nil {
+		logger.Warn("risk stats unavailable, position not scaled for volatility",
+			"symbol", rec.Symbol,
+			"error", err)
+		return
+	}
+	scale := stats.VolatilityScale(target)
+	if scale >= 1 {
+		return
+	}
+
+	scaled := rec.Quantity.Mul(decimal.NewFromFloat(scale)).Floor()
+	if minShares := decimal.NewFromInt(m.cfg.PositionSizing.MinShares); scaled.LessThan(minShares) {
+		scaled = minShares
+	}
+	if scaled.Equal(rec.Quantity) {
+		return
+	}
+	rec.Reasoning += fmt.Sprintf("Sized down from %s shares for %.0f%% annualized volatility (target %.0f%%). ",
+		rec.Quantity, stats.Volatility*100, target*100)
+	rec.Quantity = scaled
+}
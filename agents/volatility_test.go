@@ -0,0 +1,79 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
+)
+
+type mockRiskStats struct {
+	stats *models.RiskStats
+	err   error
+}
+
+func (m *mockRiskStats) Get(ctx context.Context, symbol string) (*models.RiskStats, error) {
+	return m.stats, m.err
+}
+
+func TestPortfolioManager_ScaleForVolatility(t *testing.T) {
+	tests := []struct {
+		name       string
+		action     models.RecommendationAction
+		target     float64
+		volatility float64
+		err        error
+		want       int64
+	}{
+		{"volatile buy is halved", models.RecommendationActionBuy, 0.20, 0.40, nil, 50},
+		{"volatile short is scaled", models.RecommendationActionShort, 0.20, 0.80, nil, 25},
+		{"calm symbol keeps its size", models.RecommendationActionBuy, 0.20, 0.15, nil, 100},
+		{"sells are not scaled", models.RecommendationActionSell, 0.20, 0.40, nil, 100},
+		{"scaling disabled", models.RecommendationActionBuy, 0, 0.40, nil, 100},
+		{"stats unavailable", models.RecommendationActionBuy, 0.20, 0, models.ErrInsufficientHistory, 100},
+		{"never below the minimum", models.RecommendationActionBuy, 0.001, 0.40, nil, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.PositionSizing.TargetVolatility = tt.target
+			manager := NewPortfolioManager(nil, cfg, newMockAccountProvider())
+			manager.SetRiskStatsProvider(&mockRiskStats{stats: &models.RiskStats{Volatility: tt.volatility}, err: tt.err})
+
+			rec := models.NewRecommendation("VOL", tt.action, "")
+			rec.Quantity = decimal.NewFromInt(100)
+			manager.scaleForVolatility(context.Background(), rec)
+
+			if !rec.Quantity.Equal(decimal.NewFromInt(tt.want)) {
+				t.Errorf("Quantity = %s, want %d", rec.Quantity, tt.want)
+			}
+			if scaled := tt.want != 100; strings.Contains(rec.Reasoning, "Sized down") != scaled {
+				t.Errorf("reasoning %q, want a volatility note only when scaled", rec.Reasoning)
+			}
+		})
+	}
+}
+
+func TestPortfolioManager_ScaleForVolatility_NoProvider(t *testing.T) {
+	cfg := testConfig()
+	cfg.PositionSizing.TargetVolatility = 0.20
+	manager := NewPortfolioManager(nil, cfg, newMockAccountProvider())
+
+	rec := models.NewRecommendation("VOL", models.RecommendationActionBuy, "")
+	rec.Quantity = decimal.NewFromInt(100)
+	manager.scaleForVolatility(context.Background(), rec)
+	if !rec.Quantity.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("Quantity = %s, want it unchanged without a stats provider", rec.Quantity)
+	}
+
+	manager.SetRiskStatsProvider(&mockRiskStats{err: errors.New("bars unavailable")})
+	manager.scaleForVolatility(context.Background(), rec)
+	if !rec.Quantity.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("Quantity = %s, want it unchanged when stats fail", rec.Quantity)
+	}
+}
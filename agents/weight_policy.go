@@ -0,0 +1,76 @@
+package agents
+
+import "trade-machine/models"
+
+// agentWeights returns the configured weight for each agent type
+func (m *PortfolioManager) agentWeights() map[models.AgentType]float64 {
+	return map[models.AgentType]float64{
+		models.AgentTypeFundamental: m.cfg.Agent.WeightFundamental,
+		models.AgentTypeNews:        m.cfg.Agent.WeightNews,
+		models.AgentTypeTechnical:   m.cfg.Agent.WeightTechnical,
+		models.AgentTypeSocial:      m.cfg.Agent.WeightSocial,
+		models.AgentTypeInsider:     m.cfg.Agent.WeightInsider,
+		models.AgentTypeMacro:       m.cfg.Agent.WeightMacro,
+	}
+}
+
+// weightsSnapshot returns the configured weights, recorded on each recommendation so it
+// can be attributed to the agent that drove it under the weights of the time
+func (m *PortfolioManager) weightsSnapshot() *models.AgentWeights {
+	return &models.AgentWeights{
+		Fundamental: m.cfg.Agent.WeightFundamental,
+		News:        m.cfg.Agent.WeightNews,
+		Technical:   m.cfg.Agent.WeightTechnical,
+		Social:      m.cfg.Agent.WeightSocial,
+		Insider:     m.cfg.Agent.WeightInsider,
+		Macro:       m.cfg.Agent.WeightMacro,
+	}
+}
+
+// weightPolicy returns the configured policy for missing agents, defaulting to redistribute
+func (m *PortfolioManager) weightPolicy() models.WeightPolicy {
+	switch policy := models.WeightPolicy(m.cfg.Agent.WeightPolicy); policy {
+	case models.WeightPolicyFloor, models.WeightPolicyAbstain:
+		return policy
+	default:
+		return models.WeightPolicyRedistribute
+	}
+}
+
+// combineScores computes the confidence-weighted final score from the agents that reported.
+// When agents are missing, the configured weight policy decides what happens to their weight
+// and is returned so it can be recorded; it is empty when every agent reported.
+// abstain is true when the policy refuses to score because the fundamental agent is missing.
+func (m *PortfolioManager) combineScores(analyses []*Analysis, missingAgents []models.MissingAgentInfo) (score float64, policy models.WeightPolicy, abstain bool) {
+	weights := m.agentWeights()
+
+	var weightedScore, totalWeight float64
+	for _, analysis := range analyses {
+		weight := weights[analysis.AgentType]
+		score, _ := m.horizonScore(analysis)
+		weightedScore += score * weight * (analysis.Confidence / 100)
+		totalWeight += weight * (analysis.Confidence / 100)
+	}
+
+	if len(missingAgents) > 0 {
+		policy = m.weightPolicy()
+		switch policy {
+		case models.WeightPolicyFloor:
+			// Missing agents count as a neutral 0 at their full weight, pulling the score toward 0
+			for _, missing := range missingAgents {
+				totalWeight += weights[missing.AgentType]
+			}
+		case models.WeightPolicyAbstain:
+			for _, missing := range missingAgents {
+				if missing.AgentType == models.AgentTypeFundamental {
+					return 0, policy, true
+				}
+			}
+		}
+	}
+
+	if totalWeight > 0 {
+		score = weightedScore / totalWeight
+	}
+	return score, policy, false
+}
@@ -0,0 +1,76 @@
+package agents
+
+import (
+	"context"
+	"testing"
+
+	"trade-machine/models"
+)
+
+func TestPortfolioManager_CombineScores(t *testing.T) {
+	fundamental := &Analysis{AgentType: models.AgentTypeFundamental, Score: 50, Confidence: 100}
+	news := &Analysis{AgentType: models.AgentTypeNews, Score: 40, Confidence: 100}
+	technical := &Analysis{AgentType: models.AgentTypeTechnical, Score: 60, Confidence: 100}
+	missingTechnical := []models.MissingAgentInfo{{AgentType: models.AgentTypeTechnical}}
+	missingFundamental := []models.MissingAgentInfo{{AgentType: models.AgentTypeFundamental}}
+
+	tests := []struct {
+		name        string
+		policy      string
+		analyses    []*Analysis
+		missing     []models.MissingAgentInfo
+		wantScore   float64
+		wantPolicy  models.WeightPolicy
+		wantAbstain bool
+	}{
+		{"all agents reported", "floor", []*Analysis{fundamental, news, technical}, nil, 50, "", false},
+		{"redistribute", "redistribute", []*Analysis{fundamental, news}, missingTechnical, 45.714, models.WeightPolicyRedistribute, false},
+		{"floor", "floor", []*Analysis{fundamental, news}, missingTechnical, 32, models.WeightPolicyFloor, false},
+		{"abstain with fundamental present", "abstain", []*Analysis{fundamental, news}, missingTechnical, 45.714, models.WeightPolicyAbstain, false},
+		{"abstain without fundamental", "abstain", []*Analysis{news, technical}, missingFundamental, 0, models.WeightPolicyAbstain, true},
+		{"unknown policy redistributes", "bogus", []*Analysis{fundamental, news}, missingTechnical, 45.714, models.WeightPolicyRedistribute, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Agent.WeightPolicy = tt.policy
+			manager := NewPortfolioManager(nil, cfg, newMockAccountProvider())
+
+			score, policy, abstain := manager.combineScores(tt.analyses, tt.missing)
+			if !floatNearlyEqual(score, tt.wantScore, 0.01) {
+				t.Errorf("score = %v, want %v", score, tt.wantScore)
+			}
+			if policy != tt.wantPolicy {
+				t.Errorf("policy = %q, want %q", policy, tt.wantPolicy)
+			}
+			if abstain != tt.wantAbstain {
+				t.Errorf("abstain = %v, want %v", abstain, tt.wantAbstain)
+			}
+		})
+	}
+}
+
+func TestPortfolioManager_SynthesizeRecommendation_AbstainPolicy(t *testing.T) {
+	cfg := testConfig()
+	cfg.Agent.WeightPolicy = "abstain"
+	manager := NewPortfolioManager(nil, cfg, newMockAccountProvider())
+
+	analyses := []*Analysis{
+		{Symbol: "AAPL", AgentType: models.AgentTypeNews, Score: 90, Confidence: 90, Reasoning: "Great news"},
+		{Symbol: "AAPL", AgentType: models.AgentTypeTechnical, Score: 90, Confidence: 90, Reasoning: "Breakout"},
+	}
+	missing := []models.MissingAgentInfo{{AgentType: models.AgentTypeFundamental, Reason: "Alpha Vantage down"}}
+
+	rec := manager.synthesizeRecommendation(context.Background(), "AAPL", analyses, missing)
+
+	if rec.Action != models.RecommendationActionHold {
+		t.Errorf("Action = %v, want hold when abstaining", rec.Action)
+	}
+	if rec.WeightPolicy != models.WeightPolicyAbstain {
+		t.Errorf("WeightPolicy = %q, want abstain", rec.WeightPolicy)
+	}
+	if !containsString(rec.Reasoning, "Abstained") {
+		t.Error("Reasoning should explain the abstention")
+	}
+}
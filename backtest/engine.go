@@ -0,0 +1,228 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"trade-machine/agents"
+	"trade-machine/models"
+
+	marketdata "github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/google/uuid"
+)
+
+// warmupCalendarDays of bars are loaded before the start so the long timeframe can be
+// scored from the first replayed day
+const warmupCalendarDays = 380
+
+// BarSource supplies historical bars
+type BarSource interface {
+	GetBars(ctx context.Context, symbol string, start, end time.Time, timeframe marketdata.TimeFrame) ([]marketdata.Bar, error)
+}
+
+// Engine replays daily bars through an action strategy. Each day's close is scored with
+// the technical analyst's timeframe scores, the strategy turns the score into an action,
+// and the resulting order fills at the next day's open, so no signal trades on prices it
+// could not have seen. The simulation is long only: buys open a position of a fixed
+// fraction of equity and sells close it. The LLM-backed analysts are not replayed, since
+// their inputs (news, fundamentals as then reported) are not available as of past dates.
+type Engine struct {
+	bars BarSource
+	now  func() time.Time
+}
+
+// NewEngine creates a new Engine
+func NewEngine(bars BarSource) *Engine {
+	return &Engine{bars: bars, now: time.Now}
+}
+
+// series is one symbol's daily bars, indexed by market date
+type series struct {
+	bars   []marketdata.Bar
+	byDate map[string]int
+}
+
+// holding is an open simulated position
+type holding struct {
+	quantity   float64
+	entryDate  time.Time
+	entryPrice float64
+}
+
+// Run replays req, which must have been normalized, with strategy from start through end
+func (e *Engine) Run(ctx context.Context, req models.BacktestRequest, strategy agents.ActionStrategy, start, end time.Time) (*models.BacktestRun, error) {
+	started := e.now()
+	run := &models.BacktestRun{
+		ID:        uuid.New(),
+		Request:   req,
+		Strategy:  strategy.Name(),
+		Equity:    []models.EquityPoint{},
+		Trades:    []models.BacktestTrade{},
+		Warnings:  []string{},
+		CreatedAt: started,
+	}
+
+	data := make(map[string]*series, len(req.Symbols))
+	dates := make(map[string]time.Time)
+	for _, symbol := range req.Symbols {
+		bars, err := e.bars.GetBars(ctx, symbol, start.AddDate(0, 0, -warmupCalendarDays), end.AddDate(0, 0, 1), marketdata.OneDay)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bars for %s: %w", symbol, err)
+		}
+		s := &series{bars: bars, byDate: make(map[string]int, len(bars))}
+		inRange := 0
+		for i, bar := range bars {
+			day := marketDate(bar.Timestamp)
+			s.byDate[day] = i
+			if d := dayStart(bar.Timestamp); !d.Before(start) && !d.After(end) {
+				dates[day] = d
+				inRange++
+			}
+		}
+		if inRange == 0 {
+			run.Warnings = append(run.Warnings, fmt.Sprintf("%s: no bars between %s and %s", symbol, req.Start, req.End))
+			continue
+		}
+		data[symbol] = s
+	}
+	if len(dates) == 0 {
+		return nil, fmt.Errorf("%w: no bars for any symbol in the period", models.ErrInsufficientHistory)
+	}
+
+	days := make([]string, 0, len(dates))
+	for day := range dates {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	cash := req.InitialCash
+	holdings := make(map[string]*holding)
+	pending := make(map[string]models.RecommendationAction)
+	lastClose := make(map[string]float64)
+	equity := cash
+
+	for _, day := range days {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// Fill yesterday's signals at today's open
+		for _, symbol := range req.Symbols {
+			action, ok := pending[symbol]
+			s := data[symbol]
+			if !ok || s == nil {
+				continue
+			}
+			i, traded := s.byDate[day]
+			if !traded {
+				continue
+			}
+			delete(pending, symbol)
+			open := s.bars[i].Open
+			if open <= 0 {
+				continue
+			}
+			switch action {
+			case models.RecommendationActionBuy:
+				budget := math.Min(cash, equity*req.PositionPercent)
+				qty := math.Floor(budget / open)
+				if qty < 1 {
+					continue
+				}
+				cash -= qty * open
+				holdings[symbol] = &holding{quantity: qty, entryDate: dates[day], entryPrice: open}
+			case models.RecommendationActionSell:
+				h := holdings[symbol]
+				cash += h.quantity * open
+				run.Trades = append(run.Trades, closeTrade(symbol, h, dates[day], open, false))
+				delete(holdings, symbol)
+			}
+		}
+
+		// Score today's close and queue tomorrow's orders
+		for _, symbol := range req.Symbols {
+			s := data[symbol]
+			if s == nil {
+				continue
+			}
+			i, traded := s.byDate[day]
+			if !traded {
+				continue
+			}
+			lastClose[symbol] = s.bars[i].Close
+
+			score, confidence, ok := agents.TechnicalSignal(closes(s.bars[:i+1]), req.Horizon)
+			if !ok {
+				continue
+			}
+			_, held := holdings[symbol]
+			switch strategy.DetermineAction(score, confidence) {
+			case models.RecommendationActionBuy:
+				if !held {
+					pending[symbol] = models.RecommendationActionBuy
+				}
+			case models.RecommendationActionSell:
+				if held {
+					pending[symbol] = models.RecommendationActionSell
+				}
+			}
+		}
+
+		equity = cash
+		for symbol, h := range holdings {
+			equity += h.quantity * lastClose[symbol]
+		}
+		run.Equity = append(run.Equity, models.EquityPoint{Date: dates[day], Equity: roundCents(equity)})
+	}
+
+	// Positions still open are valued at the last close
+	lastDay := dates[days[len(days)-1]]
+	for _, symbol := range req.Symbols {
+		if h, ok := holdings[symbol]; ok {
+			run.Trades = append(run.Trades, closeTrade(symbol, h, lastDay, lastClose[symbol], true))
+		}
+	}
+
+	run.Stats = models.NewBacktestStats(req.InitialCash, run.Equity, run.Trades)
+	run.DurationMs = e.now().Sub(started).Milliseconds()
+	return run, nil
+}
+
+func closeTrade(symbol string, h *holding, date time.Time, price float64, openAtEnd bool) models.BacktestTrade {
+	return models.BacktestTrade{
+		Symbol:     symbol,
+		Quantity:   h.quantity,
+		EntryDate:  h.entryDate,
+		EntryPrice: h.entryPrice,
+		ExitDate:   date,
+		ExitPrice:  price,
+		PnL:        roundCents(h.quantity * (price - h.entryPrice)),
+		OpenAtEnd:  openAtEnd,
+	}
+}
+
+func closes(bars []marketdata.Bar) []float64 {
+	prices := make([]float64, len(bars))
+	for i, bar := range bars {
+		prices[i] = bar.Close
+	}
+	return prices
+}
+
+// marketDate returns the exchange-time-zone date of a bar
+func marketDate(t time.Time) string {
+	return t.In(models.MarketLocation()).Format("2006-01-02")
+}
+
+// dayStart returns midnight of the bar's market date
+func dayStart(t time.Time) time.Time {
+	et := t.In(models.MarketLocation())
+	return time.Date(et.Year(), et.Month(), et.Day(), 0, 0, 0, 0, models.MarketLocation())
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,139 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"trade-machine/agents"
+	"trade-machine/models"
+
+	marketdata "github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+)
+
+// mockBars serves each known symbol's daily bars within the requested range
+type mockBars struct {
+	bars    map[string][]marketdata.Bar
+	err     error
+	lastEnd time.Time
+}
+
+func (m *mockBars) GetBars(ctx context.Context, symbol string, start, end time.Time, timeframe marketdata.TimeFrame) ([]marketdata.Bar, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.lastEnd = end
+	var bars []marketdata.Bar
+	for _, bar := range m.bars[symbol] {
+		if !bar.Timestamp.Before(start) && bar.Timestamp.Before(end) {
+			bars = append(bars, bar)
+		}
+	}
+	return bars, nil
+}
+
+// trendBars returns one bar a day from first, rising 0.5% a day for up days, then falling
+// 1% a day for down days. Bars open slightly below the previous close.
+func trendBars(first time.Time, up, down int) []marketdata.Bar {
+	bars := make([]marketdata.Bar, 0, up+down)
+	price := 100.0
+	for i := 0; i < up+down; i++ {
+		open := price
+		if i < up {
+			price *= 1.005
+		} else {
+			price *= 0.99
+		}
+		// Alpaca stamps daily bars at midnight Eastern
+		ts := time.Date(first.Year(), first.Month(), first.Day()+i, 5, 0, 0, 0, time.UTC)
+		bars = append(bars, marketdata.Bar{Timestamp: ts, Open: open * 0.999, Close: price})
+	}
+	return bars
+}
+
+func testRequest(t *testing.T, symbols ...string) (models.BacktestRequest, time.Time, time.Time) {
+	t.Helper()
+	req := models.BacktestRequest{Symbols: symbols, Start: "2025-03-03", End: "2025-06-30", PositionPercent: 0.5}
+	start, end, err := req.Normalize(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 0.1)
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	return req, start, end
+}
+
+func TestEngine_Run(t *testing.T) {
+	req, start, end := testRequest(t, "AAPL", "GONE")
+	// A year of warm-up and the first 30 days of the test rise, then the price falls
+	first := start.AddDate(0, 0, -warmupCalendarDays)
+	bars := &mockBars{bars: map[string][]marketdata.Bar{"AAPL": trendBars(first, warmupCalendarDays+30, 200)}}
+
+	run, err := NewEngine(bars).Run(context.Background(), req, agents.NewDefaultStrategy(), start, end)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !bars.lastEnd.After(end) {
+		t.Errorf("bars requested through %v, want the end day included", bars.lastEnd)
+	}
+	if len(run.Warnings) != 1 {
+		t.Errorf("Warnings = %v, want one for the symbol without bars", run.Warnings)
+	}
+	if want := int(math.Round(end.Sub(start).Hours()/24)) + 1; len(run.Equity) != want {
+		t.Errorf("%d equity points, want %d, one per day with bars", len(run.Equity), want)
+	}
+
+	if len(run.Trades) != 1 {
+		t.Fatalf("Trades = %+v, want one round trip", run.Trades)
+	}
+	trade := run.Trades[0]
+	// The first day's close signals the buy, which fills at the second day's open
+	aapl := bars.bars["AAPL"]
+	entryBar := aapl[warmupCalendarDays+1]
+	if !trade.EntryDate.Equal(dayStart(entryBar.Timestamp)) || trade.EntryPrice != entryBar.Open {
+		t.Errorf("entry %v at %v, want the open of %v at %v", trade.EntryDate, trade.EntryPrice, entryBar.Timestamp, entryBar.Open)
+	}
+	if !trade.ExitDate.After(start.AddDate(0, 0, 30)) || trade.OpenAtEnd {
+		t.Errorf("exit on %v (open at end %v), want a sell after the decline started", trade.ExitDate, trade.OpenAtEnd)
+	}
+	if trade.Quantity != float64(int(req.InitialCash*req.PositionPercent/entryBar.Open)) {
+		t.Errorf("Quantity = %v, want half the starting equity in whole shares", trade.Quantity)
+	}
+
+	if run.Strategy != "default" || run.Stats.Trades != 1 || run.Stats.MaxDrawdown <= 0 {
+		t.Errorf("run = %s with stats %+v, want the default strategy's single trade and a drawdown", run.Strategy, run.Stats)
+	}
+	if run.Stats.FinalEquity != run.Equity[len(run.Equity)-1].Equity {
+		t.Errorf("FinalEquity = %v, want the last equity point", run.Stats.FinalEquity)
+	}
+}
+
+func TestEngine_RunOpenAtEnd(t *testing.T) {
+	req, start, end := testRequest(t, "AAPL")
+	first := start.AddDate(0, 0, -warmupCalendarDays)
+	bars := &mockBars{bars: map[string][]marketdata.Bar{"AAPL": trendBars(first, warmupCalendarDays+200, 0)}}
+
+	run, err := NewEngine(bars).Run(context.Background(), req, agents.NewAggressiveStrategy(), start, end)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(run.Trades) != 1 || !run.Trades[0].OpenAtEnd || run.Trades[0].PnL <= 0 {
+		t.Fatalf("Trades = %+v, want one profitable position still open at the end", run.Trades)
+	}
+	if run.Stats.TotalReturn <= 0 || run.Stats.WinRate != 1 {
+		t.Errorf("stats = %+v, want a positive return and every trade a win", run.Stats)
+	}
+}
+
+func TestEngine_RunErrors(t *testing.T) {
+	req, start, end := testRequest(t, "AAPL")
+
+	if _, err := NewEngine(&mockBars{}).Run(context.Background(), req, agents.NewDefaultStrategy(), start, end); !errors.Is(err, models.ErrInsufficientHistory) {
+		t.Errorf("Run() error = %v, want ErrInsufficientHistory without bars", err)
+	}
+
+	provider := errors.New("rate limited")
+	if _, err := NewEngine(&mockBars{err: provider}).Run(context.Background(), req, agents.NewDefaultStrategy(), start, end); !errors.Is(err, provider) {
+		t.Errorf("Run() error = %v, want the bar source's error", err)
+	}
+}
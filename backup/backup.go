@@ -0,0 +1,267 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+const (
+	// namePrefix and nameSuffix mark the objects in storage that are backups, so
+	// retention never deletes anything else under the configured prefix
+	namePrefix = "trade-machine-"
+	nameSuffix = ".dump.enc"
+	// nameTimeFormat orders backup names chronologically
+	nameTimeFormat = "20060102T150405Z"
+)
+
+// ErrStorageNotConfigured is returned when no backup storage is configured in settings
+var ErrStorageNotConfigured = errors.New("backup storage not configured")
+
+// ErrBackupNotFound is returned when restoring a backup that is not in storage
+var ErrBackupNotFound = errors.New("backup not found")
+
+// Storage holds encrypted backups
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	List(ctx context.Context) ([]Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// StorageResolver returns the configured storage, or ErrStorageNotConfigured. It is
+// called on every run so storage changed in settings applies to the next backup.
+type StorageResolver func(ctx context.Context) (Storage, error)
+
+// Dumper exports the database and loads an export back into it
+type Dumper interface {
+	Dump(ctx context.Context) ([]byte, error)
+	Restore(ctx context.Context, data []byte) error
+}
+
+// Manager backs the database up on a schedule: each dump is encrypted with the
+// configured key before it leaves the machine, uploaded, and the oldest backups
+// beyond the retention count are deleted.
+type Manager struct {
+	dumper        Dumper
+	storage       StorageResolver
+	encryptionKey string
+	interval      time.Duration
+	retention     int
+	now           func() time.Time
+
+	mu     sync.Mutex
+	status models.BackupStatus
+}
+
+// NewManager creates a new Manager
+func NewManager(dumper Dumper, storage StorageResolver, encryptionKey string, interval time.Duration, retention int) *Manager {
+	return &Manager{
+		dumper:        dumper,
+		storage:       storage,
+		encryptionKey: encryptionKey,
+		interval:      interval,
+		retention:     retention,
+		now:           time.Now,
+	}
+}
+
+// Run backs up whenever the newest backup in storage is older than the interval,
+// until ctx is cancelled
+func (m *Manager) Run(ctx context.Context) {
+	observability.Info("database backups started", "interval", m.interval, "retention", m.retention)
+
+	timer := time.NewTimer(m.untilDue(ctx))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		if _, err := m.Backup(ctx); err != nil {
+			observability.Warn("database backup failed", "error", err)
+		}
+		timer.Reset(m.interval)
+		m.setNext(m.now().Add(m.interval))
+	}
+}
+
+// untilDue returns how long until the next backup is due, counting from the newest
+// backup in storage so restarts don't back up again early
+func (m *Manager) untilDue(ctx context.Context) time.Duration {
+	backups, err := m.List(ctx)
+	if err != nil || len(backups) == 0 {
+		m.setNext(m.now())
+		return 0
+	}
+
+	m.mu.Lock()
+	m.status.Retained = len(backups)
+	m.mu.Unlock()
+
+	next := backups[0].CreatedAt.Add(m.interval)
+	m.setNext(next)
+	if wait := next.Sub(m.now()); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// Backup dumps, encrypts and uploads the database, then deletes backups beyond the
+// retention count
+func (m *Manager) Backup(ctx context.Context) (*models.Backup, error) {
+	started := m.now()
+	backup, retained, err := m.backup(ctx, started)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status.LastAttemptAt = &started
+	if err != nil {
+		m.status.LastError = err.Error()
+		return nil, err
+	}
+	m.status.LastError = ""
+	m.status.LastSuccessAt = &started
+	m.status.LastBackup = backup
+	m.status.Retained = retained
+	return backup, nil
+}
+
+func (m *Manager) backup(ctx context.Context, started time.Time) (*models.Backup, int, error) {
+	storage, err := m.storage(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	dump, err := m.dumper.Dump(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to dump database: %w", err)
+	}
+	data, err := Encrypt(m.encryptionKey, dump)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to encrypt backup: %w", err)
+	}
+
+	name := namePrefix + started.UTC().Format(nameTimeFormat) + nameSuffix
+	if err := storage.Put(ctx, name, data); err != nil {
+		return nil, 0, fmt.Errorf("failed to upload backup: %w", err)
+	}
+	backup := &models.Backup{Name: name, SizeBytes: int64(len(data)), CreatedAt: started.UTC()}
+	observability.Info("database backed up", "name", name, "bytes", len(data))
+
+	retained, err := m.prune(ctx, storage)
+	if err != nil {
+		return nil, 0, fmt.Errorf("backup %s uploaded but pruning old backups failed: %w", name, err)
+	}
+	return backup, retained, nil
+}
+
+// prune deletes the oldest backups beyond the retention count and returns how many remain
+func (m *Manager) prune(ctx context.Context, storage Storage) (int, error) {
+	backups, err := listBackups(ctx, storage)
+	if err != nil {
+		return 0, err
+	}
+	if len(backups) <= m.retention {
+		return len(backups), nil
+	}
+	for _, b := range backups[m.retention:] {
+		if err := storage.Delete(ctx, b.Name); err != nil {
+			return 0, err
+		}
+		observability.Info("deleted expired backup", "name", b.Name)
+	}
+	return m.retention, nil
+}
+
+// List returns the backups in storage, newest first
+func (m *Manager) List(ctx context.Context) ([]models.Backup, error) {
+	storage, err := m.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return listBackups(ctx, storage)
+}
+
+// Restore downloads and decrypts the named backup and loads it into the database,
+// replacing the objects it contains
+func (m *Manager) Restore(ctx context.Context, name string) error {
+	storage, err := m.storage(ctx)
+	if err != nil {
+		return err
+	}
+	backups, err := listBackups(ctx, storage)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, b := range backups {
+		if b.Name == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrBackupNotFound, name)
+	}
+
+	data, err := storage.Get(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to download backup: %w", err)
+	}
+	dump, err := Decrypt(m.encryptionKey, data)
+	if err != nil {
+		return err
+	}
+	if err := m.dumper.Restore(ctx, dump); err != nil {
+		return fmt.Errorf("failed to restore database: %w", err)
+	}
+	return nil
+}
+
+// Status returns the outcome of the last backup and when the next one is due
+func (m *Manager) Status() *models.BackupStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status := m.status
+	return &status
+}
+
+func (m *Manager) setNext(next time.Time) {
+	m.mu.Lock()
+	m.status.NextAt = &next
+	m.mu.Unlock()
+}
+
+// listBackups returns the backups in storage, newest first, skipping other objects
+func listBackups(ctx context.Context, storage Storage) ([]models.Backup, error) {
+	objects, err := storage.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var backups []models.Backup
+	for _, o := range objects {
+		if !strings.HasPrefix(o.Key, namePrefix) || !strings.HasSuffix(o.Key, nameSuffix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(o.Key, namePrefix), nameSuffix)
+		createdAt, err := time.Parse(nameTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, models.Backup{Name: o.Key, SizeBytes: o.Size, CreatedAt: createdAt})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
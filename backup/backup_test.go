@@ -0,0 +1,184 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+type memStorage struct {
+	objects map[string][]byte
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{objects: make(map[string][]byte)}
+}
+
+func (m *memStorage) Put(ctx context.Context, key string, data []byte) error {
+	m.objects[key] = data
+	return nil
+}
+
+func (m *memStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	return m.objects[key], nil
+}
+
+func (m *memStorage) List(ctx context.Context) ([]Object, error) {
+	var objects []Object
+	for key, data := range m.objects {
+		objects = append(objects, Object{Key: key, Size: int64(len(data))})
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+func (m *memStorage) Delete(ctx context.Context, key string) error {
+	delete(m.objects, key)
+	return nil
+}
+
+type mockDumper struct {
+	dump     []byte
+	restored []byte
+}
+
+func (m *mockDumper) Dump(ctx context.Context) ([]byte, error) {
+	return m.dump, nil
+}
+
+func (m *mockDumper) Restore(ctx context.Context, data []byte) error {
+	m.restored = data
+	return nil
+}
+
+func newTestManager(storage Storage, dumper Dumper, retention int) (*Manager, *time.Time) {
+	resolve := func(ctx context.Context) (Storage, error) {
+		if storage == nil {
+			return nil, ErrStorageNotConfigured
+		}
+		return storage, nil
+	}
+	m := NewManager(dumper, resolve, "test-key", 24*time.Hour, retention)
+	now := time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	return m, &now
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	data := []byte("PGDMP database contents")
+
+	sealed, err := Encrypt("passphrase", data)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if string(sealed) == string(data) {
+		t.Fatal("expected ciphertext to differ from the dump")
+	}
+
+	opened, err := Decrypt("passphrase", sealed)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	if string(opened) != string(data) {
+		t.Errorf("Decrypt = %q, want %q", opened, data)
+	}
+
+	if _, err := Decrypt("wrong", sealed); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt for the wrong key, got %v", err)
+	}
+	if _, err := Decrypt("passphrase", data); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt for plaintext, got %v", err)
+	}
+	if _, err := Encrypt("", data); err == nil {
+		t.Error("expected an error without an encryption key")
+	}
+}
+
+func TestManager_BackupAndRetention(t *testing.T) {
+	storage := newMemStorage()
+	storage.objects["notes.txt"] = []byte("not a backup")
+	dumper := &mockDumper{dump: []byte("dump")}
+	m, now := newTestManager(storage, dumper, 2)
+
+	for i := 0; i < 3; i++ {
+		if _, err := m.Backup(context.Background()); err != nil {
+			t.Fatalf("Backup failed: %v", err)
+		}
+		*now = now.Add(24 * time.Hour)
+	}
+
+	backups, err := m.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 retained backups, got %d", len(backups))
+	}
+	if backups[0].Name != "trade-machine-20240303T020000Z.dump.enc" || backups[1].Name != "trade-machine-20240302T020000Z.dump.enc" {
+		t.Errorf("expected the newest backups first, got %s and %s", backups[0].Name, backups[1].Name)
+	}
+	if _, ok := storage.objects["notes.txt"]; !ok {
+		t.Error("expected objects that are not backups to be kept")
+	}
+
+	status := m.Status()
+	if !status.Healthy() || status.Retained != 2 || status.LastBackup.Name != backups[0].Name {
+		t.Errorf("Status = %+v, want a healthy status for the latest backup", status)
+	}
+}
+
+func TestManager_Restore(t *testing.T) {
+	storage := newMemStorage()
+	dumper := &mockDumper{dump: []byte("dump")}
+	m, _ := newTestManager(storage, dumper, 7)
+
+	backup, err := m.Backup(context.Background())
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	if string(storage.objects[backup.Name]) == "dump" {
+		t.Fatal("expected the uploaded backup to be encrypted")
+	}
+
+	if err := m.Restore(context.Background(), backup.Name); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if string(dumper.restored) != "dump" {
+		t.Errorf("restored %q, want the decrypted dump", dumper.restored)
+	}
+
+	if err := m.Restore(context.Background(), "trade-machine-20000101T000000Z.dump.enc"); !errors.Is(err, ErrBackupNotFound) {
+		t.Errorf("expected ErrBackupNotFound, got %v", err)
+	}
+}
+
+func TestManager_StorageNotConfigured(t *testing.T) {
+	m, _ := newTestManager(nil, &mockDumper{}, 7)
+
+	if _, err := m.Backup(context.Background()); !errors.Is(err, ErrStorageNotConfigured) {
+		t.Fatalf("expected ErrStorageNotConfigured, got %v", err)
+	}
+	status := m.Status()
+	if status.Healthy() || status.LastAttemptAt == nil || status.LastSuccessAt != nil {
+		t.Errorf("Status = %+v, want a failed attempt", status)
+	}
+}
+
+func TestManager_UntilDue(t *testing.T) {
+	storage := newMemStorage()
+	m, now := newTestManager(storage, &mockDumper{dump: []byte("dump")}, 7)
+
+	if wait := m.untilDue(context.Background()); wait != 0 {
+		t.Errorf("expected a backup right away with none in storage, got %v", wait)
+	}
+
+	if _, err := m.Backup(context.Background()); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	*now = now.Add(6 * time.Hour)
+	if wait := m.untilDue(context.Background()); wait != 18*time.Hour {
+		t.Errorf("expected the next backup a day after the last, got %v", wait)
+	}
+}
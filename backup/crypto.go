@@ -0,0 +1,89 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	saltSize   = 16
+	keySize    = 32 // AES-256
+	iterations = 600000
+)
+
+// magic identifies the encrypted backup format: magic, salt, nonce, then the
+// AES-256-GCM sealed dump
+var magic = []byte("TMBACKUP1")
+
+// ErrDecrypt is returned when a backup is not in the encrypted format or the key is wrong
+var ErrDecrypt = errors.New("failed to decrypt backup: wrong encryption key or corrupted file")
+
+// Encrypt seals data with a key derived from passphrase. The passphrase never leaves
+// this machine, so storage only ever holds ciphertext.
+func Encrypt(passphrase string, data []byte) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("encryption key is required")
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(magic)+saltSize+len(nonce)+len(data)+gcm.Overhead())
+	out = append(out, magic...)
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, data, magic), nil
+}
+
+// Decrypt opens data sealed by Encrypt with the same passphrase
+func Decrypt(passphrase string, data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, magic) {
+		return nil, ErrDecrypt
+	}
+	data = data[len(magic):]
+	if len(data) < saltSize {
+		return nil, ErrDecrypt
+	}
+
+	gcm, err := newGCM(passphrase, data[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	data = data[saltSize:]
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrDecrypt
+	}
+
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], magic)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// newGCM derives an AES key from passphrase and salt using PBKDF2
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key := pbkdf2.Key([]byte(passphrase), salt, iterations, keySize, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,59 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// PgDump exports the database with pg_dump in its custom format and restores with
+// pg_restore from the same directory
+type PgDump struct {
+	dumpPath    string
+	restorePath string
+	databaseURL string
+}
+
+// NewPgDump creates a PgDump for the database at databaseURL. dumpPath is the pg_dump
+// binary, looked up on PATH when it has no directory.
+func NewPgDump(dumpPath, databaseURL string) *PgDump {
+	restorePath := "pg_restore"
+	if dir := filepath.Dir(dumpPath); dir != "." {
+		restorePath = filepath.Join(dir, restorePath)
+	}
+	return &PgDump{dumpPath: dumpPath, restorePath: restorePath, databaseURL: databaseURL}
+}
+
+// Dump exports the whole database, without ownership so it restores under any role
+func (p *PgDump) Dump(ctx context.Context) ([]byte, error) {
+	var stdout bytes.Buffer
+	if err := p.run(ctx, p.dumpPath, nil, &stdout, "--format=custom", "--no-owner", "--dbname="+p.databaseURL); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// Restore drops the objects in the export and recreates them from it, in one transaction
+func (p *PgDump) Restore(ctx context.Context, data []byte) error {
+	return p.run(ctx, p.restorePath, bytes.NewReader(data), nil,
+		"--clean", "--if-exists", "--no-owner", "--single-transaction", "--dbname="+p.databaseURL)
+}
+
+func (p *PgDump) run(ctx context.Context, path string, stdin *bytes.Reader, stdout *bytes.Buffer, args ...string) error {
+	cmd := exec.CommandContext(ctx, path, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	if stdout != nil {
+		cmd.Stdout = stdout
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w: %s", filepath.Base(path), err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestS3Storage(t *testing.T) {
+	objects := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("x-amz-content-sha256") == "" || r.Header.Get("x-amz-date") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/bucket":
+			if r.URL.Query().Get("list-type") != "2" || r.URL.Query().Get("prefix") != "nightly/" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, "<ListBucketResult><IsTruncated>false</IsTruncated>")
+			for key, data := range objects {
+				fmt.Fprintf(w, "<Contents><Key>%s</Key><Size>%d</Size><LastModified>2024-03-01T02:00:00.000Z</LastModified></Contents>", key, len(data))
+			}
+			fmt.Fprint(w, "</ListBucketResult>")
+		case r.Method == http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			objects[strings.TrimPrefix(r.URL.Path, "/bucket/")] = data
+		case r.Method == http.MethodGet:
+			data, ok := objects[strings.TrimPrefix(r.URL.Path, "/bucket/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		case r.Method == http.MethodDelete:
+			delete(objects, strings.TrimPrefix(r.URL.Path, "/bucket/"))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	storage, err := NewS3Storage(S3Config{URL: server.URL + "/bucket/nightly", AccessKey: "AKID", SecretKey: "secret"})
+	if err != nil {
+		t.Fatalf("NewS3Storage failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := storage.Put(ctx, "a.dump.enc", []byte("sealed")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if _, ok := objects["nightly/a.dump.enc"]; !ok {
+		t.Fatalf("expected the object under the prefix, got %v", objects)
+	}
+
+	listed, err := storage.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Key != "a.dump.enc" || listed[0].Size != 6 {
+		t.Errorf("List = %+v, want a.dump.enc relative to the prefix", listed)
+	}
+
+	data, err := storage.Get(ctx, "a.dump.enc")
+	if err != nil || string(data) != "sealed" {
+		t.Errorf("Get = %q, %v, want the uploaded data", data, err)
+	}
+
+	if err := storage.Delete(ctx, "a.dump.enc"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := storage.Get(ctx, "a.dump.enc"); err == nil {
+		t.Error("expected an error for a deleted object")
+	}
+}
+
+func TestNewS3Storage_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  S3Config
+	}{
+		{"missing credentials", S3Config{URL: "https://s3.example.com/bucket"}},
+		{"missing bucket", S3Config{URL: "https://s3.example.com", AccessKey: "a", SecretKey: "s"}},
+		{"not a URL", S3Config{URL: "s3.example.com/bucket", AccessKey: "a", SecretKey: "s"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewS3Storage(tt.cfg); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestURIEncode(t *testing.T) {
+	if got := uriEncode("/bucket/a b+c.dump", false); got != "/bucket/a%20b%2Bc.dump" {
+		t.Errorf("uriEncode path = %q", got)
+	}
+	if got := uriEncode("nightly/", true); got != "nightly%2F" {
+		t.Errorf("uriEncode query = %q", got)
+	}
+}
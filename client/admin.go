@@ -0,0 +1,26 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"trade-machine/models"
+)
+
+// Diagnostics downloads a sanitized diagnostic bundle from the server
+func (c *Client) Diagnostics(ctx context.Context) (*models.DiagnosticBundle, error) {
+	var bundle models.DiagnosticBundle
+	if err := c.do(ctx, http.MethodGet, "/api/admin/diagnostics", nil, nil, &bundle); err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+// ImportDiagnostics loads a diagnostic bundle into the server's database
+func (c *Client) ImportDiagnostics(ctx context.Context, bundle *models.DiagnosticBundle) (*models.DiagnosticImport, error) {
+	var result models.DiagnosticImport
+	if err := c.do(ctx, http.MethodPost, "/api/admin/diagnostics/import", nil, bundle, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,217 @@
+// Package client is a typed Go SDK for the trade-machine HTTP API, for automation
+// built on top of a server-mode deployment. Responses decode into the same models
+// the server uses.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. Reads are retried after network
+// errors and 429, 502, 503 and 504 responses; writes only after 429 and 503, which the
+// server returns before doing any work.
+type RetryPolicy struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy retries three times, doubling the wait from 200ms up to 5s
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     3,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
+
+// Client calls the trade-machine HTTP API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	token      string
+	retry      RetryPolicy
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithAPIToken sends token as a bearer token on every request, for servers behind an
+// authenticating proxy
+func WithAPIToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy. A zero MaxRetries disables retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// New creates a client for the server at baseURL, e.g. "http://localhost:8080"
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q", baseURL)
+	}
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: 5 * time.Minute}, // Analyses can take minutes
+		retry:      DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// APIError is returned when the server responds with an error status
+type APIError struct {
+	StatusCode      int
+	Message         string
+	MissingServices []string // Set when the screener is not configured
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("trade-machine: %d %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is an APIError with status 404
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is an APIError with status 409, returned when a
+// recommendation changed since it was read or can no longer be acted on
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// do sends a request with a JSON body, if any, and decodes the JSON response into out,
+// if non-nil, retrying transient failures
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	u := c.baseURL.JoinPath(path)
+	u.RawQuery = query.Encode()
+
+	backoff := c.retry.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, u.String(), payload)
+		retryable := err != nil && method == http.MethodGet && ctx.Err() == nil
+		if err == nil {
+			if resp.StatusCode < 300 {
+				return decodeResponse(resp, out)
+			}
+			err = decodeError(resp)
+			retryable = retryableStatus(method, resp.StatusCode)
+			if wait := retryAfter(resp.Header); wait > backoff {
+				backoff = wait
+			}
+		}
+		if !retryable || attempt >= c.retry.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, c.retry.MaxBackoff)
+	}
+}
+
+// send makes a single attempt at a request
+func (c *Client) send(ctx context.Context, method, u string, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return c.httpClient.Do(req)
+}
+
+// retryableStatus reports whether a response status is worth retrying for method
+func retryableStatus(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return method == http.MethodGet
+	default:
+		return false
+	}
+}
+
+// retryAfter returns the wait requested by a Retry-After header in seconds, or zero
+func retryAfter(h http.Header) time.Duration {
+	seconds, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func decodeResponse(resp *http.Response, out any) error {
+	defer resp.Body.Close()
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func decodeError(resp *http.Response) error {
+	defer resp.Body.Close()
+	var body struct {
+		Error           string   `json:"error"`
+		MissingServices []string `json:"missing_services"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == "" {
+		body.Error = http.StatusText(resp.StatusCode)
+	}
+	return &APIError{StatusCode: resp.StatusCode, Message: body.Error, MissingServices: body.MissingServices}
+}
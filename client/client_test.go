@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"trade-machine/models"
+)
+
+// fastRetries keeps retry tests quick
+var fastRetries = WithRetryPolicy(RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c, err := New(server.URL, append([]Option{fastRetries}, opts...)...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return c
+}
+
+func TestNew_InvalidURL(t *testing.T) {
+	for _, u := range []string{"", "localhost:8080", "://bad"} {
+		if _, err := New(u); err == nil {
+			t.Errorf("New(%q) expected an error", u)
+		}
+	}
+}
+
+func TestClient_Analyze(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/analyze" {
+			t.Errorf("request = %s %s, want POST /api/analyze", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want the bearer token", got)
+		}
+		if got := r.Header.Get("Accept"); got != "application/json" {
+			t.Errorf("Accept = %q, want application/json", got)
+		}
+		var req struct {
+			Symbol string `json:"symbol"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(models.Recommendation{Symbol: req.Symbol, Action: models.RecommendationActionBuy})
+	}, WithAPIToken("secret"))
+
+	rec, err := c.Analyze(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if rec.Symbol != "AAPL" || rec.Action != models.RecommendationActionBuy {
+		t.Errorf("Analyze() = %+v, want a buy for AAPL", rec)
+	}
+}
+
+func TestClient_RetriesTransientErrors(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode([]models.Recommendation{{Symbol: "MSFT"}})
+	})
+
+	recs, err := c.PendingRecommendations(context.Background())
+	if err != nil {
+		t.Fatalf("PendingRecommendations() error = %v", err)
+	}
+	if len(recs) != 1 || calls.Load() != 3 {
+		t.Errorf("got %d recommendations after %d calls, want 1 after 3", len(recs), calls.Load())
+	}
+}
+
+func TestClient_DoesNotRetryWrites(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	if _, err := c.ReviewPortfolio(context.Background()); err == nil {
+		t.Fatal("ReviewPortfolio() expected an error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d calls, want 1: a 502 on a write may have done the work", calls.Load())
+	}
+}
+
+func TestClient_APIError(t *testing.T) {
+	id := uuid.New()
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/recommendations/"+id.String()+"/approve" || r.URL.Query().Get("version") != "3" {
+			t.Errorf("request = %s, want approve with version 3", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": "changed elsewhere"})
+	})
+
+	_, err := c.ApproveRecommendation(context.Background(), id, 3)
+	if !IsConflict(err) || IsNotFound(err) {
+		t.Fatalf("ApproveRecommendation() error = %v, want a conflict", err)
+	}
+	if err.Error() != "trade-machine: 409 changed elsewhere" {
+		t.Errorf("error = %q", err.Error())
+	}
+}
+
+func TestClient_LatestScreenerRun_None(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"run":null}`))
+	})
+
+	run, err := c.LatestScreenerRun(context.Background())
+	if err != nil || run != nil {
+		t.Errorf("LatestScreenerRun() = %v, %v; want nil, nil before the first run", run, err)
+	}
+}
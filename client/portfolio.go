@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+
+	"trade-machine/models"
+)
+
+// Portfolio returns the open positions with their totals and cumulative fees
+func (c *Client) Portfolio(ctx context.Context) (*models.PortfolioSummary, error) {
+	var summary models.PortfolioSummary
+	if err := c.do(ctx, http.MethodGet, "/api/portfolio", nil, nil, &summary); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// Positions returns the open positions
+func (c *Client) Positions(ctx context.Context) ([]models.Position, error) {
+	var positions []models.Position
+	err := c.do(ctx, http.MethodGet, "/api/positions", nil, nil, &positions)
+	return positions, err
+}
+
+// PortfolioAsOf returns the portfolio reconstructed at the close of a past day
+func (c *Client) PortfolioAsOf(ctx context.Context, date time.Time) (*models.PortfolioAsOf, error) {
+	var portfolio models.PortfolioAsOf
+	query := url.Values{"date": {date.Format("2006-01-02")}}
+	if err := c.do(ctx, http.MethodGet, "/api/portfolio/asof", query, nil, &portfolio); err != nil {
+		return nil, err
+	}
+	return &portfolio, nil
+}
+
+// ReviewPortfolio starts analyzing every open position and returns the running review;
+// poll PortfolioReview with its ID until Running is false
+func (c *Client) ReviewPortfolio(ctx context.Context) (*models.PortfolioReview, error) {
+	var review models.PortfolioReview
+	if err := c.do(ctx, http.MethodPost, "/api/portfolio/analyze", nil, nil, &review); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// PortfolioReviews returns up to limit past portfolio reviews, newest first
+func (c *Client) PortfolioReviews(ctx context.Context, limit int) ([]models.PortfolioReview, error) {
+	var reviews []models.PortfolioReview
+	err := c.do(ctx, http.MethodGet, "/api/portfolio/reviews", limitQuery(limit), nil, &reviews)
+	return reviews, err
+}
+
+// PortfolioReview returns a single portfolio review
+func (c *Client) PortfolioReview(ctx context.Context, id uuid.UUID) (*models.PortfolioReview, error) {
+	var review models.PortfolioReview
+	if err := c.do(ctx, http.MethodGet, "/api/portfolio/reviews/"+id.String(), nil, nil, &review); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"trade-machine/models"
+)
+
+// RecommendationAction is the result of approving, rejecting or executing a recommendation
+type RecommendationAction struct {
+	Status         string                 `json:"status"`
+	ID             string                 `json:"id"`
+	Recommendation *models.Recommendation `json:"recommendation"`
+	Trade          *models.Trade          `json:"trade,omitempty"` // Set when the action placed an order
+}
+
+// Analyze runs the agents on symbol and returns the resulting recommendation
+func (c *Client) Analyze(ctx context.Context, symbol string) (*models.Recommendation, error) {
+	var rec models.Recommendation
+	if err := c.do(ctx, http.MethodPost, "/api/analyze", nil, map[string]string{"symbol": symbol}, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// Recommendations returns up to limit recent recommendations, newest first
+func (c *Client) Recommendations(ctx context.Context, limit int) ([]models.Recommendation, error) {
+	var recs []models.Recommendation
+	err := c.do(ctx, http.MethodGet, "/api/recommendations", limitQuery(limit), nil, &recs)
+	return recs, err
+}
+
+// PendingRecommendations returns recommendations awaiting approval
+func (c *Client) PendingRecommendations(ctx context.Context) ([]models.Recommendation, error) {
+	var recs []models.Recommendation
+	err := c.do(ctx, http.MethodGet, "/api/recommendations/pending", nil, nil, &recs)
+	return recs, err
+}
+
+// ApproveRecommendation approves a pending recommendation. Pass the version last read to
+// fail with a conflict if it changed since, or models.AnyVersion to skip the check.
+func (c *Client) ApproveRecommendation(ctx context.Context, id uuid.UUID, version int) (*RecommendationAction, error) {
+	return c.recommendationAction(ctx, id, "approve", version)
+}
+
+// RejectRecommendation rejects a pending recommendation
+func (c *Client) RejectRecommendation(ctx context.Context, id uuid.UUID, version int) (*RecommendationAction, error) {
+	return c.recommendationAction(ctx, id, "reject", version)
+}
+
+// ExecuteRecommendation places the order for an approved recommendation
+func (c *Client) ExecuteRecommendation(ctx context.Context, id uuid.UUID, version int) (*RecommendationAction, error) {
+	return c.recommendationAction(ctx, id, "execute", version)
+}
+
+func (c *Client) recommendationAction(ctx context.Context, id uuid.UUID, action string, version int) (*RecommendationAction, error) {
+	query := url.Values{}
+	if version != models.AnyVersion {
+		query.Set("version", strconv.Itoa(version))
+	}
+	var result RecommendationAction
+	if err := c.do(ctx, http.MethodPost, "/api/recommendations/"+id.String()+"/"+action, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// limitQuery returns a limit query parameter, or none for a non-positive limit
+func limitQuery(limit int) url.Values {
+	if limit <= 0 {
+		return nil
+	}
+	return url.Values{"limit": {strconv.Itoa(limit)}}
+}
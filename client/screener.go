@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"trade-machine/models"
+)
+
+// ScreenerRunResult is a screener run together with the picks it produced
+type ScreenerRunResult struct {
+	*models.ScreenerRun
+	Picks []models.ScreenerCandidate `json:"picks"`
+}
+
+// TopPicks is the top picks along with the run they came from
+type TopPicks struct {
+	Run   *models.ScreenerRun        `json:"run"`
+	Picks []models.ScreenerCandidate `json:"picks"`
+	Count int                        `json:"count"`
+}
+
+// RunScreener runs the screener. Non-nil overrides replace the configured listing
+// filters for this run only.
+func (c *Client) RunScreener(ctx context.Context, overrides *models.ScreenerFilters) (*ScreenerRunResult, error) {
+	var body any
+	if overrides != nil {
+		body = overrides
+	}
+	var result ScreenerRunResult
+	if err := c.do(ctx, http.MethodPost, "/api/screener/run", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// LatestScreenerRun returns the most recent screener run, or nil if the screener has never run
+func (c *Client) LatestScreenerRun(ctx context.Context) (*models.ScreenerRun, error) {
+	var run models.ScreenerRun
+	if err := c.do(ctx, http.MethodGet, "/api/screener/latest", nil, nil, &run); err != nil {
+		return nil, err
+	}
+	if run.ID == uuid.Nil {
+		return nil, nil
+	}
+	return &run, nil
+}
+
+// ScreenerRuns returns up to limit past screener runs, newest first
+func (c *Client) ScreenerRuns(ctx context.Context, limit int) ([]models.ScreenerRun, error) {
+	var runs []models.ScreenerRun
+	err := c.do(ctx, http.MethodGet, "/api/screener/runs", limitQuery(limit), nil, &runs)
+	return runs, err
+}
+
+// ScreenerRun returns a single screener run
+func (c *Client) ScreenerRun(ctx context.Context, id uuid.UUID) (*models.ScreenerRun, error) {
+	var run models.ScreenerRun
+	if err := c.do(ctx, http.MethodGet, "/api/screener/runs/"+id.String(), nil, nil, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ReplayScreenerRun starts screening the archived universe of a finished run again with
+// req's criteria and ranking strategy, returning the new run, still running, compared
+// with the original. ScreenerReplayComparison reports it once it completes.
+func (c *Client) ReplayScreenerRun(ctx context.Context, id uuid.UUID, req models.ScreenerReplayRequest) (*models.ScreenerRunComparison, error) {
+	var cmp models.ScreenerRunComparison
+	if err := c.do(ctx, http.MethodPost, "/api/screener/runs/"+id.String()+"/replay", nil, req, &cmp); err != nil {
+		return nil, err
+	}
+	return &cmp, nil
+}
+
+// ScreenerReplayComparison compares a replay run with the run it replayed
+func (c *Client) ScreenerReplayComparison(ctx context.Context, replayID uuid.UUID) (*models.ScreenerRunComparison, error) {
+	var cmp models.ScreenerRunComparison
+	if err := c.do(ctx, http.MethodGet, "/api/screener/runs/"+replayID.String()+"/comparison", nil, nil, &cmp); err != nil {
+		return nil, err
+	}
+	return &cmp, nil
+}
+
+// TopPicks returns the top picks from the latest completed screener run
+func (c *Client) TopPicks(ctx context.Context) (*TopPicks, error) {
+	var picks TopPicks
+	if err := c.do(ctx, http.MethodGet, "/api/screener/picks/latest-run", nil, nil, &picks); err != nil {
+		return nil, err
+	}
+	return &picks, nil
+}
+
+// ScreenerSchedule returns when the screener runs on its own, with its next and last runs
+func (c *Client) ScreenerSchedule(ctx context.Context) (*models.ScreenerScheduleStatus, error) {
+	var status models.ScreenerScheduleStatus
+	if err := c.do(ctx, http.MethodGet, "/api/screener/schedule", nil, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// SetScreenerSchedule replaces the screener schedule; an empty cron stops scheduled runs
+func (c *Client) SetScreenerSchedule(ctx context.Context, schedule models.ScreenerSchedule) (*models.ScreenerScheduleStatus, error) {
+	var status models.ScreenerScheduleStatus
+	if err := c.do(ctx, http.MethodPut, "/api/screener/schedule", nil, schedule, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
@@ -0,0 +1,125 @@
+// Package main provides the command-line tool for listing, taking and restoring the
+// encrypted database backups made by the app.
+//
+// Usage:
+//
+//	backup list
+//	backup run
+//	backup restore -yes trade-machine-20240301T020000Z.dump.enc
+//
+// The bucket is read from the settings stored in the database. To restore into an empty
+// database, pass it with -url, -region, -access-key and -secret-key instead.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"trade-machine/backup"
+	"trade-machine/config"
+	"trade-machine/internal/app"
+	"trade-machine/internal/settings"
+	"trade-machine/repository"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	_ = godotenv.Load()
+
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	storageURL := flags.String("url", "", "bucket URL in path style, overriding settings")
+	region := flags.String("region", "", "bucket region")
+	accessKey := flags.String("access-key", "", "storage access key")
+	secretKey := flags.String("secret-key", "", "storage secret key")
+	confirm := flags.Bool("yes", false, "confirm replacing the database contents on restore")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: backup [flags] list | run | restore NAME")
+		flags.PrintDefaults()
+	}
+	if len(os.Args) < 2 {
+		flags.Usage()
+		return fmt.Errorf("missing command")
+	}
+	command := os.Args[1]
+	flags.Parse(os.Args[2:])
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if !cfg.HasDatabase() {
+		return fmt.Errorf("DATABASE_URL environment variable is required")
+	}
+	if cfg.Backup.EncryptionKey == "" {
+		return fmt.Errorf("BACKUP_ENCRYPTION_KEY environment variable is required")
+	}
+
+	ctx := context.Background()
+	var resolver backup.StorageResolver
+	if *storageURL != "" {
+		storage, err := backup.NewS3Storage(backup.S3Config{URL: *storageURL, Region: *region, AccessKey: *accessKey, SecretKey: *secretKey})
+		if err != nil {
+			return err
+		}
+		resolver = func(ctx context.Context) (backup.Storage, error) { return storage, nil }
+	} else {
+		repo, err := repository.NewRepository(ctx, cfg.Database.URL)
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		defer repo.Close()
+		store, err := settings.NewStore(os.Getenv("SETTINGS_DIR"), os.Getenv("SETTINGS_PASSPHRASE"), repo)
+		if err != nil {
+			return fmt.Errorf("failed to load settings: %w", err)
+		}
+		resolver = app.NewBackupStorageResolver(store)
+	}
+
+	dumper := backup.NewPgDump(cfg.Backup.PgDumpPath, cfg.Database.URL)
+	manager := backup.NewManager(dumper, resolver, cfg.Backup.EncryptionKey, time.Duration(cfg.Backup.IntervalHours)*time.Hour, cfg.Backup.Retention)
+
+	switch command {
+	case "list":
+		backups, err := manager.List(ctx)
+		if err != nil {
+			return err
+		}
+		for _, b := range backups {
+			fmt.Printf("%s\t%s\t%d bytes\n", b.Name, b.CreatedAt.Format(time.RFC3339), b.SizeBytes)
+		}
+		return nil
+	case "run":
+		b, err := manager.Backup(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("backed up %s (%d bytes)\n", b.Name, b.SizeBytes)
+		return nil
+	case "restore":
+		if flags.NArg() != 1 {
+			return fmt.Errorf("restore needs the name of a backup, see backup list")
+		}
+		if !*confirm {
+			return fmt.Errorf("restore replaces the database contents; rerun with -yes to confirm")
+		}
+		if err := manager.Restore(ctx, flags.Arg(0)); err != nil {
+			return err
+		}
+		fmt.Printf("restored %s\n", flags.Arg(0))
+		return nil
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
@@ -0,0 +1,143 @@
+// Package main provides the command-line tool for support diagnostics: exporting a
+// sanitized bundle from a running server, and importing one into a local database to
+// reproduce the failures it records.
+//
+// Usage:
+//
+//	diagnostics export -server http://localhost:8080 -o bundle.json
+//	diagnostics import bundle.json
+//
+// Import reads DATABASE_URL like the app, adds the bundle's failed agent runs and provider
+// alerts, and prints the settings that differ from the local configuration.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"trade-machine/client"
+	"trade-machine/config"
+	"trade-machine/internal/app"
+	"trade-machine/models"
+	"trade-machine/repository"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	_ = godotenv.Load()
+
+	flags := flag.NewFlagSet("diagnostics", flag.ExitOnError)
+	server := flags.String("server", "http://localhost:8080", "base URL of the server to export from")
+	token := flags.String("token", "", "bearer token for a server behind an authenticating proxy")
+	output := flags.String("o", "", "file to write the bundle to (default: a timestamped name)")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: diagnostics [flags] export | import FILE")
+		flags.PrintDefaults()
+	}
+	if len(os.Args) < 2 {
+		flags.Usage()
+		return fmt.Errorf("missing command")
+	}
+	command := os.Args[1]
+	flags.Parse(os.Args[2:])
+
+	ctx := context.Background()
+	switch command {
+	case "export":
+		return export(ctx, *server, *token, *output)
+	case "import":
+		if flags.NArg() != 1 {
+			return fmt.Errorf("import needs the bundle file")
+		}
+		return importBundle(ctx, flags.Arg(0))
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func export(ctx context.Context, server, token, output string) error {
+	c, err := client.New(server, client.WithAPIToken(token))
+	if err != nil {
+		return err
+	}
+	bundle, err := c.Diagnostics(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to export diagnostics: %w", err)
+	}
+
+	if output == "" {
+		output = fmt.Sprintf("trade-machine-diagnostics-%s.json", bundle.CreatedAt.UTC().Format("20060102T150405Z"))
+	}
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, data, 0o600); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s: %d log records, %d failed agent runs, %d provider alerts\n",
+		output, len(bundle.Logs), len(bundle.FailedAgentRuns), len(bundle.ProviderAlerts))
+	for _, w := range bundle.Warnings {
+		fmt.Println("warning:", w)
+	}
+	return nil
+}
+
+func importBundle(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var bundle models.DiagnosticBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if !cfg.HasDatabase() {
+		return fmt.Errorf("DATABASE_URL environment variable is required")
+	}
+	repo, err := repository.NewRepository(ctx, cfg.Database.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer repo.Close()
+
+	result, err := app.ImportDiagnosticBundle(ctx, repo, cfg, &bundle)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("imported bundle from %s (%s, %s): %d failed agent runs, %d provider alerts, %d already present\n",
+		bundle.CreatedAt.Format(time.RFC3339), bundle.Platform, bundle.GoVersion, result.AgentRuns, result.ProviderAlerts, result.Skipped)
+	for _, w := range result.Warnings {
+		fmt.Println("warning:", w)
+	}
+	if len(result.ConfigDiffs) > 0 {
+		fmt.Println("\nsettings that differ from the bundle:")
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "KEY\tBUNDLE\tLOCAL")
+		for _, d := range result.ConfigDiffs {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", d.Key, d.Bundle, d.Local)
+		}
+		tw.Flush()
+	}
+	return nil
+}
@@ -43,7 +43,11 @@ func main() {
 
 	// Load config with minimal settings for testing
 	cfg := &config.Config{
-		HTTP: config.HTTPConfig{},
+		HTTP: config.HTTPConfig{
+			// Lets the E2E suite exercise API token auth
+			AuthEnabled: os.Getenv("API_AUTH_ENABLED") == "true",
+			AdminToken:  os.Getenv("API_ADMIN_TOKEN"),
+		},
 		Agent: config.AgentConfig{
 			ConcurrencyLimit: 3,
 			TimeoutSeconds:   30,
@@ -143,6 +143,38 @@ func (m *MockFMPService) GetCompanyProfile(ctx context.Context, symbol string) (
 	}, nil
 }
 
+func (m *MockFMPService) GetNextEarningsDate(ctx context.Context, symbol string) (*time.Time, error) {
+	next := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 21)
+	return &next, nil
+}
+
+func (m *MockFMPService) GetRatios(ctx context.Context, symbol string) (*services.Ratios, error) {
+	return &services.Ratios{Symbol: symbol, PERatio: 14.2, PBRatio: 2.1, DividendYield: 2.8, EPS: 6.5}, nil
+}
+
+func (m *MockFMPService) GetInsiderTrades(ctx context.Context, symbol string, limit int) ([]models.InsiderTrade, error) {
+	return []models.InsiderTrade{{
+		Symbol:          symbol,
+		Insider:         "Mock Director",
+		Role:            "director",
+		TransactionType: "P-Purchase",
+		Shares:          1000,
+		Price:           100,
+		TradedAt:        time.Now().UTC().AddDate(0, 0, -14),
+		FiledAt:         time.Now().UTC().AddDate(0, 0, -12),
+	}}, nil
+}
+
+func (m *MockFMPService) GetGrowthMetrics(ctx context.Context, symbol string) (*services.GrowthMetrics, error) {
+	return &services.GrowthMetrics{Symbol: symbol, RevenueGrowth: 0.18, EPSGrowth: 0.22, PriceChange6M: 12.5}, nil
+}
+
+func (m *MockFMPService) GetDividendHistory(ctx context.Context, symbol string) ([]models.Dividend, error) {
+	exDate := time.Now().UTC().AddDate(0, 0, -30).Truncate(24 * time.Hour)
+	paid := exDate.AddDate(0, 0, 14)
+	return []models.Dividend{{Symbol: symbol, ExDate: exDate, PaymentDate: &paid, AmountPerShare: decimal.NewFromFloat(0.25)}}, nil
+}
+
 // MockPortfolioManager provides mock analysis for e2e testing
 type MockPortfolioManager struct {
 	repo ScreenerRepoInterface
@@ -220,6 +252,10 @@ func (m *MockAlpacaService) GetDailyBars(ctx context.Context, symbol string, day
 	return []marketdata.Bar{}, nil
 }
 
+func (m *MockAlpacaService) GetAverageDailyVolume(ctx context.Context, symbol string, days int) (int64, error) {
+	return 50_000_000, nil
+}
+
 func (m *MockAlpacaService) GetQuote(ctx context.Context, symbol string) (*models.Quote, error) {
 	return &models.Quote{
 		Symbol: symbol,
@@ -246,10 +282,18 @@ func (m *MockAlpacaService) GetAccount(ctx context.Context) (*models.Account, er
 	}, nil
 }
 
-func (m *MockAlpacaService) PlaceOrder(ctx context.Context, symbol string, qty decimal.Decimal, side models.TradeSide, orderType string) (string, error) {
+func (m *MockAlpacaService) PlaceOrder(ctx context.Context, req models.OrderRequest) (string, error) {
 	return "mock-order-id", nil
 }
 
+func (m *MockAlpacaService) GetOrder(ctx context.Context, orderID string) (*models.BrokerOrder, error) {
+	return &models.BrokerOrder{ID: orderID, Status: models.BrokerOrderFilled}, nil
+}
+
+func (m *MockAlpacaService) GetShortAvailability(ctx context.Context, symbol string) (*models.ShortAvailability, error) {
+	return &models.ShortAvailability{Symbol: symbol, Shortable: true, EasyToBorrow: true}, nil
+}
+
 func (m *MockAlpacaService) GetPositions(ctx context.Context) ([]models.Position, error) {
 	return []models.Position{}, nil
 }
@@ -0,0 +1,79 @@
+package compliance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Jurisdiction selects a preset disclaimer worded for a regulatory regime
+type Jurisdiction string
+
+const (
+	JurisdictionUS Jurisdiction = "us" // United States (SEC/FINRA)
+	JurisdictionUK Jurisdiction = "uk" // United Kingdom (FCA)
+	JurisdictionEU Jurisdiction = "eu" // European Union (MiFID II)
+	JurisdictionCA Jurisdiction = "ca" // Canada (CIRO)
+	JurisdictionAU Jurisdiction = "au" // Australia (ASIC)
+)
+
+// Jurisdictions lists the jurisdictions with a preset disclaimer
+var Jurisdictions = []Jurisdiction{JurisdictionUS, JurisdictionUK, JurisdictionEU, JurisdictionCA, JurisdictionAU}
+
+const commonDisclaimer = "Recommendations are generated automatically by AI models from third-party data that may be incomplete, delayed or wrong. " +
+	"They are not personalized advice and do not consider your financial situation or objectives. " +
+	"Trading involves risk, including the loss of your entire investment, and past performance does not guarantee future results."
+
+var presets = map[Jurisdiction]string{
+	JurisdictionUS: "For informational purposes only. Not investment advice or a recommendation to buy or sell any security; " +
+		"this software is not a registered investment adviser or broker-dealer. " + commonDisclaimer,
+	JurisdictionUK: "For information only. This is not financial advice or a personal recommendation, and this software is not authorised or regulated by the Financial Conduct Authority. " +
+		"The value of investments can go down as well as up and you may get back less than you invest. " + commonDisclaimer,
+	JurisdictionEU: "For information only. This is not investment advice within the meaning of MiFID II, and this software is not an authorised investment firm. " +
+		"The value of investments can fall as well as rise. " + commonDisclaimer,
+	JurisdictionCA: "For informational purposes only. Not investment advice; this software is not registered with any Canadian securities regulator or CIRO. " + commonDisclaimer,
+	JurisdictionAU: "General information only. This is not personal financial product advice, and this software does not hold an Australian Financial Services Licence. " +
+		"Consider whether it is appropriate for you and seek independent advice before acting. " + commonDisclaimer,
+}
+
+// IsValid reports whether j has a preset disclaimer
+func (j Jurisdiction) IsValid() bool {
+	return slices.Contains(Jurisdictions, j)
+}
+
+// Disclaimer is the text attached to recommendations, reports and exports. Its version
+// changes whenever the text does, so a new text must be acknowledged again.
+type Disclaimer struct {
+	Jurisdiction Jurisdiction `json:"jurisdiction"`
+	Text         string       `json:"text"`
+	Version      string       `json:"version"`
+}
+
+// NewDisclaimer returns the preset disclaimer for a jurisdiction (US when empty), or
+// custom text in its place when set
+func NewDisclaimer(jurisdiction Jurisdiction, custom string) (*Disclaimer, error) {
+	if jurisdiction == "" {
+		jurisdiction = JurisdictionUS
+	}
+	if !jurisdiction.IsValid() {
+		return nil, fmt.Errorf("unknown jurisdiction %q", jurisdiction)
+	}
+
+	text := strings.TrimSpace(custom)
+	if text == "" {
+		text = presets[jurisdiction]
+	}
+	sum := sha256.Sum256([]byte(text))
+	return &Disclaimer{
+		Jurisdiction: jurisdiction,
+		Text:         text,
+		Version:      hex.EncodeToString(sum[:6]),
+	}, nil
+}
+
+// Append adds the disclaimer to the end of a text document such as an export
+func (d *Disclaimer) Append(text string) string {
+	return strings.TrimRight(text, "\n") + "\n\n" + d.Text + "\n"
+}
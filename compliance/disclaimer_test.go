@@ -0,0 +1,49 @@
+package compliance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewDisclaimer_Presets(t *testing.T) {
+	for _, j := range Jurisdictions {
+		d, err := NewDisclaimer(j, "")
+		if err != nil {
+			t.Fatalf("NewDisclaimer(%q) failed: %v", j, err)
+		}
+		if d.Text == "" || d.Version == "" {
+			t.Errorf("NewDisclaimer(%q) = %+v, want preset text and version", j, d)
+		}
+	}
+
+	if _, err := NewDisclaimer("mars", ""); err == nil {
+		t.Error("expected an error for an unknown jurisdiction")
+	}
+}
+
+func TestNewDisclaimer_CustomTextChangesVersion(t *testing.T) {
+	preset, _ := NewDisclaimer(JurisdictionUS, "")
+	custom, err := NewDisclaimer(JurisdictionUS, "  Internal use only.  ")
+	if err != nil {
+		t.Fatalf("NewDisclaimer failed: %v", err)
+	}
+	if custom.Text != "Internal use only." {
+		t.Errorf("Text = %q, want the trimmed custom text", custom.Text)
+	}
+	if custom.Version == preset.Version {
+		t.Error("a different text should have a different version")
+	}
+
+	again, _ := NewDisclaimer(JurisdictionUS, "Internal use only.")
+	if again.Version != custom.Version {
+		t.Error("the same text should keep its version")
+	}
+}
+
+func TestDisclaimer_Append(t *testing.T) {
+	d, _ := NewDisclaimer(JurisdictionUS, "Not advice.")
+	got := d.Append("symbol,action\nAAPL,buy\n")
+	if !strings.HasSuffix(got, "AAPL,buy\n\nNot advice.\n") {
+		t.Errorf("Append() = %q", got)
+	}
+}
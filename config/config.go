@@ -2,8 +2,13 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+
+	"trade-machine/observability"
 )
 
 // Config holds all application configuration
@@ -11,14 +16,29 @@ type Config struct {
 	// Database configuration
 	Database DatabaseConfig
 
+	// Which LLM provider the agents use
+	LLM LLMConfig
+
 	// OpenAI configuration
 	OpenAI OpenAIConfig
 
+	// Anthropic API configuration
+	Anthropic AnthropicConfig
+
+	// Local Ollama server configuration
+	Ollama OllamaConfig
+
 	// External service configurations
 	Alpaca       AlpacaConfig
+	IBKR         IBKRConfig
 	AlphaVantage AlphaVantageConfig
 	NewsAPI      NewsAPIConfig
+	Social       SocialConfig
 	FMP          FMPConfig
+	FRED         FREDConfig
+
+	// Broker that new orders are routed to
+	Broker BrokerConfig
 
 	// Agent configuration
 	Agent AgentConfig
@@ -29,6 +49,78 @@ type Config struct {
 	// Screener configuration
 	Screener ScreenerConfig
 
+	// Price-move watcher configuration
+	PriceWatch PriceWatchConfig
+
+	// Broker reconciliation configuration
+	Reconciliation ReconciliationConfig
+
+	// Target weights for portfolio rebalancing
+	Rebalance RebalanceConfig
+
+	// Option chains and covered-call suggestions
+	Options OptionsConfig
+
+	// Order placement on approval
+	Execution ExecutionConfig
+
+	// Expiry of stale open recommendations
+	RecommendationExpiry RecommendationExpiryConfig
+
+	// Fee schedule for paper trading
+	Fees FeeConfig
+
+	// Portfolio review configuration
+	PortfolioReview PortfolioReviewConfig
+
+	// Daily portfolio snapshots for the equity curve
+	PortfolioSnapshots PortfolioSnapshotsConfig
+
+	// Outbound API call ledger configuration
+	APILedger APILedgerConfig
+
+	// Outbound request quotas per provider
+	RateLimits RateLimitConfig
+
+	// Retries of failed provider calls
+	Retry RetryConfig
+
+	// Buffered non-critical database writes
+	WriteBuffer WriteBufferConfig
+
+	// Disclaimers and acknowledgment
+	Compliance ComplianceConfig
+
+	// Encrypted database backups
+	Backup BackupConfig
+
+	// Dashboard data preloaded on startup
+	Warmup WarmupConfig
+
+	// Hot cache entries refreshed in the background during market hours
+	CacheRefresh CacheRefreshConfig
+
+	// Provider responses kept in the market data cache
+	ResponseCache ResponseCacheConfig
+
+	// Log levels, globally and per module
+	Logging LoggingConfig
+
+	// OpenTelemetry trace export
+	Tracing TracingConfig
+
+	// Forwarding of domain events to an external message bus
+	Events EventsConfig
+
+	// Status menu with quick actions
+	Tray TrayConfig
+
+	// Trailing volatility, beta and drawdown per symbol
+	RiskStats RiskStatsConfig
+
+	// Portfolio-level limits checked before a recommendation is saved
+	RiskManager RiskManagerConfig
+
 	// HTTP configuration
 	HTTP HTTPConfig
 }
@@ -40,9 +132,41 @@ type DatabaseConfig struct {
 
 // OpenAIConfig holds OpenAI API configuration
 type OpenAIConfig struct {
+	APIKey              string
+	Model               string
+	MaxTokens           int
+	EmbeddingModel      string // Model used to embed past analyses for similarity search
+	EmbeddingDimensions int    // Dimensions requested from models that can shorten embeddings (default: 0, the model's own size)
+}
+
+// LLMConfig selects the LLM provider
+type LLMConfig struct {
+	Provider         string              // openai, anthropic or ollama; empty uses the first configured, in that order
+	Routes           map[string]LLMRoute // Provider and model per agent type, overriding Provider
+	FallbackProvider string              // Provider used while the routed one's circuit breaker is open; empty for none
+	MonthlyBudget    float64             // Estimated LLM spend allowed per calendar month, in USD (default: 0 = no budget)
+	BudgetMode       string              // What to do once the budget is spent: warn or block new analyses (default: warn)
+}
+
+// LLMRoute sends one agent type's LLM calls to a provider, and a model of that provider
+type LLMRoute struct {
+	Provider string
+	Model    string // Empty uses the provider's configured model
+}
+
+// AnthropicConfig holds Anthropic API configuration, for Claude models without AWS
+type AnthropicConfig struct {
 	APIKey    string
 	Model     string
 	MaxTokens int
+	BaseURL   string
+}
+
+// OllamaConfig holds configuration for a local Ollama server, for running agents offline
+type OllamaConfig struct {
+	BaseURL   string // Empty leaves Ollama off unless LLM_PROVIDER=ollama, which uses localhost
+	Model     string
+	MaxTokens int
 }
 
 // AlpacaConfig holds Alpaca API configuration
@@ -52,6 +176,24 @@ type AlpacaConfig struct {
 	BaseURL   string
 }
 
+// IsPaper reports whether the base URL points at Alpaca's paper trading environment
+func (c AlpacaConfig) IsPaper() bool {
+	return strings.Contains(c.BaseURL, "paper-api")
+}
+
+// IBKRConfig holds Interactive Brokers Client Portal gateway configuration. The gateway
+// holds the login session, so no credentials are configured here.
+type IBKRConfig struct {
+	GatewayURL         string // Gateway API root, e.g. https://localhost:5000/v1/api
+	AccountID          string // Empty uses the first account the gateway reports
+	InsecureSkipVerify bool   // Accept the gateway's self-signed certificate (default: false)
+}
+
+// BrokerConfig selects the broker orders are placed with
+type BrokerConfig struct {
+	Provider string // alpaca or ibkr (default: alpaca); a broker chosen in settings takes precedence
+}
+
 // AlphaVantageConfig holds Alpha Vantage API configuration
 type AlphaVantageConfig struct {
 	APIKey string
@@ -62,95 +204,492 @@ type NewsAPIConfig struct {
 	APIKey string
 }
 
+// SocialConfig holds configuration for the Reddit and StockTwits sentiment sources. Both
+// are read through their public endpoints, so no keys are needed.
+type SocialConfig struct {
+	Enabled    bool     // Register the social sentiment agent (default: false)
+	Subreddits []string // Subreddits searched for cashtag mentions (default: wallstreetbets, stocks, investing)
+	UserAgent  string   // Sent with Reddit requests, which refuses generic clients (default: trade-machine/1.0)
+}
+
 // FMPConfig holds Financial Modeling Prep API configuration
 type FMPConfig struct {
 	APIKey string
 }
 
+// FREDConfig holds St. Louis Fed (FRED) API configuration
+type FREDConfig struct {
+	APIKey string
+}
+
 // AgentConfig holds agent-related configuration
 type AgentConfig struct {
 	TimeoutSeconds        int
 	ConcurrencyLimit      int
 	TechnicalLookbackDays int
+	NewsLookbackDays      int // Only fetch news published within this many days (default: 7)
+	NewsHalfLifeHours     int // Article sentiment weight halves every this many hours (default: 24)
 	WeightFundamental     float64
 	WeightNews            float64
 	WeightTechnical       float64
+	WeightSocial          float64 // Weight of the social sentiment agent; raise it by lowering the others (default: 0)
+	WeightInsider         float64 // Weight of the insider activity agent, registered only when above 0 (default: 0)
+	InsiderLookbackDays   int     // Only count insider trades made within this many days (default: 90)
+	WeightMacro           float64 // Weight of the macro agent, registered only when above 0 (default: 0)
 	Strategy              string  // default, conservative, aggressive, or custom
 	BuyThreshold          float64 // for custom strategy
 	SellThreshold         float64 // for custom strategy
 	MinConfidence         float64 // for custom/conservative strategy
 	HealthCacheTTLSeconds int     // TTL for health check caching (default: 30)
+	Language              string  // ISO 639-1 code for agent reasoning and the dashboard shell (default: en)
+	MinRiskReward         float64 // Buys below this reward/risk ratio are downgraded to hold (default: 1.5, 0 disables)
+	StopLossPercent       float64 // Fallback stop distance from entry when agents give no level (default: 0.05)
+	TakeProfitPercent     float64 // Fallback target distance from entry when agents give no level (default: 0.10)
+	WeightPolicy          string  // Missing-agent weight handling: redistribute, floor, or abstain (default: redistribute)
+	SignalOnly            bool    // Skip quotes and position sizing; recommendations carry no quantity (default: false)
+	LatencyBudgetSeconds  int     // Return a partial recommendation once an analysis runs this long (default: 0, disabled)
+	Horizon               string  // Weights technical timeframes for short, medium, or long holds; overall uses the technical score as is (default: medium)
+
+	// Per symbol-class strategy thresholds keyed by class (mega_cap, large_cap, mid_cap,
+	// small_cap, crypto). Classes without an entry use the global strategy.
+	ClassThresholds map[string]ClassThreshold
+
+	// Per agent-type timeout, retry, and model overrides keyed by agent type (fundamental,
+	// news, technical). Agents without an entry use TimeoutSeconds, no retries, and the
+	// default model.
+	TypeOverrides map[string]AgentOverride
+}
+
+// AgentOverride customizes how one agent type is run
+type AgentOverride struct {
+	TimeoutSeconds int    // 0 uses Agent.TimeoutSeconds
+	Retries        int    // Additional attempts after a failure
+	Model          string // LLM model override; empty uses the default model
+}
+
+// ClassThreshold overrides the action thresholds for one symbol class
+type ClassThreshold struct {
+	BuyThreshold  float64
+	SellThreshold float64
+	MinConfidence float64
 }
 
+// overridableAgentTypes lists the agent types that accept AGENT_TYPE_OVERRIDES
+var overridableAgentTypes = []string{"fundamental", "news", "technical", "social", "insider", "macro"}
+
+// llmAgentTypes lists the agent types that prompt an LLM and accept LLM_ROUTES
+var llmAgentTypes = []string{"fundamental", "news", "technical", "social"}
+
+// maxAgentRetries caps per-agent retries so a failing provider cannot stall an analysis
+const maxAgentRetries = 5
+
+// minAdminTokenLength keeps API_ADMIN_TOKEN long enough that it can't be guessed
+const minAdminTokenLength = 32
+
+// symbolClasses mirrors models.SymbolClasses; config does not import models
+var symbolClasses = []string{"mega_cap", "large_cap", "mid_cap", "small_cap", "crypto"}
+
+// rankingStrategies lists the top-picks ranking presets plus custom
+var rankingStrategies = []string{"default", "conservative", "aggressive", "value", "custom"}
+
+// rankingComponents mirrors the models.RankComponent constants; config does not import models
+var rankingComponents = []string{"score", "confidence", "completeness", "margin_of_safety", "liquidity"}
+
+// complianceJurisdictions mirrors compliance.Jurisdictions; config does not import compliance
+// brokers mirrors models.Brokers; config does not import models
+var brokers = []string{"alpaca", "ibkr"}
+
+var complianceJurisdictions = []string{"us", "uk", "eu", "ca", "au"}
+
 // PositionSizingConfig holds position sizing configuration
 type PositionSizingConfig struct {
-	MaxPositionPercent   float64
-	RiskPercent          float64
-	MinShares            int64
-	MaxShares            int64
-	UseConfidenceScaling bool
+	MaxPositionPercent     float64
+	RiskPercent            float64
+	MinShares              int64
+	MaxShares              int64
+	UseConfidenceScaling   bool
+	AllowShorts            bool    // Turn bearish signals without a long position into short entries (default: false)
+	AllowHardToBorrow      bool    // Allow shorts in hard-to-borrow symbols (default: false)
+	ShortMarginRequirement float64 // Buying power held per dollar shorted (default: 1.5, the Reg T initial requirement)
+	MaxADVPercent          float64 // Largest order as a fraction of average daily volume (default: 0 = off)
+	ADVMode                string  // What to do with orders above MaxADVPercent: warn or reject (default: warn)
+	ADVLookbackDays        int     // Calendar days of daily bars averaged for volume (default: 30)
+	TargetVolatility       float64 // Annualized volatility a full-size position may run at; more volatile symbols are sized down (default: 0 = no scaling)
 }
 
 // ScreenerConfig holds value screener configuration
 type ScreenerConfig struct {
-	MarketCapMin       int64   // Minimum market cap filter (default: 1B)
-	PERatioMax         float64 // Maximum P/E ratio filter (default: 15)
-	PBRatioMax         float64 // Maximum P/B ratio filter (default: 1.5)
-	PreFilterLimit     int     // Number of candidates to pre-filter (default: 15)
-	TopPicksCount      int     // Number of top picks to return (default: 3)
-	AnalysisTimeoutSec int     // Timeout for full analysis in seconds (default: 120)
-	MaxConcurrent      int     // Max concurrent analyses (default: 5)
+	MarketCapMin       int64    // Minimum market cap filter (default: 1B)
+	PERatioMax         float64  // Maximum P/E ratio filter (default: 15)
+	PBRatioMax         float64  // Maximum P/B ratio filter (default: 1.5)
+	PreFilterLimit     int      // Number of candidates to pre-filter (default: 15)
+	TopPicksCount      int      // Number of top picks to return (default: 3)
+	AnalysisTimeoutSec int      // Timeout for full analysis in seconds (default: 120)
+	MaxConcurrent      int      // Max concurrent analyses (default: 5)
+	RateLimitPauseMs   int      // Initial pause after a provider rate-limit error, doubled on repeats (default: 2000)
+	Exchanges          []string // Exchange allowlist for candidates (default: NYSE, NASDAQ, AMEX)
+	Country            string   // Country filter for candidates (default: US)
+	PriceMin           float64  // Minimum share price (default: 0 = no minimum)
+	AvgVolumeMin       int64    // Minimum average daily volume (default: 0 = no minimum)
+	DollarVolumeMin    float64  // Minimum average daily dollar volume, price times volume (default: 0 = no minimum)
+	MinListingMonths   int      // Months a company must have been public (default: 0 = no minimum)
+	RecentListingMode  string   // What to do with recent listings: exclude or flag (default: exclude)
+
+	// Top-picks ranking formula: default, conservative, aggressive, value, or custom (default: default)
+	RankingStrategy string
+	// Component weights for the custom ranking strategy, keyed by ranking component
+	RankingWeights map[string]float64
+
+	// When the screener runs on its own; changeable at runtime from the API
+	Schedule ScreenerScheduleConfig
+}
+
+// ScreenerScheduleConfig holds the starting schedule for automatic screener runs
+type ScreenerScheduleConfig struct {
+	Cron    string // Five-field cron spec in US Eastern time, e.g. "0 8 * * 1-5" (default: "" = no scheduled runs)
+	Analyze bool   // Fully analyze scheduled runs' candidates, creating recommendations (default: true)
+}
+
+// PriceWatchConfig holds configuration for re-analyzing symbols after significant price moves
+type PriceWatchConfig struct {
+	Enabled         bool    // Run the watcher in the background (default: false)
+	IntervalSeconds int     // Seconds between price checks (default: 300)
+	MovePercent     float64 // Move since the last recommendation that triggers a re-analysis (default: 5)
+	RecentDays      int     // Also watch symbols recommended within this many days (default: 7)
+	MaxPerCycle     int     // Re-analyses queued per check at most (default: 3)
+	CooldownMinutes int     // Minimum minutes between triggers for the same symbol (default: 60)
+}
+
+// ReconciliationConfig holds configuration for the monthly broker reconciliation job
+type ReconciliationConfig struct {
+	Enabled bool // Reconcile each finished month against Alpaca in the background (default: true)
+}
+
+// RebalanceConfig holds the default target weights portfolio rebalancing moves toward.
+// Targets saved in settings take precedence.
+type RebalanceConfig struct {
+	PositionTargets map[string]float64 // Symbol to fraction of equity
+	SectorTargets   map[string]float64 // GICS sector to fraction of equity
+	DriftThreshold  float64            // Weights closer to target than this are left alone (default: 0.02)
+}
+
+// OptionsConfig holds where option chains come from and which calls are suggested for
+// writing against held shares
+type OptionsConfig struct {
+	Feed          string  // Alpaca option feed: indicative (free, delayed) or opra (needs a subscription) (default: indicative)
+	MinDays       int     // Fewest days to expiration of a suggested call (default: 14)
+	MaxDays       int     // Most days to expiration of a suggested call (default: 60)
+	MinOTMPercent float64 // Smallest distance of the strike above the share price, in percent (default: 2)
+	MaxOTMPercent float64 // Largest distance of the strike above the share price, in percent (default: 15)
+	MaxDelta      float64 // Calls more likely than this to be assigned are skipped, when the feed has greeks (default: 0.4)
+}
+
+// ExecutionConfig holds what happens when a recommendation is approved
+type ExecutionConfig struct {
+	Mode          string // manual (approve, then execute) or auto (approving places the order) (default: manual)
+	BracketOrders bool   // Attach stop-loss and take-profit legs to buys and shorts (default: false)
+}
+
+// Auto reports whether approving a recommendation places its order
+func (c ExecutionConfig) Auto() bool {
+	return c.Mode == "auto"
+}
+
+// RecommendationExpiryConfig holds when pending and approved recommendations go stale
+type RecommendationExpiryConfig struct {
+	Enabled             bool    // Expire stale recommendations in the background (default: true)
+	TTLHours            int     // Age at which an open recommendation expires; 0 disables (default: 72)
+	MaxDeviationPercent float64 // Price move from the entry price at which it expires; 0 disables (default: 10)
+	IntervalMinutes     int     // Minutes between checks (default: 15)
+}
+
+// FeeConfig holds the fee schedule applied to paper fills, which carry no broker fee data
+type FeeConfig struct {
+	CommissionPerTrade float64 // Flat commission per trade in dollars (default: 0)
+	CommissionPerShare float64 // Commission per share in dollars (default: 0)
+	SellFeeRate        float64 // Regulatory fee as a fraction of sell proceeds (default: 0)
+}
+
+// PortfolioReviewConfig holds configuration for analyzing every open position at once
+type PortfolioReviewConfig struct {
+	MaxPositions int // Largest positions analyzed per review; the rest are skipped (default: 25, 0 = no limit)
+}
+
+// PortfolioSnapshotsConfig holds configuration for recording the portfolio after each close
+type PortfolioSnapshotsConfig struct {
+	Enabled         bool // Record a snapshot of equity, cash and positions each trading day (default: true)
+	IntervalMinutes int  // Minutes between checks for a missing snapshot (default: 15)
+}
+
+// APILedgerConfig holds configuration for recording outbound API calls
+type APILedgerConfig struct {
+	RetentionDays int // Days individual calls are kept; daily totals are kept indefinitely (default: 30, 0 = forever)
+}
+
+// RateLimitConfig holds the request quotas enforced on outbound provider calls
+type RateLimitConfig struct {
+	FMPPerMinute          int // FMP requests per minute (default: 300, 0 = unlimited)
+	AlphaVantagePerMinute int // Alpha Vantage requests per minute (default: 5, 0 = unlimited)
+	NewsAPIPerDay         int // NewsAPI requests per day (default: 100, 0 = unlimited)
+	MaxWaitSeconds        int // Longest a request waits for its provider's quota before failing (default: 30)
+}
+
+// RetryConfig holds how failed FMP, NewsAPI, Alpha Vantage, Alpaca and LLM calls are retried.
+// Rate limits, server errors and network failures are retried; other errors are not.
+type RetryConfig struct {
+	MaxAttempts   int // Attempts per call, including the first (default: 4, 1 = no retries)
+	BaseDelayMs   int // Milliseconds before the first retry, doubled for each one after (default: 250)
+	MaxDelayMs    int // Longest wait between attempts; a longer Retry-After fails the call instead (default: 5000)
+	JitterPercent int // Random share taken off each wait, in percent (default: 20)
+}
+
+// WriteBufferConfig holds configuration for buffering non-critical database writes
+type WriteBufferConfig struct {
+	Capacity int // Writes held while the database is unreachable; the oldest are dropped beyond this (default: 1000)
+}
+
+// ComplianceConfig holds the disclaimer attached to recommendations, reports and exports
+type ComplianceConfig struct {
+	Jurisdiction          string // Preset disclaimer: us, uk, eu, ca, or au (default: us)
+	Disclaimer            string // Custom text replacing the preset (default: "")
+	RequireAcknowledgment bool   // Block approving and executing trades until the disclaimer is accepted (default: false)
+}
+
+// BackupConfig holds configuration for encrypted database backups to S3-compatible storage,
+// whose credentials are configured in settings
+type BackupConfig struct {
+	Enabled       bool   // Back up the database in the background (default: false)
+	IntervalHours int    // Hours between backups (default: 24)
+	Retention     int    // Backups kept in storage; older ones are deleted (default: 7)
+	EncryptionKey string // Passphrase backups are encrypted with before upload; required when enabled
+	PgDumpPath    string // pg_dump binary used to export the database (default: pg_dump)
+}
+
+// WarmupConfig holds configuration for preloading dashboard data on startup
+type WarmupConfig struct {
+	Enabled        bool // Preload positions, picks, pending recommendations and quotes before the window opens (default: true)
+	TimeoutSeconds int  // Longest startup waits for the warm-up; slower loads finish in the background (default: 3)
+}
+
+// RiskStatsConfig holds configuration for the trailing risk stats computed from daily bars
+type RiskStatsConfig struct {
+	LookbackDays int    // Calendar days of daily bars the stats cover (default: 365)
+	Benchmark    string // Symbol beta is measured against, also for the portfolio history (default: SPY)
+}
+
+// RiskManagerConfig holds the portfolio-level limits the risk manager holds new buys and
+// shorts to. A zero limit disables its check.
+type RiskManagerConfig struct {
+	Enabled          bool    // Review recommendations against the portfolio after synthesis (default: true)
+	MaxSectorPercent float64 // Largest share of equity held in one sector (default: 0.30)
+	MaxSymbolPercent float64 // Largest share of equity held in one symbol, counting correlated positions (default: 0.15)
+	MaxCorrelation   float64 // Return correlation above which a position counts toward the symbol's exposure (default: 0.80)
+	MaxDrawdown      float64 // Portfolio drawdown from its peak equity at which new positions are vetoed (default: 0.15)
+	LookbackDays     int     // Calendar days of history for correlations and the equity peak (default: 90)
+}
+
+// CacheRefreshConfig holds configuration for refreshing hot cache entries (quotes for
+// holdings, ratios for the latest picks) in the background while the market is open
+type CacheRefreshConfig struct {
+	Enabled              bool // Refresh in the background (default: true)
+	QuoteIntervalSeconds int  // Base seconds between quote refreshes, kept below the quote cache lifetime (default: 10)
+	RatioIntervalMinutes int  // Base minutes between ratio refreshes (default: 30)
+	JitterPercent        int  // Random spread applied to each interval, in percent (default: 20)
+	AlpacaCallsPerMinute int  // Alpaca calls the refresher may make per minute (default: 60)
+	FMPCallsPerDay       int  // FMP calls the refresher may make per day (default: 50)
+}
+
+// ResponseCacheConfig holds how long each kind of provider response is kept in the
+// market data cache. A zero TTL stops caching that kind.
+type ResponseCacheConfig struct {
+	Enabled                bool // Serve repeated FMP, Alpha Vantage and NewsAPI lookups from the cache (default: true)
+	FundamentalsTTLMinutes int  // Company profiles, overviews and balance sheets (default: 1440)
+	RatiosTTLMinutes       int  // FMP TTM ratios, which move with the price (default: 60)
+	NewsTTLMinutes         int  // News searches and headlines (default: 30)
+	EarningsTTLMinutes     int  // Earnings calendars (default: 720)
+	InsiderTTLMinutes      int  // Insider trading filings (default: 360)
+}
+
+// LoggingConfig holds log verbosity configuration. Levels can also be changed at runtime
+// through /api/admin/log-level.
+type LoggingConfig struct {
+	Level        string            // Default level: debug, info, warn, or error (default: info)
+	ModuleLevels map[string]string // Levels for individual modules (api, agents, screener, services, repository); others use Level
+}
+
+// TracingConfig holds OpenTelemetry trace export configuration, read from the standard
+// OTEL_* variables. Tracing is off unless an OTLP endpoint is set.
+type TracingConfig struct {
+	Endpoint    string            // OTLP/HTTP traces URL, from OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT plus /v1/traces
+	Headers     map[string]string // Headers sent with each export, such as an API key (OTEL_EXPORTER_OTLP_HEADERS)
+	ServiceName string            // Service name on exported spans (default: trade-machine)
+	SampleRatio float64           // Fraction of traces recorded, from 0 to 1 (OTEL_TRACES_SAMPLER_ARG, default: 1)
+}
+
+// EventsConfig holds where domain events are forwarded besides the in-process subscribers.
+// Forwarding is off unless a NATS URL is set.
+type EventsConfig struct {
+	NATSURL       string // NATS server events are published to, such as nats://localhost:4222
+	SubjectPrefix string // Events go to <prefix>.<type>, e.g. trade_machine.trade.filled (default: trade_machine)
+}
+
+// TrayConfig holds configuration for the status menu showing the market session and
+// pending recommendations, with quick actions
+type TrayConfig struct {
+	Enabled        bool // Show the status menu (default: true)
+	RefreshSeconds int  // Seconds between status refreshes (default: 30)
 }
 
 // HTTPConfig holds HTTP server configuration
 type HTTPConfig struct {
 	CORSAllowedOrigins string
+	AuthEnabled        bool   // Require an API token with the right scope on /api and /metrics (default: false)
+	AdminToken         string // Secret accepted with every scope, for creating the first tokens (optional)
 }
 
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
+	classThresholds, err := ParseClassThresholds(os.Getenv("AGENT_CLASS_THRESHOLDS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AGENT_CLASS_THRESHOLDS: %w", err)
+	}
+
+	typeOverrides, err := ParseAgentOverrides(os.Getenv("AGENT_TYPE_OVERRIDES"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AGENT_TYPE_OVERRIDES: %w", err)
+	}
+
+	moduleLevels, err := ParseModuleLevels(os.Getenv("LOG_MODULE_LEVELS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOG_MODULE_LEVELS: %w", err)
+	}
+
+	otlpHeaders, err := ParseOTLPHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS: %w", err)
+	}
+
+	rankingWeights, err := ParseRankingWeights(os.Getenv("SCREENER_RANKING_WEIGHTS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCREENER_RANKING_WEIGHTS: %w", err)
+	}
+
+	positionTargets, err := ParseTargetWeights(os.Getenv("REBALANCE_POSITION_TARGETS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REBALANCE_POSITION_TARGETS: %w", err)
+	}
+
+	sectorTargets, err := ParseTargetWeights(os.Getenv("REBALANCE_SECTOR_TARGETS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REBALANCE_SECTOR_TARGETS: %w", err)
+	}
+
+	llmRoutes, err := ParseLLMRoutes(os.Getenv("LLM_ROUTES"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LLM_ROUTES: %w", err)
+	}
+
 	cfg := &Config{
 		Database: DatabaseConfig{
 			URL: os.Getenv("DATABASE_URL"),
 		},
 		OpenAI: OpenAIConfig{
-			APIKey:    os.Getenv("OPENAI_API_KEY"),
-			Model:     getEnvString("OPENAI_MODEL", "gpt-4o"),
-			MaxTokens: getEnvInt("OPENAI_MAX_TOKENS", 4096),
+			APIKey:              os.Getenv("OPENAI_API_KEY"),
+			Model:               getEnvString("OPENAI_MODEL", "gpt-4o"),
+			MaxTokens:           getEnvInt("OPENAI_MAX_TOKENS", 4096),
+			EmbeddingModel:      getEnvString("OPENAI_EMBEDDING_MODEL", "text-embedding-3-small"),
+			EmbeddingDimensions: getEnvInt("OPENAI_EMBEDDING_DIMENSIONS", 0),
+		},
+		LLM: LLMConfig{
+			Provider:         strings.ToLower(strings.TrimSpace(os.Getenv("LLM_PROVIDER"))),
+			Routes:           llmRoutes,
+			FallbackProvider: strings.ToLower(strings.TrimSpace(os.Getenv("LLM_FALLBACK_PROVIDER"))),
+			MonthlyBudget:    getEnvFloatUnbounded("LLM_MONTHLY_BUDGET", 0),
+			BudgetMode:       strings.ToLower(getEnvString("LLM_BUDGET_MODE", "warn")),
+		},
+		Anthropic: AnthropicConfig{
+			APIKey:    os.Getenv("ANTHROPIC_API_KEY"),
+			Model:     getEnvString("ANTHROPIC_MODEL", "claude-sonnet-4-5"),
+			MaxTokens: getEnvInt("ANTHROPIC_MAX_TOKENS", 4096),
+			BaseURL:   getEnvString("ANTHROPIC_BASE_URL", "https://api.anthropic.com"),
+		},
+		Ollama: OllamaConfig{
+			BaseURL:   os.Getenv("OLLAMA_BASE_URL"),
+			Model:     getEnvString("OLLAMA_MODEL", "llama3.1"),
+			MaxTokens: getEnvInt("OLLAMA_MAX_TOKENS", 4096),
 		},
 		Alpaca: AlpacaConfig{
 			APIKey:    os.Getenv("ALPACA_API_KEY"),
 			APISecret: os.Getenv("ALPACA_API_SECRET"),
 			BaseURL:   getEnvString("ALPACA_BASE_URL", "https://paper-api.alpaca.markets"),
 		},
+		IBKR: IBKRConfig{
+			GatewayURL:         os.Getenv("IBKR_GATEWAY_URL"),
+			AccountID:          os.Getenv("IBKR_ACCOUNT_ID"),
+			InsecureSkipVerify: getEnvBool("IBKR_INSECURE_SKIP_VERIFY", false),
+		},
 		AlphaVantage: AlphaVantageConfig{
 			APIKey: os.Getenv("ALPHA_VANTAGE_API_KEY"),
 		},
 		NewsAPI: NewsAPIConfig{
 			APIKey: os.Getenv("NEWS_API_KEY"),
 		},
+		Social: SocialConfig{
+			Enabled:    getEnvBool("SOCIAL_SENTIMENT_ENABLED", false),
+			Subreddits: getEnvList("SOCIAL_REDDIT_SUBREDDITS", []string{"wallstreetbets", "stocks", "investing"}),
+			UserAgent:  getEnvString("SOCIAL_USER_AGENT", "trade-machine/1.0"),
+		},
 		FMP: FMPConfig{
 			APIKey: os.Getenv("FMP_API_KEY"),
 		},
+		FRED: FREDConfig{
+			APIKey: os.Getenv("FRED_API_KEY"),
+		},
+		Broker: BrokerConfig{
+			Provider: strings.ToLower(getEnvString("BROKER", "alpaca")),
+		},
 		Agent: AgentConfig{
 			TimeoutSeconds:        getEnvInt("AGENT_TIMEOUT_SECONDS", 30),
 			ConcurrencyLimit:      getEnvInt("ANALYSIS_CONCURRENCY_LIMIT", 3),
 			TechnicalLookbackDays: getEnvInt("TECHNICAL_ANALYSIS_LOOKBACK_DAYS", 100),
+			NewsLookbackDays:      getEnvInt("NEWS_LOOKBACK_DAYS", 7),
+			NewsHalfLifeHours:     getEnvInt("NEWS_RECENCY_HALF_LIFE_HOURS", 24),
 			WeightFundamental:     getEnvFloat("AGENT_WEIGHT_FUNDAMENTAL", 0.4),
 			WeightNews:            getEnvFloat("AGENT_WEIGHT_NEWS", 0.3),
 			WeightTechnical:       getEnvFloat("AGENT_WEIGHT_TECHNICAL", 0.3),
+			WeightSocial:          getEnvFloat("AGENT_WEIGHT_SOCIAL", 0),
+			WeightInsider:         getEnvFloat("AGENT_WEIGHT_INSIDER", 0),
+			InsiderLookbackDays:   getEnvInt("INSIDER_LOOKBACK_DAYS", 90),
+			WeightMacro:           getEnvFloat("AGENT_WEIGHT_MACRO", 0),
 			Strategy:              getEnvString("AGENT_STRATEGY", "default"),
 			BuyThreshold:          getEnvFloatUnbounded("AGENT_BUY_THRESHOLD", 25),
 			SellThreshold:         getEnvFloatUnbounded("AGENT_SELL_THRESHOLD", -25),
 			MinConfidence:         getEnvFloatUnbounded("AGENT_MIN_CONFIDENCE", 0),
 			HealthCacheTTLSeconds: getEnvInt("AGENT_HEALTH_CACHE_TTL_SECONDS", 30),
+			Language:              getEnvString("AGENT_LANGUAGE", "en"),
+			MinRiskReward:         getEnvFloatUnbounded("AGENT_MIN_RISK_REWARD", 1.5),
+			StopLossPercent:       getEnvFloatRange("AGENT_STOP_LOSS_PERCENT", 0.05, 0.001, 0.5),
+			TakeProfitPercent:     getEnvFloatRange("AGENT_TAKE_PROFIT_PERCENT", 0.10, 0.001, 2.0),
+			WeightPolicy:          getEnvString("AGENT_WEIGHT_POLICY", "redistribute"),
+			SignalOnly:            getEnvBool("AGENT_SIGNAL_ONLY", false),
+			LatencyBudgetSeconds:  getEnvInt("AGENT_LATENCY_BUDGET_SECONDS", 0),
+			Horizon:               getEnvString("AGENT_HORIZON", "medium"),
+			ClassThresholds:       classThresholds,
+			TypeOverrides:         typeOverrides,
 		},
 		PositionSizing: PositionSizingConfig{
-			MaxPositionPercent:   getEnvFloatRange("POSITION_MAX_PERCENT", 0.10, 0.01, 1.0),
-			RiskPercent:          getEnvFloatRange("POSITION_RISK_PERCENT", 0.02, 0.001, 0.1),
-			MinShares:            int64(getEnvInt("POSITION_MIN_SHARES", 1)),
-			MaxShares:            int64(getEnvInt("POSITION_MAX_SHARES", 0)),
-			UseConfidenceScaling: getEnvBool("POSITION_USE_CONFIDENCE_SCALING", true),
+			MaxPositionPercent:     getEnvFloatRange("POSITION_MAX_PERCENT", 0.10, 0.01, 1.0),
+			RiskPercent:            getEnvFloatRange("POSITION_RISK_PERCENT", 0.02, 0.001, 0.1),
+			MinShares:              int64(getEnvInt("POSITION_MIN_SHARES", 1)),
+			MaxShares:              int64(getEnvInt("POSITION_MAX_SHARES", 0)),
+			UseConfidenceScaling:   getEnvBool("POSITION_USE_CONFIDENCE_SCALING", true),
+			AllowShorts:            getEnvBool("POSITION_ALLOW_SHORTS", false),
+			AllowHardToBorrow:      getEnvBool("POSITION_ALLOW_HARD_TO_BORROW", false),
+			ShortMarginRequirement: getEnvFloatRange("POSITION_SHORT_MARGIN_REQUIREMENT", 1.5, 1.0, 3.0),
+			MaxADVPercent:          getEnvFloat("POSITION_MAX_ADV_PERCENT", 0),
+			ADVMode:                getEnvString("POSITION_ADV_MODE", "warn"),
+			ADVLookbackDays:        getEnvInt("POSITION_ADV_LOOKBACK_DAYS", 30),
+			TargetVolatility:       getEnvFloat("POSITION_TARGET_VOLATILITY", 0),
 		},
 		Screener: ScreenerConfig{
 			MarketCapMin:       int64(getEnvInt("SCREENER_MARKET_CAP_MIN", 1_000_000_000)),
@@ -160,9 +699,151 @@ func Load() (*Config, error) {
 			TopPicksCount:      getEnvInt("SCREENER_TOP_PICKS_COUNT", 3),
 			AnalysisTimeoutSec: getEnvInt("SCREENER_ANALYSIS_TIMEOUT_SEC", 120),
 			MaxConcurrent:      getEnvInt("SCREENER_MAX_CONCURRENT", 5),
+			RateLimitPauseMs:   getEnvInt("SCREENER_RATE_LIMIT_PAUSE_MS", 2000),
+			Exchanges:          getEnvList("SCREENER_EXCHANGES", []string{"NYSE", "NASDAQ", "AMEX"}),
+			Country:            getEnvString("SCREENER_COUNTRY", "US"),
+			PriceMin:           getEnvFloatUnbounded("SCREENER_PRICE_MIN", 0),
+			AvgVolumeMin:       int64(getEnvInt("SCREENER_AVG_VOLUME_MIN", 0)),
+			DollarVolumeMin:    getEnvFloatUnbounded("SCREENER_DOLLAR_VOLUME_MIN", 0),
+			MinListingMonths:   getEnvInt("SCREENER_MIN_LISTING_MONTHS", 0),
+			RecentListingMode:  getEnvString("SCREENER_RECENT_LISTING_MODE", "exclude"),
+			RankingStrategy:    getEnvString("SCREENER_RANKING_STRATEGY", "default"),
+			RankingWeights:     rankingWeights,
+			Schedule: ScreenerScheduleConfig{
+				Cron:    getEnvString("SCREENER_SCHEDULE", ""),
+				Analyze: getEnvBool("SCREENER_SCHEDULE_ANALYZE", true),
+			},
+		},
+		PriceWatch: PriceWatchConfig{
+			Enabled:         getEnvBool("PRICE_WATCH_ENABLED", false),
+			IntervalSeconds: getEnvInt("PRICE_WATCH_INTERVAL_SECONDS", 300),
+			MovePercent:     getEnvFloatRange("PRICE_WATCH_MOVE_PERCENT", 5.0, 0.1, 100),
+			RecentDays:      getEnvInt("PRICE_WATCH_RECENT_DAYS", 7),
+			MaxPerCycle:     getEnvInt("PRICE_WATCH_MAX_PER_CYCLE", 3),
+			CooldownMinutes: getEnvInt("PRICE_WATCH_COOLDOWN_MINUTES", 60),
+		},
+		Reconciliation: ReconciliationConfig{
+			Enabled: getEnvBool("RECONCILIATION_ENABLED", true),
+		},
+		Rebalance: RebalanceConfig{
+			PositionTargets: positionTargets,
+			SectorTargets:   sectorTargets,
+			DriftThreshold:  getEnvFloat("REBALANCE_DRIFT_THRESHOLD", 0.02),
+		},
+		Options: OptionsConfig{
+			Feed:          strings.ToLower(getEnvString("OPTIONS_FEED", "indicative")),
+			MinDays:       getEnvInt("COVERED_CALL_MIN_DAYS", 14),
+			MaxDays:       getEnvInt("COVERED_CALL_MAX_DAYS", 60),
+			MinOTMPercent: getEnvFloat("COVERED_CALL_MIN_OTM_PERCENT", 2),
+			MaxOTMPercent: getEnvFloat("COVERED_CALL_MAX_OTM_PERCENT", 15),
+			MaxDelta:      getEnvFloat("COVERED_CALL_MAX_DELTA", 0.4),
+		},
+		Execution: ExecutionConfig{
+			Mode:          strings.ToLower(getEnvString("EXECUTION_MODE", "manual")),
+			BracketOrders: getEnvBool("EXECUTION_BRACKET_ORDERS", false),
+		},
+		RecommendationExpiry: RecommendationExpiryConfig{
+			Enabled:             getEnvBool("RECOMMENDATION_EXPIRY_ENABLED", true),
+			TTLHours:            getEnvInt("RECOMMENDATION_TTL_HOURS", 72),
+			MaxDeviationPercent: getEnvFloatRange("RECOMMENDATION_MAX_DEVIATION_PERCENT", 10, 0, 100),
+			IntervalMinutes:     getEnvInt("RECOMMENDATION_EXPIRY_INTERVAL_MINUTES", 15),
+		},
+		Fees: FeeConfig{
+			CommissionPerTrade: getEnvFloatRange("FEE_COMMISSION_PER_TRADE", 0, 0, 1000),
+			CommissionPerShare: getEnvFloatRange("FEE_COMMISSION_PER_SHARE", 0, 0, 10),
+			SellFeeRate:        getEnvFloatRange("FEE_SELL_RATE", 0, 0, 0.01),
+		},
+		PortfolioReview: PortfolioReviewConfig{
+			MaxPositions: getEnvInt("PORTFOLIO_REVIEW_MAX_POSITIONS", 25),
+		},
+		PortfolioSnapshots: PortfolioSnapshotsConfig{
+			Enabled:         getEnvBool("PORTFOLIO_SNAPSHOTS_ENABLED", true),
+			IntervalMinutes: getEnvInt("PORTFOLIO_SNAPSHOT_INTERVAL_MINUTES", 15),
+		},
+		APILedger: APILedgerConfig{
+			RetentionDays: getEnvInt("API_LEDGER_RETENTION_DAYS", 30),
+		},
+		RateLimits: RateLimitConfig{
+			FMPPerMinute:          getEnvInt("FMP_RATE_LIMIT_PER_MINUTE", 300),
+			AlphaVantagePerMinute: getEnvInt("ALPHAVANTAGE_RATE_LIMIT_PER_MINUTE", 5),
+			NewsAPIPerDay:         getEnvInt("NEWSAPI_RATE_LIMIT_PER_DAY", 100),
+			MaxWaitSeconds:        getEnvInt("RATE_LIMIT_MAX_WAIT_SECONDS", 30),
+		},
+		Retry: RetryConfig{
+			MaxAttempts:   getEnvInt("RETRY_MAX_ATTEMPTS", 4),
+			BaseDelayMs:   getEnvInt("RETRY_BASE_DELAY_MS", 250),
+			MaxDelayMs:    getEnvInt("RETRY_MAX_DELAY_MS", 5000),
+			JitterPercent: getEnvInt("RETRY_JITTER_PERCENT", 20),
+		},
+		WriteBuffer: WriteBufferConfig{
+			Capacity: getEnvInt("WRITE_BUFFER_CAPACITY", 1000),
+		},
+		Compliance: ComplianceConfig{
+			Jurisdiction:          getEnvString("COMPLIANCE_JURISDICTION", "us"),
+			Disclaimer:            getEnvString("COMPLIANCE_DISCLAIMER", ""),
+			RequireAcknowledgment: getEnvBool("COMPLIANCE_REQUIRE_ACKNOWLEDGMENT", false),
+		},
+		Backup: BackupConfig{
+			Enabled:       getEnvBool("BACKUP_ENABLED", false),
+			IntervalHours: getEnvInt("BACKUP_INTERVAL_HOURS", 24),
+			Retention:     getEnvInt("BACKUP_RETENTION", 7),
+			EncryptionKey: getEnvString("BACKUP_ENCRYPTION_KEY", ""),
+			PgDumpPath:    getEnvString("BACKUP_PG_DUMP_PATH", "pg_dump"),
+		},
+		Warmup: WarmupConfig{
+			Enabled:        getEnvBool("WARMUP_ENABLED", true),
+			TimeoutSeconds: getEnvInt("WARMUP_TIMEOUT_SECONDS", 3),
+		},
+		CacheRefresh: CacheRefreshConfig{
+			Enabled:              getEnvBool("CACHE_REFRESH_ENABLED", true),
+			QuoteIntervalSeconds: getEnvInt("CACHE_REFRESH_QUOTE_INTERVAL_SECONDS", 10),
+			RatioIntervalMinutes: getEnvInt("CACHE_REFRESH_RATIO_INTERVAL_MINUTES", 30),
+			JitterPercent:        getEnvInt("CACHE_REFRESH_JITTER_PERCENT", 20),
+			AlpacaCallsPerMinute: getEnvInt("CACHE_REFRESH_ALPACA_CALLS_PER_MINUTE", 60),
+			FMPCallsPerDay:       getEnvInt("CACHE_REFRESH_FMP_CALLS_PER_DAY", 50),
+		},
+		ResponseCache: ResponseCacheConfig{
+			Enabled:                getEnvBool("RESPONSE_CACHE_ENABLED", true),
+			FundamentalsTTLMinutes: getEnvInt("RESPONSE_CACHE_FUNDAMENTALS_TTL_MINUTES", 1440),
+			RatiosTTLMinutes:       getEnvInt("RESPONSE_CACHE_RATIOS_TTL_MINUTES", 60),
+			NewsTTLMinutes:         getEnvInt("RESPONSE_CACHE_NEWS_TTL_MINUTES", 30),
+			EarningsTTLMinutes:     getEnvInt("RESPONSE_CACHE_EARNINGS_TTL_MINUTES", 720),
+			InsiderTTLMinutes:      getEnvInt("RESPONSE_CACHE_INSIDER_TTL_MINUTES", 360),
+		},
+		Logging: LoggingConfig{
+			Level:        getEnvString("LOG_LEVEL", "info"),
+			ModuleLevels: moduleLevels,
+		},
+		Tracing: TracingConfig{
+			Endpoint:    otlpTracesEndpoint(),
+			Headers:     otlpHeaders,
+			ServiceName: getEnvString("OTEL_SERVICE_NAME", "trade-machine"),
+			SampleRatio: getEnvFloat("OTEL_TRACES_SAMPLER_ARG", 1),
+		},
+		Events: EventsConfig{
+			NATSURL:       os.Getenv("EVENTS_NATS_URL"),
+			SubjectPrefix: getEnvString("EVENTS_NATS_SUBJECT_PREFIX", "trade_machine"),
+		},
+		Tray: TrayConfig{
+			Enabled:        getEnvBool("TRAY_ENABLED", true),
+			RefreshSeconds: getEnvInt("TRAY_REFRESH_SECONDS", 30),
+		},
+		RiskStats: RiskStatsConfig{
+			LookbackDays: getEnvInt("RISK_STATS_LOOKBACK_DAYS", 365),
+			Benchmark:    strings.ToUpper(getEnvString("RISK_STATS_BENCHMARK", "SPY")),
+		},
+		RiskManager: RiskManagerConfig{
+			Enabled:          getEnvBool("RISK_MANAGER_ENABLED", true),
+			MaxSectorPercent: getEnvFloat("RISK_MANAGER_MAX_SECTOR_PERCENT", 0.30),
+			MaxSymbolPercent: getEnvFloat("RISK_MANAGER_MAX_SYMBOL_PERCENT", 0.15),
+			MaxCorrelation:   getEnvFloat("RISK_MANAGER_MAX_CORRELATION", 0.80),
+			MaxDrawdown:      getEnvFloat("RISK_MANAGER_MAX_DRAWDOWN", 0.15),
+			LookbackDays:     getEnvInt("RISK_MANAGER_LOOKBACK_DAYS", 90),
 		},
 		HTTP: HTTPConfig{
 			CORSAllowedOrigins: getEnvString("CORS_ALLOWED_ORIGINS", "*"),
+			AuthEnabled:        getEnvBool("API_AUTH_ENABLED", false),
+			AdminToken:         os.Getenv("API_ADMIN_TOKEN"),
 		},
 	}
 
@@ -176,10 +857,10 @@ func Load() (*Config, error) {
 // Validate validates the configuration
 func (c *Config) Validate() error {
 	// Validate agent weights sum to 1.0
-	weightSum := c.Agent.WeightFundamental + c.Agent.WeightNews + c.Agent.WeightTechnical
+	weightSum := c.Agent.WeightFundamental + c.Agent.WeightNews + c.Agent.WeightTechnical + c.Agent.WeightSocial + c.Agent.WeightInsider + c.Agent.WeightMacro
 	if weightSum < 0.99 || weightSum > 1.01 {
-		return fmt.Errorf("agent weights must sum to 1.0, got %.2f (fundamental=%.2f, news=%.2f, technical=%.2f)",
-			weightSum, c.Agent.WeightFundamental, c.Agent.WeightNews, c.Agent.WeightTechnical)
+		return fmt.Errorf("agent weights must sum to 1.0, got %.2f (fundamental=%.2f, news=%.2f, technical=%.2f, social=%.2f, insider=%.2f, macro=%.2f)",
+			weightSum, c.Agent.WeightFundamental, c.Agent.WeightNews, c.Agent.WeightTechnical, c.Agent.WeightSocial, c.Agent.WeightInsider, c.Agent.WeightMacro)
 	}
 
 	// Validate weight ranges
@@ -192,6 +873,30 @@ func (c *Config) Validate() error {
 	if c.Agent.WeightTechnical < 0 || c.Agent.WeightTechnical > 1 {
 		return fmt.Errorf("AGENT_WEIGHT_TECHNICAL must be between 0 and 1, got %.2f", c.Agent.WeightTechnical)
 	}
+	if c.Agent.WeightSocial < 0 || c.Agent.WeightSocial > 1 {
+		return fmt.Errorf("AGENT_WEIGHT_SOCIAL must be between 0 and 1, got %.2f", c.Agent.WeightSocial)
+	}
+	if c.Agent.WeightInsider < 0 || c.Agent.WeightInsider > 1 {
+		return fmt.Errorf("AGENT_WEIGHT_INSIDER must be between 0 and 1, got %.2f", c.Agent.WeightInsider)
+	}
+	if c.Agent.WeightInsider > 0 && c.Agent.InsiderLookbackDays <= 0 {
+		return fmt.Errorf("INSIDER_LOOKBACK_DAYS must be positive, got %d", c.Agent.InsiderLookbackDays)
+	}
+	if c.Agent.WeightMacro < 0 || c.Agent.WeightMacro > 1 {
+		return fmt.Errorf("AGENT_WEIGHT_MACRO must be between 0 and 1, got %.2f", c.Agent.WeightMacro)
+	}
+	if c.Agent.WeightMacro > 0 && !c.HasFRED() {
+		return fmt.Errorf("AGENT_WEIGHT_MACRO requires FRED_API_KEY")
+	}
+	if !slices.Contains(brokers, c.Broker.Provider) {
+		return fmt.Errorf("BROKER must be one of %s, got %q", strings.Join(brokers, ", "), c.Broker.Provider)
+	}
+	if c.Broker.Provider == "ibkr" && !c.HasIBKR() {
+		return fmt.Errorf("BROKER=ibkr requires IBKR_GATEWAY_URL")
+	}
+	if c.Social.Enabled && len(c.Social.Subreddits) == 0 {
+		return fmt.Errorf("SOCIAL_REDDIT_SUBREDDITS must list at least one subreddit when SOCIAL_SENTIMENT_ENABLED is set")
+	}
 
 	// Validate positive integers
 	if c.Agent.TimeoutSeconds <= 0 {
@@ -203,6 +908,286 @@ func (c *Config) Validate() error {
 	if c.Agent.TechnicalLookbackDays <= 0 {
 		return fmt.Errorf("TECHNICAL_ANALYSIS_LOOKBACK_DAYS must be positive, got %d", c.Agent.TechnicalLookbackDays)
 	}
+	if c.Agent.NewsLookbackDays <= 0 {
+		return fmt.Errorf("NEWS_LOOKBACK_DAYS must be positive, got %d", c.Agent.NewsLookbackDays)
+	}
+	if c.Agent.NewsHalfLifeHours <= 0 {
+		return fmt.Errorf("NEWS_RECENCY_HALF_LIFE_HOURS must be positive, got %d", c.Agent.NewsHalfLifeHours)
+	}
+	if c.Agent.LatencyBudgetSeconds < 0 {
+		return fmt.Errorf("AGENT_LATENCY_BUDGET_SECONDS must not be negative, got %d", c.Agent.LatencyBudgetSeconds)
+	}
+	if c.Agent.MinRiskReward < 0 {
+		return fmt.Errorf("AGENT_MIN_RISK_REWARD must not be negative, got %.2f", c.Agent.MinRiskReward)
+	}
+	switch c.Execution.Mode {
+	case "manual", "auto":
+	default:
+		return fmt.Errorf("EXECUTION_MODE must be manual or auto, got %q", c.Execution.Mode)
+	}
+	switch c.LLM.Provider {
+	case "", "openai", "anthropic", "ollama":
+	default:
+		return fmt.Errorf("LLM_PROVIDER must be openai, anthropic, or ollama, got %q", c.LLM.Provider)
+	}
+	switch c.LLM.FallbackProvider {
+	case "", "openai", "anthropic", "ollama":
+	default:
+		return fmt.Errorf("LLM_FALLBACK_PROVIDER must be openai, anthropic, or ollama, got %q", c.LLM.FallbackProvider)
+	}
+	for agentType, route := range c.LLM.Routes {
+		if !slices.Contains(llmAgentTypes, agentType) {
+			return fmt.Errorf("LLM_ROUTES has unknown agent type %q, expected one of %s", agentType, strings.Join(llmAgentTypes, ", "))
+		}
+		switch route.Provider {
+		case "openai", "anthropic", "ollama":
+		default:
+			return fmt.Errorf("LLM_ROUTES %s provider must be openai, anthropic, or ollama, got %q", agentType, route.Provider)
+		}
+	}
+	if c.LLM.MonthlyBudget < 0 {
+		return fmt.Errorf("LLM_MONTHLY_BUDGET must not be negative, got %.2f", c.LLM.MonthlyBudget)
+	}
+	switch c.LLM.BudgetMode {
+	case "warn", "block":
+	default:
+		return fmt.Errorf("LLM_BUDGET_MODE must be warn or block, got %q", c.LLM.BudgetMode)
+	}
+	if c.OpenAI.EmbeddingDimensions < 0 {
+		return fmt.Errorf("OPENAI_EMBEDDING_DIMENSIONS must not be negative, got %d", c.OpenAI.EmbeddingDimensions)
+	}
+	switch c.Agent.WeightPolicy {
+	case "redistribute", "floor", "abstain":
+	default:
+		return fmt.Errorf("AGENT_WEIGHT_POLICY must be redistribute, floor, or abstain, got %q", c.Agent.WeightPolicy)
+	}
+	switch c.Agent.Language {
+	case "en", "es", "fr", "de", "pt", "it", "ja", "zh":
+	default:
+		return fmt.Errorf("AGENT_LANGUAGE must be one of en, es, fr, de, pt, it, ja, zh, got %q", c.Agent.Language)
+	}
+	switch c.Agent.Horizon {
+	case "short", "medium", "long", "overall":
+	default:
+		return fmt.Errorf("AGENT_HORIZON must be short, medium, long, or overall, got %q", c.Agent.Horizon)
+	}
+	for agentType, o := range c.Agent.TypeOverrides {
+		if !slices.Contains(overridableAgentTypes, agentType) {
+			return fmt.Errorf("AGENT_TYPE_OVERRIDES has unknown agent type %q, expected one of %s", agentType, strings.Join(overridableAgentTypes, ", "))
+		}
+		if o.TimeoutSeconds < 0 {
+			return fmt.Errorf("AGENT_TYPE_OVERRIDES %s timeout must not be negative, got %d", agentType, o.TimeoutSeconds)
+		}
+		if o.Retries < 0 || o.Retries > maxAgentRetries {
+			return fmt.Errorf("AGENT_TYPE_OVERRIDES %s retries must be between 0 and %d, got %d", agentType, maxAgentRetries, o.Retries)
+		}
+	}
+	switch c.PositionSizing.ADVMode {
+	case "warn", "reject":
+	default:
+		return fmt.Errorf("POSITION_ADV_MODE must be warn or reject, got %q", c.PositionSizing.ADVMode)
+	}
+	if c.PositionSizing.ADVLookbackDays <= 0 {
+		return fmt.Errorf("POSITION_ADV_LOOKBACK_DAYS must be positive, got %d", c.PositionSizing.ADVLookbackDays)
+	}
+	if c.PositionSizing.TargetVolatility < 0 {
+		return fmt.Errorf("POSITION_TARGET_VOLATILITY must not be negative, got %.2f", c.PositionSizing.TargetVolatility)
+	}
+	if c.Screener.DollarVolumeMin < 0 {
+		return fmt.Errorf("SCREENER_DOLLAR_VOLUME_MIN must not be negative, got %.2f", c.Screener.DollarVolumeMin)
+	}
+	if c.RecommendationExpiry.TTLHours < 0 {
+		return fmt.Errorf("RECOMMENDATION_TTL_HOURS must not be negative, got %d", c.RecommendationExpiry.TTLHours)
+	}
+	if c.RecommendationExpiry.Enabled && c.RecommendationExpiry.IntervalMinutes <= 0 {
+		return fmt.Errorf("RECOMMENDATION_EXPIRY_INTERVAL_MINUTES must be positive, got %d", c.RecommendationExpiry.IntervalMinutes)
+	}
+	if c.PortfolioSnapshots.Enabled && c.PortfolioSnapshots.IntervalMinutes <= 0 {
+		return fmt.Errorf("PORTFOLIO_SNAPSHOT_INTERVAL_MINUTES must be positive, got %d", c.PortfolioSnapshots.IntervalMinutes)
+	}
+	for _, ttl := range []struct {
+		name  string
+		value int
+	}{
+		{"RESPONSE_CACHE_FUNDAMENTALS_TTL_MINUTES", c.ResponseCache.FundamentalsTTLMinutes},
+		{"RESPONSE_CACHE_RATIOS_TTL_MINUTES", c.ResponseCache.RatiosTTLMinutes},
+		{"RESPONSE_CACHE_NEWS_TTL_MINUTES", c.ResponseCache.NewsTTLMinutes},
+		{"RESPONSE_CACHE_EARNINGS_TTL_MINUTES", c.ResponseCache.EarningsTTLMinutes},
+		{"RESPONSE_CACHE_INSIDER_TTL_MINUTES", c.ResponseCache.InsiderTTLMinutes},
+	} {
+		if ttl.value < 0 {
+			return fmt.Errorf("%s must not be negative, got %d", ttl.name, ttl.value)
+		}
+	}
+	for _, limit := range []struct {
+		name  string
+		value int
+	}{
+		{"FMP_RATE_LIMIT_PER_MINUTE", c.RateLimits.FMPPerMinute},
+		{"ALPHAVANTAGE_RATE_LIMIT_PER_MINUTE", c.RateLimits.AlphaVantagePerMinute},
+		{"NEWSAPI_RATE_LIMIT_PER_DAY", c.RateLimits.NewsAPIPerDay},
+		{"RATE_LIMIT_MAX_WAIT_SECONDS", c.RateLimits.MaxWaitSeconds},
+	} {
+		if limit.value < 0 {
+			return fmt.Errorf("%s must not be negative, got %d", limit.name, limit.value)
+		}
+	}
+	if c.Retry.MaxAttempts < 1 {
+		return fmt.Errorf("RETRY_MAX_ATTEMPTS must be at least 1, got %d", c.Retry.MaxAttempts)
+	}
+	if c.Retry.BaseDelayMs < 0 || c.Retry.MaxDelayMs < c.Retry.BaseDelayMs {
+		return fmt.Errorf("RETRY_BASE_DELAY_MS must not be negative or exceed RETRY_MAX_DELAY_MS, got %d and %d", c.Retry.BaseDelayMs, c.Retry.MaxDelayMs)
+	}
+	if c.Retry.JitterPercent < 0 || c.Retry.JitterPercent > 100 {
+		return fmt.Errorf("RETRY_JITTER_PERCENT must be between 0 and 100, got %d", c.Retry.JitterPercent)
+	}
+	switch c.Screener.RecentListingMode {
+	case "exclude", "flag":
+	default:
+		return fmt.Errorf("SCREENER_RECENT_LISTING_MODE must be exclude or flag, got %q", c.Screener.RecentListingMode)
+	}
+	// The spec is parsed when the schedule starts; config does not import models
+	if n := len(strings.Fields(c.Screener.Schedule.Cron)); n != 0 && n != 5 {
+		return fmt.Errorf("SCREENER_SCHEDULE must have five fields (minute hour day month weekday), got %q", c.Screener.Schedule.Cron)
+	}
+	if !slices.Contains(rankingStrategies, c.Screener.RankingStrategy) {
+		return fmt.Errorf("SCREENER_RANKING_STRATEGY must be one of %s, got %q", strings.Join(rankingStrategies, ", "), c.Screener.RankingStrategy)
+	}
+	if c.Screener.RankingStrategy == "custom" {
+		if len(c.Screener.RankingWeights) == 0 {
+			return fmt.Errorf("SCREENER_RANKING_WEIGHTS is required for the custom ranking strategy")
+		}
+		var sum float64
+		for component, w := range c.Screener.RankingWeights {
+			if !slices.Contains(rankingComponents, component) {
+				return fmt.Errorf("SCREENER_RANKING_WEIGHTS has unknown component %q, expected one of %s", component, strings.Join(rankingComponents, ", "))
+			}
+			if w < 0 || w > 1 {
+				return fmt.Errorf("SCREENER_RANKING_WEIGHTS %s weight must be between 0 and 1, got %.2f", component, w)
+			}
+			sum += w
+		}
+		if sum < 0.99 || sum > 1.01 {
+			return fmt.Errorf("SCREENER_RANKING_WEIGHTS must sum to 1.0, got %.2f", sum)
+		}
+	}
+	for _, t := range []struct {
+		name    string
+		targets map[string]float64
+	}{
+		{"REBALANCE_POSITION_TARGETS", c.Rebalance.PositionTargets},
+		{"REBALANCE_SECTOR_TARGETS", c.Rebalance.SectorTargets},
+	} {
+		var sum float64
+		for key, w := range t.targets {
+			if w <= 0 || w > 1 {
+				return fmt.Errorf("%s %s weight must be between 0 and 1, got %.2f", t.name, key, w)
+			}
+			sum += w
+		}
+		if sum > 1.0001 {
+			return fmt.Errorf("%s must not sum to more than 1.0, got %.2f", t.name, sum)
+		}
+	}
+	if !slices.Contains(complianceJurisdictions, c.Compliance.Jurisdiction) {
+		return fmt.Errorf("COMPLIANCE_JURISDICTION must be one of %s, got %q", strings.Join(complianceJurisdictions, ", "), c.Compliance.Jurisdiction)
+	}
+	if c.Backup.Enabled {
+		if c.Backup.EncryptionKey == "" {
+			return fmt.Errorf("BACKUP_ENCRYPTION_KEY is required when BACKUP_ENABLED is true")
+		}
+		if c.Backup.IntervalHours < 1 {
+			return fmt.Errorf("BACKUP_INTERVAL_HOURS must be at least 1, got %d", c.Backup.IntervalHours)
+		}
+		if c.Backup.Retention < 1 {
+			return fmt.Errorf("BACKUP_RETENTION must be at least 1, got %d", c.Backup.Retention)
+		}
+	}
+	if c.Warmup.Enabled && c.Warmup.TimeoutSeconds < 1 {
+		return fmt.Errorf("WARMUP_TIMEOUT_SECONDS must be at least 1, got %d", c.Warmup.TimeoutSeconds)
+	}
+	if c.CacheRefresh.Enabled {
+		if c.CacheRefresh.QuoteIntervalSeconds < 1 {
+			return fmt.Errorf("CACHE_REFRESH_QUOTE_INTERVAL_SECONDS must be at least 1, got %d", c.CacheRefresh.QuoteIntervalSeconds)
+		}
+		if c.CacheRefresh.RatioIntervalMinutes < 1 {
+			return fmt.Errorf("CACHE_REFRESH_RATIO_INTERVAL_MINUTES must be at least 1, got %d", c.CacheRefresh.RatioIntervalMinutes)
+		}
+		if c.CacheRefresh.JitterPercent < 0 || c.CacheRefresh.JitterPercent > 50 {
+			return fmt.Errorf("CACHE_REFRESH_JITTER_PERCENT must be between 0 and 50, got %d", c.CacheRefresh.JitterPercent)
+		}
+		if c.CacheRefresh.AlpacaCallsPerMinute < 0 || c.CacheRefresh.FMPCallsPerDay < 0 {
+			return fmt.Errorf("CACHE_REFRESH call budgets cannot be negative")
+		}
+	}
+	if c.Options.Feed != "indicative" && c.Options.Feed != "opra" {
+		return fmt.Errorf("OPTIONS_FEED must be indicative or opra, got %q", c.Options.Feed)
+	}
+	if c.Options.MinDays < 1 || c.Options.MaxDays < c.Options.MinDays {
+		return fmt.Errorf("COVERED_CALL_MIN_DAYS must be at least 1 and at most COVERED_CALL_MAX_DAYS, got %d and %d", c.Options.MinDays, c.Options.MaxDays)
+	}
+	if c.Options.MinOTMPercent < 0 || c.Options.MaxOTMPercent < c.Options.MinOTMPercent {
+		return fmt.Errorf("COVERED_CALL_MIN_OTM_PERCENT must not be negative or above COVERED_CALL_MAX_OTM_PERCENT, got %.1f and %.1f", c.Options.MinOTMPercent, c.Options.MaxOTMPercent)
+	}
+	if c.Options.MaxDelta <= 0 || c.Options.MaxDelta > 1 {
+		return fmt.Errorf("COVERED_CALL_MAX_DELTA must be above 0 and at most 1, got %.2f", c.Options.MaxDelta)
+	}
+	if c.Tray.Enabled && c.Tray.RefreshSeconds < 1 {
+		return fmt.Errorf("TRAY_REFRESH_SECONDS must be at least 1, got %d", c.Tray.RefreshSeconds)
+	}
+	if c.RiskStats.LookbackDays < 30 {
+		return fmt.Errorf("RISK_STATS_LOOKBACK_DAYS must be at least 30 to cover 20 trading days, got %d", c.RiskStats.LookbackDays)
+	}
+	if c.RiskStats.Benchmark == "" {
+		return fmt.Errorf("RISK_STATS_BENCHMARK must not be empty")
+	}
+	if rm := c.RiskManager; rm.Enabled {
+		limits := []struct {
+			name  string
+			value float64
+		}{
+			{"RISK_MANAGER_MAX_SECTOR_PERCENT", rm.MaxSectorPercent},
+			{"RISK_MANAGER_MAX_SYMBOL_PERCENT", rm.MaxSymbolPercent},
+			{"RISK_MANAGER_MAX_CORRELATION", rm.MaxCorrelation},
+			{"RISK_MANAGER_MAX_DRAWDOWN", rm.MaxDrawdown},
+		}
+		for _, l := range limits {
+			if l.value < 0 || l.value > 1 {
+				return fmt.Errorf("%s must be between 0 and 1, got %.2f", l.name, l.value)
+			}
+		}
+		if rm.LookbackDays < 30 {
+			return fmt.Errorf("RISK_MANAGER_LOOKBACK_DAYS must be at least 30 to cover 20 trading days, got %d", rm.LookbackDays)
+		}
+	}
+	if _, err := observability.ParseLevel(c.Logging.Level); err != nil {
+		return fmt.Errorf("LOG_LEVEL: %w", err)
+	}
+	for module, level := range c.Logging.ModuleLevels {
+		if !observability.KnownModule(module) {
+			return fmt.Errorf("LOG_MODULE_LEVELS has unknown module %q", module)
+		}
+		if _, err := observability.ParseLevel(level); err != nil {
+			return fmt.Errorf("LOG_MODULE_LEVELS %s: %w", module, err)
+		}
+	}
+	if c.HTTP.AdminToken != "" && len(c.HTTP.AdminToken) < minAdminTokenLength {
+		return fmt.Errorf("API_ADMIN_TOKEN must be at least %d characters", minAdminTokenLength)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("OTEL_TRACES_SAMPLER_ARG must be between 0 and 1, got %.2f", c.Tracing.SampleRatio)
+	}
+	for class, t := range c.Agent.ClassThresholds {
+		if !isSymbolClass(class) {
+			return fmt.Errorf("AGENT_CLASS_THRESHOLDS has unknown class %q, expected one of %s", class, strings.Join(symbolClasses, ", "))
+		}
+		if t.BuyThreshold <= t.SellThreshold {
+			return fmt.Errorf("AGENT_CLASS_THRESHOLDS %s buy threshold %.1f must be above sell threshold %.1f", class, t.BuyThreshold, t.SellThreshold)
+		}
+		if t.MinConfidence < 0 || t.MinConfidence > 100 {
+			return fmt.Errorf("AGENT_CLASS_THRESHOLDS %s min confidence must be between 0 and 100, got %.1f", class, t.MinConfidence)
+		}
+	}
 
 	return nil
 }
@@ -217,6 +1202,11 @@ func (c *Config) HasOpenAI() bool {
 	return c.OpenAI.APIKey != ""
 }
 
+// HasAnthropic returns true if Anthropic configuration is available
+func (c *Config) HasAnthropic() bool {
+	return c.Anthropic.APIKey != ""
+}
+
 // HasAlpaca returns true if Alpaca configuration is available
 func (c *Config) HasAlpaca() bool {
 	return c.Alpaca.APIKey != "" && c.Alpaca.APISecret != ""
@@ -237,6 +1227,221 @@ func (c *Config) HasFMP() bool {
 	return c.FMP.APIKey != ""
 }
 
+// HasIBKR returns true if an Interactive Brokers gateway is configured
+func (c *Config) HasIBKR() bool {
+	return c.IBKR.GatewayURL != ""
+}
+
+// HasFRED returns true if St. Louis Fed (FRED) configuration is available
+func (c *Config) HasFRED() bool {
+	return c.FRED.APIKey != ""
+}
+
+// ParseClassThresholds parses per-class thresholds of the form
+// "small_cap=35:-35:60,crypto=50:-50" (buy:sell[:min confidence]).
+// An empty string yields no overrides.
+func ParseClassThresholds(raw string) (map[string]ClassThreshold, error) {
+	thresholds := make(map[string]ClassThreshold)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		class, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("entry %q must be class=buy:sell[:min_confidence]", entry)
+		}
+		class = strings.ToLower(strings.TrimSpace(class))
+		parts := strings.Split(spec, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("entry %q must be class=buy:sell[:min_confidence]", entry)
+		}
+		values := make([]float64, len(parts))
+		for i, p := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+			if err != nil {
+				return nil, fmt.Errorf("entry %q has invalid number %q", entry, p)
+			}
+			values[i] = v
+		}
+		t := ClassThreshold{BuyThreshold: values[0], SellThreshold: values[1]}
+		if len(values) == 3 {
+			t.MinConfidence = values[2]
+		}
+		thresholds[class] = t
+	}
+	return thresholds, nil
+}
+
+// ParseRankingWeights parses custom ranking weights of the form
+// "score=0.5,confidence=0.3,liquidity=0.2". An empty string yields no weights.
+func ParseRankingWeights(raw string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		component, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("entry %q must be component=weight", entry)
+		}
+		w, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("entry %q has invalid weight %q", entry, value)
+		}
+		weights[strings.ToLower(strings.TrimSpace(component))] = w
+	}
+	return weights, nil
+}
+
+// ParseTargetWeights parses rebalance targets of the form "AAPL=0.10,MSFT=0.08" or
+// "Information Technology=0.30". Names keep their case. An empty string yields no targets.
+func ParseTargetWeights(raw string) (map[string]float64, error) {
+	targets := make(map[string]float64)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("entry %q must be name=weight", entry)
+		}
+		w, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("entry %q has invalid weight %q", entry, value)
+		}
+		targets[name] = w
+	}
+	return targets, nil
+}
+
+// ParseModuleLevels parses per-module log levels of the form "screener=debug,api=warn".
+// An empty string yields no levels.
+func ParseModuleLevels(raw string) (map[string]string, error) {
+	levels := make(map[string]string)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		module, level, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("entry %q must be module=level", entry)
+		}
+		levels[strings.ToLower(strings.TrimSpace(module))] = strings.ToLower(strings.TrimSpace(level))
+	}
+	return levels, nil
+}
+
+// otlpTracesEndpoint returns the URL traces are exported to. A traces-specific endpoint is
+// used as given; a base endpoint gets the standard /v1/traces path.
+func otlpTracesEndpoint() string {
+	if endpoint := getEnvString("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", ""); endpoint != "" {
+		return endpoint
+	}
+	if endpoint := getEnvString("OTEL_EXPORTER_OTLP_ENDPOINT", ""); endpoint != "" {
+		return strings.TrimRight(endpoint, "/") + "/v1/traces"
+	}
+	return ""
+}
+
+// ParseOTLPHeaders parses OTLP export headers of the form "api-key=secret,x-team=ops".
+// Values may be percent-encoded. An empty string yields no headers.
+func ParseOTLPHeaders(raw string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("entry %q must be key=value", entry)
+		}
+		decoded, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("entry %q: %w", entry, err)
+		}
+		headers[strings.TrimSpace(key)] = decoded
+	}
+	return headers, nil
+}
+
+// ParseAgentOverrides parses per-agent overrides of the form
+// "news=10:0,fundamental=60:2:gpt-4o" (timeout_seconds:retries[:model]). Empty fields
+// keep the default, so "technical=:1" only adds a retry. The model may contain colons.
+func ParseAgentOverrides(raw string) (map[string]AgentOverride, error) {
+	overrides := make(map[string]AgentOverride)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		agentType, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("entry %q must be type=timeout:retries[:model]", entry)
+		}
+		agentType = strings.ToLower(strings.TrimSpace(agentType))
+		parts := strings.SplitN(spec, ":", 3)
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("entry %q must be type=timeout:retries[:model]", entry)
+		}
+		var o AgentOverride
+		for i, field := range []*int{&o.TimeoutSeconds, &o.Retries} {
+			p := strings.TrimSpace(parts[i])
+			if p == "" {
+				continue
+			}
+			v, err := strconv.Atoi(p)
+			if err != nil {
+				return nil, fmt.Errorf("entry %q has invalid number %q", entry, p)
+			}
+			*field = v
+		}
+		if len(parts) == 3 {
+			o.Model = strings.TrimSpace(parts[2])
+		}
+		overrides[agentType] = o
+	}
+	return overrides, nil
+}
+
+// ParseLLMRoutes parses per-agent LLM routes of the form
+// "news=anthropic:claude-haiku-4-5,fundamental=openai" (provider[:model]). The model may
+// contain colons.
+func ParseLLMRoutes(raw string) (map[string]LLMRoute, error) {
+	routes := make(map[string]LLMRoute)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		agentType, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("entry %q must be type=provider[:model]", entry)
+		}
+		provider, model, _ := strings.Cut(spec, ":")
+		route := LLMRoute{Provider: strings.ToLower(strings.TrimSpace(provider)), Model: strings.TrimSpace(model)}
+		if route.Provider == "" {
+			return nil, fmt.Errorf("entry %q has no provider", entry)
+		}
+		routes[strings.ToLower(strings.TrimSpace(agentType))] = route
+	}
+	return routes, nil
+}
+
+func isSymbolClass(class string) bool {
+	for _, c := range symbolClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
 func getEnvString(key, defaultValue string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
@@ -280,6 +1485,21 @@ func getEnvFloatUnbounded(key string, defaultValue float64) float64 {
 	return defaultValue
 }
 
+// getEnvList reads a comma-separated list, trimming and upper-casing each entry
+func getEnvList(key string, defaultValue []string) []string {
+	val := os.Getenv(key)
+	if val == "" {
+		return defaultValue
+	}
+	var list []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.ToUpper(strings.TrimSpace(item)); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	if val := os.Getenv(key); val != "" {
 		if parsed, err := strconv.ParseBool(val); err == nil {
@@ -296,8 +1516,23 @@ func NewTestConfig() *Config {
 			URL: "",
 		},
 		OpenAI: OpenAIConfig{
+			APIKey:         "",
+			Model:          "gpt-4o",
+			MaxTokens:      4096,
+			EmbeddingModel: "text-embedding-3-small",
+		},
+		Anthropic: AnthropicConfig{
 			APIKey:    "",
-			Model:     "gpt-4o",
+			Model:     "claude-sonnet-4-5",
+			MaxTokens: 4096,
+			BaseURL:   "https://api.anthropic.com",
+		},
+		LLM: LLMConfig{
+			BudgetMode: "warn",
+		},
+		Ollama: OllamaConfig{
+			BaseURL:   "",
+			Model:     "llama3.1",
 			MaxTokens: 4096,
 		},
 		Alpaca: AlpacaConfig{
@@ -311,28 +1546,52 @@ func NewTestConfig() *Config {
 		NewsAPI: NewsAPIConfig{
 			APIKey: "",
 		},
+		Social: SocialConfig{
+			Subreddits: []string{"wallstreetbets", "stocks", "investing"},
+			UserAgent:  "trade-machine/1.0",
+		},
 		FMP: FMPConfig{
 			APIKey: "",
 		},
+		FRED: FREDConfig{
+			APIKey: "",
+		},
+		Broker: BrokerConfig{
+			Provider: "alpaca",
+		},
 		Agent: AgentConfig{
 			TimeoutSeconds:        30,
 			ConcurrencyLimit:      3,
 			TechnicalLookbackDays: 100,
+			NewsLookbackDays:      7,
+			NewsHalfLifeHours:     24,
 			WeightFundamental:     0.4,
 			WeightNews:            0.3,
 			WeightTechnical:       0.3,
+			InsiderLookbackDays:   90,
 			Strategy:              "default",
 			BuyThreshold:          25,
 			SellThreshold:         -25,
 			MinConfidence:         0,
 			HealthCacheTTLSeconds: 30,
+			Language:              "en",
+			MinRiskReward:         1.5,
+			StopLossPercent:       0.05,
+			TakeProfitPercent:     0.10,
+			WeightPolicy:          "redistribute",
+			Horizon:               "medium",
+			ClassThresholds:       map[string]ClassThreshold{},
+			TypeOverrides:         map[string]AgentOverride{},
 		},
 		PositionSizing: PositionSizingConfig{
-			MaxPositionPercent:   0.10,
-			RiskPercent:          0.02,
-			MinShares:            1,
-			MaxShares:            0,
-			UseConfidenceScaling: true,
+			MaxPositionPercent:     0.10,
+			RiskPercent:            0.02,
+			MinShares:              1,
+			MaxShares:              0,
+			UseConfidenceScaling:   true,
+			ShortMarginRequirement: 1.5,
+			ADVMode:                "warn",
+			ADVLookbackDays:        30,
 		},
 		Screener: ScreenerConfig{
 			MarketCapMin:       1_000_000_000,
@@ -342,6 +1601,110 @@ func NewTestConfig() *Config {
 			TopPicksCount:      3,
 			AnalysisTimeoutSec: 120,
 			MaxConcurrent:      5,
+			RateLimitPauseMs:   2000,
+			Exchanges:          []string{"NYSE", "NASDAQ", "AMEX"},
+			Country:            "US",
+			RecentListingMode:  "exclude",
+			RankingStrategy:    "default",
+			Schedule:           ScreenerScheduleConfig{Analyze: true},
+		},
+		PriceWatch: PriceWatchConfig{
+			IntervalSeconds: 300,
+			MovePercent:     5.0,
+			RecentDays:      7,
+			MaxPerCycle:     3,
+			CooldownMinutes: 60,
+		},
+		Reconciliation: ReconciliationConfig{
+			Enabled: true,
+		},
+		Rebalance: RebalanceConfig{
+			DriftThreshold: 0.02,
+		},
+		Options: OptionsConfig{
+			Feed:          "indicative",
+			MinDays:       14,
+			MaxDays:       60,
+			MinOTMPercent: 2,
+			MaxOTMPercent: 15,
+			MaxDelta:      0.4,
+		},
+		Execution: ExecutionConfig{
+			Mode: "manual",
+		},
+		RecommendationExpiry: RecommendationExpiryConfig{
+			Enabled:             true,
+			TTLHours:            72,
+			MaxDeviationPercent: 10,
+			IntervalMinutes:     15,
+		},
+		PortfolioReview: PortfolioReviewConfig{
+			MaxPositions: 25,
+		},
+		PortfolioSnapshots: PortfolioSnapshotsConfig{
+			IntervalMinutes: 15,
+		},
+		APILedger: APILedgerConfig{
+			RetentionDays: 30,
+		},
+		RateLimits: RateLimitConfig{
+			FMPPerMinute:          300,
+			AlphaVantagePerMinute: 5,
+			NewsAPIPerDay:         100,
+			MaxWaitSeconds:        30,
+		},
+		Retry: RetryConfig{
+			MaxAttempts:   4,
+			BaseDelayMs:   250,
+			MaxDelayMs:    5000,
+			JitterPercent: 20,
+		},
+		WriteBuffer: WriteBufferConfig{
+			Capacity: 1000,
+		},
+		// Acknowledgment is not required so tests can trade without accepting the disclaimer
+		Compliance: ComplianceConfig{
+			Jurisdiction: "us",
+		},
+		Backup: BackupConfig{
+			IntervalHours: 24,
+			Retention:     7,
+			PgDumpPath:    "pg_dump",
+		},
+		Warmup: WarmupConfig{
+			TimeoutSeconds: 3,
+		},
+		CacheRefresh: CacheRefreshConfig{
+			QuoteIntervalSeconds: 10,
+			RatioIntervalMinutes: 30,
+			JitterPercent:        20,
+			AlpacaCallsPerMinute: 60,
+			FMPCallsPerDay:       50,
+		},
+		ResponseCache: ResponseCacheConfig{
+			Enabled:                true,
+			FundamentalsTTLMinutes: 1440,
+			RatiosTTLMinutes:       60,
+			NewsTTLMinutes:         30,
+			EarningsTTLMinutes:     720,
+			InsiderTTLMinutes:      360,
+		},
+		Logging: LoggingConfig{
+			Level: "info",
+		},
+		Tray: TrayConfig{
+			RefreshSeconds: 30,
+		},
+		RiskStats: RiskStatsConfig{
+			LookbackDays: 365,
+			Benchmark:    "SPY",
+		},
+		RiskManager: RiskManagerConfig{
+			MaxSectorPercent: 0.30,
+			MaxSymbolPercent: 0.15,
+			MaxCorrelation:   0.80,
+			MaxDrawdown:      0.15,
+			LookbackDays:     90,
 		},
 		HTTP: HTTPConfig{
 			CORSAllowedOrigins: "*",
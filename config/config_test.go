@@ -1,8 +1,13 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+
+	"trade-machine/observability"
 )
 
 // saveEnv saves current environment variables for restoration
@@ -40,6 +45,16 @@ var allEnvKeys = []string{
 	"OPENAI_API_KEY",
 	"OPENAI_MODEL",
 	"OPENAI_MAX_TOKENS",
+	"OPENAI_EMBEDDING_MODEL",
+	"LLM_PROVIDER",
+	"ANTHROPIC_API_KEY",
+	"ANTHROPIC_MODEL",
+	"ANTHROPIC_MAX_TOKENS",
+	"ANTHROPIC_BASE_URL",
+	"OLLAMA_BASE_URL",
+	"OLLAMA_MODEL",
+	"OLLAMA_MAX_TOKENS",
+	"EXECUTION_MODE",
 	"ALPACA_API_KEY",
 	"ALPACA_API_SECRET",
 	"ALPACA_BASE_URL",
@@ -48,10 +63,72 @@ var allEnvKeys = []string{
 	"AGENT_TIMEOUT_SECONDS",
 	"ANALYSIS_CONCURRENCY_LIMIT",
 	"TECHNICAL_ANALYSIS_LOOKBACK_DAYS",
+	"NEWS_LOOKBACK_DAYS",
+	"NEWS_RECENCY_HALF_LIFE_HOURS",
 	"AGENT_WEIGHT_FUNDAMENTAL",
 	"AGENT_WEIGHT_NEWS",
 	"AGENT_WEIGHT_TECHNICAL",
+	"AGENT_LANGUAGE",
+	"AGENT_WEIGHT_POLICY",
+	"AGENT_SIGNAL_ONLY",
+	"AGENT_LATENCY_BUDGET_SECONDS",
+	"AGENT_CLASS_THRESHOLDS",
+	"AGENT_TYPE_OVERRIDES",
+	"SCREENER_EXCHANGES",
+	"SCREENER_COUNTRY",
+	"SCREENER_RECENT_LISTING_MODE",
+	"SCREENER_RANKING_STRATEGY",
+	"SCREENER_RANKING_WEIGHTS",
+	"SCREENER_SCHEDULE",
+	"SCREENER_SCHEDULE_ANALYZE",
+	"PRICE_WATCH_ENABLED",
+	"PRICE_WATCH_MOVE_PERCENT",
+	"RECONCILIATION_ENABLED",
+	"OPTIONS_FEED",
+	"COVERED_CALL_MIN_DAYS",
+	"COVERED_CALL_MAX_DAYS",
+	"COVERED_CALL_MIN_OTM_PERCENT",
+	"COVERED_CALL_MAX_OTM_PERCENT",
+	"COVERED_CALL_MAX_DELTA",
+	"FEE_COMMISSION_PER_TRADE",
+	"FEE_COMMISSION_PER_SHARE",
+	"FEE_SELL_RATE",
+	"PORTFOLIO_REVIEW_MAX_POSITIONS",
+	"API_LEDGER_RETENTION_DAYS",
+	"WRITE_BUFFER_CAPACITY",
+	"COMPLIANCE_JURISDICTION",
+	"COMPLIANCE_DISCLAIMER",
+	"COMPLIANCE_REQUIRE_ACKNOWLEDGMENT",
+	"BACKUP_ENABLED",
+	"BACKUP_INTERVAL_HOURS",
+	"BACKUP_RETENTION",
+	"BACKUP_ENCRYPTION_KEY",
+	"BACKUP_PG_DUMP_PATH",
+	"WARMUP_ENABLED",
+	"WARMUP_TIMEOUT_SECONDS",
+	"CACHE_REFRESH_ENABLED",
+	"CACHE_REFRESH_QUOTE_INTERVAL_SECONDS",
+	"CACHE_REFRESH_RATIO_INTERVAL_MINUTES",
+	"CACHE_REFRESH_JITTER_PERCENT",
+	"CACHE_REFRESH_ALPACA_CALLS_PER_MINUTE",
+	"CACHE_REFRESH_FMP_CALLS_PER_DAY",
+	"LOG_LEVEL",
+	"LOG_MODULE_LEVELS",
+	"OTEL_EXPORTER_OTLP_ENDPOINT",
+	"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+	"OTEL_EXPORTER_OTLP_HEADERS",
+	"OTEL_SERVICE_NAME",
+	"OTEL_TRACES_SAMPLER_ARG",
+	"EVENTS_NATS_URL",
+	"EVENTS_NATS_SUBJECT_PREFIX",
+	"TRAY_ENABLED",
+	"TRAY_REFRESH_SECONDS",
+	"POSITION_TARGET_VOLATILITY",
+	"RISK_STATS_LOOKBACK_DAYS",
+	"RISK_STATS_BENCHMARK",
 	"CORS_ALLOWED_ORIGINS",
+	"API_AUTH_ENABLED",
+	"API_ADMIN_TOKEN",
 }
 
 func TestLoad_Defaults(t *testing.T) {
@@ -71,12 +148,21 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.Agent.TimeoutSeconds != 30 {
 		t.Errorf("expected TimeoutSeconds=30, got %d", cfg.Agent.TimeoutSeconds)
 	}
+	if cfg.OpenAI.EmbeddingModel != "text-embedding-3-small" {
+		t.Errorf("expected EmbeddingModel=text-embedding-3-small, got %s", cfg.OpenAI.EmbeddingModel)
+	}
 	if cfg.Agent.ConcurrencyLimit != 3 {
 		t.Errorf("expected ConcurrencyLimit=3, got %d", cfg.Agent.ConcurrencyLimit)
 	}
 	if cfg.Agent.TechnicalLookbackDays != 100 {
 		t.Errorf("expected TechnicalLookbackDays=100, got %d", cfg.Agent.TechnicalLookbackDays)
 	}
+	if cfg.Agent.NewsLookbackDays != 7 {
+		t.Errorf("expected NewsLookbackDays=7, got %d", cfg.Agent.NewsLookbackDays)
+	}
+	if cfg.Agent.NewsHalfLifeHours != 24 {
+		t.Errorf("expected NewsHalfLifeHours=24, got %d", cfg.Agent.NewsHalfLifeHours)
+	}
 	if cfg.Agent.WeightFundamental != 0.4 {
 		t.Errorf("expected WeightFundamental=0.4, got %f", cfg.Agent.WeightFundamental)
 	}
@@ -86,6 +172,18 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.Agent.WeightTechnical != 0.3 {
 		t.Errorf("expected WeightTechnical=0.3, got %f", cfg.Agent.WeightTechnical)
 	}
+	if cfg.Agent.Language != "en" {
+		t.Errorf("expected Language='en', got %s", cfg.Agent.Language)
+	}
+	if cfg.Agent.Horizon != "medium" {
+		t.Errorf("expected Horizon='medium', got %s", cfg.Agent.Horizon)
+	}
+	if cfg.Agent.WeightPolicy != "redistribute" {
+		t.Errorf("expected WeightPolicy='redistribute', got %s", cfg.Agent.WeightPolicy)
+	}
+	if len(cfg.Agent.ClassThresholds) != 0 {
+		t.Errorf("expected no class thresholds, got %v", cfg.Agent.ClassThresholds)
+	}
 	if cfg.HTTP.CORSAllowedOrigins != "*" {
 		t.Errorf("expected CORSAllowedOrigins='*', got %s", cfg.HTTP.CORSAllowedOrigins)
 	}
@@ -105,9 +203,12 @@ func TestLoad_CustomValues(t *testing.T) {
 	os.Setenv("AGENT_TIMEOUT_SECONDS", "60")
 	os.Setenv("ANALYSIS_CONCURRENCY_LIMIT", "5")
 	os.Setenv("TECHNICAL_ANALYSIS_LOOKBACK_DAYS", "200")
+	os.Setenv("NEWS_LOOKBACK_DAYS", "3")
+	os.Setenv("NEWS_RECENCY_HALF_LIFE_HOURS", "12")
 	os.Setenv("AGENT_WEIGHT_FUNDAMENTAL", "0.5")
 	os.Setenv("AGENT_WEIGHT_NEWS", "0.25")
 	os.Setenv("AGENT_WEIGHT_TECHNICAL", "0.25")
+	os.Setenv("AGENT_LANGUAGE", "es")
 	os.Setenv("CORS_ALLOWED_ORIGINS", "http://localhost:3000")
 
 	cfg, err := Load()
@@ -130,9 +231,15 @@ func TestLoad_CustomValues(t *testing.T) {
 	if cfg.Agent.ConcurrencyLimit != 5 {
 		t.Errorf("expected ConcurrencyLimit=5, got %d", cfg.Agent.ConcurrencyLimit)
 	}
+	if cfg.Agent.NewsLookbackDays != 3 || cfg.Agent.NewsHalfLifeHours != 12 {
+		t.Errorf("expected news lookback 3 days and half-life 12h, got %d and %d", cfg.Agent.NewsLookbackDays, cfg.Agent.NewsHalfLifeHours)
+	}
 	if cfg.Agent.WeightFundamental != 0.5 {
 		t.Errorf("expected WeightFundamental=0.5, got %f", cfg.Agent.WeightFundamental)
 	}
+	if cfg.Agent.Language != "es" {
+		t.Errorf("expected Language='es', got %s", cfg.Agent.Language)
+	}
 	if cfg.HTTP.CORSAllowedOrigins != "http://localhost:3000" {
 		t.Errorf("expected CORSAllowedOrigins='http://localhost:3000', got %s", cfg.HTTP.CORSAllowedOrigins)
 	}
@@ -178,265 +285,1385 @@ func TestValidate_WeightRange(t *testing.T) {
 	}
 }
 
-func TestValidate_PositiveIntegers(t *testing.T) {
-	tests := []struct {
-		name    string
-		envKey  string
-		envVal  string
-		wantErr bool
-	}{
-		{
-			name:    "negative timeout uses default",
-			envKey:  "AGENT_TIMEOUT_SECONDS",
-			envVal:  "-5",
-			wantErr: false, // uses default
-		},
-		{
-			name:    "zero concurrency uses default",
-			envKey:  "ANALYSIS_CONCURRENCY_LIMIT",
-			envVal:  "0",
-			wantErr: false, // uses default
-		},
-		{
-			name:    "invalid number uses default",
-			envKey:  "OPENAI_MAX_TOKENS",
-			envVal:  "not-a-number",
-			wantErr: false, // uses default
-		},
+func TestValidate_SocialWeight(t *testing.T) {
+	cfg := NewTestConfig()
+	cfg.Agent.WeightSocial = 0.2
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error when the social weight pushes the sum past 1.0")
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			saved := saveEnv(t, allEnvKeys)
-			defer restoreEnv(t, saved)
-			clearEnv(t, allEnvKeys)
-
-			os.Setenv(tt.envKey, tt.envVal)
-
-			_, err := Load()
-			if tt.wantErr && err == nil {
-				t.Error("expected error")
-			}
-			if !tt.wantErr && err != nil {
-				t.Errorf("unexpected error: %v", err)
-			}
-		})
+	cfg.Agent.WeightFundamental = 0.3
+	cfg.Agent.WeightNews = 0.2
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected rebalanced weights to be valid, got %v", err)
 	}
 }
 
-func TestHasDatabase(t *testing.T) {
-	cfg := &Config{
-		Database: DatabaseConfig{URL: ""},
-	}
-	if cfg.HasDatabase() {
-		t.Error("expected HasDatabase() to return false for empty URL")
+func TestValidate_MacroWeight(t *testing.T) {
+	cfg := NewTestConfig()
+	cfg.Agent.WeightTechnical = 0.2
+	cfg.Agent.WeightMacro = 0.1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error when the macro agent is weighted without a FRED key")
 	}
 
-	cfg.Database.URL = "postgres://localhost/test"
-	if !cfg.HasDatabase() {
-		t.Error("expected HasDatabase() to return true for non-empty URL")
+	cfg.FRED.APIKey = "test-key"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected a weighted macro agent with a FRED key to be valid, got %v", err)
 	}
 }
 
-func TestHasAlpaca(t *testing.T) {
-	cfg := &Config{
-		Alpaca: AlpacaConfig{APIKey: "", APISecret: ""},
+func TestValidate_MinRiskReward(t *testing.T) {
+	cfg := NewTestConfig()
+	cfg.Agent.MinRiskReward = -1
+
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for negative minimum risk/reward")
 	}
-	if cfg.HasAlpaca() {
-		t.Error("expected HasAlpaca() to return false for empty config")
+
+	cfg.Agent.MinRiskReward = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected zero minimum risk/reward to be valid, got %v", err)
 	}
+}
 
-	cfg.Alpaca.APIKey = "key"
-	if cfg.HasAlpaca() {
-		t.Error("expected HasAlpaca() to return false without secret")
+func TestValidate_LatencyBudget(t *testing.T) {
+	cfg := NewTestConfig()
+	cfg.Agent.LatencyBudgetSeconds = -1
+
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for negative latency budget")
 	}
 
-	cfg.Alpaca.APISecret = "secret"
-	if !cfg.HasAlpaca() {
-		t.Error("expected HasAlpaca() to return true for complete config")
+	cfg.Agent.LatencyBudgetSeconds = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected a disabled latency budget to be valid, got %v", err)
 	}
 }
 
-func TestHasAlphaVantage(t *testing.T) {
-	cfg := &Config{
-		AlphaVantage: AlphaVantageConfig{APIKey: ""},
+func TestValidate_Retry(t *testing.T) {
+	cfg := NewTestConfig()
+	cfg.Retry.MaxAttempts = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for zero retry attempts")
 	}
-	if cfg.HasAlphaVantage() {
-		t.Error("expected HasAlphaVantage() to return false for empty key")
+
+	cfg = NewTestConfig()
+	cfg.Retry.BaseDelayMs = 10000
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for a base delay above the max delay")
 	}
 
-	cfg.AlphaVantage.APIKey = "key"
-	if !cfg.HasAlphaVantage() {
-		t.Error("expected HasAlphaVantage() to return true for non-empty key")
+	cfg = NewTestConfig()
+	cfg.Retry.MaxAttempts = 1
+	cfg.Retry.JitterPercent = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected retries disabled without jitter to be valid, got %v", err)
 	}
 }
 
-func TestHasNewsAPI(t *testing.T) {
-	cfg := &Config{
-		NewsAPI: NewsAPIConfig{APIKey: ""},
-	}
-	if cfg.HasNewsAPI() {
-		t.Error("expected HasNewsAPI() to return false for empty key")
+func TestValidate_WeightPolicy(t *testing.T) {
+	for _, policy := range []string{"redistribute", "floor", "abstain"} {
+		cfg := NewTestConfig()
+		cfg.Agent.WeightPolicy = policy
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("expected policy %q to be valid, got %v", policy, err)
+		}
 	}
 
-	cfg.NewsAPI.APIKey = "key"
-	if !cfg.HasNewsAPI() {
-		t.Error("expected HasNewsAPI() to return true for non-empty key")
+	cfg := NewTestConfig()
+	cfg.Agent.WeightPolicy = "ignore"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for unknown weight policy")
 	}
 }
 
-func TestGetEnvString(t *testing.T) {
-	key := "TEST_GET_ENV_STRING"
-	defer os.Unsetenv(key)
-
-	// Empty returns default
-	os.Unsetenv(key)
-	if got := getEnvString(key, "default"); got != "default" {
-		t.Errorf("expected 'default', got %s", got)
+func TestValidate_LLMProvider(t *testing.T) {
+	for _, provider := range []string{"", "openai", "anthropic", "ollama"} {
+		cfg := NewTestConfig()
+		cfg.LLM.Provider = provider
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("expected provider %q to be valid, got %v", provider, err)
+		}
 	}
 
-	// Set value returns value
-	os.Setenv(key, "custom")
-	if got := getEnvString(key, "default"); got != "custom" {
-		t.Errorf("expected 'custom', got %s", got)
+	cfg := NewTestConfig()
+	cfg.LLM.Provider = "bedrock"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for unknown LLM provider")
 	}
 }
 
-func TestGetEnvInt(t *testing.T) {
-	key := "TEST_GET_ENV_INT"
-	defer os.Unsetenv(key)
-
-	// Empty returns default
-	os.Unsetenv(key)
-	if got := getEnvInt(key, 42); got != 42 {
-		t.Errorf("expected 42, got %d", got)
+func TestValidate_LLMRoutes(t *testing.T) {
+	cfg := NewTestConfig()
+	cfg.LLM.Routes = map[string]LLMRoute{"news": {Provider: "anthropic", Model: "claude-haiku-4-5"}}
+	cfg.LLM.FallbackProvider = "ollama"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected routes to be valid, got %v", err)
 	}
 
-	// Valid integer
-	os.Setenv(key, "100")
-	if got := getEnvInt(key, 42); got != 100 {
-		t.Errorf("expected 100, got %d", got)
+	for name, mutate := range map[string]func(*Config){
+		"unknown agent type": func(c *Config) { c.LLM.Routes = map[string]LLMRoute{"insider": {Provider: "openai"}} },
+		"unknown provider":   func(c *Config) { c.LLM.Routes = map[string]LLMRoute{"news": {Provider: "bedrock"}} },
+		"unknown fallback":   func(c *Config) { c.LLM.FallbackProvider = "bedrock" },
+	} {
+		cfg := NewTestConfig()
+		mutate(cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("expected error for %s", name)
+		}
 	}
+}
 
-	// Invalid integer returns default
-	os.Setenv(key, "invalid")
-	if got := getEnvInt(key, 42); got != 42 {
-		t.Errorf("expected 42 for invalid value, got %d", got)
+func TestValidate_LLMBudget(t *testing.T) {
+	for _, mode := range []string{"warn", "block"} {
+		cfg := NewTestConfig()
+		cfg.LLM.MonthlyBudget = 50
+		cfg.LLM.BudgetMode = mode
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("expected budget mode %q to be valid, got %v", mode, err)
+		}
 	}
 
-	// Negative returns default
-	os.Setenv(key, "-5")
-	if got := getEnvInt(key, 42); got != 42 {
-		t.Errorf("expected 42 for negative value, got %d", got)
+	cfg := NewTestConfig()
+	cfg.LLM.BudgetMode = "reject"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for unknown budget mode")
 	}
 
-	// Zero returns default
-	os.Setenv(key, "0")
-	if got := getEnvInt(key, 42); got != 42 {
-		t.Errorf("expected 42 for zero value, got %d", got)
+	cfg = NewTestConfig()
+	cfg.LLM.MonthlyBudget = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for a negative budget")
 	}
 }
 
-func TestGetEnvFloat(t *testing.T) {
-	key := "TEST_GET_ENV_FLOAT"
-	defer os.Unsetenv(key)
+func TestValidate_ExecutionMode(t *testing.T) {
+	cfg := NewTestConfig()
+	if cfg.Execution.Auto() {
+		t.Error("expected manual execution by default")
+	}
+	cfg.Execution.Mode = "auto"
+	if err := cfg.Validate(); err != nil || !cfg.Execution.Auto() {
+		t.Errorf("expected auto mode to be valid, got %v", err)
+	}
+	cfg.Execution.Mode = "immediate"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for unknown execution mode")
+	}
+}
 
-	// Empty returns default
-	os.Unsetenv(key)
-	if got := getEnvFloat(key, 0.5); got != 0.5 {
-		t.Errorf("expected 0.5, got %f", got)
+func TestValidate_RiskManager(t *testing.T) {
+	cfg := NewTestConfig()
+	cfg.RiskManager.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected default risk limits to be valid, got %v", err)
 	}
+	cfg.RiskManager.MaxSectorPercent = 1.5
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for a sector limit above 100%")
+	}
+	cfg.RiskManager.MaxSectorPercent = 0.30
+	cfg.RiskManager.LookbackDays = 10
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for a lookback too short to correlate")
+	}
+}
 
-	// Valid float
-	os.Setenv(key, "0.75")
-	if got := getEnvFloat(key, 0.5); got != 0.75 {
-		t.Errorf("expected 0.75, got %f", got)
+func TestValidate_Language(t *testing.T) {
+	cfg := NewTestConfig()
+	cfg.Agent.Language = "ja"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected ja to be valid, got %v", err)
 	}
 
-	// Invalid float returns default
-	os.Setenv(key, "invalid")
-	if got := getEnvFloat(key, 0.5); got != 0.5 {
-		t.Errorf("expected 0.5 for invalid value, got %f", got)
+	cfg.Agent.Language = "ko"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for a language without a UI catalog")
 	}
+}
 
-	// Out of range (> 1) returns default
-	os.Setenv(key, "1.5")
-	if got := getEnvFloat(key, 0.5); got != 0.5 {
-		t.Errorf("expected 0.5 for value > 1, got %f", got)
+func TestValidate_Horizon(t *testing.T) {
+	for _, horizon := range []string{"short", "medium", "long", "overall"} {
+		cfg := NewTestConfig()
+		cfg.Agent.Horizon = horizon
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("expected horizon %q to be valid, got %v", horizon, err)
+		}
 	}
 
-	// Negative returns default
-	os.Setenv(key, "-0.1")
-	if got := getEnvFloat(key, 0.5); got != 0.5 {
-		t.Errorf("expected 0.5 for negative value, got %f", got)
+	cfg := NewTestConfig()
+	for _, horizon := range []string{"", "weekly"} {
+		cfg.Agent.Horizon = horizon
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("expected error for horizon %q", horizon)
+		}
 	}
 }
 
-func TestHasOpenAI(t *testing.T) {
-	cfg := &Config{
-		OpenAI: OpenAIConfig{APIKey: ""},
+func TestValidate_ADVMode(t *testing.T) {
+	for _, mode := range []string{"warn", "reject"} {
+		cfg := NewTestConfig()
+		cfg.PositionSizing.ADVMode = mode
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("expected mode %q to be valid, got %v", mode, err)
+		}
 	}
-	if cfg.HasOpenAI() {
-		t.Error("expected HasOpenAI() to return false for empty key")
+
+	cfg := NewTestConfig()
+	cfg.PositionSizing.ADVMode = "block"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for unknown ADV mode")
 	}
 
-	cfg.OpenAI.APIKey = "sk-test"
-	if !cfg.HasOpenAI() {
-		t.Error("expected HasOpenAI() to return true for non-empty key")
+	cfg = NewTestConfig()
+	cfg.PositionSizing.ADVLookbackDays = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for a zero ADV lookback")
 	}
 }
 
-func TestLoad_OpenAIDefaults(t *testing.T) {
+func TestValidate_RecentListingMode(t *testing.T) {
+	for _, mode := range []string{"exclude", "flag"} {
+		cfg := NewTestConfig()
+		cfg.Screener.RecentListingMode = mode
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("expected mode %q to be valid, got %v", mode, err)
+		}
+	}
+
+	cfg := NewTestConfig()
+	cfg.Screener.RecentListingMode = "warn"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for unknown recent listing mode")
+	}
+}
+
+func TestLoad_ScreenerSchedule(t *testing.T) {
 	saved := saveEnv(t, allEnvKeys)
 	defer restoreEnv(t, saved)
 	clearEnv(t, allEnvKeys)
 
 	cfg, err := Load()
 	if err != nil {
-		t.Fatalf("Load() with defaults failed: %v", err)
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Screener.Schedule.Cron != "" || !cfg.Screener.Schedule.Analyze {
+		t.Errorf("Schedule = %+v, want no cron and analysis on by default", cfg.Screener.Schedule)
 	}
 
-	// Check OpenAI defaults
-	if cfg.OpenAI.Model != "gpt-4o" {
-		t.Errorf("expected OpenAI.Model='gpt-4o', got %s", cfg.OpenAI.Model)
+	os.Setenv("SCREENER_SCHEDULE", "0 8 * * 1-5")
+	os.Setenv("SCREENER_SCHEDULE_ANALYZE", "false")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
 	}
-	if cfg.OpenAI.MaxTokens != 4096 {
-		t.Errorf("expected OpenAI.MaxTokens=4096, got %d", cfg.OpenAI.MaxTokens)
+	if cfg.Screener.Schedule.Cron != "0 8 * * 1-5" || cfg.Screener.Schedule.Analyze {
+		t.Errorf("Schedule = %+v, want the configured cron without analysis", cfg.Screener.Schedule)
+	}
+
+	cfg.Screener.Schedule.Cron = "0 8 * *"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for a cron spec without five fields")
 	}
 }
 
-func TestLoad_OpenAICustomValues(t *testing.T) {
+func TestParseClassThresholds(t *testing.T) {
+	got, err := ParseClassThresholds(" small_cap=35:-35:60, CRYPTO=50:-50 ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 classes, got %d", len(got))
+	}
+	if want := (ClassThreshold{BuyThreshold: 35, SellThreshold: -35, MinConfidence: 60}); got["small_cap"] != want {
+		t.Errorf("small_cap = %+v, want %+v", got["small_cap"], want)
+	}
+	if want := (ClassThreshold{BuyThreshold: 50, SellThreshold: -50}); got["crypto"] != want {
+		t.Errorf("crypto = %+v, want %+v", got["crypto"], want)
+	}
+
+	for _, raw := range []string{"small_cap", "small_cap=35", "small_cap=a:-35", "small_cap=1:2:3:4"} {
+		if _, err := ParseClassThresholds(raw); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestValidate_ClassThresholds(t *testing.T) {
+	tests := []struct {
+		name      string
+		class     string
+		threshold ClassThreshold
+		wantErr   bool
+	}{
+		{"valid", "mega_cap", ClassThreshold{BuyThreshold: 20, SellThreshold: -20}, false},
+		{"unknown class", "penny", ClassThreshold{BuyThreshold: 20, SellThreshold: -20}, true},
+		{"buy not above sell", "crypto", ClassThreshold{BuyThreshold: -10, SellThreshold: 10}, true},
+		{"confidence out of range", "small_cap", ClassThreshold{BuyThreshold: 20, SellThreshold: -20, MinConfidence: 150}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewTestConfig()
+			cfg.Agent.ClassThresholds = map[string]ClassThreshold{tt.class: tt.threshold}
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidClassThresholds(t *testing.T) {
 	saved := saveEnv(t, allEnvKeys)
 	defer restoreEnv(t, saved)
 	clearEnv(t, allEnvKeys)
 
-	os.Setenv("OPENAI_API_KEY", "sk-test-key")
-	os.Setenv("OPENAI_MODEL", "gpt-4-turbo")
-	os.Setenv("OPENAI_MAX_TOKENS", "2048")
+	os.Setenv("AGENT_CLASS_THRESHOLDS", "small_cap=high:low")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for malformed AGENT_CLASS_THRESHOLDS")
+	}
+}
 
-	cfg, err := Load()
+func TestParseAgentOverrides(t *testing.T) {
+	got, err := ParseAgentOverrides(" news=10:0, Fundamental=60:2:ft:gpt-4o:custom, technical=:1 ")
 	if err != nil {
-		t.Fatalf("Load() with OpenAI custom values failed: %v", err)
+		t.Fatalf("unexpected error: %v", err)
 	}
-
-	if cfg.OpenAI.APIKey != "sk-test-key" {
-		t.Errorf("expected OpenAI.APIKey='sk-test-key', got %s", cfg.OpenAI.APIKey)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 agent types, got %d", len(got))
 	}
-	if cfg.OpenAI.Model != "gpt-4-turbo" {
-		t.Errorf("expected OpenAI.Model='gpt-4-turbo', got %s", cfg.OpenAI.Model)
+	if want := (AgentOverride{TimeoutSeconds: 10}); got["news"] != want {
+		t.Errorf("news = %+v, want %+v", got["news"], want)
 	}
-	if cfg.OpenAI.MaxTokens != 2048 {
-		t.Errorf("expected OpenAI.MaxTokens=2048, got %d", cfg.OpenAI.MaxTokens)
+	if want := (AgentOverride{TimeoutSeconds: 60, Retries: 2, Model: "ft:gpt-4o:custom"}); got["fundamental"] != want {
+		t.Errorf("fundamental = %+v, want %+v", got["fundamental"], want)
+	}
+	if want := (AgentOverride{Retries: 1}); got["technical"] != want {
+		t.Errorf("technical = %+v, want %+v", got["technical"], want)
 	}
-}
 
-func TestNewTestConfig_IncludesOpenAI(t *testing.T) {
-	cfg := NewTestConfig()
+	for _, raw := range []string{"news", "news=10", "news=fast:0", "news=10:x"} {
+		if _, err := ParseAgentOverrides(raw); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+}
 
-	if cfg.OpenAI.Model != "gpt-4o" {
-		t.Errorf("expected OpenAI.Model='gpt-4o', got %s", cfg.OpenAI.Model)
+func TestParseLLMRoutes(t *testing.T) {
+	got, err := ParseLLMRoutes(" News=Anthropic:claude-haiku-4-5, fundamental=openai:ft:gpt-4o:custom, technical=ollama ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if cfg.OpenAI.MaxTokens != 4096 {
+	if len(got) != 3 {
+		t.Fatalf("expected 3 agent types, got %d", len(got))
+	}
+	if want := (LLMRoute{Provider: "anthropic", Model: "claude-haiku-4-5"}); got["news"] != want {
+		t.Errorf("news = %+v, want %+v", got["news"], want)
+	}
+	if want := (LLMRoute{Provider: "openai", Model: "ft:gpt-4o:custom"}); got["fundamental"] != want {
+		t.Errorf("fundamental = %+v, want %+v", got["fundamental"], want)
+	}
+	if want := (LLMRoute{Provider: "ollama"}); got["technical"] != want {
+		t.Errorf("technical = %+v, want %+v", got["technical"], want)
+	}
+
+	for _, raw := range []string{"news", "news=", "news=:gpt-4o"} {
+		if _, err := ParseLLMRoutes(raw); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestParseRankingWeights(t *testing.T) {
+	got, err := ParseRankingWeights(" Score=0.6, liquidity=0.4 ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got["score"] != 0.6 || got["liquidity"] != 0.4 {
+		t.Errorf("unexpected weights: %+v", got)
+	}
+
+	for _, raw := range []string{"score", "score=high"} {
+		if _, err := ParseRankingWeights(raw); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestParseTargetWeights(t *testing.T) {
+	got, err := ParseTargetWeights(" AAPL=0.1, Information Technology=0.3 ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got["AAPL"] != 0.1 || got["Information Technology"] != 0.3 {
+		t.Errorf("unexpected targets: %+v", got)
+	}
+
+	for _, raw := range []string{"AAPL", "=0.1", "AAPL=lots"} {
+		if _, err := ParseTargetWeights(raw); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestValidate_RebalanceTargets(t *testing.T) {
+	tests := []struct {
+		name      string
+		positions map[string]float64
+		sectors   map[string]float64
+		wantErr   bool
+	}{
+		{"none", nil, nil, false},
+		{"valid", map[string]float64{"AAPL": 0.1}, map[string]float64{"Energy": 0.2}, false},
+		{"zero weight", map[string]float64{"AAPL": 0}, nil, true},
+		{"sectors over the whole portfolio", nil, map[string]float64{"Energy": 0.6, "Utilities": 0.6}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewTestConfig()
+			cfg.Rebalance.PositionTargets = tt.positions
+			cfg.Rebalance.SectorTargets = tt.sectors
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Options(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*OptionsConfig)
+		wantErr bool
+	}{
+		{"defaults", func(*OptionsConfig) {}, false},
+		{"opra feed", func(c *OptionsConfig) { c.Feed = "opra" }, false},
+		{"unknown feed", func(c *OptionsConfig) { c.Feed = "cboe" }, true},
+		{"max days below min", func(c *OptionsConfig) { c.MinDays, c.MaxDays = 30, 20 }, true},
+		{"negative OTM", func(c *OptionsConfig) { c.MinOTMPercent = -1 }, true},
+		{"delta above 1", func(c *OptionsConfig) { c.MaxDelta = 1.5 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewTestConfig()
+			tt.modify(&cfg.Options)
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Broker(t *testing.T) {
+	tests := []struct {
+		name       string
+		provider   string
+		gatewayURL string
+		wantErr    bool
+	}{
+		{"alpaca", "alpaca", "", false},
+		{"ibkr", "ibkr", "https://localhost:5000/v1/api", false},
+		{"ibkr without a gateway", "ibkr", "", true},
+		{"unknown", "schwab", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewTestConfig()
+			cfg.Broker.Provider = tt.provider
+			cfg.IBKR.GatewayURL = tt.gatewayURL
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_RankingStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		weights  map[string]float64
+		wantErr  bool
+	}{
+		{"preset", "value", nil, false},
+		{"unknown strategy", "momentum", nil, true},
+		{"custom", "custom", map[string]float64{"score": 0.5, "margin_of_safety": 0.5}, false},
+		{"custom without weights", "custom", nil, true},
+		{"custom unknown component", "custom", map[string]float64{"score": 0.5, "momentum": 0.5}, true},
+		{"custom weights not summing to one", "custom", map[string]float64{"score": 0.5, "confidence": 0.2}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewTestConfig()
+			cfg.Screener.RankingStrategy = tt.strategy
+			cfg.Screener.RankingWeights = tt.weights
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_AgentOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		agentType string
+		override  AgentOverride
+		wantErr   bool
+	}{
+		{"valid", "fundamental", AgentOverride{TimeoutSeconds: 90, Retries: 2, Model: "gpt-4o"}, false},
+		{"unknown type", "sentiment", AgentOverride{TimeoutSeconds: 10}, true},
+		{"negative timeout", "news", AgentOverride{TimeoutSeconds: -1}, true},
+		{"too many retries", "technical", AgentOverride{Retries: 6}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewTestConfig()
+			cfg.Agent.TypeOverrides = map[string]AgentOverride{tt.agentType: tt.override}
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidAgentOverrides(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	os.Setenv("AGENT_TYPE_OVERRIDES", "news=quick")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for malformed AGENT_TYPE_OVERRIDES")
+	}
+}
+
+func TestValidate_PositiveIntegers(t *testing.T) {
+	tests := []struct {
+		name    string
+		envKey  string
+		envVal  string
+		wantErr bool
+	}{
+		{
+			name:    "negative timeout uses default",
+			envKey:  "AGENT_TIMEOUT_SECONDS",
+			envVal:  "-5",
+			wantErr: false, // uses default
+		},
+		{
+			name:    "zero concurrency uses default",
+			envKey:  "ANALYSIS_CONCURRENCY_LIMIT",
+			envVal:  "0",
+			wantErr: false, // uses default
+		},
+		{
+			name:    "invalid number uses default",
+			envKey:  "OPENAI_MAX_TOKENS",
+			envVal:  "not-a-number",
+			wantErr: false, // uses default
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := saveEnv(t, allEnvKeys)
+			defer restoreEnv(t, saved)
+			clearEnv(t, allEnvKeys)
+
+			os.Setenv(tt.envKey, tt.envVal)
+
+			_, err := Load()
+			if tt.wantErr && err == nil {
+				t.Error("expected error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestHasDatabase(t *testing.T) {
+	cfg := &Config{
+		Database: DatabaseConfig{URL: ""},
+	}
+	if cfg.HasDatabase() {
+		t.Error("expected HasDatabase() to return false for empty URL")
+	}
+
+	cfg.Database.URL = "postgres://localhost/test"
+	if !cfg.HasDatabase() {
+		t.Error("expected HasDatabase() to return true for non-empty URL")
+	}
+}
+
+func TestHasAlpaca(t *testing.T) {
+	cfg := &Config{
+		Alpaca: AlpacaConfig{APIKey: "", APISecret: ""},
+	}
+	if cfg.HasAlpaca() {
+		t.Error("expected HasAlpaca() to return false for empty config")
+	}
+
+	cfg.Alpaca.APIKey = "key"
+	if cfg.HasAlpaca() {
+		t.Error("expected HasAlpaca() to return false without secret")
+	}
+
+	cfg.Alpaca.APISecret = "secret"
+	if !cfg.HasAlpaca() {
+		t.Error("expected HasAlpaca() to return true for complete config")
+	}
+}
+
+func TestHasAlphaVantage(t *testing.T) {
+	cfg := &Config{
+		AlphaVantage: AlphaVantageConfig{APIKey: ""},
+	}
+	if cfg.HasAlphaVantage() {
+		t.Error("expected HasAlphaVantage() to return false for empty key")
+	}
+
+	cfg.AlphaVantage.APIKey = "key"
+	if !cfg.HasAlphaVantage() {
+		t.Error("expected HasAlphaVantage() to return true for non-empty key")
+	}
+}
+
+func TestHasNewsAPI(t *testing.T) {
+	cfg := &Config{
+		NewsAPI: NewsAPIConfig{APIKey: ""},
+	}
+	if cfg.HasNewsAPI() {
+		t.Error("expected HasNewsAPI() to return false for empty key")
+	}
+
+	cfg.NewsAPI.APIKey = "key"
+	if !cfg.HasNewsAPI() {
+		t.Error("expected HasNewsAPI() to return true for non-empty key")
+	}
+}
+
+func TestGetEnvString(t *testing.T) {
+	key := "TEST_GET_ENV_STRING"
+	defer os.Unsetenv(key)
+
+	// Empty returns default
+	os.Unsetenv(key)
+	if got := getEnvString(key, "default"); got != "default" {
+		t.Errorf("expected 'default', got %s", got)
+	}
+
+	// Set value returns value
+	os.Setenv(key, "custom")
+	if got := getEnvString(key, "default"); got != "custom" {
+		t.Errorf("expected 'custom', got %s", got)
+	}
+}
+
+func TestGetEnvInt(t *testing.T) {
+	key := "TEST_GET_ENV_INT"
+	defer os.Unsetenv(key)
+
+	// Empty returns default
+	os.Unsetenv(key)
+	if got := getEnvInt(key, 42); got != 42 {
+		t.Errorf("expected 42, got %d", got)
+	}
+
+	// Valid integer
+	os.Setenv(key, "100")
+	if got := getEnvInt(key, 42); got != 100 {
+		t.Errorf("expected 100, got %d", got)
+	}
+
+	// Invalid integer returns default
+	os.Setenv(key, "invalid")
+	if got := getEnvInt(key, 42); got != 42 {
+		t.Errorf("expected 42 for invalid value, got %d", got)
+	}
+
+	// Negative returns default
+	os.Setenv(key, "-5")
+	if got := getEnvInt(key, 42); got != 42 {
+		t.Errorf("expected 42 for negative value, got %d", got)
+	}
+
+	// Zero returns default
+	os.Setenv(key, "0")
+	if got := getEnvInt(key, 42); got != 42 {
+		t.Errorf("expected 42 for zero value, got %d", got)
+	}
+}
+
+func TestGetEnvList(t *testing.T) {
+	key := "TEST_GET_ENV_LIST"
+	defer os.Unsetenv(key)
+
+	os.Unsetenv(key)
+	if got := getEnvList(key, []string{"NYSE"}); !reflect.DeepEqual(got, []string{"NYSE"}) {
+		t.Errorf("expected default, got %v", got)
+	}
+
+	os.Setenv(key, " nyse, ,Nasdaq ")
+	if got := getEnvList(key, nil); !reflect.DeepEqual(got, []string{"NYSE", "NASDAQ"}) {
+		t.Errorf("expected [NYSE NASDAQ], got %v", got)
+	}
+}
+
+func TestLoad_ScreenerListingDefaults(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Screener.Exchanges, []string{"NYSE", "NASDAQ", "AMEX"}) {
+		t.Errorf("Screener.Exchanges = %v", cfg.Screener.Exchanges)
+	}
+	if cfg.Screener.Country != "US" {
+		t.Errorf("Screener.Country = %q, want US", cfg.Screener.Country)
+	}
+}
+
+func TestLoad_PriceWatch(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.PriceWatch.Enabled {
+		t.Error("expected price watcher disabled by default")
+	}
+	if cfg.PriceWatch.MovePercent != 5.0 {
+		t.Errorf("PriceWatch.MovePercent = %v, want 5", cfg.PriceWatch.MovePercent)
+	}
+
+	os.Setenv("PRICE_WATCH_ENABLED", "true")
+	os.Setenv("PRICE_WATCH_MOVE_PERCENT", "2.5")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !cfg.PriceWatch.Enabled || cfg.PriceWatch.MovePercent != 2.5 {
+		t.Errorf("PriceWatch = %+v, want enabled with 2.5%% moves", cfg.PriceWatch)
+	}
+}
+
+func TestLoad_Reconciliation(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !cfg.Reconciliation.Enabled {
+		t.Error("expected reconciliation enabled by default")
+	}
+
+	os.Setenv("RECONCILIATION_ENABLED", "false")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Reconciliation.Enabled {
+		t.Error("expected RECONCILIATION_ENABLED=false to disable reconciliation")
+	}
+}
+
+func TestLoad_Fees(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	os.Setenv("FEE_COMMISSION_PER_TRADE", "1")
+	os.Setenv("FEE_COMMISSION_PER_SHARE", "0.005")
+	os.Setenv("FEE_SELL_RATE", "0.5") // Out of range, falls back to the default
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Fees.CommissionPerTrade != 1 || cfg.Fees.CommissionPerShare != 0.005 {
+		t.Errorf("Fees = %+v, want 1 per trade and 0.005 per share", cfg.Fees)
+	}
+	if cfg.Fees.SellFeeRate != 0 {
+		t.Errorf("SellFeeRate = %v, want default 0 for out-of-range value", cfg.Fees.SellFeeRate)
+	}
+}
+
+func TestLoad_PortfolioReview(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.PortfolioReview.MaxPositions != 25 {
+		t.Errorf("MaxPositions = %d, want default 25", cfg.PortfolioReview.MaxPositions)
+	}
+
+	os.Setenv("PORTFOLIO_REVIEW_MAX_POSITIONS", "10")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.PortfolioReview.MaxPositions != 10 {
+		t.Errorf("MaxPositions = %d, want 10", cfg.PortfolioReview.MaxPositions)
+	}
+}
+
+func TestLoad_APILedger(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.APILedger.RetentionDays != 30 {
+		t.Errorf("RetentionDays = %d, want default 30", cfg.APILedger.RetentionDays)
+	}
+
+	os.Setenv("API_LEDGER_RETENTION_DAYS", "7")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.APILedger.RetentionDays != 7 {
+		t.Errorf("RetentionDays = %d, want 7", cfg.APILedger.RetentionDays)
+	}
+}
+
+func TestLoad_WriteBuffer(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.WriteBuffer.Capacity != 1000 {
+		t.Errorf("Capacity = %d, want default 1000", cfg.WriteBuffer.Capacity)
+	}
+
+	os.Setenv("WRITE_BUFFER_CAPACITY", "50")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.WriteBuffer.Capacity != 50 {
+		t.Errorf("Capacity = %d, want 50", cfg.WriteBuffer.Capacity)
+	}
+}
+
+func TestLoad_Compliance(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Compliance.Jurisdiction != "us" || cfg.Compliance.RequireAcknowledgment {
+		t.Errorf("Compliance = %+v, want us without acknowledgment required", cfg.Compliance)
+	}
+
+	os.Setenv("COMPLIANCE_JURISDICTION", "uk")
+	os.Setenv("COMPLIANCE_DISCLAIMER", "Internal use only.")
+	os.Setenv("COMPLIANCE_REQUIRE_ACKNOWLEDGMENT", "true")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Compliance.Jurisdiction != "uk" || cfg.Compliance.Disclaimer != "Internal use only." || !cfg.Compliance.RequireAcknowledgment {
+		t.Errorf("Compliance = %+v, want the configured values", cfg.Compliance)
+	}
+
+	os.Setenv("COMPLIANCE_JURISDICTION", "mars")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error for an unknown jurisdiction")
+	}
+}
+
+func TestLoad_Backup(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Backup.Enabled || cfg.Backup.IntervalHours != 24 || cfg.Backup.Retention != 7 || cfg.Backup.PgDumpPath != "pg_dump" {
+		t.Errorf("Backup = %+v, want disabled daily backups keeping 7", cfg.Backup)
+	}
+
+	os.Setenv("BACKUP_ENABLED", "true")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error for backups without an encryption key")
+	}
+
+	os.Setenv("BACKUP_ENCRYPTION_KEY", "correct horse battery staple")
+	os.Setenv("BACKUP_INTERVAL_HOURS", "6")
+	os.Setenv("BACKUP_RETENTION", "28")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !cfg.Backup.Enabled || cfg.Backup.IntervalHours != 6 || cfg.Backup.Retention != 28 {
+		t.Errorf("Backup = %+v, want the configured values", cfg.Backup)
+	}
+
+	cfg.Backup.Retention = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected an error for a retention below 1")
+	}
+}
+
+func TestLoad_Warmup(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !cfg.Warmup.Enabled || cfg.Warmup.TimeoutSeconds != 3 {
+		t.Errorf("Warmup = %+v, want enabled with a 3s timeout", cfg.Warmup)
+	}
+
+	cfg.Warmup.TimeoutSeconds = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected an error for a timeout below 1")
+	}
+
+	os.Setenv("WARMUP_ENABLED", "false")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Warmup.Enabled {
+		t.Error("expected warm-up to be disabled")
+	}
+}
+
+func TestLoad_SignalOnly(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Agent.SignalOnly {
+		t.Error("SignalOnly should default to false")
+	}
+
+	os.Setenv("AGENT_SIGNAL_ONLY", "true")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !cfg.Agent.SignalOnly {
+		t.Error("SignalOnly should be enabled by AGENT_SIGNAL_ONLY=true")
+	}
+}
+
+func TestAlpacaConfig_IsPaper(t *testing.T) {
+	if !(AlpacaConfig{BaseURL: "https://paper-api.alpaca.markets"}).IsPaper() {
+		t.Error("expected paper URL to be paper")
+	}
+	if (AlpacaConfig{BaseURL: "https://api.alpaca.markets"}).IsPaper() {
+		t.Error("expected live URL not to be paper")
+	}
+}
+
+func TestGetEnvFloat(t *testing.T) {
+	key := "TEST_GET_ENV_FLOAT"
+	defer os.Unsetenv(key)
+
+	// Empty returns default
+	os.Unsetenv(key)
+	if got := getEnvFloat(key, 0.5); got != 0.5 {
+		t.Errorf("expected 0.5, got %f", got)
+	}
+
+	// Valid float
+	os.Setenv(key, "0.75")
+	if got := getEnvFloat(key, 0.5); got != 0.75 {
+		t.Errorf("expected 0.75, got %f", got)
+	}
+
+	// Invalid float returns default
+	os.Setenv(key, "invalid")
+	if got := getEnvFloat(key, 0.5); got != 0.5 {
+		t.Errorf("expected 0.5 for invalid value, got %f", got)
+	}
+
+	// Out of range (> 1) returns default
+	os.Setenv(key, "1.5")
+	if got := getEnvFloat(key, 0.5); got != 0.5 {
+		t.Errorf("expected 0.5 for value > 1, got %f", got)
+	}
+
+	// Negative returns default
+	os.Setenv(key, "-0.1")
+	if got := getEnvFloat(key, 0.5); got != 0.5 {
+		t.Errorf("expected 0.5 for negative value, got %f", got)
+	}
+}
+
+func TestHasOpenAI(t *testing.T) {
+	cfg := &Config{
+		OpenAI: OpenAIConfig{APIKey: ""},
+	}
+	if cfg.HasOpenAI() {
+		t.Error("expected HasOpenAI() to return false for empty key")
+	}
+
+	cfg.OpenAI.APIKey = "sk-test"
+	if !cfg.HasOpenAI() {
+		t.Error("expected HasOpenAI() to return true for non-empty key")
+	}
+}
+
+func TestLoad_OpenAIDefaults(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() with defaults failed: %v", err)
+	}
+
+	// Check OpenAI defaults
+	if cfg.OpenAI.Model != "gpt-4o" {
+		t.Errorf("expected OpenAI.Model='gpt-4o', got %s", cfg.OpenAI.Model)
+	}
+	if cfg.OpenAI.MaxTokens != 4096 {
+		t.Errorf("expected OpenAI.MaxTokens=4096, got %d", cfg.OpenAI.MaxTokens)
+	}
+}
+
+func TestLoad_OpenAICustomValues(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	os.Setenv("OPENAI_API_KEY", "sk-test-key")
+	os.Setenv("OPENAI_MODEL", "gpt-4-turbo")
+	os.Setenv("OPENAI_MAX_TOKENS", "2048")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() with OpenAI custom values failed: %v", err)
+	}
+
+	if cfg.OpenAI.APIKey != "sk-test-key" {
+		t.Errorf("expected OpenAI.APIKey='sk-test-key', got %s", cfg.OpenAI.APIKey)
+	}
+	if cfg.OpenAI.Model != "gpt-4-turbo" {
+		t.Errorf("expected OpenAI.Model='gpt-4-turbo', got %s", cfg.OpenAI.Model)
+	}
+	if cfg.OpenAI.MaxTokens != 2048 {
+		t.Errorf("expected OpenAI.MaxTokens=2048, got %d", cfg.OpenAI.MaxTokens)
+	}
+}
+
+func TestNewTestConfig_IncludesOpenAI(t *testing.T) {
+	cfg := NewTestConfig()
+
+	if cfg.OpenAI.Model != "gpt-4o" {
+		t.Errorf("expected OpenAI.Model='gpt-4o', got %s", cfg.OpenAI.Model)
+	}
+	if cfg.OpenAI.MaxTokens != 4096 {
 		t.Errorf("expected OpenAI.MaxTokens=4096, got %d", cfg.OpenAI.MaxTokens)
 	}
 }
+
+func TestLoad_CacheRefresh(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	want := CacheRefreshConfig{
+		Enabled:              true,
+		QuoteIntervalSeconds: 10,
+		RatioIntervalMinutes: 30,
+		JitterPercent:        20,
+		AlpacaCallsPerMinute: 60,
+		FMPCallsPerDay:       50,
+	}
+	if cfg.CacheRefresh != want {
+		t.Errorf("CacheRefresh = %+v, want %+v", cfg.CacheRefresh, want)
+	}
+
+	os.Setenv("CACHE_REFRESH_JITTER_PERCENT", "80")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error for jitter above 50%")
+	}
+
+	os.Setenv("CACHE_REFRESH_ENABLED", "false")
+	if _, err := Load(); err != nil {
+		t.Errorf("expected settings to be ignored while disabled, got %v", err)
+	}
+}
+
+func TestLoad_Tray(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if want := (TrayConfig{Enabled: true, RefreshSeconds: 30}); cfg.Tray != want {
+		t.Errorf("Tray = %+v, want %+v", cfg.Tray, want)
+	}
+
+	os.Setenv("TRAY_REFRESH_SECONDS", "0")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Tray.RefreshSeconds != 30 {
+		t.Errorf("Tray.RefreshSeconds = %d, want a zero interval to fall back to 30", cfg.Tray.RefreshSeconds)
+	}
+
+	cfg.Tray.RefreshSeconds = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected an error for a zero refresh interval")
+	}
+	cfg.Tray.Enabled = false
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected the interval to be ignored while disabled, got %v", err)
+	}
+}
+
+func TestLoad_RiskStats(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if want := (RiskStatsConfig{LookbackDays: 365, Benchmark: "SPY"}); cfg.RiskStats != want {
+		t.Errorf("RiskStats = %+v, want %+v", cfg.RiskStats, want)
+	}
+	if cfg.PositionSizing.TargetVolatility != 0 {
+		t.Errorf("PositionSizing.TargetVolatility = %v, want volatility scaling off by default", cfg.PositionSizing.TargetVolatility)
+	}
+
+	os.Setenv("RISK_STATS_BENCHMARK", "qqq")
+	os.Setenv("POSITION_TARGET_VOLATILITY", "0.25")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.RiskStats.Benchmark != "QQQ" || cfg.PositionSizing.TargetVolatility != 0.25 {
+		t.Errorf("Benchmark = %q, TargetVolatility = %v; want QQQ and 0.25", cfg.RiskStats.Benchmark, cfg.PositionSizing.TargetVolatility)
+	}
+
+	os.Setenv("RISK_STATS_LOOKBACK_DAYS", "20")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error for a lookback shorter than 30 days")
+	}
+	cfg.PositionSizing.TargetVolatility = -0.1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected an error for a negative target volatility")
+	}
+}
+
+func TestLoad_Logging(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Logging.Level != "info" || len(cfg.Logging.ModuleLevels) != 0 {
+		t.Errorf("Logging = %+v, want info with no module levels", cfg.Logging)
+	}
+
+	os.Setenv("LOG_LEVEL", "warn")
+	os.Setenv("LOG_MODULE_LEVELS", "Screener=debug, api=error")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Logging.Level != "warn" || cfg.Logging.ModuleLevels["screener"] != "debug" || cfg.Logging.ModuleLevels["api"] != "error" {
+		t.Errorf("Logging = %+v, want warn with screener at debug and api at error", cfg.Logging)
+	}
+
+	os.Setenv("LOG_MODULE_LEVELS", "billing=debug")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error for an unknown module")
+	}
+
+	os.Setenv("LOG_MODULE_LEVELS", "screener")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error for an entry without a level")
+	}
+
+	os.Setenv("LOG_MODULE_LEVELS", "")
+	os.Setenv("LOG_LEVEL", "verbose")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error for an invalid level")
+	}
+}
+
+func TestLoad_APIAuth(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.HTTP.AuthEnabled || cfg.HTTP.AdminToken != "" {
+		t.Errorf("HTTP = %+v, want auth off without an admin token", cfg.HTTP)
+	}
+
+	os.Setenv("API_AUTH_ENABLED", "true")
+	os.Setenv("API_ADMIN_TOKEN", strings.Repeat("k", 32))
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !cfg.HTTP.AuthEnabled || cfg.HTTP.AdminToken != strings.Repeat("k", 32) {
+		t.Errorf("HTTP = %+v, want auth on with the admin token", cfg.HTTP)
+	}
+
+	os.Setenv("API_ADMIN_TOKEN", "short")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error for a short admin token")
+	}
+}
+
+func TestLoad_Events(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Events.NATSURL != "" || cfg.Events.SubjectPrefix != "trade_machine" {
+		t.Errorf("Events = %+v, want no NATS server and the default subject prefix", cfg.Events)
+	}
+
+	os.Setenv("EVENTS_NATS_URL", "nats://localhost:4222")
+	os.Setenv("EVENTS_NATS_SUBJECT_PREFIX", "tm")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Events.NATSURL != "nats://localhost:4222" || cfg.Events.SubjectPrefix != "tm" {
+		t.Errorf("Events = %+v, want the configured server and prefix", cfg.Events)
+	}
+}
+
+func TestLoad_Tracing(t *testing.T) {
+	saved := saveEnv(t, allEnvKeys)
+	defer restoreEnv(t, saved)
+	clearEnv(t, allEnvKeys)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Tracing.Endpoint != "" || cfg.Tracing.ServiceName != "trade-machine" || cfg.Tracing.SampleRatio != 1 {
+		t.Errorf("Tracing = %+v, want no endpoint, the default service name and every trace sampled", cfg.Tracing)
+	}
+
+	os.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318/")
+	os.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=abc%3D%3D, x-team=ops")
+	os.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Tracing.Endpoint != "http://collector:4318/v1/traces" {
+		t.Errorf("Endpoint = %q, want the base endpoint with /v1/traces", cfg.Tracing.Endpoint)
+	}
+	if cfg.Tracing.Headers["api-key"] != "abc==" || cfg.Tracing.Headers["x-team"] != "ops" {
+		t.Errorf("Headers = %v, want decoded api-key and x-team", cfg.Tracing.Headers)
+	}
+	if cfg.Tracing.SampleRatio != 0.25 {
+		t.Errorf("SampleRatio = %v, want 0.25", cfg.Tracing.SampleRatio)
+	}
+
+	os.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "https://traces.example.com/ingest")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Tracing.Endpoint != "https://traces.example.com/ingest" {
+		t.Errorf("Endpoint = %q, want the traces endpoint as given", cfg.Tracing.Endpoint)
+	}
+
+	os.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error for a header without a value")
+	}
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := NewTestConfig()
+	cfg.Database.URL = "postgres://app:hunter2@db:5432/trademachine?sslmode=disable"
+	cfg.OpenAI.APIKey = "sk-live"
+	cfg.Alpaca.APISecret = "alpaca-secret"
+	cfg.Backup.EncryptionKey = "backup passphrase"
+	cfg.Tracing.Headers = map[string]string{"x-honeycomb-team": "hc-team-key"}
+
+	redacted, err := cfg.Redacted()
+	if err != nil {
+		t.Fatalf("Redacted() error = %v", err)
+	}
+	data, _ := json.Marshal(redacted)
+	for _, secret := range []string{"hunter2", "sk-live", "alpaca-secret", "backup passphrase", "hc-team-key"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("redacted config contains %q", secret)
+		}
+	}
+
+	openAI := redacted["OpenAI"].(map[string]any)
+	if openAI["APIKey"] != observability.Redacted || openAI["MaxTokens"] != float64(4096) {
+		t.Errorf("OpenAI = %v, want the key redacted and the token limit kept", openAI)
+	}
+	if fmp := redacted["FMP"].(map[string]any); fmp["APIKey"] != "" {
+		t.Errorf("FMP.APIKey = %v, want an unset key left empty", fmp["APIKey"])
+	}
+	if db := redacted["Database"].(map[string]any); db["URL"] != "postgres://app:[redacted]@db:5432/trademachine?sslmode=disable" {
+		t.Errorf("Database.URL = %v, want only the password redacted", db["URL"])
+	}
+}
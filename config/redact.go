@@ -0,0 +1,42 @@
+package config
+
+import (
+	"encoding/json"
+
+	"trade-machine/observability"
+)
+
+// Redacted returns the configuration as a JSON object with credentials replaced, so it
+// can be shared for support. Unset credentials stay empty, showing which services are
+// configured without revealing their keys.
+func (c *Config) Redacted() (map[string]any, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	redactTree(out)
+	// OTLP headers carry a vendor API key under a header name of the vendor's choosing
+	if tracing, ok := out["Tracing"].(map[string]any); ok {
+		if headers, ok := tracing["Headers"].(map[string]any); ok {
+			for k := range headers {
+				headers[k] = observability.Redacted
+			}
+		}
+	}
+	return out, nil
+}
+
+func redactTree(m map[string]any) {
+	for k, v := range m {
+		switch v := v.(type) {
+		case map[string]any:
+			redactTree(v)
+		case string:
+			m[k] = observability.RedactValue(k, v)
+		}
+	}
+}
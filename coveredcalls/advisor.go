@@ -0,0 +1,143 @@
+package coveredcalls
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+	"trade-machine/observability"
+	"trade-machine/services"
+
+	"github.com/shopspring/decimal"
+)
+
+// suggestionsPerPosition is how many calls are suggested for each position at most
+const suggestionsPerPosition = 3
+
+// Broker supplies the positions calls are written against, valued at current prices
+type Broker interface {
+	GetPositions(ctx context.Context) ([]models.Position, error)
+}
+
+// CoveredCallAdvisor suggests out-of-the-money calls to sell against long positions of at
+// least one contract's worth of shares, ranked by the premium they would collect per year
+type CoveredCallAdvisor struct {
+	broker Broker
+	chains services.OptionsDataService
+	cfg    *config.OptionsConfig
+	now    func() time.Time
+}
+
+// NewCoveredCallAdvisor creates a new CoveredCallAdvisor
+func NewCoveredCallAdvisor(broker Broker, chains services.OptionsDataService, cfg *config.OptionsConfig) *CoveredCallAdvisor {
+	return &CoveredCallAdvisor{
+		broker: broker,
+		chains: chains,
+		cfg:    cfg,
+		now:    time.Now,
+	}
+}
+
+// Suggest returns up to three calls for each long position, struck between the configured
+// distances above the share price and expiring within the configured window. Calls are
+// priced at their bid; those without one, or with a delta above the limit, are left out.
+// Positions that can't be covered, or whose chain can't be fetched, are listed as skipped.
+func (a *CoveredCallAdvisor) Suggest(ctx context.Context) (*models.CoveredCallReport, error) {
+	positions, err := a.broker.GetPositions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+	now := a.now()
+
+	report := &models.CoveredCallReport{Suggestions: []models.CoveredCallSuggestion{}, GeneratedAt: now}
+	for _, p := range positions {
+		if p.EffectiveSide() == models.PositionSideShort {
+			continue
+		}
+		contracts := int(p.Quantity.Div(decimal.NewFromInt(models.OptionContractSize)).IntPart())
+		price := p.CurrentPrice.InexactFloat64()
+		switch {
+		case contracts < 1:
+			report.Skipped = append(report.Skipped, models.CoveredCallSkip{Symbol: p.Symbol, Reason: fmt.Sprintf("fewer than %d shares", models.OptionContractSize)})
+			continue
+		case price <= 0:
+			report.Skipped = append(report.Skipped, models.CoveredCallSkip{Symbol: p.Symbol, Reason: "no current price"})
+			continue
+		}
+
+		suggestions, err := a.suggestFor(ctx, p, contracts, price, now)
+		if err != nil {
+			observability.Warn("option chain unavailable", "symbol", p.Symbol, "error", err)
+			report.Skipped = append(report.Skipped, models.CoveredCallSkip{Symbol: p.Symbol, Reason: err.Error()})
+			continue
+		}
+		if len(suggestions) == 0 {
+			report.Skipped = append(report.Skipped, models.CoveredCallSkip{Symbol: p.Symbol, Reason: "no bid calls in the strike and expiration range"})
+			continue
+		}
+		report.Suggestions = append(report.Suggestions, suggestions...)
+	}
+
+	sort.SliceStable(report.Suggestions, func(i, j int) bool {
+		return report.Suggestions[i].AnnualizedYield > report.Suggestions[j].AnnualizedYield
+	})
+	return report, nil
+}
+
+// suggestFor returns a position's best calls by annualized yield
+func (a *CoveredCallAdvisor) suggestFor(ctx context.Context, p models.Position, contracts int, price float64, now time.Time) ([]models.CoveredCallSuggestion, error) {
+	filter := models.OptionChainFilter{
+		Type:          models.OptionTypeCall,
+		MinStrike:     price * (1 + a.cfg.MinOTMPercent/100),
+		MaxStrike:     price * (1 + a.cfg.MaxOTMPercent/100),
+		ExpiresAfter:  now.AddDate(0, 0, a.cfg.MinDays),
+		ExpiresBefore: now.AddDate(0, 0, a.cfg.MaxDays),
+	}
+	chain, err := a.chains.GetOptionChain(ctx, p.Symbol, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := float64(contracts * models.OptionContractSize)
+	costBasis := p.AvgEntryPrice.InexactFloat64()
+	var suggestions []models.CoveredCallSuggestion
+	for _, c := range chain {
+		if c.Type != models.OptionTypeCall || c.Bid <= 0 || c.Strike <= price {
+			continue
+		}
+		if c.Delta != nil && *c.Delta > a.cfg.MaxDelta {
+			continue
+		}
+		days := max(c.DaysToExpiry(now), 1)
+		premiumYield := c.Bid / price * 100
+		suggestions = append(suggestions, models.CoveredCallSuggestion{
+			Symbol:          p.Symbol,
+			Shares:          p.Quantity,
+			Contracts:       contracts,
+			SharePrice:      price,
+			AvgEntryPrice:   costBasis,
+			Contract:        c,
+			DaysToExpiry:    days,
+			OTMPercent:      round2((c.Strike/price - 1) * 100),
+			Premium:         round2(c.Bid * shares),
+			PremiumYield:    round2(premiumYield),
+			AnnualizedYield: round2(premiumYield * 365 / float64(days)),
+			CalledAwayYield: round2((c.Strike - price + c.Bid) / price * 100),
+			BelowCostBasis:  c.Strike+c.Bid < costBasis,
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].AnnualizedYield > suggestions[j].AnnualizedYield
+	})
+	return suggestions[:min(len(suggestions), suggestionsPerPosition)], nil
+}
+
+// round2 rounds to two decimal places
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
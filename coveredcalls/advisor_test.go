@@ -0,0 +1,158 @@
+package coveredcalls
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
+)
+
+type mockBroker struct {
+	positions []models.Position
+}
+
+func (m *mockBroker) GetPositions(ctx context.Context) ([]models.Position, error) {
+	return m.positions, nil
+}
+
+type mockChains struct {
+	chains  map[string][]models.OptionContract
+	err     map[string]error
+	filters map[string]models.OptionChainFilter
+}
+
+func (m *mockChains) GetOptionChain(ctx context.Context, underlying string, filter models.OptionChainFilter) ([]models.OptionContract, error) {
+	m.filters[underlying] = filter
+	if err := m.err[underlying]; err != nil {
+		return nil, err
+	}
+	return m.chains[underlying], nil
+}
+
+var testNow = time.Date(2025, 1, 3, 15, 0, 0, 0, time.UTC)
+
+func call(underlying string, strike, bid float64, days int, delta *float64) models.OptionContract {
+	return models.OptionContract{
+		Underlying: underlying,
+		Type:       models.OptionTypeCall,
+		Strike:     strike,
+		Expiration: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC).AddDate(0, 0, days),
+		Bid:        bid,
+		Ask:        bid + 0.1,
+		Delta:      delta,
+	}
+}
+
+func position(symbol string, quantity int64, price, entry float64) models.Position {
+	return models.Position{Symbol: symbol, Quantity: decimal.NewFromInt(quantity), CurrentPrice: decimal.NewFromFloat(price), AvgEntryPrice: decimal.NewFromFloat(entry)}
+}
+
+func testConfig() *config.OptionsConfig {
+	return &config.OptionsConfig{Feed: "indicative", MinDays: 14, MaxDays: 60, MinOTMPercent: 2, MaxOTMPercent: 15, MaxDelta: 0.4}
+}
+
+func TestCoveredCallAdvisor_Suggest(t *testing.T) {
+	highDelta := 0.55
+	lowDelta := 0.25
+	broker := &mockBroker{positions: []models.Position{
+		position("AAPL", 250, 100, 80),
+		position("KO", 50, 60, 55),
+		{Symbol: "TSLA", Quantity: decimal.NewFromInt(-100), CurrentPrice: decimal.NewFromInt(200), Side: models.PositionSideShort},
+		position("INTC", 300, 20, 45),
+		position("XOM", 100, 110, 90),
+	}}
+	chains := &mockChains{
+		chains: map[string][]models.OptionContract{
+			"AAPL": {
+				call("AAPL", 105, 2.00, 30, &lowDelta),
+				call("AAPL", 103, 3.50, 30, &highDelta),
+				call("AAPL", 110, 0, 30, nil),
+				call("AAPL", 105, 1.10, 15, nil),
+				call("AAPL", 110, 0.50, 45, nil),
+				call("AAPL", 112, 0.20, 45, nil),
+			},
+			"INTC": {call("INTC", 21, 0.40, 30, nil)},
+		},
+		err:     map[string]error{"XOM": errors.New("rate limited")},
+		filters: make(map[string]models.OptionChainFilter),
+	}
+	advisor := NewCoveredCallAdvisor(broker, chains, testConfig())
+	advisor.now = func() time.Time { return testNow }
+
+	report, err := advisor.Suggest(context.Background())
+	if err != nil {
+		t.Fatalf("Suggest error = %v", err)
+	}
+
+	filter := chains.filters["AAPL"]
+	if filter.Type != models.OptionTypeCall || math.Abs(filter.MinStrike-102) > 1e-9 || math.Abs(filter.MaxStrike-115) > 1e-9 || !filter.ExpiresAfter.Equal(testNow.AddDate(0, 0, 14)) || !filter.ExpiresBefore.Equal(testNow.AddDate(0, 0, 60)) {
+		t.Errorf("AAPL filter = %+v, want calls 2-15%% above the price expiring in 14-60 days", filter)
+	}
+	if _, ok := chains.filters["TSLA"]; ok {
+		t.Error("expected no chain fetched for a short position")
+	}
+
+	var aapl []models.CoveredCallSuggestion
+	var intc *models.CoveredCallSuggestion
+	for i, s := range report.Suggestions {
+		switch s.Symbol {
+		case "AAPL":
+			aapl = append(aapl, s)
+		case "INTC":
+			intc = &report.Suggestions[i]
+		}
+	}
+	if len(aapl) != 3 {
+		t.Fatalf("got %d AAPL suggestions, want the best three of the bid, low-delta calls", len(aapl))
+	}
+	// The 15-day call yields 1.1% in 15 days, beating 2% in 30 days
+	first := aapl[0]
+	if first.Contract.Strike != 105 || first.DaysToExpiry != 15 || first.AnnualizedYield != 26.77 {
+		t.Errorf("best AAPL call = %+v, want the 15-day 105 call at 26.77%% a year", first)
+	}
+	second := aapl[1]
+	if second.Contracts != 2 || second.Premium != 400 || second.PremiumYield != 2 || second.OTMPercent != 5 || second.CalledAwayYield != 7 || second.BelowCostBasis {
+		t.Errorf("30-day AAPL call = %+v, want two contracts collecting $400", second)
+	}
+	for _, s := range aapl {
+		if s.Contract.Strike == 103 || s.Contract.Bid == 0 {
+			t.Errorf("suggested %+v, want no high-delta or unbid calls", s.Contract)
+		}
+	}
+
+	if intc == nil || !intc.BelowCostBasis || intc.Contracts != 3 {
+		t.Errorf("INTC suggestion = %+v, want three contracts flagged below cost basis", intc)
+	}
+
+	for i := 1; i < len(report.Suggestions); i++ {
+		if report.Suggestions[i].AnnualizedYield > report.Suggestions[i-1].AnnualizedYield {
+			t.Fatalf("suggestions not sorted by annualized yield: %+v", report.Suggestions)
+		}
+	}
+
+	skipped := make(map[string]string)
+	for _, s := range report.Skipped {
+		skipped[s.Symbol] = s.Reason
+	}
+	if len(skipped) != 2 || skipped["KO"] != "fewer than 100 shares" || skipped["XOM"] != "rate limited" {
+		t.Errorf("skipped = %v, want KO for its size and XOM for its chain", skipped)
+	}
+}
+
+func TestCoveredCallAdvisor_Suggest_NoPositions(t *testing.T) {
+	advisor := NewCoveredCallAdvisor(&mockBroker{}, &mockChains{filters: make(map[string]models.OptionChainFilter)}, testConfig())
+
+	report, err := advisor.Suggest(context.Background())
+	if err != nil {
+		t.Fatalf("Suggest error = %v", err)
+	}
+	if report.Suggestions == nil || len(report.Suggestions) != 0 || len(report.Skipped) != 0 {
+		t.Errorf("report = %+v, want an empty list of suggestions", report)
+	}
+}
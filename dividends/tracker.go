@@ -0,0 +1,128 @@
+package dividends
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/shopspring/decimal"
+)
+
+// Source supplies a symbol's dividend history
+type Source interface {
+	GetDividendHistory(ctx context.Context, symbol string) ([]models.Dividend, error)
+}
+
+// Repository defines the repository operations needed by Tracker
+type Repository interface {
+	GetPositions(ctx context.Context) ([]models.Position, error)
+	SaveDividends(ctx context.Context, dividends []models.Dividend) error
+	GetDividends(ctx context.Context, symbols []string) ([]models.Dividend, error)
+}
+
+// Tracker records the dividends that go ex while a position is held, expected until their
+// payment date and received after, and projects the portfolio's annual dividend income
+type Tracker struct {
+	repo   Repository
+	source Source
+	now    func() time.Time
+}
+
+// NewTracker creates a new Tracker
+func NewTracker(repo Repository, source Source) *Tracker {
+	return &Tracker{
+		repo:   repo,
+		source: source,
+		now:    time.Now,
+	}
+}
+
+// Income refreshes the dividends tracked for the long positions and returns each one's
+// projected annual income and yield on cost, from the trailing twelve months of dividends.
+// A position whose history can't be fetched is projected at zero and keeps the dividends
+// tracked for it earlier. Short positions owe dividends rather than earn them and are left out.
+func (t *Tracker) Income(ctx context.Context) (*models.DividendIncome, error) {
+	positions, err := t.repo.GetPositions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+	now := t.now()
+
+	var held []models.Position
+	var symbols []string
+	annual := make(map[string]decimal.Decimal)
+	var tracked []models.Dividend
+	for _, p := range positions {
+		if p.Side == models.PositionSideShort {
+			continue
+		}
+		held = append(held, p)
+		symbols = append(symbols, p.Symbol)
+
+		history, err := t.source.GetDividendHistory(ctx, p.Symbol)
+		if err != nil {
+			observability.Warn("dividend history unavailable", "symbol", p.Symbol, "error", err)
+			continue
+		}
+		annual[p.Symbol] = models.TrailingAnnualDividend(history, now)
+		tracked = append(tracked, trackWhileHeld(p, history, now)...)
+	}
+	if len(held) == 0 {
+		return models.NewDividendIncome(nil, now), nil
+	}
+
+	if len(tracked) > 0 {
+		if err := t.repo.SaveDividends(ctx, tracked); err != nil {
+			return nil, err
+		}
+	}
+	stored, err := t.repo.GetDividends(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+	bySymbol := make(map[string][]models.Dividend)
+	for _, d := range stored {
+		d.Status = d.StatusAt(now)
+		bySymbol[d.Symbol] = append(bySymbol[d.Symbol], d)
+	}
+
+	incomes := make([]models.PositionDividendIncome, 0, len(held))
+	for _, p := range held {
+		var own []models.Dividend
+		since := heldSince(p)
+		for _, d := range bySymbol[p.Symbol] {
+			// Dividends from before a sold position was bought back don't count toward it
+			if !d.ExDate.Before(since) {
+				own = append(own, d)
+			}
+		}
+		incomes = append(incomes, models.NewPositionDividendIncome(p, annual[p.Symbol], own))
+	}
+	return models.NewDividendIncome(incomes, now), nil
+}
+
+// heldSince returns the market date the position was opened, in the form ex-dates take
+func heldSince(p models.Position) time.Time {
+	y, m, d := p.CreatedAt.In(models.MarketLocation()).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// trackWhileHeld returns the dividends in history that go ex on or after the day the
+// position was opened, on its current shares
+func trackWhileHeld(p models.Position, history []models.Dividend, now time.Time) []models.Dividend {
+	since := heldSince(p)
+	var tracked []models.Dividend
+	for _, d := range history {
+		if d.ExDate.Before(since) {
+			continue
+		}
+		d.Symbol = p.Symbol
+		d.Shares = p.Quantity
+		d.Status = d.StatusAt(now)
+		tracked = append(tracked, d)
+	}
+	return tracked
+}
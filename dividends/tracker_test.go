@@ -0,0 +1,131 @@
+package dividends
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
+)
+
+type mockSource struct {
+	history map[string][]models.Dividend
+	err     map[string]error
+}
+
+func (m *mockSource) GetDividendHistory(ctx context.Context, symbol string) ([]models.Dividend, error) {
+	if err := m.err[symbol]; err != nil {
+		return nil, err
+	}
+	return m.history[symbol], nil
+}
+
+// mockRepo keeps saved dividends by symbol and ex-date, like the table's unique key
+type mockRepo struct {
+	positions []models.Position
+	saved     map[string]models.Dividend
+}
+
+func (m *mockRepo) GetPositions(ctx context.Context) ([]models.Position, error) {
+	return m.positions, nil
+}
+
+func (m *mockRepo) SaveDividends(ctx context.Context, dividends []models.Dividend) error {
+	for _, d := range dividends {
+		m.saved[d.Symbol+d.ExDate.Format("2006-01-02")] = d
+	}
+	return nil
+}
+
+func (m *mockRepo) GetDividends(ctx context.Context, symbols []string) ([]models.Dividend, error) {
+	var dividends []models.Dividend
+	for _, d := range m.saved {
+		for _, s := range symbols {
+			if d.Symbol == s {
+				dividends = append(dividends, d)
+			}
+		}
+	}
+	return dividends, nil
+}
+
+func date(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+func dividend(exDate, paid string, amount float64) models.Dividend {
+	payment := date(paid)
+	return models.Dividend{ExDate: date(exDate), PaymentDate: &payment, AmountPerShare: decimal.NewFromFloat(amount)}
+}
+
+func TestTracker_Income(t *testing.T) {
+	now := time.Date(2026, 6, 20, 16, 0, 0, 0, time.UTC)
+	repo := &mockRepo{
+		positions: []models.Position{
+			{Symbol: "KO", Quantity: decimal.NewFromInt(100), AvgEntryPrice: decimal.NewFromInt(50), Side: models.PositionSideLong, CreatedAt: date("2026-01-05")},
+			{Symbol: "T", Quantity: decimal.NewFromInt(50), AvgEntryPrice: decimal.NewFromInt(20), Side: models.PositionSideShort, CreatedAt: date("2026-01-05")},
+			{Symbol: "PEP", Quantity: decimal.NewFromInt(10), AvgEntryPrice: decimal.NewFromInt(160), Side: models.PositionSideLong, CreatedAt: date("2026-01-05")},
+		},
+		saved: make(map[string]models.Dividend),
+	}
+	source := &mockSource{
+		history: map[string][]models.Dividend{
+			"KO": {
+				dividend("2026-06-13", "2026-07-01", 0.5), // Ex while held, not yet paid
+				dividend("2026-03-13", "2026-04-01", 0.5), // Paid while held
+				dividend("2025-11-28", "2025-12-15", 0.5), // Before the position was opened
+				dividend("2025-09-12", "2025-10-01", 0.5),
+				dividend("2025-06-13", "2025-07-01", 0.45), // Over a year ago
+			},
+		},
+		err: map[string]error{"PEP": errors.New("FMP unavailable")},
+	}
+	tracker := NewTracker(repo, source)
+	tracker.now = func() time.Time { return now }
+
+	income, err := tracker.Income(context.Background())
+	if err != nil {
+		t.Fatalf("Income() error = %v", err)
+	}
+	if len(income.Positions) != 2 {
+		t.Fatalf("got %d positions, want the two long positions", len(income.Positions))
+	}
+
+	ko := income.Positions[0]
+	if !ko.AnnualDividendPerShare.Equal(decimal.NewFromInt(2)) || !ko.ProjectedAnnualIncome.Equal(decimal.NewFromInt(200)) {
+		t.Errorf("KO annual %s per share and %s income, want 2 and 200", ko.AnnualDividendPerShare, ko.ProjectedAnnualIncome)
+	}
+	if ko.YieldOnCost != 4 {
+		t.Errorf("KO yield on cost = %v, want 4", ko.YieldOnCost)
+	}
+	if len(ko.Dividends) != 2 || !ko.ReceivedIncome.Equal(decimal.NewFromInt(50)) || !ko.ExpectedIncome.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("KO tracked %d dividends, received %s and expected %s; want 2, 50 and 50", len(ko.Dividends), ko.ReceivedIncome, ko.ExpectedIncome)
+	}
+	if len(repo.saved) != 2 {
+		t.Errorf("saved %d dividends, want the two that went ex while held", len(repo.saved))
+	}
+
+	pep := income.Positions[1]
+	if !pep.ProjectedAnnualIncome.IsZero() || pep.YieldOnCost != 0 {
+		t.Errorf("PEP = %+v, want no projected income without its history", pep)
+	}
+	if !income.ProjectedAnnualIncome.Equal(decimal.NewFromInt(200)) || !income.CostBasis.Equal(decimal.NewFromInt(6600)) {
+		t.Errorf("totals %s income on %s cost, want 200 on 6600", income.ProjectedAnnualIncome, income.CostBasis)
+	}
+}
+
+func TestTracker_IncomeWithoutPositions(t *testing.T) {
+	tracker := NewTracker(&mockRepo{saved: make(map[string]models.Dividend)}, &mockSource{})
+
+	income, err := tracker.Income(context.Background())
+	if err != nil {
+		t.Fatalf("Income() error = %v", err)
+	}
+	if income.Positions == nil || len(income.Positions) != 0 || !income.ProjectedAnnualIncome.IsZero() {
+		t.Errorf("income = %+v, want an empty report", income)
+	}
+}
@@ -0,0 +1,179 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"trade-machine/observability"
+)
+
+// subscriberQueueSize is how many events can wait for a subscriber before new ones are
+// dropped for it
+const subscriberQueueSize = 100
+
+// Handler receives events for a subscriber, one at a time in publish order
+type Handler func(e Event)
+
+// subscriber is a named handler with its own queue, so a slow one delays only itself
+type subscriber struct {
+	name   string
+	types  []Type // Empty receives every type
+	queue  chan Event
+	handle Handler
+}
+
+func (s *subscriber) wants(t Type) bool {
+	return len(s.types) == 0 || slices.Contains(s.types, t)
+}
+
+// Bus delivers domain events to subscribers in the background. Publishers announce what
+// happened without knowing who listens, so a new integration is a subscriber rather than
+// another call wherever the event occurs. Publishing never blocks: each subscriber has a
+// bounded queue, and an event it has no room for is dropped for that subscriber and counted.
+type Bus struct {
+	mu       sync.RWMutex
+	subs     []*subscriber
+	backends []Backend
+	closed   bool
+	wg       sync.WaitGroup
+	now      func() time.Time
+}
+
+// NewBus creates an event bus without subscribers
+func NewBus() *Bus {
+	return &Bus{now: time.Now}
+}
+
+// Subscribe registers handle under name for the given event types, or for every type if
+// none are given. Subscribing to a closed bus does nothing.
+func (b *Bus) Subscribe(name string, handle Handler, types ...Type) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	s := &subscriber{name: name, types: types, queue: make(chan Event, subscriberQueueSize), handle: handle}
+	b.subs = append(b.subs, s)
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for e := range s.queue {
+			deliver(s, e)
+		}
+	}()
+}
+
+// Backend carries events beyond this process, such as a NATS server other services
+// subscribe to. *NATS is one.
+type Backend interface {
+	Publish(subject string, data []byte) error
+	Close() error
+}
+
+// Forward sends every event published from now on to backend as JSON, under the subject
+// prefix.<type>. Forwarding is a subscriber like any other, so an unreachable backend
+// drops events for itself without holding up publishers or other subscribers. Close closes
+// the backend once queued events are sent.
+func (b *Bus) Forward(prefix string, backend Backend) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.backends = append(b.backends, backend)
+	b.mu.Unlock()
+
+	b.Subscribe("backend", func(e Event) {
+		data, err := json.Marshal(e)
+		if err != nil {
+			observability.Warn("failed to encode event for backend", "type", string(e.Type), "error", err)
+			return
+		}
+		if err := backend.Publish(prefix+"."+string(e.Type), data); err != nil {
+			observability.Warn("failed to forward event to backend", "type", string(e.Type), "error", err)
+		}
+	})
+}
+
+// deliver hands one event to a subscriber, containing a panic so the subscriber keeps
+// receiving later events
+func deliver(s *subscriber, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			observability.Error("event subscriber panicked",
+				"subscriber", s.name,
+				"type", string(e.Type),
+				"panic", fmt.Sprint(r))
+		}
+	}()
+	s.handle(e)
+}
+
+// Publish queues an event for every subscriber of its type. Events published after Close
+// are discarded.
+func (b *Bus) Publish(t Type, payload any) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+
+	metrics := observability.GetMetrics()
+	metrics.RecordEventPublished(string(t))
+	e := Event{Type: t, Time: b.now(), Payload: payload}
+	for _, s := range b.subs {
+		if !s.wants(t) {
+			continue
+		}
+		select {
+		case s.queue <- e:
+		default:
+			metrics.RecordEventDropped(string(t), s.name)
+			observability.Warn("event subscriber queue full, dropping event",
+				"subscriber", s.name,
+				"type", string(t))
+		}
+	}
+}
+
+// Close stops accepting events, waits for subscribers to handle those already queued, and
+// closes forwarding backends
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	for _, s := range b.subs {
+		close(s.queue)
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+	for _, backend := range b.backends {
+		if err := backend.Close(); err != nil {
+			observability.Warn("failed to close event backend", "error", err)
+		}
+	}
+}
+
+// defaultBus receives events published with the package-level Publish; nil discards them
+var defaultBus atomic.Pointer[Bus]
+
+// SetDefault sets the bus the package-level Publish uses. Pass nil to discard events.
+func SetDefault(b *Bus) {
+	defaultBus.Store(b)
+}
+
+// Publish publishes an event on the default bus, if one is set
+func Publish(t Type, payload any) {
+	if b := defaultBus.Load(); b != nil {
+		b.Publish(t, payload)
+	}
+}
@@ -0,0 +1,169 @@
+package events
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"trade-machine/models"
+)
+
+// recorder collects the events a subscriber received
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recorder) handle(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *recorder) types() []Type {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	types := make([]Type, len(r.events))
+	for i, e := range r.events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+func TestBus_PublishFiltersByType(t *testing.T) {
+	bus := NewBus()
+	all, trades := &recorder{}, &recorder{}
+	bus.Subscribe("all", all.handle)
+	bus.Subscribe("trades", trades.handle, TradeFilled)
+
+	trade := &models.Trade{Symbol: "AAPL"}
+	bus.Publish(RecommendationCreated, &models.Recommendation{Symbol: "AAPL"})
+	bus.Publish(TradeFilled, trade)
+	bus.Close()
+
+	if got := all.types(); len(got) != 2 || got[0] != RecommendationCreated || got[1] != TradeFilled {
+		t.Errorf("all received %v, want both events in publish order", got)
+	}
+	if got := trades.types(); len(got) != 1 || got[0] != TradeFilled {
+		t.Fatalf("trades received %v, want only the fill", got)
+	}
+	if e := trades.events[0]; e.Trade() != trade || e.Recommendation() != nil || e.Time.IsZero() {
+		t.Errorf("event = %+v, want the published trade with a timestamp", e)
+	}
+}
+
+func TestBus_DropsWhenQueueFull(t *testing.T) {
+	bus := NewBus()
+	release := make(chan struct{})
+	slow := &recorder{}
+	bus.Subscribe("slow", func(e Event) {
+		<-release
+		slow.handle(e)
+	})
+
+	// One event is taken off the queue and blocks in the handler, the rest fill the queue
+	for i := 0; i < subscriberQueueSize+10; i++ {
+		bus.Publish(ScreenerCompleted, &models.ScreenerRun{})
+	}
+	close(release)
+	bus.Close()
+
+	if n := len(slow.types()); n < subscriberQueueSize || n > subscriberQueueSize+1 {
+		t.Errorf("slow subscriber handled %d events, want the queue's worth and the drops discarded", n)
+	}
+}
+
+func TestBus_SubscriberPanicIsContained(t *testing.T) {
+	bus := NewBus()
+	got := &recorder{}
+	bus.Subscribe("flaky", func(e Event) {
+		if _, ok := e.BreakerOpen(); ok {
+			panic("boom")
+		}
+		got.handle(e)
+	})
+
+	bus.Publish(BreakerOpened, BreakerOpen{Provider: "fmp"})
+	bus.Publish(TradeFilled, &models.Trade{})
+	bus.Close()
+
+	if types := got.types(); len(types) != 1 || types[0] != TradeFilled {
+		t.Errorf("received %v after a panic, want later events still delivered", types)
+	}
+}
+
+func TestBus_Closed(t *testing.T) {
+	bus := NewBus()
+	got := &recorder{}
+	bus.Subscribe("before", got.handle)
+	bus.Close()
+	bus.Close()
+
+	bus.Publish(TradeFilled, &models.Trade{})
+	bus.Subscribe("after", got.handle)
+	if len(got.types()) != 0 {
+		t.Errorf("received %v, want events after Close discarded", got.types())
+	}
+}
+
+func TestPublish_DefaultBus(t *testing.T) {
+	Publish(TradeFilled, &models.Trade{}) // No default bus: discarded
+
+	bus := NewBus()
+	got := &recorder{}
+	bus.Subscribe("default", got.handle)
+	SetDefault(bus)
+	t.Cleanup(func() { SetDefault(nil) })
+
+	Publish(TradeFilled, &models.Trade{})
+	bus.Close()
+	if len(got.types()) != 1 {
+		t.Errorf("received %v, want the event published on the default bus", got.types())
+	}
+}
+
+// fakeBackend records what the bus forwards to it
+type fakeBackend struct {
+	mu       sync.Mutex
+	subjects []string
+	data     [][]byte
+	closed   bool
+}
+
+func (f *fakeBackend) Publish(subject string, data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subjects = append(f.subjects, subject)
+	f.data = append(f.data, data)
+	return nil
+}
+
+func (f *fakeBackend) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+func TestBus_Forward(t *testing.T) {
+	bus := NewBus()
+	backend := &fakeBackend{}
+	bus.Forward("tm", backend)
+
+	bus.Publish(TradeFilled, &models.Trade{Symbol: "AAPL"})
+	bus.Close()
+
+	if len(backend.subjects) != 1 || backend.subjects[0] != "tm.trade.filled" {
+		t.Fatalf("forwarded to %v, want tm.trade.filled", backend.subjects)
+	}
+	var e struct {
+		Type    Type         `json:"type"`
+		Payload models.Trade `json:"payload"`
+	}
+	if err := json.Unmarshal(backend.data[0], &e); err != nil || e.Type != TradeFilled || e.Payload.Symbol != "AAPL" {
+		t.Errorf("forwarded %s (%v), want the event as JSON", backend.data[0], err)
+	}
+	if !backend.closed {
+		t.Error("backend left open after Close")
+	}
+}
@@ -0,0 +1,120 @@
+package events
+
+import (
+	"time"
+
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+)
+
+// Type identifies a domain event
+type Type string
+
+const (
+	// RecommendationCreated is published when an analysis saves a recommendation; the
+	// payload is the *models.Recommendation
+	RecommendationCreated Type = "recommendation.created"
+	// RecommendationApproved is published when a recommendation is approved; the payload
+	// is the *models.Recommendation as saved
+	RecommendationApproved Type = "recommendation.approved"
+	// RecommendationRejected is published when a recommendation is rejected; the payload
+	// is the *models.Recommendation as saved
+	RecommendationRejected Type = "recommendation.rejected"
+	// AgentRunStarted is published when an agent starts analyzing a symbol; the payload
+	// is the *models.AgentRun
+	AgentRunStarted Type = "agent_run.started"
+	// AgentRunCompleted is published when an agent run finishes, whether it succeeded or
+	// failed; the payload is the *models.AgentRun
+	AgentRunCompleted Type = "agent_run.completed"
+	// TradeFilled is published when a broker fill is recorded on a trade; the payload is
+	// the *models.Trade
+	TradeFilled Type = "trade.filled"
+	// ScreenerCompleted is published when a screener run, retry or replay finishes; the
+	// payload is the *models.ScreenerRun
+	ScreenerCompleted Type = "screener.completed"
+	// ScreenerCandidateStarted is published when a screener run starts analyzing a
+	// candidate; the payload is a CandidateProgress
+	ScreenerCandidateStarted Type = "screener.candidate_started"
+	// ScreenerCandidateScored is published when a candidate's analysis produces a
+	// recommendation; the payload is a CandidateProgress with the score
+	ScreenerCandidateScored Type = "screener.candidate_scored"
+	// ScreenerCandidateFailed is published when a candidate's analysis fails; the payload
+	// is a CandidateProgress with the error
+	ScreenerCandidateFailed Type = "screener.candidate_failed"
+	// BreakerOpened is published when a provider's circuit breaker trips; the payload is
+	// a BreakerOpen
+	BreakerOpened Type = "breaker.opened"
+	// ActionAudited is published when a user takes a state-changing action, for the audit
+	// log; the payload is the *models.AuditEntry
+	ActionAudited Type = "audit.action"
+)
+
+// Event is a domain event delivered to subscribers
+type Event struct {
+	Type    Type      `json:"type"`
+	Time    time.Time `json:"time"`
+	Payload any       `json:"payload"`
+}
+
+// BreakerOpen describes a circuit breaker that tripped
+type BreakerOpen struct {
+	Provider  string    `json:"provider"`
+	LastError string    `json:"last_error,omitempty"`
+	RecoverAt time.Time `json:"recover_at"` // When the breaker next lets a probe through
+}
+
+// CandidateProgress describes a screener candidate's analysis during a run. Completed and
+// Total count the candidates of the current pass, which for a retry of failed candidates
+// is only those being retried.
+type CandidateProgress struct {
+	RunID     uuid.UUID `json:"run_id"`
+	Symbol    string    `json:"symbol"`
+	Score     *float64  `json:"score,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Completed int       `json:"completed"` // Candidates scored or failed so far
+	Total     int       `json:"total"`
+}
+
+// Recommendation returns the event's recommendation, or nil for other event types
+func (e Event) Recommendation() *models.Recommendation {
+	rec, _ := e.Payload.(*models.Recommendation)
+	return rec
+}
+
+// AgentRun returns the event's agent run, or nil for other event types
+func (e Event) AgentRun() *models.AgentRun {
+	run, _ := e.Payload.(*models.AgentRun)
+	return run
+}
+
+// Trade returns the event's trade, or nil for other event types
+func (e Event) Trade() *models.Trade {
+	trade, _ := e.Payload.(*models.Trade)
+	return trade
+}
+
+// ScreenerRun returns the event's screener run, or nil for other event types
+func (e Event) ScreenerRun() *models.ScreenerRun {
+	run, _ := e.Payload.(*models.ScreenerRun)
+	return run
+}
+
+// CandidateProgress returns the event's screener candidate progress, and false for other
+// event types
+func (e Event) CandidateProgress() (CandidateProgress, bool) {
+	p, ok := e.Payload.(CandidateProgress)
+	return p, ok
+}
+
+// AuditEntry returns the event's audit entry, or nil for other event types
+func (e Event) AuditEntry() *models.AuditEntry {
+	entry, _ := e.Payload.(*models.AuditEntry)
+	return entry
+}
+
+// BreakerOpen returns the event's tripped breaker, and false for other event types
+func (e Event) BreakerOpen() (BreakerOpen, bool) {
+	b, ok := e.Payload.(BreakerOpen)
+	return b, ok
+}
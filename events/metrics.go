@@ -0,0 +1,31 @@
+package events
+
+import "trade-machine/observability"
+
+// MetricsEvents are the event types RecordMetrics counts
+var MetricsEvents = []Type{RecommendationCreated, TradeFilled, ScreenerCompleted, BreakerOpened}
+
+// RecordMetrics is the subscriber that exports domain events as Prometheus metrics
+func RecordMetrics(e Event) {
+	metrics := observability.GetMetrics()
+	switch e.Type {
+	case RecommendationCreated:
+		if rec := e.Recommendation(); rec != nil {
+			// Simple average, since the agent weights aren't known here
+			score := (rec.FundamentalScore + rec.SentimentScore + rec.TechnicalScore) / 3
+			metrics.RecordRecommendation(string(rec.Action), score, rec.Confidence)
+		}
+	case TradeFilled:
+		if trade := e.Trade(); trade != nil {
+			metrics.RecordTradeFilled(string(trade.Side))
+		}
+	case ScreenerCompleted:
+		if run := e.ScreenerRun(); run != nil {
+			metrics.RecordScreenerRun(string(run.Status))
+		}
+	case BreakerOpened:
+		if b, ok := e.BreakerOpen(); ok {
+			metrics.RecordCircuitBreakerTrip(b.Provider)
+		}
+	}
+}
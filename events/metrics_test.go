@@ -0,0 +1,29 @@
+package events
+
+import (
+	"testing"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecordMetrics(t *testing.T) {
+	metrics := observability.GetMetrics()
+	fills := testutil.ToFloat64(metrics.TradesFilled.WithLabelValues("sell"))
+	trips := testutil.ToFloat64(metrics.CircuitBreakerTrips.WithLabelValues("metrics-test"))
+
+	bus := NewBus()
+	bus.Subscribe("metrics", RecordMetrics, MetricsEvents...)
+	bus.Publish(TradeFilled, &models.Trade{Side: models.TradeSideSell})
+	bus.Publish(BreakerOpened, BreakerOpen{Provider: "metrics-test"})
+	bus.Close()
+
+	if got := testutil.ToFloat64(metrics.TradesFilled.WithLabelValues("sell")); got != fills+1 {
+		t.Errorf("sell fills = %v, want %v", got, fills+1)
+	}
+	if got := testutil.ToFloat64(metrics.CircuitBreakerTrips.WithLabelValues("metrics-test")); got != trips+1 {
+		t.Errorf("breaker trips = %v, want %v", got, trips+1)
+	}
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeNATS accepts one client, completes the handshake and sends what it publishes on
+// the returned channel as "subject payload"
+func fakeNATS(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	published := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch fields := strings.Fields(line); fields[0] {
+			case "PING":
+				conn.Write([]byte("PONG\r\n"))
+			case "PUB":
+				payload, _ := r.ReadString('\n')
+				published <- fields[1] + " " + strings.TrimSpace(payload)
+			}
+		}
+	}()
+	return "nats://" + ln.Addr().String(), published
+}
+
+func TestNATS_Publish(t *testing.T) {
+	url, published := fakeNATS(t)
+	n, err := DialNATS(url)
+	if err != nil {
+		t.Fatalf("DialNATS() error = %v", err)
+	}
+	defer n.Close()
+
+	if err := n.Publish("tm.trade.filled", []byte(`{"type":"trade.filled"}`)); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if got := <-published; got != `tm.trade.filled {"type":"trade.filled"}` {
+		t.Errorf("server received %q, want the subject and payload", got)
+	}
+
+	n.Close()
+	if err := n.Publish("tm.trade.filled", nil); err == nil {
+		t.Error("Publish() after Close succeeded, want an error")
+	}
+}
+
+func TestDialNATS_InvalidURL(t *testing.T) {
+	for _, url := range []string{"http://localhost:4222", "nats://", "://"} {
+		if _, err := DialNATS(url); err == nil {
+			t.Errorf("DialNATS(%q) succeeded, want an error", url)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrUnsupportedFormat is returned for an export format other than csv or xlsx
+var ErrUnsupportedFormat = errors.New("unsupported export format: use csv or xlsx")
+
+// Format is the file format a table is exported as
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// ParseFormat returns the format named s, defaulting to CSV when s is empty
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, s)
+}
+
+// ContentType returns the MIME type of files in the format
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Table is one sheet of an export: a header row of column names and a row of cells per
+// record. Cells are strings, numbers, decimals, bools, times or nil for an empty cell;
+// a row shorter than the header leaves its last columns empty.
+type Table struct {
+	Name    string
+	Columns []string
+	Rows    [][]any
+}
+
+// Write writes the table to w in the format, row by row
+func Write(w io.Writer, f Format, t Table) error {
+	if f == FormatXLSX {
+		return writeXLSX(w, t)
+	}
+	return writeCSV(w, t)
+}
+
+func writeCSV(w io.Writer, t Table) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Columns); err != nil {
+		return err
+	}
+	record := make([]string, len(t.Columns))
+	for _, row := range t.Rows {
+		for i := range record {
+			record[i] = ""
+			if i < len(row) {
+				record[i] = csvCell(row[i])
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formulaPrefixes start a cell spreadsheet apps open as a formula rather than as text
+const formulaPrefixes = "=+-@\t\r"
+
+// csvCell returns a cell's text for a CSV file. Text a spreadsheet would run as a formula,
+// such as a symbol or note starting with "=", is prefixed with a single quote so it opens
+// as text; numbers are written as they are.
+func csvCell(cell any) string {
+	if number, ok := numericCell(cell); ok {
+		return number
+	}
+	text := formatCell(cell)
+	if text != "" && strings.ContainsRune(formulaPrefixes, rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+// formatCell returns a cell's text. Times are written in RFC 3339, in UTC.
+func formatCell(cell any) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case decimal.Decimal:
+		return v.String()
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(cell)
+}
+
+// numericCell returns a cell's value as a number, reporting false for cells that aren't one
+func numericCell(cell any) (string, bool) {
+	switch v := cell.(type) {
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", false
+		}
+		return formatCell(v), true
+	case int, int64, decimal.Decimal:
+		return formatCell(v), true
+	}
+	return "", false
+}
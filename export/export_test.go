@@ -0,0 +1,194 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestParseFormat(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want Format
+	}{
+		{"", FormatCSV},
+		{"csv", FormatCSV},
+		{"xlsx", FormatXLSX},
+	} {
+		if got, err := ParseFormat(tt.in); err != nil || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := ParseFormat("pdf"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("ParseFormat(pdf) error = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func testTable() Table {
+	executed := time.Date(2026, 3, 2, 15, 30, 0, 0, time.UTC)
+	return Trades([]models.Trade{
+		{ID: uuid.New(), Symbol: "AAPL", Side: models.TradeSideBuy, Quantity: decimal.NewFromInt(10), Price: decimal.NewFromFloat(187.5),
+			Status: models.TradeStatusExecuted, ExecutedAt: &executed, CreatedAt: executed},
+		{ID: uuid.New(), Symbol: "R&D, \"Inc\"", Side: models.TradeSideSell, Status: models.TradeStatusPending, CreatedAt: executed},
+	})
+}
+
+func TestWrite_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatCSV, testTable()); err != nil {
+		t.Fatalf("Write error = %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	if len(records) != 3 || records[0][1] != "symbol" {
+		t.Fatalf("records = %v, want a header and two trades", records)
+	}
+	if records[1][5] != "187.5" || records[1][10] != models.BrokerAlpaca || records[1][12] != "2026-03-02T15:30:00Z" {
+		t.Errorf("first trade = %v, want its price, broker and execution time", records[1])
+	}
+	if records[2][1] != `R&D, "Inc"` || records[2][12] != "" {
+		t.Errorf("second trade = %v, want the quoted symbol and no execution time", records[2])
+	}
+}
+
+func TestWrite_CSVFormulas(t *testing.T) {
+	table := Table{
+		Columns: []string{"note", "amount"},
+		Rows: [][]any{
+			{"=HYPERLINK(\"http://example.com\")", -12.5},
+			{"+1", decimal.NewFromInt(-3)},
+			{"-2+3", nil},
+			{"@SUM(A1)", 1},
+			{"\tcmd", nil},
+			{"\rcmd", nil},
+			{"AAPL", nil},
+		},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatCSV, table); err != nil {
+		t.Fatalf("Write error = %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	want := []string{"'=HYPERLINK(\"http://example.com\")", "'+1", "'-2+3", "'@SUM(A1)", "'\tcmd", "'\rcmd", "AAPL"}
+	for i, text := range want {
+		if records[i+1][0] != text {
+			t.Errorf("row %d note = %q, want %q", i+1, records[i+1][0], text)
+		}
+	}
+	if records[1][1] != "-12.5" || records[2][1] != "-3" {
+		t.Errorf("amounts = %q, %q, want negative numbers left as they are", records[1][1], records[2][1])
+	}
+}
+
+func TestWrite_XLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatXLSX, testTable()); err != nil {
+		t.Fatalf("Write error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("output is not a zip archive: %v", err)
+	}
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		body, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(body)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("workbook is missing %s", name)
+		}
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet, `<c r="F2"><v>187.5</v></c>`) {
+		t.Errorf("sheet = %s, want the price as a number", sheet)
+	}
+	if !strings.Contains(sheet, `R&amp;D, &#34;Inc&#34;`) {
+		t.Errorf("sheet = %s, want the symbol escaped", sheet)
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `name="Trades"`) {
+		t.Errorf("workbook = %s, want the sheet named Trades", parts["xl/workbook.xml"])
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestNumericCell(t *testing.T) {
+	if _, ok := numericCell(math.NaN()); ok {
+		t.Error("NaN must not be written as a number")
+	}
+	if v, ok := numericCell(decimal.NewFromFloat(1.25)); !ok || v != "1.25" {
+		t.Errorf("numericCell(decimal 1.25) = %q, %v", v, ok)
+	}
+}
+
+func TestScreenerRuns(t *testing.T) {
+	recID := uuid.New()
+	score := 72.5
+	runs := []models.ScreenerRun{
+		{ID: uuid.New(), Status: models.ScreenerRunStatusCompleted, TopPicks: []uuid.UUID{recID}, Candidates: []models.ScreenerCandidate{
+			{Symbol: "KO", Score: &score, Analyzed: true, RecommendationID: &recID},
+			{Symbol: "PEP"},
+		}},
+		{ID: uuid.New(), Status: models.ScreenerRunStatusFailed, Error: "FMP unavailable"},
+	}
+
+	table := ScreenerRuns(runs)
+	if len(table.Rows) != 3 {
+		t.Fatalf("got %d rows, want one per candidate and one for the empty run", len(table.Rows))
+	}
+	if len(table.Rows[0]) != len(table.Columns) {
+		t.Errorf("candidate row has %d cells for %d columns", len(table.Rows[0]), len(table.Columns))
+	}
+	if table.Rows[0][19] != score || table.Rows[0][28] != true || table.Rows[1][28] != false {
+		t.Errorf("rows = %v, want KO's score and only KO as a top pick", table.Rows[:2])
+	}
+}
+
+func TestRecommendations_RowsMatchColumns(t *testing.T) {
+	short := 61.0
+	table := Recommendations([]models.Recommendation{{
+		ID:              uuid.New(),
+		Symbol:          "AAPL",
+		TimeframeScores: &models.TimeframeScores{Short: &short},
+		MissingAgents:   []models.MissingAgentInfo{{AgentType: models.AgentTypeNews, Reason: "rate limited"}},
+	}})
+
+	if len(table.Rows[0]) != len(table.Columns) {
+		t.Fatalf("row has %d cells for %d columns", len(table.Rows[0]), len(table.Columns))
+	}
+	if table.Rows[0][16] != short || table.Rows[0][20] != "news: rate limited" {
+		t.Errorf("row = %v, want the short timeframe score and missing agent", table.Rows[0])
+	}
+}
@@ -0,0 +1,115 @@
+package export
+
+import (
+	"slices"
+	"strings"
+
+	"trade-machine/models"
+)
+
+// Resources are the records that can be exported, by the name used in export URLs
+var Resources = []string{"trades", "positions", "recommendations", "screener-runs"}
+
+// value returns the value p points to, or nil for an empty cell
+func value[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// Trades returns a table of trades, one row per trade
+func Trades(trades []models.Trade) Table {
+	t := Table{
+		Name: "Trades",
+		Columns: []string{"id", "symbol", "side", "quantity", "filled_quantity", "price", "total_value",
+			"commission", "fees", "status", "broker", "broker_order_id", "executed_at", "created_at"},
+		Rows: make([][]any, 0, len(trades)),
+	}
+	for _, tr := range trades {
+		t.Rows = append(t.Rows, []any{tr.ID, tr.Symbol, string(tr.Side), tr.Quantity, tr.FilledQuantity, tr.Price, tr.TotalValue,
+			tr.Commission, tr.Fees, string(tr.Status), tr.BrokerName(), tr.AlpacaOrderID, value(tr.ExecutedAt), tr.CreatedAt})
+	}
+	return t
+}
+
+// Positions returns a table of positions, one row per position
+func Positions(positions []models.Position) Table {
+	t := Table{
+		Name: "Positions",
+		Columns: []string{"id", "symbol", "side", "quantity", "avg_entry_price", "current_price", "unrealized_pl",
+			"stop_loss_price", "take_profit_price", "stop_loss_order_id", "take_profit_order_id", "version", "created_at", "updated_at"},
+		Rows: make([][]any, 0, len(positions)),
+	}
+	for _, p := range positions {
+		t.Rows = append(t.Rows, []any{p.ID, p.Symbol, string(p.Side), p.Quantity, p.AvgEntryPrice, p.CurrentPrice, p.UnrealizedPL,
+			value(p.StopLossPrice), value(p.TakeProfitPrice), p.StopLossOrderID, p.TakeProfitOrderID, p.Version, p.CreatedAt, p.UpdatedAt})
+	}
+	return t
+}
+
+// Recommendations returns a table of recommendations with each agent's score, one row per
+// recommendation. Missing agents are listed as agent type and reason pairs.
+func Recommendations(recs []models.Recommendation) Table {
+	t := Table{
+		Name: "Recommendations",
+		Columns: []string{"id", "symbol", "action", "status", "quantity", "entry_price", "target_price", "stop_price",
+			"risk_reward", "confidence", "fundamental_score", "sentiment_score", "technical_score", "social_score",
+			"insider_score", "macro_score", "technical_short_score", "technical_medium_score", "technical_long_score",
+			"data_completeness", "missing_agents", "weight_policy", "trigger_reason", "previous_recommendation_id", "partial",
+			"override_quantity", "override_order_type", "override_limit_price", "approved_at", "rejected_at",
+			"executed_trade_id", "version", "created_at", "reasoning"},
+		Rows: make([][]any, 0, len(recs)),
+	}
+	for _, r := range recs {
+		var timeframes models.TimeframeScores
+		if r.TimeframeScores != nil {
+			timeframes = *r.TimeframeScores
+		}
+		var override models.RecommendationOverride
+		if r.Override != nil {
+			override = *r.Override
+		}
+		missing := make([]string, len(r.MissingAgents))
+		for i, m := range r.MissingAgents {
+			missing[i] = string(m.AgentType) + ": " + m.Reason
+		}
+		t.Rows = append(t.Rows, []any{r.ID, r.Symbol, string(r.Action), string(r.Status), r.Quantity, r.EntryPrice, r.TargetPrice, r.StopPrice,
+			r.RiskReward, r.Confidence, r.FundamentalScore, r.SentimentScore, r.TechnicalScore, r.SocialScore,
+			r.InsiderScore, r.MacroScore, value(timeframes.Short), value(timeframes.Medium), value(timeframes.Long),
+			r.DataCompleteness, strings.Join(missing, "; "), string(r.WeightPolicy), r.TriggerReason, value(r.PreviousID), r.Partial,
+			value(override.Quantity), override.OrderType, value(override.LimitPrice), value(r.ApprovedAt), value(r.RejectedAt),
+			value(r.ExecutedTradeID), r.Version, r.CreatedAt, r.Reasoning})
+	}
+	return t
+}
+
+// ScreenerRuns returns a table of screener runs, one row per candidate with its run's
+// details repeated. A run without candidates gets a single row with the candidate
+// columns left empty.
+func ScreenerRuns(runs []models.ScreenerRun) Table {
+	t := Table{
+		Name: "Screener Runs",
+		Columns: []string{"run_id", "run_at", "run_status", "run_error", "duration_ms", "replay_of",
+			"symbol", "company_name", "sector", "industry", "market_cap", "price", "pe_ratio", "pb_ratio", "eps",
+			"dividend_yield", "beta", "volume", "value_score", "score", "confidence", "data_completeness",
+			"margin_of_safety", "rank_score", "revenue_growth", "eps_growth", "price_change_6m", "analyzed",
+			"top_pick", "recommendation_id", "analysis_error"},
+	}
+	for _, run := range runs {
+		runCells := []any{run.ID, run.RunAt, string(run.Status), run.Error, run.DurationMs, value(run.ReplayOf)}
+		if len(run.Candidates) == 0 {
+			t.Rows = append(t.Rows, runCells)
+			continue
+		}
+		for _, c := range run.Candidates {
+			topPick := c.RecommendationID != nil && slices.Contains(run.TopPicks, *c.RecommendationID)
+			t.Rows = append(t.Rows, append(slices.Clone(runCells),
+				c.Symbol, c.CompanyName, c.Sector, c.Industry, c.MarketCap, c.Price, c.PERatio, c.PBRatio, c.EPS,
+				c.DividendYield, c.Beta, c.Volume, c.ValueScore, value(c.Score), value(c.Confidence), value(c.DataCompleteness),
+				value(c.MarginOfSafety), value(c.RankScore), value(c.RevenueGrowth), value(c.EPSGrowth), value(c.PriceChange6M), c.Analyzed,
+				topPick, value(c.RecommendationID), c.AnalysisError))
+		}
+	}
+	return t
+}
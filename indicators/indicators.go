@@ -0,0 +1,209 @@
+package indicators
+
+import (
+	"math"
+
+	"trade-machine/models"
+
+	marketdata "github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+// Standard periods used by Compute
+const (
+	RSIPeriod       = 14
+	MACDFast        = 12
+	MACDSlow        = 26
+	MACDSignal      = 9
+	BollingerPeriod = 20
+	BollingerWidth  = 2 // Standard deviations from the middle band
+	ATRPeriod       = 14
+)
+
+// MACDValue is the MACD line, its signal line and the histogram between them
+type MACDValue struct {
+	MACD      float64 `json:"macd"`
+	Signal    float64 `json:"signal"`
+	Histogram float64 `json:"histogram"`
+}
+
+// Bands are Bollinger Bands: a moving average and the bands a number of standard
+// deviations either side of it
+type Bands struct {
+	Upper  float64 `json:"upper"`
+	Middle float64 `json:"middle"`
+	Lower  float64 `json:"lower"`
+}
+
+// SMA returns the simple moving average of the last period values, and false when there
+// are fewer than period values
+func SMA(values []float64, period int) (float64, bool) {
+	if period <= 0 || len(values) < period {
+		return 0, false
+	}
+	sum := 0.0
+	for _, v := range values[len(values)-period:] {
+		sum += v
+	}
+	return sum / float64(period), true
+}
+
+// EMA returns the exponential moving average series, seeded with the simple average of
+// the first period values. The series starts at values[period-1], so it has
+// len(values)-period+1 entries, and is nil when there are fewer than period values.
+func EMA(values []float64, period int) []float64 {
+	seed, ok := SMA(values[:min(period, len(values))], period)
+	if !ok {
+		return nil
+	}
+
+	multiplier := 2.0 / float64(period+1)
+	ema := make([]float64, 0, len(values)-period+1)
+	ema = append(ema, seed)
+	for _, v := range values[period:] {
+		prev := ema[len(ema)-1]
+		ema = append(ema, (v-prev)*multiplier+prev)
+	}
+	return ema
+}
+
+// RSI returns the relative strength index (0-100) of the latest value with Wilder's
+// smoothing, and false when there are period or fewer values
+func RSI(values []float64, period int) (float64, bool) {
+	if period <= 0 || len(values) <= period {
+		return 0, false
+	}
+
+	var avgGain, avgLoss float64
+	for i := 1; i < len(values); i++ {
+		change := values[i] - values[i-1]
+		gain, loss := max(change, 0), max(-change, 0)
+		if i <= period {
+			avgGain += gain / float64(period)
+			avgLoss += loss / float64(period)
+			continue
+		}
+		avgGain = (avgGain*float64(period-1) + gain) / float64(period)
+		avgLoss = (avgLoss*float64(period-1) + loss) / float64(period)
+	}
+
+	if avgLoss == 0 {
+		if avgGain == 0 {
+			return 50, true
+		}
+		return 100, true
+	}
+	return 100 - 100/(1+avgGain/avgLoss), true
+}
+
+// MACD returns the latest MACD line (fast EMA less slow EMA), its signal EMA and the
+// histogram, and false until there are enough values for the signal line
+func MACD(values []float64, fast, slow, signal int) (MACDValue, bool) {
+	if fast > slow {
+		return MACDValue{}, false
+	}
+	fastEMA, slowEMA := EMA(values, fast), EMA(values, slow)
+	if slowEMA == nil {
+		return MACDValue{}, false
+	}
+
+	// Both series end at the latest value; the slow one starts later
+	offset := len(fastEMA) - len(slowEMA)
+	line := make([]float64, len(slowEMA))
+	for i := range slowEMA {
+		line[i] = fastEMA[i+offset] - slowEMA[i]
+	}
+	signalEMA := EMA(line, signal)
+	if signalEMA == nil {
+		return MACDValue{}, false
+	}
+
+	m, s := line[len(line)-1], signalEMA[len(signalEMA)-1]
+	return MACDValue{MACD: m, Signal: s, Histogram: m - s}, true
+}
+
+// Bollinger returns the bands width population standard deviations either side of the
+// period SMA, and false when there are fewer than period values
+func Bollinger(values []float64, period int, width float64) (Bands, bool) {
+	middle, ok := SMA(values, period)
+	if !ok {
+		return Bands{}, false
+	}
+
+	variance := 0.0
+	for _, v := range values[len(values)-period:] {
+		variance += (v - middle) * (v - middle)
+	}
+	deviation := width * math.Sqrt(variance/float64(period))
+	return Bands{Upper: middle + deviation, Middle: middle, Lower: middle - deviation}, true
+}
+
+// ATR returns the average true range of the latest bar with Wilder's smoothing, and
+// false when there are period or fewer bars. A bar's true range also spans any gap from
+// the previous close.
+func ATR(bars []marketdata.Bar, period int) (float64, bool) {
+	if period <= 0 || len(bars) <= period {
+		return 0, false
+	}
+
+	atr := 0.0
+	for i := 1; i < len(bars); i++ {
+		prevClose := bars[i-1].Close
+		tr := max(bars[i].High-bars[i].Low, math.Abs(bars[i].High-prevClose), math.Abs(bars[i].Low-prevClose))
+		if i <= period {
+			atr += tr / float64(period)
+			continue
+		}
+		atr = (atr*float64(period-1) + tr) / float64(period)
+	}
+	return atr, true
+}
+
+// Closes returns the closing price of each bar
+func Closes(bars []marketdata.Bar) []float64 {
+	closes := make([]float64, len(bars))
+	for i, bar := range bars {
+		closes[i] = bar.Close
+	}
+	return closes
+}
+
+// Compute returns the standard indicators for daily bars, oldest first. Indicators
+// without enough history are left zero.
+func Compute(symbol string, bars []marketdata.Bar) models.TechnicalIndicators {
+	result := models.TechnicalIndicators{Symbol: symbol}
+	if len(bars) == 0 {
+		return result
+	}
+	closes := Closes(bars)
+	result.UpdatedAt = bars[len(bars)-1].Timestamp
+
+	if rsi, ok := RSI(closes, RSIPeriod); ok {
+		result.RSI = rsi
+	}
+	if macd, ok := MACD(closes, MACDFast, MACDSlow, MACDSignal); ok {
+		result.MACD, result.MACDSignal, result.MACDHistogram = macd.MACD, macd.Signal, macd.Histogram
+	}
+	for period, field := range map[int]*decimal.Decimal{20: &result.SMA20, 50: &result.SMA50, 200: &result.SMA200} {
+		if sma, ok := SMA(closes, period); ok {
+			*field = price(sma)
+		}
+	}
+	for period, field := range map[int]*decimal.Decimal{12: &result.EMA12, 26: &result.EMA26} {
+		if ema := EMA(closes, period); ema != nil {
+			*field = price(ema[len(ema)-1])
+		}
+	}
+	if bands, ok := Bollinger(closes, BollingerPeriod, BollingerWidth); ok {
+		result.BollingerUpper, result.BollingerLower = price(bands.Upper), price(bands.Lower)
+	}
+	if atr, ok := ATR(bars, ATRPeriod); ok {
+		result.ATR = price(atr)
+	}
+	return result
+}
+
+// price converts an indicator in dollars, rounded to four places for display
+func price(v float64) decimal.Decimal {
+	return decimal.NewFromFloat(v).Round(4)
+}
@@ -0,0 +1,147 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	marketdata "github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+)
+
+func rising(n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = 100 + float64(i)*0.5
+	}
+	return values
+}
+
+func TestSMA(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		values []float64
+		period int
+		want   float64
+		wantOK bool
+	}{
+		{"whole series", []float64{10, 20, 30, 40, 50}, 5, 30, true},
+		{"latest values", []float64{10, 20, 30, 40, 50}, 3, 40, true},
+		{"single value", []float64{100}, 1, 100, true},
+		{"too short", []float64{10, 20}, 5, 0, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := SMA(tt.values, tt.period)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("SMA() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestEMA(t *testing.T) {
+	ema := EMA([]float64{10, 20, 30, 40, 50, 60}, 5)
+	if len(ema) != 2 {
+		t.Fatalf("EMA() has %d values, want one per value from the fifth", len(ema))
+	}
+	// Seeded with SMA(10..50) = 30, then 30 + (60-30)/3
+	if ema[0] != 30 || ema[1] != 40 {
+		t.Errorf("EMA() = %v, want [30 40]", ema)
+	}
+	if EMA([]float64{1, 2}, 5) != nil {
+		t.Error("expected no EMA for a series shorter than the period")
+	}
+}
+
+func TestRSI(t *testing.T) {
+	falling := make([]float64, 16)
+	for i := range falling {
+		falling[i] = 55 - float64(i)
+	}
+	zigzag := []float64{44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08, 45.89, 46.03, 45.61, 46.28, 46.28}
+
+	for _, tt := range []struct {
+		name     string
+		values   []float64
+		min, max float64
+	}{
+		{"uptrend is overbought", rising(16), 70, 100},
+		{"downtrend is oversold", falling, 0, 30},
+		{"flat is neutral", []float64{50, 50, 50, 50, 50, 50, 50, 50, 50, 50, 50, 50, 50, 50, 50}, 50, 50},
+		// Wilder's worked example: 14 changes give an RSI of about 70.5
+		{"mixed", zigzag, 70.4, 70.6},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RSI(tt.values, 14)
+			if !ok || got < tt.min || got > tt.max {
+				t.Errorf("RSI() = %v, %v, want between %v and %v", got, ok, tt.min, tt.max)
+			}
+		})
+	}
+	if _, ok := RSI([]float64{100, 101, 102}, 14); ok {
+		t.Error("expected no RSI without enough changes")
+	}
+}
+
+func TestMACD(t *testing.T) {
+	macd, ok := MACD(rising(60), MACDFast, MACDSlow, MACDSignal)
+	if !ok {
+		t.Fatal("expected a MACD with 60 values")
+	}
+	// A steady rise keeps the fast EMA above the slow one by a constant gap
+	if macd.MACD <= 0 || math.Abs(macd.Histogram) > 1e-9 {
+		t.Errorf("MACD() = %+v, want a positive line the signal has caught up with", macd)
+	}
+	if _, ok := MACD(rising(30), MACDFast, MACDSlow, MACDSignal); ok {
+		t.Error("expected no MACD before the signal line has enough values")
+	}
+}
+
+func TestBollinger(t *testing.T) {
+	bands, ok := Bollinger([]float64{2, 4, 4, 4, 5, 5, 7, 9}, 8, 2)
+	// Mean 5, population standard deviation 2
+	if !ok || bands.Middle != 5 || bands.Upper != 9 || bands.Lower != 1 {
+		t.Errorf("Bollinger() = %+v, %v, want 1/5/9", bands, ok)
+	}
+}
+
+func TestATR(t *testing.T) {
+	bars := []marketdata.Bar{
+		{High: 11, Low: 9, Close: 10},
+		{High: 12, Low: 10, Close: 11},
+		// Gaps up: the true range runs from the previous close
+		{High: 16, Low: 15, Close: 15.5},
+	}
+	atr, ok := ATR(bars, 2)
+	if !ok || atr != 3.5 {
+		t.Errorf("ATR() = %v, %v, want the average of ranges 2 and 5", atr, ok)
+	}
+	if _, ok := ATR(bars, 3); ok {
+		t.Error("expected no ATR without a range per period")
+	}
+}
+
+func TestCompute(t *testing.T) {
+	closes := rising(100)
+	bars := make([]marketdata.Bar, len(closes))
+	last := time.Date(2026, 3, 2, 5, 0, 0, 0, time.UTC)
+	for i, c := range closes {
+		bars[i] = marketdata.Bar{High: c + 1, Low: c - 1, Close: c, Timestamp: last.AddDate(0, 0, i-len(closes)+1)}
+	}
+
+	got := Compute("AAPL", bars)
+	if got.Symbol != "AAPL" || !got.UpdatedAt.Equal(last) {
+		t.Errorf("Compute() = %+v, want the symbol and the last bar's time", got)
+	}
+	if got.RSI != 100 || got.MACD <= 0 {
+		t.Errorf("RSI = %v, MACD = %v, want both bullish", got.RSI, got.MACD)
+	}
+	if !got.SMA20.GreaterThan(got.SMA50) || !got.EMA12.GreaterThan(got.EMA26) {
+		t.Errorf("averages = %+v, want the faster ones above in an uptrend", got)
+	}
+	if !got.BollingerUpper.GreaterThan(got.SMA20) || !got.BollingerLower.LessThan(got.SMA20) {
+		t.Errorf("bands = %v/%v, want either side of SMA 20 %v", got.BollingerLower, got.BollingerUpper, got.SMA20)
+	}
+	if got.ATR.String() != "2" || !got.SMA200.IsZero() {
+		t.Errorf("ATR = %v, SMA 200 = %v, want 2 and none with 100 bars", got.ATR, got.SMA200)
+	}
+}
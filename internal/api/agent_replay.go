@@ -0,0 +1,43 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"trade-machine/internal/app"
+	"trade-machine/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// HandleReplayAgentRun sends a past agent run's stored prompt to its agent again with the
+// current system prompt and model, returning the new score, confidence and reasoning next
+// to the old ones. ?dry_run=true saves nothing; otherwise the replay is recorded as a new
+// agent run. No recommendation is made either way.
+func (h *Handler) HandleReplayAgentRun(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		h.jsonError(w, "Invalid agent run ID", http.StatusBadRequest)
+		return
+	}
+
+	replay, err := h.app.ReplayAgentRun(r.Context(), id, r.URL.Query().Get("dry_run") == "true")
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, models.ErrAgentRunNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, models.ErrAgentRunNotReplayable):
+			status = http.StatusUnprocessableEntity
+		case errors.Is(err, app.ErrAnalysisQueueFull):
+			status = http.StatusServiceUnavailable
+		case errors.Is(err, models.ErrLLMBudgetExceeded):
+			status = http.StatusTooManyRequests
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+
+	h.jsonResponse(w, replay)
+}
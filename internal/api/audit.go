@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+
+	"trade-machine/models"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// auditDefaultLimit is how many audit entries are returned without ?limit=N
+const auditDefaultLimit = 50
+
+// audit records a state-changing action taken by the request in the audit log. The entry
+// is published on the event bus and written by the audit log's subscriber, so a failed
+// write is logged there rather than failing the request.
+func (h *Handler) audit(r *http.Request, action models.AuditAction, target string, details map[string]any) {
+	entry := models.NewAuditEntry(action, r.RemoteAddr, target, details)
+	entry.RequestID = middleware.GetReqID(r.Context())
+	if token := apiTokenFromContext(r.Context()); token != nil {
+		entry.Actor = "token:" + token.Name
+	}
+	h.app.RecordAudit(entry)
+}
+
+// HandleGetAuditLog returns the most recent approvals, rejections, executions, settings
+// changes and screener runs, newest first, with ?limit=N entries (default 50)
+func (h *Handler) HandleGetAuditLog(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.app.GetAuditLog(h.ParseLimitParam(r, auditDefaultLimit))
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.jsonResponse(w, entries)
+}
+
+// screenerRunAuditDetails records the preset and filter overrides a screener run was
+// started with, if any
+func screenerRunAuditDetails(overrides *models.ScreenerFilters) map[string]any {
+	if overrides == nil {
+		return nil
+	}
+	return map[string]any{"filters": overrides}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_GetAuditLog(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/audit?limit=10", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500 without a database, got %d", w.Code)
+	}
+}
+
+func TestHandler_AuditFailureKeepsAction(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	// The action takes effect even though the audit entry can't be written
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/log-level", strings.NewReader(`{"module":"default","level":"info"}`))
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", w.Code)
+	}
+}
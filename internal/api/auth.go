@@ -0,0 +1,258 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"trade-machine/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+type apiTokenKey struct{}
+
+// apiTokenFromContext returns the token a request authenticated with, or nil when
+// authentication is off
+func apiTokenFromContext(ctx context.Context) *models.APIToken {
+	token, _ := ctx.Value(apiTokenKey{}).(*models.APIToken)
+	return token
+}
+
+// scopeRules map requests to the scope they need, checked in order. Requests no rule
+// matches need read for GET and HEAD and write otherwise. Paths are relative to /api.
+var scopeRules = []struct {
+	method  string // Empty matches every method
+	pattern *regexp.Regexp
+	scope   models.APIScope
+}{
+	{"", regexp.MustCompile(`^/(auth|settings|admin|onboarding|e2e)(/|$)`), models.APIScopeAdmin},
+	{http.MethodPut, regexp.MustCompile(`^/(broker|rebalance/targets|screener/schedule)$`), models.APIScopeAdmin},
+	{http.MethodPost, regexp.MustCompile(`^/compliance/acknowledge$`), models.APIScopeAdmin},
+	{http.MethodPost, regexp.MustCompile(`^/(recommendations/[^/]+/execute|rebalance/execute)$`), models.APIScopeTrade},
+	{http.MethodPost, regexp.MustCompile(`^/recommendations/[^/]+/(approve|reject|split)$`), models.APIScopeApprove},
+	{http.MethodPatch, regexp.MustCompile(`^/recommendations/[^/]+$`), models.APIScopeApprove},
+	{http.MethodDelete, regexp.MustCompile(`^/recommendations/[^/]+/tranches$`), models.APIScopeApprove},
+}
+
+// approvePattern matches approvals, which also place the order when EXECUTION_MODE is auto
+var approvePattern = regexp.MustCompile(`^/recommendations/[^/]+/approve$`)
+
+// splitPattern matches split approvals, whose tranches are placed in the background
+// without another check
+var splitPattern = regexp.MustCompile(`^/recommendations/[^/]+/split$`)
+
+// requiredScopes returns every scope a request to path, relative to /api, needs. A split
+// approval places its orders, as does an approval with autoExecute, so they need trade as
+// well as approve.
+func requiredScopes(method, path string, autoExecute bool) []models.APIScope {
+	scope := requiredScope(method, path)
+	if method == http.MethodPost && (splitPattern.MatchString(path) || autoExecute && approvePattern.MatchString(path)) {
+		return []models.APIScope{scope, models.APIScopeTrade}
+	}
+	return []models.APIScope{scope}
+}
+
+// requiredScope returns the scope a request to path, relative to /api, needs
+func requiredScope(method, path string) models.APIScope {
+	for _, rule := range scopeRules {
+		if (rule.method == "" || rule.method == method) && rule.pattern.MatchString(path) {
+			return rule.scope
+		}
+	}
+	if method == http.MethodGet || method == http.MethodHead {
+		return models.APIScopeRead
+	}
+	return models.APIScopeWrite
+}
+
+// sessionCookie holds the token the web UI signed in with, since HTMX requests can't add
+// an Authorization header
+const sessionCookie = "tm_session"
+
+// sessionPath is where the web UI exchanges a token for the session cookie. It is open so
+// a signed-out browser can reach it.
+const sessionPath = "/api/auth/session"
+
+// bearerToken returns the token in the Authorization header. WebSocket upgrades, which
+// browsers can't add headers to, may pass it as ?access_token= instead, and the web UI
+// sends it in the session cookie.
+func bearerToken(r *http.Request) string {
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	if websocket.IsWebSocketUpgrade(r) {
+		if token := r.URL.Query().Get("access_token"); token != "" {
+			return token
+		}
+	}
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// AuthMiddleware requires an API token with the scope each request needs when
+// API_AUTH_ENABLED is set. The health check, the web UI's sign-in and CORS preflight
+// requests stay open.
+func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
+	if !h.cfg.HTTP.AuthEnabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions || r.URL.Path == "/api/health" || r.URL.Path == sessionPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		secret := bearerToken(r)
+		if secret == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="trade-machine"`)
+			h.jsonError(w, "API token required", http.StatusUnauthorized)
+			return
+		}
+		token, err := h.app.AuthenticateAPIToken(secret)
+		if err != nil {
+			logger.Warn("failed to authenticate API token", "error", err)
+			h.jsonError(w, "Failed to authenticate API token", http.StatusInternalServerError)
+			return
+		}
+		if token == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="trade-machine", error="invalid_token"`)
+			h.jsonError(w, "Invalid API token", http.StatusUnauthorized)
+			return
+		}
+
+		for _, scope := range requiredScopes(r.Method, strings.TrimPrefix(r.URL.Path, "/api"), h.cfg.Execution.Auto()) {
+			if !token.HasScope(scope) {
+				h.jsonError(w, fmt.Sprintf("API token lacks the %s scope", scope), http.StatusForbidden)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiTokenKey{}, token)))
+	})
+}
+
+// SessionRequest signs the web UI in with an API token
+type SessionRequest struct {
+	Token string `json:"token"`
+}
+
+// HandleCreateSession checks an API token and stores it in an HttpOnly session cookie, so
+// the web UI's requests carry it. SameSite=Strict keeps other sites from sending it.
+func (h *Handler) HandleCreateSession(w http.ResponseWriter, r *http.Request) {
+	var req SessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		h.jsonError(w, "Invalid JSON request, expected {\"token\": ...}", http.StatusBadRequest)
+		return
+	}
+	token, err := h.app.AuthenticateAPIToken(req.Token)
+	if err != nil {
+		logger.Warn("failed to authenticate API token", "error", err)
+		h.jsonError(w, "Failed to authenticate API token", http.StatusInternalServerError)
+		return
+	}
+	if token == nil {
+		h.jsonError(w, "Invalid API token", http.StatusUnauthorized)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    req.Token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	h.jsonResponse(w, token)
+}
+
+// HandleDeleteSession signs the web UI out by clearing the session cookie
+func (h *Handler) HandleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	h.jsonResponse(w, map[string]string{"status": "signed_out"})
+}
+
+// APITokenRequest creates an API token
+type APITokenRequest struct {
+	Name   string            `json:"name"`
+	Scopes []models.APIScope `json:"scopes"`
+}
+
+// APITokenResponse is a newly created API token with its secret, which is shown only once
+type APITokenResponse struct {
+	Token string           `json:"token"`
+	Info  *models.APIToken `json:"info"`
+}
+
+// HandleCreateAPIToken creates an API token from a body such as
+// {"name": "ci", "scopes": ["read", "approve"]}
+func (h *Handler) HandleCreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	var req APITokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	token, secret, err := h.app.CreateAPIToken(req.Name, req.Scopes)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, models.ErrInvalidAPIToken) {
+			status = http.StatusBadRequest
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+	h.audit(r, models.AuditSettingsChanged, "api_tokens/"+token.ID.String(), map[string]any{"name": token.Name, "scopes": token.Scopes})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(APITokenResponse{Token: secret, Info: token})
+}
+
+// HandleGetAPITokens lists the API tokens without their secrets
+func (h *Handler) HandleGetAPITokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.app.GetAPITokens()
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.jsonResponse(w, tokens)
+}
+
+// HandleRevokeAPIToken deletes an API token, so requests using it are refused
+func (h *Handler) HandleRevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		h.jsonError(w, "Invalid API token ID", http.StatusBadRequest)
+		return
+	}
+	deleted, err := h.app.RevokeAPIToken(id)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		h.jsonError(w, "API token not found", http.StatusNotFound)
+		return
+	}
+	h.audit(r, models.AuditSettingsChanged, "api_tokens/"+id, map[string]any{"revoked": true})
+
+	h.jsonResponse(w, map[string]string{"status": "revoked", "id": id})
+}
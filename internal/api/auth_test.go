@@ -0,0 +1,140 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"trade-machine/internal/app"
+	"trade-machine/models"
+)
+
+func TestRequiredScope(t *testing.T) {
+	for _, tt := range []struct {
+		method string
+		path   string
+		want   models.APIScope
+	}{
+		{http.MethodGet, "/positions", models.APIScopeRead},
+		{http.MethodHead, "/recommendations", models.APIScopeRead},
+		{http.MethodPost, "/analyze/AAPL", models.APIScopeWrite},
+		{http.MethodPost, "/recommendations/123/approve", models.APIScopeApprove},
+		{http.MethodPost, "/recommendations/123/split", models.APIScopeApprove},
+		{http.MethodPatch, "/recommendations/123", models.APIScopeApprove},
+		{http.MethodDelete, "/recommendations/123/tranches", models.APIScopeApprove},
+		{http.MethodPost, "/recommendations/123/execute", models.APIScopeTrade},
+		{http.MethodPost, "/rebalance/execute", models.APIScopeTrade},
+		{http.MethodPost, "/rebalance/plan", models.APIScopeWrite},
+		{http.MethodGet, "/rebalance/targets", models.APIScopeRead},
+		{http.MethodPut, "/rebalance/targets", models.APIScopeAdmin},
+		{http.MethodGet, "/settings", models.APIScopeAdmin},
+		{http.MethodGet, "/auth/tokens", models.APIScopeAdmin},
+		{http.MethodPost, "/compliance/acknowledge", models.APIScopeAdmin},
+		{http.MethodGet, "/settingsx", models.APIScopeRead},
+	} {
+		if got := requiredScope(tt.method, tt.path); got != tt.want {
+			t.Errorf("requiredScope(%s %s) = %s, want %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	adminToken := strings.Repeat("a", 32)
+	cfg := testConfig()
+	cfg.HTTP.AuthEnabled = true
+	cfg.HTTP.AdminToken = adminToken
+	router := NewRouter(NewHandler(app.New(cfg, nil, nil, nil), cfg), cfg)
+
+	for _, tt := range []struct {
+		name       string
+		path       string
+		auth       string
+		wantStatus int
+	}{
+		{"health check stays open", "/api/health", "", http.StatusOK},
+		{"missing token", "/api/positions", "", http.StatusUnauthorized},
+		{"unknown token", "/api/positions", "Bearer tm_unknown", http.StatusUnauthorized},
+		{"wrong scheme", "/api/positions", "Basic " + adminToken, http.StatusUnauthorized},
+		{"metrics need a token", "/metrics", "", http.StatusUnauthorized},
+		{"admin token", "/metrics", "Bearer " + adminToken, http.StatusOK},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected a WWW-Authenticate challenge")
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_Disabled(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200 without auth enabled, got %d", w.Code)
+	}
+}
+
+func TestRequiredScopes_AutoExecute(t *testing.T) {
+	if got := requiredScopes(http.MethodPost, "/recommendations/123/approve", false); len(got) != 1 || got[0] != models.APIScopeApprove {
+		t.Errorf("manual approval scopes = %v, want approve", got)
+	}
+	got := requiredScopes(http.MethodPost, "/recommendations/123/approve", true)
+	if len(got) != 2 || got[0] != models.APIScopeApprove || got[1] != models.APIScopeTrade {
+		t.Errorf("auto approval scopes = %v, want approve and trade", got)
+	}
+	if got := requiredScopes(http.MethodPost, "/recommendations/123/reject", true); len(got) != 1 || got[0] != models.APIScopeApprove {
+		t.Errorf("auto rejection scopes = %v, want approve", got)
+	}
+	got = requiredScopes(http.MethodPost, "/recommendations/123/split", false)
+	if len(got) != 2 || got[0] != models.APIScopeApprove || got[1] != models.APIScopeTrade {
+		t.Errorf("split scopes = %v, want approve and trade", got)
+	}
+}
+
+func TestAuthMiddleware_Session(t *testing.T) {
+	adminToken := strings.Repeat("a", 32)
+	cfg := testConfig()
+	cfg.HTTP.AuthEnabled = true
+	cfg.HTTP.AdminToken = adminToken
+	router := NewRouter(NewHandler(app.New(cfg, nil, nil, nil), cfg), cfg)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/session", strings.NewReader(`{"token": "tm_unknown"}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("unknown token: expected status 401, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/auth/session", strings.NewReader(`{"token": "`+adminToken+`"}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	cookies := w.Result().Cookies()
+	if w.Code != http.StatusOK || len(cookies) != 1 || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteStrictMode {
+		t.Fatalf("sign-in: status %d with cookies %+v, want an HttpOnly SameSite=Strict session", w.Code, cookies)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("session cookie: expected status 200, got %d", w.Code)
+	}
+}
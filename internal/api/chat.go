@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"trade-machine/internal/app"
+	"trade-machine/models"
+)
+
+// HandleChat answers a free-form question about a symbol with the agents' latest analysis
+// as context, streaming the answer as Server-Sent Events: a chat.delta event with {"text"}
+// for each piece, then chat.done with the whole {"answer"}. The body is {"symbol",
+// "messages"}, the conversation so far ending with the user's question. Failures before
+// the answer starts get a JSON error and status; one partway ends the stream with a
+// chat.error event. Clients should send Accept: text/event-stream so the request isn't
+// cut off by the API timeout.
+func (h *Handler) HandleChat(w http.ResponseWriter, r *http.Request) {
+	var req models.ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	flusher := http.NewResponseController(w)
+	started := false
+	answer, err := h.app.ChatAboutSymbol(r.Context(), req, func(delta string) error {
+		if !started {
+			started = true
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("X-Accel-Buffering", "no")
+			w.WriteHeader(http.StatusOK)
+		}
+		if err := writeChatEvent(w, "chat.delta", map[string]string{"text": delta}); err != nil {
+			return err
+		}
+		return flusher.Flush()
+	})
+	if err != nil {
+		if started {
+			writeChatEvent(w, "chat.error", map[string]string{"error": err.Error()})
+			flusher.Flush()
+			return
+		}
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, models.ErrInvalidChat):
+			status = http.StatusBadRequest
+		case errors.Is(err, app.ErrChatUnavailable):
+			status = http.StatusServiceUnavailable
+		case errors.Is(err, models.ErrLLMBudgetExceeded):
+			status = http.StatusTooManyRequests
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+
+	writeChatEvent(w, "chat.done", map[string]string{"answer": answer})
+	flusher.Flush()
+}
+
+// writeChatEvent writes a Server-Sent Event with payload's JSON as its data
+func writeChatEvent(w http.ResponseWriter, event string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"trade-machine/services"
+)
+
+// answeringLLM streams a fixed answer in two pieces
+type answeringLLM struct {
+	services.LLMService
+}
+
+func (l *answeringLLM) ChatStream(ctx context.Context, systemPrompt string, messages []services.ChatMessage, onDelta func(delta string) error) (string, error) {
+	for _, delta := range []string{"Services ", "growth."} {
+		if err := onDelta(delta); err != nil {
+			return "", err
+		}
+	}
+	return "Services growth.", nil
+}
+
+func TestHandler_Chat(t *testing.T) {
+	question := `{"symbol":"AAPL","messages":[{"role":"user","content":"Why is this a buy?"}]}`
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+		req := httptest.NewRequest(http.MethodPost, "/api/chat", strings.NewReader("{"))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("no LLM configured", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+		req := httptest.NewRequest(http.MethodPost, "/api/chat", strings.NewReader(question))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+		}
+	})
+
+	t.Run("missing question", func(t *testing.T) {
+		a := testApp(nil)
+		a.SetChatLLM(&answeringLLM{})
+		router := testRouter(a)
+		req := httptest.NewRequest(http.MethodPost, "/api/chat", strings.NewReader(`{"symbol":"AAPL","messages":[]}`))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("streams the answer", func(t *testing.T) {
+		a := testApp(nil)
+		a.Startup(context.Background())
+		a.SetChatLLM(&answeringLLM{})
+		router := testRouter(a)
+		req := httptest.NewRequest(http.MethodPost, "/api/chat", strings.NewReader(question))
+		req.Header.Set("Accept", "text/event-stream")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("Content-Type = %q, want text/event-stream", ct)
+		}
+		body := w.Body.String()
+		for _, want := range []string{
+			"event: chat.delta\ndata: {\"text\":\"Services \"}\n\n",
+			"event: chat.delta\ndata: {\"text\":\"growth.\"}\n\n",
+			"event: chat.done\ndata: {\"answer\":\"Services growth.\"}\n\n",
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("body is missing %q:\n%s", want, body)
+			}
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"trade-machine/export"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// exportDefaultLimit is how many trades or recommendations an export includes without ?limit=N
+	exportDefaultLimit = 1000
+	// exportRunDefaultLimit is how many screener runs an export includes without ?limit=N
+	exportRunDefaultLimit = 50
+)
+
+// exportTable loads the records of an export resource as a table
+func (h *Handler) exportTable(resource string, r *http.Request) (export.Table, error) {
+	switch resource {
+	case "trades":
+		trades, err := h.app.GetTrades(h.ParseLimitParam(r, exportDefaultLimit))
+		return export.Trades(trades), err
+	case "positions":
+		positions, err := h.app.GetPositions()
+		return export.Positions(positions), err
+	case "recommendations":
+		recs, err := h.app.GetRecommendations(h.ParseLimitParam(r, exportDefaultLimit))
+		return export.Recommendations(recs), err
+	case "screener-runs":
+		runs, err := h.app.GetScreenerRunHistory(h.ParseLimitParam(r, exportRunDefaultLimit))
+		return export.ScreenerRuns(runs), err
+	}
+	return export.Table{}, fmt.Errorf("unknown export resource %q", resource)
+}
+
+// HandleExport downloads trades, positions, recommendations or screener runs as a CSV or
+// Excel file with every field, including each agent's score on recommendations and one
+// row per candidate for screener runs. ?format=csv (the default) or xlsx picks the file
+// type and ?limit=N how many of the most recent records are included.
+func (h *Handler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	resource := chi.URLParam(r, "resource")
+	if !slices.Contains(export.Resources, resource) {
+		h.jsonError(w, "Unknown export resource, use one of: "+strings.Join(export.Resources, ", "), http.StatusNotFound)
+		return
+	}
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if resource == "screener-runs" && h.app.Screener() == nil {
+		h.jsonError(w, "Screener not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	table, err := h.exportTable(resource, r)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("trade-machine-%s-%s.%s", resource, time.Now().UTC().Format("20060102"), format)
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.WriteHeader(http.StatusOK)
+	if err := export.Write(w, format, table); err != nil {
+		logger.Warn("export interrupted", "resource", resource, "format", format, "error", err)
+	}
+}
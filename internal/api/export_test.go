@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_Export(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	for _, tt := range []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/api/export/accounts", http.StatusNotFound},
+		{"/api/export/trades?format=pdf", http.StatusBadRequest},
+		{"/api/export/trades", http.StatusInternalServerError},
+		{"/api/export/screener-runs?format=xlsx", http.StatusServiceUnavailable},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.wantStatus, w.Code)
+		}
+	}
+}
+
+func TestHandler_ExportScreenerRuns(t *testing.T) {
+	a := testApp(nil)
+	a.SetScreener(newStubScreener())
+	router := testRouter(a)
+
+	t.Run("csv", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/export/screener-runs", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		if cd := w.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, `attachment; filename="trade-machine-screener-runs-`) || !strings.HasSuffix(cd, `.csv"`) {
+			t.Errorf("Content-Disposition = %q, want a CSV attachment", cd)
+		}
+		records, err := csv.NewReader(w.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("body is not valid CSV: %v", err)
+		}
+		if len(records) != 3 || records[1][6] != "AAPL" || records[2][6] != "MSFT" {
+			t.Errorf("records = %v, want a header and a row per candidate", records)
+		}
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/export/screener-runs?format=xlsx", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" {
+			t.Errorf("Content-Type = %q, want the xlsx type", ct)
+		}
+		if !strings.HasPrefix(w.Body.String(), "PK") {
+			t.Error("expected a zip archive")
+		}
+	})
+}
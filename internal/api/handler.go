@@ -3,12 +3,15 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"trade-machine/config"
 	"trade-machine/internal/app"
@@ -21,17 +24,28 @@ import (
 	"trade-machine/templates/partials"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/shopspring/decimal"
 )
 
+// logger is the api module's logger
+var logger = observability.Module(observability.ModuleAPI)
+
 // Handler handles HTTP API requests
 type Handler struct {
-	app *app.App
-	cfg *config.Config
+	app       *app.App
+	cfg       *config.Config
+	stream    *streamHub
+	runEvents *runEventHub
 }
 
 // NewHandler creates a new Handler
 func NewHandler(application *app.App, cfg *config.Config) *Handler {
-	return &Handler{app: application, cfg: cfg}
+	h := &Handler{app: application, cfg: cfg, stream: newStreamHub(), runEvents: newRunEventHub()}
+	if application != nil {
+		application.SubscribeEvents("websocket", h.stream.publish, streamEventTypes...)
+		application.SubscribeEvents("screener-progress", h.runEvents.publish, screenerRunEventTypes...)
+	}
+	return h
 }
 
 // HandleIndex serves the main application page using templ
@@ -61,13 +75,24 @@ func (h *Handler) HandleHealth(w http.ResponseWriter, r *http.Request) {
 		status["services"].(map[string]string)["database"] = "not_configured"
 	}
 
+	// Writes waiting in the buffer mean the database is, or recently was, unreachable
+	status["write_buffer_depth"] = h.app.WriteBufferDepth()
+
+	// A failed backup leaves the data without an off-site copy until the next one succeeds
+	if backup := h.app.BackupStatus(); backup != nil {
+		status["backup"] = backup
+		if !backup.Healthy() {
+			status["status"] = "degraded"
+		}
+	}
+
 	// Add circuit breaker status
 	cbStatus := services.GetGlobalRegistry().Status()
 	status["circuit_breakers"] = cbStatus
 
-	// Check if any breakers are open (degraded state)
+	// Check if any breakers are open or soft-degraded
 	for _, cb := range cbStatus {
-		if cb.State == "open" {
+		if cb.Level != services.DegradationNone.String() {
 			status["status"] = "degraded"
 			break
 		}
@@ -76,23 +101,9 @@ func (h *Handler) HandleHealth(w http.ResponseWriter, r *http.Request) {
 	h.jsonResponse(w, status)
 }
 
-// HandleGetPortfolio returns portfolio summary
+// HandleGetPortfolio returns portfolio summary, including cumulative fees paid
 func (h *Handler) HandleGetPortfolio(w http.ResponseWriter, r *http.Request) {
-	positions, err := h.app.GetPositions()
-	if err != nil {
-		h.jsonError(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	h.jsonResponse(w, map[string]interface{}{
-		"positions": positions,
-		"count":     len(positions),
-	})
-}
-
-// HandleGetPositions returns all positions
-func (h *Handler) HandleGetPositions(w http.ResponseWriter, r *http.Request) {
-	positions, err := h.app.GetPositions()
+	summary, err := h.app.GetPortfolioSummary()
 	if err != nil {
 		if isHTMXRequest(r) {
 			h.htmlError(w, err.Error(), r)
@@ -103,38 +114,26 @@ func (h *Handler) HandleGetPositions(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if isHTMXRequest(r) {
-		h.htmlResponse(w, partials.PositionsList(positions), r)
+		h.htmlResponse(w, partials.PortfolioSummary(summary), r)
 		return
 	}
 
-	h.jsonResponse(w, positions)
+	h.jsonResponse(w, summary)
 }
 
-// HandleGetRecommendations returns recommendations
-func (h *Handler) HandleGetRecommendations(w http.ResponseWriter, r *http.Request) {
-	limit := h.ParseLimitParam(r, 50)
-
-	recs, err := h.app.GetRecommendations(limit)
+// HandleGetPortfolioAsOf returns the portfolio reconstructed at the close of ?date=YYYY-MM-DD
+func (h *Handler) HandleGetPortfolioAsOf(w http.ResponseWriter, r *http.Request) {
+	date, err := models.ParseAsOfDate(r.URL.Query().Get("date"), time.Now())
 	if err != nil {
 		if isHTMXRequest(r) {
 			h.htmlError(w, err.Error(), r)
 			return
 		}
-		h.jsonError(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	if isHTMXRequest(r) {
-		h.htmlResponse(w, partials.RecommendationsList(recs), r)
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	h.jsonResponse(w, recs)
-}
-
-// HandleGetPendingRecommendations returns pending recommendations
-func (h *Handler) HandleGetPendingRecommendations(w http.ResponseWriter, r *http.Request) {
-	recs, err := h.app.GetPendingRecommendations()
+	portfolio, err := h.app.GetPortfolioAsOf(date)
 	if err != nil {
 		if isHTMXRequest(r) {
 			h.htmlError(w, err.Error(), r)
@@ -145,26 +144,50 @@ func (h *Handler) HandleGetPendingRecommendations(w http.ResponseWriter, r *http
 	}
 
 	if isHTMXRequest(r) {
-		h.htmlResponse(w, partials.RecommendationsList(recs), r)
+		h.htmlResponse(w, partials.PortfolioAsOf(portfolio), r)
 		return
 	}
 
-	h.jsonResponse(w, recs)
+	h.jsonResponse(w, portfolio)
 }
 
-// HandleApproveRecommendation approves a recommendation
-func (h *Handler) HandleApproveRecommendation(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		if isHTMXRequest(r) {
-			h.htmlError(w, "Missing recommendation ID", r)
-			return
+// HandleGetDividendIncome returns the projected annual dividend income and yield on cost
+// of each long position, with the dividends expected and received while it was held
+func (h *Handler) HandleGetDividendIncome(w http.ResponseWriter, r *http.Request) {
+	income, err := h.app.GetDividendIncome()
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, app.ErrDividendsUnavailable) {
+			status = http.StatusServiceUnavailable
 		}
-		h.jsonError(w, "Missing recommendation ID", http.StatusBadRequest)
+		h.jsonError(w, err.Error(), status)
 		return
 	}
 
-	if err := h.app.ApproveRecommendation(id); err != nil {
+	h.jsonResponse(w, income)
+}
+
+// HandleGetAttribution returns realized P&L of closed positions decomposed by the agent
+// that drove each opening recommendation, in total and per month. ?days=N limits it to
+// positions closed in the last N days; all closed positions are included by default.
+func (h *Handler) HandleGetAttribution(w http.ResponseWriter, r *http.Request) {
+	var since *time.Time
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days <= 0 {
+			if isHTMXRequest(r) {
+				h.htmlError(w, "days must be a positive number", r)
+				return
+			}
+			h.jsonError(w, "days must be a positive number", http.StatusBadRequest)
+			return
+		}
+		start := time.Now().AddDate(0, 0, -days)
+		since = &start
+	}
+
+	report, err := h.app.GetAttributionReport(since)
+	if err != nil {
 		if isHTMXRequest(r) {
 			h.htmlError(w, err.Error(), r)
 			return
@@ -174,133 +197,131 @@ func (h *Handler) HandleApproveRecommendation(w http.ResponseWriter, r *http.Req
 	}
 
 	if isHTMXRequest(r) {
-		// Return the updated recommendation card
-		rec, err := h.app.GetRecommendationByID(id)
-		if err != nil {
-			h.htmlError(w, err.Error(), r)
-			return
-		}
-		h.htmlResponse(w, partials.RecommendationCardUpdated(*rec), r)
+		h.htmlResponse(w, partials.Attribution(report), r)
 		return
 	}
 
-	h.jsonResponse(w, map[string]string{"status": "approved", "id": id})
+	h.jsonResponse(w, report)
 }
 
-// HandleRejectRecommendation rejects a recommendation
-func (h *Handler) HandleRejectRecommendation(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		if isHTMXRequest(r) {
-			h.htmlError(w, "Missing recommendation ID", r)
-			return
-		}
-		h.jsonError(w, "Missing recommendation ID", http.StatusBadRequest)
-		return
-	}
-
-	if err := h.app.RejectRecommendation(id); err != nil {
+// HandleAnalyzePortfolio starts analyzing every open position and returns the running
+// portfolio review with 202 Accepted, to poll at /api/portfolio/reviews/{id}. A second
+// request while a review is running gets 409 Conflict.
+func (h *Handler) HandleAnalyzePortfolio(w http.ResponseWriter, r *http.Request) {
+	review, err := h.app.ReviewPortfolio(r.Context())
+	if err != nil {
 		if isHTMXRequest(r) {
 			h.htmlError(w, err.Error(), r)
 			return
 		}
-		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if errors.Is(err, app.ErrPortfolioReviewRunning) {
+			status = http.StatusConflict
+		}
+		h.jsonError(w, err.Error(), status)
 		return
 	}
 
 	if isHTMXRequest(r) {
-		// Return the updated recommendation card
-		rec, err := h.app.GetRecommendationByID(id)
-		if err != nil {
-			h.htmlError(w, err.Error(), r)
-			return
-		}
-		h.htmlResponse(w, partials.RecommendationCardUpdated(*rec), r)
+		h.htmlResponse(w, partials.PortfolioReviewDetail(review), r)
 		return
 	}
 
-	h.jsonResponse(w, map[string]string{"status": "rejected", "id": id})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(review)
 }
 
-// HandleAnalyzeStock triggers analysis of a stock
-func (h *Handler) HandleAnalyzeStock(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Symbol string `json:"symbol"`
-	}
-
-	contentType := r.Header.Get("Content-Type")
-	if strings.Contains(contentType, "application/json") {
-		_ = json.NewDecoder(r.Body).Decode(&req)
-	} else {
-		_ = r.ParseForm()
-		req.Symbol = r.FormValue("symbol")
-	}
+// HandleGetPortfolioReviews returns recent portfolio reviews
+func (h *Handler) HandleGetPortfolioReviews(w http.ResponseWriter, r *http.Request) {
+	limit := h.ParseLimitParam(r, 10)
 
-	if req.Symbol == "" {
+	reviews, err := h.app.GetPortfolioReviews(limit)
+	if err != nil {
 		if isHTMXRequest(r) {
-			h.htmlError(w, "Symbol is required", r)
+			h.htmlError(w, err.Error(), r)
 			return
 		}
-		h.jsonError(w, "Symbol is required", http.StatusBadRequest)
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.PortfolioReviews(reviews), r)
+		return
+	}
 
-	if err := h.ValidateSymbol(req.Symbol); err != nil {
+	h.shapedJSONResponse(w, r, reviews)
+}
+
+// HandleGetPortfolioReview returns a single portfolio review with every position's suggestion
+func (h *Handler) HandleGetPortfolioReview(w http.ResponseWriter, r *http.Request) {
+	review, err := h.app.GetPortfolioReview(chi.URLParam(r, "id"))
+	if err != nil {
 		if isHTMXRequest(r) {
 			h.htmlError(w, err.Error(), r)
 			return
 		}
-		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	rec, err := h.app.AnalyzeStock(req.Symbol)
-	if err != nil {
+	if review == nil {
 		if isHTMXRequest(r) {
-			h.htmlError(w, err.Error(), r)
+			h.htmlError(w, "Portfolio review not found", r)
 			return
 		}
-		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		h.jsonError(w, "Portfolio review not found", http.StatusNotFound)
 		return
 	}
 
 	if isHTMXRequest(r) {
-		h.htmlResponse(w, partials.AnalyzeResult(rec), r)
+		h.htmlResponse(w, partials.PortfolioReviewDetail(review), r)
 		return
 	}
 
-	h.jsonResponse(w, rec)
+	h.jsonResponse(w, review)
 }
 
-// HandleGetTrades returns recent trades
-func (h *Handler) HandleGetTrades(w http.ResponseWriter, r *http.Request) {
-	limit := h.ParseLimitParam(r, 50)
+// HandleRunBacktest replays daily bars for the requested symbols through an action strategy
+// and returns the saved run with its stats, equity curve and trades
+func (h *Handler) HandleRunBacktest(w http.ResponseWriter, r *http.Request) {
+	var req models.BacktestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
 
-	trades, err := h.app.GetTrades(limit)
+	run, err := h.app.RunBacktest(req)
 	if err != nil {
 		if isHTMXRequest(r) {
 			h.htmlError(w, err.Error(), r)
 			return
 		}
-		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, models.ErrInvalidBacktest):
+			status = http.StatusBadRequest
+		case errors.Is(err, models.ErrInsufficientHistory):
+			status = http.StatusUnprocessableEntity
+		case errors.Is(err, app.ErrBacktestUnavailable):
+			status = http.StatusServiceUnavailable
+		}
+		h.jsonError(w, err.Error(), status)
 		return
 	}
 
 	if isHTMXRequest(r) {
-		h.htmlResponse(w, partials.TradesList(trades), r)
+		h.htmlResponse(w, partials.BacktestRun(run), r)
 		return
 	}
 
-	h.jsonResponse(w, trades)
+	h.jsonResponse(w, run)
 }
 
-// HandleGetAgentRuns returns recent agent runs
-func (h *Handler) HandleGetAgentRuns(w http.ResponseWriter, r *http.Request) {
-	limit := h.ParseLimitParam(r, 50)
-
-	runs, err := h.app.GetAgentRuns(limit)
+// HandleGetBacktestRun returns a saved backtest
+func (h *Handler) HandleGetBacktestRun(w http.ResponseWriter, r *http.Request) {
+	run, err := h.app.GetBacktestRun(chi.URLParam(r, "id"))
 	if err != nil {
 		if isHTMXRequest(r) {
 			h.htmlError(w, err.Error(), r)
@@ -310,101 +331,140 @@ func (h *Handler) HandleGetAgentRuns(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if run == nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Backtest not found", r)
+			return
+		}
+		h.jsonError(w, "Backtest not found", http.StatusNotFound)
+		return
+	}
+
 	if isHTMXRequest(r) {
-		h.htmlResponse(w, partials.AgentRunsList(runs), r)
+		h.htmlResponse(w, partials.BacktestRun(run), r)
 		return
 	}
 
-	h.jsonResponse(w, runs)
+	h.jsonResponse(w, run)
 }
 
-// Helper functions
+// HandlePlanRebalance returns the orders that would move the portfolio to its target weights.
+// A JSON body with positions and sectors plans against those targets instead of the saved ones.
+func (h *Handler) HandlePlanRebalance(w http.ResponseWriter, r *http.Request) {
+	targets, ok := h.decodeRebalanceTargets(w, r)
+	if !ok {
+		return
+	}
 
-// isHTMXRequest checks if the request is from HTMX
-func isHTMXRequest(r *http.Request) bool {
-	return r.Header.Get("HX-Request") == "true"
+	plan, err := h.app.PlanRebalance(targets)
+	if err != nil {
+		h.rebalanceError(w, err)
+		return
+	}
+	h.jsonResponse(w, plan)
 }
 
-// templComponent matches the templ.Component interface
-type templComponent interface {
-	Render(ctx context.Context, w io.Writer) error
-}
+// HandleExecuteRebalance records the rebalancing orders as recommendations and places them,
+// reporting each order's trade or the reason it failed
+func (h *Handler) HandleExecuteRebalance(w http.ResponseWriter, r *http.Request) {
+	targets, ok := h.decodeRebalanceTargets(w, r)
+	if !ok {
+		return
+	}
 
-// htmlResponse renders a templ component as HTML
-func (h *Handler) htmlResponse(w http.ResponseWriter, component templComponent, r *http.Request) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	component.Render(r.Context(), w)
+	plan, err := h.app.ExecuteRebalance(targets)
+	if err != nil {
+		h.rebalanceError(w, err)
+		return
+	}
+	h.audit(r, models.AuditRecommendationExecuted, "rebalance", map[string]any{"items": len(plan.Items)})
+	h.jsonResponse(w, plan)
 }
 
-// htmlError renders an error state as HTML
-func (h *Handler) htmlError(w http.ResponseWriter, message string, r *http.Request) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	components.ErrorState(message).Render(r.Context(), w)
+// HandleGetRebalanceTargets returns the target weights rebalancing moves toward
+func (h *Handler) HandleGetRebalanceTargets(w http.ResponseWriter, r *http.Request) {
+	h.jsonResponse(w, h.app.GetRebalanceTargets())
 }
 
-// ValidateSymbol validates a stock symbol
-func (h *Handler) ValidateSymbol(symbol string) error {
-	if symbol == "" {
-		return fmt.Errorf("symbol is required")
-	}
-
-	if len(symbol) > 10 {
-		return fmt.Errorf("symbol too long (max 10 characters)")
+// HandleSetRebalanceTargets replaces the target weights rebalancing moves toward
+func (h *Handler) HandleSetRebalanceTargets(w http.ResponseWriter, r *http.Request) {
+	var req models.RebalanceTargets
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
+		return
 	}
 
-	matched, _ := regexp.MatchString("^[A-Z0-9.-]+$", symbol)
-	if !matched {
-		return fmt.Errorf("invalid symbol format (alphanumeric, dots, and dashes only)")
+	targets, err := h.app.SetRebalanceTargets(req)
+	if err != nil {
+		h.rebalanceError(w, err)
+		return
 	}
-
-	return nil
+	h.audit(r, models.AuditSettingsChanged, "rebalance_targets", nil)
+	h.jsonResponse(w, targets)
 }
 
-// ParseLimitParam parses the limit query parameter
-func (h *Handler) ParseLimitParam(r *http.Request, defaultLimit int) int {
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			return l
-		}
+// decodeRebalanceTargets reads optional targets from a JSON body, returning nil without one
+func (h *Handler) decodeRebalanceTargets(w http.ResponseWriter, r *http.Request) (*models.RebalanceTargets, bool) {
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") || r.ContentLength == 0 {
+		return nil, true
 	}
-	return defaultLimit
-}
-
-func (h *Handler) jsonResponse(w http.ResponseWriter, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(data)
+	targets := &models.RebalanceTargets{}
+	if err := json.NewDecoder(r.Body).Decode(targets); err != nil {
+		h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
+		return nil, false
+	}
+	return targets, true
 }
 
-func (h *Handler) jsonError(w http.ResponseWriter, message string, status int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+// rebalanceError maps invalid targets to 400 Bad Request, trading before the disclaimer is
+// accepted to 403 Forbidden, and a missing rebalancer to 503 Service Unavailable
+func (h *Handler) rebalanceError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, models.ErrInvalidRebalanceTargets):
+		status = http.StatusBadRequest
+	case errors.Is(err, app.ErrDisclaimerNotAcknowledged):
+		status = http.StatusForbidden
+	case errors.Is(err, app.ErrRebalanceUnavailable):
+		status = http.StatusServiceUnavailable
+	case errors.Is(err, app.ErrRebalanceRunning):
+		status = http.StatusConflict
+	}
+	h.jsonError(w, err.Error(), status)
 }
 
-// StatusResponse represents a status response
-type StatusResponse struct {
-	Status  string `json:"status"`
-	Message string `json:"message,omitempty"`
+// HandleGetBroker returns the broker new orders go to and the brokers that are configured
+func (h *Handler) HandleGetBroker(w http.ResponseWriter, r *http.Request) {
+	h.jsonResponse(w, h.app.GetBroker())
 }
 
-// AnalyzeRequest represents a stock analysis request
-type AnalyzeRequest struct {
-	Symbol string `json:"symbol"`
-}
+// HandleSetBroker routes new orders to the broker named in the body, such as {"broker": "ibkr"}.
+// Unknown or unconfigured brokers are rejected with 400 Bad Request.
+func (h *Handler) HandleSetBroker(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Broker string `json:"broker"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
 
-// HandleRunScreener triggers a full screener run
-func (h *Handler) HandleRunScreener(w http.ResponseWriter, r *http.Request) {
-	if h.app.Screener() == nil {
-		if isHTMXRequest(r) {
-			status := h.app.ScreenerStatus()
-			h.htmlResponse(w, partials.ScreenerNotConfigured(status.MissingServices), r)
-			return
+	status, err := h.app.SetActiveBroker(req.Broker)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, models.ErrUnknownBroker) {
+			code = http.StatusBadRequest
 		}
-		h.jsonError(w, "Screener not configured", http.StatusServiceUnavailable)
+		h.jsonError(w, err.Error(), code)
 		return
 	}
+	h.audit(r, models.AuditSettingsChanged, "broker", map[string]any{"broker": req.Broker})
+	h.jsonResponse(w, status)
+}
 
-	run, err := h.app.RunScreener()
+// HandleGetPositions returns all positions
+func (h *Handler) HandleGetPositions(w http.ResponseWriter, r *http.Request) {
+	positions, err := h.app.GetPositions()
 	if err != nil {
 		if isHTMXRequest(r) {
 			h.htmlError(w, err.Error(), r)
@@ -415,26 +475,24 @@ func (h *Handler) HandleRunScreener(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if isHTMXRequest(r) {
-		picks, _ := h.app.GetTopPicks()
-		h.htmlResponse(w, partials.TodaysPicks(run, picks), r)
+		h.htmlResponse(w, partials.PositionsList(positions), r)
 		return
 	}
 
-	h.jsonResponse(w, run)
+	h.jsonResponse(w, positions)
 }
 
-// HandleGetLatestScreenerRun returns the most recent screener run
-func (h *Handler) HandleGetLatestScreenerRun(w http.ResponseWriter, r *http.Request) {
-	if h.app.Screener() == nil {
-		if isHTMXRequest(r) {
-			h.htmlError(w, "Screener not configured", r)
-			return
-		}
-		h.jsonError(w, "Screener not configured", http.StatusServiceUnavailable)
+// HandleGetRecommendations returns recommendations, optionally filtered with ?status=,
+// such as ?status=expired
+func (h *Handler) HandleGetRecommendations(w http.ResponseWriter, r *http.Request) {
+	limit := h.ParseLimitParam(r, 50)
+	status, err := models.ParseRecommendationStatus(r.URL.Query().Get("status"))
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	run, err := h.app.GetLatestScreenerRun()
+	recs, err := h.app.GetRecommendationsByStatus(status, limit)
 	if err != nil {
 		if isHTMXRequest(r) {
 			h.htmlError(w, err.Error(), r)
@@ -444,76 +502,2106 @@ func (h *Handler) HandleGetLatestScreenerRun(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if run == nil {
-		if isHTMXRequest(r) {
-			h.htmlResponse(w, partials.ScreenerEmpty(), r)
-			return
-		}
-		h.jsonResponse(w, map[string]interface{}{"run": nil})
-		return
-	}
-
 	if isHTMXRequest(r) {
-		h.htmlResponse(w, partials.ScreenerRunResult(run), r)
+		h.htmlResponse(w, partials.RecommendationsList(recs), r)
 		return
 	}
 
-	h.jsonResponse(w, run)
+	h.shapedJSONResponse(w, r, recs)
 }
 
-// HandleGetScreenerRuns returns screener run history
-func (h *Handler) HandleGetScreenerRuns(w http.ResponseWriter, r *http.Request) {
-	if h.app.Screener() == nil {
+// HandleGetPendingRecommendations returns pending recommendations
+func (h *Handler) HandleGetPendingRecommendations(w http.ResponseWriter, r *http.Request) {
+	recs, err := h.app.GetPendingRecommendations()
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.RecommendationsList(recs), r)
+		return
+	}
+
+	h.shapedJSONResponse(w, r, recs)
+}
+
+// HandleApproveRecommendation approves a recommendation
+func (h *Handler) HandleApproveRecommendation(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Missing recommendation ID", r)
+			return
+		}
+		h.jsonError(w, "Missing recommendation ID", http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseVersionParam(r)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.app.ApproveRecommendation(id, version); err != nil {
+		h.recommendationUpdateError(w, r, err)
+		return
+	}
+	h.audit(r, models.AuditRecommendationApproved, id, nil)
+
+	if isHTMXRequest(r) {
+		// Return the updated recommendation card
+		rec, err := h.app.GetRecommendationByID(id)
+		if err != nil {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.htmlResponse(w, partials.RecommendationCardUpdated(*rec), r)
+		return
+	}
+
+	rec, err := h.app.GetRecommendationByID(id)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// With EXECUTION_MODE=auto the approval also placed the order
+	status := "approved"
+	if rec.Status == models.RecommendationStatusExecuted {
+		status = "executed"
+	}
+	h.jsonResponse(w, RecommendationActionResponse{Status: status, ID: id, Recommendation: rec})
+}
+
+// SplitPlanRequest approves a pending recommendation to execute in tranches
+type SplitPlanRequest struct {
+	models.SplitPlan
+	Version int `json:"version,omitempty"`
+}
+
+// HandleSplitRecommendation approves a pending recommendation with a split plan, so it is
+// executed as several child orders by time interval or price level. Accepts JSON or form
+// values, with form price levels comma-separated.
+func (h *Handler) HandleSplitRecommendation(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Missing recommendation ID", r)
+			return
+		}
+		h.jsonError(w, "Missing recommendation ID", http.StatusBadRequest)
+		return
+	}
+
+	req, err := parseSplitPlan(r)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	progress, err := h.app.ApproveWithSplitPlan(id, req.Version, req.SplitPlan)
+	if err != nil {
+		h.recommendationUpdateError(w, r, err)
+		return
+	}
+	h.audit(r, models.AuditRecommendationApproved, id, map[string]any{"split_trigger": req.Trigger})
+
+	rec, err := h.app.GetRecommendationByID(id)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.RecommendationCardUpdated(*rec), r)
+		return
+	}
+
+	h.jsonResponse(w, RecommendationActionResponse{Status: "approved", ID: id, Recommendation: rec, Split: progress})
+}
+
+// parseSplitPlan reads a SplitPlanRequest from a JSON body or form values. The version may
+// also be passed as a query parameter.
+func parseSplitPlan(r *http.Request) (SplitPlanRequest, error) {
+	var req SplitPlanRequest
+	if strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return req, errors.New("invalid JSON request")
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			return req, errors.New("failed to parse form")
+		}
+		req.Trigger = models.SplitTrigger(r.FormValue("trigger"))
+		for field, dst := range map[string]*int{"count": &req.Count, "interval_minutes": &req.IntervalMinutes} {
+			if raw := r.FormValue(field); raw != "" {
+				n, err := strconv.Atoi(raw)
+				if err != nil {
+					return req, fmt.Errorf("invalid %s %q", field, raw)
+				}
+				*dst = n
+			}
+		}
+		if raw := r.FormValue("price_levels"); raw != "" {
+			for _, part := range strings.Split(raw, ",") {
+				level, err := decimal.NewFromString(strings.TrimSpace(part))
+				if err != nil {
+					return req, fmt.Errorf("invalid price level %q", part)
+				}
+				req.PriceLevels = append(req.PriceLevels, level)
+			}
+		}
+	}
+
+	if req.Version == 0 {
+		version, err := parseVersionParam(r)
+		if err != nil {
+			return req, err
+		}
+		req.Version = version
+	}
+	return req, nil
+}
+
+// HandleGetRecommendationTranches returns a split recommendation's tranches and fill progress
+func (h *Handler) HandleGetRecommendationTranches(w http.ResponseWriter, r *http.Request) {
+	progress, err := h.app.GetSplitProgress(chi.URLParam(r, "id"))
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if progress == nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Recommendation was not split", r)
+			return
+		}
+		h.jsonError(w, "Recommendation was not split", http.StatusNotFound)
+		return
+	}
+
+	h.jsonResponse(w, progress)
+}
+
+// HandleCancelRecommendationTranches cancels the tranches of a split recommendation that
+// have not executed yet
+func (h *Handler) HandleCancelRecommendationTranches(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	progress, err := h.app.CancelSplitPlan(id)
+	if err != nil {
+		h.recommendationUpdateError(w, r, err)
+		return
+	}
+
+	rec, err := h.app.GetRecommendationByID(id)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.RecommendationCardUpdated(*rec), r)
+		return
+	}
+
+	h.jsonResponse(w, RecommendationActionResponse{Status: "split_cancelled", ID: id, Recommendation: rec, Split: progress})
+}
+
+// RecommendationEditRequest carries draft edits to a pending recommendation; omitted
+// fields keep their current values
+type RecommendationEditRequest struct {
+	Quantity   *decimal.Decimal `json:"quantity,omitempty"`
+	OrderType  string           `json:"order_type,omitempty"`
+	LimitPrice *decimal.Decimal `json:"limit_price,omitempty"`
+	Version    int              `json:"version,omitempty"`
+}
+
+// HandleEditRecommendation records user edits to a pending recommendation's quantity,
+// order type and limit price. Accepts JSON or form values.
+func (h *Handler) HandleEditRecommendation(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Missing recommendation ID", r)
+			return
+		}
+		h.jsonError(w, "Missing recommendation ID", http.StatusBadRequest)
+		return
+	}
+
+	req, err := parseRecommendationEdit(r)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	edit := models.RecommendationOverride{Quantity: req.Quantity, OrderType: req.OrderType, LimitPrice: req.LimitPrice}
+	rec, err := h.app.EditRecommendation(id, edit, req.Version)
+	if err != nil {
+		h.recommendationUpdateError(w, r, err)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.RecommendationCardUpdated(*rec), r)
+		return
+	}
+
+	h.jsonResponse(w, RecommendationActionResponse{Status: "edited", ID: id, Recommendation: rec})
+}
+
+// parseRecommendationEdit reads a RecommendationEditRequest from a JSON body or form values.
+// Empty form fields are left unset; the version may also be passed as a query parameter.
+func parseRecommendationEdit(r *http.Request) (RecommendationEditRequest, error) {
+	var req RecommendationEditRequest
+	if strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return req, errors.New("invalid JSON request")
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			return req, errors.New("failed to parse form")
+		}
+		req.OrderType = r.FormValue("order_type")
+		var err error
+		if req.Quantity, err = parseDecimalForm(r, "quantity"); err != nil {
+			return req, err
+		}
+		if req.LimitPrice, err = parseDecimalForm(r, "limit_price"); err != nil {
+			return req, err
+		}
+	}
+
+	if req.Version == 0 {
+		version, err := parseVersionParam(r)
+		if err != nil {
+			return req, err
+		}
+		req.Version = version
+	}
+	return req, nil
+}
+
+// parseDecimalForm parses an optional decimal form value, returning nil when it is empty
+func parseDecimalForm(r *http.Request, field string) (*decimal.Decimal, error) {
+	raw := r.FormValue(field)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := decimal.NewFromString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q", field, raw)
+	}
+	return &value, nil
+}
+
+// HandleRejectRecommendation rejects a recommendation
+func (h *Handler) HandleRejectRecommendation(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Missing recommendation ID", r)
+			return
+		}
+		h.jsonError(w, "Missing recommendation ID", http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseVersionParam(r)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.app.RejectRecommendation(id, version); err != nil {
+		h.recommendationUpdateError(w, r, err)
+		return
+	}
+	h.audit(r, models.AuditRecommendationRejected, id, nil)
+
+	if isHTMXRequest(r) {
+		// Return the updated recommendation card
+		rec, err := h.app.GetRecommendationByID(id)
+		if err != nil {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.htmlResponse(w, partials.RecommendationCardUpdated(*rec), r)
+		return
+	}
+
+	rec, err := h.app.GetRecommendationByID(id)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.jsonResponse(w, RecommendationActionResponse{Status: "rejected", ID: id, Recommendation: rec})
+}
+
+// HandleExecuteRecommendation approves a pending recommendation if needed, places its order,
+// and records the trade and position update atomically
+func (h *Handler) HandleExecuteRecommendation(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	version, err := parseVersionParam(r)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	trade, err := h.app.ExecuteRecommendation(id, version)
+	if err != nil {
+		h.recommendationUpdateError(w, r, err)
+		return
+	}
+	h.audit(r, models.AuditRecommendationExecuted, id, map[string]any{
+		"trade_id": trade.ID, "symbol": trade.Symbol, "side": trade.Side, "quantity": trade.Quantity,
+	})
+
+	rec, err := h.app.GetRecommendationByID(id)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.RecommendationCardUpdated(*rec), r)
+		return
+	}
+
+	h.jsonResponse(w, RecommendationActionResponse{Status: "executed", ID: id, Recommendation: rec, Trade: trade})
+}
+
+// HandlePreviewRecommendation returns the order ticket for a recommendation: the estimated
+// fill, costs and account impact of executing it. For HTMX requests the ticket is rendered
+// with a button confirming the "intent" query parameter, approve or execute (the default).
+func (h *Handler) HandlePreviewRecommendation(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	ticket, err := h.app.PreviewRecommendation(id)
+	if err != nil {
+		h.recommendationUpdateError(w, r, err)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		intent := r.URL.Query().Get("intent")
+		if intent != "approve" {
+			intent = "execute"
+		}
+		h.htmlResponse(w, partials.OrderTicket(*ticket, intent), r)
+		return
+	}
+
+	h.jsonResponse(w, ticket)
+}
+
+// HandleAnalyzeStock triggers analysis of a stock. With ?async=true, JSON requests get the
+// analysis job to poll at /api/analyze/jobs/{id} instead of waiting for the recommendation.
+func (h *Handler) HandleAnalyzeStock(w http.ResponseWriter, r *http.Request) {
+	req := parseAnalyzeRequest(r)
+	if req.Symbol == "" {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Symbol is required", r)
+			return
+		}
+		h.jsonError(w, "Symbol is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ValidateSymbol(req.Symbol); err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.URL.Query().Get("async") == "true" && !isHTMXRequest(r) {
+		job, err := h.app.AnalyzeStockAsync(req.Symbol)
+		if err != nil {
+			h.jsonError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		h.jsonResponse(w, job)
+		return
+	}
+
+	rec, err := h.app.AnalyzeStockFor(r.Context(), req.Symbol)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		status := http.StatusInternalServerError
+		if errors.Is(err, models.ErrLLMBudgetExceeded) {
+			status = http.StatusTooManyRequests
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.AnalyzeResult(rec), r)
+		return
+	}
+
+	h.jsonResponse(w, rec)
+}
+
+// HandleAnalyzeBatch starts analyzing the symbols in a JSON body such as
+// {"symbols": ["AAPL", "MSFT"]} and returns the batch with its ID to poll. Symbols are
+// validated as a single analysis's are, so a blocklisted symbol rejects the batch.
+func (h *Handler) HandleAnalyzeBatch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Symbols []string `json:"symbols"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+	for i, symbol := range req.Symbols {
+		req.Symbols[i] = strings.ToUpper(strings.TrimSpace(symbol))
+		if err := h.ValidateSymbol(req.Symbols[i]); err != nil {
+			h.jsonError(w, fmt.Sprintf("%s: %v", symbol, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	batch, err := h.app.AnalyzeBatch(req.Symbols)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, models.ErrInvalidBatchAnalysis) {
+			status = http.StatusBadRequest
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+	h.jsonResponse(w, batch)
+}
+
+// HandleGetBatchAnalysis returns a batch analysis's progress and the results finished so far
+func (h *Handler) HandleGetBatchAnalysis(w http.ResponseWriter, r *http.Request) {
+	batch, err := h.app.GetBatchAnalysis(chi.URLParam(r, "id"))
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if batch == nil {
+		h.jsonError(w, "Batch analysis not found", http.StatusNotFound)
+		return
+	}
+	h.jsonResponse(w, batch)
+}
+
+// HandleGetAnalysisJob returns an async analysis's per-agent progress and, once it
+// completes, its recommendation
+func (h *Handler) HandleGetAnalysisJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.app.GetAnalysisJob(chi.URLParam(r, "id"))
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if job == nil {
+		h.jsonError(w, "Analysis job not found", http.StatusNotFound)
+		return
+	}
+	h.jsonResponse(w, job)
+}
+
+// HandleGetTrades returns recent trades
+func (h *Handler) HandleGetTrades(w http.ResponseWriter, r *http.Request) {
+	limit := h.ParseLimitParam(r, 50)
+
+	trades, err := h.app.GetTrades(limit)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.TradesList(trades), r)
+		return
+	}
+
+	h.jsonResponse(w, trades)
+}
+
+// HandleGetTrade returns a trade with its order's current state at the broker
+func (h *Handler) HandleGetTrade(w http.ResponseWriter, r *http.Request) {
+	trade, err := h.app.GetTrade(chi.URLParam(r, "id"))
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if trade == nil {
+		h.jsonError(w, "Trade not found", http.StatusNotFound)
+		return
+	}
+
+	h.jsonResponse(w, trade)
+}
+
+// ActivityFeedResponse is a page of the activity feed. NextBefore is the cursor for the
+// next page and is omitted once there are no more events.
+type ActivityFeedResponse struct {
+	Events     []models.ActivityEvent `json:"events"`
+	NextBefore string                 `json:"next_before,omitempty"`
+}
+
+// HandleGetRecommendationEvents returns the state transition timeline of a recommendation
+func (h *Handler) HandleGetRecommendationEvents(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	rec, err := h.app.GetRecommendationByID(id)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if rec == nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Recommendation not found", r)
+			return
+		}
+		h.jsonError(w, "Recommendation not found", http.StatusNotFound)
+		return
+	}
+
+	events, err := h.app.GetRecommendationEvents(id)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.RecommendationTimeline(events), r)
+		return
+	}
+
+	if events == nil {
+		events = []models.RecommendationEvent{}
+	}
+	h.jsonResponse(w, events)
+}
+
+// HandleGetRecommendationMarkdown returns a Markdown summary of a recommendation for sharing
+func (h *Handler) HandleGetRecommendationMarkdown(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	rec, err := h.app.GetRecommendationByID(id)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if rec == nil {
+		h.jsonError(w, "Recommendation not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	// Quotes and control characters in the filename are escaped rather than written raw
+	filename := fmt.Sprintf("%s-%s.md", rec.Symbol, rec.CreatedAt.Format("2006-01-02"))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": filename}))
+	_, _ = w.Write([]byte(rec.Markdown()))
+}
+
+// HandleGetActivity returns the merged account activity feed, paginated by the "before"
+// cursor from the previous page's next_before
+func (h *Handler) HandleGetActivity(w http.ResponseWriter, r *http.Request) {
+	limit := h.ParseLimitParam(r, 20)
+
+	var after *models.ActivityCursor
+	beforeParam := r.URL.Query().Get("before")
+	if beforeParam != "" {
+		cursor, err := models.ParseActivityCursor(beforeParam)
+		if err != nil {
+			if isHTMXRequest(r) {
+				h.htmlError(w, "Invalid 'before' cursor", r)
+				return
+			}
+			h.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		after = &cursor
+	}
+
+	events, err := h.app.GetActivity(after, limit)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var nextBefore string
+	if len(events) == limit {
+		nextBefore = events[len(events)-1].Cursor().String()
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.ActivityFeed(events, nextBefore, beforeParam == ""), r)
+		return
+	}
+
+	if events == nil {
+		events = []models.ActivityEvent{}
+	}
+	h.jsonResponse(w, ActivityFeedResponse{Events: events, NextBefore: nextBefore})
+}
+
+// HandleGetAgentRuns returns recent agent runs
+func (h *Handler) HandleGetAgentRuns(w http.ResponseWriter, r *http.Request) {
+	limit := h.ParseLimitParam(r, 50)
+
+	runs, err := h.app.GetAgentRuns(limit)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.AgentRunsList(runs), r)
+		return
+	}
+
+	h.shapedJSONResponse(w, r, runs)
+}
+
+// HandleGetQuote returns the latest quote for a symbol with its market session
+func (h *Handler) HandleGetQuote(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(strings.TrimSpace(chi.URLParam(r, "symbol")))
+	if err := h.ValidateSymbol(symbol); err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	quote, err := h.app.GetQuote(symbol)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.QuoteCard(quote), r)
+		return
+	}
+
+	h.jsonResponse(w, quote)
+}
+
+// HandleGetQuickLook returns a cached mini-summary of a symbol. HTMX requests get the
+// popover shown when hovering a ticker.
+func (h *Handler) HandleGetQuickLook(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(strings.TrimSpace(chi.URLParam(r, "symbol")))
+	if err := h.ValidateSymbol(symbol); err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	look := h.app.QuickLook(symbol)
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.QuickLook(look), r)
+		return
+	}
+
+	h.jsonResponse(w, look)
+}
+
+// HandleGetSimilar returns past analyses whose reasoning is closest to the latest analysis
+// of the symbol in the query string
+func (h *Handler) HandleGetSimilar(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("symbol")))
+	if err := validateSymbolFormat(symbol); err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit := h.ParseLimitParam(r, 10)
+
+	similar, err := h.app.SimilarAnalyses(symbol, limit)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, app.ErrSimilarityUnavailable):
+			status = http.StatusServiceUnavailable
+		case errors.Is(err, models.ErrAnalysisNotIndexed):
+			status = http.StatusNotFound
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.SimilarAnalyses(similar), r)
+		return
+	}
+
+	if similar == nil {
+		similar = []models.SimilarAnalysis{}
+	}
+	h.shapedJSONResponse(w, r, similar)
+}
+
+// HandleGetRiskStats returns a symbol's trailing volatility, beta and max drawdown. HTMX
+// requests get the panel shown on recommendation cards.
+func (h *Handler) HandleGetRiskStats(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(strings.TrimSpace(chi.URLParam(r, "symbol")))
+	if err := h.ValidateSymbol(symbol); err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.app.GetRiskStats(symbol)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, app.ErrRiskStatsUnavailable):
+			status = http.StatusServiceUnavailable
+		case errors.Is(err, models.ErrInsufficientHistory):
+			status = http.StatusUnprocessableEntity
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.RiskStats(stats), r)
+		return
+	}
+
+	h.jsonResponse(w, stats)
+}
+
+// MarketSessionResponse describes the trading session currently in progress
+type MarketSessionResponse struct {
+	Session       models.MarketSession `json:"session"`
+	Label         string               `json:"label"`
+	ExtendedHours bool                 `json:"extended_hours"`
+	Reason        string               `json:"reason"`
+	NextOpen      *time.Time           `json:"next_open,omitempty"`
+	NextClose     *time.Time           `json:"next_close,omitempty"`
+}
+
+// HandleGetMarketSession returns the current market session from the broker's calendar,
+// with the next regular-session open and close
+func (h *Handler) HandleGetMarketSession(w http.ResponseWriter, r *http.Request) {
+	status := h.app.MarketStatus()
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.MarketSessionIndicator(status), r)
+		return
+	}
+
+	h.jsonResponse(w, MarketSessionResponse{
+		Session:       status.Session,
+		Label:         status.Session.Label(),
+		ExtendedHours: status.Session.IsExtendedHours(),
+		Reason:        status.Reason,
+		NextOpen:      status.NextOpen,
+		NextClose:     status.NextClose,
+	})
+}
+
+// Helper functions
+
+// isHTMXRequest checks if the request is from HTMX and has not opted out of HTML
+func isHTMXRequest(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true" && !prefersJSON(r)
+}
+
+// templComponent matches the templ.Component interface
+type templComponent interface {
+	Render(ctx context.Context, w io.Writer) error
+}
+
+// htmlResponse renders a templ component as HTML
+func (h *Handler) htmlResponse(w http.ResponseWriter, component templComponent, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	component.Render(r.Context(), w)
+}
+
+// htmlError renders an error state as HTML
+func (h *Handler) htmlError(w http.ResponseWriter, message string, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	components.ErrorState(message).Render(r.Context(), w)
+}
+
+// ValidateSymbol validates a stock symbol's format and rejects blocklisted symbols
+func (h *Handler) ValidateSymbol(symbol string) error {
+	if err := validateSymbolFormat(symbol); err != nil {
+		return err
+	}
+	return h.app.CheckSymbolAllowed(symbol)
+}
+
+// validateSymbolFormat checks that a symbol is a well-formed ticker
+func validateSymbolFormat(symbol string) error {
+	if symbol == "" {
+		return fmt.Errorf("symbol is required")
+	}
+
+	if len(symbol) > 10 {
+		return fmt.Errorf("symbol too long (max 10 characters)")
+	}
+
+	matched, _ := regexp.MatchString("^[A-Z0-9.-]+$", symbol)
+	if !matched {
+		return fmt.Errorf("invalid symbol format (alphanumeric, dots, and dashes only)")
+	}
+
+	return nil
+}
+
+// ParseLimitParam parses the limit query parameter
+func (h *Handler) ParseLimitParam(r *http.Request, defaultLimit int) int {
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			return l
+		}
+	}
+	return defaultLimit
+}
+
+// parseVersionParam reads the optional "version" form or query value used for optimistic
+// locking. A missing value returns models.AnyVersion.
+func parseVersionParam(r *http.Request) (int, error) {
+	raw := r.FormValue("version")
+	if raw == "" {
+		return models.AnyVersion, nil
+	}
+	version, err := strconv.Atoi(raw)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid version %q", raw)
+	}
+	return version, nil
+}
+
+// recommendationUpdateError reports a failed recommendation transition, mapping stale
+// versions, non-executable recommendations, blocklisted symbols, and risk rule violations
+// to 409 Conflict, invalid draft edits and split plans to 400 Bad Request, and trading
+// before the disclaimer is accepted to 403 Forbidden
+func (h *Handler) recommendationUpdateError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, app.ErrDisclaimerNotAcknowledged) {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, models.ErrVersionConflict) {
+		const msg = "This recommendation was changed elsewhere. Refresh to see its current state."
+		if isHTMXRequest(r) {
+			h.htmlError(w, msg, r)
+			return
+		}
+		h.jsonError(w, msg, http.StatusConflict)
+		return
+	}
+	if errors.Is(err, models.ErrRecommendationNotFound) {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, models.ErrInvalidOverride) || errors.Is(err, models.ErrInvalidSplitPlan) {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, app.ErrAutoExecutionFailed) {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if errors.Is(err, models.ErrRecommendationNotExecutable) || errors.Is(err, models.ErrSymbolBlocked) || errors.Is(err, models.ErrRiskRuleViolation) {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if isHTMXRequest(r) {
+		h.htmlError(w, err.Error(), r)
+		return
+	}
+	h.jsonError(w, err.Error(), http.StatusInternalServerError)
+}
+
+func (h *Handler) jsonResponse(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
+
+func (h *Handler) jsonError(w http.ResponseWriter, message string, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// jsonErrorWithFields writes a JSON error carrying the same context the HTML view shows
+func (h *Handler) jsonErrorWithFields(w http.ResponseWriter, message string, status int, fields map[string]interface{}) {
+	body := map[string]interface{}{"error": message}
+	for k, v := range fields {
+		body[k] = v
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// StatusResponse represents a status response
+type StatusResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// RecommendationActionResponse is returned after approving or rejecting a recommendation
+type RecommendationActionResponse struct {
+	Status         string                 `json:"status"`
+	ID             string                 `json:"id"`
+	Recommendation *models.Recommendation `json:"recommendation"`
+	Trade          *models.Trade          `json:"trade,omitempty"` // Set when the action placed an order
+	Split          *models.SplitProgress  `json:"split,omitempty"` // Set when the action changed a split plan
+}
+
+// ScreenerRunResponse is a screener run together with the picks it produced
+type ScreenerRunResponse struct {
+	*models.ScreenerRun
+	Picks []models.ScreenerCandidate `json:"picks"`
+}
+
+// TopPicksResponse is the top picks along with the run they came from
+type TopPicksResponse struct {
+	Run   *models.ScreenerRun        `json:"run"`
+	Picks []models.ScreenerCandidate `json:"picks"`
+	Count int                        `json:"count"`
+}
+
+// AnalyzeRequest represents a stock analysis request
+type AnalyzeRequest struct {
+	Symbol string `json:"symbol"`
+}
+
+// parseAnalyzeRequest reads the symbol from a JSON body or form, upper-cased and trimmed.
+// A body that can't be parsed leaves the symbol empty.
+func parseAnalyzeRequest(r *http.Request) AnalyzeRequest {
+	var req AnalyzeRequest
+	if strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		_ = json.NewDecoder(r.Body).Decode(&req)
+	} else {
+		_ = r.ParseForm()
+		req.Symbol = r.FormValue("symbol")
+	}
+	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+	return req
+}
+
+// HandleRunScreener triggers a full screener run. A JSON body may override the configured
+// listing filters (exchanges, country, price_min, avg_volume_min) for this run, and the
+// preset query parameter (or body field) picks the value or growth screen or a saved preset.
+func (h *Handler) HandleRunScreener(w http.ResponseWriter, r *http.Request) {
+	if h.app.Screener() == nil {
+		h.screenerNotConfigured(w, r)
+		return
+	}
+
+	var overrides *models.ScreenerFilters
+	if strings.Contains(r.Header.Get("Content-Type"), "application/json") && r.ContentLength != 0 {
+		overrides = &models.ScreenerFilters{}
+		if err := json.NewDecoder(r.Body).Decode(overrides); err != nil {
+			h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
+			return
+		}
+		if overrides.PriceMin < 0 || overrides.AvgVolumeMin < 0 || overrides.DollarVolumeMin < 0 {
+			h.jsonError(w, "price_min, avg_volume_min and dollar_volume_min must not be negative", http.StatusBadRequest)
+			return
+		}
+		for i, exchange := range overrides.Exchanges {
+			overrides.Exchanges[i] = strings.ToUpper(strings.TrimSpace(exchange))
+		}
+		overrides.Country = strings.ToUpper(strings.TrimSpace(overrides.Country))
+	}
+	if preset := r.URL.Query().Get("preset"); preset != "" {
+		if overrides == nil {
+			overrides = &models.ScreenerFilters{}
+		}
+		overrides.Preset = preset
+	}
+	if overrides != nil && overrides.Preset != "" && !models.ValidScreenerPresetName(overrides.Preset) {
+		h.jsonError(w, fmt.Sprintf("preset must be one of %s or the name of a saved preset", strings.Join(models.ScreenerPresets, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	run, err := h.app.RunScreener(overrides)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, models.ErrScreenerPresetNotFound):
+			status = http.StatusBadRequest
+		case errors.Is(err, models.ErrScreenerRunInProgress):
+			status = http.StatusConflict
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+	h.audit(r, models.AuditScreenerRun, run.ID.String(), screenerRunAuditDetails(overrides))
+
+	picks, _ := h.app.GetTopPicks()
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.TodaysPicks(run, picks), r)
+		return
+	}
+
+	h.jsonResponse(w, ScreenerRunResponse{ScreenerRun: run, Picks: picks})
+}
+
+// HandleGetLatestScreenerRun returns the most recent screener run
+func (h *Handler) HandleGetLatestScreenerRun(w http.ResponseWriter, r *http.Request) {
+	if h.app.Screener() == nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Screener not configured", r)
+			return
+		}
+		h.jsonError(w, "Screener not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	run, err := h.app.GetLatestScreenerRun()
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if run == nil {
+		if isHTMXRequest(r) {
+			h.htmlResponse(w, partials.ScreenerEmpty(), r)
+			return
+		}
+		h.jsonResponse(w, map[string]interface{}{"run": nil})
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.ScreenerRunResult(run), r)
+		return
+	}
+
+	h.jsonResponse(w, run)
+}
+
+// HandleGetScreenerRuns returns screener run history
+func (h *Handler) HandleGetScreenerRuns(w http.ResponseWriter, r *http.Request) {
+	if h.app.Screener() == nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Screener not configured", r)
+			return
+		}
+		h.jsonError(w, "Screener not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	limit := h.ParseLimitParam(r, 10)
+
+	runs, err := h.app.GetScreenerRunHistory(limit)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.ScreenerRunsList(runs), r)
+		return
+	}
+
+	h.jsonResponse(w, runs)
+}
+
+// HandleGetScreenerRun returns a specific screener run by ID
+func (h *Handler) HandleGetScreenerRun(w http.ResponseWriter, r *http.Request) {
+	if h.app.Screener() == nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Screener not configured", r)
+			return
+		}
+		h.jsonError(w, "Screener not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Missing screener run ID", r)
+			return
+		}
+		h.jsonError(w, "Missing screener run ID", http.StatusBadRequest)
+		return
+	}
+
+	run, err := h.app.GetScreenerRun(id)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if run == nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Screener run not found", r)
+			return
+		}
+		h.jsonError(w, "Screener run not found", http.StatusNotFound)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.ScreenerRunResult(run), r)
+		return
+	}
+
+	h.jsonResponse(w, run)
+}
+
+// HandleReplayScreenerRun starts screening a run's archived universe again in the
+// background. A JSON body may override the run's criteria and pick a different
+// ranking_strategy; the 202 response compares the new replay run, still running, with
+// the original, and HandleGetScreenerReplayComparison reports it once it completes.
+func (h *Handler) HandleReplayScreenerRun(w http.ResponseWriter, r *http.Request) {
+	if h.app.Screener() == nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Screener not configured", r)
+			return
+		}
+		h.jsonError(w, "Screener not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req models.ScreenerReplayRequest
+	if strings.Contains(r.Header.Get("Content-Type"), "application/json") && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
+			return
+		}
+		for i, exchange := range req.Exchanges {
+			req.Exchanges[i] = strings.ToUpper(strings.TrimSpace(exchange))
+		}
+		req.Country = strings.ToUpper(strings.TrimSpace(req.Country))
+	}
+
+	id := chi.URLParam(r, "id")
+	cmp, err := h.app.ReplayScreenerRun(id, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, models.ErrScreenerRunInProgress):
+			status = http.StatusConflict
+		case errors.Is(err, models.ErrInvalidScreenerReplay), errors.Is(err, models.ErrScreenerUniverseMissing):
+			status = http.StatusBadRequest
+		}
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+
+	if cmp == nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Screener run not found", r)
+			return
+		}
+		h.jsonError(w, "Screener run not found", http.StatusNotFound)
+		return
+	}
+	h.audit(r, models.AuditScreenerRun, cmp.Replay.ID.String(), map[string]any{"replay_of": id})
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.ScreenerReplayComparison(cmp), r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(cmp)
+}
+
+// HandleGetScreenerReplayComparison compares a replay run with the run it replayed
+func (h *Handler) HandleGetScreenerReplayComparison(w http.ResponseWriter, r *http.Request) {
+	if h.app.Screener() == nil {
+		h.screenerNotConfigured(w, r)
+		return
+	}
+
+	cmp, err := h.app.GetScreenerReplayComparison(chi.URLParam(r, "id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, models.ErrInvalidScreenerReplay) {
+			status = http.StatusBadRequest
+		}
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+	if cmp == nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Screener run not found", r)
+			return
+		}
+		h.jsonError(w, "Screener run not found", http.StatusNotFound)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.ScreenerReplayComparison(cmp), r)
+		return
+	}
+	h.jsonResponse(w, cmp)
+}
+
+// HandleRetryFailedScreenerCandidates re-analyzes the failed candidates of a screener run
+func (h *Handler) HandleRetryFailedScreenerCandidates(w http.ResponseWriter, r *http.Request) {
+	if h.app.Screener() == nil {
+		h.screenerNotConfigured(w, r)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	run, err := h.app.RetryFailedScreenerCandidates(id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, models.ErrScreenerRunInProgress) {
+			status = http.StatusConflict
+		}
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+
+	if run == nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Screener run not found", r)
+			return
+		}
+		h.jsonError(w, "Screener run not found", http.StatusNotFound)
+		return
+	}
+	h.audit(r, models.AuditScreenerRun, id, map[string]any{"retry_failed": true})
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.ScreenerRunResult(run), r)
+		return
+	}
+
+	h.jsonResponse(w, run)
+}
+
+// HandleGetScreenerSchedule returns when the screener runs on its own, with its next and last runs
+func (h *Handler) HandleGetScreenerSchedule(w http.ResponseWriter, r *http.Request) {
+	h.jsonResponse(w, h.app.GetScreenerSchedule())
+}
+
+// HandleSetScreenerSchedule replaces the screener schedule. An empty cron stops scheduled runs.
+func (h *Handler) HandleSetScreenerSchedule(w http.ResponseWriter, r *http.Request) {
+	var req models.ScreenerSchedule
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.app.SetScreenerSchedule(req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, models.ErrInvalidCron) {
+			status = http.StatusBadRequest
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+	h.audit(r, models.AuditSettingsChanged, "screener_schedule", map[string]any{"cron": req.Cron, "analyze": req.Analyze})
+
+	h.jsonResponse(w, schedule)
+}
+
+// HandleGetTopPicks returns the top picks from the latest completed screener run as a JSON
+// array. Use HandleGetTopPicksWithRun for the picks together with the run they came from.
+func (h *Handler) HandleGetTopPicks(w http.ResponseWriter, r *http.Request) {
+	run, picks, ok := h.latestTopPicks(w, r)
+	if !ok {
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.TodaysPicks(run, picks), r)
+		return
+	}
+
+	h.jsonResponse(w, picks)
+}
+
+// HandleGetTopPicksWithRun returns the top picks along with the latest completed screener run
+func (h *Handler) HandleGetTopPicksWithRun(w http.ResponseWriter, r *http.Request) {
+	run, picks, ok := h.latestTopPicks(w, r)
+	if !ok {
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.TodaysPicks(run, picks), r)
+		return
+	}
+
+	h.jsonResponse(w, TopPicksResponse{Run: run, Picks: picks, Count: len(picks)})
+}
+
+// latestTopPicks loads the latest screener run and its top picks, writing the error response
+// and returning false if either is unavailable
+func (h *Handler) latestTopPicks(w http.ResponseWriter, r *http.Request) (*models.ScreenerRun, []models.ScreenerCandidate, bool) {
+	if h.app.Screener() == nil {
+		h.screenerNotConfigured(w, r)
+		return nil, nil, false
+	}
+
+	run, err := h.app.GetLatestScreenerRun()
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return nil, nil, false
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	picks, err := h.app.GetTopPicks()
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return nil, nil, false
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	return run, picks, true
+}
+
+// screenerNotConfigured responds that the screener isn't configured, listing the services
+// it is missing
+func (h *Handler) screenerNotConfigured(w http.ResponseWriter, r *http.Request) {
+	status := h.app.ScreenerStatus()
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.ScreenerNotConfigured(status.MissingServices), r)
+		return
+	}
+	h.jsonErrorWithFields(w, "Screener not configured", http.StatusServiceUnavailable, map[string]interface{}{
+		"missing_services": status.MissingServices,
+	})
+}
+
+// Ensure models are exported for JSON serialization
+var _ = models.Position{}
+var _ = models.Trade{}
+var _ = models.Recommendation{}
+var _ = models.AgentRun{}
+var _ = models.ScreenerRun{}
+
+// HandleGetReconciliationReports returns recent monthly reconciliation reports
+func (h *Handler) HandleGetReconciliationReports(w http.ResponseWriter, r *http.Request) {
+	limit := h.ParseLimitParam(r, 12)
+
+	reports, err := h.app.GetReconciliationReports(limit)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.ReconciliationReports(reports), r)
+		return
+	}
+
+	h.jsonResponse(w, reports)
+}
+
+// HandleGetReconciliationReport returns a single reconciliation report with its discrepancies
+func (h *Handler) HandleGetReconciliationReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.app.GetReconciliationReport(chi.URLParam(r, "id"))
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if report == nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Reconciliation report not found", r)
+			return
+		}
+		h.jsonError(w, "Reconciliation report not found", http.StatusNotFound)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.ReconciliationReportDetail(report), r)
+		return
+	}
+
+	h.jsonResponse(w, report)
+}
+
+// HandleRunReconciliation reconciles a month against the broker. The optional "month"
+// query parameter (YYYY-MM) defaults to the previous calendar month.
+func (h *Handler) HandleRunReconciliation(w http.ResponseWriter, r *http.Request) {
+	var month time.Time
+	if param := r.URL.Query().Get("month"); param != "" {
+		parsed, err := time.Parse("2006-01", param)
+		if err != nil {
+			if isHTMXRequest(r) {
+				h.htmlError(w, "Invalid month, expected YYYY-MM", r)
+				return
+			}
+			h.jsonError(w, "Invalid month, expected YYYY-MM", http.StatusBadRequest)
+			return
+		}
+		month = parsed
+	}
+
+	report, err := h.app.RunReconciliation(month)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		status := http.StatusInternalServerError
+		if errors.Is(err, app.ErrReconciliationUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.HandleGetReconciliationReports(w, r)
+		return
+	}
+
+	h.jsonResponse(w, report)
+}
+
+// HandleGetSettings returns masked API key settings
+func (h *Handler) HandleGetSettings(w http.ResponseWriter, r *http.Request) {
+	settingsStore := h.app.Settings()
+	if settingsStore == nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Settings not available", r)
+			return
+		}
+		h.jsonError(w, "Settings not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	masked := settingsStore.GetMaskedSettings()
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.SettingsForm(masked), r)
+		return
+	}
+
+	h.jsonResponse(w, masked)
+}
+
+// HandleUpdateAPIKey updates a single API key configuration
+func (h *Handler) HandleUpdateAPIKey(w http.ResponseWriter, r *http.Request) {
+	settingsStore := h.app.Settings()
+	if settingsStore == nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Settings not available", r)
+			return
+		}
+		h.jsonError(w, "Settings not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req settings.APIKeyConfig
+	contentType := r.Header.Get("Content-Type")
+	if strings.Contains(contentType, "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			if isHTMXRequest(r) {
+				h.htmlError(w, "Invalid JSON request", r)
+				return
+			}
+			h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
+			return
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			if isHTMXRequest(r) {
+				h.htmlError(w, "Failed to parse form", r)
+				return
+			}
+			h.jsonError(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+		req.ServiceName = settings.ServiceName(r.FormValue("service_name"))
+		req.APIKey = r.FormValue("api_key")
+		req.APISecret = r.FormValue("api_secret")
+		req.BaseURL = r.FormValue("base_url")
+		req.Region = r.FormValue("region")
+		req.ModelID = r.FormValue("model_id")
+	}
+
+	if req.ServiceName == "" {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Service name is required", r)
+			return
+		}
+		h.jsonError(w, "Service name is required", http.StatusBadRequest)
+		return
+	}
+
+	// Check if at least one field has a value to update
+	hasUpdate := req.APIKey != "" || req.APISecret != "" || req.BaseURL != "" || req.Region != "" || req.ModelID != ""
+	if !hasUpdate {
+		// No fields to update - just return current state
+		if isHTMXRequest(r) {
+			masked := settingsStore.GetMaskedSettings()
+			h.htmlResponse(w, partials.SettingsForm(masked), r)
+			return
+		}
+		h.jsonResponse(w, map[string]string{"status": "no changes", "service": string(req.ServiceName)})
+		return
+	}
+
+	// The audit log records which fields changed, never their values
+	var updatedFields []string
+	for _, f := range []struct{ name, value string }{
+		{"api_key", req.APIKey}, {"api_secret", req.APISecret}, {"base_url", req.BaseURL}, {"region", req.Region}, {"model_id", req.ModelID},
+	} {
+		if f.value != "" {
+			updatedFields = append(updatedFields, f.name)
+		}
+	}
+
+	// Merge with existing config to preserve fields not being updated
+	existingConfig := settingsStore.GetAPIKey(req.ServiceName)
+	if existingConfig != nil {
+		if req.APIKey == "" {
+			req.APIKey = existingConfig.APIKey
+		}
+		if req.APISecret == "" {
+			req.APISecret = existingConfig.APISecret
+		}
+		if req.BaseURL == "" {
+			req.BaseURL = existingConfig.BaseURL
+		}
+		if req.Region == "" {
+			req.Region = existingConfig.Region
+		}
+		if req.ModelID == "" {
+			req.ModelID = existingConfig.ModelID
+		}
+	}
+
+	if err := settingsStore.SetAPIKey(&req); err != nil {
 		if isHTMXRequest(r) {
-			h.htmlError(w, "Screener not configured", r)
+			h.htmlError(w, err.Error(), r)
 			return
 		}
-		h.jsonError(w, "Screener not configured", http.StatusServiceUnavailable)
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.audit(r, models.AuditSettingsChanged, "api_keys/"+string(req.ServiceName), map[string]any{"fields": updatedFields})
 
-	limit := h.ParseLimitParam(r, 10)
+	// If FMP API key was updated, reinitialize the screener
+	if req.ServiceName == settings.ServiceFMP && req.APIKey != "" {
+		if err := h.app.InitializeScreenerWithFMPKey(req.APIKey); err != nil {
+			logger.Warn("failed to reinitialize screener with new FMP key", "error", err)
+		}
+	}
 
-	runs, err := h.app.GetScreenerRunHistory(limit)
+	if isHTMXRequest(r) {
+		masked := settingsStore.GetMaskedSettings()
+		h.htmlResponse(w, partials.SettingsForm(masked), r)
+		return
+	}
+
+	h.jsonResponse(w, map[string]string{"status": "saved", "service": string(req.ServiceName)})
+}
+
+// HandleTestAPIKey tests if an API key is valid
+func (h *Handler) HandleTestAPIKey(w http.ResponseWriter, r *http.Request) {
+	service := chi.URLParam(r, "service")
+	if service == "" {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Service name is required", r)
+			return
+		}
+		h.jsonError(w, "Service name is required", http.StatusBadRequest)
+		return
+	}
+
+	settingsStore := h.app.Settings()
+	if settingsStore == nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Settings not available", r)
+			return
+		}
+		h.jsonError(w, "Settings not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	serviceName := settings.ServiceName(service)
+	config := settingsStore.GetAPIKey(serviceName)
+	if config == nil {
+		if isHTMXRequest(r) {
+			h.htmlResponse(w, partials.ServiceStatus(serviceName, false, "Not configured"), r)
+			return
+		}
+		h.jsonError(w, "Service not configured", http.StatusNotFound)
+		return
+	}
+
+	validator := settings.NewValidator()
+	result, err := validator.ValidateAPIKey(r.Context(), config)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlResponse(w, partials.ServiceStatus(serviceName, false, err.Error()), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.ServiceStatus(serviceName, result.Valid, result.Message), r)
+		return
+	}
+
+	h.jsonResponse(w, result)
+}
+
+// HandleDeleteAPIKey removes an API key configuration
+func (h *Handler) HandleDeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	service := chi.URLParam(r, "service")
+	if service == "" {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Service name is required", r)
+			return
+		}
+		h.jsonError(w, "Service name is required", http.StatusBadRequest)
+		return
+	}
+
+	settingsStore := h.app.Settings()
+	if settingsStore == nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, "Settings not available", r)
+			return
+		}
+		h.jsonError(w, "Settings not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	serviceName := settings.ServiceName(service)
+	if err := settingsStore.DeleteAPIKey(serviceName); err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, models.AuditSettingsChanged, "api_keys/"+service, map[string]any{"deleted": true})
+
+	if isHTMXRequest(r) {
+		masked := settingsStore.GetMaskedSettings()
+		h.htmlResponse(w, partials.SettingsForm(masked), r)
+		return
+	}
+
+	h.jsonResponse(w, map[string]string{"status": "deleted", "service": service})
+}
+
+// ScreenerPresetsResponse lists the built-in screener presets and the user's saved ones
+type ScreenerPresetsResponse struct {
+	BuiltIn []string                `json:"built_in"`
+	Saved   []models.ScreenerPreset `json:"saved"`
+}
+
+// HandleGetScreenerPresets returns the presets a screener run can use
+func (h *Handler) HandleGetScreenerPresets(w http.ResponseWriter, r *http.Request) {
+	presets, err := h.app.GetScreenerPresets()
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.jsonResponse(w, ScreenerPresetsResponse{BuiltIn: models.ScreenerPresets, Saved: presets})
+}
+
+// HandleSaveScreenerPreset saves a named set of screener criteria, replacing any preset
+// with the same name. Run it with POST /api/screener/run?preset={name}.
+func (h *Handler) HandleSaveScreenerPreset(w http.ResponseWriter, r *http.Request) {
+	var preset models.ScreenerPreset
+	if err := json.NewDecoder(r.Body).Decode(&preset); err != nil {
+		h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+	preset.Name = strings.ToLower(strings.TrimSpace(preset.Name))
+	preset.Sector = strings.TrimSpace(preset.Sector)
+	if err := preset.Validate(); err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.app.SaveScreenerPreset(&preset); err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.jsonResponse(w, preset)
+}
+
+// HandleDeleteScreenerPreset removes a saved screener preset
+func (h *Handler) HandleDeleteScreenerPreset(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	deleted, err := h.app.DeleteScreenerPreset(name)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		h.jsonError(w, "Screener preset not found", http.StatusNotFound)
+		return
+	}
+	h.jsonResponse(w, map[string]string{"status": "deleted", "name": name})
+}
+
+// SymbolListEntryRequest adds a symbol to the blocklist or allowlist
+type SymbolListEntryRequest struct {
+	List   models.SymbolListType `json:"list"`
+	Symbol string                `json:"symbol"`
+	Reason string                `json:"reason"`
+}
+
+// HandleGetSymbolLists returns the blocklist and allowlist
+func (h *Handler) HandleGetSymbolLists(w http.ResponseWriter, r *http.Request) {
+	lists, err := h.app.GetSymbolLists()
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.SymbolLists(lists), r)
+		return
+	}
+
+	h.jsonResponse(w, lists)
+}
+
+// HandleAddSymbolListEntry adds a symbol to the blocklist or allowlist
+func (h *Handler) HandleAddSymbolListEntry(w http.ResponseWriter, r *http.Request) {
+	var req SymbolListEntryRequest
+	if strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
+			return
+		}
+	} else {
+		_ = r.ParseForm()
+		req.List = models.SymbolListType(r.FormValue("list"))
+		req.Symbol = r.FormValue("symbol")
+		req.Reason = r.FormValue("reason")
+	}
+
+	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if err := validateSymbolFormat(req.Symbol); err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !req.List.Valid() {
+		msg := fmt.Sprintf("unknown symbol list %q, expected block or allow", req.List)
+		if isHTMXRequest(r) {
+			h.htmlError(w, msg, r)
+			return
+		}
+		h.jsonError(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	entry, err := h.app.AddSymbolListEntry(req.List, req.Symbol, strings.TrimSpace(req.Reason))
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, models.AuditSettingsChanged, "symbol_lists/"+string(req.List), map[string]any{"added": req.Symbol})
+
+	if isHTMXRequest(r) {
+		h.HandleGetSymbolLists(w, r)
+		return
+	}
+
+	h.jsonResponse(w, entry)
+}
+
+// HandleRemoveSymbolListEntry removes a symbol from the blocklist or allowlist
+func (h *Handler) HandleRemoveSymbolListEntry(w http.ResponseWriter, r *http.Request) {
+	list := models.SymbolListType(chi.URLParam(r, "list"))
+	symbol := strings.ToUpper(chi.URLParam(r, "symbol"))
+
+	if !list.Valid() {
+		msg := fmt.Sprintf("unknown symbol list %q, expected block or allow", list)
+		if isHTMXRequest(r) {
+			h.htmlError(w, msg, r)
+			return
+		}
+		h.jsonError(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.app.RemoveSymbolListEntry(list, symbol); err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, models.AuditSettingsChanged, "symbol_lists/"+string(list), map[string]any{"removed": symbol})
+
+	if isHTMXRequest(r) {
+		h.HandleGetSymbolLists(w, r)
+		return
+	}
+
+	h.jsonResponse(w, map[string]string{"status": "removed", "list": string(list), "symbol": symbol})
+}
+
+// maxWatchlistImportBytes caps the size of an uploaded ticker list
+const maxWatchlistImportBytes = 1 << 20
+
+// WatchlistImportRequest imports a ticker list as a new watchlist
+type WatchlistImportRequest struct {
+	Name    string `json:"name"`
+	Data    string `json:"data"`    // CSV or plain-text ticker list
+	Analyze bool   `json:"analyze"` // Queue analysis for every imported symbol
+}
+
+// HandleImportWatchlist creates a watchlist from a CSV or text ticker list
+func (h *Handler) HandleImportWatchlist(w http.ResponseWriter, r *http.Request) {
+	req, err := parseWatchlistImport(w, r)
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.app.ImportWatchlist(strings.TrimSpace(req.Name), req.Data, req.Analyze)
 	if err != nil {
 		if isHTMXRequest(r) {
 			h.htmlError(w, err.Error(), r)
 			return
 		}
-		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if errors.Is(err, models.ErrInvalidWatchlistImport) {
+			status = http.StatusBadRequest
+		}
+		h.jsonError(w, err.Error(), status)
 		return
 	}
 
 	if isHTMXRequest(r) {
-		h.htmlResponse(w, partials.ScreenerRunsList(runs), r)
+		h.htmlResponse(w, partials.WatchlistImportResult(result), r)
 		return
 	}
 
-	h.jsonResponse(w, runs)
+	h.jsonResponse(w, result)
 }
 
-// HandleGetScreenerRun returns a specific screener run by ID
-func (h *Handler) HandleGetScreenerRun(w http.ResponseWriter, r *http.Request) {
-	if h.app.Screener() == nil {
-		if isHTMXRequest(r) {
-			h.htmlError(w, "Screener not configured", r)
-			return
+// parseWatchlistImport reads an import from a JSON body, a form with a "tickers" field or
+// "file" upload, or a raw CSV/text body with name and analyze query parameters
+func parseWatchlistImport(w http.ResponseWriter, r *http.Request) (WatchlistImportRequest, error) {
+	var req WatchlistImportRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxWatchlistImportBytes)
+	contentType := r.Header.Get("Content-Type")
+
+	switch {
+	case strings.Contains(contentType, "application/json"):
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return req, errors.New("invalid JSON request")
 		}
-		h.jsonError(w, "Screener not configured", http.StatusServiceUnavailable)
-		return
+	case strings.Contains(contentType, "multipart/form-data"), strings.Contains(contentType, "application/x-www-form-urlencoded"):
+		if err := r.ParseMultipartForm(maxWatchlistImportBytes); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return req, errors.New("failed to parse form")
+		}
+		req.Name = r.FormValue("name")
+		req.Data = r.FormValue("tickers")
+		req.Analyze = r.FormValue("analyze") == "true"
+		if file, _, err := r.FormFile("file"); err == nil {
+			defer file.Close()
+			data, err := io.ReadAll(file)
+			if err != nil {
+				return req, errors.New("failed to read uploaded file")
+			}
+			req.Data += "\n" + string(data)
+		}
+	default:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return req, errors.New("failed to read request body")
+		}
+		req.Data = string(data)
+		req.Name = r.URL.Query().Get("name")
+		req.Analyze = r.URL.Query().Get("analyze") == "true"
 	}
 
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		if isHTMXRequest(r) {
-			h.htmlError(w, "Missing screener run ID", r)
-			return
-		}
-		h.jsonError(w, "Missing screener run ID", http.StatusBadRequest)
-		return
+	if strings.TrimSpace(req.Data) == "" {
+		return req, errors.New("no tickers provided")
 	}
+	return req, nil
+}
 
-	run, err := h.app.GetScreenerRun(id)
+// HandleGetWatchlists returns the imported watchlists
+func (h *Handler) HandleGetWatchlists(w http.ResponseWriter, r *http.Request) {
+	watchlists, err := h.app.GetWatchlists()
 	if err != nil {
 		if isHTMXRequest(r) {
 			h.htmlError(w, err.Error(), r)
@@ -523,36 +2611,27 @@ func (h *Handler) HandleGetScreenerRun(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if run == nil {
-		if isHTMXRequest(r) {
-			h.htmlError(w, "Screener run not found", r)
-			return
-		}
-		h.jsonError(w, "Screener run not found", http.StatusNotFound)
-		return
-	}
-
 	if isHTMXRequest(r) {
-		h.htmlResponse(w, partials.ScreenerRunResult(run), r)
+		h.htmlResponse(w, partials.Watchlists(watchlists), r)
 		return
 	}
 
-	h.jsonResponse(w, run)
+	h.jsonResponse(w, watchlists)
 }
 
-// HandleGetTopPicks returns the top picks from the latest completed screener run
-func (h *Handler) HandleGetTopPicks(w http.ResponseWriter, r *http.Request) {
-	if h.app.Screener() == nil {
-		if isHTMXRequest(r) {
-			status := h.app.ScreenerStatus()
-			h.htmlResponse(w, partials.ScreenerNotConfigured(status.MissingServices), r)
-			return
+// maxAPIUsageDays bounds the window the usage endpoint reports on
+const maxAPIUsageDays = 365
+
+// HandleGetAPIUsage returns outbound API calls per provider and endpoint over the last ?days=N days (default 30)
+func (h *Handler) HandleGetAPIUsage(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		if d, err := strconv.Atoi(daysStr); err == nil && d > 0 {
+			days = min(d, maxAPIUsageDays)
 		}
-		h.jsonError(w, "Screener not configured", http.StatusServiceUnavailable)
-		return
 	}
 
-	run, err := h.app.GetLatestScreenerRun()
+	report, err := h.app.GetAPIUsage(days)
 	if err != nil {
 		if isHTMXRequest(r) {
 			h.htmlError(w, err.Error(), r)
@@ -562,7 +2641,18 @@ func (h *Handler) HandleGetTopPicks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	picks, err := h.app.GetTopPicks()
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.APIUsage(report), r)
+		return
+	}
+
+	h.jsonResponse(w, report)
+}
+
+// HandleDataHealth runs the database integrity checks and reports table sizes and
+// cache statistics. POST fixes the issues that are safe to repair before reporting.
+func (h *Handler) HandleDataHealth(w http.ResponseWriter, r *http.Request) {
+	report, err := h.app.DataHealth(r.Method == http.MethodPost)
 	if err != nil {
 		if isHTMXRequest(r) {
 			h.htmlError(w, err.Error(), r)
@@ -573,240 +2663,264 @@ func (h *Handler) HandleGetTopPicks(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if isHTMXRequest(r) {
-		h.htmlResponse(w, partials.TodaysPicks(run, picks), r)
+		h.htmlResponse(w, partials.DataHealth(report), r)
 		return
 	}
 
-	h.jsonResponse(w, picks)
+	h.jsonResponse(w, report)
 }
 
-// Ensure models are exported for JSON serialization
-var _ = models.Position{}
-var _ = models.Trade{}
-var _ = models.Recommendation{}
-var _ = models.AgentRun{}
-var _ = models.ScreenerRun{}
+// LogLevelRequest sets a module's log level; level "inherit" makes the module follow the
+// default level again
+type LogLevelRequest struct {
+	Module string `json:"module"`
+	Level  string `json:"level"`
+}
 
-// HandleGetSettings returns masked API key settings
-func (h *Handler) HandleGetSettings(w http.ResponseWriter, r *http.Request) {
-	settingsStore := h.app.Settings()
-	if settingsStore == nil {
-		if isHTMXRequest(r) {
-			h.htmlError(w, "Settings not available", r)
+// HandleGetLogLevels returns the default log level and every module's level
+func (h *Handler) HandleGetLogLevels(w http.ResponseWriter, r *http.Request) {
+	h.jsonResponse(w, observability.Levels())
+}
+
+// HandleSetLogLevel changes a module's log level until restart
+func (h *Handler) HandleSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req LogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+	req.Module = strings.ToLower(strings.TrimSpace(req.Module))
+	req.Level = strings.ToLower(strings.TrimSpace(req.Level))
+	if req.Module == "" {
+		req.Module = observability.DefaultModule
+	}
+
+	var err error
+	if req.Level == "inherit" && req.Module != observability.DefaultModule {
+		err = observability.ResetLevel(req.Module)
+	} else {
+		level, parseErr := observability.ParseLevel(req.Level)
+		if parseErr != nil {
+			h.jsonError(w, parseErr.Error(), http.StatusBadRequest)
 			return
 		}
-		h.jsonError(w, "Settings not available", http.StatusServiceUnavailable)
+		err = observability.SetLevel(req.Module, level)
+	}
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	masked := settingsStore.GetMaskedSettings()
+	logger.Info("log level changed", "target_module", req.Module, "level", req.Level)
+	h.audit(r, models.AuditSettingsChanged, "log_level", map[string]any{"module": req.Module, "level": req.Level})
+	h.jsonResponse(w, observability.Levels())
+}
 
-	if isHTMXRequest(r) {
-		h.htmlResponse(w, partials.SettingsForm(masked), r)
+// maxDiagnosticBundleBytes caps the size of an imported diagnostic bundle
+const maxDiagnosticBundleBytes = 20 << 20
+
+// HandleExportDiagnostics downloads a sanitized diagnostic bundle to attach to a bug report
+func (h *Handler) HandleExportDiagnostics(w http.ResponseWriter, r *http.Request) {
+	bundle, err := h.app.ExportDiagnostics()
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-
-	h.jsonResponse(w, masked)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="trade-machine-diagnostics-%s.json"`, bundle.CreatedAt.UTC().Format("20060102T150405Z")))
+	h.jsonResponse(w, bundle)
 }
 
-// HandleUpdateAPIKey updates a single API key configuration
-func (h *Handler) HandleUpdateAPIKey(w http.ResponseWriter, r *http.Request) {
-	settingsStore := h.app.Settings()
-	if settingsStore == nil {
-		if isHTMXRequest(r) {
-			h.htmlError(w, "Settings not available", r)
-			return
-		}
-		h.jsonError(w, "Settings not available", http.StatusServiceUnavailable)
+// HandleImportDiagnostics loads a diagnostic bundle from another install, to reproduce
+// its failures locally
+func (h *Handler) HandleImportDiagnostics(w http.ResponseWriter, r *http.Request) {
+	var bundle models.DiagnosticBundle
+	r.Body = http.MaxBytesReader(w, r.Body, maxDiagnosticBundleBytes)
+	if err := json.NewDecoder(r.Body).Decode(&bundle); err != nil {
+		h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
 		return
 	}
 
-	var req settings.APIKeyConfig
-	contentType := r.Header.Get("Content-Type")
-	if strings.Contains(contentType, "application/json") {
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			if isHTMXRequest(r) {
-				h.htmlError(w, "Invalid JSON request", r)
-				return
-			}
-			h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
-			return
-		}
-	} else {
-		if err := r.ParseForm(); err != nil {
-			if isHTMXRequest(r) {
-				h.htmlError(w, "Failed to parse form", r)
-				return
-			}
-			h.jsonError(w, "Failed to parse form", http.StatusBadRequest)
-			return
+	result, err := h.app.ImportDiagnostics(&bundle)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, models.ErrUnsupportedBundle):
+			status = http.StatusBadRequest
+		case errors.Is(err, app.ErrDiagnosticImportUnavailable):
+			status = http.StatusServiceUnavailable
 		}
-		req.ServiceName = settings.ServiceName(r.FormValue("service_name"))
-		req.APIKey = r.FormValue("api_key")
-		req.APISecret = r.FormValue("api_secret")
-		req.BaseURL = r.FormValue("base_url")
-		req.Region = r.FormValue("region")
-		req.ModelID = r.FormValue("model_id")
+		h.jsonError(w, err.Error(), status)
+		return
 	}
+	h.jsonResponse(w, result)
+}
 
-	if req.ServiceName == "" {
+// HandleGetProviderAlerts returns alerts raised when a provider's circuit breaker opened or
+// its API quota ran out. Only active alerts are returned unless ?all=true.
+func (h *Handler) HandleGetProviderAlerts(w http.ResponseWriter, r *http.Request) {
+	alerts, err := h.app.GetProviderAlerts(r.URL.Query().Get("all") == "true")
+	if err != nil {
 		if isHTMXRequest(r) {
-			h.htmlError(w, "Service name is required", r)
+			h.htmlError(w, err.Error(), r)
 			return
 		}
-		h.jsonError(w, "Service name is required", http.StatusBadRequest)
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Check if at least one field has a value to update
-	hasUpdate := req.APIKey != "" || req.APISecret != "" || req.BaseURL != "" || req.Region != "" || req.ModelID != ""
-	if !hasUpdate {
-		// No fields to update - just return current state
-		if isHTMXRequest(r) {
-			masked := settingsStore.GetMaskedSettings()
-			h.htmlResponse(w, partials.SettingsForm(masked), r)
-			return
-		}
-		h.jsonResponse(w, map[string]string{"status": "no changes", "service": string(req.ServiceName)})
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.ProviderAlerts(alerts), r)
 		return
 	}
 
-	// Merge with existing config to preserve fields not being updated
-	existingConfig := settingsStore.GetAPIKey(req.ServiceName)
-	if existingConfig != nil {
-		if req.APIKey == "" {
-			req.APIKey = existingConfig.APIKey
-		}
-		if req.APISecret == "" {
-			req.APISecret = existingConfig.APISecret
-		}
-		if req.BaseURL == "" {
-			req.BaseURL = existingConfig.BaseURL
-		}
-		if req.Region == "" {
-			req.Region = existingConfig.Region
-		}
-		if req.ModelID == "" {
-			req.ModelID = existingConfig.ModelID
-		}
+	if alerts == nil {
+		alerts = []models.ProviderAlert{}
 	}
+	h.jsonResponse(w, alerts)
+}
 
-	if err := settingsStore.SetAPIKey(&req); err != nil {
+// HandleDismissProviderAlert hides a provider alert from the active list
+func (h *Handler) HandleDismissProviderAlert(w http.ResponseWriter, r *http.Request) {
+	if err := h.app.DismissProviderAlert(chi.URLParam(r, "id")); err != nil {
 		if isHTMXRequest(r) {
 			h.htmlError(w, err.Error(), r)
 			return
 		}
-		h.jsonError(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// If FMP API key was updated, reinitialize the screener
-	if req.ServiceName == settings.ServiceFMP && req.APIKey != "" {
-		if err := h.app.InitializeScreenerWithFMPKey(req.APIKey); err != nil {
-			observability.Warn("failed to reinitialize screener with new FMP key", "error", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, models.ErrProviderAlertNotFound) {
+			status = http.StatusNotFound
 		}
+		h.jsonError(w, err.Error(), status)
+		return
 	}
 
 	if isHTMXRequest(r) {
-		masked := settingsStore.GetMaskedSettings()
-		h.htmlResponse(w, partials.SettingsForm(masked), r)
+		// Swap in the remaining active alerts
+		h.HandleGetProviderAlerts(w, r)
 		return
 	}
 
-	h.jsonResponse(w, map[string]string{"status": "saved", "service": string(req.ServiceName)})
+	h.jsonResponse(w, map[string]string{"status": "dismissed"})
 }
 
-// HandleTestAPIKey tests if an API key is valid
-func (h *Handler) HandleTestAPIKey(w http.ResponseWriter, r *http.Request) {
-	service := chi.URLParam(r, "service")
-	if service == "" {
+// HandleGetOnboardingStatus returns progress through the first-run setup wizard
+func (h *Handler) HandleGetOnboardingStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.app.OnboardingStatus()
+	if err != nil {
 		if isHTMXRequest(r) {
-			h.htmlError(w, "Service name is required", r)
+			h.htmlError(w, err.Error(), r)
 			return
 		}
-		h.jsonError(w, "Service name is required", http.StatusBadRequest)
+		h.jsonError(w, err.Error(), http.StatusServiceUnavailable)
 		return
 	}
 
-	settingsStore := h.app.Settings()
-	if settingsStore == nil {
-		if isHTMXRequest(r) {
-			h.htmlError(w, "Settings not available", r)
-			return
-		}
-		h.jsonError(w, "Settings not available", http.StatusServiceUnavailable)
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.Onboarding(status.OnboardingState, status.Strategies, nil, nil), r)
 		return
 	}
 
-	serviceName := settings.ServiceName(service)
-	config := settingsStore.GetAPIKey(serviceName)
-	if config == nil {
-		if isHTMXRequest(r) {
-			h.htmlResponse(w, partials.ServiceStatus(serviceName, false, "Not configured"), r)
+	h.jsonResponse(w, status)
+}
+
+// HandleOnboardingStep runs one step of the setup wizard. Accepts JSON (see
+// app.OnboardingStepRequest) or a form with step, strategy and skip fields.
+func (h *Handler) HandleOnboardingStep(w http.ResponseWriter, r *http.Request) {
+	var req app.OnboardingStepRequest
+	if strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			if isHTMXRequest(r) {
+				h.htmlError(w, "Invalid JSON request", r)
+				return
+			}
+			h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
 			return
 		}
-		h.jsonError(w, "Service not configured", http.StatusNotFound)
-		return
+	} else {
+		req.Step = settings.OnboardingStep(r.FormValue("step"))
+		req.Strategy = r.FormValue("strategy")
+		req.Skip = r.FormValue("skip") == "true"
 	}
 
-	validator := settings.NewValidator()
-	result, err := validator.ValidateAPIKey(r.Context(), config)
+	result, err := h.app.CompleteOnboardingStep(r.Context(), req)
 	if err != nil {
 		if isHTMXRequest(r) {
-			h.htmlResponse(w, partials.ServiceStatus(serviceName, false, err.Error()), r)
+			h.htmlError(w, err.Error(), r)
 			return
 		}
-		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, app.ErrInvalidOnboardingStep):
+			status = http.StatusBadRequest
+		case errors.Is(err, app.ErrSettingsUnavailable):
+			status = http.StatusServiceUnavailable
+		}
+		h.jsonError(w, err.Error(), status)
 		return
 	}
 
 	if isHTMXRequest(r) {
-		h.htmlResponse(w, partials.ServiceStatus(serviceName, result.Valid, result.Message), r)
+		h.htmlResponse(w, partials.Onboarding(result.Status.OnboardingState, result.Status.Strategies, result.Validation, result.Demo), r)
 		return
 	}
 
 	h.jsonResponse(w, result)
 }
 
-// HandleDeleteAPIKey removes an API key configuration
-func (h *Handler) HandleDeleteAPIKey(w http.ResponseWriter, r *http.Request) {
-	service := chi.URLParam(r, "service")
-	if service == "" {
-		if isHTMXRequest(r) {
-			h.htmlError(w, "Service name is required", r)
-			return
-		}
-		h.jsonError(w, "Service name is required", http.StatusBadRequest)
+// HandleGetCompliance returns the disclaimer and whether it has been accepted
+func (h *Handler) HandleGetCompliance(w http.ResponseWriter, r *http.Request) {
+	status := h.app.ComplianceStatus()
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.ComplianceNotice(status.Disclaimer, status.RequireAcknowledgment && !status.Acknowledged), r)
 		return
 	}
 
-	settingsStore := h.app.Settings()
-	if settingsStore == nil {
-		if isHTMXRequest(r) {
-			h.htmlError(w, "Settings not available", r)
+	h.jsonResponse(w, status)
+}
+
+// HandleAcknowledgeDisclaimer records that the user accepted the disclaimer. Accepts JSON
+// {"version": "..."} or a form with a version field.
+func (h *Handler) HandleAcknowledgeDisclaimer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Version string `json:"version"`
+	}
+	if strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			if isHTMXRequest(r) {
+				h.htmlError(w, "Invalid JSON request", r)
+				return
+			}
+			h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
 			return
 		}
-		h.jsonError(w, "Settings not available", http.StatusServiceUnavailable)
-		return
+	} else {
+		req.Version = r.FormValue("version")
 	}
 
-	serviceName := settings.ServiceName(service)
-	if err := settingsStore.DeleteAPIKey(serviceName); err != nil {
+	status, err := h.app.AcknowledgeDisclaimer(req.Version)
+	if err != nil {
 		if isHTMXRequest(r) {
 			h.htmlError(w, err.Error(), r)
 			return
 		}
-		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, app.ErrDisclaimerVersionMismatch):
+			code = http.StatusConflict
+		case errors.Is(err, app.ErrSettingsUnavailable):
+			code = http.StatusServiceUnavailable
+		}
+		h.jsonError(w, err.Error(), code)
 		return
 	}
 
 	if isHTMXRequest(r) {
-		masked := settingsStore.GetMaskedSettings()
-		h.htmlResponse(w, partials.SettingsForm(masked), r)
+		h.htmlResponse(w, partials.ComplianceNotice(status.Disclaimer, false), r)
 		return
 	}
 
-	h.jsonResponse(w, map[string]string{"status": "deleted", "service": service})
+	h.jsonResponse(w, status)
 }
 
 // HandleResetSettings removes all API key configurations (for E2E testing)
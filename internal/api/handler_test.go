@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"mime"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -12,17 +13,24 @@ import (
 	"trade-machine/config"
 	"trade-machine/internal/app"
 	"trade-machine/internal/settings"
+	"trade-machine/models"
+	"trade-machine/observability"
 	"trade-machine/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // mockSettingsRepository implements settings.RepositoryInterface for testing
 type mockSettingsRepository struct {
-	apiKeys map[string]*settings.APIKeyModel
+	apiKeys     map[string]*settings.APIKeyModel
+	appSettings map[string][]byte
 }
 
 func newMockSettingsRepository() *mockSettingsRepository {
 	return &mockSettingsRepository{
-		apiKeys: make(map[string]*settings.APIKeyModel),
+		apiKeys:     make(map[string]*settings.APIKeyModel),
+		appSettings: make(map[string][]byte),
 	}
 }
 
@@ -52,6 +60,15 @@ func (m *mockSettingsRepository) DeleteAPIKey(ctx context.Context, serviceName s
 	return nil
 }
 
+func (m *mockSettingsRepository) GetAppSetting(ctx context.Context, key string) ([]byte, error) {
+	return m.appSettings[key], nil
+}
+
+func (m *mockSettingsRepository) UpsertAppSetting(ctx context.Context, key string, value []byte) error {
+	m.appSettings[key] = value
+	return nil
+}
+
 // testConfig returns a test configuration
 func testConfig() *config.Config {
 	return config.NewTestConfig()
@@ -164,9 +181,45 @@ func TestHandler_Health(t *testing.T) {
 		if status, ok := response["status"].(string); !ok || status != "ok" {
 			t.Errorf("expected status ok, got %v", response["status"])
 		}
+		if _, ok := response["backup"]; ok {
+			t.Error("expected no backup status when backups are disabled")
+		}
+	})
+
+	t.Run("failed backup degrades health", func(t *testing.T) {
+		a := testApp(nil)
+		a.SetBackupManager(&stubBackupManager{status: &models.BackupStatus{LastError: "backup storage not configured"}})
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		var response struct {
+			Status string              `json:"status"`
+			Backup models.BackupStatus `json:"backup"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if response.Status != "degraded" || response.Backup.LastError == "" {
+			t.Errorf("expected degraded status with the backup error, got %+v", response)
+		}
 	})
 }
 
+// stubBackupManager reports a fixed backup status
+type stubBackupManager struct {
+	status *models.BackupStatus
+}
+
+func (s *stubBackupManager) Run(ctx context.Context) {}
+
+func (s *stubBackupManager) Status() *models.BackupStatus {
+	return s.status
+}
+
 func TestHandler_AnalyzeStock(t *testing.T) {
 	t.Run("missing symbol", func(t *testing.T) {
 		a := testApp(nil)
@@ -191,20 +244,926 @@ func TestHandler_AnalyzeStock(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
 
-		router.ServeHTTP(w, req)
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+}
+
+func TestHandler_GetRecommendations(t *testing.T) {
+	t.Run("database not initialized", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/recommendations", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+
+	t.Run("with limit parameter", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/recommendations?limit=10", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+}
+
+func TestHandler_ApproveRecommendation(t *testing.T) {
+	t.Run("invalid UUID", func(t *testing.T) {
+		ctx := context.Background()
+		connString := "host=localhost port=5432 user=postgres password=postgres dbname=trademachine_test sslmode=disable"
+		repo, err := repository.NewRepository(ctx, connString)
+		if err != nil {
+			t.Skip("database not available")
+		}
+		defer repo.Close()
+
+		a := testApp(repo)
+		a.Startup(ctx)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/recommendations/invalid-uuid/approve", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/recommendations/"+uuid.New().String()+"/approve?version=stale", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+}
+
+// recommendationRepo returns a fixed recommendation lookup result and has no analysis jobs
+type recommendationRepo struct {
+	app.RepositoryInterface
+	rec *models.Recommendation
+	err error
+}
+
+func (r *recommendationRepo) GetRecommendation(ctx context.Context, id uuid.UUID) (*models.Recommendation, error) {
+	return r.rec, r.err
+}
+
+func (r *recommendationRepo) FailInterruptedAnalysisJobs(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestHandler_EditRecommendation(t *testing.T) {
+	path := "/api/recommendations/" + uuid.New().String()
+
+	for _, tt := range []struct {
+		name string
+		repo *recommendationRepo
+		want int
+	}{
+		{"not found", &recommendationRepo{}, http.StatusNotFound},
+		{"lookup failed", &recommendationRepo{err: errors.New("connection reset")}, http.StatusInternalServerError},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// The stub repository only answers lookups, so no background jobs may start
+			cfg := testConfig()
+			cfg.RecommendationExpiry.Enabled = false
+			a := app.New(cfg, tt.repo, nil, nil)
+			a.Startup(context.Background())
+			router := testRouter(a)
+
+			req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(`{"quantity": "5"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+
+	t.Run("database not initialized", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+
+		req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(`{"quantity": "5"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+
+	t.Run("invalid form values", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+
+		for _, body := range []string{"quantity=lots", "limit_price=abc", "version=stale"} {
+			req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", body, w.Code)
+			}
+		}
+	})
+}
+
+func TestHandler_SplitRecommendation(t *testing.T) {
+	path := "/api/recommendations/" + uuid.New().String()
+
+	t.Run("database not initialized", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+
+		req := httptest.NewRequest(http.MethodPost, path+"/split", strings.NewReader(`{"trigger": "time", "count": 3, "interval_minutes": 30}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+
+	t.Run("invalid form values", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+
+		for _, body := range []string{"trigger=time&count=three", "trigger=price&price_levels=100,abc", "version=stale"} {
+			req := httptest.NewRequest(http.MethodPost, path+"/split", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", body, w.Code)
+			}
+		}
+	})
+
+	t.Run("tranches without database", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+
+		for _, method := range []string{http.MethodGet, http.MethodDelete} {
+			req := httptest.NewRequest(method, path+"/tranches", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusInternalServerError {
+				t.Errorf("%s: expected status 500, got %d", method, w.Code)
+			}
+		}
+	})
+}
+
+func TestParseSplitPlan(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/?version=4", strings.NewReader("trigger=price&price_levels=101.5, 98"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	plan, err := parseSplitPlan(req)
+	if err != nil {
+		t.Fatalf("parseSplitPlan() error = %v", err)
+	}
+	if plan.Trigger != models.SplitTriggerPrice || len(plan.PriceLevels) != 2 || !plan.PriceLevels[1].Equal(decimal.NewFromInt(98)) || plan.Version != 4 {
+		t.Errorf("parseSplitPlan() = %+v, want a price plan at 101.5 and 98 for version 4", plan)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"trigger": "time", "count": 3, "interval_minutes": 15, "version": 2}`))
+	req.Header.Set("Content-Type", "application/json")
+	if plan, err = parseSplitPlan(req); err != nil {
+		t.Fatalf("parseSplitPlan() error = %v", err)
+	}
+	if plan.Trigger != models.SplitTriggerTime || plan.Count != 3 || plan.IntervalMinutes != 15 || plan.Version != 2 {
+		t.Errorf("parseSplitPlan() = %+v, want 3 tranches 15 minutes apart for version 2", plan)
+	}
+}
+
+func TestHandler_GetPositions(t *testing.T) {
+	t.Run("database not initialized", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/positions", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+}
+
+func TestHandler_GetTrades(t *testing.T) {
+	t.Run("database not initialized", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/trades", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+
+	t.Run("with limit parameter", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/trades?limit=25", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+}
+
+func TestHandler_GetTrade(t *testing.T) {
+	t.Run("database not initialized", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/trades/"+uuid.New().String(), nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+}
+
+func TestHandler_GetActivity(t *testing.T) {
+	t.Run("database not initialized", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/activity", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+
+	t.Run("invalid before cursor", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/activity?before=yesterday", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestHandler_ExecuteRecommendation(t *testing.T) {
+	t.Run("database not initialized", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/recommendations/"+uuid.New().String()+"/execute", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/recommendations/"+uuid.New().String()+"/execute?version=0", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestHandler_PreviewRecommendation(t *testing.T) {
+	t.Run("database not initialized", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/recommendations/"+uuid.New().String()+"/preview", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+
+	t.Run("htmx error", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/recommendations/"+uuid.New().String()+"/preview?intent=approve", nil)
+		req.Header.Set("HX-Request", "true")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if !strings.Contains(w.Body.String(), "database not initialized") {
+			t.Errorf("expected the error in the response, got %s", w.Body.String())
+		}
+	})
+}
+
+func TestHandler_GetRecommendationEvents(t *testing.T) {
+	t.Run("database not initialized", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/recommendations/"+uuid.New().String()+"/events", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+}
+
+func TestHandler_SymbolLists(t *testing.T) {
+	t.Run("database not initialized", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/settings/symbol-lists", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+
+	t.Run("invalid symbol", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		body := strings.NewReader(`{"list": "block", "symbol": "BAD SYMBOL!"}`)
+		req := httptest.NewRequest(http.MethodPost, "/api/settings/symbol-lists", body)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("unknown list", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/settings/symbol-lists/watch/AAPL", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestHandler_ScreenerPresets(t *testing.T) {
+	t.Run("database not initialized", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/screener/presets", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+
+	t.Run("invalid preset", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+
+		body := strings.NewReader(`{"name": "growth", "pe_ratio_max": 30}`)
+		req := httptest.NewRequest(http.MethodPost, "/api/screener/presets", body)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for a built-in name, got %d", w.Code)
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/screener/presets", strings.NewReader("{"))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestHandler_PortfolioReviews(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/api/portfolio/analyze"},
+		{http.MethodGet, "/api/portfolio/reviews"},
+		{http.MethodGet, "/api/portfolio/reviews/550e8400-e29b-41d4-a716-446655440000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			router := testRouter(testApp(nil))
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusInternalServerError {
+				t.Errorf("expected status 500 without a database, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_ImportWatchlist(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{"empty body", "text/csv", "", http.StatusBadRequest},
+		{"invalid JSON", "application/json", "{", http.StatusBadRequest},
+		{"no tickers in form", "application/x-www-form-urlencoded", "name=Tech&tickers=", http.StatusBadRequest},
+		{"database not initialized", "text/plain", "AAPL\nMSFT", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := testRouter(testApp(nil))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/watchlists/import?name=Tech", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_GetPortfolioAsOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{"missing date", "", http.StatusBadRequest},
+		{"malformed date", "?date=06/30/2024", http.StatusBadRequest},
+		{"future date", "?date=2999-01-01", http.StatusBadRequest},
+		{"database not initialized", "?date=2024-06-30", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := testRouter(testApp(nil))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/portfolio/asof"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_GetAttribution(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{"malformed days", "?days=month", http.StatusBadRequest},
+		{"negative days", "?days=-7", http.StatusBadRequest},
+		{"database not initialized", "?days=90", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := testRouter(testApp(nil))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/analytics/attribution"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_GetAPIUsage(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/usage?days=7", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500 without a database, got %d", w.Code)
+	}
+}
+
+func TestHandler_DataHealth(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		req := httptest.NewRequest(method, "/api/admin/data-health", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("%s: expected status 500 without a database, got %d", method, w.Code)
+		}
+	}
+}
+
+func TestHandler_LogLevel(t *testing.T) {
+	router := testRouter(testApp(nil))
+	t.Cleanup(func() { observability.ResetLevel(observability.ModuleScreener) })
+
+	for _, tt := range []struct {
+		body       string
+		wantStatus int
+	}{
+		{`{"module":"screener","level":"debug"}`, http.StatusOK},
+		{`{"module":"nonexistent","level":"debug"}`, http.StatusBadRequest},
+		{`{"module":"screener","level":"loud"}`, http.StatusBadRequest},
+		{`not json`, http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodPut, "/api/admin/log-level", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.body, tt.wantStatus, w.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/log-level", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var levels []observability.ModuleLevel
+	if err := json.Unmarshal(w.Body.Bytes(), &levels); err != nil {
+		t.Fatalf("failed to decode levels: %v", err)
+	}
+	for _, l := range levels {
+		if l.Module == observability.ModuleScreener && (l.Level != "debug" || l.Inherited) {
+			t.Errorf("screener level = %+v, want debug set explicitly", l)
+		}
+	}
+}
+
+func TestHandler_ScreenerSchedule(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	for _, tt := range []struct {
+		body       string
+		wantStatus int
+	}{
+		{`{"cron":"30 8 * * 1-5","analyze":true}`, http.StatusOK},
+		{`{"cron":"61 8 * * *"}`, http.StatusBadRequest},
+		{`not json`, http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodPut, "/api/screener/schedule", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.body, tt.wantStatus, w.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/screener/schedule", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var status models.ScreenerScheduleStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to decode schedule: %v", err)
+	}
+	if status.Cron != "30 8 * * 1-5" || !status.Analyze || status.NextRun == nil {
+		t.Errorf("schedule = %+v, want the one set above with a next run", status)
+	}
+}
+
+func TestHandler_Diagnostics(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/diagnostics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Disposition"), "attachment") {
+		t.Fatalf("export: status %d, Content-Disposition %q; want a 200 download", w.Code, w.Header().Get("Content-Disposition"))
+	}
+	var bundle models.DiagnosticBundle
+	if err := json.Unmarshal(w.Body.Bytes(), &bundle); err != nil {
+		t.Fatalf("failed to decode bundle: %v", err)
+	}
+
+	exported, _ := json.Marshal(bundle)
+	bundle.FormatVersion = 99
+	unsupported, _ := json.Marshal(bundle)
+	for _, tt := range []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"bad json", `not json`, http.StatusBadRequest},
+		{"unsupported", string(unsupported), http.StatusBadRequest},
+		{"no database", string(exported), http.StatusServiceUnavailable},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/diagnostics/import", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.wantStatus, w.Code)
+		}
+	}
+}
+
+func TestHandler_GetSimilar(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	for _, tt := range []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/api/similar", http.StatusBadRequest},
+		{"/api/similar?symbol=bad$", http.StatusBadRequest},
+		{"/api/similar?symbol=aapl", http.StatusServiceUnavailable},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.wantStatus, w.Code)
+		}
+	}
+}
+
+func TestHandler_GetRiskStats(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	for _, tt := range []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/api/stats/bad$", http.StatusBadRequest},
+		{"/api/stats/aapl", http.StatusServiceUnavailable},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.wantStatus, w.Code)
+		}
+	}
+}
+
+type stubDividendTracker struct{}
+
+func (stubDividendTracker) Income(ctx context.Context) (*models.DividendIncome, error) {
+	return &models.DividendIncome{Positions: []models.PositionDividendIncome{}}, nil
+}
+
+func TestHandler_GetDividendIncome(t *testing.T) {
+	t.Run("tracker not configured", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+		req := httptest.NewRequest(http.MethodGet, "/api/portfolio/dividends", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns income", func(t *testing.T) {
+		a := testApp(nil)
+		a.SetDividendTracker(stubDividendTracker{})
+		router := testRouter(a)
+		req := httptest.NewRequest(http.MethodGet, "/api/portfolio/dividends", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		if !strings.Contains(w.Body.String(), `"projected_annual_income"`) {
+			t.Errorf("body = %s, want the income report", w.Body.String())
+		}
+	})
+}
+
+func TestHandler_Backtest(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	for _, tt := range []struct {
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{http.MethodPost, "/api/backtest", "not json", http.StatusBadRequest},
+		{http.MethodPost, "/api/backtest", `{"symbols":["AAPL"],"start":"2025-01-02","end":"2025-06-30"}`, http.StatusServiceUnavailable},
+		{http.MethodGet, "/api/backtest/not-a-uuid", "", http.StatusInternalServerError},
+	} {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.wantStatus, w.Code)
+		}
+	}
+}
+
+func TestHandler_ProviderAlerts(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	for _, tt := range []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/api/alerts?all=true"},
+		{http.MethodPost, "/api/alerts/550e8400-e29b-41d4-a716-446655440000/dismiss"},
+	} {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("%s %s: expected status 500 without a database, got %d", tt.method, tt.path, w.Code)
+		}
+	}
+}
+
+func TestHandler_Onboarding(t *testing.T) {
+	t.Run("settings not available", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/onboarding/status", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", w.Code)
+		}
+	})
+
+	t.Run("walks through the wizard", func(t *testing.T) {
+		router := testRouter(testAppWithSettings(t))
+
+		step := func(body string) (int, app.OnboardingStepResult) {
+			req := httptest.NewRequest(http.MethodPost, "/api/onboarding/step", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			var result app.OnboardingStepResult
+			json.Unmarshal(w.Body.Bytes(), &result)
+			return w.Code, result
+		}
+
+		if code, _ := step(`{"step":"keys","api_keys":[]}`); code != http.StatusBadRequest {
+			t.Errorf("keys step without keys: expected status 400, got %d", code)
+		}
+		code, result := step(`{"step":"keys","api_keys":[{"service_name":"openai","api_key":"sk-test"}]}`)
+		if code != http.StatusOK || !result.Status.Services[settings.ServiceOpenAI].IsConfigured {
+			t.Fatalf("keys step: status %d, result %+v", code, result.Status)
+		}
+		if result.Status.NextStep != settings.OnboardingStepValidate {
+			t.Errorf("expected next step validate, got %q", result.Status.NextStep)
+		}
+
+		if code, _ := step(`{"step":"strategy","strategy":"reckless"}`); code != http.StatusBadRequest {
+			t.Errorf("unknown strategy: expected status 400, got %d", code)
+		}
+		if code, result := step(`{"step":"strategy","strategy":"conservative"}`); code != http.StatusOK || result.Status.Strategy != "conservative" {
+			t.Errorf("strategy step: status %d, strategy %q", code, result.Status.Strategy)
+		}
+
+		code, result = step(`{"step":"demo"}`)
+		if code != http.StatusOK || result.Demo == nil {
+			t.Fatalf("demo step: status %d, demo %+v", code, result.Demo)
+		}
+		if result.Status.Finished {
+			t.Error("wizard should not be finished before keys are validated")
+		}
 
-		if w.Code != http.StatusInternalServerError {
-			t.Errorf("expected status 500, got %d", w.Code)
+		if code, result := step(`{"skip":true}`); code != http.StatusOK || !result.Status.Finished || !result.Status.Skipped {
+			t.Errorf("skip: status %d, result %+v", code, result.Status)
 		}
 	})
 }
 
-func TestHandler_GetRecommendations(t *testing.T) {
+func TestHandler_Reconciliation(t *testing.T) {
 	t.Run("database not initialized", func(t *testing.T) {
 		a := testApp(nil)
 		router := testRouter(a)
 
-		req := httptest.NewRequest(http.MethodGet, "/api/recommendations", nil)
+		req := httptest.NewRequest(http.MethodGet, "/api/reconciliation/reports", nil)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -214,52 +1173,41 @@ func TestHandler_GetRecommendations(t *testing.T) {
 		}
 	})
 
-	t.Run("with limit parameter", func(t *testing.T) {
+	t.Run("invalid month", func(t *testing.T) {
 		a := testApp(nil)
 		router := testRouter(a)
 
-		req := httptest.NewRequest(http.MethodGet, "/api/recommendations?limit=10", nil)
+		req := httptest.NewRequest(http.MethodPost, "/api/reconciliation/run?month=May-2024", nil)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
 
-		if w.Code != http.StatusInternalServerError {
-			t.Errorf("expected status 500, got %d", w.Code)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
 		}
 	})
-}
-
-func TestHandler_ApproveRecommendation(t *testing.T) {
-	t.Run("invalid UUID", func(t *testing.T) {
-		ctx := context.Background()
-		connString := "host=localhost port=5432 user=postgres password=postgres dbname=trademachine_test sslmode=disable"
-		repo, err := repository.NewRepository(ctx, connString)
-		if err != nil {
-			t.Skip("database not available")
-		}
-		defer repo.Close()
 
-		a := testApp(repo)
-		a.Startup(ctx)
+	t.Run("reconciler not configured", func(t *testing.T) {
+		a := testApp(nil)
 		router := testRouter(a)
 
-		req := httptest.NewRequest(http.MethodPost, "/api/recommendations/invalid-uuid/approve", nil)
+		req := httptest.NewRequest(http.MethodPost, "/api/reconciliation/run?month=2024-05", nil)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
 
-		if w.Code != http.StatusInternalServerError {
-			t.Errorf("expected status 500, got %d", w.Code)
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", w.Code)
 		}
 	})
 }
 
-func TestHandler_GetPositions(t *testing.T) {
+func TestHandler_GetRecommendationMarkdown(t *testing.T) {
 	t.Run("database not initialized", func(t *testing.T) {
 		a := testApp(nil)
 		router := testRouter(a)
 
-		req := httptest.NewRequest(http.MethodGet, "/api/positions", nil)
+		req := httptest.NewRequest(http.MethodGet, "/api/recommendations/"+uuid.New().String()+"/markdown", nil)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -268,36 +1216,68 @@ func TestHandler_GetPositions(t *testing.T) {
 			t.Errorf("expected status 500, got %d", w.Code)
 		}
 	})
-}
 
-func TestHandler_GetTrades(t *testing.T) {
-	t.Run("database not initialized", func(t *testing.T) {
-		a := testApp(nil)
+	t.Run("filename escaped", func(t *testing.T) {
+		rec := models.NewRecommendation("AB\"C\r\nX-Injected: 1", models.RecommendationActionBuy, "test")
+		cfg := testConfig()
+		cfg.RecommendationExpiry.Enabled = false
+		a := app.New(cfg, &recommendationRepo{rec: rec}, nil, nil)
+		a.Startup(context.Background())
 		router := testRouter(a)
 
-		req := httptest.NewRequest(http.MethodGet, "/api/trades", nil)
+		req := httptest.NewRequest(http.MethodGet, "/api/recommendations/"+rec.ID.String()+"/markdown", nil)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
 
-		if w.Code != http.StatusInternalServerError {
-			t.Errorf("expected status 500, got %d", w.Code)
+		disposition := w.Header().Get("Content-Disposition")
+		if strings.ContainsAny(disposition, "\r\n") {
+			t.Fatalf("Content-Disposition = %q, want no line breaks", disposition)
+		}
+		_, params, err := mime.ParseMediaType(disposition)
+		want := rec.Symbol + "-" + rec.CreatedAt.Format("2006-01-02") + ".md"
+		if err != nil || params["filename"] != want {
+			t.Errorf("Content-Disposition = %q (%v), want filename %q", disposition, err, want)
 		}
 	})
+}
 
-	t.Run("with limit parameter", func(t *testing.T) {
-		a := testApp(nil)
-		router := testRouter(a)
+func TestHandler_Compliance(t *testing.T) {
+	router := testRouter(testAppWithSettings(t))
 
-		req := httptest.NewRequest(http.MethodGet, "/api/trades?limit=25", nil)
-		w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/compliance", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
 
-		router.ServeHTTP(w, req)
+	var status app.ComplianceStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to decode status: %v", err)
+	}
+	if status.Acknowledged || status.Text == "" {
+		t.Fatalf("status = %+v, want an unaccepted disclaimer", status)
+	}
 
-		if w.Code != http.StatusInternalServerError {
-			t.Errorf("expected status 500, got %d", w.Code)
-		}
-	})
+	req = httptest.NewRequest(http.MethodPost, "/api/compliance/acknowledge", strings.NewReader(`{"version": "stale"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("stale version: expected status 409, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/compliance/acknowledge", strings.NewReader(`{"version": "`+status.Version+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to decode status: %v", err)
+	}
+	if !status.Acknowledged || status.AcknowledgedAt == nil {
+		t.Errorf("status = %+v, want acknowledged", status)
+	}
 }
 
 func TestHandler_GetAgentRuns(t *testing.T) {
@@ -408,6 +1388,52 @@ func TestHandler_ValidateSymbol(t *testing.T) {
 	}
 }
 
+// FuzzHandler_ValidateSymbol checks that every symbol ValidateSymbol accepts is a short
+// ticker made only of upper-case letters, digits, dots and dashes
+func FuzzHandler_ValidateSymbol(f *testing.F) {
+	handler := testHandler(testApp(nil))
+	for _, seed := range []string{"AAPL", "BRK.B", "BRK-B", "ABCDEFGHIJ", "", "ABCDEFGHIJK", "aapl", "AAPL!", "AA PL", "ÄAPL", "AAPL\n", "../ETC"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, symbol string) {
+		if handler.ValidateSymbol(symbol) != nil {
+			return
+		}
+		if len(symbol) == 0 || len(symbol) > 10 {
+			t.Fatalf("accepted %q with length %d", symbol, len(symbol))
+		}
+		if strings.Trim(symbol, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789.-") != "" {
+			t.Fatalf("accepted %q with characters outside A-Z, 0-9, dot and dash", symbol)
+		}
+	})
+}
+
+// FuzzParseAnalyzeRequest checks that arbitrary JSON and form bodies never panic the
+// analyze request parser and always yield a trimmed, upper-case symbol
+func FuzzParseAnalyzeRequest(f *testing.F) {
+	f.Add(`{"symbol":" aapl "}`, true)
+	f.Add(`{"symbol":123}`, true)
+	f.Add(`{"symbol":"\u0000brk.b"}`, true)
+	f.Add(`not json`, true)
+	f.Add(`symbol=msft`, false)
+	f.Add(`symbol=%zz&symbol=goog`, false)
+
+	f.Fuzz(func(t *testing.T, body string, isJSON bool) {
+		req := httptest.NewRequest(http.MethodPost, "/api/analyze", strings.NewReader(body))
+		if isJSON {
+			req.Header.Set("Content-Type", "application/json")
+		} else {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+
+		symbol := parseAnalyzeRequest(req).Symbol
+		if symbol != strings.ToUpper(symbol) || symbol != strings.TrimSpace(symbol) {
+			t.Fatalf("parsed symbol %q from %q is not trimmed and upper-cased", symbol, body)
+		}
+	})
+}
+
 func TestHandler_AnalyzeStock_InvalidSymbol(t *testing.T) {
 	a := testApp(nil)
 	router := testRouter(a)
@@ -759,6 +1785,37 @@ func TestHandler_RunScreener(t *testing.T) {
 			t.Errorf("expected status 503, got %d", w.Code)
 		}
 	})
+
+	t.Run("invalid filter overrides", func(t *testing.T) {
+		a := testApp(nil)
+		a.SetScreener(newStubScreener())
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/screener/run", strings.NewReader(`{"price_min": -1}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("unknown preset", func(t *testing.T) {
+		a := testApp(nil)
+		a.SetScreener(newStubScreener())
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/screener/run?preset=Not+A+Preset", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
 }
 
 func TestHandler_GetLatestScreenerRun(t *testing.T) {
@@ -823,6 +1880,121 @@ func TestHandler_GetScreenerRun(t *testing.T) {
 	})
 }
 
+func TestHandler_RetryFailedScreenerCandidates(t *testing.T) {
+	t.Run("screener not configured", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/screener/runs/550e8400-e29b-41d4-a716-446655440000/retry-failed", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", w.Code)
+		}
+		if !strings.Contains(w.Body.String(), "missing_services") {
+			t.Errorf("expected missing_services in JSON error, got %s", w.Body.String())
+		}
+	})
+}
+
+func TestHandler_GetScreenerReplayComparison(t *testing.T) {
+	t.Run("run is not a replay", func(t *testing.T) {
+		a := testApp(nil)
+		a.SetScreener(newStubScreener())
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/screener/runs/550e8400-e29b-41d4-a716-446655440000/comparison", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("compares a replay with its original", func(t *testing.T) {
+		stub := newStubScreener()
+		original := *stub.run
+		stub.run.ReplayOf = &original.ID
+		a := testApp(nil)
+		a.SetScreener(stub)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/screener/runs/550e8400-e29b-41d4-a716-446655440000/comparison", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), `"replay"`) {
+			t.Errorf("expected a comparison, got %s", w.Body.String())
+		}
+	})
+}
+
+func TestHandler_ReplayScreenerRun(t *testing.T) {
+	t.Run("screener not configured", func(t *testing.T) {
+		a := testApp(nil)
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/screener/runs/550e8400-e29b-41d4-a716-446655440000/replay", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns comparison", func(t *testing.T) {
+		a := testApp(nil)
+		a.SetScreener(newStubScreener())
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/screener/runs/550e8400-e29b-41d4-a716-446655440000/replay",
+			strings.NewReader(`{"pe_ratio_max": 25, "ranking_strategy": "value"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("expected status 202, got %d: %s", w.Code, w.Body.String())
+		}
+		var body map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		for _, key := range []string{"original", "replay", "added", "removed", "original_picks", "replay_picks"} {
+			if _, ok := body[key]; !ok {
+				t.Errorf("expected key %q in response", key)
+			}
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		a := testApp(nil)
+		a.SetScreener(newStubScreener())
+		router := testRouter(a)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/screener/runs/550e8400-e29b-41d4-a716-446655440000/replay", strings.NewReader("{"))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+}
+
 func TestHandler_GetTopPicks(t *testing.T) {
 	t.Run("screener not configured", func(t *testing.T) {
 		a := testApp(nil)
@@ -1091,3 +2263,104 @@ func TestHandler_AnalyzeStock_FormData(t *testing.T) {
 		}
 	})
 }
+
+func TestHandler_GetQuote(t *testing.T) {
+	t.Run("invalid symbol", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/quotes/bad$sym", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("alpaca not configured", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/quotes/AAPL", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+}
+
+func TestHandler_GetQuickLook(t *testing.T) {
+	t.Run("invalid symbol", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/quick-look/bad$sym", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("summary without data sources", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/quick-look/aapl", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		var look models.QuickLook
+		if err := json.Unmarshal(w.Body.Bytes(), &look); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if look.Symbol != "AAPL" {
+			t.Errorf("expected symbol AAPL, got %s", look.Symbol)
+		}
+	})
+
+	t.Run("htmx popover", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/quick-look/AAPL", nil)
+		req.Header.Set("HX-Request", "true")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		if !strings.Contains(w.Body.String(), "Not analyzed yet") {
+			t.Errorf("expected popover to note the missing analysis, got %s", w.Body.String())
+		}
+	})
+}
+
+func TestHandler_GetMarketSession(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/market/session", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var resp MarketSessionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Label == "" || resp.Session == "" {
+		t.Errorf("expected session and label, got %+v", resp)
+	}
+}
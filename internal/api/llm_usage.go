@@ -0,0 +1,30 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"trade-machine/models"
+)
+
+// HandleGetLLMUsage returns the LLM tokens and estimated cost of agent runs per agent and
+// per model over ?period=day, week or month (default month), with the month's spend
+// against the budget when one is configured
+func (h *Handler) HandleGetLLMUsage(w http.ResponseWriter, r *http.Request) {
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = "month"
+	}
+
+	report, err := h.app.GetLLMUsage(period)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, models.ErrInvalidUsagePeriod) {
+			status = http.StatusBadRequest
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+
+	h.jsonResponse(w, report)
+}
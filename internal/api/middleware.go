@@ -1,13 +1,20 @@
 package api
 
 import (
+	"bufio"
+	"errors"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"trade-machine/internal/settings"
 	"trade-machine/observability"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/gorilla/websocket"
 )
 
 // responseWriter wraps http.ResponseWriter to capture status code and response size
@@ -35,6 +42,98 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return size, err
 }
 
+// Hijack hands the connection over for a WebSocket upgrade
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	rw.statusCode = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// TimeoutMiddleware cancels a request's context after d, except for WebSocket upgrades and
+// Server-Sent Event streams, which stay open for as long as the client is connected
+func TimeoutMiddleware(d time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		timed := middleware.Timeout(d)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if websocket.IsWebSocketUpgrade(r) || strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			timed.ServeHTTP(w, r)
+		})
+	}
+}
+
+// apiKeyHeaderServices are the services a request can bring its own key for, in an
+// X-Api-Key-<service> header with hyphens for underscores, such as X-Api-Key-Alpha-Vantage.
+// Alpaca also needs its secret in X-Api-Secret-Alpaca. Ollama is configured by URL and
+// always uses the shared settings.
+var apiKeyHeaderServices = []settings.ServiceName{
+	settings.ServiceOpenAI,
+	settings.ServiceAnthropic,
+	settings.ServiceAlpaca,
+	settings.ServiceAlphaVantage,
+	settings.ServiceNewsAPI,
+	settings.ServiceFMP,
+}
+
+// APIKeysMiddleware attaches the API keys a request carries in its headers to its
+// context, so the clients serving it use the caller's own keys ahead of the stored ones
+func APIKeysMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var keys map[settings.ServiceName]*settings.APIKeyConfig
+		for _, service := range apiKeyHeaderServices {
+			name := strings.ReplaceAll(string(service), "_", "-")
+			key := strings.TrimSpace(r.Header.Get("X-Api-Key-" + name))
+			secret := strings.TrimSpace(r.Header.Get("X-Api-Secret-" + name))
+			if key == "" || (service == settings.ServiceAlpaca && secret == "") {
+				continue
+			}
+			if keys == nil {
+				keys = make(map[settings.ServiceName]*settings.APIKeyConfig)
+			}
+			keys[service] = &settings.APIKeyConfig{ServiceName: service, APIKey: key, APISecret: secret}
+		}
+		if keys != nil {
+			r = r.WithContext(settings.WithAPIKeys(r.Context(), keys))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TracingMiddleware starts a trace span for each request, continuing the caller's trace
+// when the request carries a W3C traceparent header. The span is named after the matched
+// route, so requests for different symbols group together.
+func TracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := observability.StartServerSpan(r.Context(), r.Method, r.Header.Get("traceparent"),
+			"http.request.method", r.Method,
+			"url.path", r.URL.Path,
+			"request_id", middleware.GetReqID(r.Context()))
+		defer span.End()
+
+		wrapped := newResponseWriter(w)
+		next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+		if routePattern := chi.RouteContext(r.Context()).RoutePattern(); routePattern != "" {
+			span.SetName(r.Method + " " + routePattern)
+			span.SetAttributes("http.route", routePattern)
+		}
+		span.SetAttributes("http.response.status_code", wrapped.statusCode)
+		if wrapped.statusCode >= http.StatusInternalServerError {
+			span.RecordError(errors.New(http.StatusText(wrapped.statusCode)))
+		}
+	})
+}
+
 // MetricsMiddleware records HTTP metrics for each request
 func MetricsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1,10 +1,14 @@
 package api
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"trade-machine/internal/settings"
+	"trade-machine/observability"
+
 	"github.com/go-chi/chi/v5"
 )
 
@@ -69,6 +73,55 @@ func TestMetricsMiddleware(t *testing.T) {
 	}
 }
 
+func TestTracingMiddleware(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer collector.Close()
+	observability.InitTracing(observability.TracingOptions{Endpoint: collector.URL, SampleRatio: 1})
+	defer observability.ShutdownTracing(context.Background())
+
+	var traceID string
+	r := chi.NewRouter()
+	r.Use(TracingMiddleware)
+	r.Get("/api/stock/{symbol}", func(w http.ResponseWriter, r *http.Request) {
+		traceID = observability.TraceID(r.Context())
+	})
+
+	req := httptest.NewRequest("GET", "/api/stock/AAPL", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("handler trace ID = %q, want the caller's trace", traceID)
+	}
+}
+
+func TestAPIKeysMiddleware(t *testing.T) {
+	var keys map[settings.ServiceName]*settings.APIKeyConfig
+	handler := APIKeysMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = settings.APIKeysFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/api/portfolio", nil)
+	req.Header.Set("X-Api-Key-Alpha-Vantage", "av-key")
+	req.Header.Set("X-Api-Key-Alpaca", "alpaca-key") // No secret, so it's ignored
+	req.Header.Set("X-Api-Key-Ollama", "ignored")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if len(keys) != 1 || keys[settings.ServiceAlphaVantage] == nil || keys[settings.ServiceAlphaVantage].APIKey != "av-key" {
+		t.Errorf("keys = %v, want only the Alpha Vantage key", keys)
+	}
+
+	req.Header.Set("X-Api-Secret-Alpaca", "alpaca-secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if alpaca := keys[settings.ServiceAlpaca]; alpaca == nil || alpaca.APIKey != "alpaca-key" || alpaca.APISecret != "alpaca-secret" {
+		t.Errorf("alpaca key = %+v, want the key and secret", alpaca)
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/portfolio", nil))
+	if keys != nil {
+		t.Errorf("keys = %v without headers, want none", keys)
+	}
+}
+
 func TestMetricsMiddleware_Error(t *testing.T) {
 	// Create a handler that returns an error
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
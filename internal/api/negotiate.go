@@ -0,0 +1,46 @@
+package api
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Content negotiation
+//
+// Every endpoint serves two representations of the same data: an HTML partial
+// for HTMX requests and JSON for everything else. API clients get JSON by
+// default. A client that sends HX-Request can still opt out of HTML by asking
+// for JSON explicitly with "Accept: application/json" or "?format=json".
+
+// prefersJSON reports whether the client explicitly asked for a JSON response
+func prefersJSON(r *http.Request) bool {
+	if strings.EqualFold(r.URL.Query().Get("format"), "json") {
+		return true
+	}
+	return preferredMediaType(r.Header.Get("Accept")) == "application/json"
+}
+
+// preferredMediaType returns the highest-quality media type in an Accept header.
+// Ties keep the order given by the client. Returns an empty string if none is set.
+func preferredMediaType(accept string) string {
+	best := ""
+	bestQ := -1.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if qs, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(qs, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > bestQ {
+			best, bestQ = mediaType, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,290 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+)
+
+// stubScreener returns a fixed completed run and its picks
+type stubScreener struct {
+	run   *models.ScreenerRun
+	picks []models.ScreenerCandidate
+}
+
+func newStubScreener() *stubScreener {
+	run := models.NewScreenerRun(models.ScreenerCriteria{Limit: 15})
+	picks := []models.ScreenerCandidate{
+		{Symbol: "AAPL", CompanyName: "Apple Inc.", ValueScore: 72, Analyzed: true},
+		{Symbol: "MSFT", CompanyName: "Microsoft Corp.", ValueScore: 65, Analyzed: true},
+	}
+	run.Candidates = picks
+	run.Complete(1200, []uuid.UUID{uuid.New(), uuid.New()})
+	run.RunAt = time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC)
+	return &stubScreener{run: run, picks: picks}
+}
+
+func (s *stubScreener) RunScreen(ctx context.Context, overrides *models.ScreenerFilters) (*models.ScreenerRun, error) {
+	return s.run, nil
+}
+
+func (s *stubScreener) GetLatestPicks(ctx context.Context) ([]models.ScreenerCandidate, error) {
+	return s.picks, nil
+}
+
+func (s *stubScreener) GetLatestRun(ctx context.Context) (*models.ScreenerRun, error) {
+	return s.run, nil
+}
+
+func (s *stubScreener) GetRunHistory(ctx context.Context, limit int) ([]models.ScreenerRun, error) {
+	return []models.ScreenerRun{*s.run}, nil
+}
+
+func (s *stubScreener) GetRun(ctx context.Context, id uuid.UUID) (*models.ScreenerRun, error) {
+	return s.run, nil
+}
+
+func (s *stubScreener) RetryFailed(ctx context.Context, id uuid.UUID) (*models.ScreenerRun, error) {
+	return s.run, nil
+}
+
+func (s *stubScreener) ReplayRun(ctx context.Context, id uuid.UUID, req models.ScreenerReplayRequest) (*models.ScreenerRunComparison, error) {
+	return models.CompareScreenerRuns(s.run, s.run), nil
+}
+
+func TestPreferredMediaType(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+	}{
+		{"empty", "", ""},
+		{"single", "application/json", "application/json"},
+		{"first wins on tie", "text/html, application/json", "text/html"},
+		{"quality wins", "text/html;q=0.5, application/json", "application/json"},
+		{"wildcard", "*/*", "*/*"},
+		{"invalid entries skipped", ";;, application/json", "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := preferredMediaType(tt.accept); got != tt.want {
+				t.Errorf("preferredMediaType(%q) = %q, want %q", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsHTMXRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		htmx   bool
+		accept string
+		want   bool
+	}{
+		{"plain API request", "/api/trades", false, "", false},
+		{"htmx request", "/api/trades", true, "", true},
+		{"htmx with html accept", "/api/trades", true, "text/html", true},
+		{"htmx opting out via accept", "/api/trades", true, "application/json", false},
+		{"htmx opting out via format param", "/api/trades?format=json", true, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.htmx {
+				req.Header.Set("HX-Request", "true")
+			}
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			if got := isHTMXRequest(req); got != tt.want {
+				t.Errorf("isHTMXRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestJSONParity_OptOut checks that every endpoint honours a JSON opt-out from HTMX clients
+func TestJSONParity_OptOut(t *testing.T) {
+	endpoints := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/api/portfolio"},
+		{http.MethodGet, "/api/portfolio/asof?date=2024-06-30"},
+		{http.MethodPost, "/api/portfolio/analyze"},
+		{http.MethodGet, "/api/portfolio/reviews"},
+		{http.MethodGet, "/api/portfolio/reviews/550e8400-e29b-41d4-a716-446655440000"},
+		{http.MethodGet, "/api/positions"},
+		{http.MethodGet, "/api/recommendations"},
+		{http.MethodGet, "/api/recommendations/pending"},
+		{http.MethodPatch, "/api/recommendations/550e8400-e29b-41d4-a716-446655440000"},
+		{http.MethodPost, "/api/recommendations/550e8400-e29b-41d4-a716-446655440000/approve"},
+		{http.MethodPost, "/api/recommendations/550e8400-e29b-41d4-a716-446655440000/reject"},
+		{http.MethodPost, "/api/recommendations/550e8400-e29b-41d4-a716-446655440000/split"},
+		{http.MethodGet, "/api/recommendations/550e8400-e29b-41d4-a716-446655440000/tranches"},
+		{http.MethodDelete, "/api/recommendations/550e8400-e29b-41d4-a716-446655440000/tranches"},
+		{http.MethodPost, "/api/analyze"},
+		{http.MethodGet, "/api/quotes/AAPL"},
+		{http.MethodGet, "/api/stats/AAPL"},
+		{http.MethodPost, "/api/backtest"},
+		{http.MethodGet, "/api/backtest/550e8400-e29b-41d4-a716-446655440000"},
+		{http.MethodGet, "/api/market/session"},
+		{http.MethodGet, "/api/trades"},
+		{http.MethodGet, "/api/agents/runs"},
+		{http.MethodGet, "/api/watchlists"},
+		{http.MethodPost, "/api/watchlists/import"},
+		{http.MethodGet, "/api/usage"},
+		{http.MethodGet, "/api/alerts"},
+		{http.MethodPost, "/api/alerts/550e8400-e29b-41d4-a716-446655440000/dismiss"},
+		{http.MethodPost, "/api/screener/run"},
+		{http.MethodGet, "/api/screener/latest"},
+		{http.MethodGet, "/api/screener/runs"},
+		{http.MethodGet, "/api/screener/runs/550e8400-e29b-41d4-a716-446655440000"},
+		{http.MethodPost, "/api/screener/runs/550e8400-e29b-41d4-a716-446655440000/replay"},
+		{http.MethodGet, "/api/screener/picks"},
+		{http.MethodGet, "/api/screener/picks/latest-run"},
+		{http.MethodGet, "/api/settings"},
+		{http.MethodGet, "/api/onboarding/status"},
+		{http.MethodPost, "/api/onboarding/step"},
+	}
+
+	router := testRouter(testApp(nil))
+	for _, ep := range endpoints {
+		t.Run(ep.method+" "+ep.path, func(t *testing.T) {
+			req := httptest.NewRequest(ep.method, ep.path, nil)
+			req.Header.Set("HX-Request", "true")
+			req.Header.Set("Accept", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if ct := w.Header().Get("Content-Type"); !strings.Contains(ct, "application/json") {
+				t.Errorf("expected Content-Type application/json, got %q", ct)
+			}
+			var body interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Errorf("expected valid JSON body: %v", err)
+			}
+		})
+	}
+}
+
+func TestJSONParity_TopPicksIncludesRun(t *testing.T) {
+	a := testApp(nil)
+	a.SetScreener(newStubScreener())
+	router := testRouter(a)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/screener/picks/latest-run", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var resp TopPicksResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Run == nil {
+		t.Fatal("expected run context in JSON response")
+	}
+	if resp.Run.Status != models.ScreenerRunStatusCompleted {
+		t.Errorf("expected completed run, got %s", resp.Run.Status)
+	}
+	if resp.Count != 2 || len(resp.Picks) != 2 {
+		t.Errorf("expected 2 picks, got count=%d len=%d", resp.Count, len(resp.Picks))
+	}
+}
+
+func TestTopPicks_KeepsArrayResponse(t *testing.T) {
+	a := testApp(nil)
+	a.SetScreener(newStubScreener())
+	router := testRouter(a)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/screener/picks", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var picks []models.ScreenerCandidate
+	if err := json.Unmarshal(w.Body.Bytes(), &picks); err != nil {
+		t.Fatalf("expected a JSON array of picks: %v", err)
+	}
+	if len(picks) != 2 {
+		t.Errorf("expected 2 picks, got %d", len(picks))
+	}
+}
+
+func TestJSONParity_RunScreenerIncludesPicks(t *testing.T) {
+	a := testApp(nil)
+	a.SetScreener(newStubScreener())
+	router := testRouter(a)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/screener/run", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	for _, key := range []string{"id", "status", "candidates", "picks"} {
+		if _, ok := body[key]; !ok {
+			t.Errorf("expected key %q in response", key)
+		}
+	}
+}
+
+func TestJSONParity_ScreenerNotConfiguredIncludesMissingServices(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/screener/picks", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", w.Code)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if _, ok := body["missing_services"]; !ok {
+		t.Error("expected missing_services in JSON error")
+	}
+}
+
+func TestJSONParity_HTMXStillGetsHTML(t *testing.T) {
+	a := testApp(nil)
+	a.SetScreener(newStubScreener())
+	router := testRouter(a)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/screener/picks", nil)
+	req.Header.Set("HX-Request", "true")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if ct := w.Header().Get("Content-Type"); !strings.Contains(ct, "text/html") {
+		t.Errorf("expected Content-Type text/html, got %q", ct)
+	}
+}
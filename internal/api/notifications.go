@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"trade-machine/internal/app"
+	"trade-machine/models"
+	"trade-machine/notifications"
+)
+
+// notificationSettingsStatus maps a notification settings error to its HTTP status
+func notificationSettingsStatus(err error) int {
+	switch {
+	case errors.Is(err, notifications.ErrInvalidConfig), errors.Is(err, app.ErrNoNotificationChannels):
+		return http.StatusBadRequest
+	case errors.Is(err, app.ErrSettingsUnavailable):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// HandleGetNotificationSettings returns the notification channels and events, with
+// webhook URLs and the SMTP password masked
+func (h *Handler) HandleGetNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	cfg, err := h.app.GetNotificationSettings()
+	if err != nil {
+		h.jsonError(w, err.Error(), notificationSettingsStatus(err))
+		return
+	}
+	h.jsonResponse(w, cfg)
+}
+
+// HandleSetNotificationSettings replaces the notification channels and events from a body
+// such as {"slack_webhook_url": "https://hooks.slack.com/...", "events": ["trade.filled"]}
+func (h *Handler) HandleSetNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	var cfg notifications.Config
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	saved, err := h.app.SetNotificationSettings(cfg)
+	if err != nil {
+		h.jsonError(w, err.Error(), notificationSettingsStatus(err))
+		return
+	}
+	h.audit(r, models.AuditSettingsChanged, "notifications", map[string]any{
+		"slack":   cfg.SlackWebhookURL != "",
+		"discord": cfg.DiscordWebhookURL != "",
+		"smtp":    cfg.SMTP != nil,
+		"events":  cfg.Events,
+	})
+
+	h.jsonResponse(w, saved)
+}
+
+// HandleTestNotification sends a test message to every configured channel
+func (h *Handler) HandleTestNotification(w http.ResponseWriter, r *http.Request) {
+	if err := h.app.SendTestNotification(r.Context()); err != nil {
+		status := notificationSettingsStatus(err)
+		if status == http.StatusInternalServerError {
+			// A channel refused the message; report which one
+			status = http.StatusBadGateway
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+	h.jsonResponse(w, map[string]string{"status": "sent"})
+}
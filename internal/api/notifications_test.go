@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_NotificationSettings(t *testing.T) {
+	router := testRouter(testAppWithSettings(t))
+
+	for _, tt := range []struct {
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{http.MethodPost, "/api/settings/notifications/test", "", http.StatusBadRequest},
+		{http.MethodPut, "/api/settings/notifications", `{"slack_webhook_url": "hooks.slack.com"}`, http.StatusBadRequest},
+		{http.MethodPut, "/api/settings/notifications", `{"discord_webhook_url": "https://discord.com/api/webhooks/1/secret"}`, http.StatusOK},
+		{http.MethodGet, "/api/settings/notifications", "", http.StatusOK},
+	} {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s %s: expected status %d, got %d: %s", tt.method, tt.path, tt.wantStatus, w.Code, w.Body.String())
+		}
+		if strings.Contains(w.Body.String(), "secret") {
+			t.Errorf("%s %s: response exposes the webhook URL: %s", tt.method, tt.path, w.Body.String())
+		}
+	}
+}
+
+func TestHandler_NotificationSettings_NoStore(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/settings/notifications", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", w.Code)
+	}
+}
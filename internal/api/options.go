@@ -0,0 +1,26 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"trade-machine/internal/app"
+)
+
+// HandleGetCoveredCallSuggestions returns out-of-the-money calls that could be sold against
+// the long positions, with the premium each would collect at its bid and that premium as a
+// yield on the shares, highest annualized yield first. Positions that can't be covered are
+// listed with the reason.
+func (h *Handler) HandleGetCoveredCallSuggestions(w http.ResponseWriter, r *http.Request) {
+	report, err := h.app.GetCoveredCallSuggestions()
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, app.ErrOptionsUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+
+	h.jsonResponse(w, report)
+}
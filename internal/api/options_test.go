@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"trade-machine/models"
+)
+
+type stubCoveredCallAdvisor struct{}
+
+func (stubCoveredCallAdvisor) Suggest(ctx context.Context) (*models.CoveredCallReport, error) {
+	return &models.CoveredCallReport{
+		Suggestions: []models.CoveredCallSuggestion{{Symbol: "AAPL", Contracts: 2, Premium: 400, AnnualizedYield: 24.33}},
+		Skipped:     []models.CoveredCallSkip{{Symbol: "KO", Reason: "fewer than 100 shares"}},
+	}, nil
+}
+
+func TestHandler_GetCoveredCallSuggestions(t *testing.T) {
+	t.Run("unavailable", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+		req := httptest.NewRequest(http.MethodGet, "/api/options/suggestions", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503 without an advisor, got %d", w.Code)
+		}
+	})
+
+	t.Run("suggestions", func(t *testing.T) {
+		a := testApp(nil)
+		a.SetCoveredCallAdvisor(stubCoveredCallAdvisor{})
+		router := testRouter(a)
+		req := httptest.NewRequest(http.MethodGet, "/api/options/suggestions", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var report models.CoveredCallReport
+		if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(report.Suggestions) != 1 || report.Suggestions[0].Premium != 400 || len(report.Skipped) != 1 {
+			t.Errorf("report = %+v, want the advisor's suggestion and skipped position", report)
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"trade-machine/models"
+)
+
+// HandleGetPortfolioHistory returns the equity curve recorded by the daily portfolio
+// snapshots over ?range=1m, 3m, 6m, ytd, 1y or all (default 1y), with its time-weighted
+// return and max drawdown compared with ?benchmark=SYMBOL (default RISK_STATS_BENCHMARK)
+func (h *Handler) HandleGetPortfolioHistory(w http.ResponseWriter, r *http.Request) {
+	rng := r.URL.Query().Get("range")
+	if rng == "" {
+		rng = "1y"
+	}
+	benchmark := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("benchmark")))
+	if benchmark != "" {
+		if err := validateSymbolFormat(benchmark); err != nil {
+			h.jsonError(w, "invalid benchmark: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	history, err := h.app.GetPortfolioHistory(rng, benchmark)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, models.ErrInvalidHistoryRange) {
+			status = http.StatusBadRequest
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+
+	h.jsonResponse(w, history)
+}
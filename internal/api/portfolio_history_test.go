@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_GetPortfolioHistory(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{"default range", "", http.StatusInternalServerError},
+		{"ytd", "?range=ytd", http.StatusInternalServerError},
+		{"invalid range", "?range=5y", http.StatusBadRequest},
+		{"benchmark", "?range=1m&benchmark=qqq", http.StatusInternalServerError},
+		{"invalid benchmark", "?benchmark=S%26P", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := testRouter(testApp(nil))
+			req := httptest.NewRequest(http.MethodGet, "/api/portfolio/history"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"trade-machine/internal/app"
+	"trade-machine/models"
+	"trade-machine/templates/partials"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// priceBarsDefaultLimit is how many bars are returned without ?limit=N
+const priceBarsDefaultLimit = 200
+
+// HandleGetPriceBars returns a symbol's most recent OHLCV bars, oldest first, for
+// charting. ?timeframe= is 1Min, 5Min, 15Min, 1H, 1D (the default), 1W or 1M and
+// ?limit=N how many bars (default 200, at most 1000). HTMX requests get an inline chart.
+func (h *Handler) HandleGetPriceBars(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(strings.TrimSpace(chi.URLParam(r, "symbol")))
+	if err := h.ValidateSymbol(symbol); err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	timeframe, err := models.ParseBarTimeframe(r.URL.Query().Get("timeframe"))
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.app.GetPriceBars(symbol, timeframe, h.ParseLimitParam(r, priceBarsDefaultLimit))
+	if err != nil {
+		if isHTMXRequest(r) {
+			h.htmlError(w, err.Error(), r)
+			return
+		}
+		status := http.StatusInternalServerError
+		if errors.Is(err, app.ErrPriceBarsUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		h.htmlResponse(w, partials.PriceChart(history), r)
+		return
+	}
+
+	h.jsonResponse(w, history)
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_GetPriceBars(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	for _, tt := range []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/api/market/NOT_A_SYMBOL!/bars", http.StatusBadRequest},
+		{"/api/market/AAPL/bars?timeframe=2D", http.StatusBadRequest},
+		{"/api/market/AAPL/bars?timeframe=1D&limit=200", http.StatusServiceUnavailable},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.wantStatus, w.Code)
+		}
+	}
+}
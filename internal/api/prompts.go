@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"trade-machine/internal/app"
+	"trade-machine/internal/settings"
+	"trade-machine/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// agentPromptStatus maps an agent prompt settings error to its HTTP status
+func agentPromptStatus(err error) int {
+	switch {
+	case errors.Is(err, settings.ErrInvalidPrompt):
+		return http.StatusBadRequest
+	case errors.Is(err, app.ErrUnknownPromptAgent), errors.Is(err, settings.ErrPromptVersionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, app.ErrSettingsUnavailable):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// HandleGetAgentPrompt returns the system prompt an agent (fundamental, news, technical
+// or social) uses, its built-in default and the saved versions
+func (h *Handler) HandleGetAgentPrompt(w http.ResponseWriter, r *http.Request) {
+	prompt, err := h.app.GetAgentPrompt(chi.URLParam(r, "agent"))
+	if err != nil {
+		h.jsonError(w, err.Error(), agentPromptStatus(err))
+		return
+	}
+	h.jsonResponse(w, prompt)
+}
+
+// HandleSetAgentPrompt saves a new version of an agent's system prompt from a body such
+// as {"prompt": "You are a financial analyst..."}, or rolls back to a saved version with
+// {"version": 2}, or to the built-in prompt with {"version": 0}
+func (h *Handler) HandleSetAgentPrompt(w http.ResponseWriter, r *http.Request) {
+	var update app.AgentPromptUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		h.jsonError(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	prompt, err := h.app.SetAgentPrompt(chi.URLParam(r, "agent"), update)
+	if err != nil {
+		h.jsonError(w, err.Error(), agentPromptStatus(err))
+		return
+	}
+	h.audit(r, models.AuditSettingsChanged, "prompts/"+string(prompt.Agent), map[string]any{"version": prompt.ActiveVersion})
+
+	h.jsonResponse(w, prompt)
+}
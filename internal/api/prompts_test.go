@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"trade-machine/internal/app"
+)
+
+func TestHandler_AgentPrompts(t *testing.T) {
+	router := testRouter(testAppWithSettings(t))
+
+	for _, tt := range []struct {
+		method      string
+		path        string
+		body        string
+		wantStatus  int
+		wantVersion int
+		wantCustom  bool
+	}{
+		{http.MethodGet, "/api/settings/prompts/macro", "", http.StatusNotFound, 0, false},
+		{http.MethodPut, "/api/settings/prompts/news", `{}`, http.StatusBadRequest, 0, false},
+		{http.MethodPut, "/api/settings/prompts/news", `{"version": 1}`, http.StatusNotFound, 0, false},
+		{http.MethodPut, "/api/settings/prompts/news", `{"prompt": "You are a skeptical news analyst."}`, http.StatusOK, 1, true},
+		{http.MethodGet, "/api/settings/prompts/news", "", http.StatusOK, 1, true},
+		{http.MethodPut, "/api/settings/prompts/news", `{"version": 0}`, http.StatusOK, 0, false},
+		{http.MethodGet, "/api/settings/prompts/fundamental", "", http.StatusOK, 0, false},
+	} {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Fatalf("%s %s %s: expected status %d, got %d: %s", tt.method, tt.path, tt.body, tt.wantStatus, w.Code, w.Body.String())
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		var prompt app.AgentPromptSettings
+		if err := json.NewDecoder(w.Body).Decode(&prompt); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if prompt.ActiveVersion != tt.wantVersion || (prompt.Prompt != prompt.Default) != tt.wantCustom {
+			t.Errorf("%s %s %s: prompt = %+v, want version %d", tt.method, tt.path, tt.body, prompt, tt.wantVersion)
+		}
+	}
+}
+
+func TestHandler_AgentPrompts_NoStore(t *testing.T) {
+	router := testRouter(testApp(nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/settings/prompts/news", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", w.Code)
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"trade-machine/internal/app"
+	"trade-machine/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// recommendationHistoryDefaultLimit is how many recommendations are returned without ?limit=N
+const recommendationHistoryDefaultLimit = 50
+
+// HandleGetRecommendationHistory returns a symbol's recommendations, oldest first, each
+// with how its scores moved from the analysis before it. ?limit=N is how many of the most
+// recent to return (default 50, at most 500).
+func (h *Handler) HandleGetRecommendationHistory(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(strings.TrimSpace(chi.URLParam(r, "symbol")))
+	if err := h.ValidateSymbol(symbol); err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.app.GetRecommendationHistory(symbol, h.ParseLimitParam(r, recommendationHistoryDefaultLimit))
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.jsonResponse(w, history)
+}
+
+// HandleReanalyzeSymbol analyzes a symbol again and returns the new recommendation next to
+// the one it followed, with the change in each score
+func (h *Handler) HandleReanalyzeSymbol(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(strings.TrimSpace(chi.URLParam(r, "symbol")))
+	if err := h.ValidateSymbol(symbol); err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	comparison, err := h.app.ReanalyzeSymbol(r.Context(), symbol)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, app.ErrAnalysisQueueFull):
+			status = http.StatusServiceUnavailable
+		case errors.Is(err, models.ErrLLMBudgetExceeded):
+			status = http.StatusTooManyRequests
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+
+	h.jsonResponse(w, comparison)
+}
@@ -19,8 +19,9 @@ func NewRouter(h *Handler, cfg *config.Config) http.Handler {
 	r.Use(middleware.RealIP)
 	r.Use(middleware.RequestID)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(time.Duration(cfg.Agent.TimeoutSeconds) * time.Second))
+	r.Use(TimeoutMiddleware(time.Duration(cfg.Agent.TimeoutSeconds) * time.Second))
 	r.Use(CORSMiddleware(cfg.HTTP.CORSAllowedOrigins))
+	r.Use(TracingMiddleware)
 	r.Use(MetricsMiddleware)
 
 	// Root routes
@@ -28,33 +29,133 @@ func NewRouter(h *Handler, cfg *config.Config) http.Handler {
 	r.Get("/index.html", h.HandleIndex)
 
 	// Metrics endpoint for Prometheus
-	r.Handle("/metrics", promhttp.Handler())
+	r.With(h.AuthMiddleware).Handle("/metrics", promhttp.Handler())
 
 	// API routes
 	r.Route("/api", func(r chi.Router) {
+		// Scoped API tokens, when API_AUTH_ENABLED is set
+		r.Use(h.AuthMiddleware)
+		// Per-request API keys, which take precedence over the stored ones
+		r.Use(APIKeysMiddleware)
+
 		// Health check
 		r.Get("/health", h.HandleHealth)
 
+		// Live recommendation, agent run and screener events over WebSocket
+		r.Get("/ws", h.HandleEventStream)
+
 		// Portfolio
 		r.Get("/portfolio", h.HandleGetPortfolio)
+		r.Get("/portfolio/asof", h.HandleGetPortfolioAsOf)
+		r.Get("/portfolio/dividends", h.HandleGetDividendIncome)
+		r.Get("/portfolio/history", h.HandleGetPortfolioHistory)
+		r.Post("/portfolio/analyze", h.HandleAnalyzePortfolio)
+		r.Get("/portfolio/reviews", h.HandleGetPortfolioReviews)
+		r.Get("/portfolio/reviews/{id}", h.HandleGetPortfolioReview)
+
+		// Backtests
+		r.Post("/backtest", h.HandleRunBacktest)
+		r.Get("/backtest/{id}", h.HandleGetBacktestRun)
+
+		// Rebalancing
+		r.Route("/rebalance", func(r chi.Router) {
+			r.Post("/plan", h.HandlePlanRebalance)
+			r.Post("/execute", h.HandleExecuteRebalance)
+			r.Get("/targets", h.HandleGetRebalanceTargets)
+			r.Put("/targets", h.HandleSetRebalanceTargets)
+		})
+
+		// Options
+		r.Get("/options/suggestions", h.HandleGetCoveredCallSuggestions)
+
+		// Broker routing
+		r.Get("/broker", h.HandleGetBroker)
+		r.Put("/broker", h.HandleSetBroker)
 		r.Get("/positions", h.HandleGetPositions)
 
+		// Analytics
+		r.Get("/analytics/attribution", h.HandleGetAttribution)
+
 		// Recommendations
 		r.Route("/recommendations", func(r chi.Router) {
 			r.Get("/", h.HandleGetRecommendations)
 			r.Get("/pending", h.HandleGetPendingRecommendations)
+			r.Patch("/{id}", h.HandleEditRecommendation)
 			r.Post("/{id}/approve", h.HandleApproveRecommendation)
 			r.Post("/{id}/reject", h.HandleRejectRecommendation)
+			r.Post("/{id}/execute", h.HandleExecuteRecommendation)
+			r.Post("/{id}/split", h.HandleSplitRecommendation)
+			r.Get("/{id}/tranches", h.HandleGetRecommendationTranches)
+			r.Delete("/{id}/tranches", h.HandleCancelRecommendationTranches)
+			r.Get("/{id}/preview", h.HandlePreviewRecommendation)
+			r.Get("/{id}/events", h.HandleGetRecommendationEvents)
+			r.Get("/{id}/markdown", h.HandleGetRecommendationMarkdown)
 		})
 
 		// Analysis
 		r.Post("/analyze", h.HandleAnalyzeStock)
+		r.Post("/analyze/batch", h.HandleAnalyzeBatch)
+		r.Get("/analyze/batch/{id}", h.HandleGetBatchAnalysis)
+		r.Get("/analyze/jobs/{id}", h.HandleGetAnalysisJob)
+		r.Post("/chat", h.HandleChat)
+		r.Get("/symbols/{symbol}/recommendations", h.HandleGetRecommendationHistory)
+		r.Post("/symbols/{symbol}/reanalyze", h.HandleReanalyzeSymbol)
+
+		// Market data
+		r.Get("/quotes/{symbol}", h.HandleGetQuote)
+		r.Get("/quick-look/{symbol}", h.HandleGetQuickLook)
+		r.Get("/stats/{symbol}", h.HandleGetRiskStats)
+		r.Get("/market/session", h.HandleGetMarketSession)
+		r.Get("/market/{symbol}/bars", h.HandleGetPriceBars)
+
+		// Similar past analyses
+		r.Get("/similar", h.HandleGetSimilar)
 
 		// Trades
 		r.Get("/trades", h.HandleGetTrades)
+		r.Get("/trades/{id}", h.HandleGetTrade)
 
 		// Agent runs
 		r.Get("/agents/runs", h.HandleGetAgentRuns)
+		r.Post("/agents/runs/{id}/replay", h.HandleReplayAgentRun)
+
+		// Activity feed
+		r.Get("/activity", h.HandleGetActivity)
+
+		// Audit log of state-changing actions
+		r.Get("/audit", h.HandleGetAuditLog)
+
+		// API tokens, and the web UI's session cookie
+		r.Post("/auth/session", h.HandleCreateSession)
+		r.Delete("/auth/session", h.HandleDeleteSession)
+		r.Route("/auth/tokens", func(r chi.Router) {
+			r.Get("/", h.HandleGetAPITokens)
+			r.Post("/", h.HandleCreateAPIToken)
+			r.Delete("/{id}", h.HandleRevokeAPIToken)
+		})
+
+		// Watchlists
+		r.Get("/watchlists", h.HandleGetWatchlists)
+		r.Post("/watchlists/import", h.HandleImportWatchlist)
+
+		// File exports
+		r.Get("/export/{resource}", h.HandleExport)
+
+		// External API usage and LLM cost
+		r.Get("/usage", h.HandleGetAPIUsage)
+		r.Get("/usage/llm", h.HandleGetLLMUsage)
+
+		// Administration
+		r.Get("/admin/data-health", h.HandleDataHealth)
+		r.Post("/admin/data-health", h.HandleDataHealth)
+		r.Get("/admin/log-level", h.HandleGetLogLevels)
+		r.Put("/admin/log-level", h.HandleSetLogLevel)
+		r.Get("/admin/diagnostics", h.HandleExportDiagnostics)
+		r.Post("/admin/diagnostics/import", h.HandleImportDiagnostics)
+
+		// Provider alerts
+		r.Get("/alerts", h.HandleGetProviderAlerts)
+		r.Post("/alerts/{id}/dismiss", h.HandleDismissProviderAlert)
 
 		// Screener
 		r.Route("/screener", func(r chi.Router) {
@@ -62,7 +163,24 @@ func NewRouter(h *Handler, cfg *config.Config) http.Handler {
 			r.Get("/latest", h.HandleGetLatestScreenerRun)
 			r.Get("/runs", h.HandleGetScreenerRuns)
 			r.Get("/runs/{id}", h.HandleGetScreenerRun)
+			r.Get("/runs/{id}/events", h.HandleScreenerRunEvents)
+			r.Post("/runs/{id}/retry-failed", h.HandleRetryFailedScreenerCandidates)
+			r.Post("/runs/{id}/replay", h.HandleReplayScreenerRun)
+			r.Get("/runs/{id}/comparison", h.HandleGetScreenerReplayComparison)
 			r.Get("/picks", h.HandleGetTopPicks)
+			r.Get("/picks/latest-run", h.HandleGetTopPicksWithRun)
+			r.Get("/schedule", h.HandleGetScreenerSchedule)
+			r.Put("/schedule", h.HandleSetScreenerSchedule)
+			r.Get("/presets", h.HandleGetScreenerPresets)
+			r.Post("/presets", h.HandleSaveScreenerPreset)
+			r.Delete("/presets/{name}", h.HandleDeleteScreenerPreset)
+		})
+
+		// Broker reconciliation
+		r.Route("/reconciliation", func(r chi.Router) {
+			r.Get("/reports", h.HandleGetReconciliationReports)
+			r.Get("/reports/{id}", h.HandleGetReconciliationReport)
+			r.Post("/run", h.HandleRunReconciliation)
 		})
 
 		// Settings
@@ -71,6 +189,24 @@ func NewRouter(h *Handler, cfg *config.Config) http.Handler {
 			r.Post("/api-keys", h.HandleUpdateAPIKey)
 			r.Post("/api-keys/{service}/test", h.HandleTestAPIKey)
 			r.Delete("/api-keys/{service}", h.HandleDeleteAPIKey)
+			r.Get("/symbol-lists", h.HandleGetSymbolLists)
+			r.Post("/symbol-lists", h.HandleAddSymbolListEntry)
+			r.Delete("/symbol-lists/{list}/{symbol}", h.HandleRemoveSymbolListEntry)
+			r.Get("/notifications", h.HandleGetNotificationSettings)
+			r.Put("/notifications", h.HandleSetNotificationSettings)
+			r.Post("/notifications/test", h.HandleTestNotification)
+			r.Get("/prompts/{agent}", h.HandleGetAgentPrompt)
+			r.Put("/prompts/{agent}", h.HandleSetAgentPrompt)
+		})
+
+		// Disclaimer and its acknowledgment
+		r.Get("/compliance", h.HandleGetCompliance)
+		r.Post("/compliance/acknowledge", h.HandleAcknowledgeDisclaimer)
+
+		// First-run setup wizard
+		r.Route("/onboarding", func(r chi.Router) {
+			r.Get("/status", h.HandleGetOnboardingStatus)
+			r.Post("/step", h.HandleOnboardingStep)
 		})
 
 		// E2E testing endpoints (only available in test mode)
@@ -88,7 +224,7 @@ func CORSMiddleware(allowedOrigins string) func(next http.Handler) http.Handler
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", allowedOrigins)
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 			if r.Method == http.MethodOptions {
 				w.WriteHeader(http.StatusOK)
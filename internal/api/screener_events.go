@@ -0,0 +1,173 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"trade-machine/events"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// screenerRunEventTypes are the domain events streamed to screener run progress clients
+var screenerRunEventTypes = []events.Type{
+	events.ScreenerCandidateStarted,
+	events.ScreenerCandidateScored,
+	events.ScreenerCandidateFailed,
+	events.ScreenerCompleted,
+}
+
+// screenerEventKeepalive is how often an idle progress stream sends a comment, so proxies
+// don't close it while a slow candidate is analyzed
+const screenerEventKeepalive = 15 * time.Second
+
+// runEventHub fans screener progress events out to the clients following each run. Like
+// the WebSocket hub, a client that falls behind is dropped rather than allowed to hold
+// up the others.
+type runEventHub struct {
+	mu      sync.Mutex
+	clients map[uuid.UUID]map[chan events.Event]struct{}
+}
+
+func newRunEventHub() *runEventHub {
+	return &runEventHub{clients: make(map[uuid.UUID]map[chan events.Event]struct{})}
+}
+
+// subscribe returns a queue of the run's progress events, closed if the client falls behind
+func (s *runEventHub) subscribe(runID uuid.UUID) chan events.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queue := make(chan events.Event, streamClientBuffer)
+	if s.clients[runID] == nil {
+		s.clients[runID] = make(map[chan events.Event]struct{})
+	}
+	s.clients[runID][queue] = struct{}{}
+	return queue
+}
+
+// unsubscribe drops a client's queue, closing it unless it was already dropped
+func (s *runEventHub) unsubscribe(runID uuid.UUID, queue chan events.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drop(runID, queue)
+}
+
+func (s *runEventHub) drop(runID uuid.UUID, queue chan events.Event) {
+	if _, ok := s.clients[runID][queue]; !ok {
+		return
+	}
+	delete(s.clients[runID], queue)
+	if len(s.clients[runID]) == 0 {
+		delete(s.clients, runID)
+	}
+	close(queue)
+}
+
+// publish sends a progress or completion event to the clients following its run
+func (s *runEventHub) publish(e events.Event) {
+	var runID uuid.UUID
+	if progress, ok := e.CandidateProgress(); ok {
+		runID = progress.RunID
+	} else if run := e.ScreenerRun(); run != nil {
+		runID = run.ID
+	} else {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for queue := range s.clients[runID] {
+		select {
+		case queue <- e:
+		default:
+			logger.Warn("dropping slow screener progress client", "run_id", runID)
+			s.drop(runID, queue)
+		}
+	}
+}
+
+// writeServerSentEvent writes an event as a Server-Sent Event named after its type, with
+// the event's JSON as its data
+func writeServerSentEvent(w http.ResponseWriter, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+	return err
+}
+
+// HandleScreenerRunEvents streams a screener run's progress as Server-Sent Events while it
+// runs: screener.candidate_started, screener.candidate_scored and
+// screener.candidate_failed for each candidate, then screener.completed with the finished
+// run, after which the stream ends. A run that has already finished gets only the
+// screener.completed event. Clients should send Accept: text/event-stream, as EventSource
+// does, so the request isn't cut off by the API timeout.
+func (h *Handler) HandleScreenerRunEvents(w http.ResponseWriter, r *http.Request) {
+	if h.app.Screener() == nil {
+		h.jsonError(w, "Screener not configured", http.StatusServiceUnavailable)
+		return
+	}
+	runID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.jsonError(w, "Invalid screener run ID", http.StatusBadRequest)
+		return
+	}
+
+	// Subscribe before loading the run so a completion in between isn't missed
+	queue := h.runEvents.subscribe(runID)
+	defer h.runEvents.unsubscribe(runID, queue)
+
+	run, err := h.app.GetScreenerRun(runID.String())
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if run == nil {
+		h.jsonError(w, "Screener run not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher := http.NewResponseController(w)
+
+	if !run.IsRunning() {
+		writeServerSentEvent(w, events.Event{Type: events.ScreenerCompleted, Time: time.Now(), Payload: run})
+		flusher.Flush()
+		return
+	}
+	flusher.Flush()
+
+	keepalive := time.NewTicker(screenerEventKeepalive)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-queue:
+			if !ok {
+				return
+			}
+			if err := writeServerSentEvent(w, e); err != nil {
+				return
+			}
+			if err := flusher.Flush(); err != nil || e.Type == events.ScreenerCompleted {
+				return
+			}
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			if err := flusher.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
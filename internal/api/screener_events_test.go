@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"trade-machine/events"
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+)
+
+func runEventClientCount(s *runEventHub, runID uuid.UUID) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.clients[runID])
+}
+
+func TestRunEventHub_RoutesByRun(t *testing.T) {
+	hub := newRunEventHub()
+	runID := uuid.New()
+	queue := hub.subscribe(runID)
+	defer hub.unsubscribe(runID, queue)
+
+	hub.publish(events.Event{Type: events.ScreenerCandidateStarted, Payload: events.CandidateProgress{RunID: uuid.New(), Symbol: "MSFT"}})
+	hub.publish(events.Event{Type: events.ScreenerCandidateStarted, Payload: events.CandidateProgress{RunID: runID, Symbol: "AAPL"}})
+	hub.publish(events.Event{Type: events.ScreenerCompleted, Payload: &models.ScreenerRun{ID: runID}})
+
+	select {
+	case e := <-queue:
+		if progress, _ := e.CandidateProgress(); progress.Symbol != "AAPL" {
+			t.Errorf("first event = %+v, want only this run's progress", e.Payload)
+		}
+	default:
+		t.Fatal("expected the run's progress event")
+	}
+	select {
+	case e := <-queue:
+		if e.Type != events.ScreenerCompleted {
+			t.Errorf("second event = %s, want screener.completed", e.Type)
+		}
+	default:
+		t.Fatal("expected the run's completion event")
+	}
+}
+
+func TestRunEventHub_DropsSlowClient(t *testing.T) {
+	hub := newRunEventHub()
+	runID := uuid.New()
+	queue := hub.subscribe(runID)
+
+	for range streamClientBuffer + 1 {
+		hub.publish(events.Event{Type: events.ScreenerCandidateScored, Payload: events.CandidateProgress{RunID: runID}})
+	}
+
+	if n := runEventClientCount(hub, runID); n != 0 {
+		t.Errorf("clients = %d, want the slow client dropped", n)
+	}
+	hub.unsubscribe(runID, queue) // Already dropped; must not close the queue twice
+}
+
+func TestHandler_ScreenerRunEvents(t *testing.T) {
+	t.Run("screener not configured", func(t *testing.T) {
+		router := testRouter(testApp(nil))
+		req := httptest.NewRequest(http.MethodGet, "/api/screener/runs/550e8400-e29b-41d4-a716-446655440000/events", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", w.Code)
+		}
+	})
+
+	t.Run("invalid run ID", func(t *testing.T) {
+		a := testApp(nil)
+		a.SetScreener(newStubScreener())
+		router := testRouter(a)
+		req := httptest.NewRequest(http.MethodGet, "/api/screener/runs/not-a-uuid/events", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("finished run sends completion", func(t *testing.T) {
+		a := testApp(nil)
+		a.SetScreener(newStubScreener())
+		router := testRouter(a)
+		req := httptest.NewRequest(http.MethodGet, "/api/screener/runs/550e8400-e29b-41d4-a716-446655440000/events", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("Content-Type = %q, want text/event-stream", ct)
+		}
+		if !strings.HasPrefix(w.Body.String(), "event: screener.completed\ndata: ") {
+			t.Errorf("body = %q, want a screener.completed event", w.Body.String())
+		}
+	})
+
+	t.Run("running run streams progress", func(t *testing.T) {
+		stub := newStubScreener()
+		stub.run.Status = models.ScreenerRunStatusRunning
+		a := testApp(nil)
+		a.SetScreener(stub)
+		h := NewHandler(a, testConfig())
+		server := httptest.NewServer(NewRouter(h, h.cfg))
+		defer server.Close()
+
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/screener/runs/"+stub.run.ID.String()+"/events", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request error = %v", err)
+		}
+		defer resp.Body.Close()
+
+		deadline := time.Now().Add(2 * time.Second)
+		for runEventClientCount(h.runEvents, stub.run.ID) == 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("client was not registered with the hub")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		h.runEvents.publish(events.Event{Type: events.ScreenerCandidateScored, Time: time.Now(),
+			Payload: events.CandidateProgress{RunID: stub.run.ID, Symbol: "AAPL", Completed: 1, Total: 2}})
+		h.runEvents.publish(events.Event{Type: events.ScreenerCompleted, Time: time.Now(), Payload: stub.run})
+
+		var names []string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if name, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+				names = append(names, name)
+			}
+		}
+		if strings.Join(names, ",") != "screener.candidate_scored,screener.completed" {
+			t.Errorf("events = %v, want the candidate's progress then completion", names)
+		}
+	})
+}
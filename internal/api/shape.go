@@ -0,0 +1,155 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// Response shaping
+//
+// List endpoints whose items carry long text can trim their JSON with two query
+// parameters:
+//
+//	?fields=id,symbol,action  keep only these top-level fields of each item (sparse fieldset)
+//	?summary=true             shorten long text fields, such as reasoning, wherever they appear
+//
+// Shaping applies to the JSON representation only; HTML partials are unchanged.
+
+// summaryTextLength is the most characters a summarized text field keeps
+const summaryTextLength = 280
+
+// summarizedFields are the text fields shortened in summary mode
+var summarizedFields = []string{"reasoning"}
+
+// responseShape is the field selection and summary mode requested by a client
+type responseShape struct {
+	fields  map[string]bool // Top-level fields to keep; nil keeps them all
+	summary bool
+}
+
+// parseResponseShape reads the fields and summary query parameters
+func parseResponseShape(r *http.Request) responseShape {
+	var shape responseShape
+	query := r.URL.Query()
+	if raw := query.Get("fields"); raw != "" {
+		shape.fields = make(map[string]bool)
+		for _, field := range strings.Split(raw, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				shape.fields[field] = true
+			}
+		}
+	}
+	shape.summary = query.Get("summary") == "true"
+	return shape
+}
+
+// isZero reports whether the shape leaves responses unchanged
+func (s responseShape) isZero() bool {
+	return s.fields == nil && !s.summary
+}
+
+// apply returns data's JSON form with the shape applied. An array is shaped item by item.
+func (s responseShape) apply(data interface{}) (interface{}, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Numbers stay json.Number so prices and quantities are written back unchanged
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	if items, ok := value.([]interface{}); ok {
+		for _, item := range items {
+			s.shapeItem(item)
+		}
+	} else {
+		s.shapeItem(value)
+	}
+	return value, nil
+}
+
+func (s responseShape) shapeItem(item interface{}) {
+	obj, ok := item.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if s.fields != nil {
+		for key := range obj {
+			if !s.fields[key] {
+				delete(obj, key)
+			}
+		}
+	}
+	if s.summary {
+		summarizeFields(obj)
+	}
+}
+
+// summarizeFields shortens summarized text fields in obj and every object nested in it
+func summarizeFields(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if text, ok := field.(string); ok {
+				for _, name := range summarizedFields {
+					if key == name {
+						v[key] = summarizeText(text)
+					}
+				}
+				continue
+			}
+			summarizeFields(field)
+		}
+	case []interface{}:
+		for _, item := range v {
+			summarizeFields(item)
+		}
+	}
+}
+
+// summarizeText keeps the first paragraph of text, cut at a word boundary to at most
+// summaryTextLength characters with an ellipsis
+func summarizeText(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.Index(text, "\n\n"); i >= 0 {
+		text = strings.TrimRightFunc(text[:i], unicode.IsSpace) + " …"
+	}
+	runes := []rune(text)
+	if len(runes) <= summaryTextLength {
+		return text
+	}
+
+	cut := summaryTextLength
+	for i := cut; i > summaryTextLength/2; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace) + " …"
+}
+
+// shapedJSONResponse writes data as JSON, shaped by the request's fields and summary
+// parameters
+func (h *Handler) shapedJSONResponse(w http.ResponseWriter, r *http.Request, data interface{}) {
+	shape := parseResponseShape(r)
+	if shape.isZero() {
+		h.jsonResponse(w, data)
+		return
+	}
+
+	shaped, err := shape.apply(data)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.jsonResponse(w, shaped)
+}
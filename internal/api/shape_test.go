@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func shapeTestRecommendations() []models.Recommendation {
+	reasoning := strings.Repeat("Margins keep expanding while the valuation stays reasonable. ", 10) +
+		"\n\nSecond paragraph with the risks."
+	return []models.Recommendation{
+		{ID: uuid.New(), Symbol: "AAPL", Action: models.RecommendationActionBuy, Quantity: decimal.NewFromInt(10), Confidence: 80, Reasoning: reasoning},
+		{ID: uuid.New(), Symbol: "MSFT", Action: models.RecommendationActionHold, Confidence: 55, Reasoning: "Short."},
+	}
+}
+
+func shapedResponse(t *testing.T, target string, data interface{}) []map[string]interface{} {
+	t.Helper()
+	h := NewHandler(nil, nil)
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	w := httptest.NewRecorder()
+
+	h.shapedJSONResponse(w, req, data)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var items []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &items); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return items
+}
+
+func TestShapedJSONResponse_Fields(t *testing.T) {
+	items := shapedResponse(t, "/api/recommendations?fields=id,symbol,%20action,unknown", shapeTestRecommendations())
+
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	for _, item := range items {
+		if len(item) != 3 {
+			t.Errorf("expected only id, symbol and action, got %v", item)
+		}
+		for _, field := range []string{"id", "symbol", "action"} {
+			if _, ok := item[field]; !ok {
+				t.Errorf("expected field %q in %v", field, item)
+			}
+		}
+	}
+}
+
+func TestShapedJSONResponse_Summary(t *testing.T) {
+	recs := shapeTestRecommendations()
+	items := shapedResponse(t, "/api/recommendations?summary=true", recs)
+
+	long := items[0]["reasoning"].(string)
+	if len([]rune(long)) > summaryTextLength+2 {
+		t.Errorf("expected reasoning cut to %d characters, got %d", summaryTextLength, len([]rune(long)))
+	}
+	if !strings.HasSuffix(long, " …") {
+		t.Errorf("expected an ellipsis, got %q", long)
+	}
+	if strings.Contains(long, "Second paragraph") {
+		t.Error("expected only the first paragraph to be kept")
+	}
+	if items[1]["reasoning"] != "Short." {
+		t.Errorf("expected short reasoning unchanged, got %v", items[1]["reasoning"])
+	}
+	// Other fields are untouched, including decimal quantities
+	if items[0]["quantity"] != "10" || items[0]["symbol"] != "AAPL" {
+		t.Errorf("expected other fields unchanged, got %v", items[0])
+	}
+}
+
+func TestShapedJSONResponse_SummaryNested(t *testing.T) {
+	similar := []models.SimilarAnalysis{{Recommendation: shapeTestRecommendations()[0], Similarity: 0.92}}
+	items := shapedResponse(t, "/api/similar?symbol=AAPL&summary=true&fields=recommendation,similarity", similar)
+
+	rec := items[0]["recommendation"].(map[string]interface{})
+	if reasoning := rec["reasoning"].(string); !strings.HasSuffix(reasoning, " …") {
+		t.Errorf("expected nested reasoning summarized, got %q", reasoning)
+	}
+	if items[0]["similarity"] != 0.92 {
+		t.Errorf("expected similarity kept, got %v", items[0]["similarity"])
+	}
+}
+
+func TestShapedJSONResponse_Unshaped(t *testing.T) {
+	recs := shapeTestRecommendations()
+	items := shapedResponse(t, "/api/recommendations", recs)
+
+	if items[0]["reasoning"] != recs[0].Reasoning {
+		t.Error("expected reasoning unchanged without shaping parameters")
+	}
+}
+
+func TestSummarizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"short", "Buy on strength.", "Buy on strength."},
+		{"paragraphs", "First point.\n\nSecond point.", "First point. …"},
+		{"word boundary", strings.Repeat("word ", 100), strings.TrimSpace(strings.Repeat("word ", 56)) + " …"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeText(tt.text); got != tt.want {
+				t.Errorf("summarizeText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
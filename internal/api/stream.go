@@ -0,0 +1,200 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"trade-machine/events"
+
+	"github.com/gorilla/websocket"
+)
+
+// streamEventTypes are the domain events pushed to /api/ws clients
+var streamEventTypes = []events.Type{
+	events.RecommendationCreated,
+	events.RecommendationApproved,
+	events.RecommendationRejected,
+	events.AgentRunStarted,
+	events.AgentRunCompleted,
+	events.ScreenerCompleted,
+}
+
+const (
+	// streamClientBuffer is how many events can wait for a client before it is disconnected
+	// as too slow
+	streamClientBuffer = 64
+	// streamWriteWait bounds how long a write to a client may take
+	streamWriteWait = 10 * time.Second
+	// streamPongWait is how long a client may stay silent before it is considered gone; pings
+	// are sent often enough for a live client to answer in time
+	streamPongWait     = 60 * time.Second
+	streamPingInterval = streamPongWait * 9 / 10
+)
+
+// streamClient is one WebSocket connection and the events it asked for
+type streamClient struct {
+	send  chan []byte
+	types map[events.Type]bool // nil for every streamed type
+}
+
+func (c *streamClient) wants(t events.Type) bool {
+	return c.types == nil || c.types[t]
+}
+
+// streamHub fans events from the bus out to connected WebSocket clients. A client that
+// falls behind is disconnected rather than allowed to hold up the others; it can reconnect
+// and reload what it missed.
+type streamHub struct {
+	mu      sync.Mutex
+	clients map[*streamClient]struct{}
+}
+
+func newStreamHub() *streamHub {
+	return &streamHub{clients: make(map[*streamClient]struct{})}
+}
+
+func (s *streamHub) add(c *streamClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients[c] = struct{}{}
+}
+
+// remove drops a client and closes its queue, which ends its writer
+func (s *streamHub) remove(c *streamClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.clients[c]; ok {
+		delete(s.clients, c)
+		close(c.send)
+	}
+}
+
+// publish sends an event to every client that wants it, as the event's JSON
+func (s *streamHub) publish(e events.Event) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		logger.Warn("failed to encode streamed event", "type", e.Type, "error", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.clients {
+		if !c.wants(e.Type) {
+			continue
+		}
+		select {
+		case c.send <- data:
+		default:
+			logger.Warn("disconnecting slow event stream client", "type", e.Type)
+			delete(s.clients, c)
+			close(c.send)
+		}
+	}
+}
+
+// parseStreamTypes reads a comma-separated list of event types to stream, returning nil
+// for all of them
+func parseStreamTypes(param string) (map[events.Type]bool, error) {
+	if strings.TrimSpace(param) == "" {
+		return nil, nil
+	}
+	types := make(map[events.Type]bool)
+	for _, name := range strings.Split(param, ",") {
+		t := events.Type(strings.TrimSpace(name))
+		if !slices.Contains(streamEventTypes, t) {
+			return nil, fmt.Errorf("unknown event type %q", t)
+		}
+		types[t] = true
+	}
+	return types, nil
+}
+
+// streamOriginAllowed accepts upgrades from the app's own pages, from the configured CORS
+// origin, and from clients that send no Origin, such as scripts and SDKs
+func streamOriginAllowed(allowedOrigins string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || allowedOrigins == "*" || origin == allowedOrigins {
+			return true
+		}
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
+
+// HandleEventStream upgrades to a WebSocket and pushes recommendation, agent run and
+// screener events as they happen, each as the JSON {"type", "time", "payload"}. ?types=
+// limits the stream to a comma-separated list of event types. Messages from the client
+// are ignored.
+func (h *Handler) HandleEventStream(w http.ResponseWriter, r *http.Request) {
+	types, err := parseStreamTypes(r.URL.Query().Get("types"))
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	upgrader := websocket.Upgrader{CheckOrigin: streamOriginAllowed(h.cfg.HTTP.CORSAllowedOrigins)}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already written the error response
+		return
+	}
+
+	client := &streamClient{send: make(chan []byte, streamClientBuffer), types: types}
+	h.stream.add(client)
+
+	go readStream(conn, func() { h.stream.remove(client) })
+	writeStream(conn, client.send)
+}
+
+// readStream discards client messages, keeping the read deadline moving with each pong,
+// and calls done once the connection fails or closes
+func readStream(conn *websocket.Conn, done func()) {
+	defer done()
+	conn.SetReadLimit(512)
+	conn.SetReadDeadline(time.Now().Add(streamPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(streamPongWait))
+	})
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writeStream sends queued events and keepalive pings until the queue is closed or a
+// write fails, then closes the connection
+func writeStream(conn *websocket.Conn, send <-chan []byte) {
+	ping := time.NewTicker(streamPingInterval)
+	defer func() {
+		ping.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case data, ok := <-send:
+			conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
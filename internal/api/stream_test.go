@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"trade-machine/events"
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// dialStream connects to the event stream of a test server, waiting until the hub has
+// registered the client
+func dialStream(t *testing.T, h *Handler, query string) *websocket.Conn {
+	t.Helper()
+	server := httptest.NewServer(NewRouter(h, h.cfg))
+	t.Cleanup(server.Close)
+
+	before := streamClientCount(h.stream)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/ws"+query, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	deadline := time.Now().Add(2 * time.Second)
+	for streamClientCount(h.stream) == before {
+		if time.Now().After(deadline) {
+			t.Fatal("client was not registered with the hub")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return conn
+}
+
+func streamClientCount(s *streamHub) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.clients)
+}
+
+func TestHandler_EventStream(t *testing.T) {
+	h := NewHandler(testApp(nil), testConfig())
+	all := dialStream(t, h, "")
+	screenerOnly := dialStream(t, h, "?types=screener.completed")
+
+	rec := &models.Recommendation{ID: uuid.New(), Symbol: "AAPL", Action: models.RecommendationActionBuy}
+	h.stream.publish(events.Event{Type: events.RecommendationCreated, Time: time.Now(), Payload: rec})
+	h.stream.publish(events.Event{Type: events.ScreenerCompleted, Time: time.Now(), Payload: &models.ScreenerRun{ID: uuid.New()}})
+
+	var got struct {
+		Type    events.Type           `json:"type"`
+		Payload models.Recommendation `json:"payload"`
+	}
+	all.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := all.ReadJSON(&got); err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	if got.Type != events.RecommendationCreated || got.Payload.ID != rec.ID {
+		t.Errorf("first message = %+v, want the created recommendation", got)
+	}
+
+	screenerOnly.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var filtered events.Event
+	if err := screenerOnly.ReadJSON(&filtered); err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	if filtered.Type != events.ScreenerCompleted {
+		t.Errorf("filtered client got %s, want only screener events", filtered.Type)
+	}
+}
+
+func TestHandler_EventStream_UnknownType(t *testing.T) {
+	router := testRouter(testApp(nil))
+	req := httptest.NewRequest(http.MethodGet, "/api/ws?types=trade.filled", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an event type that isn't streamed, got %d", w.Code)
+	}
+}
+
+func TestStreamHub_DisconnectsSlowClient(t *testing.T) {
+	hub := newStreamHub()
+	slow := &streamClient{send: make(chan []byte, 1)}
+	hub.add(slow)
+
+	e := events.Event{Type: events.AgentRunStarted, Payload: &models.AgentRun{ID: uuid.New()}}
+	hub.publish(e)
+	hub.publish(e)
+
+	if streamClientCount(hub) != 0 {
+		t.Error("slow client still registered after its queue overflowed")
+	}
+	var msg events.Event
+	if err := json.Unmarshal(<-slow.send, &msg); err != nil || msg.Type != events.AgentRunStarted {
+		t.Errorf("queued message = %+v, %v; want the first event", msg, err)
+	}
+	if _, open := <-slow.send; open {
+		t.Error("slow client's queue was not closed")
+	}
+	hub.remove(slow) // Removing twice is harmless
+}
+
+func TestStreamOriginAllowed(t *testing.T) {
+	tests := []struct {
+		allowed, origin string
+		want            bool
+	}{
+		{"http://localhost:3000", "", true},
+		{"http://localhost:3000", "http://example.com", true}, // Same host as the request
+		{"http://localhost:3000", "http://localhost:3000", true},
+		{"http://localhost:3000", "http://evil.test", false},
+		{"*", "http://evil.test", true},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/api/ws", nil)
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		if got := streamOriginAllowed(tt.allowed)(req); got != tt.want {
+			t.Errorf("allowed %q, origin %q: got %v, want %v", tt.allowed, tt.origin, got, tt.want)
+		}
+	}
+}
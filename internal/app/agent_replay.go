@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// AgentRunReplayer is implemented by portfolio managers that can send a past agent run's
+// prompt to its agent again
+type AgentRunReplayer interface {
+	ReplayAgentRun(ctx context.Context, run *models.AgentRun, dryRun bool) (*models.AgentRunReplay, error)
+}
+
+// ReplayAgentRun sends a past agent run's stored prompt to its agent again with the
+// current system prompt and model, returning the new output next to the old for prompt
+// tuning. With dryRun set nothing is saved; otherwise the replay is recorded as a new
+// agent run. It shares the analysis slots with AnalyzeStock, returning
+// ErrAnalysisQueueFull rather than waiting.
+func (a *App) ReplayAgentRun(ctx context.Context, id string, dryRun bool) (*models.AgentRunReplay, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	runID, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+	replayer, ok := a.portfolioManager.(AgentRunReplayer)
+	if !ok {
+		return nil, fmt.Errorf("%w: no agents are registered", models.ErrAgentRunNotReplayable)
+	}
+
+	ctx, span := observability.StartSpan(a.requestContext(ctx), "app.ReplayAgentRun", "run_id", id)
+	defer span.End()
+
+	run, err := a.repo.GetAgentRun(ctx, runID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if run == nil {
+		return nil, models.ErrAgentRunNotFound
+	}
+
+	select {
+	case a.analysisSem <- struct{}{}:
+		defer func() { <-a.analysisSem }()
+	default:
+		span.RecordError(ErrAnalysisQueueFull)
+		return nil, ErrAnalysisQueueFull
+	}
+
+	replay, err := replayer.ReplayAgentRun(ctx, run, dryRun)
+	span.RecordError(err)
+	return replay, err
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+)
+
+// agentRunRepo serves a single agent run by ID
+type agentRunRepo struct {
+	RepositoryInterface
+	run *models.AgentRun
+}
+
+func (r *agentRunRepo) GetAgentRun(ctx context.Context, id uuid.UUID) (*models.AgentRun, error) {
+	if r.run == nil || r.run.ID != id {
+		return nil, nil
+	}
+	return r.run, nil
+}
+
+// stubReplayer replays every run with a fixed score
+type stubReplayer struct {
+	mockPortfolioManager
+}
+
+func (stubReplayer) ReplayAgentRun(ctx context.Context, run *models.AgentRun, dryRun bool) (*models.AgentRunReplay, error) {
+	replay := models.NewAgentRunReplay(run, models.AgentRunOutput{Score: 10})
+	replay.DryRun = dryRun
+	return replay, nil
+}
+
+func TestApp_ReplayAgentRun(t *testing.T) {
+	ctx := context.Background()
+	run := models.NewAgentRun(models.AgentTypeNews, "AAPL")
+
+	t.Run("replays the run", func(t *testing.T) {
+		a := New(testConfig(), &agentRunRepo{run: run}, &stubReplayer{}, nil)
+		a.ctx = ctx
+		replay, err := a.ReplayAgentRun(ctx, run.ID.String(), true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if replay.RunID != run.ID || !replay.DryRun || replay.Replayed.Score != 10 {
+			t.Errorf("replay = %+v, want a dry run of the stored run", replay)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		a := New(testConfig(), &agentRunRepo{run: run}, &stubReplayer{}, nil)
+		a.ctx = ctx
+		if _, err := a.ReplayAgentRun(ctx, uuid.NewString(), true); !errors.Is(err, models.ErrAgentRunNotFound) {
+			t.Errorf("expected ErrAgentRunNotFound, got %v", err)
+		}
+	})
+
+	t.Run("manager cannot replay", func(t *testing.T) {
+		a := New(testConfig(), &agentRunRepo{run: run}, &mockPortfolioManager{}, nil)
+		if _, err := a.ReplayAgentRun(ctx, run.ID.String(), true); !errors.Is(err, models.ErrAgentRunNotReplayable) {
+			t.Errorf("expected ErrAgentRunNotReplayable, got %v", err)
+		}
+	})
+
+	t.Run("respects analysis budget", func(t *testing.T) {
+		a := New(testConfig(), &agentRunRepo{run: run}, &stubReplayer{}, nil)
+		a.ctx = ctx
+		for i := 0; i < a.AnalysisSemCapacity(); i++ {
+			a.analysisSem <- struct{}{}
+		}
+		if _, err := a.ReplayAgentRun(ctx, run.ID.String(), true); !errors.Is(err, ErrAnalysisQueueFull) {
+			t.Errorf("expected ErrAnalysisQueueFull, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/google/uuid"
+)
+
+// AnalyzeStockAsync starts analyzing symbol in the background and returns the job to
+// poll at once, so slow LLM calls don't hold the request open. It takes an analysis slot
+// like AnalyzeStock, returning ErrAnalysisQueueFull rather than waiting for one.
+func (a *App) AnalyzeStockAsync(symbol string) (*models.AnalysisJob, error) {
+	if a.portfolioManager == nil {
+		return nil, fmt.Errorf("portfolio manager not initialized")
+	}
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	select {
+	case a.analysisSem <- struct{}{}:
+	default:
+		return nil, ErrAnalysisQueueFull
+	}
+
+	job := models.NewAnalysisJob(symbol)
+	if err := a.repo.CreateAnalysisJob(a.ctx, job); err != nil {
+		<-a.analysisSem
+		return nil, err
+	}
+	go a.runAnalysisJob(job)
+
+	observability.Info("analysis job started", "job_id", job.ID, "symbol", symbol)
+	return job, nil
+}
+
+// runAnalysisJob analyzes the job's symbol in the slot taken by AnalyzeStockAsync and
+// records the outcome. The outcome is saved even when the app is shutting down so the job
+// isn't left running.
+func (a *App) runAnalysisJob(job *models.AnalysisJob) {
+	rec, err := a.analyzeInSlot(models.WithAnalysisJob(a.ctx, job.ID), job.Symbol)
+	if err != nil {
+		observability.Warn("analysis job failed", "job_id", job.ID, "symbol", job.Symbol, "error", err)
+		job.Fail(err)
+	} else {
+		job.Complete(rec)
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(a.ctx), 5*time.Second)
+	defer cancel()
+	if err := a.repo.UpdateAnalysisJob(ctx, job); err != nil {
+		observability.Error("failed to save analysis job", "job_id", job.ID, "error", err)
+	}
+}
+
+// GetAnalysisJob returns an analysis job with the status of each agent run so far and,
+// once it completes, its recommendation, or nil if not found. Agent runs are written
+// through the write buffer, so they can lag the job briefly.
+func (a *App) GetAnalysisJob(id string) (*models.AnalysisJob, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	jobID, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := a.repo.GetAnalysisJob(a.ctx, jobID)
+	if err != nil || job == nil {
+		return nil, err
+	}
+	agents, err := a.repo.GetAgentRunsByJob(a.ctx, uuid.UUID(jobID))
+	if err != nil {
+		return nil, err
+	}
+	job.Agents = agents
+	if job.RecommendationID != nil {
+		rec, err := a.withDisclaimer(a.repo.GetRecommendation(a.ctx, *job.RecommendationID))
+		if err != nil {
+			return nil, err
+		}
+		job.Recommendation = rec
+	}
+	return job, nil
+}
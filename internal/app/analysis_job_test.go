@@ -0,0 +1,179 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+)
+
+// jobRepo keeps analysis jobs in memory, recording an agent run for each job it's asked about
+type jobRepo struct {
+	RepositoryInterface
+	mu   sync.Mutex
+	jobs map[uuid.UUID]models.AnalysisJob
+	recs map[uuid.UUID]*models.Recommendation
+}
+
+func (r *jobRepo) CreateAnalysisJob(ctx context.Context, job *models.AnalysisJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *jobRepo) UpdateAnalysisJob(ctx context.Context, job *models.AnalysisJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := *job
+	saved.Recommendation = nil
+	r.jobs[job.ID] = saved
+	if job.Recommendation != nil {
+		r.recs[job.Recommendation.ID] = job.Recommendation
+	}
+	return nil
+}
+
+func (r *jobRepo) GetAnalysisJob(ctx context.Context, id uuid.UUID) (*models.AnalysisJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+func (r *jobRepo) FailInterruptedAnalysisJobs(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for id, job := range r.jobs {
+		if job.Status == models.AnalysisJobStatusRunning {
+			job.Fail(errors.New("interrupted: the app restarted before the analysis finished"))
+			r.jobs[id] = job
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *jobRepo) GetAgentRunsByJob(ctx context.Context, jobID uuid.UUID) ([]models.AgentRun, error) {
+	run := models.NewAgentRun(models.AgentTypeNews, "AAPL")
+	run.JobID = &jobID
+	return []models.AgentRun{*run}, nil
+}
+
+func (r *jobRepo) GetRecommendation(ctx context.Context, id uuid.UUID) (*models.Recommendation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.recs[id], nil
+}
+
+// jobManager checks each analysis runs for a job before analyzing it once released
+type jobManager struct {
+	release chan struct{}
+	fail    bool
+}
+
+func (m *jobManager) AnalyzeSymbol(ctx context.Context, symbol string) (*models.Recommendation, error) {
+	<-m.release
+	if _, ok := models.AnalysisJobFromContext(ctx); !ok {
+		return nil, errors.New("analysis not tied to a job")
+	}
+	if m.fail {
+		return nil, errors.New("agents failed")
+	}
+	return models.NewRecommendation(symbol, models.RecommendationActionBuy, ""), nil
+}
+
+func TestApp_AnalyzeStockAsync(t *testing.T) {
+	cfg := testConfig()
+	cfg.Agent.ConcurrencyLimit = 1
+	repo := &jobRepo{jobs: make(map[uuid.UUID]models.AnalysisJob), recs: make(map[uuid.UUID]*models.Recommendation)}
+	manager := &jobManager{release: make(chan struct{})}
+	a := New(cfg, repo, manager, nil)
+	a.ctx = context.Background()
+
+	job, err := a.AnalyzeStockAsync("AAPL")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Status != models.AnalysisJobStatusRunning {
+		t.Errorf("Status = %s, want running", job.Status)
+	}
+
+	// The job holds the only analysis slot until it finishes
+	if _, err := a.AnalyzeStockAsync("MSFT"); !errors.Is(err, ErrAnalysisQueueFull) {
+		t.Errorf("expected ErrAnalysisQueueFull while the job runs, got %v", err)
+	}
+
+	running, err := a.GetAnalysisJob(job.ID.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if running.Status != models.AnalysisJobStatusRunning || len(running.Agents) != 1 || running.Recommendation != nil {
+		t.Errorf("job = %+v, want running with its agent runs", running)
+	}
+
+	manager.release <- struct{}{}
+	var done *models.AnalysisJob
+	waitFor(t, func() bool {
+		done, _ = a.GetAnalysisJob(job.ID.String())
+		return done.Status != models.AnalysisJobStatusRunning
+	})
+	if done.Status != models.AnalysisJobStatusCompleted || done.Recommendation == nil || done.Recommendation.Symbol != "AAPL" {
+		t.Errorf("job = %+v, want completed with its recommendation", done)
+	}
+	if done.Recommendation.Disclaimer == "" {
+		t.Error("expected the recommendation to carry the disclaimer")
+	}
+
+	manager.fail = true
+	failing, err := a.AnalyzeStockAsync("MSFT")
+	if err != nil {
+		t.Fatalf("expected the slot to be released, got %v", err)
+	}
+	manager.release <- struct{}{}
+	waitFor(t, func() bool {
+		done, _ = a.GetAnalysisJob(failing.ID.String())
+		return done.Status != models.AnalysisJobStatusRunning
+	})
+	if done.Status != models.AnalysisJobStatusFailed || done.ErrorMessage != "agents failed" {
+		t.Errorf("job = %+v, want failed with its error", done)
+	}
+
+	if unknown, err := a.GetAnalysisJob(uuid.New().String()); err != nil || unknown != nil {
+		t.Errorf("GetAnalysisJob(unknown) = %v, %v; want nil", unknown, err)
+	}
+}
+
+func TestApp_Startup_FailsInterruptedAnalysisJobs(t *testing.T) {
+	running := models.NewAnalysisJob("AAPL")
+	finished := models.NewAnalysisJob("MSFT")
+	finished.Complete(nil)
+	repo := &jobRepo{
+		jobs: map[uuid.UUID]models.AnalysisJob{running.ID: *running, finished.ID: *finished},
+		recs: make(map[uuid.UUID]*models.Recommendation),
+	}
+	// The stub repository only keeps jobs, so no background jobs may start
+	cfg := testConfig()
+	cfg.RecommendationExpiry.Enabled = false
+	a := New(cfg, repo, nil, nil)
+	a.Startup(context.Background())
+
+	interrupted, err := a.GetAnalysisJob(running.ID.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if interrupted.Status != models.AnalysisJobStatusFailed || interrupted.ErrorMessage == "" || interrupted.CompletedAt == nil {
+		t.Errorf("job = %+v, want failed as interrupted", interrupted)
+	}
+	if done, _ := a.GetAnalysisJob(finished.ID.String()); done.Status != models.AnalysisJobStatusCompleted {
+		t.Errorf("finished job status = %s, want it left completed", done.Status)
+	}
+}
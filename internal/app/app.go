@@ -2,29 +2,116 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"trade-machine/compliance"
 	"trade-machine/config"
+	"trade-machine/events"
 	"trade-machine/internal/settings"
 	"trade-machine/models"
 	"trade-machine/observability"
+	"trade-machine/repository"
 	"trade-machine/services"
 
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
+// ErrAnalysisQueueFull is returned when every analysis slot is in use. It is the models
+// error, so triggers outside the app such as the price watcher can recognize it.
+var ErrAnalysisQueueFull = models.ErrAnalysisQueueFull
+
+// ErrReconciliationUnavailable is returned when no reconciler is configured
+var ErrReconciliationUnavailable = errors.New("reconciliation not available: Alpaca and database required")
+
+// ErrSimilarityUnavailable is returned when no similarity index is configured
+var ErrSimilarityUnavailable = errors.New("similar analyses not available: OpenAI and database required")
+
+// ErrRiskStatsUnavailable is returned when no risk stats service is configured
+var ErrRiskStatsUnavailable = errors.New("risk stats not available: Alpaca required")
+
+// ErrDividendsUnavailable is returned when no dividend tracker is configured
+var ErrDividendsUnavailable = errors.New("dividend income not available: FMP and database required")
+
 // RepositoryInterface defines the repository operations needed by App
 type RepositoryInterface interface {
 	Close()
 	Health(ctx context.Context) error
+	UnitOfWork(ctx context.Context, fn func(tx repository.RepositoryInterface) error) error
 	GetRecommendations(ctx context.Context, status models.RecommendationStatus, limit int) ([]models.Recommendation, error)
 	GetRecommendation(ctx context.Context, id uuid.UUID) (*models.Recommendation, error)
+	GetLatestRecommendationForSymbol(ctx context.Context, symbol string) (*models.Recommendation, error)
+	GetRecommendationsForSymbol(ctx context.Context, symbol string, limit int) ([]models.Recommendation, error)
 	GetPendingRecommendations(ctx context.Context) ([]models.Recommendation, error)
-	ApproveRecommendation(ctx context.Context, id uuid.UUID) error
-	RejectRecommendation(ctx context.Context, id uuid.UUID) error
+	GetExecutedRecommendations(ctx context.Context) ([]models.Recommendation, error)
+	ApproveRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error
+	RejectRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error
+	ReleaseRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error
+	ExpireStaleRecommendations(ctx context.Context, createdBefore time.Time, ids []uuid.UUID) ([]uuid.UUID, error)
+	GetRecommendationEvents(ctx context.Context, id uuid.UUID) ([]models.RecommendationEvent, error)
+	UpdateRecommendationOverride(ctx context.Context, id uuid.UUID, override *models.RecommendationOverride, expectedVersion int) error
+	GetRecommendationTranches(ctx context.Context, recID uuid.UUID) ([]models.RecommendationTranche, error)
+	GetPendingTranches(ctx context.Context) ([]models.RecommendationTranche, error)
+	ClaimTranche(ctx context.Context, id uuid.UUID) error
+	ReleaseTranche(ctx context.Context, id uuid.UUID) error
 	GetPositions(ctx context.Context) ([]models.Position, error)
+	GetPositionBySymbol(ctx context.Context, symbol string) (*models.Position, error)
 	GetTrades(ctx context.Context, limit int) ([]models.Trade, error)
+	GetTrade(ctx context.Context, id uuid.UUID) (*models.Trade, error)
+	GetTotalFees(ctx context.Context) (decimal.Decimal, error)
+	GetExecutedTradesBetween(ctx context.Context, start, end time.Time) ([]models.Trade, error)
+	GetAgentRun(ctx context.Context, id uuid.UUID) (*models.AgentRun, error)
 	GetAgentRuns(ctx context.Context, agentType models.AgentType, limit int) ([]models.AgentRun, error)
+	GetFailedAgentRuns(ctx context.Context, limit int) ([]models.AgentRun, error)
+	ImportAgentRun(ctx context.Context, run *models.AgentRun) (bool, error)
+	GetAgentRunsByJob(ctx context.Context, jobID uuid.UUID) ([]models.AgentRun, error)
+	GetRecentRunsForSymbol(ctx context.Context, symbol string, limit int) ([]models.AgentRun, error)
+	CreateAnalysisJob(ctx context.Context, job *models.AnalysisJob) error
+	UpdateAnalysisJob(ctx context.Context, job *models.AnalysisJob) error
+	GetAnalysisJob(ctx context.Context, id uuid.UUID) (*models.AnalysisJob, error)
+	FailInterruptedAnalysisJobs(ctx context.Context) (int64, error)
+	GetActivity(ctx context.Context, after *models.ActivityCursor, limit int) ([]models.ActivityEvent, error)
+	CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+	GetAuditLog(ctx context.Context, limit int) ([]models.AuditEntry, error)
+	CreateAPIToken(ctx context.Context, token *models.APIToken, tokenHash string) error
+	GetAPITokenByHash(ctx context.Context, tokenHash string) (*models.APIToken, error)
+	GetAPITokens(ctx context.Context) ([]models.APIToken, error)
+	TouchAPIToken(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+	DeleteAPIToken(ctx context.Context, id uuid.UUID) (bool, error)
+	GetSymbolListEntries(ctx context.Context) ([]models.SymbolListEntry, error)
+	AddSymbolListEntry(ctx context.Context, entry *models.SymbolListEntry) error
+	GetScreenerPresets(ctx context.Context) ([]models.ScreenerPreset, error)
+	SaveScreenerPreset(ctx context.Context, preset *models.ScreenerPreset) error
+	DeleteScreenerPreset(ctx context.Context, name string) (bool, error)
+	RemoveSymbolListEntry(ctx context.Context, list models.SymbolListType, symbol string) error
+	GetReconciliationReports(ctx context.Context, limit int) ([]models.ReconciliationReport, error)
+	GetReconciliationReport(ctx context.Context, id uuid.UUID) (*models.ReconciliationReport, error)
+	SavePortfolioReview(ctx context.Context, review *models.PortfolioReview) error
+	GetPortfolioReviews(ctx context.Context, limit int) ([]models.PortfolioReview, error)
+	GetPortfolioReview(ctx context.Context, id uuid.UUID) (*models.PortfolioReview, error)
+	SaveBacktestRun(ctx context.Context, run *models.BacktestRun) error
+	GetBacktestRun(ctx context.Context, id uuid.UUID) (*models.BacktestRun, error)
+	SaveWatchlist(ctx context.Context, watchlist *models.Watchlist) error
+	GetWatchlists(ctx context.Context) ([]models.Watchlist, error)
+	GetAPIUsage(ctx context.Context, since time.Time) ([]models.APIUsage, error)
+	GetLLMUsage(ctx context.Context, since time.Time) ([]models.LLMUsageRow, error)
+	GetLLMCostSince(ctx context.Context, since time.Time) (float64, error)
+	SaveLLMUsage(ctx context.Context, usage []models.LLMUsage) error
+	SavePortfolioSnapshot(ctx context.Context, snapshot *models.PortfolioSnapshot) error
+	GetPortfolioSnapshots(ctx context.Context, since time.Time) ([]models.PortfolioSnapshot, error)
+	GetLatestPortfolioSnapshot(ctx context.Context) (*models.PortfolioSnapshot, error)
+	GetProviderAlerts(ctx context.Context, activeOnly bool, limit int) ([]models.ProviderAlert, error)
+	DismissProviderAlert(ctx context.Context, id uuid.UUID) error
+	ImportProviderAlert(ctx context.Context, alert *models.ProviderAlert) (bool, error)
+	GetSchemaVersion(ctx context.Context) (int64, error)
+	GetDataHealth(ctx context.Context, staleBefore time.Time) (*models.DataHealthReport, error)
+	FixDataHealth(ctx context.Context, staleBefore time.Time) (map[string]int64, error)
 }
 
 // PortfolioManagerInterface defines the analysis operations
@@ -34,11 +121,13 @@ type PortfolioManagerInterface interface {
 
 // ScreenerInterface defines the screener operations
 type ScreenerInterface interface {
-	RunScreen(ctx context.Context) (*models.ScreenerRun, error)
+	RunScreen(ctx context.Context, overrides *models.ScreenerFilters) (*models.ScreenerRun, error)
 	GetLatestPicks(ctx context.Context) ([]models.ScreenerCandidate, error)
 	GetLatestRun(ctx context.Context) (*models.ScreenerRun, error)
 	GetRunHistory(ctx context.Context, limit int) ([]models.ScreenerRun, error)
 	GetRun(ctx context.Context, id uuid.UUID) (*models.ScreenerRun, error)
+	RetryFailed(ctx context.Context, id uuid.UUID) (*models.ScreenerRun, error)
+	ReplayRun(ctx context.Context, id uuid.UUID, req models.ScreenerReplayRequest) (*models.ScreenerRunComparison, error)
 }
 
 // ScreenerRepositoryInterface defines the repository operations needed for screener initialization
@@ -48,7 +137,66 @@ type ScreenerRepositoryInterface interface {
 	GetScreenerRun(ctx context.Context, id uuid.UUID) (*models.ScreenerRun, error)
 	GetLatestScreenerRun(ctx context.Context) (*models.ScreenerRun, error)
 	GetScreenerRunHistory(ctx context.Context, limit int) ([]models.ScreenerRun, error)
+	SaveScreenerUniverse(ctx context.Context, runID uuid.UUID, entries []models.ScreenerUniverseEntry) error
+	GetScreenerUniverse(ctx context.Context, runID uuid.UUID) ([]models.ScreenerUniverseEntry, error)
 	CreateRecommendation(ctx context.Context, rec *models.Recommendation) error
+	GetSymbolListEntries(ctx context.Context) ([]models.SymbolListEntry, error)
+	GetScreenerPreset(ctx context.Context, name string) (*models.ScreenerPreset, error)
+}
+
+// PriceWatcherInterface defines the background price-move watcher
+type PriceWatcherInterface interface {
+	Run(ctx context.Context)
+}
+
+// ReconcilerInterface defines the monthly broker reconciliation job
+type ReconcilerInterface interface {
+	Run(ctx context.Context)
+	Reconcile(ctx context.Context, month time.Time) (*models.ReconciliationReport, error)
+}
+
+// CallLedgerInterface defines the job that writes recorded API calls
+type CallLedgerInterface interface {
+	Run(ctx context.Context)
+}
+
+// AlertNotifierInterface defines the job that saves provider alerts
+type AlertNotifierInterface interface {
+	Run(ctx context.Context)
+}
+
+// EventBusInterface defines the bus domain events are published on
+type EventBusInterface interface {
+	Subscribe(name string, handle events.Handler, types ...events.Type)
+	Close()
+}
+
+// SimilarityIndexInterface defines the job that embeds past analyses and searches them
+type SimilarityIndexInterface interface {
+	Run(ctx context.Context)
+	Similar(ctx context.Context, symbol string, limit int) ([]models.SimilarAnalysis, error)
+}
+
+// RiskStatsInterface defines the service computing trailing volatility, beta and drawdown
+type RiskStatsInterface interface {
+	Get(ctx context.Context, symbol string) (*models.RiskStats, error)
+}
+
+// DividendTrackerInterface defines the service tracking dividends on held positions
+type DividendTrackerInterface interface {
+	Income(ctx context.Context) (*models.DividendIncome, error)
+}
+
+// BackupManagerInterface defines the job that backs up the database on a schedule
+type BackupManagerInterface interface {
+	Run(ctx context.Context)
+	Status() *models.BackupStatus
+}
+
+// WriteBufferInterface defines the job that flushes buffered non-critical writes
+type WriteBufferInterface interface {
+	Run(ctx context.Context)
+	Depth() int
 }
 
 // ScreenerFactory creates a new screener instance with the given FMP service
@@ -64,31 +212,193 @@ type App struct {
 	alpacaService    services.AlpacaServiceInterface
 	settings         *settings.Store
 	analysisSem      chan struct{}
+	feeSchedule      models.FeeSchedule
+	// Brokers trades can be routed to by name, and the one new orders go to
+	brokerMu     sync.RWMutex
+	brokers      map[string]services.BrokerService
+	activeBroker string
 	// For dynamic screener initialization when FMP key is updated
 	screenerRepo    ScreenerRepositoryInterface
 	screenerFactory ScreenerFactory
 	// useMockServices prevents dynamic service reinitialization (for e2e testing)
 	useMockServices bool
+	// clients resolves API clients per request context, when configured
+	clients *services.ClientProvider
+	// chatLLM answers questions about symbols; nil when no LLM is configured
+	chatLLM services.LLMService
+	// Background jobs, stopped on shutdown
+	priceWatcher   PriceWatcherInterface
+	reconciler     ReconcilerInterface
+	callLedger     CallLedgerInterface
+	ledgerDone     chan struct{} // Closed once the call ledger has written its last calls
+	alertNotifier  AlertNotifierInterface
+	alertsDone     chan struct{} // Closed once the alert notifier has saved its last alerts
+	eventBus       EventBusInterface
+	similarity     SimilarityIndexInterface
+	riskStats      RiskStatsInterface
+	dividends      DividendTrackerInterface
+	backtester     BacktestEngineInterface
+	rebalancer     RebalancerInterface
+	rebalancing    sync.Mutex // Held while a rebalance places its orders
+	reviewing      sync.Mutex // Held while a portfolio review analyzes positions
+	activeReview   atomic.Pointer[models.PortfolioReview]
+	coveredCalls   CoveredCallAdvisorInterface
+	backups        BackupManagerInterface
+	stopBackground context.CancelFunc
+	// Flushed after the other background jobs stop, since they write through it
+	writeBuffer     WriteBufferInterface
+	writeBufferDone chan struct{}
+	stopWriteBuffer context.CancelFunc
+	// Attached to recommendations and reports; trading waits for its acknowledgment
+	disclaimer *compliance.Disclaimer
+	// Recent quotes, hover summaries and chart bars, so tickers repeated across the UI share one fetch
+	quotes     *ttlCache[*models.Quote]
+	quickLooks *ttlCache[*models.QuickLook]
+	priceBars  *ttlCache[*models.PriceHistory]
+	// Dashboard data preloaded on startup, each entry served to the first read only
+	warm *ttlCache[any]
+	// Batch analyses in progress or recently finished, by ID
+	batches *ttlCache[*batchJob]
+	// Limits batch analyses to fewer analyses at once than analysisSem allows
+	batchSem chan struct{}
+	// Starts screener runs on a cron schedule
+	screenerSchedule *screenerScheduler
+	// Set from the status menu to hold price-move re-analyses, cache refreshes and tranches
+	automationPaused atomic.Bool
 }
 
 // New creates a new App application struct
 func New(cfg *config.Config, repo RepositoryInterface, manager PortfolioManagerInterface, alpaca services.AlpacaServiceInterface) *App {
-	return &App{
+	a := &App{
 		cfg:              cfg,
 		repo:             repo,
 		portfolioManager: manager,
 		alpacaService:    alpaca,
 		analysisSem:      make(chan struct{}, cfg.Agent.ConcurrencyLimit),
+		disclaimer:       newDisclaimer(cfg.Compliance),
+		quotes:           newTTLCache[*models.Quote](quoteTTL),
+		quickLooks:       newTTLCache[*models.QuickLook](quickLookTTL),
+		priceBars:        newTTLCache[*models.PriceHistory](priceBarsTTL),
+		warm:             newTTLCache[any](warmupTTL),
+		batches:          newTTLCache[*batchJob](batchRetention),
+		batchSem:         make(chan struct{}, batchConcurrencyLimit(cfg.Agent.ConcurrencyLimit)),
+		brokers:          make(map[string]services.BrokerService),
+		activeBroker:     cfg.Broker.Provider,
+	}
+	if a.activeBroker == "" {
+		a.activeBroker = models.BrokerAlpaca
+	}
+	if alpaca != nil {
+		a.brokers[models.BrokerAlpaca] = alpaca
+	}
+	a.screenerSchedule = newScreenerScheduler(a, cfg.Screener.Schedule)
+	return a
+}
+
+// failInterruptedAnalysisJobs fails the analysis jobs a previous run left running. No job of
+// this process has started yet, so none of them will finish.
+func (a *App) failInterruptedAnalysisJobs() {
+	n, err := a.repo.FailInterruptedAnalysisJobs(a.ctx)
+	if err != nil {
+		observability.Warn("failed to mark interrupted analysis jobs", "error", err)
+		return
+	}
+	if n > 0 {
+		observability.Info("marked interrupted analysis jobs failed", "count", n)
 	}
 }
 
 // Startup is called when the app starts
 func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
+	if a.repo != nil {
+		a.failInterruptedAnalysisJobs()
+	}
+	if a.writeBuffer != nil {
+		bufferCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+		a.stopWriteBuffer = stop
+		a.writeBufferDone = make(chan struct{})
+		go func() {
+			defer close(a.writeBufferDone)
+			a.writeBuffer.Run(bufferCtx)
+		}()
+	}
+	if a.cfg.Warmup.Enabled {
+		a.warmUp(time.Duration(a.cfg.Warmup.TimeoutSeconds) * time.Second)
+	}
+	trading := a.repo != nil && a.alpacaService != nil
+	// The screener can be configured later from settings, so the schedule runs whenever it could
+	screening := a.screener != nil || a.screenerFactory != nil
+	expiring := a.repo != nil && a.cfg.RecommendationExpiry.Enabled
+	snapshotting := trading && a.cfg.PortfolioSnapshots.Enabled
+	if a.priceWatcher == nil && a.reconciler == nil && a.callLedger == nil && a.alertNotifier == nil && a.similarity == nil && a.backups == nil && !a.cfg.CacheRefresh.Enabled && !trading && !screening && !expiring {
+		return
+	}
+	bgCtx, cancel := context.WithCancel(ctx)
+	a.stopBackground = cancel
+	if a.priceWatcher != nil {
+		go a.priceWatcher.Run(bgCtx)
+	}
+	if a.reconciler != nil {
+		go a.reconciler.Run(bgCtx)
+	}
+	if a.similarity != nil {
+		go a.similarity.Run(bgCtx)
+	}
+	if a.backups != nil {
+		go a.backups.Run(bgCtx)
+	}
+	if a.cfg.CacheRefresh.Enabled {
+		go newCacheRefresher(a, a.cfg.CacheRefresh).Run(bgCtx)
+	}
+	if trading {
+		go newTrancheExecutor(a).Run(bgCtx)
+	}
+	if screening {
+		go a.screenerSchedule.Run(bgCtx)
+	}
+	if expiring {
+		go newRecommendationExpirer(a, a.cfg.RecommendationExpiry).Run(bgCtx)
+	}
+	if snapshotting {
+		go newPortfolioSnapshotter(a, a.cfg.PortfolioSnapshots).Run(bgCtx)
+	}
+	if a.callLedger != nil {
+		a.ledgerDone = make(chan struct{})
+		go func() {
+			defer close(a.ledgerDone)
+			a.callLedger.Run(bgCtx)
+		}()
+	}
+	if a.alertNotifier != nil {
+		a.alertsDone = make(chan struct{})
+		go func() {
+			defer close(a.alertsDone)
+			a.alertNotifier.Run(bgCtx)
+		}()
+	}
 }
 
 // Shutdown is called when the app is closing
 func (a *App) Shutdown(ctx context.Context) {
+	// Deliver queued events first, so subscribers such as the alert notifier see them
+	// before their own jobs stop
+	if a.eventBus != nil {
+		a.eventBus.Close()
+	}
+	if a.stopBackground != nil {
+		a.stopBackground()
+	}
+	if a.ledgerDone != nil {
+		<-a.ledgerDone
+	}
+	if a.alertsDone != nil {
+		<-a.alertsDone
+	}
+	if a.stopWriteBuffer != nil {
+		a.stopWriteBuffer()
+		<-a.writeBufferDone
+	}
 	if a.repo != nil {
 		a.repo.Close()
 	}
@@ -112,11 +422,11 @@ func (a *App) Screener() ScreenerInterface {
 // ScreenerStatus returns information about what's needed to enable the screener
 func (a *App) ScreenerStatus() ScreenerStatus {
 	status := ScreenerStatus{
-		Available:        a.screener != nil,
-		HasFMPKey:        a.screener != nil || a.screenerFactory != nil, // If factory is set, FMP can be configured dynamically
-		HasPortfolio:     a.portfolioManager != nil,
-		HasDatabase:      a.repo != nil,
-		MissingServices:  []string{},
+		Available:       a.screener != nil,
+		HasFMPKey:       a.screener != nil || a.screenerFactory != nil, // If factory is set, FMP can be configured dynamically
+		HasPortfolio:    a.portfolioManager != nil,
+		HasDatabase:     a.repo != nil,
+		MissingServices: []string{},
 	}
 
 	if !status.HasDatabase {
@@ -141,6 +451,87 @@ type ScreenerStatus struct {
 	MissingServices []string
 }
 
+// SetPriceWatcher sets the price-move watcher (optional dependency), started by Startup
+func (a *App) SetPriceWatcher(w PriceWatcherInterface) {
+	a.priceWatcher = w
+}
+
+// SetFeeSchedule sets the schedule used to estimate commission and fees on new trades
+func (a *App) SetFeeSchedule(schedule models.FeeSchedule) {
+	a.feeSchedule = schedule
+}
+
+// SetReconciler sets the broker reconciliation job (optional dependency), started by Startup
+func (a *App) SetReconciler(r ReconcilerInterface) {
+	a.reconciler = r
+}
+
+// SetCallLedger sets the job that writes recorded API calls (optional dependency), started by Startup
+func (a *App) SetCallLedger(l CallLedgerInterface) {
+	a.callLedger = l
+}
+
+// SetWriteBuffer sets the buffer non-critical writes go through (optional dependency),
+// started by Startup and flushed last on shutdown
+func (a *App) SetWriteBuffer(b WriteBufferInterface) {
+	a.writeBuffer = b
+}
+
+// WriteBufferDepth returns the number of buffered writes waiting for the database, or 0
+// without a write buffer
+func (a *App) WriteBufferDepth() int {
+	if a.writeBuffer == nil {
+		return 0
+	}
+	return a.writeBuffer.Depth()
+}
+
+// SetAlertNotifier sets the job that saves provider alerts (optional dependency), started by Startup
+func (a *App) SetAlertNotifier(n AlertNotifierInterface) {
+	a.alertNotifier = n
+}
+
+// SetEventBus sets the bus domain events are published on (optional dependency), closed by Shutdown
+func (a *App) SetEventBus(b EventBusInterface) {
+	a.eventBus = b
+}
+
+// SubscribeEvents registers handle for the given domain event types on the event bus.
+// Without a bus nothing is ever delivered.
+func (a *App) SubscribeEvents(name string, handle events.Handler, types ...events.Type) {
+	if a.eventBus != nil {
+		a.eventBus.Subscribe(name, handle, types...)
+	}
+}
+
+// SetSimilarityIndex sets the index of past analyses (optional dependency), started by Startup
+func (a *App) SetSimilarityIndex(x SimilarityIndexInterface) {
+	a.similarity = x
+}
+
+// SetRiskStats sets the service computing per-symbol risk stats (optional dependency)
+func (a *App) SetRiskStats(s RiskStatsInterface) {
+	a.riskStats = s
+}
+
+// SetDividendTracker sets the service tracking dividend income (optional dependency)
+func (a *App) SetDividendTracker(t DividendTrackerInterface) {
+	a.dividends = t
+}
+
+// SetBackupManager sets the scheduled database backup job (optional dependency), started by Startup
+func (a *App) SetBackupManager(m BackupManagerInterface) {
+	a.backups = m
+}
+
+// BackupStatus returns the state of the backup job, or nil when backups are disabled
+func (a *App) BackupStatus() *models.BackupStatus {
+	if a.backups == nil {
+		return nil
+	}
+	return a.backups.Status()
+}
+
 // SetScreenerFactory sets the factory function and repository for dynamic screener creation
 func (a *App) SetScreenerFactory(factory ScreenerFactory, repo ScreenerRepositoryInterface) {
 	a.screenerFactory = factory
@@ -172,7 +563,11 @@ func (a *App) InitializeScreenerWithFMPKey(apiKey string) error {
 		return fmt.Errorf("portfolio manager not available")
 	}
 
-	fmpService := services.NewFMPService(apiKey)
+	// A keyed client picks up this and later key changes without another rebuild
+	var fmpService services.FMPServiceInterface = services.NewFMPService(apiKey)
+	if a.clients != nil {
+		fmpService = a.clients.FMP()
+	}
 	screener := a.screenerFactory(fmpService, a.portfolioManager, a.screenerRepo, &a.cfg.Screener)
 	a.screener = screener
 
@@ -183,6 +578,23 @@ func (a *App) InitializeScreenerWithFMPKey(apiKey string) error {
 // SetSettings sets the settings store (optional dependency)
 func (a *App) SetSettings(s *settings.Store) {
 	a.settings = s
+	if s == nil {
+		return
+	}
+	// A strategy chosen during onboarding takes precedence over AGENT_STRATEGY
+	if strategy := s.Onboarding().Strategy; strategy != "" {
+		a.applyStrategy(strategy)
+	}
+	// As does a screener schedule set from the API over SCREENER_SCHEDULE
+	if saved := s.ScreenerSchedule(); saved != nil {
+		if err := a.screenerSchedule.set(models.ScreenerSchedule{Cron: saved.Cron, Analyze: saved.Analyze}); err != nil {
+			observability.Warn("ignoring saved screener schedule", "error", err)
+		}
+	}
+	// And a broker chosen from the API over BROKER
+	if saved := s.Broker(); saved != nil {
+		a.applySavedBroker(saved)
+	}
 }
 
 // Settings returns the settings store
@@ -190,28 +602,149 @@ func (a *App) Settings() *settings.Store {
 	return a.settings
 }
 
+// SetClientProvider sets the provider that resolves API clients per request context
+func (a *App) SetClientProvider(p *services.ClientProvider) {
+	a.clients = p
+}
+
+// settingsServices maps the service names used by clients to their settings entries
+var settingsServices = map[string]settings.ServiceName{
+	services.BreakerOpenAI:       settings.ServiceOpenAI,
+	services.BreakerAnthropic:    settings.ServiceAnthropic,
+	services.BreakerOllama:       settings.ServiceOllama,
+	services.BreakerAlpaca:       settings.ServiceAlpaca,
+	services.BreakerAlphaVantage: settings.ServiceAlphaVantage,
+	services.BreakerNewsAPI:      settings.ServiceNewsAPI,
+	services.BreakerFMP:          settings.ServiceFMP,
+}
+
+// NewKeyResolver resolves service credentials from, in order, the keys carried by the
+// request context, the settings store and the environment configuration. The store is
+// read on every call, so keys saved in settings apply without a restart.
+func NewKeyResolver(cfg *config.Config, store *settings.Store) services.KeyResolver {
+	return func(ctx context.Context, service string) (services.Credentials, bool) {
+		if name, ok := settingsServices[service]; ok {
+			if key := store.Resolve(ctx, name); key != nil {
+				return services.Credentials{APIKey: key.APIKey, APISecret: key.APISecret, BaseURL: key.BaseURL, Model: key.ModelID}, true
+			}
+		}
+
+		var creds services.Credentials
+		switch service {
+		case services.BreakerOpenAI:
+			creds.APIKey = cfg.OpenAI.APIKey
+		case services.BreakerAnthropic:
+			creds.APIKey = cfg.Anthropic.APIKey
+		case services.BreakerOllama:
+			// Ollama takes no key; it is configured by a base URL or by being chosen
+			creds.BaseURL = cfg.Ollama.BaseURL
+			return creds, creds.BaseURL != "" || cfg.LLM.Provider == services.BreakerOllama
+		case services.BreakerAlpaca:
+			if !cfg.HasAlpaca() {
+				return creds, false
+			}
+			creds = services.Credentials{APIKey: cfg.Alpaca.APIKey, APISecret: cfg.Alpaca.APISecret, BaseURL: cfg.Alpaca.BaseURL}
+		case services.BreakerAlphaVantage:
+			creds.APIKey = cfg.AlphaVantage.APIKey
+		case services.BreakerNewsAPI:
+			creds.APIKey = cfg.NewsAPI.APIKey
+		case services.BreakerFMP:
+			creds.APIKey = cfg.FMP.APIKey
+		}
+		return creds, creds.APIKey != ""
+	}
+}
+
+// requestContext returns the app's context carrying the trace and the API keys of the
+// request in ctx, so work that outlives the request still continues its trace and uses
+// the caller's keys
+func (a *App) requestContext(ctx context.Context) context.Context {
+	appCtx := observability.ContextWithSpan(a.ctx, ctx)
+	if keys := settings.APIKeysFromContext(ctx); keys != nil {
+		appCtx = settings.WithAPIKeys(appCtx, keys)
+	}
+	return appCtx
+}
+
 // AnalyzeStock runs all agents to analyze a stock and generate a recommendation
 func (a *App) AnalyzeStock(symbol string) (*models.Recommendation, error) {
+	return a.AnalyzeStockFor(a.ctx, symbol)
+}
+
+// AnalyzeStockFor analyzes a stock as AnalyzeStock does on behalf of a request,
+// continuing the trace in ctx. The analysis still runs on the app's context, so it
+// isn't cut short when the client goes away.
+func (a *App) AnalyzeStockFor(ctx context.Context, symbol string) (*models.Recommendation, error) {
+	if a.portfolioManager == nil {
+		return nil, fmt.Errorf("portfolio manager not initialized")
+	}
+
+	ctx, span := observability.StartSpan(a.requestContext(ctx), "app.AnalyzeStock", "symbol", symbol)
+	defer span.End()
+
+	select {
+	case a.analysisSem <- struct{}{}:
+	default:
+		span.RecordError(ErrAnalysisQueueFull)
+		return nil, ErrAnalysisQueueFull
+	}
+
+	rec, err := a.withDisclaimer(a.analyzeInSlot(ctx, symbol))
+	span.RecordError(err)
+	return rec, err
+}
+
+// analyzeInSlot analyzes a symbol in the analysis slot the caller has taken, releasing the
+// slot once every agent has returned. A partial recommendation comes back while agents are
+// still running, so its slot is held until the portfolio manager reports them done. Warmed
+// data is dropped once the analysis saves a recommendation and again when its agents finish.
+func (a *App) analyzeInSlot(ctx context.Context, symbol string) (*models.Recommendation, error) {
+	release := sync.OnceFunc(func() {
+		a.invalidateWarm()
+		<-a.analysisSem
+	})
+	rec, err := a.portfolioManager.AnalyzeSymbol(models.WithAgentsDone(ctx, release), symbol)
+	if rec != nil {
+		a.invalidateWarm()
+	}
+	if rec == nil || !rec.Partial {
+		release()
+	}
+	return rec, err
+}
+
+// AnalyzeTriggered re-analyzes a symbol on behalf of a background trigger such as a
+// price move. It shares the analysis slots with AnalyzeStock, returning
+// ErrAnalysisQueueFull rather than waiting, and records reason on the recommendation.
+func (a *App) AnalyzeTriggered(ctx context.Context, symbol, reason string) (*models.Recommendation, error) {
 	if a.portfolioManager == nil {
 		return nil, fmt.Errorf("portfolio manager not initialized")
 	}
+	if err := a.CheckSymbolAllowed(symbol); err != nil {
+		return nil, err
+	}
 
 	select {
 	case a.analysisSem <- struct{}{}:
-		defer func() { <-a.analysisSem }()
 	default:
-		return nil, fmt.Errorf("analysis queue full, too many concurrent requests - try again later")
+		return nil, ErrAnalysisQueueFull
 	}
 
-	return a.portfolioManager.AnalyzeSymbol(a.ctx, symbol)
+	return a.analyzeInSlot(models.WithTriggerReason(ctx, reason), symbol)
 }
 
 // GetRecommendations returns recent recommendations
 func (a *App) GetRecommendations(limit int) ([]models.Recommendation, error) {
+	return a.GetRecommendationsByStatus("", limit)
+}
+
+// GetRecommendationsByStatus returns recent recommendations with a status, such as expired,
+// or of every status when it is empty
+func (a *App) GetRecommendationsByStatus(status models.RecommendationStatus, limit int) ([]models.Recommendation, error) {
 	if a.repo == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
-	return a.repo.GetRecommendations(a.ctx, "", limit)
+	return a.withDisclaimers(a.repo.GetRecommendations(a.ctx, status, limit))
 }
 
 // GetPendingRecommendations returns pending recommendations awaiting approval
@@ -219,118 +752,1232 @@ func (a *App) GetPendingRecommendations() ([]models.Recommendation, error) {
 	if a.repo == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
-	return a.repo.GetPendingRecommendations(a.ctx)
+	if recs, ok := takeWarm[[]models.Recommendation](a, warmPending); ok {
+		return recs, nil
+	}
+	return a.withDisclaimers(a.repo.GetPendingRecommendations(a.ctx))
 }
 
-// ApproveRecommendation approves a recommendation for execution
-func (a *App) ApproveRecommendation(id string) error {
+// ApproveRecommendation approves a pending recommendation for execution. expectedVersion is
+// the version the caller last saw, or models.AnyVersion to skip the concurrency check. With
+// EXECUTION_MODE=auto the order is placed as well, returning ErrAutoExecutionFailed if
+// the approval stood but the order did not. Approving one in any other status, such as one
+// already executing, fails, so auto-execution can't place its order twice.
+func (a *App) ApproveRecommendation(id string, expectedVersion int) error {
 	if a.repo == nil {
 		return fmt.Errorf("database not initialized")
 	}
+	a.invalidateWarm()
+	if err := a.checkDisclaimerAcknowledged(); err != nil {
+		return err
+	}
 
-	uuid, err := ParseUUID(id)
+	recID, err := ParseUUID(id)
 	if err != nil {
 		return err
 	}
 
-	return a.repo.ApproveRecommendation(a.ctx, uuid)
-}
-
-// RejectRecommendation rejects a recommendation
-func (a *App) RejectRecommendation(id string) error {
-	if a.repo == nil {
-		return fmt.Errorf("database not initialized")
+	rec, err := a.repo.GetRecommendation(a.ctx, recID)
+	if err != nil {
+		return err
+	}
+	if rec == nil {
+		return fmt.Errorf("%w: %s", models.ErrRecommendationNotFound, id)
+	}
+	if rec.Status != models.RecommendationStatusPending {
+		return fmt.Errorf("%w: %s recommendation is %s", models.ErrRecommendationNotExecutable, rec.Symbol, rec.Status)
+	}
+	if err := a.checkRiskRules(rec); err != nil {
+		return err
+	}
+	// Approve the version that was checked, so auto-execution places the order for it
+	if expectedVersion == models.AnyVersion {
+		expectedVersion = rec.Version
 	}
 
-	uuid, err := ParseUUID(id)
-	if err != nil {
+	if err := a.repo.ApproveRecommendation(a.ctx, recID, expectedVersion); err != nil {
 		return err
 	}
+	a.publishRecommendation(events.RecommendationApproved, recID)
+	return a.autoExecute(rec, expectedVersion+1)
+}
 
-	return a.repo.RejectRecommendation(a.ctx, uuid)
+// publishRecommendation announces a recommendation's change of status with the row as
+// saved, so subscribers see its new status and version
+func (a *App) publishRecommendation(t events.Type, id uuid.UUID) {
+	rec, err := a.repo.GetRecommendation(a.ctx, id)
+	if err != nil || rec == nil {
+		observability.Warn("failed to load recommendation for event", "event", t, "id", id, "error", err)
+		return
+	}
+	events.Publish(t, rec)
 }
 
-// GetRecommendationByID returns a single recommendation by ID
-func (a *App) GetRecommendationByID(id string) (*models.Recommendation, error) {
+// EditRecommendation records a user's edits to the quantity, order type and limit price of a
+// pending recommendation. The edits are stored as overrides next to the agent-suggested values
+// and checked against the risk rules when the recommendation is approved.
+func (a *App) EditRecommendation(id string, edit models.RecommendationOverride, expectedVersion int) (*models.Recommendation, error) {
 	if a.repo == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
 
-	uuid, err := ParseUUID(id)
+	recID, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rec, err := a.repo.GetRecommendation(a.ctx, recID)
 	if err != nil {
 		return nil, err
 	}
+	if rec == nil {
+		return nil, fmt.Errorf("%w: %s", models.ErrRecommendationNotFound, id)
+	}
+	if err := rec.Edit(edit); err != nil {
+		return nil, err
+	}
+	if err := a.repo.UpdateRecommendationOverride(a.ctx, recID, rec.Override, expectedVersion); err != nil {
+		return nil, err
+	}
+	a.invalidateWarm()
 
-	return a.repo.GetRecommendation(a.ctx, uuid)
+	edited, err := a.repo.GetRecommendation(a.ctx, recID)
+	if err != nil {
+		return nil, err
+	}
+	if edited == nil {
+		return nil, fmt.Errorf("%w: %s", models.ErrRecommendationNotFound, id)
+	}
+	return a.withDisclaimer(edited, nil)
 }
 
-// GetPositions returns all current positions
-func (a *App) GetPositions() ([]models.Position, error) {
-	if a.repo == nil {
-		return nil, fmt.Errorf("database not initialized")
+// checkRiskRules validates a user-edited recommendation against the position sizing rules.
+// Agent-suggested values were sized by the position sizer and are not checked again, except
+// for shorts, whose borrow availability can change before approval, and the liquidity limit.
+// Account limits are only applied when Alpaca is configured.
+func (a *App) checkRiskRules(rec *models.Recommendation) error {
+	if err := a.checkLiquidity(rec); err != nil {
+		return err
 	}
-	return a.repo.GetPositions(a.ctx)
+
+	short := rec.Action == models.RecommendationActionShort
+	if rec.Override == nil && !short {
+		return nil
+	}
+	if short && !a.cfg.PositionSizing.AllowShorts {
+		return fmt.Errorf("%w: short selling is disabled", models.ErrRiskRuleViolation)
+	}
+
+	limits := models.RiskLimits{
+		MaxPositionPercent:     a.cfg.PositionSizing.MaxPositionPercent,
+		MaxShares:              a.cfg.PositionSizing.MaxShares,
+		ShortMarginRequirement: a.cfg.PositionSizing.ShortMarginRequirement,
+	}
+	price := rec.EntryPrice
+	if limitPrice := rec.EffectiveLimitPrice(); limitPrice != nil {
+		price = *limitPrice
+	}
+
+	var account *models.Account
+	var position *models.Position
+	if _, broker := a.broker(); broker != nil {
+		var err error
+		if account, err = broker.GetAccount(a.ctx); err != nil {
+			return fmt.Errorf("failed to check risk rules: %w", err)
+		}
+		if rec.Action == models.RecommendationActionSell || rec.Action == models.RecommendationActionCover {
+			if position, err = a.brokerPosition(rec.Symbol); err != nil {
+				return fmt.Errorf("failed to check risk rules: %w", err)
+			}
+		}
+		if price.IsZero() && a.alpacaService != nil {
+			quote, err := a.fetchQuote(rec.Symbol)
+			if err != nil {
+				return fmt.Errorf("failed to check risk rules: %w", err)
+			}
+			price = quote.Price()
+		}
+		if short {
+			availability, err := broker.GetShortAvailability(a.ctx, rec.Symbol)
+			if err != nil {
+				return fmt.Errorf("failed to check risk rules: %w", err)
+			}
+			if availability != nil {
+				if err := availability.Check(a.cfg.PositionSizing.AllowHardToBorrow); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return limits.Check(rec.Action, rec.EffectiveQuantity(), price, account, position)
 }
 
-// GetTrades returns recent trades
-func (a *App) GetTrades(limit int) ([]models.Trade, error) {
+// brokerPosition returns the active broker's open position in symbol, or nil when there is
+// none. Positions are listed rather than fetched by symbol because the broker reports a
+// missing position as an error.
+func (a *App) brokerPosition(symbol string) (*models.Position, error) {
+	_, broker := a.broker()
+	if broker == nil {
+		return nil, nil
+	}
+	positions, err := broker.GetPositions(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range positions {
+		if positions[i].Symbol == symbol && positions[i].Quantity.IsPositive() {
+			return &positions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// checkLiquidity rejects an order for more than POSITION_MAX_ADV_PERCENT of the symbol's
+// average daily volume when POSITION_ADV_MODE is reject. In warn mode the warning is only
+// added to the reasoning when the recommendation is generated. An unavailable ADV is
+// logged and the order is allowed, so a bars outage doesn't block every approval.
+func (a *App) checkLiquidity(rec *models.Recommendation) error {
+	sizing := a.cfg.PositionSizing
+	if sizing.ADVMode != "reject" || sizing.MaxADVPercent <= 0 || a.alpacaService == nil || rec.Action == models.RecommendationActionHold {
+		return nil
+	}
+
+	adv, err := a.alpacaService.GetAverageDailyVolume(a.ctx, rec.Symbol, sizing.ADVLookbackDays)
+	if err != nil {
+		observability.Warn("average daily volume unavailable, skipping the liquidity check",
+			"symbol", rec.Symbol,
+			"error", err)
+		return nil
+	}
+	_, err = models.LiquidityLimit{MaxADVPercent: sizing.MaxADVPercent, Reject: true}.Check(rec.Symbol, rec.EffectiveQuantity(), adv)
+	return err
+}
+
+// RejectRecommendation rejects a recommendation, with the same version check as ApproveRecommendation
+func (a *App) RejectRecommendation(id string, expectedVersion int) error {
 	if a.repo == nil {
-		return nil, fmt.Errorf("database not initialized")
+		return fmt.Errorf("database not initialized")
 	}
-	return a.repo.GetTrades(a.ctx, limit)
+	a.invalidateWarm()
+
+	uuid, err := ParseUUID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := a.repo.RejectRecommendation(a.ctx, uuid, expectedVersion); err != nil {
+		return err
+	}
+	a.publishRecommendation(events.RecommendationRejected, uuid)
+	return nil
 }
 
-// GetAgentRuns returns recent agent runs
-func (a *App) GetAgentRuns(limit int) ([]models.AgentRun, error) {
+// ExecuteRecommendation approves (if still pending) and executes a recommendation: it places
+// the order (a market order unless the user edited it) and records the trade, the executed
+// status, and the resulting position in one transaction. expectedVersion is the version the
+// caller last saw, or models.AnyVersion.
+//
+// The broker order cannot be rolled back, so the recommendation is first claimed as executing
+// with a version check and committed: a concurrent execution fails the claim instead of
+// placing a second order. The claim is released back to approved if the order is refused.
+// If recording the trade still fails after the order was accepted, the recommendation stays
+// executing and the order ID is logged for reconciliation.
+func (a *App) ExecuteRecommendation(id string, expectedVersion int) (*models.Trade, error) {
 	if a.repo == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
-	return a.repo.GetAgentRuns(a.ctx, "", limit)
-}
+	brokerName, broker := a.broker()
+	if broker == nil {
+		return nil, fmt.Errorf("trading not available: %s not configured", brokerName)
+	}
+	if err := a.checkDisclaimerAcknowledged(); err != nil {
+		return nil, err
+	}
+	a.invalidateWarm()
 
-// RunScreener triggers a new screener run
-func (a *App) RunScreener() (*models.ScreenerRun, error) {
-	if a.screener == nil {
-		return nil, fmt.Errorf("screener not initialized")
+	recID, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
 	}
-	return a.screener.RunScreen(a.ctx)
-}
 
-// GetLatestScreenerRun returns the most recent screener run
-func (a *App) GetLatestScreenerRun() (*models.ScreenerRun, error) {
-	if a.screener == nil {
-		return nil, fmt.Errorf("screener not initialized")
+	rec, err := a.repo.GetRecommendation(a.ctx, recID)
+	if err != nil {
+		return nil, err
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("recommendation %s not found", id)
+	}
+	if !rec.Executable() {
+		return nil, fmt.Errorf("%w: %s %s recommendation is %s", models.ErrRecommendationNotExecutable, rec.Action, rec.Symbol, rec.Status)
+	}
+	tranches, err := a.repo.GetRecommendationTranches(a.ctx, recID)
+	if err != nil {
+		return nil, err
+	}
+	if models.NewSplitProgress(tranches).Pending > 0 {
+		return nil, fmt.Errorf("%w: %s %s recommendation is executing in tranches", models.ErrRecommendationNotExecutable, rec.Action, rec.Symbol)
+	}
+	if err := a.CheckSymbolAllowed(rec.Symbol); err != nil {
+		return nil, err
+	}
+	if rec.Status == models.RecommendationStatusPending {
+		if err := a.checkRiskRules(rec); err != nil {
+			return nil, err
+		}
+	}
+	if expectedVersion == models.AnyVersion {
+		expectedVersion = rec.Version
 	}
-	return a.screener.GetLatestRun(a.ctx)
-}
 
-// GetScreenerRunHistory returns the history of screener runs
-func (a *App) GetScreenerRunHistory(limit int) ([]models.ScreenerRun, error) {
-	if a.screener == nil {
-		return nil, fmt.Errorf("screener not initialized")
+	side := rec.Action.TradeSide()
+	price := rec.EntryPrice
+	limitPrice := rec.EffectiveLimitPrice()
+	if limitPrice != nil {
+		price = *limitPrice
+	}
+	if price.IsZero() && a.alpacaService != nil {
+		quote, err := a.fetchQuote(rec.Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("failed to price order: %w", err)
+		}
+		price = quote.Price()
 	}
-	return a.screener.GetRunHistory(a.ctx, limit)
-}
 
-// GetScreenerRun returns a specific screener run by ID
-func (a *App) GetScreenerRun(id string) (*models.ScreenerRun, error) {
-	if a.screener == nil {
-		return nil, fmt.Errorf("screener not initialized")
+	version := expectedVersion
+	err = a.repo.UnitOfWork(a.ctx, func(tx repository.RepositoryInterface) error {
+		version = expectedVersion
+		if rec.Status == models.RecommendationStatusPending {
+			if err := tx.ApproveRecommendation(a.ctx, recID, version); err != nil {
+				return err
+			}
+			version++
+		}
+		if err := tx.ClaimRecommendation(a.ctx, recID, version); err != nil {
+			return err
+		}
+		version++
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	uuid, err := ParseUUID(id)
+	bracket, err := a.guardPosition(a.ctx, broker, rec, price)
 	if err != nil {
+		if releaseErr := a.repo.ReleaseRecommendation(a.ctx, recID, version); releaseErr != nil {
+			observability.Error("failed to release recommendation after its bracket legs could not be cancelled",
+				"recommendation_id", id, "error", releaseErr)
+		}
 		return nil, err
 	}
 
-	return a.screener.GetRun(a.ctx, uuid)
-}
+	quantity := rec.EffectiveQuantity()
+	orderID, err := broker.PlaceOrder(a.ctx, models.OrderRequest{
+		Symbol:     rec.Symbol,
+		Quantity:   quantity,
+		Side:       side,
+		Type:       rec.EffectiveOrderType(),
+		LimitPrice: limitPrice,
+		Bracket:    bracket,
+	})
+	if err != nil {
+		if releaseErr := a.repo.ReleaseRecommendation(a.ctx, recID, version); releaseErr != nil {
+			observability.Error("failed to release recommendation after the order was refused",
+				"recommendation_id", id, "error", releaseErr)
+		}
+		return nil, fmt.Errorf("failed to place order: %w", err)
+	}
+	recordBracketLegs(a.ctx, broker, orderID, bracket)
 
-// GetTopPicks returns the top picks from the latest completed screener run
-func (a *App) GetTopPicks() ([]models.ScreenerCandidate, error) {
-	if a.screener == nil {
+	trade := models.NewTrade(rec.Symbol, side, quantity, price)
+	trade.AlpacaOrderID = orderID
+	trade.Broker = brokerName
+	a.feeSchedule.Apply(trade)
+	err = a.repo.UnitOfWork(a.ctx, func(tx repository.RepositoryInterface) error {
+		if err := tx.CreateTrade(a.ctx, trade); err != nil {
+			return err
+		}
+		if err := tx.ExecuteRecommendation(a.ctx, recID, trade.ID, version); err != nil {
+			return err
+		}
+		return applyTradeToPosition(a.ctx, tx, trade, rec.Action, bracket)
+	})
+	if err != nil {
+		observability.Error("order placed but execution was rolled back",
+			"recommendation_id", id, "order_id", trade.AlpacaOrderID, "error", err)
+		return nil, err
+	}
+
+	return trade, nil
+}
+
+// bracketFor returns the stop-loss and take-profit legs for an order on rec entered at price,
+// or nil unless EXECUTION_BRACKET_ORDERS is on
+func (a *App) bracketFor(rec *models.Recommendation, price decimal.Decimal) *models.Bracket {
+	if !a.cfg.Execution.BracketOrders {
+		return nil
+	}
+	return rec.Bracket(price, a.cfg.Agent.StopLossPercent, a.cfg.Agent.TakeProfitPercent)
+}
+
+// applyTradeToPosition folds a trade into the stored position for its symbol. Buys open or
+// add to a long position at the weighted average cost including fees; sells reduce it and
+// close it at zero. A short opens or adds to a short position at the average proceeds net of
+// fees, and a cover reduces it. The legs of a bracket order that opens a position become its
+// protective levels.
+func applyTradeToPosition(ctx context.Context, repo repository.RepositoryInterface, trade *models.Trade, action models.RecommendationAction, bracket *models.Bracket) error {
+	pos, err := repo.GetPositionBySymbol(ctx, trade.Symbol)
+	if err != nil {
+		return err
+	}
+
+	if pos == nil {
+		side, entry := models.PositionSideLong, buyCostPerShare(trade)
+		if action == models.RecommendationActionShort {
+			side, entry = models.PositionSideShort, shortProceedsPerShare(trade)
+		} else if trade.Side == models.TradeSideSell || action == models.RecommendationActionCover {
+			return nil // No tracked position to reduce
+		}
+		now := time.Now()
+		pos := &models.Position{
+			ID:            uuid.New(),
+			Symbol:        trade.Symbol,
+			Quantity:      trade.Quantity,
+			AvgEntryPrice: entry,
+			CurrentPrice:  trade.Price,
+			Side:          side,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		pos.Protect(bracket)
+		return repo.CreatePosition(ctx, pos)
+	}
+
+	short := pos.Side == models.PositionSideShort
+	if short == (trade.Side == models.TradeSideSell) {
+		total := pos.Quantity.Add(trade.Quantity)
+		basis := pos.AvgEntryPrice.Mul(pos.Quantity).Add(trade.TotalValue)
+		if short {
+			basis = basis.Sub(trade.TotalFees())
+		} else {
+			basis = basis.Add(trade.TotalFees())
+		}
+		pos.AvgEntryPrice = basis.Div(total).Round(8)
+		pos.Quantity = total
+	} else {
+		pos.Quantity = pos.Quantity.Sub(trade.Quantity)
+		if !pos.Quantity.IsPositive() {
+			return repo.DeletePosition(ctx, pos.ID)
+		}
+	}
+	pos.CurrentPrice = trade.Price
+	pos.UnrealizedPL = pos.CalculateUnrealizedPL()
+	return repo.UpdatePosition(ctx, pos)
+}
+
+// buyCostPerShare returns the price paid per share on a buy including its commission and fees
+func buyCostPerShare(trade *models.Trade) decimal.Decimal {
+	if trade.Quantity.IsZero() {
+		return trade.Price
+	}
+	return trade.TotalValue.Add(trade.TotalFees()).Div(trade.Quantity).Round(8)
+}
+
+// shortProceedsPerShare returns the price received per share on a short sale net of its
+// commission and fees
+func shortProceedsPerShare(trade *models.Trade) decimal.Decimal {
+	if trade.Quantity.IsZero() {
+		return trade.Price
+	}
+	return trade.TotalValue.Sub(trade.TotalFees()).Div(trade.Quantity).Round(8)
+}
+
+// GetRecommendationByID returns a single recommendation by ID
+func (a *App) GetRecommendationByID(id string) (*models.Recommendation, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	uuid, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.withDisclaimer(a.repo.GetRecommendation(a.ctx, uuid))
+}
+
+// GetRecommendationEvents returns the state transitions of a recommendation, oldest first
+func (a *App) GetRecommendationEvents(id string) ([]models.RecommendationEvent, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	uuid, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.repo.GetRecommendationEvents(a.ctx, uuid)
+}
+
+// GetPositions returns all current positions
+func (a *App) GetPositions() ([]models.Position, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if positions, ok := takeWarm[[]models.Position](a, warmPositions); ok {
+		return positions, nil
+	}
+	return a.repo.GetPositions(a.ctx)
+}
+
+// GetPortfolioSummary returns current positions with their totals and the fees paid to date
+func (a *App) GetPortfolioSummary() (*models.PortfolioSummary, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if summary, ok := takeWarm[*models.PortfolioSummary](a, warmPortfolio); ok {
+		return summary, nil
+	}
+	positions, err := a.repo.GetPositions(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+	fees, err := a.repo.GetTotalFees(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewPortfolioSummary(positions, fees), nil
+}
+
+// asOfPriceLookback is how far before an as-of date to look for a closing price,
+// covering weekends and holidays
+const asOfPriceLookback = 10 * 24 * time.Hour
+
+// GetPortfolioAsOf reconstructs positions, cash and value at the close of a past day from
+// executed trades and Alpaca daily closes. Without Alpaca, positions are returned unvalued
+// and without cash.
+func (a *App) GetPortfolioAsOf(date time.Time) (*models.PortfolioAsOf, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	trades, err := a.repo.GetExecutedTradesBetween(a.ctx, time.Time{}, date.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	portfolio := models.ReplayTrades(date, trades)
+
+	if a.alpacaService == nil {
+		portfolio.Warnings = append(portfolio.Warnings, "Alpaca not configured: positions are not valued and cash is unknown")
+		portfolio.Total()
+		return portfolio, nil
+	}
+
+	for i, pos := range portfolio.Positions {
+		bars, err := a.alpacaService.GetBars(a.ctx, pos.Symbol, portfolio.Cutoff().Add(-asOfPriceLookback), portfolio.Cutoff(), marketdata.OneDay)
+		if err != nil || len(bars) == 0 {
+			portfolio.Warnings = append(portfolio.Warnings, fmt.Sprintf("no closing price for %s on or before %s", pos.Symbol, date.Format("2006-01-02")))
+			continue
+		}
+		last := bars[len(bars)-1]
+		portfolio.SetClose(i, decimal.NewFromFloat(last.Close), last.Timestamp)
+	}
+
+	account, err := a.alpacaService.GetAccount(a.ctx)
+	if err != nil {
+		portfolio.Warnings = append(portfolio.Warnings, fmt.Sprintf("cash unknown: %v", err))
+	} else {
+		later, err := a.repo.GetExecutedTradesBetween(a.ctx, portfolio.Cutoff(), time.Now())
+		if err != nil {
+			return nil, err
+		}
+		portfolio.SetCash(account.Cash, later)
+	}
+
+	portfolio.Total()
+	return portfolio, nil
+}
+
+// ErrPortfolioReviewRunning is returned when a portfolio review is asked for while another
+// is still analyzing positions
+var ErrPortfolioReviewRunning = errors.New("a portfolio review is already running")
+
+// ReviewPortfolio starts analyzing every open position in the background and returns the
+// running review at once, to poll with GetPortfolioReview until it is saved with the
+// suggested trims, adds and holds. Only the largest PortfolioReview.MaxPositions positions
+// are analyzed, the rest are listed as skipped, and analyses wait for the slots shared
+// with AnalyzeStock instead of failing when they are all in use. The review runs on the
+// app's context, continuing the trace in ctx, and only one runs at a time.
+func (a *App) ReviewPortfolio(ctx context.Context) (*models.PortfolioReview, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if a.portfolioManager == nil {
+		return nil, fmt.Errorf("portfolio manager not initialized")
+	}
+	if !a.reviewing.TryLock() {
+		return nil, ErrPortfolioReviewRunning
+	}
+
+	jobCtx := a.requestContext(ctx)
+	positions, err := a.repo.GetPositions(jobCtx)
+	if err != nil {
+		a.reviewing.Unlock()
+		return nil, err
+	}
+
+	review := models.NewPortfolioReview()
+	sort.SliceStable(positions, func(i, j int) bool {
+		return positions[i].CurrentPrice.Mul(positions[i].Quantity).Abs().
+			GreaterThan(positions[j].CurrentPrice.Mul(positions[j].Quantity).Abs())
+	})
+	if limit := a.cfg.PortfolioReview.MaxPositions; limit > 0 && len(positions) > limit {
+		for _, pos := range positions[limit:] {
+			review.Skipped = append(review.Skipped, pos.Symbol)
+		}
+		positions = positions[:limit]
+	}
+
+	running := *review
+	running.Running = true
+	a.activeReview.Store(&running)
+	go a.runPortfolioReview(jobCtx, review, positions)
+
+	observability.Info("portfolio review started", "review_id", review.ID, "positions", len(positions))
+	result := running
+	return a.reviewWithDisclaimer(&result, nil)
+}
+
+// runPortfolioReview analyzes the review's positions and saves it, releasing the guard
+// taken by ReviewPortfolio. The review is saved even when the app is shutting down, with
+// the cancelled analyses recorded as failed.
+func (a *App) runPortfolioReview(ctx context.Context, review *models.PortfolioReview, positions []models.Position) {
+	defer a.reviewing.Unlock()
+	defer a.activeReview.Store(nil)
+
+	startTime := time.Now()
+	recs := make([]*models.Recommendation, len(positions))
+	errs := make([]error, len(positions))
+	var wg sync.WaitGroup
+	for i, pos := range positions {
+		wg.Add(1)
+		go func(idx int, symbol string) {
+			defer wg.Done()
+			recs[idx], errs[idx] = a.analyzeQueued(ctx, symbol, "Portfolio review")
+		}(i, pos.Symbol)
+	}
+	wg.Wait()
+
+	for i, pos := range positions {
+		review.AddResult(pos, recs[i], errs[i])
+	}
+	review.Finish(time.Since(startTime).Milliseconds())
+
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := a.repo.SavePortfolioReview(saveCtx, review); err != nil {
+		observability.Error("failed to save portfolio review", "review_id", review.ID, "error", err)
+		return
+	}
+
+	observability.Info("portfolio review completed",
+		"review_id", review.ID,
+		"positions", len(review.Items),
+		"skipped", len(review.Skipped),
+		"failed", len(review.Failed()),
+		"duration_ms", review.DurationMs)
+}
+
+// analyzeQueued analyzes a symbol once an analysis slot frees up, recording reason on
+// the recommendation. It gives up only when ctx is done.
+func (a *App) analyzeQueued(ctx context.Context, symbol, reason string) (*models.Recommendation, error) {
+	select {
+	case a.analysisSem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return a.analyzeInSlot(models.WithTriggerReason(ctx, reason), symbol)
+}
+
+// GetPortfolioReviews returns the most recent portfolio reviews
+func (a *App) GetPortfolioReviews(limit int) ([]models.PortfolioReview, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	reviews, err := a.repo.GetPortfolioReviews(a.ctx, limit)
+	for i := range reviews {
+		reviews[i].Disclaimer = a.disclaimer.Text
+	}
+	return reviews, err
+}
+
+// GetPortfolioReview returns a single portfolio review by ID
+func (a *App) GetPortfolioReview(id string) (*models.PortfolioReview, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	reviewID, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+	if running := a.activeReview.Load(); running != nil && running.ID == uuid.UUID(reviewID) {
+		review := *running
+		return a.reviewWithDisclaimer(&review, nil)
+	}
+	return a.reviewWithDisclaimer(a.repo.GetPortfolioReview(a.ctx, reviewID))
+}
+
+// ImportWatchlist creates a watchlist from a CSV or plain-text ticker list, returning the
+// validation result for every row. Tickers are checked against Alpaca market data when it
+// is configured. With analyze set, up to MaxWatchlistAnalyses non-blocklisted symbols are
+// queued for analysis in the background. They are analyzed one at a time in the slots
+// shared with AnalyzeStock, so an import never holds more than one slot.
+func (a *App) ImportWatchlist(name, data string, analyze bool) (*models.WatchlistImport, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if analyze && a.portfolioManager == nil {
+		return nil, fmt.Errorf("portfolio manager not initialized")
+	}
+
+	rows, err := models.ParseWatchlistImport(data)
+	if err != nil {
+		return nil, err
+	}
+	a.checkWatchlistSymbols(rows)
+
+	if name == "" {
+		name = "Imported " + time.Now().Format("2006-01-02 15:04")
+	}
+	result := &models.WatchlistImport{Watchlist: models.NewWatchlist(name, rows), Rows: rows}
+	if len(result.Watchlist.Symbols) == 0 {
+		return nil, fmt.Errorf("%w: no valid tickers", models.ErrInvalidWatchlistImport)
+	}
+	if err := a.repo.SaveWatchlist(a.ctx, result.Watchlist); err != nil {
+		return nil, err
+	}
+
+	if analyze {
+		for _, symbol := range result.Watchlist.Symbols {
+			if a.CheckSymbolAllowed(symbol) != nil {
+				continue
+			}
+			if len(result.Queued) >= models.MaxWatchlistAnalyses {
+				result.NotQueued = append(result.NotQueued, symbol)
+				continue
+			}
+			result.Queued = append(result.Queued, symbol)
+		}
+		if len(result.Queued) > 0 {
+			go a.analyzeWatchlist(result.Queued, "Watchlist import: "+name)
+		}
+	}
+
+	observability.Info("watchlist imported",
+		"watchlist", name,
+		"symbols", len(result.Watchlist.Symbols),
+		"unknown", result.Count(models.WatchlistRowUnknown),
+		"duplicates", result.Count(models.WatchlistRowDuplicate),
+		"queued", len(result.Queued),
+		"not_queued", len(result.NotQueued))
+
+	return result, nil
+}
+
+// analyzeWatchlist analyzes an import's queued symbols in turn, stopping when the app shuts down
+func (a *App) analyzeWatchlist(symbols []string, reason string) {
+	for _, symbol := range symbols {
+		if _, err := a.analyzeQueued(a.ctx, symbol, reason); err != nil {
+			observability.Warn("watchlist analysis failed", "symbol", symbol, "error", err)
+			if a.ctx.Err() != nil {
+				return
+			}
+		}
+	}
+}
+
+// checkWatchlistSymbols marks valid rows unknown when Alpaca reports it has no quote for
+// them. Rows whose lookup fails otherwise stay valid with a note. Without Alpaca only the
+// ticker format is checked.
+func (a *App) checkWatchlistSymbols(rows []models.WatchlistImportRow) {
+	if a.alpacaService == nil {
+		return
+	}
+
+	sem := make(chan struct{}, 8)
+	var wg sync.WaitGroup
+	for i := range rows {
+		if rows[i].Status != models.WatchlistRowValid {
+			continue
+		}
+		wg.Add(1)
+		go func(row *models.WatchlistImportRow) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			_, err := a.alpacaService.GetQuote(a.ctx, row.Symbol)
+			switch {
+			case errors.Is(err, models.ErrSymbolNotFound):
+				row.Status = models.WatchlistRowUnknown
+				row.Message = fmt.Sprintf("no market data for %s", row.Symbol)
+			case err != nil:
+				// A failed lookup says nothing about the symbol, so it is kept
+				row.Message = "not verified: market data unavailable"
+			}
+		}(&rows[i])
+	}
+	wg.Wait()
+}
+
+// GetWatchlists returns every imported watchlist, newest first
+func (a *App) GetWatchlists() ([]models.Watchlist, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return a.repo.GetWatchlists(a.ctx)
+}
+
+// GetAPIUsage reports outbound API calls per provider and endpoint over the last days days
+func (a *App) GetAPIUsage(days int) (*models.APIUsageReport, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, -(days - 1))
+	usage, err := a.repo.GetAPIUsage(a.ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewAPIUsageReport(since, usage), nil
+}
+
+// maxProviderAlerts is the most provider alerts returned at once
+const maxProviderAlerts = 50
+
+// GetProviderAlerts returns recent provider alerts, newest first. Unless all is set, only
+// alerts that are not dismissed and whose provider has not yet recovered are returned.
+func (a *App) GetProviderAlerts(all bool) ([]models.ProviderAlert, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return a.repo.GetProviderAlerts(a.ctx, !all, maxProviderAlerts)
+}
+
+// DismissProviderAlert hides an alert from the active list
+func (a *App) DismissProviderAlert(id string) error {
+	if a.repo == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	alertID, err := ParseUUID(id)
+	if err != nil {
+		return err
+	}
+	return a.repo.DismissProviderAlert(a.ctx, alertID)
+}
+
+// quoteTTL is how long a quote is served from cache, so the same ticker shown across the
+// dashboard shares one fetch without prices going stale
+const quoteTTL = 15 * time.Second
+
+// GetQuote returns the latest quote for a symbol, including extended-hours prices.
+// The last trade price and its session are merged into the bid/ask quote when available.
+// Quotes are cached for quoteTTL for display; execution and risk checks use fetchQuote.
+func (a *App) GetQuote(symbol string) (*models.Quote, error) {
+	if a.alpacaService == nil {
+		return nil, fmt.Errorf("market data not available: Alpaca not configured")
+	}
+	if cached, ok := a.quotes.get(symbol); ok {
+		quote := *cached
+		return &quote, nil
+	}
+	return a.fetchQuote(symbol)
+}
+
+// fetchQuote loads the latest quote for a symbol from Alpaca and caches it
+func (a *App) fetchQuote(symbol string) (*models.Quote, error) {
+	quote, err := a.alpacaService.GetQuote(a.ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if trade, err := a.alpacaService.GetLatestTrade(a.ctx, symbol); err == nil && trade != nil {
+		quote.Last = trade.Last
+		quote.Volume = trade.Volume
+		if trade.Timestamp.After(quote.Timestamp) {
+			quote.Timestamp = trade.Timestamp
+			quote.Session = trade.Session
+		}
+	}
+
+	if quote.Session == "" {
+		if quote.Timestamp.IsZero() {
+			quote.Session = a.MarketSession()
+		} else {
+			quote.Session = models.MarketSessionAt(quote.Timestamp)
+		}
+	}
+
+	cached := *quote
+	a.quotes.put(symbol, &cached)
+	return quote, nil
+}
+
+// marketStatusSource is implemented by brokers that report the session from their
+// trading calendar, accounting for holidays and early closes
+type marketStatusSource interface {
+	MarketStatus(ctx context.Context) models.MarketStatus
+}
+
+// MarketStatus returns the session currently in progress from the broker's calendar, with
+// the reason and the next open and close. Brokers without a calendar get the standard hours.
+func (a *App) MarketStatus() models.MarketStatus {
+	if source, ok := a.alpacaService.(marketStatusSource); ok {
+		return source.MarketStatus(a.ctx)
+	}
+	return models.StandardHoursStatus(time.Now(), "broker calendar unavailable")
+}
+
+// MarketSession returns the trading session currently in progress
+func (a *App) MarketSession() models.MarketSession {
+	return a.MarketStatus().Session
+}
+
+// GetTrades returns recent trades
+func (a *App) GetTrades(limit int) ([]models.Trade, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return a.repo.GetTrades(a.ctx, limit)
+}
+
+// GetTrade returns a trade with its order as the broker currently reports it. When the
+// broker cannot be reached the stored trade is still returned, with the reason on BrokerError.
+func (a *App) GetTrade(id string) (*models.TradeDetail, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	tradeID, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+	trade, err := a.repo.GetTrade(a.ctx, tradeID)
+	if err != nil || trade == nil {
+		return nil, err
+	}
+
+	detail := &models.TradeDetail{Trade: *trade}
+	broker := a.brokerFor(trade)
+	if trade.AlpacaOrderID == "" || broker == nil {
+		return detail, nil
+	}
+	order, err := broker.GetOrder(a.ctx, trade.AlpacaOrderID)
+	if err != nil {
+		detail.BrokerError = err.Error()
+		return detail, nil
+	}
+	detail.BrokerOrder = order
+	return detail, nil
+}
+
+// GetAgentRuns returns recent agent runs
+func (a *App) GetAgentRuns(limit int) ([]models.AgentRun, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return a.repo.GetAgentRuns(a.ctx, "", limit)
+}
+
+// GetActivity returns the merged account activity feed, newest first, for events after the
+// cursor, or from the newest event when it is nil
+func (a *App) GetActivity(after *models.ActivityCursor, limit int) ([]models.ActivityEvent, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return a.repo.GetActivity(a.ctx, after, limit)
+}
+
+// RecordAudit announces a state-changing action; HandleAuditEvent saves it to the audit log
+func (a *App) RecordAudit(entry *models.AuditEntry) {
+	events.Publish(events.ActionAudited, entry)
+}
+
+// HandleAuditEvent is the event bus subscriber that saves audited actions to the audit
+// log. The action has already taken effect, so a failed write is logged.
+func (a *App) HandleAuditEvent(e events.Event) {
+	entry := e.AuditEntry()
+	if entry == nil {
+		return
+	}
+	if a.repo == nil {
+		observability.Warn("failed to record audit entry", "action", entry.Action, "target", entry.Target, "error", "database not initialized")
+		return
+	}
+	if err := a.repo.CreateAuditEntry(a.ctx, entry); err != nil {
+		observability.Warn("failed to record audit entry", "action", entry.Action, "target", entry.Target, "error", err)
+	}
+}
+
+// GetAuditLog returns the most recent audit log entries, newest first
+func (a *App) GetAuditLog(limit int) ([]models.AuditEntry, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return a.repo.GetAuditLog(a.ctx, limit)
+}
+
+// GetSymbolLists returns the user's blocklist and allowlist
+func (a *App) GetSymbolLists() (*models.SymbolLists, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	entries, err := a.repo.GetSymbolListEntries(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewSymbolLists(entries), nil
+}
+
+// AddSymbolListEntry adds a symbol to the blocklist or allowlist
+func (a *App) AddSymbolListEntry(list models.SymbolListType, symbol, reason string) (*models.SymbolListEntry, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if !list.Valid() {
+		return nil, fmt.Errorf("unknown symbol list %q, expected block or allow", list)
+	}
+	entry := &models.SymbolListEntry{List: list, Symbol: symbol, Reason: reason}
+	if err := a.repo.AddSymbolListEntry(a.ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// RemoveSymbolListEntry removes a symbol from the blocklist or allowlist
+func (a *App) RemoveSymbolListEntry(list models.SymbolListType, symbol string) error {
+	if a.repo == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	if !list.Valid() {
+		return fmt.Errorf("unknown symbol list %q, expected block or allow", list)
+	}
+	return a.repo.RemoveSymbolListEntry(a.ctx, list, symbol)
+}
+
+// GetScreenerPresets returns the user's saved screener presets
+func (a *App) GetScreenerPresets() ([]models.ScreenerPreset, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return a.repo.GetScreenerPresets(a.ctx)
+}
+
+// SaveScreenerPreset validates and saves a screener preset, replacing any with its name
+func (a *App) SaveScreenerPreset(preset *models.ScreenerPreset) error {
+	if a.repo == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	if err := preset.Validate(); err != nil {
+		return err
+	}
+	return a.repo.SaveScreenerPreset(a.ctx, preset)
+}
+
+// DeleteScreenerPreset removes a saved screener preset, reporting whether it existed
+func (a *App) DeleteScreenerPreset(name string) (bool, error) {
+	if a.repo == nil {
+		return false, fmt.Errorf("database not initialized")
+	}
+	return a.repo.DeleteScreenerPreset(a.ctx, name)
+}
+
+// CheckSymbolAllowed returns models.ErrSymbolBlocked if the symbol is blocklisted.
+// Without a database there are no lists, so every symbol is allowed.
+func (a *App) CheckSymbolAllowed(symbol string) error {
+	if a.repo == nil {
+		return nil
+	}
+	lists, err := a.GetSymbolLists()
+	if err != nil {
+		return fmt.Errorf("failed to check symbol lists: %w", err)
+	}
+	if lists.IsBlocked(symbol) {
+		return fmt.Errorf("%w: %s", models.ErrSymbolBlocked, symbol)
+	}
+	return nil
+}
+
+// RunReconciliation reconciles the calendar month containing month against the broker.
+// A zero month reconciles the previous calendar month.
+func (a *App) RunReconciliation(month time.Time) (*models.ReconciliationReport, error) {
+	if a.reconciler == nil {
+		return nil, ErrReconciliationUnavailable
+	}
+	if month.IsZero() {
+		thisMonth, _ := models.MonthBounds(time.Now())
+		month = thisMonth.AddDate(0, -1, 0)
+	}
+	return a.reportWithDisclaimer(a.reconciler.Reconcile(a.ctx, month))
+}
+
+// SimilarAnalyses returns the past analyses whose reasoning is closest to the symbol's
+// latest analysis
+func (a *App) SimilarAnalyses(symbol string, limit int) ([]models.SimilarAnalysis, error) {
+	if a.similarity == nil {
+		return nil, ErrSimilarityUnavailable
+	}
+	return a.similarity.Similar(a.ctx, symbol, limit)
+}
+
+// GetRiskStats returns a symbol's trailing volatility, beta against the benchmark and max
+// drawdown, computed from daily bars once per market day
+func (a *App) GetRiskStats(symbol string) (*models.RiskStats, error) {
+	if a.riskStats == nil {
+		return nil, ErrRiskStatsUnavailable
+	}
+	return a.riskStats.Get(a.ctx, symbol)
+}
+
+// GetDividendIncome returns the projected annual dividend income and yield on cost of each
+// long position, with the dividends expected and received while it was held
+func (a *App) GetDividendIncome() (*models.DividendIncome, error) {
+	if a.dividends == nil {
+		return nil, ErrDividendsUnavailable
+	}
+	return a.dividends.Income(a.ctx)
+}
+
+// GetReconciliationReports returns the most recent reconciliation reports
+func (a *App) GetReconciliationReports(limit int) ([]models.ReconciliationReport, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	reports, err := a.repo.GetReconciliationReports(a.ctx, limit)
+	for i := range reports {
+		reports[i].Disclaimer = a.disclaimer.Text
+	}
+	return reports, err
+}
+
+// GetReconciliationReport returns a single reconciliation report by ID
+func (a *App) GetReconciliationReport(id string) (*models.ReconciliationReport, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	reportID, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+	return a.reportWithDisclaimer(a.repo.GetReconciliationReport(a.ctx, reportID))
+}
+
+// RunScreener triggers a new screener run, optionally overriding the configured listing filters
+func (a *App) RunScreener(overrides *models.ScreenerFilters) (*models.ScreenerRun, error) {
+	if a.screener == nil {
+		return nil, fmt.Errorf("screener not initialized")
+	}
+	a.invalidateWarm()
+	return a.screener.RunScreen(a.ctx, overrides)
+}
+
+// GetLatestScreenerRun returns the most recent screener run
+func (a *App) GetLatestScreenerRun() (*models.ScreenerRun, error) {
+	if a.screener == nil {
+		return nil, fmt.Errorf("screener not initialized")
+	}
+	if run, ok := takeWarm[*models.ScreenerRun](a, warmLatestRun); ok {
+		return run, nil
+	}
+	return a.screener.GetLatestRun(a.ctx)
+}
+
+// GetScreenerRunHistory returns the history of screener runs
+func (a *App) GetScreenerRunHistory(limit int) ([]models.ScreenerRun, error) {
+	if a.screener == nil {
+		return nil, fmt.Errorf("screener not initialized")
+	}
+	return a.screener.GetRunHistory(a.ctx, limit)
+}
+
+// GetScreenerRun returns a specific screener run by ID
+func (a *App) GetScreenerRun(id string) (*models.ScreenerRun, error) {
+	if a.screener == nil {
 		return nil, fmt.Errorf("screener not initialized")
 	}
+
+	uuid, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.screener.GetRun(a.ctx, uuid)
+}
+
+// RetryFailedScreenerCandidates re-analyzes the failed candidates of a screener run
+func (a *App) RetryFailedScreenerCandidates(id string) (*models.ScreenerRun, error) {
+	if a.screener == nil {
+		return nil, fmt.Errorf("screener not initialized")
+	}
+
+	uuid, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.screener.RetryFailed(a.ctx, uuid)
+}
+
+// ReplayScreenerRun screens a run's archived universe again with alternative criteria in
+// the background and returns how the replay run, still running, compares with the original
+func (a *App) ReplayScreenerRun(id string, req models.ScreenerReplayRequest) (*models.ScreenerRunComparison, error) {
+	if a.screener == nil {
+		return nil, fmt.Errorf("screener not initialized")
+	}
+
+	uuid, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.screener.ReplayRun(a.ctx, uuid, req)
+}
+
+// GetScreenerReplayComparison compares a replay run with the run it replayed, so a replay
+// started by ReplayScreenerRun can be followed until it completes. Returns nil if either
+// run does not exist, and models.ErrInvalidScreenerReplay for a run that isn't a replay.
+func (a *App) GetScreenerReplayComparison(id string) (*models.ScreenerRunComparison, error) {
+	if a.screener == nil {
+		return nil, fmt.Errorf("screener not initialized")
+	}
+
+	replayID, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+	replay, err := a.screener.GetRun(a.ctx, replayID)
+	if err != nil || replay == nil {
+		return nil, err
+	}
+	if replay.ReplayOf == nil {
+		return nil, fmt.Errorf("%w: run %s is not a replay", models.ErrInvalidScreenerReplay, id)
+	}
+	original, err := a.screener.GetRun(a.ctx, *replay.ReplayOf)
+	if err != nil || original == nil {
+		return nil, err
+	}
+	return models.CompareScreenerRuns(original, replay), nil
+}
+
+// GetTopPicks returns the top picks from the latest completed screener run
+func (a *App) GetTopPicks() ([]models.ScreenerCandidate, error) {
+	if a.screener == nil {
+		return nil, fmt.Errorf("screener not initialized")
+	}
+	if picks, ok := takeWarm[[]models.ScreenerCandidate](a, warmTopPicks); ok {
+		return picks, nil
+	}
 	return a.screener.GetLatestPicks(a.ctx)
 }
 
@@ -2,14 +2,23 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"trade-machine/config"
+	"trade-machine/events"
+	"trade-machine/internal/settings"
 	"trade-machine/models"
 	"trade-machine/repository"
 	"trade-machine/services"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // testConfig returns a test configuration
@@ -57,6 +66,73 @@ func TestApp_AnalyzeStock_RateLimiting(t *testing.T) {
 	}
 }
 
+// reasonRecordingManager returns a recommendation carrying the trigger reason from its context
+type reasonRecordingManager struct{}
+
+func (m *reasonRecordingManager) AnalyzeSymbol(ctx context.Context, symbol string) (*models.Recommendation, error) {
+	rec := models.NewRecommendation(symbol, models.RecommendationActionHold, "")
+	rec.TriggerReason = models.TriggerReasonFromContext(ctx)
+	return rec, nil
+}
+
+func TestApp_AnalyzeTriggered(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("records trigger reason", func(t *testing.T) {
+		a := New(testConfig(), nil, &reasonRecordingManager{}, nil)
+		rec, err := a.AnalyzeTriggered(ctx, "AAPL", "Price moved +6.0%")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rec.TriggerReason != "Price moved +6.0%" {
+			t.Errorf("TriggerReason = %q, want the trigger reason", rec.TriggerReason)
+		}
+	})
+
+	t.Run("respects analysis budget", func(t *testing.T) {
+		a := New(testConfig(), nil, &reasonRecordingManager{}, nil)
+		for i := 0; i < a.AnalysisSemCapacity(); i++ {
+			a.analysisSem <- struct{}{}
+		}
+		if _, err := a.AnalyzeTriggered(ctx, "AAPL", "Price moved +6.0%"); !errors.Is(err, ErrAnalysisQueueFull) {
+			t.Errorf("expected ErrAnalysisQueueFull, got %v", err)
+		}
+	})
+}
+
+// partialManager returns a partial recommendation, keeping the agents-done callback
+type partialManager struct {
+	done func()
+}
+
+func (m *partialManager) AnalyzeSymbol(ctx context.Context, symbol string) (*models.Recommendation, error) {
+	m.done = models.AgentsDoneFromContext(ctx)
+	rec := models.NewRecommendation(symbol, models.RecommendationActionHold, "")
+	rec.Partial = true
+	return rec, nil
+}
+
+func TestApp_AnalyzeTriggered_HoldsSlotForPartial(t *testing.T) {
+	cfg := testConfig()
+	cfg.Agent.ConcurrencyLimit = 1
+	manager := &partialManager{}
+	a := New(cfg, nil, manager, nil)
+
+	if _, err := a.AnalyzeTriggered(context.Background(), "AAPL", "Price moved +6.0%"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The slot is held while the partial recommendation's agents are still running
+	if _, err := a.AnalyzeTriggered(context.Background(), "MSFT", "Price moved +6.0%"); !errors.Is(err, ErrAnalysisQueueFull) {
+		t.Fatalf("expected ErrAnalysisQueueFull while agents run, got %v", err)
+	}
+
+	manager.done()
+	manager.done()
+	if _, err := a.AnalyzeTriggered(context.Background(), "MSFT", "Price moved +6.0%"); err != nil {
+		t.Errorf("expected the slot to be released once agents returned, got %v", err)
+	}
+}
+
 func TestApp_GetRecommendations(t *testing.T) {
 	t.Run("repository not initialized", func(t *testing.T) {
 		a := testApp(nil)
@@ -97,6 +173,16 @@ func TestApp_GetTrades(t *testing.T) {
 	})
 }
 
+func TestApp_GetTrade(t *testing.T) {
+	t.Run("repository not initialized", func(t *testing.T) {
+		a := testApp(nil)
+		_, err := a.GetTrade(uuid.New().String())
+		if err == nil {
+			t.Error("expected error when repository is nil")
+		}
+	})
+}
+
 func TestApp_GetAgentRuns(t *testing.T) {
 	t.Run("repository not initialized", func(t *testing.T) {
 		a := testApp(nil)
@@ -125,6 +211,20 @@ func TestApp_Shutdown(t *testing.T) {
 		a := testApp(nil)
 		a.Shutdown(ctx) // Should not panic
 	})
+
+	t.Run("delivers queued events", func(t *testing.T) {
+		bus := events.NewBus()
+		var delivered atomic.Int32
+		bus.Subscribe("test", func(e events.Event) { delivered.Add(1) })
+		a := testApp(nil)
+		a.SetEventBus(bus)
+
+		bus.Publish(events.TradeFilled, &models.Trade{})
+		a.Shutdown(ctx)
+		if delivered.Load() != 1 {
+			t.Errorf("delivered %d events before Shutdown returned, want 1", delivered.Load())
+		}
+	})
 }
 
 func TestParseUUID(t *testing.T) {
@@ -164,30 +264,362 @@ func TestRecommendationActions(t *testing.T) {
 	a := testApp(nil)
 
 	t.Run("approve with nil repository", func(t *testing.T) {
-		err := a.ApproveRecommendation("550e8400-e29b-41d4-a716-446655440000")
+		err := a.ApproveRecommendation("550e8400-e29b-41d4-a716-446655440000", models.AnyVersion)
 		if err == nil {
 			t.Error("expected error when repository is nil")
 		}
 	})
 
 	t.Run("reject with nil repository", func(t *testing.T) {
-		err := a.RejectRecommendation("550e8400-e29b-41d4-a716-446655440000")
+		err := a.RejectRecommendation("550e8400-e29b-41d4-a716-446655440000", models.AnyVersion)
 		if err == nil {
 			t.Error("expected error when repository is nil")
 		}
 	})
 
 	t.Run("approve with invalid UUID", func(t *testing.T) {
-		err := a.ApproveRecommendation("invalid")
+		err := a.ApproveRecommendation("invalid", models.AnyVersion)
 		if err == nil {
 			t.Error("expected error with invalid UUID")
 		}
 	})
+
+	t.Run("execute with nil repository", func(t *testing.T) {
+		_, err := a.ExecuteRecommendation("550e8400-e29b-41d4-a716-446655440000", models.AnyVersion)
+		if err == nil {
+			t.Error("expected error when repository is nil")
+		}
+	})
+
+	t.Run("edit with nil repository", func(t *testing.T) {
+		_, err := a.EditRecommendation("550e8400-e29b-41d4-a716-446655440000", models.RecommendationOverride{}, models.AnyVersion)
+		if err == nil {
+			t.Error("expected error when repository is nil")
+		}
+	})
+}
+
+// unusedRepo satisfies the database check of operations that fail before using it
+type unusedRepo struct{ RepositoryInterface }
+
+func TestApp_DisclaimerGate(t *testing.T) {
+	cfg := testConfig()
+	cfg.Compliance.RequireAcknowledgment = true
+	a := New(cfg, &unusedRepo{}, nil, nil)
+
+	if err := a.ApproveRecommendation("550e8400-e29b-41d4-a716-446655440000", models.AnyVersion); !errors.Is(err, ErrDisclaimerNotAcknowledged) {
+		t.Errorf("ApproveRecommendation() error = %v, want ErrDisclaimerNotAcknowledged", err)
+	}
+
+	status := a.ComplianceStatus()
+	if status.Acknowledged || !status.RequireAcknowledgment || status.Version == "" || status.Jurisdiction != "us" {
+		t.Errorf("ComplianceStatus() = %+v, want the unaccepted US disclaimer", status)
+	}
+	if _, err := a.AcknowledgeDisclaimer(status.Version); !errors.Is(err, ErrSettingsUnavailable) {
+		t.Errorf("AcknowledgeDisclaimer() error = %v, want ErrSettingsUnavailable", err)
+	}
+}
+
+func TestApp_AttachesDisclaimer(t *testing.T) {
+	cfg := testConfig()
+	cfg.Compliance.Disclaimer = "Internal use only."
+	a := New(cfg, nil, nil, nil)
+
+	rec, _ := a.withDisclaimer(models.NewRecommendation("AAPL", models.RecommendationActionBuy, ""), nil)
+	if rec.Disclaimer != "Internal use only." {
+		t.Errorf("Disclaimer = %q, want the configured text", rec.Disclaimer)
+	}
+	if _, err := a.withDisclaimer(nil, errors.New("not found")); err == nil {
+		t.Error("expected the error to pass through")
+	}
+}
+
+func TestApp_CheckRiskRules(t *testing.T) {
+	a := testApp(nil)
+	a.cfg.PositionSizing.MaxShares = 100
+
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(500) // Agent-suggested values are not re-checked
+	if err := a.checkRiskRules(rec); err != nil {
+		t.Errorf("checkRiskRules() error = %v for an unedited recommendation", err)
+	}
+
+	quantity := decimal.NewFromInt(150)
+	if err := rec.Edit(models.RecommendationOverride{Quantity: &quantity}); err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	if err := a.checkRiskRules(rec); !errors.Is(err, models.ErrRiskRuleViolation) {
+		t.Errorf("checkRiskRules() error = %v, want ErrRiskRuleViolation", err)
+	}
+}
+
+// accountOrderAlpaca adds an account to orderAlpaca for the buying power checks
+type accountOrderAlpaca struct {
+	*orderAlpaca
+	account *models.Account
+}
+
+func (m *accountOrderAlpaca) GetAccount(ctx context.Context) (*models.Account, error) {
+	return m.account, nil
+}
+
+func TestApp_CheckRiskRules_PricedFromLatestTrade(t *testing.T) {
+	account := &models.Account{PortfolioValue: decimal.NewFromInt(100000), BuyingPower: decimal.NewFromInt(50000)}
+	a := New(testConfig(), nil, nil, &accountOrderAlpaca{orderAlpaca: &orderAlpaca{last: decimal.NewFromInt(200), bidAskOnly: true}, account: account})
+	a.ctx = context.Background()
+
+	// 300 shares at the latest trade's $200 is more than the buying power
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	quantity := decimal.NewFromInt(300)
+	if err := rec.Edit(models.RecommendationOverride{Quantity: &quantity}); err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	if err := a.checkRiskRules(rec); !errors.Is(err, models.ErrRiskRuleViolation) {
+		t.Errorf("checkRiskRules() error = %v, want ErrRiskRuleViolation", err)
+	}
+}
+
+// shortAlpacaService stubs the account and borrow lookups used by the short risk checks
+type shortAlpacaService struct {
+	services.AlpacaServiceInterface
+	account      *models.Account
+	availability *models.ShortAvailability
+}
+
+func (m *shortAlpacaService) GetAccount(ctx context.Context) (*models.Account, error) {
+	return m.account, nil
+}
+
+func (m *shortAlpacaService) GetShortAvailability(ctx context.Context, symbol string) (*models.ShortAvailability, error) {
+	return m.availability, nil
+}
+
+func TestApp_CheckRiskRules_Short(t *testing.T) {
+	account := &models.Account{PortfolioValue: decimal.NewFromInt(100000), BuyingPower: decimal.NewFromInt(50000), ShortingEnabled: true}
+	easy := &models.ShortAvailability{Symbol: "TSLA", Shortable: true, EasyToBorrow: true}
+
+	tests := []struct {
+		name         string
+		allowShorts  bool
+		account      *models.Account
+		availability *models.ShortAvailability
+		wantErr      bool
+	}{
+		{"allowed", true, account, easy, false},
+		{"shorts disabled", false, account, easy, true},
+		{"hard to borrow", true, account, &models.ShortAvailability{Symbol: "TSLA", Shortable: true}, true},
+		{"account cannot short", true, &models.Account{PortfolioValue: decimal.NewFromInt(100000), BuyingPower: decimal.NewFromInt(50000)}, easy, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.PositionSizing.AllowShorts = tt.allowShorts
+			a := New(cfg, nil, nil, &shortAlpacaService{account: tt.account, availability: tt.availability})
+
+			// Shorts are checked even when the agent-suggested values are unedited
+			rec := models.NewRecommendation("TSLA", models.RecommendationActionShort, "test")
+			rec.Quantity = decimal.NewFromInt(10)
+			rec.EntryPrice = decimal.NewFromInt(200)
+
+			err := a.checkRiskRules(rec)
+			if tt.wantErr && !errors.Is(err, models.ErrRiskRuleViolation) {
+				t.Errorf("checkRiskRules() error = %v, want ErrRiskRuleViolation", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("checkRiskRules() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+// positionAlpacaService stubs the account and position lookups used by the sell checks
+type positionAlpacaService struct {
+	services.AlpacaServiceInterface
+	positions []models.Position
+}
+
+func (m *positionAlpacaService) GetAccount(ctx context.Context) (*models.Account, error) {
+	return &models.Account{PortfolioValue: decimal.NewFromInt(100000), BuyingPower: decimal.NewFromInt(50000)}, nil
+}
+
+func (m *positionAlpacaService) GetPositions(ctx context.Context) ([]models.Position, error) {
+	return m.positions, nil
+}
+
+func TestApp_CheckRiskRules_SellCappedAtPosition(t *testing.T) {
+	held := []models.Position{{Symbol: "AAPL", Quantity: decimal.NewFromInt(20), Side: models.PositionSideLong}}
+
+	tests := []struct {
+		name      string
+		positions []models.Position
+		quantity  int64
+		wantErr   bool
+	}{
+		{"within position", held, 20, false},
+		{"more than held", held, 25, true},
+		{"no position", nil, 5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := New(testConfig(), nil, nil, &positionAlpacaService{positions: tt.positions})
+
+			rec := models.NewRecommendation("AAPL", models.RecommendationActionSell, "test")
+			rec.Quantity = decimal.NewFromInt(10)
+			rec.EntryPrice = decimal.NewFromInt(100)
+			quantity := decimal.NewFromInt(tt.quantity)
+			if err := rec.Edit(models.RecommendationOverride{Quantity: &quantity}); err != nil {
+				t.Fatalf("Edit() error = %v", err)
+			}
+
+			err := a.checkRiskRules(rec)
+			if tt.wantErr && !errors.Is(err, models.ErrRiskRuleViolation) {
+				t.Errorf("checkRiskRules() error = %v, want ErrRiskRuleViolation", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("checkRiskRules() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+// volumeAlpacaService stubs the average daily volume lookup used by the liquidity check
+type volumeAlpacaService struct {
+	services.AlpacaServiceInterface
+	adv int64
+	err error
+}
+
+func (m *volumeAlpacaService) GetAverageDailyVolume(ctx context.Context, symbol string, days int) (int64, error) {
+	return m.adv, m.err
+}
+
+func TestApp_CheckRiskRules_Liquidity(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		adv     int64
+		advErr  error
+		wantErr bool
+	}{
+		{"liquid", "reject", 1_000_000, nil, false},
+		{"thin volume rejected", "reject", 10_000, nil, true},
+		{"thin volume in warn mode", "warn", 10_000, nil, false},
+		{"volume unavailable", "reject", 0, errors.New("bars unavailable"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.PositionSizing.MaxADVPercent = 0.01
+			cfg.PositionSizing.ADVMode = tt.mode
+			a := New(cfg, nil, nil, &volumeAlpacaService{adv: tt.adv, err: tt.advErr})
+
+			rec := models.NewRecommendation("THIN", models.RecommendationActionBuy, "test")
+			rec.Quantity = decimal.NewFromInt(500)
+
+			err := a.checkRiskRules(rec)
+			if tt.wantErr && !errors.Is(err, models.ErrRiskRuleViolation) {
+				t.Errorf("checkRiskRules() error = %v, want ErrRiskRuleViolation", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("checkRiskRules() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+// positionRepo stores a single position for applyTradeToPosition tests
+type positionRepo struct {
+	repository.RepositoryInterface
+	position *models.Position
+}
+
+func (r *positionRepo) GetPositionBySymbol(ctx context.Context, symbol string) (*models.Position, error) {
+	return r.position, nil
+}
+
+func (r *positionRepo) CreatePosition(ctx context.Context, pos *models.Position) error {
+	r.position = pos
+	return nil
+}
+
+func (r *positionRepo) UpdatePosition(ctx context.Context, pos *models.Position) error {
+	r.position = pos
+	return nil
+}
+
+func (r *positionRepo) DeletePosition(ctx context.Context, id uuid.UUID) error {
+	r.position = nil
+	return nil
+}
+
+func TestApplyTradeToPosition_Short(t *testing.T) {
+	ctx := context.Background()
+	repo := &positionRepo{}
+	fees := models.NewFeeSchedule(1, 0, 0)
+
+	open := models.NewTrade("TSLA", models.TradeSideSell, decimal.NewFromInt(10), decimal.NewFromInt(200))
+	fees.Apply(open)
+	if err := applyTradeToPosition(ctx, repo, open, models.RecommendationActionShort, nil); err != nil {
+		t.Fatalf("open short: %v", err)
+	}
+	// (2000 - 1) / 10
+	if repo.position == nil || repo.position.Side != models.PositionSideShort || !repo.position.AvgEntryPrice.Equal(decimal.NewFromFloat(199.9)) {
+		t.Fatalf("position = %+v, want a short at 199.9", repo.position)
+	}
+
+	add := models.NewTrade("TSLA", models.TradeSideSell, decimal.NewFromInt(10), decimal.NewFromInt(180))
+	if err := applyTradeToPosition(ctx, repo, add, models.RecommendationActionShort, nil); err != nil {
+		t.Fatalf("add to short: %v", err)
+	}
+	if !repo.position.Quantity.Equal(decimal.NewFromInt(20)) || !repo.position.AvgEntryPrice.Equal(decimal.NewFromFloat(189.95)) {
+		t.Errorf("position = %s @ %s, want 20 @ 189.95", repo.position.Quantity, repo.position.AvgEntryPrice)
+	}
+	if !repo.position.UnrealizedPL.IsPositive() {
+		t.Errorf("UnrealizedPL = %s, want a gain after the price fell", repo.position.UnrealizedPL)
+	}
+
+	cover := models.NewTrade("TSLA", models.TradeSideBuy, decimal.NewFromInt(20), decimal.NewFromInt(170))
+	if err := applyTradeToPosition(ctx, repo, cover, models.RecommendationActionCover, nil); err != nil {
+		t.Fatalf("cover: %v", err)
+	}
+	if repo.position != nil {
+		t.Errorf("position = %+v, want it closed by the cover", repo.position)
+	}
+
+	if err := applyTradeToPosition(ctx, repo, cover, models.RecommendationActionCover, nil); err != nil || repo.position != nil {
+		t.Errorf("cover without a position: err = %v, position = %+v; want nothing opened", err, repo.position)
+	}
+}
+
+func TestApplyTradeToPosition_Bracket(t *testing.T) {
+	ctx := context.Background()
+	repo := &positionRepo{}
+
+	buy := models.NewTrade("AAPL", models.TradeSideBuy, decimal.NewFromInt(10), decimal.NewFromInt(100))
+	bracket := &models.Bracket{StopLoss: decimal.NewFromInt(95), TakeProfit: decimal.NewFromInt(110)}
+	if err := applyTradeToPosition(ctx, repo, buy, models.RecommendationActionBuy, bracket); err != nil {
+		t.Fatalf("open long: %v", err)
+	}
+	if repo.position.StopLossPrice == nil || !repo.position.StopLossPrice.Equal(bracket.StopLoss) ||
+		repo.position.TakeProfitPrice == nil || !repo.position.TakeProfitPrice.Equal(bracket.TakeProfit) {
+		t.Fatalf("position = %+v, want the bracket's levels", repo.position)
+	}
+
+	sell := models.NewTrade("AAPL", models.TradeSideSell, decimal.NewFromInt(4), decimal.NewFromInt(105))
+	if err := applyTradeToPosition(ctx, repo, sell, models.RecommendationActionSell, nil); err != nil {
+		t.Fatalf("reduce long: %v", err)
+	}
+	if repo.position.StopLossPrice == nil || !repo.position.StopLossPrice.Equal(bracket.StopLoss) {
+		t.Errorf("StopLossPrice = %v after a partial sell, want it kept", repo.position.StopLossPrice)
+	}
 }
 
 func TestApp_RejectRecommendation_InvalidUUID(t *testing.T) {
 	a := testApp(nil)
-	err := a.RejectRecommendation("not-a-uuid")
+	err := a.RejectRecommendation("not-a-uuid", models.AnyVersion)
 	if err == nil {
 		t.Error("expected error with invalid UUID")
 	}
@@ -205,12 +637,82 @@ func TestApp_RejectRecommendation_WithDatabase(t *testing.T) {
 	a := testApp(repo)
 	a.Startup(ctx)
 
-	err = a.RejectRecommendation("550e8400-e29b-41d4-a716-446655440000")
+	err = a.RejectRecommendation("550e8400-e29b-41d4-a716-446655440000", models.AnyVersion)
 	if err != nil {
 		t.Logf("reject recommendation error (expected for nonexistent ID): %v", err)
 	}
 }
 
+// decisionRepo rejects recommendations in memory
+type decisionRepo struct {
+	RepositoryInterface
+	rec *models.Recommendation
+}
+
+func (r *decisionRepo) RejectRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error {
+	r.rec.Status = models.RecommendationStatusRejected
+	r.rec.Version++
+	return nil
+}
+
+func (r *decisionRepo) GetRecommendation(ctx context.Context, id uuid.UUID) (*models.Recommendation, error) {
+	rec := *r.rec
+	return &rec, nil
+}
+
+func TestApp_RejectRecommendation_PublishesEvent(t *testing.T) {
+	bus := events.NewBus()
+	var got []events.Event
+	bus.Subscribe("decisions", func(e events.Event) { got = append(got, e) }, events.RecommendationRejected)
+	events.SetDefault(bus)
+	defer events.SetDefault(nil)
+
+	rec := &models.Recommendation{ID: uuid.New(), Symbol: "AAPL", Status: models.RecommendationStatusPending, Version: 1}
+	a := testApp(&decisionRepo{rec: rec})
+	a.ctx = context.Background()
+	if err := a.RejectRecommendation(rec.ID.String(), 1); err != nil {
+		t.Fatalf("RejectRecommendation() error = %v", err)
+	}
+	bus.Close()
+
+	if len(got) != 1 {
+		t.Fatalf("published %d events, want 1", len(got))
+	}
+	if r := got[0].Recommendation(); r.Status != models.RecommendationStatusRejected || r.Version != 2 {
+		t.Errorf("event recommendation = %+v, want it as saved after rejection", r)
+	}
+}
+
+// auditRepo keeps audit entries in memory
+type auditRepo struct {
+	RepositoryInterface
+	entries []*models.AuditEntry
+}
+
+func (r *auditRepo) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func TestApp_RecordAudit_WrittenBySubscriber(t *testing.T) {
+	repo := &auditRepo{}
+	a := testApp(repo)
+	a.ctx = context.Background()
+	bus := events.NewBus()
+	bus.Subscribe("audit_log", a.HandleAuditEvent, events.ActionAudited)
+	events.SetDefault(bus)
+	defer events.SetDefault(nil)
+
+	entry := models.NewAuditEntry(models.AuditRecommendationApproved, "127.0.0.1:1", "rec-1", nil)
+	a.RecordAudit(entry)
+	bus.Publish(events.TradeFilled, &models.Trade{})
+	bus.Close()
+
+	if len(repo.entries) != 1 || repo.entries[0] != entry {
+		t.Errorf("saved %v, want only the audited action", repo.entries)
+	}
+}
+
 func TestApp_SetScreener(t *testing.T) {
 	a := testApp(nil)
 
@@ -228,12 +730,410 @@ func TestApp_SetScreener(t *testing.T) {
 	}
 }
 
+func TestApp_GetActivity_NotInitialized(t *testing.T) {
+	ctx := context.Background()
+	a := testApp(nil)
+	a.Startup(ctx)
+
+	_, err := a.GetActivity(nil, 20)
+	if err == nil {
+		t.Error("expected error when repo is nil")
+	}
+}
+
+func TestApp_ProviderAlerts_NotInitialized(t *testing.T) {
+	a := testApp(nil)
+	a.Startup(context.Background())
+
+	if _, err := a.GetProviderAlerts(false); err == nil {
+		t.Error("expected error from GetProviderAlerts when repo is nil")
+	}
+	if err := a.DismissProviderAlert(uuid.New().String()); err == nil {
+		t.Error("expected error from DismissProviderAlert when repo is nil")
+	}
+}
+
+func TestApp_GetRecommendationEvents_NotInitialized(t *testing.T) {
+	ctx := context.Background()
+	a := testApp(nil)
+	a.Startup(ctx)
+
+	_, err := a.GetRecommendationEvents("550e8400-e29b-41d4-a716-446655440000")
+	if err == nil {
+		t.Error("expected error when repo is nil")
+	}
+}
+
+func TestApp_SymbolLists_NotInitialized(t *testing.T) {
+	ctx := context.Background()
+	a := testApp(nil)
+	a.Startup(ctx)
+
+	if _, err := a.GetSymbolLists(); err == nil {
+		t.Error("expected error from GetSymbolLists when repo is nil")
+	}
+	if _, err := a.AddSymbolListEntry(models.SymbolListBlock, "GME", ""); err == nil {
+		t.Error("expected error from AddSymbolListEntry when repo is nil")
+	}
+	if err := a.RemoveSymbolListEntry(models.SymbolListBlock, "GME"); err == nil {
+		t.Error("expected error from RemoveSymbolListEntry when repo is nil")
+	}
+	// Without a database nothing can be blocklisted
+	if err := a.CheckSymbolAllowed("GME"); err != nil {
+		t.Errorf("CheckSymbolAllowed without repo = %v, want nil", err)
+	}
+}
+
+// stubReconciler returns an empty report for the requested month
+type stubReconciler struct{}
+
+func (s *stubReconciler) Run(ctx context.Context) {}
+
+func (s *stubReconciler) Reconcile(ctx context.Context, month time.Time) (*models.ReconciliationReport, error) {
+	return models.NewReconciliationReport(month), nil
+}
+
+func TestApp_Reconciliation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("not initialized", func(t *testing.T) {
+		a := testApp(nil)
+		a.Startup(ctx)
+		if _, err := a.RunReconciliation(time.Time{}); !errors.Is(err, ErrReconciliationUnavailable) {
+			t.Errorf("expected ErrReconciliationUnavailable, got %v", err)
+		}
+		if _, err := a.GetReconciliationReports(12); err == nil {
+			t.Error("expected error from GetReconciliationReports when repo is nil")
+		}
+		if _, err := a.GetReconciliationReport("550e8400-e29b-41d4-a716-446655440000"); err == nil {
+			t.Error("expected error from GetReconciliationReport when repo is nil")
+		}
+	})
+
+	t.Run("defaults to previous month", func(t *testing.T) {
+		a := testApp(nil)
+		r := &stubReconciler{}
+		a.SetReconciler(r)
+		a.Startup(ctx)
+		defer a.Shutdown(ctx)
+
+		report, err := a.RunReconciliation(time.Time{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		thisMonth, _ := models.MonthBounds(time.Now())
+		if !report.PeriodEnd.Equal(thisMonth) {
+			t.Errorf("PeriodEnd = %v, want %v", report.PeriodEnd, thisMonth)
+		}
+	})
+}
+
+// stubSimilarityIndex returns one similar analysis for any symbol
+type stubSimilarityIndex struct{}
+
+func (s *stubSimilarityIndex) Run(ctx context.Context) {}
+
+func (s *stubSimilarityIndex) Similar(ctx context.Context, symbol string, limit int) ([]models.SimilarAnalysis, error) {
+	return []models.SimilarAnalysis{
+		{Recommendation: *models.NewRecommendation("MSFT", models.RecommendationActionBuy, ""), Similarity: 0.9},
+	}, nil
+}
+
+func TestApp_SimilarAnalyses(t *testing.T) {
+	ctx := context.Background()
+
+	a := testApp(nil)
+	a.Startup(ctx)
+	if _, err := a.SimilarAnalyses("AAPL", 5); !errors.Is(err, ErrSimilarityUnavailable) {
+		t.Errorf("expected ErrSimilarityUnavailable, got %v", err)
+	}
+
+	a = testApp(nil)
+	a.SetSimilarityIndex(&stubSimilarityIndex{})
+	a.Startup(ctx)
+	defer a.Shutdown(ctx)
+	similar, err := a.SimilarAnalyses("AAPL", 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(similar) != 1 || similar[0].Recommendation.Symbol != "MSFT" {
+		t.Errorf("SimilarAnalyses = %+v, want MSFT", similar)
+	}
+}
+
+// stubRiskStats returns fixed stats for any symbol
+type stubRiskStats struct{}
+
+func (s *stubRiskStats) Get(ctx context.Context, symbol string) (*models.RiskStats, error) {
+	return &models.RiskStats{Symbol: symbol, Volatility: 0.3, MaxDrawdown: 0.2}, nil
+}
+
+func TestApp_GetRiskStats(t *testing.T) {
+	a := testApp(nil)
+	a.Startup(context.Background())
+	defer a.Shutdown(context.Background())
+	if _, err := a.GetRiskStats("AAPL"); !errors.Is(err, ErrRiskStatsUnavailable) {
+		t.Errorf("expected ErrRiskStatsUnavailable, got %v", err)
+	}
+
+	a.SetRiskStats(&stubRiskStats{})
+	stats, err := a.GetRiskStats("AAPL")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Symbol != "AAPL" || stats.Volatility != 0.3 {
+		t.Errorf("GetRiskStats = %+v, want AAPL stats", stats)
+	}
+}
+
+func TestApp_GetPortfolioSummary_NotInitialized(t *testing.T) {
+	a := testApp(nil)
+	a.Startup(context.Background())
+
+	if _, err := a.GetPortfolioSummary(); err == nil {
+		t.Error("expected error when repo is nil")
+	}
+}
+
+func TestApp_PortfolioReviews_NotInitialized(t *testing.T) {
+	a := testApp(nil)
+	a.Startup(context.Background())
+
+	if _, err := a.ReviewPortfolio(context.Background()); err == nil {
+		t.Error("expected error from ReviewPortfolio when repo is nil")
+	}
+	if _, err := a.GetPortfolioReviews(10); err == nil {
+		t.Error("expected error from GetPortfolioReviews when repo is nil")
+	}
+	if _, err := a.GetPortfolioReview("550e8400-e29b-41d4-a716-446655440000"); err == nil {
+		t.Error("expected error from GetPortfolioReview when repo is nil")
+	}
+}
+
+// reviewRepo serves positions and records saved portfolio reviews
+type reviewRepo struct {
+	positionsRepo
+	mu    sync.Mutex
+	saved []*models.PortfolioReview
+}
+
+func (r *reviewRepo) SavePortfolioReview(ctx context.Context, review *models.PortfolioReview) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.saved = append(r.saved, review)
+	return nil
+}
+
+func (r *reviewRepo) GetPortfolioReview(ctx context.Context, id uuid.UUID) (*models.PortfolioReview, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, review := range r.saved {
+		if review.ID == id {
+			return review, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestApp_ReviewPortfolio(t *testing.T) {
+	repo := &reviewRepo{positionsRepo: positionsRepo{positions: []models.Position{
+		{Symbol: "AAPL", Side: models.PositionSideLong, Quantity: decimal.NewFromInt(10), CurrentPrice: decimal.NewFromInt(150)},
+		{Symbol: "MSFT", Side: models.PositionSideLong, Quantity: decimal.NewFromInt(1), CurrentPrice: decimal.NewFromInt(300)},
+	}}}
+	manager := &gatedManager{release: make(chan struct{}), fail: map[string]bool{"MSFT": true}}
+	a := New(testConfig(), repo, manager, nil)
+	a.ctx = context.Background()
+
+	review, err := a.ReviewPortfolio(context.Background())
+	if err != nil {
+		t.Fatalf("ReviewPortfolio error = %v", err)
+	}
+	if !review.Running || len(review.Items) != 0 {
+		t.Fatalf("review = %+v, want it returned running before any analysis finishes", review)
+	}
+	if _, err := a.ReviewPortfolio(context.Background()); !errors.Is(err, ErrPortfolioReviewRunning) {
+		t.Errorf("second ReviewPortfolio error = %v, want ErrPortfolioReviewRunning", err)
+	}
+	if polled, err := a.GetPortfolioReview(review.ID.String()); err != nil || polled == nil || !polled.Running {
+		t.Errorf("GetPortfolioReview = %+v, %v while running, want the running review", polled, err)
+	}
+
+	close(manager.release)
+	waitFor(t, func() bool {
+		polled, _ := a.GetPortfolioReview(review.ID.String())
+		return polled != nil && !polled.Running
+	})
+	saved, _ := a.GetPortfolioReview(review.ID.String())
+	if len(saved.Items) != 2 || saved.Items[0].Symbol != "AAPL" || saved.Items[0].Suggestion != models.PortfolioSuggestionHold || saved.Items[1].Error == "" {
+		t.Errorf("saved review = %+v, want the AAPL hold and the failed MSFT analysis", saved)
+	}
+
+	// The guard is released once the review is saved
+	waitFor(t, func() bool {
+		if !a.reviewing.TryLock() {
+			return false
+		}
+		a.reviewing.Unlock()
+		return true
+	})
+}
+
+func TestApp_Watchlists_NotInitialized(t *testing.T) {
+	a := testApp(nil)
+	a.Startup(context.Background())
+
+	if _, err := a.ImportWatchlist("Tech", "AAPL\nMSFT", false); err == nil {
+		t.Error("expected error from ImportWatchlist when repo is nil")
+	}
+	if _, err := a.GetWatchlists(); err == nil {
+		t.Error("expected error from GetWatchlists when repo is nil")
+	}
+}
+
+// watchlistRepo keeps the last saved watchlist
+type watchlistRepo struct {
+	RepositoryInterface
+	saved *models.Watchlist
+}
+
+func (r *watchlistRepo) SaveWatchlist(ctx context.Context, w *models.Watchlist) error {
+	r.saved = w
+	return nil
+}
+
+func (r *watchlistRepo) GetSymbolListEntries(ctx context.Context) ([]models.SymbolListEntry, error) {
+	return nil, nil
+}
+
+// lookupAlpaca has no quote for ZZZZ and fails to look up FLAKY
+type lookupAlpaca struct {
+	services.AlpacaServiceInterface
+}
+
+func (m *lookupAlpaca) GetQuote(ctx context.Context, symbol string) (*models.Quote, error) {
+	switch symbol {
+	case "ZZZZ":
+		return nil, fmt.Errorf("failed to get quote for %s: %w", symbol, models.ErrSymbolNotFound)
+	case "FLAKY":
+		return nil, errors.New("Alpaca returned status 503")
+	}
+	return &models.Quote{Symbol: symbol, Last: decimal.NewFromInt(10)}, nil
+}
+
+// countingManager counts the symbols it analyzes
+type countingManager struct {
+	analyzed atomic.Int32
+}
+
+func (m *countingManager) AnalyzeSymbol(ctx context.Context, symbol string) (*models.Recommendation, error) {
+	m.analyzed.Add(1)
+	return models.NewRecommendation(symbol, models.RecommendationActionHold, ""), nil
+}
+
+func TestApp_ImportWatchlist(t *testing.T) {
+	repo := &watchlistRepo{}
+	manager := &countingManager{}
+	a := New(testConfig(), repo, manager, &lookupAlpaca{})
+	a.ctx = context.Background()
+
+	tickers := []string{"ZZZZ", "FLAKY"}
+	for i := 0; i < models.MaxWatchlistAnalyses+8; i++ {
+		tickers = append(tickers, fmt.Sprintf("T%d", i))
+	}
+	result, err := a.ImportWatchlist("Big", strings.Join(tickers, "\n"), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if row := result.Rows[0]; row.Status != models.WatchlistRowUnknown {
+		t.Errorf("ZZZZ = %+v, want unknown_symbol", row)
+	}
+	if row := result.Rows[1]; row.Status != models.WatchlistRowValid || row.Message == "" {
+		t.Errorf("FLAKY = %+v, want kept as valid with a note", row)
+	}
+	if len(result.Queued) != models.MaxWatchlistAnalyses || len(result.NotQueued) != 9 {
+		t.Errorf("queued %d, not queued %d, want %d and 9", len(result.Queued), len(result.NotQueued), models.MaxWatchlistAnalyses)
+	}
+	waitFor(t, func() bool { return manager.analyzed.Load() == int32(models.MaxWatchlistAnalyses) })
+}
+
+func TestApp_GetPortfolioAsOf_NotInitialized(t *testing.T) {
+	a := testApp(nil)
+	a.Startup(context.Background())
+
+	if _, err := a.GetPortfolioAsOf(time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Error("expected error from GetPortfolioAsOf when repo is nil")
+	}
+}
+
+func TestApp_GetAPIUsage_NotInitialized(t *testing.T) {
+	a := testApp(nil)
+	a.Startup(context.Background())
+
+	if _, err := a.GetAPIUsage(30); err == nil {
+		t.Error("expected error from GetAPIUsage when repo is nil")
+	}
+}
+
+func TestApp_Onboarding_NoSettings(t *testing.T) {
+	a := testApp(nil)
+
+	if _, err := a.OnboardingStatus(); !errors.Is(err, ErrSettingsUnavailable) {
+		t.Errorf("OnboardingStatus() error = %v, want ErrSettingsUnavailable", err)
+	}
+	req := OnboardingStepRequest{Step: settings.OnboardingStepDemo}
+	if _, err := a.CompleteOnboardingStep(context.Background(), req); !errors.Is(err, ErrSettingsUnavailable) {
+		t.Errorf("CompleteOnboardingStep() error = %v, want ErrSettingsUnavailable", err)
+	}
+}
+
+func TestApp_AnalyzeQueued(t *testing.T) {
+	a := New(testConfig(), nil, &reasonRecordingManager{}, nil)
+
+	t.Run("records reason", func(t *testing.T) {
+		rec, err := a.analyzeQueued(context.Background(), "AAPL", "Portfolio review")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rec.TriggerReason != "Portfolio review" {
+			t.Errorf("TriggerReason = %q, want Portfolio review", rec.TriggerReason)
+		}
+	})
+
+	t.Run("waits for a slot until cancelled", func(t *testing.T) {
+		for i := 0; i < a.AnalysisSemCapacity(); i++ {
+			a.analysisSem <- struct{}{}
+		}
+		defer func() {
+			for i := 0; i < a.AnalysisSemCapacity(); i++ {
+				<-a.analysisSem
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if _, err := a.analyzeQueued(ctx, "AAPL", "Portfolio review"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded while slots are full, got %v", err)
+		}
+	})
+}
+
+func TestBuyCostPerShare(t *testing.T) {
+	trade := models.NewTrade("AAPL", models.TradeSideBuy, decimal.NewFromInt(10), decimal.NewFromInt(100))
+	models.NewFeeSchedule(1, 0.1, 0).Apply(trade)
+
+	// (1000 + 1 + 10 * 0.1) / 10
+	if got := buyCostPerShare(trade); !got.Equal(decimal.NewFromFloat(100.2)) {
+		t.Errorf("buyCostPerShare = %v, want 100.2", got)
+	}
+}
+
 func TestApp_RunScreener_NotInitialized(t *testing.T) {
 	ctx := context.Background()
 	a := testApp(nil)
 	a.Startup(ctx)
 
-	_, err := a.RunScreener()
+	_, err := a.RunScreener(nil)
 	if err == nil {
 		t.Error("expected error when screener is nil")
 	}
@@ -289,6 +1189,52 @@ func TestApp_GetScreenerRun_InvalidUUID(t *testing.T) {
 	}
 }
 
+func TestApp_RetryFailedScreenerCandidates(t *testing.T) {
+	ctx := context.Background()
+	a := testApp(nil)
+	a.Startup(ctx)
+
+	if _, err := a.RetryFailedScreenerCandidates("550e8400-e29b-41d4-a716-446655440000"); err == nil {
+		t.Error("expected error when screener is nil")
+	}
+
+	mockScreener := &mockScreener{}
+	a.SetScreener(mockScreener)
+
+	if _, err := a.RetryFailedScreenerCandidates("invalid-uuid"); err == nil {
+		t.Error("expected error with invalid UUID")
+	}
+	if _, err := a.RetryFailedScreenerCandidates("550e8400-e29b-41d4-a716-446655440000"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !mockScreener.retryFailedCalled {
+		t.Error("RetryFailed should be called on the screener")
+	}
+}
+
+func TestApp_ReplayScreenerRun(t *testing.T) {
+	ctx := context.Background()
+	a := testApp(nil)
+	a.Startup(ctx)
+
+	if _, err := a.ReplayScreenerRun("550e8400-e29b-41d4-a716-446655440000", models.ScreenerReplayRequest{}); err == nil {
+		t.Error("expected error when screener is nil")
+	}
+
+	mockScreener := &mockScreener{}
+	a.SetScreener(mockScreener)
+
+	if _, err := a.ReplayScreenerRun("invalid-uuid", models.ScreenerReplayRequest{}); err == nil {
+		t.Error("expected error with invalid UUID")
+	}
+	if _, err := a.ReplayScreenerRun("550e8400-e29b-41d4-a716-446655440000", models.ScreenerReplayRequest{PERatioMax: 20}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !mockScreener.replayRunCalled {
+		t.Error("ReplayRun should be called on the screener")
+	}
+}
+
 func TestApp_GetTopPicks_NotInitialized(t *testing.T) {
 	ctx := context.Background()
 	a := testApp(nil)
@@ -307,9 +1253,11 @@ type mockScreener struct {
 	getRunHistoryCalled  bool
 	getRunCalled         bool
 	getLatestPicksCalled bool
+	retryFailedCalled    bool
+	replayRunCalled      bool
 }
 
-func (m *mockScreener) RunScreen(ctx context.Context) (*models.ScreenerRun, error) {
+func (m *mockScreener) RunScreen(ctx context.Context, overrides *models.ScreenerFilters) (*models.ScreenerRun, error) {
 	m.runScreenCalled = true
 	return &models.ScreenerRun{}, nil
 }
@@ -334,6 +1282,16 @@ func (m *mockScreener) GetRun(ctx context.Context, id uuid.UUID) (*models.Screen
 	return nil, nil
 }
 
+func (m *mockScreener) RetryFailed(ctx context.Context, id uuid.UUID) (*models.ScreenerRun, error) {
+	m.retryFailedCalled = true
+	return nil, nil
+}
+
+func (m *mockScreener) ReplayRun(ctx context.Context, id uuid.UUID, req models.ScreenerReplayRequest) (*models.ScreenerRunComparison, error) {
+	m.replayRunCalled = true
+	return nil, nil
+}
+
 // mockPortfolioManager implements PortfolioManagerInterface for testing
 type mockPortfolioManager struct{}
 
@@ -364,10 +1322,26 @@ func (m *mockScreenerRepo) GetScreenerRunHistory(ctx context.Context, limit int)
 	return nil, nil
 }
 
+func (m *mockScreenerRepo) SaveScreenerUniverse(ctx context.Context, runID uuid.UUID, entries []models.ScreenerUniverseEntry) error {
+	return nil
+}
+
+func (m *mockScreenerRepo) GetScreenerUniverse(ctx context.Context, runID uuid.UUID) ([]models.ScreenerUniverseEntry, error) {
+	return nil, nil
+}
+
 func (m *mockScreenerRepo) CreateRecommendation(ctx context.Context, rec *models.Recommendation) error {
 	return nil
 }
 
+func (m *mockScreenerRepo) GetSymbolListEntries(ctx context.Context) ([]models.SymbolListEntry, error) {
+	return nil, nil
+}
+
+func (m *mockScreenerRepo) GetScreenerPreset(ctx context.Context, name string) (*models.ScreenerPreset, error) {
+	return nil, nil
+}
+
 func TestApp_SetScreenerFactory(t *testing.T) {
 	cfg := testConfig()
 	a := New(cfg, nil, &mockPortfolioManager{}, nil)
@@ -485,6 +1459,74 @@ func TestApp_InitializeScreenerWithFMPKey(t *testing.T) {
 	})
 }
 
+func TestNewKeyResolver(t *testing.T) {
+	cfg := testConfig()
+	cfg.FMP.APIKey = "env-fmp-key"
+	resolve := NewKeyResolver(cfg, nil)
+	ctx := context.Background()
+
+	if creds, ok := resolve(ctx, services.BreakerFMP); !ok || creds.APIKey != "env-fmp-key" {
+		t.Errorf("resolve(fmp) = %+v, %v, want the environment key", creds, ok)
+	}
+	if _, ok := resolve(ctx, services.BreakerNewsAPI); ok {
+		t.Error("resolve(newsapi) should report no key configured")
+	}
+
+	userCtx := settings.WithAPIKeys(ctx, map[settings.ServiceName]*settings.APIKeyConfig{
+		settings.ServiceFMP:    {ServiceName: settings.ServiceFMP, APIKey: "user-fmp-key"},
+		settings.ServiceAlpaca: {ServiceName: settings.ServiceAlpaca, APIKey: "user-alpaca-key", APISecret: "user-secret"},
+	})
+	if creds, ok := resolve(userCtx, services.BreakerFMP); !ok || creds.APIKey != "user-fmp-key" {
+		t.Errorf("resolve(fmp) = %+v, %v, want the user's key", creds, ok)
+	}
+	if creds, ok := resolve(userCtx, services.BreakerAlpaca); !ok || creds.APISecret != "user-secret" {
+		t.Errorf("resolve(alpaca) = %+v, %v, want the user's key and secret", creds, ok)
+	}
+}
+
+func TestNewKeyResolver_Ollama(t *testing.T) {
+	cfg := testConfig()
+	resolve := NewKeyResolver(cfg, nil)
+	ctx := context.Background()
+
+	if _, ok := resolve(ctx, services.BreakerOllama); ok {
+		t.Error("resolve(ollama) should report unconfigured without a base URL or LLM_PROVIDER")
+	}
+	cfg.LLM.Provider = "ollama"
+	if creds, ok := resolve(ctx, services.BreakerOllama); !ok || creds.BaseURL != "" {
+		t.Errorf("resolve(ollama) = %+v, %v, want configured with the default URL", creds, ok)
+	}
+	cfg.LLM.Provider = ""
+	cfg.Ollama.BaseURL = "http://gpu-box:11434"
+	if creds, ok := resolve(ctx, services.BreakerOllama); !ok || creds.BaseURL != "http://gpu-box:11434" {
+		t.Errorf("resolve(ollama) = %+v, %v, want the configured URL", creds, ok)
+	}
+}
+
+func TestApp_RequestContext(t *testing.T) {
+	a := New(testConfig(), nil, nil, nil)
+	appCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a.ctx = appCtx
+
+	keys := map[settings.ServiceName]*settings.APIKeyConfig{
+		settings.ServiceFMP: {ServiceName: settings.ServiceFMP, APIKey: "user-fmp-key"},
+	}
+	reqCtx, reqCancel := context.WithCancel(settings.WithAPIKeys(context.Background(), keys))
+	reqCancel()
+
+	ctx := a.requestContext(reqCtx)
+	if ctx.Err() != nil {
+		t.Error("expected the request's cancellation not to carry over")
+	}
+	if got := settings.APIKeysFromContext(ctx)[settings.ServiceFMP]; got == nil || got.APIKey != "user-fmp-key" {
+		t.Errorf("keys = %v, want the request's keys", settings.APIKeysFromContext(ctx))
+	}
+	if settings.APIKeysFromContext(a.requestContext(context.Background())) != nil {
+		t.Error("expected no keys for a request without them")
+	}
+}
+
 func TestApp_ScreenerStatus(t *testing.T) {
 	t.Run("no dependencies", func(t *testing.T) {
 		cfg := testConfig()
@@ -612,3 +1654,21 @@ func TestApp_SetUseMockServices(t *testing.T) {
 		}
 	})
 }
+
+func TestApp_PauseAutomation(t *testing.T) {
+	a := testApp(nil)
+	if a.AutomationPaused() {
+		t.Fatal("expected automation to start unpaused")
+	}
+
+	a.PauseAutomation()
+	a.PauseAutomation()
+	if !a.AutomationPaused() {
+		t.Error("expected automation paused")
+	}
+
+	a.ResumeAutomation()
+	if a.AutomationPaused() {
+		t.Error("expected automation resumed")
+	}
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+)
+
+// GetAttributionReport credits the realized P&L of every position closed since the
+// given time, or ever when since is nil, to the agent that drove the recommendation
+// opening it. Drivers are found with the agent weights recorded on each recommendation,
+// or the currently configured ones for recommendations made before weights were recorded.
+func (a *App) GetAttributionReport(since *time.Time) (*models.AttributionReport, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	// Positions closed in the period may have been opened long before it
+	trades, err := a.repo.GetExecutedTradesBetween(a.ctx, time.Time{}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	recs, err := a.repo.GetExecutedRecommendations(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := models.AgentWeights{
+		Fundamental: a.cfg.Agent.WeightFundamental,
+		News:        a.cfg.Agent.WeightNews,
+		Technical:   a.cfg.Agent.WeightTechnical,
+		Social:      a.cfg.Agent.WeightSocial,
+		Insider:     a.cfg.Agent.WeightInsider,
+		Macro:       a.cfg.Agent.WeightMacro,
+	}
+	return models.BuildAttributionReport(trades, recs, weights, since), nil
+}
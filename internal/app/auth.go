@@ -0,0 +1,86 @@
+package app
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/google/uuid"
+)
+
+// apiTokenTouchInterval is how stale a token's last use may get before a request
+// records it again, so busy clients don't write on every request
+const apiTokenTouchInterval = time.Minute
+
+// adminTokenName names the token configured by API_ADMIN_TOKEN in audit entries
+const adminTokenName = "admin (API_ADMIN_TOKEN)"
+
+// AuthenticateAPIToken returns the token a secret belongs to, or nil for an unknown
+// secret. The API_ADMIN_TOKEN secret is accepted with every scope, so the first tokens
+// can be created before any are stored.
+func (a *App) AuthenticateAPIToken(secret string) (*models.APIToken, error) {
+	if admin := a.cfg.HTTP.AdminToken; admin != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(admin)) == 1 {
+		return &models.APIToken{Name: adminTokenName, Scopes: []models.APIScope{models.APIScopeAdmin}}, nil
+	}
+	if a.repo == nil {
+		return nil, nil
+	}
+
+	token, err := a.repo.GetAPITokenByHash(a.ctx, models.HashAPIToken(secret))
+	if err != nil || token == nil {
+		return nil, err
+	}
+	now := time.Now()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenTouchInterval {
+		if err := a.repo.TouchAPIToken(a.ctx, token.ID, now); err != nil {
+			observability.Warn("failed to record API token use", "token_id", token.ID, "error", err)
+		}
+		token.LastUsedAt = &now
+	}
+	return token, nil
+}
+
+// CreateAPIToken creates an API token with the given scopes, returning it with the
+// secret, which is not stored and can't be shown again
+func (a *App) CreateAPIToken(name string, scopes []models.APIScope) (*models.APIToken, string, error) {
+	if a.repo == nil {
+		return nil, "", fmt.Errorf("database not initialized")
+	}
+	token, secret, err := models.NewAPIToken(name, scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := a.repo.CreateAPIToken(a.ctx, token, models.HashAPIToken(secret)); err != nil {
+		return nil, "", err
+	}
+
+	observability.Info("API token created", "token_id", token.ID, "name", token.Name, "scopes", token.Scopes)
+	return token, secret, nil
+}
+
+// GetAPITokens returns every API token, newest first, without their secrets
+func (a *App) GetAPITokens() ([]models.APIToken, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return a.repo.GetAPITokens(a.ctx)
+}
+
+// RevokeAPIToken deletes an API token, reporting whether it existed
+func (a *App) RevokeAPIToken(id string) (bool, error) {
+	if a.repo == nil {
+		return false, fmt.Errorf("database not initialized")
+	}
+	tokenID, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid token ID: %w", err)
+	}
+	deleted, err := a.repo.DeleteAPIToken(a.ctx, tokenID)
+	if err == nil && deleted {
+		observability.Info("API token revoked", "token_id", tokenID)
+	}
+	return deleted, err
+}
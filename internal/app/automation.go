@@ -0,0 +1,25 @@
+package app
+
+import "trade-machine/observability"
+
+// PauseAutomation stops background work that acts without the user asking: price-move
+// re-analyses, cache refreshes, scheduled screener runs and the tranches of split
+// recommendations, which wait until resumed. Reconciliation, backups and the call ledger keep running. The pause
+// lasts until ResumeAutomation or a restart.
+func (a *App) PauseAutomation() {
+	if !a.automationPaused.Swap(true) {
+		observability.Info("automated jobs paused")
+	}
+}
+
+// ResumeAutomation restarts the jobs stopped by PauseAutomation
+func (a *App) ResumeAutomation() {
+	if a.automationPaused.Swap(false) {
+		observability.Info("automated jobs resumed")
+	}
+}
+
+// AutomationPaused reports whether automated jobs are paused
+func (a *App) AutomationPaused() bool {
+	return a.automationPaused.Load()
+}
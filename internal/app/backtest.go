@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"trade-machine/agents"
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// ErrBacktestUnavailable is returned when no backtest engine is configured
+var ErrBacktestUnavailable = errors.New("backtesting not available: Alpaca and database required")
+
+// BacktestEngineInterface defines the engine that replays daily bars through a strategy
+type BacktestEngineInterface interface {
+	Run(ctx context.Context, req models.BacktestRequest, strategy agents.ActionStrategy, start, end time.Time) (*models.BacktestRun, error)
+}
+
+// StrategyGetter is implemented by portfolio managers that report their live action strategy
+type StrategyGetter interface {
+	GetStrategy() agents.ActionStrategy
+}
+
+// SetBacktester sets the backtest engine (optional dependency)
+func (a *App) SetBacktester(e BacktestEngineInterface) {
+	a.backtester = e
+}
+
+// RunBacktest replays daily bars for the request's symbols through an action strategy and
+// saves the run. Without a strategy name the portfolio manager's live strategy is used, so
+// a change can be compared against what is trading now.
+func (a *App) RunBacktest(req models.BacktestRequest) (*models.BacktestRun, error) {
+	if a.repo == nil || a.backtester == nil {
+		return nil, ErrBacktestUnavailable
+	}
+
+	start, end, err := req.Normalize(time.Now(), a.cfg.PositionSizing.MaxPositionPercent)
+	if err != nil {
+		return nil, err
+	}
+	strategy := agents.StrategyFromName(req.Strategy)
+	if getter, ok := a.portfolioManager.(StrategyGetter); ok && req.Strategy == "" {
+		strategy = getter.GetStrategy()
+	}
+
+	run, err := a.backtester.Run(a.ctx, req, strategy, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.repo.SaveBacktestRun(a.ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to save backtest run: %w", err)
+	}
+
+	observability.Info("backtest completed",
+		"symbols", len(run.Request.Symbols),
+		"strategy", run.Strategy,
+		"trades", run.Stats.Trades,
+		"total_return", run.Stats.TotalReturn,
+		"duration_ms", run.DurationMs)
+	return run, nil
+}
+
+// GetBacktestRun returns a saved backtest by ID, or nil if it does not exist
+func (a *App) GetBacktestRun(id string) (*models.BacktestRun, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	runID, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+	return a.repo.GetBacktestRun(a.ctx, runID)
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"trade-machine/agents"
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+)
+
+// backtestRepo keeps saved backtest runs in memory
+type backtestRepo struct {
+	RepositoryInterface
+	runs map[uuid.UUID]*models.BacktestRun
+}
+
+func (r *backtestRepo) SaveBacktestRun(ctx context.Context, run *models.BacktestRun) error {
+	r.runs[run.ID] = run
+	return nil
+}
+
+func (r *backtestRepo) GetBacktestRun(ctx context.Context, id uuid.UUID) (*models.BacktestRun, error) {
+	return r.runs[id], nil
+}
+
+// stubBacktester records the strategy and request it was run with
+type stubBacktester struct {
+	strategy agents.ActionStrategy
+	req      models.BacktestRequest
+}
+
+func (s *stubBacktester) Run(ctx context.Context, req models.BacktestRequest, strategy agents.ActionStrategy, start, end time.Time) (*models.BacktestRun, error) {
+	s.strategy, s.req = strategy, req
+	return &models.BacktestRun{ID: uuid.New(), Request: req, Strategy: strategy.Name()}, nil
+}
+
+// strategyManager reports a fixed live strategy
+type strategyManager struct {
+	mockPortfolioManager
+	strategy agents.ActionStrategy
+}
+
+func (m *strategyManager) GetStrategy() agents.ActionStrategy {
+	return m.strategy
+}
+
+func TestApp_RunBacktest(t *testing.T) {
+	repo := &backtestRepo{runs: make(map[uuid.UUID]*models.BacktestRun)}
+	a := New(testConfig(), repo, &strategyManager{strategy: agents.NewConservativeStrategy()}, nil)
+	a.ctx = context.Background()
+
+	req := models.BacktestRequest{Symbols: []string{"aapl"}, Start: "2025-01-02", End: "2025-06-30"}
+	if _, err := a.RunBacktest(req); !errors.Is(err, ErrBacktestUnavailable) {
+		t.Errorf("RunBacktest() error = %v, want ErrBacktestUnavailable without an engine", err)
+	}
+
+	engine := &stubBacktester{}
+	a.SetBacktester(engine)
+	if _, err := a.RunBacktest(models.BacktestRequest{Start: "2025-01-02", End: "2025-06-30"}); !errors.Is(err, models.ErrInvalidBacktest) {
+		t.Errorf("RunBacktest() error = %v, want ErrInvalidBacktest without symbols", err)
+	}
+
+	run, err := a.RunBacktest(req)
+	if err != nil {
+		t.Fatalf("RunBacktest() error = %v", err)
+	}
+	if run.Strategy != "conservative" || engine.req.Symbols[0] != "AAPL" || engine.req.PositionPercent != a.cfg.PositionSizing.MaxPositionPercent {
+		t.Errorf("ran %+v with %s, want the normalized request and the live strategy", engine.req, run.Strategy)
+	}
+	saved, err := a.GetBacktestRun(run.ID.String())
+	if err != nil || saved != run {
+		t.Errorf("GetBacktestRun() = %+v, %v; want the saved run", saved, err)
+	}
+
+	req.Strategy = "aggressive"
+	if _, err := a.RunBacktest(req); err != nil {
+		t.Fatalf("RunBacktest() error = %v", err)
+	}
+	if engine.strategy.Name() != "aggressive" {
+		t.Errorf("strategy = %s, want the requested one over the live strategy", engine.strategy.Name())
+	}
+}
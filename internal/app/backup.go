@@ -0,0 +1,26 @@
+package app
+
+import (
+	"context"
+
+	"trade-machine/backup"
+	"trade-machine/internal/settings"
+)
+
+// NewBackupStorageResolver resolves backup storage from the bucket configured in the
+// settings store. The store is read on every backup, so a bucket saved in settings
+// applies without a restart.
+func NewBackupStorageResolver(store *settings.Store) backup.StorageResolver {
+	return func(ctx context.Context) (backup.Storage, error) {
+		key := store.Resolve(ctx, settings.ServiceBackupStorage)
+		if key == nil {
+			return nil, backup.ErrStorageNotConfigured
+		}
+		return backup.NewS3Storage(backup.S3Config{
+			URL:       key.BaseURL,
+			Region:    key.Region,
+			AccessKey: key.APIKey,
+			SecretKey: key.APISecret,
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/google/uuid"
+)
+
+// batchRetention is how long a batch analysis can be polled after it starts
+const batchRetention = time.Hour
+
+// batchTriggerReason is recorded on the recommendations a batch analysis makes
+const batchTriggerReason = "Batch analysis"
+
+// batchConcurrencyLimit leaves one of the analysis slots free of batch analyses, so a
+// batch doesn't hold every slot and make single analyses fail while it runs
+func batchConcurrencyLimit(analysisLimit int) int {
+	return max(1, analysisLimit-1)
+}
+
+// batchJob guards a batch analysis its symbols' goroutines update as they finish
+type batchJob struct {
+	mu    sync.Mutex
+	batch *models.BatchAnalysis
+}
+
+func (j *batchJob) start(i int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.batch.Start(i)
+}
+
+func (j *batchJob) finish(i int, rec *models.Recommendation, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.batch.Finish(i, rec, err)
+}
+
+func (j *batchJob) snapshot() *models.BatchAnalysis {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.batch.Copy()
+}
+
+// AnalyzeBatch starts analyzing each symbol in the background and returns the batch with
+// its ID at once. Symbols wait for the analysis slots shared with AnalyzeStock rather than
+// failing when they are all in use, and batches together run one analysis fewer at a time
+// than ANALYSIS_CONCURRENCY_LIMIT, so a slot stays free for single analyses. Blocked
+// symbols fail without being analyzed.
+func (a *App) AnalyzeBatch(symbols []string) (*models.BatchAnalysis, error) {
+	if a.portfolioManager == nil {
+		return nil, fmt.Errorf("portfolio manager not initialized")
+	}
+	batch, err := models.NewBatchAnalysis(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &batchJob{batch: batch}
+	a.batches.put(batch.ID.String(), job)
+	for i, item := range batch.Items {
+		go a.runBatchItem(job, i, item.Symbol)
+	}
+
+	observability.Info("batch analysis started", "batch_id", batch.ID, "symbols", len(batch.Items))
+	return job.snapshot(), nil
+}
+
+// runBatchItem analyzes one symbol of a batch once an analysis slot frees up
+func (a *App) runBatchItem(job *batchJob, i int, symbol string) {
+	if err := a.CheckSymbolAllowed(symbol); err != nil {
+		job.finish(i, nil, err)
+		return
+	}
+
+	select {
+	case a.batchSem <- struct{}{}:
+		defer func() { <-a.batchSem }()
+	case <-a.ctx.Done():
+		job.finish(i, nil, a.ctx.Err())
+		return
+	}
+	select {
+	case a.analysisSem <- struct{}{}:
+	case <-a.ctx.Done():
+		job.finish(i, nil, a.ctx.Err())
+		return
+	}
+
+	job.start(i)
+	rec, err := a.withDisclaimer(a.analyzeInSlot(models.WithTriggerReason(a.ctx, batchTriggerReason), symbol))
+	if err != nil {
+		observability.Warn("batch analysis failed", "symbol", symbol, "error", err)
+	}
+	job.finish(i, rec, err)
+}
+
+// GetBatchAnalysis returns the progress of a batch analysis, with the results of the
+// symbols finished so far, or nil once it is unknown or past batchRetention
+func (a *App) GetBatchAnalysis(id string) (*models.BatchAnalysis, error) {
+	batchID, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+	job, ok := a.batches.get(uuid.UUID(batchID).String())
+	if !ok {
+		return nil, nil
+	}
+	return job.snapshot(), nil
+}
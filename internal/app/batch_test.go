@@ -0,0 +1,133 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+)
+
+// gatedManager analyzes each symbol once released, failing those in fail
+type gatedManager struct {
+	release chan struct{}
+	fail    map[string]bool
+}
+
+func (m *gatedManager) AnalyzeSymbol(ctx context.Context, symbol string) (*models.Recommendation, error) {
+	<-m.release
+	if m.fail[symbol] {
+		return nil, errors.New("agents failed")
+	}
+	rec := models.NewRecommendation(symbol, models.RecommendationActionHold, "")
+	rec.TriggerReason = models.TriggerReasonFromContext(ctx)
+	return rec, nil
+}
+
+func TestApp_AnalyzeBatch(t *testing.T) {
+	cfg := testConfig()
+	cfg.Agent.ConcurrencyLimit = 1
+	manager := &gatedManager{release: make(chan struct{}), fail: map[string]bool{"MSFT": true}}
+	a := New(cfg, nil, manager, nil)
+	a.ctx = context.Background()
+
+	batch, err := a.AnalyzeBatch([]string{"aapl", "MSFT"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if batch.Done || len(batch.Items) != 2 {
+		t.Fatalf("batch = %+v, want two items still in progress", batch)
+	}
+
+	// Only one analysis runs at a time with a single slot
+	waitFor(t, func() bool {
+		b, _ := a.GetBatchAnalysis(batch.ID.String())
+		running, queued := 0, 0
+		for _, item := range b.Items {
+			switch item.Status {
+			case models.BatchItemRunning:
+				running++
+			case models.BatchItemQueued:
+				queued++
+			}
+		}
+		return running == 1 && queued == 1
+	})
+
+	manager.release <- struct{}{}
+	manager.release <- struct{}{}
+	var done *models.BatchAnalysis
+	waitFor(t, func() bool {
+		done, _ = a.GetBatchAnalysis(batch.ID.String())
+		return done.Done
+	})
+	if done.Completed != 2 || done.Failed != 1 {
+		t.Errorf("completed %d, failed %d; want 2 and 1", done.Completed, done.Failed)
+	}
+	for _, item := range done.Items {
+		switch item.Symbol {
+		case "AAPL":
+			if item.Recommendation == nil || item.Recommendation.TriggerReason != batchTriggerReason {
+				t.Errorf("AAPL = %+v, want a recommendation recorded as a batch analysis", item)
+			}
+		case "MSFT":
+			if item.Status != models.BatchItemFailed || item.Error == "" {
+				t.Errorf("MSFT = %+v, want failed with its error", item)
+			}
+		}
+	}
+
+	if unknown, err := a.GetBatchAnalysis("5a3e1c2b-8f4d-4e6a-9b7c-1d2e3f4a5b6c"); err != nil || unknown != nil {
+		t.Errorf("GetBatchAnalysis(unknown) = %v, %v; want nil", unknown, err)
+	}
+	if _, err := a.AnalyzeBatch(nil); !errors.Is(err, models.ErrInvalidBatchAnalysis) {
+		t.Errorf("expected an empty batch to be refused, got %v", err)
+	}
+}
+
+func TestApp_AnalyzeBatch_LeavesSlotForSingleAnalyses(t *testing.T) {
+	cfg := testConfig()
+	cfg.Agent.ConcurrencyLimit = 3
+	manager := &gatedManager{release: make(chan struct{})}
+	a := New(cfg, nil, manager, nil)
+	a.ctx = context.Background()
+
+	batch, err := a.AnalyzeBatch([]string{"AAPL", "MSFT", "NVDA"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitFor(t, func() bool {
+		b, _ := a.GetBatchAnalysis(batch.ID.String())
+		running := 0
+		for _, item := range b.Items {
+			if item.Status == models.BatchItemRunning {
+				running++
+			}
+		}
+		return running == 2
+	})
+	if len(a.analysisSem) != 2 {
+		t.Errorf("batch holds %d analysis slots, want 2 of 3", len(a.analysisSem))
+	}
+
+	for range batch.Items {
+		manager.release <- struct{}{}
+	}
+	waitFor(t, func() bool {
+		b, _ := a.GetBatchAnalysis(batch.ID.String())
+		return b.Done
+	})
+}
+
+// waitFor polls cond until it holds, failing the test after a second
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
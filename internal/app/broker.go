@@ -0,0 +1,97 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"trade-machine/internal/settings"
+	"trade-machine/models"
+	"trade-machine/observability"
+	"trade-machine/services"
+)
+
+// SetBroker registers a broker trades can be routed to (optional dependency). Alpaca is
+// registered by New when configured; other brokers are added alongside it.
+func (a *App) SetBroker(name string, b services.BrokerService) {
+	a.brokerMu.Lock()
+	defer a.brokerMu.Unlock()
+	if a.brokers == nil {
+		a.brokers = make(map[string]services.BrokerService)
+	}
+	a.brokers[name] = b
+}
+
+// broker returns the broker new orders are placed with and its name, or nil when the
+// active broker is not configured
+func (a *App) broker() (string, services.BrokerService) {
+	a.brokerMu.RLock()
+	defer a.brokerMu.RUnlock()
+	return a.activeBroker, a.brokers[a.activeBroker]
+}
+
+// brokerFor returns the broker that placed a trade, so its order is looked up where it lives
+func (a *App) brokerFor(trade *models.Trade) services.BrokerService {
+	a.brokerMu.RLock()
+	defer a.brokerMu.RUnlock()
+	return a.brokers[trade.BrokerName()]
+}
+
+// GetBroker returns the broker new orders go to and the brokers that are configured
+func (a *App) GetBroker() models.BrokerStatus {
+	a.brokerMu.RLock()
+	defer a.brokerMu.RUnlock()
+
+	status := models.BrokerStatus{Active: a.activeBroker, Available: []string{}}
+	for _, name := range models.Brokers {
+		if _, ok := a.brokers[name]; ok {
+			status.Available = append(status.Available, name)
+		}
+	}
+	return status
+}
+
+// SetActiveBroker routes new orders to the named broker and saves the choice in settings,
+// when available, so it outlives a restart. Trades already placed keep their broker.
+func (a *App) SetActiveBroker(name string) (models.BrokerStatus, error) {
+	name, err := models.NormalizeBroker(name)
+	if err != nil {
+		return models.BrokerStatus{}, err
+	}
+	a.brokerMu.RLock()
+	_, ok := a.brokers[name]
+	a.brokerMu.RUnlock()
+	if !ok {
+		return models.BrokerStatus{}, fmt.Errorf("%w: %s is not configured", models.ErrUnknownBroker, name)
+	}
+
+	if a.settings != nil {
+		if err := a.settings.SaveBroker(settings.Broker{Name: name, UpdatedAt: time.Now()}); err != nil {
+			return models.BrokerStatus{}, err
+		}
+	}
+	a.setActiveBroker(name)
+
+	observability.Info("broker changed", "broker", name)
+	return a.GetBroker(), nil
+}
+
+func (a *App) setActiveBroker(name string) {
+	a.brokerMu.Lock()
+	a.activeBroker = name
+	a.brokerMu.Unlock()
+}
+
+// applySavedBroker switches to the broker chosen from the API, ignoring a choice that is
+// no longer configured so trading falls back to BROKER
+func (a *App) applySavedBroker(saved *settings.Broker) {
+	name, err := models.NormalizeBroker(saved.Name)
+	if err == nil && !slices.Contains(a.GetBroker().Available, name) {
+		err = fmt.Errorf("%w: %s is not configured", models.ErrUnknownBroker, name)
+	}
+	if err != nil {
+		observability.Warn("ignoring saved broker", "error", err)
+		return
+	}
+	a.setActiveBroker(name)
+}
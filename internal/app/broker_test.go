@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"trade-machine/models"
+	"trade-machine/services"
+
+	"github.com/shopspring/decimal"
+)
+
+type stubBroker struct {
+	services.BrokerService
+	positions []models.Position
+}
+
+func (m *stubBroker) GetPositions(ctx context.Context) ([]models.Position, error) {
+	return m.positions, nil
+}
+
+func TestApp_SetActiveBroker(t *testing.T) {
+	alpaca := &positionAlpacaService{positions: []models.Position{{Symbol: "AAPL", Quantity: decimal.NewFromInt(20)}}}
+	a := New(testConfig(), nil, nil, alpaca)
+	a.ctx = context.Background()
+
+	if status := a.GetBroker(); status.Active != models.BrokerAlpaca || !slices.Equal(status.Available, []string{models.BrokerAlpaca}) {
+		t.Errorf("GetBroker() = %+v, want alpaca active and the only one available", status)
+	}
+	if _, err := a.SetActiveBroker("ibkr"); !errors.Is(err, models.ErrUnknownBroker) {
+		t.Errorf("expected an unconfigured broker to be refused, got %v", err)
+	}
+	if _, err := a.SetActiveBroker("schwab"); !errors.Is(err, models.ErrUnknownBroker) {
+		t.Errorf("expected an unsupported broker to be refused, got %v", err)
+	}
+
+	a.SetBroker(models.BrokerIBKR, &stubBroker{positions: []models.Position{{Symbol: "AAPL", Quantity: decimal.NewFromInt(5)}}})
+	status, err := a.SetActiveBroker(" IBKR ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Active != models.BrokerIBKR || len(status.Available) != 2 {
+		t.Errorf("status = %+v, want ibkr active with both brokers available", status)
+	}
+
+	position, err := a.brokerPosition("AAPL")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if position == nil || !position.Quantity.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("position = %+v, want the IBKR position once it is the active broker", position)
+	}
+
+	// Trades keep the broker that placed them, defaulting to Alpaca for older trades
+	if a.brokerFor(&models.Trade{}) != alpaca {
+		t.Error("expected a trade without a broker to be looked up at Alpaca")
+	}
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"sync"
+	"time"
+)
+
+// ttlCache is a small in-memory cache whose entries expire a fixed time after they
+// are stored. Expired entries are pruned on write.
+type ttlCache[V any] struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]ttlEntry[V]
+}
+
+type ttlEntry[V any] struct {
+	value    V
+	storedAt time.Time
+}
+
+func newTTLCache[V any](ttl time.Duration) *ttlCache[V] {
+	return &ttlCache[V]{ttl: ttl, entries: make(map[string]ttlEntry[V])}
+}
+
+// get returns the value stored under key, if it hasn't expired
+func (c *ttlCache[V]) get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || time.Since(e.storedAt) >= c.ttl {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// take returns the value stored under key like get, and removes it
+func (c *ttlCache[V]) take(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	delete(c.entries, key)
+	if !ok || time.Since(e.storedAt) >= c.ttl {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// put stores value under key
+func (c *ttlCache[V]) put(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, e := range c.entries {
+		if now.Sub(e.storedAt) >= c.ttl {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = ttlEntry[V]{value: value, storedAt: now}
+}
+
+// clear removes every entry
+func (c *ttlCache[V]) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
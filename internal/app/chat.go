@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"trade-machine/agents"
+	"trade-machine/models"
+	"trade-machine/observability"
+	"trade-machine/services"
+)
+
+// ErrChatUnavailable is returned for a chat question without an LLM configured
+var ErrChatUnavailable = errors.New("chat not available: an LLM provider required")
+
+// chatRunLimit is how many of a symbol's recent agent runs are searched for the context
+// given to the model
+const chatRunLimit = 20
+
+// SetChatLLM sets the LLM that answers questions about symbols (optional dependency)
+func (a *App) SetChatLLM(llm services.LLMService) {
+	a.chatLLM = llm
+}
+
+// ChatAboutSymbol answers the last question of a conversation about a symbol, passing
+// the answer to onDelta as it streams in, and returns the whole answer. The symbol's
+// latest recommendation and each agent's latest run are given to the model as context;
+// without a database the model is told there is no analysis. Questions are refused like
+// analyses once the monthly LLM budget blocks, and their tokens are recorded as the chat
+// agent's usage.
+func (a *App) ChatAboutSymbol(ctx context.Context, req models.ChatRequest, onDelta func(delta string) error) (string, error) {
+	if a.chatLLM == nil {
+		return "", ErrChatUnavailable
+	}
+	if err := req.Normalize(); err != nil {
+		return "", err
+	}
+
+	ctx, span := observability.StartSpan(a.requestContext(ctx), "app.ChatAboutSymbol", "symbol", req.Symbol)
+	defer span.End()
+	if err := a.checkLLMBudget(ctx); err != nil {
+		return "", err
+	}
+
+	var (
+		rec  *models.Recommendation
+		runs []models.AgentRun
+	)
+	if a.repo != nil {
+		var err error
+		if rec, err = a.repo.GetLatestRecommendationForSymbol(ctx, req.Symbol); err != nil {
+			observability.Debug("chat recommendation unavailable", "symbol", req.Symbol, "error", err)
+		}
+		if runs, err = a.repo.GetRecentRunsForSymbol(ctx, req.Symbol, chatRunLimit); err != nil {
+			observability.Debug("chat agent runs unavailable", "symbol", req.Symbol, "error", err)
+		}
+	}
+
+	messages := make([]services.ChatMessage, len(req.Messages))
+	for i, msg := range req.Messages {
+		messages[i] = services.ChatMessage{Role: msg.Role, Content: msg.Content}
+	}
+	usageCtx, usage := services.WithLLMUsage(ctx)
+	answer, err := a.chatLLM.ChatStream(usageCtx, agents.ChatSystemPrompt(req.Symbol, rec, runs), messages, onDelta)
+	a.recordLLMUsage(ctx, models.AgentTypeChat, req.Symbol, usage)
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+	return answer, nil
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/services"
+)
+
+// chatRepo serves a symbol's latest recommendation and agent runs, and the month's LLM
+// spend, recording the LLM usage saved
+type chatRepo struct {
+	RepositoryInterface
+	rec   *models.Recommendation
+	runs  []models.AgentRun
+	spent float64
+	usage []models.LLMUsage
+}
+
+func (r *chatRepo) GetLLMCostSince(ctx context.Context, since time.Time) (float64, error) {
+	return r.spent, nil
+}
+
+func (r *chatRepo) SaveLLMUsage(ctx context.Context, usage []models.LLMUsage) error {
+	r.usage = append(r.usage, usage...)
+	return nil
+}
+
+func (r *chatRepo) GetLatestRecommendationForSymbol(ctx context.Context, symbol string) (*models.Recommendation, error) {
+	return r.rec, nil
+}
+
+func (r *chatRepo) GetRecentRunsForSymbol(ctx context.Context, symbol string, limit int) ([]models.AgentRun, error) {
+	return r.runs, nil
+}
+
+// streamingLLM streams a fixed answer in two pieces, recording what it was asked
+type streamingLLM struct {
+	services.LLMService
+	systemPrompt string
+	messages     []services.ChatMessage
+}
+
+func (l *streamingLLM) ChatStream(ctx context.Context, systemPrompt string, messages []services.ChatMessage, onDelta func(delta string) error) (string, error) {
+	l.systemPrompt, l.messages = systemPrompt, messages
+	services.RecordLLMUsage(ctx, services.BreakerOpenAI, "gpt-4o", 1200, 80)
+	for _, delta := range []string{"Services ", "growth."} {
+		if err := onDelta(delta); err != nil {
+			return "", err
+		}
+	}
+	return "Services growth.", nil
+}
+
+func TestApp_ChatAboutSymbol(t *testing.T) {
+	ctx := context.Background()
+	req := models.ChatRequest{Symbol: "aapl", Messages: []models.ChatTurn{{Role: "user", Content: "Why is this a buy?"}}}
+
+	t.Run("without an LLM", func(t *testing.T) {
+		a := New(testConfig(), nil, nil, nil)
+		if _, err := a.ChatAboutSymbol(ctx, req, func(string) error { return nil }); !errors.Is(err, ErrChatUnavailable) {
+			t.Errorf("error = %v, want ErrChatUnavailable", err)
+		}
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		a := New(testConfig(), nil, nil, nil)
+		a.SetChatLLM(&streamingLLM{})
+		if _, err := a.ChatAboutSymbol(ctx, models.ChatRequest{Symbol: "AAPL"}, func(string) error { return nil }); !errors.Is(err, models.ErrInvalidChat) {
+			t.Errorf("error = %v, want ErrInvalidChat", err)
+		}
+	})
+
+	t.Run("streams an answer with the agents' analysis", func(t *testing.T) {
+		repo := &chatRepo{
+			rec: &models.Recommendation{Symbol: "AAPL", Action: models.RecommendationActionBuy, Reasoning: "Strong services growth", CreatedAt: time.Now()},
+			runs: []models.AgentRun{
+				{AgentType: models.AgentTypeNews, Status: models.AgentRunStatusCompleted, OutputData: map[string]interface{}{"reasoning": "Upbeat launch coverage"}, StartedAt: time.Now()},
+			},
+		}
+		llm := &streamingLLM{}
+		a := New(testConfig(), repo, nil, nil)
+		a.ctx = ctx
+		a.SetChatLLM(llm)
+
+		var streamed strings.Builder
+		answer, err := a.ChatAboutSymbol(ctx, req, func(delta string) error {
+			streamed.WriteString(delta)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if answer != "Services growth." || streamed.String() != answer {
+			t.Errorf("answer = %q, streamed %q", answer, streamed.String())
+		}
+		if !strings.Contains(llm.systemPrompt, "AAPL") || !strings.Contains(llm.systemPrompt, "Strong services growth") || !strings.Contains(llm.systemPrompt, "Upbeat launch coverage") {
+			t.Errorf("system prompt = %q, want the recommendation and agent runs", llm.systemPrompt)
+		}
+		if len(llm.messages) != 1 || llm.messages[0].Content != "Why is this a buy?" {
+			t.Errorf("messages = %+v, want the question", llm.messages)
+		}
+		if len(repo.usage) != 1 || repo.usage[0].AgentType != models.AgentTypeChat || repo.usage[0].Symbol != "AAPL" || repo.usage[0].InputTokens != 1200 {
+			t.Errorf("usage = %+v, want the answer's tokens recorded for the chat agent", repo.usage)
+		}
+	})
+
+	t.Run("over the LLM budget", func(t *testing.T) {
+		repo := &chatRepo{spent: 12}
+		llm := &streamingLLM{}
+		a := New(testConfig(), repo, nil, nil)
+		a.ctx = ctx
+		a.cfg.LLM.MonthlyBudget, a.cfg.LLM.BudgetMode = 10, "block"
+		a.SetChatLLM(llm)
+
+		if _, err := a.ChatAboutSymbol(ctx, req, func(string) error { return nil }); !errors.Is(err, models.ErrLLMBudgetExceeded) {
+			t.Errorf("error = %v, want ErrLLMBudgetExceeded", err)
+		}
+		if llm.systemPrompt != "" {
+			t.Error("expected the LLM not to be asked once the budget blocks")
+		}
+
+		// In warn mode the question is still answered
+		a.cfg.LLM.BudgetMode = "warn"
+		if _, err := a.ChatAboutSymbol(ctx, req, func(string) error { return nil }); err != nil {
+			t.Errorf("error = %v in warn mode, want an answer", err)
+		}
+	})
+}
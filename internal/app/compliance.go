@@ -0,0 +1,124 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"trade-machine/compliance"
+	"trade-machine/config"
+	"trade-machine/internal/settings"
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// ErrDisclaimerNotAcknowledged is returned by trading operations until the current
+// disclaimer has been accepted
+var ErrDisclaimerNotAcknowledged = errors.New("the disclaimer must be accepted before trading")
+
+// ErrDisclaimerVersionMismatch is returned when accepting a disclaimer that has since changed
+var ErrDisclaimerVersionMismatch = errors.New("the disclaimer has changed, review the current version")
+
+// ComplianceStatus describes the disclaimer and whether it has been accepted
+type ComplianceStatus struct {
+	compliance.Disclaimer
+	RequireAcknowledgment bool       `json:"require_acknowledgment"`
+	Acknowledged          bool       `json:"acknowledged"` // The current version was accepted
+	AcknowledgedAt        *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+// newDisclaimer builds the configured disclaimer, falling back to the US preset when the
+// jurisdiction is unknown (Load rejects those, hand-built configs may not)
+func newDisclaimer(cfg config.ComplianceConfig) *compliance.Disclaimer {
+	d, err := compliance.NewDisclaimer(compliance.Jurisdiction(cfg.Jurisdiction), cfg.Disclaimer)
+	if err != nil {
+		observability.Warn("invalid compliance jurisdiction, using the US disclaimer", "error", err)
+		d, _ = compliance.NewDisclaimer(compliance.JurisdictionUS, cfg.Disclaimer)
+	}
+	return d
+}
+
+// Disclaimer returns the disclaimer attached to recommendations, reports and exports
+func (a *App) Disclaimer() *compliance.Disclaimer {
+	return a.disclaimer
+}
+
+// ComplianceStatus returns the disclaimer and whether the user has accepted it
+func (a *App) ComplianceStatus() *ComplianceStatus {
+	status := &ComplianceStatus{
+		Disclaimer:            *a.disclaimer,
+		RequireAcknowledgment: a.cfg.Compliance.RequireAcknowledgment,
+	}
+	if a.settings == nil {
+		return status
+	}
+	if ack := a.settings.DisclaimerAcknowledgment(); ack != nil && ack.Version == a.disclaimer.Version {
+		status.Acknowledged = true
+		status.AcknowledgedAt = &ack.AcceptedAt
+	}
+	return status
+}
+
+// AcknowledgeDisclaimer records that the user accepted the disclaimer. version must be the
+// version the user was shown, so a disclaimer changed in the meantime is not accepted unseen.
+func (a *App) AcknowledgeDisclaimer(version string) (*ComplianceStatus, error) {
+	if a.settings == nil {
+		return nil, ErrSettingsUnavailable
+	}
+	if version != a.disclaimer.Version {
+		return nil, fmt.Errorf("%w: accepted %q, current is %q", ErrDisclaimerVersionMismatch, version, a.disclaimer.Version)
+	}
+
+	err := a.settings.SaveDisclaimerAcknowledgment(settings.DisclaimerAcknowledgment{
+		Version:      a.disclaimer.Version,
+		Jurisdiction: string(a.disclaimer.Jurisdiction),
+		AcceptedAt:   time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	observability.Info("disclaimer acknowledged", "version", a.disclaimer.Version, "jurisdiction", a.disclaimer.Jurisdiction)
+	return a.ComplianceStatus(), nil
+}
+
+// checkDisclaimerAcknowledged blocks trading until the current disclaimer is accepted,
+// when acknowledgment is required
+func (a *App) checkDisclaimerAcknowledged() error {
+	if !a.cfg.Compliance.RequireAcknowledgment || a.ComplianceStatus().Acknowledged {
+		return nil
+	}
+	return ErrDisclaimerNotAcknowledged
+}
+
+// withDisclaimer attaches the disclaimer to a recommendation returned to the user
+func (a *App) withDisclaimer(rec *models.Recommendation, err error) (*models.Recommendation, error) {
+	if rec != nil {
+		rec.Disclaimer = a.disclaimer.Text
+	}
+	return rec, err
+}
+
+// withDisclaimers attaches the disclaimer to recommendations returned to the user
+func (a *App) withDisclaimers(recs []models.Recommendation, err error) ([]models.Recommendation, error) {
+	for i := range recs {
+		recs[i].Disclaimer = a.disclaimer.Text
+	}
+	return recs, err
+}
+
+// reviewWithDisclaimer attaches the disclaimer to a portfolio review returned to the user
+func (a *App) reviewWithDisclaimer(review *models.PortfolioReview, err error) (*models.PortfolioReview, error) {
+	if review != nil {
+		review.Disclaimer = a.disclaimer.Text
+	}
+	return review, err
+}
+
+// reportWithDisclaimer attaches the disclaimer to a reconciliation report returned to the user
+func (a *App) reportWithDisclaimer(report *models.ReconciliationReport, err error) (*models.ReconciliationReport, error) {
+	if report != nil {
+		report.Disclaimer = a.disclaimer.Text
+	}
+	return report, err
+}
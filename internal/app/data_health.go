@@ -0,0 +1,43 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// staleAgentRunAge is how long after starting an agent run still marked running is taken
+// to be abandoned. Agents time out within minutes, so such a run was cut off by a
+// restart or crash and will never be completed.
+const staleAgentRunAge = time.Hour
+
+// DataHealth runs the database integrity checks and reports table sizes and cache
+// statistics. With fix set, the issues that are safe to repair are fixed first and the
+// report shows what remains along with the rows repaired per check.
+func (a *App) DataHealth(fix bool) (*models.DataHealthReport, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	staleBefore := time.Now().Add(-staleAgentRunAge)
+
+	var fixed map[string]int64
+	if fix {
+		var err error
+		if fixed, err = a.repo.FixDataHealth(a.ctx, staleBefore); err != nil {
+			return nil, err
+		}
+		a.invalidateWarm()
+		observability.Info("data-health auto-fix applied", "fixed", fixed)
+	}
+
+	report, err := a.repo.GetDataHealth(a.ctx, staleBefore)
+	if err != nil {
+		return nil, err
+	}
+	if fix {
+		report.ApplyFixes(fixed)
+	}
+	return report, nil
+}
@@ -0,0 +1,166 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+	"trade-machine/observability"
+	"trade-machine/services"
+)
+
+// maxDiagnosticAgentRuns is the most failed agent runs put in a diagnostic bundle
+const maxDiagnosticAgentRuns = 50
+
+// ErrDiagnosticImportUnavailable is returned when importing a bundle without a database
+var ErrDiagnosticImportUnavailable = errors.New("diagnostic import not available: database required")
+
+// ExportDiagnostics builds a sanitized diagnostic bundle for support. A section that
+// can't be collected, such as agent runs without a database, is left empty and noted in
+// the bundle's warnings rather than failing the export.
+func (a *App) ExportDiagnostics() (*models.DiagnosticBundle, error) {
+	cfg, err := a.cfg.Redacted()
+	if err != nil {
+		return nil, fmt.Errorf("failed to redact configuration: %w", err)
+	}
+
+	bundle := &models.DiagnosticBundle{
+		FormatVersion:   models.DiagnosticBundleVersion,
+		CreatedAt:       time.Now(),
+		GoVersion:       runtime.Version(),
+		Platform:        runtime.GOOS + "/" + runtime.GOARCH,
+		Config:          cfg,
+		Logs:            []models.DiagnosticLog{},
+		FailedAgentRuns: []models.AgentRun{},
+		CircuitBreakers: []models.DiagnosticBreaker{},
+		ProviderAlerts:  []models.ProviderAlert{},
+	}
+	for _, entry := range observability.RecentLogs() {
+		bundle.Logs = append(bundle.Logs, models.DiagnosticLog(entry))
+	}
+	for _, s := range services.GetGlobalRegistry().Status() {
+		bundle.CircuitBreakers = append(bundle.CircuitBreakers, models.DiagnosticBreaker{
+			Name:                s.Name,
+			State:               s.State,
+			Level:               s.Level,
+			TotalFailures:       s.TotalFailures,
+			ConsecutiveFailures: s.ConsecutiveFails,
+		})
+	}
+	sort.Slice(bundle.CircuitBreakers, func(i, j int) bool {
+		return bundle.CircuitBreakers[i].Name < bundle.CircuitBreakers[j].Name
+	})
+
+	if a.repo == nil {
+		bundle.Warnings = append(bundle.Warnings, "database not initialized: schema version, agent runs and provider alerts left out")
+		return bundle, nil
+	}
+	if bundle.SchemaVersion, err = a.repo.GetSchemaVersion(a.ctx); err != nil {
+		bundle.Warnings = append(bundle.Warnings, err.Error())
+	}
+	if runs, err := a.repo.GetFailedAgentRuns(a.ctx, maxDiagnosticAgentRuns); err != nil {
+		bundle.Warnings = append(bundle.Warnings, err.Error())
+	} else if runs != nil {
+		bundle.FailedAgentRuns = runs
+	}
+	if alerts, err := a.repo.GetProviderAlerts(a.ctx, false, maxProviderAlerts); err != nil {
+		bundle.Warnings = append(bundle.Warnings, err.Error())
+	} else if alerts != nil {
+		bundle.ProviderAlerts = alerts
+	}
+	return bundle, nil
+}
+
+// ImportDiagnostics loads a bundle from another install into the database, so its failed
+// agent runs and provider alerts can be inspected locally, and lists the settings that
+// differ from the local configuration
+func (a *App) ImportDiagnostics(bundle *models.DiagnosticBundle) (*models.DiagnosticImport, error) {
+	if err := bundle.Validate(); err != nil {
+		return nil, err
+	}
+	if a.repo == nil {
+		return nil, ErrDiagnosticImportUnavailable
+	}
+	return ImportDiagnosticBundle(a.ctx, a.repo, a.cfg, bundle)
+}
+
+// ImportDiagnosticBundle is ImportDiagnostics for tools that have a repository but no
+// running App. Rows already present are skipped, so importing the same bundle twice is
+// harmless. Logs are not imported; they stay in the bundle.
+func ImportDiagnosticBundle(ctx context.Context, repo RepositoryInterface, cfg *config.Config, bundle *models.DiagnosticBundle) (*models.DiagnosticImport, error) {
+	if err := bundle.Validate(); err != nil {
+		return nil, err
+	}
+
+	result := &models.DiagnosticImport{ConfigDiffs: []models.ConfigDiff{}}
+	for i := range bundle.FailedAgentRuns {
+		inserted, err := repo.ImportAgentRun(ctx, &bundle.FailedAgentRuns[i])
+		if err != nil {
+			return result, err
+		}
+		if inserted {
+			result.AgentRuns++
+		} else {
+			result.Skipped++
+		}
+	}
+	for i := range bundle.ProviderAlerts {
+		inserted, err := repo.ImportProviderAlert(ctx, &bundle.ProviderAlerts[i])
+		if err != nil {
+			return result, err
+		}
+		if inserted {
+			result.ProviderAlerts++
+		} else {
+			result.Skipped++
+		}
+	}
+
+	if local, err := repo.GetSchemaVersion(ctx); err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	} else if local != bundle.SchemaVersion {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("bundle was made at schema version %d, local database is at %d", bundle.SchemaVersion, local))
+	}
+
+	localCfg, err := cfg.Redacted()
+	if err != nil {
+		return result, fmt.Errorf("failed to redact configuration: %w", err)
+	}
+	result.ConfigDiffs = diffConfig(bundle.Config, localCfg)
+
+	observability.Info("diagnostic bundle imported", "created_at", bundle.CreatedAt,
+		"agent_runs", result.AgentRuns, "provider_alerts", result.ProviderAlerts, "skipped", result.Skipped)
+	return result, nil
+}
+
+// diffConfig lists the settings in a bundle whose values differ from the local ones, sorted
+// by key. Both sides are redacted, so credentials compare only by whether they are set.
+func diffConfig(bundle, local map[string]any) []models.ConfigDiff {
+	b, l := map[string]string{}, map[string]string{}
+	flattenConfig(b, "", bundle)
+	flattenConfig(l, "", local)
+
+	diffs := []models.ConfigDiff{}
+	for key, bv := range b {
+		if lv, ok := l[key]; !ok || lv != bv {
+			diffs = append(diffs, models.ConfigDiff{Key: key, Bundle: bv, Local: lv})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Key < diffs[j].Key })
+	return diffs
+}
+
+func flattenConfig(into map[string]string, prefix string, m map[string]any) {
+	for k, v := range m {
+		if nested, ok := v.(map[string]any); ok {
+			flattenConfig(into, prefix+k+".", nested)
+			continue
+		}
+		into[prefix+k] = fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+)
+
+// diagnosticsRepo keeps agent runs and provider alerts in memory, keyed by ID
+type diagnosticsRepo struct {
+	RepositoryInterface
+	schema int64
+	runs   map[uuid.UUID]models.AgentRun
+	alerts map[uuid.UUID]models.ProviderAlert
+}
+
+func newDiagnosticsRepo(schema int64) *diagnosticsRepo {
+	return &diagnosticsRepo{schema: schema, runs: map[uuid.UUID]models.AgentRun{}, alerts: map[uuid.UUID]models.ProviderAlert{}}
+}
+
+func (r *diagnosticsRepo) GetSchemaVersion(ctx context.Context) (int64, error) {
+	return r.schema, nil
+}
+
+func (r *diagnosticsRepo) GetFailedAgentRuns(ctx context.Context, limit int) ([]models.AgentRun, error) {
+	var runs []models.AgentRun
+	for _, run := range r.runs {
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+func (r *diagnosticsRepo) GetProviderAlerts(ctx context.Context, activeOnly bool, limit int) ([]models.ProviderAlert, error) {
+	return nil, errors.New("provider_alerts: relation does not exist")
+}
+
+func (r *diagnosticsRepo) ImportAgentRun(ctx context.Context, run *models.AgentRun) (bool, error) {
+	if _, ok := r.runs[run.ID]; ok {
+		return false, nil
+	}
+	r.runs[run.ID] = *run
+	return true, nil
+}
+
+func (r *diagnosticsRepo) ImportProviderAlert(ctx context.Context, alert *models.ProviderAlert) (bool, error) {
+	if _, ok := r.alerts[alert.ID]; ok {
+		return false, nil
+	}
+	r.alerts[alert.ID] = *alert
+	return true, nil
+}
+
+func TestApp_ExportDiagnostics(t *testing.T) {
+	repo := newDiagnosticsRepo(27)
+	failed := models.AgentRun{ID: uuid.New(), Status: models.AgentRunStatusFailed, ErrorMessage: "timeout"}
+	repo.runs[failed.ID] = failed
+	cfg := testConfig()
+	cfg.OpenAI.APIKey = "sk-live-key"
+	a := New(cfg, repo, nil, nil)
+	a.ctx = context.Background()
+
+	bundle, err := a.ExportDiagnostics()
+	if err != nil {
+		t.Fatalf("ExportDiagnostics() error = %v", err)
+	}
+	if bundle.FormatVersion != models.DiagnosticBundleVersion || bundle.SchemaVersion != 27 || len(bundle.FailedAgentRuns) != 1 {
+		t.Errorf("bundle = %+v, want the schema version and failed run", bundle)
+	}
+	if len(bundle.Warnings) != 1 || !strings.Contains(bundle.Warnings[0], "provider_alerts") {
+		t.Errorf("Warnings = %v, want the provider alerts failure noted", bundle.Warnings)
+	}
+	data, _ := json.Marshal(bundle)
+	if strings.Contains(string(data), "sk-live-key") {
+		t.Error("bundle contains the OpenAI key")
+	}
+
+	noDB := testApp(nil)
+	bundle, err = noDB.ExportDiagnostics()
+	if err != nil || len(bundle.Warnings) != 1 || bundle.FailedAgentRuns == nil {
+		t.Errorf("ExportDiagnostics() = %+v, %v; want an empty bundle with a warning", bundle, err)
+	}
+}
+
+func TestApp_ImportDiagnostics(t *testing.T) {
+	source := testConfig()
+	source.Screener.TopPicksCount = 7
+	source.OpenAI.APIKey = "sk-their-key"
+	exporter := New(source, newDiagnosticsRepo(26), nil, nil)
+	exporter.ctx = context.Background()
+	bundle, err := exporter.ExportDiagnostics()
+	if err != nil {
+		t.Fatalf("ExportDiagnostics() error = %v", err)
+	}
+	bundle.FailedAgentRuns = []models.AgentRun{{ID: uuid.New(), Status: models.AgentRunStatusFailed}}
+	bundle.ProviderAlerts = []models.ProviderAlert{*models.NewProviderAlert("fmp", models.ProviderAlertBreakerOpen, "503", nil)}
+
+	if _, err := testApp(nil).ImportDiagnostics(bundle); !errors.Is(err, ErrDiagnosticImportUnavailable) {
+		t.Errorf("ImportDiagnostics() without a database error = %v", err)
+	}
+
+	repo := newDiagnosticsRepo(27)
+	a := testApp(repo)
+	a.ctx = context.Background()
+	result, err := a.ImportDiagnostics(bundle)
+	if err != nil {
+		t.Fatalf("ImportDiagnostics() error = %v", err)
+	}
+	if result.AgentRuns != 1 || result.ProviderAlerts != 1 || result.Skipped != 0 {
+		t.Errorf("result = %+v, want one run and one alert imported", result)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "schema version 26") {
+		t.Errorf("Warnings = %v, want the schema mismatch noted", result.Warnings)
+	}
+	want := map[string]models.ConfigDiff{
+		"OpenAI.APIKey":          {Key: "OpenAI.APIKey", Bundle: "[redacted]", Local: ""},
+		"Screener.TopPicksCount": {Key: "Screener.TopPicksCount", Bundle: "7", Local: "3"},
+	}
+	if len(result.ConfigDiffs) != len(want) {
+		t.Fatalf("ConfigDiffs = %+v, want %d", result.ConfigDiffs, len(want))
+	}
+	for _, d := range result.ConfigDiffs {
+		if d != want[d.Key] {
+			t.Errorf("diff %+v, want %+v", d, want[d.Key])
+		}
+	}
+
+	result, err = a.ImportDiagnostics(bundle)
+	if err != nil || result.Skipped != 2 || result.AgentRuns != 0 {
+		t.Errorf("second import = %+v, %v; want both rows skipped", result, err)
+	}
+
+	bundle.FormatVersion = 99
+	if _, err := a.ImportDiagnostics(bundle); !errors.Is(err, models.ErrUnsupportedBundle) {
+		t.Errorf("ImportDiagnostics() error = %v, want ErrUnsupportedBundle", err)
+	}
+}
@@ -0,0 +1,263 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+	"trade-machine/repository"
+	"trade-machine/services"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrAutoExecutionFailed is returned by ApproveRecommendation when EXECUTION_MODE is auto
+// and the approval succeeded but the order could not be placed. The recommendation stays
+// approved, so it can be executed once the cause is fixed.
+var ErrAutoExecutionFailed = errors.New("approved, but the order could not be placed")
+
+// autoExecute places the order for a recommendation that was just approved, when
+// EXECUTION_MODE is auto. version is the one the approval left it at, so an edit made in
+// between fails the version check instead of being executed unseen. Recommendations with
+// nothing to send to the broker, such as holds, and installs without a configured broker
+// stay approved for the user to act on.
+func (a *App) autoExecute(rec *models.Recommendation, version int) error {
+	if !a.cfg.Execution.Auto() || rec == nil || !rec.Executable() {
+		return nil
+	}
+	if _, broker := a.broker(); broker == nil {
+		return nil
+	}
+
+	trade, err := a.ExecuteRecommendation(rec.ID.String(), version)
+	if err != nil {
+		observability.Warn("failed to auto-execute approved recommendation",
+			"recommendation_id", rec.ID, "symbol", rec.Symbol, "error", err)
+		return fmt.Errorf("%w: %v", ErrAutoExecutionFailed, err)
+	}
+	observability.Info("auto-executed approved recommendation", "recommendation_id", rec.ID,
+		"symbol", rec.Symbol, "trade_id", trade.ID, "order_id", trade.AlpacaOrderID)
+	return nil
+}
+
+// TradeFillChanged keeps the local position and the recommendation a trade executed in step
+// with the broker's fills. Execution books the whole order on the position when it is
+// placed, so the shares the broker hasn't filled come back off while the order fills in
+// part, go back on as the rest fills, and come off for good once it is cancelled, rejected
+// or expired. A recommendation whose order filled nothing is marked failed; a split's
+// tranches are left to their own status.
+func (a *App) TradeFillChanged(ctx context.Context, previous, trade *models.Trade) error {
+	if a.repo == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	change := trade.PositionQuantity().Sub(previous.PositionQuantity())
+	if change.IsZero() {
+		return nil
+	}
+
+	err := a.repo.UnitOfWork(ctx, func(tx repository.RepositoryInterface) error {
+		rec, err := tx.GetRecommendationByTradeID(ctx, trade.ID)
+		if err != nil {
+			return err
+		}
+		if err := adjustPositionForFill(ctx, tx, trade, rec, change); err != nil {
+			return err
+		}
+		if rec == nil || trade.Status.Open() || trade.PositionQuantity().IsPositive() {
+			return nil
+		}
+		tranches, err := tx.GetRecommendationTranches(ctx, rec.ID)
+		if err != nil || len(tranches) > 0 {
+			return err
+		}
+		return tx.FailRecommendation(ctx, rec.ID, trade.ID)
+	})
+	if err != nil {
+		return err
+	}
+	a.invalidateWarm()
+	observability.Info("adjusted position for order fills", "trade_id", trade.ID, "symbol", trade.Symbol,
+		"status", trade.Status, "filled", trade.FilledQuantity.String(), "change", change.String())
+	return nil
+}
+
+// reversedActions maps each action to the one that undoes it
+var reversedActions = map[models.RecommendationAction]models.RecommendationAction{
+	models.RecommendationActionBuy:   models.RecommendationActionSell,
+	models.RecommendationActionSell:  models.RecommendationActionBuy,
+	models.RecommendationActionShort: models.RecommendationActionCover,
+	models.RecommendationActionCover: models.RecommendationActionShort,
+}
+
+// adjustPositionForFill books change shares of trade on its position at the trade's price:
+// shares that filled after all are added like the trade itself, and shares that won't fill
+// are reversed with a trade on the opposite side. The recommendation's action, when there is
+// one, decides whether a position the trade closed is reopened.
+func adjustPositionForFill(ctx context.Context, tx repository.RepositoryInterface, trade *models.Trade, rec *models.Recommendation, change decimal.Decimal) error {
+	adjustment := *trade
+	adjustment.Quantity = change.Abs()
+	adjustment.TotalValue = adjustment.Quantity.Mul(trade.Price)
+	adjustment.Commission, adjustment.Fees = decimal.Zero, decimal.Zero
+
+	action := models.RecommendationActionBuy
+	if trade.Side == models.TradeSideSell {
+		action = models.RecommendationActionSell
+	}
+	if rec != nil {
+		action = rec.Action
+	}
+	if change.IsNegative() {
+		adjustment.Side = models.TradeSideBuy
+		if trade.Side == models.TradeSideBuy {
+			adjustment.Side = models.TradeSideSell
+		}
+		action = reversedActions[action]
+	}
+	return applyTradeToPosition(ctx, tx, &adjustment, action, nil)
+}
+
+// orderCanceler is implemented by brokers that can cancel an open order
+type orderCanceler interface {
+	CancelOrder(ctx context.Context, orderID string) error
+}
+
+// guardPosition prepares the position in rec's symbol for an order on rec entered at price,
+// returning the bracket to attach to it. An order that reduces a position guarded by
+// bracket legs cancels them first, since the broker holds the shares for them. A bracket
+// guards the lot that opened the position, so an order adding to a position already held
+// goes out without one rather than moving the levels of the whole position.
+func (a *App) guardPosition(ctx context.Context, broker services.BrokerService, rec *models.Recommendation, price decimal.Decimal) (*models.Bracket, error) {
+	pos, err := a.repo.GetPositionBySymbol(ctx, rec.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	if pos == nil {
+		return a.bracketFor(rec, price), nil
+	}
+	if short := pos.Side == models.PositionSideShort; short == (rec.Action.TradeSide() == models.TradeSideSell) {
+		if a.bracketFor(rec, price) != nil {
+			observability.Info("placing order without a bracket: it adds to an existing position",
+				"recommendation_id", rec.ID, "symbol", rec.Symbol)
+		}
+		return nil, nil
+	}
+	return nil, a.cancelBracketLegs(ctx, broker, pos)
+}
+
+// cancelBracketLegs cancels the stop-loss and take-profit legs guarding pos and clears its
+// protective levels. A leg that already closed at the broker is fine to leave; one that
+// filled is booked on the position and reported as an error, since the order about to be
+// placed was sized for shares that are gone.
+func (a *App) cancelBracketLegs(ctx context.Context, broker services.BrokerService, pos *models.Position) error {
+	legs := pos.BracketLegs()
+	if len(legs) == 0 {
+		return nil
+	}
+	canceler, ok := broker.(orderCanceler)
+	if !ok {
+		return fmt.Errorf("%s position is guarded by bracket legs the broker cannot cancel", pos.Symbol)
+	}
+	for _, id := range legs {
+		cancelErr := canceler.CancelOrder(ctx, id)
+		if cancelErr == nil {
+			continue
+		}
+		leg, err := broker.GetOrder(ctx, id)
+		if err != nil || leg.TradeStatus().Open() {
+			return fmt.Errorf("failed to cancel %s bracket leg: %w", pos.Symbol, cancelErr)
+		}
+		if leg.FilledQuantity.IsPositive() {
+			if err := a.BracketLegClosed(ctx, pos, leg); err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: %s bracket leg %s already filled %s shares", models.ErrRecommendationNotExecutable,
+				pos.Symbol, id, leg.FilledQuantity)
+		}
+	}
+
+	err := a.repo.UnitOfWork(ctx, func(tx repository.RepositoryInterface) error {
+		current, err := tx.GetPositionBySymbol(ctx, pos.Symbol)
+		if err != nil || current == nil {
+			return err
+		}
+		current.Unprotect()
+		return tx.UpdatePosition(ctx, current)
+	})
+	if err != nil {
+		return err
+	}
+	observability.Info("cancelled bracket legs before reducing position", "symbol", pos.Symbol, "orders", legs)
+	return nil
+}
+
+// recordBracketLegs looks up the legs the broker created for a bracket order, so the
+// position they guard can cancel them and sync their fills. Legs that can't be looked up are
+// logged and left untracked; the broker still cancels them with the entry order.
+func recordBracketLegs(ctx context.Context, broker services.BrokerService, orderID string, bracket *models.Bracket) {
+	if bracket == nil {
+		return
+	}
+	order, err := broker.GetOrder(ctx, orderID)
+	if err != nil {
+		observability.Warn("bracket legs unavailable", "order_id", orderID, "error", err)
+		return
+	}
+	bracket.StopLossOrderID, bracket.TakeProfitOrderID = order.BracketLegs()
+}
+
+// BracketLegClosed books a stop-loss or take-profit leg that closed at the broker on the
+// position it guarded. A leg that filled is recorded as an executed trade that reduces or
+// closes the position, and both levels are cleared since the broker cancels the other leg
+// with it. A leg cancelled unfilled just stops being tracked.
+func (a *App) BracketLegClosed(ctx context.Context, pos *models.Position, leg *models.BrokerOrder) error {
+	if a.repo == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	var trade *models.Trade
+	err := a.repo.UnitOfWork(ctx, func(tx repository.RepositoryInterface) error {
+		current, err := tx.GetPositionBySymbol(ctx, pos.Symbol)
+		if err != nil || current == nil || current.ID != pos.ID {
+			return err
+		}
+		if !leg.FilledQuantity.IsPositive() {
+			switch leg.ID {
+			case current.StopLossOrderID:
+				current.StopLossPrice, current.StopLossOrderID = nil, ""
+			case current.TakeProfitOrderID:
+				current.TakeProfitPrice, current.TakeProfitOrderID = nil, ""
+			default:
+				return nil
+			}
+			return tx.UpdatePosition(ctx, current)
+		}
+
+		trade = models.NewTrade(current.Symbol, leg.Side, leg.FilledQuantity, current.CurrentPrice)
+		trade.AlpacaOrderID = leg.ID
+		leg.ApplyTo(trade)
+		a.feeSchedule.Apply(trade)
+		if err := tx.CreateTrade(ctx, trade); err != nil {
+			return err
+		}
+		current.Unprotect()
+		if err := tx.UpdatePosition(ctx, current); err != nil {
+			return err
+		}
+		action := models.RecommendationActionSell
+		if current.Side == models.PositionSideShort {
+			action = models.RecommendationActionCover
+		}
+		return applyTradeToPosition(ctx, tx, trade, action, nil)
+	})
+	if err != nil {
+		return err
+	}
+	a.invalidateWarm()
+	if trade != nil {
+		observability.Info("bracket leg filled", "symbol", pos.Symbol, "order_id", leg.ID,
+			"quantity", trade.FilledQuantity.String(), "price", trade.Price.String())
+	}
+	return nil
+}
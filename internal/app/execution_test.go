@@ -0,0 +1,294 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"trade-machine/models"
+	"trade-machine/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestApp_ApproveRecommendation_AutoExecute(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+	rec.Version = 3
+	alpaca := &orderAlpaca{last: decimal.NewFromInt(100)}
+	a, repo := splitTestApp(rec, alpaca)
+	a.cfg.Execution.Mode = "auto"
+
+	if err := a.ApproveRecommendation(rec.ID.String(), models.AnyVersion); err != nil {
+		t.Fatalf("ApproveRecommendation() error = %v", err)
+	}
+	if rec.Status != models.RecommendationStatusExecuted || len(alpaca.orders) != 1 || len(repo.trades) != 1 {
+		t.Fatalf("status %s with %d orders and %d trades, want executed with one of each", rec.Status, len(alpaca.orders), len(repo.trades))
+	}
+	if repo.claimed != 4 {
+		t.Errorf("claimed at version %d, want 4, the version the approval left", repo.claimed)
+	}
+	if alpaca.orders[0].Side != models.TradeSideBuy || !alpaca.orders[0].Quantity.Equal(rec.Quantity) {
+		t.Errorf("order = %+v, want a buy of 10", alpaca.orders[0])
+	}
+	if repo.position == nil || !repo.position.Quantity.Equal(rec.Quantity) {
+		t.Errorf("position = %+v, want 10 shares opened", repo.position)
+	}
+}
+
+func TestApp_ApproveRecommendation_ManualMode(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+	alpaca := &orderAlpaca{last: decimal.NewFromInt(100)}
+	a, _ := splitTestApp(rec, alpaca)
+
+	if err := a.ApproveRecommendation(rec.ID.String(), models.AnyVersion); err != nil {
+		t.Fatalf("ApproveRecommendation() error = %v", err)
+	}
+	if rec.Status != models.RecommendationStatusApproved || len(alpaca.orders) != 0 {
+		t.Errorf("status %s with %d orders, want approved without an order", rec.Status, len(alpaca.orders))
+	}
+}
+
+func TestApp_ApproveRecommendation_AutoExecuteFailure(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+	alpaca := &orderAlpaca{last: decimal.NewFromInt(100), reject: errors.New("insufficient buying power")}
+	a, repo := splitTestApp(rec, alpaca)
+	a.cfg.Execution.Mode = "auto"
+
+	err := a.ApproveRecommendation(rec.ID.String(), models.AnyVersion)
+	if !errors.Is(err, ErrAutoExecutionFailed) {
+		t.Fatalf("ApproveRecommendation() error = %v, want ErrAutoExecutionFailed", err)
+	}
+	if rec.Status != models.RecommendationStatusApproved || len(repo.trades) != 0 {
+		t.Errorf("status %s with %d trades, want the approval kept without a trade", rec.Status, len(repo.trades))
+	}
+}
+
+func TestApp_ApproveRecommendation_NotPending(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+	alpaca := &orderAlpaca{last: decimal.NewFromInt(100)}
+	a, _ := splitTestApp(rec, alpaca)
+	a.cfg.Execution.Mode = "auto"
+
+	for _, status := range []models.RecommendationStatus{models.RecommendationStatusExecuting, models.RecommendationStatusExecuted, models.RecommendationStatusRejected} {
+		rec.Status = status
+		if err := a.ApproveRecommendation(rec.ID.String(), models.AnyVersion); !errors.Is(err, models.ErrRecommendationNotExecutable) {
+			t.Errorf("ApproveRecommendation() on %s error = %v, want ErrRecommendationNotExecutable", status, err)
+		}
+		if rec.Status != status {
+			t.Errorf("status = %s, want it left %s", rec.Status, status)
+		}
+	}
+	if len(alpaca.orders) != 0 {
+		t.Errorf("%d orders placed, want none for a recommendation that isn't pending", len(alpaca.orders))
+	}
+}
+
+func TestApp_ExecuteRecommendation_PricedFromLatestTrade(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+	a, _ := splitTestApp(rec, &orderAlpaca{last: decimal.NewFromInt(100), bidAskOnly: true})
+
+	trade, err := a.ExecuteRecommendation(rec.ID.String(), models.AnyVersion)
+	if err != nil {
+		t.Fatalf("ExecuteRecommendation() error = %v", err)
+	}
+	if !trade.Price.Equal(decimal.NewFromInt(100)) || !trade.TotalValue.Equal(decimal.NewFromInt(1000)) {
+		t.Errorf("trade priced at %s for %s, want the latest trade's $100", trade.Price, trade.TotalValue)
+	}
+}
+
+func TestApp_ExecuteRecommendation_Claimed(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+	alpaca := &orderAlpaca{last: decimal.NewFromInt(100)}
+	a, repo := splitTestApp(rec, alpaca)
+	rec.Approve()
+
+	// Another execution claims it after this one loaded the recommendation
+	a.repo = &claimingRepo{trancheRepo: repo}
+	if _, err := a.ExecuteRecommendation(rec.ID.String(), models.AnyVersion); !errors.Is(err, models.ErrRecommendationNotExecutable) {
+		t.Fatalf("ExecuteRecommendation() error = %v, want ErrRecommendationNotExecutable", err)
+	}
+	if len(alpaca.orders) != 0 {
+		t.Errorf("%d orders placed, want none once the recommendation was claimed", len(alpaca.orders))
+	}
+}
+
+// claimingRepo lets a concurrent execution claim the recommendation just before this one does
+type claimingRepo struct {
+	*trancheRepo
+}
+
+func (r *claimingRepo) ClaimRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error {
+	r.rec.Status = models.RecommendationStatusExecuting
+	return r.trancheRepo.ClaimRecommendation(ctx, id, expectedVersion)
+}
+
+func (r *claimingRepo) UnitOfWork(ctx context.Context, fn func(tx repository.RepositoryInterface) error) error {
+	return fn(r)
+}
+
+func TestApp_TradeFillChanged(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+	a, repo := splitTestApp(rec, &orderAlpaca{last: decimal.NewFromInt(100)})
+	trade, err := a.ExecuteRecommendation(rec.ID.String(), models.AnyVersion)
+	if err != nil {
+		t.Fatalf("ExecuteRecommendation() error = %v", err)
+	}
+	fill := func(status models.TradeStatus, filled int64) {
+		t.Helper()
+		previous := *trade
+		trade.Status, trade.FilledQuantity = status, decimal.NewFromInt(filled)
+		if err := a.TradeFillChanged(context.Background(), &previous, trade); err != nil {
+			t.Fatalf("TradeFillChanged() error = %v", err)
+		}
+	}
+
+	// Booked in full when placed, then down to the 4 shares filled, then back up as the rest fill
+	fill(models.TradeStatusPartiallyFilled, 4)
+	if repo.position == nil || !repo.position.Quantity.Equal(decimal.NewFromInt(4)) {
+		t.Fatalf("position = %+v after a partial fill, want the 4 filled shares", repo.position)
+	}
+	fill(models.TradeStatusExecuted, 10)
+	if !repo.position.Quantity.Equal(decimal.NewFromInt(10)) || rec.Status != models.RecommendationStatusExecuted {
+		t.Fatalf("position = %+v with %s recommendation, want all 10 shares executed", repo.position, rec.Status)
+	}
+
+	// Had the order been cancelled before anything filled, it comes back off and the
+	// recommendation fails
+	trade.Status, trade.FilledQuantity = models.TradeStatusPending, decimal.Zero
+	fill(models.TradeStatusCancelled, 0)
+	if repo.position != nil || rec.Status != models.RecommendationStatusFailed {
+		t.Errorf("position = %+v with %s recommendation, want it removed and the recommendation failed", repo.position, rec.Status)
+	}
+}
+
+// bracketAlpaca reports stop-loss and take-profit legs on every entry order and records the
+// orders cancelled
+type bracketAlpaca struct {
+	*orderAlpaca
+	legs      map[string]*models.BrokerOrder // Leg status by order ID
+	cancelled []string
+}
+
+func (m *bracketAlpaca) GetOrder(ctx context.Context, orderID string) (*models.BrokerOrder, error) {
+	if leg, ok := m.legs[orderID]; ok {
+		return leg, nil
+	}
+	return &models.BrokerOrder{ID: orderID, Legs: []models.BrokerOrder{
+		{ID: "sl-" + orderID, Type: models.OrderTypeStop},
+		{ID: "tp-" + orderID, Type: models.OrderTypeLimit},
+	}}, nil
+}
+
+func (m *bracketAlpaca) CancelOrder(ctx context.Context, orderID string) error {
+	if leg, ok := m.legs[orderID]; ok && !leg.TradeStatus().Open() {
+		return errors.New("order is not cancelable")
+	}
+	m.cancelled = append(m.cancelled, orderID)
+	return nil
+}
+
+func TestApp_ExecuteRecommendation_BracketLegs(t *testing.T) {
+	buy := func(qty int64) *models.Recommendation {
+		rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+		rec.Quantity = decimal.NewFromInt(qty)
+		rec.StopPrice, rec.TargetPrice = decimal.NewFromInt(90), decimal.NewFromInt(120)
+		return rec
+	}
+	alpaca := &bracketAlpaca{orderAlpaca: &orderAlpaca{last: decimal.NewFromInt(100)}}
+	a, repo := splitTestApp(buy(10), alpaca.orderAlpaca)
+	a.cfg.Execution.BracketOrders = true
+	a.alpacaService = alpaca
+	a.brokers[models.BrokerAlpaca] = alpaca
+
+	opened, err := a.ExecuteRecommendation(repo.rec.ID.String(), models.AnyVersion)
+	if err != nil {
+		t.Fatalf("ExecuteRecommendation() error = %v", err)
+	}
+	pos := repo.position
+	if pos == nil || pos.StopLossOrderID != "sl-"+opened.AlpacaOrderID || pos.TakeProfitOrderID != "tp-"+opened.AlpacaOrderID || !pos.StopLossPrice.Equal(decimal.NewFromInt(90)) {
+		t.Fatalf("position = %+v, want the legs of the opening order", pos)
+	}
+
+	// An add goes out without a bracket and leaves the opening lot's levels alone
+	repo.rec = buy(5)
+	repo.rec.StopPrice = decimal.NewFromInt(95)
+	if _, err := a.ExecuteRecommendation(repo.rec.ID.String(), models.AnyVersion); err != nil {
+		t.Fatalf("ExecuteRecommendation(add) error = %v", err)
+	}
+	if alpaca.orders[1].Bracket != nil || !repo.position.StopLossPrice.Equal(decimal.NewFromInt(90)) || repo.position.StopLossOrderID != pos.StopLossOrderID {
+		t.Errorf("add order %+v left position %+v, want no bracket and the opening levels", alpaca.orders[1], repo.position)
+	}
+
+	// A sell cancels both legs before it is placed
+	repo.rec = models.NewRecommendation("AAPL", models.RecommendationActionSell, "test")
+	repo.rec.Quantity = decimal.NewFromInt(15)
+	if _, err := a.ExecuteRecommendation(repo.rec.ID.String(), models.AnyVersion); err != nil {
+		t.Fatalf("ExecuteRecommendation(sell) error = %v", err)
+	}
+	if len(alpaca.cancelled) != 2 || len(alpaca.orders) != 3 || repo.position != nil {
+		t.Errorf("cancelled %v with %d orders and position %+v, want both legs cancelled and the position closed", alpaca.cancelled, len(alpaca.orders), repo.position)
+	}
+}
+
+func TestApp_ExecuteRecommendation_BracketLegFilled(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionSell, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+	price := decimal.NewFromInt(120)
+	alpaca := &bracketAlpaca{orderAlpaca: &orderAlpaca{last: decimal.NewFromInt(100)}, legs: map[string]*models.BrokerOrder{
+		"sl": {ID: "sl", Status: models.BrokerOrderCanceled},
+		"tp": {ID: "tp", Side: models.TradeSideSell, Status: models.BrokerOrderFilled, FilledQuantity: decimal.NewFromInt(10), FilledAvgPrice: &price},
+	}}
+	a, repo := splitTestApp(rec, alpaca.orderAlpaca)
+	a.brokers[models.BrokerAlpaca] = alpaca
+	stop, target := decimal.NewFromInt(90), decimal.NewFromInt(120)
+	repo.position = &models.Position{ID: uuid.New(), Symbol: "AAPL", Side: models.PositionSideLong, Quantity: decimal.NewFromInt(10),
+		AvgEntryPrice: decimal.NewFromInt(100), StopLossPrice: &stop, TakeProfitPrice: &target, StopLossOrderID: "sl", TakeProfitOrderID: "tp"}
+
+	// The target was hit before the sell: it is booked and the sell refused
+	if _, err := a.ExecuteRecommendation(rec.ID.String(), models.AnyVersion); !errors.Is(err, models.ErrRecommendationNotExecutable) {
+		t.Fatalf("ExecuteRecommendation() error = %v, want ErrRecommendationNotExecutable", err)
+	}
+	if len(alpaca.orders) != 0 || repo.position != nil || len(repo.trades) != 1 || rec.Status != models.RecommendationStatusApproved {
+		t.Fatalf("%d orders, position %+v, %d trades and %s recommendation; want the leg fill booked and the sell released", len(alpaca.orders), repo.position, len(repo.trades), rec.Status)
+	}
+	for _, trade := range repo.trades {
+		if trade.AlpacaOrderID != "tp" || trade.Status != models.TradeStatusExecuted || !trade.Price.Equal(price) {
+			t.Errorf("leg trade = %+v, want the take-profit fill at 120", trade)
+		}
+	}
+}
+
+func TestApp_BracketLegClosed(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	a, repo := splitTestApp(rec, &orderAlpaca{})
+	stop, target := decimal.NewFromInt(90), decimal.NewFromInt(120)
+	pos := &models.Position{ID: uuid.New(), Symbol: "AAPL", Side: models.PositionSideShort, Quantity: decimal.NewFromInt(10),
+		AvgEntryPrice: decimal.NewFromInt(100), StopLossPrice: &target, TakeProfitPrice: &stop, StopLossOrderID: "sl", TakeProfitOrderID: "tp"}
+	repo.position = pos
+
+	// A stop cancelled by hand stops being tracked; the target keeps guarding the position
+	if err := a.BracketLegClosed(context.Background(), pos, &models.BrokerOrder{ID: "sl", Status: models.BrokerOrderCanceled}); err != nil {
+		t.Fatalf("BracketLegClosed() error = %v", err)
+	}
+	if repo.position.StopLossPrice != nil || repo.position.StopLossOrderID != "" || repo.position.TakeProfitOrderID != "tp" {
+		t.Errorf("position = %+v, want only the stop cleared", repo.position)
+	}
+
+	// The target covering 4 shares reduces the short
+	price := decimal.NewFromInt(90)
+	leg := &models.BrokerOrder{ID: "tp", Side: models.TradeSideBuy, Status: models.BrokerOrderFilled, FilledQuantity: decimal.NewFromInt(4), FilledAvgPrice: &price}
+	if err := a.BracketLegClosed(context.Background(), pos, leg); err != nil {
+		t.Fatalf("BracketLegClosed() error = %v", err)
+	}
+	if !repo.position.Quantity.Equal(decimal.NewFromInt(6)) || len(repo.position.BracketLegs()) != 0 || repo.position.TakeProfitPrice != nil || len(repo.trades) != 1 {
+		t.Errorf("position = %+v with %d trades, want 6 shares short, no legs and the cover recorded", repo.position, len(repo.trades))
+	}
+}
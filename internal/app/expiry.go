@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/google/uuid"
+)
+
+// expiryScanLimit caps the open recommendations of each status checked for price deviation
+const expiryScanLimit = 200
+
+// recommendationExpirer moves open recommendations that have gone stale to expired: those
+// older than RECOMMENDATION_TTL_HOURS, and those whose price has moved more than
+// RECOMMENDATION_MAX_DEVIATION_PERCENT from the price they would be entered at. Prices
+// come from the shared quote cache and are only checked when Alpaca is configured.
+type recommendationExpirer struct {
+	app *App
+	cfg config.RecommendationExpiryConfig
+	now func() time.Time
+}
+
+func newRecommendationExpirer(a *App, cfg config.RecommendationExpiryConfig) *recommendationExpirer {
+	return &recommendationExpirer{app: a, cfg: cfg, now: time.Now}
+}
+
+// Run expires stale recommendations on startup and then every interval until ctx is cancelled
+func (e *recommendationExpirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(e.cfg.IntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		e.expire(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// expire expires the recommendations past their TTL or price deviation, returning how many
+func (e *recommendationExpirer) expire(ctx context.Context) int {
+	a := e.app
+	var createdBefore time.Time
+	if e.cfg.TTLHours > 0 {
+		createdBefore = e.now().Add(-time.Duration(e.cfg.TTLHours) * time.Hour)
+	}
+	deviated := e.deviated(ctx)
+	if createdBefore.IsZero() && len(deviated) == 0 {
+		return 0
+	}
+
+	expired, err := a.repo.ExpireStaleRecommendations(ctx, createdBefore, deviated)
+	if err != nil {
+		observability.Warn("recommendation expiry failed", "error", err)
+		return 0
+	}
+	if len(expired) > 0 {
+		a.invalidateWarm()
+		observability.Info("expired stale recommendations", "count", len(expired), "price_moved", len(deviated))
+	}
+	return len(expired)
+}
+
+// deviated returns the open recommendations whose price has moved past the deviation limit
+func (e *recommendationExpirer) deviated(ctx context.Context) []uuid.UUID {
+	a := e.app
+	if e.cfg.MaxDeviationPercent <= 0 || a.alpacaService == nil {
+		return nil
+	}
+
+	var ids []uuid.UUID
+	prices := make(map[string]*models.Quote)
+	for _, status := range []models.RecommendationStatus{models.RecommendationStatusPending, models.RecommendationStatusApproved} {
+		recs, err := a.repo.GetRecommendations(ctx, status, expiryScanLimit)
+		if err != nil {
+			observability.Warn("recommendation expiry: open recommendations unavailable", "status", status, "error", err)
+			continue
+		}
+		for i := range recs {
+			rec := &recs[i]
+			quote, ok := prices[rec.Symbol]
+			if !ok {
+				if quote, err = a.fetchQuote(rec.Symbol); err != nil {
+					observability.Debug("recommendation expiry: quote failed", "symbol", rec.Symbol, "error", err)
+				}
+				prices[rec.Symbol] = quote
+			}
+			if quote != nil && rec.PriceDeviationPercent(quote.Last) > e.cfg.MaxDeviationPercent {
+				ids = append(ids, rec.ID)
+			}
+		}
+	}
+	return ids
+}
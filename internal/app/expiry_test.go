@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+	"trade-machine/services"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// expiryRepo stubs the open recommendation reads and the expiry of RepositoryInterface
+type expiryRepo struct {
+	RepositoryInterface
+	open          map[models.RecommendationStatus][]models.Recommendation
+	createdBefore time.Time
+	ids           []uuid.UUID
+}
+
+func (m *expiryRepo) GetRecommendations(ctx context.Context, status models.RecommendationStatus, limit int) ([]models.Recommendation, error) {
+	return m.open[status], nil
+}
+
+func (m *expiryRepo) ExpireStaleRecommendations(ctx context.Context, createdBefore time.Time, ids []uuid.UUID) ([]uuid.UUID, error) {
+	m.createdBefore, m.ids = createdBefore, ids
+	return ids, nil
+}
+
+// lastPriceAlpacaService returns the last price of each symbol it knows
+type lastPriceAlpacaService struct {
+	services.AlpacaServiceInterface
+	last map[string]float64
+}
+
+func (m *lastPriceAlpacaService) GetQuote(ctx context.Context, symbol string) (*models.Quote, error) {
+	last, ok := m.last[symbol]
+	if !ok {
+		return nil, errors.New("no quote")
+	}
+	return &models.Quote{Symbol: symbol, Last: decimal.NewFromFloat(last)}, nil
+}
+
+func (m *lastPriceAlpacaService) GetLatestTrade(ctx context.Context, symbol string) (*models.Quote, error) {
+	return nil, errors.New("no trade")
+}
+
+func TestRecommendationExpirer_Expire(t *testing.T) {
+	rec := func(symbol string, entry float64) models.Recommendation {
+		r := models.NewRecommendation(symbol, models.RecommendationActionBuy, "test")
+		r.EntryPrice = decimal.NewFromFloat(entry)
+		return *r
+	}
+	moved, steady, unpriced := rec("AAPL", 100), rec("MSFT", 400), rec("XYZ", 10)
+	approved := rec("AAPL", 120)
+	repo := &expiryRepo{open: map[models.RecommendationStatus][]models.Recommendation{
+		models.RecommendationStatusPending:  {moved, steady, unpriced},
+		models.RecommendationStatusApproved: {approved},
+	}}
+	alpaca := &lastPriceAlpacaService{last: map[string]float64{"AAPL": 115, "MSFT": 410}}
+	a := New(testConfig(), repo, nil, alpaca)
+	a.ctx = context.Background()
+
+	now := time.Date(2024, 6, 12, 15, 0, 0, 0, time.UTC)
+	e := newRecommendationExpirer(a, config.RecommendationExpiryConfig{TTLHours: 72, MaxDeviationPercent: 10})
+	e.now = func() time.Time { return now }
+
+	if count := e.expire(context.Background()); count != 1 {
+		t.Errorf("expired %d, want 1", count)
+	}
+	if !repo.createdBefore.Equal(now.Add(-72 * time.Hour)) {
+		t.Errorf("createdBefore = %v, want 72 hours before now", repo.createdBefore)
+	}
+	// AAPL moved 15% from one entry and 4% from the other; MSFT 2.5%; XYZ has no price
+	if len(repo.ids) != 1 || repo.ids[0] != moved.ID {
+		t.Errorf("ids = %v, want only the recommendation the price moved away from", repo.ids)
+	}
+}
+
+func TestRecommendationExpirer_Disabled(t *testing.T) {
+	repo := &expiryRepo{}
+	a := New(testConfig(), repo, nil, nil)
+	e := newRecommendationExpirer(a, config.RecommendationExpiryConfig{})
+
+	if count := e.expire(context.Background()); count != 0 || repo.ids != nil {
+		t.Errorf("expired %d, want nothing with no TTL or deviation limit", count)
+	}
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+	"trade-machine/services"
+)
+
+// GetLLMUsage reports the LLM tokens agents used and their estimated cost per agent and
+// per model since the start of period (day, week or month). When LLM_MONTHLY_BUDGET is
+// set the report includes the month's spend against it, whatever the period.
+func (a *App) GetLLMUsage(period string) (*models.LLMUsageReport, error) {
+	now := time.Now()
+	since, err := models.LLMUsagePeriodStart(period, now)
+	if err != nil {
+		return nil, err
+	}
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	rows, err := a.repo.GetLLMUsage(a.ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	report := models.NewLLMUsageReport(period, since, rows)
+
+	if limit := a.cfg.LLM.MonthlyBudget; limit > 0 {
+		spent := report.CostUSD
+		if period != "month" {
+			monthStart, _ := models.LLMUsagePeriodStart("month", now)
+			if spent, err = a.repo.GetLLMCostSince(a.ctx, monthStart); err != nil {
+				return nil, err
+			}
+		}
+		report.Budget = models.NewLLMBudget(limit, spent, a.cfg.LLM.BudgetMode)
+	}
+	return report, nil
+}
+
+// checkLLMBudget returns ErrLLMBudgetExceeded once the month's estimated LLM spend reaches
+// LLM_MONTHLY_BUDGET and LLM_BUDGET_MODE is block, as analyses do; in warn mode it only logs.
+// A failed lookup doesn't stop the request.
+func (a *App) checkLLMBudget(ctx context.Context) error {
+	budget := a.cfg.LLM.MonthlyBudget
+	if budget <= 0 || a.repo == nil {
+		return nil
+	}
+	since, _ := models.LLMUsagePeriodStart("month", time.Now())
+	spent, err := a.repo.GetLLMCostSince(ctx, since)
+	if err != nil {
+		observability.Warn("failed to check LLM budget", "error", err)
+		return nil
+	}
+	if spent < budget {
+		return nil
+	}
+	if a.cfg.LLM.BudgetMode == "block" {
+		return fmt.Errorf("%w: $%.2f of $%.2f spent this month", models.ErrLLMBudgetExceeded, spent, budget)
+	}
+	observability.Warn("monthly LLM budget exceeded", "spent_usd", spent, "budget_usd", budget)
+	return nil
+}
+
+// recordLLMUsage saves the LLM calls tallied for agentType, so they count toward the
+// usage report and the monthly budget
+func (a *App) recordLLMUsage(ctx context.Context, agentType models.AgentType, symbol string, tally *services.LLMUsageTally) {
+	calls := tally.Calls()
+	if len(calls) == 0 || a.repo == nil {
+		return
+	}
+	usage := make([]models.LLMUsage, len(calls))
+	for i, call := range calls {
+		usage[i] = models.NewLLMUsage(agentType, symbol, call.Provider, call.Model, call.InputTokens, call.OutputTokens)
+	}
+	if err := a.repo.SaveLLMUsage(ctx, usage); err != nil {
+		observability.Warn("failed to save LLM usage", "agent", agentType, "symbol", symbol, "error", err)
+	}
+}
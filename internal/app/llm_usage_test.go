@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+)
+
+// llmUsageRepo serves fixed LLM usage and monthly spend
+type llmUsageRepo struct {
+	RepositoryInterface
+	rows  []models.LLMUsageRow
+	spent float64
+	since time.Time
+}
+
+func (r *llmUsageRepo) GetLLMUsage(ctx context.Context, since time.Time) ([]models.LLMUsageRow, error) {
+	r.since = since
+	return r.rows, nil
+}
+
+func (r *llmUsageRepo) GetLLMCostSince(ctx context.Context, since time.Time) (float64, error) {
+	return r.spent, nil
+}
+
+func TestApp_GetLLMUsage(t *testing.T) {
+	repo := &llmUsageRepo{
+		rows: []models.LLMUsageRow{
+			{AgentType: models.AgentTypeNews, Provider: "openai", Model: "gpt-4o", LLMUsageTotals: models.LLMUsageTotals{Calls: 3, CostUSD: 1.5}},
+		},
+		spent: 40,
+	}
+
+	t.Run("without a budget", func(t *testing.T) {
+		a := New(testConfig(), repo, nil, nil)
+		report, err := a.GetLLMUsage("month")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Calls != 3 || len(report.ByAgent) != 1 || report.Budget != nil {
+			t.Errorf("report = %+v, want the usage without a budget", report)
+		}
+		if repo.since.Day() != 1 {
+			t.Errorf("since = %v, want the first of the month", repo.since)
+		}
+	})
+
+	t.Run("budget uses the month's spend", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.LLM.MonthlyBudget = 50
+		cfg.LLM.BudgetMode = "block"
+		a := New(cfg, repo, nil, nil)
+
+		report, err := a.GetLLMUsage("month")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Budget == nil || report.Budget.SpentUSD != 1.5 || report.Budget.Mode != "block" {
+			t.Errorf("budget = %+v, want the month's $1.50 against $50", report.Budget)
+		}
+
+		report, err = a.GetLLMUsage("day")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Budget == nil || report.Budget.SpentUSD != 40 || report.Budget.RemainingUSD != 10 {
+			t.Errorf("budget = %+v, want the month's $40 spend rather than the day's", report.Budget)
+		}
+	})
+
+	t.Run("invalid period", func(t *testing.T) {
+		a := New(testConfig(), repo, nil, nil)
+		if _, err := a.GetLLMUsage("year"); !errors.Is(err, models.ErrInvalidUsagePeriod) {
+			t.Errorf("expected ErrInvalidUsagePeriod, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/services"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+// quoteAlpacaService stubs the market data methods of AlpacaServiceInterface
+type quoteAlpacaService struct {
+	services.AlpacaServiceInterface
+	quote *models.Quote
+	trade *models.Quote
+}
+
+func (m *quoteAlpacaService) GetQuote(ctx context.Context, symbol string) (*models.Quote, error) {
+	q := *m.quote
+	return &q, nil
+}
+
+func (m *quoteAlpacaService) GetLatestTrade(ctx context.Context, symbol string) (*models.Quote, error) {
+	t := *m.trade
+	return &t, nil
+}
+
+func TestApp_GetQuote_NotConfigured(t *testing.T) {
+	a := testApp(nil)
+	if _, err := a.GetQuote("AAPL"); err == nil {
+		t.Error("expected error when Alpaca is not configured")
+	}
+}
+
+func TestApp_GetQuote_MergesLatestTrade(t *testing.T) {
+	quoteTime := time.Date(2024, 1, 16, 20, 59, 0, 0, time.UTC) // 15:59 ET, regular
+	tradeTime := time.Date(2024, 1, 16, 21, 30, 0, 0, time.UTC) // 16:30 ET, after-hours
+
+	alpaca := &quoteAlpacaService{
+		quote: &models.Quote{
+			Symbol:    "AAPL",
+			Bid:       decimal.NewFromInt(99),
+			Ask:       decimal.NewFromInt(101),
+			Timestamp: quoteTime,
+			Session:   models.MarketSessionRegular,
+		},
+		trade: &models.Quote{
+			Symbol:    "AAPL",
+			Last:      decimal.NewFromInt(102),
+			Volume:    500,
+			Timestamp: tradeTime,
+			Session:   models.MarketSessionAfter,
+		},
+	}
+
+	a := New(testConfig(), nil, nil, alpaca)
+	a.Startup(context.Background())
+
+	quote, err := a.GetQuote("AAPL")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !quote.Last.Equal(decimal.NewFromInt(102)) {
+		t.Errorf("Last = %s, want 102", quote.Last)
+	}
+	if quote.Session != models.MarketSessionAfter {
+		t.Errorf("Session = %s, want after", quote.Session)
+	}
+	if !quote.Timestamp.Equal(tradeTime) {
+		t.Errorf("Timestamp = %v, want %v", quote.Timestamp, tradeTime)
+	}
+}
+
+func TestApp_MarketSession(t *testing.T) {
+	a := testApp(nil)
+	switch a.MarketSession() {
+	case models.MarketSessionPre, models.MarketSessionRegular, models.MarketSessionAfter, models.MarketSessionClosed:
+	default:
+		t.Errorf("unexpected session %q", a.MarketSession())
+	}
+}
+
+// calendarAlpacaService reports a fixed market status from the broker's calendar
+type calendarAlpacaService struct {
+	quoteAlpacaService
+	status models.MarketStatus
+}
+
+func (m *calendarAlpacaService) MarketStatus(ctx context.Context) models.MarketStatus {
+	return m.status
+}
+
+func TestApp_MarketStatus_UsesBrokerCalendar(t *testing.T) {
+	holiday := models.MarketStatus{Session: models.MarketSessionClosed, Reason: "Closed for a market holiday"}
+	a := New(testConfig(), nil, nil, &calendarAlpacaService{status: holiday})
+	a.Startup(context.Background())
+
+	if status := a.MarketStatus(); status.Reason != holiday.Reason {
+		t.Errorf("MarketStatus() = %+v, want the broker's holiday", status)
+	}
+	if session := a.MarketSession(); session != models.MarketSessionClosed {
+		t.Errorf("MarketSession() = %q, want closed on a holiday", session)
+	}
+}
+
+// barsAlpacaService adds daily bars to quoteAlpacaService and counts bar requests
+type barsAlpacaService struct {
+	quoteAlpacaService
+	bars      []marketdata.Bar
+	barCalls  int
+	timeframe marketdata.TimeFrame
+}
+
+func (m *barsAlpacaService) GetDailyBars(ctx context.Context, symbol string, days int) ([]marketdata.Bar, error) {
+	m.barCalls++
+	return m.bars, nil
+}
+
+func (m *barsAlpacaService) GetBars(ctx context.Context, symbol string, start, end time.Time, timeframe marketdata.TimeFrame) ([]marketdata.Bar, error) {
+	m.barCalls++
+	m.timeframe = timeframe
+	return m.bars, nil
+}
+
+func TestApp_QuickLook(t *testing.T) {
+	now := time.Now()
+	alpaca := &barsAlpacaService{
+		quoteAlpacaService: quoteAlpacaService{
+			quote: &models.Quote{Symbol: "AAPL", Bid: decimal.NewFromInt(109), Ask: decimal.NewFromInt(111), Timestamp: now},
+			trade: &models.Quote{Symbol: "AAPL", Last: decimal.NewFromInt(110), Timestamp: now},
+		},
+		bars: []marketdata.Bar{
+			{Timestamp: now.AddDate(0, 0, -3), Close: 90},
+			{Timestamp: now.AddDate(0, 0, -2), Close: 100},
+			{Timestamp: now, Close: 108}, // today's bar in progress
+		},
+	}
+
+	a := New(testConfig(), nil, nil, alpaca)
+	a.Startup(context.Background())
+
+	q := a.QuickLook("aapl")
+	if q.Symbol != "AAPL" {
+		t.Errorf("Symbol = %s, want AAPL", q.Symbol)
+	}
+	if !q.Price.Equal(decimal.NewFromInt(110)) {
+		t.Errorf("Price = %s, want 110", q.Price)
+	}
+	if !q.PreviousClose.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("PreviousClose = %s, want 100", q.PreviousClose)
+	}
+	if !q.DayChange.Equal(decimal.NewFromInt(10)) || q.DayChangePercent != 10 {
+		t.Errorf("day change = %s (%.2f%%), want 10 (10%%)", q.DayChange, q.DayChangePercent)
+	}
+	if q.LatestRecommendation != nil || q.NextEarnings != nil {
+		t.Error("expected no recommendation or earnings without a database or FMP")
+	}
+
+	if cached := a.QuickLook("AAPL"); cached != q {
+		t.Error("expected the second quick look to be served from cache")
+	}
+	if alpaca.barCalls != 1 {
+		t.Errorf("GetDailyBars called %d times, want 1", alpaca.barCalls)
+	}
+}
+
+func TestApp_QuickLook_NoMarketData(t *testing.T) {
+	q := testApp(nil).QuickLook("AAPL")
+	if q.HasPrice() || q.HasDayChange() {
+		t.Errorf("expected an empty summary without market data, got %+v", q)
+	}
+}
+
+func TestApp_GetPriceBars(t *testing.T) {
+	now := time.Now()
+	alpaca := &barsAlpacaService{bars: []marketdata.Bar{
+		{Timestamp: now.Add(-10 * time.Minute), Open: 100, High: 102, Low: 99, Close: 101, Volume: 500},
+		{Timestamp: now.Add(-5 * time.Minute), Open: 101, High: 103, Low: 100, Close: 102, Volume: 700},
+		{Timestamp: now, Open: 102, High: 104, Low: 101, Close: 103, Volume: 900},
+	}}
+	a := New(testConfig(), nil, nil, alpaca)
+	a.Startup(context.Background())
+
+	history, err := a.GetPriceBars("aapl", models.BarTimeframe5Min, 2)
+	if err != nil {
+		t.Fatalf("GetPriceBars error = %v", err)
+	}
+	if history.Symbol != "AAPL" || len(history.Bars) != 2 || history.Bars[1].Close != 103 || history.Bars[0].Volume != 700 {
+		t.Errorf("history = %+v, want the two most recent bars", history)
+	}
+	if alpaca.timeframe != marketdata.NewTimeFrame(5, marketdata.Min) {
+		t.Errorf("requested %v bars, want 5Min", alpaca.timeframe)
+	}
+
+	if _, err := a.GetPriceBars("AAPL", models.BarTimeframe5Min, 2); err != nil || alpaca.barCalls != 1 {
+		t.Errorf("expected the second request served from cache, got %d fetches (error %v)", alpaca.barCalls, err)
+	}
+}
+
+func TestApp_GetPriceBars_NoMarketData(t *testing.T) {
+	if _, err := testApp(nil).GetPriceBars("AAPL", models.BarTimeframe1Day, 10); !errors.Is(err, ErrPriceBarsUnavailable) {
+		t.Errorf("expected ErrPriceBarsUnavailable, got %v", err)
+	}
+}
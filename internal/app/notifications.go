@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"trade-machine/notifications"
+	"trade-machine/observability"
+)
+
+// ErrNoNotificationChannels is returned when a test notification has nowhere to go
+var ErrNoNotificationChannels = errors.New("no notification channels configured")
+
+// GetNotificationSettings returns the notification channels and events, with webhook
+// URLs and the SMTP password masked
+func (a *App) GetNotificationSettings() (notifications.Config, error) {
+	if a.settings == nil {
+		return notifications.Config{}, ErrSettingsUnavailable
+	}
+	return a.settings.Notifications().Masked(), nil
+}
+
+// SetNotificationSettings replaces the notification channels and events. Secrets sent
+// back masked keep their stored values. The notifier reads the settings for every event,
+// so they apply at once.
+func (a *App) SetNotificationSettings(cfg notifications.Config) (notifications.Config, error) {
+	if a.settings == nil {
+		return notifications.Config{}, ErrSettingsUnavailable
+	}
+	cfg = cfg.WithSecretsFrom(a.settings.Notifications())
+	if err := cfg.Validate(); err != nil {
+		return notifications.Config{}, err
+	}
+	if err := a.settings.SaveNotifications(cfg); err != nil {
+		return notifications.Config{}, err
+	}
+
+	observability.Info("notification settings changed", "channels", len(cfg.Channels()), "events", cfg.Events)
+	return cfg.Masked(), nil
+}
+
+// SendTestNotification sends a message to every configured channel, so a webhook or mail
+// server can be checked without waiting for an event
+func (a *App) SendTestNotification(ctx context.Context) error {
+	if a.settings == nil {
+		return ErrSettingsUnavailable
+	}
+	channels := a.settings.Notifications().Channels()
+	if len(channels) == 0 {
+		return ErrNoNotificationChannels
+	}
+	return notifications.Send(ctx, channels, notifications.Message{
+		Title: "Test notification",
+		Text:  "Notifications from trade-machine will arrive here.",
+	})
+}
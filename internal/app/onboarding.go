@@ -0,0 +1,182 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"trade-machine/agents"
+	"trade-machine/internal/settings"
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// ErrSettingsUnavailable is returned when no settings store is configured
+var ErrSettingsUnavailable = errors.New("settings not available")
+
+// ErrInvalidOnboardingStep is returned when an onboarding step is unknown or its input is invalid
+var ErrInvalidOnboardingStep = errors.New("invalid onboarding step")
+
+// OnboardingStrategies lists the action strategies offered by the onboarding wizard
+var OnboardingStrategies = []string{"default", "conservative", "aggressive"}
+
+// StrategySetter is implemented by portfolio managers whose action strategy can be changed at runtime
+type StrategySetter interface {
+	SetStrategy(strategy agents.ActionStrategy)
+}
+
+// OnboardingStatus describes progress through the first-run wizard
+type OnboardingStatus struct {
+	settings.OnboardingState
+	NextStep   settings.OnboardingStep                               `json:"next_step,omitempty"` // Empty once the wizard is finished
+	Finished   bool                                                  `json:"finished"`
+	Services   map[settings.ServiceName]*settings.MaskedAPIKeyConfig `json:"services"`
+	Strategies []string                                              `json:"strategies"`
+}
+
+// OnboardingStepRequest submits one step of the wizard
+type OnboardingStepRequest struct {
+	Step     settings.OnboardingStep `json:"step"`
+	APIKeys  []settings.APIKeyConfig `json:"api_keys,omitempty"` // Keys step: keys to save
+	Strategy string                  `json:"strategy,omitempty"` // Strategy step: one of OnboardingStrategies
+	Skip     bool                    `json:"skip,omitempty"`     // Dismiss the wizard instead of completing a step
+}
+
+// OnboardingStepResult is the outcome of a wizard step
+type OnboardingStepResult struct {
+	Status     *OnboardingStatus            `json:"status"`
+	Validation []*settings.ValidationResult `json:"validation,omitempty"` // Validate step: one result per configured service
+	Demo       *models.Recommendation       `json:"demo,omitempty"`       // Demo step: the sample recommendation
+}
+
+// OnboardingStatus returns the first-run wizard's progress
+func (a *App) OnboardingStatus() (*OnboardingStatus, error) {
+	if a.settings == nil {
+		return nil, ErrSettingsUnavailable
+	}
+	return a.onboardingStatus(a.settings.Onboarding()), nil
+}
+
+func (a *App) onboardingStatus(state settings.OnboardingState) *OnboardingStatus {
+	return &OnboardingStatus{
+		OnboardingState: state,
+		NextStep:        state.NextStep(),
+		Finished:        state.Finished(),
+		Services:        a.settings.GetMaskedSettings(),
+		Strategies:      OnboardingStrategies,
+	}
+}
+
+// CompleteOnboardingStep runs one wizard step and records it as completed. A validate step
+// with failing keys returns the results without completing the step.
+func (a *App) CompleteOnboardingStep(ctx context.Context, req OnboardingStepRequest) (*OnboardingStepResult, error) {
+	if a.settings == nil {
+		return nil, ErrSettingsUnavailable
+	}
+
+	state := a.settings.Onboarding()
+	result := &OnboardingStepResult{}
+	completed := true
+
+	switch {
+	case req.Skip:
+		state.Skip()
+	case req.Step == settings.OnboardingStepKeys:
+		if err := a.saveOnboardingKeys(req.APIKeys); err != nil {
+			return nil, err
+		}
+	case req.Step == settings.OnboardingStepValidate:
+		validation, err := a.validateOnboardingKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result.Validation = validation
+		completed = !slices.ContainsFunc(validation, func(v *settings.ValidationResult) bool { return !v.Valid })
+	case req.Step == settings.OnboardingStepStrategy:
+		if !slices.Contains(OnboardingStrategies, req.Strategy) {
+			return nil, fmt.Errorf("%w: strategy must be one of %v, got %q", ErrInvalidOnboardingStep, OnboardingStrategies, req.Strategy)
+		}
+		state.Strategy = req.Strategy
+		a.applyStrategy(req.Strategy)
+	case req.Step == settings.OnboardingStepDemo:
+		result.Demo = agents.DemoAnalysis(a.cfg, agents.StrategyFromName(state.Strategy))
+	default:
+		return nil, fmt.Errorf("%w: unknown step %q", ErrInvalidOnboardingStep, req.Step)
+	}
+
+	if completed && !req.Skip {
+		state.CompleteStep(req.Step)
+	}
+	if err := a.settings.SaveOnboarding(state); err != nil {
+		return nil, err
+	}
+	result.Status = a.onboardingStatus(state)
+	return result, nil
+}
+
+// saveOnboardingKeys stores the keys entered in the wizard. At least one service must
+// have a key once they are saved.
+func (a *App) saveOnboardingKeys(keys []settings.APIKeyConfig) error {
+	known := a.settings.GetMaskedSettings()
+	for _, key := range keys {
+		if _, ok := known[key.ServiceName]; !ok {
+			return fmt.Errorf("%w: unknown service %q", ErrInvalidOnboardingStep, key.ServiceName)
+		}
+		if !key.HasCredentials() {
+			continue
+		}
+		// Keep fields left blank from any existing config, as the settings form does
+		if existing := a.settings.GetAPIKey(key.ServiceName); existing != nil {
+			key.APISecret = cmp.Or(key.APISecret, existing.APISecret)
+			key.BaseURL = cmp.Or(key.BaseURL, existing.BaseURL)
+			key.Region = cmp.Or(key.Region, existing.Region)
+			key.ModelID = cmp.Or(key.ModelID, existing.ModelID)
+		}
+		if err := a.settings.SetAPIKey(&key); err != nil {
+			return err
+		}
+		if key.ServiceName == settings.ServiceFMP {
+			if err := a.InitializeScreenerWithFMPKey(key.APIKey); err != nil {
+				observability.Warn("failed to reinitialize screener with new FMP key", "error", err)
+			}
+		}
+	}
+
+	if len(a.settings.GetAllAPIKeys()) == 0 {
+		return fmt.Errorf("%w: enter at least one API key", ErrInvalidOnboardingStep)
+	}
+	return nil
+}
+
+// validateOnboardingKeys checks every configured key against its provider
+func (a *App) validateOnboardingKeys(ctx context.Context) ([]*settings.ValidationResult, error) {
+	keys := a.settings.GetAllAPIKeys()
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no API keys to validate", ErrInvalidOnboardingStep)
+	}
+
+	validator := settings.NewValidator()
+	var results []*settings.ValidationResult
+	for _, service := range []settings.ServiceName{settings.ServiceOpenAI, settings.ServiceAnthropic, settings.ServiceOllama, settings.ServiceAlpaca, settings.ServiceAlphaVantage, settings.ServiceNewsAPI, settings.ServiceFMP} {
+		config, ok := keys[service]
+		if !ok {
+			continue
+		}
+		result, err := validator.ValidateAPIKey(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// applyStrategy switches the portfolio manager to the named action strategy, if it supports it
+func (a *App) applyStrategy(name string) {
+	if setter, ok := a.portfolioManager.(StrategySetter); ok {
+		setter.SetStrategy(agents.StrategyFromName(name))
+		observability.Info("action strategy changed", "strategy", name)
+	}
+}
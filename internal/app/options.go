@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"trade-machine/models"
+)
+
+// ErrOptionsUnavailable is returned when no covered-call advisor is configured
+var ErrOptionsUnavailable = errors.New("options suggestions not available: Alpaca required")
+
+// CoveredCallAdvisorInterface defines the advisor that suggests calls to write against held shares
+type CoveredCallAdvisorInterface interface {
+	Suggest(ctx context.Context) (*models.CoveredCallReport, error)
+}
+
+// SetCoveredCallAdvisor sets the covered-call advisor (optional dependency)
+func (a *App) SetCoveredCallAdvisor(c CoveredCallAdvisorInterface) {
+	a.coveredCalls = c
+}
+
+// GetCoveredCallSuggestions returns calls that could be sold against the long positions,
+// with their premium and yield, highest annualized yield first
+func (a *App) GetCoveredCallSuggestions() (*models.CoveredCallReport, error) {
+	if a.coveredCalls == nil {
+		return nil, ErrOptionsUnavailable
+	}
+	report, err := a.coveredCalls.Suggest(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.Disclaimer = a.disclaimer.Text
+	return report, nil
+}
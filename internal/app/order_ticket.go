@@ -0,0 +1,67 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// PreviewRecommendation estimates the order executing a recommendation would place: the
+// fill price, notional, fees, the resulting position weight and the buying power it uses,
+// from the latest quote and the broker account. Broker data is best effort, so the ticket
+// is still returned, with fewer figures, when Alpaca is unavailable. Risk rules that would
+// refuse execution are reported as the ticket's blocker rather than as an error.
+func (a *App) PreviewRecommendation(id string) (*models.OrderTicket, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	recID, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := a.repo.GetRecommendation(a.ctx, recID)
+	if err != nil {
+		return nil, err
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("recommendation %s not found", id)
+	}
+	if !rec.Executable() {
+		return nil, fmt.Errorf("%w: %s %s recommendation is %s", models.ErrRecommendationNotExecutable, rec.Action, rec.Symbol, rec.Status)
+	}
+
+	var quote *models.Quote
+	var account *models.Account
+	var position *models.Position
+	if a.alpacaService != nil {
+		if quote, err = a.fetchQuote(rec.Symbol); err != nil {
+			observability.Debug("order ticket quote unavailable", "symbol", rec.Symbol, "error", err)
+		}
+	}
+	if _, broker := a.broker(); broker != nil {
+		if account, err = broker.GetAccount(a.ctx); err != nil {
+			observability.Debug("order ticket account unavailable", "error", err)
+		}
+		if position, err = a.brokerPosition(rec.Symbol); err != nil {
+			observability.Debug("order ticket position unavailable", "symbol", rec.Symbol, "error", err)
+		}
+	}
+
+	ticket := models.NewOrderTicket(rec, quote, account, position, a.feeSchedule, a.cfg.PositionSizing.ShortMarginRequirement)
+
+	blocked := a.CheckSymbolAllowed(rec.Symbol)
+	if blocked == nil && rec.Status == models.RecommendationStatusPending {
+		blocked = a.checkRiskRules(rec)
+	}
+	switch {
+	case blocked == nil:
+	case errors.Is(blocked, models.ErrSymbolBlocked) || errors.Is(blocked, models.ErrRiskRuleViolation):
+		ticket.Blocker = blocked.Error()
+	default:
+		ticket.Warnings = append(ticket.Warnings, "Risk rules could not be checked: "+blocked.Error())
+	}
+	return ticket, nil
+}
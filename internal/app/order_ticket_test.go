@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// recommendationRepo stubs the recommendation and symbol list reads of RepositoryInterface
+type recommendationRepo struct {
+	RepositoryInterface
+	rec *models.Recommendation
+}
+
+func (r *recommendationRepo) GetRecommendation(ctx context.Context, id uuid.UUID) (*models.Recommendation, error) {
+	return r.rec, nil
+}
+
+func (r *recommendationRepo) GetSymbolListEntries(ctx context.Context) ([]models.SymbolListEntry, error) {
+	return nil, nil
+}
+
+// ticketAlpacaService stubs the quote, account and position lookups behind an order ticket
+type ticketAlpacaService struct {
+	positionAlpacaService
+}
+
+func (m *ticketAlpacaService) GetQuote(ctx context.Context, symbol string) (*models.Quote, error) {
+	return &models.Quote{Symbol: symbol, Bid: decimal.NewFromInt(99), Ask: decimal.NewFromInt(100), Session: models.MarketSessionRegular, Timestamp: time.Now()}, nil
+}
+
+func (m *ticketAlpacaService) GetLatestTrade(ctx context.Context, symbol string) (*models.Quote, error) {
+	return nil, nil
+}
+
+func TestApp_PreviewRecommendation(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+	held := []models.Position{{Symbol: "AAPL", Quantity: decimal.NewFromInt(10), Side: models.PositionSideLong}}
+	a := New(testConfig(), &recommendationRepo{rec: rec}, nil, &ticketAlpacaService{positionAlpacaService{positions: held}})
+	a.ctx = context.Background()
+	// A quote cached for display is not used to size the order
+	a.quotes.put("AAPL", &models.Quote{Symbol: "AAPL", Ask: decimal.NewFromInt(50)})
+
+	ticket, err := a.PreviewRecommendation(rec.ID.String())
+	if err != nil {
+		t.Fatalf("PreviewRecommendation() error = %v", err)
+	}
+	if !ticket.Notional.Equal(decimal.NewFromInt(1000)) || ticket.PriceSource != models.TicketPriceAsk {
+		t.Errorf("notional = %s from %q, want 1000 at the ask", ticket.Notional, ticket.PriceSource)
+	}
+	if ticket.CurrentWeight != 1 || ticket.ResultingWeight != 2 {
+		t.Errorf("weights = %.1f%% -> %.1f%%, want 1%% -> 2%%", ticket.CurrentWeight, ticket.ResultingWeight)
+	}
+	if ticket.Blocker != "" {
+		t.Errorf("Blocker = %q, want none", ticket.Blocker)
+	}
+
+	// An edit over the share limit would be refused on execution
+	a.cfg.PositionSizing.MaxShares = 5
+	quantity := decimal.NewFromInt(11)
+	if err := rec.Edit(models.RecommendationOverride{Quantity: &quantity}); err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	if ticket, err = a.PreviewRecommendation(rec.ID.String()); err != nil || ticket.Blocker == "" {
+		t.Errorf("PreviewRecommendation() = %+v, %v; want a risk rule blocker", ticket, err)
+	}
+
+	rec.Status = models.RecommendationStatusExecuted
+	if _, err := a.PreviewRecommendation(rec.ID.String()); !errors.Is(err, models.ErrRecommendationNotExecutable) {
+		t.Errorf("PreviewRecommendation() error = %v, want ErrRecommendationNotExecutable", err)
+	}
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+// cashFlowSource is implemented by brokers that report deposits and withdrawals, so they
+// can be left out of the portfolio's returns
+type cashFlowSource interface {
+	GetCashFlows(ctx context.Context, after, until time.Time) (decimal.Decimal, error)
+}
+
+// portfolioSnapshotter records the account's equity, cash and positions once per weekday
+// after the regular session closes at 16:00 ET. Exchange holidays are recorded like any
+// other weekday and show as flat days; days the app isn't running after the close are
+// missing from the equity curve.
+type portfolioSnapshotter struct {
+	app *App
+	cfg config.PortfolioSnapshotsConfig
+	now func() time.Time
+}
+
+func newPortfolioSnapshotter(a *App, cfg config.PortfolioSnapshotsConfig) *portfolioSnapshotter {
+	return &portfolioSnapshotter{app: a, cfg: cfg, now: time.Now}
+}
+
+// Run records a missing snapshot on startup and then every interval until ctx is cancelled
+func (s *portfolioSnapshotter) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.cfg.IntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		s.snapshot(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// snapshot records today's snapshot if the session has closed and it hasn't been taken
+// yet, returning it. A failed step is logged and retried on the next check.
+func (s *portfolioSnapshotter) snapshot(ctx context.Context) *models.PortfolioSnapshot {
+	a := s.app
+	now := s.now()
+	et := now.In(models.MarketLocation())
+	if et.Weekday() == time.Saturday || et.Weekday() == time.Sunday || et.Hour() < 16 {
+		return nil
+	}
+
+	latest, err := a.repo.GetLatestPortfolioSnapshot(ctx)
+	if err != nil {
+		observability.Warn("portfolio snapshot: latest snapshot unavailable", "error", err)
+		return nil
+	}
+	if latest != nil && !latest.Date.Before(models.MarketDate(now)) {
+		return nil
+	}
+
+	account, err := a.alpacaService.GetAccount(ctx)
+	if err != nil {
+		observability.Warn("portfolio snapshot: account unavailable", "error", err)
+		return nil
+	}
+	positions, err := a.alpacaService.GetPositions(ctx)
+	if err != nil {
+		observability.Warn("portfolio snapshot: positions unavailable", "error", err)
+		return nil
+	}
+	cashFlow, err := s.cashFlow(ctx, latest, now)
+	if err != nil {
+		observability.Warn("portfolio snapshot: cash flows unavailable", "error", err)
+		return nil
+	}
+
+	snapshot := models.NewPortfolioSnapshot(now, account, positions, cashFlow)
+	if err := a.repo.SavePortfolioSnapshot(ctx, snapshot); err != nil {
+		observability.Warn("portfolio snapshot failed", "error", err)
+		return nil
+	}
+	observability.Info("recorded portfolio snapshot", "date", snapshot.Date.Format("2006-01-02"), "equity", snapshot.Equity.String(), "positions", len(positions))
+	return snapshot
+}
+
+// cashFlow returns the deposits less withdrawals since the latest snapshot, or zero for the
+// first snapshot and for brokers that don't report them
+func (s *portfolioSnapshotter) cashFlow(ctx context.Context, latest *models.PortfolioSnapshot, now time.Time) (decimal.Decimal, error) {
+	source, ok := s.app.alpacaService.(cashFlowSource)
+	if !ok || latest == nil {
+		return decimal.Zero, nil
+	}
+	return source.GetCashFlows(ctx, latest.CreatedAt, now)
+}
+
+// benchmarkBarLookback reaches back far enough before the first snapshot to find the
+// benchmark's close on or before its date, across weekends and holidays
+const benchmarkBarLookback = 7 * 24 * time.Hour
+
+// GetPortfolioHistory returns the equity curve from the daily portfolio snapshots over rng
+// (1m, 3m, 6m, ytd, 1y or all), with its time-weighted return and max drawdown, compared
+// with benchmark's daily closes from Alpaca. An empty benchmark uses RISK_STATS_BENCHMARK.
+// The comparison is left out when Alpaca isn't configured or the bars can't be fetched.
+func (a *App) GetPortfolioHistory(rng, benchmark string) (*models.PortfolioHistory, error) {
+	since, err := models.HistoryRangeStart(rng, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	snapshots, err := a.repo.GetPortfolioSnapshots(a.ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	history := models.NewPortfolioHistory(rng, snapshots)
+	if len(snapshots) == 0 || a.alpacaService == nil {
+		return history, nil
+	}
+
+	if benchmark == "" {
+		benchmark = a.cfg.RiskStats.Benchmark
+	}
+	bars, err := a.alpacaService.GetBars(a.ctx, benchmark, snapshots[0].Date.Add(-benchmarkBarLookback), time.Now(), marketdata.OneDay)
+	if err != nil {
+		observability.Warn("benchmark bars unavailable for portfolio history", "benchmark", benchmark, "error", err)
+		return history, nil
+	}
+	closes := make([]models.DailyClose, len(bars))
+	for i, bar := range bars {
+		closes[i] = models.DailyClose{Date: models.MarketDate(bar.Timestamp), Close: bar.Close}
+	}
+	history.AddBenchmark(benchmark, closes)
+	return history, nil
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/services"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+// snapshotRepo keeps saved portfolio snapshots in date order
+type snapshotRepo struct {
+	RepositoryInterface
+	snapshots []models.PortfolioSnapshot
+}
+
+func (m *snapshotRepo) SavePortfolioSnapshot(ctx context.Context, snapshot *models.PortfolioSnapshot) error {
+	m.snapshots = append(m.snapshots, *snapshot)
+	return nil
+}
+
+func (m *snapshotRepo) GetLatestPortfolioSnapshot(ctx context.Context) (*models.PortfolioSnapshot, error) {
+	if len(m.snapshots) == 0 {
+		return nil, nil
+	}
+	latest := m.snapshots[len(m.snapshots)-1]
+	return &latest, nil
+}
+
+func (m *snapshotRepo) GetPortfolioSnapshots(ctx context.Context, since time.Time) ([]models.PortfolioSnapshot, error) {
+	var snapshots []models.PortfolioSnapshot
+	for _, s := range m.snapshots {
+		if !s.Date.Before(since) {
+			snapshots = append(snapshots, s)
+		}
+	}
+	return snapshots, nil
+}
+
+// cashFlowAlpacaService reports a fixed account, one position, a deposit since the
+// previous snapshot and daily bars for the benchmark
+type cashFlowAlpacaService struct {
+	services.AlpacaServiceInterface
+	equity decimal.Decimal
+	after  time.Time
+	bars   []marketdata.Bar
+	symbol string
+}
+
+func (m *cashFlowAlpacaService) GetAccount(ctx context.Context) (*models.Account, error) {
+	return &models.Account{Equity: m.equity, Cash: decimal.NewFromInt(1000)}, nil
+}
+
+func (m *cashFlowAlpacaService) GetPositions(ctx context.Context) ([]models.Position, error) {
+	return []models.Position{{Symbol: "AAPL", Side: models.PositionSideLong, Quantity: decimal.NewFromInt(10), CurrentPrice: decimal.NewFromInt(150)}}, nil
+}
+
+func (m *cashFlowAlpacaService) GetBars(ctx context.Context, symbol string, start, end time.Time, timeframe marketdata.TimeFrame) ([]marketdata.Bar, error) {
+	m.symbol = symbol
+	return m.bars, nil
+}
+
+func (m *cashFlowAlpacaService) GetCashFlows(ctx context.Context, after, until time.Time) (decimal.Decimal, error) {
+	m.after = after
+	return decimal.NewFromInt(500), nil
+}
+
+func TestPortfolioSnapshotter_Snapshot(t *testing.T) {
+	repo := &snapshotRepo{}
+	alpaca := &cashFlowAlpacaService{equity: decimal.NewFromInt(10000)}
+	a := New(testConfig(), repo, nil, alpaca)
+	s := newPortfolioSnapshotter(a, a.cfg.PortfolioSnapshots)
+
+	// Wednesday 15:30 ET, before the close
+	now := time.Date(2024, 6, 12, 19, 30, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	if snapshot := s.snapshot(context.Background()); snapshot != nil {
+		t.Fatalf("snapshot = %+v before the close, want none", snapshot)
+	}
+
+	// 16:15 ET the first snapshot has no earlier one to take cash flows from
+	now = now.Add(45 * time.Minute)
+	first := s.snapshot(context.Background())
+	if first == nil || !first.Date.Equal(time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC)) || !first.CashFlow.IsZero() || len(first.Positions) != 1 {
+		t.Fatalf("first snapshot = %+v, want June 12th with no cash flow", first)
+	}
+	now = now.Add(15 * time.Minute)
+	if snapshot := s.snapshot(context.Background()); snapshot != nil {
+		t.Errorf("snapshot = %+v, want one per day", snapshot)
+	}
+
+	// Saturday is skipped; Monday's snapshot includes the deposit since Wednesday's
+	now = time.Date(2024, 6, 15, 21, 0, 0, 0, time.UTC)
+	if snapshot := s.snapshot(context.Background()); snapshot != nil {
+		t.Errorf("snapshot = %+v on a Saturday, want none", snapshot)
+	}
+	now = time.Date(2024, 6, 17, 21, 0, 0, 0, time.UTC)
+	alpaca.equity = decimal.NewFromInt(11000)
+	monday := s.snapshot(context.Background())
+	if monday == nil || !monday.CashFlow.Equal(decimal.NewFromInt(500)) || !alpaca.after.Equal(first.CreatedAt) {
+		t.Fatalf("Monday snapshot = %+v after %v, want the deposit since the first snapshot", monday, alpaca.after)
+	}
+
+	// Daily bars are stamped at midnight Eastern
+	alpaca.bars = []marketdata.Bar{
+		{Timestamp: time.Date(2024, 6, 12, 4, 0, 0, 0, time.UTC), Close: 500},
+		{Timestamp: time.Date(2024, 6, 14, 4, 0, 0, 0, time.UTC), Close: 505},
+		{Timestamp: time.Date(2024, 6, 17, 4, 0, 0, 0, time.UTC), Close: 510},
+	}
+	a.ctx = context.Background()
+	history, err := a.GetPortfolioHistory("all", "")
+	if err != nil {
+		t.Fatalf("GetPortfolioHistory error = %v", err)
+	}
+	// $1,000 gained of which $500 was deposited
+	if len(history.Points) != 2 || history.TimeWeightedReturn < 0.0499 || history.TimeWeightedReturn > 0.0501 {
+		t.Errorf("history = %+v, want two points and a 5%% return", history)
+	}
+	if b := history.Benchmark; alpaca.symbol != "SPY" || b == nil || b.Return < 0.0199 || b.Return > 0.0201 || b.RelativeReturn < 0.0299 || b.RelativeReturn > 0.0301 || b.Beta != nil {
+		t.Errorf("benchmark %s = %+v, want a 2%% return and no beta over two days", alpaca.symbol, b)
+	}
+	if _, err := a.GetPortfolioHistory("5y", ""); err == nil {
+		t.Error("GetPortfolioHistory(5y) error = nil, want an invalid range")
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"trade-machine/models"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+)
+
+// ErrPriceBarsUnavailable is returned for price history without a market data source
+var ErrPriceBarsUnavailable = errors.New("price history not available: Alpaca required")
+
+const (
+	// priceBarsTTL is how long price history is served from cache, so charts on several
+	// pages don't refetch the same bars while the latest one is still forming
+	priceBarsTTL = time.Minute
+	// maxPriceBars is the most bars returned for one request
+	maxPriceBars = 1000
+)
+
+// alpacaTimeframes maps bar timeframes to Alpaca's
+var alpacaTimeframes = map[models.BarTimeframe]marketdata.TimeFrame{
+	models.BarTimeframe1Min:  marketdata.NewTimeFrame(1, marketdata.Min),
+	models.BarTimeframe5Min:  marketdata.NewTimeFrame(5, marketdata.Min),
+	models.BarTimeframe15Min: marketdata.NewTimeFrame(15, marketdata.Min),
+	models.BarTimeframe1Hour: marketdata.NewTimeFrame(1, marketdata.Hour),
+	models.BarTimeframe1Day:  marketdata.NewTimeFrame(1, marketdata.Day),
+	models.BarTimeframe1Week: marketdata.NewTimeFrame(1, marketdata.Week),
+	models.BarTimeframe1Mon:  marketdata.NewTimeFrame(1, marketdata.Month),
+}
+
+// GetPriceBars returns up to limit of a symbol's most recent bars of the timeframe, oldest
+// first, for charting. Results are cached for a minute.
+func (a *App) GetPriceBars(symbol string, timeframe models.BarTimeframe, limit int) (*models.PriceHistory, error) {
+	if a.alpacaService == nil {
+		return nil, ErrPriceBarsUnavailable
+	}
+	tf, ok := alpacaTimeframes[timeframe]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", models.ErrInvalidBarTimeframe, timeframe)
+	}
+	symbol = strings.ToUpper(symbol)
+	limit = min(max(limit, 1), maxPriceBars)
+
+	key := fmt.Sprintf("%s/%s/%d", symbol, timeframe, limit)
+	if cached, ok := a.priceBars.get(key); ok {
+		return cached, nil
+	}
+
+	end := time.Now()
+	bars, err := a.alpacaService.GetBars(a.ctx, symbol, end.Add(-timeframe.Lookback(limit)), end, tf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s bars for %s: %w", timeframe, symbol, err)
+	}
+	bars = bars[max(len(bars)-limit, 0):]
+
+	history := &models.PriceHistory{Symbol: symbol, Timeframe: timeframe, Bars: make([]models.PriceBar, len(bars)), FetchedAt: end}
+	for i, bar := range bars {
+		history.Bars[i] = models.PriceBar{
+			Time:   bar.Timestamp,
+			Open:   bar.Open,
+			High:   bar.High,
+			Low:    bar.Low,
+			Close:  bar.Close,
+			Volume: bar.Volume,
+			VWAP:   bar.VWAP,
+		}
+	}
+
+	a.priceBars.put(key, history)
+	return history, nil
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"trade-machine/agents"
+	"trade-machine/internal/settings"
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// ErrUnknownPromptAgent is returned for an agent without a system prompt to customize
+var ErrUnknownPromptAgent = errors.New("agent has no system prompt")
+
+// AgentPromptSettings is an agent's system prompt: the one in use, its built-in default
+// and the saved versions, oldest first. ActiveVersion 0 is the built-in prompt.
+type AgentPromptSettings struct {
+	Agent         models.AgentType         `json:"agent"`
+	Prompt        string                   `json:"prompt"`
+	Default       string                   `json:"default"`
+	ActiveVersion int                      `json:"active_version"`
+	Versions      []settings.PromptVersion `json:"versions"`
+}
+
+// AgentPromptUpdate changes an agent's system prompt: Prompt saves a new version and puts
+// it in use, while Version rolls back to a saved version, or to the built-in prompt for 0
+type AgentPromptUpdate struct {
+	Prompt  *string `json:"prompt,omitempty"`
+	Version *int    `json:"version,omitempty"`
+}
+
+// GetAgentPrompt returns an agent's system prompt with its saved versions
+func (a *App) GetAgentPrompt(agent string) (*AgentPromptSettings, error) {
+	agentType, defaultPrompt, err := a.promptAgent(agent)
+	if err != nil {
+		return nil, err
+	}
+	return newAgentPromptSettings(agentType, defaultPrompt, a.settings.AgentPrompt(string(agentType))), nil
+}
+
+// SetAgentPrompt saves a new version of an agent's system prompt or rolls back to an
+// earlier one. Agents read the prompt for every run, so the change applies to the next
+// analysis.
+func (a *App) SetAgentPrompt(agent string, update AgentPromptUpdate) (*AgentPromptSettings, error) {
+	agentType, defaultPrompt, err := a.promptAgent(agent)
+	if err != nil {
+		return nil, err
+	}
+
+	var saved settings.AgentPrompt
+	switch {
+	case update.Prompt != nil && update.Version != nil:
+		return nil, fmt.Errorf("%w: give a prompt or a version, not both", settings.ErrInvalidPrompt)
+	case update.Prompt != nil:
+		saved, err = a.settings.SaveAgentPrompt(string(agentType), *update.Prompt)
+	case update.Version != nil:
+		saved, err = a.settings.ActivateAgentPrompt(string(agentType), *update.Version)
+	default:
+		return nil, fmt.Errorf("%w: a prompt or a version is required", settings.ErrInvalidPrompt)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	observability.Info("agent prompt changed", "agent", agentType, "version", saved.ActiveVersion)
+	return newAgentPromptSettings(agentType, defaultPrompt, saved), nil
+}
+
+// promptAgent resolves an agent name to its type and built-in system prompt
+func (a *App) promptAgent(agent string) (models.AgentType, string, error) {
+	if a.settings == nil {
+		return "", "", ErrSettingsUnavailable
+	}
+	agentType := models.AgentType(strings.ToLower(strings.TrimSpace(agent)))
+	defaultPrompt, ok := agents.DefaultSystemPrompt(agentType)
+	if !ok {
+		return "", "", fmt.Errorf("%w: %q", ErrUnknownPromptAgent, agent)
+	}
+	return agentType, defaultPrompt, nil
+}
+
+func newAgentPromptSettings(agentType models.AgentType, defaultPrompt string, saved settings.AgentPrompt) *AgentPromptSettings {
+	prompt, version := saved.Active()
+	if version == 0 {
+		prompt = defaultPrompt
+	}
+	versions := saved.Versions
+	if versions == nil {
+		versions = []settings.PromptVersion{}
+	}
+	return &AgentPromptSettings{
+		Agent:         agentType,
+		Prompt:        prompt,
+		Default:       defaultPrompt,
+		ActiveVersion: version,
+		Versions:      versions,
+	}
+}
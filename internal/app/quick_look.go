@@ -0,0 +1,82 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+	"trade-machine/services"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+// quickLookTTL is how long a quick-look summary is served from cache. Hovering the same
+// ticker across pages shouldn't refetch quotes, bars and the earnings calendar each time.
+const quickLookTTL = time.Minute
+
+// quickLookBarDays covers the previous trading day across weekends and holidays
+const quickLookBarDays = 7
+
+// QuickLook returns a cached mini-summary of a symbol: price, day change, latest
+// recommendation and next earnings date. Each part is fetched best effort, so an
+// unavailable source leaves its fields empty instead of failing the summary.
+func (a *App) QuickLook(symbol string) *models.QuickLook {
+	symbol = strings.ToUpper(symbol)
+	if cached, ok := a.quickLooks.get(symbol); ok {
+		return cached
+	}
+	now := time.Now()
+
+	q := &models.QuickLook{Symbol: symbol, FetchedAt: now}
+
+	if a.alpacaService != nil {
+		if quote, err := a.GetQuote(symbol); err == nil {
+			q.Price = quote.Last
+			if !q.Price.IsPositive() && quote.Bid.IsPositive() && quote.Ask.IsPositive() {
+				q.Price = quote.Bid.Add(quote.Ask).Div(decimal.NewFromInt(2))
+			}
+			q.Session = quote.Session
+		} else {
+			observability.Debug("quick look quote unavailable", "symbol", symbol, "error", err)
+		}
+
+		if bars, err := a.alpacaService.GetDailyBars(a.ctx, symbol, quickLookBarDays); err == nil {
+			q.SetPreviousClose(previousClose(bars, now))
+		} else {
+			observability.Debug("quick look bars unavailable", "symbol", symbol, "error", err)
+		}
+	}
+
+	if a.repo != nil {
+		if rec, err := a.repo.GetLatestRecommendationForSymbol(a.ctx, symbol); err == nil {
+			q.LatestRecommendation = rec
+		} else {
+			observability.Debug("quick look recommendation unavailable", "symbol", symbol, "error", err)
+		}
+	}
+
+	if a.clients != nil && a.clients.Configured(a.ctx, services.BreakerFMP) {
+		if next, err := a.clients.FMP().GetNextEarningsDate(a.ctx, symbol); err == nil {
+			q.NextEarnings = next
+		} else {
+			observability.Debug("quick look earnings date unavailable", "symbol", symbol, "error", err)
+		}
+	}
+
+	a.quickLooks.put(symbol, q)
+	return q
+}
+
+// previousClose returns the close of the last daily bar from a trading day before
+// now's market date, or zero if there is none. Bars are oldest first.
+func previousClose(bars []marketdata.Bar, now time.Time) decimal.Decimal {
+	today := now.In(models.MarketLocation()).Format("2006-01-02")
+	for i := len(bars) - 1; i >= 0; i-- {
+		if bars[i].Timestamp.In(models.MarketLocation()).Format("2006-01-02") < today {
+			return decimal.NewFromFloat(bars[i].Close)
+		}
+	}
+	return decimal.Zero
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"trade-machine/internal/settings"
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// ErrRebalanceUnavailable is returned when no rebalancer is configured
+var ErrRebalanceUnavailable = errors.New("rebalancing not available: Alpaca and database required")
+
+// ErrRebalanceRunning is returned when a rebalance is asked for while another is placing its orders
+var ErrRebalanceRunning = errors.New("a rebalance is already running")
+
+// RebalancerInterface defines the planner that sizes orders toward target weights
+type RebalancerInterface interface {
+	Plan(ctx context.Context, targets models.RebalanceTargets) (*models.RebalancePlan, error)
+	Generate(ctx context.Context, targets models.RebalanceTargets) (*models.RebalancePlan, error)
+}
+
+// SetRebalancer sets the portfolio rebalancer (optional dependency)
+func (a *App) SetRebalancer(r RebalancerInterface) {
+	a.rebalancer = r
+}
+
+// GetRebalanceTargets returns the targets saved from the API, or those from
+// REBALANCE_POSITION_TARGETS and REBALANCE_SECTOR_TARGETS when none were saved
+func (a *App) GetRebalanceTargets() models.RebalanceTargets {
+	if a.settings != nil {
+		if saved := a.settings.RebalanceTargets(); saved != nil {
+			return models.RebalanceTargets{Positions: saved.Positions, Sectors: saved.Sectors}.Normalize()
+		}
+	}
+	return models.RebalanceTargets{
+		Positions: a.cfg.Rebalance.PositionTargets,
+		Sectors:   a.cfg.Rebalance.SectorTargets,
+	}.Normalize()
+}
+
+// SetRebalanceTargets replaces the target weights and saves them in settings, when
+// available, so they outlive a restart
+func (a *App) SetRebalanceTargets(targets models.RebalanceTargets) (models.RebalanceTargets, error) {
+	targets = targets.Normalize()
+	if err := targets.Validate(); err != nil {
+		return models.RebalanceTargets{}, err
+	}
+	if a.settings == nil {
+		return models.RebalanceTargets{}, errors.New("settings not available")
+	}
+	saved := settings.RebalanceTargets{Positions: targets.Positions, Sectors: targets.Sectors, UpdatedAt: time.Now()}
+	if err := a.settings.SaveRebalanceTargets(saved); err != nil {
+		return models.RebalanceTargets{}, err
+	}
+
+	observability.Info("rebalance targets changed", "positions", len(targets.Positions), "sectors", len(targets.Sectors))
+	return targets, nil
+}
+
+// PlanRebalance returns the orders that would move the portfolio to its target weights
+// without recording or placing them. Nil targets use the saved ones.
+func (a *App) PlanRebalance(targets *models.RebalanceTargets) (*models.RebalancePlan, error) {
+	if a.rebalancer == nil {
+		return nil, ErrRebalanceUnavailable
+	}
+	plan, err := a.rebalancer.Plan(a.ctx, a.rebalanceTargets(targets))
+	if err != nil {
+		return nil, err
+	}
+	plan.Disclaimer = a.disclaimer.Text
+	return plan, nil
+}
+
+// ExecuteRebalance records the rebalancing orders as recommendations and executes them,
+// sells first so their proceeds fund the buys. An order that fails has its recommendation
+// rejected and the error on its item, and the remaining orders are still placed. Only one
+// rebalance runs at a time, so two can't size orders from the same positions.
+func (a *App) ExecuteRebalance(targets *models.RebalanceTargets) (*models.RebalancePlan, error) {
+	if a.rebalancer == nil {
+		return nil, ErrRebalanceUnavailable
+	}
+	if err := a.checkDisclaimerAcknowledged(); err != nil {
+		return nil, err
+	}
+	if !a.rebalancing.TryLock() {
+		return nil, ErrRebalanceRunning
+	}
+	defer a.rebalancing.Unlock()
+
+	plan, err := a.rebalancer.Generate(a.ctx, a.rebalanceTargets(targets))
+	if err != nil {
+		return nil, err
+	}
+	for i := range plan.Items {
+		item := &plan.Items[i]
+		trade, err := a.ExecuteRecommendation(item.RecommendationID.String(), item.RecommendationVersion)
+		if err != nil {
+			item.Error = err.Error()
+			observability.Warn("rebalance order failed", "symbol", item.Symbol, "action", item.Action, "error", err)
+			a.rejectFailedRebalanceItem(item)
+			continue
+		}
+		id := trade.ID
+		item.TradeID = &id
+	}
+	plan.Executed = true
+	plan.Disclaimer = a.disclaimer.Text
+
+	observability.Info("rebalance executed", "orders", len(plan.Items), "failed", len(plan.Failed()))
+	return plan, nil
+}
+
+// rejectFailedRebalanceItem rejects the recommendation of a rebalance order that could not
+// be placed, so it doesn't linger as pending or approved for a plan that has moved on. One
+// that changed status in the meantime, such as by executing, is left alone.
+func (a *App) rejectFailedRebalanceItem(item *models.RebalanceItem) {
+	rec, err := a.repo.GetRecommendation(a.ctx, *item.RecommendationID)
+	if err == nil && rec != nil && (rec.Status == models.RecommendationStatusPending || rec.Status == models.RecommendationStatusApproved) {
+		err = a.repo.RejectRecommendation(a.ctx, rec.ID, rec.Version)
+	}
+	if err != nil {
+		observability.Warn("failed to reject failed rebalance recommendation", "symbol", item.Symbol,
+			"recommendation_id", item.RecommendationID, "error", err)
+	}
+}
+
+// rebalanceTargets returns targets when given, otherwise the saved targets
+func (a *App) rebalanceTargets(targets *models.RebalanceTargets) models.RebalanceTargets {
+	if targets != nil && !targets.Empty() {
+		return *targets
+	}
+	return a.GetRebalanceTargets()
+}
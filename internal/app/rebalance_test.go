@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// stubRebalancer records the targets it was asked to plan for, generating items when given
+type stubRebalancer struct {
+	targets models.RebalanceTargets
+	items   []models.RebalanceItem
+}
+
+func (s *stubRebalancer) Plan(ctx context.Context, targets models.RebalanceTargets) (*models.RebalancePlan, error) {
+	s.targets = targets
+	return &models.RebalancePlan{Targets: targets}, nil
+}
+
+func (s *stubRebalancer) Generate(ctx context.Context, targets models.RebalanceTargets) (*models.RebalancePlan, error) {
+	plan, err := s.Plan(ctx, targets)
+	plan.Items = s.items
+	return plan, err
+}
+
+func TestApp_PlanRebalance(t *testing.T) {
+	cfg := testConfig()
+	cfg.Rebalance.PositionTargets = map[string]float64{"aapl": 0.1}
+	cfg.Compliance.Disclaimer = "Internal use only."
+	a := New(cfg, nil, nil, nil)
+	a.ctx = context.Background()
+
+	if _, err := a.PlanRebalance(nil); !errors.Is(err, ErrRebalanceUnavailable) {
+		t.Errorf("PlanRebalance() error = %v, want ErrRebalanceUnavailable without a rebalancer", err)
+	}
+
+	rebalancer := &stubRebalancer{}
+	a.SetRebalancer(rebalancer)
+	plan, err := a.PlanRebalance(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rebalancer.targets.Positions["AAPL"] != 0.1 {
+		t.Errorf("targets = %+v, want the configured AAPL target", rebalancer.targets)
+	}
+	if plan.Disclaimer == "" {
+		t.Error("expected the disclaimer on the plan")
+	}
+
+	override := &models.RebalanceTargets{Sectors: map[string]float64{"Energy": 0.2}}
+	if _, err := a.PlanRebalance(override); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rebalancer.targets.Positions) != 0 || rebalancer.targets.Sectors["Energy"] != 0.2 {
+		t.Errorf("targets = %+v, want the request's targets in place of the configured ones", rebalancer.targets)
+	}
+}
+
+func TestApp_ExecuteRebalance(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "Rebalance to target weights")
+	rec.Quantity = decimal.NewFromInt(10)
+	rec.Version = 1
+	alpaca := &orderAlpaca{last: decimal.NewFromInt(100), reject: errors.New("insufficient buying power")}
+	a, repo := splitTestApp(rec, alpaca)
+	id := rec.ID
+	a.SetRebalancer(&stubRebalancer{items: []models.RebalanceItem{
+		{Symbol: "AAPL", Action: models.RecommendationActionBuy, RecommendationID: &id, RecommendationVersion: 1},
+	}})
+
+	// Only one rebalance places orders at a time
+	a.rebalancing.Lock()
+	if _, err := a.ExecuteRebalance(nil); !errors.Is(err, ErrRebalanceRunning) {
+		t.Errorf("ExecuteRebalance() error = %v while another runs, want ErrRebalanceRunning", err)
+	}
+	a.rebalancing.Unlock()
+
+	plan, err := a.ExecuteRebalance(nil)
+	if err != nil {
+		t.Fatalf("ExecuteRebalance() error = %v", err)
+	}
+	if len(plan.Failed()) != 1 || repo.rec.Status != models.RecommendationStatusRejected {
+		t.Errorf("%d failed items with %s recommendation, want the refused order's recommendation rejected", len(plan.Failed()), repo.rec.Status)
+	}
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// maxRecommendationHistory is the most recommendations returned in a symbol's history
+const maxRecommendationHistory = 500
+
+// GetRecommendationHistory returns up to limit of a symbol's most recent recommendations,
+// oldest first, each with how its scores moved from the analysis before it
+func (a *App) GetRecommendationHistory(symbol string, limit int) (*models.RecommendationHistory, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	symbol = strings.ToUpper(symbol)
+	limit = min(limit, maxRecommendationHistory)
+
+	recs, err := a.withDisclaimers(a.repo.GetRecommendationsForSymbol(a.ctx, symbol, limit))
+	if err != nil {
+		return nil, err
+	}
+	return models.NewRecommendationHistory(symbol, recs), nil
+}
+
+// ReanalyzeSymbol analyzes a symbol again, linking the new recommendation to the symbol's
+// latest one, and returns the two side by side. Like AnalyzeStock, it returns
+// ErrAnalysisQueueFull rather than waiting for an analysis slot.
+func (a *App) ReanalyzeSymbol(ctx context.Context, symbol string) (*models.RecommendationComparison, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if a.portfolioManager == nil {
+		return nil, fmt.Errorf("portfolio manager not initialized")
+	}
+	symbol = strings.ToUpper(symbol)
+
+	ctx, span := observability.StartSpan(a.requestContext(ctx), "app.ReanalyzeSymbol", "symbol", symbol)
+	defer span.End()
+
+	previous, err := a.withDisclaimer(a.repo.GetLatestRecommendationForSymbol(ctx, symbol))
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if previous != nil {
+		ctx = models.WithPreviousRecommendation(ctx, previous.ID)
+	}
+
+	select {
+	case a.analysisSem <- struct{}{}:
+	default:
+		span.RecordError(ErrAnalysisQueueFull)
+		return nil, ErrAnalysisQueueFull
+	}
+
+	rec, err := a.withDisclaimer(a.analyzeInSlot(ctx, symbol))
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return models.NewRecommendationComparison(previous, rec), nil
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"trade-machine/models"
+)
+
+// latestRecRepo serves a single recommendation as every symbol's latest
+type latestRecRepo struct {
+	RepositoryInterface
+	latest *models.Recommendation
+}
+
+func (r *latestRecRepo) GetLatestRecommendationForSymbol(ctx context.Context, symbol string) (*models.Recommendation, error) {
+	return r.latest, nil
+}
+
+// previousLinkingManager returns a recommendation linked to the one in its context
+type previousLinkingManager struct{}
+
+func (m *previousLinkingManager) AnalyzeSymbol(ctx context.Context, symbol string) (*models.Recommendation, error) {
+	rec := models.NewRecommendation(symbol, models.RecommendationActionBuy, "")
+	rec.Confidence = 80
+	rec.PreviousID = models.PreviousRecommendationFromContext(ctx)
+	return rec, nil
+}
+
+func TestApp_ReanalyzeSymbol(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("links to the latest recommendation", func(t *testing.T) {
+		latest := models.NewRecommendation("AAPL", models.RecommendationActionHold, "")
+		latest.Confidence = 60
+		a := New(testConfig(), &latestRecRepo{latest: latest}, &previousLinkingManager{}, nil)
+		a.ctx = ctx
+
+		c, err := a.ReanalyzeSymbol(ctx, "aapl")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.Current.PreviousID == nil || *c.Current.PreviousID != latest.ID {
+			t.Errorf("PreviousID = %v, want %v", c.Current.PreviousID, latest.ID)
+		}
+		if c.Delta == nil || !c.Delta.ActionChanged || c.Delta.Confidence != 20 {
+			t.Errorf("delta = %+v, want hold to buy with confidence up 20", c.Delta)
+		}
+	})
+
+	t.Run("first analysis has nothing to compare", func(t *testing.T) {
+		a := New(testConfig(), &latestRecRepo{}, &previousLinkingManager{}, nil)
+		a.ctx = ctx
+		c, err := a.ReanalyzeSymbol(ctx, "AAPL")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.Previous != nil || c.Delta != nil || c.Current.PreviousID != nil {
+			t.Errorf("comparison = %+v, want only the new recommendation", c)
+		}
+	})
+
+	t.Run("respects analysis budget", func(t *testing.T) {
+		a := New(testConfig(), &latestRecRepo{}, &previousLinkingManager{}, nil)
+		a.ctx = ctx
+		for i := 0; i < a.AnalysisSemCapacity(); i++ {
+			a.analysisSem <- struct{}{}
+		}
+		if _, err := a.ReanalyzeSymbol(ctx, "AAPL"); !errors.Is(err, ErrAnalysisQueueFull) {
+			t.Errorf("expected ErrAnalysisQueueFull, got %v", err)
+		}
+	})
+
+	t.Run("repository not initialized", func(t *testing.T) {
+		a := New(testConfig(), nil, &previousLinkingManager{}, nil)
+		if _, err := a.ReanalyzeSymbol(ctx, "AAPL"); err == nil {
+			t.Error("expected an error without a database")
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+	"trade-machine/observability"
+	"trade-machine/services"
+)
+
+// refreshPickLimit caps how many of the latest picks get their ratios refreshed
+const refreshPickLimit = 10
+
+// quoteRefreshCalls is the number of Alpaca calls a quote refresh makes: the quote
+// and the latest trade
+const quoteRefreshCalls = 2
+
+// callBudget caps the calls made to a provider within a fixed window
+type callBudget struct {
+	limit       int
+	window      time.Duration
+	windowStart time.Time
+	used        int
+}
+
+// take reserves n calls, reporting false without reserving any if the window's
+// budget can't cover them
+func (b *callBudget) take(n int, now time.Time) bool {
+	if now.Sub(b.windowStart) >= b.window {
+		b.windowStart = now
+		b.used = 0
+	}
+	if b.used+n > b.limit {
+		return false
+	}
+	b.used += n
+	return true
+}
+
+// jittered spreads d randomly by up to percent in either direction, so refreshes
+// don't line up with other periodic work or hit providers in lockstep
+func jittered(d time.Duration, percent int) time.Duration {
+	if percent <= 0 {
+		return d
+	}
+	spread := int64(d) * int64(percent) / 100
+	return d + time.Duration(rand.Int64N(2*spread+1)-spread)
+}
+
+// providerHealthy reports whether a provider's circuit breaker lets traffic through
+// freely; a struggling provider is left to the requests users are waiting on
+func providerHealthy(name string) bool {
+	return services.BreakerLevel(name) == services.DegradationNone
+}
+
+// cacheRefresher keeps hot cache entries fresh while the market is open and automation
+// isn't paused: quotes for holdings, so the dashboard never waits on Alpaca, and TTM
+// ratios for the latest picks, so screener runs find them cached. Each kind runs on its
+// own jittered schedule within a per-provider call budget.
+type cacheRefresher struct {
+	app    *App
+	cfg    config.CacheRefreshConfig
+	alpaca callBudget
+	fmp    callBudget
+	offset int // Rotates which holdings are refreshed first when the budget runs short
+}
+
+func newCacheRefresher(a *App, cfg config.CacheRefreshConfig) *cacheRefresher {
+	return &cacheRefresher{
+		app:    a,
+		cfg:    cfg,
+		alpaca: callBudget{limit: cfg.AlpacaCallsPerMinute, window: time.Minute},
+		fmp:    callBudget{limit: cfg.FMPCallsPerDay, window: 24 * time.Hour},
+	}
+}
+
+// Run refreshes until ctx is cancelled
+func (r *cacheRefresher) Run(ctx context.Context) {
+	quoteEvery := time.Duration(r.cfg.QuoteIntervalSeconds) * time.Second
+	ratioEvery := time.Duration(r.cfg.RatioIntervalMinutes) * time.Minute
+	quoteTimer := time.NewTimer(jittered(quoteEvery, r.cfg.JitterPercent))
+	defer quoteTimer.Stop()
+	ratioTimer := time.NewTimer(jittered(ratioEvery, r.cfg.JitterPercent))
+	defer ratioTimer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-quoteTimer.C:
+			if marketOpen(time.Now()) && !r.app.AutomationPaused() {
+				r.refreshQuotes(time.Now())
+			}
+			quoteTimer.Reset(jittered(quoteEvery, r.cfg.JitterPercent))
+		case <-ratioTimer.C:
+			if marketOpen(time.Now()) && !r.app.AutomationPaused() {
+				r.refreshRatios(ctx, time.Now())
+			}
+			ratioTimer.Reset(jittered(ratioEvery, r.cfg.JitterPercent))
+		}
+	}
+}
+
+// marketOpen reports whether prices are moving, extended hours included
+func marketOpen(now time.Time) bool {
+	return models.MarketSessionAt(now) != models.MarketSessionClosed
+}
+
+// refreshQuotes refetches quotes for held symbols into the quote cache, as many as the
+// Alpaca budget allows. It returns the number refreshed.
+func (r *cacheRefresher) refreshQuotes(now time.Time) int {
+	a := r.app
+	if a.alpacaService == nil || a.repo == nil || !providerHealthy(services.BreakerAlpaca) {
+		return 0
+	}
+	positions, err := a.repo.GetPositions(a.ctx)
+	if err != nil {
+		observability.Debug("cache refresh: positions unavailable", "error", err)
+		return 0
+	}
+	if len(positions) == 0 {
+		return 0
+	}
+
+	refreshed := 0
+	start := r.offset % len(positions)
+	for i := range positions {
+		pos := positions[(start+i)%len(positions)]
+		if !r.alpaca.take(quoteRefreshCalls, now) {
+			// Start with the holdings skipped here next time
+			r.offset = start + i
+			return refreshed
+		}
+		if _, err := a.fetchQuote(pos.Symbol); err != nil {
+			observability.Debug("cache refresh: quote failed", "symbol", pos.Symbol, "error", err)
+			continue
+		}
+		refreshed++
+	}
+	return refreshed
+}
+
+// refreshRatios refetches TTM ratios for the latest picks, as many as the FMP budget
+// allows. It returns the number refreshed.
+func (r *cacheRefresher) refreshRatios(ctx context.Context, now time.Time) int {
+	a := r.app
+	if a.screener == nil || a.clients == nil || !a.clients.Configured(ctx, services.BreakerFMP) || !providerHealthy(services.BreakerFMP) {
+		return 0
+	}
+	picks, err := a.screener.GetLatestPicks(ctx)
+	if err != nil {
+		observability.Debug("cache refresh: latest picks unavailable", "error", err)
+		return 0
+	}
+
+	fmp := a.clients.FMP()
+	freshCtx := services.WithFreshData(ctx)
+	refreshed := 0
+	for i, pick := range picks {
+		if i == refreshPickLimit || !r.fmp.take(1, now) {
+			break
+		}
+		if _, err := fmp.GetRatios(freshCtx, pick.Symbol); err != nil {
+			observability.Debug("cache refresh: ratios failed", "symbol", pick.Symbol, "error", err)
+			continue
+		}
+		refreshed++
+	}
+	return refreshed
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/services"
+
+	"github.com/shopspring/decimal"
+)
+
+// positionsRepo stubs the positions read of RepositoryInterface
+type positionsRepo struct {
+	RepositoryInterface
+	positions []models.Position
+}
+
+func (r *positionsRepo) GetPositions(ctx context.Context) ([]models.Position, error) {
+	return r.positions, nil
+}
+
+// symbolQuoteAlpaca records the symbols quotes are requested for
+type symbolQuoteAlpaca struct {
+	services.AlpacaServiceInterface
+	symbols []string
+}
+
+func (m *symbolQuoteAlpaca) GetQuote(ctx context.Context, symbol string) (*models.Quote, error) {
+	m.symbols = append(m.symbols, symbol)
+	return &models.Quote{Symbol: symbol, Last: decimal.NewFromInt(10), Timestamp: time.Now()}, nil
+}
+
+func (m *symbolQuoteAlpaca) GetLatestTrade(ctx context.Context, symbol string) (*models.Quote, error) {
+	return nil, nil
+}
+
+func TestCallBudget(t *testing.T) {
+	now := time.Now()
+	b := callBudget{limit: 3, window: time.Minute}
+
+	if !b.take(2, now) {
+		t.Fatal("expected the first calls to fit the budget")
+	}
+	if b.take(2, now.Add(time.Second)) {
+		t.Error("expected calls over the budget to be refused")
+	}
+	if !b.take(1, now.Add(time.Second)) {
+		t.Error("expected the remaining call to fit the budget")
+	}
+	if !b.take(3, now.Add(time.Minute)) {
+		t.Error("expected the budget to reset with the next window")
+	}
+}
+
+func TestJittered(t *testing.T) {
+	for range 100 {
+		d := jittered(10*time.Second, 20)
+		if d < 8*time.Second || d > 12*time.Second {
+			t.Fatalf("jittered(10s, 20%%) = %v, want within 8s-12s", d)
+		}
+	}
+	if d := jittered(10*time.Second, 0); d != 10*time.Second {
+		t.Errorf("jittered without jitter = %v, want 10s", d)
+	}
+}
+
+func TestCacheRefresher_RefreshQuotes(t *testing.T) {
+	services.SetGlobalRegistry(services.NewCircuitBreakerRegistry(services.DefaultCircuitBreakerConfig))
+	alpaca := &symbolQuoteAlpaca{}
+	repo := &positionsRepo{positions: []models.Position{{Symbol: "AAPL"}, {Symbol: "MSFT"}, {Symbol: "NVDA"}}}
+	a := New(testConfig(), repo, nil, alpaca)
+	a.ctx = context.Background()
+
+	cfg := testConfig().CacheRefresh
+	cfg.AlpacaCallsPerMinute = 2 * quoteRefreshCalls
+	r := newCacheRefresher(a, cfg)
+
+	now := time.Now()
+	if n := r.refreshQuotes(now); n != 2 {
+		t.Fatalf("refreshed %d quotes, want 2 within the budget", n)
+	}
+	if _, ok := a.quotes.get("MSFT"); !ok {
+		t.Error("expected the refreshed quote to be cached")
+	}
+	if n := r.refreshQuotes(now.Add(time.Second)); n != 0 {
+		t.Errorf("refreshed %d quotes with the budget spent, want 0", n)
+	}
+
+	// The next window starts with the holding skipped last time
+	r.refreshQuotes(now.Add(time.Minute))
+	if got := alpaca.symbols[2]; got != "NVDA" {
+		t.Errorf("third quote refreshed was %s, want NVDA", got)
+	}
+}
+
+func TestCacheRefresher_SkipsWithoutProviders(t *testing.T) {
+	r := newCacheRefresher(testApp(nil), testConfig().CacheRefresh)
+	now := time.Now()
+	if n := r.refreshQuotes(now); n != 0 {
+		t.Errorf("refreshed %d quotes without Alpaca, want 0", n)
+	}
+	if n := r.refreshRatios(context.Background(), now); n != 0 {
+		t.Errorf("refreshed %d ratios without FMP, want 0", n)
+	}
+}
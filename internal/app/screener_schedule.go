@@ -0,0 +1,180 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/internal/settings"
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/google/uuid"
+)
+
+// screenerScheduler starts screener runs at the times of a cron schedule, such as every
+// weekday before the open. The schedule starts from config and can be replaced from the
+// API, which wakes the loop so the change takes effect at once. Runs are skipped while
+// automation is paused or the screener isn't configured.
+type screenerScheduler struct {
+	app       *App
+	mu        sync.Mutex
+	schedule  models.ScreenerSchedule
+	cron      *models.CronSchedule // nil when scheduled runs are disabled
+	lastRun   *time.Time
+	lastRunID *uuid.UUID
+	lastError string
+	changed   chan struct{}
+	now       func() time.Time
+}
+
+func newScreenerScheduler(a *App, cfg config.ScreenerScheduleConfig) *screenerScheduler {
+	s := &screenerScheduler{
+		app:     a,
+		changed: make(chan struct{}, 1),
+		now:     time.Now,
+	}
+	schedule := models.ScreenerSchedule{Cron: cfg.Cron, Analyze: cfg.Analyze}
+	if err := s.set(schedule); err != nil {
+		observability.Warn("scheduled screener runs disabled", "error", err)
+	}
+	return s
+}
+
+// set replaces the schedule, leaving it unchanged if the cron spec does not parse
+func (s *screenerScheduler) set(schedule models.ScreenerSchedule) error {
+	cron, err := schedule.Normalize()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.schedule, s.cron = schedule, cron
+	s.mu.Unlock()
+
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// next returns when the schedule next fires, or the zero time if it is disabled
+func (s *screenerScheduler) next() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cron == nil {
+		return time.Time{}
+	}
+	return s.cron.Next(s.now())
+}
+
+// status returns the schedule with its next and last runs
+func (s *screenerScheduler) status() *models.ScreenerScheduleStatus {
+	next := s.next()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := &models.ScreenerScheduleStatus{
+		ScreenerSchedule: s.schedule,
+		TimeZone:         models.MarketLocation().String(),
+		LastRun:          s.lastRun,
+		LastRunID:        s.lastRunID,
+		LastError:        s.lastError,
+	}
+	if !next.IsZero() {
+		status.NextRun = &next
+	}
+	return status
+}
+
+// Run starts screener runs on schedule until ctx is cancelled
+func (s *screenerScheduler) Run(ctx context.Context) {
+	for {
+		var due <-chan time.Time
+		var timer *time.Timer
+		if next := s.next(); !next.IsZero() {
+			timer = time.NewTimer(time.Until(next))
+			due = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-s.changed:
+			if timer != nil {
+				timer.Stop()
+			}
+		case <-due:
+			s.runOnce(ctx)
+		}
+	}
+}
+
+// runOnce starts one scheduled screener run and records how it went
+func (s *screenerScheduler) runOnce(ctx context.Context) {
+	a := s.app
+	started := s.now()
+	s.mu.Lock()
+	analyze := s.schedule.Analyze
+	s.lastRun, s.lastRunID, s.lastError = &started, nil, ""
+	s.mu.Unlock()
+
+	var errMsg string
+	switch {
+	case a.AutomationPaused():
+		errMsg = "skipped: automation paused"
+	case a.screener == nil:
+		errMsg = "skipped: screener not configured"
+	}
+	if errMsg != "" {
+		observability.Info("scheduled screener run skipped", "reason", errMsg)
+		s.mu.Lock()
+		s.lastError = errMsg
+		s.mu.Unlock()
+		return
+	}
+
+	observability.Info("scheduled screener run starting", "analyze", analyze)
+	a.invalidateWarm()
+	run, err := a.screener.RunScreen(ctx, &models.ScreenerFilters{ScreenOnly: !analyze})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if run != nil {
+		s.lastRunID = &run.ID
+	}
+	if err != nil {
+		s.lastError = err.Error()
+		observability.Warn("scheduled screener run failed", "error", err)
+	}
+}
+
+// GetScreenerSchedule returns when the screener runs on its own, with its next and last runs
+func (a *App) GetScreenerSchedule() *models.ScreenerScheduleStatus {
+	return a.screenerSchedule.status()
+}
+
+// SetScreenerSchedule replaces the screener schedule and saves it in settings, when
+// available, so it outlives a restart. An empty cron spec stops scheduled runs.
+func (a *App) SetScreenerSchedule(schedule models.ScreenerSchedule) (*models.ScreenerScheduleStatus, error) {
+	if _, err := schedule.Normalize(); err != nil {
+		return nil, err
+	}
+	if a.settings != nil {
+		saved := settings.ScreenerSchedule{Cron: schedule.Cron, Analyze: schedule.Analyze, UpdatedAt: time.Now()}
+		if err := a.settings.SaveScreenerSchedule(saved); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.screenerSchedule.set(schedule); err != nil {
+		return nil, err
+	}
+
+	observability.Info("screener schedule changed", "cron", schedule.Cron, "analyze", schedule.Analyze)
+	return a.screenerSchedule.status(), nil
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+)
+
+// scheduledScreener records the filters each run was started with
+type scheduledScreener struct {
+	ScreenerInterface
+	runs []*models.ScreenerFilters
+	err  error
+}
+
+func (s *scheduledScreener) RunScreen(ctx context.Context, overrides *models.ScreenerFilters) (*models.ScreenerRun, error) {
+	s.runs = append(s.runs, overrides)
+	return &models.ScreenerRun{ID: uuid.New()}, s.err
+}
+
+func TestApp_ScreenerSchedule(t *testing.T) {
+	cfg := testConfig()
+	cfg.Screener.Schedule.Cron = "0 8 * * 1-5"
+	a := New(cfg, nil, nil, nil)
+
+	status := a.GetScreenerSchedule()
+	if status.Cron != "0 8 * * 1-5" || !status.Analyze || status.NextRun == nil || status.TimeZone != models.MarketLocation().String() {
+		t.Errorf("status = %+v, want the configured schedule with a next run", status)
+	}
+	if next := status.NextRun.In(models.MarketLocation()); next.Hour() != 8 || next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+		t.Errorf("NextRun = %v, want 8:00 Eastern on a weekday", next)
+	}
+
+	if _, err := a.SetScreenerSchedule(models.ScreenerSchedule{Cron: "every morning"}); !errors.Is(err, models.ErrInvalidCron) {
+		t.Errorf("SetScreenerSchedule() error = %v, want ErrInvalidCron", err)
+	}
+	if a.GetScreenerSchedule().Cron != "0 8 * * 1-5" {
+		t.Error("an invalid schedule should leave the current one in place")
+	}
+
+	status, err := a.SetScreenerSchedule(models.ScreenerSchedule{Cron: ""})
+	if err != nil {
+		t.Fatalf("SetScreenerSchedule() error = %v", err)
+	}
+	if status.Cron != "" || status.NextRun != nil {
+		t.Errorf("status = %+v, want scheduled runs disabled", status)
+	}
+}
+
+func TestScreenerScheduler_RunOnce(t *testing.T) {
+	a := testApp(nil)
+	a.ctx = context.Background()
+	s := a.screenerSchedule
+	if err := s.set(models.ScreenerSchedule{Cron: "0 8 * * *", Analyze: false}); err != nil {
+		t.Fatalf("set() error = %v", err)
+	}
+
+	s.runOnce(context.Background())
+	if status := s.status(); status.LastRun == nil || status.LastError == "" {
+		t.Errorf("status = %+v, want the run skipped without a screener", status)
+	}
+
+	screener := &scheduledScreener{}
+	a.SetScreener(screener)
+	a.PauseAutomation()
+	s.runOnce(context.Background())
+	if len(screener.runs) != 0 {
+		t.Error("a scheduled run started while automation was paused")
+	}
+
+	a.ResumeAutomation()
+	s.runOnce(context.Background())
+	if len(screener.runs) != 1 || !screener.runs[0].ScreenOnly {
+		t.Fatalf("runs = %+v, want one screen-only run", screener.runs)
+	}
+	if status := s.status(); status.LastRunID == nil || status.LastError != "" {
+		t.Errorf("status = %+v, want the run recorded", status)
+	}
+
+	screener.err = errors.New("fmp down")
+	s.runOnce(context.Background())
+	if status := s.status(); status.LastRunID == nil || status.LastError != "fmp down" {
+		t.Errorf("status = %+v, want the failed run and its error", status)
+	}
+}
+
+func TestScreenerScheduler_Run(t *testing.T) {
+	a := testApp(nil)
+	a.ctx = context.Background()
+	screener := &scheduledScreener{}
+	a.SetScreener(screener)
+	s := a.screenerSchedule
+
+	// On a clock stuck in the past the next run is already due
+	clock := time.Date(2025, 3, 7, 9, 0, 30, 0, models.MarketLocation())
+	s.now = func() time.Time { return clock }
+	s.set(models.ScreenerSchedule{Cron: "* * * * *", Analyze: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		s.mu.Lock()
+		ran := s.lastRunID != nil
+		s.mu.Unlock()
+		if ran {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("scheduled run did not start")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	cancel()
+	<-done
+
+	if screener.runs[0].ScreenOnly {
+		t.Error("an analyzing schedule started a screen-only run")
+	}
+}
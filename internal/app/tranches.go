@@ -0,0 +1,332 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+	"trade-machine/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// trancheCheckInterval is how often due tranches of split recommendations are executed
+const trancheCheckInterval = time.Minute
+
+// ApproveWithSplitPlan approves a pending recommendation to be executed as several child
+// orders instead of one. The tranches are stored with the approval and executed in the
+// background as their time or price comes; the first tranche of a time plan goes out on
+// the next check during market hours.
+func (a *App) ApproveWithSplitPlan(id string, expectedVersion int, plan models.SplitPlan) (*models.SplitProgress, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if brokerName, broker := a.broker(); broker == nil {
+		return nil, fmt.Errorf("trading not available: %s not configured", brokerName)
+	}
+	if a.alpacaService == nil {
+		return nil, fmt.Errorf("split orders not available: Alpaca not configured for quotes")
+	}
+	if err := a.checkDisclaimerAcknowledged(); err != nil {
+		return nil, err
+	}
+	a.invalidateWarm()
+
+	recID, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := a.repo.GetRecommendation(a.ctx, recID)
+	if err != nil {
+		return nil, err
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("recommendation %s not found", id)
+	}
+	if rec.Status != models.RecommendationStatusPending || !rec.Executable() {
+		return nil, fmt.Errorf("%w: %s %s recommendation is %s", models.ErrRecommendationNotExecutable, rec.Action, rec.Symbol, rec.Status)
+	}
+	if err := a.CheckSymbolAllowed(rec.Symbol); err != nil {
+		return nil, err
+	}
+	if err := a.checkRiskRules(rec); err != nil {
+		return nil, err
+	}
+
+	tranches, err := plan.Tranches(recID, rec.EffectiveQuantity(), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	err = a.repo.UnitOfWork(a.ctx, func(tx repository.RepositoryInterface) error {
+		if err := tx.ApproveRecommendation(a.ctx, recID, expectedVersion); err != nil {
+			return err
+		}
+		return tx.CreateRecommendationTranches(a.ctx, tranches)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	progress := models.NewSplitProgress(tranches)
+	return &progress, nil
+}
+
+// GetSplitProgress returns the tranches of a split recommendation and how much has filled,
+// or nil if it was not split
+func (a *App) GetSplitProgress(id string) (*models.SplitProgress, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	recID, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+	tranches, err := a.repo.GetRecommendationTranches(a.ctx, recID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tranches) == 0 {
+		return nil, nil
+	}
+
+	progress := models.NewSplitProgress(tranches)
+	return &progress, nil
+}
+
+// CancelSplitPlan cancels the tranches of a split recommendation that have not executed.
+// If some already placed orders, the recommendation is marked executed with what was
+// placed; otherwise it stays approved and can be executed in one order.
+func (a *App) CancelSplitPlan(id string) (*models.SplitProgress, error) {
+	if a.repo == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	a.invalidateWarm()
+
+	recID, err := ParseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var tranches []models.RecommendationTranche
+	err = a.repo.UnitOfWork(a.ctx, func(tx repository.RepositoryInterface) error {
+		cancelled, err := tx.CancelPendingTranches(a.ctx, recID)
+		if err != nil {
+			return err
+		}
+		if cancelled == 0 {
+			return fmt.Errorf("%w: recommendation %s has no tranches left to cancel", models.ErrRecommendationNotExecutable, id)
+		}
+		if err := finishSplit(a.ctx, tx, recID); err != nil {
+			return err
+		}
+		tranches, err = tx.GetRecommendationTranches(a.ctx, recID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	progress := models.NewSplitProgress(tranches)
+	return &progress, nil
+}
+
+// finishSplit marks a split recommendation executed once none of its tranches are left
+// waiting or being placed, recording the trade of the last tranche that placed an order. A plan whose
+// tranches all failed leaves the recommendation approved.
+func finishSplit(ctx context.Context, tx repository.RepositoryInterface, recID uuid.UUID) error {
+	tranches, err := tx.GetRecommendationTranches(ctx, recID)
+	if err != nil {
+		return err
+	}
+
+	var lastTrade *uuid.UUID
+	for _, t := range tranches {
+		if t.Status == models.TrancheStatusPending || t.Status == models.TrancheStatusSubmitting {
+			return nil
+		}
+		if t.Status == models.TrancheStatusSubmitted && t.TradeID != nil {
+			lastTrade = t.TradeID
+		}
+	}
+	if lastTrade == nil {
+		return nil
+	}
+	return tx.ExecuteRecommendation(ctx, recID, *lastTrade, models.AnyVersion)
+}
+
+// trancheExecutor places the child orders of split recommendations as they come due. It
+// only trades in the regular session by the broker's calendar, so holidays and early
+// closes are skipped, and holds off while automation is paused.
+type trancheExecutor struct {
+	app *App
+	now func() time.Time
+}
+
+func newTrancheExecutor(a *App) *trancheExecutor {
+	return &trancheExecutor{app: a, now: time.Now}
+}
+
+// Run checks for due tranches until ctx is cancelled
+func (e *trancheExecutor) Run(ctx context.Context) {
+	ticker := time.NewTicker(trancheCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if e.app.MarketSession() == models.MarketSessionRegular && !e.app.AutomationPaused() {
+				e.executeDue(ctx)
+			}
+		}
+	}
+}
+
+// executeDue places at most one due tranche per recommendation, so a plan that fell behind
+// catches up one interval at a time instead of all at once. Price tranches are checked
+// against the last trade, or the bid/ask midpoint without one. It returns the number placed.
+func (e *trancheExecutor) executeDue(ctx context.Context) int {
+	a := e.app
+	pending, err := a.repo.GetPendingTranches(ctx)
+	if err != nil {
+		observability.Warn("tranche executor: pending tranches unavailable", "error", err)
+		return 0
+	}
+
+	now := e.now()
+	recs := make(map[uuid.UUID]*models.Recommendation)
+	prices := make(map[string]decimal.Decimal)
+	done := make(map[uuid.UUID]bool)
+	placed := 0
+	for i := range pending {
+		t := &pending[i]
+		if done[t.RecommendationID] {
+			continue
+		}
+		rec, ok := recs[t.RecommendationID]
+		if !ok {
+			if rec, err = a.repo.GetRecommendation(ctx, t.RecommendationID); err != nil {
+				observability.Warn("tranche executor: recommendation unavailable", "recommendation_id", t.RecommendationID, "error", err)
+			}
+			recs[t.RecommendationID] = rec
+		}
+		if rec == nil {
+			continue
+		}
+
+		price, ok := prices[rec.Symbol]
+		if !ok && t.TriggerPrice != nil {
+			quote, err := a.fetchQuote(rec.Symbol)
+			if err != nil {
+				observability.Debug("tranche executor: quote failed", "symbol", rec.Symbol, "error", err)
+				continue
+			}
+			price = quote.Price()
+			prices[rec.Symbol] = price
+		}
+		if !t.Due(rec.Action, now, price) {
+			continue
+		}
+
+		done[t.RecommendationID] = true
+		if err := e.execute(ctx, rec, t, price); err != nil {
+			observability.Warn("tranche execution failed", "recommendation_id", rec.ID, "sequence", t.Sequence, "error", err)
+			continue
+		}
+		placed++
+	}
+	return placed
+}
+
+// execute places one tranche's order and records its trade, the submitted tranche and the
+// resulting position in one transaction, marking the recommendation executed after the
+// last tranche. Price tranches go out as limit orders at their level.
+//
+// The broker order cannot be rolled back, so the tranche is first claimed as submitting:
+// a second pickup fails the claim instead of placing another order. An order the broker
+// refuses fails the tranche for good rather than being retried every minute. If recording
+// the trade fails after the order was accepted, the tranche stays submitting and the order
+// ID is logged for reconciliation.
+func (e *trancheExecutor) execute(ctx context.Context, rec *models.Recommendation, t *models.RecommendationTranche, price decimal.Decimal) error {
+	a := e.app
+	side := rec.Action.TradeSide()
+	orderType, limitPrice := rec.EffectiveOrderType(), rec.EffectiveLimitPrice()
+	if t.TriggerPrice != nil {
+		orderType, limitPrice = models.OrderTypeLimit, t.TriggerPrice
+	}
+	if limitPrice != nil {
+		price = *limitPrice
+	}
+	if price.IsZero() {
+		price = rec.EntryPrice
+	}
+
+	brokerName, broker := a.broker()
+	if broker == nil {
+		return fmt.Errorf("trading not available: %s not configured", brokerName)
+	}
+	if err := a.repo.ClaimTranche(ctx, t.ID); err != nil {
+		return err
+	}
+	bracket, err := a.guardPosition(ctx, broker, rec, price)
+	if err != nil {
+		if releaseErr := a.repo.ReleaseTranche(ctx, t.ID); releaseErr != nil {
+			observability.Error("failed to release tranche after its bracket legs could not be cancelled",
+				"recommendation_id", rec.ID, "sequence", t.Sequence, "error", releaseErr)
+		}
+		return err
+	}
+
+	orderID, err := broker.PlaceOrder(ctx, models.OrderRequest{
+		Symbol:     rec.Symbol,
+		Quantity:   t.Quantity,
+		Side:       side,
+		Type:       orderType,
+		LimitPrice: limitPrice,
+		Bracket:    bracket,
+	})
+	if err != nil {
+		reason := err.Error()
+		failErr := a.repo.UnitOfWork(ctx, func(tx repository.RepositoryInterface) error {
+			if err := tx.MarkTrancheFailed(ctx, t.ID, reason); err != nil {
+				return err
+			}
+			return finishSplit(ctx, tx, rec.ID)
+		})
+		if failErr != nil {
+			observability.Warn("failed to record tranche failure", "recommendation_id", rec.ID, "sequence", t.Sequence, "error", failErr)
+		}
+		return fmt.Errorf("failed to place order: %w", err)
+	}
+	recordBracketLegs(ctx, broker, orderID, bracket)
+
+	trade := models.NewTrade(rec.Symbol, side, t.Quantity, price)
+	trade.AlpacaOrderID = orderID
+	trade.Broker = brokerName
+	a.feeSchedule.Apply(trade)
+	err = a.repo.UnitOfWork(ctx, func(tx repository.RepositoryInterface) error {
+		if err := tx.CreateTrade(ctx, trade); err != nil {
+			return err
+		}
+		if err := tx.MarkTrancheSubmitted(ctx, t.ID, trade.ID); err != nil {
+			return err
+		}
+		if err := applyTradeToPosition(ctx, tx, trade, rec.Action, bracket); err != nil {
+			return err
+		}
+		return finishSplit(ctx, tx, rec.ID)
+	})
+	if err != nil {
+		observability.Error("order placed but tranche execution was rolled back",
+			"recommendation_id", rec.ID, "sequence", t.Sequence, "order_id", trade.AlpacaOrderID, "error", err)
+		return err
+	}
+
+	a.invalidateWarm()
+	return nil
+}
@@ -0,0 +1,375 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/repository"
+	"trade-machine/services"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// trancheRepo keeps one recommendation, its tranches and the trades they place in memory
+type trancheRepo struct {
+	repository.RepositoryInterface
+	rec       *models.Recommendation
+	tranches  []models.RecommendationTranche
+	trades    map[uuid.UUID]*models.Trade
+	position  *models.Position
+	claimed   int   // Version the recommendation was claimed at
+	failTrade error // Returned when recording a tranche's trade
+}
+
+func newTrancheRepo(rec *models.Recommendation) *trancheRepo {
+	return &trancheRepo{rec: rec, trades: make(map[uuid.UUID]*models.Trade)}
+}
+
+func (r *trancheRepo) UnitOfWork(ctx context.Context, fn func(tx repository.RepositoryInterface) error) error {
+	return fn(r)
+}
+
+func (r *trancheRepo) GetRecommendation(ctx context.Context, id uuid.UUID) (*models.Recommendation, error) {
+	rec := *r.rec
+	return &rec, nil
+}
+
+func (r *trancheRepo) ApproveRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error {
+	r.rec.Approve()
+	return nil
+}
+
+func (r *trancheRepo) RejectRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error {
+	r.rec.Reject()
+	return nil
+}
+
+func (r *trancheRepo) ClaimRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error {
+	if r.rec.Status != models.RecommendationStatusApproved {
+		return models.ErrRecommendationNotExecutable
+	}
+	r.rec.Status = models.RecommendationStatusExecuting
+	r.claimed = expectedVersion
+	return nil
+}
+
+func (r *trancheRepo) ReleaseRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error {
+	r.rec.Status = models.RecommendationStatusApproved
+	return nil
+}
+
+func (r *trancheRepo) ExecuteRecommendation(ctx context.Context, id uuid.UUID, tradeID uuid.UUID, expectedVersion int) error {
+	r.rec.MarkExecuted(tradeID)
+	return nil
+}
+
+func (r *trancheRepo) GetRecommendationByTradeID(ctx context.Context, tradeID uuid.UUID) (*models.Recommendation, error) {
+	if r.rec.ExecutedTradeID == nil || *r.rec.ExecutedTradeID != tradeID {
+		return nil, nil
+	}
+	rec := *r.rec
+	return &rec, nil
+}
+
+func (r *trancheRepo) FailRecommendation(ctx context.Context, id uuid.UUID, tradeID uuid.UUID) error {
+	r.rec.Status = models.RecommendationStatusFailed
+	return nil
+}
+
+func (r *trancheRepo) GetSymbolListEntries(ctx context.Context) ([]models.SymbolListEntry, error) {
+	return nil, nil
+}
+
+func (r *trancheRepo) CreateRecommendationTranches(ctx context.Context, tranches []models.RecommendationTranche) error {
+	r.tranches = append(r.tranches, tranches...)
+	return nil
+}
+
+func (r *trancheRepo) GetRecommendationTranches(ctx context.Context, recID uuid.UUID) ([]models.RecommendationTranche, error) {
+	return append([]models.RecommendationTranche(nil), r.tranches...), nil
+}
+
+func (r *trancheRepo) GetPendingTranches(ctx context.Context) ([]models.RecommendationTranche, error) {
+	var pending []models.RecommendationTranche
+	for _, t := range r.tranches {
+		if t.Status == models.TrancheStatusPending && r.rec.Status == models.RecommendationStatusApproved {
+			pending = append(pending, t)
+		}
+	}
+	return pending, nil
+}
+
+func (r *trancheRepo) ClaimTranche(ctx context.Context, id uuid.UUID) error {
+	t := r.tranche(id)
+	if t.Status != models.TrancheStatusPending {
+		return models.ErrRecommendationNotExecutable
+	}
+	t.Status = models.TrancheStatusSubmitting
+	return nil
+}
+
+func (r *trancheRepo) ReleaseTranche(ctx context.Context, id uuid.UUID) error {
+	r.tranche(id).Status = models.TrancheStatusPending
+	return nil
+}
+
+func (r *trancheRepo) MarkTrancheSubmitted(ctx context.Context, id uuid.UUID, tradeID uuid.UUID) error {
+	if r.failTrade != nil {
+		return r.failTrade
+	}
+	t := r.tranche(id)
+	if t.Status != models.TrancheStatusSubmitting {
+		return models.ErrRecommendationNotExecutable
+	}
+	t.Status, t.TradeID = models.TrancheStatusSubmitted, &tradeID
+	return nil
+}
+
+func (r *trancheRepo) MarkTrancheFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	t := r.tranche(id)
+	t.Status, t.Error = models.TrancheStatusFailed, reason
+	return nil
+}
+
+func (r *trancheRepo) CancelPendingTranches(ctx context.Context, recID uuid.UUID) (int64, error) {
+	var n int64
+	for i := range r.tranches {
+		if r.tranches[i].Status == models.TrancheStatusPending {
+			r.tranches[i].Status = models.TrancheStatusCancelled
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *trancheRepo) CreateTrade(ctx context.Context, trade *models.Trade) error {
+	r.trades[trade.ID] = trade
+	return nil
+}
+
+func (r *trancheRepo) GetPositionBySymbol(ctx context.Context, symbol string) (*models.Position, error) {
+	return r.position, nil
+}
+
+func (r *trancheRepo) CreatePosition(ctx context.Context, pos *models.Position) error {
+	r.position = pos
+	return nil
+}
+
+func (r *trancheRepo) UpdatePosition(ctx context.Context, pos *models.Position) error {
+	r.position = pos
+	return nil
+}
+
+func (r *trancheRepo) DeletePosition(ctx context.Context, id uuid.UUID) error {
+	r.position = nil
+	return nil
+}
+
+func (r *trancheRepo) tranche(id uuid.UUID) *models.RecommendationTranche {
+	for i := range r.tranches {
+		if r.tranches[i].ID == id {
+			return &r.tranches[i]
+		}
+	}
+	return &models.RecommendationTranche{}
+}
+
+// orderAlpaca quotes a fixed price and records the orders placed
+type orderAlpaca struct {
+	services.AlpacaServiceInterface
+	last       decimal.Decimal
+	bidAskOnly bool // Quote only a bid and ask around last, as Alpaca does, leaving it to the latest trade
+	orders     []models.OrderRequest
+	reject     error
+}
+
+func (m *orderAlpaca) GetQuote(ctx context.Context, symbol string) (*models.Quote, error) {
+	if m.bidAskOnly {
+		spread := decimal.NewFromFloat(0.5)
+		return &models.Quote{Symbol: symbol, Bid: m.last.Sub(spread), Ask: m.last.Add(spread), Timestamp: time.Now()}, nil
+	}
+	return &models.Quote{Symbol: symbol, Last: m.last, Timestamp: time.Now()}, nil
+}
+
+func (m *orderAlpaca) GetLatestTrade(ctx context.Context, symbol string) (*models.Quote, error) {
+	return &models.Quote{Symbol: symbol, Last: m.last, Timestamp: time.Now()}, nil
+}
+
+func (m *orderAlpaca) PlaceOrder(ctx context.Context, req models.OrderRequest) (string, error) {
+	if m.reject != nil {
+		return "", m.reject
+	}
+	m.orders = append(m.orders, req)
+	return uuid.NewString(), nil
+}
+
+func splitTestApp(rec *models.Recommendation, alpaca *orderAlpaca) (*App, *trancheRepo) {
+	repo := newTrancheRepo(rec)
+	a := New(testConfig(), repo, nil, alpaca)
+	a.ctx = context.Background()
+	return a, repo
+}
+
+func TestApp_ApproveWithSplitPlan(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+	a, repo := splitTestApp(rec, &orderAlpaca{last: decimal.NewFromInt(100)})
+
+	if _, err := a.ApproveWithSplitPlan(rec.ID.String(), models.AnyVersion, models.SplitPlan{Trigger: models.SplitTriggerTime, Count: 20, IntervalMinutes: 5}); !errors.Is(err, models.ErrInvalidSplitPlan) {
+		t.Errorf("ApproveWithSplitPlan() error = %v, want ErrInvalidSplitPlan for more tranches than shares", err)
+	}
+	if rec.Status != models.RecommendationStatusPending {
+		t.Fatal("an invalid plan should leave the recommendation pending")
+	}
+
+	progress, err := a.ApproveWithSplitPlan(rec.ID.String(), models.AnyVersion, models.SplitPlan{Trigger: models.SplitTriggerTime, Count: 3, IntervalMinutes: 5})
+	if err != nil {
+		t.Fatalf("ApproveWithSplitPlan() error = %v", err)
+	}
+	if rec.Status != models.RecommendationStatusApproved || len(repo.tranches) != 3 || progress.Pending != 3 {
+		t.Errorf("status %s with %d tranches (%d pending), want approved with 3 pending", rec.Status, len(repo.tranches), progress.Pending)
+	}
+
+	if _, err := a.ExecuteRecommendation(rec.ID.String(), models.AnyVersion); !errors.Is(err, models.ErrRecommendationNotExecutable) {
+		t.Errorf("ExecuteRecommendation() error = %v, want it refused while tranches are pending", err)
+	}
+	if _, err := a.ApproveWithSplitPlan(rec.ID.String(), models.AnyVersion, models.SplitPlan{Trigger: models.SplitTriggerTime, Count: 2, IntervalMinutes: 5}); !errors.Is(err, models.ErrRecommendationNotExecutable) {
+		t.Errorf("ApproveWithSplitPlan() error = %v, want approved recommendations refused", err)
+	}
+}
+
+func TestTrancheExecutor_TimePlan(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(9)
+	alpaca := &orderAlpaca{last: decimal.NewFromInt(100)}
+	a, repo := splitTestApp(rec, alpaca)
+	if _, err := a.ApproveWithSplitPlan(rec.ID.String(), models.AnyVersion, models.SplitPlan{Trigger: models.SplitTriggerTime, Count: 3, IntervalMinutes: 30}); err != nil {
+		t.Fatalf("ApproveWithSplitPlan() error = %v", err)
+	}
+
+	start := time.Now()
+	e := newTrancheExecutor(a)
+	e.now = func() time.Time { return start }
+	if placed := e.executeDue(context.Background()); placed != 1 {
+		t.Fatalf("executeDue() placed %d, want the first tranche right away", placed)
+	}
+	if placed := e.executeDue(context.Background()); placed != 0 {
+		t.Errorf("executeDue() placed %d before the next interval, want 0", placed)
+	}
+
+	// Both remaining tranches are due after an hour, but a plan catches up one at a time
+	e.now = func() time.Time { return start.Add(time.Hour) }
+	if placed := e.executeDue(context.Background()); placed != 1 {
+		t.Errorf("executeDue() placed %d, want one tranche per check", placed)
+	}
+	if rec.Status != models.RecommendationStatusApproved {
+		t.Errorf("status = %s with a tranche left, want approved", rec.Status)
+	}
+	e.executeDue(context.Background())
+
+	if len(alpaca.orders) != 3 || rec.Status != models.RecommendationStatusExecuted {
+		t.Fatalf("%d orders, status %s; want 3 orders and the recommendation executed", len(alpaca.orders), rec.Status)
+	}
+	if *rec.ExecutedTradeID != *repo.tranches[2].TradeID {
+		t.Error("expected the recommendation to record the last tranche's trade")
+	}
+	if repo.position == nil || !repo.position.Quantity.Equal(decimal.NewFromInt(9)) {
+		t.Errorf("position = %+v, want all 9 shares", repo.position)
+	}
+	for _, order := range alpaca.orders {
+		if !order.Quantity.Equal(decimal.NewFromInt(3)) || order.Type != models.OrderTypeMarket {
+			t.Errorf("order = %+v, want market orders of 3 shares", order)
+		}
+	}
+}
+
+func TestTrancheExecutor_PricePlan(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+	alpaca := &orderAlpaca{last: decimal.NewFromInt(100), bidAskOnly: true}
+	a, repo := splitTestApp(rec, alpaca)
+	plan := models.SplitPlan{Trigger: models.SplitTriggerPrice, PriceLevels: []decimal.Decimal{decimal.NewFromInt(98), decimal.NewFromInt(95)}}
+	if _, err := a.ApproveWithSplitPlan(rec.ID.String(), models.AnyVersion, plan); err != nil {
+		t.Fatalf("ApproveWithSplitPlan() error = %v", err)
+	}
+
+	e := newTrancheExecutor(a)
+	if placed := e.executeDue(context.Background()); placed != 0 {
+		t.Errorf("executeDue() placed %d above every level, want 0", placed)
+	}
+
+	alpaca.last = decimal.NewFromInt(96)
+	if placed := e.executeDue(context.Background()); placed != 1 {
+		t.Fatalf("executeDue() placed %d at 96, want the 98 tranche", placed)
+	}
+	order := alpaca.orders[0]
+	if order.Type != models.OrderTypeLimit || order.LimitPrice == nil || !order.LimitPrice.Equal(decimal.NewFromInt(98)) {
+		t.Errorf("order = %+v, want a limit order at the tranche's level", order)
+	}
+
+	// A refused order fails its tranche for good, and the plan finishes with what was placed
+	alpaca.last = decimal.NewFromInt(94)
+	alpaca.reject = errors.New("insufficient buying power")
+	e.executeDue(context.Background())
+	if repo.tranches[1].Status != models.TrancheStatusFailed || repo.tranches[1].Error == "" {
+		t.Errorf("tranche = %+v, want it failed with the broker's reason", repo.tranches[1])
+	}
+	if rec.Status != models.RecommendationStatusExecuted || *rec.ExecutedTradeID != *repo.tranches[0].TradeID {
+		t.Errorf("status = %s, want executed with the first tranche's trade", rec.Status)
+	}
+}
+
+func TestTrancheExecutor_RecordFailureKeepsClaim(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+	alpaca := &orderAlpaca{last: decimal.NewFromInt(100)}
+	a, repo := splitTestApp(rec, alpaca)
+	if _, err := a.ApproveWithSplitPlan(rec.ID.String(), models.AnyVersion, models.SplitPlan{Trigger: models.SplitTriggerTime, Count: 2, IntervalMinutes: 30}); err != nil {
+		t.Fatalf("ApproveWithSplitPlan() error = %v", err)
+	}
+
+	// The broker accepts the order but the trade can't be recorded
+	repo.failTrade = errors.New("connection reset")
+	e := newTrancheExecutor(a)
+	if placed := e.executeDue(context.Background()); placed != 0 {
+		t.Errorf("executeDue() placed %d, want the failure reported", placed)
+	}
+	if repo.tranches[0].Status != models.TrancheStatusSubmitting {
+		t.Fatalf("tranche status = %s, want it left submitting", repo.tranches[0].Status)
+	}
+
+	// The claimed tranche isn't due again, so its order isn't placed twice
+	repo.failTrade = nil
+	e.executeDue(context.Background())
+	if len(alpaca.orders) != 1 {
+		t.Errorf("%d orders placed, want the first tranche's only", len(alpaca.orders))
+	}
+	if progress, _ := a.GetSplitProgress(rec.ID.String()); progress == nil || progress.Pending != 2 {
+		t.Errorf("progress = %+v, want the submitting tranche still counted as pending", progress)
+	}
+}
+
+func TestApp_CancelSplitPlan(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+	a, _ := splitTestApp(rec, &orderAlpaca{last: decimal.NewFromInt(100)})
+	if _, err := a.ApproveWithSplitPlan(rec.ID.String(), models.AnyVersion, models.SplitPlan{Trigger: models.SplitTriggerTime, Count: 2, IntervalMinutes: 30}); err != nil {
+		t.Fatalf("ApproveWithSplitPlan() error = %v", err)
+	}
+
+	progress, err := a.CancelSplitPlan(rec.ID.String())
+	if err != nil {
+		t.Fatalf("CancelSplitPlan() error = %v", err)
+	}
+	if progress.Pending != 0 || rec.Status != models.RecommendationStatusApproved {
+		t.Errorf("%d pending, status %s; want none pending and the recommendation still approved", progress.Pending, rec.Status)
+	}
+	if _, err := a.CancelSplitPlan(rec.ID.String()); !errors.Is(err, models.ErrRecommendationNotExecutable) {
+		t.Errorf("CancelSplitPlan() error = %v, want nothing left to cancel", err)
+	}
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"sync"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// warmupTTL is how long data preloaded on startup may be served. The first dashboard
+// paint follows startup within seconds; anything later reads from the source.
+const warmupTTL = 30 * time.Second
+
+// warmupQuoteLimit caps how many holdings get a quote during warm-up
+const warmupQuoteLimit = 25
+
+// Warm cache keys, one per dashboard read preloaded on startup
+const (
+	warmPortfolio = "portfolio"
+	warmPositions = "positions"
+	warmPending   = "pending"
+	warmLatestRun = "latest_run"
+	warmTopPicks  = "top_picks"
+)
+
+// takeWarm returns the value preloaded under key, removing it so that only the first
+// read after startup is served from the warm-up
+func takeWarm[T any](a *App, key string) (T, bool) {
+	v, ok := a.warm.take(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	t, ok := v.(T)
+	return t, ok
+}
+
+// invalidateWarm drops preloaded data after a change it may no longer reflect
+func (a *App) invalidateWarm() {
+	a.warm.clear()
+}
+
+// warmUp preloads what the dashboard shows first: the portfolio, pending recommendations,
+// the latest screener run and its picks, and quotes for holdings. Steps run in parallel
+// and startup waits for them up to timeout; slower steps finish in the background and
+// are served if they land before the first read. Each step's duration is exported as a
+// startup metric, with the whole warm-up as step "total".
+func (a *App) warmUp(timeout time.Duration) {
+	metrics := observability.GetMetrics()
+	total := metrics.NewTimer()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	failed := 0
+	step := func(name string, load func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			timer := metrics.NewTimer()
+			status := "success"
+			if err := load(); err != nil {
+				status = "error"
+				mu.Lock()
+				failed++
+				mu.Unlock()
+				observability.Warn("warm-up step failed", "step", name, "error", err)
+			}
+			timer.ObserveWarmup(name, status)
+		}()
+	}
+
+	if a.repo != nil {
+		step(warmPortfolio, func() error {
+			summary, err := a.GetPortfolioSummary()
+			if err != nil {
+				return err
+			}
+			a.warm.put(warmPortfolio, summary)
+			a.warm.put(warmPositions, summary.Positions)
+			if a.alpacaService != nil && len(summary.Positions) > 0 {
+				step("quotes", func() error { return a.warmQuotes(summary.Positions) })
+			}
+			return nil
+		})
+		step(warmPending, func() error {
+			recs, err := a.GetPendingRecommendations()
+			if err != nil {
+				return err
+			}
+			a.warm.put(warmPending, recs)
+			return nil
+		})
+	}
+	if a.screener != nil {
+		step(warmLatestRun, func() error {
+			run, err := a.GetLatestScreenerRun()
+			if err != nil {
+				return err
+			}
+			a.warm.put(warmLatestRun, run)
+			return nil
+		})
+		step(warmTopPicks, func() error {
+			picks, err := a.GetTopPicks()
+			if err != nil {
+				return err
+			}
+			a.warm.put(warmTopPicks, picks)
+			return nil
+		})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		status := "success"
+		if failed > 0 {
+			status = "error"
+		}
+		total.ObserveWarmup("total", status)
+		observability.Info("startup warm-up finished", "duration", total.Duration(), "failed_steps", failed)
+	case <-time.After(timeout):
+		total.ObserveWarmup("total", "timeout")
+		observability.Warn("startup warm-up timed out, continuing in the background", "timeout", timeout)
+	}
+}
+
+// warmQuotes loads quotes for held symbols into the quote cache. It fails only if no
+// quote could be loaded at all.
+func (a *App) warmQuotes(positions []models.Position) error {
+	var lastErr error
+	loaded := 0
+	for i, pos := range positions {
+		if i == warmupQuoteLimit {
+			break
+		}
+		if _, err := a.GetQuote(pos.Symbol); err != nil {
+			lastErr = err
+			continue
+		}
+		loaded++
+	}
+	if loaded == 0 {
+		return lastErr
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// countingScreener counts latest run and pick loads
+type countingScreener struct {
+	mockScreener
+	runLoads  int
+	pickLoads int
+}
+
+func (m *countingScreener) GetLatestRun(ctx context.Context) (*models.ScreenerRun, error) {
+	m.runLoads++
+	return &models.ScreenerRun{}, nil
+}
+
+func (m *countingScreener) GetLatestPicks(ctx context.Context) ([]models.ScreenerCandidate, error) {
+	m.pickLoads++
+	return []models.ScreenerCandidate{{Symbol: "AAPL"}}, nil
+}
+
+func TestApp_Startup_WarmUp(t *testing.T) {
+	cfg := testConfig()
+	cfg.Warmup.Enabled = true
+	screener := &countingScreener{}
+	a := New(cfg, nil, nil, nil)
+	a.SetScreener(screener)
+	a.Startup(context.Background())
+
+	if screener.runLoads != 1 || screener.pickLoads != 1 {
+		t.Fatalf("warm-up loaded run %d and picks %d times, want 1 each", screener.runLoads, screener.pickLoads)
+	}
+
+	// The first reads are served from the warm-up, later ones from the screener
+	if _, err := a.GetLatestScreenerRun(); err != nil {
+		t.Fatalf("GetLatestScreenerRun failed: %v", err)
+	}
+	picks, err := a.GetTopPicks()
+	if err != nil || len(picks) != 1 {
+		t.Fatalf("GetTopPicks = %v, %v; want the warmed pick", picks, err)
+	}
+	if screener.runLoads != 1 || screener.pickLoads != 1 {
+		t.Errorf("first reads hit the screener: run %d, picks %d", screener.runLoads, screener.pickLoads)
+	}
+
+	a.GetLatestScreenerRun()
+	a.GetTopPicks()
+	if screener.runLoads != 2 || screener.pickLoads != 2 {
+		t.Errorf("second reads should hit the screener: run %d, picks %d", screener.runLoads, screener.pickLoads)
+	}
+}
+
+func TestApp_Startup_WarmUpDisabled(t *testing.T) {
+	screener := &countingScreener{}
+	a := testApp(nil)
+	a.SetScreener(screener)
+	a.Startup(context.Background())
+
+	if screener.runLoads != 0 || screener.pickLoads != 0 {
+		t.Errorf("warm-up ran while disabled: run %d, picks %d", screener.runLoads, screener.pickLoads)
+	}
+}
+
+func TestApp_WarmUp_InvalidatedByScreenerRun(t *testing.T) {
+	cfg := testConfig()
+	cfg.Warmup.Enabled = true
+	screener := &countingScreener{}
+	a := New(cfg, nil, nil, nil)
+	a.SetScreener(screener)
+	a.Startup(context.Background())
+
+	if _, err := a.RunScreener(nil); err != nil {
+		t.Fatalf("RunScreener failed: %v", err)
+	}
+	a.GetTopPicks()
+	if screener.pickLoads != 2 {
+		t.Errorf("picks loaded %d times, want the warmed picks dropped after a run", screener.pickLoads)
+	}
+}
+
+// overrideRepo stores a recommendation's overrides
+type overrideRepo struct {
+	recommendationRepo
+}
+
+func (r *overrideRepo) UpdateRecommendationOverride(ctx context.Context, id uuid.UUID, override *models.RecommendationOverride, expectedVersion int) error {
+	r.rec.Override = override
+	return nil
+}
+
+func TestApp_WarmUp_InvalidatedByEdit(t *testing.T) {
+	rec := models.NewRecommendation("AAPL", models.RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+	a := New(testConfig(), &overrideRepo{recommendationRepo{rec: rec}}, nil, nil)
+	a.ctx = context.Background()
+	a.warm.put(warmPending, []models.Recommendation{*rec})
+
+	quantity := decimal.NewFromInt(5)
+	if _, err := a.EditRecommendation(rec.ID.String(), models.RecommendationOverride{Quantity: &quantity}, models.AnyVersion); err != nil {
+		t.Fatalf("EditRecommendation failed: %v", err)
+	}
+	if _, ok := takeWarm[[]models.Recommendation](a, warmPending); ok {
+		t.Error("warmed pending recommendations served after an edit")
+	}
+}
@@ -0,0 +1,395 @@
+package i18n
+
+import (
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is used when no language is set or a key is missing from a catalog
+const DefaultLanguage = "en"
+
+// catalogs maps language code to message key to translated string
+var catalogs = map[string]map[string]string{
+	"en": {
+		"app.tagline":                 "AI-Powered Trading",
+		"nav.picks":                   "Today's Picks",
+		"nav.analyze":                 "Analyze Stock",
+		"nav.recommendations":         "Recommendations",
+		"nav.portfolio":               "Portfolio",
+		"nav.trades":                  "Trades",
+		"nav.agents":                  "Agent Runs",
+		"nav.settings":                "Settings",
+		"picks.title":                 "Today's Value Picks",
+		"picks.subtitle":              "Find undervalued stocks with strong fundamentals",
+		"picks.last_updated":          "Last updated:",
+		"picks.run":                   "Find Value Stocks",
+		"analyze.title":               "Analyze Stock",
+		"analyze.symbol":              "Stock Symbol",
+		"common.loading":              "Loading...",
+		"recommendations.approve":     "Approve",
+		"recommendations.reject":      "Reject",
+		"recommendations.execute":     "Execute",
+		"activity.title":              "Recent Activity",
+		"analyze.intro":               "Enter a stock symbol to run AI-powered analysis using fundamental, technical, and sentiment agents. The system will generate a BUY, SELL, or HOLD recommendation with detailed reasoning.",
+		"analyze.submit":              "Analyze",
+		"analyze.analyzing":           "Analyzing...",
+		"analyze.running":             "Running analysis...",
+		"recommendations.pending":     "Pending",
+		"recommendations.all":         "All",
+		"recommendations.empty_title": "No Recommendations Loaded",
+		"recommendations.empty_hint":  "Click 'Pending' or 'All' to load recommendations.",
+		"common.refresh":              "Refresh",
+		"portfolio.as_of":             "Portfolio As Of",
+		"portfolio.reviews":           "Portfolio Reviews",
+		"portfolio.analyze":           "Analyze Holdings",
+		"portfolio.analyzing":         "Analyzing holdings...",
+		"attribution.title":           "Agent Attribution",
+		"attribution.all_time":        "All time",
+		"attribution.last_90_days":    "Last 90 days",
+		"attribution.last_year":       "Last year",
+		"trades.title":                "Recent Trades",
+		"reconciliation.title":        "Broker Reconciliation",
+		"reconciliation.run":          "Reconcile Last Month",
+		"settings.subtitle":           "Configure your API keys and application settings",
+	},
+	"es": {
+		"app.tagline":                 "Trading impulsado por IA",
+		"nav.picks":                   "Selecciones de hoy",
+		"nav.analyze":                 "Analizar acción",
+		"nav.recommendations":         "Recomendaciones",
+		"nav.portfolio":               "Cartera",
+		"nav.trades":                  "Operaciones",
+		"nav.agents":                  "Ejecuciones de agentes",
+		"nav.settings":                "Configuración",
+		"picks.title":                 "Selecciones de valor de hoy",
+		"picks.subtitle":              "Encuentra acciones infravaloradas con fundamentos sólidos",
+		"picks.last_updated":          "Última actualización:",
+		"picks.run":                   "Buscar acciones de valor",
+		"analyze.title":               "Analizar acción",
+		"analyze.symbol":              "Símbolo",
+		"common.loading":              "Cargando...",
+		"recommendations.approve":     "Aprobar",
+		"recommendations.reject":      "Rechazar",
+		"recommendations.execute":     "Ejecutar",
+		"activity.title":              "Actividad reciente",
+		"analyze.intro":               "Introduce el símbolo de una acción para ejecutar un análisis con IA mediante agentes fundamentales, técnicos y de sentimiento. El sistema generará una recomendación de COMPRA, VENTA o MANTENER con un razonamiento detallado.",
+		"analyze.submit":              "Analizar",
+		"analyze.analyzing":           "Analizando...",
+		"analyze.running":             "Ejecutando el análisis...",
+		"recommendations.pending":     "Pendientes",
+		"recommendations.all":         "Todas",
+		"recommendations.empty_title": "No hay recomendaciones cargadas",
+		"recommendations.empty_hint":  "Haz clic en «Pendientes» o «Todas» para cargar las recomendaciones.",
+		"common.refresh":              "Actualizar",
+		"portfolio.as_of":             "Cartera a fecha de",
+		"portfolio.reviews":           "Revisiones de la cartera",
+		"portfolio.analyze":           "Analizar posiciones",
+		"portfolio.analyzing":         "Analizando posiciones...",
+		"attribution.title":           "Atribución por agente",
+		"attribution.all_time":        "Todo el historial",
+		"attribution.last_90_days":    "Últimos 90 días",
+		"attribution.last_year":       "Último año",
+		"trades.title":                "Operaciones recientes",
+		"reconciliation.title":        "Conciliación con el bróker",
+		"reconciliation.run":          "Conciliar el mes pasado",
+		"settings.subtitle":           "Configura tus claves de API y los ajustes de la aplicación",
+	},
+	"fr": {
+		"app.tagline":                 "Trading propulsé par l'IA",
+		"nav.picks":                   "Sélections du jour",
+		"nav.analyze":                 "Analyser une action",
+		"nav.recommendations":         "Recommandations",
+		"nav.portfolio":               "Portefeuille",
+		"nav.trades":                  "Transactions",
+		"nav.agents":                  "Exécutions des agents",
+		"nav.settings":                "Paramètres",
+		"picks.title":                 "Valeurs sélectionnées du jour",
+		"picks.subtitle":              "Trouvez des actions sous-évaluées aux fondamentaux solides",
+		"picks.last_updated":          "Dernière mise à jour :",
+		"picks.run":                   "Rechercher des valeurs",
+		"analyze.title":               "Analyser une action",
+		"analyze.symbol":              "Symbole",
+		"common.loading":              "Chargement...",
+		"recommendations.approve":     "Approuver",
+		"recommendations.reject":      "Rejeter",
+		"recommendations.execute":     "Exécuter",
+		"activity.title":              "Activité récente",
+		"analyze.intro":               "Saisissez le symbole d'une action pour lancer une analyse par IA avec des agents fondamentaux, techniques et de sentiment. Le système produira une recommandation d'ACHAT, de VENTE ou de CONSERVATION avec un raisonnement détaillé.",
+		"analyze.submit":              "Analyser",
+		"analyze.analyzing":           "Analyse en cours...",
+		"analyze.running":             "Exécution de l'analyse...",
+		"recommendations.pending":     "En attente",
+		"recommendations.all":         "Toutes",
+		"recommendations.empty_title": "Aucune recommandation chargée",
+		"recommendations.empty_hint":  "Cliquez sur « En attente » ou « Toutes » pour charger les recommandations.",
+		"common.refresh":              "Actualiser",
+		"portfolio.as_of":             "Portefeuille au",
+		"portfolio.reviews":           "Revues du portefeuille",
+		"portfolio.analyze":           "Analyser les positions",
+		"portfolio.analyzing":         "Analyse des positions...",
+		"attribution.title":           "Attribution par agent",
+		"attribution.all_time":        "Depuis le début",
+		"attribution.last_90_days":    "90 derniers jours",
+		"attribution.last_year":       "Dernière année",
+		"trades.title":                "Transactions récentes",
+		"reconciliation.title":        "Rapprochement avec le courtier",
+		"reconciliation.run":          "Rapprocher le mois dernier",
+		"settings.subtitle":           "Configurez vos clés d'API et les paramètres de l'application",
+	},
+	"de": {
+		"app.tagline":                 "KI-gestützter Handel",
+		"nav.picks":                   "Heutige Auswahl",
+		"nav.analyze":                 "Aktie analysieren",
+		"nav.recommendations":         "Empfehlungen",
+		"nav.portfolio":               "Portfolio",
+		"nav.trades":                  "Trades",
+		"nav.agents":                  "Agentenläufe",
+		"nav.settings":                "Einstellungen",
+		"picks.title":                 "Heutige Value-Auswahl",
+		"picks.subtitle":              "Unterbewertete Aktien mit soliden Fundamentaldaten finden",
+		"picks.last_updated":          "Zuletzt aktualisiert:",
+		"picks.run":                   "Value-Aktien suchen",
+		"analyze.title":               "Aktie analysieren",
+		"analyze.symbol":              "Aktiensymbol",
+		"common.loading":              "Wird geladen...",
+		"recommendations.approve":     "Genehmigen",
+		"recommendations.reject":      "Ablehnen",
+		"recommendations.execute":     "Ausführen",
+		"activity.title":              "Letzte Aktivitäten",
+		"analyze.intro":               "Geben Sie ein Aktiensymbol ein, um eine KI-gestützte Analyse mit Fundamental-, Technik- und Stimmungsagenten zu starten. Das System erstellt eine KAUFEN-, VERKAUFEN- oder HALTEN-Empfehlung mit ausführlicher Begründung.",
+		"analyze.submit":              "Analysieren",
+		"analyze.analyzing":           "Wird analysiert...",
+		"analyze.running":             "Analyse läuft...",
+		"recommendations.pending":     "Ausstehend",
+		"recommendations.all":         "Alle",
+		"recommendations.empty_title": "Keine Empfehlungen geladen",
+		"recommendations.empty_hint":  "Klicken Sie auf „Ausstehend“ oder „Alle“, um Empfehlungen zu laden.",
+		"common.refresh":              "Aktualisieren",
+		"portfolio.as_of":             "Portfolio zum Stichtag",
+		"portfolio.reviews":           "Portfolio-Überprüfungen",
+		"portfolio.analyze":           "Bestände analysieren",
+		"portfolio.analyzing":         "Bestände werden analysiert...",
+		"attribution.title":           "Agenten-Attribution",
+		"attribution.all_time":        "Gesamter Zeitraum",
+		"attribution.last_90_days":    "Letzte 90 Tage",
+		"attribution.last_year":       "Letztes Jahr",
+		"trades.title":                "Letzte Trades",
+		"reconciliation.title":        "Broker-Abgleich",
+		"reconciliation.run":          "Letzten Monat abgleichen",
+		"settings.subtitle":           "Konfigurieren Sie Ihre API-Schlüssel und Anwendungseinstellungen",
+	},
+	"pt": {
+		"app.tagline":                 "Trading com IA",
+		"nav.picks":                   "Seleções de hoje",
+		"nav.analyze":                 "Analisar ação",
+		"nav.recommendations":         "Recomendações",
+		"nav.portfolio":               "Carteira",
+		"nav.trades":                  "Operações",
+		"nav.agents":                  "Execuções de agentes",
+		"nav.settings":                "Configurações",
+		"picks.title":                 "Seleções de valor de hoje",
+		"picks.subtitle":              "Encontre ações subvalorizadas com fundamentos sólidos",
+		"picks.last_updated":          "Última atualização:",
+		"picks.run":                   "Buscar ações de valor",
+		"analyze.title":               "Analisar ação",
+		"analyze.symbol":              "Código da ação",
+		"common.loading":              "Carregando...",
+		"recommendations.approve":     "Aprovar",
+		"recommendations.reject":      "Rejeitar",
+		"recommendations.execute":     "Executar",
+		"activity.title":              "Atividade recente",
+		"analyze.intro":               "Digite o código de uma ação para executar uma análise com IA usando agentes fundamentalistas, técnicos e de sentimento. O sistema gerará uma recomendação de COMPRA, VENDA ou MANTER com justificativa detalhada.",
+		"analyze.submit":              "Analisar",
+		"analyze.analyzing":           "Analisando...",
+		"analyze.running":             "Executando a análise...",
+		"recommendations.pending":     "Pendentes",
+		"recommendations.all":         "Todas",
+		"recommendations.empty_title": "Nenhuma recomendação carregada",
+		"recommendations.empty_hint":  "Clique em “Pendentes” ou “Todas” para carregar as recomendações.",
+		"common.refresh":              "Atualizar",
+		"portfolio.as_of":             "Carteira na data",
+		"portfolio.reviews":           "Revisões da carteira",
+		"portfolio.analyze":           "Analisar posições",
+		"portfolio.analyzing":         "Analisando posições...",
+		"attribution.title":           "Atribuição por agente",
+		"attribution.all_time":        "Todo o período",
+		"attribution.last_90_days":    "Últimos 90 dias",
+		"attribution.last_year":       "Último ano",
+		"trades.title":                "Operações recentes",
+		"reconciliation.title":        "Conciliação com a corretora",
+		"reconciliation.run":          "Conciliar o mês passado",
+		"settings.subtitle":           "Configure suas chaves de API e as configurações do aplicativo",
+	},
+	"it": {
+		"app.tagline":                 "Trading basato sull'IA",
+		"nav.picks":                   "Selezioni di oggi",
+		"nav.analyze":                 "Analizza titolo",
+		"nav.recommendations":         "Raccomandazioni",
+		"nav.portfolio":               "Portafoglio",
+		"nav.trades":                  "Operazioni",
+		"nav.agents":                  "Esecuzioni degli agenti",
+		"nav.settings":                "Impostazioni",
+		"picks.title":                 "Selezioni value di oggi",
+		"picks.subtitle":              "Trova titoli sottovalutati con fondamentali solidi",
+		"picks.last_updated":          "Ultimo aggiornamento:",
+		"picks.run":                   "Cerca titoli value",
+		"analyze.title":               "Analizza titolo",
+		"analyze.symbol":              "Simbolo del titolo",
+		"common.loading":              "Caricamento...",
+		"recommendations.approve":     "Approva",
+		"recommendations.reject":      "Rifiuta",
+		"recommendations.execute":     "Esegui",
+		"activity.title":              "Attività recenti",
+		"analyze.intro":               "Inserisci il simbolo di un titolo per avviare un'analisi basata sull'IA con agenti fondamentali, tecnici e di sentiment. Il sistema genererà una raccomandazione di ACQUISTO, VENDITA o MANTENIMENTO con una motivazione dettagliata.",
+		"analyze.submit":              "Analizza",
+		"analyze.analyzing":           "Analisi in corso...",
+		"analyze.running":             "Analisi in esecuzione...",
+		"recommendations.pending":     "In sospeso",
+		"recommendations.all":         "Tutte",
+		"recommendations.empty_title": "Nessuna raccomandazione caricata",
+		"recommendations.empty_hint":  "Fai clic su «In sospeso» o «Tutte» per caricare le raccomandazioni.",
+		"common.refresh":              "Aggiorna",
+		"portfolio.as_of":             "Portafoglio alla data",
+		"portfolio.reviews":           "Revisioni del portafoglio",
+		"portfolio.analyze":           "Analizza le posizioni",
+		"portfolio.analyzing":         "Analisi delle posizioni...",
+		"attribution.title":           "Attribuzione per agente",
+		"attribution.all_time":        "Da sempre",
+		"attribution.last_90_days":    "Ultimi 90 giorni",
+		"attribution.last_year":       "Ultimo anno",
+		"trades.title":                "Operazioni recenti",
+		"reconciliation.title":        "Riconciliazione con il broker",
+		"reconciliation.run":          "Riconcilia il mese scorso",
+		"settings.subtitle":           "Configura le chiavi API e le impostazioni dell'applicazione",
+	},
+	"ja": {
+		"app.tagline":                 "AIを活用した取引",
+		"nav.picks":                   "本日の注目銘柄",
+		"nav.analyze":                 "銘柄を分析",
+		"nav.recommendations":         "推奨",
+		"nav.portfolio":               "ポートフォリオ",
+		"nav.trades":                  "取引",
+		"nav.agents":                  "エージェント実行履歴",
+		"nav.settings":                "設定",
+		"picks.title":                 "本日のバリュー銘柄",
+		"picks.subtitle":              "堅実なファンダメンタルズを持つ割安株を探す",
+		"picks.last_updated":          "最終更新:",
+		"picks.run":                   "バリュー株を探す",
+		"analyze.title":               "銘柄を分析",
+		"analyze.symbol":              "銘柄コード",
+		"common.loading":              "読み込み中...",
+		"recommendations.approve":     "承認",
+		"recommendations.reject":      "却下",
+		"recommendations.execute":     "実行",
+		"activity.title":              "最近のアクティビティ",
+		"analyze.intro":               "銘柄コードを入力すると、ファンダメンタル・テクニカル・センチメントの各エージェントによるAI分析を実行します。詳しい根拠とともに買い・売り・保有の推奨を生成します。",
+		"analyze.submit":              "分析",
+		"analyze.analyzing":           "分析中...",
+		"analyze.running":             "分析を実行中...",
+		"recommendations.pending":     "保留中",
+		"recommendations.all":         "すべて",
+		"recommendations.empty_title": "推奨が読み込まれていません",
+		"recommendations.empty_hint":  "「保留中」または「すべて」をクリックして推奨を読み込みます。",
+		"common.refresh":              "更新",
+		"portfolio.as_of":             "指定日のポートフォリオ",
+		"portfolio.reviews":           "ポートフォリオレビュー",
+		"portfolio.analyze":           "保有銘柄を分析",
+		"portfolio.analyzing":         "保有銘柄を分析中...",
+		"attribution.title":           "エージェント別寄与",
+		"attribution.all_time":        "全期間",
+		"attribution.last_90_days":    "過去90日",
+		"attribution.last_year":       "過去1年",
+		"trades.title":                "最近の取引",
+		"reconciliation.title":        "ブローカー照合",
+		"reconciliation.run":          "先月を照合",
+		"settings.subtitle":           "APIキーとアプリケーション設定を構成します",
+	},
+	"zh": {
+		"app.tagline":                 "AI 驱动的交易",
+		"nav.picks":                   "今日精选",
+		"nav.analyze":                 "分析股票",
+		"nav.recommendations":         "推荐",
+		"nav.portfolio":               "投资组合",
+		"nav.trades":                  "交易",
+		"nav.agents":                  "代理运行记录",
+		"nav.settings":                "设置",
+		"picks.title":                 "今日价值精选",
+		"picks.subtitle":              "寻找基本面稳健的低估股票",
+		"picks.last_updated":          "最后更新:",
+		"picks.run":                   "查找价值股",
+		"analyze.title":               "分析股票",
+		"analyze.symbol":              "股票代码",
+		"common.loading":              "加载中...",
+		"recommendations.approve":     "批准",
+		"recommendations.reject":      "拒绝",
+		"recommendations.execute":     "执行",
+		"activity.title":              "最近动态",
+		"analyze.intro":               "输入股票代码，使用基本面、技术面和情绪分析代理运行 AI 分析。系统将生成附有详细理由的买入、卖出或持有建议。",
+		"analyze.submit":              "分析",
+		"analyze.analyzing":           "分析中...",
+		"analyze.running":             "正在运行分析...",
+		"recommendations.pending":     "待处理",
+		"recommendations.all":         "全部",
+		"recommendations.empty_title": "未加载建议",
+		"recommendations.empty_hint":  "点击“待处理”或“全部”加载建议。",
+		"common.refresh":              "刷新",
+		"portfolio.as_of":             "指定日期的投资组合",
+		"portfolio.reviews":           "投资组合评估",
+		"portfolio.analyze":           "分析持仓",
+		"portfolio.analyzing":         "正在分析持仓...",
+		"attribution.title":           "代理归因",
+		"attribution.all_time":        "全部时间",
+		"attribution.last_90_days":    "最近 90 天",
+		"attribution.last_year":       "最近一年",
+		"trades.title":                "最近交易",
+		"reconciliation.title":        "券商对账",
+		"reconciliation.run":          "对账上月",
+		"settings.subtitle":           "配置 API 密钥和应用设置",
+	},
+}
+
+var (
+	mu      sync.RWMutex
+	current = DefaultLanguage
+)
+
+// SetLanguage sets the active UI language. Unsupported codes fall back to English.
+func SetLanguage(code string) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if !IsSupported(code) {
+		code = DefaultLanguage
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	current = code
+}
+
+// Language returns the active UI language code
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// IsSupported reports whether a message catalog exists for the language code
+func IsSupported(code string) bool {
+	_, ok := catalogs[code]
+	return ok
+}
+
+// T translates a message key into the active language.
+// Missing keys fall back to English, then to the key itself.
+func T(key string) string {
+	return Translate(Language(), key)
+}
+
+// Translate translates a message key into the given language
+func Translate(code, key string) string {
+	if msg, ok := catalogs[code][key]; ok {
+		return msg
+	}
+	if msg, ok := catalogs[DefaultLanguage][key]; ok {
+		return msg
+	}
+	return key
+}
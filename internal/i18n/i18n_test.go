@@ -0,0 +1,61 @@
+package i18n
+
+import "testing"
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		key  string
+		want string
+	}{
+		{"english", "en", "nav.portfolio", "Portfolio"},
+		{"spanish", "es", "nav.settings", "Configuración"},
+		{"unknown language falls back to english", "xx", "nav.trades", "Trades"},
+		{"unknown key returns key", "es", "missing.key", "missing.key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Translate(tt.code, tt.key); got != tt.want {
+				t.Errorf("Translate(%q, %q) = %q, want %q", tt.code, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+
+	SetLanguage("FR")
+	if got := Language(); got != "fr" {
+		t.Errorf("Language() = %q, want fr", got)
+	}
+	if got := T("nav.settings"); got != "Paramètres" {
+		t.Errorf("T(nav.settings) = %q, want Paramètres", got)
+	}
+
+	SetLanguage("klingon")
+	if got := Language(); got != DefaultLanguage {
+		t.Errorf("Language() = %q, want %q for unsupported code", got, DefaultLanguage)
+	}
+}
+
+func TestPromptLanguagesHaveCatalogs(t *testing.T) {
+	// Every language agents can be prompted in must also have a UI catalog
+	for _, code := range []string{"en", "es", "fr", "de", "pt", "it", "ja", "zh"} {
+		if !IsSupported(code) {
+			t.Errorf("no UI catalog for prompt language %q", code)
+		}
+	}
+}
+
+func TestCatalogsHaveAllEnglishKeys(t *testing.T) {
+	for code, catalog := range catalogs {
+		for key := range catalogs[DefaultLanguage] {
+			if _, ok := catalog[key]; !ok {
+				t.Errorf("catalog %q is missing key %q", code, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// brokerKey is the app setting a broker chosen at runtime is stored under
+const brokerKey = "broker"
+
+// Broker is the broker chosen from the API, which replaces BROKER until changed again
+type Broker struct {
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Broker returns the broker chosen from the API, or nil if it was never changed
+func (s *Store) Broker() *Broker {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.broker == nil {
+		return nil
+	}
+	broker := *s.broker
+	return &broker
+}
+
+// SaveBroker stores a broker chosen from the API
+func (s *Store) SaveBroker(broker Broker) error {
+	data, err := json.Marshal(broker)
+	if err != nil {
+		return fmt.Errorf("failed to marshal broker: %w", err)
+	}
+	if err := s.repo.UpsertAppSetting(s.ctx, brokerKey, data); err != nil {
+		return fmt.Errorf("failed to save broker: %w", err)
+	}
+
+	s.mu.Lock()
+	s.broker = &broker
+	s.mu.Unlock()
+	return nil
+}
+
+// loadBroker reads the broker chosen from the API from the database
+func (s *Store) loadBroker() error {
+	data, err := s.repo.GetAppSetting(s.ctx, brokerKey)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+
+	var broker Broker
+	if err := json.Unmarshal(data, &broker); err != nil {
+		return fmt.Errorf("failed to unmarshal broker: %w", err)
+	}
+	s.broker = &broker
+	return nil
+}
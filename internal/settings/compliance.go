@@ -0,0 +1,63 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// disclaimerAcknowledgmentKey is the app setting the disclaimer acknowledgment is stored under
+const disclaimerAcknowledgmentKey = "disclaimer_acknowledgment"
+
+// DisclaimerAcknowledgment records that the user accepted a version of the disclaimer
+type DisclaimerAcknowledgment struct {
+	Version      string    `json:"version"`
+	Jurisdiction string    `json:"jurisdiction"`
+	AcceptedAt   time.Time `json:"accepted_at"`
+}
+
+// DisclaimerAcknowledgment returns the last accepted disclaimer, or nil if none was accepted
+func (s *Store) DisclaimerAcknowledgment() *DisclaimerAcknowledgment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.acknowledgment == nil {
+		return nil
+	}
+	ack := *s.acknowledgment
+	return &ack
+}
+
+// SaveDisclaimerAcknowledgment records that the user accepted a disclaimer version
+func (s *Store) SaveDisclaimerAcknowledgment(ack DisclaimerAcknowledgment) error {
+	data, err := json.Marshal(ack)
+	if err != nil {
+		return fmt.Errorf("failed to marshal disclaimer acknowledgment: %w", err)
+	}
+	if err := s.repo.UpsertAppSetting(s.ctx, disclaimerAcknowledgmentKey, data); err != nil {
+		return fmt.Errorf("failed to save disclaimer acknowledgment: %w", err)
+	}
+
+	s.mu.Lock()
+	s.acknowledgment = &ack
+	s.mu.Unlock()
+	return nil
+}
+
+// loadDisclaimerAcknowledgment reads the disclaimer acknowledgment from the database
+func (s *Store) loadDisclaimerAcknowledgment() error {
+	data, err := s.repo.GetAppSetting(s.ctx, disclaimerAcknowledgmentKey)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+
+	var ack DisclaimerAcknowledgment
+	if err := json.Unmarshal(data, &ack); err != nil {
+		return fmt.Errorf("failed to unmarshal disclaimer acknowledgment: %w", err)
+	}
+	s.acknowledgment = &ack
+	return nil
+}
@@ -0,0 +1,80 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"trade-machine/notifications"
+)
+
+// notificationsKey is the app setting notification channels are stored under
+const notificationsKey = "notifications"
+
+// encryptedSetting wraps an app setting holding secrets, such as webhook URLs and the
+// SMTP password, so the stored JSON carries only ciphertext
+type encryptedSetting struct {
+	Encrypted []byte `json:"encrypted"`
+}
+
+// Notifications returns the notification channels and events, empty until configured
+func (s *Store) Notifications() notifications.Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cfg := s.notifications
+	if cfg.SMTP != nil {
+		smtp := *cfg.SMTP
+		cfg.SMTP = &smtp
+	}
+	return cfg
+}
+
+// SaveNotifications stores the notification channels and events, encrypted
+func (s *Store) SaveNotifications(cfg notifications.Config) error {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification settings: %w", err)
+	}
+	encrypted, err := s.crypto.Encrypt(data)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt notification settings: %w", err)
+	}
+	data, err = json.Marshal(encryptedSetting{Encrypted: encrypted})
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification settings: %w", err)
+	}
+	if err := s.repo.UpsertAppSetting(s.ctx, notificationsKey, data); err != nil {
+		return fmt.Errorf("failed to save notification settings: %w", err)
+	}
+
+	s.mu.Lock()
+	s.notifications = cfg
+	s.mu.Unlock()
+	return nil
+}
+
+// loadNotifications reads the notification settings from the database
+func (s *Store) loadNotifications() error {
+	data, err := s.repo.GetAppSetting(s.ctx, notificationsKey)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+
+	var setting encryptedSetting
+	if err := json.Unmarshal(data, &setting); err != nil {
+		return fmt.Errorf("failed to unmarshal notification settings: %w", err)
+	}
+	decrypted, err := s.crypto.Decrypt(setting.Encrypted)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt notification settings: %w", err)
+	}
+	var cfg notifications.Config
+	if err := json.Unmarshal(decrypted, &cfg); err != nil {
+		return fmt.Errorf("failed to unmarshal notification settings: %w", err)
+	}
+	s.notifications = cfg
+	return nil
+}
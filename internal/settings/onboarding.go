@@ -0,0 +1,129 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// onboardingKey is the app setting the onboarding progress is stored under
+const onboardingKey = "onboarding"
+
+// OnboardingStep is one step of the first-run setup wizard
+type OnboardingStep string
+
+const (
+	OnboardingStepKeys     OnboardingStep = "keys"     // Enter API keys
+	OnboardingStepValidate OnboardingStep = "validate" // Check the entered keys against each provider
+	OnboardingStepStrategy OnboardingStep = "strategy" // Choose an action strategy
+	OnboardingStepDemo     OnboardingStep = "demo"     // Run a demo analysis on sample data
+)
+
+// OnboardingSteps lists the wizard steps in order
+var OnboardingSteps = []OnboardingStep{
+	OnboardingStepKeys,
+	OnboardingStepValidate,
+	OnboardingStepStrategy,
+	OnboardingStepDemo,
+}
+
+// IsValid reports whether s is a known onboarding step
+func (s OnboardingStep) IsValid() bool {
+	return slices.Contains(OnboardingSteps, s)
+}
+
+// OnboardingState tracks progress through the first-run wizard
+type OnboardingState struct {
+	CompletedSteps []OnboardingStep `json:"completed_steps"`
+	Strategy       string           `json:"strategy,omitempty"` // Action strategy chosen in the strategy step
+	Skipped        bool             `json:"skipped,omitempty"`  // The user dismissed the wizard
+	CompletedAt    *time.Time       `json:"completed_at,omitempty"`
+}
+
+// StepCompleted reports whether step has been completed
+func (s OnboardingState) StepCompleted(step OnboardingStep) bool {
+	return slices.Contains(s.CompletedSteps, step)
+}
+
+// NextStep returns the first step not yet completed, or "" when the wizard is finished
+func (s OnboardingState) NextStep() OnboardingStep {
+	if s.Finished() {
+		return ""
+	}
+	for _, step := range OnboardingSteps {
+		if !s.StepCompleted(step) {
+			return step
+		}
+	}
+	return ""
+}
+
+// Finished reports whether the wizard was completed or skipped
+func (s OnboardingState) Finished() bool {
+	return s.CompletedAt != nil
+}
+
+// CompleteStep marks step as completed, finishing the wizard once every step is done
+func (s *OnboardingState) CompleteStep(step OnboardingStep) {
+	if !s.StepCompleted(step) {
+		s.CompletedSteps = append(s.CompletedSteps, step)
+	}
+	if s.CompletedAt == nil && !slices.ContainsFunc(OnboardingSteps, func(st OnboardingStep) bool { return !s.StepCompleted(st) }) {
+		now := time.Now()
+		s.CompletedAt = &now
+	}
+}
+
+// Skip dismisses the wizard without completing the remaining steps
+func (s *OnboardingState) Skip() {
+	s.Skipped = true
+	if s.CompletedAt == nil {
+		now := time.Now()
+		s.CompletedAt = &now
+	}
+}
+
+// Onboarding returns the current onboarding progress
+func (s *Store) Onboarding() OnboardingState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state := s.onboarding
+	state.CompletedSteps = slices.Clone(state.CompletedSteps)
+	return state
+}
+
+// SaveOnboarding stores the onboarding progress
+func (s *Store) SaveOnboarding(state OnboardingState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal onboarding state: %w", err)
+	}
+	if err := s.repo.UpsertAppSetting(s.ctx, onboardingKey, data); err != nil {
+		return fmt.Errorf("failed to save onboarding state: %w", err)
+	}
+
+	s.mu.Lock()
+	s.onboarding = state
+	s.mu.Unlock()
+	return nil
+}
+
+// loadOnboarding reads the onboarding progress from the database
+func (s *Store) loadOnboarding() error {
+	data, err := s.repo.GetAppSetting(s.ctx, onboardingKey)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+
+	var state OnboardingState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to unmarshal onboarding state: %w", err)
+	}
+	s.onboarding = state
+	return nil
+}
@@ -0,0 +1,141 @@
+package settings
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"trade-machine/notifications"
+)
+
+func TestOnboardingState_Steps(t *testing.T) {
+	var state OnboardingState
+	if state.NextStep() != OnboardingStepKeys {
+		t.Fatalf("NextStep() = %q, want %q", state.NextStep(), OnboardingStepKeys)
+	}
+
+	state.CompleteStep(OnboardingStepStrategy)
+	state.CompleteStep(OnboardingStepKeys)
+	if state.NextStep() != OnboardingStepValidate {
+		t.Errorf("NextStep() = %q, want %q", state.NextStep(), OnboardingStepValidate)
+	}
+	if state.Finished() {
+		t.Error("Finished() = true with steps remaining")
+	}
+
+	state.CompleteStep(OnboardingStepValidate)
+	state.CompleteStep(OnboardingStepDemo)
+	if !state.Finished() || state.NextStep() != "" {
+		t.Errorf("expected wizard finished once every step is done, got %+v", state)
+	}
+}
+
+func TestOnboardingState_Skip(t *testing.T) {
+	var state OnboardingState
+	state.Skip()
+	if !state.Finished() || !state.Skipped || state.NextStep() != "" {
+		t.Errorf("expected skipped wizard to be finished, got %+v", state)
+	}
+}
+
+func TestStore_Onboarding(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := newMockRepository()
+	store, err := NewStore(tmpDir, "test-passphrase", repo)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	state := store.Onboarding()
+	state.Strategy = "conservative"
+	state.CompleteStep(OnboardingStepKeys)
+	if err := store.SaveOnboarding(state); err != nil {
+		t.Fatalf("SaveOnboarding() error = %v", err)
+	}
+
+	// A new store loads the saved progress from the database
+	reloaded, err := NewStore(tmpDir, "test-passphrase", repo)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	got := reloaded.Onboarding()
+	if got.Strategy != "conservative" || !got.StepCompleted(OnboardingStepKeys) {
+		t.Errorf("Onboarding() = %+v, want the saved progress", got)
+	}
+}
+
+func TestStore_DisclaimerAcknowledgment(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := newMockRepository()
+	store, err := NewStore(tmpDir, "test-passphrase", repo)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if store.DisclaimerAcknowledgment() != nil {
+		t.Fatal("expected no acknowledgment before the disclaimer is accepted")
+	}
+
+	ack := DisclaimerAcknowledgment{Version: "abc123", Jurisdiction: "us", AcceptedAt: time.Now()}
+	if err := store.SaveDisclaimerAcknowledgment(ack); err != nil {
+		t.Fatalf("SaveDisclaimerAcknowledgment() error = %v", err)
+	}
+
+	reloaded, err := NewStore(tmpDir, "test-passphrase", repo)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	got := reloaded.DisclaimerAcknowledgment()
+	if got == nil || got.Version != "abc123" || got.Jurisdiction != "us" {
+		t.Errorf("DisclaimerAcknowledgment() = %+v, want the saved acknowledgment", got)
+	}
+}
+
+func TestStore_ScreenerSchedule(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := newMockRepository()
+	store, err := NewStore(tmpDir, "test-passphrase", repo)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if store.ScreenerSchedule() != nil {
+		t.Fatal("expected no schedule before one is set")
+	}
+
+	if err := store.SaveScreenerSchedule(ScreenerSchedule{Cron: "0 8 * * 1-5", UpdatedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveScreenerSchedule() error = %v", err)
+	}
+
+	reloaded, err := NewStore(tmpDir, "test-passphrase", repo)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	got := reloaded.ScreenerSchedule()
+	if got == nil || got.Cron != "0 8 * * 1-5" || got.Analyze {
+		t.Errorf("ScreenerSchedule() = %+v, want the saved schedule", got)
+	}
+}
+
+func TestStore_Notifications(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := newMockRepository()
+	store, err := NewStore(tmpDir, "test-passphrase", repo)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	webhook := "https://hooks.slack.com/services/T/B/secret"
+	if err := store.SaveNotifications(notifications.Config{SlackWebhookURL: webhook}); err != nil {
+		t.Fatalf("SaveNotifications() error = %v", err)
+	}
+	if strings.Contains(string(repo.appSettings[notificationsKey]), "secret") {
+		t.Error("expected the webhook URL to be stored encrypted")
+	}
+
+	reloaded, err := NewStore(tmpDir, "test-passphrase", repo)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if got := reloaded.Notifications(); got.SlackWebhookURL != webhook {
+		t.Errorf("Notifications() = %+v, want the saved webhook", got)
+	}
+}
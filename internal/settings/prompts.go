@@ -0,0 +1,157 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// agentPromptsKey is the app setting customized agent system prompts are stored under
+const agentPromptsKey = "agent_prompts"
+
+const (
+	// maxPromptVersions is how many saved versions of an agent's prompt are kept
+	maxPromptVersions = 20
+	// maxPromptLength bounds a customized system prompt, in characters
+	maxPromptLength = 20000
+)
+
+var (
+	// ErrInvalidPrompt is returned for an empty or overlong system prompt
+	ErrInvalidPrompt = errors.New("invalid prompt")
+	// ErrPromptVersionNotFound is returned when activating a version that was not saved
+	ErrPromptVersionNotFound = errors.New("prompt version not found")
+)
+
+// PromptVersion is one saved version of an agent's system prompt
+type PromptVersion struct {
+	Version   int       `json:"version"`
+	Prompt    string    `json:"prompt"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AgentPrompt is an agent's saved system prompts, oldest first, and the one in use.
+// ActiveVersion 0 is the agent's built-in prompt.
+type AgentPrompt struct {
+	ActiveVersion int             `json:"active_version"`
+	Versions      []PromptVersion `json:"versions"`
+	UpdatedAt     time.Time       `json:"updated_at,omitempty"`
+}
+
+// Active returns the prompt in use and its version, or "" and 0 for the built-in prompt
+func (p AgentPrompt) Active() (string, int) {
+	for _, v := range p.Versions {
+		if v.Version == p.ActiveVersion {
+			return v.Prompt, v.Version
+		}
+	}
+	return "", 0
+}
+
+// AgentPrompt returns an agent's saved system prompts, empty until one is saved
+func (s *Store) AgentPrompt(agent string) AgentPrompt {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prompt := s.agentPrompts[agent]
+	prompt.Versions = slices.Clone(prompt.Versions)
+	return prompt
+}
+
+// ActivePrompt returns the system prompt an agent should use and its version, or "" and 0
+// when it should use its built-in prompt
+func (s *Store) ActivePrompt(agent string) (string, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.agentPrompts[agent].Active()
+}
+
+// SaveAgentPrompt stores prompt as a new version of an agent's system prompt and makes it
+// the one in use. Only the latest maxPromptVersions versions are kept.
+func (s *Store) SaveAgentPrompt(agent, prompt string) (AgentPrompt, error) {
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return AgentPrompt{}, fmt.Errorf("%w: prompt is required", ErrInvalidPrompt)
+	}
+	if n := len([]rune(prompt)); n > maxPromptLength {
+		return AgentPrompt{}, fmt.Errorf("%w: %d characters, at most %d", ErrInvalidPrompt, n, maxPromptLength)
+	}
+
+	return s.updateAgentPrompt(agent, func(p *AgentPrompt) error {
+		version := 1
+		if n := len(p.Versions); n > 0 {
+			version = p.Versions[n-1].Version + 1
+		}
+		p.Versions = append(p.Versions, PromptVersion{Version: version, Prompt: prompt, CreatedAt: time.Now()})
+		if n := len(p.Versions); n > maxPromptVersions {
+			p.Versions = p.Versions[n-maxPromptVersions:]
+		}
+		p.ActiveVersion = version
+		return nil
+	})
+}
+
+// ActivateAgentPrompt rolls an agent's system prompt back to a saved version, or to the
+// built-in prompt for version 0. Saved versions are kept either way.
+func (s *Store) ActivateAgentPrompt(agent string, version int) (AgentPrompt, error) {
+	return s.updateAgentPrompt(agent, func(p *AgentPrompt) error {
+		if version != 0 && !slices.ContainsFunc(p.Versions, func(v PromptVersion) bool { return v.Version == version }) {
+			return fmt.Errorf("%w: %s has no version %d", ErrPromptVersionNotFound, agent, version)
+		}
+		p.ActiveVersion = version
+		return nil
+	})
+}
+
+// updateAgentPrompt applies update to a copy of an agent's prompts and saves every
+// agent's prompts
+func (s *Store) updateAgentPrompt(agent string, update func(*AgentPrompt) error) (AgentPrompt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prompt := s.agentPrompts[agent]
+	prompt.Versions = slices.Clone(prompt.Versions)
+	if err := update(&prompt); err != nil {
+		return AgentPrompt{}, err
+	}
+	prompt.UpdatedAt = time.Now()
+
+	prompts := maps.Clone(s.agentPrompts)
+	if prompts == nil {
+		prompts = make(map[string]AgentPrompt)
+	}
+	prompts[agent] = prompt
+	data, err := json.Marshal(prompts)
+	if err != nil {
+		return AgentPrompt{}, fmt.Errorf("failed to marshal agent prompts: %w", err)
+	}
+	if err := s.repo.UpsertAppSetting(s.ctx, agentPromptsKey, data); err != nil {
+		return AgentPrompt{}, fmt.Errorf("failed to save agent prompts: %w", err)
+	}
+
+	s.agentPrompts = prompts
+	prompt.Versions = slices.Clone(prompt.Versions)
+	return prompt, nil
+}
+
+// loadAgentPrompts reads the customized agent prompts from the database
+func (s *Store) loadAgentPrompts() error {
+	data, err := s.repo.GetAppSetting(s.ctx, agentPromptsKey)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+
+	var prompts map[string]AgentPrompt
+	if err := json.Unmarshal(data, &prompts); err != nil {
+		return fmt.Errorf("failed to unmarshal agent prompts: %w", err)
+	}
+	s.agentPrompts = prompts
+	return nil
+}
@@ -0,0 +1,80 @@
+package settings
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStore_AgentPrompts(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := newMockRepository()
+	store, err := NewStore(tmpDir, "test-passphrase", repo)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if prompt, version := store.ActivePrompt("news"); prompt != "" || version != 0 {
+		t.Fatalf("ActivePrompt() = %q, %d, want the built-in prompt before one is saved", prompt, version)
+	}
+
+	for _, prompt := range []string{"  ", strings.Repeat("x", maxPromptLength+1)} {
+		if _, err := store.SaveAgentPrompt("news", prompt); !errors.Is(err, ErrInvalidPrompt) {
+			t.Errorf("SaveAgentPrompt(%d chars) error = %v, want ErrInvalidPrompt", len(prompt), err)
+		}
+	}
+
+	if _, err := store.SaveAgentPrompt("news", "You are a news analyst."); err != nil {
+		t.Fatalf("SaveAgentPrompt() error = %v", err)
+	}
+	saved, err := store.SaveAgentPrompt("news", "You are a skeptical news analyst.")
+	if err != nil {
+		t.Fatalf("SaveAgentPrompt() error = %v", err)
+	}
+	if saved.ActiveVersion != 2 || len(saved.Versions) != 2 {
+		t.Errorf("SaveAgentPrompt() = %+v, want version 2 active of two", saved)
+	}
+
+	if _, err := store.ActivateAgentPrompt("news", 7); !errors.Is(err, ErrPromptVersionNotFound) {
+		t.Errorf("ActivateAgentPrompt(7) error = %v, want ErrPromptVersionNotFound", err)
+	}
+	if _, err := store.ActivateAgentPrompt("news", 1); err != nil {
+		t.Fatalf("ActivateAgentPrompt(1) error = %v", err)
+	}
+
+	reloaded, err := NewStore(tmpDir, "test-passphrase", repo)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if prompt, version := reloaded.ActivePrompt("news"); prompt != "You are a news analyst." || version != 1 {
+		t.Errorf("ActivePrompt() = %q, %d, want the rolled back version 1", prompt, version)
+	}
+	if prompt, _ := reloaded.ActivePrompt("technical"); prompt != "" {
+		t.Errorf("ActivePrompt(technical) = %q, want the built-in prompt", prompt)
+	}
+
+	if _, err := reloaded.ActivateAgentPrompt("news", 0); err != nil {
+		t.Fatalf("ActivateAgentPrompt(0) error = %v", err)
+	}
+	if prompt, version := reloaded.ActivePrompt("news"); prompt != "" || version != 0 {
+		t.Errorf("ActivePrompt() = %q, %d, want the built-in prompt after a reset", prompt, version)
+	}
+	if got := reloaded.AgentPrompt("news"); len(got.Versions) != 2 {
+		t.Errorf("AgentPrompt() = %+v, want the saved versions kept after a reset", got)
+	}
+}
+
+func TestStore_AgentPrompts_KeepsLatestVersions(t *testing.T) {
+	store, err := NewStore(t.TempDir(), "test-passphrase", newMockRepository())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	for i := 0; i < maxPromptVersions+3; i++ {
+		if _, err := store.SaveAgentPrompt("fundamental", "prompt"); err != nil {
+			t.Fatalf("SaveAgentPrompt() error = %v", err)
+		}
+	}
+	got := store.AgentPrompt("fundamental")
+	if len(got.Versions) != maxPromptVersions || got.Versions[0].Version != 4 || got.ActiveVersion != maxPromptVersions+3 {
+		t.Errorf("AgentPrompt() kept versions %d..%d, active %d", got.Versions[0].Version, got.Versions[len(got.Versions)-1].Version, got.ActiveVersion)
+	}
+}
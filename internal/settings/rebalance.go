@@ -0,0 +1,69 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+)
+
+// rebalanceTargetsKey is the app setting rebalance targets set at runtime are stored under
+const rebalanceTargetsKey = "rebalance_targets"
+
+// RebalanceTargets are target weights set from the API, which replace
+// REBALANCE_POSITION_TARGETS and REBALANCE_SECTOR_TARGETS until changed again
+type RebalanceTargets struct {
+	Positions map[string]float64 `json:"positions,omitempty"`
+	Sectors   map[string]float64 `json:"sectors,omitempty"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// RebalanceTargets returns the targets set from the API, or nil if they were never changed
+func (s *Store) RebalanceTargets() *RebalanceTargets {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.rebalanceTargets == nil {
+		return nil
+	}
+	targets := *s.rebalanceTargets
+	targets.Positions = maps.Clone(s.rebalanceTargets.Positions)
+	targets.Sectors = maps.Clone(s.rebalanceTargets.Sectors)
+	return &targets
+}
+
+// SaveRebalanceTargets stores targets set from the API
+func (s *Store) SaveRebalanceTargets(targets RebalanceTargets) error {
+	data, err := json.Marshal(targets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rebalance targets: %w", err)
+	}
+	if err := s.repo.UpsertAppSetting(s.ctx, rebalanceTargetsKey, data); err != nil {
+		return fmt.Errorf("failed to save rebalance targets: %w", err)
+	}
+
+	targets.Positions = maps.Clone(targets.Positions)
+	targets.Sectors = maps.Clone(targets.Sectors)
+	s.mu.Lock()
+	s.rebalanceTargets = &targets
+	s.mu.Unlock()
+	return nil
+}
+
+// loadRebalanceTargets reads the targets set from the API from the database
+func (s *Store) loadRebalanceTargets() error {
+	data, err := s.repo.GetAppSetting(s.ctx, rebalanceTargetsKey)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+
+	var targets RebalanceTargets
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return fmt.Errorf("failed to unmarshal rebalance targets: %w", err)
+	}
+	s.rebalanceTargets = &targets
+	return nil
+}
@@ -0,0 +1,64 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// screenerScheduleKey is the app setting a schedule changed at runtime is stored under
+const screenerScheduleKey = "screener_schedule"
+
+// ScreenerSchedule is a screener schedule set from the API, which replaces SCREENER_SCHEDULE
+// and SCREENER_SCHEDULE_ANALYZE until changed again
+type ScreenerSchedule struct {
+	Cron      string    `json:"cron"`
+	Analyze   bool      `json:"analyze"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ScreenerSchedule returns the schedule set from the API, or nil if it was never changed
+func (s *Store) ScreenerSchedule() *ScreenerSchedule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.screenerSchedule == nil {
+		return nil
+	}
+	schedule := *s.screenerSchedule
+	return &schedule
+}
+
+// SaveScreenerSchedule stores a schedule set from the API
+func (s *Store) SaveScreenerSchedule(schedule ScreenerSchedule) error {
+	data, err := json.Marshal(schedule)
+	if err != nil {
+		return fmt.Errorf("failed to marshal screener schedule: %w", err)
+	}
+	if err := s.repo.UpsertAppSetting(s.ctx, screenerScheduleKey, data); err != nil {
+		return fmt.Errorf("failed to save screener schedule: %w", err)
+	}
+
+	s.mu.Lock()
+	s.screenerSchedule = &schedule
+	s.mu.Unlock()
+	return nil
+}
+
+// loadScreenerSchedule reads the schedule set from the API from the database
+func (s *Store) loadScreenerSchedule() error {
+	data, err := s.repo.GetAppSetting(s.ctx, screenerScheduleKey)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+
+	var schedule ScreenerSchedule
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return fmt.Errorf("failed to unmarshal screener schedule: %w", err)
+	}
+	s.screenerSchedule = &schedule
+	return nil
+}
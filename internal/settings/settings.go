@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"sync"
 
+	"trade-machine/notifications"
+
 	"github.com/google/uuid"
 )
 
@@ -17,10 +19,16 @@ type ServiceName string
 
 const (
 	ServiceOpenAI       ServiceName = "openai"
+	ServiceAnthropic    ServiceName = "anthropic"
 	ServiceAlpaca       ServiceName = "alpaca"
 	ServiceAlphaVantage ServiceName = "alpha_vantage"
 	ServiceNewsAPI      ServiceName = "newsapi"
 	ServiceFMP          ServiceName = "fmp"
+	// Local Ollama server: BaseURL and ModelID, no key
+	ServiceOllama ServiceName = "ollama"
+	// S3-compatible bucket for database backups: APIKey and APISecret are the access key
+	// and secret key, BaseURL the path-style bucket URL, and Region the signing region
+	ServiceBackupStorage ServiceName = "backup_storage"
 )
 
 // APIKeyConfig represents configuration for a single API key
@@ -33,6 +41,15 @@ type APIKeyConfig struct {
 	ModelID     string      `json:"model_id,omitempty"`   // For AI services
 }
 
+// HasCredentials reports whether the config can be used to reach its service: an API
+// key, or for Ollama, which takes no key, a base URL
+func (c *APIKeyConfig) HasCredentials() bool {
+	if c.ServiceName == ServiceOllama {
+		return c.BaseURL != ""
+	}
+	return c.APIKey != ""
+}
+
 // Settings holds all user-configurable settings
 type Settings struct {
 	APIKeys map[ServiceName]*APIKeyConfig `json:"api_keys"`
@@ -55,6 +72,8 @@ type RepositoryInterface interface {
 	GetAllAPIKeys(ctx context.Context) ([]APIKeyModel, error)
 	UpsertAPIKey(ctx context.Context, apiKey *APIKeyModel) error
 	DeleteAPIKey(ctx context.Context, serviceName string) error
+	GetAppSetting(ctx context.Context, key string) ([]byte, error)
+	UpsertAppSetting(ctx context.Context, key string, value []byte) error
 }
 
 // APIKeyModel represents the database model for API keys
@@ -73,10 +92,23 @@ type Store struct {
 	mu         sync.RWMutex
 	filePath   string
 	settings   *Settings
-	crypto     *Crypto
-	passphrase string
-	repo       RepositoryInterface
-	ctx        context.Context
+	onboarding OnboardingState
+	// Last accepted disclaimer; nil until the user accepts one
+	acknowledgment *DisclaimerAcknowledgment
+	// Schedule set from the API; nil until changed there
+	screenerSchedule *ScreenerSchedule
+	// Broker chosen from the API; nil until changed there
+	broker *Broker
+	// Rebalance targets set from the API; nil until changed there
+	rebalanceTargets *RebalanceTargets
+	// Customized agent system prompts by agent type; empty until one is saved
+	agentPrompts map[string]AgentPrompt
+	// Notification channels; empty until configured
+	notifications notifications.Config
+	crypto        *Crypto
+	passphrase    string
+	repo          RepositoryInterface
+	ctx           context.Context
 }
 
 // NewStore creates a new settings store
@@ -121,6 +153,28 @@ func NewStore(dataDir string, passphrase string, repo RepositoryInterface) (*Sto
 		}
 	}
 
+	if err := store.loadOnboarding(); err != nil {
+		fmt.Printf("warning: failed to load onboarding state: %v\n", err)
+	}
+	if err := store.loadDisclaimerAcknowledgment(); err != nil {
+		fmt.Printf("warning: failed to load disclaimer acknowledgment: %v\n", err)
+	}
+	if err := store.loadScreenerSchedule(); err != nil {
+		fmt.Printf("warning: failed to load screener schedule: %v\n", err)
+	}
+	if err := store.loadRebalanceTargets(); err != nil {
+		fmt.Printf("warning: failed to load rebalance targets: %v\n", err)
+	}
+	if err := store.loadBroker(); err != nil {
+		fmt.Printf("warning: failed to load broker: %v\n", err)
+	}
+	if err := store.loadNotifications(); err != nil {
+		fmt.Printf("warning: failed to load notification settings: %v\n", err)
+	}
+	if err := store.loadAgentPrompts(); err != nil {
+		fmt.Printf("warning: failed to load agent prompts: %v\n", err)
+	}
+
 	return store, nil
 }
 
@@ -328,7 +382,7 @@ func (s *Store) GetMaskedSettings() map[ServiceName]*MaskedAPIKeyConfig {
 	result := make(map[ServiceName]*MaskedAPIKeyConfig)
 
 	// Include all known services
-	for _, service := range []ServiceName{ServiceOpenAI, ServiceAlpaca, ServiceAlphaVantage, ServiceNewsAPI, ServiceFMP} {
+	for _, service := range []ServiceName{ServiceOpenAI, ServiceAnthropic, ServiceOllama, ServiceAlpaca, ServiceAlphaVantage, ServiceNewsAPI, ServiceFMP, ServiceBackupStorage} {
 		masked := &MaskedAPIKeyConfig{
 			ServiceName:  service,
 			IsConfigured: false,
@@ -340,7 +394,7 @@ func (s *Store) GetMaskedSettings() map[ServiceName]*MaskedAPIKeyConfig {
 			masked.BaseURL = config.BaseURL
 			masked.Region = config.Region
 			masked.ModelID = config.ModelID
-			masked.IsConfigured = config.APIKey != "" || config.APISecret != ""
+			masked.IsConfigured = config.HasCredentials() || config.APISecret != ""
 		}
 
 		result[service] = masked
@@ -359,7 +413,37 @@ func (s *Store) IsConfigured(service ServiceName) bool {
 		return false
 	}
 
-	return config.APIKey != ""
+	return config.HasCredentials()
+}
+
+type apiKeysContextKey struct{}
+
+// WithAPIKeys returns a context carrying a user's own API keys. In server mode these
+// take precedence over the shared settings when clients are resolved for a request.
+func WithAPIKeys(ctx context.Context, keys map[ServiceName]*APIKeyConfig) context.Context {
+	return context.WithValue(ctx, apiKeysContextKey{}, keys)
+}
+
+// APIKeysFromContext returns the API keys carried by ctx, if any
+func APIKeysFromContext(ctx context.Context) map[ServiceName]*APIKeyConfig {
+	keys, _ := ctx.Value(apiKeysContextKey{}).(map[ServiceName]*APIKeyConfig)
+	return keys
+}
+
+// Resolve returns the API key config for a service in ctx: keys carried by the
+// context first, then the stored settings. Returns nil when neither has a key.
+func (s *Store) Resolve(ctx context.Context, service ServiceName) *APIKeyConfig {
+	if config, ok := APIKeysFromContext(ctx)[service]; ok && config.HasCredentials() {
+		configCopy := *config
+		return &configCopy
+	}
+	if s == nil {
+		return nil
+	}
+	if config := s.GetAPIKey(service); config != nil && config.HasCredentials() {
+		return config
+	}
+	return nil
 }
 
 // maskString masks a string showing only last 4 characters
@@ -400,6 +484,10 @@ func ServiceDisplayName(service ServiceName) string {
 	switch service {
 	case ServiceOpenAI:
 		return "OpenAI"
+	case ServiceAnthropic:
+		return "Anthropic"
+	case ServiceOllama:
+		return "Ollama"
 	case ServiceAlpaca:
 		return "Alpaca Markets"
 	case ServiceAlphaVantage:
@@ -408,6 +496,8 @@ func ServiceDisplayName(service ServiceName) string {
 		return "NewsAPI"
 	case ServiceFMP:
 		return "Financial Modeling Prep"
+	case ServiceBackupStorage:
+		return "Backup Storage"
 	default:
 		return string(service)
 	}
@@ -418,6 +508,10 @@ func ServiceDescription(service ServiceName) string {
 	switch service {
 	case ServiceOpenAI:
 		return "AI model for stock analysis and recommendations"
+	case ServiceAnthropic:
+		return "Claude models for analysis, used when OpenAI has no key or LLM_PROVIDER=anthropic"
+	case ServiceOllama:
+		return "Local models for offline analysis, used when no cloud LLM has a key or LLM_PROVIDER=ollama"
 	case ServiceAlpaca:
 		return "Market data and paper/live trading"
 	case ServiceAlphaVantage:
@@ -426,6 +520,8 @@ func ServiceDescription(service ServiceName) string {
 		return "News articles for sentiment analysis"
 	case ServiceFMP:
 		return "Stock screening and additional fundamentals"
+	case ServiceBackupStorage:
+		return "S3-compatible bucket for encrypted database backups"
 	default:
 		return ""
 	}
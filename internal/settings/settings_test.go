@@ -1,6 +1,7 @@
 package settings
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -181,8 +182,8 @@ func TestGetMaskedSettings(t *testing.T) {
 	masked := store.GetMaskedSettings()
 
 	// Should have all services
-	if len(masked) != 5 {
-		t.Errorf("GetMaskedSettings() returned %d services, want 5", len(masked))
+	if len(masked) != 8 {
+		t.Errorf("GetMaskedSettings() returned %d services, want 8", len(masked))
 	}
 
 	// OpenAI should be configured and masked
@@ -251,6 +252,54 @@ func TestIsConfigured(t *testing.T) {
 	}
 }
 
+func TestResolve(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewStore(tmpDir, "test-passphrase", newMockRepository())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	store.SetAPIKey(&APIKeyConfig{ServiceName: ServiceFMP, APIKey: "shared-key"})
+
+	ctx := context.Background()
+	if got := store.Resolve(ctx, ServiceFMP); got == nil || got.APIKey != "shared-key" {
+		t.Errorf("Resolve() = %+v, want the stored key", got)
+	}
+	if got := store.Resolve(ctx, ServiceNewsAPI); got != nil {
+		t.Errorf("Resolve() = %+v for unconfigured service, want nil", got)
+	}
+
+	userCtx := WithAPIKeys(ctx, map[ServiceName]*APIKeyConfig{
+		ServiceFMP: {ServiceName: ServiceFMP, APIKey: "user-key"},
+	})
+	if got := store.Resolve(userCtx, ServiceFMP); got == nil || got.APIKey != "user-key" {
+		t.Errorf("Resolve() = %+v, want the context key to take precedence", got)
+	}
+
+	var noStore *Store
+	if got := noStore.Resolve(userCtx, ServiceFMP); got == nil || got.APIKey != "user-key" {
+		t.Errorf("Resolve() on nil store = %+v, want the context key", got)
+	}
+}
+
+func TestResolve_Ollama(t *testing.T) {
+	store, err := NewStore(t.TempDir(), "test-passphrase", newMockRepository())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	store.SetAPIKey(&APIKeyConfig{ServiceName: ServiceOllama, ModelID: "llama3.1"})
+	if got := store.Resolve(context.Background(), ServiceOllama); got != nil {
+		t.Errorf("Resolve() = %+v without a base URL, want nil", got)
+	}
+
+	store.SetAPIKey(&APIKeyConfig{ServiceName: ServiceOllama, BaseURL: "http://gpu-box:11434", ModelID: "llama3.1"})
+	if got := store.Resolve(context.Background(), ServiceOllama); got == nil || got.BaseURL != "http://gpu-box:11434" {
+		t.Errorf("Resolve() = %+v, want the keyless Ollama config", got)
+	}
+	if !store.IsConfigured(ServiceOllama) {
+		t.Error("IsConfigured() = false for Ollama with a base URL")
+	}
+}
+
 func TestPersistence(t *testing.T) {
 	tmpDir := t.TempDir()
 	repo := newMockRepository()
@@ -353,10 +402,12 @@ func TestServiceDisplayName(t *testing.T) {
 		expected string
 	}{
 		{ServiceOpenAI, "OpenAI"},
+		{ServiceAnthropic, "Anthropic"},
 		{ServiceAlpaca, "Alpaca Markets"},
 		{ServiceAlphaVantage, "Alpha Vantage"},
 		{ServiceNewsAPI, "NewsAPI"},
 		{ServiceFMP, "Financial Modeling Prep"},
+		{ServiceBackupStorage, "Backup Storage"},
 		{ServiceName("unknown"), "unknown"},
 	}
 
@@ -376,10 +427,12 @@ func TestServiceDescription(t *testing.T) {
 		hasResult bool
 	}{
 		{ServiceOpenAI, true},
+		{ServiceAnthropic, true},
 		{ServiceAlpaca, true},
 		{ServiceAlphaVantage, true},
 		{ServiceNewsAPI, true},
 		{ServiceFMP, true},
+		{ServiceBackupStorage, true},
 		{ServiceName("unknown"), false},
 	}
 
@@ -7,13 +7,15 @@ import (
 
 // mockRepository implements RepositoryInterface for testing
 type mockRepository struct {
-	apiKeys map[string]*APIKeyModel
-	err     error
+	apiKeys     map[string]*APIKeyModel
+	appSettings map[string][]byte
+	err         error
 }
 
 func newMockRepository() *mockRepository {
 	return &mockRepository{
-		apiKeys: make(map[string]*APIKeyModel),
+		apiKeys:     make(map[string]*APIKeyModel),
+		appSettings: make(map[string][]byte),
 	}
 }
 
@@ -55,6 +57,21 @@ func (m *mockRepository) DeleteAPIKey(ctx context.Context, serviceName string) e
 	return nil
 }
 
+func (m *mockRepository) GetAppSetting(ctx context.Context, key string) ([]byte, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.appSettings[key], nil
+}
+
+func (m *mockRepository) UpsertAppSetting(ctx context.Context, key string, value []byte) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.appSettings[key] = value
+	return nil
+}
+
 // mockRepositoryWithOnce extends mockRepository to support one-time error
 type mockRepositoryWithOnce struct {
 	*mockRepository
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	"trade-machine/backup"
 )
 
 // ValidationResult represents the result of validating an API key
@@ -46,6 +48,10 @@ func (v *Validator) ValidateAPIKey(ctx context.Context, config *APIKeyConfig) (*
 	switch config.ServiceName {
 	case ServiceOpenAI:
 		err = v.validateOpenAI(ctx, config)
+	case ServiceAnthropic:
+		err = v.validateAnthropic(ctx, config)
+	case ServiceOllama:
+		err = v.validateOllama(ctx, config)
 	case ServiceAlpaca:
 		err = v.validateAlpaca(ctx, config)
 	case ServiceAlphaVantage:
@@ -54,6 +60,8 @@ func (v *Validator) ValidateAPIKey(ctx context.Context, config *APIKeyConfig) (*
 		err = v.validateNewsAPI(ctx, config)
 	case ServiceFMP:
 		err = v.validateFMP(ctx, config)
+	case ServiceBackupStorage:
+		err = v.validateBackupStorage(ctx, config)
 	default:
 		err = fmt.Errorf("unknown service: %s", config.ServiceName)
 	}
@@ -99,6 +107,81 @@ func (v *Validator) validateOpenAI(ctx context.Context, config *APIKeyConfig) er
 	return nil
 }
 
+// validateAnthropic tests Anthropic API connectivity
+func (v *Validator) validateAnthropic(ctx context.Context, config *APIKeyConfig) error {
+	if config.APIKey == "" {
+		return errors.New("API key is required")
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.anthropic.com"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/v1/models", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-api-key", config.APIKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 401 || resp.StatusCode == 403 {
+		return errors.New("invalid API key")
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// validateOllama checks that the Ollama server answers and has the configured model pulled
+func (v *Validator) validateOllama(ctx context.Context, config *APIKeyConfig) error {
+	if config.BaseURL == "" {
+		return errors.New("base URL is required")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", config.BaseURL+"/api/tags", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	if config.ModelID == "" {
+		return nil
+	}
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	for _, m := range tags.Models {
+		// Tags without a version are stored as name:latest
+		if m.Name == config.ModelID || m.Name == config.ModelID+":latest" {
+			return nil
+		}
+	}
+	return fmt.Errorf("model %s is not pulled; run ollama pull %s", config.ModelID, config.ModelID)
+}
+
 // validateAlpaca tests Alpaca API connectivity
 func (v *Validator) validateAlpaca(ctx context.Context, config *APIKeyConfig) error {
 	if config.APIKey == "" {
@@ -238,3 +321,23 @@ func (v *Validator) validateFMP(ctx context.Context, config *APIKeyConfig) error
 	return nil
 }
 
+// validateBackupStorage tests that the backup bucket can be listed with the credentials
+func (v *Validator) validateBackupStorage(ctx context.Context, config *APIKeyConfig) error {
+	if config.BaseURL == "" {
+		return errors.New("bucket URL is required")
+	}
+
+	storage, err := backup.NewS3Storage(backup.S3Config{
+		URL:       config.BaseURL,
+		Region:    config.Region,
+		AccessKey: config.APIKey,
+		SecretKey: config.APISecret,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := storage.List(ctx); err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+	return nil
+}
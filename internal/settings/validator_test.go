@@ -44,6 +44,7 @@ func TestValidatorMissingAPIKey(t *testing.T) {
 		service ServiceName
 	}{
 		{"OpenAI", ServiceOpenAI},
+		{"Anthropic", ServiceAnthropic},
 		{"AlphaVantage", ServiceAlphaVantage},
 		{"NewsAPI", ServiceNewsAPI},
 		{"FMP", ServiceFMP},
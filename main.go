@@ -3,16 +3,31 @@ package main
 import (
 	"context"
 	"os"
+	"time"
 
 	"trade-machine/agents"
+	"trade-machine/backtest"
+	"trade-machine/backup"
 	"trade-machine/config"
+	"trade-machine/coveredcalls"
+	"trade-machine/dividends"
+	"trade-machine/events"
 	"trade-machine/internal/api"
 	"trade-machine/internal/app"
+	"trade-machine/internal/i18n"
 	"trade-machine/internal/settings"
+	"trade-machine/models"
+	"trade-machine/notifications"
 	"trade-machine/observability"
+	"trade-machine/rebalance"
+	"trade-machine/reconciliation"
 	"trade-machine/repository"
 	"trade-machine/screener"
 	"trade-machine/services"
+	"trade-machine/similarity"
+	"trade-machine/stats"
+	"trade-machine/tray"
+	"trade-machine/watcher"
 
 	"github.com/joho/godotenv"
 	"github.com/wailsapp/wails/v2"
@@ -38,6 +53,17 @@ func main() {
 	if err != nil {
 		observability.Fatal("failed to load configuration", "error", err)
 	}
+	if err := observability.ConfigureLevels(cfg.Logging.Level, cfg.Logging.ModuleLevels); err != nil {
+		observability.Fatal("failed to configure log levels", "error", err)
+	}
+
+	// Export trace spans when an OTLP endpoint is configured
+	observability.InitTracing(observability.TracingOptions{
+		Endpoint:    cfg.Tracing.Endpoint,
+		Headers:     cfg.Tracing.Headers,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
 
 	ctx := context.Background()
 
@@ -52,73 +78,176 @@ func main() {
 		observability.Fatal("DATABASE_URL environment variable is required")
 	}
 
-	// Initialize services (with nil checks for graceful degradation)
-	var llmService services.LLMService
-	var alpacaService *services.AlpacaService
-	var alphaVantageService *services.AlphaVantageService
-	var newsAPIService *services.NewsAPIService
-	var fmpService *services.FMPService
-
-	// Initialize OpenAI Service
-	if cfg.HasOpenAI() {
-		openaiService, err := services.NewOpenAIService(cfg)
-		if err != nil {
-			observability.Warn("failed to initialize OpenAI service", "error", err)
+	// Agent runs, LLM usage, API call ledger batches and audit entries survive database
+	// blips in a write buffer
+	writeBuffer := repository.NewWriteBuffer(cfg.WriteBuffer.Capacity)
+	bufferedRepo := repository.NewBufferedRepository(repo, writeBuffer)
+
+	// Record every outbound API call so /api/usage can show where provider quotas went
+	ledger := services.NewCallLedger(bufferedRepo, cfg.APILedger.RetentionDays)
+	services.SetCallLedger(ledger)
+
+	// Keep provider calls within their quotas rather than being cut off by a 429
+	services.SetRateLimiter(services.NewRateLimiterFromConfig(cfg.RateLimits))
+
+	// Ride out transient 429s and 5xx responses instead of failing agent runs on them
+	services.SetRetryPolicy(services.NewRetryPolicyFromConfig(cfg.Retry))
+
+	// Reuse fundamentals and news fetched by earlier runs, even across restarts
+	if cfg.ResponseCache.Enabled {
+		services.SetResponseCache(services.NewResponseCache(repo, cfg.ResponseCache))
+	}
+
+	// Domain events (recommendations, fills, screener runs, breaker trips) are published
+	// here; integrations subscribe rather than being called from where the event happens
+	eventBus := events.NewBus()
+	events.SetDefault(eventBus)
+	eventBus.Subscribe("metrics", events.RecordMetrics, events.MetricsEvents...)
+
+	// Optionally publish the same events to NATS for services outside the app
+	if cfg.Events.NATSURL != "" {
+		if nats, err := events.DialNATS(cfg.Events.NATSURL); err != nil {
+			observability.Warn("failed to connect to NATS, events stay in-process", "error", err)
 		} else {
-			llmService = openaiService
-			observability.Info("initialized OpenAI service", "model", cfg.OpenAI.Model)
+			eventBus.Forward(cfg.Events.SubjectPrefix, nats)
+			observability.Info("forwarding events to NATS", "subject_prefix", cfg.Events.SubjectPrefix)
 		}
 	}
 
-	if llmService == nil {
-		observability.Warn("no LLM service configured, AI agents disabled - set OPENAI_API_KEY")
+	// Alert the user when a provider's breaker opens or its quota runs out
+	alertNotifier := services.NewAlertNotifier(repo)
+	services.SetAlertNotifier(alertNotifier)
+	eventBus.Subscribe("provider_alerts", alertNotifier.HandleEvent, events.BreakerOpened)
+
+	// Initialize Settings Store
+	settingsPassphrase := os.Getenv("SETTINGS_PASSPHRASE")
+	settingsDir := os.Getenv("SETTINGS_DIR")
+	settingsStore, err := settings.NewStore(settingsDir, settingsPassphrase, repo)
+	if err != nil {
+		observability.Warn("failed to initialize settings store", "error", err)
+	} else {
+		observability.Info("settings store initialized")
+
+		// Post to Slack, Discord or email, as configured in settings, when a
+		// recommendation awaits approval, a trade executes, a screener run finishes or a
+		// breaker opens
+		notifier := notifications.NewNotifier(settingsStore.Notifications)
+		eventBus.Subscribe("notifications", notifier.HandleEvent, notifications.Events...)
 	}
 
+	// API clients resolve their keys per request: keys carried by the request context,
+	// then the settings store, then the environment. Services are enabled when a key
+	// is available at startup; changing a key later takes effect on the next call.
+	clients := services.NewClientProvider(cfg, app.NewKeyResolver(cfg, settingsStore))
+
+	// Initialize services (with nil checks for graceful degradation)
+	var llmService services.LLMService
+	var alpacaService *services.KeyedAlpaca
+	var alphaVantageService services.AlphaVantageServiceInterface
+	var newsAPIService services.NewsAPIServiceInterface
+	var fmpService services.FMPServiceInterface
+
+	// LLM Service (OpenAI, Anthropic or a local Ollama server, per LLM_PROVIDER)
+	if provider := clients.LLMProvider(ctx); clients.Configured(ctx, provider) {
+		llmService = agents.WithLanguage(clients.LLM(), cfg.Agent.Language)
+		observability.Info("initialized LLM service", "provider", provider)
+	} else {
+		observability.Warn("no LLM service configured, AI agents disabled - set OPENAI_API_KEY, ANTHROPIC_API_KEY or OLLAMA_BASE_URL")
+	}
+	i18n.SetLanguage(cfg.Agent.Language)
+
 	// Alpaca Service
-	if cfg.HasAlpaca() {
-		alpacaService = services.NewAlpacaService(cfg.Alpaca.APIKey, cfg.Alpaca.APISecret, cfg.Alpaca.BaseURL)
+	if clients.Configured(ctx, services.BreakerAlpaca) {
+		alpacaService = clients.Alpaca()
+		if cfg.Execution.Auto() {
+			observability.Info("auto-execution enabled, approving a recommendation places its order")
+		}
 	} else {
 		observability.Warn("Alpaca API credentials not set, trading disabled")
 	}
 
 	// Alpha Vantage Service
-	if cfg.HasAlphaVantage() {
-		alphaVantageService = services.NewAlphaVantageService(cfg.AlphaVantage.APIKey)
+	if clients.Configured(ctx, services.BreakerAlphaVantage) {
+		alphaVantageService = clients.AlphaVantage()
 	} else {
 		observability.Warn("Alpha Vantage API key not set, fundamental analysis disabled")
 	}
 
 	// NewsAPI Service
-	if cfg.HasNewsAPI() {
-		newsAPIService = services.NewNewsAPIService(cfg.NewsAPI.APIKey)
+	if clients.Configured(ctx, services.BreakerNewsAPI) {
+		newsAPIService = clients.NewsAPI()
 	} else {
 		observability.Warn("NewsAPI key not set, news sentiment analysis disabled")
 	}
 
 	// FMP Service (Financial Modeling Prep for stock screening)
-	if cfg.HasFMP() {
-		fmpService = services.NewFMPService(cfg.FMP.APIKey)
+	if clients.Configured(ctx, services.BreakerFMP) {
+		fmpService = clients.FMP()
 	} else {
 		observability.Warn("FMP_API_KEY not set, stock screener disabled")
 	}
 
-	// Initialize Portfolio Manager and register agents
+	// Trailing volatility, beta and drawdown from daily bars, cached per market day
+	var riskStats *stats.Service
+	if alpacaService != nil {
+		var cache stats.Cache
+		if repo != nil {
+			cache = repo
+		}
+		riskStats = stats.NewService(alpacaService, cache, &cfg.RiskStats)
+	}
+
+	// Initialize Portfolio Manager and register agents. Without a broker the manager
+	// runs in signal-only mode: recommendations carry the action and scores but no quantity.
 	var portfolioManager *agents.PortfolioManager
-	if repo != nil && alpacaService != nil {
-		portfolioManager = agents.NewPortfolioManager(repo, cfg, alpacaService)
+	if repo != nil {
+		var accountProvider agents.AccountProvider
+		if alpacaService != nil {
+			accountProvider = alpacaService
+		}
+		portfolioManager = agents.NewPortfolioManager(bufferedRepo, cfg, accountProvider)
+		if portfolioManager.SignalOnly() {
+			observability.Info("portfolio manager in signal-only mode, position sizing disabled")
+		}
+		if riskStats != nil {
+			portfolioManager.SetRiskStatsProvider(riskStats)
+		}
+		if alpacaService != nil && cfg.RiskManager.Enabled {
+			// Sectors come from Alpha Vantage fundamentals; without it only the symbol,
+			// correlation and drawdown limits apply
+			portfolioManager.SetRiskManager(agents.NewRiskManager(cfg.RiskManager, alpacaService, alphaVantageService))
+		}
+		if settingsStore != nil {
+			// System prompts customized in settings replace the agents' built-in ones
+			portfolioManager.SetPromptStore(settingsStore)
+		}
+		// Token usage and estimated cost of every agent run, checked against LLM_MONTHLY_BUDGET
+		portfolioManager.SetLLMUsageStore(bufferedRepo)
 
 		// Register agents if their dependencies are available
 		if llmService != nil && alphaVantageService != nil {
-			portfolioManager.RegisterAgent(agents.NewFundamentalAnalyst(llmService, alphaVantageService))
+			fundamentalAnalyst := agents.NewFundamentalAnalyst(llmService, alphaVantageService)
+			fundamentalAnalyst.SetSnapshotStore(repo)
+			portfolioManager.RegisterAgent(fundamentalAnalyst)
 		}
 		if llmService != nil && newsAPIService != nil {
-			portfolioManager.RegisterAgent(agents.NewNewsAnalyst(llmService, newsAPIService))
+			portfolioManager.RegisterAgent(agents.NewNewsAnalyst(llmService, newsAPIService, cfg))
 		}
-		if llmService != nil {
+		if llmService != nil && alpacaService != nil {
 			portfolioManager.RegisterAgent(agents.NewTechnicalAnalyst(llmService, alpacaService, cfg))
 		}
-	} else if repo != nil {
-		observability.Warn("Alpaca service required for position sizing, portfolio manager disabled")
+		if llmService != nil && cfg.Social.Enabled {
+			portfolioManager.RegisterAgent(agents.NewSocialSentimentAnalyst(llmService, services.NewSocialSentimentService(cfg.Social)))
+			if cfg.Agent.WeightSocial == 0 {
+				observability.Warn("social sentiment agent enabled with AGENT_WEIGHT_SOCIAL=0, its score will not affect recommendations")
+			}
+		}
+		if fmpService != nil && cfg.Agent.WeightInsider > 0 {
+			portfolioManager.RegisterAgent(agents.NewInsiderActivityAnalyst(fmpService, cfg.Agent.InsiderLookbackDays))
+		}
+		if cfg.HasFRED() && cfg.Agent.WeightMacro > 0 {
+			portfolioManager.RegisterAgent(agents.NewMacroAnalyst(services.NewFREDService(cfg.FRED.APIKey)))
+		}
 	}
 
 	// Initialize app
@@ -126,20 +255,37 @@ func main() {
 	// In Go, an interface holding a nil concrete pointer is not itself nil.
 	var repoInterface app.RepositoryInterface
 	if repo != nil {
-		repoInterface = repo
+		repoInterface = bufferedRepo
+	}
+	var alpacaInterface services.AlpacaServiceInterface
+	if alpacaService != nil {
+		alpacaInterface = alpacaService
 	}
-	application := app.New(cfg, repoInterface, portfolioManager, alpacaService)
+	application := app.New(cfg, repoInterface, portfolioManager, alpacaInterface)
 
-	// Initialize Settings Store
-	settingsPassphrase := os.Getenv("SETTINGS_PASSPHRASE")
-	settingsDir := os.Getenv("SETTINGS_DIR")
-	settingsStore, err := settings.NewStore(settingsDir, settingsPassphrase, repo)
-	if err != nil {
-		observability.Warn("failed to initialize settings store", "error", err)
-	} else {
+	// Paper fills carry no broker fee data, so estimate them from the configured schedule
+	var feeSchedule models.FeeSchedule
+	if cfg.Alpaca.IsPaper() {
+		feeSchedule = models.NewFeeSchedule(cfg.Fees.CommissionPerTrade, cfg.Fees.CommissionPerShare, cfg.Fees.SellFeeRate)
+		application.SetFeeSchedule(feeSchedule)
+	}
+
+	// Route orders to Interactive Brokers alongside Alpaca, which still supplies market data.
+	// Registered before settings are applied so a saved choice of broker can select it.
+	var ibkrService *services.IBKRService
+	if cfg.HasIBKR() {
+		ibkrService = services.NewIBKRService(cfg.IBKR.GatewayURL, cfg.IBKR.AccountID, cfg.IBKR.InsecureSkipVerify)
+		application.SetBroker(models.BrokerIBKR, ibkrService)
+	}
+
+	if settingsStore != nil {
 		application.SetSettings(settingsStore)
-		observability.Info("settings store initialized")
 	}
+	application.SetClientProvider(clients)
+	if llmService != nil {
+		application.SetChatLLM(llmService)
+	}
+	observability.Info("routing orders", "broker", application.GetBroker().Active)
 
 	// Set up screener factory for dynamic initialization when FMP key is updated via settings
 	if portfolioManager != nil && repo != nil {
@@ -163,11 +309,74 @@ func main() {
 		}
 	}
 
+	// Re-analyze held and recently recommended symbols on significant price moves
+	if cfg.PriceWatch.Enabled && portfolioManager != nil && alpacaService != nil {
+		application.SetPriceWatcher(watcher.NewPriceWatcher(repo, alpacaService, application, &cfg.PriceWatch))
+		observability.Info("price watcher enabled", "move_percent", cfg.PriceWatch.MovePercent)
+	}
+
+	// Record broker fills on trades, taking unfilled shares back off positions, and reconcile
+	// each finished month against Alpaca
+	if cfg.Reconciliation.Enabled && repo != nil && alpacaService != nil {
+		reconciler := reconciliation.NewReconciler(repo, alpacaService, feeSchedule)
+		if ibkrService != nil {
+			reconciler.AddOrderSource(models.BrokerIBKR, ibkrService)
+		}
+		reconciler.SetFillHandler(application)
+		application.SetReconciler(reconciler)
+		observability.Info("monthly broker reconciliation enabled")
+	}
+
+	// Size orders toward target position and sector weights. Sectors come from fundamentals.
+	if repo != nil && alpacaService != nil {
+		application.SetRebalancer(rebalance.NewRebalancer(repo, alpacaService, alphaVantageService, cfg.Rebalance.DriftThreshold))
+	}
+
+	// Suggest covered calls on held positions from Alpaca's option chains
+	if alpacaService != nil {
+		application.SetCoveredCallAdvisor(coveredcalls.NewCoveredCallAdvisor(alpacaService, alpacaService, &cfg.Options))
+	}
+
+	// Embed past analyses so similar ones can be found across symbols. Embeddings come
+	// from OpenAI whichever provider the agents use.
+	if repo != nil && clients.Configured(ctx, services.BreakerOpenAI) {
+		application.SetSimilarityIndex(similarity.NewIndex(repo, clients.Embedder()))
+		observability.Info("analysis similarity search enabled", "model", cfg.OpenAI.EmbeddingModel)
+	}
+
+	if riskStats != nil {
+		application.SetRiskStats(riskStats)
+	}
+
+	// Track dividends on held positions and project their annual income
+	if repo != nil && fmpService != nil {
+		application.SetDividendTracker(dividends.NewTracker(repo, fmpService))
+	}
+
+	// Replay daily bars through the action strategies on request
+	if repo != nil && alpacaService != nil {
+		application.SetBacktester(backtest.NewEngine(alpacaService))
+	}
+
+	// Back up the database, encrypted with the configured key, to the bucket set in settings
+	if cfg.Backup.Enabled && settingsStore != nil {
+		dumper := backup.NewPgDump(cfg.Backup.PgDumpPath, cfg.Database.URL)
+		interval := time.Duration(cfg.Backup.IntervalHours) * time.Hour
+		application.SetBackupManager(backup.NewManager(dumper, app.NewBackupStorageResolver(settingsStore), cfg.Backup.EncryptionKey, interval, cfg.Backup.Retention))
+		observability.Info("database backups enabled", "interval_hours", cfg.Backup.IntervalHours, "retention", cfg.Backup.Retention)
+	}
+
+	application.SetWriteBuffer(writeBuffer)
+	application.SetCallLedger(ledger)
+	observability.Info("API call ledger enabled", "retention_days", cfg.APILedger.RetentionDays)
+	application.SetAlertNotifier(alertNotifier)
+	application.SetEventBus(eventBus)
+	eventBus.Subscribe("audit_log", application.HandleAuditEvent, events.ActionAudited)
+
 	handler := api.NewHandler(application, cfg)
 	router := api.NewRouter(handler, cfg)
 
-	// Run Wails application
-	err = wails.Run(&options.App{
+	appOptions := &options.App{
 		Title:  "Trade Machine",
 		Width:  1280,
 		Height: 800,
@@ -177,7 +386,27 @@ func main() {
 		BackgroundColour: options.NewRGB(27, 38, 54),
 		OnStartup:        application.Startup,
 		OnShutdown:       application.Shutdown,
-	})
+	}
+
+	// Status menu with the market session, pending recommendations and quick actions
+	if cfg.Tray.Enabled {
+		statusMenu := tray.NewWailsMenu(tray.New(application, time.Duration(cfg.Tray.RefreshSeconds)*time.Second))
+		appOptions.Menu = statusMenu.Menu()
+		appOptions.OnStartup = func(ctx context.Context) {
+			application.Startup(ctx)
+			go statusMenu.Run(ctx)
+		}
+	}
+
+	// Run Wails application
+	err = wails.Run(appOptions)
+
+	// Send the spans still queued before exiting
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := observability.ShutdownTracing(flushCtx); err != nil {
+		observability.Warn("failed to flush trace spans", "error", err)
+	}
+	cancelFlush()
 
 	if err != nil {
 		observability.Fatal("wails application error", "error", err)
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Add entry/stop price levels and risk/reward ratio to recommendations
+ALTER TABLE recommendations
+ADD COLUMN entry_price DECIMAL(20,8) NOT NULL DEFAULT 0,
+ADD COLUMN stop_price DECIMAL(20,8) NOT NULL DEFAULT 0,
+ADD COLUMN risk_reward DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (risk_reward >= 0);
+
+UPDATE recommendations SET target_price = 0 WHERE target_price IS NULL;
+
+COMMENT ON COLUMN recommendations.entry_price IS 'Reference price when the recommendation was generated';
+COMMENT ON COLUMN recommendations.stop_price IS 'Price at which the thesis is invalidated';
+COMMENT ON COLUMN recommendations.risk_reward IS 'Reward/risk ratio implied by entry, target, and stop (0 if unknown)';
+
+-- +goose Down
+ALTER TABLE recommendations
+DROP COLUMN IF EXISTS entry_price,
+DROP COLUMN IF EXISTS stop_price,
+DROP COLUMN IF EXISTS risk_reward;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Record how missing agents' weights were handled when scoring a recommendation
+ALTER TABLE recommendations
+ADD COLUMN weight_policy VARCHAR(20) NOT NULL DEFAULT ''
+    CHECK (weight_policy IN ('', 'redistribute', 'floor', 'abstain'));
+
+COMMENT ON COLUMN recommendations.weight_policy IS 'Weight policy applied for unavailable agents (empty when all agents reported)';
+
+-- +goose Down
+ALTER TABLE recommendations
+DROP COLUMN IF EXISTS weight_policy;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- Append-only log of recommendation state transitions
+CREATE TABLE recommendation_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recommendation_id UUID NOT NULL REFERENCES recommendations(id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL
+        CHECK (event_type IN ('created', 'approved', 'rejected', 'executed', 'expired')),
+    actor VARCHAR(50) NOT NULL DEFAULT 'system',
+    trade_id UUID REFERENCES trades(id),
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_recommendation_events_recommendation ON recommendation_events(recommendation_id, occurred_at);
+CREATE INDEX idx_recommendation_events_occurred_at ON recommendation_events(occurred_at DESC);
+
+-- Backfill transitions previously inferred from nullable columns
+INSERT INTO recommendation_events (recommendation_id, event_type, actor, occurred_at)
+SELECT id, 'created', 'migration', created_at FROM recommendations;
+
+INSERT INTO recommendation_events (recommendation_id, event_type, actor, occurred_at)
+SELECT id, 'approved', 'migration', approved_at FROM recommendations WHERE approved_at IS NOT NULL;
+
+INSERT INTO recommendation_events (recommendation_id, event_type, actor, occurred_at)
+SELECT id, 'rejected', 'migration', rejected_at FROM recommendations WHERE rejected_at IS NOT NULL;
+
+INSERT INTO recommendation_events (recommendation_id, event_type, actor, trade_id, occurred_at)
+SELECT r.id, 'executed', 'migration', r.executed_trade_id, COALESCE(t.executed_at, t.created_at, r.created_at)
+FROM recommendations r
+LEFT JOIN trades t ON t.id = r.executed_trade_id
+WHERE r.executed_trade_id IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS recommendation_events;
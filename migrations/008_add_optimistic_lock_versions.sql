@@ -0,0 +1,17 @@
+-- +goose Up
+-- Row versions for optimistic concurrency control; each update increments the version
+ALTER TABLE recommendations
+ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE positions
+ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN recommendations.version IS 'Incremented on every state transition; stale writers get a conflict';
+COMMENT ON COLUMN positions.version IS 'Incremented on every update; stale writers get a conflict';
+
+-- +goose Down
+ALTER TABLE positions
+DROP COLUMN IF EXISTS version;
+
+ALTER TABLE recommendations
+DROP COLUMN IF EXISTS version;
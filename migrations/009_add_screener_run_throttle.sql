@@ -0,0 +1,10 @@
+-- +goose Up
+-- Record how the screener adapted its concurrency to provider rate limits during a run
+ALTER TABLE screener_runs
+ADD COLUMN throttle JSONB;
+
+COMMENT ON COLUMN screener_runs.throttle IS 'Adaptive throttling report: rate-limit errors and concurrency adjustments';
+
+-- +goose Down
+ALTER TABLE screener_runs
+DROP COLUMN IF EXISTS throttle;
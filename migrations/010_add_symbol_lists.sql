@@ -0,0 +1,14 @@
+-- +goose Up
+-- User-managed blocklist (never analyze or trade) and allowlist (screener universe)
+CREATE TABLE symbol_lists (
+    list_type VARCHAR(10) NOT NULL CHECK (list_type IN ('block', 'allow')),
+    symbol VARCHAR(10) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (list_type, symbol)
+);
+
+CREATE INDEX idx_symbol_lists_symbol ON symbol_lists(symbol);
+
+-- +goose Down
+DROP TABLE IF EXISTS symbol_lists;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Record why an automatic re-analysis produced a recommendation (e.g. a price move)
+ALTER TABLE recommendations
+ADD COLUMN trigger_reason TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN recommendations.trigger_reason IS 'Reason an automatic re-analysis ran (empty for user-requested analyses)';
+
+-- +goose Down
+ALTER TABLE recommendations
+DROP COLUMN IF EXISTS trigger_reason;
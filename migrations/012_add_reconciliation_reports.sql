@@ -0,0 +1,15 @@
+-- +goose Up
+-- Monthly comparison of local trades, positions and cash against broker activity
+CREATE TABLE reconciliation_reports (
+    id UUID PRIMARY KEY,
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL UNIQUE,
+    period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    discrepancy_count INTEGER NOT NULL DEFAULT 0,
+    report JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN reconciliation_reports.report IS 'Full discrepancy report; re-running a period replaces it';
+
+-- +goose Down
+DROP TABLE IF EXISTS reconciliation_reports;
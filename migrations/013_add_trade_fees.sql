@@ -0,0 +1,10 @@
+-- +goose Up
+-- Regulatory and exchange fees charged on a fill, tracked separately from commission
+ALTER TABLE trades
+ADD COLUMN fees DECIMAL(20,8) NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN trades.fees IS 'Regulatory and exchange fees from broker fee activities or the configured fee schedule';
+
+-- +goose Down
+ALTER TABLE trades
+DROP COLUMN IF EXISTS fees;
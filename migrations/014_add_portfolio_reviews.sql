@@ -0,0 +1,13 @@
+-- +goose Up
+-- Reviews produced by analyzing every open position at once
+CREATE TABLE portfolio_reviews (
+    id UUID PRIMARY KEY,
+    position_count INTEGER NOT NULL DEFAULT 0,
+    review JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_portfolio_reviews_created_at ON portfolio_reviews(created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS portfolio_reviews;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- User edits to a pending recommendation, kept apart from the agent-suggested values
+ALTER TABLE recommendations
+ADD COLUMN user_override JSONB;
+
+COMMENT ON COLUMN recommendations.user_override IS 'User-edited quantity, order type and limit price (NULL when executing as suggested)';
+
+ALTER TABLE recommendation_events DROP CONSTRAINT IF EXISTS recommendation_events_event_type_check;
+ALTER TABLE recommendation_events ADD CONSTRAINT recommendation_events_event_type_check
+    CHECK (event_type IN ('created', 'approved', 'rejected', 'executed', 'expired', 'edited'));
+
+-- +goose Down
+DELETE FROM recommendation_events WHERE event_type = 'edited';
+
+ALTER TABLE recommendation_events DROP CONSTRAINT IF EXISTS recommendation_events_event_type_check;
+ALTER TABLE recommendation_events ADD CONSTRAINT recommendation_events_event_type_check
+    CHECK (event_type IN ('created', 'approved', 'rejected', 'executed', 'expired'));
+
+ALTER TABLE recommendations
+DROP COLUMN IF EXISTS user_override;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Named ticker lists imported by the user
+CREATE TABLE watchlists (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    symbols TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_watchlists_created_at ON watchlists(created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS watchlists;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Recommendations returned when the analysis latency budget ran out before every agent reported
+ALTER TABLE recommendations
+ADD COLUMN partial BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN recommendations.partial IS 'TRUE until the agents still running at the latency budget report and the recommendation is re-synthesized';
+
+ALTER TABLE recommendation_events DROP CONSTRAINT IF EXISTS recommendation_events_event_type_check;
+ALTER TABLE recommendation_events ADD CONSTRAINT recommendation_events_event_type_check
+    CHECK (event_type IN ('created', 'approved', 'rejected', 'executed', 'expired', 'edited', 'completed'));
+
+-- +goose Down
+DELETE FROM recommendation_events WHERE event_type = 'completed';
+
+ALTER TABLE recommendation_events DROP CONSTRAINT IF EXISTS recommendation_events_event_type_check;
+ALTER TABLE recommendation_events ADD CONSTRAINT recommendation_events_event_type_check
+    CHECK (event_type IN ('created', 'approved', 'rejected', 'executed', 'expired', 'edited'));
+
+ALTER TABLE recommendations
+DROP COLUMN IF EXISTS partial;
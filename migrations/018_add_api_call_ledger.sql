@@ -0,0 +1,31 @@
+-- +goose Up
+-- Every outbound call to an external API, pruned after the configured retention
+CREATE TABLE api_calls (
+    id BIGSERIAL PRIMARY KEY,
+    provider VARCHAR(20) NOT NULL,
+    endpoint TEXT NOT NULL,
+    status INTEGER NOT NULL,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    bytes BIGINT NOT NULL DEFAULT 0,
+    cached BOOLEAN NOT NULL DEFAULT FALSE,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_api_calls_occurred_at ON api_calls(occurred_at);
+
+-- Daily totals per provider endpoint, kept after the individual calls are pruned
+CREATE TABLE api_usage_daily (
+    day DATE NOT NULL,
+    provider VARCHAR(20) NOT NULL,
+    endpoint TEXT NOT NULL,
+    calls BIGINT NOT NULL DEFAULT 0,
+    errors BIGINT NOT NULL DEFAULT 0,
+    cached_calls BIGINT NOT NULL DEFAULT 0,
+    bytes BIGINT NOT NULL DEFAULT 0,
+    total_latency_ms BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, provider, endpoint)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_usage_daily;
+DROP TABLE IF EXISTS api_calls;
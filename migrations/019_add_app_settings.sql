@@ -0,0 +1,10 @@
+-- +goose Up
+-- General user settings stored as JSON by key (e.g. onboarding progress)
+CREATE TABLE app_settings (
+    key VARCHAR(100) PRIMARY KEY,
+    value JSONB NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS app_settings;
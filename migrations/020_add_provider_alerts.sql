@@ -0,0 +1,16 @@
+-- +goose Up
+-- Alerts raised when a provider's circuit breaker opens or its API quota runs out
+CREATE TABLE provider_alerts (
+    id UUID PRIMARY KEY,
+    provider VARCHAR(20) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    error_sample TEXT NOT NULL DEFAULT '',
+    recover_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    dismissed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_provider_alerts_created_at ON provider_alerts(created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS provider_alerts;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Raw provider screen results each run started from, so a run can be replayed against new criteria
+CREATE TABLE screener_universes (
+    run_id UUID PRIMARY KEY REFERENCES screener_runs(id) ON DELETE CASCADE,
+    entries JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE screener_runs ADD COLUMN replay_of UUID REFERENCES screener_runs(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE screener_runs DROP COLUMN IF EXISTS replay_of;
+DROP TABLE IF EXISTS screener_universes;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Short entries and covers alongside buy, sell and hold
+ALTER TABLE recommendations DROP CONSTRAINT IF EXISTS recommendations_action_check;
+ALTER TABLE recommendations ADD CONSTRAINT recommendations_action_check
+    CHECK (action IN ('buy', 'sell', 'hold', 'short', 'cover'));
+
+-- +goose Down
+UPDATE recommendations SET action = 'sell' WHERE action = 'short';
+UPDATE recommendations SET action = 'buy' WHERE action = 'cover';
+
+ALTER TABLE recommendations DROP CONSTRAINT IF EXISTS recommendations_action_check;
+ALTER TABLE recommendations ADD CONSTRAINT recommendations_action_check
+    CHECK (action IN ('buy', 'sell', 'hold'));
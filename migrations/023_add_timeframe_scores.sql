@@ -0,0 +1,6 @@
+-- +goose Up
+-- Technical sub-scores per timeframe (short, medium, long) behind a recommendation's technical score
+ALTER TABLE recommendations ADD COLUMN timeframe_scores JSONB;
+
+-- +goose Down
+ALTER TABLE recommendations DROP COLUMN IF EXISTS timeframe_scores;
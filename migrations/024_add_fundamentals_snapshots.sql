@@ -0,0 +1,13 @@
+-- +goose Up
+-- Fundamentals each analysis was based on, so re-analyses can focus on what changed
+CREATE TABLE fundamentals_snapshots (
+    id UUID PRIMARY KEY,
+    symbol VARCHAR(10) NOT NULL,
+    fundamentals JSONB NOT NULL,
+    captured_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_fundamentals_snapshots_symbol ON fundamentals_snapshots(symbol, captured_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS fundamentals_snapshots;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Embeddings of recommendation reasoning for finding similar past analyses (pgvector)
+CREATE EXTENSION IF NOT EXISTS vector;
+
+CREATE TABLE analysis_embeddings (
+    recommendation_id UUID PRIMARY KEY REFERENCES recommendations(id) ON DELETE CASCADE,
+    symbol VARCHAR(10) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    embedding vector(1536) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_analysis_embeddings_symbol ON analysis_embeddings(symbol, created_at DESC);
+CREATE INDEX idx_analysis_embeddings_vector ON analysis_embeddings USING hnsw (embedding vector_cosine_ops);
+
+-- +goose Down
+DROP TABLE IF EXISTS analysis_embeddings;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Child orders for recommendations approved with a split plan, executed over time
+CREATE TABLE recommendation_tranches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recommendation_id UUID NOT NULL REFERENCES recommendations(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL,
+    quantity DECIMAL(20,8) NOT NULL CHECK (quantity > 0),
+    trigger_price DECIMAL(20,8),
+    scheduled_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'submitted', 'failed', 'cancelled')),
+    trade_id UUID REFERENCES trades(id) ON DELETE SET NULL,
+    error TEXT,
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (recommendation_id, sequence),
+    CHECK (trigger_price IS NOT NULL OR scheduled_at IS NOT NULL)
+);
+
+CREATE INDEX idx_recommendation_tranches_pending ON recommendation_tranches(recommendation_id, sequence) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE IF EXISTS recommendation_tranches;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Replays of daily bars through an action strategy, with their stats, equity curve and trades
+CREATE TABLE backtest_runs (
+    id UUID PRIMARY KEY,
+    strategy VARCHAR(50) NOT NULL,
+    symbols TEXT[] NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    run JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_backtest_runs_created_at ON backtest_runs(created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS backtest_runs;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Orders the broker has filled in part, and how much of each order has filled
+ALTER TABLE trades ADD COLUMN filled_quantity DECIMAL(20,8) NOT NULL DEFAULT 0;
+UPDATE trades SET filled_quantity = quantity WHERE status = 'executed';
+
+ALTER TABLE trades DROP CONSTRAINT IF EXISTS trades_status_check;
+ALTER TABLE trades ADD CONSTRAINT trades_status_check
+    CHECK (status IN ('pending', 'partially_filled', 'executed', 'rejected', 'cancelled'));
+
+-- +goose Down
+UPDATE trades SET status = 'pending' WHERE status = 'partially_filled';
+
+ALTER TABLE trades DROP CONSTRAINT IF EXISTS trades_status_check;
+ALTER TABLE trades ADD CONSTRAINT trades_status_check
+    CHECK (status IN ('pending', 'executed', 'rejected', 'cancelled'));
+
+ALTER TABLE trades DROP COLUMN IF EXISTS filled_quantity;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Stop-loss and take-profit levels of the bracket order that last opened or added to a position
+ALTER TABLE positions ADD COLUMN stop_loss_price DECIMAL(20,8);
+ALTER TABLE positions ADD COLUMN take_profit_price DECIMAL(20,8);
+
+-- +goose Down
+ALTER TABLE positions DROP COLUMN IF EXISTS take_profit_price;
+ALTER TABLE positions DROP COLUMN IF EXISTS stop_loss_price;
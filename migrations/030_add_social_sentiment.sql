@@ -0,0 +1,16 @@
+-- +goose Up
+-- Score from the social sentiment agent (Reddit and StockTwits), and its agent runs
+ALTER TABLE recommendations ADD COLUMN social_score DECIMAL(5,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE agent_runs DROP CONSTRAINT IF EXISTS agent_runs_agent_type_check;
+ALTER TABLE agent_runs ADD CONSTRAINT agent_runs_agent_type_check
+    CHECK (agent_type IN ('fundamental', 'news', 'technical', 'social', 'manager'));
+
+-- +goose Down
+DELETE FROM agent_runs WHERE agent_type = 'social';
+
+ALTER TABLE agent_runs DROP CONSTRAINT IF EXISTS agent_runs_agent_type_check;
+ALTER TABLE agent_runs ADD CONSTRAINT agent_runs_agent_type_check
+    CHECK (agent_type IN ('fundamental', 'news', 'technical', 'manager'));
+
+ALTER TABLE recommendations DROP COLUMN IF EXISTS social_score;
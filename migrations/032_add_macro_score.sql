@@ -0,0 +1,16 @@
+-- +goose Up
+-- Score from the macro agent (FRED rates, inflation and unemployment), and its agent runs
+ALTER TABLE recommendations ADD COLUMN macro_score DECIMAL(5,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE agent_runs DROP CONSTRAINT IF EXISTS agent_runs_agent_type_check;
+ALTER TABLE agent_runs ADD CONSTRAINT agent_runs_agent_type_check
+    CHECK (agent_type IN ('fundamental', 'news', 'technical', 'social', 'insider', 'macro', 'manager'));
+
+-- +goose Down
+DELETE FROM agent_runs WHERE agent_type = 'macro';
+
+ALTER TABLE agent_runs DROP CONSTRAINT IF EXISTS agent_runs_agent_type_check;
+ALTER TABLE agent_runs ADD CONSTRAINT agent_runs_agent_type_check
+    CHECK (agent_type IN ('fundamental', 'news', 'technical', 'social', 'insider', 'manager'));
+
+ALTER TABLE recommendations DROP COLUMN IF EXISTS macro_score;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Broker each trade's order was routed to; every earlier order went to Alpaca
+ALTER TABLE trades ADD COLUMN broker VARCHAR(20) NOT NULL DEFAULT 'alpaca';
+
+-- +goose Down
+ALTER TABLE trades DROP COLUMN IF EXISTS broker;
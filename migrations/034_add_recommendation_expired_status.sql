@@ -0,0 +1,12 @@
+-- +goose Up
+-- Open recommendations left past their TTL, or after the price moved away, expire
+ALTER TABLE recommendations DROP CONSTRAINT IF EXISTS recommendations_status_check;
+ALTER TABLE recommendations ADD CONSTRAINT recommendations_status_check
+    CHECK (status IN ('pending', 'approved', 'rejected', 'executed', 'expired'));
+
+-- +goose Down
+UPDATE recommendations SET status = 'rejected' WHERE status = 'expired';
+
+ALTER TABLE recommendations DROP CONSTRAINT IF EXISTS recommendations_status_check;
+ALTER TABLE recommendations ADD CONSTRAINT recommendations_status_check
+    CHECK (status IN ('pending', 'approved', 'rejected', 'executed'));
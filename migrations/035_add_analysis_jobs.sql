@@ -0,0 +1,22 @@
+-- +goose Up
+-- Analyses started with ?async=true, polled by ID while their agents run
+CREATE TABLE analysis_jobs (
+    id UUID PRIMARY KEY,
+    symbol VARCHAR(10) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'completed', 'failed')),
+    recommendation_id UUID REFERENCES recommendations(id) ON DELETE SET NULL,
+    error_message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_analysis_jobs_created_at ON analysis_jobs(created_at DESC);
+
+-- Agent runs made for an analysis job
+ALTER TABLE agent_runs ADD COLUMN job_id UUID;
+CREATE INDEX idx_agent_runs_job_id ON agent_runs(job_id) WHERE job_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_agent_runs_job_id;
+ALTER TABLE agent_runs DROP COLUMN IF EXISTS job_id;
+DROP TABLE IF EXISTS analysis_jobs;
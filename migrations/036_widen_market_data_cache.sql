@@ -0,0 +1,15 @@
+-- +goose Up
+-- The cache also holds provider responses keyed by request, and risk stats, so keys and
+-- data types are no longer limited to symbols and the original four types
+ALTER TABLE market_data_cache DROP CONSTRAINT IF EXISTS market_data_cache_data_type_check;
+ALTER TABLE market_data_cache ALTER COLUMN symbol TYPE TEXT;
+ALTER TABLE market_data_cache ALTER COLUMN data_type TYPE VARCHAR(50);
+
+-- +goose Down
+DELETE FROM market_data_cache
+WHERE length(symbol) > 10 OR data_type NOT IN ('quote', 'fundamentals', 'news', 'technical');
+
+ALTER TABLE market_data_cache ALTER COLUMN data_type TYPE VARCHAR(20);
+ALTER TABLE market_data_cache ALTER COLUMN symbol TYPE VARCHAR(10);
+ALTER TABLE market_data_cache ADD CONSTRAINT market_data_cache_data_type_check
+    CHECK (data_type IN ('quote', 'fundamentals', 'news', 'technical'));
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Named screener criteria saved by the user, run with POST /api/screener/run?preset={name}
+CREATE TABLE screener_presets (
+    name VARCHAR(50) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    market_cap_min BIGINT NOT NULL DEFAULT 0,
+    market_cap_max BIGINT NOT NULL DEFAULT 0,
+    pe_ratio_max DOUBLE PRECISION NOT NULL DEFAULT 0,
+    pb_ratio_max DOUBLE PRECISION NOT NULL DEFAULT 0,
+    dividend_yield_min DOUBLE PRECISION NOT NULL DEFAULT 0,
+    eps_min DOUBLE PRECISION NOT NULL DEFAULT 0,
+    sector VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS screener_presets;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Dividends on held positions, expected once they go ex and received once paid
+CREATE TABLE dividends (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    symbol VARCHAR(10) NOT NULL,
+    ex_date DATE NOT NULL,
+    payment_date DATE,
+    amount_per_share DECIMAL(20,8) NOT NULL,
+    shares DECIMAL(20,8) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'expected' CHECK (status IN ('expected', 'received')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (symbol, ex_date)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS dividends;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Who approved, rejected or executed a recommendation, changed a setting or ran the
+-- screener, and when. Rows are only ever inserted.
+CREATE TABLE audit_log (
+    id UUID PRIMARY KEY,
+    action VARCHAR(50) NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    target VARCHAR(200) NOT NULL DEFAULT '',
+    details JSONB,
+    request_id VARCHAR(100) NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_log_occurred_at ON audit_log (occurred_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Tokens for the HTTP API when API_AUTH_ENABLED is set. Only a SHA-256 hash of each
+-- secret is kept.
+CREATE TABLE api_tokens (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    hint VARCHAR(10) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP WITH TIME ZONE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_tokens;
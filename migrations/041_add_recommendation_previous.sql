@@ -0,0 +1,14 @@
+-- +goose Up
+-- Link a re-analysis to the recommendation it followed, so the two can be compared
+ALTER TABLE recommendations
+ADD COLUMN previous_recommendation_id UUID REFERENCES recommendations(id) ON DELETE SET NULL;
+
+COMMENT ON COLUMN recommendations.previous_recommendation_id IS 'Recommendation a re-analysis was requested from (NULL for other analyses)';
+
+-- A symbol's recommendation history is read in order
+CREATE INDEX idx_recommendations_symbol_created_at ON recommendations(symbol, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_recommendations_symbol_created_at;
+ALTER TABLE recommendations
+DROP COLUMN IF EXISTS previous_recommendation_id;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Tokens and estimated cost of every LLM call made by an agent. agent_run_id is NULL for
+-- dry-run replays, which are charged but not recorded as agent runs.
+CREATE TABLE llm_usage (
+    id UUID PRIMARY KEY,
+    agent_run_id UUID,
+    agent_type VARCHAR(50) NOT NULL,
+    symbol VARCHAR(10) NOT NULL,
+    provider VARCHAR(20) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    input_tokens BIGINT NOT NULL DEFAULT 0,
+    output_tokens BIGINT NOT NULL DEFAULT 0,
+    cost_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_llm_usage_created_at ON llm_usage(created_at);
+CREATE INDEX idx_llm_usage_agent_run_id ON llm_usage(agent_run_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS llm_usage;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Account equity, cash and positions after each trading day's close, for the equity curve.
+-- cash_flow is deposits less withdrawals since the previous snapshot, so they aren't
+-- counted as returns.
+CREATE TABLE portfolio_snapshots (
+    date DATE PRIMARY KEY,
+    equity DECIMAL(20,8) NOT NULL,
+    cash DECIMAL(20,8) NOT NULL,
+    cash_flow DECIMAL(20,8) NOT NULL DEFAULT 0,
+    positions JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS portfolio_snapshots;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- A recommendation is claimed as executing before its order is placed with the broker, so
+-- a second execution cannot place another order
+ALTER TABLE recommendations DROP CONSTRAINT IF EXISTS recommendations_status_check;
+ALTER TABLE recommendations ADD CONSTRAINT recommendations_status_check
+    CHECK (status IN ('pending', 'approved', 'executing', 'rejected', 'executed', 'expired'));
+
+-- +goose Down
+UPDATE recommendations SET status = 'approved' WHERE status = 'executing';
+
+ALTER TABLE recommendations DROP CONSTRAINT IF EXISTS recommendations_status_check;
+ALTER TABLE recommendations ADD CONSTRAINT recommendations_status_check
+    CHECK (status IN ('pending', 'approved', 'rejected', 'executed', 'expired'));
@@ -0,0 +1,22 @@
+-- +goose Up
+-- An executed recommendation fails when the broker cancels, rejects or expires its order
+-- before anything fills
+ALTER TABLE recommendations DROP CONSTRAINT IF EXISTS recommendations_status_check;
+ALTER TABLE recommendations ADD CONSTRAINT recommendations_status_check
+    CHECK (status IN ('pending', 'approved', 'executing', 'rejected', 'executed', 'expired', 'failed'));
+
+ALTER TABLE recommendation_events DROP CONSTRAINT IF EXISTS recommendation_events_event_type_check;
+ALTER TABLE recommendation_events ADD CONSTRAINT recommendation_events_event_type_check
+    CHECK (event_type IN ('created', 'approved', 'rejected', 'executed', 'expired', 'edited', 'completed', 'failed'));
+
+-- +goose Down
+DELETE FROM recommendation_events WHERE event_type = 'failed';
+UPDATE recommendations SET status = 'executed' WHERE status = 'failed';
+
+ALTER TABLE recommendation_events DROP CONSTRAINT IF EXISTS recommendation_events_event_type_check;
+ALTER TABLE recommendation_events ADD CONSTRAINT recommendation_events_event_type_check
+    CHECK (event_type IN ('created', 'approved', 'rejected', 'executed', 'expired', 'edited', 'completed'));
+
+ALTER TABLE recommendations DROP CONSTRAINT IF EXISTS recommendations_status_check;
+ALTER TABLE recommendations ADD CONSTRAINT recommendations_status_check
+    CHECK (status IN ('pending', 'approved', 'executing', 'rejected', 'executed', 'expired'));
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Broker order IDs of the stop-loss and take-profit legs guarding a position, cancelled
+-- before an order that reduces it and polled for fills
+ALTER TABLE positions ADD COLUMN stop_loss_order_id VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE positions ADD COLUMN take_profit_order_id VARCHAR(64) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE positions DROP COLUMN IF EXISTS take_profit_order_id;
+ALTER TABLE positions DROP COLUMN IF EXISTS stop_loss_order_id;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Recommendations the embedding model rejected, skipped by the similarity index so one bad
+-- row isn't retried forever. Switching models retries them.
+CREATE TABLE analysis_embedding_failures (
+    recommendation_id UUID NOT NULL REFERENCES recommendations(id) ON DELETE CASCADE,
+    model VARCHAR(100) NOT NULL,
+    error TEXT NOT NULL,
+    failed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (recommendation_id, model)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS analysis_embedding_failures;
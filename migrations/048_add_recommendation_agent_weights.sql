@@ -0,0 +1,7 @@
+-- +goose Up
+-- Agent weights in effect when a recommendation was made, so attribution credits the agent
+-- that drove it at the time rather than under today's weights
+ALTER TABLE recommendations ADD COLUMN agent_weights JSONB;
+
+-- +goose Down
+ALTER TABLE recommendations DROP COLUMN IF EXISTS agent_weights;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- A tranche is claimed as submitting before its order goes to the broker, so a tranche
+-- whose trade failed to record isn't placed again on the next check
+ALTER TABLE recommendation_tranches DROP CONSTRAINT IF EXISTS recommendation_tranches_status_check;
+ALTER TABLE recommendation_tranches ADD CONSTRAINT recommendation_tranches_status_check
+    CHECK (status IN ('pending', 'submitting', 'submitted', 'failed', 'cancelled'));
+
+-- +goose Down
+UPDATE recommendation_tranches SET status = 'failed', error = 'interrupted while submitting' WHERE status = 'submitting';
+
+ALTER TABLE recommendation_tranches DROP CONSTRAINT IF EXISTS recommendation_tranches_status_check;
+ALTER TABLE recommendation_tranches ADD CONSTRAINT recommendation_tranches_status_check
+    CHECK (status IN ('pending', 'submitted', 'failed', 'cancelled'));
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidActivityCursor is returned for an activity cursor that isn't a timestamp,
+// optionally followed by an event ID
+var ErrInvalidActivityCursor = errors.New("invalid activity cursor")
+
+// ActivityType identifies the kind of event in the activity feed
+type ActivityType string
+
+const (
+	ActivityTradeFilled            ActivityType = "trade_filled"
+	ActivityRecommendationCreated  ActivityType = "recommendation_created"
+	ActivityRecommendationApproved ActivityType = "recommendation_approved"
+	ActivityRecommendationRejected ActivityType = "recommendation_rejected"
+	ActivityScreenerRun            ActivityType = "screener_run"
+	ActivityProviderAlert          ActivityType = "provider_alert"
+	ActivityDeposit                ActivityType = "deposit"
+	ActivityWithdrawal             ActivityType = "withdrawal"
+)
+
+// ActivityEvent is a single entry in the account activity feed, derived from trades,
+// recommendation events, screener runs, provider alerts, and the net deposits or
+// withdrawals recorded with each daily portfolio snapshot
+type ActivityEvent struct {
+	ID         uuid.UUID    `json:"id"` // ID of the source row (trade, recommendation, screener run, or provider alert), or one derived from the snapshot's date
+	Type       ActivityType `json:"type"`
+	Symbol     string       `json:"symbol,omitempty"`
+	Detail     string       `json:"detail"`
+	OccurredAt time.Time    `json:"occurred_at"`
+}
+
+// Label returns a human-readable name for the activity type
+func (t ActivityType) Label() string {
+	switch t {
+	case ActivityTradeFilled:
+		return "Trade filled"
+	case ActivityRecommendationCreated:
+		return "Recommendation"
+	case ActivityRecommendationApproved:
+		return "Approved"
+	case ActivityRecommendationRejected:
+		return "Rejected"
+	case ActivityScreenerRun:
+		return "Screener run"
+	case ActivityProviderAlert:
+		return "Provider alert"
+	case ActivityDeposit:
+		return "Deposit"
+	case ActivityWithdrawal:
+		return "Withdrawal"
+	default:
+		return string(t)
+	}
+}
+
+// ActivityCursor is the position of an event in the activity feed, which is ordered by
+// time and then ID, newest first. A page starts after the cursor, so events sharing a
+// timestamp are neither skipped nor repeated across pages.
+type ActivityCursor struct {
+	OccurredAt time.Time
+	ID         uuid.UUID
+}
+
+// Cursor returns the position of the event, for requesting the events after it
+func (e ActivityEvent) Cursor() ActivityCursor {
+	return ActivityCursor{OccurredAt: e.OccurredAt, ID: e.ID}
+}
+
+// String encodes the cursor as its RFC 3339 timestamp and ID joined by an underscore
+func (c ActivityCursor) String() string {
+	return c.OccurredAt.Format(time.RFC3339Nano) + "_" + c.ID.String()
+}
+
+// ParseActivityCursor decodes a cursor from String. A bare timestamp is accepted too and
+// starts the page with the events strictly before it.
+func ParseActivityCursor(s string) (ActivityCursor, error) {
+	at, id, hasID := strings.Cut(s, "_")
+	occurredAt, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return ActivityCursor{}, fmt.Errorf("%w: %q, expected an RFC 3339 timestamp", ErrInvalidActivityCursor, s)
+	}
+	cursor := ActivityCursor{OccurredAt: occurredAt}
+	if hasID {
+		if cursor.ID, err = uuid.Parse(id); err != nil {
+			return ActivityCursor{}, fmt.Errorf("%w: %q has an invalid event ID", ErrInvalidActivityCursor, s)
+		}
+	}
+	return cursor, nil
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestActivityCursor_RoundTrip(t *testing.T) {
+	e := ActivityEvent{ID: uuid.New(), OccurredAt: time.Date(2024, 3, 8, 14, 30, 0, 123456000, time.UTC)}
+
+	cursor, err := ParseActivityCursor(e.Cursor().String())
+	if err != nil {
+		t.Fatalf("ParseActivityCursor error = %v", err)
+	}
+	if !cursor.OccurredAt.Equal(e.OccurredAt) || cursor.ID != e.ID {
+		t.Errorf("cursor = %+v, want the event's time and ID", cursor)
+	}
+
+	// A bare timestamp pages from strictly before it
+	bare, err := ParseActivityCursor("2024-03-08T14:30:00Z")
+	if err != nil || bare.ID != uuid.Nil || !bare.OccurredAt.Equal(time.Date(2024, 3, 8, 14, 30, 0, 0, time.UTC)) {
+		t.Errorf("bare cursor = %+v, %v; want the time with no ID", bare, err)
+	}
+
+	for _, s := range []string{"yesterday", "2024-03-08T14:30:00Z_not-a-uuid"} {
+		if _, err := ParseActivityCursor(s); !errors.Is(err, ErrInvalidActivityCursor) {
+			t.Errorf("ParseActivityCursor(%q) error = %v, want ErrInvalidActivityCursor", s, err)
+		}
+	}
+}
@@ -17,6 +17,7 @@ type AgentRun struct {
 	DurationMs   int                    `json:"duration_ms"`
 	StartedAt    time.Time              `json:"started_at"`
 	CompletedAt  *time.Time             `json:"completed_at,omitempty"`
+	JobID        *uuid.UUID             `json:"job_id,omitempty"` // Set when run for an analysis job
 }
 
 type AgentType string
@@ -25,7 +26,11 @@ const (
 	AgentTypeFundamental AgentType = "fundamental"
 	AgentTypeNews        AgentType = "news"
 	AgentTypeTechnical   AgentType = "technical"
+	AgentTypeSocial      AgentType = "social"
+	AgentTypeInsider     AgentType = "insider"
+	AgentTypeMacro       AgentType = "macro"
 	AgentTypeManager     AgentType = "manager"
+	AgentTypeChat        AgentType = "chat" // Answers to questions about a symbol; only its LLM usage is recorded
 )
 
 type AgentRunStatus string
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AgentRunPromptKey is the agent run input holding the user prompt the agent sent its LLM,
+// which a replay sends again
+const AgentRunPromptKey = "user_prompt"
+
+// AgentRunPromptVersionKey is the agent run input holding the version of the customized
+// system prompt the agent used; it is absent for the built-in prompt
+const AgentRunPromptVersionKey = "prompt_version"
+
+var (
+	// ErrAgentRunNotFound is returned when replaying an agent run that does not exist
+	ErrAgentRunNotFound = errors.New("agent run not found")
+	// ErrAgentRunNotReplayable is returned when an agent run has no stored prompt or its
+	// agent does not prompt an LLM
+	ErrAgentRunNotReplayable = errors.New("agent run cannot be replayed")
+)
+
+// AgentRunOutput is the part of an agent's output compared between a run and its replay
+type AgentRunOutput struct {
+	Score      float64 `json:"score"`
+	Confidence float64 `json:"confidence"`
+	Reasoning  string  `json:"reasoning"`
+}
+
+// Prompt returns the user prompt stored on the run, or "" for runs made before prompts
+// were stored and for agents that don't prompt an LLM
+func (r *AgentRun) Prompt() string {
+	prompt, _ := r.InputData[AgentRunPromptKey].(string)
+	return prompt
+}
+
+// Output returns the score, confidence and reasoning the run produced, or nil if it failed
+func (r *AgentRun) Output() *AgentRunOutput {
+	if r.Status != AgentRunStatusCompleted {
+		return nil
+	}
+	score, _ := r.OutputData["score"].(float64)
+	confidence, _ := r.OutputData["confidence"].(float64)
+	reasoning, _ := r.OutputData["reasoning"].(string)
+	return &AgentRunOutput{Score: score, Confidence: confidence, Reasoning: reasoning}
+}
+
+// AgentRunReplay is a past agent run's prompt sent again with the agent's current system
+// prompt and model, next to what the run originally produced. PromptVersion is the
+// customized system prompt used, 0 for the built-in one. Original is nil when the run
+// failed. ReplayRunID is the agent run recording the replay, nil for a dry run.
+type AgentRunReplay struct {
+	RunID            uuid.UUID       `json:"run_id"`
+	AgentType        AgentType       `json:"agent_type"`
+	Symbol           string          `json:"symbol"`
+	Model            string          `json:"model,omitempty"`
+	PromptVersion    int             `json:"prompt_version"`
+	DryRun           bool            `json:"dry_run"`
+	ReplayRunID      *uuid.UUID      `json:"replay_run_id,omitempty"`
+	Original         *AgentRunOutput `json:"original,omitempty"`
+	Replayed         AgentRunOutput  `json:"replayed"`
+	ScoreDelta       float64         `json:"score_delta"`
+	ConfidenceDelta  float64         `json:"confidence_delta"`
+	ReasoningChanged bool            `json:"reasoning_changed"`
+	ReplayedAt       time.Time       `json:"replayed_at"`
+}
+
+// NewAgentRunReplay compares a replay's output with the run it replayed
+func NewAgentRunReplay(run *AgentRun, replayed AgentRunOutput) *AgentRunReplay {
+	replay := &AgentRunReplay{
+		RunID:            run.ID,
+		AgentType:        run.AgentType,
+		Symbol:           run.Symbol,
+		Original:         run.Output(),
+		Replayed:         replayed,
+		ReasoningChanged: true,
+		ReplayedAt:       time.Now(),
+	}
+	if replay.Original != nil {
+		replay.ScoreDelta = replayed.Score - replay.Original.Score
+		replay.ConfidenceDelta = replayed.Confidence - replay.Original.Confidence
+		replay.ReasoningChanged = replayed.Reasoning != replay.Original.Reasoning
+	}
+	return replay
+}
@@ -166,3 +166,27 @@ func TestAgentRun_AllAgentTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestNewAgentRunReplay(t *testing.T) {
+	run := NewAgentRun(AgentTypeNews, "AAPL")
+	run.InputData = map[string]interface{}{AgentRunPromptKey: "Analyze the following recent news about AAPL"}
+	run.Complete(map[string]interface{}{"score": 40.0, "confidence": 70.0, "reasoning": "Strong launch"})
+
+	if got := run.Prompt(); got != "Analyze the following recent news about AAPL" {
+		t.Errorf("Prompt = %q, want the stored prompt", got)
+	}
+
+	replay := NewAgentRunReplay(run, AgentRunOutput{Score: 25, Confidence: 75, Reasoning: "Strong launch"})
+	if replay.Original == nil || replay.ScoreDelta != -15 || replay.ConfidenceDelta != 5 || replay.ReasoningChanged {
+		t.Errorf("replay = %+v, want score down 15 and confidence up 5 with the same reasoning", replay)
+	}
+
+	failed := NewAgentRun(AgentTypeNews, "AAPL")
+	failed.Fail(errors.New("timeout"))
+	if replay := NewAgentRunReplay(failed, AgentRunOutput{Score: 10}); replay.Original != nil || replay.ScoreDelta != 0 || !replay.ReasoningChanged {
+		t.Errorf("replay of a failed run = %+v, want no original to compare", replay)
+	}
+	if got := failed.Prompt(); got != "" {
+		t.Errorf("Prompt without a stored prompt = %q, want empty", got)
+	}
+}
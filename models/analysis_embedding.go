@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmbeddingDimensions is the size of stored analysis embeddings. Models of another size
+// need OPENAI_EMBEDDING_DIMENSIONS set to this, if they can shorten their embeddings.
+const EmbeddingDimensions = 1536
+
+// ErrAnalysisNotIndexed is returned when a symbol has no embedded analysis to compare against
+var ErrAnalysisNotIndexed = errors.New("no indexed analysis for symbol")
+
+// AnalysisEmbedding is the embedding of a recommendation's reasoning, used to find past
+// analyses that reached similar conclusions
+type AnalysisEmbedding struct {
+	RecommendationID uuid.UUID `json:"recommendation_id"`
+	Symbol           string    `json:"symbol"`
+	Model            string    `json:"model"` // Embeddings from different models are not comparable
+	Vector           []float32 `json:"-"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// SimilarAnalysis is a past recommendation and how close its reasoning is to the query,
+// from -1 to 1 (cosine similarity)
+type SimilarAnalysis struct {
+	Recommendation Recommendation `json:"recommendation"`
+	Similarity     float64        `json:"similarity"`
+}
+
+// EmbeddingText returns the text embedded for a recommendation. The symbol is left out
+// so analyses of other companies with the same thesis rank as similar.
+func EmbeddingText(rec *Recommendation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Action: %s\n", rec.Action)
+	fmt.Fprintf(&b, "Scores: fundamental %.0f, sentiment %.0f, technical %.0f\n",
+		rec.FundamentalScore, rec.SentimentScore, rec.TechnicalScore)
+	b.WriteString(strings.TrimSpace(rec.Reasoning))
+	return b.String()
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmbeddingText(t *testing.T) {
+	rec := &Recommendation{
+		Symbol:           "AAPL",
+		Action:           RecommendationActionBuy,
+		FundamentalScore: 62,
+		SentimentScore:   -10,
+		TechnicalScore:   35,
+		Reasoning:        "  Margins expanding on services growth.\n",
+	}
+
+	text := EmbeddingText(rec)
+	for _, want := range []string{"Action: buy", "fundamental 62, sentiment -10, technical 35", "Margins expanding on services growth."} {
+		if !strings.Contains(text, want) {
+			t.Errorf("EmbeddingText() = %q, missing %q", text, want)
+		}
+	}
+	if strings.Contains(text, "AAPL") {
+		t.Errorf("EmbeddingText() = %q, should not include the symbol", text)
+	}
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AnalysisJobStatus is the state of an analysis started in the background
+type AnalysisJobStatus string
+
+const (
+	AnalysisJobStatusRunning   AnalysisJobStatus = "running"
+	AnalysisJobStatusCompleted AnalysisJobStatus = "completed"
+	AnalysisJobStatusFailed    AnalysisJobStatus = "failed"
+)
+
+// AnalysisJob is a single-symbol analysis run in the background, so the request that
+// starts it returns before the agents finish. Agents lists the job's agent runs as they
+// are recorded, and Recommendation is the result once the job completes.
+type AnalysisJob struct {
+	ID               uuid.UUID         `json:"id"`
+	Symbol           string            `json:"symbol"`
+	Status           AnalysisJobStatus `json:"status"`
+	RecommendationID *uuid.UUID        `json:"recommendation_id,omitempty"`
+	ErrorMessage     string            `json:"error_message,omitempty"`
+	Agents           []AgentRun        `json:"agents"`
+	Recommendation   *Recommendation   `json:"recommendation,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	CompletedAt      *time.Time        `json:"completed_at,omitempty"`
+}
+
+// NewAnalysisJob creates a running analysis job for symbol
+func NewAnalysisJob(symbol string) *AnalysisJob {
+	return &AnalysisJob{
+		ID:        uuid.New(),
+		Symbol:    symbol,
+		Status:    AnalysisJobStatusRunning,
+		Agents:    []AgentRun{},
+		CreatedAt: time.Now(),
+	}
+}
+
+// Complete marks the job completed with the recommendation it produced
+func (j *AnalysisJob) Complete(rec *Recommendation) {
+	now := time.Now()
+	j.CompletedAt = &now
+	j.Status = AnalysisJobStatusCompleted
+	j.Recommendation = rec
+	if rec != nil {
+		id := rec.ID
+		j.RecommendationID = &id
+	}
+}
+
+// Fail marks the job failed with err
+func (j *AnalysisJob) Fail(err error) {
+	now := time.Now()
+	j.CompletedAt = &now
+	j.Status = AnalysisJobStatusFailed
+	j.ErrorMessage = err.Error()
+}
+
+type analysisJobKey struct{}
+
+// WithAnalysisJob returns a context that records the analysis job an analysis runs for,
+// so its agent runs can be tied to the job
+func WithAnalysisJob(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, analysisJobKey{}, id)
+}
+
+// AnalysisJobFromContext returns the job ID set by WithAnalysisJob, or false when the
+// analysis is not part of a job
+func AnalysisJobFromContext(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(analysisJobKey{}).(uuid.UUID)
+	return id, ok
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAnalysisJob_Complete(t *testing.T) {
+	job := NewAnalysisJob("AAPL")
+	if job.Status != AnalysisJobStatusRunning || job.CompletedAt != nil {
+		t.Fatalf("job = %+v, want running", job)
+	}
+
+	rec := NewRecommendation("AAPL", RecommendationActionBuy, "test")
+	job.Complete(rec)
+	if job.Status != AnalysisJobStatusCompleted || job.CompletedAt == nil {
+		t.Errorf("job = %+v, want completed", job)
+	}
+	if job.RecommendationID == nil || *job.RecommendationID != rec.ID {
+		t.Errorf("RecommendationID = %v, want %s", job.RecommendationID, rec.ID)
+	}
+
+	failed := NewAnalysisJob("MSFT")
+	failed.Fail(errors.New("all agents failed"))
+	if failed.Status != AnalysisJobStatusFailed || failed.ErrorMessage != "all agents failed" {
+		t.Errorf("job = %+v, want failed with its error", failed)
+	}
+}
+
+func TestAnalysisJobFromContext(t *testing.T) {
+	if _, ok := AnalysisJobFromContext(context.Background()); ok {
+		t.Error("expected no job on a plain context")
+	}
+	job := NewAnalysisJob("AAPL")
+	if id, ok := AnalysisJobFromContext(WithAnalysisJob(context.Background(), job.ID)); !ok || id != job.ID {
+		t.Errorf("AnalysisJobFromContext() = %s, %v; want %s", id, ok, job.ID)
+	}
+}
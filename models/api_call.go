@@ -0,0 +1,82 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// APICall is one outbound request to an external provider, as recorded in the call ledger
+type APICall struct {
+	Provider   string    `json:"provider"` // Circuit breaker name of the provider (fmp, newsapi, openai, ...)
+	Endpoint   string    `json:"endpoint"` // URL path with symbols and IDs replaced by placeholders
+	Status     int       `json:"status"`   // HTTP status; 0 when no response was received
+	LatencyMs  int64     `json:"latency_ms"`
+	Bytes      int64     `json:"bytes"` // Response body size
+	Cached     bool      `json:"cached"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Failed reports whether the call got no response or an error status
+func (c APICall) Failed() bool {
+	return c.Status == 0 || c.Status >= 400
+}
+
+// APIUsage aggregates the calls made to one provider endpoint. Day is zero for totals
+// spanning several days.
+type APIUsage struct {
+	Day            time.Time `json:"day,omitempty"`
+	Provider       string    `json:"provider"`
+	Endpoint       string    `json:"endpoint,omitempty"` // Empty for per-provider totals
+	Calls          int64     `json:"calls"`
+	Errors         int64     `json:"errors"`
+	CachedCalls    int64     `json:"cached_calls"`
+	Bytes          int64     `json:"bytes"`
+	TotalLatencyMs int64     `json:"total_latency_ms"`
+}
+
+// AvgLatencyMs returns the mean call latency in milliseconds
+func (u APIUsage) AvgLatencyMs() float64 {
+	if u.Calls == 0 {
+		return 0
+	}
+	return float64(u.TotalLatencyMs) / float64(u.Calls)
+}
+
+// APIUsageReport summarizes daily API usage since a given day
+type APIUsageReport struct {
+	Since     time.Time  `json:"since"`
+	Providers []APIUsage `json:"providers"` // Totals per provider, most calls first
+	Daily     []APIUsage `json:"daily"`     // Per day and endpoint, newest day first
+}
+
+// NewAPIUsageReport totals daily usage rows per provider
+func NewAPIUsageReport(since time.Time, daily []APIUsage) *APIUsageReport {
+	totals := make(map[string]*APIUsage)
+	for _, u := range daily {
+		t, ok := totals[u.Provider]
+		if !ok {
+			t = &APIUsage{Provider: u.Provider}
+			totals[u.Provider] = t
+		}
+		t.Calls += u.Calls
+		t.Errors += u.Errors
+		t.CachedCalls += u.CachedCalls
+		t.Bytes += u.Bytes
+		t.TotalLatencyMs += u.TotalLatencyMs
+	}
+
+	report := &APIUsageReport{Since: since, Providers: []APIUsage{}, Daily: daily}
+	if report.Daily == nil {
+		report.Daily = []APIUsage{}
+	}
+	for _, t := range totals {
+		report.Providers = append(report.Providers, *t)
+	}
+	sort.Slice(report.Providers, func(i, j int) bool {
+		if report.Providers[i].Calls != report.Providers[j].Calls {
+			return report.Providers[i].Calls > report.Providers[j].Calls
+		}
+		return report.Providers[i].Provider < report.Providers[j].Provider
+	})
+	return report
+}
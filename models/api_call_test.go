@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAPICall_Failed(t *testing.T) {
+	for status, want := range map[int]bool{0: true, 200: false, 304: false, 429: true, 500: true} {
+		if got := (APICall{Status: status}).Failed(); got != want {
+			t.Errorf("Failed() for status %d = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestNewAPIUsageReport(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	daily := []APIUsage{
+		{Day: day, Provider: "fmp", Endpoint: "/api/v3/profile/{symbol}", Calls: 10, Errors: 1, Bytes: 1000, TotalLatencyMs: 2000},
+		{Day: day, Provider: "fmp", Endpoint: "/api/v3/stock-screener", Calls: 2, Bytes: 500, TotalLatencyMs: 400},
+		{Day: day.AddDate(0, 0, -1), Provider: "newsapi", Endpoint: "/v2/everything", Calls: 3, CachedCalls: 1, TotalLatencyMs: 300},
+	}
+
+	report := NewAPIUsageReport(day.AddDate(0, 0, -7), daily)
+	if len(report.Providers) != 2 {
+		t.Fatalf("got %d provider totals, want 2", len(report.Providers))
+	}
+	fmp := report.Providers[0]
+	if fmp.Provider != "fmp" || fmp.Calls != 12 || fmp.Errors != 1 || fmp.Bytes != 1500 {
+		t.Errorf("fmp totals = %+v, want 12 calls, 1 error, 1500 bytes", fmp)
+	}
+	if fmp.AvgLatencyMs() != 200 {
+		t.Errorf("AvgLatencyMs() = %v, want 200", fmp.AvgLatencyMs())
+	}
+	if report.Providers[1].CachedCalls != 1 {
+		t.Errorf("newsapi cached calls = %d, want 1", report.Providers[1].CachedCalls)
+	}
+
+	empty := NewAPIUsageReport(day, nil)
+	if empty.Daily == nil || empty.Providers == nil {
+		t.Error("expected empty slices rather than nil for JSON output")
+	}
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIScope grants an API token access to a group of endpoints
+type APIScope string
+
+const (
+	APIScopeRead    APIScope = "read"    // Reading portfolio, recommendations, runs and metrics
+	APIScopeWrite   APIScope = "write"   // Analyses, screener runs, watchlists and other changes that place no orders
+	APIScopeApprove APIScope = "approve" // Approving, rejecting, splitting and editing recommendations
+	APIScopeTrade   APIScope = "trade"   // Executing recommendations and rebalances
+	APIScopeAdmin   APIScope = "admin"   // Settings, API keys, tokens and every other scope
+)
+
+// APIScopes lists every scope a token can be granted
+var APIScopes = []APIScope{APIScopeRead, APIScopeWrite, APIScopeApprove, APIScopeTrade, APIScopeAdmin}
+
+// APITokenPrefix starts every generated token, so a leaked token is easy to recognize
+const APITokenPrefix = "tm_"
+
+// apiTokenNameLimit is the longest token name accepted
+const apiTokenNameLimit = 100
+
+// ErrInvalidAPIToken is returned when a token is requested without a name or with unknown scopes
+var ErrInvalidAPIToken = errors.New("invalid API token")
+
+// APIToken authenticates requests to the HTTP API. Only a hash of the secret is stored;
+// the secret itself is shown once, when the token is created.
+type APIToken struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []APIScope `json:"scopes"`
+	Hint       string     `json:"hint"` // Last characters of the secret, to tell tokens apart
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// NewAPIToken creates a token with the given scopes and returns it with its secret
+func NewAPIToken(name string, scopes []APIScope) (*APIToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > apiTokenNameLimit {
+		return nil, "", fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidAPIToken, apiTokenNameLimit)
+	}
+	if len(scopes) == 0 {
+		return nil, "", fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIToken)
+	}
+	var granted []APIScope
+	for _, s := range scopes {
+		if !slices.Contains(APIScopes, s) {
+			return nil, "", fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIToken, s)
+		}
+		if !slices.Contains(granted, s) {
+			granted = append(granted, s)
+		}
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	secret := APITokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	return &APIToken{
+		ID:        uuid.New(),
+		Name:      name,
+		Scopes:    granted,
+		Hint:      secret[len(secret)-4:],
+		CreatedAt: time.Now(),
+	}, secret, nil
+}
+
+// HashAPIToken returns the hash a token's secret is stored and looked up by
+func HashAPIToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// HasScope reports whether the token grants scope. The admin scope grants every scope.
+func (t *APIToken) HasScope(scope APIScope) bool {
+	return slices.Contains(t.Scopes, scope) || slices.Contains(t.Scopes, APIScopeAdmin)
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewAPIToken(t *testing.T) {
+	token, secret, err := NewAPIToken(" ci ", []APIScope{APIScopeRead, APIScopeApprove, APIScopeRead})
+	if err != nil {
+		t.Fatalf("NewAPIToken error = %v", err)
+	}
+	if token.Name != "ci" || len(token.Scopes) != 2 {
+		t.Errorf("token = %+v, want the trimmed name and each scope once", token)
+	}
+	if !strings.HasPrefix(secret, APITokenPrefix) || !strings.HasSuffix(secret, token.Hint) {
+		t.Errorf("secret = %q, want the prefix and ending in hint %q", secret, token.Hint)
+	}
+	if HashAPIToken(secret) == HashAPIToken(secret+"x") || len(HashAPIToken(secret)) != 64 {
+		t.Error("expected distinct SHA-256 hex hashes")
+	}
+
+	_, other, _ := NewAPIToken("ci", []APIScope{APIScopeRead})
+	if other == secret {
+		t.Error("expected every token to get a new secret")
+	}
+
+	for _, tt := range []struct {
+		name   string
+		scopes []APIScope
+	}{
+		{"", []APIScope{APIScopeRead}},
+		{"ci", nil},
+		{"ci", []APIScope{"delete"}},
+	} {
+		if _, _, err := NewAPIToken(tt.name, tt.scopes); !errors.Is(err, ErrInvalidAPIToken) {
+			t.Errorf("NewAPIToken(%q, %v) error = %v, want ErrInvalidAPIToken", tt.name, tt.scopes, err)
+		}
+	}
+}
+
+func TestAPIToken_HasScope(t *testing.T) {
+	reader := &APIToken{Scopes: []APIScope{APIScopeRead}}
+	if !reader.HasScope(APIScopeRead) || reader.HasScope(APIScopeTrade) {
+		t.Error("expected a read token to grant only read")
+	}
+	admin := &APIToken{Scopes: []APIScope{APIScopeAdmin}}
+	if !admin.HasScope(APIScopeTrade) {
+		t.Error("expected admin to grant every scope")
+	}
+}
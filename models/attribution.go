@@ -0,0 +1,278 @@
+package models
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// AgentUnattributed groups closed positions without a driving agent: those opened
+// outside the app, or whose recommendation no agent agreed with
+const AgentUnattributed AgentType = "unattributed"
+
+// attributedAgents are the agents that can drive a recommendation
+var attributedAgents = []AgentType{AgentTypeFundamental, AgentTypeNews, AgentTypeTechnical, AgentTypeSocial, AgentTypeInsider, AgentTypeMacro}
+
+// attributionGroups are the groups reported on, in display order
+var attributionGroups = []AgentType{AgentTypeFundamental, AgentTypeNews, AgentTypeTechnical, AgentTypeSocial, AgentTypeInsider, AgentTypeMacro, AgentUnattributed}
+
+// optionalAttributionGroups are listed only when they drove a position: the social,
+// insider and macro agents are off unless configured
+var optionalAttributionGroups = []AgentType{AgentTypeSocial, AgentTypeInsider, AgentTypeMacro, AgentUnattributed}
+
+// AgentWeights are the weights the portfolio manager gives each agent's score
+type AgentWeights struct {
+	Fundamental float64 `json:"fundamental"`
+	News        float64 `json:"news"`
+	Technical   float64 `json:"technical"`
+	Social      float64 `json:"social"`
+	Insider     float64 `json:"insider"`
+	Macro       float64 `json:"macro"`
+}
+
+// DrivingAgent returns the agent whose weighted score pushed hardest toward the
+// recommendation's action: the most positive for buys and covers, the most negative
+// for sells and shorts. Agents that did not report are skipped. Returns false for
+// holds and when no reporting agent agreed with the action.
+func DrivingAgent(rec *Recommendation, weights AgentWeights) (AgentType, bool) {
+	var direction float64
+	switch rec.Action {
+	case RecommendationActionBuy, RecommendationActionCover:
+		direction = 1
+	case RecommendationActionSell, RecommendationActionShort:
+		direction = -1
+	default:
+		return "", false
+	}
+
+	missing := make(map[AgentType]bool)
+	for _, m := range rec.MissingAgents {
+		missing[m.AgentType] = true
+	}
+	contributions := map[AgentType]float64{
+		AgentTypeFundamental: rec.FundamentalScore * weights.Fundamental,
+		AgentTypeNews:        rec.SentimentScore * weights.News,
+		AgentTypeTechnical:   rec.TechnicalScore * weights.Technical,
+		AgentTypeSocial:      rec.SocialScore * weights.Social,
+		AgentTypeInsider:     rec.InsiderScore * weights.Insider,
+		AgentTypeMacro:       rec.MacroScore * weights.Macro,
+	}
+
+	var driver AgentType
+	best := 0.0
+	for _, agent := range attributedAgents {
+		if missing[agent] {
+			continue
+		}
+		if c := contributions[agent] * direction; c > best {
+			best, driver = c, agent
+		}
+	}
+	return driver, driver != ""
+}
+
+// AttributedPosition is a closed position, from opening to flat again, credited to the
+// agent that drove the recommendation that opened it
+type AttributedPosition struct {
+	Symbol           string          `json:"symbol"`
+	Side             PositionSide    `json:"side"`
+	RecommendationID *uuid.UUID      `json:"recommendation_id,omitempty"` // Nil when the opening trade had no recommendation
+	Driver           AgentType       `json:"driver"`
+	OpenedAt         time.Time       `json:"opened_at"`
+	ClosedAt         time.Time       `json:"closed_at"`
+	RealizedPL       decimal.Decimal `json:"realized_pl"` // Net of commission and fees
+}
+
+// AgentAttribution aggregates the closed positions credited to one agent
+type AgentAttribution struct {
+	Agent      AgentType       `json:"agent"`
+	Positions  int             `json:"positions"`
+	Winners    int             `json:"winners"`
+	Losers     int             `json:"losers"`
+	WinRate    float64         `json:"win_rate"` // % of positions closed with a profit
+	RealizedPL decimal.Decimal `json:"realized_pl"`
+	AveragePL  decimal.Decimal `json:"average_pl"`
+}
+
+// AttributionPeriod is the attribution of positions closed in one calendar month
+type AttributionPeriod struct {
+	Month  string             `json:"month"` // YYYY-MM
+	Agents []AgentAttribution `json:"agents"`
+}
+
+// AttributionReport decomposes realized P&L of closed positions by the agent that most
+// strongly drove each opening recommendation
+type AttributionReport struct {
+	Since     *time.Time           `json:"since,omitempty"` // Only positions closed on or after this time; nil for all
+	Weights   AgentWeights         `json:"weights"`         // Current weights, used for recommendations made before weights were recorded on them
+	Agents    []AgentAttribution   `json:"agents"`
+	Periods   []AttributionPeriod  `json:"periods"`   // Oldest month first
+	Positions []AttributedPosition `json:"positions"` // Most recently closed first
+}
+
+// openPosition tracks a position from its opening trade while replaying trades
+type openPosition struct {
+	quantity decimal.Decimal // Signed: negative for shorts
+	cashFlow decimal.Decimal // Net cash moved by the position's trades so far
+	opened   AttributedPosition
+}
+
+// BuildAttributionReport replays executed trades, oldest first, to find every position
+// that was opened and closed again, and credits its realized P&L to the driving agent
+// of the recommendation whose trade opened it, under the weights recorded on that
+// recommendation; weights is the fallback for older ones. A trade that flips a position
+// from long to short closes one position and opens the next, splitting its cash flow pro
+// rata.
+func BuildAttributionReport(trades []Trade, recs []Recommendation, weights AgentWeights, since *time.Time) *AttributionReport {
+	recByTrade := make(map[uuid.UUID]*Recommendation)
+	for i := range recs {
+		if recs[i].ExecutedTradeID != nil {
+			recByTrade[*recs[i].ExecutedTradeID] = &recs[i]
+		}
+	}
+
+	executed := make([]Trade, 0, len(trades))
+	for _, t := range trades {
+		if t.ExecutedAt != nil && t.Quantity.IsPositive() {
+			executed = append(executed, t)
+		}
+	}
+	sort.SliceStable(executed, func(i, j int) bool {
+		return executed[i].ExecutedAt.Before(*executed[j].ExecutedAt)
+	})
+
+	report := &AttributionReport{Since: since, Weights: weights, Positions: []AttributedPosition{}}
+	open := make(map[string]*openPosition)
+	for _, t := range executed {
+		delta := t.Quantity
+		if t.Side == TradeSideSell {
+			delta = delta.Neg()
+		}
+		flow := t.CashFlow()
+
+		pos, ok := open[t.Symbol]
+		if !ok {
+			open[t.Symbol] = openAttributed(t, delta, flow, recByTrade, weights)
+			continue
+		}
+
+		remaining := pos.quantity.Add(delta)
+		switch {
+		case remaining.IsZero():
+			pos.cashFlow = pos.cashFlow.Add(flow)
+			report.close(pos, *t.ExecutedAt)
+			delete(open, t.Symbol)
+		case remaining.Sign() != pos.quantity.Sign():
+			closing := pos.quantity.Abs().Div(t.Quantity)
+			pos.cashFlow = pos.cashFlow.Add(flow.Mul(closing))
+			report.close(pos, *t.ExecutedAt)
+			open[t.Symbol] = openAttributed(t, remaining, flow.Sub(flow.Mul(closing)), recByTrade, weights)
+		default:
+			pos.quantity = remaining
+			pos.cashFlow = pos.cashFlow.Add(flow)
+		}
+	}
+
+	report.aggregate()
+	return report
+}
+
+// openAttributed starts a position opened by trade t
+func openAttributed(t Trade, quantity, flow decimal.Decimal, recByTrade map[uuid.UUID]*Recommendation, weights AgentWeights) *openPosition {
+	pos := &openPosition{
+		quantity: quantity,
+		cashFlow: flow,
+		opened: AttributedPosition{
+			Symbol:   t.Symbol,
+			Side:     PositionSideLong,
+			Driver:   AgentUnattributed,
+			OpenedAt: *t.ExecutedAt,
+		},
+	}
+	if quantity.IsNegative() {
+		pos.opened.Side = PositionSideShort
+	}
+	if rec, ok := recByTrade[t.ID]; ok {
+		id := rec.ID
+		pos.opened.RecommendationID = &id
+		if rec.AgentWeights != nil {
+			weights = *rec.AgentWeights
+		}
+		if driver, ok := DrivingAgent(rec, weights); ok {
+			pos.opened.Driver = driver
+		}
+	}
+	return pos
+}
+
+// close records pos as closed at closedAt, unless it closed before the report period
+func (r *AttributionReport) close(pos *openPosition, closedAt time.Time) {
+	if r.Since != nil && closedAt.Before(*r.Since) {
+		return
+	}
+	closed := pos.opened
+	closed.ClosedAt = closedAt
+	closed.RealizedPL = pos.cashFlow.Round(2)
+	r.Positions = append(r.Positions, closed)
+}
+
+// aggregate totals the closed positions per agent, overall and per month of closing
+func (r *AttributionReport) aggregate() {
+	r.Agents = summarizeAttribution(r.Positions)
+
+	byMonth := make(map[string][]AttributedPosition)
+	for _, p := range r.Positions {
+		month := p.ClosedAt.In(marketLocation).Format("2006-01")
+		byMonth[month] = append(byMonth[month], p)
+	}
+	months := make([]string, 0, len(byMonth))
+	for month := range byMonth {
+		months = append(months, month)
+	}
+	sort.Strings(months)
+	r.Periods = make([]AttributionPeriod, 0, len(months))
+	for _, month := range months {
+		r.Periods = append(r.Periods, AttributionPeriod{Month: month, Agents: summarizeAttribution(byMonth[month])})
+	}
+
+	sort.SliceStable(r.Positions, func(i, j int) bool {
+		return r.Positions[i].ClosedAt.After(r.Positions[j].ClosedAt)
+	})
+}
+
+// summarizeAttribution totals positions per agent. Every agent is listed so one that
+// drove no trades shows as such; optional agents and unattributed positions are listed only
+// when present.
+func summarizeAttribution(positions []AttributedPosition) []AgentAttribution {
+	totals := make(map[AgentType]*AgentAttribution)
+	for _, agent := range attributionGroups {
+		totals[agent] = &AgentAttribution{Agent: agent}
+	}
+	for _, p := range positions {
+		a := totals[p.Driver]
+		a.Positions++
+		a.RealizedPL = a.RealizedPL.Add(p.RealizedPL)
+		if p.RealizedPL.IsPositive() {
+			a.Winners++
+		} else if p.RealizedPL.IsNegative() {
+			a.Losers++
+		}
+	}
+
+	result := make([]AgentAttribution, 0, len(totals))
+	for _, agent := range attributionGroups {
+		a := totals[agent]
+		if a.Positions == 0 && slices.Contains(optionalAttributionGroups, agent) {
+			continue
+		}
+		if a.Positions > 0 {
+			a.WinRate = float64(a.Winners) / float64(a.Positions) * 100
+			a.AveragePL = a.RealizedPL.Div(decimal.NewFromInt(int64(a.Positions))).Round(2)
+		}
+		result = append(result, *a)
+	}
+	return result
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var testAttributionWeights = AgentWeights{Fundamental: 0.4, News: 0.3, Technical: 0.3}
+
+func attributionTrade(symbol string, side TradeSide, qty, price int64, at time.Time) Trade {
+	t := NewTrade(symbol, side, decimal.NewFromInt(qty), decimal.NewFromInt(price))
+	t.Status = TradeStatusExecuted
+	t.ExecutedAt = &at
+	return *t
+}
+
+func attributionRec(action RecommendationAction, fundamental, sentiment, technical float64, trade Trade) Recommendation {
+	rec := NewRecommendation(trade.Symbol, action, "")
+	rec.FundamentalScore = fundamental
+	rec.SentimentScore = sentiment
+	rec.TechnicalScore = technical
+	rec.ExecutedTradeID = &trade.ID
+	return *rec
+}
+
+func TestDrivingAgent(t *testing.T) {
+	tests := []struct {
+		name   string
+		rec    Recommendation
+		want   AgentType
+		wantOK bool
+	}{
+		{"buy driven by news", Recommendation{Action: RecommendationActionBuy, FundamentalScore: 20, SentimentScore: 80, TechnicalScore: 10}, AgentTypeNews, true},
+		{"weights decide", Recommendation{Action: RecommendationActionBuy, FundamentalScore: 50, SentimentScore: 60}, AgentTypeFundamental, true},
+		{"short driven by the most negative", Recommendation{Action: RecommendationActionShort, FundamentalScore: 30, SentimentScore: -20, TechnicalScore: -70}, AgentTypeTechnical, true},
+		{"missing agents are skipped", Recommendation{Action: RecommendationActionBuy, FundamentalScore: 90, SentimentScore: 10, MissingAgents: []MissingAgentInfo{{AgentType: AgentTypeFundamental}}}, AgentTypeNews, true},
+		{"no agent agrees", Recommendation{Action: RecommendationActionBuy, FundamentalScore: -10, SentimentScore: -5}, "", false},
+		{"hold", Recommendation{Action: RecommendationActionHold, FundamentalScore: 90}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := DrivingAgent(&tt.rec, testAttributionWeights)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("DrivingAgent() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestBuildAttributionReport(t *testing.T) {
+	day := time.Date(2024, 3, 4, 15, 0, 0, 0, time.UTC)
+
+	// News-driven AAPL buy closed at a loss over two sells
+	aaplBuy := attributionTrade("AAPL", TradeSideBuy, 10, 100, day)
+	aaplSell1 := attributionTrade("AAPL", TradeSideSell, 4, 95, day.AddDate(0, 0, 1))
+	aaplSell2 := attributionTrade("AAPL", TradeSideSell, 6, 90, day.AddDate(0, 0, 2))
+	// Fundamental-driven MSFT buy closed at a profit in the next month
+	msftBuy := attributionTrade("MSFT", TradeSideBuy, 5, 200, day)
+	msftSell := attributionTrade("MSFT", TradeSideSell, 5, 220, day.AddDate(0, 1, 0))
+	// NVDA bought outside the app and still open
+	nvdaBuy := attributionTrade("NVDA", TradeSideBuy, 3, 500, day)
+
+	trades := []Trade{msftSell, aaplSell2, aaplBuy, aaplSell1, msftBuy, nvdaBuy}
+	recs := []Recommendation{
+		attributionRec(RecommendationActionBuy, 10, 90, 20, aaplBuy),
+		attributionRec(RecommendationActionBuy, 80, 10, 30, msftBuy),
+		attributionRec(RecommendationActionSell, -50, -50, -50, aaplSell1),
+	}
+
+	report := BuildAttributionReport(trades, recs, testAttributionWeights, nil)
+
+	if len(report.Positions) != 2 {
+		t.Fatalf("expected 2 closed positions, got %d", len(report.Positions))
+	}
+	if p := report.Positions[0]; p.Symbol != "MSFT" || p.Driver != AgentTypeFundamental || !p.RealizedPL.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("newest position = %+v, want MSFT driven by fundamentals with 100 profit", p)
+	}
+	if p := report.Positions[1]; p.Symbol != "AAPL" || p.Driver != AgentTypeNews || !p.RealizedPL.Equal(decimal.NewFromInt(-80)) {
+		t.Errorf("oldest position = %+v, want AAPL driven by news with an 80 loss", p)
+	}
+
+	if len(report.Agents) != 3 {
+		t.Fatalf("expected the three agents without unattributed positions, got %+v", report.Agents)
+	}
+	news := report.Agents[1]
+	if news.Agent != AgentTypeNews || news.Positions != 1 || news.Losers != 1 || news.WinRate != 0 || !news.AveragePL.Equal(decimal.NewFromInt(-80)) {
+		t.Errorf("news attribution = %+v, want one losing position", news)
+	}
+	if technical := report.Agents[2]; technical.Positions != 0 {
+		t.Errorf("technical attribution = %+v, want no positions", technical)
+	}
+
+	if len(report.Periods) != 2 || report.Periods[0].Month != "2024-03" || report.Periods[1].Month != "2024-04" {
+		t.Errorf("periods = %+v, want March then April", report.Periods)
+	}
+
+	since := day.AddDate(0, 0, 20)
+	report = BuildAttributionReport(trades, recs, testAttributionWeights, &since)
+	if len(report.Positions) != 1 || report.Positions[0].Symbol != "MSFT" {
+		t.Errorf("expected only MSFT closed since %s, got %+v", since, report.Positions)
+	}
+}
+
+func TestBuildAttributionReport_FlipAndUnattributed(t *testing.T) {
+	day := time.Date(2024, 3, 4, 15, 0, 0, 0, time.UTC)
+
+	// Bought outside the app, then one sell closes the long and opens a short
+	buy := attributionTrade("TSLA", TradeSideBuy, 10, 100, day)
+	flip := attributionTrade("TSLA", TradeSideSell, 15, 110, day.AddDate(0, 0, 1))
+	cover := attributionTrade("TSLA", TradeSideBuy, 5, 100, day.AddDate(0, 0, 2))
+	recs := []Recommendation{attributionRec(RecommendationActionShort, -20, -10, -60, flip)}
+
+	report := BuildAttributionReport([]Trade{buy, flip, cover}, recs, testAttributionWeights, nil)
+
+	if len(report.Positions) != 2 {
+		t.Fatalf("expected the long and the short closed, got %+v", report.Positions)
+	}
+	short, long := report.Positions[0], report.Positions[1]
+	if short.Side != PositionSideShort || short.Driver != AgentTypeTechnical || short.RecommendationID == nil || !short.RealizedPL.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("short = %+v, want a technical-driven short with 50 profit", short)
+	}
+	if long.Side != PositionSideLong || long.Driver != AgentUnattributed || long.RecommendationID != nil || !long.RealizedPL.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("long = %+v, want an unattributed long with 100 profit", long)
+	}
+	if last := report.Agents[len(report.Agents)-1]; last.Agent != AgentUnattributed || last.Positions != 1 {
+		t.Errorf("expected unattributed positions listed last, got %+v", report.Agents)
+	}
+}
+
+func TestBuildAttributionReport_RecordedWeights(t *testing.T) {
+	day := time.Date(2024, 3, 4, 15, 0, 0, 0, time.UTC)
+	buy := attributionTrade("AAPL", TradeSideBuy, 10, 100, day)
+	sell := attributionTrade("AAPL", TradeSideSell, 10, 110, day.AddDate(0, 0, 1))
+
+	// Fundamentals lead under today's weights, but technicals carried all the weight then
+	rec := attributionRec(RecommendationActionBuy, 80, 10, 30, buy)
+	rec.AgentWeights = &AgentWeights{Technical: 1}
+
+	report := BuildAttributionReport([]Trade{buy, sell}, []Recommendation{rec}, testAttributionWeights, nil)
+	if len(report.Positions) != 1 || report.Positions[0].Driver != AgentTypeTechnical {
+		t.Errorf("positions = %+v, want the technical driver under the recorded weights", report.Positions)
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction identifies a state-changing action recorded in the audit log
+type AuditAction string
+
+const (
+	AuditRecommendationApproved AuditAction = "recommendation_approved"
+	AuditRecommendationRejected AuditAction = "recommendation_rejected"
+	AuditRecommendationExecuted AuditAction = "recommendation_executed"
+	AuditSettingsChanged        AuditAction = "settings_changed"
+	AuditScreenerRun            AuditAction = "screener_run"
+)
+
+// AuditEntry records who took a state-changing action, what it acted on, and when
+type AuditEntry struct {
+	ID         uuid.UUID      `json:"id"`
+	Action     AuditAction    `json:"action"`
+	Actor      string         `json:"actor"`            // Client address of the request that took the action
+	Target     string         `json:"target,omitempty"` // What was acted on, such as a recommendation ID or setting name
+	Details    map[string]any `json:"details,omitempty"`
+	RequestID  string         `json:"request_id,omitempty"`
+	OccurredAt time.Time      `json:"occurred_at"`
+}
+
+// NewAuditEntry creates an audit entry for an action taken now. The actor is a client
+// address, with any port dropped so entries from one client group together.
+func NewAuditEntry(action AuditAction, actor, target string, details map[string]any) *AuditEntry {
+	if host, _, err := net.SplitHostPort(actor); err == nil {
+		actor = host
+	}
+	return &AuditEntry{
+		ID:         uuid.New(),
+		Action:     action,
+		Actor:      actor,
+		Target:     target,
+		Details:    details,
+		OccurredAt: time.Now(),
+	}
+}
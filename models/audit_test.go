@@ -0,0 +1,24 @@
+package models
+
+import "testing"
+
+func TestNewAuditEntry(t *testing.T) {
+	for _, tt := range []struct {
+		actor string
+		want  string
+	}{
+		{"192.0.2.10:51234", "192.0.2.10"},
+		{"[2001:db8::1]:443", "2001:db8::1"},
+		{"192.0.2.10", "192.0.2.10"},
+	} {
+		entry := NewAuditEntry(AuditRecommendationApproved, tt.actor, "rec-1", nil)
+		if entry.Actor != tt.want {
+			t.Errorf("NewAuditEntry(%q).Actor = %q, want %q", tt.actor, entry.Actor, tt.want)
+		}
+	}
+
+	entry := NewAuditEntry(AuditSettingsChanged, "127.0.0.1:1", "broker", map[string]any{"broker": "ibkr"})
+	if entry.ID.String() == "00000000-0000-0000-0000-000000000000" || entry.OccurredAt.IsZero() || entry.Details["broker"] != "ibkr" {
+		t.Errorf("entry = %+v, want an ID, a time and the details", entry)
+	}
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidBacktest is returned when backtest parameters are invalid
+var ErrInvalidBacktest = errors.New("invalid backtest")
+
+const (
+	// MaxBacktestSymbols caps how many symbols one backtest replays
+	MaxBacktestSymbols = 20
+	// MaxBacktestYears caps how far apart a backtest's start and end may be
+	MaxBacktestYears = 5
+	// DefaultBacktestCash is the starting cash when a request doesn't set one
+	DefaultBacktestCash = 100_000
+)
+
+// BacktestRequest describes a replay of daily bars through an action strategy
+type BacktestRequest struct {
+	Symbols         []string        `json:"symbols"`
+	Start           string          `json:"start"`                      // First day replayed, YYYY-MM-DD
+	End             string          `json:"end"`                        // Last day replayed, YYYY-MM-DD
+	Strategy        string          `json:"strategy,omitempty"`         // default, conservative or aggressive; empty uses the live strategy
+	Horizon         AnalysisHorizon `json:"horizon,omitempty"`          // Timeframe weighting of the technical score (default: medium)
+	InitialCash     float64         `json:"initial_cash,omitempty"`     // Starting cash (default: 100,000)
+	PositionPercent float64         `json:"position_percent,omitempty"` // Fraction of equity put into each new position (default: POSITION_MAX_PERCENT)
+}
+
+// Normalize upper-cases and de-duplicates the symbols, fills in defaults and returns the
+// first and last day to replay, as midnight in the exchange time zone. The end may not be
+// after today.
+func (r *BacktestRequest) Normalize(now time.Time, defaultPositionPercent float64) (start, end time.Time, err error) {
+	seen := make(map[string]bool)
+	symbols := make([]string, 0, len(r.Symbols))
+	for _, s := range r.Symbols {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		if s != "" && !seen[s] {
+			seen[s] = true
+			symbols = append(symbols, s)
+		}
+	}
+	if len(symbols) == 0 || len(symbols) > MaxBacktestSymbols {
+		return start, end, fmt.Errorf("%w: give 1 to %d symbols", ErrInvalidBacktest, MaxBacktestSymbols)
+	}
+	r.Symbols = symbols
+
+	if start, err = time.ParseInLocation("2006-01-02", r.Start, marketLocation); err != nil {
+		return start, end, fmt.Errorf("%w: start must be YYYY-MM-DD", ErrInvalidBacktest)
+	}
+	if end, err = time.ParseInLocation("2006-01-02", r.End, marketLocation); err != nil {
+		return start, end, fmt.Errorf("%w: end must be YYYY-MM-DD", ErrInvalidBacktest)
+	}
+	if !end.After(start) {
+		return start, end, fmt.Errorf("%w: end must be after start", ErrInvalidBacktest)
+	}
+	if end.After(now) {
+		return start, end, fmt.Errorf("%w: end must not be in the future", ErrInvalidBacktest)
+	}
+	if end.After(start.AddDate(MaxBacktestYears, 0, 0)) {
+		return start, end, fmt.Errorf("%w: replay at most %d years", ErrInvalidBacktest, MaxBacktestYears)
+	}
+
+	switch r.Strategy {
+	case "", "default", "conservative", "aggressive":
+	default:
+		return start, end, fmt.Errorf("%w: strategy must be default, conservative or aggressive", ErrInvalidBacktest)
+	}
+	if r.Horizon == HorizonOverall {
+		r.Horizon = HorizonMedium
+	}
+	if _, err := ParseAnalysisHorizon(string(r.Horizon)); err != nil {
+		return start, end, fmt.Errorf("%w: %v", ErrInvalidBacktest, err)
+	}
+
+	if r.InitialCash == 0 {
+		r.InitialCash = DefaultBacktestCash
+	}
+	if r.InitialCash < 0 {
+		return start, end, fmt.Errorf("%w: initial cash must be positive", ErrInvalidBacktest)
+	}
+	if r.PositionPercent == 0 {
+		r.PositionPercent = defaultPositionPercent
+	}
+	if r.PositionPercent <= 0 || r.PositionPercent > 1 {
+		return start, end, fmt.Errorf("%w: position percent must be between 0 and 1", ErrInvalidBacktest)
+	}
+	return start, end, nil
+}
+
+// EquityPoint is the simulated account value at one day's close
+type EquityPoint struct {
+	Date   time.Time `json:"date"`
+	Equity float64   `json:"equity"`
+}
+
+// BacktestTrade is one simulated round trip, bought and sold at the open after the signal
+type BacktestTrade struct {
+	Symbol     string    `json:"symbol"`
+	Quantity   float64   `json:"quantity"`
+	EntryDate  time.Time `json:"entry_date"`
+	EntryPrice float64   `json:"entry_price"`
+	ExitDate   time.Time `json:"exit_date"`
+	ExitPrice  float64   `json:"exit_price"`
+	PnL        float64   `json:"pnl"`
+	OpenAtEnd  bool      `json:"open_at_end,omitempty"` // Still held on the last day and valued at its close
+}
+
+// BacktestStats summarizes a backtest's equity curve and trades
+type BacktestStats struct {
+	InitialEquity float64 `json:"initial_equity"`
+	FinalEquity   float64 `json:"final_equity"`
+	TotalReturn   float64 `json:"total_return"` // 0.12 = 12%
+	MaxDrawdown   float64 `json:"max_drawdown"` // Largest peak-to-trough fall of the equity curve, 0.08 = 8%
+	SharpeRatio   float64 `json:"sharpe_ratio"` // Annualized mean over standard deviation of daily returns, no risk-free rate
+	WinRate       float64 `json:"win_rate"`     // Share of trades with a positive P&L, 0.55 = 55%
+	Trades        int     `json:"trades"`
+}
+
+// NewBacktestStats computes the stats of an equity curve in date order and its trades
+func NewBacktestStats(initial float64, equity []EquityPoint, trades []BacktestTrade) BacktestStats {
+	stats := BacktestStats{InitialEquity: initial, FinalEquity: initial, Trades: len(trades)}
+	if len(equity) > 0 {
+		stats.FinalEquity = equity[len(equity)-1].Equity
+	}
+	if initial > 0 {
+		stats.TotalReturn = stats.FinalEquity/initial - 1
+	}
+
+	curve := make([]DailyClose, 0, len(equity)+1)
+	curve = append(curve, DailyClose{Close: initial})
+	for _, p := range equity {
+		curve = append(curve, DailyClose{Date: p.Date, Close: p.Equity})
+	}
+	stats.MaxDrawdown = maxDrawdown(curve)
+
+	var returns []float64
+	for i := 1; i < len(curve); i++ {
+		if curve[i-1].Close > 0 {
+			returns = append(returns, curve[i].Close/curve[i-1].Close-1)
+		}
+	}
+	if len(returns) > 1 {
+		if sd := stdev(returns); sd > 0 {
+			stats.SharpeRatio = mean(returns) / sd * math.Sqrt(tradingDaysPerYear)
+		}
+	}
+
+	wins := 0
+	for _, t := range trades {
+		if t.PnL > 0 {
+			wins++
+		}
+	}
+	if len(trades) > 0 {
+		stats.WinRate = float64(wins) / float64(len(trades))
+	}
+	return stats
+}
+
+// BacktestRun is a finished backtest with its parameters, stats, equity curve and trades
+type BacktestRun struct {
+	ID         uuid.UUID       `json:"id"`
+	Request    BacktestRequest `json:"request"`
+	Strategy   string          `json:"strategy"` // Name of the strategy replayed
+	Stats      BacktestStats   `json:"stats"`
+	Equity     []EquityPoint   `json:"equity"`
+	Trades     []BacktestTrade `json:"trades"`
+	Warnings   []string        `json:"warnings"` // Symbols skipped or cut short for missing bars
+	DurationMs int64           `json:"duration_ms"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBacktestRequest_Normalize(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	req := BacktestRequest{Symbols: []string{" aapl", "MSFT", "AAPL", ""}, Start: "2025-01-02", End: "2025-12-31"}
+	start, end, err := req.Normalize(now, 0.1)
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if len(req.Symbols) != 2 || req.Symbols[0] != "AAPL" || req.Symbols[1] != "MSFT" {
+		t.Errorf("Symbols = %v, want [AAPL MSFT]", req.Symbols)
+	}
+	if req.Horizon != HorizonMedium || req.InitialCash != DefaultBacktestCash || req.PositionPercent != 0.1 {
+		t.Errorf("request = %+v, want the default horizon, cash and position size", req)
+	}
+	if start.Location() != MarketLocation() || start.Format("2006-01-02") != "2025-01-02" || end.Format("2006-01-02") != "2025-12-31" {
+		t.Errorf("start, end = %v, %v; want the requested days in the exchange time zone", start, end)
+	}
+
+	for _, tt := range []struct {
+		name string
+		req  BacktestRequest
+	}{
+		{"no symbols", BacktestRequest{Start: "2025-01-02", End: "2025-12-31"}},
+		{"bad start", BacktestRequest{Symbols: []string{"AAPL"}, Start: "01/02/2025", End: "2025-12-31"}},
+		{"end before start", BacktestRequest{Symbols: []string{"AAPL"}, Start: "2025-12-31", End: "2025-01-02"}},
+		{"future end", BacktestRequest{Symbols: []string{"AAPL"}, Start: "2025-01-02", End: "2026-12-31"}},
+		{"too long", BacktestRequest{Symbols: []string{"AAPL"}, Start: "2019-01-02", End: "2025-12-31"}},
+		{"unknown strategy", BacktestRequest{Symbols: []string{"AAPL"}, Start: "2025-01-02", End: "2025-12-31", Strategy: "yolo"}},
+		{"unknown horizon", BacktestRequest{Symbols: []string{"AAPL"}, Start: "2025-01-02", End: "2025-12-31", Horizon: "decade"}},
+		{"oversized positions", BacktestRequest{Symbols: []string{"AAPL"}, Start: "2025-01-02", End: "2025-12-31", PositionPercent: 1.5}},
+	} {
+		if _, _, err := tt.req.Normalize(now, 0.1); !errors.Is(err, ErrInvalidBacktest) {
+			t.Errorf("%s: error = %v, want ErrInvalidBacktest", tt.name, err)
+		}
+	}
+}
+
+func TestNewBacktestStats(t *testing.T) {
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	equity := []EquityPoint{
+		{Date: day, Equity: 110},
+		{Date: day.AddDate(0, 0, 1), Equity: 99},
+		{Date: day.AddDate(0, 0, 2), Equity: 120},
+	}
+	trades := []BacktestTrade{{PnL: 30}, {PnL: -10}, {PnL: 0}, {PnL: 5}}
+
+	stats := NewBacktestStats(100, equity, trades)
+	if stats.FinalEquity != 120 || math.Abs(stats.TotalReturn-0.2) > 1e-9 {
+		t.Errorf("FinalEquity = %v, TotalReturn = %v; want 120 and 0.2", stats.FinalEquity, stats.TotalReturn)
+	}
+	if math.Abs(stats.MaxDrawdown-0.1) > 1e-9 {
+		t.Errorf("MaxDrawdown = %v, want 0.1 from 110 to 99", stats.MaxDrawdown)
+	}
+	if stats.WinRate != 0.5 || stats.Trades != 4 {
+		t.Errorf("WinRate = %v over %d trades, want 0.5 over 4", stats.WinRate, stats.Trades)
+	}
+	if stats.SharpeRatio <= 0 {
+		t.Errorf("SharpeRatio = %v, want positive for a rising curve", stats.SharpeRatio)
+	}
+
+	flat := NewBacktestStats(100, nil, nil)
+	if flat.FinalEquity != 100 || flat.TotalReturn != 0 || flat.SharpeRatio != 0 || flat.WinRate != 0 {
+		t.Errorf("stats without days = %+v, want the initial equity and zero ratios", flat)
+	}
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Backup is an encrypted database backup held in remote storage
+type Backup struct {
+	Name      string    `json:"name"`
+	SizeBytes int64     `json:"size_bytes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackupStatus summarizes the background backup job for the health endpoint
+type BackupStatus struct {
+	LastAttemptAt *time.Time `json:"last_attempt_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastBackup    *Backup    `json:"last_backup,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	Retained      int        `json:"retained"` // Backups in storage after the last successful run
+	NextAt        *time.Time `json:"next_at,omitempty"`
+}
+
+// Healthy reports whether the last backup attempt succeeded
+func (s *BackupStatus) Healthy() bool {
+	return s.LastError == ""
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidBatchAnalysis is returned when a batch has no symbols or too many
+var ErrInvalidBatchAnalysis = errors.New("invalid batch analysis")
+
+// MaxBatchSymbols is the most symbols one batch analysis accepts
+const MaxBatchSymbols = 50
+
+// BatchItemStatus is where one symbol of a batch analysis has got to
+type BatchItemStatus string
+
+const (
+	BatchItemQueued    BatchItemStatus = "queued" // Waiting for an analysis slot
+	BatchItemRunning   BatchItemStatus = "running"
+	BatchItemCompleted BatchItemStatus = "completed"
+	BatchItemFailed    BatchItemStatus = "failed"
+)
+
+// BatchAnalysisItem is the analysis of one symbol in a batch
+type BatchAnalysisItem struct {
+	Symbol         string          `json:"symbol"`
+	Status         BatchItemStatus `json:"status"`
+	Recommendation *Recommendation `json:"recommendation,omitempty"` // Set once completed
+	Error          string          `json:"error,omitempty"`
+	StartedAt      *time.Time      `json:"started_at,omitempty"`
+	CompletedAt    *time.Time      `json:"completed_at,omitempty"`
+}
+
+// BatchAnalysis analyzes several symbols concurrently, reporting each result as it completes
+type BatchAnalysis struct {
+	ID          uuid.UUID           `json:"id"`
+	Items       []BatchAnalysisItem `json:"items"`
+	Completed   int                 `json:"completed"` // Items completed or failed
+	Failed      int                 `json:"failed"`
+	Done        bool                `json:"done"`
+	CreatedAt   time.Time           `json:"created_at"`
+	CompletedAt *time.Time          `json:"completed_at,omitempty"`
+}
+
+// NewBatchAnalysis creates a batch with every symbol queued. Symbols are upper-cased and
+// duplicates dropped, keeping the first occurrence.
+func NewBatchAnalysis(symbols []string) (*BatchAnalysis, error) {
+	var unique []string
+	for _, s := range symbols {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		if s != "" && !slices.Contains(unique, s) {
+			unique = append(unique, s)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("%w: at least one symbol is required", ErrInvalidBatchAnalysis)
+	}
+	if len(unique) > MaxBatchSymbols {
+		return nil, fmt.Errorf("%w: %d symbols, at most %d", ErrInvalidBatchAnalysis, len(unique), MaxBatchSymbols)
+	}
+
+	batch := &BatchAnalysis{
+		ID:        uuid.New(),
+		Items:     make([]BatchAnalysisItem, len(unique)),
+		CreatedAt: time.Now(),
+	}
+	for i, s := range unique {
+		batch.Items[i] = BatchAnalysisItem{Symbol: s, Status: BatchItemQueued}
+	}
+	return batch, nil
+}
+
+// Start marks item i as running
+func (b *BatchAnalysis) Start(i int) {
+	now := time.Now()
+	b.Items[i].Status = BatchItemRunning
+	b.Items[i].StartedAt = &now
+}
+
+// Finish records the outcome of item i, marking the batch done after its last item
+func (b *BatchAnalysis) Finish(i int, rec *Recommendation, err error) {
+	now := time.Now()
+	item := &b.Items[i]
+	item.CompletedAt = &now
+	if err != nil {
+		item.Status = BatchItemFailed
+		item.Error = err.Error()
+		b.Failed++
+	} else {
+		item.Status = BatchItemCompleted
+		item.Recommendation = rec
+	}
+	b.Completed++
+	if b.Completed == len(b.Items) {
+		b.Done = true
+		b.CompletedAt = &now
+	}
+}
+
+// Copy returns a copy of the batch whose items can be read while the original is updated
+func (b *BatchAnalysis) Copy() *BatchAnalysis {
+	c := *b
+	c.Items = slices.Clone(b.Items)
+	return &c
+}
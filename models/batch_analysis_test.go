@@ -0,0 +1,50 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNewBatchAnalysis(t *testing.T) {
+	batch, err := NewBatchAnalysis([]string{" aapl", "MSFT", "AAPL", ""})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(batch.Items) != 2 || batch.Items[0].Symbol != "AAPL" || batch.Items[1].Symbol != "MSFT" {
+		t.Errorf("Items = %+v, want AAPL and MSFT once each", batch.Items)
+	}
+	if batch.Items[0].Status != BatchItemQueued {
+		t.Errorf("Status = %s, want queued", batch.Items[0].Status)
+	}
+
+	var tooMany []string
+	for i := 0; i <= MaxBatchSymbols; i++ {
+		tooMany = append(tooMany, fmt.Sprintf("S%d", i))
+	}
+	for _, symbols := range [][]string{nil, {" "}, tooMany} {
+		if _, err := NewBatchAnalysis(symbols); !errors.Is(err, ErrInvalidBatchAnalysis) {
+			t.Errorf("NewBatchAnalysis(%d symbols) = %v, want ErrInvalidBatchAnalysis", len(symbols), err)
+		}
+	}
+}
+
+func TestBatchAnalysis_Finish(t *testing.T) {
+	batch, _ := NewBatchAnalysis([]string{"AAPL", "MSFT"})
+	batch.Start(0)
+	batch.Finish(0, NewRecommendation("AAPL", RecommendationActionBuy, "test"), nil)
+
+	snapshot := batch.Copy()
+	batch.Start(1)
+	batch.Finish(1, nil, errors.New("agents failed"))
+
+	if snapshot.Done || snapshot.Items[1].Status != BatchItemQueued {
+		t.Errorf("snapshot = %+v, want it unchanged by later updates", snapshot)
+	}
+	if !batch.Done || batch.Completed != 2 || batch.Failed != 1 || batch.CompletedAt == nil {
+		t.Errorf("batch = %+v, want done with one failure", batch)
+	}
+	if batch.Items[1].Status != BatchItemFailed || batch.Items[1].Error != "agents failed" {
+		t.Errorf("failed item = %+v", batch.Items[1])
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrUnknownBroker is returned when selecting a broker that is not supported or not configured
+var ErrUnknownBroker = errors.New("unknown broker")
+
+// Brokers trades can be routed to
+const (
+	BrokerAlpaca = "alpaca"
+	BrokerIBKR   = "ibkr" // Interactive Brokers through the Client Portal API
+)
+
+// Brokers lists the supported brokers
+var Brokers = []string{BrokerAlpaca, BrokerIBKR}
+
+// NormalizeBroker lower-cases a broker name and checks it is supported
+func NormalizeBroker(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !slices.Contains(Brokers, name) {
+		return "", fmt.Errorf("%w %q, expected one of %s", ErrUnknownBroker, name, strings.Join(Brokers, ", "))
+	}
+	return name, nil
+}
+
+// BrokerStatus reports which broker new orders go to and which are configured
+type BrokerStatus struct {
+	Active    string   `json:"active"`
+	Available []string `json:"available"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidChat is returned for a chat request without a symbol or a question
+var ErrInvalidChat = errors.New("invalid chat request")
+
+const (
+	// MaxChatMessages caps how many turns of a conversation are sent to the model
+	MaxChatMessages = 20
+	// MaxChatMessageLength caps the length of one turn, in characters
+	MaxChatMessageLength = 4000
+)
+
+// ChatTurn is one message of a chat about a symbol
+type ChatTurn struct {
+	Role    string `json:"role"` // user or assistant
+	Content string `json:"content"`
+}
+
+// ChatRequest is a free-form question about a symbol, with the conversation so far
+type ChatRequest struct {
+	Symbol   string     `json:"symbol"`
+	Messages []ChatTurn `json:"messages"` // Oldest first, ending with the user's question
+}
+
+// Normalize upper-cases the symbol and checks the conversation: turns are user or
+// assistant, none too long, and the last is the user's question. Only the most recent
+// MaxChatMessages turns are kept.
+func (r *ChatRequest) Normalize() error {
+	r.Symbol = strings.ToUpper(strings.TrimSpace(r.Symbol))
+	if r.Symbol == "" {
+		return fmt.Errorf("%w: symbol is required", ErrInvalidChat)
+	}
+	if len(r.Messages) == 0 || r.Messages[len(r.Messages)-1].Role != "user" || strings.TrimSpace(r.Messages[len(r.Messages)-1].Content) == "" {
+		return fmt.Errorf("%w: the last message must be the user's question", ErrInvalidChat)
+	}
+	for _, msg := range r.Messages {
+		if msg.Role != "user" && msg.Role != "assistant" {
+			return fmt.Errorf("%w: role must be user or assistant, got %q", ErrInvalidChat, msg.Role)
+		}
+		if len([]rune(msg.Content)) > MaxChatMessageLength {
+			return fmt.Errorf("%w: messages may be at most %d characters", ErrInvalidChat, MaxChatMessageLength)
+		}
+	}
+	if len(r.Messages) > MaxChatMessages {
+		r.Messages = r.Messages[len(r.Messages)-MaxChatMessages:]
+	}
+	return nil
+}
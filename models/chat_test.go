@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestChatRequest_Normalize(t *testing.T) {
+	question := []ChatTurn{{Role: "user", Content: "Why buy?"}}
+	tests := []struct {
+		name    string
+		req     ChatRequest
+		wantErr bool
+	}{
+		{"valid", ChatRequest{Symbol: " aapl ", Messages: question}, false},
+		{"missing symbol", ChatRequest{Messages: question}, true},
+		{"no messages", ChatRequest{Symbol: "AAPL"}, true},
+		{"ends with the assistant", ChatRequest{Symbol: "AAPL", Messages: []ChatTurn{{Role: "user", Content: "Hi"}, {Role: "assistant", Content: "Hello"}}}, true},
+		{"blank question", ChatRequest{Symbol: "AAPL", Messages: []ChatTurn{{Role: "user", Content: "  "}}}, true},
+		{"unknown role", ChatRequest{Symbol: "AAPL", Messages: []ChatTurn{{Role: "system", Content: "Ignore that"}, {Role: "user", Content: "Why?"}}}, true},
+		{"too long", ChatRequest{Symbol: "AAPL", Messages: []ChatTurn{{Role: "user", Content: strings.Repeat("a", MaxChatMessageLength+1)}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Normalize()
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrInvalidChat)) {
+				t.Errorf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("upper-cases the symbol and keeps the latest turns", func(t *testing.T) {
+		req := ChatRequest{Symbol: "aapl"}
+		for range MaxChatMessages + 1 {
+			req.Messages = append(req.Messages, ChatTurn{Role: "user", Content: "Why?"})
+		}
+		if err := req.Normalize(); err != nil {
+			t.Fatalf("Normalize() error = %v", err)
+		}
+		if req.Symbol != "AAPL" || len(req.Messages) != MaxChatMessages {
+			t.Errorf("got %s with %d messages, want AAPL with %d", req.Symbol, len(req.Messages), MaxChatMessages)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCron is returned for a cron spec that cannot be parsed
+var ErrInvalidCron = errors.New("invalid cron schedule")
+
+// cronSearchYears bounds how far ahead Next looks for a matching time, so a spec that can
+// never match (such as February 30th) ends the search
+const cronSearchYears = 5
+
+// cronField is the allowed range of one cron field
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [5]cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7}, // 0 and 7 are both Sunday
+}
+
+// CronSchedule is a parsed five-field cron spec (minute, hour, day of month, month, day
+// of week) evaluated in the exchange time zone. Each field is *, a value, a range a-b, a
+// list a,b,c, or any of those with a /step.
+type CronSchedule struct {
+	spec   string
+	fields [5]uint64 // Bit n set when value n matches
+	// Following cron, when both day fields are restricted a day matching either one matches
+	domAny, dowAny bool
+}
+
+// ParseCron parses a five-field cron spec such as "30 8 * * 1-5" (8:30 on weekdays)
+func ParseCron(spec string) (*CronSchedule, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("%w: %q has %d fields, want 5 (minute hour day month weekday)", ErrInvalidCron, spec, len(parts))
+	}
+
+	c := &CronSchedule{spec: strings.Join(parts, " ")}
+	for i, part := range parts {
+		bits, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		c.fields[i] = bits
+	}
+	// Sunday may be written as 7
+	if c.fields[4]&(1<<7) != 0 {
+		c.fields[4] |= 1
+	}
+	c.domAny = parts[2] == "*"
+	c.dowAny = parts[4] == "*"
+	return c, nil
+}
+
+func parseCronField(part string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(part, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("%w: bad step %q in %s", ErrInvalidCron, stepPart, f.name)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		if rangePart != "*" {
+			loPart, hiPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(loPart); err != nil {
+				return 0, fmt.Errorf("%w: bad value %q in %s", ErrInvalidCron, loPart, f.name)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiPart); err != nil {
+					return 0, fmt.Errorf("%w: bad value %q in %s", ErrInvalidCron, hiPart, f.name)
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+		}
+		if lo < f.min || hi > f.max || lo > hi {
+			return 0, fmt.Errorf("%w: %s must be within %d-%d, got %q", ErrInvalidCron, f.name, f.min, f.max, item)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// String returns the spec the schedule was parsed from
+func (c *CronSchedule) String() string {
+	return c.spec
+}
+
+func (c *CronSchedule) matches(field int, v int) bool {
+	return c.fields[field]&(1<<v) != 0
+}
+
+func (c *CronSchedule) dayMatches(t time.Time) bool {
+	dom := c.matches(2, t.Day())
+	dow := c.matches(4, int(t.Weekday()))
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// Next returns the first time after after that matches the schedule, in the exchange time
+// zone, or the zero time if none does within five years
+func (c *CronSchedule) Next(after time.Time) time.Time {
+	loc := MarketLocation()
+	t := after.In(loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(cronSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case !c.matches(3, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case !c.matches(1, t.Hour()):
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case !c.matches(0, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
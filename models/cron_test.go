@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	for _, spec := range []string{"* * * * *", "0 8 * * 1-5", "*/15 9-16 * * MON", "0 8 * *", "60 * * * *", "0 8 * * 5-1", "0 */0 * * *"} {
+		_, err := ParseCron(spec)
+		valid := spec == "* * * * *" || spec == "0 8 * * 1-5"
+		if valid && err != nil {
+			t.Errorf("ParseCron(%q) error = %v", spec, err)
+		}
+		if !valid && !errors.Is(err, ErrInvalidCron) {
+			t.Errorf("ParseCron(%q) error = %v, want ErrInvalidCron", spec, err)
+		}
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	et := MarketLocation()
+	// Friday 2025-03-07 09:00 Eastern
+	friday := time.Date(2025, 3, 7, 9, 0, 0, 0, et)
+
+	tests := []struct {
+		spec  string
+		after time.Time
+		want  time.Time
+	}{
+		// Weekday pre-market runs skip the weekend and the DST change on the 9th
+		{"30 8 * * 1-5", friday, time.Date(2025, 3, 10, 8, 30, 0, 0, et)},
+		{"30 8 * * 1-5", friday.Add(-time.Hour), time.Date(2025, 3, 7, 8, 30, 0, 0, et)},
+		// Strictly after: a time that matches exactly moves to the next match
+		{"0 9 * * *", friday, time.Date(2025, 3, 8, 9, 0, 0, 0, et)},
+		{"*/20 * * * *", friday.Add(5 * time.Minute), time.Date(2025, 3, 7, 9, 20, 0, 0, et)},
+		// Sunday written as 7
+		{"0 12 * * 7", friday, time.Date(2025, 3, 9, 12, 0, 0, 0, et)},
+		// With both day fields restricted, either matches: the 15th or a Monday
+		{"0 0 15 * 1", friday, time.Date(2025, 3, 10, 0, 0, 0, 0, et)},
+		{"0 0 1 1 *", friday, time.Date(2026, 1, 1, 0, 0, 0, 0, et)},
+		// Time zones of the argument don't matter
+		{"0 8 * * *", friday.UTC(), time.Date(2025, 3, 8, 8, 0, 0, 0, et)},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseCron(%q) error = %v", tt.spec, err)
+		}
+		if got := c.Next(tt.after); !got.Equal(tt.want) {
+			t.Errorf("%q after %v = %v, want %v", tt.spec, tt.after, got, tt.want)
+		}
+	}
+
+	never, _ := ParseCron("0 0 30 2 *")
+	if got := never.Next(friday); !got.IsZero() {
+		t.Errorf("Next() = %v, want the zero time for February 30th", got)
+	}
+}
+
+func TestScreenerSchedule_Normalize(t *testing.T) {
+	s := ScreenerSchedule{Cron: "  0  8 * * 1-5 "}
+	cron, err := s.Normalize()
+	if err != nil || cron == nil || s.Cron != "0 8 * * 1-5" {
+		t.Errorf("Normalize() = %v, %v with cron %q; want the trimmed spec parsed", cron, err, s.Cron)
+	}
+
+	off := ScreenerSchedule{Cron: " "}
+	if cron, err := off.Normalize(); cron != nil || err != nil {
+		t.Errorf("Normalize() = %v, %v; want nil for a disabled schedule", cron, err)
+	}
+
+	bad := ScreenerSchedule{Cron: "8am daily"}
+	if _, err := bad.Normalize(); !errors.Is(err, ErrInvalidCron) {
+		t.Errorf("Normalize() error = %v, want ErrInvalidCron", err)
+	}
+}
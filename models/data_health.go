@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// Data-health checks run against the database
+const (
+	DataCheckExecutedWithoutTrade = "executed_without_trade"  // Executed recommendations whose trade is missing
+	DataCheckZeroQuantity         = "zero_quantity_positions" // Positions left open with no shares
+	DataCheckStaleAgentRuns       = "stale_agent_runs"        // Agent runs still marked running long after they started
+	DataCheckExpiredCache         = "expired_cache"           // Market data cache entries past their expiry
+)
+
+// dataHealthFixable lists the checks whose issues can be repaired without losing
+// anything of value: empty positions and expired cache entries are deleted, and stale
+// agent runs are marked failed. Recommendations missing their trade need a person to
+// look at the broker's records, so they are only reported.
+var dataHealthFixable = map[string]bool{
+	DataCheckZeroQuantity:   true,
+	DataCheckStaleAgentRuns: true,
+	DataCheckExpiredCache:   true,
+}
+
+// DataHealthFixable reports whether a check's issues are safe to fix automatically
+func DataHealthFixable(check string) bool {
+	return dataHealthFixable[check]
+}
+
+// DataHealthIssue is the result of one integrity check
+type DataHealthIssue struct {
+	Check       string   `json:"check"`
+	Description string   `json:"description"`
+	Count       int64    `json:"count"`
+	SampleIDs   []string `json:"sample_ids,omitempty"` // A few of the affected rows
+	Fixable     bool     `json:"fixable"`
+	Fixed       int64    `json:"fixed,omitempty"` // Rows repaired by auto-fix
+}
+
+// TableStats describes the size of a database table
+type TableStats struct {
+	Name       string `json:"name"`
+	Rows       int64  `json:"rows"`      // Estimated live rows
+	DeadRows   int64  `json:"dead_rows"` // Rows awaiting vacuum
+	TotalBytes int64  `json:"total_bytes"`
+}
+
+// CacheStats describes the market data cache
+type CacheStats struct {
+	Entries    int64            `json:"entries"`
+	Expired    int64            `json:"expired"`
+	TotalBytes int64            `json:"total_bytes"`
+	ByType     map[string]int64 `json:"by_type"` // Entries per data type
+}
+
+// ExpiredPercent returns the share of cache entries past their expiry
+func (s CacheStats) ExpiredPercent() float64 {
+	if s.Entries == 0 {
+		return 0
+	}
+	return float64(s.Expired) / float64(s.Entries) * 100
+}
+
+// DataHealthReport is the result of the data-health checks, with table sizes and cache
+// statistics to spot tables that need pruning
+type DataHealthReport struct {
+	CheckedAt time.Time         `json:"checked_at"`
+	Healthy   bool              `json:"healthy"` // Whether every check passed, set by Finish
+	Issues    []DataHealthIssue `json:"issues"`
+	Tables    []TableStats      `json:"tables"`
+	Cache     CacheStats        `json:"cache"`
+	AutoFixed bool              `json:"auto_fixed"` // Whether safe issues were fixed before the report was made
+}
+
+// Finish marks which issues are fixable and whether the report is healthy, once every
+// check has run
+func (r *DataHealthReport) Finish() {
+	for i := range r.Issues {
+		r.Issues[i].Fixable = DataHealthFixable(r.Issues[i].Check)
+	}
+	r.Healthy = r.IssueCount() == 0
+}
+
+// IssueCount returns the number of affected rows across all checks
+func (r *DataHealthReport) IssueCount() int64 {
+	var n int64
+	for _, issue := range r.Issues {
+		n += issue.Count
+	}
+	return n
+}
+
+// ApplyFixes records the rows repaired per check by an auto-fix run
+func (r *DataHealthReport) ApplyFixes(fixed map[string]int64) {
+	r.AutoFixed = true
+	for i := range r.Issues {
+		r.Issues[i].Fixed = fixed[r.Issues[i].Check]
+	}
+}
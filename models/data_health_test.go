@@ -0,0 +1,38 @@
+package models
+
+import "testing"
+
+func TestDataHealthReport_Finish(t *testing.T) {
+	report := &DataHealthReport{Issues: []DataHealthIssue{
+		{Check: DataCheckExecutedWithoutTrade},
+		{Check: DataCheckZeroQuantity},
+	}}
+	report.Finish()
+	if !report.Healthy {
+		t.Error("expected a report without affected rows to be healthy")
+	}
+	if report.Issues[0].Fixable || !report.Issues[1].Fixable {
+		t.Errorf("fixable = %v, %v; want only empty positions fixable", report.Issues[0].Fixable, report.Issues[1].Fixable)
+	}
+
+	report.Issues[0].Count = 2
+	report.Issues[1].Count = 3
+	report.Finish()
+	if report.Healthy || report.IssueCount() != 5 {
+		t.Errorf("Healthy = %v, IssueCount() = %d; want unhealthy with 5 rows", report.Healthy, report.IssueCount())
+	}
+
+	report.ApplyFixes(map[string]int64{DataCheckZeroQuantity: 3})
+	if !report.AutoFixed || report.Issues[1].Fixed != 3 || report.Issues[0].Fixed != 0 {
+		t.Errorf("issues after fixes = %+v", report.Issues)
+	}
+}
+
+func TestCacheStats_ExpiredPercent(t *testing.T) {
+	if got := (CacheStats{}).ExpiredPercent(); got != 0 {
+		t.Errorf("ExpiredPercent() of an empty cache = %v, want 0", got)
+	}
+	if got := (CacheStats{Entries: 8, Expired: 2}).ExpiredPercent(); got != 25 {
+		t.Errorf("ExpiredPercent() = %v, want 25", got)
+	}
+}
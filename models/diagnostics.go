@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DiagnosticBundleVersion is the format version of bundles this build writes and reads
+const DiagnosticBundleVersion = 1
+
+// ErrUnsupportedBundle is returned when importing a bundle written in another format
+var ErrUnsupportedBundle = errors.New("unsupported diagnostic bundle")
+
+// DiagnosticBundle is a sanitized snapshot of the app's state for support: what it is
+// configured to do, what it logged lately, and what has been failing. Credentials are
+// redacted before the bundle is built, so it can be attached to a bug report.
+type DiagnosticBundle struct {
+	FormatVersion   int                 `json:"format_version"`
+	CreatedAt       time.Time           `json:"created_at"`
+	GoVersion       string              `json:"go_version"`
+	Platform        string              `json:"platform"`       // GOOS/GOARCH
+	SchemaVersion   int64               `json:"schema_version"` // Latest applied migration; 0 without a database
+	Config          map[string]any      `json:"config"`
+	Logs            []DiagnosticLog     `json:"logs"` // Oldest first
+	FailedAgentRuns []AgentRun          `json:"failed_agent_runs"`
+	CircuitBreakers []DiagnosticBreaker `json:"circuit_breakers"`
+	ProviderAlerts  []ProviderAlert     `json:"provider_alerts"`    // Breaker trips and exhausted quotas, newest first
+	Warnings        []string            `json:"warnings,omitempty"` // Sections that could not be collected
+}
+
+// DiagnosticLog is a recent log record with its attributes as strings
+type DiagnosticLog struct {
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+}
+
+// DiagnosticBreaker is a provider's circuit breaker state when the bundle was made
+type DiagnosticBreaker struct {
+	Name                string `json:"name"`
+	State               string `json:"state"`
+	Level               string `json:"level"`
+	TotalFailures       uint32 `json:"total_failures"`
+	ConsecutiveFailures uint32 `json:"consecutive_failures"`
+}
+
+// Validate checks that the bundle is in a format this build can import
+func (b *DiagnosticBundle) Validate() error {
+	if b.FormatVersion != DiagnosticBundleVersion {
+		return fmt.Errorf("%w: format version %d, want %d", ErrUnsupportedBundle, b.FormatVersion, DiagnosticBundleVersion)
+	}
+	if b.CreatedAt.IsZero() {
+		return fmt.Errorf("%w: missing created_at", ErrUnsupportedBundle)
+	}
+	return nil
+}
+
+// DiagnosticImport is what importing a diagnostic bundle did
+type DiagnosticImport struct {
+	AgentRuns      int          `json:"agent_runs"`      // Failed runs added to the database
+	ProviderAlerts int          `json:"provider_alerts"` // Alerts added to the database
+	Skipped        int          `json:"skipped"`         // Rows that were already present
+	ConfigDiffs    []ConfigDiff `json:"config_diffs"`    // Settings to change locally to match the bundle
+	Warnings       []string     `json:"warnings,omitempty"`
+}
+
+// ConfigDiff is a setting whose value in a bundle differs from the local configuration
+type ConfigDiff struct {
+	Key    string `json:"key"` // Dotted path, such as Screener.MarketCapMin
+	Bundle string `json:"bundle"`
+	Local  string `json:"local"`
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// DividendStatus is whether a tracked dividend has been paid
+type DividendStatus string
+
+const (
+	DividendStatusExpected DividendStatus = "expected" // Declared, payment date not yet reached
+	DividendStatusReceived DividendStatus = "received"
+)
+
+// Dividend is a cash dividend on a symbol. Dividends tracked for a held position record
+// the shares held when they were tracked; a dividend from the provider's history has none.
+type Dividend struct {
+	ID             uuid.UUID       `json:"id,omitempty"`
+	Symbol         string          `json:"symbol"`
+	ExDate         time.Time       `json:"ex_date"`
+	PaymentDate    *time.Time      `json:"payment_date,omitempty"`
+	AmountPerShare decimal.Decimal `json:"amount_per_share"` // Split-adjusted
+	Shares         decimal.Decimal `json:"shares"`
+	Status         DividendStatus  `json:"status"`
+	CreatedAt      time.Time       `json:"created_at,omitempty"`
+	UpdatedAt      time.Time       `json:"updated_at,omitempty"`
+}
+
+// Amount returns the cash the dividend pays on the tracked shares
+func (d Dividend) Amount() decimal.Decimal {
+	return d.AmountPerShare.Mul(d.Shares)
+}
+
+// StatusAt returns received once the payment date has passed, or for a dividend without
+// one once the ex-date has
+func (d Dividend) StatusAt(now time.Time) DividendStatus {
+	paid := d.ExDate
+	if d.PaymentDate != nil {
+		paid = *d.PaymentDate
+	}
+	if paid.After(now) {
+		return DividendStatusExpected
+	}
+	return DividendStatusReceived
+}
+
+// TrailingAnnualDividend sums the per-share dividends that went ex in the year up to asOf
+func TrailingAnnualDividend(history []Dividend, asOf time.Time) decimal.Decimal {
+	since := asOf.AddDate(-1, 0, 0)
+	total := decimal.Zero
+	for _, d := range history {
+		if d.ExDate.After(since) && !d.ExDate.After(asOf) {
+			total = total.Add(d.AmountPerShare)
+		}
+	}
+	return total
+}
+
+// PositionDividendIncome is the dividend income of one held position
+type PositionDividendIncome struct {
+	Symbol                 string          `json:"symbol"`
+	Quantity               decimal.Decimal `json:"quantity"`
+	AvgEntryPrice          decimal.Decimal `json:"avg_entry_price"`
+	AnnualDividendPerShare decimal.Decimal `json:"annual_dividend_per_share"` // Trailing twelve months
+	ProjectedAnnualIncome  decimal.Decimal `json:"projected_annual_income"`
+	YieldOnCost            float64         `json:"yield_on_cost"`   // Percent of the average entry price
+	ReceivedIncome         decimal.Decimal `json:"received_income"` // Paid while the position was held
+	ExpectedIncome         decimal.Decimal `json:"expected_income"` // Gone ex while held, not yet paid
+	Dividends              []Dividend      `json:"dividends"`       // Tracked while held, newest first
+}
+
+// NewPositionDividendIncome projects a position's annual dividend income from the trailing
+// twelve months of per-share dividends, and totals the dividends tracked for it
+func NewPositionDividendIncome(p Position, annualPerShare decimal.Decimal, tracked []Dividend) PositionDividendIncome {
+	income := PositionDividendIncome{
+		Symbol:                 p.Symbol,
+		Quantity:               p.Quantity,
+		AvgEntryPrice:          p.AvgEntryPrice,
+		AnnualDividendPerShare: annualPerShare,
+		ProjectedAnnualIncome:  annualPerShare.Mul(p.Quantity),
+		ReceivedIncome:         decimal.Zero,
+		ExpectedIncome:         decimal.Zero,
+		Dividends:              tracked,
+	}
+	if p.AvgEntryPrice.IsPositive() {
+		income.YieldOnCost = annualPerShare.Div(p.AvgEntryPrice).Mul(decimal.NewFromInt(100)).InexactFloat64()
+	}
+	if income.Dividends == nil {
+		income.Dividends = []Dividend{}
+	}
+	for _, d := range tracked {
+		if d.Status == DividendStatusReceived {
+			income.ReceivedIncome = income.ReceivedIncome.Add(d.Amount())
+		} else {
+			income.ExpectedIncome = income.ExpectedIncome.Add(d.Amount())
+		}
+	}
+	return income
+}
+
+// DividendIncome is the dividend income of the portfolio's long positions
+type DividendIncome struct {
+	Positions             []PositionDividendIncome `json:"positions"`
+	ProjectedAnnualIncome decimal.Decimal          `json:"projected_annual_income"`
+	ReceivedIncome        decimal.Decimal          `json:"received_income"`
+	ExpectedIncome        decimal.Decimal          `json:"expected_income"`
+	CostBasis             decimal.Decimal          `json:"cost_basis"`
+	YieldOnCost           float64                  `json:"yield_on_cost"` // Percent of the cost basis
+	GeneratedAt           time.Time                `json:"generated_at"`
+}
+
+// NewDividendIncome totals the positions' dividend income
+func NewDividendIncome(positions []PositionDividendIncome, now time.Time) *DividendIncome {
+	income := &DividendIncome{
+		Positions:             positions,
+		ProjectedAnnualIncome: decimal.Zero,
+		ReceivedIncome:        decimal.Zero,
+		ExpectedIncome:        decimal.Zero,
+		CostBasis:             decimal.Zero,
+		GeneratedAt:           now,
+	}
+	if income.Positions == nil {
+		income.Positions = []PositionDividendIncome{}
+	}
+	for _, p := range positions {
+		income.ProjectedAnnualIncome = income.ProjectedAnnualIncome.Add(p.ProjectedAnnualIncome)
+		income.ReceivedIncome = income.ReceivedIncome.Add(p.ReceivedIncome)
+		income.ExpectedIncome = income.ExpectedIncome.Add(p.ExpectedIncome)
+		income.CostBasis = income.CostBasis.Add(p.AvgEntryPrice.Mul(p.Quantity))
+	}
+	if income.CostBasis.IsPositive() {
+		income.YieldOnCost = income.ProjectedAnnualIncome.Div(income.CostBasis).Mul(decimal.NewFromInt(100)).InexactFloat64()
+	}
+	return income
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDividend_StatusAt(t *testing.T) {
+	now := time.Date(2026, 5, 15, 12, 0, 0, 0, time.UTC)
+	paid := now.AddDate(0, 0, -2)
+	upcoming := now.AddDate(0, 0, 10)
+
+	tests := []struct {
+		name     string
+		dividend Dividend
+		want     DividendStatus
+	}{
+		{"paid", Dividend{ExDate: now.AddDate(0, 0, -20), PaymentDate: &paid}, DividendStatusReceived},
+		{"ex but not yet paid", Dividend{ExDate: now.AddDate(0, 0, -5), PaymentDate: &upcoming}, DividendStatusExpected},
+		{"no payment date, past ex-date", Dividend{ExDate: now.AddDate(0, 0, -5)}, DividendStatusReceived},
+		{"no payment date, future ex-date", Dividend{ExDate: now.AddDate(0, 0, 5)}, DividendStatusExpected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dividend.StatusAt(now); got != tt.want {
+				t.Errorf("StatusAt() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrailingAnnualDividend(t *testing.T) {
+	asOf := time.Date(2026, 5, 15, 0, 0, 0, 0, time.UTC)
+	history := []Dividend{
+		{ExDate: asOf.AddDate(0, 1, 0), AmountPerShare: decimal.NewFromFloat(0.30)}, // Declared, not yet ex
+		{ExDate: asOf.AddDate(0, -2, 0), AmountPerShare: decimal.NewFromFloat(0.25)},
+		{ExDate: asOf.AddDate(0, -5, 0), AmountPerShare: decimal.NewFromFloat(0.25)},
+		{ExDate: asOf.AddDate(0, -8, 0), AmountPerShare: decimal.NewFromFloat(0.24)},
+		{ExDate: asOf.AddDate(0, -11, 0), AmountPerShare: decimal.NewFromFloat(0.24)},
+		{ExDate: asOf.AddDate(0, -14, 0), AmountPerShare: decimal.NewFromFloat(0.24)},
+	}
+
+	if got := TrailingAnnualDividend(history, asOf); !got.Equal(decimal.NewFromFloat(0.98)) {
+		t.Errorf("TrailingAnnualDividend() = %s, want 0.98", got)
+	}
+}
+
+func TestNewDividendIncome(t *testing.T) {
+	position := Position{Symbol: "KO", Quantity: decimal.NewFromInt(100), AvgEntryPrice: decimal.NewFromInt(50)}
+	tracked := []Dividend{
+		{Symbol: "KO", AmountPerShare: decimal.NewFromFloat(0.5), Shares: decimal.NewFromInt(100), Status: DividendStatusExpected},
+		{Symbol: "KO", AmountPerShare: decimal.NewFromFloat(0.5), Shares: decimal.NewFromInt(80), Status: DividendStatusReceived},
+	}
+
+	p := NewPositionDividendIncome(position, decimal.NewFromInt(2), tracked)
+	if !p.ProjectedAnnualIncome.Equal(decimal.NewFromInt(200)) {
+		t.Errorf("ProjectedAnnualIncome = %s, want 200", p.ProjectedAnnualIncome)
+	}
+	if p.YieldOnCost != 4 {
+		t.Errorf("YieldOnCost = %v, want 4", p.YieldOnCost)
+	}
+	if !p.ReceivedIncome.Equal(decimal.NewFromInt(40)) || !p.ExpectedIncome.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("received %s and expected %s, want 40 and 50", p.ReceivedIncome, p.ExpectedIncome)
+	}
+
+	none := NewPositionDividendIncome(Position{Symbol: "NVDA", Quantity: decimal.NewFromInt(10), AvgEntryPrice: decimal.NewFromInt(150)}, decimal.Zero, nil)
+	income := NewDividendIncome([]PositionDividendIncome{p, none}, time.Now())
+	if !income.CostBasis.Equal(decimal.NewFromInt(6500)) {
+		t.Errorf("CostBasis = %s, want 6500", income.CostBasis)
+	}
+	// 200 of income on 6,500 of cost
+	if income.YieldOnCost < 3.07 || income.YieldOnCost > 3.08 {
+		t.Errorf("YieldOnCost = %.4f, want 3.077", income.YieldOnCost)
+	}
+	if none.Dividends == nil {
+		t.Error("expected an empty dividend list, not nil")
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// FeeSchedule estimates commission and fees for fills that carry no broker fee data,
+// such as paper trading
+type FeeSchedule struct {
+	PerTrade decimal.Decimal // Flat commission per trade
+	PerShare decimal.Decimal // Commission per share traded
+	SellRate decimal.Decimal // Regulatory fee as a fraction of sell proceeds
+}
+
+// NewFeeSchedule creates a fee schedule from its configured amounts
+func NewFeeSchedule(perTrade, perShare, sellRate float64) FeeSchedule {
+	return FeeSchedule{
+		PerTrade: decimal.NewFromFloat(perTrade),
+		PerShare: decimal.NewFromFloat(perShare),
+		SellRate: decimal.NewFromFloat(sellRate),
+	}
+}
+
+// IsZero reports whether the schedule charges nothing
+func (s FeeSchedule) IsZero() bool {
+	return s.PerTrade.IsZero() && s.PerShare.IsZero() && s.SellRate.IsZero()
+}
+
+// Apply sets the trade's commission and fees from the schedule, rounded to cents, charging
+// on the shares filled once the broker has reported any, so an order still filling pays
+// for the part that has. A zero schedule leaves the trade unchanged.
+func (s FeeSchedule) Apply(t *Trade) {
+	if s.IsZero() {
+		return
+	}
+	shares, value := t.Quantity, t.TotalValue
+	if t.FilledQuantity.IsPositive() {
+		shares, value = t.FilledQuantity, t.FilledQuantity.Mul(t.Price)
+	}
+	t.Commission = s.PerTrade.Add(s.PerShare.Mul(shares)).Round(2)
+	t.Fees = decimal.Zero
+	if t.Side == TradeSideSell {
+		t.Fees = s.SellRate.Mul(value).Round(2)
+	}
+}
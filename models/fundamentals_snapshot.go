@@ -0,0 +1,154 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// fundamentalsChangeThreshold is the smallest relative change (1%) reported for amounts
+const fundamentalsChangeThreshold = 0.01
+
+// marginChangeThreshold is the smallest margin change, in percentage points, reported
+const marginChangeThreshold = 0.5
+
+// FundamentalsSnapshot is the fundamental data an analysis of a symbol was based on
+type FundamentalsSnapshot struct {
+	ID           uuid.UUID    `json:"id"`
+	Symbol       string       `json:"symbol"`
+	Fundamentals Fundamentals `json:"fundamentals"`
+	CapturedAt   time.Time    `json:"captured_at"`
+}
+
+// NewFundamentalsSnapshot captures fundamentals for storage
+func NewFundamentalsSnapshot(f *Fundamentals) *FundamentalsSnapshot {
+	return &FundamentalsSnapshot{
+		ID:           uuid.New(),
+		Symbol:       f.Symbol,
+		Fundamentals: *f,
+		CapturedAt:   time.Now(),
+	}
+}
+
+// FundamentalsChange is one metric that moved between two snapshots
+type FundamentalsChange struct {
+	Metric   string `json:"metric"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+	Change   string `json:"change"` // e.g. "+4.2%" or "-1.3 pp"
+}
+
+// String formats the change for prompts and reasoning
+func (c FundamentalsChange) String() string {
+	return fmt.Sprintf("%s %s -> %s (%s)", c.Metric, c.Previous, c.Current, c.Change)
+}
+
+// FundamentalsDelta lists what changed in a symbol's fundamentals since a previous analysis
+type FundamentalsDelta struct {
+	Since   time.Time            `json:"since"`
+	Changes []FundamentalsChange `json:"changes"`
+}
+
+// DiffFundamentals compares current fundamentals with a previous snapshot. Metrics
+// missing from either side are skipped, except debt appearing where there was none.
+// Amounts are reported when they move by at least 1%, margins by at least half a point.
+func DiffFundamentals(prev *FundamentalsSnapshot, cur *Fundamentals) *FundamentalsDelta {
+	p := prev.Fundamentals
+	delta := &FundamentalsDelta{Since: prev.CapturedAt}
+
+	delta.addAmount("Revenue (TTM)", p.Revenue, cur.Revenue)
+	delta.addAmount("Gross profit (TTM)", p.GrossProfit, cur.GrossProfit)
+	delta.addMargin("Gross margin", p.GrossMargin(), cur.GrossMargin())
+	delta.addMargin("Operating margin", p.OperatingMargin*100, cur.OperatingMargin*100)
+	delta.addMargin("Profit margin", p.ProfitMargin*100, cur.ProfitMargin*100)
+	delta.addAmount("EPS", p.EPS, cur.EPS)
+	delta.addAmount("Market cap", p.MarketCap, cur.MarketCap)
+	if p.PERatio > 0 && cur.PERatio > 0 {
+		delta.addAmount("P/E ratio", decimal.NewFromFloat(p.PERatio), decimal.NewFromFloat(cur.PERatio))
+	}
+
+	if p.TotalDebt.IsZero() && cur.TotalDebt.IsPositive() {
+		delta.Changes = append(delta.Changes, FundamentalsChange{
+			Metric:   "Total debt",
+			Previous: "none",
+			Current:  formatAmount(cur.TotalDebt),
+			Change:   "new debt",
+		})
+	} else {
+		delta.addAmount("Total debt", p.TotalDebt, cur.TotalDebt)
+	}
+
+	return delta
+}
+
+// GrossMargin returns gross profit as a percentage of revenue, or 0 if revenue is unknown
+func (f Fundamentals) GrossMargin() float64 {
+	if !f.Revenue.IsPositive() {
+		return 0
+	}
+	return f.GrossProfit.Div(f.Revenue).InexactFloat64() * 100
+}
+
+func (d *FundamentalsDelta) addAmount(metric string, prev, cur decimal.Decimal) {
+	if prev.IsZero() || cur.IsZero() {
+		return
+	}
+	change := cur.Sub(prev).Div(prev.Abs()).InexactFloat64()
+	if math.Abs(change) < fundamentalsChangeThreshold {
+		return
+	}
+	d.Changes = append(d.Changes, FundamentalsChange{
+		Metric:   metric,
+		Previous: formatAmount(prev),
+		Current:  formatAmount(cur),
+		Change:   fmt.Sprintf("%+.1f%%", change*100),
+	})
+}
+
+func (d *FundamentalsDelta) addMargin(metric string, prev, cur float64) {
+	if prev == 0 || cur == 0 || math.Abs(cur-prev) < marginChangeThreshold {
+		return
+	}
+	d.Changes = append(d.Changes, FundamentalsChange{
+		Metric:   metric,
+		Previous: fmt.Sprintf("%.1f%%", prev),
+		Current:  fmt.Sprintf("%.1f%%", cur),
+		Change:   fmt.Sprintf("%+.1f pp", cur-prev),
+	})
+}
+
+// formatAmount abbreviates large amounts (e.g. 2.5B) and keeps small ones to two decimals
+func formatAmount(v decimal.Decimal) string {
+	f := v.InexactFloat64()
+	switch abs := math.Abs(f); {
+	case abs >= 1e12:
+		return fmt.Sprintf("%.2fT", f/1e12)
+	case abs >= 1e9:
+		return fmt.Sprintf("%.2fB", f/1e9)
+	case abs >= 1e6:
+		return fmt.Sprintf("%.2fM", f/1e6)
+	default:
+		return v.StringFixed(2)
+	}
+}
+
+// HasChanges reports whether any metric moved past its threshold
+func (d *FundamentalsDelta) HasChanges() bool {
+	return d != nil && len(d.Changes) > 0
+}
+
+// Summary formats the changes on one line, or "" if nothing changed
+func (d *FundamentalsDelta) Summary() string {
+	if !d.HasChanges() {
+		return ""
+	}
+	parts := make([]string, len(d.Changes))
+	for i, c := range d.Changes {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDiffFundamentals(t *testing.T) {
+	prev := &FundamentalsSnapshot{
+		Symbol:     "AAPL",
+		CapturedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Fundamentals: Fundamentals{
+			Revenue:      decimal.NewFromInt(100_000_000_000),
+			GrossProfit:  decimal.NewFromInt(40_000_000_000),
+			ProfitMargin: 0.20,
+			EPS:          decimal.NewFromFloat(6.00),
+			MarketCap:    decimal.NewFromInt(2_000_000_000_000),
+		},
+	}
+	cur := &Fundamentals{
+		Revenue:      decimal.NewFromInt(110_000_000_000),
+		GrossProfit:  decimal.NewFromInt(40_000_000_000),
+		ProfitMargin: 0.202,
+		EPS:          decimal.NewFromFloat(6.02),
+		MarketCap:    decimal.NewFromInt(2_000_000_000_000),
+		TotalDebt:    decimal.NewFromInt(5_000_000_000),
+	}
+
+	delta := DiffFundamentals(prev, cur)
+	if !delta.Since.Equal(prev.CapturedAt) {
+		t.Errorf("Since = %v, want %v", delta.Since, prev.CapturedAt)
+	}
+
+	got := map[string]FundamentalsChange{}
+	for _, c := range delta.Changes {
+		got[c.Metric] = c
+	}
+	if c, ok := got["Revenue (TTM)"]; !ok || c.Change != "+10.0%" || c.Current != "110.00B" {
+		t.Errorf("revenue change = %+v", c)
+	}
+	if c, ok := got["Gross margin"]; !ok || c.Change != "-3.6 pp" {
+		t.Errorf("gross margin change = %+v", c)
+	}
+	if c, ok := got["Total debt"]; !ok || c.Change != "new debt" {
+		t.Errorf("debt change = %+v", c)
+	}
+	for _, unchanged := range []string{"Profit margin", "EPS", "Market cap", "Gross profit (TTM)"} {
+		if _, ok := got[unchanged]; ok {
+			t.Errorf("%s moved less than the threshold and should not be reported", unchanged)
+		}
+	}
+	if !strings.Contains(delta.Summary(), "Revenue (TTM) 100.00B -> 110.00B (+10.0%)") {
+		t.Errorf("Summary() = %q", delta.Summary())
+	}
+}
+
+func TestDiffFundamentals_SkipsUnknownMetrics(t *testing.T) {
+	prev := &FundamentalsSnapshot{Fundamentals: Fundamentals{EPS: decimal.NewFromInt(5)}}
+	cur := &Fundamentals{EPS: decimal.NewFromInt(5), Revenue: decimal.NewFromInt(100)}
+
+	delta := DiffFundamentals(prev, cur)
+	if delta.HasChanges() {
+		t.Errorf("expected no changes, got %+v", delta.Changes)
+	}
+	if delta.Summary() != "" {
+		t.Errorf("Summary() = %q, want empty", delta.Summary())
+	}
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// insiderSaleDiscount scales down insider sales against purchases. Insiders sell for taxes,
+// diversification and scheduled plans, but buy on the open market for one reason.
+const insiderSaleDiscount = 0.5
+
+// InsiderTrade is a transaction reported by a company insider on SEC Form 4
+type InsiderTrade struct {
+	Symbol          string    `json:"symbol"`
+	Insider         string    `json:"insider"`          // Reporting person
+	Role            string    `json:"role,omitempty"`   // e.g. "director", "officer: CEO"
+	TransactionType string    `json:"transaction_type"` // Form 4 code and description, e.g. "P-Purchase"
+	Shares          float64   `json:"shares"`
+	Price           float64   `json:"price"`
+	TradedAt        time.Time `json:"traded_at"`
+	FiledAt         time.Time `json:"filed_at"`
+}
+
+// OpenMarketPurchase reports whether the trade is an open-market buy (Form 4 code P)
+func (t InsiderTrade) OpenMarketPurchase() bool {
+	return strings.HasPrefix(t.TransactionType, "P")
+}
+
+// OpenMarketSale reports whether the trade is an open-market sale (Form 4 code S).
+// Awards, option exercises, gifts and shares withheld for taxes are neither.
+func (t InsiderTrade) OpenMarketSale() bool {
+	return strings.HasPrefix(t.TransactionType, "S")
+}
+
+// Value returns the dollar value of the trade
+func (t InsiderTrade) Value() float64 {
+	return t.Shares * t.Price
+}
+
+// InsiderActivity totals the open-market insider trades in a symbol over a period
+type InsiderActivity struct {
+	Purchases     int     `json:"purchases"`
+	Sales         int     `json:"sales"`
+	PurchaseValue float64 `json:"purchase_value"`
+	SaleValue     float64 `json:"sale_value"`
+	Buyers        int     `json:"buyers"`  // Distinct insiders who bought
+	Sellers       int     `json:"sellers"` // Distinct insiders who sold
+}
+
+// SummarizeInsiderTrades totals the open-market purchases and sales traded at or after since
+func SummarizeInsiderTrades(trades []InsiderTrade, since time.Time) InsiderActivity {
+	var a InsiderActivity
+	buyers := make(map[string]bool)
+	sellers := make(map[string]bool)
+	for _, t := range trades {
+		if t.TradedAt.Before(since) {
+			continue
+		}
+		switch {
+		case t.OpenMarketPurchase():
+			a.Purchases++
+			a.PurchaseValue += t.Value()
+			buyers[t.Insider] = true
+		case t.OpenMarketSale():
+			a.Sales++
+			a.SaleValue += t.Value()
+			sellers[t.Insider] = true
+		}
+	}
+	a.Buyers, a.Sellers = len(buyers), len(sellers)
+	return a
+}
+
+// Score returns the net buying pressure from -100 (only selling) to 100 (only buying), with
+// sales discounted against purchases. It is 0 without open-market trades.
+func (a InsiderActivity) Score() float64 {
+	buys := a.PurchaseValue
+	sells := a.SaleValue * insiderSaleDiscount
+	if buys+sells == 0 {
+		return 0
+	}
+	return (buys - sells) / (buys + sells) * 100
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeInsiderTrades(t *testing.T) {
+	now := time.Now()
+	trades := []InsiderTrade{
+		{Insider: "Jane CEO", TransactionType: "P-Purchase", Shares: 1000, Price: 50, TradedAt: now.AddDate(0, 0, -5)},
+		{Insider: "Jane CEO", TransactionType: "P-Purchase", Shares: 1000, Price: 50, TradedAt: now.AddDate(0, 0, -3)},
+		{Insider: "John CFO", TransactionType: "S-Sale", Shares: 2000, Price: 50, TradedAt: now.AddDate(0, 0, -10)},
+		{Insider: "John CFO", TransactionType: "M-Exempt", Shares: 5000, Price: 10, TradedAt: now.AddDate(0, 0, -10)},
+		{Insider: "Old Director", TransactionType: "P-Purchase", Shares: 9999, Price: 50, TradedAt: now.AddDate(0, 0, -200)},
+	}
+
+	a := SummarizeInsiderTrades(trades, now.AddDate(0, 0, -90))
+	if a.Purchases != 2 || a.Buyers != 1 || a.PurchaseValue != 100000 {
+		t.Errorf("unexpected purchases %+v", a)
+	}
+	if a.Sales != 1 || a.Sellers != 1 || a.SaleValue != 100000 {
+		t.Errorf("unexpected sales %+v", a)
+	}
+	// Equal values with sales discounted by half: (100k - 50k) / (100k + 50k)
+	if score := a.Score(); score < 33.3 || score > 33.4 {
+		t.Errorf("Score() = %.2f, want 33.33", score)
+	}
+}
+
+func TestInsiderActivity_Score(t *testing.T) {
+	tests := []struct {
+		name     string
+		activity InsiderActivity
+		want     float64
+	}{
+		{"no trades", InsiderActivity{}, 0},
+		{"only buying", InsiderActivity{PurchaseValue: 10000}, 100},
+		{"only selling", InsiderActivity{SaleValue: 10000}, -100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.activity.Score(); got != tt.want {
+				t.Errorf("Score() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
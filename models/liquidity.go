@@ -0,0 +1,35 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// LiquidityLimit caps an order's size relative to the symbol's average daily volume (ADV).
+// Orders that take a large share of a day's volume move the price against themselves.
+type LiquidityLimit struct {
+	MaxADVPercent float64 // Largest order as a fraction of ADV (0-1, 0 = unlimited)
+	Reject        bool    // Reject orders over the limit instead of only warning
+}
+
+// Check compares an order for quantity shares against the symbol's average daily volume.
+// It returns a warning describing an oversized order, or an ErrRiskRuleViolation instead
+// when the limit rejects. Unknown volume (adv <= 0) is not checked.
+func (l LiquidityLimit) Check(symbol string, quantity decimal.Decimal, adv int64) (string, error) {
+	if l.MaxADVPercent <= 0 || adv <= 0 || !quantity.IsPositive() {
+		return "", nil
+	}
+
+	share, _ := quantity.Div(decimal.NewFromInt(adv)).Float64()
+	if share <= l.MaxADVPercent {
+		return "", nil
+	}
+
+	msg := fmt.Sprintf("%s shares of %s is %.1f%% of average daily volume (%d), above the %.1f%% limit",
+		quantity, symbol, share*100, adv, l.MaxADVPercent*100)
+	if l.Reject {
+		return "", fmt.Errorf("%w: %s", ErrRiskRuleViolation, msg)
+	}
+	return msg, nil
+}
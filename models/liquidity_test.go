@@ -0,0 +1,37 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestLiquidityLimit_Check(t *testing.T) {
+	tests := []struct {
+		name        string
+		limit       LiquidityLimit
+		quantity    int64
+		adv         int64
+		wantWarning bool
+		wantErr     bool
+	}{
+		{"within limit", LiquidityLimit{MaxADVPercent: 0.01}, 500, 100_000, false, false},
+		{"over limit warns", LiquidityLimit{MaxADVPercent: 0.01}, 5_000, 100_000, true, false},
+		{"over limit rejects", LiquidityLimit{MaxADVPercent: 0.01, Reject: true}, 5_000, 100_000, false, true},
+		{"disabled", LiquidityLimit{}, 5_000, 100_000, false, false},
+		{"unknown volume", LiquidityLimit{MaxADVPercent: 0.01, Reject: true}, 5_000, 0, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warning, err := tt.limit.Check("AAPL", decimal.NewFromInt(tt.quantity), tt.adv)
+			if (warning != "") != tt.wantWarning {
+				t.Errorf("warning = %q, want warning %v", warning, tt.wantWarning)
+			}
+			if tt.wantErr != errors.Is(err, ErrRiskRuleViolation) || (!tt.wantErr && err != nil) {
+				t.Errorf("err = %v, want ErrRiskRuleViolation %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrLLMBudgetExceeded is returned when an analysis would spend past the monthly LLM
+	// budget and LLM_BUDGET_MODE is block
+	ErrLLMBudgetExceeded = errors.New("monthly LLM budget exceeded")
+	// ErrInvalidUsagePeriod is returned for a usage period other than day, week or month
+	ErrInvalidUsagePeriod = errors.New("invalid usage period")
+)
+
+// LLMUsage is the tokens one agent run's LLM call used and what they cost. AgentRunID is
+// nil for dry-run replays, which are charged but not recorded as runs.
+type LLMUsage struct {
+	ID           uuid.UUID  `json:"id"`
+	AgentRunID   *uuid.UUID `json:"agent_run_id,omitempty"`
+	AgentType    AgentType  `json:"agent_type"`
+	Symbol       string     `json:"symbol"`
+	Provider     string     `json:"provider"`
+	Model        string     `json:"model"`
+	InputTokens  int64      `json:"input_tokens"`
+	OutputTokens int64      `json:"output_tokens"`
+	CostUSD      float64    `json:"cost_usd"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// NewLLMUsage creates a usage record for a call, estimating its cost
+func NewLLMUsage(agentType AgentType, symbol, provider, model string, inputTokens, outputTokens int64) LLMUsage {
+	cost, _ := EstimateLLMCost(provider, model, inputTokens, outputTokens)
+	return LLMUsage{
+		ID:           uuid.New(),
+		AgentType:    agentType,
+		Symbol:       symbol,
+		Provider:     provider,
+		Model:        model,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		CostUSD:      cost,
+		CreatedAt:    time.Now(),
+	}
+}
+
+// LLMModelPrice is what a model costs per million input and output tokens, in USD
+type LLMModelPrice struct {
+	Input  float64
+	Output float64
+}
+
+// llmModelPrices are list prices by model name prefix. The longest matching prefix wins,
+// so dated snapshots such as gpt-4o-2024-08-06 are priced as their model.
+var llmModelPrices = map[string]LLMModelPrice{
+	"gpt-4o":            {Input: 2.50, Output: 10},
+	"gpt-4o-mini":       {Input: 0.15, Output: 0.60},
+	"gpt-4.1":           {Input: 2, Output: 8},
+	"gpt-4.1-mini":      {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano":      {Input: 0.10, Output: 0.40},
+	"gpt-5":             {Input: 1.25, Output: 10},
+	"gpt-5-mini":        {Input: 0.25, Output: 2},
+	"gpt-5-nano":        {Input: 0.05, Output: 0.40},
+	"o3":                {Input: 2, Output: 8},
+	"o3-mini":           {Input: 1.10, Output: 4.40},
+	"o4-mini":           {Input: 1.10, Output: 4.40},
+	"claude-opus-4":     {Input: 15, Output: 75},
+	"claude-opus-4-5":   {Input: 5, Output: 25},
+	"claude-sonnet-4":   {Input: 3, Output: 15},
+	"claude-haiku-4-5":  {Input: 1, Output: 5},
+	"claude-3-7-sonnet": {Input: 3, Output: 15},
+	"claude-3-5-sonnet": {Input: 3, Output: 15},
+	"claude-3-5-haiku":  {Input: 0.80, Output: 4},
+}
+
+// EstimateLLMCost returns what a call is estimated to cost in USD, and false when the
+// model's price is unknown and the cost was taken as zero. Local Ollama models are free.
+func EstimateLLMCost(provider, model string, inputTokens, outputTokens int64) (float64, bool) {
+	if provider == "ollama" {
+		return 0, true
+	}
+	price, ok := LLMModelPriceOf(model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(inputTokens)*price.Input + float64(outputTokens)*price.Output) / 1_000_000, true
+}
+
+// LLMModelPriceOf returns the list price of a model, or false if it is unknown
+func LLMModelPriceOf(model string) (LLMModelPrice, bool) {
+	var (
+		best    LLMModelPrice
+		bestLen int
+	)
+	for prefix, price := range llmModelPrices {
+		if len(prefix) > bestLen && strings.HasPrefix(model, prefix) {
+			best, bestLen = price, len(prefix)
+		}
+	}
+	return best, bestLen > 0
+}
+
+// LLMUsageTotals are the calls, tokens and estimated cost of a group of LLM calls
+type LLMUsageTotals struct {
+	Calls        int64   `json:"calls"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// add adds other's calls, tokens and cost
+func (t *LLMUsageTotals) add(other LLMUsageTotals) {
+	t.Calls += other.Calls
+	t.InputTokens += other.InputTokens
+	t.OutputTokens += other.OutputTokens
+	t.CostUSD += other.CostUSD
+}
+
+// LLMUsageRow totals the LLM calls one agent type made on one model
+type LLMUsageRow struct {
+	AgentType AgentType
+	Provider  string
+	Model     string
+	LLMUsageTotals
+}
+
+// LLMAgentUsage totals one agent type's LLM calls
+type LLMAgentUsage struct {
+	AgentType AgentType `json:"agent_type"`
+	LLMUsageTotals
+}
+
+// LLMModelUsage totals the LLM calls made on one model. Priced is false when the model's
+// price is unknown and its cost is counted as zero.
+type LLMModelUsage struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	Priced   bool   `json:"priced"`
+	LLMUsageTotals
+}
+
+// LLMBudget is the month's LLM spend against LLM_MONTHLY_BUDGET. Mode is warn or block.
+type LLMBudget struct {
+	LimitUSD     float64 `json:"limit_usd"`
+	SpentUSD     float64 `json:"spent_usd"`
+	RemainingUSD float64 `json:"remaining_usd"`
+	PercentUsed  float64 `json:"percent_used"`
+	Mode         string  `json:"mode"`
+	Exceeded     bool    `json:"exceeded"`
+}
+
+// NewLLMBudget compares the month's spend with a budget
+func NewLLMBudget(limit, spent float64, mode string) *LLMBudget {
+	budget := &LLMBudget{
+		LimitUSD:     limit,
+		SpentUSD:     spent,
+		RemainingUSD: max(limit-spent, 0),
+		Mode:         mode,
+		Exceeded:     spent >= limit,
+	}
+	if limit > 0 {
+		budget.PercentUsed = spent / limit * 100
+	}
+	return budget
+}
+
+// LLMUsageReport summarizes LLM token usage and estimated cost over a period. Budget is
+// nil when no monthly budget is configured.
+type LLMUsageReport struct {
+	Period string    `json:"period"`
+	Since  time.Time `json:"since"`
+	LLMUsageTotals
+	ByAgent []LLMAgentUsage `json:"by_agent"` // Most expensive first
+	ByModel []LLMModelUsage `json:"by_model"` // Most expensive first
+	Budget  *LLMBudget      `json:"budget,omitempty"`
+}
+
+// NewLLMUsageReport totals usage rows per agent type and per model
+func NewLLMUsageReport(period string, since time.Time, rows []LLMUsageRow) *LLMUsageReport {
+	report := &LLMUsageReport{Period: period, Since: since, ByAgent: []LLMAgentUsage{}, ByModel: []LLMModelUsage{}}
+	agentIdx := make(map[AgentType]int)
+	modelIdx := make(map[[2]string]int)
+	for _, row := range rows {
+		report.add(row.LLMUsageTotals)
+
+		i, ok := agentIdx[row.AgentType]
+		if !ok {
+			i = len(report.ByAgent)
+			agentIdx[row.AgentType] = i
+			report.ByAgent = append(report.ByAgent, LLMAgentUsage{AgentType: row.AgentType})
+		}
+		report.ByAgent[i].add(row.LLMUsageTotals)
+
+		key := [2]string{row.Provider, row.Model}
+		j, ok := modelIdx[key]
+		if !ok {
+			j = len(report.ByModel)
+			modelIdx[key] = j
+			_, priced := EstimateLLMCost(row.Provider, row.Model, 0, 0)
+			report.ByModel = append(report.ByModel, LLMModelUsage{Provider: row.Provider, Model: row.Model, Priced: priced})
+		}
+		report.ByModel[j].add(row.LLMUsageTotals)
+	}
+
+	sort.SliceStable(report.ByAgent, func(i, j int) bool {
+		return report.ByAgent[i].CostUSD > report.ByAgent[j].CostUSD
+	})
+	sort.SliceStable(report.ByModel, func(i, j int) bool {
+		return report.ByModel[i].CostUSD > report.ByModel[j].CostUSD
+	})
+	return report
+}
+
+// LLMUsagePeriodStart returns when a usage period began: the start of the UTC day for
+// day, six days before that for week and the first of the UTC month for month
+func LLMUsagePeriodStart(period string, now time.Time) (time.Time, error) {
+	today := now.UTC().Truncate(24 * time.Hour)
+	switch period {
+	case "day":
+		return today, nil
+	case "week":
+		return today.AddDate(0, 0, -6), nil
+	case "month":
+		return today.AddDate(0, 0, 1-today.Day()), nil
+	default:
+		return time.Time{}, fmt.Errorf("%w: %q, expected day, week or month", ErrInvalidUsagePeriod, period)
+	}
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEstimateLLMCost(t *testing.T) {
+	tests := []struct {
+		provider, model string
+		want            float64
+		priced          bool
+	}{
+		{"openai", "gpt-4o", 2.50 + 10, true},
+		{"openai", "gpt-4o-mini-2024-07-18", 0.15 + 0.60, true},
+		{"anthropic", "claude-sonnet-4-5", 3 + 15, true},
+		{"anthropic", "claude-opus-4-5-20251101", 5 + 25, true},
+		{"ollama", "llama3.1", 0, true},
+		{"openai", "unknown-model", 0, false},
+	}
+	for _, tt := range tests {
+		got, priced := EstimateLLMCost(tt.provider, tt.model, 1_000_000, 1_000_000)
+		if math.Abs(got-tt.want) > 1e-9 || priced != tt.priced {
+			t.Errorf("EstimateLLMCost(%s, %s) = %v, %v, want %v, %v", tt.provider, tt.model, got, priced, tt.want, tt.priced)
+		}
+	}
+}
+
+func TestNewLLMUsageReport(t *testing.T) {
+	rows := []LLMUsageRow{
+		{AgentType: AgentTypeNews, Provider: "openai", Model: "gpt-4o", LLMUsageTotals: LLMUsageTotals{Calls: 4, InputTokens: 4000, OutputTokens: 800, CostUSD: 0.018}},
+		{AgentType: AgentTypeFundamental, Provider: "openai", Model: "gpt-4o", LLMUsageTotals: LLMUsageTotals{Calls: 2, InputTokens: 6000, OutputTokens: 600, CostUSD: 0.021}},
+		{AgentType: AgentTypeNews, Provider: "custom", Model: "in-house", LLMUsageTotals: LLMUsageTotals{Calls: 1, InputTokens: 500, OutputTokens: 100}},
+	}
+
+	report := NewLLMUsageReport("month", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), rows)
+	if report.Calls != 7 || report.InputTokens != 10500 || math.Abs(report.CostUSD-0.039) > 1e-9 {
+		t.Errorf("totals = %+v, want 7 calls, 10500 input tokens, $0.039", report.LLMUsageTotals)
+	}
+	if len(report.ByAgent) != 2 || report.ByAgent[0].AgentType != AgentTypeFundamental || report.ByAgent[1].Calls != 5 {
+		t.Errorf("by agent = %+v, want fundamental first and news with 5 calls", report.ByAgent)
+	}
+	if len(report.ByModel) != 2 || report.ByModel[0].Model != "gpt-4o" || report.ByModel[0].Calls != 6 || !report.ByModel[0].Priced {
+		t.Errorf("by model = %+v, want gpt-4o first with 6 calls", report.ByModel)
+	}
+	if report.ByModel[1].Priced {
+		t.Errorf("model %s reported as priced", report.ByModel[1].Model)
+	}
+}
+
+func TestNewLLMBudget(t *testing.T) {
+	budget := NewLLMBudget(50, 60, "warn")
+	if !budget.Exceeded || budget.RemainingUSD != 0 || budget.PercentUsed != 120 {
+		t.Errorf("budget = %+v, want exceeded at 120%% with nothing remaining", budget)
+	}
+	if budget := NewLLMBudget(50, 10, "block"); budget.Exceeded || budget.RemainingUSD != 40 {
+		t.Errorf("budget = %+v, want $40 remaining", budget)
+	}
+}
+
+func TestLLMUsagePeriodStart(t *testing.T) {
+	now := time.Date(2024, 5, 17, 15, 30, 0, 0, time.UTC)
+	for period, want := range map[string]time.Time{
+		"day":   time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC),
+		"week":  time.Date(2024, 5, 11, 0, 0, 0, 0, time.UTC),
+		"month": time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		if got, err := LLMUsagePeriodStart(period, now); err != nil || !got.Equal(want) {
+			t.Errorf("LLMUsagePeriodStart(%s) = %v, %v, want %v", period, got, err, want)
+		}
+	}
+	if _, err := LLMUsagePeriodStart("year", now); !errors.Is(err, ErrInvalidUsagePeriod) {
+		t.Errorf("LLMUsagePeriodStart(year) error = %v, want ErrInvalidUsagePeriod", err)
+	}
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// EconomicObservation is one dated value of an economic data series
+type EconomicObservation struct {
+	Date  time.Time `json:"date"`
+	Value float64   `json:"value"`
+}
+
+// MacroSnapshot is the latest reading of the economic indicators behind the macro score
+type MacroSnapshot struct {
+	FedFundsRate     float64   `json:"fed_funds_rate"`    // Effective federal funds rate, percent
+	FedFundsChange   float64   `json:"fed_funds_change"`  // Change over the past year, percentage points
+	YieldCurve       float64   `json:"yield_curve"`       // 10-year minus 2-year Treasury yield, percentage points
+	Inflation        float64   `json:"inflation"`         // CPI change over the past year, percent
+	UnemploymentRate float64   `json:"unemployment_rate"` // Percent of the labor force
+	UnemploymentRise float64   `json:"unemployment_rise"` // Rise above the past year's low, percentage points
+	AsOf             time.Time `json:"as_of"`             // Latest reading date of the series that lags most
+}
+
+// YieldCurveInverted reports whether short-term Treasuries yield more than long-term ones,
+// which has preceded most US recessions
+func (s MacroSnapshot) YieldCurveInverted() bool {
+	return s.YieldCurve < 0
+}
+
+// SahmRecession reports whether unemployment has risen half a point above its low of the
+// past year, the Sahm rule's early recession signal. The rule uses three-month averages;
+// monthly readings trip it slightly earlier.
+func (s MacroSnapshot) SahmRecession() bool {
+	return s.UnemploymentRise >= 0.5
+}
@@ -1,11 +1,16 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/shopspring/decimal"
 )
 
+// ErrSymbolNotFound is returned when a market data provider definitely has no data for a
+// symbol, as opposed to failing to answer
+var ErrSymbolNotFound = errors.New("symbol not found")
+
 // Quote represents real-time quote data for a stock
 type Quote struct {
 	Symbol    string          `json:"symbol"`
@@ -16,6 +21,19 @@ type Quote struct {
 	Last      decimal.Decimal `json:"last"`
 	Volume    int64           `json:"volume"`
 	Timestamp time.Time       `json:"timestamp"`
+	Session   MarketSession   `json:"session,omitempty"` // Session the quote was printed in (pre, regular, after, closed)
+}
+
+// Price returns the last trade price, or the bid/ask midpoint for a quote without one, such
+// as Alpaca's latest quote. It is zero when the quote has neither.
+func (q *Quote) Price() decimal.Decimal {
+	if q.Last.IsPositive() {
+		return q.Last
+	}
+	if q.Bid.IsPositive() && q.Ask.IsPositive() {
+		return q.Bid.Add(q.Ask).Div(decimal.NewFromInt(2))
+	}
+	return decimal.Zero
 }
 
 // Bar represents OHLCV price data for a time period
@@ -32,17 +50,22 @@ type Bar struct {
 
 // Fundamentals represents key fundamental data for a stock
 type Fundamentals struct {
-	Symbol        string          `json:"symbol"`
-	MarketCap     decimal.Decimal `json:"market_cap"`
-	PERatio       float64         `json:"pe_ratio"`
-	EPS           decimal.Decimal `json:"eps"`
-	DividendYield float64         `json:"dividend_yield"`
-	Week52High    decimal.Decimal `json:"week52_high"`
-	Week52Low     decimal.Decimal `json:"week52_low"`
-	Beta          float64         `json:"beta"`
-	Revenue       decimal.Decimal `json:"revenue"`
-	GrossProfit   decimal.Decimal `json:"gross_profit"`
-	UpdatedAt     time.Time       `json:"updated_at"`
+	Symbol          string          `json:"symbol"`
+	Sector          string          `json:"sector,omitempty"`   // GICS sector
+	Industry        string          `json:"industry,omitempty"` // GICS industry when recognized, otherwise provider value
+	MarketCap       decimal.Decimal `json:"market_cap"`
+	PERatio         float64         `json:"pe_ratio"`
+	EPS             decimal.Decimal `json:"eps"`
+	DividendYield   float64         `json:"dividend_yield"`
+	Week52High      decimal.Decimal `json:"week52_high"`
+	Week52Low       decimal.Decimal `json:"week52_low"`
+	Beta            float64         `json:"beta"`
+	Revenue         decimal.Decimal `json:"revenue"`
+	GrossProfit     decimal.Decimal `json:"gross_profit"`
+	ProfitMargin    float64         `json:"profit_margin,omitempty"`    // Net margin as a fraction (0.25 = 25%)
+	OperatingMargin float64         `json:"operating_margin,omitempty"` // Operating margin as a fraction
+	TotalDebt       decimal.Decimal `json:"total_debt,omitempty"`       // Short and long-term debt from the latest balance sheet, zero if unknown
+	UpdatedAt       time.Time       `json:"updated_at"`
 }
 
 // NewsArticle represents a news article about a stock
@@ -56,6 +79,43 @@ type NewsArticle struct {
 	PublishedAt time.Time `json:"published_at"`
 }
 
+// SocialPost is a Reddit post or StockTwits message mentioning a symbol
+type SocialPost struct {
+	Source    string          `json:"source"` // "reddit" or "stocktwits"
+	Text      string          `json:"text"`
+	URL       string          `json:"url,omitempty"`
+	Sentiment SocialSentiment `json:"sentiment,omitempty"` // Author's own label; only StockTwits messages carry one
+	Score     int             `json:"score"`               // Reddit upvotes or StockTwits likes
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// SocialSentiment is the bullish or bearish label an author puts on a post
+type SocialSentiment string
+
+const (
+	SocialSentimentBullish SocialSentiment = "bullish"
+	SocialSentimentBearish SocialSentiment = "bearish"
+)
+
+// SocialLabelScore returns the net share of labeled posts that are bullish, from -100 (all
+// bearish) to 100 (all bullish), and how many posts carried a label
+func SocialLabelScore(posts []SocialPost) (score float64, labeled int) {
+	var bullish, bearish int
+	for _, p := range posts {
+		switch p.Sentiment {
+		case SocialSentimentBullish:
+			bullish++
+		case SocialSentimentBearish:
+			bearish++
+		}
+	}
+	labeled = bullish + bearish
+	if labeled == 0 {
+		return 0, 0
+	}
+	return float64(bullish-bearish) / float64(labeled) * 100, labeled
+}
+
 // TechnicalIndicators holds computed technical analysis indicators
 type TechnicalIndicators struct {
 	Symbol         string          `json:"symbol"`
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestQuote_Price(t *testing.T) {
+	tests := []struct {
+		name  string
+		quote Quote
+		want  int64
+	}{
+		{"last trade", Quote{Bid: decimal.NewFromInt(99), Ask: decimal.NewFromInt(103), Last: decimal.NewFromInt(100)}, 100},
+		{"midpoint without a trade", Quote{Bid: decimal.NewFromInt(99), Ask: decimal.NewFromInt(103)}, 101},
+		{"one-sided quote", Quote{Bid: decimal.NewFromInt(99)}, 0},
+		{"empty", Quote{}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.quote.Price(); !got.Equal(decimal.NewFromInt(tt.want)) {
+			t.Errorf("%s: Price() = %s, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrOutsideRegularSession is returned when a market order is placed outside the regular session
+var ErrOutsideRegularSession = errors.New("market orders are only accepted during the regular session")
+
+// MarketSession identifies the US equity trading session a timestamp falls in
+type MarketSession string
+
+const (
+	MarketSessionPre     MarketSession = "pre"
+	MarketSessionRegular MarketSession = "regular"
+	MarketSessionAfter   MarketSession = "after"
+	MarketSessionClosed  MarketSession = "closed"
+)
+
+// marketLocation is the exchange time zone used for session boundaries
+var marketLocation = loadMarketLocation()
+
+func loadMarketLocation() *time.Location {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		// tzdata unavailable; EST is close enough to keep sessions roughly aligned
+		return time.FixedZone("EST", -5*60*60)
+	}
+	return loc
+}
+
+// MarketLocation returns the exchange time zone used for session boundaries
+func MarketLocation() *time.Location {
+	return marketLocation
+}
+
+// MarketSessionAt returns the session for a point in time from the standard hours alone.
+// Pre-market runs 04:00-09:30, regular 09:30-16:00, and after-hours 16:00-20:00 Eastern.
+// Weekends are closed; exchange holidays and early closes are not accounted for, so the
+// current session comes from MarketStatusFromCalendar with the broker's calendar instead.
+func MarketSessionAt(t time.Time) MarketSession {
+	et := t.In(marketLocation)
+	if et.Weekday() == time.Saturday || et.Weekday() == time.Sunday {
+		return MarketSessionClosed
+	}
+
+	minutes := et.Hour()*60 + et.Minute()
+	switch {
+	case minutes >= 4*60 && minutes < 9*60+30:
+		return MarketSessionPre
+	case minutes >= 9*60+30 && minutes < 16*60:
+		return MarketSessionRegular
+	case minutes >= 16*60 && minutes < 20*60:
+		return MarketSessionAfter
+	default:
+		return MarketSessionClosed
+	}
+}
+
+// IsExtendedHours returns true for the pre-market and after-hours sessions
+func (s MarketSession) IsExtendedHours() bool {
+	return s == MarketSessionPre || s == MarketSessionAfter
+}
+
+// Label returns a human-readable name for the session
+func (s MarketSession) Label() string {
+	switch s {
+	case MarketSessionPre:
+		return "Pre-market"
+	case MarketSessionRegular:
+		return "Regular"
+	case MarketSessionAfter:
+		return "After-hours"
+	case MarketSessionClosed:
+		return "Closed"
+	default:
+		return "Unknown"
+	}
+}
+
+// TradingDay is one day the exchange is open, from the broker's calendar
+type TradingDay struct {
+	Open  time.Time // Regular session open
+	Close time.Time // Regular session close, earlier than 16:00 ET on early-close days
+}
+
+// MarketStatus describes the current session and why the market is in it. NextOpen and
+// NextClose are the next regular-session open and close, nil when no calendar was available.
+type MarketStatus struct {
+	Session   MarketSession `json:"session"`
+	Reason    string        `json:"reason"`
+	NextOpen  *time.Time    `json:"next_open,omitempty"`
+	NextClose *time.Time    `json:"next_close,omitempty"`
+}
+
+// StandardHoursStatus returns the session at t from the standard hours alone, noting why
+// the exchange's calendar wasn't used
+func StandardHoursStatus(t time.Time, note string) MarketStatus {
+	session := MarketSessionAt(t)
+	return MarketStatus{Session: session, Reason: fmt.Sprintf("%s (standard hours; %s)", session.Label(), note)}
+}
+
+// MarketStatusFromCalendar returns the session at t from the exchange's calendar entries
+// for t's date onward, in date order, with the next regular-session open and close after t.
+// A date missing from days is a weekend or market holiday.
+func MarketStatusFromCalendar(t time.Time, days []TradingDay) MarketStatus {
+	var today *TradingDay
+	for i := range days {
+		if MarketDate(days[i].Open).Equal(MarketDate(t)) {
+			today = &days[i]
+			break
+		}
+	}
+
+	status := MarketStatusAt(t, today)
+	for _, d := range days {
+		if status.NextOpen == nil && d.Open.After(t) {
+			open := d.Open
+			status.NextOpen = &open
+		}
+		if status.NextClose == nil && d.Close.After(t) {
+			closeAt := d.Close
+			status.NextClose = &closeAt
+		}
+	}
+	return status
+}
+
+// MarketStatusAt returns the session at t given the exchange's calendar entry for t's date.
+// A nil day means the exchange is closed all day: a weekend or market holiday. Extended
+// hours run from 04:00 Eastern to the open and from the close to 20:00.
+func MarketStatusAt(t time.Time, day *TradingDay) MarketStatus {
+	et := t.In(marketLocation)
+	if day == nil {
+		if et.Weekday() == time.Saturday || et.Weekday() == time.Sunday {
+			return MarketStatus{Session: MarketSessionClosed, Reason: "Closed for the weekend"}
+		}
+		return MarketStatus{Session: MarketSessionClosed, Reason: "Closed for a market holiday"}
+	}
+
+	preOpen := time.Date(et.Year(), et.Month(), et.Day(), 4, 0, 0, 0, marketLocation)
+	afterClose := time.Date(et.Year(), et.Month(), et.Day(), 20, 0, 0, 0, marketLocation)
+	closeET := day.Close.In(marketLocation)
+	earlyClose := closeET.Hour() < 16
+	openLabel := day.Open.In(marketLocation).Format("15:04")
+
+	switch {
+	case et.Before(preOpen):
+		return MarketStatus{Session: MarketSessionClosed, Reason: "Closed overnight; pre-market opens at 04:00 ET"}
+	case et.Before(day.Open):
+		return MarketStatus{Session: MarketSessionPre, Reason: fmt.Sprintf("Pre-market; the regular session opens at %s ET", openLabel)}
+	case et.Before(day.Close):
+		if earlyClose {
+			return MarketStatus{Session: MarketSessionRegular, Reason: fmt.Sprintf("Regular session with an early close at %s ET", closeET.Format("15:04"))}
+		}
+		return MarketStatus{Session: MarketSessionRegular, Reason: "Regular session"}
+	case et.Before(afterClose):
+		if earlyClose {
+			return MarketStatus{Session: MarketSessionAfter, Reason: fmt.Sprintf("After-hours following an early close at %s ET", closeET.Format("15:04"))}
+		}
+		return MarketStatus{Session: MarketSessionAfter, Reason: "After-hours; the regular session has closed"}
+	default:
+		return MarketStatus{Session: MarketSessionClosed, Reason: "Closed for the night"}
+	}
+}
+
+// OutsideSessionError is returned when a market order cannot be placed in the current
+// session. It carries the session so the caller can offer a limit or queued order instead.
+type OutsideSessionError struct {
+	Status MarketStatus
+}
+
+func (e *OutsideSessionError) Error() string {
+	return fmt.Sprintf("%s (%s)", ErrOutsideRegularSession, e.Status.Reason)
+}
+
+// Unwrap lets errors.Is match ErrOutsideRegularSession
+func (e *OutsideSessionError) Unwrap() error {
+	return ErrOutsideRegularSession
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMarketSessionAt(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("America/New_York time zone not available")
+	}
+
+	tests := []struct {
+		name string
+		time time.Time
+		want MarketSession
+	}{
+		{"overnight", time.Date(2024, 1, 16, 3, 59, 0, 0, ny), MarketSessionClosed},
+		{"pre-market open", time.Date(2024, 1, 16, 4, 0, 0, 0, ny), MarketSessionPre},
+		{"just before open", time.Date(2024, 1, 16, 9, 29, 0, 0, ny), MarketSessionPre},
+		{"regular open", time.Date(2024, 1, 16, 9, 30, 0, 0, ny), MarketSessionRegular},
+		{"midday", time.Date(2024, 1, 16, 12, 0, 0, 0, ny), MarketSessionRegular},
+		{"close", time.Date(2024, 1, 16, 16, 0, 0, 0, ny), MarketSessionAfter},
+		{"late after-hours", time.Date(2024, 1, 16, 19, 59, 0, 0, ny), MarketSessionAfter},
+		{"evening", time.Date(2024, 1, 16, 20, 0, 0, 0, ny), MarketSessionClosed},
+		{"saturday", time.Date(2024, 1, 20, 12, 0, 0, 0, ny), MarketSessionClosed},
+		{"utc input", time.Date(2024, 1, 16, 15, 0, 0, 0, time.UTC), MarketSessionRegular},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MarketSessionAt(tt.time); got != tt.want {
+				t.Errorf("MarketSessionAt(%v) = %v, want %v", tt.time, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarketSession_IsExtendedHours(t *testing.T) {
+	tests := []struct {
+		session MarketSession
+		want    bool
+	}{
+		{MarketSessionPre, true},
+		{MarketSessionRegular, false},
+		{MarketSessionAfter, true},
+		{MarketSessionClosed, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.session.IsExtendedHours(); got != tt.want {
+			t.Errorf("%s.IsExtendedHours() = %v, want %v", tt.session, got, tt.want)
+		}
+	}
+}
+
+func TestMarketSession_Label(t *testing.T) {
+	if got := MarketSessionPre.Label(); got != "Pre-market" {
+		t.Errorf("Label() = %q, want Pre-market", got)
+	}
+	if got := MarketSession("bogus").Label(); got != "Unknown" {
+		t.Errorf("Label() = %q, want Unknown", got)
+	}
+}
+
+func TestMarketStatusAt(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("America/New_York time zone not available")
+	}
+
+	regular := &TradingDay{Open: time.Date(2024, 1, 16, 9, 30, 0, 0, ny), Close: time.Date(2024, 1, 16, 16, 0, 0, 0, ny)}
+	early := &TradingDay{Open: time.Date(2024, 11, 29, 9, 30, 0, 0, ny), Close: time.Date(2024, 11, 29, 13, 0, 0, 0, ny)}
+
+	tests := []struct {
+		name string
+		time time.Time
+		day  *TradingDay
+		want MarketSession
+	}{
+		{"holiday", time.Date(2024, 1, 15, 12, 0, 0, 0, ny), nil, MarketSessionClosed},
+		{"weekend", time.Date(2024, 1, 20, 12, 0, 0, 0, ny), nil, MarketSessionClosed},
+		{"overnight", time.Date(2024, 1, 16, 3, 0, 0, 0, ny), regular, MarketSessionClosed},
+		{"pre-market", time.Date(2024, 1, 16, 8, 0, 0, 0, ny), regular, MarketSessionPre},
+		{"regular", time.Date(2024, 1, 16, 12, 0, 0, 0, ny), regular, MarketSessionRegular},
+		{"after early close", time.Date(2024, 11, 29, 14, 0, 0, 0, ny), early, MarketSessionAfter},
+		{"evening", time.Date(2024, 1, 16, 21, 0, 0, 0, ny), regular, MarketSessionClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := MarketStatusAt(tt.time, tt.day)
+			if status.Session != tt.want {
+				t.Errorf("Session = %v, want %v", status.Session, tt.want)
+			}
+			if status.Reason == "" {
+				t.Error("expected a reason")
+			}
+		})
+	}
+
+	if got := MarketStatusAt(time.Date(2024, 1, 15, 12, 0, 0, 0, ny), nil).Reason; got != "Closed for a market holiday" {
+		t.Errorf("holiday reason = %q", got)
+	}
+}
+
+func TestMarketStatusFromCalendar(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("America/New_York time zone not available")
+	}
+
+	// Friday 2024-11-29 closes early; the weekend follows
+	days := []TradingDay{
+		{Open: time.Date(2024, 11, 29, 9, 30, 0, 0, ny), Close: time.Date(2024, 11, 29, 13, 0, 0, 0, ny)},
+		{Open: time.Date(2024, 12, 2, 9, 30, 0, 0, ny), Close: time.Date(2024, 12, 2, 16, 0, 0, 0, ny)},
+	}
+
+	status := MarketStatusFromCalendar(time.Date(2024, 11, 29, 12, 0, 0, 0, ny), days)
+	if status.Session != MarketSessionRegular || status.NextClose == nil || !status.NextClose.Equal(days[0].Close) || !status.NextOpen.Equal(days[1].Open) {
+		t.Errorf("status = %+v, want regular closing at 13:00 and opening Monday", status)
+	}
+
+	status = MarketStatusFromCalendar(time.Date(2024, 11, 30, 12, 0, 0, 0, ny), days)
+	if status.Session != MarketSessionClosed || status.Reason != "Closed for the weekend" || !status.NextOpen.Equal(days[1].Open) || !status.NextClose.Equal(days[1].Close) {
+		t.Errorf("status = %+v, want closed for the weekend until Monday", status)
+	}
+
+	if status := MarketStatusFromCalendar(time.Date(2024, 12, 3, 12, 0, 0, 0, ny), days); status.NextOpen != nil || status.NextClose != nil {
+		t.Errorf("status = %+v, want no next open or close past the calendar", status)
+	}
+}
+
+func TestOutsideSessionError(t *testing.T) {
+	err := error(&OutsideSessionError{Status: MarketStatus{Session: MarketSessionClosed, Reason: "Closed for a market holiday"}})
+	if !errors.Is(err, ErrOutsideRegularSession) {
+		t.Error("expected errors.Is to match ErrOutsideRegularSession")
+	}
+	var sessionErr *OutsideSessionError
+	if !errors.As(err, &sessionErr) || sessionErr.Status.Reason != "Closed for a market holiday" {
+		t.Errorf("errors.As() = %+v", sessionErr)
+	}
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidOptionSymbol is returned for a contract symbol that isn't in OCC format
+var ErrInvalidOptionSymbol = errors.New("invalid option symbol")
+
+// OptionType is whether a contract is a call or a put
+type OptionType string
+
+const (
+	OptionTypeCall OptionType = "call"
+	OptionTypePut  OptionType = "put"
+)
+
+// OptionContractSize is how many shares one equity option contract covers
+const OptionContractSize = 100
+
+// OptionContract is one contract of an option chain with its latest quote
+type OptionContract struct {
+	Symbol            string     `json:"symbol"` // OCC symbol, e.g. AAPL250117C00190000
+	Underlying        string     `json:"underlying"`
+	Type              OptionType `json:"type"`
+	Strike            float64    `json:"strike"`
+	Expiration        time.Time  `json:"expiration"` // Expiration date at midnight UTC
+	Bid               float64    `json:"bid"`
+	Ask               float64    `json:"ask"`
+	Last              float64    `json:"last,omitempty"`
+	ImpliedVolatility float64    `json:"implied_volatility,omitempty"`
+	Delta             *float64   `json:"delta,omitempty"` // Nil when the feed has no greeks for the contract
+}
+
+// Mid returns the midpoint of the bid and ask, or the bid when there is no ask
+func (c OptionContract) Mid() float64 {
+	if c.Ask <= 0 {
+		return c.Bid
+	}
+	return (c.Bid + c.Ask) / 2
+}
+
+// DaysToExpiry returns the calendar days from now until the contract expires, rounded up
+func (c OptionContract) DaysToExpiry(now time.Time) int {
+	return int(c.Expiration.Sub(now).Hours()/24 + 0.999)
+}
+
+// ParseOptionSymbol reads the underlying, expiration, type and strike of an OCC option
+// symbol: the root, a YYMMDD expiration, C or P and the strike in thousandths of a dollar
+// padded to eight digits. Quote fields are left empty.
+func ParseOptionSymbol(symbol string) (OptionContract, error) {
+	const suffixLen = 6 + 1 + 8
+	if len(symbol) <= suffixLen || len(symbol) > 6+suffixLen {
+		return OptionContract{}, fmt.Errorf("%w %q", ErrInvalidOptionSymbol, symbol)
+	}
+	root, suffix := symbol[:len(symbol)-suffixLen], symbol[len(symbol)-suffixLen:]
+
+	expiration, err := time.Parse("060102", suffix[:6])
+	if err != nil {
+		return OptionContract{}, fmt.Errorf("%w %q: bad expiration", ErrInvalidOptionSymbol, symbol)
+	}
+	var optionType OptionType
+	switch suffix[6] {
+	case 'C':
+		optionType = OptionTypeCall
+	case 'P':
+		optionType = OptionTypePut
+	default:
+		return OptionContract{}, fmt.Errorf("%w %q: type must be C or P", ErrInvalidOptionSymbol, symbol)
+	}
+	strike, err := strconv.ParseUint(suffix[7:], 10, 64)
+	if err != nil {
+		return OptionContract{}, fmt.Errorf("%w %q: bad strike", ErrInvalidOptionSymbol, symbol)
+	}
+
+	return OptionContract{
+		Symbol:     symbol,
+		Underlying: root,
+		Type:       optionType,
+		Strike:     float64(strike) / 1000,
+		Expiration: expiration,
+	}, nil
+}
+
+// OptionChainFilter narrows the contracts an option chain request returns. Zero fields
+// don't filter.
+type OptionChainFilter struct {
+	Type          OptionType
+	MinStrike     float64
+	MaxStrike     float64
+	ExpiresAfter  time.Time // Earliest expiration date, inclusive
+	ExpiresBefore time.Time // Latest expiration date, inclusive
+}
+
+// CoveredCallSuggestion is a call that could be written against shares already held,
+// priced at its bid so the premium is what a market sell order would collect
+type CoveredCallSuggestion struct {
+	Symbol          string          `json:"symbol"`
+	Shares          decimal.Decimal `json:"shares"`
+	Contracts       int             `json:"contracts"` // Whole contracts the shares cover
+	SharePrice      float64         `json:"share_price"`
+	AvgEntryPrice   float64         `json:"avg_entry_price"`
+	Contract        OptionContract  `json:"contract"`
+	DaysToExpiry    int             `json:"days_to_expiry"`
+	OTMPercent      float64         `json:"otm_percent"`       // How far the strike is above the share price
+	Premium         float64         `json:"premium"`           // Bid times shares covered
+	PremiumYield    float64         `json:"premium_yield"`     // Bid as a percent of the share price
+	AnnualizedYield float64         `json:"annualized_yield"`  // Premium yield scaled to a year
+	CalledAwayYield float64         `json:"called_away_yield"` // Premium plus the gain up to the strike, as a percent of the share price
+	BelowCostBasis  bool            `json:"below_cost_basis"`  // Strike plus premium is below the average entry price, so assignment would lock in a loss
+}
+
+// CoveredCallSkip records why a position got no suggestion
+type CoveredCallSkip struct {
+	Symbol string `json:"symbol"`
+	Reason string `json:"reason"`
+}
+
+// CoveredCallReport lists covered calls for the portfolio's long positions, highest
+// annualized yield first
+type CoveredCallReport struct {
+	Suggestions []CoveredCallSuggestion `json:"suggestions"`
+	Skipped     []CoveredCallSkip       `json:"skipped,omitempty"`
+	GeneratedAt time.Time               `json:"generated_at"`
+	Disclaimer  string                  `json:"disclaimer,omitempty"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseOptionSymbol(t *testing.T) {
+	c, err := ParseOptionSymbol("AAPL250117C00192500")
+	if err != nil {
+		t.Fatalf("ParseOptionSymbol error = %v", err)
+	}
+	want := OptionContract{Symbol: "AAPL250117C00192500", Underlying: "AAPL", Type: OptionTypeCall, Strike: 192.5, Expiration: time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)}
+	if c != want {
+		t.Errorf("ParseOptionSymbol = %+v, want %+v", c, want)
+	}
+
+	if c, err := ParseOptionSymbol("F250620P00012000"); err != nil || c.Type != OptionTypePut || c.Strike != 12 || c.Underlying != "F" {
+		t.Errorf("ParseOptionSymbol(F put) = %+v, %v", c, err)
+	}
+
+	for _, symbol := range []string{"", "250117C00192500", "AAPL251317C00192500", "AAPL250117X00192500", "AAPL250117C0019250A", "TOOLONGX250117C00192500"} {
+		if _, err := ParseOptionSymbol(symbol); !errors.Is(err, ErrInvalidOptionSymbol) {
+			t.Errorf("ParseOptionSymbol(%q) error = %v, want ErrInvalidOptionSymbol", symbol, err)
+		}
+	}
+}
+
+func TestOptionContract_DaysToExpiry(t *testing.T) {
+	c := OptionContract{Expiration: time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)}
+	if got := c.DaysToExpiry(time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)); got != 14 {
+		t.Errorf("DaysToExpiry = %d, want 14", got)
+	}
+	if got := c.DaysToExpiry(time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)); got != 15 {
+		t.Errorf("DaysToExpiry partway through a day = %d, want 15", got)
+	}
+}
+
+func TestOptionContract_Mid(t *testing.T) {
+	if got := (OptionContract{Bid: 1.0, Ask: 1.5}).Mid(); got != 1.25 {
+		t.Errorf("Mid = %v, want 1.25", got)
+	}
+	if got := (OptionContract{Bid: 0.8}).Mid(); got != 0.8 {
+		t.Errorf("Mid without an ask = %v, want the bid", got)
+	}
+}
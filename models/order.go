@@ -0,0 +1,187 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidOrder is returned when an order request is missing a field its type requires
+var ErrInvalidOrder = errors.New("invalid order")
+
+// Order types accepted by the broker in addition to market and limit
+const (
+	OrderTypeStop      = "stop"
+	OrderTypeStopLimit = "stop_limit"
+)
+
+// OrderRequest describes an order to submit to the broker
+type OrderRequest struct {
+	Symbol     string
+	Quantity   decimal.Decimal
+	Side       TradeSide
+	Type       string           // OrderTypeMarket, OrderTypeLimit, OrderTypeStop, or OrderTypeStopLimit; others are market
+	LimitPrice *decimal.Decimal // Required for limit and stop-limit orders
+	Bracket    *Bracket         // Stop-loss and take-profit legs; nil places a simple order
+}
+
+// Bracket is the pair of exit orders attached to an entry order: once the entry fills, a
+// stop order at StopLoss and a limit order at TakeProfit guard the position, and whichever
+// fills first cancels the other
+type Bracket struct {
+	StopLoss   decimal.Decimal `json:"stop_loss"`
+	TakeProfit decimal.Decimal `json:"take_profit"`
+
+	// Broker order IDs of the legs, known once the entry order has been placed
+	StopLossOrderID   string `json:"stop_loss_order_id,omitempty"`
+	TakeProfitOrderID string `json:"take_profit_order_id,omitempty"`
+}
+
+// Validate checks that the legs sit on either side of the entry price for an entry on side:
+// the stop below and the target above it for buys, the other way round for sells. A zero
+// price, as for market orders, only checks the legs against each other.
+func (b Bracket) Validate(side TradeSide, price decimal.Decimal) error {
+	if !b.StopLoss.IsPositive() || !b.TakeProfit.IsPositive() {
+		return fmt.Errorf("%w: bracket prices must be positive", ErrInvalidOrder)
+	}
+	below, above := b.StopLoss, b.TakeProfit
+	if side == TradeSideSell {
+		below, above = b.TakeProfit, b.StopLoss
+	}
+	if !below.LessThan(above) || (price.IsPositive() && (!below.LessThan(price) || !above.GreaterThan(price))) {
+		return fmt.Errorf("%w: stop loss %s and take profit %s do not bracket a %s at %s",
+			ErrInvalidOrder, b.StopLoss, b.TakeProfit, side, price)
+	}
+	return nil
+}
+
+// OrderType returns the request's order type, treating unknown types as market orders
+func (o OrderRequest) OrderType() string {
+	switch o.Type {
+	case OrderTypeLimit, OrderTypeStop, OrderTypeStopLimit:
+		return o.Type
+	}
+	return OrderTypeMarket
+}
+
+// Validate checks that the order can be submitted. Limit and stop-limit orders must carry a
+// positive limit price, and only market and limit orders can carry a bracket.
+func (o OrderRequest) Validate() error {
+	if o.Symbol == "" {
+		return fmt.Errorf("%w: symbol is required", ErrInvalidOrder)
+	}
+	if !o.Quantity.IsPositive() {
+		return fmt.Errorf("%w: quantity must be positive", ErrInvalidOrder)
+	}
+	if o.NeedsLimitPrice() && (o.LimitPrice == nil || !o.LimitPrice.IsPositive()) {
+		return fmt.Errorf("%w: %s order for %s has no limit price", ErrInvalidOrder, o.OrderType(), o.Symbol)
+	}
+	if o.Bracket != nil {
+		if t := o.OrderType(); t != OrderTypeMarket && t != OrderTypeLimit {
+			return fmt.Errorf("%w: %s order for %s cannot carry a bracket", ErrInvalidOrder, t, o.Symbol)
+		}
+		price := decimal.Zero
+		if o.NeedsLimitPrice() {
+			price = *o.LimitPrice
+		}
+		if err := o.Bracket.Validate(o.Side, price); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NeedsLimitPrice reports whether the order type is priced by LimitPrice
+func (o OrderRequest) NeedsLimitPrice() bool {
+	t := o.OrderType()
+	return t == OrderTypeLimit || t == OrderTypeStopLimit
+}
+
+// Broker order statuses that settle a trade; the others leave it open
+const (
+	BrokerOrderFilled          = "filled"
+	BrokerOrderPartiallyFilled = "partially_filled"
+	BrokerOrderCanceled        = "canceled"
+	BrokerOrderExpired         = "expired"
+	BrokerOrderRejected        = "rejected"
+)
+
+// BrokerOrder is the broker's current view of a submitted order
+type BrokerOrder struct {
+	ID             string           `json:"id"`
+	Symbol         string           `json:"symbol"`
+	Side           TradeSide        `json:"side"`
+	Type           string           `json:"type"`
+	Status         string           `json:"status"` // As reported by the broker, such as new, partially_filled or filled
+	Quantity       decimal.Decimal  `json:"quantity"`
+	FilledQuantity decimal.Decimal  `json:"filled_quantity"`
+	FilledAvgPrice *decimal.Decimal `json:"filled_avg_price,omitempty"`
+	SubmittedAt    time.Time        `json:"submitted_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	FilledAt       *time.Time       `json:"filled_at,omitempty"`
+	CanceledAt     *time.Time       `json:"canceled_at,omitempty"` // Also set when the order expired or was rejected
+	Legs           []BrokerOrder    `json:"legs,omitempty"`        // Stop-loss and take-profit legs of a bracket order
+}
+
+// BracketLegs returns the order IDs of a bracket order's stop-loss and take-profit legs,
+// empty when the order has none
+func (o *BrokerOrder) BracketLegs() (stopLoss, takeProfit string) {
+	for _, leg := range o.Legs {
+		switch leg.Type {
+		case OrderTypeStop, OrderTypeStopLimit:
+			stopLoss = leg.ID
+		case OrderTypeLimit:
+			takeProfit = leg.ID
+		}
+	}
+	return stopLoss, takeProfit
+}
+
+// TradeStatus maps the order's status onto the local trade lifecycle. An order canceled or
+// expired after filling in part leaves an executed trade for the shares that filled.
+func (o *BrokerOrder) TradeStatus() TradeStatus {
+	filled := o.FilledQuantity.IsPositive()
+	switch o.Status {
+	case BrokerOrderFilled:
+		return TradeStatusExecuted
+	case BrokerOrderCanceled, BrokerOrderExpired:
+		if filled {
+			return TradeStatusExecuted
+		}
+		return TradeStatusCancelled
+	case BrokerOrderRejected:
+		if filled {
+			return TradeStatusExecuted
+		}
+		return TradeStatusRejected
+	}
+	if filled {
+		return TradeStatusPartiallyFilled
+	}
+	return TradeStatusPending
+}
+
+// ApplyTo records the order's status and fills on its trade, reporting whether anything
+// changed. Commission and fees are left for the caller to price.
+func (o *BrokerOrder) ApplyTo(t *Trade) bool {
+	status := o.TradeStatus()
+	if status == t.Status && o.FilledQuantity.Equal(t.FilledQuantity) {
+		return false
+	}
+	t.Status = status
+	t.FilledQuantity = o.FilledQuantity
+	if o.FilledAvgPrice != nil && o.FilledQuantity.IsPositive() {
+		t.Price = o.FilledAvgPrice.Round(4)
+		t.TotalValue = t.FilledQuantity.Mul(t.Price)
+	}
+	if status == TradeStatusExecuted {
+		executedAt := o.UpdatedAt
+		if o.FilledAt != nil {
+			executedAt = *o.FilledAt
+		}
+		t.ExecutedAt = &executedAt
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestOrderRequest_Validate(t *testing.T) {
+	price := decimal.NewFromInt(100)
+	zero := decimal.Zero
+	longBracket := &Bracket{StopLoss: decimal.NewFromInt(95), TakeProfit: decimal.NewFromInt(110)}
+
+	tests := []struct {
+		name    string
+		req     OrderRequest
+		wantErr bool
+	}{
+		{"market", OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Type: OrderTypeMarket}, false},
+		{"limit with price", OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Type: OrderTypeLimit, LimitPrice: &price}, false},
+		{"limit without price", OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Type: OrderTypeLimit}, true},
+		{"limit with zero price", OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Type: OrderTypeLimit, LimitPrice: &zero}, true},
+		{"stop limit without price", OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Type: OrderTypeStopLimit}, true},
+		{"zero quantity", OrderRequest{Symbol: "AAPL", Type: OrderTypeMarket}, true},
+		{"missing symbol", OrderRequest{Quantity: decimal.NewFromInt(1)}, true},
+		{"market buy with bracket", OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Side: TradeSideBuy, Bracket: longBracket}, false},
+		{"limit buy with bracket", OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Side: TradeSideBuy, Type: OrderTypeLimit, LimitPrice: &price, Bracket: longBracket}, false},
+		{"sell with buy bracket", OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Side: TradeSideSell, Bracket: longBracket}, true},
+		{"stop with bracket", OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Side: TradeSideBuy, Type: OrderTypeStop, Bracket: longBracket}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidOrder) {
+				t.Errorf("Validate() error = %v, want ErrInvalidOrder", err)
+			}
+		})
+	}
+}
+
+func TestBracket_Validate(t *testing.T) {
+	short := Bracket{StopLoss: decimal.NewFromInt(105), TakeProfit: decimal.NewFromInt(90)}
+	if err := short.Validate(TradeSideSell, decimal.NewFromInt(100)); err != nil {
+		t.Errorf("short bracket around 100: %v", err)
+	}
+	if err := short.Validate(TradeSideSell, decimal.NewFromInt(106)); err == nil {
+		t.Error("expected error for a short entered above its stop")
+	}
+	if err := (Bracket{TakeProfit: decimal.NewFromInt(90)}).Validate(TradeSideSell, decimal.Zero); err == nil {
+		t.Error("expected error for a bracket without a stop loss")
+	}
+}
+
+func TestBrokerOrder_TradeStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		filled int64
+		want   TradeStatus
+	}{
+		{"new", 0, TradeStatusPending},
+		{"accepted", 0, TradeStatusPending},
+		{BrokerOrderPartiallyFilled, 4, TradeStatusPartiallyFilled},
+		{BrokerOrderFilled, 10, TradeStatusExecuted},
+		{BrokerOrderCanceled, 0, TradeStatusCancelled},
+		{BrokerOrderCanceled, 4, TradeStatusExecuted},
+		{BrokerOrderExpired, 0, TradeStatusCancelled},
+		{BrokerOrderRejected, 0, TradeStatusRejected},
+	}
+	for _, tt := range tests {
+		o := BrokerOrder{Status: tt.status, FilledQuantity: decimal.NewFromInt(tt.filled)}
+		if got := o.TradeStatus(); got != tt.want {
+			t.Errorf("%s with %d filled: TradeStatus() = %s, want %s", tt.status, tt.filled, got, tt.want)
+		}
+	}
+}
+
+func TestBrokerOrder_ApplyTo(t *testing.T) {
+	trade := NewTrade("AAPL", TradeSideBuy, decimal.NewFromInt(10), decimal.NewFromInt(100))
+	avg := decimal.NewFromFloat(99.5)
+	filledAt := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+
+	partial := BrokerOrder{Status: BrokerOrderPartiallyFilled, FilledQuantity: decimal.NewFromInt(4), FilledAvgPrice: &avg}
+	if !partial.ApplyTo(trade) {
+		t.Fatal("ApplyTo() = false for a new partial fill")
+	}
+	if trade.Status != TradeStatusPartiallyFilled || !trade.TotalValue.Equal(decimal.NewFromInt(398)) || trade.ExecutedAt != nil {
+		t.Errorf("after partial fill: status %s, total %s, executed %v", trade.Status, trade.TotalValue, trade.ExecutedAt)
+	}
+	if partial.ApplyTo(trade) {
+		t.Error("ApplyTo() = true for an unchanged order")
+	}
+
+	filled := BrokerOrder{Status: BrokerOrderFilled, FilledQuantity: decimal.NewFromInt(10), FilledAvgPrice: &avg, FilledAt: &filledAt}
+	filled.ApplyTo(trade)
+	if trade.Status != TradeStatusExecuted || !trade.FilledQuantity.Equal(trade.Quantity) || trade.ExecutedAt == nil || !trade.ExecutedAt.Equal(filledAt) {
+		t.Errorf("after fill: status %s, filled %s, executed %v", trade.Status, trade.FilledQuantity, trade.ExecutedAt)
+	}
+}
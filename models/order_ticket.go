@@ -0,0 +1,170 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Price sources for an order ticket's estimated fill
+const (
+	TicketPriceLimit = "limit" // The order's limit price
+	TicketPriceAsk   = "ask"   // The quote's ask, for market buys and covers
+	TicketPriceBid   = "bid"   // The quote's bid, for market sells and shorts
+	TicketPriceLast  = "last"  // The last trade, when the quote has no bid or ask
+	TicketPriceEntry = "entry" // The agent's entry price, when there is no quote
+)
+
+// OrderTicket is the estimated outcome of executing a recommendation, shown for
+// confirmation before the order is placed. Amounts are estimates from the latest quote
+// and account; the actual fill can differ, most of all for market orders outside
+// regular hours.
+type OrderTicket struct {
+	RecommendationID  uuid.UUID            `json:"recommendation_id"`
+	Version           int                  `json:"version"`
+	Symbol            string               `json:"symbol"`
+	Action            RecommendationAction `json:"action"`
+	Side              TradeSide            `json:"side"`
+	OrderType         string               `json:"order_type"`
+	Quantity          decimal.Decimal      `json:"quantity"`
+	EstimatedPrice    decimal.Decimal      `json:"estimated_price"`
+	PriceSource       string               `json:"price_source"` // One of the TicketPrice constants, empty if no price is known
+	Notional          decimal.Decimal      `json:"notional"`
+	Commission        decimal.Decimal      `json:"commission"`
+	Fees              decimal.Decimal      `json:"fees"`
+	NetCash           decimal.Decimal      `json:"net_cash"` // Cash moved after costs: negative for buys and covers
+	PortfolioValue    decimal.Decimal      `json:"portfolio_value"`
+	CurrentQuantity   decimal.Decimal      `json:"current_quantity"`   // Shares held before the order, negative when short
+	ResultingQuantity decimal.Decimal      `json:"resulting_quantity"` // Shares held after the order, negative when short
+	CurrentWeight     float64              `json:"current_weight"`     // Position value as a percent of the portfolio
+	ResultingWeight   float64              `json:"resulting_weight"`
+	BuyingPower       decimal.Decimal      `json:"buying_power"`
+	BuyingPowerChange decimal.Decimal      `json:"buying_power_change"` // Negative when the order consumes buying power
+	BuyingPowerAfter  decimal.Decimal      `json:"buying_power_after"`
+	InitialMargin     decimal.Decimal      `json:"initial_margin"`     // Broker-reported, before the order
+	MaintenanceMargin decimal.Decimal      `json:"maintenance_margin"` // Broker-reported, before the order
+	Session           MarketSession        `json:"session,omitempty"`
+	QuotedAt          *time.Time           `json:"quoted_at,omitempty"`
+	Warnings          []string             `json:"warnings,omitempty"`
+	Blocker           string               `json:"blocker,omitempty"` // Why execution would be refused, empty if it would go ahead
+}
+
+// NewOrderTicket estimates the order rec would place. quote, account and position may be
+// nil when unavailable; position is the broker's position in the symbol. Short orders
+// hold shortMarginRequirement of their value as buying power (0 holds the value itself).
+func NewOrderTicket(rec *Recommendation, quote *Quote, account *Account, position *Position, fees FeeSchedule, shortMarginRequirement float64) *OrderTicket {
+	t := &OrderTicket{
+		RecommendationID: rec.ID,
+		Version:          rec.Version,
+		Symbol:           rec.Symbol,
+		Action:           rec.Action,
+		Side:             rec.Action.TradeSide(),
+		OrderType:        rec.EffectiveOrderType(),
+		Quantity:         rec.EffectiveQuantity(),
+	}
+	t.EstimatedPrice, t.PriceSource = ticketPrice(rec, quote)
+	if quote != nil {
+		t.Session = quote.Session
+		if !quote.Timestamp.IsZero() {
+			quotedAt := quote.Timestamp
+			t.QuotedAt = &quotedAt
+		}
+	}
+
+	trade := NewTrade(rec.Symbol, t.Side, t.Quantity, t.EstimatedPrice)
+	fees.Apply(trade)
+	t.Notional = trade.TotalValue
+	t.Commission = trade.Commission
+	t.Fees = trade.Fees
+	t.NetCash = trade.CashFlow()
+
+	if position != nil {
+		t.CurrentQuantity = position.Quantity
+		if position.EffectiveSide() == PositionSideShort {
+			t.CurrentQuantity = position.Quantity.Neg()
+		}
+	}
+	if t.Side == TradeSideBuy {
+		t.ResultingQuantity = t.CurrentQuantity.Add(t.Quantity)
+	} else {
+		t.ResultingQuantity = t.CurrentQuantity.Sub(t.Quantity)
+	}
+
+	t.BuyingPowerChange = t.NetCash
+	if rec.Action == RecommendationActionShort || rec.Action == RecommendationActionCover {
+		requirement := decimal.NewFromInt(1)
+		if shortMarginRequirement > 0 {
+			requirement = decimal.NewFromFloat(shortMarginRequirement)
+		}
+		margin := t.Notional.Mul(requirement).Round(2)
+		if rec.Action == RecommendationActionShort {
+			// Short proceeds stay with the broker as collateral
+			t.BuyingPowerChange = margin.Neg()
+		} else {
+			// Covering pays for the shares and releases the margin held for them
+			t.BuyingPowerChange = t.NetCash.Add(margin)
+		}
+	}
+
+	if account != nil {
+		t.PortfolioValue = account.PortfolioValue
+		if !t.PortfolioValue.IsPositive() {
+			t.PortfolioValue = account.Equity
+		}
+		t.BuyingPower = account.BuyingPower
+		t.BuyingPowerAfter = account.BuyingPower.Add(t.BuyingPowerChange)
+		t.InitialMargin = account.InitialMargin
+		t.MaintenanceMargin = account.MaintenanceMargin
+		t.CurrentWeight = positionWeight(t.CurrentQuantity, t.EstimatedPrice, t.PortfolioValue)
+		t.ResultingWeight = positionWeight(t.ResultingQuantity, t.EstimatedPrice, t.PortfolioValue)
+		if t.BuyingPowerAfter.IsNegative() {
+			t.Warnings = append(t.Warnings, "The order needs more buying power than the account has")
+		}
+	}
+
+	if t.PriceSource == "" {
+		t.Warnings = append(t.Warnings, "No price is available, so costs can't be estimated")
+	}
+	if t.OrderType == OrderTypeMarket && t.Session != "" && t.Session != MarketSessionRegular {
+		t.Warnings = append(t.Warnings, "Market orders placed outside regular hours fill at the next open")
+	}
+	return t
+}
+
+// HasAccount reports whether the ticket includes the broker account's figures
+func (t *OrderTicket) HasAccount() bool {
+	return t.PortfolioValue.IsPositive() || t.BuyingPower.IsPositive()
+}
+
+// ticketPrice picks the price the order is expected to fill at: the limit price, else
+// the side of the quote a market order crosses, else the last trade or entry price
+func ticketPrice(rec *Recommendation, quote *Quote) (decimal.Decimal, string) {
+	if limit := rec.EffectiveLimitPrice(); limit != nil {
+		return *limit, TicketPriceLimit
+	}
+	if quote != nil {
+		if rec.Action.TradeSide() == TradeSideBuy && quote.Ask.IsPositive() {
+			return quote.Ask, TicketPriceAsk
+		}
+		if rec.Action.TradeSide() == TradeSideSell && quote.Bid.IsPositive() {
+			return quote.Bid, TicketPriceBid
+		}
+		if quote.Last.IsPositive() {
+			return quote.Last, TicketPriceLast
+		}
+	}
+	if rec.EntryPrice.IsPositive() {
+		return rec.EntryPrice, TicketPriceEntry
+	}
+	return decimal.Zero, ""
+}
+
+// positionWeight returns the value of quantity shares at price as a percent of
+// portfolioValue, or 0 when the portfolio value is unknown
+func positionWeight(quantity, price, portfolioValue decimal.Decimal) float64 {
+	if !portfolioValue.IsPositive() {
+		return 0
+	}
+	return quantity.Abs().Mul(price).Div(portfolioValue).Mul(decimal.NewFromInt(100)).InexactFloat64()
+}
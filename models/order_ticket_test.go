@@ -0,0 +1,80 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestNewOrderTicket_Buy(t *testing.T) {
+	rec := NewRecommendation("AAPL", RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+	quote := &Quote{Bid: decimal.NewFromInt(99), Ask: decimal.NewFromInt(100), Last: decimal.NewFromInt(98), Session: MarketSessionRegular, Timestamp: time.Now()}
+	account := &Account{PortfolioValue: decimal.NewFromInt(10000), BuyingPower: decimal.NewFromInt(5000)}
+	position := &Position{Symbol: "AAPL", Quantity: decimal.NewFromInt(10), Side: PositionSideLong}
+	fees := NewFeeSchedule(1, 0, 0)
+
+	ticket := NewOrderTicket(rec, quote, account, position, fees, 1.5)
+
+	if ticket.PriceSource != TicketPriceAsk || !ticket.EstimatedPrice.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("estimated price = %s from %q, want 100 from the ask", ticket.EstimatedPrice, ticket.PriceSource)
+	}
+	if !ticket.Notional.Equal(decimal.NewFromInt(1000)) || !ticket.Commission.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("notional = %s, commission = %s; want 1000 and 1", ticket.Notional, ticket.Commission)
+	}
+	if !ticket.NetCash.Equal(decimal.NewFromInt(-1001)) || !ticket.BuyingPowerAfter.Equal(decimal.NewFromInt(3999)) {
+		t.Errorf("net cash = %s, buying power after = %s; want -1001 and 3999", ticket.NetCash, ticket.BuyingPowerAfter)
+	}
+	if !ticket.ResultingQuantity.Equal(decimal.NewFromInt(20)) {
+		t.Errorf("resulting quantity = %s, want 20", ticket.ResultingQuantity)
+	}
+	if ticket.CurrentWeight != 10 || ticket.ResultingWeight != 20 {
+		t.Errorf("weights = %.1f%% -> %.1f%%, want 10%% -> 20%%", ticket.CurrentWeight, ticket.ResultingWeight)
+	}
+	if len(ticket.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", ticket.Warnings)
+	}
+}
+
+func TestNewOrderTicket_Short(t *testing.T) {
+	rec := NewRecommendation("TSLA", RecommendationActionShort, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+	quote := &Quote{Bid: decimal.NewFromInt(200), Ask: decimal.NewFromInt(201), Session: MarketSessionAfter}
+	account := &Account{PortfolioValue: decimal.NewFromInt(10000), BuyingPower: decimal.NewFromInt(2000)}
+
+	ticket := NewOrderTicket(rec, quote, account, nil, FeeSchedule{}, 1.5)
+
+	if ticket.PriceSource != TicketPriceBid {
+		t.Errorf("price source = %q, want the bid for a short", ticket.PriceSource)
+	}
+	if !ticket.BuyingPowerChange.Equal(decimal.NewFromInt(-3000)) {
+		t.Errorf("buying power change = %s, want -3000 held as short margin", ticket.BuyingPowerChange)
+	}
+	if !ticket.ResultingQuantity.Equal(decimal.NewFromInt(-10)) || ticket.ResultingWeight != 20 {
+		t.Errorf("resulting position = %s at %.1f%%, want -10 at 20%%", ticket.ResultingQuantity, ticket.ResultingWeight)
+	}
+	if len(ticket.Warnings) != 2 {
+		t.Errorf("warnings = %v, want buying power and extended-hours warnings", ticket.Warnings)
+	}
+}
+
+func TestNewOrderTicket_LimitWithoutBrokerData(t *testing.T) {
+	rec := NewRecommendation("MSFT", RecommendationActionSell, "test")
+	rec.Quantity = decimal.NewFromInt(5)
+	if err := rec.Edit(RecommendationOverride{OrderType: OrderTypeLimit, LimitPrice: decimalPtr(300)}); err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+
+	ticket := NewOrderTicket(rec, nil, nil, nil, NewFeeSchedule(0, 0, 0.001), 0)
+
+	if ticket.PriceSource != TicketPriceLimit || !ticket.Notional.Equal(decimal.NewFromInt(1500)) {
+		t.Errorf("notional = %s from %q, want 1500 at the limit price", ticket.Notional, ticket.PriceSource)
+	}
+	if !ticket.Fees.Equal(decimal.NewFromFloat(1.5)) || !ticket.NetCash.Equal(decimal.NewFromFloat(1498.5)) {
+		t.Errorf("fees = %s, net cash = %s; want 1.5 and 1498.5", ticket.Fees, ticket.NetCash)
+	}
+	if ticket.HasAccount() || ticket.ResultingWeight != 0 {
+		t.Error("expected no account figures without broker data")
+	}
+}
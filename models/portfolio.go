@@ -0,0 +1,26 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// PortfolioSummary aggregates open positions with the fees paid on executed trades
+type PortfolioSummary struct {
+	Positions    []Position      `json:"positions"`
+	Count        int             `json:"count"`
+	TotalValue   decimal.Decimal `json:"total_value"`
+	UnrealizedPL decimal.Decimal `json:"unrealized_pl"` // Net of buy-side fees, which are part of the cost basis
+	FeesPaid     decimal.Decimal `json:"fees_paid"`     // Cumulative commission and fees across executed trades
+}
+
+// NewPortfolioSummary totals the given positions
+func NewPortfolioSummary(positions []Position, feesPaid decimal.Decimal) *PortfolioSummary {
+	summary := &PortfolioSummary{
+		Positions: positions,
+		Count:     len(positions),
+		FeesPaid:  feesPaid,
+	}
+	for _, pos := range positions {
+		summary.TotalValue = summary.TotalValue.Add(pos.CurrentPrice.Mul(pos.Quantity))
+		summary.UnrealizedPL = summary.UnrealizedPL.Add(pos.UnrealizedPL)
+	}
+	return summary
+}
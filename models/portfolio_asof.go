@@ -0,0 +1,153 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidAsOfDate is returned for a malformed or future as-of date
+var ErrInvalidAsOfDate = errors.New("invalid as-of date")
+
+// HistoricalPosition is a holding reconstructed from executed trades
+type HistoricalPosition struct {
+	Symbol        string          `json:"symbol"`
+	Side          PositionSide    `json:"side"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	AvgEntryPrice decimal.Decimal `json:"avg_entry_price"`      // Cost per share including fees, or for shorts proceeds per share net of fees
+	CostBasis     decimal.Decimal `json:"cost_basis"`           // For shorts, the proceeds of the shares still short
+	ClosePrice    decimal.Decimal `json:"close_price"`          // Last close on or before the date; zero when unknown
+	PriceDate     *time.Time      `json:"price_date,omitempty"` // Day of the close used
+	MarketValue   decimal.Decimal `json:"market_value"`         // Negative for short positions
+	UnrealizedPL  decimal.Decimal `json:"unrealized_pl"`
+}
+
+// PortfolioAsOf is the portfolio reconstructed at the close of a past day
+type PortfolioAsOf struct {
+	Date           time.Time            `json:"date"`
+	Positions      []HistoricalPosition `json:"positions"`
+	PositionsValue decimal.Decimal      `json:"positions_value"`
+	Cash           *decimal.Decimal     `json:"cash,omitempty"`        // Nil when the broker account is unavailable
+	TotalValue     *decimal.Decimal     `json:"total_value,omitempty"` // Positions plus cash, when cash is known
+	RealizedPL     decimal.Decimal      `json:"realized_pl"`           // Net of fees, from sells and covers up to the date
+	FeesPaid       decimal.Decimal      `json:"fees_paid"`
+	TradeCount     int                  `json:"trade_count"` // Executed trades up to the date
+	Warnings       []string             `json:"warnings"`    // Data that could not be reconstructed
+}
+
+// ParseAsOfDate parses a YYYY-MM-DD date as that day in the exchange time zone.
+// Today and future dates are rejected since their close is not yet known.
+func ParseAsOfDate(s string, now time.Time) (time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", s, marketLocation)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: expected YYYY-MM-DD, got %q", ErrInvalidAsOfDate, s)
+	}
+	if !date.AddDate(0, 0, 1).Before(now) {
+		return time.Time{}, fmt.Errorf("%w: %s has not closed yet", ErrInvalidAsOfDate, s)
+	}
+	return date, nil
+}
+
+// Cutoff returns the end of the as-of day; trades executed before it are included
+func (p *PortfolioAsOf) Cutoff() time.Time {
+	return p.Date.AddDate(0, 0, 1)
+}
+
+// ReplayTrades rebuilds the holdings at the end of date from executed trades, oldest
+// first, using the same average-cost accounting as live position updates. A sell while no
+// long position is held opens or adds to a short, and a buy while short covers it. Shares
+// sold or covered beyond the position are ignored, as they are for live positions.
+func ReplayTrades(date time.Time, trades []Trade) *PortfolioAsOf {
+	p := &PortfolioAsOf{Date: date, Positions: []HistoricalPosition{}, Warnings: []string{}}
+	holdings := make(map[string]*HistoricalPosition)
+
+	for _, t := range trades {
+		if t.ExecutedAt == nil || !t.ExecutedAt.Before(p.Cutoff()) {
+			continue
+		}
+		p.TradeCount++
+		p.FeesPaid = p.FeesPaid.Add(t.TotalFees())
+
+		pos, ok := holdings[t.Symbol]
+		if !ok {
+			side := PositionSideLong
+			if t.Side == TradeSideSell {
+				side = PositionSideShort
+			}
+			pos = &HistoricalPosition{Symbol: t.Symbol, Side: side}
+			holdings[t.Symbol] = pos
+		}
+
+		short := pos.Side == PositionSideShort
+		if short == (t.Side == TradeSideSell) {
+			// Opens or adds to the position: a long's basis is what was paid, a short's what was received
+			pos.Quantity = pos.Quantity.Add(t.Quantity)
+			if short {
+				pos.CostBasis = pos.CostBasis.Add(t.CashFlow())
+			} else {
+				pos.CostBasis = pos.CostBasis.Sub(t.CashFlow())
+			}
+			pos.AvgEntryPrice = pos.CostBasis.Div(pos.Quantity).Round(8)
+			continue
+		}
+
+		closed := decimal.Min(t.Quantity, pos.Quantity)
+		if short {
+			// Covering pays the cash flow back against the proceeds received
+			p.RealizedPL = p.RealizedPL.Add(pos.AvgEntryPrice.Mul(closed)).Add(t.CashFlow())
+		} else {
+			p.RealizedPL = p.RealizedPL.Add(t.CashFlow()).Sub(pos.AvgEntryPrice.Mul(closed))
+		}
+		pos.Quantity = pos.Quantity.Sub(closed)
+		pos.CostBasis = pos.AvgEntryPrice.Mul(pos.Quantity)
+		if !pos.Quantity.IsPositive() {
+			delete(holdings, t.Symbol)
+		}
+	}
+
+	for _, pos := range holdings {
+		p.Positions = append(p.Positions, *pos)
+	}
+	sort.Slice(p.Positions, func(i, j int) bool { return p.Positions[i].Symbol < p.Positions[j].Symbol })
+	return p
+}
+
+// SetClose values position i at a closing price from day. A short is a liability worth the
+// cost of buying its shares back, gaining as the price falls below its proceeds.
+func (p *PortfolioAsOf) SetClose(i int, price decimal.Decimal, day time.Time) {
+	pos := &p.Positions[i]
+	pos.ClosePrice = price
+	pos.PriceDate = &day
+	pos.MarketValue = price.Mul(pos.Quantity)
+	if pos.Side == PositionSideShort {
+		pos.MarketValue = pos.MarketValue.Neg()
+		pos.UnrealizedPL = pos.CostBasis.Add(pos.MarketValue)
+		return
+	}
+	pos.UnrealizedPL = pos.MarketValue.Sub(pos.CostBasis)
+}
+
+// SetCash derives cash at the as-of date by reversing the cash moved by trades executed
+// since then. Deposits and withdrawals are not recorded, so they are not reversed.
+func (p *PortfolioAsOf) SetCash(current decimal.Decimal, laterTrades []Trade) {
+	cash := current
+	for _, t := range laterTrades {
+		cash = cash.Sub(t.CashFlow())
+	}
+	p.Cash = &cash
+}
+
+// Total sums position values and cash once prices and cash have been set
+func (p *PortfolioAsOf) Total() {
+	p.PositionsValue = decimal.Zero
+	for _, pos := range p.Positions {
+		p.PositionsValue = p.PositionsValue.Add(pos.MarketValue)
+	}
+	if p.Cash != nil {
+		total := p.PositionsValue.Add(*p.Cash)
+		p.TotalValue = &total
+	}
+}
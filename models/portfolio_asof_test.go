@@ -0,0 +1,105 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func executedTrade(symbol string, side TradeSide, qty, price, fees float64, at time.Time) Trade {
+	t := NewTrade(symbol, side, decimal.NewFromFloat(qty), decimal.NewFromFloat(price))
+	t.Fees = decimal.NewFromFloat(fees)
+	t.Status = TradeStatusExecuted
+	t.ExecutedAt = &at
+	return *t
+}
+
+func TestReplayTrades(t *testing.T) {
+	date := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	trades := []Trade{
+		executedTrade("AAPL", TradeSideBuy, 10, 100, 1, date.AddDate(0, -1, 0)),
+		executedTrade("AAPL", TradeSideBuy, 10, 120, 1, date.AddDate(0, 0, -10)),
+		executedTrade("AAPL", TradeSideSell, 5, 130, 0.5, date.Add(15*time.Hour)),
+		executedTrade("MSFT", TradeSideBuy, 2, 400, 0, date.AddDate(0, 0, -5)),
+		executedTrade("MSFT", TradeSideSell, 2, 410, 0, date.AddDate(0, 0, -1)),
+		executedTrade("NVDA", TradeSideBuy, 1, 100, 0, date.AddDate(0, 0, 1)), // After the date
+	}
+
+	p := ReplayTrades(date, trades)
+	if p.TradeCount != 5 {
+		t.Errorf("TradeCount = %d, want 5", p.TradeCount)
+	}
+	if len(p.Positions) != 1 || p.Positions[0].Symbol != "AAPL" {
+		t.Fatalf("Positions = %+v, want only AAPL", p.Positions)
+	}
+	aapl := p.Positions[0]
+	if !aapl.Quantity.Equal(decimal.NewFromInt(15)) || !aapl.AvgEntryPrice.Equal(decimal.NewFromFloat(110.1)) {
+		t.Errorf("AAPL = %s @ %s, want 15 @ 110.1", aapl.Quantity, aapl.AvgEntryPrice)
+	}
+	// AAPL: 5 * 130 - 0.5 - 5 * 110.1 = 99; MSFT: 820 - 800 = 20
+	if !p.RealizedPL.Equal(decimal.NewFromInt(119)) {
+		t.Errorf("RealizedPL = %s, want 119", p.RealizedPL)
+	}
+	if !p.FeesPaid.Equal(decimal.NewFromFloat(2.5)) {
+		t.Errorf("FeesPaid = %s, want 2.5", p.FeesPaid)
+	}
+
+	p.SetClose(0, decimal.NewFromInt(125), date)
+	p.SetCash(decimal.NewFromInt(1000), trades[5:])
+	p.Total()
+	if !p.PositionsValue.Equal(decimal.NewFromInt(1875)) || !p.Positions[0].UnrealizedPL.Equal(decimal.NewFromFloat(223.5)) {
+		t.Errorf("PositionsValue = %s, UnrealizedPL = %s, want 1875 and 223.5", p.PositionsValue, p.Positions[0].UnrealizedPL)
+	}
+	// The NVDA buy after the date spent 100, so cash was 1100 at the date
+	if p.Cash == nil || !p.Cash.Equal(decimal.NewFromInt(1100)) || !p.TotalValue.Equal(decimal.NewFromInt(2975)) {
+		t.Errorf("Cash = %v, TotalValue = %v, want 1100 and 2975", p.Cash, p.TotalValue)
+	}
+}
+
+func TestReplayTrades_Shorts(t *testing.T) {
+	date := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	trades := []Trade{
+		executedTrade("TSLA", TradeSideSell, 10, 200, 1, date.AddDate(0, 0, -20)),
+		executedTrade("TSLA", TradeSideSell, 10, 220, 1, date.AddDate(0, 0, -10)),
+		executedTrade("TSLA", TradeSideBuy, 5, 180, 0.5, date.AddDate(0, 0, -5)),
+		executedTrade("GME", TradeSideSell, 4, 25, 0, date.AddDate(0, 0, -4)),
+		executedTrade("GME", TradeSideBuy, 6, 20, 0, date.AddDate(0, 0, -3)), // Covers all 4; the rest is ignored
+	}
+
+	p := ReplayTrades(date, trades)
+	if len(p.Positions) != 1 {
+		t.Fatalf("Positions = %+v, want only TSLA", p.Positions)
+	}
+	tsla := p.Positions[0]
+	// Proceeds of 1999 + 2199 over 20 shares, with 5 covered
+	if tsla.Side != PositionSideShort || !tsla.Quantity.Equal(decimal.NewFromInt(15)) || !tsla.AvgEntryPrice.Equal(decimal.NewFromFloat(209.9)) {
+		t.Errorf("TSLA = %s %s @ %s, want 15 short @ 209.9", tsla.Side, tsla.Quantity, tsla.AvgEntryPrice)
+	}
+	// TSLA: 5 * 209.9 - 900.5 = 149; GME: 4 * 25 - 120 = -20
+	if !p.RealizedPL.Equal(decimal.NewFromInt(129)) {
+		t.Errorf("RealizedPL = %s, want 129", p.RealizedPL)
+	}
+
+	p.SetClose(0, decimal.NewFromInt(190), date)
+	p.Total()
+	if !p.Positions[0].MarketValue.Equal(decimal.NewFromInt(-2850)) || !p.Positions[0].UnrealizedPL.Equal(decimal.NewFromFloat(298.5)) {
+		t.Errorf("MarketValue = %s, UnrealizedPL = %s, want -2850 and 298.5", p.Positions[0].MarketValue, p.Positions[0].UnrealizedPL)
+	}
+	if !p.PositionsValue.Equal(decimal.NewFromInt(-2850)) {
+		t.Errorf("PositionsValue = %s, want the short as a liability", p.PositionsValue)
+	}
+}
+
+func TestParseAsOfDate(t *testing.T) {
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := ParseAsOfDate("2024-06-30", now); err != nil {
+		t.Errorf("ParseAsOfDate(2024-06-30) error = %v", err)
+	}
+	for _, s := range []string{"", "06/30/2024", "2024-07-01", "2025-01-01"} {
+		if _, err := ParseAsOfDate(s, now); !errors.Is(err, ErrInvalidAsOfDate) {
+			t.Errorf("ParseAsOfDate(%q) error = %v, want ErrInvalidAsOfDate", s, err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// PortfolioSuggestion is what a portfolio review suggests doing with a holding
+type PortfolioSuggestion string
+
+const (
+	PortfolioSuggestionAdd  PortfolioSuggestion = "add"
+	PortfolioSuggestionTrim PortfolioSuggestion = "trim"
+	PortfolioSuggestionHold PortfolioSuggestion = "hold"
+)
+
+// SuggestionFor maps a recommendation on a held symbol to a suggestion for the position.
+// For short positions a buy means trimming the short and a sell means adding to it.
+func SuggestionFor(side PositionSide, action RecommendationAction) PortfolioSuggestion {
+	switch action {
+	case RecommendationActionShort:
+		return PortfolioSuggestionAdd
+	case RecommendationActionCover:
+		return PortfolioSuggestionTrim
+	case RecommendationActionBuy:
+		if side == PositionSideShort {
+			return PortfolioSuggestionTrim
+		}
+		return PortfolioSuggestionAdd
+	case RecommendationActionSell:
+		if side == PositionSideShort {
+			return PortfolioSuggestionAdd
+		}
+		return PortfolioSuggestionTrim
+	default:
+		return PortfolioSuggestionHold
+	}
+}
+
+// PortfolioReviewItem is the analysis outcome for one open position
+type PortfolioReviewItem struct {
+	Symbol           string              `json:"symbol"`
+	Side             PositionSide        `json:"side"`
+	Quantity         decimal.Decimal     `json:"quantity"`
+	MarketValue      decimal.Decimal     `json:"market_value"`
+	Weight           float64             `json:"weight"`               // % of the reviewed market value
+	Suggestion       PortfolioSuggestion `json:"suggestion,omitempty"` // Empty when analysis failed
+	RecommendationID *uuid.UUID          `json:"recommendation_id,omitempty"`
+	Confidence       float64             `json:"confidence,omitempty"`
+	Reasoning        string              `json:"reasoning,omitempty"`
+	Error            string              `json:"error,omitempty"`
+}
+
+// PortfolioReview aggregates analyses of every open position into suggested trims, adds and holds
+type PortfolioReview struct {
+	ID         uuid.UUID             `json:"id"`
+	Items      []PortfolioReviewItem `json:"items"`
+	Skipped    []string              `json:"skipped,omitempty"` // Positions beyond the review budget
+	DurationMs int64                 `json:"duration_ms"`
+	CreatedAt  time.Time             `json:"created_at"`
+	Running    bool                  `json:"running,omitempty"`    // Set while positions are still being analyzed; never stored
+	Disclaimer string                `json:"disclaimer,omitempty"` // Compliance text attached when served; not stored
+}
+
+// NewPortfolioReview creates an empty PortfolioReview
+func NewPortfolioReview() *PortfolioReview {
+	return &PortfolioReview{
+		ID:        uuid.New(),
+		Items:     []PortfolioReviewItem{},
+		CreatedAt: time.Now(),
+	}
+}
+
+// AddResult records the analysis of a position. A nil recommendation with an error
+// records the position as unreviewed.
+func (r *PortfolioReview) AddResult(pos Position, rec *Recommendation, err error) {
+	item := PortfolioReviewItem{
+		Symbol:      pos.Symbol,
+		Side:        pos.Side,
+		Quantity:    pos.Quantity,
+		MarketValue: pos.CurrentPrice.Mul(pos.Quantity),
+	}
+	switch {
+	case err != nil:
+		item.Error = err.Error()
+	case rec == nil:
+		item.Error = "analysis returned no recommendation"
+	default:
+		recID := rec.ID
+		item.Suggestion = SuggestionFor(pos.Side, rec.Action)
+		item.RecommendationID = &recID
+		item.Confidence = rec.Confidence
+		item.Reasoning = rec.Reasoning
+	}
+	r.Items = append(r.Items, item)
+}
+
+// Finish computes each item's portfolio weight, orders items by market value and records the duration
+func (r *PortfolioReview) Finish(durationMs int64) {
+	total := decimal.Zero
+	for _, item := range r.Items {
+		total = total.Add(item.MarketValue.Abs())
+	}
+	for i := range r.Items {
+		if total.IsPositive() {
+			r.Items[i].Weight = r.Items[i].MarketValue.Abs().Div(total).Mul(decimal.NewFromInt(100)).InexactFloat64()
+		}
+	}
+	sort.SliceStable(r.Items, func(i, j int) bool {
+		return r.Items[i].MarketValue.Abs().GreaterThan(r.Items[j].MarketValue.Abs())
+	})
+	r.DurationMs = durationMs
+}
+
+// WithSuggestion returns the items with the given suggestion
+func (r *PortfolioReview) WithSuggestion(s PortfolioSuggestion) []PortfolioReviewItem {
+	var items []PortfolioReviewItem
+	for _, item := range r.Items {
+		if item.Suggestion == s {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Failed returns the items whose analysis did not complete
+func (r *PortfolioReview) Failed() []PortfolioReviewItem {
+	var items []PortfolioReviewItem
+	for _, item := range r.Items {
+		if item.Error != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSuggestionFor(t *testing.T) {
+	tests := []struct {
+		side   PositionSide
+		action RecommendationAction
+		want   PortfolioSuggestion
+	}{
+		{PositionSideLong, RecommendationActionBuy, PortfolioSuggestionAdd},
+		{PositionSideLong, RecommendationActionSell, PortfolioSuggestionTrim},
+		{PositionSideLong, RecommendationActionHold, PortfolioSuggestionHold},
+		{PositionSideShort, RecommendationActionBuy, PortfolioSuggestionTrim},
+		{PositionSideShort, RecommendationActionSell, PortfolioSuggestionAdd},
+		{PositionSideShort, RecommendationActionShort, PortfolioSuggestionAdd},
+		{PositionSideShort, RecommendationActionCover, PortfolioSuggestionTrim},
+	}
+	for _, tt := range tests {
+		if got := SuggestionFor(tt.side, tt.action); got != tt.want {
+			t.Errorf("SuggestionFor(%s, %s) = %s, want %s", tt.side, tt.action, got, tt.want)
+		}
+	}
+}
+
+func TestPortfolioReview(t *testing.T) {
+	position := func(symbol string, qty, price int64) Position {
+		return Position{Symbol: symbol, Side: PositionSideLong, Quantity: decimal.NewFromInt(qty), CurrentPrice: decimal.NewFromInt(price)}
+	}
+
+	review := NewPortfolioReview()
+	review.AddResult(position("SMALL", 10, 10), NewRecommendation("SMALL", RecommendationActionBuy, "cheap"), nil)
+	review.AddResult(position("BIG", 10, 70), NewRecommendation("BIG", RecommendationActionSell, "stretched"), nil)
+	review.AddResult(position("MID", 10, 20), nil, errors.New("timeout"))
+	review.Finish(1500)
+
+	if len(review.Items) != 3 || review.Items[0].Symbol != "BIG" {
+		t.Fatalf("items should be ordered by market value, got %+v", review.Items)
+	}
+	if review.Items[0].Weight != 70 {
+		t.Errorf("BIG weight = %v, want 70", review.Items[0].Weight)
+	}
+	if trims := review.WithSuggestion(PortfolioSuggestionTrim); len(trims) != 1 || trims[0].Symbol != "BIG" {
+		t.Errorf("trims = %+v, want BIG", trims)
+	}
+	if adds := review.WithSuggestion(PortfolioSuggestionAdd); len(adds) != 1 || adds[0].RecommendationID == nil {
+		t.Errorf("adds = %+v, want SMALL with its recommendation", adds)
+	}
+	if failed := review.Failed(); len(failed) != 1 || failed[0].Error != "timeout" {
+		t.Errorf("failed = %+v, want MID", failed)
+	}
+	if review.DurationMs != 1500 {
+		t.Errorf("DurationMs = %d, want 1500", review.DurationMs)
+	}
+}
@@ -0,0 +1,226 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidHistoryRange is returned for a portfolio history range other than 1m, 3m, 6m,
+// ytd, 1y or all
+var ErrInvalidHistoryRange = errors.New("invalid history range")
+
+// PositionSnapshot is one position's value and P&L when a portfolio snapshot was taken
+type PositionSnapshot struct {
+	Symbol        string          `json:"symbol"`
+	Side          PositionSide    `json:"side"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	AvgEntryPrice decimal.Decimal `json:"avg_entry_price"`
+	CurrentPrice  decimal.Decimal `json:"current_price"`
+	MarketValue   decimal.Decimal `json:"market_value"` // Negative for short positions
+	UnrealizedPL  decimal.Decimal `json:"unrealized_pl"`
+}
+
+// PortfolioSnapshot is the account's value after the close of one trading day
+type PortfolioSnapshot struct {
+	Date      time.Time          `json:"date"` // Market date, as midnight UTC
+	Equity    decimal.Decimal    `json:"equity"`
+	Cash      decimal.Decimal    `json:"cash"`
+	CashFlow  decimal.Decimal    `json:"cash_flow"` // Deposits less withdrawals since the previous snapshot
+	Positions []PositionSnapshot `json:"positions"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// NewPortfolioSnapshot records the account and its positions for the market date of at
+func NewPortfolioSnapshot(at time.Time, account *Account, positions []Position, cashFlow decimal.Decimal) *PortfolioSnapshot {
+	snapshot := &PortfolioSnapshot{
+		Date:      MarketDate(at),
+		Equity:    account.Equity,
+		Cash:      account.Cash,
+		CashFlow:  cashFlow,
+		Positions: make([]PositionSnapshot, len(positions)),
+		CreatedAt: at,
+	}
+	for i, p := range positions {
+		value := p.Quantity.Mul(p.CurrentPrice)
+		if p.Side == PositionSideShort {
+			value = value.Neg()
+		}
+		snapshot.Positions[i] = PositionSnapshot{
+			Symbol:        p.Symbol,
+			Side:          p.Side,
+			Quantity:      p.Quantity,
+			AvgEntryPrice: p.AvgEntryPrice,
+			CurrentPrice:  p.CurrentPrice,
+			MarketValue:   value,
+			UnrealizedPL:  p.UnrealizedPL,
+		}
+	}
+	return snapshot
+}
+
+// MarketDate returns the exchange's calendar date at t, as midnight UTC
+func MarketDate(t time.Time) time.Time {
+	et := t.In(MarketLocation())
+	return time.Date(et.Year(), et.Month(), et.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// HistoryRangeStart returns the first market date a portfolio history range covers: one,
+// three, six or twelve months back for 1m, 3m, 6m and 1y, January 1st for ytd, and the zero
+// time for all
+func HistoryRangeStart(rng string, now time.Time) (time.Time, error) {
+	today := MarketDate(now)
+	switch rng {
+	case "1m":
+		return today.AddDate(0, -1, 0), nil
+	case "3m":
+		return today.AddDate(0, -3, 0), nil
+	case "6m":
+		return today.AddDate(0, -6, 0), nil
+	case "ytd":
+		return time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, time.UTC), nil
+	case "1y":
+		return today.AddDate(-1, 0, 0), nil
+	case "all":
+		return time.Time{}, nil
+	default:
+		return time.Time{}, fmt.Errorf("%w: %q, expected 1m, 3m, 6m, ytd, 1y or all", ErrInvalidHistoryRange, rng)
+	}
+}
+
+// PortfolioHistoryPoint is the portfolio's value on one day of the equity curve
+type PortfolioHistoryPoint struct {
+	Date             time.Time       `json:"date"`
+	Equity           decimal.Decimal `json:"equity"`
+	Cash             decimal.Decimal `json:"cash"`
+	CashFlow         decimal.Decimal `json:"cash_flow"`
+	DailyReturn      float64         `json:"daily_return"`               // Since the previous point, net of the cash flow
+	CumulativeReturn float64         `json:"cumulative_return"`          // Time-weighted since the first point, 0.12 = 12%
+	Drawdown         float64         `json:"drawdown"`                   // Below the running peak of the cumulative return, 0.05 = 5%
+	BenchmarkReturn  *float64        `json:"benchmark_return,omitempty"` // Benchmark's price return since the first point
+}
+
+// PortfolioHistory is the equity curve over a range with its time-weighted return and
+// max drawdown
+type PortfolioHistory struct {
+	Range              string                  `json:"range"`
+	Points             []PortfolioHistoryPoint `json:"points"`
+	TimeWeightedReturn float64                 `json:"time_weighted_return"` // 0.12 = 12%
+	MaxDrawdown        float64                 `json:"max_drawdown"`         // Largest peak-to-trough decline, 0.30 = 30%
+	NetCashFlow        decimal.Decimal         `json:"net_cash_flow"`        // Deposits less withdrawals after the first point
+	Benchmark          *BenchmarkComparison    `json:"benchmark,omitempty"`
+}
+
+// BenchmarkComparison compares the portfolio's returns with a benchmark's over a history
+// range. Beta and alpha come from the daily returns of the days both have a value for.
+type BenchmarkComparison struct {
+	Symbol         string   `json:"symbol"`
+	Return         float64  `json:"return"`          // Price return over the range, 0.10 = 10%
+	RelativeReturn float64  `json:"relative_return"` // Time-weighted return less the benchmark's
+	Beta           *float64 `json:"beta"`            // nil when fewer than MinRiskObservations days pair up
+	Alpha          *float64 `json:"alpha"`           // Annualized return not explained by beta, ignoring the risk-free rate; nil with beta
+	Observations   int      `json:"observations"`    // Daily returns paired for beta and alpha
+}
+
+// NewPortfolioHistory builds the equity curve from snapshots in date order. Each day's
+// return is its equity less that day's cash flow over the previous day's equity, so
+// deposits and withdrawals don't count as gains or losses; returns are chained into the
+// time-weighted return. The first snapshot's cash flow predates the range and is ignored.
+func NewPortfolioHistory(rng string, snapshots []PortfolioSnapshot) *PortfolioHistory {
+	history := &PortfolioHistory{
+		Range:       rng,
+		Points:      make([]PortfolioHistoryPoint, len(snapshots)),
+		NetCashFlow: decimal.Zero,
+	}
+
+	growth, peak := 1.0, 1.0
+	for i, s := range snapshots {
+		var daily float64
+		if i > 0 {
+			history.NetCashFlow = history.NetCashFlow.Add(s.CashFlow)
+			if prev := snapshots[i-1].Equity; prev.IsPositive() {
+				daily = s.Equity.Sub(s.CashFlow).Div(prev).InexactFloat64() - 1
+				growth *= 1 + daily
+			}
+		}
+		peak = max(peak, growth)
+		point := PortfolioHistoryPoint{
+			Date:             s.Date,
+			Equity:           s.Equity,
+			Cash:             s.Cash,
+			CashFlow:         s.CashFlow,
+			DailyReturn:      daily,
+			CumulativeReturn: growth - 1,
+		}
+		if peak > 0 {
+			point.Drawdown = (peak - growth) / peak
+		}
+		history.Points[i] = point
+		history.MaxDrawdown = max(history.MaxDrawdown, point.Drawdown)
+	}
+	history.TimeWeightedReturn = growth - 1
+	return history
+}
+
+// AddBenchmark compares the equity curve with a benchmark's daily closes in date order.
+// Each point gets the benchmark's return since the first point, measured from the last
+// close on or before each date, so a snapshot on an exchange holiday carries the previous
+// close. Beta and alpha pair the portfolio's daily returns with the benchmark's on
+// consecutive points that both have a close, and are left nil when fewer than
+// MinRiskObservations pairs remain. Nothing is added without a close on or before the
+// last point.
+func (h *PortfolioHistory) AddBenchmark(symbol string, closes []DailyClose) {
+	closeOn := make(map[string]float64, len(closes))
+	for _, c := range closes {
+		if c.Close > 0 {
+			closeOn[c.Date.Format("2006-01-02")] = c.Close
+		}
+	}
+
+	var base, last, prevClose float64
+	var portfolioReturns, benchmarkReturns []float64
+	next := 0
+	for i := range h.Points {
+		p := &h.Points[i]
+		for next < len(closes) && !closes[next].Date.After(p.Date) {
+			if closes[next].Close > 0 {
+				last = closes[next].Close
+			}
+			next++
+		}
+		dayClose := closeOn[p.Date.Format("2006-01-02")]
+		if i > 0 && dayClose > 0 && prevClose > 0 && h.Points[i-1].Equity.IsPositive() {
+			portfolioReturns = append(portfolioReturns, p.DailyReturn)
+			benchmarkReturns = append(benchmarkReturns, dayClose/prevClose-1)
+		}
+		prevClose = dayClose
+		if last == 0 {
+			continue
+		}
+		if base == 0 {
+			base = last
+		}
+		r := last/base - 1
+		p.BenchmarkReturn = &r
+	}
+	if base == 0 {
+		return
+	}
+
+	comparison := &BenchmarkComparison{
+		Symbol:         symbol,
+		Return:         last/base - 1,
+		RelativeReturn: h.TimeWeightedReturn - (last/base - 1),
+		Observations:   len(portfolioReturns),
+	}
+	if len(portfolioReturns) >= MinRiskObservations {
+		if v := variance(benchmarkReturns); v > 0 {
+			beta := covariance(portfolioReturns, benchmarkReturns) / v
+			alpha := (mean(portfolioReturns) - beta*mean(benchmarkReturns)) * tradingDaysPerYear
+			comparison.Beta, comparison.Alpha = &beta, &alpha
+		}
+	}
+	h.Benchmark = comparison
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestNewPortfolioSnapshot(t *testing.T) {
+	// 21:30 UTC on a Friday is still Friday in New York
+	at := time.Date(2024, 3, 8, 21, 30, 0, 0, time.UTC)
+	account := &Account{Equity: decimal.NewFromInt(10000), Cash: decimal.NewFromInt(4000)}
+	positions := []Position{
+		{Symbol: "AAPL", Side: PositionSideLong, Quantity: decimal.NewFromInt(10), CurrentPrice: decimal.NewFromInt(170), UnrealizedPL: decimal.NewFromInt(100)},
+		{Symbol: "TSLA", Side: PositionSideShort, Quantity: decimal.NewFromInt(5), CurrentPrice: decimal.NewFromInt(180), UnrealizedPL: decimal.NewFromInt(-40)},
+	}
+
+	snapshot := NewPortfolioSnapshot(at, account, positions, decimal.NewFromInt(500))
+	if !snapshot.Date.Equal(time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Date = %v, want the market date", snapshot.Date)
+	}
+	if !snapshot.Equity.Equal(account.Equity) || !snapshot.Cash.Equal(account.Cash) || !snapshot.CashFlow.Equal(decimal.NewFromInt(500)) {
+		t.Errorf("snapshot = %+v, want the account's equity, cash and the cash flow", snapshot)
+	}
+	if len(snapshot.Positions) != 2 || !snapshot.Positions[0].MarketValue.Equal(decimal.NewFromInt(1700)) || !snapshot.Positions[1].MarketValue.Equal(decimal.NewFromInt(-900)) {
+		t.Errorf("positions = %+v, want long and short market values", snapshot.Positions)
+	}
+}
+
+func TestHistoryRangeStart(t *testing.T) {
+	now := time.Date(2024, 5, 15, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		rng  string
+		want time.Time
+	}{
+		{"1m", time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC)},
+		{"6m", time.Date(2023, 11, 15, 0, 0, 0, 0, time.UTC)},
+		{"ytd", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"1y", time.Date(2023, 5, 15, 0, 0, 0, 0, time.UTC)},
+		{"all", time.Time{}},
+	}
+	for _, tt := range tests {
+		got, err := HistoryRangeStart(tt.rng, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("HistoryRangeStart(%q) = %v, %v, want %v", tt.rng, got, err, tt.want)
+		}
+	}
+
+	if _, err := HistoryRangeStart("2y", now); !errors.Is(err, ErrInvalidHistoryRange) {
+		t.Errorf("HistoryRangeStart(2y) error = %v, want ErrInvalidHistoryRange", err)
+	}
+}
+
+func TestNewPortfolioHistory(t *testing.T) {
+	day := func(d int, equity, flow int64) PortfolioSnapshot {
+		return PortfolioSnapshot{
+			Date:     time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC),
+			Equity:   decimal.NewFromInt(equity),
+			CashFlow: decimal.NewFromInt(flow),
+		}
+	}
+	snapshots := []PortfolioSnapshot{
+		day(4, 100, 1000), // The first day's flow predates the range
+		day(5, 110, 0),    // +10%
+		day(6, 160, 50),   // The $50 deposit isn't a gain: flat
+		day(7, 120, 0),    // -25%
+		day(8, 140, -10),  // The $10 withdrawal isn't a loss: +25%
+	}
+
+	history := NewPortfolioHistory("1m", snapshots)
+	if len(history.Points) != 5 || !history.NetCashFlow.Equal(decimal.NewFromInt(40)) {
+		t.Fatalf("history = %+v, want 5 points and $40 net cash flow", history)
+	}
+	wantTWR := 1.1*0.75*1.25 - 1
+	if math.Abs(history.TimeWeightedReturn-wantTWR) > 1e-9 {
+		t.Errorf("TimeWeightedReturn = %v, want %v", history.TimeWeightedReturn, wantTWR)
+	}
+	if math.Abs(history.MaxDrawdown-0.25) > 1e-9 || math.Abs(history.Points[3].Drawdown-0.25) > 1e-9 {
+		t.Errorf("MaxDrawdown = %v, day 4 drawdown = %v, want 25%%", history.MaxDrawdown, history.Points[3].Drawdown)
+	}
+	if math.Abs(history.Points[2].CumulativeReturn-0.1) > 1e-9 {
+		t.Errorf("day 3 cumulative return = %v, want 10%%", history.Points[2].CumulativeReturn)
+	}
+
+	if empty := NewPortfolioHistory("1y", nil); empty.TimeWeightedReturn != 0 || empty.MaxDrawdown != 0 || len(empty.Points) != 0 {
+		t.Errorf("empty history = %+v, want zeros", empty)
+	}
+}
+
+func TestPortfolioHistory_AddBenchmark(t *testing.T) {
+	// The portfolio returns twice the benchmark's daily return plus 0.1%
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	closes := []DailyClose{{Date: start.AddDate(0, 0, -3), Close: 99}, {Date: start, Close: 100}}
+	snapshots := []PortfolioSnapshot{{Date: start, Equity: decimal.NewFromInt(10000)}}
+	equity, bench := 10000.0, 100.0
+	for d := 1; d <= 30; d++ {
+		date := start.AddDate(0, 0, d)
+		if d != 15 {
+			// Day 15 is an exchange holiday, recorded flat with no benchmark close
+			b := 0.01
+			if d%2 == 0 {
+				b = -0.008
+			}
+			bench *= 1 + b
+			equity *= 1 + 2*b + 0.001
+			closes = append(closes, DailyClose{Date: date, Close: bench})
+		}
+		snapshots = append(snapshots, PortfolioSnapshot{Date: date, Equity: decimal.NewFromFloat(equity)})
+	}
+
+	history := NewPortfolioHistory("1m", snapshots)
+	history.AddBenchmark("SPY", closes)
+	c := history.Benchmark
+	if c == nil || c.Symbol != "SPY" || c.Beta == nil || c.Alpha == nil {
+		t.Fatalf("Benchmark = %+v, want beta and alpha", c)
+	}
+	// The holiday and the day after it have no paired return
+	if c.Observations != 28 {
+		t.Errorf("Observations = %d, want 28", c.Observations)
+	}
+	if math.Abs(*c.Beta-2) > 1e-6 || math.Abs(*c.Alpha-0.001*tradingDaysPerYear) > 1e-6 {
+		t.Errorf("beta = %v, alpha = %v, want 2 and %v", *c.Beta, *c.Alpha, 0.001*tradingDaysPerYear)
+	}
+	wantReturn := bench/100 - 1
+	if math.Abs(c.Return-wantReturn) > 1e-9 || math.Abs(c.RelativeReturn-(history.TimeWeightedReturn-wantReturn)) > 1e-9 {
+		t.Errorf("Return = %v, RelativeReturn = %v, want %v and the difference from the portfolio's", c.Return, c.RelativeReturn, wantReturn)
+	}
+	holiday, before := history.Points[15].BenchmarkReturn, history.Points[14].BenchmarkReturn
+	if holiday == nil || before == nil || *holiday != *before {
+		t.Errorf("holiday benchmark return = %v, want the previous day's %v", holiday, before)
+	}
+
+	// Too few days to pair up leaves beta unknown
+	short := NewPortfolioHistory("1m", snapshots[:5])
+	short.AddBenchmark("SPY", closes)
+	if short.Benchmark == nil || short.Benchmark.Beta != nil || short.Benchmark.Observations != 4 {
+		t.Errorf("short Benchmark = %+v, want 4 observations and no beta", short.Benchmark)
+	}
+
+	none := NewPortfolioHistory("1m", snapshots)
+	none.AddBenchmark("SPY", nil)
+	if none.Benchmark != nil || none.Points[0].BenchmarkReturn != nil {
+		t.Errorf("Benchmark = %+v without closes, want nil", none.Benchmark)
+	}
+}
@@ -1,22 +1,31 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
+// ErrPositionNotFound is returned by brokers asked for a position in a symbol not held
+var ErrPositionNotFound = errors.New("position does not exist")
+
 type Position struct {
-	ID            uuid.UUID       `json:"id"`
-	Symbol        string          `json:"symbol"`
-	Quantity      decimal.Decimal `json:"quantity"`
-	AvgEntryPrice decimal.Decimal `json:"avg_entry_price"`
-	CurrentPrice  decimal.Decimal `json:"current_price"`
-	UnrealizedPL  decimal.Decimal `json:"unrealized_pl"`
-	Side          PositionSide    `json:"side"`
-	CreatedAt     time.Time       `json:"created_at"`
-	UpdatedAt     time.Time       `json:"updated_at"`
+	ID                uuid.UUID        `json:"id"`
+	Symbol            string           `json:"symbol"`
+	Quantity          decimal.Decimal  `json:"quantity"`
+	AvgEntryPrice     decimal.Decimal  `json:"avg_entry_price"`
+	CurrentPrice      decimal.Decimal  `json:"current_price"`
+	UnrealizedPL      decimal.Decimal  `json:"unrealized_pl"`
+	Side              PositionSide     `json:"side"`
+	StopLossPrice     *decimal.Decimal `json:"stop_loss_price,omitempty"`      // Stop of the bracket order that opened the position
+	TakeProfitPrice   *decimal.Decimal `json:"take_profit_price,omitempty"`    // Target of the same bracket order
+	StopLossOrderID   string           `json:"stop_loss_order_id,omitempty"`   // Broker order ID of the stop-loss leg
+	TakeProfitOrderID string           `json:"take_profit_order_id,omitempty"` // Broker order ID of the take-profit leg
+	Version           int              `json:"version"`                        // Row version for optimistic locking, incremented on each update
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
 }
 
 type PositionSide string
@@ -36,3 +45,40 @@ func (p *Position) CalculateUnrealizedPL() decimal.Decimal {
 	}
 	return priceDiff.Mul(p.Quantity)
 }
+
+// Protect records the legs of the bracket order that opened the position as its protective
+// levels, with their broker order IDs
+func (p *Position) Protect(b *Bracket) {
+	if b == nil {
+		return
+	}
+	stopLoss, takeProfit := b.StopLoss, b.TakeProfit
+	p.StopLossPrice = &stopLoss
+	p.TakeProfitPrice = &takeProfit
+	p.StopLossOrderID, p.TakeProfitOrderID = b.StopLossOrderID, b.TakeProfitOrderID
+}
+
+// Unprotect forgets the bracket legs once they have been cancelled or one of them filled
+func (p *Position) Unprotect() {
+	p.StopLossPrice, p.TakeProfitPrice = nil, nil
+	p.StopLossOrderID, p.TakeProfitOrderID = "", ""
+}
+
+// BracketLegs returns the broker order IDs of the legs guarding the position
+func (p *Position) BracketLegs() []string {
+	var legs []string
+	for _, id := range []string{p.StopLossOrderID, p.TakeProfitOrderID} {
+		if id != "" {
+			legs = append(legs, id)
+		}
+	}
+	return legs
+}
+
+// EffectiveSide returns the position's side, treating an unset side as long
+func (p *Position) EffectiveSide() PositionSide {
+	if p.Side == PositionSideShort {
+		return PositionSideShort
+	}
+	return PositionSideLong
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidBarTimeframe is returned for a bar timeframe other than BarTimeframes
+var ErrInvalidBarTimeframe = errors.New("invalid bar timeframe")
+
+// BarTimeframe is the period each price bar covers
+type BarTimeframe string
+
+const (
+	BarTimeframe1Min  BarTimeframe = "1Min"
+	BarTimeframe5Min  BarTimeframe = "5Min"
+	BarTimeframe15Min BarTimeframe = "15Min"
+	BarTimeframe1Hour BarTimeframe = "1H"
+	BarTimeframe1Day  BarTimeframe = "1D"
+	BarTimeframe1Week BarTimeframe = "1W"
+	BarTimeframe1Mon  BarTimeframe = "1M"
+)
+
+// BarTimeframes lists the supported bar timeframes, shortest first
+var BarTimeframes = []BarTimeframe{
+	BarTimeframe1Min, BarTimeframe5Min, BarTimeframe15Min, BarTimeframe1Hour,
+	BarTimeframe1Day, BarTimeframe1Week, BarTimeframe1Mon,
+}
+
+// tradingMinutesPerDay is the length of a regular US equity session
+const tradingMinutesPerDay = 390
+
+// ParseBarTimeframe returns the timeframe named by s, ignoring case, defaulting to daily
+// bars when s is empty. A month must be written "1M", since "1m" reads as a minute.
+func ParseBarTimeframe(s string) (BarTimeframe, error) {
+	if s == "" {
+		return BarTimeframe1Day, nil
+	}
+	for _, tf := range BarTimeframes {
+		if s == string(tf) || (tf != BarTimeframe1Mon && strings.EqualFold(s, string(tf))) {
+			return tf, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q: use one of 1Min, 5Min, 15Min, 1H, 1D, 1W or 1M", ErrInvalidBarTimeframe, s)
+}
+
+// Intraday reports whether bars of the timeframe cover less than a trading day
+func (t BarTimeframe) Intraday() bool {
+	return t.minutes() > 0
+}
+
+// minutes returns the length of an intraday bar, or zero for daily and longer bars
+func (t BarTimeframe) minutes() int {
+	switch t {
+	case BarTimeframe1Min:
+		return 1
+	case BarTimeframe5Min:
+		return 5
+	case BarTimeframe15Min:
+		return 15
+	case BarTimeframe1Hour:
+		return 60
+	}
+	return 0
+}
+
+// Lookback returns how far back to request bars so that about limit of them come back,
+// allowing for nights, weekends and holidays without trading
+func (t BarTimeframe) Lookback(limit int) time.Duration {
+	const day = 24 * time.Hour
+	switch t {
+	case BarTimeframe1Week:
+		return time.Duration(limit+1) * 7 * day
+	case BarTimeframe1Mon:
+		return time.Duration(limit+1) * 31 * day
+	case BarTimeframe1Day:
+		// About 252 trading days a year, plus a margin for holidays
+		return time.Duration(limit*365/252+7) * day
+	}
+	barsPerDay := tradingMinutesPerDay / t.minutes()
+	tradingDays := (limit + barsPerDay - 1) / barsPerDay
+	return time.Duration(tradingDays*7/5+4) * day
+}
+
+// PriceBar is one OHLCV candle. Prices are floats so charts can plot them directly.
+type PriceBar struct {
+	Time   time.Time `json:"time"` // Start of the bar's period
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume uint64    `json:"volume"`
+	VWAP   float64   `json:"vwap,omitempty"`
+}
+
+// PriceHistory is a symbol's most recent price bars, oldest first
+type PriceHistory struct {
+	Symbol    string       `json:"symbol"`
+	Timeframe BarTimeframe `json:"timeframe"`
+	Bars      []PriceBar   `json:"bars"`
+	FetchedAt time.Time    `json:"fetched_at"`
+}
+
+// Range returns the lowest low and highest high of the bars, or zeros without bars
+func (h *PriceHistory) Range() (low, high float64) {
+	for i, bar := range h.Bars {
+		if i == 0 || bar.Low < low {
+			low = bar.Low
+		}
+		if i == 0 || bar.High > high {
+			high = bar.High
+		}
+	}
+	return low, high
+}
+
+// Change returns the move from the first bar's open to the last bar's close, in dollars
+// and percent, or zeros without bars
+func (h *PriceHistory) Change() (amount, percent float64) {
+	if len(h.Bars) == 0 || h.Bars[0].Open == 0 {
+		return 0, 0
+	}
+	first, last := h.Bars[0].Open, h.Bars[len(h.Bars)-1].Close
+	return last - first, (last/first - 1) * 100
+}
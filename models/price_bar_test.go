@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseBarTimeframe(t *testing.T) {
+	for in, want := range map[string]BarTimeframe{
+		"":      BarTimeframe1Day,
+		"1D":    BarTimeframe1Day,
+		"1d":    BarTimeframe1Day,
+		"5min":  BarTimeframe5Min,
+		"1Min":  BarTimeframe1Min,
+		"1M":    BarTimeframe1Mon,
+		"1H":    BarTimeframe1Hour,
+		"15MIN": BarTimeframe15Min,
+	} {
+		if got, err := ParseBarTimeframe(in); err != nil || got != want {
+			t.Errorf("ParseBarTimeframe(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"1m", "2D", "1Y"} {
+		if _, err := ParseBarTimeframe(in); !errors.Is(err, ErrInvalidBarTimeframe) {
+			t.Errorf("ParseBarTimeframe(%q) error = %v, want ErrInvalidBarTimeframe", in, err)
+		}
+	}
+}
+
+func TestBarTimeframe_Lookback(t *testing.T) {
+	const day = 24 * time.Hour
+	for _, tt := range []struct {
+		timeframe BarTimeframe
+		limit     int
+		want      time.Duration
+	}{
+		{BarTimeframe1Day, 252, 372 * day},
+		{BarTimeframe1Week, 52, 371 * day},
+		{BarTimeframe5Min, 78, 5 * day},   // One session, across a weekend
+		{BarTimeframe1Hour, 60, 18 * day}, // 6 bars a session, 10 sessions
+	} {
+		if got := tt.timeframe.Lookback(tt.limit); got != tt.want {
+			t.Errorf("%s.Lookback(%d) = %v, want %v", tt.timeframe, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestPriceHistory_RangeAndChange(t *testing.T) {
+	h := &PriceHistory{Bars: []PriceBar{
+		{Open: 100, High: 105, Low: 98, Close: 104},
+		{Open: 104, High: 112, Low: 103, Close: 110},
+	}}
+	if low, high := h.Range(); low != 98 || high != 112 {
+		t.Errorf("Range() = %v, %v, want 98, 112", low, high)
+	}
+	if amount, percent := h.Change(); amount != 10 || math.Abs(percent-10) > 1e-9 {
+		t.Errorf("Change() = %v, %v%%, want 10, 10%%", amount, percent)
+	}
+	if amount, percent := (&PriceHistory{}).Change(); amount != 0 || percent != 0 {
+		t.Error("expected no change without bars")
+	}
+}
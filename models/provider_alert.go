@@ -0,0 +1,80 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProviderAlertKind identifies why an external provider stopped serving requests
+type ProviderAlertKind string
+
+const (
+	ProviderAlertBreakerOpen    ProviderAlertKind = "breaker_open"
+	ProviderAlertQuotaExhausted ProviderAlertKind = "quota_exhausted"
+)
+
+// ErrProviderAlertNotFound is returned when dismissing an alert that does not exist
+var ErrProviderAlertNotFound = errors.New("provider alert not found")
+
+// maxErrorSampleLen bounds the error text stored with an alert
+const maxErrorSampleLen = 300
+
+// Label returns a human-readable name for the alert kind
+func (k ProviderAlertKind) Label() string {
+	switch k {
+	case ProviderAlertBreakerOpen:
+		return "Circuit breaker open"
+	case ProviderAlertQuotaExhausted:
+		return "API quota exhausted"
+	default:
+		return string(k)
+	}
+}
+
+// ProviderAlert notifies the user that an external provider is unavailable, so
+// analyses relying on it are degraded until it recovers
+type ProviderAlert struct {
+	ID          uuid.UUID         `json:"id"`
+	Provider    string            `json:"provider"` // Circuit breaker name of the provider (fmp, newsapi, openai, ...)
+	Kind        ProviderAlertKind `json:"kind"`
+	ErrorSample string            `json:"error_sample"`         // Last error seen from the provider
+	RecoverAt   *time.Time        `json:"recover_at,omitempty"` // Expected recovery; nil when the provider gave no hint
+	CreatedAt   time.Time         `json:"created_at"`
+	DismissedAt *time.Time        `json:"dismissed_at,omitempty"`
+}
+
+// NewProviderAlert creates an alert, truncating long error samples
+func NewProviderAlert(provider string, kind ProviderAlertKind, errorSample string, recoverAt *time.Time) *ProviderAlert {
+	if len(errorSample) > maxErrorSampleLen {
+		errorSample = errorSample[:maxErrorSampleLen] + "..."
+	}
+	return &ProviderAlert{
+		ID:          uuid.New(),
+		Provider:    provider,
+		Kind:        kind,
+		ErrorSample: errorSample,
+		RecoverAt:   recoverAt,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// Active reports whether the alert has not been dismissed and its provider is not yet
+// expected to have recovered
+func (a *ProviderAlert) Active(now time.Time) bool {
+	if a.DismissedAt != nil {
+		return false
+	}
+	return a.RecoverAt == nil || now.Before(*a.RecoverAt)
+}
+
+// Message summarizes the alert in one sentence
+func (a *ProviderAlert) Message() string {
+	msg := fmt.Sprintf("%s: %s", a.Provider, a.Kind.Label())
+	if a.RecoverAt != nil {
+		msg += fmt.Sprintf(", expected to recover at %s", a.RecoverAt.Format("15:04:05 MST"))
+	}
+	return msg
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewProviderAlert_TruncatesErrorSample(t *testing.T) {
+	alert := NewProviderAlert("fmp", ProviderAlertBreakerOpen, strings.Repeat("x", 1000), nil)
+	if len(alert.ErrorSample) != maxErrorSampleLen+len("...") {
+		t.Errorf("ErrorSample length = %d, want %d", len(alert.ErrorSample), maxErrorSampleLen+3)
+	}
+}
+
+func TestProviderAlert_Active(t *testing.T) {
+	now := time.Date(2024, 6, 3, 14, 0, 0, 0, time.UTC)
+	later, earlier := now.Add(time.Minute), now.Add(-time.Minute)
+
+	tests := []struct {
+		name  string
+		alert ProviderAlert
+		want  bool
+	}{
+		{"recovering", ProviderAlert{RecoverAt: &later}, true},
+		{"no recovery hint", ProviderAlert{}, true},
+		{"recovered", ProviderAlert{RecoverAt: &earlier}, false},
+		{"dismissed", ProviderAlert{RecoverAt: &later, DismissedAt: &earlier}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.alert.Active(now); got != tt.want {
+				t.Errorf("Active() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderAlert_Message(t *testing.T) {
+	recoverAt := time.Date(2024, 6, 3, 14, 0, 30, 0, time.UTC)
+	alert := ProviderAlert{Provider: "newsapi", Kind: ProviderAlertQuotaExhausted, RecoverAt: &recoverAt}
+	if got, want := alert.Message(), "newsapi: API quota exhausted, expected to recover at 14:00:30 UTC"; got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+}
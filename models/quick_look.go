@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// QuickLook is a compact summary of a symbol for hover popovers: where it trades,
+// how it moved today, what the agents last recommended and when it next reports.
+// Parts that couldn't be fetched are left empty rather than failing the summary.
+type QuickLook struct {
+	Symbol               string          `json:"symbol"`
+	Price                decimal.Decimal `json:"price"`
+	Session              MarketSession   `json:"session,omitempty"`
+	PreviousClose        decimal.Decimal `json:"previous_close"`
+	DayChange            decimal.Decimal `json:"day_change"`
+	DayChangePercent     float64         `json:"day_change_percent"`
+	LatestRecommendation *Recommendation `json:"latest_recommendation,omitempty"`
+	NextEarnings         *time.Time      `json:"next_earnings,omitempty"`
+	FetchedAt            time.Time       `json:"fetched_at"`
+}
+
+// HasPrice reports whether a price was available for the symbol
+func (q *QuickLook) HasPrice() bool {
+	return q.Price.IsPositive()
+}
+
+// HasDayChange reports whether the day change could be computed
+func (q *QuickLook) HasDayChange() bool {
+	return q.HasPrice() && q.PreviousClose.IsPositive()
+}
+
+// SetPreviousClose records the prior session's close and derives the day change from it
+func (q *QuickLook) SetPreviousClose(prev decimal.Decimal) {
+	q.PreviousClose = prev
+	if !q.HasDayChange() {
+		return
+	}
+	q.DayChange = q.Price.Sub(prev)
+	q.DayChangePercent, _ = q.DayChange.Div(prev).Mul(decimal.NewFromInt(100)).Float64()
+}
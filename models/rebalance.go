@@ -0,0 +1,128 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidRebalanceTargets is returned when target weights can't be rebalanced to
+var ErrInvalidRebalanceTargets = errors.New("invalid rebalance targets")
+
+// RebalanceTargets are the shares of equity the portfolio is rebalanced toward. Symbol
+// targets size single positions; sector targets scale the other positions in a sector
+// together. Positions covered by neither are left alone.
+type RebalanceTargets struct {
+	Positions map[string]float64 `json:"positions,omitempty"` // Symbol to fraction of equity
+	Sectors   map[string]float64 `json:"sectors,omitempty"`   // GICS sector to fraction of equity
+}
+
+// Empty reports whether no targets are set
+func (t RebalanceTargets) Empty() bool {
+	return len(t.Positions) == 0 && len(t.Sectors) == 0
+}
+
+// Normalize upper-cases symbols and maps sector names to their GICS names
+func (t RebalanceTargets) Normalize() RebalanceTargets {
+	normalized := RebalanceTargets{}
+	if len(t.Positions) > 0 {
+		normalized.Positions = make(map[string]float64, len(t.Positions))
+		for symbol, weight := range t.Positions {
+			normalized.Positions[strings.ToUpper(strings.TrimSpace(symbol))] = weight
+		}
+	}
+	if len(t.Sectors) > 0 {
+		normalized.Sectors = make(map[string]float64, len(t.Sectors))
+		for sector, weight := range t.Sectors {
+			normalized.Sectors[NormalizeSector(sector)] = weight
+		}
+	}
+	return normalized
+}
+
+// Validate checks every target is a fraction of equity in (0, 1] and that neither the symbol
+// nor the sector targets add up to more than the whole portfolio
+func (t RebalanceTargets) Validate() error {
+	for _, group := range []struct {
+		name    string
+		targets map[string]float64
+	}{{"position", t.Positions}, {"sector", t.Sectors}} {
+		var sum float64
+		for key, weight := range group.targets {
+			if key == "" {
+				return fmt.Errorf("%w: %s target without a name", ErrInvalidRebalanceTargets, group.name)
+			}
+			if weight <= 0 || weight > 1 {
+				return fmt.Errorf("%w: %s target %s must be between 0 and 1, got %.2f", ErrInvalidRebalanceTargets, group.name, key, weight)
+			}
+			sum += weight
+		}
+		if sum > 1.0001 {
+			return fmt.Errorf("%w: %s targets add up to %.2f, more than the whole portfolio", ErrInvalidRebalanceTargets, group.name, sum)
+		}
+	}
+	return nil
+}
+
+// RebalanceItem is one order of a rebalancing plan
+type RebalanceItem struct {
+	Symbol           string               `json:"symbol"`
+	Sector           string               `json:"sector,omitempty"` // Set when a sector target sized the order
+	Action           RecommendationAction `json:"action"`           // Buy or sell
+	Quantity         decimal.Decimal      `json:"quantity"`
+	Price            decimal.Decimal      `json:"price"`
+	CurrentWeight    float64              `json:"current_weight"` // Fraction of equity before the order
+	TargetWeight     float64              `json:"target_weight"`  // Fraction of equity after it
+	Reasoning        string               `json:"reasoning"`
+	RecommendationID *uuid.UUID           `json:"recommendation_id,omitempty"` // Set once the order is recorded as a recommendation
+	TradeID          *uuid.UUID           `json:"trade_id,omitempty"`          // Set once the order is placed
+	Error            string               `json:"error,omitempty"`             // Why the order was not placed
+
+	// Version the recommendation was recorded at, so executing it fails if it changed since
+	RecommendationVersion int `json:"-"`
+}
+
+// Value returns the order's dollar value at its planned price
+func (i RebalanceItem) Value() decimal.Decimal {
+	return i.Quantity.Mul(i.Price)
+}
+
+// RebalancePlan is the set of orders that moves the portfolio to its target weights
+type RebalancePlan struct {
+	Equity         decimal.Decimal  `json:"equity"`
+	Targets        RebalanceTargets `json:"targets"`
+	DriftThreshold float64          `json:"drift_threshold"` // Weights closer to target than this are left alone
+	Items          []RebalanceItem  `json:"items"`
+	Notes          []string         `json:"notes,omitempty"` // Targets that could not be planned for, and why
+	Executed       bool             `json:"executed"`
+	CreatedAt      time.Time        `json:"created_at"`
+	Disclaimer     string           `json:"disclaimer,omitempty"` // Compliance text attached when served; not stored
+}
+
+// SortItems orders sells before buys, so their proceeds fund the buys, and larger orders
+// first within each side
+func (p *RebalancePlan) SortItems() {
+	sort.SliceStable(p.Items, func(i, j int) bool {
+		a, b := p.Items[i], p.Items[j]
+		if a.Action != b.Action {
+			return a.Action == RecommendationActionSell
+		}
+		return a.Value().GreaterThan(b.Value())
+	})
+}
+
+// Failed returns the items whose order was not placed
+func (p *RebalancePlan) Failed() []RebalanceItem {
+	var failed []RebalanceItem
+	for _, item := range p.Items {
+		if item.Error != "" {
+			failed = append(failed, item)
+		}
+	}
+	return failed
+}
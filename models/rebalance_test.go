@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRebalanceTargets_NormalizeAndValidate(t *testing.T) {
+	targets := RebalanceTargets{
+		Positions: map[string]float64{" aapl ": 0.1},
+		Sectors:   map[string]float64{"TECHNOLOGY": 0.3},
+	}.Normalize()
+
+	if targets.Positions["AAPL"] != 0.1 {
+		t.Errorf("Positions = %v, want AAPL upper-cased", targets.Positions)
+	}
+	if targets.Sectors[SectorInformationTechnology] != 0.3 {
+		t.Errorf("Sectors = %v, want the GICS sector name", targets.Sectors)
+	}
+	if err := targets.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		targets RebalanceTargets
+	}{
+		{"zero weight", RebalanceTargets{Positions: map[string]float64{"AAPL": 0}}},
+		{"weight above one", RebalanceTargets{Sectors: map[string]float64{SectorEnergy: 1.5}}},
+		{"over the whole portfolio", RebalanceTargets{Positions: map[string]float64{"AAPL": 0.6, "MSFT": 0.5}}},
+		{"unnamed", RebalanceTargets{Positions: map[string]float64{"": 0.1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.targets.Validate(); !errors.Is(err, ErrInvalidRebalanceTargets) {
+				t.Errorf("Validate() = %v, want ErrInvalidRebalanceTargets", err)
+			}
+		})
+	}
+}
+
+func TestRebalancePlan_SortItems(t *testing.T) {
+	plan := &RebalancePlan{Items: []RebalanceItem{
+		{Symbol: "SMALLBUY", Action: RecommendationActionBuy, Quantity: decimal.NewFromInt(1), Price: decimal.NewFromInt(10)},
+		{Symbol: "SELL", Action: RecommendationActionSell, Quantity: decimal.NewFromInt(1), Price: decimal.NewFromInt(10)},
+		{Symbol: "BIGBUY", Action: RecommendationActionBuy, Quantity: decimal.NewFromInt(10), Price: decimal.NewFromInt(10)},
+	}}
+	plan.SortItems()
+
+	for i, want := range []string{"SELL", "BIGBUY", "SMALLBUY"} {
+		if plan.Items[i].Symbol != want {
+			t.Errorf("Items[%d] = %s, want %s", i, plan.Items[i].Symbol, want)
+		}
+	}
+}
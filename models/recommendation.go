@@ -1,30 +1,58 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
+// ErrRecommendationNotExecutable is returned when executing a recommendation that is
+// a hold, has no quantity, or has already been rejected or executed
+var ErrRecommendationNotExecutable = errors.New("recommendation cannot be executed")
+
+// ErrRecommendationNotFound is returned when a recommendation ID matches no recommendation
+var ErrRecommendationNotFound = errors.New("recommendation not found")
+
+// ErrInvalidRecommendationStatus is returned when filtering by a status that does not exist
+var ErrInvalidRecommendationStatus = errors.New("invalid recommendation status")
+
 type Recommendation struct {
-	ID               uuid.UUID            `json:"id"`
-	Symbol           string               `json:"symbol"`
-	Action           RecommendationAction `json:"action"`
-	Quantity         decimal.Decimal      `json:"quantity"`
-	TargetPrice      decimal.Decimal      `json:"target_price"`
-	Confidence       float64              `json:"confidence"`
-	Reasoning        string               `json:"reasoning"`
-	FundamentalScore float64              `json:"fundamental_score"`
-	SentimentScore   float64              `json:"sentiment_score"`
-	TechnicalScore   float64              `json:"technical_score"`
-	DataCompleteness float64              `json:"data_completeness"` // 0-100: percentage of agents that succeeded
-	MissingAgents    []MissingAgentInfo   `json:"missing_agents,omitempty"`
-	Status           RecommendationStatus `json:"status"`
-	ApprovedAt       *time.Time           `json:"approved_at,omitempty"`
-	RejectedAt       *time.Time           `json:"rejected_at,omitempty"`
-	ExecutedTradeID  *uuid.UUID           `json:"executed_trade_id,omitempty"`
-	CreatedAt        time.Time            `json:"created_at"`
+	ID               uuid.UUID               `json:"id"`
+	Symbol           string                  `json:"symbol"`
+	Action           RecommendationAction    `json:"action"`
+	Quantity         decimal.Decimal         `json:"quantity"`
+	EntryPrice       decimal.Decimal         `json:"entry_price"`
+	TargetPrice      decimal.Decimal         `json:"target_price"`
+	StopPrice        decimal.Decimal         `json:"stop_price"`
+	RiskReward       float64                 `json:"risk_reward"` // Reward/risk ratio from entry, target, and stop; 0 if unknown
+	Confidence       float64                 `json:"confidence"`
+	Reasoning        string                  `json:"reasoning"`
+	FundamentalScore float64                 `json:"fundamental_score"`
+	SentimentScore   float64                 `json:"sentiment_score"`
+	TechnicalScore   float64                 `json:"technical_score"`
+	SocialScore      float64                 `json:"social_score,omitempty"`     // Zero when the social sentiment agent is off or did not report
+	InsiderScore     float64                 `json:"insider_score,omitempty"`    // Zero when the insider activity agent is off or did not report
+	MacroScore       float64                 `json:"macro_score,omitempty"`      // Zero when the macro agent is off or did not report
+	TimeframeScores  *TimeframeScores        `json:"timeframe_scores,omitempty"` // Technical sub-scores per timeframe; nil if the technical agent did not report them
+	AgentWeights     *AgentWeights           `json:"agent_weights,omitempty"`    // Weights the scores were combined with; nil for recommendations made before they were recorded
+	DataCompleteness float64                 `json:"data_completeness"`          // 0-100: percentage of agents that succeeded
+	MissingAgents    []MissingAgentInfo      `json:"missing_agents,omitempty"`
+	WeightPolicy     WeightPolicy            `json:"weight_policy,omitempty"`              // Applied to missing agents' weights; empty when all agents reported
+	TriggerReason    string                  `json:"trigger_reason,omitempty"`             // Why an automatic re-analysis ran; empty for user-requested analyses
+	PreviousID       *uuid.UUID              `json:"previous_recommendation_id,omitempty"` // Recommendation a requested re-analysis followed; nil otherwise
+	Override         *RecommendationOverride `json:"override,omitempty"`                   // User edits made while pending; nil when the suggestion is used as is
+	Partial          bool                    `json:"partial,omitempty"`                    // Synthesized before every agent reported; updated when they finish
+	Status           RecommendationStatus    `json:"status"`
+	ApprovedAt       *time.Time              `json:"approved_at,omitempty"`
+	RejectedAt       *time.Time              `json:"rejected_at,omitempty"`
+	ExecutedTradeID  *uuid.UUID              `json:"executed_trade_id,omitempty"`
+	Version          int                     `json:"version"` // Row version for optimistic locking, incremented on each transition
+	CreatedAt        time.Time               `json:"created_at"`
+	Disclaimer       string                  `json:"disclaimer,omitempty"` // Compliance text attached when served; not stored
 }
 
 // MissingAgentInfo captures information about an agent that was unavailable or failed
@@ -33,23 +61,65 @@ type MissingAgentInfo struct {
 	Reason    string    `json:"reason"`
 }
 
+// WeightPolicy controls how the weight of an unavailable agent is handled when scoring
+type WeightPolicy string
+
+const (
+	// WeightPolicyRedistribute spreads missing weight proportionally across the agents that reported
+	WeightPolicyRedistribute WeightPolicy = "redistribute"
+	// WeightPolicyFloor scores missing agents as a neutral 0 at their full weight
+	WeightPolicyFloor WeightPolicy = "floor"
+	// WeightPolicyAbstain holds instead of scoring when the fundamental agent is missing, otherwise redistributes
+	WeightPolicyAbstain WeightPolicy = "abstain"
+)
+
 type RecommendationAction string
 
 const (
-	RecommendationActionBuy  RecommendationAction = "buy"
-	RecommendationActionSell RecommendationAction = "sell"
-	RecommendationActionHold RecommendationAction = "hold"
+	RecommendationActionBuy   RecommendationAction = "buy"
+	RecommendationActionSell  RecommendationAction = "sell"
+	RecommendationActionHold  RecommendationAction = "hold"
+	RecommendationActionShort RecommendationAction = "short" // Open or add to a short position
+	RecommendationActionCover RecommendationAction = "cover" // Buy back shares of a short position
 )
 
+// TradeSide returns the order side that carries out the action. Shorts are sells
+// without a long position to sell, and covers are buys.
+func (a RecommendationAction) TradeSide() TradeSide {
+	if a == RecommendationActionSell || a == RecommendationActionShort {
+		return TradeSideSell
+	}
+	return TradeSideBuy
+}
+
+// Bearish reports whether the action profits when the price falls
+func (a RecommendationAction) Bearish() bool {
+	return a == RecommendationActionSell || a == RecommendationActionShort
+}
+
 type RecommendationStatus string
 
 const (
-	RecommendationStatusPending  RecommendationStatus = "pending"
-	RecommendationStatusApproved RecommendationStatus = "approved"
-	RecommendationStatusRejected RecommendationStatus = "rejected"
-	RecommendationStatusExecuted RecommendationStatus = "executed"
+	RecommendationStatusPending   RecommendationStatus = "pending"
+	RecommendationStatusApproved  RecommendationStatus = "approved"
+	RecommendationStatusExecuting RecommendationStatus = "executing" // Claimed while its order is placed with the broker
+	RecommendationStatusRejected  RecommendationStatus = "rejected"
+	RecommendationStatusExecuted  RecommendationStatus = "executed"
+	RecommendationStatusExpired   RecommendationStatus = "expired" // Left open past its TTL or after the price moved away
+	RecommendationStatusFailed    RecommendationStatus = "failed"  // Executed, but the broker cancelled, rejected or expired the order unfilled
 )
 
+// ParseRecommendationStatus checks a status filter, returning "" for an empty one
+func ParseRecommendationStatus(s string) (RecommendationStatus, error) {
+	status := RecommendationStatus(strings.ToLower(strings.TrimSpace(s)))
+	switch status {
+	case "", RecommendationStatusPending, RecommendationStatusApproved, RecommendationStatusExecuting,
+		RecommendationStatusRejected, RecommendationStatusExecuted, RecommendationStatusExpired, RecommendationStatusFailed:
+		return status, nil
+	}
+	return "", fmt.Errorf("%w %q", ErrInvalidRecommendationStatus, s)
+}
+
 func NewRecommendation(symbol string, action RecommendationAction, reasoning string) *Recommendation {
 	return &Recommendation{
 		ID:        uuid.New(),
@@ -61,6 +131,76 @@ func NewRecommendation(symbol string, action RecommendationAction, reasoning str
 	}
 }
 
+// CalculateRiskReward returns the reward-to-risk ratio implied by the entry, target, and stop prices.
+// Buys and covers profit when price rises to target; sells and shorts profit when price falls to target.
+// Returns 0 if the levels are missing or inconsistent with the action.
+func (r *Recommendation) CalculateRiskReward() float64 {
+	if r.EntryPrice.IsZero() || r.TargetPrice.IsZero() || r.StopPrice.IsZero() || r.Action == RecommendationActionHold {
+		return 0
+	}
+
+	reward := r.TargetPrice.Sub(r.EntryPrice)
+	risk := r.EntryPrice.Sub(r.StopPrice)
+	if r.Action.Bearish() {
+		reward, risk = reward.Neg(), risk.Neg()
+	}
+
+	if !reward.IsPositive() || !risk.IsPositive() {
+		return 0
+	}
+	ratio, _ := reward.Div(risk).Round(2).Float64()
+	return ratio
+}
+
+// Bracket returns the stop-loss and take-profit legs protecting the position an opening buy
+// or short leaves when entered at price. The recommendation's stop and target are used when
+// they straddle price; otherwise the legs are set stopLossPercent and takeProfitPercent away
+// from it. Sells, covers and holds close or leave positions and get no bracket.
+func (r *Recommendation) Bracket(price decimal.Decimal, stopLossPercent, takeProfitPercent float64) *Bracket {
+	if !price.IsPositive() || (r.Action != RecommendationActionBuy && r.Action != RecommendationActionShort) {
+		return nil
+	}
+	side := r.Action.TradeSide()
+	b := &Bracket{StopLoss: r.StopPrice, TakeProfit: r.TargetPrice}
+	if b.Validate(side, price) == nil {
+		return b
+	}
+
+	one := decimal.NewFromInt(1)
+	stopLoss := decimal.NewFromFloat(stopLossPercent)
+	takeProfit := decimal.NewFromFloat(takeProfitPercent)
+	if r.Action.Bearish() {
+		stopLoss, takeProfit = stopLoss.Neg(), takeProfit.Neg()
+	}
+	return &Bracket{
+		StopLoss:   price.Mul(one.Sub(stopLoss)).Round(2),
+		TakeProfit: price.Mul(one.Add(takeProfit)).Round(2),
+	}
+}
+
+// Executable reports whether the recommendation can be sent to the broker
+func (r *Recommendation) Executable() bool {
+	if r.Action == RecommendationActionHold || !r.EffectiveQuantity().IsPositive() {
+		return false
+	}
+	return r.Status == RecommendationStatusPending || r.Status == RecommendationStatusApproved
+}
+
+// PriceDeviationPercent returns how far price has moved from the price the recommendation
+// would be entered at, its limit price or else its entry price, as an unsigned percentage.
+// It is 0 when either price is unknown.
+func (r *Recommendation) PriceDeviationPercent(price decimal.Decimal) float64 {
+	entry := r.EntryPrice
+	if limitPrice := r.EffectiveLimitPrice(); limitPrice != nil {
+		entry = *limitPrice
+	}
+	if !entry.IsPositive() || !price.IsPositive() {
+		return 0
+	}
+	deviation, _ := price.Sub(entry).Abs().Div(entry).Mul(decimal.NewFromInt(100)).Float64()
+	return deviation
+}
+
 func (r *Recommendation) Approve() {
 	now := time.Now()
 	r.ApprovedAt = &now
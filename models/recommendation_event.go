@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecommendationEventType identifies a recommendation state transition
+type RecommendationEventType string
+
+const (
+	RecommendationEventCreated   RecommendationEventType = "created"
+	RecommendationEventApproved  RecommendationEventType = "approved"
+	RecommendationEventRejected  RecommendationEventType = "rejected"
+	RecommendationEventExecuted  RecommendationEventType = "executed"
+	RecommendationEventExpired   RecommendationEventType = "expired"
+	RecommendationEventEdited    RecommendationEventType = "edited"
+	RecommendationEventCompleted RecommendationEventType = "completed" // A partial recommendation was updated with every agent's result
+	RecommendationEventFailed    RecommendationEventType = "failed"    // The broker cancelled, rejected or expired the executed order unfilled
+)
+
+// Actors recorded on recommendation events
+const (
+	ActorSystem = "system" // Agents, the screener, and background jobs
+	ActorUser   = "user"   // Actions taken through the API or dashboard
+)
+
+// RecommendationEvent is an entry in a recommendation's append-only state log.
+// Events are written in the same statement as the transition they describe.
+type RecommendationEvent struct {
+	ID               uuid.UUID               `json:"id"`
+	RecommendationID uuid.UUID               `json:"recommendation_id"`
+	Type             RecommendationEventType `json:"type"`
+	Actor            string                  `json:"actor"`
+	TradeID          *uuid.UUID              `json:"trade_id,omitempty"` // Set on executed events
+	OccurredAt       time.Time               `json:"occurred_at"`
+}
+
+// Label returns a human-readable name for the event type
+func (t RecommendationEventType) Label() string {
+	switch t {
+	case RecommendationEventCreated:
+		return "Created"
+	case RecommendationEventApproved:
+		return "Approved"
+	case RecommendationEventRejected:
+		return "Rejected"
+	case RecommendationEventExecuted:
+		return "Executed"
+	case RecommendationEventExpired:
+		return "Expired"
+	case RecommendationEventEdited:
+		return "Edited"
+	case RecommendationEventCompleted:
+		return "Analysis completed"
+	case RecommendationEventFailed:
+		return "Order not filled"
+	default:
+		return string(t)
+	}
+}
@@ -0,0 +1,93 @@
+package models
+
+import "github.com/google/uuid"
+
+// RecommendationDelta is how a recommendation's scores moved from the one before it.
+// Each field is the later value minus the earlier one.
+type RecommendationDelta struct {
+	PreviousID       uuid.UUID            `json:"previous_id"`
+	PreviousAction   RecommendationAction `json:"previous_action"`
+	ActionChanged    bool                 `json:"action_changed"`
+	Confidence       float64              `json:"confidence"`
+	FundamentalScore float64              `json:"fundamental_score"`
+	SentimentScore   float64              `json:"sentiment_score"`
+	TechnicalScore   float64              `json:"technical_score"`
+	SocialScore      float64              `json:"social_score"`
+	InsiderScore     float64              `json:"insider_score"`
+	MacroScore       float64              `json:"macro_score"`
+	DataCompleteness float64              `json:"data_completeness"`
+	HoursBetween     float64              `json:"hours_between"`
+}
+
+// CompareRecommendations returns how current's scores moved from previous's
+func CompareRecommendations(previous, current *Recommendation) RecommendationDelta {
+	return RecommendationDelta{
+		PreviousID:       previous.ID,
+		PreviousAction:   previous.Action,
+		ActionChanged:    previous.Action != current.Action,
+		Confidence:       current.Confidence - previous.Confidence,
+		FundamentalScore: current.FundamentalScore - previous.FundamentalScore,
+		SentimentScore:   current.SentimentScore - previous.SentimentScore,
+		TechnicalScore:   current.TechnicalScore - previous.TechnicalScore,
+		SocialScore:      current.SocialScore - previous.SocialScore,
+		InsiderScore:     current.InsiderScore - previous.InsiderScore,
+		MacroScore:       current.MacroScore - previous.MacroScore,
+		DataCompleteness: current.DataCompleteness - previous.DataCompleteness,
+		HoursBetween:     current.CreatedAt.Sub(previous.CreatedAt).Hours(),
+	}
+}
+
+// RecommendationHistoryEntry is one analysis of a symbol with how it moved from the
+// analysis before it; Delta is nil for the first
+type RecommendationHistoryEntry struct {
+	Recommendation
+	Delta *RecommendationDelta `json:"delta,omitempty"`
+}
+
+// RecommendationHistory is a symbol's recommendations, oldest first
+type RecommendationHistory struct {
+	Symbol  string                       `json:"symbol"`
+	Entries []RecommendationHistoryEntry `json:"entries"`
+}
+
+// NewRecommendationHistory builds a symbol's history from its recommendations, oldest
+// first. Each entry is compared with the recommendation it re-analyzed when that is in
+// the history, otherwise with the entry before it.
+func NewRecommendationHistory(symbol string, recs []Recommendation) *RecommendationHistory {
+	history := &RecommendationHistory{Symbol: symbol, Entries: make([]RecommendationHistoryEntry, len(recs))}
+	byID := make(map[uuid.UUID]int, len(recs))
+	for i := range recs {
+		history.Entries[i].Recommendation = recs[i]
+		byID[recs[i].ID] = i
+		if i == 0 {
+			continue
+		}
+		previous := &recs[i-1]
+		if recs[i].PreviousID != nil {
+			if j, ok := byID[*recs[i].PreviousID]; ok {
+				previous = &recs[j]
+			}
+		}
+		delta := CompareRecommendations(previous, &recs[i])
+		history.Entries[i].Delta = &delta
+	}
+	return history
+}
+
+// RecommendationComparison is a re-analysis next to the recommendation it followed, for
+// diffing. Previous and Delta are nil when the symbol had not been analyzed before.
+type RecommendationComparison struct {
+	Previous *Recommendation      `json:"previous,omitempty"`
+	Current  *Recommendation      `json:"current"`
+	Delta    *RecommendationDelta `json:"delta,omitempty"`
+}
+
+// NewRecommendationComparison compares current with previous, which may be nil
+func NewRecommendationComparison(previous, current *Recommendation) *RecommendationComparison {
+	comparison := &RecommendationComparison{Previous: previous, Current: current}
+	if previous != nil {
+		delta := CompareRecommendations(previous, current)
+		comparison.Delta = &delta
+	}
+	return comparison
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func historyRec(action RecommendationAction, confidence, fundamental float64, at time.Time) Recommendation {
+	r := NewRecommendation("AAPL", action, "test")
+	r.Confidence, r.FundamentalScore, r.CreatedAt = confidence, fundamental, at
+	return *r
+}
+
+func TestNewRecommendationHistory(t *testing.T) {
+	start := time.Date(2025, 3, 3, 14, 0, 0, 0, time.UTC)
+	first := historyRec(RecommendationActionHold, 50, 10, start)
+	second := historyRec(RecommendationActionBuy, 70, 40, start.Add(24*time.Hour))
+	// A re-analysis of the first recommendation, requested after the second was made
+	third := historyRec(RecommendationActionHold, 55, 20, start.Add(48*time.Hour))
+	third.PreviousID = &first.ID
+
+	history := NewRecommendationHistory("AAPL", []Recommendation{first, second, third})
+
+	if len(history.Entries) != 3 || history.Entries[0].Delta != nil {
+		t.Fatalf("entries = %+v, want three with no delta on the first", history.Entries)
+	}
+	d := history.Entries[1].Delta
+	if d == nil || d.PreviousID != first.ID || !d.ActionChanged || d.Confidence != 20 || d.FundamentalScore != 30 || d.HoursBetween != 24 {
+		t.Errorf("second delta = %+v, want the move from the first", d)
+	}
+	d = history.Entries[2].Delta
+	if d == nil || d.PreviousID != first.ID || d.ActionChanged || d.Confidence != 5 || d.HoursBetween != 48 {
+		t.Errorf("third delta = %+v, want the move from the recommendation it re-analyzed", d)
+	}
+}
+
+func TestNewRecommendationComparison(t *testing.T) {
+	now := time.Now()
+	current := historyRec(RecommendationActionSell, 60, -30, now)
+	if c := NewRecommendationComparison(nil, &current); c.Previous != nil || c.Delta != nil || c.Current != &current {
+		t.Errorf("comparison = %+v, want only the current recommendation", c)
+	}
+
+	previous := historyRec(RecommendationActionBuy, 80, 20, now.Add(-time.Hour))
+	c := NewRecommendationComparison(&previous, &current)
+	if c.Delta == nil || c.Delta.PreviousAction != RecommendationActionBuy || c.Delta.Confidence != -20 || c.Delta.FundamentalScore != -50 {
+		t.Errorf("delta = %+v, want the move from buy to sell", c.Delta)
+	}
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RecommendationDisclaimer is appended to shared recommendation summaries when no
+// configured disclaimer is attached to the recommendation
+const RecommendationDisclaimer = "Generated by Trade Machine's automated analysis. This is not financial advice; " +
+	"scores and price levels are model output and may be wrong or out of date. Do your own research before trading."
+
+// Markdown renders the recommendation as a self-contained Markdown summary for sharing
+func (r *Recommendation) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s: %s\n\n", r.Symbol, strings.ToUpper(string(r.Action)))
+	fmt.Fprintf(&b, "**Confidence:** %.0f%%  \n", r.Confidence)
+	fmt.Fprintf(&b, "**Status:** %s  \n", r.Status)
+	fmt.Fprintf(&b, "**Date:** %s\n\n", r.CreatedAt.UTC().Format("2006-01-02 15:04 MST"))
+	if r.TriggerReason != "" {
+		fmt.Fprintf(&b, "> Re-analysis: %s\n\n", r.TriggerReason)
+	}
+
+	b.WriteString("## Scores\n\n")
+	b.WriteString("| Agent | Score |\n")
+	b.WriteString("|---|---:|\n")
+	fmt.Fprintf(&b, "| Fundamental | %.1f |\n", r.FundamentalScore)
+	fmt.Fprintf(&b, "| Sentiment | %.1f |\n", r.SentimentScore)
+	fmt.Fprintf(&b, "| Technical | %.1f |\n", r.TechnicalScore)
+	if r.SocialScore != 0 {
+		fmt.Fprintf(&b, "| Social | %.1f |\n", r.SocialScore)
+	}
+	if r.InsiderScore != 0 {
+		fmt.Fprintf(&b, "| Insider | %.1f |\n", r.InsiderScore)
+	}
+	if r.MacroScore != 0 {
+		fmt.Fprintf(&b, "| Macro | %.1f |\n", r.MacroScore)
+	}
+	b.WriteString("\n")
+
+	if r.Reasoning != "" {
+		b.WriteString("## Reasoning\n\n")
+		b.WriteString(strings.TrimSpace(r.Reasoning))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString("## Key Factors\n\n")
+	if r.Quantity.IsPositive() {
+		fmt.Fprintf(&b, "- **Quantity:** %s shares\n", r.Quantity.String())
+	}
+	if r.EntryPrice.IsPositive() {
+		fmt.Fprintf(&b, "- **Entry:** $%s\n", r.EntryPrice.StringFixed(2))
+	}
+	if r.TargetPrice.IsPositive() {
+		fmt.Fprintf(&b, "- **Target:** $%s\n", r.TargetPrice.StringFixed(2))
+	}
+	if r.StopPrice.IsPositive() {
+		fmt.Fprintf(&b, "- **Stop:** $%s\n", r.StopPrice.StringFixed(2))
+	}
+	if r.RiskReward > 0 {
+		fmt.Fprintf(&b, "- **Risk/Reward:** %.2f\n", r.RiskReward)
+	}
+	fmt.Fprintf(&b, "- **Data completeness:** %.0f%%\n", r.DataCompleteness)
+	for _, m := range r.MissingAgents {
+		fmt.Fprintf(&b, "- **Missing %s analysis:** %s\n", m.AgentType, m.Reason)
+	}
+	b.WriteString("\n")
+
+	disclaimer := r.Disclaimer
+	if disclaimer == "" {
+		disclaimer = RecommendationDisclaimer
+	}
+	b.WriteString("---\n\n")
+	fmt.Fprintf(&b, "_%s_\n", disclaimer)
+
+	return b.String()
+}
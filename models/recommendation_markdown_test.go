@@ -0,0 +1,67 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRecommendation_Markdown(t *testing.T) {
+	rec := NewRecommendation("AAPL", RecommendationActionBuy, "Strong fundamentals and improving momentum.")
+	rec.Confidence = 78
+	rec.FundamentalScore = 65.5
+	rec.SentimentScore = 40
+	rec.TechnicalScore = 55.3
+	rec.Quantity = decimal.NewFromInt(10)
+	rec.EntryPrice = decimal.NewFromFloat(175.5)
+	rec.TargetPrice = decimal.NewFromInt(200)
+	rec.StopPrice = decimal.NewFromInt(165)
+	rec.RiskReward = 2.33
+	rec.DataCompleteness = 66.7
+	rec.MissingAgents = []MissingAgentInfo{{AgentType: AgentTypeNews, Reason: "timeout"}}
+	rec.CreatedAt = time.Date(2024, 3, 1, 14, 30, 0, 0, time.UTC)
+
+	md := rec.Markdown()
+
+	for _, want := range []string{
+		"# AAPL: BUY",
+		"**Confidence:** 78%",
+		"| Fundamental | 65.5 |",
+		"| Technical | 55.3 |",
+		"Strong fundamentals and improving momentum.",
+		"- **Entry:** $175.50",
+		"- **Risk/Reward:** 2.33",
+		"- **Missing news analysis:** timeout",
+		RecommendationDisclaimer,
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown() missing %q\n%s", want, md)
+		}
+	}
+}
+
+func TestRecommendation_Markdown_OmitsUnsetPrices(t *testing.T) {
+	rec := NewRecommendation("MSFT", RecommendationActionHold, "")
+
+	md := rec.Markdown()
+
+	if strings.Contains(md, "**Entry:**") || strings.Contains(md, "## Reasoning") {
+		t.Errorf("expected unset prices and empty reasoning to be omitted\n%s", md)
+	}
+}
+
+func TestRecommendation_Markdown_UsesAttachedDisclaimer(t *testing.T) {
+	rec := NewRecommendation("MSFT", RecommendationActionHold, "")
+	rec.Disclaimer = "Not investment advice within the meaning of MiFID II."
+
+	md := rec.Markdown()
+
+	if !strings.Contains(md, "_Not investment advice within the meaning of MiFID II._") {
+		t.Errorf("expected the attached disclaimer\n%s", md)
+	}
+	if strings.Contains(md, RecommendationDisclaimer) {
+		t.Errorf("expected the attached disclaimer to replace the default\n%s", md)
+	}
+}
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidOverride is returned when a draft edit is not valid for the recommendation
+var ErrInvalidOverride = errors.New("invalid recommendation edit")
+
+// ErrRiskRuleViolation is returned when an edited recommendation breaks a risk rule at approval
+var ErrRiskRuleViolation = errors.New("recommendation violates risk rules")
+
+// Order types a recommendation can be executed with
+const (
+	OrderTypeMarket = "market"
+	OrderTypeLimit  = "limit"
+)
+
+// RecommendationOverride holds a user's edits to a pending recommendation. The agent-suggested
+// values stay on the recommendation; approval and execution use the overrides when set.
+type RecommendationOverride struct {
+	Quantity   *decimal.Decimal `json:"quantity,omitempty"`
+	OrderType  string           `json:"order_type,omitempty"`
+	LimitPrice *decimal.Decimal `json:"limit_price,omitempty"` // Only kept for limit orders
+	EditedAt   time.Time        `json:"edited_at"`
+}
+
+// Edit merges a user's edit into the recommendation's overrides. Only pending recommendations
+// that trade can be edited; nil fields in edit keep their current values.
+func (r *Recommendation) Edit(edit RecommendationOverride) error {
+	if r.Status != RecommendationStatusPending || r.Action == RecommendationActionHold {
+		return fmt.Errorf("%w: %s %s recommendation is %s", ErrInvalidOverride, r.Action, r.Symbol, r.Status)
+	}
+
+	merged := RecommendationOverride{}
+	if r.Override != nil {
+		merged = *r.Override
+	}
+	if edit.Quantity != nil {
+		if !edit.Quantity.IsPositive() {
+			return fmt.Errorf("%w: quantity must be positive", ErrInvalidOverride)
+		}
+		merged.Quantity = edit.Quantity
+	}
+	if edit.OrderType != "" {
+		if edit.OrderType != OrderTypeMarket && edit.OrderType != OrderTypeLimit {
+			return fmt.Errorf("%w: order type must be %s or %s", ErrInvalidOverride, OrderTypeMarket, OrderTypeLimit)
+		}
+		merged.OrderType = edit.OrderType
+	}
+	if edit.LimitPrice != nil {
+		if !edit.LimitPrice.IsPositive() {
+			return fmt.Errorf("%w: limit price must be positive", ErrInvalidOverride)
+		}
+		merged.LimitPrice = edit.LimitPrice
+	}
+
+	if merged.OrderType == OrderTypeLimit && merged.LimitPrice == nil {
+		return fmt.Errorf("%w: limit orders need a limit price", ErrInvalidOverride)
+	}
+	if merged.OrderType != OrderTypeLimit {
+		merged.LimitPrice = nil
+	}
+
+	merged.EditedAt = time.Now()
+	r.Override = &merged
+	return nil
+}
+
+// EffectiveQuantity returns the user's quantity if edited, otherwise the suggested quantity
+func (r *Recommendation) EffectiveQuantity() decimal.Decimal {
+	if r.Override != nil && r.Override.Quantity != nil {
+		return *r.Override.Quantity
+	}
+	return r.Quantity
+}
+
+// EffectiveOrderType returns the user's order type if edited, otherwise a market order
+func (r *Recommendation) EffectiveOrderType() string {
+	if r.Override != nil && r.Override.OrderType != "" {
+		return r.Override.OrderType
+	}
+	return OrderTypeMarket
+}
+
+// EffectiveLimitPrice returns the limit price of an edited limit order, or nil
+func (r *Recommendation) EffectiveLimitPrice() *decimal.Decimal {
+	if r.EffectiveOrderType() != OrderTypeLimit {
+		return nil
+	}
+	return r.Override.LimitPrice
+}
+
+// RiskLimits are the position sizing rules an edited recommendation is checked against at approval
+type RiskLimits struct {
+	MaxPositionPercent     float64 // Largest buy or short as a fraction of portfolio value (0-1)
+	MaxShares              int64   // Largest quantity for a single order (0 = unlimited)
+	ShortMarginRequirement float64 // Buying power held per dollar shorted (0 = the order value)
+}
+
+// Check reports whether an order for quantity shares at price fits the limits. Account
+// limits are skipped when account is nil. Sells and covers are checked against MaxShares and,
+// when the account is known, capped at the shares held on the matching side of position,
+// which is nil when there is none.
+func (l RiskLimits) Check(action RecommendationAction, quantity, price decimal.Decimal, account *Account, position *Position) error {
+	if l.MaxShares > 0 && quantity.GreaterThan(decimal.NewFromInt(l.MaxShares)) {
+		return fmt.Errorf("%w: %s shares exceeds the %d share limit", ErrRiskRuleViolation, quantity, l.MaxShares)
+	}
+	if action == RecommendationActionShort && !quantity.Equal(quantity.Floor()) {
+		return fmt.Errorf("%w: short sales must be whole shares", ErrRiskRuleViolation)
+	}
+	if account != nil && (action == RecommendationActionSell || action == RecommendationActionCover) {
+		return checkHeldQuantity(action, quantity, position)
+	}
+	if (action != RecommendationActionBuy && action != RecommendationActionShort) || account == nil || !price.IsPositive() {
+		return nil
+	}
+	if action == RecommendationActionShort && !account.ShortingEnabled {
+		return fmt.Errorf("%w: shorting is not enabled on the account", ErrRiskRuleViolation)
+	}
+
+	value := quantity.Mul(price)
+	portfolioValue := account.PortfolioValue
+	if !portfolioValue.IsPositive() {
+		portfolioValue = account.Equity
+	}
+	if l.MaxPositionPercent > 0 && portfolioValue.IsPositive() {
+		maxValue := portfolioValue.Mul(decimal.NewFromFloat(l.MaxPositionPercent))
+		if value.GreaterThan(maxValue) {
+			return fmt.Errorf("%w: order value $%s exceeds %.0f%% of the portfolio ($%s)",
+				ErrRiskRuleViolation, value.StringFixed(2), l.MaxPositionPercent*100, maxValue.StringFixed(2))
+		}
+	}
+	if action == RecommendationActionShort && l.ShortMarginRequirement > 0 {
+		margin := value.Mul(decimal.NewFromFloat(l.ShortMarginRequirement))
+		if margin.GreaterThan(account.BuyingPower) {
+			return fmt.Errorf("%w: short margin $%s exceeds buying power ($%s)",
+				ErrRiskRuleViolation, margin.StringFixed(2), account.BuyingPower.StringFixed(2))
+		}
+		return nil
+	}
+	if value.GreaterThan(account.BuyingPower) {
+		return fmt.Errorf("%w: order value $%s exceeds buying power ($%s)",
+			ErrRiskRuleViolation, value.StringFixed(2), account.BuyingPower.StringFixed(2))
+	}
+	return nil
+}
+
+// checkHeldQuantity rejects a sell larger than the long position, or a cover larger than the
+// short position
+func checkHeldQuantity(action RecommendationAction, quantity decimal.Decimal, position *Position) error {
+	want, verb := PositionSideLong, "sell"
+	if action == RecommendationActionCover {
+		want, verb = PositionSideShort, "cover"
+	}
+
+	held := decimal.Zero
+	if position != nil && position.EffectiveSide() == want {
+		held = position.Quantity
+	}
+	if quantity.GreaterThan(held) {
+		return fmt.Errorf("%w: cannot %s %s shares with %s %s shares held", ErrRiskRuleViolation, verb, quantity, held, want)
+	}
+	return nil
+}
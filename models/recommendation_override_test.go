@@ -0,0 +1,137 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func decimalPtr(v int64) *decimal.Decimal {
+	d := decimal.NewFromInt(v)
+	return &d
+}
+
+func TestRecommendation_Edit(t *testing.T) {
+	rec := NewRecommendation("AAPL", RecommendationActionBuy, "test")
+	rec.Quantity = decimal.NewFromInt(10)
+
+	if err := rec.Edit(RecommendationOverride{Quantity: decimalPtr(5)}); err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	if !rec.EffectiveQuantity().Equal(decimal.NewFromInt(5)) || !rec.Quantity.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("quantity = %s (suggested %s), want the edit kept apart from the suggestion", rec.EffectiveQuantity(), rec.Quantity)
+	}
+	if rec.EffectiveOrderType() != OrderTypeMarket {
+		t.Errorf("EffectiveOrderType() = %s, want market", rec.EffectiveOrderType())
+	}
+
+	if err := rec.Edit(RecommendationOverride{OrderType: OrderTypeLimit}); !errors.Is(err, ErrInvalidOverride) {
+		t.Errorf("Edit() limit without price error = %v, want ErrInvalidOverride", err)
+	}
+	if err := rec.Edit(RecommendationOverride{OrderType: OrderTypeLimit, LimitPrice: decimalPtr(150)}); err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	if price := rec.EffectiveLimitPrice(); price == nil || !price.Equal(decimal.NewFromInt(150)) {
+		t.Errorf("EffectiveLimitPrice() = %v, want 150", price)
+	}
+	if !rec.EffectiveQuantity().Equal(decimal.NewFromInt(5)) {
+		t.Error("earlier quantity edit should be kept when editing the order type")
+	}
+
+	// Switching back to market drops the limit price
+	if err := rec.Edit(RecommendationOverride{OrderType: OrderTypeMarket}); err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	if rec.EffectiveLimitPrice() != nil {
+		t.Error("market orders should have no limit price")
+	}
+
+	for name, edit := range map[string]RecommendationOverride{
+		"zero quantity":      {Quantity: decimalPtr(0)},
+		"unknown order type": {OrderType: "stop"},
+		"negative limit":     {OrderType: OrderTypeLimit, LimitPrice: decimalPtr(-1)},
+	} {
+		if err := rec.Edit(edit); !errors.Is(err, ErrInvalidOverride) {
+			t.Errorf("%s: Edit() error = %v, want ErrInvalidOverride", name, err)
+		}
+	}
+
+	rec.Approve()
+	if err := rec.Edit(RecommendationOverride{Quantity: decimalPtr(1)}); !errors.Is(err, ErrInvalidOverride) {
+		t.Errorf("Edit() on approved recommendation error = %v, want ErrInvalidOverride", err)
+	}
+}
+
+func TestRiskLimits_Check(t *testing.T) {
+	limits := RiskLimits{MaxPositionPercent: 0.10, MaxShares: 100, ShortMarginRequirement: 1.5}
+	account := &Account{PortfolioValue: decimal.NewFromInt(100000), BuyingPower: decimal.NewFromInt(5000), ShortingEnabled: true}
+	price := decimal.NewFromInt(100)
+	long := &Position{Symbol: "AAPL", Quantity: decimal.NewFromInt(90), Side: PositionSideLong}
+	short := &Position{Symbol: "AAPL", Quantity: decimal.NewFromInt(90), Side: PositionSideShort}
+
+	tests := []struct {
+		name     string
+		action   RecommendationAction
+		quantity int64
+		account  *Account
+		position *Position
+		wantErr  bool
+	}{
+		{"within limits", RecommendationActionBuy, 40, account, nil, false},
+		{"over share limit", RecommendationActionSell, 150, account, long, true},
+		{"over buying power", RecommendationActionBuy, 60, account, nil, true},
+		{"over position percent", RecommendationActionBuy, 60, &Account{PortfolioValue: decimal.NewFromInt(50000), BuyingPower: decimal.NewFromInt(50000)}, nil, true},
+		{"sell ignores account limits", RecommendationActionSell, 90, account, long, false},
+		{"sell more than held", RecommendationActionSell, 95, account, long, true},
+		{"sell without a position", RecommendationActionSell, 10, account, nil, true},
+		{"sell against a short", RecommendationActionSell, 10, account, short, true},
+		{"no account", RecommendationActionBuy, 90, nil, nil, false},
+		{"sell without account", RecommendationActionSell, 95, nil, nil, false},
+		{"short within margin", RecommendationActionShort, 30, account, nil, false},
+		{"short over margin", RecommendationActionShort, 40, account, nil, true},
+		{"shorting disabled", RecommendationActionShort, 10, &Account{PortfolioValue: decimal.NewFromInt(100000), BuyingPower: decimal.NewFromInt(5000)}, nil, true},
+		{"cover ignores account limits", RecommendationActionCover, 90, account, short, false},
+		{"cover more than short", RecommendationActionCover, 95, account, short, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := limits.Check(tt.action, decimal.NewFromInt(tt.quantity), price, tt.account, tt.position)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrRiskRuleViolation) {
+				t.Errorf("Check() error = %v, want ErrRiskRuleViolation", err)
+			}
+		})
+	}
+}
+
+func TestRiskLimits_Check_FractionalShort(t *testing.T) {
+	err := RiskLimits{}.Check(RecommendationActionShort, decimal.NewFromFloat(2.5), decimal.NewFromInt(100), nil, nil)
+	if !errors.Is(err, ErrRiskRuleViolation) {
+		t.Errorf("Check() error = %v, want ErrRiskRuleViolation for a fractional short", err)
+	}
+}
+
+func TestShortAvailability_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		avail    ShortAvailability
+		allowHTB bool
+		wantErr  bool
+	}{
+		{"easy to borrow", ShortAvailability{Symbol: "AAPL", Shortable: true, EasyToBorrow: true}, false, false},
+		{"not shortable", ShortAvailability{Symbol: "GME", EasyToBorrow: true}, true, true},
+		{"hard to borrow", ShortAvailability{Symbol: "GME", Shortable: true}, false, true},
+		{"hard to borrow allowed", ShortAvailability{Symbol: "GME", Shortable: true}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.avail.Check(tt.allowHTB)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrRiskRuleViolation)) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
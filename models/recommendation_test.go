@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -88,6 +89,34 @@ func TestRecommendation_MarkExecuted(t *testing.T) {
 	}
 }
 
+func TestRecommendation_Executable(t *testing.T) {
+	tests := []struct {
+		name     string
+		action   RecommendationAction
+		status   RecommendationStatus
+		quantity int64
+		want     bool
+	}{
+		{"pending buy", RecommendationActionBuy, RecommendationStatusPending, 10, true},
+		{"approved sell", RecommendationActionSell, RecommendationStatusApproved, 5, true},
+		{"hold", RecommendationActionHold, RecommendationStatusPending, 10, false},
+		{"zero quantity", RecommendationActionBuy, RecommendationStatusPending, 0, false},
+		{"rejected", RecommendationActionBuy, RecommendationStatusRejected, 10, false},
+		{"already executed", RecommendationActionBuy, RecommendationStatusExecuted, 10, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := NewRecommendation("AAPL", tt.action, "test")
+			rec.Status = tt.status
+			rec.Quantity = decimal.NewFromInt(tt.quantity)
+			if got := rec.Executable(); got != tt.want {
+				t.Errorf("Executable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRecommendationAction_Constants(t *testing.T) {
 	actions := map[RecommendationAction]string{
 		RecommendationActionBuy:  "buy",
@@ -167,3 +196,107 @@ func TestRecommendation_Timestamps(t *testing.T) {
 		t.Errorf("ApprovedAt = %v, should be between %v and %v", rec.ApprovedAt, beforeApprove, afterApprove)
 	}
 }
+
+func TestRecommendation_CalculateRiskReward(t *testing.T) {
+	tests := []struct {
+		name   string
+		action RecommendationAction
+		entry  float64
+		target float64
+		stop   float64
+		want   float64
+	}{
+		{"buy 2:1", RecommendationActionBuy, 100, 110, 95, 2.0},
+		{"sell 3:1", RecommendationActionSell, 100, 85, 105, 3.0},
+		{"short 3:1", RecommendationActionShort, 100, 85, 105, 3.0},
+		{"cover 2:1", RecommendationActionCover, 100, 110, 95, 2.0},
+		{"hold has no ratio", RecommendationActionHold, 100, 110, 95, 0},
+		{"missing stop", RecommendationActionBuy, 100, 110, 0, 0},
+		{"buy target below entry", RecommendationActionBuy, 100, 90, 95, 0},
+		{"buy stop above entry", RecommendationActionBuy, 100, 110, 105, 0},
+		{"rounded to two places", RecommendationActionBuy, 100, 110, 97, 3.33},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := NewRecommendation("AAPL", tt.action, "test")
+			rec.EntryPrice = decimal.NewFromFloat(tt.entry)
+			rec.TargetPrice = decimal.NewFromFloat(tt.target)
+			rec.StopPrice = decimal.NewFromFloat(tt.stop)
+
+			if got := rec.CalculateRiskReward(); got != tt.want {
+				t.Errorf("CalculateRiskReward() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecommendation_Bracket(t *testing.T) {
+	tests := []struct {
+		name             string
+		action           RecommendationAction
+		price            float64
+		target           float64
+		stop             float64
+		wantStop, wantTP float64 // 0 for no bracket
+	}{
+		{"buy uses its levels", RecommendationActionBuy, 100, 110, 95, 95, 110},
+		{"short uses its levels", RecommendationActionShort, 100, 85, 105, 105, 85},
+		{"buy without levels falls back to percentages", RecommendationActionBuy, 100, 0, 0, 95, 110},
+		{"short without levels falls back to percentages", RecommendationActionShort, 100, 0, 0, 105, 90},
+		{"levels the fill price moved past fall back", RecommendationActionBuy, 112, 110, 95, 106.4, 123.2},
+		{"sell gets no bracket", RecommendationActionSell, 100, 85, 105, 0, 0},
+		{"cover gets no bracket", RecommendationActionCover, 100, 110, 95, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := NewRecommendation("AAPL", tt.action, "test")
+			rec.TargetPrice = decimal.NewFromFloat(tt.target)
+			rec.StopPrice = decimal.NewFromFloat(tt.stop)
+
+			b := rec.Bracket(decimal.NewFromFloat(tt.price), 0.05, 0.10)
+			if tt.wantStop == 0 {
+				if b != nil {
+					t.Errorf("Bracket() = %+v, want nil", b)
+				}
+				return
+			}
+			if b == nil || !b.StopLoss.Equal(decimal.NewFromFloat(tt.wantStop)) || !b.TakeProfit.Equal(decimal.NewFromFloat(tt.wantTP)) {
+				t.Errorf("Bracket() = %+v, want stop %v and take profit %v", b, tt.wantStop, tt.wantTP)
+			}
+		})
+	}
+}
+
+func TestParseRecommendationStatus(t *testing.T) {
+	for _, s := range []string{"", "pending", "EXPIRED", " executed "} {
+		if _, err := ParseRecommendationStatus(s); err != nil {
+			t.Errorf("ParseRecommendationStatus(%q) = %v, want no error", s, err)
+		}
+	}
+	if status, _ := ParseRecommendationStatus("Expired"); status != RecommendationStatusExpired {
+		t.Errorf("status = %q, want expired", status)
+	}
+	if _, err := ParseRecommendationStatus("stale"); !errors.Is(err, ErrInvalidRecommendationStatus) {
+		t.Errorf("expected ErrInvalidRecommendationStatus, got %v", err)
+	}
+}
+
+func TestRecommendation_PriceDeviationPercent(t *testing.T) {
+	rec := NewRecommendation("AAPL", RecommendationActionBuy, "test")
+	if got := rec.PriceDeviationPercent(decimal.NewFromInt(100)); got != 0 {
+		t.Errorf("deviation without an entry price = %v, want 0", got)
+	}
+
+	rec.EntryPrice = decimal.NewFromInt(100)
+	if got := rec.PriceDeviationPercent(decimal.NewFromInt(88)); got != 12 {
+		t.Errorf("deviation = %v, want 12", got)
+	}
+
+	limit := decimal.NewFromInt(80)
+	rec.Override = &RecommendationOverride{OrderType: OrderTypeLimit, LimitPrice: &limit}
+	if got := rec.PriceDeviationPercent(decimal.NewFromInt(88)); got != 10 {
+		t.Errorf("deviation from the limit price = %v, want 10", got)
+	}
+}
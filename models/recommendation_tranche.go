@@ -0,0 +1,197 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidSplitPlan is returned when a split plan can't be applied to the recommendation
+var ErrInvalidSplitPlan = errors.New("invalid split plan")
+
+// MaxSplitTranches caps how many child orders a recommendation can be split into
+const MaxSplitTranches = 10
+
+// SplitTrigger decides when each tranche of a split plan is executed
+type SplitTrigger string
+
+const (
+	// SplitTriggerTime executes the first tranche right away and the rest at fixed intervals
+	SplitTriggerTime SplitTrigger = "time"
+	// SplitTriggerPrice executes each tranche once the price reaches its level
+	SplitTriggerPrice SplitTrigger = "price"
+)
+
+// SplitPlan describes how an approved recommendation scales into (or out of) a position
+// over several child orders instead of one
+type SplitPlan struct {
+	Trigger         SplitTrigger      `json:"trigger"`
+	Count           int               `json:"count,omitempty"`            // Number of tranches for time plans; price plans use one per level
+	IntervalMinutes int               `json:"interval_minutes,omitempty"` // Time between tranches for time plans
+	PriceLevels     []decimal.Decimal `json:"price_levels,omitempty"`     // Trigger price of each tranche for price plans
+}
+
+// TrancheStatus is the state of one child order of a split plan
+type TrancheStatus string
+
+const (
+	TrancheStatusPending    TrancheStatus = "pending"    // Waiting for its time or price
+	TrancheStatusSubmitting TrancheStatus = "submitting" // Claimed while its order is placed with the broker
+	TrancheStatusSubmitted  TrancheStatus = "submitted"  // Order placed; the trade records the fill
+	TrancheStatusFailed     TrancheStatus = "failed"     // The order was not accepted and won't be retried
+	TrancheStatusCancelled  TrancheStatus = "cancelled"  // Dropped before it was due
+)
+
+// RecommendationTranche is one child order of a recommendation approved with a split plan
+type RecommendationTranche struct {
+	ID               uuid.UUID        `json:"id"`
+	RecommendationID uuid.UUID        `json:"recommendation_id"`
+	Sequence         int              `json:"sequence"` // 1-based position in the plan
+	Quantity         decimal.Decimal  `json:"quantity"`
+	TriggerPrice     *decimal.Decimal `json:"trigger_price,omitempty"` // Set for price plans
+	ScheduledAt      *time.Time       `json:"scheduled_at,omitempty"`  // Set for time plans
+	Status           TrancheStatus    `json:"status"`
+	TradeID          *uuid.UUID       `json:"trade_id,omitempty"`
+	Error            string           `json:"error,omitempty"` // Why the order failed
+	SubmittedAt      *time.Time       `json:"submitted_at,omitempty"`
+	CreatedAt        time.Time        `json:"created_at"`
+	TradeStatus      TradeStatus      `json:"trade_status,omitempty"` // Read from the tranche's trade; not stored
+	FillPrice        decimal.Decimal  `json:"fill_price,omitempty"`   // Read from the tranche's trade once filled; not stored
+}
+
+// Tranches splits quantity into the plan's child orders for recommendation recID. Whole
+// shares are spread as evenly as possible, earlier tranches taking the remainder, and any
+// fractional share goes to the last tranche. Time plans start at now.
+func (p SplitPlan) Tranches(recID uuid.UUID, quantity decimal.Decimal, now time.Time) ([]RecommendationTranche, error) {
+	count := p.Count
+	switch p.Trigger {
+	case SplitTriggerTime:
+		if p.IntervalMinutes < 1 {
+			return nil, fmt.Errorf("%w: interval must be at least one minute", ErrInvalidSplitPlan)
+		}
+		if len(p.PriceLevels) > 0 {
+			return nil, fmt.Errorf("%w: time plans don't take price levels", ErrInvalidSplitPlan)
+		}
+	case SplitTriggerPrice:
+		if p.Count != 0 && p.Count != len(p.PriceLevels) {
+			return nil, fmt.Errorf("%w: %d tranches but %d price levels", ErrInvalidSplitPlan, p.Count, len(p.PriceLevels))
+		}
+		count = len(p.PriceLevels)
+		for _, level := range p.PriceLevels {
+			if !level.IsPositive() {
+				return nil, fmt.Errorf("%w: price levels must be positive", ErrInvalidSplitPlan)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: trigger must be %s or %s", ErrInvalidSplitPlan, SplitTriggerTime, SplitTriggerPrice)
+	}
+	if count < 2 || count > MaxSplitTranches {
+		return nil, fmt.Errorf("%w: split into 2 to %d tranches", ErrInvalidSplitPlan, MaxSplitTranches)
+	}
+
+	whole := quantity.Floor()
+	n := decimal.NewFromInt(int64(count))
+	if whole.LessThan(n) {
+		return nil, fmt.Errorf("%w: %s shares can't fill %d tranches of at least one share", ErrInvalidSplitPlan, quantity, count)
+	}
+	base := whole.Div(n).Floor()
+	remainder := whole.Sub(base.Mul(n)).IntPart()
+
+	tranches := make([]RecommendationTranche, count)
+	for i := range tranches {
+		qty := base
+		if int64(i) < remainder {
+			qty = qty.Add(decimal.NewFromInt(1))
+		}
+		if i == count-1 {
+			qty = qty.Add(quantity.Sub(whole))
+		}
+		tranche := RecommendationTranche{
+			ID:               uuid.New(),
+			RecommendationID: recID,
+			Sequence:         i + 1,
+			Quantity:         qty,
+			Status:           TrancheStatusPending,
+			CreatedAt:        now,
+		}
+		if p.Trigger == SplitTriggerPrice {
+			level := p.PriceLevels[i]
+			tranche.TriggerPrice = &level
+		} else {
+			at := now.Add(time.Duration(i*p.IntervalMinutes) * time.Minute)
+			tranche.ScheduledAt = &at
+		}
+		tranches[i] = tranche
+	}
+	return tranches, nil
+}
+
+// Due reports whether a pending tranche should be executed now. Price tranches of buys and
+// covers are due once price falls to their level, and of sells and shorts once it rises to it.
+func (t *RecommendationTranche) Due(action RecommendationAction, now time.Time, price decimal.Decimal) bool {
+	if t.Status != TrancheStatusPending {
+		return false
+	}
+	if t.TriggerPrice != nil {
+		if !price.IsPositive() {
+			return false
+		}
+		if action.TradeSide() == TradeSideBuy {
+			return price.LessThanOrEqual(*t.TriggerPrice)
+		}
+		return price.GreaterThanOrEqual(*t.TriggerPrice)
+	}
+	return t.ScheduledAt != nil && !now.Before(*t.ScheduledAt)
+}
+
+// Filled reports whether the broker has filled the tranche's order
+func (t *RecommendationTranche) Filled() bool {
+	return t.Status == TrancheStatusSubmitted && t.TradeStatus == TradeStatusExecuted
+}
+
+// SplitProgress summarizes how far a recommendation's split plan has executed
+type SplitProgress struct {
+	Tranches          []RecommendationTranche `json:"tranches"`
+	TotalQuantity     decimal.Decimal         `json:"total_quantity"`
+	SubmittedQuantity decimal.Decimal         `json:"submitted_quantity"`
+	FilledQuantity    decimal.Decimal         `json:"filled_quantity"`
+	AvgFillPrice      decimal.Decimal         `json:"avg_fill_price"` // Weighted by filled quantity; zero until a tranche fills
+	Pending           int                     `json:"pending"`        // Tranches still waiting for their time or price, or being placed
+	Complete          bool                    `json:"complete"`       // No tranche is left waiting
+}
+
+// NewSplitProgress totals tranches, which are expected in sequence order
+func NewSplitProgress(tranches []RecommendationTranche) SplitProgress {
+	progress := SplitProgress{Tranches: tranches}
+	filledValue := decimal.Zero
+	for _, t := range tranches {
+		progress.TotalQuantity = progress.TotalQuantity.Add(t.Quantity)
+		switch t.Status {
+		case TrancheStatusPending, TrancheStatusSubmitting:
+			progress.Pending++
+		case TrancheStatusSubmitted:
+			progress.SubmittedQuantity = progress.SubmittedQuantity.Add(t.Quantity)
+			if t.Filled() {
+				progress.FilledQuantity = progress.FilledQuantity.Add(t.Quantity)
+				filledValue = filledValue.Add(t.Quantity.Mul(t.FillPrice))
+			}
+		}
+	}
+	if progress.FilledQuantity.IsPositive() {
+		progress.AvgFillPrice = filledValue.Div(progress.FilledQuantity).Round(4)
+	}
+	progress.Complete = progress.Pending == 0
+	return progress
+}
+
+// FilledPercent returns the share of the plan's quantity filled so far, 0-100
+func (p SplitProgress) FilledPercent() float64 {
+	if !p.TotalQuantity.IsPositive() {
+		return 0
+	}
+	percent, _ := p.FilledQuantity.Div(p.TotalQuantity).Mul(decimal.NewFromInt(100)).Round(1).Float64()
+	return percent
+}
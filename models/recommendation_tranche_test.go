@@ -0,0 +1,122 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestSplitPlan_Tranches(t *testing.T) {
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	recID := uuid.New()
+
+	tranches, err := SplitPlan{Trigger: SplitTriggerTime, Count: 3, IntervalMinutes: 30}.Tranches(recID, decimal.RequireFromString("10.5"), now)
+	if err != nil {
+		t.Fatalf("Tranches() error = %v", err)
+	}
+	wantQty := []string{"4", "3", "3.5"}
+	for i, tr := range tranches {
+		if !tr.Quantity.Equal(decimal.RequireFromString(wantQty[i])) {
+			t.Errorf("tranche %d quantity = %s, want %s", i+1, tr.Quantity, wantQty[i])
+		}
+		if tr.Sequence != i+1 || tr.RecommendationID != recID || tr.Status != TrancheStatusPending {
+			t.Errorf("tranche %d = %+v, want pending in sequence for the recommendation", i+1, tr)
+		}
+		if want := now.Add(time.Duration(i*30) * time.Minute); tr.ScheduledAt == nil || !tr.ScheduledAt.Equal(want) || tr.TriggerPrice != nil {
+			t.Errorf("tranche %d scheduled at %v, want %v", i+1, tr.ScheduledAt, want)
+		}
+	}
+
+	levels := []decimal.Decimal{decimal.NewFromInt(100), decimal.NewFromInt(95)}
+	tranches, err = SplitPlan{Trigger: SplitTriggerPrice, PriceLevels: levels}.Tranches(recID, decimal.NewFromInt(10), now)
+	if err != nil {
+		t.Fatalf("Tranches() error = %v", err)
+	}
+	if len(tranches) != 2 || !tranches[1].TriggerPrice.Equal(levels[1]) || tranches[1].ScheduledAt != nil {
+		t.Errorf("price plan tranches = %+v, want one per level", tranches)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		plan     SplitPlan
+		quantity string
+	}{
+		{"unknown trigger", SplitPlan{Trigger: "volume", Count: 2}, "10"},
+		{"one tranche", SplitPlan{Trigger: SplitTriggerTime, Count: 1, IntervalMinutes: 5}, "10"},
+		{"too many tranches", SplitPlan{Trigger: SplitTriggerTime, Count: MaxSplitTranches + 1, IntervalMinutes: 5}, "100"},
+		{"no interval", SplitPlan{Trigger: SplitTriggerTime, Count: 2}, "10"},
+		{"count mismatch", SplitPlan{Trigger: SplitTriggerPrice, Count: 3, PriceLevels: levels}, "10"},
+		{"non-positive level", SplitPlan{Trigger: SplitTriggerPrice, PriceLevels: []decimal.Decimal{decimal.NewFromInt(100), decimal.Zero}}, "10"},
+		{"fewer shares than tranches", SplitPlan{Trigger: SplitTriggerTime, Count: 5, IntervalMinutes: 5}, "4.9"},
+	} {
+		if _, err := tt.plan.Tranches(recID, decimal.RequireFromString(tt.quantity), now); !errors.Is(err, ErrInvalidSplitPlan) {
+			t.Errorf("%s: error = %v, want ErrInvalidSplitPlan", tt.name, err)
+		}
+	}
+}
+
+func TestRecommendationTranche_Due(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Minute)
+	level := decimal.NewFromInt(100)
+	scheduled := RecommendationTranche{Status: TrancheStatusPending, ScheduledAt: &later}
+	priced := RecommendationTranche{Status: TrancheStatusPending, TriggerPrice: &level}
+
+	for _, tt := range []struct {
+		name    string
+		tranche RecommendationTranche
+		action  RecommendationAction
+		now     time.Time
+		price   int64
+		want    bool
+	}{
+		{"before schedule", scheduled, RecommendationActionBuy, now, 0, false},
+		{"at schedule", scheduled, RecommendationActionBuy, later, 0, true},
+		{"buy above level", priced, RecommendationActionBuy, now, 101, false},
+		{"buy at level", priced, RecommendationActionBuy, now, 100, true},
+		{"cover below level", priced, RecommendationActionCover, now, 99, true},
+		{"sell below level", priced, RecommendationActionSell, now, 99, false},
+		{"short above level", priced, RecommendationActionShort, now, 101, true},
+		{"no price", priced, RecommendationActionBuy, now, 0, false},
+	} {
+		if got := tt.tranche.Due(tt.action, tt.now, decimal.NewFromInt(tt.price)); got != tt.want {
+			t.Errorf("%s: Due() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	submitted := scheduled
+	submitted.Status = TrancheStatusSubmitted
+	if submitted.Due(RecommendationActionBuy, later, decimal.Zero) {
+		t.Error("submitted tranches should never be due again")
+	}
+}
+
+func TestNewSplitProgress(t *testing.T) {
+	progress := NewSplitProgress([]RecommendationTranche{
+		{Quantity: decimal.NewFromInt(4), Status: TrancheStatusSubmitted, TradeStatus: TradeStatusExecuted, FillPrice: decimal.NewFromInt(100)},
+		{Quantity: decimal.NewFromInt(4), Status: TrancheStatusSubmitted, TradeStatus: TradeStatusExecuted, FillPrice: decimal.NewFromInt(94)},
+		{Quantity: decimal.NewFromInt(2), Status: TrancheStatusSubmitted, TradeStatus: TradeStatusPending},
+		{Quantity: decimal.NewFromInt(5), Status: TrancheStatusPending},
+		{Quantity: decimal.NewFromInt(5), Status: TrancheStatusFailed},
+	})
+
+	if !progress.TotalQuantity.Equal(decimal.NewFromInt(20)) || !progress.SubmittedQuantity.Equal(decimal.NewFromInt(10)) || !progress.FilledQuantity.Equal(decimal.NewFromInt(8)) {
+		t.Errorf("quantities = %s total, %s submitted, %s filled, want 20, 10, 8", progress.TotalQuantity, progress.SubmittedQuantity, progress.FilledQuantity)
+	}
+	if !progress.AvgFillPrice.Equal(decimal.NewFromInt(97)) {
+		t.Errorf("AvgFillPrice = %s, want 97", progress.AvgFillPrice)
+	}
+	if progress.Pending != 1 || progress.Complete {
+		t.Errorf("Pending = %d, Complete = %v, want 1 pending and incomplete", progress.Pending, progress.Complete)
+	}
+	if got := progress.FilledPercent(); got != 40 {
+		t.Errorf("FilledPercent() = %v, want 40", got)
+	}
+
+	if empty := NewSplitProgress(nil); !empty.Complete || empty.FilledPercent() != 0 {
+		t.Errorf("empty progress = %+v, want complete with nothing filled", empty)
+	}
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Broker activity types reconciled against the local books
+const (
+	BrokerActivityFill = "FILL"
+	BrokerActivityFee  = "FEE"
+)
+
+// Broker activity types that move cash into or out of the account
+const (
+	BrokerActivityDeposit    = "CSD"
+	BrokerActivityWithdrawal = "CSW"
+)
+
+// BrokerActivity is a single entry from the broker's account activity history
+type BrokerActivity struct {
+	ID              string          `json:"id"`
+	Type            string          `json:"type"` // FILL, FEE, or another broker activity type
+	Symbol          string          `json:"symbol,omitempty"`
+	Side            TradeSide       `json:"side,omitempty"`
+	Quantity        decimal.Decimal `json:"quantity"`
+	Price           decimal.Decimal `json:"price"`
+	NetAmount       decimal.Decimal `json:"net_amount"`
+	OrderID         string          `json:"order_id,omitempty"`
+	Description     string          `json:"description,omitempty"`
+	TransactionTime time.Time       `json:"transaction_time"`
+}
+
+// FillMismatch is a broker order whose filled quantity or average price differs from the local trade
+type FillMismatch struct {
+	OrderID        string          `json:"order_id"`
+	Symbol         string          `json:"symbol"`
+	TradeID        uuid.UUID       `json:"trade_id"`
+	LocalQuantity  decimal.Decimal `json:"local_quantity"`
+	BrokerQuantity decimal.Decimal `json:"broker_quantity"`
+	LocalPrice     decimal.Decimal `json:"local_price"`
+	BrokerPrice    decimal.Decimal `json:"broker_price"`
+}
+
+// FeeDiscrepancy compares locally recorded commissions with fees charged by the broker for a symbol
+type FeeDiscrepancy struct {
+	Symbol     string          `json:"symbol"` // Empty for fees the broker did not attribute to a symbol
+	LocalFees  decimal.Decimal `json:"local_fees"`
+	BrokerFees decimal.Decimal `json:"broker_fees"`
+}
+
+// PositionDrift is a symbol whose local quantity differs from the broker's
+type PositionDrift struct {
+	Symbol         string          `json:"symbol"`
+	LocalQuantity  decimal.Decimal `json:"local_quantity"`
+	BrokerQuantity decimal.Decimal `json:"broker_quantity"`
+}
+
+// Difference returns the broker quantity minus the local quantity
+func (d PositionDrift) Difference() decimal.Decimal {
+	return d.BrokerQuantity.Sub(d.LocalQuantity)
+}
+
+// CashFlowComparison compares net cash moved by trades and fees over the period
+type CashFlowComparison struct {
+	Local  decimal.Decimal `json:"local"`
+	Broker decimal.Decimal `json:"broker"`
+}
+
+// Difference returns the broker cash flow minus the local cash flow
+func (c CashFlowComparison) Difference() decimal.Decimal {
+	return c.Broker.Sub(c.Local)
+}
+
+// ReconciliationReport lists where the local books disagree with the broker for a period
+type ReconciliationReport struct {
+	ID                   uuid.UUID          `json:"id"`
+	PeriodStart          time.Time          `json:"period_start"`
+	PeriodEnd            time.Time          `json:"period_end"`
+	UnmatchedBrokerFills []BrokerActivity   `json:"unmatched_broker_fills"` // Broker fills with no local trade
+	UnmatchedTrades      []Trade            `json:"unmatched_trades"`       // Executed local trades with no broker fill
+	FillMismatches       []FillMismatch     `json:"fill_mismatches"`
+	FeeDiscrepancies     []FeeDiscrepancy   `json:"fee_discrepancies"`
+	PositionDrift        []PositionDrift    `json:"position_drift"` // As of when the report ran
+	CashFlow             CashFlowComparison `json:"cash_flow"`
+	Partial              bool               `json:"partial"` // Ran before the period ended, so later activity is missing
+	CreatedAt            time.Time          `json:"created_at"`
+	Disclaimer           string             `json:"disclaimer,omitempty"` // Compliance text attached when served; not stored
+}
+
+// NewReconciliationReport creates an empty report for the calendar month containing month,
+// marked partial when the month hasn't ended yet
+func NewReconciliationReport(month time.Time) *ReconciliationReport {
+	start, end := MonthBounds(month)
+	now := time.Now()
+	return &ReconciliationReport{
+		ID:          uuid.New(),
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Partial:     now.Before(end),
+		CreatedAt:   now,
+	}
+}
+
+// Complete reports whether the report ran after its period ended, so it covers all of it.
+// Reports saved before Partial was recorded are judged by when they were created.
+func (r *ReconciliationReport) Complete() bool {
+	return !r.Partial && !r.CreatedAt.Before(r.PeriodEnd)
+}
+
+// DiscrepancyCount returns the number of discrepancies in the report
+func (r *ReconciliationReport) DiscrepancyCount() int {
+	count := len(r.UnmatchedBrokerFills) + len(r.UnmatchedTrades) + len(r.FillMismatches) +
+		len(r.FeeDiscrepancies) + len(r.PositionDrift)
+	if !r.CashFlow.Difference().IsZero() {
+		count++
+	}
+	return count
+}
+
+// Clean reports whether the local books fully agree with the broker
+func (r *ReconciliationReport) Clean() bool {
+	return r.DiscrepancyCount() == 0
+}
+
+// MonthBounds returns the start of the calendar month containing t and the start of
+// the following month, both in UTC
+func MonthBounds(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
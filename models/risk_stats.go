@@ -0,0 +1,181 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrInsufficientHistory is returned when there are too few daily bars to estimate risk
+var ErrInsufficientHistory = errors.New("insufficient price history")
+
+const (
+	// tradingDaysPerYear annualizes daily volatility
+	tradingDaysPerYear = 252
+	// MinRiskObservations is the fewest daily returns risk stats are computed from
+	MinRiskObservations = 20
+)
+
+// DailyClose is a symbol's closing price on one trading day
+type DailyClose struct {
+	Date  time.Time
+	Close float64
+}
+
+// RiskStats summarizes a symbol's trailing price risk from its daily closes
+type RiskStats struct {
+	Symbol       string    `json:"symbol"`
+	Benchmark    string    `json:"benchmark"`
+	AsOf         time.Time `json:"as_of"`         // Trading day of the last close used
+	LookbackDays int       `json:"lookback_days"` // Calendar days of bars requested
+	Observations int       `json:"observations"`  // Daily returns the stats are computed from
+	Volatility   float64   `json:"volatility"`    // Annualized standard deviation of daily log returns, 0.25 = 25%
+	Beta         *float64  `json:"beta"`          // Against the benchmark; nil when too few days overlap
+	MaxDrawdown  float64   `json:"max_drawdown"`  // Largest peak-to-trough decline of the closes, 0.30 = 30%
+	ComputedAt   time.Time `json:"computed_at"`
+}
+
+// ComputeRiskStats computes trailing volatility, max drawdown and beta from daily closes in
+// date order. Beta pairs the symbol's returns with the benchmark's on the days both traded;
+// it is left nil when fewer than MinRiskObservations pairs remain.
+func ComputeRiskStats(symbol string, closes, benchmark []DailyClose) (*RiskStats, error) {
+	returns := logReturns(closes)
+	if len(returns) < MinRiskObservations {
+		return nil, fmt.Errorf("%w: %d daily returns for %s, need %d", ErrInsufficientHistory, len(returns), symbol, MinRiskObservations)
+	}
+
+	stats := &RiskStats{
+		Symbol:       symbol,
+		AsOf:         closes[len(closes)-1].Date,
+		Observations: len(returns),
+		Volatility:   stdev(returns) * math.Sqrt(tradingDaysPerYear),
+		MaxDrawdown:  maxDrawdown(closes),
+	}
+
+	symReturns, benchReturns := pairedReturns(closes, benchmark)
+	if len(symReturns) >= MinRiskObservations {
+		if v := variance(benchReturns); v > 0 {
+			beta := covariance(symReturns, benchReturns) / v
+			stats.Beta = &beta
+		}
+	}
+
+	return stats, nil
+}
+
+// VolatilityScale returns the fraction of a full-size position to take so the position
+// runs at no more than the target annualized volatility. It never scales up, and returns
+// 1 when target is zero (disabled) or the volatility is unknown.
+func (s *RiskStats) VolatilityScale(target float64) float64 {
+	if s == nil || target <= 0 || s.Volatility <= 0 || s.Volatility <= target {
+		return 1
+	}
+	return target / s.Volatility
+}
+
+// ReturnCorrelation returns the correlation of two series' daily log returns on the days
+// both traded. ok is false when fewer than MinRiskObservations days pair up or either series
+// is flat.
+func ReturnCorrelation(a, b []DailyClose) (corr float64, ok bool) {
+	ra, rb := pairedReturns(a, b)
+	if len(ra) < MinRiskObservations {
+		return 0, false
+	}
+	sa, sb := stdev(ra), stdev(rb)
+	if sa == 0 || sb == 0 {
+		return 0, false
+	}
+	return covariance(ra, rb) / (sa * sb), true
+}
+
+// pairedReturns returns the log returns of a and b over the days both have a close. The
+// slices have equal length, or are both nil when the paired closes yield different counts.
+func pairedReturns(a, b []DailyClose) ([]float64, []float64) {
+	bByDay := make(map[string]float64, len(b))
+	for _, c := range b {
+		bByDay[c.Date.Format("2006-01-02")] = c.Close
+	}
+	var pairedA, pairedB []DailyClose
+	for _, c := range a {
+		if bc, ok := bByDay[c.Date.Format("2006-01-02")]; ok {
+			pairedA = append(pairedA, c)
+			pairedB = append(pairedB, DailyClose{Date: c.Date, Close: bc})
+		}
+	}
+	ra, rb := logReturns(pairedA), logReturns(pairedB)
+	if len(ra) != len(rb) {
+		return nil, nil
+	}
+	return ra, rb
+}
+
+// CurrentDrawdown returns how far the last value of a series is below its running peak, as
+// a fraction of the peak
+func CurrentDrawdown(closes []DailyClose) float64 {
+	if len(closes) == 0 {
+		return 0
+	}
+	var peak float64
+	for _, c := range closes {
+		peak = math.Max(peak, c.Close)
+	}
+	if peak <= 0 {
+		return 0
+	}
+	return math.Max(0, (peak-closes[len(closes)-1].Close)/peak)
+}
+
+// logReturns returns the log return between each pair of consecutive positive closes
+func logReturns(closes []DailyClose) []float64 {
+	var returns []float64
+	for i := 1; i < len(closes); i++ {
+		prev, cur := closes[i-1].Close, closes[i].Close
+		if prev <= 0 || cur <= 0 {
+			continue
+		}
+		returns = append(returns, math.Log(cur/prev))
+	}
+	return returns
+}
+
+// maxDrawdown returns the largest fall from a running peak close, as a fraction of the peak
+func maxDrawdown(closes []DailyClose) float64 {
+	var peak, worst float64
+	for _, c := range closes {
+		if c.Close > peak {
+			peak = c.Close
+		}
+		if peak > 0 {
+			worst = math.Max(worst, (peak-c.Close)/peak)
+		}
+	}
+	return worst
+}
+
+func mean(xs []float64) float64 {
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+// variance returns the sample variance of xs, which must hold at least two values
+func variance(xs []float64) float64 {
+	return covariance(xs, xs)
+}
+
+func stdev(xs []float64) float64 {
+	return math.Sqrt(variance(xs))
+}
+
+// covariance returns the sample covariance of two equal-length series
+func covariance(xs, ys []float64) float64 {
+	mx, my := mean(xs), mean(ys)
+	var sum float64
+	for i := range xs {
+		sum += (xs[i] - mx) * (ys[i] - my)
+	}
+	return sum / float64(len(xs)-1)
+}
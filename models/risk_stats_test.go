@@ -0,0 +1,125 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// closeSeries builds daily closes starting 2026-01-02 from a price per day
+func closeSeries(prices []float64) []DailyClose {
+	start := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	closes := make([]DailyClose, len(prices))
+	for i, p := range prices {
+		closes[i] = DailyClose{Date: start.AddDate(0, 0, i), Close: p}
+	}
+	return closes
+}
+
+func TestComputeRiskStats(t *testing.T) {
+	// The benchmark alternates +1%/-1% moves; the symbol moves twice as far each day
+	bench := make([]float64, 41)
+	sym := make([]float64, 41)
+	bench[0], sym[0] = 100, 50
+	for i := 1; i < len(bench); i++ {
+		step := 0.01
+		if i%2 == 0 {
+			step = -0.01
+		}
+		bench[i] = bench[i-1] * math.Exp(step)
+		sym[i] = sym[i-1] * math.Exp(2*step)
+	}
+
+	stats, err := ComputeRiskStats("AAPL", closeSeries(sym), closeSeries(bench))
+	if err != nil {
+		t.Fatalf("ComputeRiskStats() error = %v", err)
+	}
+	if stats.Observations != 40 {
+		t.Errorf("Observations = %d, want 40", stats.Observations)
+	}
+	if stats.Beta == nil || math.Abs(*stats.Beta-2) > 1e-9 {
+		t.Errorf("Beta = %v, want 2", stats.Beta)
+	}
+	// Daily log returns of ±0.02 have a sample stdev just over 0.02
+	wantVol := 0.02 * math.Sqrt(40.0/39) * math.Sqrt(252)
+	if math.Abs(stats.Volatility-wantVol) > 1e-9 {
+		t.Errorf("Volatility = %v, want %v", stats.Volatility, wantVol)
+	}
+	if want := 1 - math.Exp(-0.02); math.Abs(stats.MaxDrawdown-want) > 1e-9 {
+		t.Errorf("MaxDrawdown = %v, want %v", stats.MaxDrawdown, want)
+	}
+	if !stats.AsOf.Equal(closeSeries(sym)[40].Date) {
+		t.Errorf("AsOf = %v, want the last close's day", stats.AsOf)
+	}
+
+	// Without overlapping benchmark days beta is unknown, but the rest still computes
+	stats, err = ComputeRiskStats("AAPL", closeSeries(sym), nil)
+	if err != nil || stats.Beta != nil {
+		t.Errorf("ComputeRiskStats() = %+v, %v; want stats without beta", stats, err)
+	}
+
+	if _, err := ComputeRiskStats("AAPL", closeSeries(sym[:10]), closeSeries(bench)); !errors.Is(err, ErrInsufficientHistory) {
+		t.Errorf("ComputeRiskStats() error = %v, want ErrInsufficientHistory", err)
+	}
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	got := maxDrawdown(closeSeries([]float64{100, 120, 90, 110, 60, 130}))
+	if want := 0.5; math.Abs(got-want) > 1e-9 {
+		t.Errorf("maxDrawdown() = %v, want %v", got, want)
+	}
+	if got := maxDrawdown(closeSeries([]float64{1, 2, 3})); got != 0 {
+		t.Errorf("maxDrawdown() of a rising series = %v, want 0", got)
+	}
+}
+
+func TestReturnCorrelation(t *testing.T) {
+	a := make([]float64, 31)
+	b := make([]float64, 31)
+	a[0], b[0] = 100, 50
+	for i := 1; i < len(a); i++ {
+		step := 0.01 * float64(i%3-1)
+		a[i] = a[i-1] * math.Exp(step)
+		b[i] = b[i-1] * math.Exp(-3*step)
+	}
+
+	if got, ok := ReturnCorrelation(closeSeries(a), closeSeries(a)); !ok || math.Abs(got-1) > 1e-9 {
+		t.Errorf("ReturnCorrelation() with itself = %v, %v; want 1", got, ok)
+	}
+	if got, ok := ReturnCorrelation(closeSeries(a), closeSeries(b)); !ok || math.Abs(got+1) > 1e-9 {
+		t.Errorf("ReturnCorrelation() with the mirror = %v, %v; want -1", got, ok)
+	}
+	if _, ok := ReturnCorrelation(closeSeries(a[:10]), closeSeries(b)); ok {
+		t.Error("ReturnCorrelation() ok with too few overlapping days")
+	}
+}
+
+func TestCurrentDrawdown(t *testing.T) {
+	if got := CurrentDrawdown(closeSeries([]float64{100, 120, 90, 108})); math.Abs(got-0.1) > 1e-9 {
+		t.Errorf("CurrentDrawdown() = %v, want 0.1", got)
+	}
+	if got := CurrentDrawdown(closeSeries([]float64{100, 90, 130})); got != 0 {
+		t.Errorf("CurrentDrawdown() at a new peak = %v, want 0", got)
+	}
+	if got := CurrentDrawdown(nil); got != 0 {
+		t.Errorf("CurrentDrawdown(nil) = %v, want 0", got)
+	}
+}
+
+func TestRiskStats_VolatilityScale(t *testing.T) {
+	stats := &RiskStats{Volatility: 0.40}
+	if got := stats.VolatilityScale(0.20); got != 0.5 {
+		t.Errorf("VolatilityScale(0.20) = %v, want 0.5", got)
+	}
+	if got := stats.VolatilityScale(0.60); got != 1 {
+		t.Errorf("VolatilityScale(0.60) = %v, want 1 (never scales up)", got)
+	}
+	if got := stats.VolatilityScale(0); got != 1 {
+		t.Errorf("VolatilityScale(0) = %v, want 1 when disabled", got)
+	}
+	var unknown *RiskStats
+	if got := unknown.VolatilityScale(0.20); got != 1 {
+		t.Errorf("VolatilityScale() without stats = %v, want 1", got)
+	}
+}
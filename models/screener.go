@@ -1,11 +1,17 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrScreenerRunInProgress is returned when an operation needs a finished screener run
+var ErrScreenerRunInProgress = errors.New("screener run is still in progress")
+
 // ScreenerRunStatus represents the status of a screener run
 type ScreenerRunStatus string
 
@@ -25,9 +31,34 @@ type ScreenerRun struct {
 	DurationMs int64               `json:"duration_ms"`
 	Status     ScreenerRunStatus   `json:"status"`
 	Error      string              `json:"error,omitempty"`
+	Throttle   *ThrottleReport     `json:"throttle,omitempty"`  // How analysis concurrency adapted to rate limits
+	ReplayOf   *uuid.UUID          `json:"replay_of,omitempty"` // Run whose archived universe this run replayed
 	CreatedAt  time.Time           `json:"created_at"`
 }
 
+// ThrottleReport records how the screener adapted its analysis concurrency to
+// provider rate limits during a run
+type ThrottleReport struct {
+	InitialConcurrency int                  `json:"initial_concurrency"`
+	FinalConcurrency   int                  `json:"final_concurrency"`
+	MinConcurrency     int                  `json:"min_concurrency"`
+	RateLimitErrors    int                  `json:"rate_limit_errors"`
+	Adjustments        []ThrottleAdjustment `json:"adjustments,omitempty"`
+}
+
+// ThrottleAdjustment is a single change to the screener's concurrency or pause
+type ThrottleAdjustment struct {
+	At          time.Time `json:"at"`
+	Concurrency int       `json:"concurrency"`
+	PauseMs     int64     `json:"pause_ms"`
+	Reason      string    `json:"reason"`
+}
+
+// Throttled returns true if the run slowed down because of rate limits
+func (t *ThrottleReport) Throttled() bool {
+	return t != nil && t.RateLimitErrors > 0
+}
+
 // ScreenerCriteria defines the filtering criteria used for a screener run
 type ScreenerCriteria struct {
 	MarketCapMin     int64   `json:"market_cap_min"`
@@ -36,8 +67,100 @@ type ScreenerCriteria struct {
 	PBRatioMax       float64 `json:"pb_ratio_max"`
 	EPSMin           float64 `json:"eps_min"`
 	DividendYieldMin float64 `json:"dividend_yield_min,omitempty"`
-	Sector           string  `json:"sector,omitempty"`
+	Sector           string  `json:"sector,omitempty"` // GICS sector or a known provider alias
 	Limit            int     `json:"limit"`
+	MinListingMonths int     `json:"min_listing_months,omitempty"` // Companies public for less are excluded or flagged
+	ScreenerFilters
+	Ranking *RankingFormula `json:"ranking,omitempty"` // How the run's top picks were ordered
+}
+
+// RankComponent is one normalized input to the top-picks ranking formula
+type RankComponent string
+
+const (
+	RankComponentScore          RankComponent = "score"            // Combined agent score
+	RankComponentConfidence     RankComponent = "confidence"       // Recommendation confidence
+	RankComponentCompleteness   RankComponent = "completeness"     // Share of agents that returned data
+	RankComponentMarginOfSafety RankComponent = "margin_of_safety" // Discount of price to target
+	RankComponentLiquidity      RankComponent = "liquidity"        // Daily dollar volume
+)
+
+// rankComponentLabels are the display names used when rendering a formula
+var rankComponentLabels = map[RankComponent]string{
+	RankComponentScore:          "Score",
+	RankComponentConfidence:     "Confidence",
+	RankComponentCompleteness:   "Data Completeness",
+	RankComponentMarginOfSafety: "Margin of Safety",
+	RankComponentLiquidity:      "Liquidity",
+}
+
+// Label returns the component's display name
+func (c RankComponent) Label() string {
+	if label, ok := rankComponentLabels[c]; ok {
+		return label
+	}
+	return string(c)
+}
+
+// RankingWeight is a component's share of the ranking score, 0-1
+type RankingWeight struct {
+	Component RankComponent `json:"component"`
+	Weight    float64       `json:"weight"`
+}
+
+// RankingFormula is the weighted sum of normalized components used to order top picks
+type RankingFormula struct {
+	Strategy string          `json:"strategy"`
+	Weights  []RankingWeight `json:"weights"`
+}
+
+// String renders the formula, e.g. "0.5 × Score + 0.3 × Confidence"
+func (f *RankingFormula) String() string {
+	if f == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(f.Weights))
+	for _, w := range f.Weights {
+		if w.Weight == 0 {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%g × %s", w.Weight, w.Component.Label()))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// Screener presets select how the universe is screened and pre-filter scored
+const (
+	ScreenerPresetValue  = "value"  // Low P/E and P/B with dividends, capped by the configured ratios
+	ScreenerPresetGrowth = "growth" // Revenue and EPS growth with relative price strength, no valuation caps
+)
+
+// ScreenerPresets are the presets a screener run accepts; an empty preset is value
+var ScreenerPresets = []string{ScreenerPresetValue, ScreenerPresetGrowth}
+
+// ScreenerFilters restricts the screener universe by listing and liquidity, and whether
+// the remaining candidates are analyzed
+type ScreenerFilters struct {
+	Preset          string   `json:"preset,omitempty"`            // One of ScreenerPresets; empty is value
+	Exchanges       []string `json:"exchanges,omitempty"`         // Exchange allowlist, e.g. NYSE, NASDAQ
+	Country         string   `json:"country,omitempty"`           // ISO country code, e.g. US
+	PriceMin        float64  `json:"price_min,omitempty"`         // Minimum share price
+	AvgVolumeMin    int64    `json:"avg_volume_min,omitempty"`    // Minimum average daily volume
+	DollarVolumeMin float64  `json:"dollar_volume_min,omitempty"` // Minimum average daily dollar volume (price × volume)
+	// Rank candidates by value score without full analysis, so no recommendations are
+	// created; retrying the run's failed candidates analyzes them later
+	ScreenOnly bool `json:"screen_only,omitempty"`
+}
+
+// IsGrowth reports whether the filters select the growth preset
+func (f ScreenerFilters) IsGrowth() bool {
+	return f.Preset == ScreenerPresetGrowth
+}
+
+// Liquid reports whether an entry's daily dollar volume meets DollarVolumeMin. The provider
+// cannot filter on dollar volume, so it is applied to the screen results locally.
+func (f ScreenerFilters) Liquid(e ScreenerUniverseEntry) bool {
+	return f.DollarVolumeMin <= 0 || e.Price*float64(e.Volume) >= f.DollarVolumeMin
 }
 
 // ScreenerCandidate represents a stock candidate from the screener
@@ -53,10 +176,60 @@ type ScreenerCandidate struct {
 	Industry      string   `json:"industry"`
 	Price         float64  `json:"price"`
 	Beta          float64  `json:"beta"`
+	Volume        int64    `json:"volume,omitempty"`
 	ValueScore    float64  `json:"value_score"`    // Pre-filter score
 	Score         *float64 `json:"score,omitempty"` // After full analysis
 	Confidence    *float64 `json:"confidence,omitempty"`
 	Analyzed      bool     `json:"analyzed"`
+
+	DataCompleteness *float64 `json:"data_completeness,omitempty"` // 0-100, set by analysis
+	MarginOfSafety   *float64 `json:"margin_of_safety,omitempty"`  // % discount of price to target, set by analysis
+	RankScore        *float64 `json:"rank_score,omitempty"`        // Ranking formula result, set when ranked
+
+	RevenueGrowth *float64 `json:"revenue_growth,omitempty"`  // Annual revenue growth, e.g. 0.25 for 25%; set by growth screens
+	EPSGrowth     *float64 `json:"eps_growth,omitempty"`      // Annual EPS growth; set by growth screens
+	PriceChange6M *float64 `json:"price_change_6m,omitempty"` // Six-month price change in percent; set by growth screens
+
+	IPODate       *time.Time `json:"ipo_date,omitempty"`       // Set when the listing age was checked
+	RecentListing bool       `json:"recent_listing,omitempty"` // Public for less than the run's minimum listing age
+
+	RecommendationID *uuid.UUID      `json:"recommendation_id,omitempty"` // Recommendation produced by analysis
+	AnalysisError    string          `json:"analysis_error,omitempty"`    // Last analysis failure, cleared on success
+	ScoreBreakdown   *ScoreBreakdown `json:"score_breakdown,omitempty"`   // Why the candidate ranked where it did
+}
+
+// ScoreComponent is one weighted input to a candidate's value score
+type ScoreComponent struct {
+	Name         string  `json:"name"`
+	Value        float64 `json:"value"`        // Raw metric, e.g. the P/E ratio
+	Score        float64 `json:"score"`        // Metric normalized to 0-100
+	Weight       float64 `json:"weight"`       // Fraction of the value score, 0-1
+	Contribution float64 `json:"contribution"` // Score × Weight
+}
+
+// ScoreBreakdown records the metrics and formulas that produced a candidate's scores
+type ScoreBreakdown struct {
+	Components          []ScoreComponent `json:"components"`
+	MarketCapPercentile float64          `json:"market_cap_percentile"` // 0-100 rank of market cap within the run
+	ValueFormula        string           `json:"value_formula"`
+	AnalysisFormula     string           `json:"analysis_formula,omitempty"` // Set once the candidate is analyzed
+}
+
+// Summary renders the breakdown as plain text, one line per component
+func (b *ScoreBreakdown) Summary() string {
+	if b == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, c := range b.Components {
+		fmt.Fprintf(&sb, "%s %.2f → %.0f × %.0f%% = %.1f\n", c.Name, c.Value, c.Score, c.Weight*100, c.Contribution)
+	}
+	fmt.Fprintf(&sb, "Market cap percentile: %.0f\n", b.MarketCapPercentile)
+	sb.WriteString("Value score = " + b.ValueFormula)
+	if b.AnalysisFormula != "" {
+		sb.WriteString("\nAnalysis score = " + b.AnalysisFormula)
+	}
+	return sb.String()
 }
 
 // NewScreenerRun creates a new ScreenerRun with default values
@@ -107,6 +280,17 @@ func (s *ScreenerRun) IsCompleted() bool {
 	return s.Status == ScreenerRunStatusCompleted
 }
 
+// FailedCandidates returns the candidates whose analysis did not complete
+func (s *ScreenerRun) FailedCandidates() []ScreenerCandidate {
+	var failed []ScreenerCandidate
+	for _, c := range s.Candidates {
+		if !c.Analyzed {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
 // IsFailed returns true if the screener run failed
 func (s *ScreenerRun) IsFailed() bool {
 	return s.Status == ScreenerRunStatusFailed
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// ErrScreenerPresetNotFound is returned when a run asks for a preset that is neither
+// built in nor saved
+var ErrScreenerPresetNotFound = errors.New("screener preset not found")
+
+// ErrInvalidScreenerPreset is returned when a saved preset's name or criteria are invalid
+var ErrInvalidScreenerPreset = errors.New("invalid screener preset")
+
+// screenerPresetName is the form of a saved preset's name, so it can be passed in a URL
+var screenerPresetName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// ScreenerPreset is a user-defined set of valuation criteria a screener run can use in
+// place of the configured ones. Zero thresholds don't restrict the screen.
+type ScreenerPreset struct {
+	Name             string    `json:"name"`
+	Description      string    `json:"description,omitempty"`
+	MarketCapMin     int64     `json:"market_cap_min,omitempty"`
+	MarketCapMax     int64     `json:"market_cap_max,omitempty"`
+	PERatioMax       float64   `json:"pe_ratio_max,omitempty"`
+	PBRatioMax       float64   `json:"pb_ratio_max,omitempty"`
+	DividendYieldMin float64   `json:"dividend_yield_min,omitempty"` // Percent
+	EPSMin           float64   `json:"eps_min,omitempty"`
+	Sector           string    `json:"sector,omitempty"` // GICS sector or a known provider alias
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// ValidScreenerPresetName reports whether name is a built-in preset or could name a saved one
+func ValidScreenerPresetName(name string) bool {
+	return slices.Contains(ScreenerPresets, name) || screenerPresetName.MatchString(name)
+}
+
+// Validate checks the preset's name and thresholds. Names are lowercase letters, digits,
+// hyphens and underscores, and can't shadow a built-in preset.
+func (p ScreenerPreset) Validate() error {
+	if !screenerPresetName.MatchString(p.Name) {
+		return fmt.Errorf("%w: name must be 1-50 lowercase letters, digits, hyphens or underscores", ErrInvalidScreenerPreset)
+	}
+	if slices.Contains(ScreenerPresets, p.Name) {
+		return fmt.Errorf("%w: %q is a built-in preset", ErrInvalidScreenerPreset, p.Name)
+	}
+	if p.MarketCapMin < 0 || p.MarketCapMax < 0 || p.PERatioMax < 0 || p.PBRatioMax < 0 || p.DividendYieldMin < 0 {
+		return fmt.Errorf("%w: thresholds must not be negative", ErrInvalidScreenerPreset)
+	}
+	if p.MarketCapMax > 0 && p.MarketCapMax < p.MarketCapMin {
+		return fmt.Errorf("%w: market_cap_max must not be below market_cap_min", ErrInvalidScreenerPreset)
+	}
+	return nil
+}
+
+// Apply returns the criteria with the preset's thresholds replacing the configured ones
+func (p ScreenerPreset) Apply(c ScreenerCriteria) ScreenerCriteria {
+	c.MarketCapMin = p.MarketCapMin
+	c.MarketCapMax = p.MarketCapMax
+	c.PERatioMax = p.PERatioMax
+	c.PBRatioMax = p.PBRatioMax
+	c.DividendYieldMin = p.DividendYieldMin
+	c.EPSMin = p.EPSMin
+	c.Sector = p.Sector
+	return c
+}
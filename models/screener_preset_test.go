@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestScreenerPreset_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		preset  ScreenerPreset
+		wantErr bool
+	}{
+		{"valid", ScreenerPreset{Name: "small-caps", MarketCapMin: 300_000_000, MarketCapMax: 2_000_000_000}, false},
+		{"empty name", ScreenerPreset{}, true},
+		{"uppercase name", ScreenerPreset{Name: "SmallCaps"}, true},
+		{"name with spaces", ScreenerPreset{Name: "small caps"}, true},
+		{"built-in name", ScreenerPreset{Name: ScreenerPresetGrowth}, true},
+		{"negative threshold", ScreenerPreset{Name: "cheap", PERatioMax: -1}, true},
+		{"inverted market cap range", ScreenerPreset{Name: "caps", MarketCapMin: 10_000_000_000, MarketCapMax: 1_000_000_000}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.preset.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidScreenerPreset) {
+				t.Errorf("Validate() error = %v, want ErrInvalidScreenerPreset", err)
+			}
+		})
+	}
+}
+
+func TestScreenerPreset_Apply(t *testing.T) {
+	configured := ScreenerCriteria{MarketCapMin: 1_000_000_000, PERatioMax: 15, PBRatioMax: 1.5, Limit: 30,
+		ScreenerFilters: ScreenerFilters{Country: "US", Preset: "dividends"}}
+	preset := ScreenerPreset{Name: "dividends", DividendYieldMin: 3, PERatioMax: 20, Sector: "Utilities"}
+
+	got := preset.Apply(configured)
+
+	if got.PERatioMax != 20 || got.PBRatioMax != 0 || got.MarketCapMin != 0 || got.DividendYieldMin != 3 || got.Sector != "Utilities" {
+		t.Errorf("Apply() = %+v, want the preset's criteria in place of the configured ones", got)
+	}
+	if got.Limit != 30 || got.Country != "US" || got.Preset != "dividends" {
+		t.Errorf("Apply() = %+v, want the limit and listing filters kept", got)
+	}
+}
+
+func TestValidScreenerPresetName(t *testing.T) {
+	for name, want := range map[string]bool{"value": true, "growth": true, "small-caps": true, "Not Valid!": false, "": false} {
+		if got := ValidScreenerPresetName(name); got != want {
+			t.Errorf("ValidScreenerPresetName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrScreenerUniverseMissing is returned when replaying a run saved before universes were archived
+var ErrScreenerUniverseMissing = errors.New("screener run has no archived universe")
+
+// ErrInvalidScreenerReplay is returned when replay criteria are invalid
+var ErrInvalidScreenerReplay = errors.New("invalid screener replay")
+
+// ScreenerUniverseEntry is one row of the raw provider screen a run started from, before
+// the symbol lists, value ranking and listing-age checks narrowed it down
+type ScreenerUniverseEntry struct {
+	Symbol        string  `json:"symbol"`
+	CompanyName   string  `json:"company_name"`
+	MarketCap     int64   `json:"market_cap"`
+	Sector        string  `json:"sector"`
+	Industry      string  `json:"industry"`
+	Price         float64 `json:"price"`
+	PERatio       float64 `json:"pe_ratio"`
+	PBRatio       float64 `json:"pb_ratio"`
+	EPS           float64 `json:"eps"`
+	DividendYield float64 `json:"dividend_yield"`
+	Beta          float64 `json:"beta"`
+	Volume        int64   `json:"volume"`
+	Exchange      string  `json:"exchange"`
+	Country       string  `json:"country"`
+}
+
+// Candidate returns the entry as an unanalyzed screener candidate
+func (e ScreenerUniverseEntry) Candidate() ScreenerCandidate {
+	return ScreenerCandidate{
+		Symbol:        e.Symbol,
+		CompanyName:   e.CompanyName,
+		MarketCap:     e.MarketCap,
+		PERatio:       e.PERatio,
+		PBRatio:       e.PBRatio,
+		EPS:           e.EPS,
+		DividendYield: e.DividendYield,
+		Sector:        e.Sector,
+		Industry:      e.Industry,
+		Price:         e.Price,
+		Beta:          e.Beta,
+		Volume:        e.Volume,
+	}
+}
+
+// Admits reports whether an archived entry passes the criteria, applying locally the
+// filters the provider applied to the original screen. Sectors are compared by name;
+// provider aliases are not resolved.
+func (c ScreenerCriteria) Admits(e ScreenerUniverseEntry) bool {
+	switch {
+	case c.MarketCapMin > 0 && e.MarketCap < c.MarketCapMin,
+		c.MarketCapMax > 0 && e.MarketCap > c.MarketCapMax,
+		c.PERatioMax > 0 && e.PERatio > c.PERatioMax,
+		c.PBRatioMax > 0 && e.PBRatio > c.PBRatioMax,
+		c.EPSMin != 0 && e.EPS < c.EPSMin,
+		c.DividendYieldMin > 0 && e.DividendYield < c.DividendYieldMin,
+		c.Sector != "" && !strings.EqualFold(c.Sector, e.Sector),
+		c.Country != "" && !strings.EqualFold(c.Country, e.Country),
+		c.PriceMin > 0 && e.Price < c.PriceMin,
+		c.AvgVolumeMin > 0 && e.Volume < c.AvgVolumeMin,
+		!c.Liquid(e):
+		return false
+	}
+	if len(c.Exchanges) > 0 && !slices.ContainsFunc(c.Exchanges, func(x string) bool { return strings.EqualFold(x, e.Exchange) }) {
+		return false
+	}
+	return true
+}
+
+// ScreenerReplayRequest holds the criteria and ranking strategy to replay an archived
+// universe with. Non-zero fields replace the original run's values.
+type ScreenerReplayRequest struct {
+	MarketCapMin     int64   `json:"market_cap_min,omitempty"`
+	MarketCapMax     int64   `json:"market_cap_max,omitempty"`
+	PERatioMax       float64 `json:"pe_ratio_max,omitempty"`
+	PBRatioMax       float64 `json:"pb_ratio_max,omitempty"`
+	EPSMin           float64 `json:"eps_min,omitempty"`
+	DividendYieldMin float64 `json:"dividend_yield_min,omitempty"`
+	Sector           string  `json:"sector,omitempty"`
+	ScreenerFilters
+	RankingStrategy string `json:"ranking_strategy,omitempty"` // Ranking preset for the replay's top picks
+}
+
+// Validate rejects negative thresholds
+func (r ScreenerReplayRequest) Validate() error {
+	if r.MarketCapMin < 0 || r.MarketCapMax < 0 || r.PERatioMax < 0 || r.PBRatioMax < 0 ||
+		r.DividendYieldMin < 0 || r.PriceMin < 0 || r.AvgVolumeMin < 0 || r.DollarVolumeMin < 0 {
+		return fmt.Errorf("%w: thresholds must not be negative", ErrInvalidScreenerReplay)
+	}
+	return nil
+}
+
+// Apply returns the original criteria with the request's non-zero fields replacing them
+func (r ScreenerReplayRequest) Apply(c ScreenerCriteria) ScreenerCriteria {
+	if r.MarketCapMin > 0 {
+		c.MarketCapMin = r.MarketCapMin
+	}
+	if r.MarketCapMax > 0 {
+		c.MarketCapMax = r.MarketCapMax
+	}
+	if r.PERatioMax > 0 {
+		c.PERatioMax = r.PERatioMax
+	}
+	if r.PBRatioMax > 0 {
+		c.PBRatioMax = r.PBRatioMax
+	}
+	if r.EPSMin != 0 {
+		c.EPSMin = r.EPSMin
+	}
+	if r.DividendYieldMin > 0 {
+		c.DividendYieldMin = r.DividendYieldMin
+	}
+	if r.Sector != "" {
+		c.Sector = r.Sector
+	}
+	if len(r.Exchanges) > 0 {
+		c.Exchanges = r.Exchanges
+	}
+	if r.Country != "" {
+		c.Country = r.Country
+	}
+	if r.PriceMin > 0 {
+		c.PriceMin = r.PriceMin
+	}
+	if r.AvgVolumeMin > 0 {
+		c.AvgVolumeMin = r.AvgVolumeMin
+	}
+	if r.DollarVolumeMin > 0 {
+		c.DollarVolumeMin = r.DollarVolumeMin
+	}
+	return c
+}
+
+// ScreenerRunComparison contrasts a replay with the run whose universe it replayed
+type ScreenerRunComparison struct {
+	Original      *ScreenerRun `json:"original"`
+	Replay        *ScreenerRun `json:"replay"`
+	Added         []string     `json:"added"`          // Candidates only the replay considered
+	Removed       []string     `json:"removed"`        // Candidates only the original considered
+	OriginalPicks []string     `json:"original_picks"` // Top pick symbols, best first
+	ReplayPicks   []string     `json:"replay_picks"`
+}
+
+// CompareScreenerRuns lists how the replay's candidates and top picks differ from the original's
+func CompareScreenerRuns(original, replay *ScreenerRun) *ScreenerRunComparison {
+	symbols := func(run *ScreenerRun) []string {
+		out := make([]string, 0, len(run.Candidates))
+		for _, c := range run.Candidates {
+			out = append(out, c.Symbol)
+		}
+		return out
+	}
+	originalSymbols, replaySymbols := symbols(original), symbols(replay)
+
+	cmp := &ScreenerRunComparison{
+		Original:      original,
+		Replay:        replay,
+		Added:         []string{},
+		Removed:       []string{},
+		OriginalPicks: original.TopPickSymbols(),
+		ReplayPicks:   replay.TopPickSymbols(),
+	}
+	for _, s := range replaySymbols {
+		if !slices.Contains(originalSymbols, s) {
+			cmp.Added = append(cmp.Added, s)
+		}
+	}
+	for _, s := range originalSymbols {
+		if !slices.Contains(replaySymbols, s) {
+			cmp.Removed = append(cmp.Removed, s)
+		}
+	}
+	return cmp
+}
+
+// TopPickSymbols returns the symbols of the run's top picks, in pick order
+func (s *ScreenerRun) TopPickSymbols() []string {
+	symbolByRec := make(map[uuid.UUID]string, len(s.Candidates))
+	for _, c := range s.Candidates {
+		if c.RecommendationID != nil {
+			symbolByRec[*c.RecommendationID] = c.Symbol
+		}
+	}
+	picks := []string{}
+	for _, id := range s.TopPicks {
+		if symbol, ok := symbolByRec[id]; ok {
+			picks = append(picks, symbol)
+		}
+	}
+	return picks
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestScreenerCriteria_Admits(t *testing.T) {
+	entry := ScreenerUniverseEntry{
+		Symbol: "AAPL", MarketCap: 3_000_000_000_000, PERatio: 28, PBRatio: 40, EPS: 6.1,
+		Sector: "Technology", Price: 190, Volume: 50_000_000, Exchange: "NASDAQ", Country: "US",
+	}
+
+	tests := []struct {
+		name     string
+		criteria ScreenerCriteria
+		want     bool
+	}{
+		{"no filters", ScreenerCriteria{}, true},
+		{"within bounds", ScreenerCriteria{MarketCapMin: 1_000_000_000, PERatioMax: 30, EPSMin: 1}, true},
+		{"pe too high", ScreenerCriteria{PERatioMax: 20}, false},
+		{"other sector", ScreenerCriteria{Sector: "Energy"}, false},
+		{"sector case", ScreenerCriteria{Sector: "technology"}, true},
+		{"exchange allowlist", ScreenerCriteria{ScreenerFilters: ScreenerFilters{Exchanges: []string{"NYSE"}}}, false},
+		{"exchange allowed", ScreenerCriteria{ScreenerFilters: ScreenerFilters{Exchanges: []string{"nyse", "nasdaq"}}}, true},
+		{"price floor", ScreenerCriteria{ScreenerFilters: ScreenerFilters{PriceMin: 200}}, false},
+		{"dollar volume met", ScreenerCriteria{ScreenerFilters: ScreenerFilters{DollarVolumeMin: 5_000_000_000}}, true},
+		{"dollar volume floor", ScreenerCriteria{ScreenerFilters: ScreenerFilters{DollarVolumeMin: 20_000_000_000}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.criteria.Admits(entry); got != tt.want {
+				t.Errorf("Admits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreenerReplayRequest_Apply(t *testing.T) {
+	original := ScreenerCriteria{MarketCapMin: 1_000_000_000, PERatioMax: 15, PBRatioMax: 3, Sector: "Energy", Limit: 20}
+	req := ScreenerReplayRequest{PERatioMax: 25, ScreenerFilters: ScreenerFilters{Country: "US"}}
+
+	got := req.Apply(original)
+	if got.PERatioMax != 25 || got.Country != "US" {
+		t.Errorf("Apply() = %+v, want the overridden P/E and country", got)
+	}
+	if got.MarketCapMin != original.MarketCapMin || got.Sector != "Energy" || got.Limit != 20 {
+		t.Errorf("Apply() = %+v, want unset fields kept from the original", got)
+	}
+
+	if err := (ScreenerReplayRequest{PERatioMax: -1}).Validate(); err == nil {
+		t.Error("Validate() expected an error for a negative threshold")
+	}
+}
+
+func TestCompareScreenerRuns(t *testing.T) {
+	recA, recB, recC := uuid.New(), uuid.New(), uuid.New()
+	original := &ScreenerRun{
+		Candidates: []ScreenerCandidate{{Symbol: "A", RecommendationID: &recA}, {Symbol: "B", RecommendationID: &recB}},
+		TopPicks:   []uuid.UUID{recB, recA},
+	}
+	replay := &ScreenerRun{
+		Candidates: []ScreenerCandidate{{Symbol: "B", RecommendationID: &recB}, {Symbol: "C", RecommendationID: &recC}},
+		TopPicks:   []uuid.UUID{recC},
+	}
+
+	cmp := CompareScreenerRuns(original, replay)
+	if !slices.Equal(cmp.Added, []string{"C"}) || !slices.Equal(cmp.Removed, []string{"A"}) {
+		t.Errorf("Added = %v, Removed = %v; want [C] and [A]", cmp.Added, cmp.Removed)
+	}
+	if !slices.Equal(cmp.OriginalPicks, []string{"B", "A"}) || !slices.Equal(cmp.ReplayPicks, []string{"C"}) {
+		t.Errorf("OriginalPicks = %v, ReplayPicks = %v", cmp.OriginalPicks, cmp.ReplayPicks)
+	}
+}
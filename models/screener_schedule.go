@@ -0,0 +1,39 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScreenerSchedule is when the screener runs on its own
+type ScreenerSchedule struct {
+	Cron    string `json:"cron"`    // Five-field cron spec in US Eastern time; empty disables scheduled runs
+	Analyze bool   `json:"analyze"` // Fully analyze candidates, saving a recommendation for each; otherwise only screen and rank them
+}
+
+// Normalize trims the cron spec and checks that it parses, returning the parsed schedule,
+// or nil when scheduled runs are disabled
+func (s *ScreenerSchedule) Normalize() (*CronSchedule, error) {
+	s.Cron = strings.Join(strings.Fields(s.Cron), " ")
+	if s.Cron == "" {
+		return nil, nil
+	}
+	cron, err := ParseCron(s.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("screener schedule: %w", err)
+	}
+	return cron, nil
+}
+
+// ScreenerScheduleStatus is the screener schedule with its next and last runs
+type ScreenerScheduleStatus struct {
+	ScreenerSchedule
+	TimeZone  string     `json:"time_zone"`
+	NextRun   *time.Time `json:"next_run,omitempty"`
+	LastRun   *time.Time `json:"last_run,omitempty"`    // When the schedule last fired, whether or not a run started
+	LastRunID *uuid.UUID `json:"last_run_id,omitempty"` // The screener run it created, if it got that far
+	LastError string     `json:"last_error,omitempty"`
+}
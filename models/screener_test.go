@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -226,6 +227,28 @@ func TestScreenerCandidate(t *testing.T) {
 	}
 }
 
+func TestScoreBreakdown_Summary(t *testing.T) {
+	var nilBreakdown *ScoreBreakdown
+	if got := nilBreakdown.Summary(); got != "" {
+		t.Errorf("nil Summary() = %q, want empty", got)
+	}
+
+	b := &ScoreBreakdown{
+		Components: []ScoreComponent{
+			{Name: "P/E", Value: 10, Score: 50, Weight: 0.5, Contribution: 25},
+		},
+		MarketCapPercentile: 75,
+		ValueFormula:        "value formula",
+		AnalysisFormula:     "analysis formula",
+	}
+	summary := b.Summary()
+	for _, want := range []string{"P/E 10.00 → 50 × 50% = 25.0", "Market cap percentile: 75", "Value score = value formula", "Analysis score = analysis formula"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary() = %q, missing %q", summary, want)
+		}
+	}
+}
+
 func TestScreenerCandidate_BeforeAnalysis(t *testing.T) {
 	candidate := ScreenerCandidate{
 		Symbol:      "AAPL",
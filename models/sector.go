@@ -0,0 +1,185 @@
+package models
+
+import "strings"
+
+// GICS sector names. Provider sector strings are normalized to these on ingestion
+// so grouping and filtering behave the same regardless of data source.
+const (
+	SectorCommunicationServices = "Communication Services"
+	SectorConsumerDiscretionary = "Consumer Discretionary"
+	SectorConsumerStaples       = "Consumer Staples"
+	SectorEnergy                = "Energy"
+	SectorFinancials            = "Financials"
+	SectorHealthCare            = "Health Care"
+	SectorIndustrials           = "Industrials"
+	SectorInformationTechnology = "Information Technology"
+	SectorMaterials             = "Materials"
+	SectorRealEstate            = "Real Estate"
+	SectorUtilities             = "Utilities"
+)
+
+// GICSSectors lists all GICS sectors in standard order
+var GICSSectors = []string{
+	SectorCommunicationServices,
+	SectorConsumerDiscretionary,
+	SectorConsumerStaples,
+	SectorEnergy,
+	SectorFinancials,
+	SectorHealthCare,
+	SectorIndustrials,
+	SectorInformationTechnology,
+	SectorMaterials,
+	SectorRealEstate,
+	SectorUtilities,
+}
+
+// sectorAliases maps lowercase provider sector strings to GICS sectors.
+// Covers FMP (Yahoo-style names), Alpha Vantage (SIC office names), and common aliases.
+var sectorAliases = map[string]string{
+	// GICS names
+	"communication services": SectorCommunicationServices,
+	"consumer discretionary": SectorConsumerDiscretionary,
+	"consumer staples":       SectorConsumerStaples,
+	"energy":                 SectorEnergy,
+	"financials":             SectorFinancials,
+	"health care":            SectorHealthCare,
+	"industrials":            SectorIndustrials,
+	"information technology": SectorInformationTechnology,
+	"materials":              SectorMaterials,
+	"real estate":            SectorRealEstate,
+	"utilities":              SectorUtilities,
+
+	// FMP
+	"technology":         SectorInformationTechnology,
+	"healthcare":         SectorHealthCare,
+	"financial services": SectorFinancials,
+	"consumer cyclical":  SectorConsumerDiscretionary,
+	"consumer defensive": SectorConsumerStaples,
+	"basic materials":    SectorMaterials,
+
+	// Alpha Vantage
+	"life sciences":              SectorHealthCare,
+	"finance":                    SectorFinancials,
+	"real estate & construction": SectorRealEstate,
+	"energy & transportation":    SectorEnergy,
+	"telecommunications":         SectorCommunicationServices,
+
+	// Common aliases
+	"tech":              SectorInformationTechnology,
+	"financial":         SectorFinancials,
+	"telecommunication": SectorCommunicationServices,
+	"telecom":           SectorCommunicationServices,
+	"communication":     SectorCommunicationServices,
+	"consumer services": SectorConsumerDiscretionary,
+	"consumer goods":    SectorConsumerStaples,
+	"industrial":        SectorIndustrials,
+	"utility":           SectorUtilities,
+	"reit":              SectorRealEstate,
+}
+
+// industryRule maps an industry keyword to a GICS industry and its sector.
+// Rules are matched in order, so more specific keywords come first.
+type industryRule struct {
+	keyword  string
+	industry string
+	sector   string
+}
+
+var industryRules = []industryRule{
+	{"semiconductor equipment", "Semiconductors & Semiconductor Equipment", SectorInformationTechnology},
+	{"semiconductor", "Semiconductors & Semiconductor Equipment", SectorInformationTechnology},
+	{"software", "Software", SectorInformationTechnology},
+	{"information technology services", "IT Services", SectorInformationTechnology},
+	{"computer hardware", "Technology Hardware, Storage & Peripherals", SectorInformationTechnology},
+	{"consumer electronics", "Technology Hardware, Storage & Peripherals", SectorInformationTechnology},
+	{"electronic components", "Electronic Equipment, Instruments & Components", SectorInformationTechnology},
+	{"communication equipment", "Communications Equipment", SectorInformationTechnology},
+	{"internet content", "Interactive Media & Services", SectorCommunicationServices},
+	{"telecom", "Diversified Telecommunication Services", SectorCommunicationServices},
+	{"entertainment", "Entertainment", SectorCommunicationServices},
+	{"broadcasting", "Media", SectorCommunicationServices},
+	{"publishing", "Media", SectorCommunicationServices},
+	{"advertising", "Media", SectorCommunicationServices},
+	{"biotech", "Biotechnology", SectorHealthCare},
+	{"drug manufacturers", "Pharmaceuticals", SectorHealthCare},
+	{"pharmaceutical", "Pharmaceuticals", SectorHealthCare},
+	{"medical devices", "Health Care Equipment & Supplies", SectorHealthCare},
+	{"medical instruments", "Health Care Equipment & Supplies", SectorHealthCare},
+	{"healthcare plans", "Health Care Providers & Services", SectorHealthCare},
+	{"medical care", "Health Care Providers & Services", SectorHealthCare},
+	{"diagnostics", "Life Sciences Tools & Services", SectorHealthCare},
+	{"bank", "Banks", SectorFinancials},
+	{"insurance", "Insurance", SectorFinancials},
+	{"asset management", "Capital Markets", SectorFinancials},
+	{"capital markets", "Capital Markets", SectorFinancials},
+	{"credit services", "Consumer Finance", SectorFinancials},
+	{"reit", "Equity Real Estate Investment Trusts (REITs)", SectorRealEstate},
+	{"real estate", "Real Estate Management & Development", SectorRealEstate},
+	{"oil & gas", "Oil, Gas & Consumable Fuels", SectorEnergy},
+	{"oil and gas", "Oil, Gas & Consumable Fuels", SectorEnergy},
+	{"coal", "Oil, Gas & Consumable Fuels", SectorEnergy},
+	{"utilities", "Electric Utilities", SectorUtilities},
+	{"electric", "Electric Utilities", SectorUtilities},
+	{"chemical", "Chemicals", SectorMaterials},
+	{"gold", "Metals & Mining", SectorMaterials},
+	{"steel", "Metals & Mining", SectorMaterials},
+	{"aluminum", "Metals & Mining", SectorMaterials},
+	{"mining", "Metals & Mining", SectorMaterials},
+	{"packaging", "Containers & Packaging", SectorMaterials},
+	{"aerospace", "Aerospace & Defense", SectorIndustrials},
+	{"airlines", "Passenger Airlines", SectorIndustrials},
+	{"railroads", "Ground Transportation", SectorIndustrials},
+	{"trucking", "Ground Transportation", SectorIndustrials},
+	{"machinery", "Machinery", SectorIndustrials},
+	{"building products", "Building Products", SectorIndustrials},
+	{"engineering & construction", "Construction & Engineering", SectorIndustrials},
+	{"auto parts", "Automobile Components", SectorConsumerDiscretionary},
+	{"auto manufacturers", "Automobiles", SectorConsumerDiscretionary},
+	{"restaurants", "Hotels, Restaurants & Leisure", SectorConsumerDiscretionary},
+	{"lodging", "Hotels, Restaurants & Leisure", SectorConsumerDiscretionary},
+	{"internet retail", "Broadline Retail", SectorConsumerDiscretionary},
+	{"apparel", "Textiles, Apparel & Luxury Goods", SectorConsumerDiscretionary},
+	{"home improvement", "Specialty Retail", SectorConsumerDiscretionary},
+	{"beverages", "Beverages", SectorConsumerStaples},
+	{"tobacco", "Tobacco", SectorConsumerStaples},
+	{"household", "Household Products", SectorConsumerStaples},
+	{"grocery", "Consumer Staples Distribution & Retail", SectorConsumerStaples},
+	{"discount stores", "Consumer Staples Distribution & Retail", SectorConsumerStaples},
+	{"packaged foods", "Food Products", SectorConsumerStaples},
+	{"farm products", "Food Products", SectorConsumerStaples},
+}
+
+// NormalizeSector maps a provider sector string to its GICS sector.
+// Unrecognized non-empty values are returned trimmed so no data is lost.
+func NormalizeSector(raw string) string {
+	trimmed := strings.TrimSpace(raw)
+	if sector, ok := sectorAliases[strings.ToLower(trimmed)]; ok {
+		return sector
+	}
+	return trimmed
+}
+
+// NormalizeClassification maps a provider sector and industry to a GICS sector and industry.
+// A recognized industry determines the sector, since some providers (e.g. Alpha Vantage's
+// "MANUFACTURING") use sector buckets that span several GICS sectors.
+func NormalizeClassification(sector, industry string) (string, string) {
+	lowerIndustry := strings.ToLower(strings.TrimSpace(industry))
+	if lowerIndustry != "" {
+		for _, rule := range industryRules {
+			if strings.Contains(lowerIndustry, rule.keyword) {
+				return rule.sector, rule.industry
+			}
+		}
+	}
+	return NormalizeSector(sector), strings.TrimSpace(industry)
+}
+
+// IsGICSSector reports whether the value is one of the GICS sector names
+func IsGICSSector(sector string) bool {
+	for _, s := range GICSSectors {
+		if s == sector {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package models
+
+import "testing"
+
+func TestNormalizeSector(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"Technology", SectorInformationTechnology},
+		{"TECHNOLOGY", SectorInformationTechnology},
+		{"Information Technology", SectorInformationTechnology},
+		{"Consumer Cyclical", SectorConsumerDiscretionary},
+		{"Consumer Defensive", SectorConsumerStaples},
+		{"Healthcare", SectorHealthCare},
+		{"LIFE SCIENCES", SectorHealthCare},
+		{"Financial Services", SectorFinancials},
+		{"FINANCE", SectorFinancials},
+		{"Basic Materials", SectorMaterials},
+		{"REAL ESTATE & CONSTRUCTION", SectorRealEstate},
+		{"  utilities  ", SectorUtilities},
+		{"", ""},
+		{"Conglomerates", "Conglomerates"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := NormalizeSector(tt.raw); got != tt.want {
+				t.Errorf("NormalizeSector(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeClassification(t *testing.T) {
+	tests := []struct {
+		name         string
+		sector       string
+		industry     string
+		wantSector   string
+		wantIndustry string
+	}{
+		{"fmp software", "Technology", "Software - Infrastructure", SectorInformationTechnology, "Software"},
+		{"fmp bank", "Financial Services", "Banks - Regional", SectorFinancials, "Banks"},
+		{"fmp reit", "Real Estate", "REIT - Retail", SectorRealEstate, "Equity Real Estate Investment Trusts (REITs)"},
+		{"av manufacturing pharma", "MANUFACTURING", "PHARMACEUTICAL PREPARATIONS", SectorHealthCare, "Pharmaceuticals"},
+		{"av manufacturing autos", "MANUFACTURING", "MOTOR VEHICLES & PASSENGER CAR BODIES", "MANUFACTURING", "MOTOR VEHICLES & PASSENGER CAR BODIES"},
+		{"electronics is not electric", "Technology", "Electronic Components", SectorInformationTechnology, "Electronic Equipment, Instruments & Components"},
+		{"unknown industry keeps provider value", "Industrials", "Conglomerates", SectorIndustrials, "Conglomerates"},
+		{"empty", "", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sector, industry := NormalizeClassification(tt.sector, tt.industry)
+			if sector != tt.wantSector || industry != tt.wantIndustry {
+				t.Errorf("NormalizeClassification(%q, %q) = (%q, %q), want (%q, %q)",
+					tt.sector, tt.industry, sector, industry, tt.wantSector, tt.wantIndustry)
+			}
+		})
+	}
+}
+
+func TestNormalizeClassification_ProvidersAgree(t *testing.T) {
+	fmpSector, _ := NormalizeClassification("Technology", "Consumer Electronics")
+	avSector, _ := NormalizeClassification("TECHNOLOGY", "ELECTRONIC COMPUTERS")
+	if fmpSector != avSector {
+		t.Errorf("FMP sector %q != Alpha Vantage sector %q", fmpSector, avSector)
+	}
+}
+
+func TestIsGICSSector(t *testing.T) {
+	if !IsGICSSector(SectorHealthCare) {
+		t.Error("Health Care should be a GICS sector")
+	}
+	if IsGICSSector("Healthcare") {
+		t.Error("Healthcare is a provider alias, not a GICS sector name")
+	}
+}
@@ -0,0 +1,22 @@
+package models
+
+import "fmt"
+
+// ShortAvailability is whether the broker can borrow a symbol's shares for a short sale
+type ShortAvailability struct {
+	Symbol       string `json:"symbol"`
+	Shortable    bool   `json:"shortable"`
+	EasyToBorrow bool   `json:"easy_to_borrow"`
+}
+
+// Check reports whether a short in the symbol may be opened. Hard-to-borrow shares carry
+// borrow fees and recall risk, so they are refused unless allowHardToBorrow is set.
+func (s ShortAvailability) Check(allowHardToBorrow bool) error {
+	if !s.Shortable {
+		return fmt.Errorf("%w: %s is not available to borrow", ErrRiskRuleViolation, s.Symbol)
+	}
+	if !s.EasyToBorrow && !allowHardToBorrow {
+		return fmt.Errorf("%w: %s is hard to borrow", ErrRiskRuleViolation, s.Symbol)
+	}
+	return nil
+}
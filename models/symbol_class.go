@@ -0,0 +1,59 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// SymbolClass groups symbols that should share strategy thresholds
+type SymbolClass string
+
+const (
+	SymbolClassMegaCap  SymbolClass = "mega_cap"
+	SymbolClassLargeCap SymbolClass = "large_cap"
+	SymbolClassMidCap   SymbolClass = "mid_cap"
+	SymbolClassSmallCap SymbolClass = "small_cap"
+	SymbolClassCrypto   SymbolClass = "crypto"
+)
+
+// Market cap lower bounds for each equity class, in dollars
+var (
+	MegaCapMin  = decimal.NewFromInt(200_000_000_000)
+	LargeCapMin = decimal.NewFromInt(10_000_000_000)
+	MidCapMin   = decimal.NewFromInt(2_000_000_000)
+)
+
+// SymbolClasses lists every class in descending size order, crypto last
+var SymbolClasses = []SymbolClass{
+	SymbolClassMegaCap,
+	SymbolClassLargeCap,
+	SymbolClassMidCap,
+	SymbolClassSmallCap,
+	SymbolClassCrypto,
+}
+
+// IsCryptoSymbol reports whether symbol is a crypto pair such as BTC/USD
+func IsCryptoSymbol(symbol string) bool {
+	return strings.Contains(symbol, "/")
+}
+
+// ClassifySymbol resolves the class of a symbol from its asset type and market cap.
+// Returns an empty class for equities whose market cap is unknown.
+func ClassifySymbol(symbol string, marketCap decimal.Decimal) SymbolClass {
+	if IsCryptoSymbol(symbol) {
+		return SymbolClassCrypto
+	}
+	switch {
+	case !marketCap.IsPositive():
+		return ""
+	case marketCap.GreaterThanOrEqual(MegaCapMin):
+		return SymbolClassMegaCap
+	case marketCap.GreaterThanOrEqual(LargeCapMin):
+		return SymbolClassLargeCap
+	case marketCap.GreaterThanOrEqual(MidCapMin):
+		return SymbolClassMidCap
+	default:
+		return SymbolClassSmallCap
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestClassifySymbol(t *testing.T) {
+	tests := []struct {
+		symbol    string
+		marketCap int64
+		want      SymbolClass
+	}{
+		{"BTC/USD", 0, SymbolClassCrypto},
+		{"ETH/USD", 1_000_000_000_000, SymbolClassCrypto},
+		{"AAPL", 3_000_000_000_000, SymbolClassMegaCap},
+		{"BIG", 200_000_000_000, SymbolClassMegaCap},
+		{"LRG", 50_000_000_000, SymbolClassLargeCap},
+		{"MID", 2_000_000_000, SymbolClassMidCap},
+		{"SML", 300_000_000, SymbolClassSmallCap},
+		{"UNK", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			if got := ClassifySymbol(tt.symbol, decimal.NewFromInt(tt.marketCap)); got != tt.want {
+				t.Errorf("ClassifySymbol(%q, %d) = %q, want %q", tt.symbol, tt.marketCap, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrSymbolBlocked is returned when analyzing or trading a symbol on the blocklist
+var ErrSymbolBlocked = errors.New("symbol is blocklisted")
+
+// SymbolListType identifies a user-managed symbol list
+type SymbolListType string
+
+const (
+	// SymbolListBlock holds symbols that are never analyzed or traded
+	SymbolListBlock SymbolListType = "block"
+	// SymbolListAllow restricts the screener to its symbols when non-empty
+	SymbolListAllow SymbolListType = "allow"
+)
+
+// Valid reports whether the list type is known
+func (t SymbolListType) Valid() bool {
+	return t == SymbolListBlock || t == SymbolListAllow
+}
+
+// SymbolListEntry is one symbol on a blocklist or allowlist
+type SymbolListEntry struct {
+	List      SymbolListType `json:"list"`
+	Symbol    string         `json:"symbol"`
+	Reason    string         `json:"reason,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// SymbolLists is the user's blocklist and allowlist, indexed for lookups
+type SymbolLists struct {
+	Blocked map[string]SymbolListEntry `json:"blocked"`
+	Allowed map[string]SymbolListEntry `json:"allowed"`
+}
+
+// NewSymbolLists indexes list entries by symbol
+func NewSymbolLists(entries []SymbolListEntry) *SymbolLists {
+	lists := &SymbolLists{
+		Blocked: make(map[string]SymbolListEntry),
+		Allowed: make(map[string]SymbolListEntry),
+	}
+	for _, e := range entries {
+		switch e.List {
+		case SymbolListBlock:
+			lists.Blocked[e.Symbol] = e
+		case SymbolListAllow:
+			lists.Allowed[e.Symbol] = e
+		}
+	}
+	return lists
+}
+
+// IsBlocked reports whether a symbol is on the blocklist
+func (l *SymbolLists) IsBlocked(symbol string) bool {
+	if l == nil {
+		return false
+	}
+	_, ok := l.Blocked[symbol]
+	return ok
+}
+
+// Screenable reports whether the screener may consider a symbol: it must not be
+// blocked and, when an allowlist exists, must be on it
+func (l *SymbolLists) Screenable(symbol string) bool {
+	if l == nil {
+		return true
+	}
+	if l.IsBlocked(symbol) {
+		return false
+	}
+	if len(l.Allowed) == 0 {
+		return true
+	}
+	_, ok := l.Allowed[symbol]
+	return ok
+}
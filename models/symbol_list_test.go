@@ -0,0 +1,45 @@
+package models
+
+import "testing"
+
+func TestSymbolLists(t *testing.T) {
+	lists := NewSymbolLists([]SymbolListEntry{
+		{List: SymbolListBlock, Symbol: "GME"},
+		{List: SymbolListAllow, Symbol: "AAPL"},
+		{List: SymbolListAllow, Symbol: "MSFT"},
+	})
+
+	if !lists.IsBlocked("GME") || lists.IsBlocked("AAPL") {
+		t.Error("IsBlocked should only match blocklisted symbols")
+	}
+	if !lists.Screenable("AAPL") {
+		t.Error("allowlisted symbol should be screenable")
+	}
+	if lists.Screenable("TSLA") {
+		t.Error("symbol outside a non-empty allowlist should not be screenable")
+	}
+	if lists.Screenable("GME") {
+		t.Error("blocked symbol should not be screenable")
+	}
+}
+
+func TestSymbolLists_NoAllowlist(t *testing.T) {
+	lists := NewSymbolLists([]SymbolListEntry{{List: SymbolListBlock, Symbol: "GME"}})
+	if !lists.Screenable("TSLA") {
+		t.Error("any unblocked symbol should be screenable without an allowlist")
+	}
+
+	var none *SymbolLists
+	if none.IsBlocked("GME") || !none.Screenable("GME") {
+		t.Error("nil lists should block nothing")
+	}
+}
+
+func TestSymbolListType_Valid(t *testing.T) {
+	if !SymbolListBlock.Valid() || !SymbolListAllow.Valid() {
+		t.Error("block and allow should be valid")
+	}
+	if SymbolListType("watch").Valid() {
+		t.Error("unknown list type should be invalid")
+	}
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// TimeframeScores holds the technical analyst's sub-scores (-100 to 100) for each timeframe.
+// A timeframe is nil when there was too little price history to score it.
+type TimeframeScores struct {
+	Short  *float64 `json:"short,omitempty"`  // About 2 weeks of daily bars
+	Medium *float64 `json:"medium,omitempty"` // About 3 months of daily bars
+	Long   *float64 `json:"long,omitempty"`   // About 1 year of daily bars
+}
+
+// IsZero reports whether no timeframe was scored
+func (s TimeframeScores) IsZero() bool {
+	return s.Short == nil && s.Medium == nil && s.Long == nil
+}
+
+// Validate checks that every scored timeframe is a finite score between -100 and 100
+func (s TimeframeScores) Validate() error {
+	for _, tf := range []struct {
+		name  string
+		score *float64
+	}{{"short", s.Short}, {"medium", s.Medium}, {"long", s.Long}} {
+		if tf.score == nil {
+			continue
+		}
+		if v := *tf.score; math.IsNaN(v) || v < -100 || v > 100 {
+			return fmt.Errorf("%s timeframe score %v is outside -100 to 100", tf.name, v)
+		}
+	}
+	return nil
+}
+
+// AnalysisHorizon is the holding period recommendations are made for. It decides how the
+// technical timeframes are weighted into the technical score.
+type AnalysisHorizon string
+
+const (
+	// HorizonOverall uses the technical analyst's overall score and ignores the timeframes
+	HorizonOverall AnalysisHorizon = ""
+	HorizonShort   AnalysisHorizon = "short"
+	HorizonMedium  AnalysisHorizon = "medium"
+	HorizonLong    AnalysisHorizon = "long"
+)
+
+// ParseAnalysisHorizon validates a horizon name; an empty name is HorizonOverall
+func ParseAnalysisHorizon(s string) (AnalysisHorizon, error) {
+	switch h := AnalysisHorizon(s); h {
+	case HorizonOverall, HorizonShort, HorizonMedium, HorizonLong:
+		return h, nil
+	}
+	return HorizonOverall, fmt.Errorf("unknown analysis horizon %q, expected short, medium, or long", s)
+}
+
+// TimeframeWeights returns the weights of the short, medium and long timeframes for the
+// horizon. They sum to 1; HorizonOverall weights nothing.
+func (h AnalysisHorizon) TimeframeWeights() (short, medium, long float64) {
+	switch h {
+	case HorizonShort:
+		return 0.6, 0.3, 0.1
+	case HorizonMedium:
+		return 0.25, 0.5, 0.25
+	case HorizonLong:
+		return 0.1, 0.3, 0.6
+	}
+	return 0, 0, 0
+}
+
+// Weighted combines the scored timeframes with the horizon's weights, spreading the weight
+// of unscored timeframes across the others. Returns false if nothing could be combined.
+func (s TimeframeScores) Weighted(h AnalysisHorizon) (float64, bool) {
+	ws, wm, wl := h.TimeframeWeights()
+
+	var total, weight float64
+	for _, tf := range []struct {
+		score  *float64
+		weight float64
+	}{{s.Short, ws}, {s.Medium, wm}, {s.Long, wl}} {
+		if tf.score != nil && tf.weight > 0 {
+			total += *tf.score * tf.weight
+			weight += tf.weight
+		}
+	}
+	if weight == 0 {
+		return 0, false
+	}
+	return total / weight, true
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTimeframeScores_Weighted(t *testing.T) {
+	short, medium, long := 80.0, 20.0, -40.0
+	full := TimeframeScores{Short: &short, Medium: &medium, Long: &long}
+
+	tests := []struct {
+		name    string
+		scores  TimeframeScores
+		horizon AnalysisHorizon
+		want    float64
+		wantOK  bool
+	}{
+		{"short horizon", full, HorizonShort, 0.6*80 + 0.3*20 + 0.1*-40, true},
+		{"long horizon", full, HorizonLong, 0.1*80 + 0.3*20 + 0.6*-40, true},
+		{"missing long is redistributed", TimeframeScores{Short: &short, Medium: &medium}, HorizonLong, (0.1*80 + 0.3*20) / 0.4, true},
+		{"overall ignores timeframes", full, HorizonOverall, 0, false},
+		{"nothing scored", TimeframeScores{}, HorizonMedium, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.scores.Weighted(tt.horizon)
+			if ok != tt.wantOK || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Weighted() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseAnalysisHorizon(t *testing.T) {
+	for _, s := range []string{"", "short", "medium", "long"} {
+		if _, err := ParseAnalysisHorizon(s); err != nil {
+			t.Errorf("ParseAnalysisHorizon(%q) error = %v", s, err)
+		}
+	}
+	if _, err := ParseAnalysisHorizon("weekly"); err == nil {
+		t.Error("expected error for unknown horizon")
+	}
+}
+
+func TestTimeframeScores_Validate(t *testing.T) {
+	valid, high, nan := -100.0, 100.5, math.NaN()
+	tests := []struct {
+		name    string
+		scores  TimeframeScores
+		wantErr bool
+	}{
+		{"unscored", TimeframeScores{}, false},
+		{"bounds", TimeframeScores{Short: &valid}, false},
+		{"above 100", TimeframeScores{Medium: &high}, true},
+		{"not a number", TimeframeScores{Long: &nan}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.scores.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
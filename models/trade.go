@@ -8,17 +8,20 @@ import (
 )
 
 type Trade struct {
-	ID            uuid.UUID       `json:"id"`
-	Symbol        string          `json:"symbol"`
-	Side          TradeSide       `json:"side"`
-	Quantity      decimal.Decimal `json:"quantity"`
-	Price         decimal.Decimal `json:"price"`
-	TotalValue    decimal.Decimal `json:"total_value"`
-	Commission    decimal.Decimal `json:"commission"`
-	Status        TradeStatus     `json:"status"`
-	AlpacaOrderID string          `json:"alpaca_order_id,omitempty"`
-	ExecutedAt    *time.Time      `json:"executed_at,omitempty"`
-	CreatedAt     time.Time       `json:"created_at"`
+	ID             uuid.UUID       `json:"id"`
+	Symbol         string          `json:"symbol"`
+	Side           TradeSide       `json:"side"`
+	Quantity       decimal.Decimal `json:"quantity"`
+	FilledQuantity decimal.Decimal `json:"filled_quantity"` // Shares the broker has filled so far
+	Price          decimal.Decimal `json:"price"`
+	TotalValue     decimal.Decimal `json:"total_value"`
+	Commission     decimal.Decimal `json:"commission"`
+	Fees           decimal.Decimal `json:"fees"` // Regulatory and exchange fees, separate from commission
+	Status         TradeStatus     `json:"status"`
+	AlpacaOrderID  string          `json:"alpaca_order_id,omitempty"` // The broker's order ID, whichever broker placed it
+	Broker         string          `json:"broker,omitempty"`          // Broker the order was routed to
+	ExecutedAt     *time.Time      `json:"executed_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
 }
 
 type TradeSide string
@@ -31,12 +34,43 @@ const (
 type TradeStatus string
 
 const (
-	TradeStatusPending   TradeStatus = "pending"
-	TradeStatusExecuted  TradeStatus = "executed"
-	TradeStatusRejected  TradeStatus = "rejected"
-	TradeStatusCancelled TradeStatus = "cancelled"
+	TradeStatusPending         TradeStatus = "pending" // Submitted to the broker, nothing filled yet
+	TradeStatusPartiallyFilled TradeStatus = "partially_filled"
+	TradeStatusExecuted        TradeStatus = "executed"
+	TradeStatusRejected        TradeStatus = "rejected"
+	TradeStatusCancelled       TradeStatus = "cancelled"
 )
 
+// BrokerName returns the broker the trade's order was routed to, treating an unset broker
+// as Alpaca, which placed every order before other brokers were supported
+func (t *Trade) BrokerName() string {
+	if t.Broker == "" {
+		return BrokerAlpaca
+	}
+	return t.Broker
+}
+
+// PositionQuantity returns the shares of the trade the local position holds. Execution books
+// the whole order when it is placed, so that is all of it while nothing has filled, then the
+// shares the broker has filled once it fills in part or settles. An order cancelled,
+// rejected or expired unfilled leaves none.
+func (t *Trade) PositionQuantity() decimal.Decimal {
+	switch t.Status {
+	case TradeStatusPending:
+		return t.Quantity
+	case TradeStatusExecuted:
+		if !t.FilledQuantity.IsPositive() {
+			return t.Quantity // Recorded as executed before fills were tracked
+		}
+	}
+	return t.FilledQuantity
+}
+
+// Open reports whether the trade's order may still fill at the broker
+func (s TradeStatus) Open() bool {
+	return s == TradeStatusPending || s == TradeStatusPartiallyFilled
+}
+
 func NewTrade(symbol string, side TradeSide, quantity, price decimal.Decimal) *Trade {
 	return &Trade{
 		ID:         uuid.New(),
@@ -46,7 +80,28 @@ func NewTrade(symbol string, side TradeSide, quantity, price decimal.Decimal) *T
 		Price:      price,
 		TotalValue: quantity.Mul(price),
 		Commission: decimal.Zero,
+		Fees:       decimal.Zero,
 		Status:     TradeStatusPending,
 		CreatedAt:  time.Now(),
 	}
 }
+
+// TotalFees returns the commission plus fees paid on the trade
+func (t *Trade) TotalFees() decimal.Decimal {
+	return t.Commission.Add(t.Fees)
+}
+
+// CashFlow returns the net cash moved by the trade after fees: negative for buys, positive for sells
+func (t *Trade) CashFlow() decimal.Decimal {
+	if t.Side == TradeSideSell {
+		return t.TotalValue.Sub(t.TotalFees())
+	}
+	return t.TotalValue.Add(t.TotalFees()).Neg()
+}
+
+// TradeDetail is a trade with its order as the broker currently reports it
+type TradeDetail struct {
+	Trade
+	BrokerOrder *BrokerOrder `json:"broker_order,omitempty"`
+	BrokerError string       `json:"broker_error,omitempty"` // Why the broker order could not be loaded
+}
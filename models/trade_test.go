@@ -122,3 +122,76 @@ func TestNewTrade_DifferentValues(t *testing.T) {
 		})
 	}
 }
+
+func TestTrade_CashFlow(t *testing.T) {
+	buy := NewTrade("AAPL", TradeSideBuy, decimal.NewFromInt(10), decimal.NewFromInt(100))
+	buy.Commission = decimal.NewFromInt(1)
+	if !buy.CashFlow().Equal(decimal.NewFromInt(-1001)) {
+		t.Errorf("buy CashFlow = %v, want -1001", buy.CashFlow())
+	}
+
+	sell := NewTrade("AAPL", TradeSideSell, decimal.NewFromInt(10), decimal.NewFromInt(100))
+	sell.Commission = decimal.NewFromInt(1)
+	sell.Fees = decimal.NewFromFloat(0.03)
+	if !sell.TotalFees().Equal(decimal.NewFromFloat(1.03)) {
+		t.Errorf("TotalFees = %v, want 1.03", sell.TotalFees())
+	}
+	if !sell.CashFlow().Equal(decimal.NewFromFloat(998.97)) {
+		t.Errorf("sell CashFlow = %v, want 998.97", sell.CashFlow())
+	}
+}
+
+func TestFeeSchedule_Apply(t *testing.T) {
+	schedule := NewFeeSchedule(1, 0.005, 0.0000278)
+
+	buy := NewTrade("AAPL", TradeSideBuy, decimal.NewFromInt(100), decimal.NewFromInt(150))
+	schedule.Apply(buy)
+	if !buy.Commission.Equal(decimal.NewFromFloat(1.5)) || !buy.Fees.IsZero() {
+		t.Errorf("buy commission/fees = %v/%v, want 1.5/0", buy.Commission, buy.Fees)
+	}
+
+	sell := NewTrade("AAPL", TradeSideSell, decimal.NewFromInt(100), decimal.NewFromInt(150))
+	schedule.Apply(sell)
+	if !sell.Fees.Equal(decimal.NewFromFloat(0.42)) {
+		t.Errorf("sell fees = %v, want 0.42", sell.Fees)
+	}
+
+	// An order still filling pays on the shares filled so far
+	partial := NewTrade("AAPL", TradeSideSell, decimal.NewFromInt(100), decimal.NewFromInt(150))
+	partial.FilledQuantity = decimal.NewFromInt(40)
+	schedule.Apply(partial)
+	if !partial.Commission.Equal(decimal.NewFromFloat(1.2)) || !partial.Fees.Equal(decimal.NewFromFloat(0.17)) {
+		t.Errorf("partial commission/fees = %v/%v, want 1.2/0.17", partial.Commission, partial.Fees)
+	}
+
+	// A zero schedule keeps broker-reported values
+	untouched := NewTrade("AAPL", TradeSideSell, decimal.NewFromInt(1), decimal.NewFromInt(10))
+	untouched.Fees = decimal.NewFromFloat(0.01)
+	FeeSchedule{}.Apply(untouched)
+	if !untouched.Fees.Equal(decimal.NewFromFloat(0.01)) {
+		t.Errorf("zero schedule changed fees to %v", untouched.Fees)
+	}
+}
+
+func TestTrade_PositionQuantity(t *testing.T) {
+	tests := []struct {
+		status TradeStatus
+		filled int64
+		want   int64
+	}{
+		{TradeStatusPending, 0, 10},
+		{TradeStatusPartiallyFilled, 4, 4},
+		{TradeStatusExecuted, 10, 10},
+		{TradeStatusExecuted, 6, 6}, // Cancelled after filling in part
+		{TradeStatusExecuted, 0, 10},
+		{TradeStatusCancelled, 0, 0},
+		{TradeStatusRejected, 0, 0},
+	}
+	for _, tt := range tests {
+		trade := NewTrade("AAPL", TradeSideBuy, decimal.NewFromInt(10), decimal.NewFromInt(100))
+		trade.Status, trade.FilledQuantity = tt.status, decimal.NewFromInt(tt.filled)
+		if got := trade.PositionQuantity(); !got.Equal(decimal.NewFromInt(tt.want)) {
+			t.Errorf("%s with %d filled: PositionQuantity() = %s, want %d", tt.status, tt.filled, got, tt.want)
+		}
+	}
+}
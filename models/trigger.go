@@ -0,0 +1,59 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrAnalysisQueueFull is returned when every analysis slot is in use
+var ErrAnalysisQueueFull = errors.New("analysis queue full, too many concurrent requests - try again later")
+
+type triggerReasonKey struct{}
+
+// WithTriggerReason returns a context that records why an analysis was started
+// automatically, so the resulting recommendation can carry the reason
+func WithTriggerReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, triggerReasonKey{}, reason)
+}
+
+// TriggerReasonFromContext returns the trigger reason set by WithTriggerReason, or ""
+func TriggerReasonFromContext(ctx context.Context) string {
+	reason, _ := ctx.Value(triggerReasonKey{}).(string)
+	return reason
+}
+
+type previousRecommendationKey struct{}
+
+// WithPreviousRecommendation returns a context that records the recommendation a requested
+// re-analysis follows, so the resulting recommendation links back to it
+func WithPreviousRecommendation(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, previousRecommendationKey{}, id)
+}
+
+// PreviousRecommendationFromContext returns the ID set by WithPreviousRecommendation, or nil
+func PreviousRecommendationFromContext(ctx context.Context) *uuid.UUID {
+	id, ok := ctx.Value(previousRecommendationKey{}).(uuid.UUID)
+	if !ok {
+		return nil
+	}
+	return &id
+}
+
+type agentsDoneKey struct{}
+
+// WithAgentsDone returns a context whose analysis calls done once the agents still running
+// behind a partial recommendation have all returned, so a caller limiting concurrent
+// analyses can hold its slot until then
+func WithAgentsDone(ctx context.Context, done func()) context.Context {
+	return context.WithValue(ctx, agentsDoneKey{}, done)
+}
+
+// AgentsDoneFromContext returns the callback set by WithAgentsDone, or a no-op
+func AgentsDoneFromContext(ctx context.Context) func() {
+	if done, ok := ctx.Value(agentsDoneKey{}).(func()); ok {
+		return done
+	}
+	return func() {}
+}
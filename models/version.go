@@ -0,0 +1,10 @@
+package models
+
+import "errors"
+
+// ErrVersionConflict is returned when an update was based on a stale row version,
+// meaning another writer changed the row since it was read
+var ErrVersionConflict = errors.New("record was modified by another request")
+
+// AnyVersion skips the optimistic concurrency check on an update
+const AnyVersion = 0
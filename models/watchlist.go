@@ -0,0 +1,164 @@
+package models
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxWatchlistImportRows caps how many tickers a single import may contain
+const MaxWatchlistImportRows = 500
+
+// MaxWatchlistAnalyses caps how many symbols a single import queues for analysis
+const MaxWatchlistAnalyses = 50
+
+// ErrInvalidWatchlistImport is returned when an import cannot be parsed or has no valid tickers
+var ErrInvalidWatchlistImport = errors.New("invalid watchlist import")
+
+var tickerPattern = regexp.MustCompile(`^[A-Z0-9.-]{1,10}$`)
+
+// Watchlist is a named list of symbols imported by the user
+type Watchlist struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Symbols   []string  `json:"symbols"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WatchlistRowStatus is the validation outcome for one imported ticker
+type WatchlistRowStatus string
+
+const (
+	WatchlistRowValid     WatchlistRowStatus = "valid"
+	WatchlistRowUnknown   WatchlistRowStatus = "unknown_symbol"
+	WatchlistRowDuplicate WatchlistRowStatus = "duplicate"
+)
+
+// WatchlistImportRow is one ticker from an import and whether it made it onto the watchlist
+type WatchlistImportRow struct {
+	Line    int                `json:"line"`
+	Input   string             `json:"input"`
+	Symbol  string             `json:"symbol,omitempty"`
+	Status  WatchlistRowStatus `json:"status"`
+	Message string             `json:"message,omitempty"`
+}
+
+// WatchlistImport is the result of importing a ticker list
+type WatchlistImport struct {
+	Watchlist *Watchlist           `json:"watchlist"`
+	Rows      []WatchlistImportRow `json:"rows"`
+	Queued    []string             `json:"queued,omitempty"`     // Symbols queued for analysis
+	NotQueued []string             `json:"not_queued,omitempty"` // Symbols past MaxWatchlistAnalyses, left unanalyzed
+}
+
+// Count returns the number of rows with the given status
+func (w *WatchlistImport) Count(status WatchlistRowStatus) int {
+	n := 0
+	for _, row := range w.Rows {
+		if row.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// ParseWatchlistImport reads tickers from CSV or plain text. When the first row has a
+// "symbol" or "ticker" column only that column is read; otherwise every comma or
+// whitespace separated field is a ticker. Malformed tickers are marked unknown and
+// repeats are marked duplicate.
+func ParseWatchlistImport(data string) ([]WatchlistImportRow, error) {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	column := -1
+	seen := make(map[string]bool)
+	var rows []WatchlistImportRow
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWatchlistImport, err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if first {
+			if column = headerColumn(record); column >= 0 {
+				continue
+			}
+		}
+
+		fields := record
+		if column >= 0 {
+			fields = nil
+			if column < len(record) {
+				fields = record[column : column+1]
+			}
+		}
+		for _, field := range fields {
+			for _, input := range strings.Fields(field) {
+				rows = append(rows, newImportRow(line, input, seen))
+			}
+		}
+		if len(rows) > MaxWatchlistImportRows {
+			return nil, fmt.Errorf("%w: more than %d tickers", ErrInvalidWatchlistImport, MaxWatchlistImportRows)
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no tickers found", ErrInvalidWatchlistImport)
+	}
+	return rows, nil
+}
+
+// headerColumn returns the index of the symbol column in a header row, or -1
+func headerColumn(record []string) int {
+	for i, field := range record {
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "symbol", "ticker":
+			return i
+		}
+	}
+	return -1
+}
+
+func newImportRow(line int, input string, seen map[string]bool) WatchlistImportRow {
+	symbol := strings.ToUpper(strings.TrimPrefix(input, "$"))
+	row := WatchlistImportRow{Line: line, Input: input, Symbol: symbol, Status: WatchlistRowValid}
+	switch {
+	case !tickerPattern.MatchString(symbol):
+		row.Symbol = ""
+		row.Status = WatchlistRowUnknown
+		row.Message = "not a ticker symbol"
+	case seen[symbol]:
+		row.Status = WatchlistRowDuplicate
+		row.Message = fmt.Sprintf("%s is already in the list", symbol)
+	default:
+		seen[symbol] = true
+	}
+	return row
+}
+
+// NewWatchlist creates a watchlist from the valid rows of an import
+func NewWatchlist(name string, rows []WatchlistImportRow) *Watchlist {
+	w := &Watchlist{
+		ID:        uuid.New(),
+		Name:      name,
+		Symbols:   []string{},
+		CreatedAt: time.Now(),
+	}
+	for _, row := range rows {
+		if row.Status == WatchlistRowValid {
+			w.Symbols = append(w.Symbols, row.Symbol)
+		}
+	}
+	return w
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseWatchlistImport(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantStatus []WatchlistRowStatus
+		wantLines  []int
+	}{
+		{
+			name:       "plain text",
+			data:       "aapl\nMSFT $nvda\n\nAAPL\n",
+			wantStatus: []WatchlistRowStatus{WatchlistRowValid, WatchlistRowValid, WatchlistRowValid, WatchlistRowDuplicate},
+			wantLines:  []int{1, 2, 2, 4},
+		},
+		{
+			name:       "comma separated",
+			data:       "AAPL, MSFT,BRK.B,???",
+			wantStatus: []WatchlistRowStatus{WatchlistRowValid, WatchlistRowValid, WatchlistRowValid, WatchlistRowUnknown},
+			wantLines:  []int{1, 1, 1, 1},
+		},
+		{
+			name:       "csv with header",
+			data:       "Name,Ticker,Shares\nApple,AAPL,10\nMicrosoft,MSFT,5\nShort row\n",
+			wantStatus: []WatchlistRowStatus{WatchlistRowValid, WatchlistRowValid},
+			wantLines:  []int{2, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ParseWatchlistImport(tt.data)
+			if err != nil {
+				t.Fatalf("ParseWatchlistImport() error = %v", err)
+			}
+			if len(rows) != len(tt.wantStatus) {
+				t.Fatalf("got %d rows, want %d: %+v", len(rows), len(tt.wantStatus), rows)
+			}
+			for i, row := range rows {
+				if row.Status != tt.wantStatus[i] || row.Line != tt.wantLines[i] {
+					t.Errorf("row %d = %+v, want status %s on line %d", i, row, tt.wantStatus[i], tt.wantLines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseWatchlistImport_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"empty":       "  \n\n",
+		"header only": "symbol\n",
+		"too many":    strings.Repeat("AAPL\n", MaxWatchlistImportRows+1),
+	} {
+		if _, err := ParseWatchlistImport(data); !errors.Is(err, ErrInvalidWatchlistImport) {
+			t.Errorf("%s: error = %v, want ErrInvalidWatchlistImport", name, err)
+		}
+	}
+}
+
+func TestNewWatchlist(t *testing.T) {
+	rows, err := ParseWatchlistImport("AAPL\nMSFT\nAAPL\n???")
+	if err != nil {
+		t.Fatalf("ParseWatchlistImport() error = %v", err)
+	}
+	w := NewWatchlist("Tech", rows)
+	if len(w.Symbols) != 2 || w.Symbols[0] != "AAPL" || w.Symbols[1] != "MSFT" {
+		t.Errorf("Symbols = %v, want [AAPL MSFT]", w.Symbols)
+	}
+}
@@ -0,0 +1,142 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+
+	"trade-machine/events"
+)
+
+// ErrInvalidConfig is returned for notification settings that can't be used
+var ErrInvalidConfig = errors.New("invalid notification settings")
+
+// masked replaces secrets in settings returned by the API
+const masked = "****"
+
+// Config selects the channels notifications go to and the events they are sent for
+type Config struct {
+	SlackWebhookURL   string        `json:"slack_webhook_url,omitempty"`
+	DiscordWebhookURL string        `json:"discord_webhook_url,omitempty"`
+	SMTP              *SMTPConfig   `json:"smtp,omitempty"`
+	Events            []events.Type `json:"events,omitempty"` // Empty sends all of Events
+}
+
+// SMTPConfig is a mail server notifications are emailed through
+type SMTPConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port,omitempty"` // Default 587
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// Validate checks that webhook URLs are absolute HTTP(S) URLs, that email has a server,
+// a sender and recipients, and that only notification events are selected
+func (c Config) Validate() error {
+	for name, raw := range map[string]string{"slack_webhook_url": c.SlackWebhookURL, "discord_webhook_url": c.DiscordWebhookURL} {
+		if raw == "" {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("%w: %s must be an http or https URL", ErrInvalidConfig, name)
+		}
+	}
+	if s := c.SMTP; s != nil {
+		if s.Host == "" {
+			return fmt.Errorf("%w: smtp host is required", ErrInvalidConfig)
+		}
+		if s.Port < 0 || s.Port > 65535 {
+			return fmt.Errorf("%w: smtp port must be between 1 and 65535", ErrInvalidConfig)
+		}
+		if _, err := mail.ParseAddress(s.From); err != nil {
+			return fmt.Errorf("%w: smtp from address %q: %v", ErrInvalidConfig, s.From, err)
+		}
+		if len(s.To) == 0 {
+			return fmt.Errorf("%w: smtp needs at least one recipient", ErrInvalidConfig)
+		}
+		for _, to := range s.To {
+			if _, err := mail.ParseAddress(to); err != nil {
+				return fmt.Errorf("%w: smtp recipient %q: %v", ErrInvalidConfig, to, err)
+			}
+		}
+	}
+	for _, t := range c.Events {
+		if !slices.Contains(Events, t) {
+			return fmt.Errorf("%w: notifications are not sent for %q", ErrInvalidConfig, t)
+		}
+	}
+	return nil
+}
+
+// Wants reports whether notifications are sent for events of type t
+func (c Config) Wants(t events.Type) bool {
+	if len(c.Events) == 0 {
+		return slices.Contains(Events, t)
+	}
+	return slices.Contains(c.Events, t)
+}
+
+// Channels returns a channel for each destination the configuration sets
+func (c Config) Channels() []Channel {
+	var channels []Channel
+	if c.SlackWebhookURL != "" {
+		channels = append(channels, NewSlackChannel(c.SlackWebhookURL))
+	}
+	if c.DiscordWebhookURL != "" {
+		channels = append(channels, NewDiscordChannel(c.DiscordWebhookURL))
+	}
+	if c.SMTP != nil {
+		channels = append(channels, NewSMTPChannel(*c.SMTP))
+	}
+	return channels
+}
+
+// Masked returns the configuration with the webhook URLs' paths, which carry their
+// tokens, and the SMTP password hidden
+func (c Config) Masked() Config {
+	c.SlackWebhookURL = maskURL(c.SlackWebhookURL)
+	c.DiscordWebhookURL = maskURL(c.DiscordWebhookURL)
+	if c.SMTP != nil {
+		smtp := *c.SMTP
+		if smtp.Password != "" {
+			smtp.Password = masked
+		}
+		c.SMTP = &smtp
+	}
+	return c
+}
+
+// WithSecretsFrom returns the configuration with any secret still masked as Masked
+// returned it replaced by current's, so settings read from the API can be saved back
+// after changing other fields
+func (c Config) WithSecretsFrom(current Config) Config {
+	if c.SlackWebhookURL != "" && c.SlackWebhookURL == maskURL(current.SlackWebhookURL) {
+		c.SlackWebhookURL = current.SlackWebhookURL
+	}
+	if c.DiscordWebhookURL != "" && c.DiscordWebhookURL == maskURL(current.DiscordWebhookURL) {
+		c.DiscordWebhookURL = current.DiscordWebhookURL
+	}
+	if c.SMTP != nil && c.SMTP.Password == masked && current.SMTP != nil {
+		smtp := *c.SMTP
+		smtp.Password = current.SMTP.Password
+		c.SMTP = &smtp
+	}
+	return c
+}
+
+// maskURL keeps a URL's scheme and host, so the service it points at stays recognizable
+func maskURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return masked
+	}
+	return u.Scheme + "://" + u.Host + "/" + masked
+}
@@ -0,0 +1,109 @@
+package notifications
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"trade-machine/events"
+	"trade-machine/models"
+)
+
+// maxReasoningLength is how much of a recommendation's reasoning a notification quotes
+const maxReasoningLength = 300
+
+// MessageFor returns the notification for an event, and false for events that don't
+// warrant one: recommendations that aren't waiting for approval, including holds, and
+// event types other than Events
+func MessageFor(e events.Event) (Message, bool) {
+	switch e.Type {
+	case events.RecommendationCreated:
+		if rec := e.Recommendation(); rec != nil && rec.Status == models.RecommendationStatusPending && rec.Action != models.RecommendationActionHold {
+			return recommendationMessage(rec), true
+		}
+	case events.TradeFilled:
+		if trade := e.Trade(); trade != nil {
+			return tradeMessage(trade), true
+		}
+	case events.ScreenerCompleted:
+		if run := e.ScreenerRun(); run != nil {
+			return screenerMessage(run), true
+		}
+	case events.BreakerOpened:
+		if b, ok := e.BreakerOpen(); ok {
+			return breakerMessage(b), true
+		}
+	}
+	return Message{}, false
+}
+
+func recommendationMessage(rec *models.Recommendation) Message {
+	var text strings.Builder
+	fmt.Fprintf(&text, "Confidence %.0f%%", rec.Confidence)
+	if rec.Quantity.IsPositive() {
+		fmt.Fprintf(&text, ", %s shares", rec.Quantity.String())
+	}
+	if rec.EntryPrice.IsPositive() {
+		fmt.Fprintf(&text, " at $%s", rec.EntryPrice.StringFixed(2))
+	}
+	if rec.TargetPrice.IsPositive() && rec.StopPrice.IsPositive() {
+		fmt.Fprintf(&text, " (target $%s, stop $%s)", rec.TargetPrice.StringFixed(2), rec.StopPrice.StringFixed(2))
+	}
+	if reasoning := truncate(rec.Reasoning, maxReasoningLength); reasoning != "" {
+		text.WriteString("\n" + reasoning)
+	}
+	return Message{
+		Title: fmt.Sprintf("%s %s awaiting approval", strings.ToUpper(string(rec.Action)), rec.Symbol),
+		Text:  text.String(),
+	}
+}
+
+func tradeMessage(trade *models.Trade) Message {
+	quantity := trade.FilledQuantity
+	if !quantity.IsPositive() {
+		quantity = trade.Quantity
+	}
+	return Message{
+		Title: fmt.Sprintf("Trade executed: %s %s %s at $%s", strings.ToUpper(string(trade.Side)), quantity.String(), trade.Symbol, trade.Price.StringFixed(2)),
+		Text:  fmt.Sprintf("Total $%s via %s", trade.TotalValue.StringFixed(2), trade.BrokerName()),
+	}
+}
+
+func screenerMessage(run *models.ScreenerRun) Message {
+	if run.Status == models.ScreenerRunStatusFailed {
+		return Message{Title: "Screener run failed", Text: run.Error}
+	}
+
+	var picks []string
+	for _, id := range run.TopPicks {
+		for _, c := range run.Candidates {
+			if c.RecommendationID != nil && *c.RecommendationID == id {
+				picks = append(picks, c.Symbol)
+				break
+			}
+		}
+	}
+	text := fmt.Sprintf("%d candidates screened in %s", len(run.Candidates), (time.Duration(run.DurationMs) * time.Millisecond).Round(time.Second))
+	if len(picks) > 0 {
+		text += ", top picks: " + strings.Join(picks, ", ")
+	}
+	return Message{Title: "Screener run completed", Text: text}
+}
+
+func breakerMessage(b events.BreakerOpen) Message {
+	text := fmt.Sprintf("Calls are paused until %s UTC", b.RecoverAt.UTC().Format("15:04"))
+	if b.LastError != "" {
+		text += "\nLast error: " + b.LastError
+	}
+	return Message{Title: fmt.Sprintf("Circuit breaker open for %s", b.Provider), Text: text}
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	s = strings.TrimSpace(s)
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
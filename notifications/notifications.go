@@ -0,0 +1,80 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"trade-machine/events"
+	"trade-machine/observability"
+)
+
+// sendTimeout bounds how long one event's notifications may take across all channels
+const sendTimeout = 15 * time.Second
+
+// Events are the domain events notifications can be sent for
+var Events = []events.Type{
+	events.RecommendationCreated,
+	events.TradeFilled,
+	events.ScreenerCompleted,
+	events.BreakerOpened,
+}
+
+// Message is a notification as sent to every channel
+type Message struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// Channel delivers notifications to one destination
+type Channel interface {
+	// Name identifies the channel in errors and logs
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// Notifier sends a notification to the configured channels for each event it handles.
+// The configuration is read for every event, so settings changes apply without a restart.
+type Notifier struct {
+	config func() Config
+}
+
+// NewNotifier creates a notifier reading its channels and events from config
+func NewNotifier(config func() Config) *Notifier {
+	return &Notifier{config: config}
+}
+
+// HandleEvent notifies every configured channel of an event the configuration asks for.
+// Subscribe it to Events. Delivery failures are logged; a failed channel doesn't stop the others.
+func (n *Notifier) HandleEvent(e events.Event) {
+	cfg := n.config()
+	if !cfg.Wants(e.Type) {
+		return
+	}
+	channels := cfg.Channels()
+	if len(channels) == 0 {
+		return
+	}
+	msg, ok := MessageFor(e)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	if err := Send(ctx, channels, msg); err != nil {
+		observability.Warn("failed to send notification", "event", string(e.Type), "error", err)
+	}
+}
+
+// Send delivers msg to each channel, returning the errors of those that failed
+func Send(ctx context.Context, channels []Channel, msg Message) error {
+	var errs []error
+	for _, c := range channels {
+		if err := c.Send(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,204 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"trade-machine/events"
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestMessageFor(t *testing.T) {
+	recID := uuid.New()
+	recoverAt := time.Date(2026, 3, 2, 15, 30, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name      string
+		event     events.Event
+		wantTitle string
+		wantText  string
+	}{
+		{"pending recommendation", events.Event{Type: events.RecommendationCreated, Payload: &models.Recommendation{
+			Symbol: "AAPL", Action: models.RecommendationActionBuy, Status: models.RecommendationStatusPending, Confidence: 82,
+			Quantity: decimal.NewFromInt(10), EntryPrice: decimal.NewFromFloat(187.5), Reasoning: "Strong earnings",
+		}}, "BUY AAPL awaiting approval", "Confidence 82%, 10 shares at $187.50\nStrong earnings"},
+		{"executed trade", events.Event{Type: events.TradeFilled, Payload: &models.Trade{
+			Symbol: "MSFT", Side: models.TradeSideSell, Quantity: decimal.NewFromInt(5), Price: decimal.NewFromInt(400),
+			TotalValue: decimal.NewFromInt(2000), Broker: models.BrokerAlpaca,
+		}}, "Trade executed: SELL 5 MSFT at $400.00", "Total $2000.00 via alpaca"},
+		{"screener run", events.Event{Type: events.ScreenerCompleted, Payload: &models.ScreenerRun{
+			Status: models.ScreenerRunStatusCompleted, DurationMs: 61000, TopPicks: []uuid.UUID{recID},
+			Candidates: []models.ScreenerCandidate{{Symbol: "KO", RecommendationID: &recID}, {Symbol: "PEP"}},
+		}}, "Screener run completed", "2 candidates screened in 1m1s, top picks: KO"},
+		{"failed screener run", events.Event{Type: events.ScreenerCompleted, Payload: &models.ScreenerRun{
+			Status: models.ScreenerRunStatusFailed, Error: "FMP unavailable",
+		}}, "Screener run failed", "FMP unavailable"},
+		{"breaker opened", events.Event{Type: events.BreakerOpened, Payload: events.BreakerOpen{
+			Provider: "fmp", LastError: "status 503", RecoverAt: recoverAt,
+		}}, "Circuit breaker open for fmp", "Calls are paused until 15:30 UTC\nLast error: status 503"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, ok := MessageFor(tt.event)
+			if !ok {
+				t.Fatal("expected a notification")
+			}
+			if msg.Title != tt.wantTitle || msg.Text != tt.wantText {
+				t.Errorf("message = %+v, want %q / %q", msg, tt.wantTitle, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestMessageFor_Skipped(t *testing.T) {
+	for name, e := range map[string]events.Event{
+		"hold":            {Type: events.RecommendationCreated, Payload: &models.Recommendation{Action: models.RecommendationActionHold, Status: models.RecommendationStatusPending}},
+		"not pending":     {Type: events.RecommendationCreated, Payload: &models.Recommendation{Action: models.RecommendationActionBuy, Status: models.RecommendationStatusApproved}},
+		"other event":     {Type: events.AgentRunCompleted, Payload: &models.AgentRun{}},
+		"missing payload": {Type: events.TradeFilled},
+	} {
+		if _, ok := MessageFor(e); ok {
+			t.Errorf("%s: expected no notification", name)
+		}
+	}
+}
+
+func TestWebhookChannels(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		if strings.HasSuffix(r.URL.Path, "/broken") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("no_service"))
+		}
+	}))
+	defer srv.Close()
+	msg := Message{Title: "Screener run completed", Text: "2 candidates"}
+
+	if err := NewSlackChannel(srv.URL+"/slack").Send(context.Background(), msg); err != nil {
+		t.Fatalf("slack Send error = %v", err)
+	}
+	if got["text"] != "*Screener run completed*\n2 candidates" {
+		t.Errorf("slack payload = %v", got)
+	}
+
+	if err := NewDiscordChannel(srv.URL+"/discord").Send(context.Background(), msg); err != nil {
+		t.Fatalf("discord Send error = %v", err)
+	}
+	if got["content"] != "**Screener run completed**\n2 candidates" {
+		t.Errorf("discord payload = %v", got)
+	}
+
+	err := NewSlackChannel(srv.URL+"/broken").Send(context.Background(), msg)
+	if err == nil || !strings.Contains(err.Error(), "404: no_service") {
+		t.Errorf("Send error = %v, want the status and response", err)
+	}
+}
+
+func TestSMTPChannel(t *testing.T) {
+	var addr, from string
+	var to []string
+	var body string
+	c := NewSMTPChannel(SMTPConfig{Host: "mail.example.com", Username: "u", Password: "p", From: "tm@example.com", To: []string{"me@example.com"}})
+	c.sendMail = func(a string, _ smtp.Auth, f string, t []string, msg []byte) error {
+		addr, from, to, body = a, f, t, string(msg)
+		return nil
+	}
+
+	if err := c.Send(context.Background(), Message{Title: "Trade executed\r\nBcc: x@example.com", Text: "Total $2000.00"}); err != nil {
+		t.Fatalf("Send error = %v", err)
+	}
+	if addr != "mail.example.com:587" || from != "tm@example.com" || len(to) != 1 {
+		t.Errorf("sent to %s from %s for %v, want the default submission port", addr, from, to)
+	}
+	if !strings.Contains(body, "Subject: [trade-machine] Trade executed  Bcc: x@example.com\r\n") {
+		t.Errorf("body = %q, want the title on one subject line", body)
+	}
+	if !strings.HasSuffix(body, "\r\n\r\nTotal $2000.00\r\n") {
+		t.Errorf("body = %q, want the text after the headers", body)
+	}
+}
+
+type stubChannel struct {
+	name string
+	err  error
+	sent []Message
+}
+
+func (c *stubChannel) Name() string { return c.name }
+
+func (c *stubChannel) Send(_ context.Context, msg Message) error {
+	c.sent = append(c.sent, msg)
+	return c.err
+}
+
+func TestSend_ContinuesPastFailures(t *testing.T) {
+	failing := &stubChannel{name: "slack", err: errors.New("status 500")}
+	working := &stubChannel{name: "discord"}
+
+	err := Send(context.Background(), []Channel{failing, working}, Message{Title: "t"})
+	if err == nil || !strings.Contains(err.Error(), "slack: status 500") {
+		t.Errorf("Send error = %v, want the failed channel named", err)
+	}
+	if len(working.sent) != 1 {
+		t.Error("expected the other channel to be notified")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := Config{
+		SlackWebhookURL: "https://hooks.slack.com/services/T/B/x",
+		SMTP:            &SMTPConfig{Host: "mail.example.com", From: "tm@example.com", To: []string{"me@example.com"}},
+		Events:          []events.Type{events.TradeFilled},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate error = %v", err)
+	}
+
+	for name, cfg := range map[string]Config{
+		"relative webhook": {DiscordWebhookURL: "/api/webhooks/1"},
+		"no recipients":    {SMTP: &SMTPConfig{Host: "mail.example.com", From: "tm@example.com"}},
+		"bad sender":       {SMTP: &SMTPConfig{Host: "mail.example.com", From: "tm", To: []string{"me@example.com"}}},
+		"unknown event":    {Events: []events.Type{events.AgentRunStarted}},
+	} {
+		if err := cfg.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: Validate error = %v, want ErrInvalidConfig", name, err)
+		}
+	}
+}
+
+func TestConfig_WantsAndMasked(t *testing.T) {
+	cfg := Config{
+		DiscordWebhookURL: "https://discord.com/api/webhooks/1/secret",
+		SMTP:              &SMTPConfig{Host: "mail.example.com", Password: "hunter2"},
+	}
+	if !cfg.Wants(events.BreakerOpened) || cfg.Wants(events.AgentRunStarted) {
+		t.Error("expected every notification event and nothing else without a selection")
+	}
+	cfg.Events = []events.Type{events.TradeFilled}
+	if cfg.Wants(events.BreakerOpened) {
+		t.Error("expected only the selected events")
+	}
+
+	m := cfg.Masked()
+	if m.DiscordWebhookURL != "https://discord.com/****" || m.SMTP.Password != "****" {
+		t.Errorf("masked = %+v", m)
+	}
+	if cfg.SMTP.Password != "hunter2" {
+		t.Error("Masked must not change the original")
+	}
+
+	m.SMTP.Host = "smtp.example.com"
+	if restored := m.WithSecretsFrom(cfg); restored.DiscordWebhookURL != cfg.DiscordWebhookURL || restored.SMTP.Password != "hunter2" || restored.SMTP.Host != "smtp.example.com" {
+		t.Errorf("WithSecretsFrom = %+v, want the stored secrets and the changed host", restored)
+	}
+}
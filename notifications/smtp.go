@@ -0,0 +1,81 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSMTPPort is the mail submission port, used when none is configured
+const defaultSMTPPort = 587
+
+// SMTPChannel emails notifications through a mail server, upgrading to TLS when the
+// server offers STARTTLS
+type SMTPChannel struct {
+	cfg SMTPConfig
+	// sendMail delivers the message; smtp.SendMail outside tests
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+	now      func() time.Time
+}
+
+// NewSMTPChannel creates a channel emailing the configured recipients
+func NewSMTPChannel(cfg SMTPConfig) *SMTPChannel {
+	return &SMTPChannel{cfg: cfg, sendMail: smtp.SendMail, now: time.Now}
+}
+
+// Name returns "smtp"
+func (c *SMTPChannel) Name() string {
+	return "smtp"
+}
+
+// Send emails msg with its title as the subject. net/smtp takes no context, so a send
+// abandoned when ctx ends finishes in the background.
+func (c *SMTPChannel) Send(ctx context.Context, msg Message) error {
+	port := c.cfg.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(port))
+	var auth smtp.Auth
+	if c.cfg.Username != "" {
+		auth = smtp.PlainAuth("", c.cfg.Username, c.cfg.Password, c.cfg.Host)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.sendMail(addr, auth, c.cfg.From, c.cfg.To, c.compose(msg))
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send email: %w", ctx.Err())
+	}
+}
+
+// compose builds a plain text email. Line breaks are removed from the subject so a title
+// can't add headers.
+func (c *SMTPChannel) compose(msg Message) []byte {
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(msg.Title)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", c.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(c.cfg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "[trade-machine] "+subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", c.now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Text, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
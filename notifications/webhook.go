@@ -0,0 +1,73 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// discordMaxContent is the longest message Discord accepts
+const discordMaxContent = 2000
+
+// webhookClient posts to chat webhooks; each send is bounded by its context
+var webhookClient = &http.Client{}
+
+// WebhookChannel posts notifications as JSON to a chat service's incoming webhook
+type WebhookChannel struct {
+	name   string
+	url    string
+	format func(msg Message) any
+}
+
+// NewSlackChannel creates a channel posting to a Slack incoming webhook
+func NewSlackChannel(webhookURL string) *WebhookChannel {
+	return &WebhookChannel{name: "slack", url: webhookURL, format: func(msg Message) any {
+		return map[string]string{"text": fmt.Sprintf("*%s*\n%s", msg.Title, msg.Text)}
+	}}
+}
+
+// NewDiscordChannel creates a channel posting to a Discord webhook
+func NewDiscordChannel(webhookURL string) *WebhookChannel {
+	return &WebhookChannel{name: "discord", url: webhookURL, format: func(msg Message) any {
+		return map[string]string{"content": truncate(fmt.Sprintf("**%s**\n%s", msg.Title, msg.Text), discordMaxContent)}
+	}}
+}
+
+// Name returns the chat service the webhook belongs to
+func (c *WebhookChannel) Name() string {
+	return c.name
+}
+
+// Send posts msg to the webhook
+func (c *WebhookChannel) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(c.format(msg))
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		// The URL is the webhook's credential, so it stays out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
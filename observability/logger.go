@@ -12,38 +12,51 @@ var Logger *slog.Logger
 // InitLogger initializes the global logger with the appropriate handler
 // For production, use JSON format; for development, use text format
 func InitLogger(production bool) {
-	var handler slog.Handler
-
+	// The handler accepts every level; the global logger and each module logger filter
+	// by their own level in front of it
 	opts := &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+		Level: slog.LevelDebug,
 	}
 
+	var handler slog.Handler
 	if production {
 		handler = slog.NewJSONHandler(os.Stdout, opts)
 	} else {
 		handler = slog.NewTextHandler(os.Stdout, opts)
 	}
+	handler = &recordingHandler{next: handler, ring: recentLogs}
+	rootHandler.Store(&handler)
 
-	Logger = slog.New(handler)
+	Logger = slog.New(&levelHandler{next: handler, level: defaultLevel})
 	slog.SetDefault(Logger)
 }
 
-// InitLoggerWithLevel initializes the logger with a specific log level
+// InitLoggerWithLevel initializes the logger with a specific default log level
 func InitLoggerWithLevel(production bool, level slog.Level) {
-	var handler slog.Handler
+	defaultLevel.Set(level)
+	InitLogger(production)
+}
 
-	opts := &slog.HandlerOptions{
-		Level: level,
-	}
+// levelHandler drops records below a level that can change at runtime
+type levelHandler struct {
+	next  slog.Handler
+	level slog.Leveler
+}
 
-	if production {
-		handler = slog.NewJSONHandler(os.Stdout, opts)
-	} else {
-		handler = slog.NewTextHandler(os.Stdout, opts)
-	}
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.next.Enabled(ctx, level)
+}
 
-	Logger = slog.New(handler)
-	slog.SetDefault(Logger)
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{next: h.next.WithAttrs(attrs), level: h.level}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{next: h.next.WithGroup(name), level: h.level}
 }
 
 // WithContext returns a logger with context fields
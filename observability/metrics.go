@@ -26,11 +26,16 @@ type Metrics struct {
 	ExternalAPIRequestsTotal *prometheus.CounterVec
 	ExternalAPIErrorsTotal   *prometheus.CounterVec
 	ExternalAPIDuration      *prometheus.HistogramVec
+	ProviderHTTPDuration     *prometheus.HistogramVec
+
+	// LLM metrics
+	LLMTokensTotal *prometheus.CounterVec
 
 	// Database metrics
-	DBQueryDuration *prometheus.HistogramVec
-	DBQueryTotal    *prometheus.CounterVec
-	DBErrorsTotal   *prometheus.CounterVec
+	DBQueryDuration     *prometheus.HistogramVec
+	DBQueryTotal        *prometheus.CounterVec
+	DBErrorsTotal       *prometheus.CounterVec
+	DBStatementDuration *prometheus.HistogramVec
 
 	// HTTP metrics
 	HTTPRequestsTotal   *prometheus.CounterVec
@@ -38,8 +43,24 @@ type Metrics struct {
 	HTTPResponseSize    *prometheus.HistogramVec
 
 	// Circuit breaker metrics
-	CircuitBreakerState *prometheus.GaugeVec
-	CircuitBreakerTrips *prometheus.CounterVec
+	CircuitBreakerState       *prometheus.GaugeVec
+	CircuitBreakerTrips       *prometheus.CounterVec
+	CircuitBreakerTransitions *prometheus.CounterVec
+	CircuitBreakerLevel       *prometheus.GaugeVec
+
+	// Write buffer metrics
+	WriteBufferDepth        prometheus.Gauge
+	WriteBufferDropped      *prometheus.CounterVec
+	WriteBufferDeadLettered *prometheus.CounterVec
+
+	// Event bus metrics
+	EventsPublished *prometheus.CounterVec
+	EventsDropped   *prometheus.CounterVec
+	TradesFilled    *prometheus.CounterVec
+	ScreenerRuns    *prometheus.CounterVec
+
+	// Startup metrics
+	WarmupDuration *prometheus.HistogramVec
 }
 
 // defaultBuckets are the default histogram buckets for duration metrics (in seconds)
@@ -182,6 +203,27 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 			},
 			[]string{"service", "operation"},
 		),
+		ProviderHTTPDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "trade_machine",
+				Subsystem: "external_api",
+				Name:      "http_duration_seconds",
+				Help:      "Latency of each HTTP request to a provider until its response headers arrive, by status class (2xx-5xx, error, or cached for responses served from the response cache)",
+				Buckets:   defaultBuckets,
+			},
+			[]string{"provider", "result"},
+		),
+
+		// LLM metrics
+		LLMTokensTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "trade_machine",
+				Subsystem: "llm",
+				Name:      "tokens_total",
+				Help:      "Tokens used by LLM requests, by provider, model and direction (input or output)",
+			},
+			[]string{"provider", "model", "direction"},
+		),
 
 		// Database metrics
 		DBQueryDuration: factory.NewHistogramVec(
@@ -212,6 +254,16 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 			},
 			[]string{"operation", "table"},
 		),
+		DBStatementDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "trade_machine",
+				Subsystem: "database",
+				Name:      "statement_duration_seconds",
+				Help:      "Duration of every SQL statement run on the pool, including those in transactions and migrations, by command",
+				Buckets:   defaultBuckets,
+			},
+			[]string{"command", "status"},
+		),
 
 		// HTTP metrics
 		HTTPRequestsTotal: factory.NewCounterVec(
@@ -263,6 +315,102 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 			},
 			[]string{"service"},
 		),
+		CircuitBreakerTransitions: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "trade_machine",
+				Subsystem: "circuit_breaker",
+				Name:      "transitions_total",
+				Help:      "Total number of circuit breaker state changes, by the states left and entered",
+			},
+			[]string{"service", "from", "to"},
+		),
+		CircuitBreakerLevel: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "trade_machine",
+				Subsystem: "circuit_breaker",
+				Name:      "degradation_level",
+				Help:      "Degradation level of circuit breakers (0=healthy, 1=degraded, 2=unavailable)",
+			},
+			[]string{"service"},
+		),
+
+		// Write buffer metrics
+		WriteBufferDepth: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: "trade_machine",
+				Subsystem: "write_buffer",
+				Name:      "depth",
+				Help:      "Non-critical database writes waiting to be flushed",
+			},
+		),
+		WriteBufferDropped: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "trade_machine",
+				Subsystem: "write_buffer",
+				Name:      "dropped_total",
+				Help:      "Buffered writes dropped because the buffer was full",
+			},
+			[]string{"kind"},
+		),
+		WriteBufferDeadLettered: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "trade_machine",
+				Subsystem: "write_buffer",
+				Name:      "dead_lettered_total",
+				Help:      "Buffered writes given up on after failing every attempt",
+			},
+			[]string{"kind"},
+		),
+
+		// Event bus metrics
+		EventsPublished: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "trade_machine",
+				Subsystem: "events",
+				Name:      "published_total",
+				Help:      "Domain events published on the internal event bus",
+			},
+			[]string{"type"},
+		),
+		EventsDropped: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "trade_machine",
+				Subsystem: "events",
+				Name:      "dropped_total",
+				Help:      "Events dropped because a subscriber's queue was full",
+			},
+			[]string{"type", "subscriber"},
+		),
+		TradesFilled: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "trade_machine",
+				Subsystem: "trade",
+				Name:      "fills_total",
+				Help:      "Broker fills recorded on trades",
+			},
+			[]string{"side"},
+		),
+		ScreenerRuns: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "trade_machine",
+				Subsystem: "screener",
+				Name:      "runs_total",
+				Help:      "Screener runs, retries and replays finished, by status",
+			},
+			[]string{"status"},
+		),
+
+		// Startup metrics
+		WarmupDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "trade_machine",
+				Subsystem: "startup",
+				Name:      "warmup_duration_seconds",
+				Help:      "Duration of each startup warm-up step, and of the whole warm-up as step \"total\"",
+				Buckets:   defaultBuckets,
+			},
+			[]string{"step", "status"},
+		),
 	}
 
 	return m
@@ -334,6 +482,21 @@ func (m *Metrics) RecordExternalAPIDuration(service, operation string, duration
 	m.ExternalAPIDuration.WithLabelValues(service, operation).Observe(duration.Seconds())
 }
 
+// RecordProviderHTTP records the latency of an HTTP request to a provider
+func (m *Metrics) RecordProviderHTTP(provider, result string, duration time.Duration) {
+	m.ProviderHTTPDuration.WithLabelValues(provider, result).Observe(duration.Seconds())
+}
+
+// RecordLLMTokens records the input and output tokens used by an LLM request
+func (m *Metrics) RecordLLMTokens(provider, model string, input, output int64) {
+	if input > 0 {
+		m.LLMTokensTotal.WithLabelValues(provider, model, "input").Add(float64(input))
+	}
+	if output > 0 {
+		m.LLMTokensTotal.WithLabelValues(provider, model, "output").Add(float64(output))
+	}
+}
+
 // RecordDBQuery records a database query
 func (m *Metrics) RecordDBQuery(operation, table string, duration time.Duration) {
 	m.DBQueryTotal.WithLabelValues(operation, table).Inc()
@@ -345,6 +508,11 @@ func (m *Metrics) RecordDBError(operation, table string) {
 	m.DBErrorsTotal.WithLabelValues(operation, table).Inc()
 }
 
+// RecordDBStatement records the duration of a SQL statement
+func (m *Metrics) RecordDBStatement(command, status string, duration time.Duration) {
+	m.DBStatementDuration.WithLabelValues(command, status).Observe(duration.Seconds())
+}
+
 // RecordHTTPRequest records an HTTP request
 func (m *Metrics) RecordHTTPRequest(method, path, statusCode string, duration time.Duration, responseSize int) {
 	m.HTTPRequestsTotal.WithLabelValues(method, path, statusCode).Inc()
@@ -362,6 +530,56 @@ func (m *Metrics) RecordCircuitBreakerTrip(service string) {
 	m.CircuitBreakerTrips.WithLabelValues(service).Inc()
 }
 
+// RecordCircuitBreakerTransition records a circuit breaker moving from one state to another
+func (m *Metrics) RecordCircuitBreakerTransition(service, from, to string) {
+	m.CircuitBreakerTransitions.WithLabelValues(service, from, to).Inc()
+}
+
+// SetCircuitBreakerLevel sets the current degradation level of a circuit breaker
+func (m *Metrics) SetCircuitBreakerLevel(service string, level int) {
+	m.CircuitBreakerLevel.WithLabelValues(service).Set(float64(level))
+}
+
+// SetWriteBufferDepth sets the number of buffered writes waiting to be flushed
+func (m *Metrics) SetWriteBufferDepth(depth int) {
+	m.WriteBufferDepth.Set(float64(depth))
+}
+
+// RecordWriteBufferDrop records a buffered write dropped on overflow
+func (m *Metrics) RecordWriteBufferDrop(kind string) {
+	m.WriteBufferDropped.WithLabelValues(kind).Inc()
+}
+
+// RecordWriteBufferDeadLetter records a buffered write given up on after its last attempt
+func (m *Metrics) RecordWriteBufferDeadLetter(kind string) {
+	m.WriteBufferDeadLettered.WithLabelValues(kind).Inc()
+}
+
+// RecordEventPublished records an event published on the event bus
+func (m *Metrics) RecordEventPublished(eventType string) {
+	m.EventsPublished.WithLabelValues(eventType).Inc()
+}
+
+// RecordEventDropped records an event a subscriber could not keep up with
+func (m *Metrics) RecordEventDropped(eventType, subscriber string) {
+	m.EventsDropped.WithLabelValues(eventType, subscriber).Inc()
+}
+
+// RecordTradeFilled records a broker fill on a trade
+func (m *Metrics) RecordTradeFilled(side string) {
+	m.TradesFilled.WithLabelValues(side).Inc()
+}
+
+// RecordScreenerRun records a finished screener run
+func (m *Metrics) RecordScreenerRun(status string) {
+	m.ScreenerRuns.WithLabelValues(status).Inc()
+}
+
+// RecordWarmupStep records how long a startup warm-up step took and whether it succeeded
+func (m *Metrics) RecordWarmupStep(step, status string, duration time.Duration) {
+	m.WarmupDuration.WithLabelValues(step, status).Observe(duration.Seconds())
+}
+
 // Timer is a helper for timing operations
 type Timer struct {
 	start   time.Time
@@ -396,6 +614,11 @@ func (t *Timer) ObserveDB(operation, table string) {
 	t.metrics.RecordDBQuery(operation, table, time.Since(t.start))
 }
 
+// ObserveWarmup records the duration of a startup warm-up step
+func (t *Timer) ObserveWarmup(step, status string) {
+	t.metrics.RecordWarmupStep(step, status, time.Since(t.start))
+}
+
 // Duration returns the elapsed time
 func (t *Timer) Duration() time.Duration {
 	return time.Since(t.start)
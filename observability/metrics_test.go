@@ -296,6 +296,107 @@ func TestCircuitBreakerMetrics(t *testing.T) {
 	if openaiTrips != 2 {
 		t.Errorf("Expected openai trips to be 2, got %f", openaiTrips)
 	}
+
+	m.RecordCircuitBreakerTransition("openai", "closed", "open")
+	if n := testutil.ToFloat64(m.CircuitBreakerTransitions.WithLabelValues("openai", "closed", "open")); n != 1 {
+		t.Errorf("Expected 1 closed to open transition, got %f", n)
+	}
+
+	m.SetCircuitBreakerLevel("newsapi", 1) // degraded
+	if level := testutil.ToFloat64(m.CircuitBreakerLevel.WithLabelValues("newsapi")); level != 1 {
+		t.Errorf("Expected newsapi level to be 1 (degraded), got %f", level)
+	}
+}
+
+func TestProviderMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.RecordProviderHTTP("fmp", "2xx", 120*time.Millisecond)
+	m.RecordProviderHTTP("fmp", "cached", time.Millisecond)
+	if count := testutil.CollectAndCount(m.ProviderHTTPDuration); count != 2 {
+		t.Errorf("Expected 2 provider latency series, got %d", count)
+	}
+
+	m.RecordLLMTokens("anthropic", "claude-sonnet-4-5", 1200, 350)
+	m.RecordLLMTokens("openai", "text-embedding-3-small", 80, 0)
+	if input := testutil.ToFloat64(m.LLMTokensTotal.WithLabelValues("anthropic", "claude-sonnet-4-5", "input")); input != 1200 {
+		t.Errorf("Expected 1200 input tokens, got %f", input)
+	}
+	if output := testutil.ToFloat64(m.LLMTokensTotal.WithLabelValues("anthropic", "claude-sonnet-4-5", "output")); output != 350 {
+		t.Errorf("Expected 350 output tokens, got %f", output)
+	}
+	// Embeddings have no output tokens, so no output series is created
+	if count := testutil.CollectAndCount(m.LLMTokensTotal); count != 3 {
+		t.Errorf("Expected 3 token series, got %d", count)
+	}
+}
+
+func TestDBStatementMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.RecordDBStatement("select", "ok", 3*time.Millisecond)
+	m.RecordDBStatement("insert", "error", time.Millisecond)
+	if count := testutil.CollectAndCount(m.DBStatementDuration); count != 2 {
+		t.Errorf("Expected 2 statement series, got %d", count)
+	}
+}
+
+func TestWriteBufferMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.SetWriteBufferDepth(12)
+	if depth := testutil.ToFloat64(m.WriteBufferDepth); depth != 12 {
+		t.Errorf("Expected depth 12, got %f", depth)
+	}
+
+	m.RecordWriteBufferDrop("agent_run")
+	m.RecordWriteBufferDrop("agent_run")
+	if dropped := testutil.ToFloat64(m.WriteBufferDropped.WithLabelValues("agent_run")); dropped != 2 {
+		t.Errorf("Expected 2 dropped agent runs, got %f", dropped)
+	}
+
+	m.RecordWriteBufferDeadLetter("audit_entry")
+	if dead := testutil.ToFloat64(m.WriteBufferDeadLettered.WithLabelValues("audit_entry")); dead != 1 {
+		t.Errorf("Expected 1 dead-lettered audit entry, got %f", dead)
+	}
+}
+
+func TestEventMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.RecordEventPublished("trade.filled")
+	m.RecordEventDropped("trade.filled", "websocket")
+	if published := testutil.ToFloat64(m.EventsPublished.WithLabelValues("trade.filled")); published != 1 {
+		t.Errorf("Expected 1 published event, got %f", published)
+	}
+	if dropped := testutil.ToFloat64(m.EventsDropped.WithLabelValues("trade.filled", "websocket")); dropped != 1 {
+		t.Errorf("Expected 1 dropped event, got %f", dropped)
+	}
+
+	m.RecordTradeFilled("buy")
+	m.RecordScreenerRun("completed")
+	if fills := testutil.ToFloat64(m.TradesFilled.WithLabelValues("buy")); fills != 1 {
+		t.Errorf("Expected 1 fill, got %f", fills)
+	}
+	if runs := testutil.ToFloat64(m.ScreenerRuns.WithLabelValues("completed")); runs != 1 {
+		t.Errorf("Expected 1 screener run, got %f", runs)
+	}
+}
+
+func TestWarmupMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.RecordWarmupStep("positions", "success", 40*time.Millisecond)
+	m.NewTimer().ObserveWarmup("total", "success")
+
+	if count := testutil.CollectAndCount(m.WarmupDuration); count != 2 {
+		t.Errorf("Expected 2 warm-up series, got %d", count)
+	}
 }
 
 func TestTimer(t *testing.T) {
@@ -0,0 +1,250 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Modules with their own logger and level
+const (
+	ModuleAPI        = "api"
+	ModuleAgents     = "agents"
+	ModuleScreener   = "screener"
+	ModuleServices   = "services"
+	ModuleRepository = "repository"
+)
+
+// DefaultModule names the level used by the global logging functions and by every
+// module without a level of its own
+const DefaultModule = "default"
+
+// ErrUnknownLogModule is returned when setting the level of a module that doesn't exist
+var ErrUnknownLogModule = errors.New("unknown log module")
+
+// defaultLevel is the level of the global logger and of modules that inherit it
+var defaultLevel = new(slog.LevelVar)
+
+// moduleLevel is a module's level, or the default level while none is set
+type moduleLevel struct {
+	level atomic.Pointer[slog.Level]
+}
+
+func (m *moduleLevel) Level() slog.Level {
+	if l := m.level.Load(); l != nil {
+		return *l
+	}
+	return defaultLevel.Level()
+}
+
+var (
+	modulesMu sync.Mutex
+	modules   = map[string]*moduleLevel{
+		ModuleAPI:        {},
+		ModuleAgents:     {},
+		ModuleScreener:   {},
+		ModuleServices:   {},
+		ModuleRepository: {},
+	}
+)
+
+// rootHandler is the handler module loggers write through, replaced by InitLogger. It
+// accepts every level; the level checks are made by the loggers in front of it.
+var rootHandler atomic.Pointer[slog.Handler]
+
+func currentRootHandler() slog.Handler {
+	if h := rootHandler.Load(); h != nil {
+		return *h
+	}
+	InitLogger(false)
+	return *rootHandler.Load()
+}
+
+// Module returns the named module's logger. Records carry a "module" attribute and are
+// filtered by the module's level, which follows the default level until set with
+// SetLevel. Module loggers may be created before InitLogger and follow it when it runs.
+func Module(name string) *slog.Logger {
+	modulesMu.Lock()
+	level, ok := modules[name]
+	if !ok {
+		level = &moduleLevel{}
+		modules[name] = level
+	}
+	modulesMu.Unlock()
+	return slog.New(&moduleHandler{module: name, level: level})
+}
+
+// moduleHandler filters records by a module's level and hands them to the current root
+// handler. Attributes and groups added to the logger are replayed onto the root handler
+// for each record, so they survive the root handler being replaced.
+type moduleHandler struct {
+	module string
+	level  slog.Leveler
+	ops    []func(slog.Handler) slog.Handler
+}
+
+func (h *moduleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *moduleHandler) Handle(ctx context.Context, r slog.Record) error {
+	handler := currentRootHandler().WithAttrs([]slog.Attr{slog.String("module", h.module)})
+	for _, op := range h.ops {
+		handler = op(handler)
+	}
+	return handler.Handle(ctx, r)
+}
+
+func (h *moduleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+func (h *moduleHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
+
+func (h *moduleHandler) with(op func(slog.Handler) slog.Handler) slog.Handler {
+	ops := make([]func(slog.Handler) slog.Handler, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &moduleHandler{module: h.module, level: h.level, ops: append(ops, op)}
+}
+
+// ParseLevel parses a level name: debug, info, warn, or error
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return 0, fmt.Errorf("invalid log level %q: must be debug, info, warn, or error", name)
+	}
+	return level, nil
+}
+
+// SetLevel sets a module's level, or the default level for DefaultModule
+func SetLevel(module string, level slog.Level) error {
+	if module == DefaultModule {
+		defaultLevel.Set(level)
+		return nil
+	}
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	m, ok := modules[module]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownLogModule, module)
+	}
+	m.level.Store(&level)
+	return nil
+}
+
+// ResetLevel makes a module follow the default level again
+func ResetLevel(module string) error {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	m, ok := modules[module]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownLogModule, module)
+	}
+	m.level.Store(nil)
+	return nil
+}
+
+// KnownModule reports whether module has a logger level that can be set
+func KnownModule(module string) bool {
+	if module == DefaultModule {
+		return true
+	}
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	_, ok := modules[module]
+	return ok
+}
+
+// ModuleLevel is a module's current level and whether it was set or follows the default
+type ModuleLevel struct {
+	Module    string `json:"module"`
+	Level     string `json:"level"`
+	Inherited bool   `json:"inherited"`
+}
+
+// Levels returns the default level followed by every module's level, sorted by name
+func Levels() []ModuleLevel {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	levels := make([]ModuleLevel, 0, len(modules)+1)
+	for name, m := range modules {
+		levels = append(levels, ModuleLevel{Module: name, Level: levelName(m.Level()), Inherited: m.level.Load() == nil})
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Module < levels[j].Module })
+	return append([]ModuleLevel{{Module: DefaultModule, Level: levelName(defaultLevel.Level())}}, levels...)
+}
+
+// ConfigureLevels sets the default level and per-module levels from configuration,
+// e.g. "info" and {"screener": "debug"}
+func ConfigureLevels(defaultName string, moduleNames map[string]string) error {
+	if defaultName != "" {
+		level, err := ParseLevel(defaultName)
+		if err != nil {
+			return err
+		}
+		defaultLevel.Set(level)
+	}
+	for module, name := range moduleNames {
+		level, err := ParseLevel(name)
+		if err != nil {
+			return err
+		}
+		if err := SetLevel(module, level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// levelName returns the lower-case name of a level, as accepted by ParseLevel
+func levelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// Sampled returns a logger that writes only the first of every n records below error
+// level, for paths that can log on every request. Errors are always written. Records
+// carry a "sampled" attribute with the rate so readers know others were dropped.
+func Sampled(logger *slog.Logger, n int) *slog.Logger {
+	if n <= 1 {
+		return logger
+	}
+	return slog.New(&samplingHandler{
+		next:  logger.Handler().WithAttrs([]slog.Attr{slog.String("sampled", fmt.Sprintf("1/%d", n))}),
+		every: uint64(n),
+		seen:  new(atomic.Uint64),
+	})
+}
+
+// samplingHandler passes one of every records below error level. Loggers derived with
+// With share the count.
+type samplingHandler struct {
+	next  slog.Handler
+	every uint64
+	seen  *atomic.Uint64
+}
+
+func (h *samplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelError && (h.seen.Add(1)-1)%h.every != 0 {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{next: h.next.WithAttrs(attrs), every: h.every, seen: h.seen}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{next: h.next.WithGroup(name), every: h.every, seen: h.seen}
+}
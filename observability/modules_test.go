@@ -0,0 +1,125 @@
+package observability
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// captureRoot sends module logs to a buffer and restores the default levels afterwards
+func captureRoot(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	var handler slog.Handler = slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	rootHandler.Store(&handler)
+	defaultLevel.Set(slog.LevelInfo)
+	t.Cleanup(func() {
+		defaultLevel.Set(slog.LevelInfo)
+		for _, m := range []string{ModuleAPI, ModuleAgents, ModuleScreener, ModuleServices, ModuleRepository} {
+			ResetLevel(m)
+		}
+		InitLogger(false)
+	})
+	return &buf
+}
+
+func TestModule_Levels(t *testing.T) {
+	buf := captureRoot(t)
+	screener := Module(ModuleScreener).With("run", "r1")
+	api := Module(ModuleAPI)
+
+	screener.Debug("hidden at the default level")
+	if buf.Len() != 0 {
+		t.Fatalf("debug logged at the default info level: %s", buf)
+	}
+
+	if err := SetLevel(ModuleScreener, slog.LevelDebug); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	screener.Debug("screener detail")
+	api.Debug("api detail")
+	out := buf.String()
+	if !strings.Contains(out, "screener detail") || !strings.Contains(out, "module=screener") || !strings.Contains(out, "run=r1") {
+		t.Errorf("expected the screener debug record with its attributes, got %s", out)
+	}
+	if strings.Contains(out, "api detail") {
+		t.Error("debugging the screener should not enable api debug logs")
+	}
+
+	buf.Reset()
+	if err := SetLevel(DefaultModule, slog.LevelError); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	api.Warn("api warning")
+	screener.Debug("still debugging")
+	if strings.Contains(buf.String(), "api warning") || !strings.Contains(buf.String(), "still debugging") {
+		t.Errorf("expected inheriting modules to follow the default level and set ones to keep theirs, got %s", buf)
+	}
+
+	buf.Reset()
+	ResetLevel(ModuleScreener)
+	screener.Warn("after reset")
+	if buf.Len() != 0 {
+		t.Errorf("expected a reset module to follow the default level, got %s", buf)
+	}
+
+	if err := SetLevel("nonexistent", slog.LevelDebug); !errors.Is(err, ErrUnknownLogModule) {
+		t.Errorf("SetLevel() error = %v, want ErrUnknownLogModule", err)
+	}
+}
+
+func TestConfigureLevels(t *testing.T) {
+	captureRoot(t)
+
+	if err := ConfigureLevels("warn", map[string]string{ModuleServices: "debug"}); err != nil {
+		t.Fatalf("ConfigureLevels() error = %v", err)
+	}
+	levels := Levels()
+	if levels[0].Module != DefaultModule || levels[0].Level != "warn" {
+		t.Errorf("first level = %+v, want the default at warn", levels[0])
+	}
+	for _, l := range levels[1:] {
+		switch l.Module {
+		case ModuleServices:
+			if l.Level != "debug" || l.Inherited {
+				t.Errorf("services level = %+v, want debug set explicitly", l)
+			}
+		case ModuleAPI:
+			if l.Level != "warn" || !l.Inherited {
+				t.Errorf("api level = %+v, want warn inherited", l)
+			}
+		}
+	}
+
+	if err := ConfigureLevels("loud", nil); err == nil {
+		t.Error("expected an error for an invalid level")
+	}
+	if err := ConfigureLevels("", map[string]string{"nonexistent": "debug"}); !errors.Is(err, ErrUnknownLogModule) {
+		t.Errorf("ConfigureLevels() error = %v, want ErrUnknownLogModule", err)
+	}
+}
+
+func TestSampled(t *testing.T) {
+	buf := captureRoot(t)
+	logger := Sampled(Module(ModuleServices), 5)
+
+	for range 10 {
+		logger.Warn("rejected request")
+	}
+	if n := strings.Count(buf.String(), "rejected request"); n != 2 {
+		t.Errorf("logged %d of 10 sampled warnings, want 2", n)
+	}
+	if !strings.Contains(buf.String(), "sampled=1/5") {
+		t.Error("expected sampled records to carry the sampling rate")
+	}
+
+	buf.Reset()
+	for range 3 {
+		logger.Error("failure")
+	}
+	if n := strings.Count(buf.String(), "failure"); n != 3 {
+		t.Errorf("logged %d of 3 errors, want every error", n)
+	}
+}
@@ -0,0 +1,119 @@
+package observability
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// recentLogSize is how many of the latest log records are kept in memory for diagnostics
+const recentLogSize = 500
+
+// LogEntry is a log record kept in memory, with its attributes flattened to strings
+type LogEntry struct {
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+}
+
+// logRing holds the most recent log records, overwriting the oldest when full
+type logRing struct {
+	mu      sync.Mutex
+	entries []LogEntry
+	next    int
+	full    bool
+}
+
+var recentLogs = &logRing{entries: make([]LogEntry, recentLogSize)}
+
+func (r *logRing) add(e LogEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *logRing) snapshot() []LogEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]LogEntry(nil), r.entries[:r.next]...)
+	}
+	out := make([]LogEntry, 0, len(r.entries))
+	out = append(out, r.entries[r.next:]...)
+	return append(out, r.entries[:r.next]...)
+}
+
+// RecentLogs returns the latest log records that passed their logger's level, oldest
+// first. Only records written after InitLogger are kept.
+func RecentLogs() []LogEntry {
+	return recentLogs.snapshot()
+}
+
+// recordingHandler copies each record into the recent log ring before handing it on.
+// Attributes added with WithAttrs are kept under their group's dotted prefix. Copies are
+// redacted, so the ring can be handed to support without leaking credentials.
+type recordingHandler struct {
+	next   slog.Handler
+	ring   *logRing
+	attrs  []slog.Attr
+	prefix string
+}
+
+func (h *recordingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *recordingHandler) Handle(ctx context.Context, r slog.Record) error {
+	entry := LogEntry{Time: r.Time, Level: r.Level.String(), Message: RedactString(r.Message)}
+	if len(h.attrs) > 0 || r.NumAttrs() > 0 {
+		entry.Attrs = make(map[string]string, len(h.attrs)+r.NumAttrs())
+		for _, a := range h.attrs {
+			flattenAttr(entry.Attrs, "", a)
+		}
+		r.Attrs(func(a slog.Attr) bool {
+			flattenAttr(entry.Attrs, h.prefix, a)
+			return true
+		})
+	}
+	h.ring.add(entry)
+	return h.next.Handle(ctx, r)
+}
+
+func (h *recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	prefixed := make([]slog.Attr, len(h.attrs), len(h.attrs)+len(attrs))
+	copy(prefixed, h.attrs)
+	for _, a := range attrs {
+		prefixed = append(prefixed, slog.Attr{Key: h.prefix + a.Key, Value: a.Value})
+	}
+	return &recordingHandler{next: h.next.WithAttrs(attrs), ring: h.ring, attrs: prefixed, prefix: h.prefix}
+}
+
+func (h *recordingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &recordingHandler{next: h.next.WithGroup(name), ring: h.ring, attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+func flattenAttr(into map[string]string, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			flattenAttr(into, prefix, ga)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	into[prefix+a.Key] = RedactValue(a.Key, v.String())
+}
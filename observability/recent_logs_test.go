@@ -0,0 +1,82 @@
+package observability
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRecordingHandler(t *testing.T) {
+	var buf bytes.Buffer
+	ring := &logRing{entries: make([]LogEntry, 3)}
+	logger := slog.New(&recordingHandler{next: slog.NewTextHandler(&buf, nil), ring: ring})
+
+	logger.With("module", "screener").WithGroup("run").Info("screen done", "candidates", 12)
+	entries := ring.snapshot()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Message != "screen done" || e.Level != "INFO" || e.Attrs["module"] != "screener" || e.Attrs["run.candidates"] != "12" {
+		t.Errorf("entry = %+v, want the message with flattened attributes", e)
+	}
+	if buf.Len() == 0 {
+		t.Error("record was not passed on to the next handler")
+	}
+
+	for i := 0; i < 4; i++ {
+		logger.Warn(fmt.Sprintf("warning %d", i))
+	}
+	entries = ring.snapshot()
+	if len(entries) != 3 || entries[0].Message != "warning 1" || entries[2].Message != "warning 3" {
+		t.Errorf("entries = %+v, want the last three oldest first", entries)
+	}
+}
+
+func TestRecentLogs(t *testing.T) {
+	InitLogger(false)
+	Module(ModuleScreener).Warn("recent logs test", "symbol", "AAPL")
+
+	logs := RecentLogs()
+	if len(logs) == 0 {
+		t.Fatal("RecentLogs() is empty")
+	}
+	last := logs[len(logs)-1]
+	if last.Message != "recent logs test" || last.Attrs["module"] != ModuleScreener || last.Attrs["symbol"] != "AAPL" {
+		t.Errorf("last entry = %+v, want the module record", last)
+	}
+}
+
+func TestRecordingHandler_Redacts(t *testing.T) {
+	ring := &logRing{entries: make([]LogEntry, 2)}
+	logger := slog.New(&recordingHandler{next: slog.NewTextHandler(&bytes.Buffer{}, nil), ring: ring})
+
+	logger.Error("GET https://financialmodelingprep.com/api/v3/quote/AAPL?apikey=abc123 failed",
+		"api_key", "abc123", "max_tokens", 4096, "dsn", "postgres://app:hunter2@db:5432/tm?sslmode=disable")
+
+	e := ring.snapshot()[0]
+	if strings.Contains(e.Message, "abc123") || e.Attrs["api_key"] != Redacted {
+		t.Errorf("entry = %+v, want the API key redacted", e)
+	}
+	if e.Attrs["max_tokens"] != "4096" {
+		t.Errorf("max_tokens = %q, want token counts kept", e.Attrs["max_tokens"])
+	}
+	if e.Attrs["dsn"] != "postgres://app:[redacted]@db:5432/tm?sslmode=disable" {
+		t.Errorf("dsn = %q, want the password redacted", e.Attrs["dsn"])
+	}
+}
+
+func TestRedactString(t *testing.T) {
+	tests := map[string]string{
+		"host=db user=app password=hunter2 dbname=tm":                   "host=db user=app password=[redacted] dbname=tm",
+		"https://newsapi.org/v2/everything?q=AAPL&apiKey=k1&pageSize=5": "https://newsapi.org/v2/everything?q=AAPL&apiKey=[redacted]&pageSize=5",
+		"https://paper-api.alpaca.markets":                              "https://paper-api.alpaca.markets",
+	}
+	for in, want := range tests {
+		if got := RedactString(in); got != want {
+			t.Errorf("RedactString(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
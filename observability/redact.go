@@ -0,0 +1,56 @@
+package observability
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces secret values in logs and diagnostics
+const Redacted = "[redacted]"
+
+// sensitiveKeyParts mark a key whose value is a credential
+var sensitiveKeyParts = []string{"secret", "password", "passphrase", "apikey", "api_key", "accesskey", "access_key", "encryptionkey", "encryption_key", "token"}
+
+var (
+	urlPassword    = regexp.MustCompile(`://([^:/@\s]+):[^@/\s]+@`)
+	dsnPassword    = regexp.MustCompile(`(?i)\bpassword=[^\s&]+`)
+	sensitiveQuery = regexp.MustCompile(`(?i)([?&][a-z_]*(?:key|token|secret)[a-z_]*)=[^&\s"]*`)
+)
+
+// SensitiveKey reports whether a key such as "APIKey" or "alpaca.api_secret" names a
+// credential. Token counts like "max_tokens" are not credentials.
+func SensitiveKey(key string) bool {
+	k := strings.ToLower(key)
+	if i := strings.LastIndex(k, "."); i >= 0 {
+		k = k[i+1:]
+	}
+	if strings.Contains(k, "tokens") {
+		return false
+	}
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(k, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString scrubs credentials embedded in free text: passwords in URLs and
+// connection strings, and API keys passed as query parameters
+func RedactString(s string) string {
+	s = urlPassword.ReplaceAllString(s, "://$1:"+Redacted+"@")
+	s = dsnPassword.ReplaceAllString(s, "password="+Redacted)
+	return sensitiveQuery.ReplaceAllString(s, "$1="+Redacted)
+}
+
+// RedactValue returns the value to show for key: Redacted for a non-empty credential,
+// otherwise the value with embedded credentials scrubbed
+func RedactValue(key, value string) string {
+	if SensitiveKey(key) {
+		if value == "" {
+			return ""
+		}
+		return Redacted
+	}
+	return RedactString(value)
+}
@@ -0,0 +1,480 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Spans are exported as OTLP/HTTP JSON, which every OpenTelemetry collector accepts,
+// so tracing needs no SDK.
+
+const (
+	// tracingBatchSize is the most spans sent in one export request
+	tracingBatchSize = 512
+	// tracingQueueSize is the most finished spans held for export; later spans are dropped
+	tracingQueueSize = 4096
+	// tracingFlushInterval is how often finished spans are exported
+	tracingFlushInterval = 5 * time.Second
+	// tracingExportTimeout bounds a single export request
+	tracingExportTimeout = 10 * time.Second
+	// tracingScope names the instrumentation on exported spans
+	tracingScope = "trade-machine"
+)
+
+// SpanKind is the OTLP role of a span in a trace
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1 // Work within the process, such as an agent run
+	SpanKindServer   SpanKind = 2 // An incoming HTTP request
+	SpanKindClient   SpanKind = 3 // A call to an external service
+)
+
+// TracingOptions configures trace export
+type TracingOptions struct {
+	Endpoint    string            // OTLP/HTTP traces URL; tracing is off when empty
+	Headers     map[string]string // Sent with each export request
+	ServiceName string            // service.name resource attribute
+	SampleRatio float64           // Fraction of new traces recorded, from 0 to 1
+}
+
+// spanContext identifies a span and carries the sampling decision to its children
+type spanContext struct {
+	traceID [16]byte
+	spanID  [8]byte
+	sampled bool
+}
+
+type spanContextKey struct{}
+
+// Span is a timed operation within a trace. A nil Span, returned while tracing is off,
+// ignores every call, so callers never need to check.
+type Span struct {
+	tracer   *tracer
+	sc       spanContext
+	parentID [8]byte
+	name     string
+	kind     SpanKind
+	start    time.Time
+
+	mu     sync.Mutex
+	end    time.Time
+	attrs  []any
+	errMsg string
+	ended  bool
+}
+
+// activeTracer is the tracer spans are started on, or nil while tracing is off
+var activeTracer atomic.Pointer[tracer]
+
+type tracer struct {
+	endpoint    string
+	headers     map[string]string
+	serviceName string
+	sampleRatio float64
+	client      *http.Client
+
+	mu      sync.Mutex
+	queue   []*Span
+	dropped int
+
+	flush chan struct{}
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// InitTracing starts exporting spans to opts.Endpoint in the background. It does
+// nothing when no endpoint is set, leaving every span a no-op.
+func InitTracing(opts TracingOptions) {
+	if opts.Endpoint == "" {
+		return
+	}
+	t := &tracer{
+		endpoint:    opts.Endpoint,
+		headers:     opts.Headers,
+		serviceName: opts.ServiceName,
+		sampleRatio: opts.SampleRatio,
+		client:      &http.Client{Timeout: tracingExportTimeout},
+		flush:       make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go t.run()
+	if previous := activeTracer.Swap(t); previous != nil {
+		previous.shutdown(context.Background())
+	}
+	Info("tracing enabled", "endpoint", RedactString(opts.Endpoint), "sample_ratio", opts.SampleRatio)
+}
+
+// ShutdownTracing stops tracing and exports the spans still queued, giving up when ctx
+// is done
+func ShutdownTracing(ctx context.Context) error {
+	t := activeTracer.Swap(nil)
+	if t == nil {
+		return nil
+	}
+	return t.shutdown(ctx)
+}
+
+// StartSpan starts a span for work within the process, as a child of the span in ctx.
+// The span is ended with End; attributes are key-value pairs as in Info.
+func StartSpan(ctx context.Context, name string, attrs ...any) (context.Context, *Span) {
+	return startSpan(ctx, SpanKindInternal, name, attrs)
+}
+
+// StartClientSpan starts a span for a call to an external service
+func StartClientSpan(ctx context.Context, name string, attrs ...any) (context.Context, *Span) {
+	return startSpan(ctx, SpanKindClient, name, attrs)
+}
+
+// StartServerSpan starts a span for an incoming request. A valid W3C traceparent header
+// makes it a child of the caller's span, keeping the caller's sampling decision.
+func StartServerSpan(ctx context.Context, name, traceparent string, attrs ...any) (context.Context, *Span) {
+	if remote, ok := parseTraceParent(traceparent); ok {
+		ctx = context.WithValue(ctx, spanContextKey{}, remote)
+	}
+	return startSpan(ctx, SpanKindServer, name, attrs)
+}
+
+// ContextWithSpan returns ctx carrying the span in from, so work running on a different
+// context, such as the app's, continues the trace without inheriting from's cancellation
+func ContextWithSpan(ctx, from context.Context) context.Context {
+	sc, ok := from.Value(spanContextKey{}).(spanContext)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// TraceID returns the trace ID of the span in ctx as hex, or "" outside a trace
+func TraceID(ctx context.Context) string {
+	sc, ok := ctx.Value(spanContextKey{}).(spanContext)
+	if !ok {
+		return ""
+	}
+	return hex.EncodeToString(sc.traceID[:])
+}
+
+func startSpan(ctx context.Context, kind SpanKind, name string, attrs []any) (context.Context, *Span) {
+	t := activeTracer.Load()
+	if t == nil {
+		return ctx, nil
+	}
+
+	s := &Span{tracer: t, name: name, kind: kind, start: time.Now()}
+	if parent, ok := ctx.Value(spanContextKey{}).(spanContext); ok {
+		s.sc.traceID = parent.traceID
+		s.sc.sampled = parent.sampled
+		s.parentID = parent.spanID
+	} else {
+		rand.Read(s.sc.traceID[:])
+		s.sc.sampled = t.sample(s.sc.traceID)
+	}
+	rand.Read(s.sc.spanID[:])
+	s.SetAttributes(attrs...)
+
+	return context.WithValue(ctx, spanContextKey{}, s.sc), s
+}
+
+// SetName renames the span, for when a better name is only known once the work is done,
+// such as an HTTP route after routing
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.name = name
+	s.mu.Unlock()
+}
+
+// SetAttributes adds key-value pairs to the span
+func (s *Span) SetAttributes(attrs ...any) {
+	if s == nil || len(attrs) == 0 {
+		return
+	}
+	s.mu.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.mu.Unlock()
+}
+
+// RecordError marks the span as failed with err. A nil error is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.errMsg = err.Error()
+	s.mu.Unlock()
+}
+
+// End finishes the span and queues it for export. Only the first call has an effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	if s.sc.sampled {
+		s.tracer.enqueue(s)
+	}
+}
+
+// sample decides whether a new trace is recorded, from the random low half of its ID so
+// every process given the same ratio agrees
+func (t *tracer) sample(traceID [16]byte) bool {
+	if t.sampleRatio >= 1 {
+		return true
+	}
+	return float64(binary.BigEndian.Uint64(traceID[8:])>>11)/(1<<53) < t.sampleRatio
+}
+
+func (t *tracer) enqueue(s *Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.queue) >= tracingQueueSize {
+		t.dropped++
+		return
+	}
+	t.queue = append(t.queue, s)
+	if len(t.queue) >= tracingBatchSize {
+		select {
+		case t.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (t *tracer) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(tracingFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-t.flush:
+		case <-t.stop:
+			return
+		}
+		t.exportQueued(context.Background())
+	}
+}
+
+func (t *tracer) shutdown(ctx context.Context) error {
+	close(t.stop)
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return t.exportQueued(ctx)
+}
+
+// exportQueued sends the queued spans in batches, logging rather than retrying failures
+// so a collector outage never backs up into requests
+func (t *tracer) exportQueued(ctx context.Context) error {
+	t.mu.Lock()
+	spans, dropped := t.queue, t.dropped
+	t.queue, t.dropped = nil, 0
+	t.mu.Unlock()
+
+	if dropped > 0 {
+		Warn("trace queue full, spans dropped", "dropped", dropped)
+	}
+	var firstErr error
+	for start := 0; start < len(spans); start += tracingBatchSize {
+		batch := spans[start:min(start+tracingBatchSize, len(spans))]
+		if err := t.export(ctx, batch); err != nil {
+			Warn("trace export failed", "spans", len(batch), "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func (t *tracer) export(ctx context.Context, spans []*Span) error {
+	body, err := json.Marshal(t.payload(spans))
+	if err != nil {
+		return fmt.Errorf("failed to encode spans: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create export request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export spans: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// OTLP/HTTP JSON request body. IDs are hex and 64-bit integers are strings, as the
+// protocol's JSON mapping requires.
+type otlpExport struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+// otlpStatusError is the OTLP status code of a failed span
+const otlpStatusError = 2
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+func (t *tracer) payload(spans []*Span) otlpExport {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.sc.traceID[:]),
+			SpanID:            hex.EncodeToString(s.sc.spanID[:]),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.attrs),
+		}
+		if s.parentID != ([8]byte{}) {
+			span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+		}
+		if s.errMsg != "" {
+			span.Status = otlpStatus{Code: otlpStatusError, Message: s.errMsg}
+		}
+		s.mu.Unlock()
+		out = append(out, span)
+	}
+
+	return otlpExport{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: otlpAttributes([]any{"service.name", t.serviceName})},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: tracingScope}, Spans: out}},
+	}}}
+}
+
+// otlpAttributes converts key-value pairs to OTLP attributes. Pairs without a string key
+// are skipped.
+func otlpAttributes(kv []any) []otlpAttribute {
+	attrs := make([]otlpAttribute, 0, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			continue
+		}
+		attrs = append(attrs, otlpAttribute{Key: key, Value: otlpValueOf(kv[i+1])})
+	}
+	return attrs
+}
+
+func otlpValueOf(v any) otlpValue {
+	switch v := v.(type) {
+	case bool:
+		return otlpValue{BoolValue: &v}
+	case int:
+		s := strconv.Itoa(v)
+		return otlpValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return otlpValue{IntValue: &s}
+	case float64:
+		return otlpValue{DoubleValue: &v}
+	case string:
+		return otlpValue{StringValue: &v}
+	}
+	s := fmt.Sprint(v)
+	return otlpValue{StringValue: &s}
+}
+
+// parseTraceParent reads a W3C traceparent header such as
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+func parseTraceParent(header string) (spanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return spanContext{}, false
+	}
+	var sc spanContext
+	if _, err := hex.Decode(sc.traceID[:], []byte(parts[1])); err != nil || sc.traceID == ([16]byte{}) {
+		return spanContext{}, false
+	}
+	if _, err := hex.Decode(sc.spanID[:], []byte(parts[2])); err != nil || sc.spanID == ([8]byte{}) {
+		return spanContext{}, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return spanContext{}, false
+	}
+	sc.sampled = flags[0]&1 == 1
+	return sc, true
+}
@@ -0,0 +1,166 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// collector is an OTLP/HTTP endpoint that keeps the spans it receives
+type collector struct {
+	mu      sync.Mutex
+	spans   []otlpSpan
+	service string
+	apiKey  string
+}
+
+func newCollector(t *testing.T) (*collector, *httptest.Server) {
+	t.Helper()
+	c := &collector{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body otlpExport
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("export body is not OTLP JSON: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.apiKey = r.Header.Get("api-key")
+		for _, rs := range body.ResourceSpans {
+			c.service = *rs.Resource.Attributes[0].Value.StringValue
+			for _, ss := range rs.ScopeSpans {
+				c.spans = append(c.spans, ss.Spans...)
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return c, srv
+}
+
+func TestTracing_ExportsSpanTree(t *testing.T) {
+	c, srv := newCollector(t)
+	InitTracing(TracingOptions{Endpoint: srv.URL, Headers: map[string]string{"api-key": "k"}, ServiceName: "tm-test", SampleRatio: 1})
+
+	ctx, root := StartServerSpan(context.Background(), "POST /api/analyze", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, child := StartClientSpan(ctx, "fmp", "http.status_code", 503, "cached", false)
+	child.RecordError(errors.New("service unavailable"))
+	child.End()
+	root.End()
+	root.End()
+
+	if err := ShutdownTracing(context.Background()); err != nil {
+		t.Fatalf("ShutdownTracing error = %v", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(c.spans))
+	}
+	if c.service != "tm-test" || c.apiKey != "k" {
+		t.Errorf("service = %q, api-key = %q, want the configured name and header", c.service, c.apiKey)
+	}
+	exportedChild, exportedRoot := c.spans[0], c.spans[1]
+	if exportedRoot.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || exportedRoot.ParentSpanID != "00f067aa0ba902b7" || exportedRoot.Kind != SpanKindServer {
+		t.Errorf("root = %+v, want a server span continuing the caller's trace", exportedRoot)
+	}
+	if exportedChild.TraceID != exportedRoot.TraceID || exportedChild.ParentSpanID != exportedRoot.SpanID || exportedChild.Kind != SpanKindClient {
+		t.Errorf("child = %+v, want a client span under the root", exportedChild)
+	}
+	if exportedChild.Status.Code != otlpStatusError || exportedChild.Status.Message != "service unavailable" {
+		t.Errorf("child status = %+v, want the recorded error", exportedChild.Status)
+	}
+	if len(exportedChild.Attributes) != 2 || *exportedChild.Attributes[0].Value.IntValue != "503" || *exportedChild.Attributes[1].Value.BoolValue {
+		t.Errorf("child attributes = %+v, want the status code and cached flag", exportedChild.Attributes)
+	}
+}
+
+func TestTracing_UnsampledTraceIsNotExported(t *testing.T) {
+	c, srv := newCollector(t)
+	InitTracing(TracingOptions{Endpoint: srv.URL, SampleRatio: 1})
+
+	ctx, root := StartServerSpan(context.Background(), "GET /api/positions", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	_, child := StartSpan(ctx, "app.positions")
+	child.End()
+	root.End()
+
+	if err := ShutdownTracing(context.Background()); err != nil {
+		t.Fatalf("ShutdownTracing error = %v", err)
+	}
+	if len(c.spans) != 0 {
+		t.Errorf("exported %d spans, want none for a trace the caller didn't sample", len(c.spans))
+	}
+}
+
+func TestTracing_Disabled(t *testing.T) {
+	InitTracing(TracingOptions{})
+
+	ctx, span := StartSpan(context.Background(), "analysis")
+	if span != nil {
+		t.Fatal("expected no span while tracing is off")
+	}
+	span.SetAttributes("symbol", "AAPL")
+	span.RecordError(errors.New("ignored"))
+	span.End()
+	if TraceID(ctx) != "" {
+		t.Error("expected no trace ID while tracing is off")
+	}
+}
+
+func TestContextWithSpan(t *testing.T) {
+	_, srv := newCollector(t)
+	InitTracing(TracingOptions{Endpoint: srv.URL, SampleRatio: 1})
+	defer ShutdownTracing(context.Background())
+
+	reqCtx, cancel := context.WithCancel(context.Background())
+	reqCtx, span := StartSpan(reqCtx, "request")
+	defer span.End()
+	cancel()
+
+	ctx := ContextWithSpan(context.Background(), reqCtx)
+	if ctx.Err() != nil {
+		t.Error("expected the request's cancellation not to carry over")
+	}
+	if TraceID(ctx) == "" || TraceID(ctx) != TraceID(reqCtx) {
+		t.Errorf("TraceID = %q, want the request's trace %q", TraceID(ctx), TraceID(reqCtx))
+	}
+}
+
+func TestParseTraceParent(t *testing.T) {
+	for _, tt := range []struct {
+		header      string
+		wantOK      bool
+		wantSampled bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true, false},
+		{"", false, false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false, false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01", false, false},
+	} {
+		sc, ok := parseTraceParent(tt.header)
+		if ok != tt.wantOK || sc.sampled != tt.wantSampled {
+			t.Errorf("parseTraceParent(%q) = sampled %v, ok %v, want %v, %v", tt.header, sc.sampled, ok, tt.wantSampled, tt.wantOK)
+		}
+	}
+}
+
+func TestTracer_Sample(t *testing.T) {
+	var low, high [16]byte
+	high[8] = 0xff
+	tr := &tracer{sampleRatio: 0.5}
+	if !tr.sample(low) || tr.sample(high) {
+		t.Error("expected the ratio to split traces by the low half of their ID")
+	}
+	tr.sampleRatio = 0
+	if tr.sample(low) {
+		t.Error("expected no traces sampled at ratio 0")
+	}
+}
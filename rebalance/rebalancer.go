@@ -0,0 +1,287 @@
+package rebalance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+	"trade-machine/repository"
+
+	"github.com/shopspring/decimal"
+)
+
+// triggerReason marks the recommendations a rebalance records
+const triggerReason = "Rebalance to target weights"
+
+// Repository defines the repository operations needed by Rebalancer
+type Repository interface {
+	UnitOfWork(ctx context.Context, fn func(tx repository.RepositoryInterface) error) error
+}
+
+// Broker supplies the account, positions and prices a plan is sized from
+type Broker interface {
+	GetAccount(ctx context.Context) (*models.Account, error)
+	GetPositions(ctx context.Context) ([]models.Position, error)
+	GetQuote(ctx context.Context, symbol string) (*models.Quote, error)
+}
+
+// SectorProvider looks up the sector of a holding for sector targets
+type SectorProvider interface {
+	GetFundamentals(ctx context.Context, symbol string) (*models.Fundamentals, error)
+}
+
+// Rebalancer plans the orders that move the portfolio's positions and sectors to their
+// target shares of equity, and records them as recommendations to execute
+type Rebalancer struct {
+	repo           Repository
+	broker         Broker
+	sectors        SectorProvider
+	driftThreshold float64
+	now            func() time.Time
+}
+
+// NewRebalancer creates a new Rebalancer. Weights within driftThreshold of their target are
+// left alone. sectors may be nil, in which case sector targets are reported as unplannable.
+func NewRebalancer(repo Repository, broker Broker, sectors SectorProvider, driftThreshold float64) *Rebalancer {
+	return &Rebalancer{
+		repo:           repo,
+		broker:         broker,
+		sectors:        sectors,
+		driftThreshold: driftThreshold,
+		now:            time.Now,
+	}
+}
+
+// holding is a long position valued at its current price
+type holding struct {
+	quantity decimal.Decimal
+	price    decimal.Decimal
+	value    decimal.Decimal
+}
+
+// Plan works out the orders that bring each targeted position and sector back to its
+// target weight without placing or recording anything. Position targets are met directly;
+// sector targets scale the sector's other long positions together, keeping their relative
+// sizes. Short positions are never rebalanced.
+func (r *Rebalancer) Plan(ctx context.Context, targets models.RebalanceTargets) (*models.RebalancePlan, error) {
+	targets = targets.Normalize()
+	if err := targets.Validate(); err != nil {
+		return nil, err
+	}
+	if targets.Empty() {
+		return nil, fmt.Errorf("%w: no position or sector targets set", models.ErrInvalidRebalanceTargets)
+	}
+
+	account, err := r.broker.GetAccount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	equity := account.Equity
+	if !equity.IsPositive() {
+		equity = account.PortfolioValue
+	}
+	if !equity.IsPositive() {
+		return nil, fmt.Errorf("cannot rebalance an account with no equity")
+	}
+
+	positions, err := r.broker.GetPositions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+	holdings := make(map[string]holding)
+	shorts := make(map[string]bool)
+	for _, p := range positions {
+		if p.EffectiveSide() == models.PositionSideShort {
+			shorts[p.Symbol] = true
+			continue
+		}
+		holdings[p.Symbol] = holding{quantity: p.Quantity, price: p.CurrentPrice, value: p.Quantity.Mul(p.CurrentPrice)}
+	}
+
+	plan := &models.RebalancePlan{
+		Equity:         equity,
+		Targets:        targets,
+		DriftThreshold: r.driftThreshold,
+		CreatedAt:      r.now(),
+	}
+	r.planPositions(ctx, plan, holdings, shorts)
+	r.planSectors(ctx, plan, holdings)
+	plan.SortItems()
+	return plan, nil
+}
+
+// planPositions sizes each symbol with a position target, pricing symbols not yet held from a
+// quote's last trade, or its bid/ask midpoint when it has none
+func (r *Rebalancer) planPositions(ctx context.Context, plan *models.RebalancePlan, holdings map[string]holding, shorts map[string]bool) {
+	symbols := make([]string, 0, len(plan.Targets.Positions))
+	for symbol := range plan.Targets.Positions {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	for _, symbol := range symbols {
+		if shorts[symbol] {
+			plan.Notes = append(plan.Notes, fmt.Sprintf("%s is held short; cover it before rebalancing to a long target", symbol))
+			continue
+		}
+		h, held := holdings[symbol]
+		if !held || !h.price.IsPositive() {
+			quote, err := r.broker.GetQuote(ctx, symbol)
+			if err != nil || quote == nil || !quote.Price().IsPositive() {
+				plan.Notes = append(plan.Notes, fmt.Sprintf("%s has no price to size an order from", symbol))
+				continue
+			}
+			h.price = quote.Price()
+			h.value = h.quantity.Mul(h.price)
+		}
+
+		target := plan.Targets.Positions[symbol]
+		targetValue := plan.Equity.Mul(decimal.NewFromFloat(target))
+		if item, ok := r.item(plan.Equity, symbol, h, targetValue); ok {
+			item.TargetWeight = target
+			item.Reasoning = fmt.Sprintf("Rebalance %s from %.1f%% to its %.1f%% target weight", symbol, item.CurrentWeight*100, target*100)
+			plan.Items = append(plan.Items, item)
+		}
+	}
+}
+
+// planSectors scales the long positions of each targeted sector that have no position target
+// of their own. Sectors are only traded when their combined weight has drifted past the threshold.
+func (r *Rebalancer) planSectors(ctx context.Context, plan *models.RebalancePlan, holdings map[string]holding) {
+	if len(plan.Targets.Sectors) == 0 {
+		return
+	}
+	if r.sectors == nil {
+		plan.Notes = append(plan.Notes, "sector targets need a fundamentals provider to look up sectors")
+		return
+	}
+
+	members := make(map[string][]string)
+	for symbol := range holdings {
+		if _, targeted := plan.Targets.Positions[symbol]; targeted {
+			continue
+		}
+		fundamentals, err := r.sectors.GetFundamentals(ctx, symbol)
+		if err != nil || fundamentals == nil || fundamentals.Sector == "" {
+			observability.Warn("rebalance: sector lookup failed", "symbol", symbol, "error", err)
+			continue
+		}
+		sector := models.NormalizeSector(fundamentals.Sector)
+		if _, targeted := plan.Targets.Sectors[sector]; targeted {
+			members[sector] = append(members[sector], symbol)
+		}
+	}
+
+	sectors := make([]string, 0, len(plan.Targets.Sectors))
+	for sector := range plan.Targets.Sectors {
+		sectors = append(sectors, sector)
+	}
+	sort.Strings(sectors)
+
+	for _, sector := range sectors {
+		symbols := members[sector]
+		if len(symbols) == 0 {
+			plan.Notes = append(plan.Notes, fmt.Sprintf("no untargeted holdings in %s to scale; add a position target to buy into it", sector))
+			continue
+		}
+		sort.Strings(symbols)
+
+		current := decimal.Zero
+		for _, symbol := range symbols {
+			current = current.Add(holdings[symbol].value)
+		}
+		target := plan.Targets.Sectors[sector]
+		currentWeight, _ := current.Div(plan.Equity).Float64()
+		if math.Abs(currentWeight-target) <= r.driftThreshold || !current.IsPositive() {
+			continue
+		}
+
+		scale := plan.Equity.Mul(decimal.NewFromFloat(target)).Div(current)
+		for _, symbol := range symbols {
+			h := holdings[symbol]
+			targetValue := h.value.Mul(scale)
+			item, ok := r.sized(plan.Equity, symbol, h, targetValue)
+			if !ok {
+				continue
+			}
+			item.Sector = sector
+			item.TargetWeight, _ = targetValue.Div(plan.Equity).Float64()
+			item.Reasoning = fmt.Sprintf("Rebalance %s from %.1f%% to its %.1f%% target weight", sector, currentWeight*100, target*100)
+			plan.Items = append(plan.Items, item)
+		}
+	}
+}
+
+// item returns the order moving h to targetValue when its weight has drifted past the threshold
+func (r *Rebalancer) item(equity decimal.Decimal, symbol string, h holding, targetValue decimal.Decimal) (models.RebalanceItem, bool) {
+	drift, _ := h.value.Sub(targetValue).Div(equity).Float64()
+	if math.Abs(drift) <= r.driftThreshold {
+		return models.RebalanceItem{}, false
+	}
+	return r.sized(equity, symbol, h, targetValue)
+}
+
+// sized returns the whole-share order moving h toward targetValue, never selling more than is
+// held, or false when the difference is less than one share
+func (r *Rebalancer) sized(equity decimal.Decimal, symbol string, h holding, targetValue decimal.Decimal) (models.RebalanceItem, bool) {
+	delta := targetValue.Sub(h.value)
+	action := models.RecommendationActionBuy
+	if delta.IsNegative() {
+		action = models.RecommendationActionSell
+	}
+	quantity := delta.Abs().Div(h.price).Floor()
+	if action == models.RecommendationActionSell && quantity.GreaterThan(h.quantity) {
+		quantity = h.quantity
+	}
+	if !quantity.IsPositive() {
+		return models.RebalanceItem{}, false
+	}
+
+	currentWeight, _ := h.value.Div(equity).Float64()
+	return models.RebalanceItem{
+		Symbol:        symbol,
+		Action:        action,
+		Quantity:      quantity,
+		Price:         h.price,
+		CurrentWeight: currentWeight,
+	}, true
+}
+
+// Generate plans the rebalance and records each order as a pending recommendation in one
+// transaction, so a failure leaves none of them behind, returning the plan with the
+// recommendation IDs and versions filled in
+func (r *Rebalancer) Generate(ctx context.Context, targets models.RebalanceTargets) (*models.RebalancePlan, error) {
+	plan, err := r.Plan(ctx, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.repo.UnitOfWork(ctx, func(tx repository.RepositoryInterface) error {
+		for i := range plan.Items {
+			item := &plan.Items[i]
+			rec := models.NewRecommendation(item.Symbol, item.Action, item.Reasoning)
+			rec.Quantity = item.Quantity
+			rec.EntryPrice = item.Price
+			rec.Confidence = 100
+			rec.DataCompleteness = 100
+			rec.TriggerReason = triggerReason
+			if err := tx.CreateRecommendation(ctx, rec); err != nil {
+				return fmt.Errorf("failed to save %s rebalance recommendation: %w", item.Symbol, err)
+			}
+			id := rec.ID
+			item.RecommendationID = &id
+			item.RecommendationVersion = rec.Version
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	observability.Info("rebalance planned", "orders", len(plan.Items), "notes", len(plan.Notes))
+	return plan, nil
+}
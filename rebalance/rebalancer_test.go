@@ -0,0 +1,211 @@
+package rebalance
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"trade-machine/models"
+	"trade-machine/repository"
+
+	"github.com/shopspring/decimal"
+)
+
+// mockRepo saves recommendations in a unit of work, keeping them only if it succeeds
+type mockRepo struct {
+	repository.RepositoryInterface
+	saved   []*models.Recommendation
+	pending []*models.Recommendation
+	failOn  string // Symbol whose recommendation fails to save
+}
+
+func (m *mockRepo) UnitOfWork(ctx context.Context, fn func(tx repository.RepositoryInterface) error) error {
+	m.pending = nil
+	if err := fn(m); err != nil {
+		return err
+	}
+	m.saved = append(m.saved, m.pending...)
+	return nil
+}
+
+func (m *mockRepo) CreateRecommendation(ctx context.Context, rec *models.Recommendation) error {
+	if rec.Symbol == m.failOn {
+		return errors.New("insert failed")
+	}
+	rec.Version = 1
+	m.pending = append(m.pending, rec)
+	return nil
+}
+
+type mockBroker struct {
+	equity    decimal.Decimal
+	positions []models.Position
+	quotes    map[string]decimal.Decimal
+}
+
+func (m *mockBroker) GetAccount(ctx context.Context) (*models.Account, error) {
+	return &models.Account{Equity: m.equity}, nil
+}
+
+func (m *mockBroker) GetPositions(ctx context.Context) ([]models.Position, error) {
+	return m.positions, nil
+}
+
+// GetQuote quotes a bid and ask around the price without a last trade, as Alpaca does
+func (m *mockBroker) GetQuote(ctx context.Context, symbol string) (*models.Quote, error) {
+	price, ok := m.quotes[symbol]
+	if !ok {
+		return nil, errors.New("no quote")
+	}
+	spread := decimal.NewFromFloat(0.05)
+	return &models.Quote{Symbol: symbol, Bid: price.Sub(spread), Ask: price.Add(spread)}, nil
+}
+
+type mockSectors map[string]string
+
+func (m mockSectors) GetFundamentals(ctx context.Context, symbol string) (*models.Fundamentals, error) {
+	return &models.Fundamentals{Symbol: symbol, Sector: m[symbol]}, nil
+}
+
+func dec(v float64) decimal.Decimal {
+	return decimal.NewFromFloat(v)
+}
+
+func position(symbol string, qty, price float64) models.Position {
+	return models.Position{Symbol: symbol, Quantity: dec(qty), CurrentPrice: dec(price), Side: models.PositionSideLong}
+}
+
+func TestRebalancer_Plan(t *testing.T) {
+	broker := &mockBroker{
+		equity: dec(100000),
+		positions: []models.Position{
+			position("AAPL", 100, 200), // 20%
+			position("MSFT", 50, 400),  // 20%
+			position("NVDA", 100, 100), // 10%
+			position("XOM", 10, 100),   // 1%
+		},
+		quotes: map[string]decimal.Decimal{"JNJ": dec(150)},
+	}
+	sectors := mockSectors{"MSFT": "Technology", "NVDA": "Technology", "XOM": "Energy"}
+	rebalancer := NewRebalancer(&mockRepo{}, broker, sectors, 0.02)
+
+	plan, err := rebalancer.Plan(context.Background(), models.RebalanceTargets{
+		Positions: map[string]float64{"aapl": 0.10, "JNJ": 0.06},
+		Sectors:   map[string]float64{"Information Technology": 0.15, "Energy": 0.02},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []struct {
+		symbol   string
+		action   models.RecommendationAction
+		quantity int64
+	}{
+		// Sells first, largest first: technology is scaled from 30% to 15%
+		{"AAPL", models.RecommendationActionSell, 50},
+		{"MSFT", models.RecommendationActionSell, 25},
+		{"NVDA", models.RecommendationActionSell, 50},
+		{"JNJ", models.RecommendationActionBuy, 40},
+	}
+	if len(plan.Items) != len(want) {
+		t.Fatalf("got %d items %+v, want %d", len(plan.Items), plan.Items, len(want))
+	}
+	for i, w := range want {
+		item := plan.Items[i]
+		if item.Symbol != w.symbol || item.Action != w.action || !item.Quantity.Equal(decimal.NewFromInt(w.quantity)) {
+			t.Errorf("Items[%d] = %s %s %s, want %s %d %s", i, item.Action, item.Quantity, item.Symbol, w.action, w.quantity, w.symbol)
+		}
+	}
+	if plan.Items[1].Sector != models.SectorInformationTechnology {
+		t.Errorf("MSFT sector = %q, want it sized by its sector target", plan.Items[1].Sector)
+	}
+	// Energy is at 1% against a 2% target, within the drift threshold
+	for _, item := range plan.Items {
+		if item.Symbol == "XOM" {
+			t.Error("expected XOM to be left alone within the drift threshold")
+		}
+	}
+}
+
+func TestRebalancer_Plan_Notes(t *testing.T) {
+	broker := &mockBroker{
+		equity: dec(100000),
+		positions: []models.Position{
+			{Symbol: "TSLA", Quantity: dec(10), CurrentPrice: dec(200), Side: models.PositionSideShort},
+		},
+	}
+	rebalancer := NewRebalancer(&mockRepo{}, broker, nil, 0.02)
+
+	plan, err := rebalancer.Plan(context.Background(), models.RebalanceTargets{
+		Positions: map[string]float64{"TSLA": 0.05, "UNKNOWN": 0.05},
+		Sectors:   map[string]float64{"Energy": 0.1},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Items) != 0 {
+		t.Errorf("expected no orders, got %+v", plan.Items)
+	}
+	notes := strings.Join(plan.Notes, "\n")
+	for _, want := range []string{"TSLA is held short", "UNKNOWN has no price", "need a fundamentals provider"} {
+		if !strings.Contains(notes, want) {
+			t.Errorf("Notes = %q, want one containing %q", notes, want)
+		}
+	}
+}
+
+func TestRebalancer_Plan_InvalidTargets(t *testing.T) {
+	rebalancer := NewRebalancer(&mockRepo{}, &mockBroker{equity: dec(1000)}, nil, 0.02)
+
+	for _, targets := range []models.RebalanceTargets{
+		{},
+		{Positions: map[string]float64{"AAPL": 1.5}},
+	} {
+		if _, err := rebalancer.Plan(context.Background(), targets); !errors.Is(err, models.ErrInvalidRebalanceTargets) {
+			t.Errorf("Plan(%+v) = %v, want ErrInvalidRebalanceTargets", targets, err)
+		}
+	}
+}
+
+func TestRebalancer_Generate(t *testing.T) {
+	repo := &mockRepo{}
+	broker := &mockBroker{equity: dec(10000), positions: []models.Position{position("AAPL", 20, 100)}}
+	rebalancer := NewRebalancer(repo, broker, nil, 0.02)
+
+	plan, err := rebalancer.Generate(context.Background(), models.RebalanceTargets{Positions: map[string]float64{"AAPL": 0.1}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.saved) != 1 || len(plan.Items) != 1 {
+		t.Fatalf("expected one saved recommendation, got %d for %d items", len(repo.saved), len(plan.Items))
+	}
+	rec := repo.saved[0]
+	if rec.Action != models.RecommendationActionSell || !rec.Quantity.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("recommendation = %s %s, want sell 10", rec.Action, rec.Quantity)
+	}
+	if rec.Status != models.RecommendationStatusPending || rec.TriggerReason != triggerReason {
+		t.Errorf("unexpected recommendation %+v", rec)
+	}
+	if plan.Items[0].RecommendationID == nil || *plan.Items[0].RecommendationID != rec.ID || plan.Items[0].RecommendationVersion != 1 {
+		t.Errorf("expected the plan item to reference recommendation %s at version 1", rec.ID)
+	}
+}
+
+func TestRebalancer_Generate_Atomic(t *testing.T) {
+	repo := &mockRepo{failOn: "MSFT"}
+	broker := &mockBroker{
+		equity:    dec(10000),
+		positions: []models.Position{position("AAPL", 20, 100), position("MSFT", 10, 100)},
+	}
+	rebalancer := NewRebalancer(repo, broker, nil, 0.02)
+
+	targets := models.RebalanceTargets{Positions: map[string]float64{"AAPL": 0.1, "MSFT": 0.2}}
+	if _, err := rebalancer.Generate(context.Background(), targets); err == nil || !strings.Contains(err.Error(), "MSFT") {
+		t.Fatalf("Generate() error = %v, want the MSFT save failure", err)
+	}
+	if len(repo.saved) != 0 {
+		t.Errorf("saved %d recommendations, want none once one of them failed", len(repo.saved))
+	}
+}
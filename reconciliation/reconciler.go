@@ -0,0 +1,599 @@
+package reconciliation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"trade-machine/events"
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/shopspring/decimal"
+)
+
+// checkInterval is how often Run looks for a finished month without a report
+const checkInterval = 15 * time.Minute
+
+// orderPollInterval is how often Run syncs fills and polls the status of open orders
+const orderPollInterval = time.Minute
+
+// priceTolerance is the largest per-share or per-symbol amount treated as a rounding difference
+var priceTolerance = decimal.NewFromFloat(0.01)
+
+// Repository defines the repository operations needed by Reconciler
+type Repository interface {
+	GetExecutedTradesBetween(ctx context.Context, start, end time.Time) ([]models.Trade, error)
+	GetUnfilledTrades(ctx context.Context) ([]models.Trade, error)
+	RecordTradeFill(ctx context.Context, trade *models.Trade) error
+	GetPositions(ctx context.Context) ([]models.Position, error)
+	SaveReconciliationReport(ctx context.Context, report *models.ReconciliationReport) error
+	GetReconciliationReportForPeriod(ctx context.Context, periodStart time.Time) (*models.ReconciliationReport, error)
+}
+
+// Broker supplies the broker's view of fills, fees and positions
+type Broker interface {
+	GetAccountActivities(ctx context.Context, after, until time.Time) ([]models.BrokerActivity, error)
+	GetPositions(ctx context.Context) ([]models.Position, error)
+	GetOrder(ctx context.Context, orderID string) (*models.BrokerOrder, error)
+}
+
+// OrderSource reports the status of orders placed with another broker, so open trades
+// routed there are kept in step with their orders too
+type OrderSource interface {
+	GetOrder(ctx context.Context, orderID string) (*models.BrokerOrder, error)
+}
+
+// FillHandler keeps the books that depend on a trade's fills in step with them. It is told
+// when the shares of a trade the local position holds change: when the order fills in part,
+// fills the rest, or is cancelled, rejected or expired with shares unfilled. It is also told
+// when a stop-loss or take-profit leg guarding a position fills or is cancelled at the
+// broker, since no local trade exists for the leg until then.
+type FillHandler interface {
+	TradeFillChanged(ctx context.Context, previous, trade *models.Trade) error
+	BracketLegClosed(ctx context.Context, pos *models.Position, leg *models.BrokerOrder) error
+}
+
+// Reconciler records broker fills on local trades and compares the local books against
+// the broker in a monthly report. The monthly report covers trades placed with Alpaca;
+// trades placed with other brokers are only synced with their orders.
+type Reconciler struct {
+	repo   Repository
+	broker Broker
+	orders map[string]OrderSource // By broker name, for trades not placed with Alpaca
+	fills  FillHandler            // Optional
+	fees   models.FeeSchedule
+	now    func() time.Time
+}
+
+// NewReconciler creates a new Reconciler. The fee schedule prices fills the broker reports
+// no fees for; pass a zero schedule to rely on broker fee activities alone.
+func NewReconciler(repo Repository, broker Broker, fees models.FeeSchedule) *Reconciler {
+	return &Reconciler{
+		repo:   repo,
+		broker: broker,
+		fees:   fees,
+		orders: make(map[string]OrderSource),
+		now:    time.Now,
+	}
+}
+
+// AddOrderSource registers the broker whose orders trades recorded under name are synced with
+func (r *Reconciler) AddOrderSource(name string, source OrderSource) {
+	r.orders[name] = source
+}
+
+// SetFillHandler sets the handler told when a trade's position quantity changes
+func (r *Reconciler) SetFillHandler(h FillHandler) {
+	r.fills = h
+}
+
+// recordFill saves a trade's fills and status, then tells the fill handler if the shares
+// the position holds for it changed from previous
+func (r *Reconciler) recordFill(ctx context.Context, previous models.Trade, t *models.Trade) error {
+	if err := r.repo.RecordTradeFill(ctx, t); err != nil {
+		return err
+	}
+	if r.fills == nil || previous.PositionQuantity().Equal(t.PositionQuantity()) {
+		return nil
+	}
+	return r.fills.TradeFillChanged(ctx, &previous, t)
+}
+
+// orderSource returns where a trade's order is looked up, or nil for a broker that is not registered
+func (r *Reconciler) orderSource(t *models.Trade) OrderSource {
+	if name := t.BrokerName(); name != models.BrokerAlpaca {
+		return r.orders[name]
+	}
+	return r.broker
+}
+
+// alpacaTrades returns the trades placed with Alpaca, the only ones its activities cover
+func alpacaTrades(trades []models.Trade) []models.Trade {
+	kept := trades[:0:0]
+	for _, t := range trades {
+		if t.BrokerName() == models.BrokerAlpaca {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+// Run keeps open trades in step with their broker orders and reconciles each month once it
+// has finished, checking periodically until ctx is cancelled
+func (r *Reconciler) Run(ctx context.Context) {
+	orderTicker := time.NewTicker(orderPollInterval)
+	defer orderTicker.Stop()
+	checkTicker := time.NewTicker(checkInterval)
+	defer checkTicker.Stop()
+
+	r.syncOpenTrades(ctx)
+	r.checkMonth(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-orderTicker.C:
+			r.syncOpenTrades(ctx)
+		case <-checkTicker.C:
+			r.checkMonth(ctx)
+		}
+	}
+}
+
+// syncOpenTrades records broker fills first, so filled orders take their broker fees, then
+// picks up the cancellations, rejections and expiries only the order status shows
+func (r *Reconciler) syncOpenTrades(ctx context.Context) {
+	if _, err := r.SyncFills(ctx); err != nil {
+		observability.Warn("trade fill sync failed", "error", err)
+	}
+	if _, err := r.SyncOrders(ctx); err != nil {
+		observability.Warn("order status sync failed", "error", err)
+	}
+}
+
+func (r *Reconciler) checkMonth(ctx context.Context) {
+	if err := r.reconcileLastMonthIfDue(ctx); err != nil {
+		observability.Warn("monthly reconciliation failed", "error", err)
+	}
+}
+
+// reconcileLastMonthIfDue reconciles the previous calendar month unless it already has a
+// complete report. A partial report, run before the month ended, is replaced.
+func (r *Reconciler) reconcileLastMonthIfDue(ctx context.Context) error {
+	thisMonth, _ := models.MonthBounds(r.now())
+	lastMonth := thisMonth.AddDate(0, -1, 0)
+
+	existing, err := r.repo.GetReconciliationReportForPeriod(ctx, lastMonth)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.Complete() {
+		return nil
+	}
+
+	report, err := r.Reconcile(ctx, lastMonth)
+	if err != nil {
+		return err
+	}
+	observability.Info("monthly reconciliation completed",
+		"period", report.PeriodStart.Format("2006-01"),
+		"discrepancies", report.DiscrepancyCount())
+	return nil
+}
+
+// Reconcile compares trades, fees and cash for the calendar month containing month, and
+// current positions, against the broker, then saves the report
+func (r *Reconciler) Reconcile(ctx context.Context, month time.Time) (*models.ReconciliationReport, error) {
+	report := models.NewReconciliationReport(month)
+
+	if _, err := r.SyncFills(ctx); err != nil {
+		return nil, err
+	}
+
+	trades, err := r.repo.GetExecutedTradesBetween(ctx, report.PeriodStart, report.PeriodEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load local trades: %w", err)
+	}
+	trades = alpacaTrades(trades)
+	activities, err := r.broker.GetAccountActivities(ctx, report.PeriodStart, report.PeriodEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load broker activities: %w", err)
+	}
+	localPositions, err := r.repo.GetPositions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load local positions: %w", err)
+	}
+	brokerPositions, err := r.broker.GetPositions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load broker positions: %w", err)
+	}
+
+	var fills, fees []models.BrokerActivity
+	for _, a := range activities {
+		switch a.Type {
+		case models.BrokerActivityFill:
+			fills = append(fills, a)
+		case models.BrokerActivityFee:
+			fees = append(fees, a)
+		}
+	}
+
+	matchFills(report, trades, fills)
+	report.FeeDiscrepancies = compareFees(trades, fees)
+	report.PositionDrift = comparePositions(localPositions, brokerPositions)
+	report.CashFlow = compareCashFlow(trades, fills, fees)
+
+	if err := r.repo.SaveReconciliationReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// SyncFills marks open trades executed once the broker reports their fills, recording the
+// average fill price and the fees charged. Trades filled in part record the shares filled so
+// far and the fees on them, and stay open. It returns the number of trades updated.
+func (r *Reconciler) SyncFills(ctx context.Context) (int, error) {
+	trades, err := r.repo.GetUnfilledTrades(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load unfilled trades: %w", err)
+	}
+	trades = alpacaTrades(trades)
+	if len(trades) == 0 {
+		return 0, nil
+	}
+
+	since, _ := dayBounds(trades[0].CreatedAt)
+	activities, err := r.broker.GetAccountActivities(ctx, since, r.now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to load broker activities: %w", err)
+	}
+
+	byOrder := make(map[string]*orderFills)
+	var fees []models.BrokerActivity
+	for _, a := range activities {
+		switch a.Type {
+		case models.BrokerActivityFill:
+			if a.OrderID == "" {
+				continue
+			}
+			o, ok := byOrder[a.OrderID]
+			if !ok {
+				o = &orderFills{}
+				byOrder[a.OrderID] = o
+			}
+			o.add(a)
+		case models.BrokerActivityFee:
+			fees = append(fees, a)
+		}
+	}
+
+	var filled, partial []*models.Trade
+	previous := make(map[*models.Trade]models.Trade)
+	lastFill := make(map[*models.Trade]time.Time)
+	for i := range trades {
+		t := &trades[i]
+		o, ok := byOrder[t.AlpacaOrderID]
+		if !ok || o.quantity.Equal(t.FilledQuantity) {
+			continue
+		}
+		previous[t] = *t
+		lastFill[t] = o.lastFill
+		t.FilledQuantity = o.quantity
+		t.Price = o.avgPrice().Round(4)
+		t.TotalValue = t.FilledQuantity.Mul(t.Price)
+		r.fees.Apply(t)
+		if o.quantity.LessThan(t.Quantity) {
+			t.Status = models.TradeStatusPartiallyFilled
+			partial = append(partial, t)
+			continue
+		}
+		executedAt := o.lastFill
+		t.Status = models.TradeStatusExecuted
+		t.ExecutedAt = &executedAt
+		filled = append(filled, t)
+	}
+	allocateBrokerFees(lastFill, fees)
+
+	for _, t := range partial {
+		if err := r.recordFill(ctx, previous[t], t); err != nil {
+			return 0, err
+		}
+	}
+	for _, t := range filled {
+		if err := r.recordFill(ctx, previous[t], t); err != nil {
+			return 0, err
+		}
+		events.Publish(events.TradeFilled, t)
+	}
+	return len(filled) + len(partial), nil
+}
+
+// SyncOrders polls the broker order of each open trade and records its status and fills,
+// pricing the commission and fees on the shares the broker reports filled from the fee
+// schedule, including orders still filling. The fill
+// handler is told of orders that filled in part or settled with shares unfilled, and of
+// bracket legs that closed. Orders the broker cannot report are skipped until the next
+// poll. It returns the number of trades updated and legs closed.
+func (r *Reconciler) SyncOrders(ctx context.Context) (int, error) {
+	trades, err := r.repo.GetUnfilledTrades(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load unfilled trades: %w", err)
+	}
+
+	updated := 0
+	for i := range trades {
+		t := &trades[i]
+		source := r.orderSource(t)
+		if source == nil {
+			continue
+		}
+		order, err := source.GetOrder(ctx, t.AlpacaOrderID)
+		if err != nil {
+			observability.Warn("order status unavailable", "trade_id", t.ID, "order_id", t.AlpacaOrderID, "error", err)
+			continue
+		}
+		previous := *t
+		if !order.ApplyTo(t) {
+			continue
+		}
+		if t.FilledQuantity.IsPositive() {
+			r.fees.Apply(t)
+		}
+		if err := r.recordFill(ctx, previous, t); err != nil {
+			return updated, err
+		}
+		if t.Status == models.TradeStatusExecuted {
+			events.Publish(events.TradeFilled, t)
+		}
+		updated++
+	}
+	if r.fills == nil {
+		return updated, nil
+	}
+	closed, err := r.syncBracketLegs(ctx)
+	return updated + closed, err
+}
+
+// syncBracketLegs polls the stop-loss and take-profit legs guarding local positions and
+// tells the fill handler of each that is no longer open, so a stop or target hit at the
+// broker reduces the position here too. Once a leg fills the broker cancels the other, so
+// the position's remaining leg isn't polled. It returns the number of legs closed.
+func (r *Reconciler) syncBracketLegs(ctx context.Context) (int, error) {
+	positions, err := r.repo.GetPositions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load positions: %w", err)
+	}
+
+	closed := 0
+	for i := range positions {
+		pos := &positions[i]
+		for _, id := range pos.BracketLegs() {
+			leg, err := r.broker.GetOrder(ctx, id)
+			if err != nil {
+				observability.Warn("bracket leg status unavailable", "symbol", pos.Symbol, "order_id", id, "error", err)
+				continue
+			}
+			if leg.TradeStatus().Open() {
+				continue
+			}
+			if err := r.fills.BracketLegClosed(ctx, pos, leg); err != nil {
+				return closed, err
+			}
+			closed++
+			if leg.FilledQuantity.IsPositive() {
+				break
+			}
+		}
+	}
+	return closed, nil
+}
+
+// allocateBrokerFees splits each symbol's broker fees for a day across the trades whose last
+// fill was in that symbol that day, in proportion to their value. Trades without broker fees
+// keep the fees already set on them.
+func allocateBrokerFees(lastFill map[*models.Trade]time.Time, fees []models.BrokerActivity) {
+	type key struct {
+		symbol string
+		day    time.Time
+	}
+	charged := make(map[key]decimal.Decimal)
+	for _, f := range fees {
+		day, _ := dayBounds(f.TransactionTime)
+		k := key{f.Symbol, day}
+		charged[k] = charged[k].Add(f.NetAmount.Abs())
+	}
+
+	byDay := make(map[key][]*models.Trade)
+	for t, at := range lastFill {
+		day, _ := dayBounds(at)
+		k := key{t.Symbol, day}
+		byDay[k] = append(byDay[k], t)
+	}
+
+	for k, group := range byDay {
+		amount, ok := charged[k]
+		if !ok {
+			continue
+		}
+		var total decimal.Decimal
+		for _, t := range group {
+			total = total.Add(t.TotalValue)
+		}
+		for _, t := range group {
+			share := amount.Div(decimal.NewFromInt(int64(len(group))))
+			if total.IsPositive() {
+				share = amount.Mul(t.TotalValue).Div(total)
+			}
+			t.Fees = share.Round(2)
+		}
+	}
+}
+
+// dayBounds returns the start of the UTC day containing t and the start of the next day
+func dayBounds(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// orderFills aggregates the partial fills of one broker order
+type orderFills struct {
+	fills    []models.BrokerActivity
+	quantity decimal.Decimal
+	notional decimal.Decimal
+	lastFill time.Time
+}
+
+func (o *orderFills) add(f models.BrokerActivity) {
+	o.fills = append(o.fills, f)
+	o.quantity = o.quantity.Add(f.Quantity)
+	o.notional = o.notional.Add(f.Quantity.Mul(f.Price))
+	if f.TransactionTime.After(o.lastFill) {
+		o.lastFill = f.TransactionTime
+	}
+}
+
+func (o orderFills) avgPrice() decimal.Decimal {
+	if o.quantity.IsZero() {
+		return decimal.Zero
+	}
+	return o.notional.Div(o.quantity)
+}
+
+// matchFills pairs broker fills with local trades by order ID, recording fills with no
+// local trade, executed trades with no fill, and orders whose quantity or price differ
+func matchFills(report *models.ReconciliationReport, trades []models.Trade, fills []models.BrokerActivity) {
+	byOrder := make(map[string]*orderFills)
+	var orderIDs []string
+	for _, f := range fills {
+		if f.OrderID == "" {
+			report.UnmatchedBrokerFills = append(report.UnmatchedBrokerFills, f)
+			continue
+		}
+		o, ok := byOrder[f.OrderID]
+		if !ok {
+			o = &orderFills{}
+			byOrder[f.OrderID] = o
+			orderIDs = append(orderIDs, f.OrderID)
+		}
+		o.add(f)
+	}
+
+	matched := make(map[string]bool)
+	for _, t := range trades {
+		o, ok := byOrder[t.AlpacaOrderID]
+		if t.AlpacaOrderID == "" || !ok {
+			report.UnmatchedTrades = append(report.UnmatchedTrades, t)
+			continue
+		}
+		matched[t.AlpacaOrderID] = true
+		if !o.quantity.Equal(t.Quantity) || o.avgPrice().Sub(t.Price).Abs().GreaterThan(priceTolerance) {
+			report.FillMismatches = append(report.FillMismatches, models.FillMismatch{
+				OrderID:        t.AlpacaOrderID,
+				Symbol:         t.Symbol,
+				TradeID:        t.ID,
+				LocalQuantity:  t.Quantity,
+				BrokerQuantity: o.quantity,
+				LocalPrice:     t.Price,
+				BrokerPrice:    o.avgPrice().Round(4),
+			})
+		}
+	}
+
+	for _, id := range orderIDs {
+		if !matched[id] {
+			report.UnmatchedBrokerFills = append(report.UnmatchedBrokerFills, byOrder[id].fills...)
+		}
+	}
+}
+
+// compareFees compares local commissions and fees with broker fees per symbol
+func compareFees(trades []models.Trade, fees []models.BrokerActivity) []models.FeeDiscrepancy {
+	local := make(map[string]decimal.Decimal)
+	broker := make(map[string]decimal.Decimal)
+	for _, t := range trades {
+		local[t.Symbol] = local[t.Symbol].Add(t.TotalFees())
+	}
+	for _, f := range fees {
+		broker[f.Symbol] = broker[f.Symbol].Add(f.NetAmount.Abs())
+	}
+
+	var discrepancies []models.FeeDiscrepancy
+	for _, symbol := range sortedKeys(local, broker) {
+		if local[symbol].Sub(broker[symbol]).Abs().GreaterThan(priceTolerance) {
+			discrepancies = append(discrepancies, models.FeeDiscrepancy{
+				Symbol:     symbol,
+				LocalFees:  local[symbol],
+				BrokerFees: broker[symbol],
+			})
+		}
+	}
+	return discrepancies
+}
+
+// comparePositions reports symbols whose signed quantity differs between the books and the broker
+func comparePositions(localPositions, brokerPositions []models.Position) []models.PositionDrift {
+	local := signedQuantities(localPositions)
+	broker := signedQuantities(brokerPositions)
+
+	var drift []models.PositionDrift
+	for _, symbol := range sortedKeys(local, broker) {
+		if !local[symbol].Equal(broker[symbol]) {
+			drift = append(drift, models.PositionDrift{
+				Symbol:         symbol,
+				LocalQuantity:  local[symbol],
+				BrokerQuantity: broker[symbol],
+			})
+		}
+	}
+	return drift
+}
+
+// compareCashFlow totals the net cash moved by trades and fees on each side, rounded to cents
+func compareCashFlow(trades []models.Trade, fills, fees []models.BrokerActivity) models.CashFlowComparison {
+	var local, broker decimal.Decimal
+	for _, t := range trades {
+		local = local.Add(t.CashFlow())
+	}
+	for _, f := range fills {
+		notional := f.Quantity.Mul(f.Price)
+		if f.Side == models.TradeSideSell {
+			broker = broker.Add(notional)
+		} else {
+			broker = broker.Sub(notional)
+		}
+	}
+	for _, f := range fees {
+		broker = broker.Sub(f.NetAmount.Abs())
+	}
+	return models.CashFlowComparison{Local: local.Round(2), Broker: broker.Round(2)}
+}
+
+func signedQuantities(positions []models.Position) map[string]decimal.Decimal {
+	quantities := make(map[string]decimal.Decimal, len(positions))
+	for _, p := range positions {
+		qty := p.Quantity.Abs()
+		if p.Side == models.PositionSideShort {
+			qty = qty.Neg()
+		}
+		quantities[p.Symbol] = quantities[p.Symbol].Add(qty)
+	}
+	return quantities
+}
+
+func sortedKeys(maps ...map[string]decimal.Decimal) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,487 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"trade-machine/events"
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
+)
+
+type mockRepo struct {
+	trades    []models.Trade
+	unfilled  []models.Trade
+	filled    []models.Trade
+	positions []models.Position
+	reports   []models.ReconciliationReport
+	period    time.Time // Period the last report lookup asked for
+	saved     *models.ReconciliationReport
+	from, to  time.Time
+}
+
+func (m *mockRepo) GetUnfilledTrades(ctx context.Context) ([]models.Trade, error) {
+	return m.unfilled, nil
+}
+
+func (m *mockRepo) RecordTradeFill(ctx context.Context, trade *models.Trade) error {
+	m.filled = append(m.filled, *trade)
+	return nil
+}
+
+func (m *mockRepo) GetExecutedTradesBetween(ctx context.Context, start, end time.Time) ([]models.Trade, error) {
+	m.from, m.to = start, end
+	return m.trades, nil
+}
+
+func (m *mockRepo) GetPositions(ctx context.Context) ([]models.Position, error) {
+	return m.positions, nil
+}
+
+func (m *mockRepo) SaveReconciliationReport(ctx context.Context, report *models.ReconciliationReport) error {
+	m.saved = report
+	return nil
+}
+
+func (m *mockRepo) GetReconciliationReportForPeriod(ctx context.Context, periodStart time.Time) (*models.ReconciliationReport, error) {
+	m.period = periodStart
+	for i := range m.reports {
+		if m.reports[i].PeriodStart.Equal(periodStart) {
+			return &m.reports[i], nil
+		}
+	}
+	return nil, nil
+}
+
+type mockBroker struct {
+	activities []models.BrokerActivity
+	positions  []models.Position
+	orders     map[string]*models.BrokerOrder
+	err        error
+}
+
+func (m *mockBroker) GetAccountActivities(ctx context.Context, after, until time.Time) ([]models.BrokerActivity, error) {
+	return m.activities, m.err
+}
+
+func (m *mockBroker) GetPositions(ctx context.Context) ([]models.Position, error) {
+	return m.positions, nil
+}
+
+func (m *mockBroker) GetOrder(ctx context.Context, orderID string) (*models.BrokerOrder, error) {
+	o, ok := m.orders[orderID]
+	if !ok {
+		return nil, errors.New("order not found")
+	}
+	return o, nil
+}
+
+// fillRecorder records the position quantity changes and closed bracket legs the
+// reconciler reports
+type fillRecorder struct {
+	changes map[string]decimal.Decimal // By order ID
+	legs    []string
+}
+
+func (f *fillRecorder) BracketLegClosed(ctx context.Context, pos *models.Position, leg *models.BrokerOrder) error {
+	f.legs = append(f.legs, pos.Symbol+" "+leg.ID)
+	return nil
+}
+
+func (f *fillRecorder) TradeFillChanged(ctx context.Context, previous, trade *models.Trade) error {
+	f.changes[trade.AlpacaOrderID] = trade.PositionQuantity().Sub(previous.PositionQuantity())
+	return nil
+}
+
+func dec(v float64) decimal.Decimal {
+	return decimal.NewFromFloat(v)
+}
+
+func trade(symbol string, side models.TradeSide, qty, price, commission float64, orderID string) models.Trade {
+	t := models.NewTrade(symbol, side, dec(qty), dec(price))
+	t.Commission = dec(commission)
+	t.Status = models.TradeStatusExecuted
+	t.AlpacaOrderID = orderID
+	return *t
+}
+
+func fill(orderID, symbol string, side models.TradeSide, qty, price float64) models.BrokerActivity {
+	return models.BrokerActivity{
+		ID:       orderID + symbol,
+		Type:     models.BrokerActivityFill,
+		Symbol:   symbol,
+		Side:     side,
+		Quantity: dec(qty),
+		Price:    dec(price),
+		OrderID:  orderID,
+	}
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	repo := &mockRepo{
+		trades: []models.Trade{
+			trade("AAPL", models.TradeSideBuy, 10, 100, 1, "o1"), // matches two partial fills
+			trade("MSFT", models.TradeSideSell, 5, 200, 0, "o2"), // broker filled 4
+			trade("TSLA", models.TradeSideBuy, 1, 250, 0, "o3"),  // no broker fill
+			trade("NVDA", models.TradeSideBuy, 2, 400, 0, ""),    // never sent to the broker
+		},
+		positions: []models.Position{
+			{Symbol: "AAPL", Quantity: dec(10), Side: models.PositionSideLong},
+			{Symbol: "GME", Quantity: dec(3), Side: models.PositionSideShort},
+		},
+	}
+	broker := &mockBroker{
+		activities: []models.BrokerActivity{
+			fill("o1", "AAPL", models.TradeSideBuy, 4, 99.5),
+			fill("o1", "AAPL", models.TradeSideBuy, 6, 100.3333),
+			fill("o2", "MSFT", models.TradeSideSell, 4, 200),
+			fill("o9", "AMD", models.TradeSideBuy, 1, 150), // unknown order
+			{ID: "f1", Type: models.BrokerActivityFee, Symbol: "AAPL", NetAmount: dec(-1)},
+			{ID: "f2", Type: models.BrokerActivityFee, Symbol: "MSFT", NetAmount: dec(-0.5)},
+			{ID: "d1", Type: "DIV", Symbol: "AAPL", NetAmount: dec(3)},
+		},
+		positions: []models.Position{
+			{Symbol: "AAPL", Quantity: dec(10), Side: models.PositionSideLong},
+			{Symbol: "GME", Quantity: dec(3), Side: models.PositionSideLong},
+			{Symbol: "AMD", Quantity: dec(1), Side: models.PositionSideLong},
+		},
+	}
+
+	r := NewReconciler(repo, broker, models.FeeSchedule{})
+	report, err := r.Reconcile(context.Background(), time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+
+	if repo.saved != report {
+		t.Error("expected report to be saved")
+	}
+	if !repo.from.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) || !repo.to.Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected period %v - %v", repo.from, repo.to)
+	}
+
+	if len(report.UnmatchedBrokerFills) != 1 || report.UnmatchedBrokerFills[0].OrderID != "o9" {
+		t.Errorf("UnmatchedBrokerFills = %+v, want the o9 fill", report.UnmatchedBrokerFills)
+	}
+	if len(report.UnmatchedTrades) != 2 || report.UnmatchedTrades[0].Symbol != "TSLA" || report.UnmatchedTrades[1].Symbol != "NVDA" {
+		t.Errorf("UnmatchedTrades = %+v, want TSLA and NVDA", report.UnmatchedTrades)
+	}
+	if len(report.FillMismatches) != 1 || report.FillMismatches[0].OrderID != "o2" {
+		t.Fatalf("FillMismatches = %+v, want only o2", report.FillMismatches)
+	}
+	if !report.FillMismatches[0].BrokerQuantity.Equal(dec(4)) {
+		t.Errorf("BrokerQuantity = %v, want 4", report.FillMismatches[0].BrokerQuantity)
+	}
+
+	if len(report.FeeDiscrepancies) != 1 || report.FeeDiscrepancies[0].Symbol != "MSFT" {
+		t.Errorf("FeeDiscrepancies = %+v, want only MSFT", report.FeeDiscrepancies)
+	}
+
+	if len(report.PositionDrift) != 2 {
+		t.Fatalf("PositionDrift = %+v, want AMD and GME", report.PositionDrift)
+	}
+	if report.PositionDrift[0].Symbol != "AMD" || !report.PositionDrift[0].Difference().Equal(dec(1)) {
+		t.Errorf("unexpected AMD drift %+v", report.PositionDrift[0])
+	}
+	if report.PositionDrift[1].Symbol != "GME" || !report.PositionDrift[1].Difference().Equal(dec(6)) {
+		t.Errorf("expected short vs long GME drift of 6, got %+v", report.PositionDrift[1])
+	}
+
+	// Local: -1000 - 1 + 1000 - 250 - 800 = -1051; broker: -1000 + 800 - 150 - 1.5 = -351.5
+	if !report.CashFlow.Local.Equal(dec(-1051)) || !report.CashFlow.Broker.Equal(dec(-351.5)) {
+		t.Errorf("CashFlow = %+v", report.CashFlow)
+	}
+	if report.Clean() {
+		t.Error("report with discrepancies should not be clean")
+	}
+}
+
+func TestReconciler_Reconcile_Clean(t *testing.T) {
+	repo := &mockRepo{
+		trades: []models.Trade{trade("AAPL", models.TradeSideBuy, 10, 100, 0, "o1")},
+	}
+	broker := &mockBroker{
+		activities: []models.BrokerActivity{fill("o1", "AAPL", models.TradeSideBuy, 10, 100.0004)},
+	}
+
+	report, err := NewReconciler(repo, broker, models.FeeSchedule{}).Reconcile(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if !report.Clean() {
+		t.Errorf("expected clean report, got %d discrepancies: %+v", report.DiscrepancyCount(), report)
+	}
+}
+
+func TestReconciler_Reconcile_BrokerError(t *testing.T) {
+	repo := &mockRepo{}
+	broker := &mockBroker{err: errors.New("unauthorized")}
+
+	if _, err := NewReconciler(repo, broker, models.FeeSchedule{}).Reconcile(context.Background(), time.Now()); err == nil {
+		t.Error("expected error when broker activities fail")
+	}
+	if repo.saved != nil {
+		t.Error("report should not be saved on failure")
+	}
+}
+
+func TestReconciler_ReconcileLastMonthIfDue(t *testing.T) {
+	now := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+	may := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		reports []models.ReconciliationReport
+		want    bool
+	}{
+		{"no reports", nil, true},
+		{"older report", []models.ReconciliationReport{{PeriodStart: may.AddDate(0, -1, 0), PeriodEnd: may, CreatedAt: may}}, true},
+		{"already reconciled", []models.ReconciliationReport{{PeriodStart: may, PeriodEnd: may.AddDate(0, 1, 0), CreatedAt: now}}, false},
+		// A manual run of June doesn't stand in for May
+		{"only a later report", []models.ReconciliationReport{{PeriodStart: may.AddDate(0, 1, 0), PeriodEnd: may.AddDate(0, 2, 0), CreatedAt: now, Partial: true}}, true},
+		{"partial report", []models.ReconciliationReport{{PeriodStart: may, PeriodEnd: may.AddDate(0, 1, 0), CreatedAt: may.AddDate(0, 0, 20), Partial: true}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{reports: tt.reports}
+			r := NewReconciler(repo, &mockBroker{}, models.FeeSchedule{})
+			r.now = func() time.Time { return now }
+
+			if err := r.reconcileLastMonthIfDue(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := repo.saved != nil; got != tt.want {
+				t.Fatalf("reconciled = %v, want %v", got, tt.want)
+			}
+			if !repo.period.Equal(may) {
+				t.Errorf("looked up period %v, want %v", repo.period, may)
+			}
+			if tt.want && (!repo.saved.PeriodStart.Equal(may) || repo.saved.Partial) {
+				t.Errorf("saved %+v, want a complete report for %v", repo.saved, may)
+			}
+		})
+	}
+}
+
+func TestReconciler_SyncFills(t *testing.T) {
+	day := time.Date(2024, 5, 6, 14, 30, 0, 0, time.UTC)
+	pending := func(symbol string, side models.TradeSide, qty, price float64, orderID string) models.Trade {
+		t := models.NewTrade(symbol, side, dec(qty), dec(price))
+		t.AlpacaOrderID = orderID
+		t.CreatedAt = day
+		return *t
+	}
+	withTime := func(a models.BrokerActivity) models.BrokerActivity {
+		a.TransactionTime = day.Add(time.Minute)
+		return a
+	}
+
+	repo := &mockRepo{
+		unfilled: []models.Trade{
+			pending("AAPL", models.TradeSideSell, 10, 100, "o1"),
+			pending("AAPL", models.TradeSideSell, 30, 100, "o2"),
+			pending("MSFT", models.TradeSideBuy, 5, 300, "o3"),
+			pending("TSLA", models.TradeSideBuy, 1, 200, "o4"),  // not filled yet
+			pending("NVDA", models.TradeSideBuy, 10, 100, "o5"), // filled in part
+		},
+	}
+	broker := &mockBroker{
+		activities: []models.BrokerActivity{
+			withTime(fill("o1", "AAPL", models.TradeSideSell, 10, 101)),
+			withTime(fill("o2", "AAPL", models.TradeSideSell, 30, 101)),
+			withTime(fill("o3", "MSFT", models.TradeSideBuy, 5, 299)),
+			withTime(models.BrokerActivity{ID: "f1", Type: models.BrokerActivityFee, Symbol: "AAPL", NetAmount: dec(-0.4)}),
+			withTime(fill("o5", "NVDA", models.TradeSideBuy, 4, 100)),
+			withTime(models.BrokerActivity{ID: "f2", Type: models.BrokerActivityFee, Symbol: "NVDA", NetAmount: dec(-0.05)}),
+		},
+	}
+
+	bus := events.NewBus()
+	var published atomic.Int32
+	bus.Subscribe("fills", func(e events.Event) { published.Add(1) }, events.TradeFilled)
+	events.SetDefault(bus)
+	defer events.SetDefault(nil)
+
+	r := NewReconciler(repo, broker, models.NewFeeSchedule(1, 0, 0))
+	r.now = func() time.Time { return day.Add(time.Hour) }
+
+	count, err := r.SyncFills(context.Background())
+	if err != nil {
+		t.Fatalf("SyncFills failed: %v", err)
+	}
+	if count != 4 || len(repo.filled) != 4 {
+		t.Fatalf("filled %d trades (%d recorded), want 4", count, len(repo.filled))
+	}
+	// Partial fills are recorded first and pay on the shares filled so far
+	partial := repo.filled[0]
+	if partial.Status != models.TradeStatusPartiallyFilled || !partial.FilledQuantity.Equal(dec(4)) || !partial.Commission.Equal(dec(1)) || !partial.Fees.Equal(dec(0.05)) {
+		t.Errorf("partial fill = %+v, want 4 filled with the commission and NVDA broker fee", partial)
+	}
+	repo.filled = repo.filled[1:]
+	bus.Close()
+	if published.Load() != 3 {
+		t.Errorf("published %d TradeFilled events, want one per fill", published.Load())
+	}
+
+	for _, trade := range repo.filled {
+		if trade.Status != models.TradeStatusExecuted || trade.ExecutedAt == nil {
+			t.Errorf("%s: expected executed trade with execution time, got %+v", trade.AlpacaOrderID, trade)
+		}
+		if !trade.Commission.Equal(dec(1)) {
+			t.Errorf("%s: Commission = %v, want scheduled 1", trade.AlpacaOrderID, trade.Commission)
+		}
+	}
+	if !repo.filled[0].Price.Equal(dec(101)) || !repo.filled[0].TotalValue.Equal(dec(1010)) {
+		t.Errorf("expected fill price 101, got %+v", repo.filled[0])
+	}
+	// Broker fees are split by value: 0.4 * 1010/4040 and 0.4 * 3030/4040
+	if !repo.filled[0].Fees.Equal(dec(0.1)) || !repo.filled[1].Fees.Equal(dec(0.3)) {
+		t.Errorf("AAPL fees = %v and %v, want 0.1 and 0.3", repo.filled[0].Fees, repo.filled[1].Fees)
+	}
+	if !repo.filled[2].Fees.IsZero() {
+		t.Errorf("MSFT fees = %v, want 0 without broker fees", repo.filled[2].Fees)
+	}
+}
+
+func TestReconciler_SyncOrders(t *testing.T) {
+	open := func(symbol string, qty float64, orderID string) models.Trade {
+		t := models.NewTrade(symbol, models.TradeSideBuy, dec(qty), dec(100))
+		t.AlpacaOrderID = orderID
+		return *t
+	}
+	avg := dec(99)
+	repo := &mockRepo{
+		unfilled: []models.Trade{
+			open("AAPL", 10, "o1"), // filled in part, still working
+			open("MSFT", 5, "o2"),  // canceled before any fill
+			open("TSLA", 1, "o3"),  // rejected
+			open("NVDA", 4, "o4"),  // canceled after filling 2
+			open("AMD", 3, "o5"),   // still new
+			open("META", 2, "o6"),  // unknown to the broker
+		},
+	}
+	broker := &mockBroker{orders: map[string]*models.BrokerOrder{
+		"o1": {Status: models.BrokerOrderPartiallyFilled, FilledQuantity: dec(4), FilledAvgPrice: &avg},
+		"o2": {Status: models.BrokerOrderCanceled},
+		"o3": {Status: models.BrokerOrderRejected},
+		"o4": {Status: models.BrokerOrderCanceled, FilledQuantity: dec(2), FilledAvgPrice: &avg},
+		"o5": {Status: "new"},
+	}}
+
+	fills := &fillRecorder{changes: make(map[string]decimal.Decimal)}
+	reconciler := NewReconciler(repo, broker, models.NewFeeSchedule(0, 0.5, 0))
+	reconciler.SetFillHandler(fills)
+	count, err := reconciler.SyncOrders(context.Background())
+	if err != nil {
+		t.Fatalf("SyncOrders failed: %v", err)
+	}
+	if count != 4 || len(repo.filled) != 4 {
+		t.Fatalf("updated %d trades (%d recorded), want 4", count, len(repo.filled))
+	}
+
+	want := []models.TradeStatus{
+		models.TradeStatusPartiallyFilled,
+		models.TradeStatusCancelled,
+		models.TradeStatusRejected,
+		models.TradeStatusExecuted,
+	}
+	for i, status := range want {
+		if repo.filled[i].Status != status {
+			t.Errorf("%s: Status = %s, want %s", repo.filled[i].AlpacaOrderID, repo.filled[i].Status, status)
+		}
+	}
+	if !repo.filled[0].TotalValue.Equal(dec(396)) || !repo.filled[0].Commission.Equal(dec(2)) {
+		t.Errorf("partial fill = %+v, want 396 filled with commission on the 4 shares filled", repo.filled[0])
+	}
+	if !repo.filled[1].Commission.IsZero() {
+		t.Errorf("unfilled cancel commission = %v, want none", repo.filled[1].Commission)
+	}
+	executed := repo.filled[3]
+	if !executed.FilledQuantity.Equal(dec(2)) || executed.ExecutedAt == nil || !executed.Commission.Equal(dec(1)) {
+		t.Errorf("executed remainder = %+v, want 2 filled with commission on the filled shares", executed)
+	}
+
+	// The shares booked when each order was placed come off as far as they didn't fill
+	wantChanges := map[string]float64{"o1": -6, "o2": -5, "o3": -1, "o4": -2}
+	if len(fills.changes) != len(wantChanges) {
+		t.Errorf("fill handler told of %v, want %v", fills.changes, wantChanges)
+	}
+	for orderID, change := range wantChanges {
+		if got := fills.changes[orderID]; !got.Equal(dec(change)) {
+			t.Errorf("%s: position change = %s, want %v", orderID, got, change)
+		}
+	}
+}
+
+func TestReconciler_SyncOrders_BracketLegs(t *testing.T) {
+	repo := &mockRepo{
+		positions: []models.Position{
+			{Symbol: "AAPL", StopLossOrderID: "s1", TakeProfitOrderID: "t1"}, // target hit
+			{Symbol: "MSFT", StopLossOrderID: "s2", TakeProfitOrderID: "t2"}, // still working
+			{Symbol: "TSLA", StopLossOrderID: "s3"},                          // stop cancelled by hand
+			{Symbol: "NVDA"},                                                 // no bracket
+		},
+	}
+	price := dec(120)
+	broker := &mockBroker{orders: map[string]*models.BrokerOrder{
+		"s1": {ID: "s1", Status: models.BrokerOrderCanceled},
+		"t1": {ID: "t1", Status: models.BrokerOrderFilled, FilledQuantity: dec(10), FilledAvgPrice: &price},
+		"s2": {ID: "s2", Status: "held"},
+		"t2": {ID: "t2", Status: "new"},
+		"s3": {ID: "s3", Status: models.BrokerOrderCanceled},
+	}}
+
+	// Without a fill handler nothing is polled
+	r := NewReconciler(repo, broker, models.FeeSchedule{})
+	if count, err := r.SyncOrders(context.Background()); err != nil || count != 0 {
+		t.Fatalf("SyncOrders() = %d, %v without a fill handler, want nothing", count, err)
+	}
+
+	fills := &fillRecorder{changes: make(map[string]decimal.Decimal)}
+	r.SetFillHandler(fills)
+	count, err := r.SyncOrders(context.Background())
+	if err != nil {
+		t.Fatalf("SyncOrders failed: %v", err)
+	}
+	want := []string{"AAPL s1", "AAPL t1", "TSLA s3"}
+	if count != len(want) || strings.Join(fills.legs, ",") != strings.Join(want, ",") {
+		t.Errorf("closed legs = %v (%d), want %v", fills.legs, count, want)
+	}
+}
+
+func TestReconciler_SyncOrders_OtherBrokers(t *testing.T) {
+	placed := func(broker, orderID string) models.Trade {
+		t := models.NewTrade("AAPL", models.TradeSideBuy, dec(1), dec(100))
+		t.AlpacaOrderID = orderID
+		t.Broker = broker
+		return *t
+	}
+	repo := &mockRepo{
+		unfilled: []models.Trade{
+			placed("", "a1"),    // recorded before trades had a broker
+			placed("ibkr", "1"), // both brokers can reuse an order ID
+			placed("other", "a1"),
+		},
+	}
+	alpaca := &mockBroker{orders: map[string]*models.BrokerOrder{"a1": {Status: models.BrokerOrderCanceled}}}
+	ibkr := &mockBroker{orders: map[string]*models.BrokerOrder{"1": {Status: models.BrokerOrderRejected}}}
+
+	r := NewReconciler(repo, alpaca, models.FeeSchedule{})
+	r.AddOrderSource(models.BrokerIBKR, ibkr)
+	count, err := r.SyncOrders(context.Background())
+	if err != nil {
+		t.Fatalf("SyncOrders failed: %v", err)
+	}
+	if count != 2 || len(repo.filled) != 2 {
+		t.Fatalf("updated %d trades, want the Alpaca and IBKR trades and not the unregistered broker's", count)
+	}
+	if repo.filled[0].Status != models.TradeStatusCancelled || repo.filled[1].Status != models.TradeStatusRejected {
+		t.Errorf("statuses = %s, %s; want each order looked up with its own broker", repo.filled[0].Status, repo.filled[1].Status)
+	}
+}
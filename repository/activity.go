@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/google/uuid"
+)
+
+// GetActivity returns events from trades, recommendation events, screener runs, provider
+// alerts, and the snapshots' deposits and withdrawals merged into a single feed, newest
+// first with ties broken by ID. Only events after the cursor in that order are returned,
+// so callers page by passing the Cursor of the last event they received; nil starts at
+// the newest event.
+func (r *Repository) GetActivity(ctx context.Context, after *models.ActivityCursor, limit int) ([]models.ActivityEvent, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	// Every event sorts after the latest time and the largest ID
+	cursor := models.ActivityCursor{OccurredAt: time.Now().Add(time.Hour), ID: uuid.Max}
+	if after != nil {
+		cursor = *after
+	}
+
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "activity")
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, type, symbol, detail, occurred_at FROM (
+			SELECT id, 'trade_filled' AS type, symbol,
+				format('%s %s @ %s', side, quantity, price) AS detail,
+				executed_at::timestamptz AS occurred_at
+			FROM trades
+			WHERE status = 'executed' AND executed_at IS NOT NULL
+			UNION ALL
+			SELECT r.id, 'recommendation_' || e.event_type, r.symbol,
+				CASE WHEN e.event_type = 'created'
+					THEN format('%s (%s%% confidence)', r.action, COALESCE(r.confidence, 0))
+					ELSE r.action END,
+				e.occurred_at
+			FROM recommendation_events e
+			JOIN recommendations r ON r.id = e.recommendation_id
+			WHERE e.event_type IN ('created', 'approved', 'rejected')
+			UNION ALL
+			SELECT id, 'screener_run', '',
+				format('%s, %s candidates, %s top picks', status, jsonb_array_length(candidates), COALESCE(cardinality(top_picks), 0)),
+				run_at
+			FROM screener_runs
+			UNION ALL
+			SELECT id, 'provider_alert', '',
+				format('%s %s: %s', provider, replace(kind, '_', ' '), error_sample),
+				created_at
+			FROM provider_alerts
+			UNION ALL
+			SELECT md5('portfolio_snapshot:' || date::text)::uuid,
+				CASE WHEN cash_flow > 0 THEN 'deposit' ELSE 'withdrawal' END, '',
+				format('$%s net since the previous snapshot', to_char(abs(cash_flow), 'FM999,999,999,990.00')),
+				created_at
+			FROM portfolio_snapshots
+			WHERE cash_flow <> 0
+		) activity
+		WHERE (occurred_at, id) < ($1, $2)
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT $3
+	`, cursor.OccurredAt, cursor.ID, limit)
+	if err != nil {
+		metrics.RecordDBError("select", "activity")
+		return nil, fmt.Errorf("failed to query activity: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.ActivityEvent
+	for rows.Next() {
+		var e models.ActivityEvent
+		if err := rows.Scan(&e.ID, &e.Type, &e.Symbol, &e.Detail, &e.OccurredAt); err != nil {
+			metrics.RecordDBError("select", "activity")
+			return nil, fmt.Errorf("failed to scan activity event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"trade-machine/models"
+	"trade-machine/observability"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -19,9 +20,9 @@ func (r *Repository) CreateAgentRun(ctx context.Context, run *models.AgentRun) e
 	inputData, _ := json.Marshal(run.InputData)
 
 	_, err := r.db.Exec(ctx, `
-		INSERT INTO agent_runs (id, agent_type, symbol, status, input_data, started_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`, run.ID, run.AgentType, run.Symbol, run.Status, inputData, run.StartedAt)
+		INSERT INTO agent_runs (id, agent_type, symbol, status, input_data, started_at, job_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, run.ID, run.AgentType, run.Symbol, run.Status, inputData, run.StartedAt, run.JobID)
 
 	if err != nil {
 		return fmt.Errorf("failed to create agent run: %w", err)
@@ -35,13 +36,14 @@ func (r *Repository) UpdateAgentRun(ctx context.Context, run *models.AgentRun) e
 	if err := r.checkDB(); err != nil {
 		return err
 	}
+	inputData, _ := json.Marshal(run.InputData)
 	outputData, _ := json.Marshal(run.OutputData)
 
 	_, err := r.db.Exec(ctx, `
 		UPDATE agent_runs 
-		SET status = $2, output_data = $3, error_message = $4, duration_ms = $5, completed_at = $6
+		SET status = $2, input_data = $3, output_data = $4, error_message = $5, duration_ms = $6, completed_at = $7
 		WHERE id = $1
-	`, run.ID, run.Status, outputData, run.ErrorMessage, run.DurationMs, run.CompletedAt)
+	`, run.ID, run.Status, inputData, outputData, run.ErrorMessage, run.DurationMs, run.CompletedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to update agent run: %w", err)
@@ -204,3 +206,58 @@ func (r *Repository) GetRecentRunsForSymbol(ctx context.Context, symbol string,
 
 	return runs, nil
 }
+
+// GetAgentRunsByJob returns the agent runs made for an analysis job, in the order they started
+func (r *Repository) GetAgentRunsByJob(ctx context.Context, jobID uuid.UUID) ([]models.AgentRun, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "agent_runs")
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, agent_type, symbol, status, input_data, output_data, error_message, duration_ms, started_at, completed_at
+		FROM agent_runs
+		WHERE job_id = $1
+		ORDER BY started_at
+	`, jobID)
+	if err != nil {
+		metrics.RecordDBError("select", "agent_runs")
+		return nil, fmt.Errorf("failed to query job agent runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []models.AgentRun{}
+	for rows.Next() {
+		var run models.AgentRun
+		var inputData, outputData []byte
+		var errorMessage *string
+		var durationMs *int
+
+		err := rows.Scan(&run.ID, &run.AgentType, &run.Symbol, &run.Status, &inputData, &outputData, &errorMessage, &durationMs, &run.StartedAt, &run.CompletedAt)
+		if err != nil {
+			metrics.RecordDBError("select", "agent_runs")
+			return nil, fmt.Errorf("failed to scan agent run: %w", err)
+		}
+
+		if errorMessage != nil {
+			run.ErrorMessage = *errorMessage
+		}
+		if durationMs != nil {
+			run.DurationMs = *durationMs
+		}
+		if inputData != nil {
+			json.Unmarshal(inputData, &run.InputData)
+		}
+		if outputData != nil {
+			json.Unmarshal(outputData, &run.OutputData)
+		}
+		id := jobID
+		run.JobID = &id
+
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
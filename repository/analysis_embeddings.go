@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveAnalysisEmbedding stores the embedding of a recommendation, replacing any earlier one
+func (r *Repository) SaveAnalysisEmbedding(ctx context.Context, embedding *models.AnalysisEmbedding) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "analysis_embeddings")
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO analysis_embeddings (recommendation_id, symbol, model, embedding, created_at)
+		VALUES ($1, $2, $3, $4::vector, $5)
+		ON CONFLICT (recommendation_id) DO UPDATE
+		SET model = EXCLUDED.model, embedding = EXCLUDED.embedding, created_at = EXCLUDED.created_at
+	`, embedding.RecommendationID, embedding.Symbol, embedding.Model, formatVector(embedding.Vector), embedding.CreatedAt)
+	if err != nil {
+		metrics.RecordDBError("insert", "analysis_embeddings")
+		return fmt.Errorf("failed to save analysis embedding: %w", err)
+	}
+
+	return nil
+}
+
+// MarkAnalysisEmbeddingFailed records that a recommendation could not be embedded with
+// model, so it is no longer returned as pending for that model
+func (r *Repository) MarkAnalysisEmbeddingFailed(ctx context.Context, recommendationID uuid.UUID, model, reason string) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "analysis_embedding_failures")
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO analysis_embedding_failures (recommendation_id, model, error, failed_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (recommendation_id, model) DO UPDATE
+		SET error = EXCLUDED.error, failed_at = EXCLUDED.failed_at
+	`, recommendationID, model, reason)
+	if err != nil {
+		metrics.RecordDBError("insert", "analysis_embedding_failures")
+		return fmt.Errorf("failed to mark analysis embedding failed: %w", err)
+	}
+
+	return nil
+}
+
+// GetRecommendationsWithoutEmbedding returns the oldest recommendations whose reasoning has
+// not been embedded with model yet. Partial recommendations are skipped until their
+// reasoning is final, and ones that failed to embed with model are skipped for good.
+func (r *Repository) GetRecommendationsWithoutEmbedding(ctx context.Context, model string, limit int) ([]models.Recommendation, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "recommendations")
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+recommendationColumns+`
+		FROM recommendations rec
+		WHERE NOT rec.partial
+		  AND NOT EXISTS (
+			SELECT 1 FROM analysis_embeddings e
+			WHERE e.recommendation_id = rec.id AND e.model = $1
+		  )
+		  AND NOT EXISTS (
+			SELECT 1 FROM analysis_embedding_failures f
+			WHERE f.recommendation_id = rec.id AND f.model = $1
+		  )
+		ORDER BY rec.created_at
+		LIMIT $2
+	`, model, limit)
+	if err != nil {
+		metrics.RecordDBError("select", "recommendations")
+		return nil, fmt.Errorf("failed to query unembedded recommendations: %w", err)
+	}
+	defer rows.Close()
+
+	var recs []models.Recommendation
+	for rows.Next() {
+		rec, err := scanRecommendation(rows)
+		if err != nil {
+			metrics.RecordDBError("select", "recommendations")
+			return nil, fmt.Errorf("failed to scan recommendation: %w", err)
+		}
+		recs = append(recs, *rec)
+	}
+
+	return recs, rows.Err()
+}
+
+// GetLatestAnalysisEmbedding returns the embedding of the most recent embedded
+// recommendation for a symbol, or nil if there is none for model
+func (r *Repository) GetLatestAnalysisEmbedding(ctx context.Context, symbol, model string) (*models.AnalysisEmbedding, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "analysis_embeddings")
+
+	var embedding models.AnalysisEmbedding
+	var vector string
+	err := r.db.QueryRow(ctx, `
+		SELECT recommendation_id, symbol, model, embedding::text, created_at
+		FROM analysis_embeddings
+		WHERE symbol = $1 AND model = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, symbol, model).Scan(&embedding.RecommendationID, &embedding.Symbol, &embedding.Model, &vector, &embedding.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		metrics.RecordDBError("select", "analysis_embeddings")
+		return nil, fmt.Errorf("failed to get analysis embedding: %w", err)
+	}
+
+	if embedding.Vector, err = parseVector(vector); err != nil {
+		return nil, fmt.Errorf("failed to parse analysis embedding: %w", err)
+	}
+	return &embedding, nil
+}
+
+// FindSimilarAnalyses returns the recommendations whose embeddings are closest to vector,
+// most similar first, leaving out the recommendation the vector came from
+func (r *Repository) FindSimilarAnalyses(ctx context.Context, vector []float32, model string, excludeID uuid.UUID, limit int) ([]models.SimilarAnalysis, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "analysis_embeddings")
+
+	if limit <= 0 {
+		limit = 10
+	}
+
+	// Rank in a subquery so the ORDER BY matches the HNSW index's distance operator
+	rows, err := r.db.Query(ctx, `
+		SELECT `+recommendationColumns+`, 1 - nearest.distance
+		FROM (
+			SELECT recommendation_id, embedding <=> $1::vector AS distance
+			FROM analysis_embeddings
+			WHERE model = $2 AND recommendation_id <> $3
+			ORDER BY embedding <=> $1::vector
+			LIMIT $4
+		) nearest
+		JOIN recommendations ON recommendations.id = nearest.recommendation_id
+		ORDER BY nearest.distance
+	`, formatVector(vector), model, excludeID, limit)
+	if err != nil {
+		metrics.RecordDBError("select", "analysis_embeddings")
+		return nil, fmt.Errorf("failed to query similar analyses: %w", err)
+	}
+	defer rows.Close()
+
+	var similar []models.SimilarAnalysis
+	for rows.Next() {
+		var similarity float64
+		rec, err := scanRecommendation(similarityRow{Row: rows, similarity: &similarity})
+		if err != nil {
+			metrics.RecordDBError("select", "analysis_embeddings")
+			return nil, fmt.Errorf("failed to scan similar analysis: %w", err)
+		}
+		similar = append(similar, models.SimilarAnalysis{Recommendation: *rec, Similarity: similarity})
+	}
+
+	return similar, rows.Err()
+}
+
+// similarityRow scans the similarity column that follows the recommendation columns
+type similarityRow struct {
+	pgx.Row
+	similarity *float64
+}
+
+func (r similarityRow) Scan(dest ...any) error {
+	return r.Row.Scan(append(dest, r.similarity)...)
+}
+
+// formatVector encodes a vector in pgvector's text format, e.g. [0.1,0.2]
+func formatVector(v []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// parseVector decodes pgvector's text format
+func parseVector(s string) ([]float32, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '[' || s[len(s)-1] != ']' {
+		return nil, fmt.Errorf("invalid vector %q", s)
+	}
+	s = s[1 : len(s)-1]
+	if s == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(s, ",")
+	v := make([]float32, len(parts))
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector element %q: %w", p, err)
+		}
+		v[i] = float32(f)
+	}
+	return v, nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateAnalysisJob stores a newly started analysis job
+func (r *Repository) CreateAnalysisJob(ctx context.Context, job *models.AnalysisJob) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "analysis_jobs")
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO analysis_jobs (id, symbol, status, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, job.ID, job.Symbol, job.Status, job.CreatedAt)
+	if err != nil {
+		metrics.RecordDBError("insert", "analysis_jobs")
+		return fmt.Errorf("failed to create analysis job: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateAnalysisJob records the outcome of an analysis job
+func (r *Repository) UpdateAnalysisJob(ctx context.Context, job *models.AnalysisJob) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("update", "analysis_jobs")
+
+	_, err := r.db.Exec(ctx, `
+		UPDATE analysis_jobs
+		SET status = $2, recommendation_id = $3, error_message = NULLIF($4, ''), completed_at = $5
+		WHERE id = $1
+	`, job.ID, job.Status, job.RecommendationID, job.ErrorMessage, job.CompletedAt)
+	if err != nil {
+		metrics.RecordDBError("update", "analysis_jobs")
+		return fmt.Errorf("failed to update analysis job: %w", err)
+	}
+
+	return nil
+}
+
+// FailInterruptedAnalysisJobs marks every job still running as failed, returning how many
+// were. It's meant for startup, when no job of the current process has begun, so a running
+// job is one a restart cut short and would otherwise be polled forever.
+func (r *Repository) FailInterruptedAnalysisJobs(ctx context.Context) (int64, error) {
+	if err := r.checkDB(); err != nil {
+		return 0, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("update", "analysis_jobs")
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE analysis_jobs
+		SET status = $1, error_message = 'interrupted: the app restarted before the analysis finished', completed_at = NOW()
+		WHERE status = $2
+	`, models.AnalysisJobStatusFailed, models.AnalysisJobStatusRunning)
+	if err != nil {
+		metrics.RecordDBError("update", "analysis_jobs")
+		return 0, fmt.Errorf("failed to fail interrupted analysis jobs: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// GetAnalysisJob returns an analysis job without its agent runs, or nil if not found
+func (r *Repository) GetAnalysisJob(ctx context.Context, id uuid.UUID) (*models.AnalysisJob, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "analysis_jobs")
+
+	var job models.AnalysisJob
+	var errorMessage *string
+	err := r.db.QueryRow(ctx, `
+		SELECT id, symbol, status, recommendation_id, error_message, created_at, completed_at
+		FROM analysis_jobs WHERE id = $1
+	`, id).Scan(&job.ID, &job.Symbol, &job.Status, &job.RecommendationID, &errorMessage, &job.CreatedAt, &job.CompletedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		metrics.RecordDBError("select", "analysis_jobs")
+		return nil, fmt.Errorf("failed to get analysis job: %w", err)
+	}
+
+	if errorMessage != nil {
+		job.ErrorMessage = *errorMessage
+	}
+	job.Agents = []models.AgentRun{}
+	return &job, nil
+}
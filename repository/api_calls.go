@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// SaveAPICalls appends calls to the ledger and adds them to the daily usage totals in a
+// single statement
+func (r *Repository) SaveAPICalls(ctx context.Context, calls []models.APICall) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	if len(calls) == 0 {
+		return nil
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "api_calls")
+
+	providers := make([]string, len(calls))
+	endpoints := make([]string, len(calls))
+	statuses := make([]int32, len(calls))
+	latencies := make([]int64, len(calls))
+	sizes := make([]int64, len(calls))
+	cached := make([]bool, len(calls))
+	occurred := make([]time.Time, len(calls))
+	for i, c := range calls {
+		providers[i], endpoints[i], statuses[i] = c.Provider, c.Endpoint, int32(c.Status)
+		latencies[i], sizes[i], cached[i], occurred[i] = c.LatencyMs, c.Bytes, c.Cached, c.OccurredAt
+	}
+
+	_, err := r.db.Exec(ctx, `
+		WITH calls AS (
+			SELECT * FROM unnest($1::text[], $2::text[], $3::int[], $4::bigint[], $5::bigint[], $6::bool[], $7::timestamptz[])
+				AS c(provider, endpoint, status, latency_ms, bytes, cached, occurred_at)
+		), inserted AS (
+			INSERT INTO api_calls (provider, endpoint, status, latency_ms, bytes, cached, occurred_at)
+			SELECT provider, endpoint, status, latency_ms, bytes, cached, occurred_at FROM calls
+		)
+		INSERT INTO api_usage_daily (day, provider, endpoint, calls, errors, cached_calls, bytes, total_latency_ms)
+		SELECT (occurred_at AT TIME ZONE 'UTC')::date, provider, endpoint, COUNT(*),
+			COUNT(*) FILTER (WHERE status = 0 OR status >= 400), COUNT(*) FILTER (WHERE cached),
+			SUM(bytes), SUM(latency_ms)
+		FROM calls
+		GROUP BY 1, 2, 3
+		ON CONFLICT (day, provider, endpoint) DO UPDATE SET
+			calls = api_usage_daily.calls + EXCLUDED.calls,
+			errors = api_usage_daily.errors + EXCLUDED.errors,
+			cached_calls = api_usage_daily.cached_calls + EXCLUDED.cached_calls,
+			bytes = api_usage_daily.bytes + EXCLUDED.bytes,
+			total_latency_ms = api_usage_daily.total_latency_ms + EXCLUDED.total_latency_ms
+	`, providers, endpoints, statuses, latencies, sizes, cached, occurred)
+	if err != nil {
+		metrics.RecordDBError("insert", "api_calls")
+		return fmt.Errorf("failed to save API calls: %w", err)
+	}
+
+	return nil
+}
+
+// PruneAPICalls deletes ledger entries older than before, returning how many were removed.
+// Daily usage totals are kept.
+func (r *Repository) PruneAPICalls(ctx context.Context, before time.Time) (int64, error) {
+	if err := r.checkDB(); err != nil {
+		return 0, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("delete", "api_calls")
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM api_calls WHERE occurred_at < $1`, before)
+	if err != nil {
+		metrics.RecordDBError("delete", "api_calls")
+		return 0, fmt.Errorf("failed to prune API calls: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// GetAPIUsage returns daily usage per provider endpoint from since onwards, newest day first
+func (r *Repository) GetAPIUsage(ctx context.Context, since time.Time) ([]models.APIUsage, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "api_usage_daily")
+
+	rows, err := r.db.Query(ctx, `
+		SELECT day, provider, endpoint, calls, errors, cached_calls, bytes, total_latency_ms
+		FROM api_usage_daily
+		WHERE day >= $1::date
+		ORDER BY day DESC, calls DESC, provider, endpoint
+	`, since)
+	if err != nil {
+		metrics.RecordDBError("select", "api_usage_daily")
+		return nil, fmt.Errorf("failed to get API usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []models.APIUsage
+	for rows.Next() {
+		var u models.APIUsage
+		if err := rows.Scan(&u.Day, &u.Provider, &u.Endpoint, &u.Calls, &u.Errors, &u.CachedCalls, &u.Bytes, &u.TotalLatencyMs); err != nil {
+			metrics.RecordDBError("select", "api_usage_daily")
+			return nil, fmt.Errorf("failed to scan API usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+
+	return usage, nil
+}
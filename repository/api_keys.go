@@ -7,6 +7,7 @@ import (
 	"trade-machine/internal/settings"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 // GetAPIKey retrieves an API key by service name
@@ -140,3 +141,43 @@ func (r *Repository) DeleteAPIKey(ctx context.Context, serviceName string) error
 
 	return nil
 }
+
+// GetAppSetting retrieves the JSON value of a general setting, or nil if it has not been set
+func (r *Repository) GetAppSetting(ctx context.Context, key string) ([]byte, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT value FROM app_settings WHERE key = $1`
+
+	var value []byte
+	err := r.db.QueryRow(ctx, query, key).Scan(&value)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app setting: %w", err)
+	}
+
+	return value, nil
+}
+
+// UpsertAppSetting stores the JSON value of a general setting
+func (r *Repository) UpsertAppSetting(ctx context.Context, key string, value []byte) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO app_settings (key, value, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (key)
+		DO UPDATE SET value = EXCLUDED.value, updated_at = NOW()
+	`
+
+	if _, err := r.db.Exec(ctx, query, key, value); err != nil {
+		return fmt.Errorf("failed to upsert app setting: %w", err)
+	}
+
+	return nil
+}
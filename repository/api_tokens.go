@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// apiTokenColumns are the api_tokens columns, in the order scanAPIToken reads them
+const apiTokenColumns = `id, name, scopes, hint, created_at, last_used_at`
+
+func scanAPIToken(row pgx.Row) (*models.APIToken, error) {
+	var t models.APIToken
+	var scopes []string
+	if err := row.Scan(&t.ID, &t.Name, &scopes, &t.Hint, &t.CreatedAt, &t.LastUsedAt); err != nil {
+		return nil, err
+	}
+	t.Scopes = make([]models.APIScope, len(scopes))
+	for i, s := range scopes {
+		t.Scopes[i] = models.APIScope(s)
+	}
+	return &t, nil
+}
+
+// CreateAPIToken saves a new API token under the hash of its secret
+func (r *Repository) CreateAPIToken(ctx context.Context, token *models.APIToken, tokenHash string) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "api_tokens")
+
+	scopes := make([]string, len(token.Scopes))
+	for i, s := range token.Scopes {
+		scopes[i] = string(s)
+	}
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO api_tokens (id, name, token_hash, scopes, hint, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, token.ID, token.Name, tokenHash, scopes, token.Hint, token.CreatedAt)
+	if err != nil {
+		metrics.RecordDBError("insert", "api_tokens")
+		return fmt.Errorf("failed to create API token: %w", err)
+	}
+
+	return nil
+}
+
+// GetAPITokenByHash returns the API token whose secret has the given hash, or nil if there is none
+func (r *Repository) GetAPITokenByHash(ctx context.Context, tokenHash string) (*models.APIToken, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "api_tokens")
+
+	t, err := scanAPIToken(r.db.QueryRow(ctx, `SELECT `+apiTokenColumns+` FROM api_tokens WHERE token_hash = $1`, tokenHash))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		metrics.RecordDBError("select", "api_tokens")
+		return nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+
+	return t, nil
+}
+
+// GetAPITokens returns every API token, newest first
+func (r *Repository) GetAPITokens(ctx context.Context) ([]models.APIToken, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "api_tokens")
+
+	rows, err := r.db.Query(ctx, `SELECT `+apiTokenColumns+` FROM api_tokens ORDER BY created_at DESC`)
+	if err != nil {
+		metrics.RecordDBError("select", "api_tokens")
+		return nil, fmt.Errorf("failed to get API tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []models.APIToken{}
+	for rows.Next() {
+		t, err := scanAPIToken(rows)
+		if err != nil {
+			metrics.RecordDBError("select", "api_tokens")
+			return nil, fmt.Errorf("failed to scan API token: %w", err)
+		}
+		tokens = append(tokens, *t)
+	}
+
+	return tokens, nil
+}
+
+// TouchAPIToken records when an API token was last used
+func (r *Repository) TouchAPIToken(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("update", "api_tokens")
+
+	if _, err := r.db.Exec(ctx, `UPDATE api_tokens SET last_used_at = $2 WHERE id = $1`, id, usedAt); err != nil {
+		metrics.RecordDBError("update", "api_tokens")
+		return fmt.Errorf("failed to update API token: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAPIToken revokes an API token, reporting whether it existed
+func (r *Repository) DeleteAPIToken(ctx context.Context, id uuid.UUID) (bool, error) {
+	if err := r.checkDB(); err != nil {
+		return false, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("delete", "api_tokens")
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM api_tokens WHERE id = $1`, id)
+	if err != nil {
+		metrics.RecordDBError("delete", "api_tokens")
+		return false, fmt.Errorf("failed to delete API token: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// CreateAuditEntry records a state-changing action in the audit log
+func (r *Repository) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "audit_log")
+
+	var details []byte
+	if len(entry.Details) > 0 {
+		details, _ = json.Marshal(entry.Details)
+	}
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO audit_log (id, action, actor, target, details, request_id, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, entry.ID, entry.Action, entry.Actor, entry.Target, details, entry.RequestID, entry.OccurredAt)
+	if err != nil {
+		metrics.RecordDBError("insert", "audit_log")
+		return fmt.Errorf("failed to create audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuditLog returns the most recent audit entries, newest first
+func (r *Repository) GetAuditLog(ctx context.Context, limit int) ([]models.AuditEntry, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "audit_log")
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, action, actor, target, details, request_id, occurred_at
+		FROM audit_log
+		ORDER BY occurred_at DESC, id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		metrics.RecordDBError("select", "audit_log")
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		var details []byte
+		if err := rows.Scan(&e.ID, &e.Action, &e.Actor, &e.Target, &details, &e.RequestID, &e.OccurredAt); err != nil {
+			metrics.RecordDBError("select", "audit_log")
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		if len(details) > 0 {
+			if err := json.Unmarshal(details, &e.Details); err != nil {
+				return nil, fmt.Errorf("failed to decode audit entry details: %w", err)
+			}
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
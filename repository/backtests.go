@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveBacktestRun stores a finished backtest
+func (r *Repository) SaveBacktestRun(ctx context.Context, run *models.BacktestRun) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "backtest_runs")
+
+	runJSON, err := json.Marshal(run)
+	if err != nil {
+		metrics.RecordDBError("insert", "backtest_runs")
+		return fmt.Errorf("failed to marshal backtest run: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO backtest_runs (id, strategy, symbols, start_date, end_date, run, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, run.ID, run.Strategy, run.Request.Symbols, run.Request.Start, run.Request.End, runJSON, run.CreatedAt)
+	if err != nil {
+		metrics.RecordDBError("insert", "backtest_runs")
+		return fmt.Errorf("failed to save backtest run: %w", err)
+	}
+
+	return nil
+}
+
+// GetBacktestRun returns a single backtest by ID, or nil if it does not exist
+func (r *Repository) GetBacktestRun(ctx context.Context, id uuid.UUID) (*models.BacktestRun, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "backtest_runs")
+
+	var runJSON []byte
+	err := r.db.QueryRow(ctx, `
+		SELECT run FROM backtest_runs WHERE id = $1
+	`, id).Scan(&runJSON)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		metrics.RecordDBError("select", "backtest_runs")
+		return nil, fmt.Errorf("failed to get backtest run: %w", err)
+	}
+
+	var run models.BacktestRun
+	if err := json.Unmarshal(runJSON, &run); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal backtest run: %w", err)
+	}
+	return &run, nil
+}
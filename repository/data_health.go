@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// dataHealthSampleSize caps how many affected row IDs an issue lists
+const dataHealthSampleSize = 5
+
+// dataHealthCheck is an integrity check counting the rows of table matching where.
+// Checks with stale set compare against the stale-run cutoff, passed as $1.
+type dataHealthCheck struct {
+	check       string
+	description string
+	table       string
+	where       string
+	stale       bool
+}
+
+var dataHealthChecks = []dataHealthCheck{
+	{
+		check:       models.DataCheckExecutedWithoutTrade,
+		description: "Executed recommendations whose trade is missing",
+		table:       "recommendations",
+		where: `status = 'executed' AND (executed_trade_id IS NULL OR NOT EXISTS (
+			SELECT 1 FROM trades t WHERE t.id = recommendations.executed_trade_id))`,
+	},
+	{
+		check:       models.DataCheckZeroQuantity,
+		description: "Positions with no shares",
+		table:       "positions",
+		where:       `quantity <= 0`,
+	},
+	{
+		check:       models.DataCheckStaleAgentRuns,
+		description: "Agent runs still marked running long after they started",
+		table:       "agent_runs",
+		where:       `status = 'running' AND started_at < $1`,
+		stale:       true,
+	},
+	{
+		check:       models.DataCheckExpiredCache,
+		description: "Market data cache entries past their expiry",
+		table:       "market_data_cache",
+		where:       `expires_at < clock_timestamp()`,
+	},
+}
+
+// GetDataHealth runs the integrity checks and collects table sizes and cache statistics.
+// Agent runs started before staleBefore and still running count as stale.
+func (r *Repository) GetDataHealth(ctx context.Context, staleBefore time.Time) (*models.DataHealthReport, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "data_health")
+
+	report := &models.DataHealthReport{CheckedAt: time.Now()}
+	for _, c := range dataHealthChecks {
+		query := fmt.Sprintf(`
+			SELECT COUNT(*), COALESCE((array_agg(id::text ORDER BY id))[1:%d], '{}')
+			FROM %s WHERE %s
+		`, dataHealthSampleSize, c.table, c.where)
+		var args []interface{}
+		if c.stale {
+			args = append(args, staleBefore)
+		}
+
+		issue := models.DataHealthIssue{Check: c.check, Description: c.description}
+		if err := r.db.QueryRow(ctx, query, args...).Scan(&issue.Count, &issue.SampleIDs); err != nil {
+			metrics.RecordDBError("select", "data_health")
+			return nil, fmt.Errorf("failed to run %s check: %w", c.check, err)
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT relname, n_live_tup, n_dead_tup, pg_total_relation_size(relid)
+		FROM pg_stat_user_tables
+		ORDER BY pg_total_relation_size(relid) DESC, relname
+	`)
+	if err != nil {
+		metrics.RecordDBError("select", "data_health")
+		return nil, fmt.Errorf("failed to query table stats: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var t models.TableStats
+		if err := rows.Scan(&t.Name, &t.Rows, &t.DeadRows, &t.TotalBytes); err != nil {
+			return nil, fmt.Errorf("failed to scan table stats: %w", err)
+		}
+		report.Tables = append(report.Tables, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read table stats: %w", err)
+	}
+
+	if err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE expires_at < clock_timestamp()),
+			pg_total_relation_size('market_data_cache')
+		FROM market_data_cache
+	`).Scan(&report.Cache.Entries, &report.Cache.Expired, &report.Cache.TotalBytes); err != nil {
+		metrics.RecordDBError("select", "data_health")
+		return nil, fmt.Errorf("failed to query cache stats: %w", err)
+	}
+
+	typeRows, err := r.db.Query(ctx, `SELECT data_type, COUNT(*) FROM market_data_cache GROUP BY data_type`)
+	if err != nil {
+		metrics.RecordDBError("select", "data_health")
+		return nil, fmt.Errorf("failed to query cache stats: %w", err)
+	}
+	defer typeRows.Close()
+	report.Cache.ByType = make(map[string]int64)
+	for typeRows.Next() {
+		var dataType string
+		var count int64
+		if err := typeRows.Scan(&dataType, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan cache stats: %w", err)
+		}
+		report.Cache.ByType[dataType] = count
+	}
+	if err := typeRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cache stats: %w", err)
+	}
+
+	report.Finish()
+	return report, nil
+}
+
+// FixDataHealth repairs the issues that are safe to fix automatically: positions with no
+// shares and expired cache entries are deleted, and agent runs started before staleBefore
+// and still running are marked failed. It returns the rows repaired per check; fixes made
+// before an error are kept.
+func (r *Repository) FixDataHealth(ctx context.Context, staleBefore time.Time) (map[string]int64, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("update", "data_health")
+
+	fixes := []struct {
+		check string
+		query string
+		args  []interface{}
+	}{
+		{models.DataCheckZeroQuantity, `DELETE FROM positions WHERE quantity <= 0`, nil},
+		{models.DataCheckStaleAgentRuns, `
+			UPDATE agent_runs
+			SET status = 'failed', error_message = 'abandoned: still running at data-health check', completed_at = NOW()
+			WHERE status = 'running' AND started_at < $1
+		`, []interface{}{staleBefore}},
+		{models.DataCheckExpiredCache, `DELETE FROM market_data_cache WHERE expires_at < clock_timestamp()`, nil},
+	}
+
+	fixed := make(map[string]int64)
+	for _, f := range fixes {
+		tag, err := r.db.Exec(ctx, f.query, f.args...)
+		if err != nil {
+			metrics.RecordDBError("update", "data_health")
+			return fixed, fmt.Errorf("failed to fix %s: %w", f.check, err)
+		}
+		fixed[f.check] = tag.RowsAffected()
+	}
+	return fixed, nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// GetSchemaVersion returns the latest migration applied to the database
+func (r *Repository) GetSchemaVersion(ctx context.Context) (int64, error) {
+	if err := r.checkDB(); err != nil {
+		return 0, err
+	}
+	var version int64
+	err := r.db.QueryRow(ctx, `
+		SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied
+	`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	return version, nil
+}
+
+// GetFailedAgentRuns returns the most recent failed agent runs, newest first
+func (r *Repository) GetFailedAgentRuns(ctx context.Context, limit int) ([]models.AgentRun, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "agent_runs")
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, agent_type, symbol, status, input_data, output_data, error_message, duration_ms, started_at, completed_at
+		FROM agent_runs
+		WHERE status = $1
+		ORDER BY started_at DESC
+		LIMIT $2
+	`, models.AgentRunStatusFailed, limit)
+	if err != nil {
+		metrics.RecordDBError("select", "agent_runs")
+		return nil, fmt.Errorf("failed to query failed agent runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []models.AgentRun
+	for rows.Next() {
+		var run models.AgentRun
+		var inputData, outputData []byte
+		var errorMessage *string
+		var durationMs *int
+		if err := rows.Scan(&run.ID, &run.AgentType, &run.Symbol, &run.Status, &inputData, &outputData, &errorMessage, &durationMs, &run.StartedAt, &run.CompletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan agent run: %w", err)
+		}
+		if errorMessage != nil {
+			run.ErrorMessage = *errorMessage
+		}
+		if durationMs != nil {
+			run.DurationMs = *durationMs
+		}
+		if inputData != nil {
+			json.Unmarshal(inputData, &run.InputData)
+		}
+		if outputData != nil {
+			json.Unmarshal(outputData, &run.OutputData)
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// ImportAgentRun inserts an agent run from a diagnostic bundle as it was recorded,
+// reporting false when a run with the same ID already exists
+func (r *Repository) ImportAgentRun(ctx context.Context, run *models.AgentRun) (bool, error) {
+	if err := r.checkDB(); err != nil {
+		return false, err
+	}
+	inputData, _ := json.Marshal(run.InputData)
+	outputData, _ := json.Marshal(run.OutputData)
+
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO agent_runs (id, agent_type, symbol, status, input_data, output_data, error_message, duration_ms, started_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (id) DO NOTHING
+	`, run.ID, run.AgentType, run.Symbol, run.Status, inputData, outputData, run.ErrorMessage, run.DurationMs, run.StartedAt, run.CompletedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to import agent run: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ImportProviderAlert inserts a provider alert from a diagnostic bundle, reporting false
+// when an alert with the same ID already exists
+func (r *Repository) ImportProviderAlert(ctx context.Context, alert *models.ProviderAlert) (bool, error) {
+	if err := r.checkDB(); err != nil {
+		return false, err
+	}
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO provider_alerts (id, provider, kind, error_sample, recover_at, created_at, dismissed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO NOTHING
+	`, alert.ID, alert.Provider, alert.Kind, alert.ErrorSample, alert.RecoverAt, alert.CreatedAt, alert.DismissedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to import provider alert: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
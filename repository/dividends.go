@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// SaveDividends records dividends on held positions, updating the amount, payment date and
+// status of ones already tracked. Shares are kept once a dividend has been received, so
+// later trades don't change what it paid.
+func (r *Repository) SaveDividends(ctx context.Context, dividends []models.Dividend) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("upsert", "dividends")
+
+	for i := range dividends {
+		d := &dividends[i]
+		err := r.db.QueryRow(ctx, `
+			INSERT INTO dividends (symbol, ex_date, payment_date, amount_per_share, shares, status)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (symbol, ex_date) DO UPDATE SET
+				payment_date = EXCLUDED.payment_date,
+				amount_per_share = EXCLUDED.amount_per_share,
+				shares = CASE WHEN dividends.status = 'received' THEN dividends.shares ELSE EXCLUDED.shares END,
+				status = EXCLUDED.status,
+				updated_at = NOW()
+			RETURNING id, shares, created_at, updated_at
+		`, d.Symbol, d.ExDate, d.PaymentDate, d.AmountPerShare, d.Shares, d.Status).Scan(&d.ID, &d.Shares, &d.CreatedAt, &d.UpdatedAt)
+		if err != nil {
+			metrics.RecordDBError("upsert", "dividends")
+			return fmt.Errorf("failed to save dividend for %s: %w", d.Symbol, err)
+		}
+	}
+
+	return nil
+}
+
+// GetDividends returns the dividends tracked for the symbols, newest ex-date first
+func (r *Repository) GetDividends(ctx context.Context, symbols []string) ([]models.Dividend, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "dividends")
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, symbol, ex_date, payment_date, amount_per_share, shares, status, created_at, updated_at
+		FROM dividends
+		WHERE symbol = ANY($1::text[])
+		ORDER BY ex_date DESC, symbol
+	`, symbols)
+	if err != nil {
+		metrics.RecordDBError("select", "dividends")
+		return nil, fmt.Errorf("failed to get dividends: %w", err)
+	}
+	defer rows.Close()
+
+	dividends := []models.Dividend{}
+	for rows.Next() {
+		var d models.Dividend
+		if err := rows.Scan(&d.ID, &d.Symbol, &d.ExDate, &d.PaymentDate, &d.AmountPerShare, &d.Shares, &d.Status, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			metrics.RecordDBError("select", "dividends")
+			return nil, fmt.Errorf("failed to scan dividend: %w", err)
+		}
+		dividends = append(dividends, d)
+	}
+
+	return dividends, nil
+}
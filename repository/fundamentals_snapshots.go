@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveFundamentalsSnapshot stores the fundamentals an analysis was based on
+func (r *Repository) SaveFundamentalsSnapshot(ctx context.Context, snapshot *models.FundamentalsSnapshot) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "fundamentals_snapshots")
+
+	fundamentalsJSON, err := json.Marshal(snapshot.Fundamentals)
+	if err != nil {
+		metrics.RecordDBError("insert", "fundamentals_snapshots")
+		return fmt.Errorf("failed to marshal fundamentals: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO fundamentals_snapshots (id, symbol, fundamentals, captured_at)
+		VALUES ($1, $2, $3, $4)
+	`, snapshot.ID, snapshot.Symbol, fundamentalsJSON, snapshot.CapturedAt)
+	if err != nil {
+		metrics.RecordDBError("insert", "fundamentals_snapshots")
+		return fmt.Errorf("failed to save fundamentals snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// GetLatestFundamentalsSnapshot returns the most recent snapshot for a symbol, or nil if
+// it has not been analyzed before
+func (r *Repository) GetLatestFundamentalsSnapshot(ctx context.Context, symbol string) (*models.FundamentalsSnapshot, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "fundamentals_snapshots")
+
+	var snapshot models.FundamentalsSnapshot
+	var fundamentalsJSON []byte
+	err := r.db.QueryRow(ctx, `
+		SELECT id, symbol, fundamentals, captured_at
+		FROM fundamentals_snapshots
+		WHERE symbol = $1
+		ORDER BY captured_at DESC
+		LIMIT 1
+	`, symbol).Scan(&snapshot.ID, &snapshot.Symbol, &fundamentalsJSON, &snapshot.CapturedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		metrics.RecordDBError("select", "fundamentals_snapshots")
+		return nil, fmt.Errorf("failed to get fundamentals snapshot: %w", err)
+	}
+
+	if err := json.Unmarshal(fundamentalsJSON, &snapshot.Fundamentals); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal fundamentals snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
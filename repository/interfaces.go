@@ -8,6 +8,7 @@ import (
 	"trade-machine/models"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // RepositoryInterface defines all repository operations
@@ -15,15 +16,37 @@ type RepositoryInterface interface {
 	// Health and lifecycle
 	Close()
 	Health(ctx context.Context) error
+	UnitOfWork(ctx context.Context, fn func(tx RepositoryInterface) error) error
 
 	// Recommendations
 	GetRecommendations(ctx context.Context, status models.RecommendationStatus, limit int) ([]models.Recommendation, error)
 	GetRecommendation(ctx context.Context, id uuid.UUID) (*models.Recommendation, error)
+	GetRecommendationByTradeID(ctx context.Context, tradeID uuid.UUID) (*models.Recommendation, error)
+	GetLatestRecommendationForSymbol(ctx context.Context, symbol string) (*models.Recommendation, error)
+	GetRecommendationsForSymbol(ctx context.Context, symbol string, limit int) ([]models.Recommendation, error)
 	CreateRecommendation(ctx context.Context, rec *models.Recommendation) error
-	ApproveRecommendation(ctx context.Context, id uuid.UUID) error
-	RejectRecommendation(ctx context.Context, id uuid.UUID) error
-	ExecuteRecommendation(ctx context.Context, id uuid.UUID, tradeID uuid.UUID) error
+	ApproveRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error
+	RejectRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error
+	ClaimRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error
+	ReleaseRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error
+	ExecuteRecommendation(ctx context.Context, id uuid.UUID, tradeID uuid.UUID, expectedVersion int) error
+	FailRecommendation(ctx context.Context, id uuid.UUID, tradeID uuid.UUID) error
 	GetPendingRecommendations(ctx context.Context) ([]models.Recommendation, error)
+	GetExecutedRecommendations(ctx context.Context) ([]models.Recommendation, error)
+	GetRecommendationEvents(ctx context.Context, id uuid.UUID) ([]models.RecommendationEvent, error)
+	UpdateRecommendationOverride(ctx context.Context, id uuid.UUID, override *models.RecommendationOverride, expectedVersion int) error
+	CompleteRecommendation(ctx context.Context, rec *models.Recommendation) error
+	ExpireStaleRecommendations(ctx context.Context, createdBefore time.Time, ids []uuid.UUID) ([]uuid.UUID, error)
+
+	// Recommendation tranches
+	CreateRecommendationTranches(ctx context.Context, tranches []models.RecommendationTranche) error
+	GetRecommendationTranches(ctx context.Context, recID uuid.UUID) ([]models.RecommendationTranche, error)
+	GetPendingTranches(ctx context.Context) ([]models.RecommendationTranche, error)
+	ClaimTranche(ctx context.Context, id uuid.UUID) error
+	ReleaseTranche(ctx context.Context, id uuid.UUID) error
+	MarkTrancheSubmitted(ctx context.Context, id uuid.UUID, tradeID uuid.UUID) error
+	MarkTrancheFailed(ctx context.Context, id uuid.UUID, reason string) error
+	CancelPendingTranches(ctx context.Context, recID uuid.UUID) (int64, error)
 
 	// Positions
 	GetPositions(ctx context.Context) ([]models.Position, error)
@@ -39,6 +62,10 @@ type RepositoryInterface interface {
 	CreateTrade(ctx context.Context, trade *models.Trade) error
 	UpdateTradeStatus(ctx context.Context, id uuid.UUID, status models.TradeStatus) error
 	GetTradesBySymbol(ctx context.Context, symbol string, limit int) ([]models.Trade, error)
+	GetExecutedTradesBetween(ctx context.Context, start, end time.Time) ([]models.Trade, error)
+	GetUnfilledTrades(ctx context.Context) ([]models.Trade, error)
+	RecordTradeFill(ctx context.Context, trade *models.Trade) error
+	GetTotalFees(ctx context.Context) (decimal.Decimal, error)
 
 	// Agent runs
 	CreateAgentRun(ctx context.Context, run *models.AgentRun) error
@@ -46,6 +73,15 @@ type RepositoryInterface interface {
 	GetAgentRun(ctx context.Context, id uuid.UUID) (*models.AgentRun, error)
 	GetAgentRuns(ctx context.Context, agentType models.AgentType, limit int) ([]models.AgentRun, error)
 	GetRecentRunsForSymbol(ctx context.Context, symbol string, limit int) ([]models.AgentRun, error)
+	GetAgentRunsByJob(ctx context.Context, jobID uuid.UUID) ([]models.AgentRun, error)
+	GetFailedAgentRuns(ctx context.Context, limit int) ([]models.AgentRun, error)
+	ImportAgentRun(ctx context.Context, run *models.AgentRun) (bool, error)
+
+	// Analysis jobs
+	CreateAnalysisJob(ctx context.Context, job *models.AnalysisJob) error
+	UpdateAnalysisJob(ctx context.Context, job *models.AnalysisJob) error
+	GetAnalysisJob(ctx context.Context, id uuid.UUID) (*models.AnalysisJob, error)
+	FailInterruptedAnalysisJobs(ctx context.Context) (int64, error)
 
 	// Cache
 	GetCachedData(ctx context.Context, symbol, dataType string) (map[string]interface{}, error)
@@ -54,18 +90,109 @@ type RepositoryInterface interface {
 	InvalidateAllCacheForSymbol(ctx context.Context, symbol string) error
 	CleanExpiredCache(ctx context.Context) (int64, error)
 
+	// Data health
+	GetDataHealth(ctx context.Context, staleBefore time.Time) (*models.DataHealthReport, error)
+	FixDataHealth(ctx context.Context, staleBefore time.Time) (map[string]int64, error)
+
 	// Screener runs
 	CreateScreenerRun(ctx context.Context, run *models.ScreenerRun) error
 	UpdateScreenerRun(ctx context.Context, run *models.ScreenerRun) error
 	GetScreenerRun(ctx context.Context, id uuid.UUID) (*models.ScreenerRun, error)
 	GetLatestScreenerRun(ctx context.Context) (*models.ScreenerRun, error)
 	GetScreenerRunHistory(ctx context.Context, limit int) ([]models.ScreenerRun, error)
+	SaveScreenerUniverse(ctx context.Context, runID uuid.UUID, entries []models.ScreenerUniverseEntry) error
+	GetScreenerUniverse(ctx context.Context, runID uuid.UUID) ([]models.ScreenerUniverseEntry, error)
+
+	// Screener presets
+	GetScreenerPresets(ctx context.Context) ([]models.ScreenerPreset, error)
+	GetScreenerPreset(ctx context.Context, name string) (*models.ScreenerPreset, error)
+	SaveScreenerPreset(ctx context.Context, preset *models.ScreenerPreset) error
+	DeleteScreenerPreset(ctx context.Context, name string) (bool, error)
+
+	// Dividends
+	SaveDividends(ctx context.Context, dividends []models.Dividend) error
+	GetDividends(ctx context.Context, symbols []string) ([]models.Dividend, error)
+
+	// Activity
+	GetActivity(ctx context.Context, after *models.ActivityCursor, limit int) ([]models.ActivityEvent, error)
+
+	// Audit log
+	CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+	GetAuditLog(ctx context.Context, limit int) ([]models.AuditEntry, error)
+
+	// HTTP API tokens
+	CreateAPIToken(ctx context.Context, token *models.APIToken, tokenHash string) error
+	GetAPITokenByHash(ctx context.Context, tokenHash string) (*models.APIToken, error)
+	GetAPITokens(ctx context.Context) ([]models.APIToken, error)
+	TouchAPIToken(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+	DeleteAPIToken(ctx context.Context, id uuid.UUID) (bool, error)
+
+	// Symbol lists
+	GetSymbolListEntries(ctx context.Context) ([]models.SymbolListEntry, error)
+	AddSymbolListEntry(ctx context.Context, entry *models.SymbolListEntry) error
+	RemoveSymbolListEntry(ctx context.Context, list models.SymbolListType, symbol string) error
+
+	// Reconciliation reports
+	SaveReconciliationReport(ctx context.Context, report *models.ReconciliationReport) error
+	GetReconciliationReports(ctx context.Context, limit int) ([]models.ReconciliationReport, error)
+	GetReconciliationReport(ctx context.Context, id uuid.UUID) (*models.ReconciliationReport, error)
+	GetReconciliationReportForPeriod(ctx context.Context, periodStart time.Time) (*models.ReconciliationReport, error)
+
+	// Portfolio reviews
+	SavePortfolioReview(ctx context.Context, review *models.PortfolioReview) error
+	GetPortfolioReviews(ctx context.Context, limit int) ([]models.PortfolioReview, error)
+	GetPortfolioReview(ctx context.Context, id uuid.UUID) (*models.PortfolioReview, error)
+
+	// Backtests
+	SaveBacktestRun(ctx context.Context, run *models.BacktestRun) error
+	GetBacktestRun(ctx context.Context, id uuid.UUID) (*models.BacktestRun, error)
+
+	// Fundamentals snapshots
+	SaveFundamentalsSnapshot(ctx context.Context, snapshot *models.FundamentalsSnapshot) error
+	GetLatestFundamentalsSnapshot(ctx context.Context, symbol string) (*models.FundamentalsSnapshot, error)
+
+	// Analysis embeddings
+	SaveAnalysisEmbedding(ctx context.Context, embedding *models.AnalysisEmbedding) error
+	GetRecommendationsWithoutEmbedding(ctx context.Context, model string, limit int) ([]models.Recommendation, error)
+	MarkAnalysisEmbeddingFailed(ctx context.Context, recommendationID uuid.UUID, model, reason string) error
+	GetLatestAnalysisEmbedding(ctx context.Context, symbol, model string) (*models.AnalysisEmbedding, error)
+	FindSimilarAnalyses(ctx context.Context, vector []float32, model string, excludeID uuid.UUID, limit int) ([]models.SimilarAnalysis, error)
+
+	// Watchlists
+	SaveWatchlist(ctx context.Context, watchlist *models.Watchlist) error
+	GetWatchlists(ctx context.Context) ([]models.Watchlist, error)
+
+	// API call ledger
+	SaveAPICalls(ctx context.Context, calls []models.APICall) error
+	PruneAPICalls(ctx context.Context, before time.Time) (int64, error)
+	GetAPIUsage(ctx context.Context, since time.Time) ([]models.APIUsage, error)
+
+	// LLM usage
+	SaveLLMUsage(ctx context.Context, usage []models.LLMUsage) error
+	GetLLMUsage(ctx context.Context, since time.Time) ([]models.LLMUsageRow, error)
+	GetLLMCostSince(ctx context.Context, since time.Time) (float64, error)
+
+	// Portfolio snapshots
+	SavePortfolioSnapshot(ctx context.Context, snapshot *models.PortfolioSnapshot) error
+	GetPortfolioSnapshots(ctx context.Context, since time.Time) ([]models.PortfolioSnapshot, error)
+	GetLatestPortfolioSnapshot(ctx context.Context) (*models.PortfolioSnapshot, error)
+
+	// Provider alerts
+	SaveProviderAlert(ctx context.Context, alert *models.ProviderAlert) error
+	GetProviderAlerts(ctx context.Context, activeOnly bool, limit int) ([]models.ProviderAlert, error)
+	DismissProviderAlert(ctx context.Context, id uuid.UUID) error
+	ImportProviderAlert(ctx context.Context, alert *models.ProviderAlert) (bool, error)
+
+	// Diagnostics
+	GetSchemaVersion(ctx context.Context) (int64, error)
 
 	// API Keys
 	GetAPIKey(ctx context.Context, serviceName string) (*settings.APIKeyModel, error)
 	GetAllAPIKeys(ctx context.Context) ([]settings.APIKeyModel, error)
 	UpsertAPIKey(ctx context.Context, apiKey *settings.APIKeyModel) error
 	DeleteAPIKey(ctx context.Context, serviceName string) error
+	GetAppSetting(ctx context.Context, key string) ([]byte, error)
+	UpsertAppSetting(ctx context.Context, key string, value []byte) error
 }
 
 // Compile-time interface verification
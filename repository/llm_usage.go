@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/google/uuid"
+)
+
+// SaveLLMUsage records the LLM calls of an agent run in a single statement
+func (r *Repository) SaveLLMUsage(ctx context.Context, usage []models.LLMUsage) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	if len(usage) == 0 {
+		return nil
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "llm_usage")
+
+	ids := make([]uuid.UUID, len(usage))
+	runIDs := make([]*uuid.UUID, len(usage))
+	agentTypes := make([]string, len(usage))
+	symbols := make([]string, len(usage))
+	providers := make([]string, len(usage))
+	llmModels := make([]string, len(usage))
+	inputTokens := make([]int64, len(usage))
+	outputTokens := make([]int64, len(usage))
+	costs := make([]float64, len(usage))
+	created := make([]time.Time, len(usage))
+	for i, u := range usage {
+		ids[i], runIDs[i], agentTypes[i], symbols[i] = u.ID, u.AgentRunID, string(u.AgentType), u.Symbol
+		providers[i], llmModels[i], inputTokens[i], outputTokens[i] = u.Provider, u.Model, u.InputTokens, u.OutputTokens
+		costs[i], created[i] = u.CostUSD, u.CreatedAt
+	}
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO llm_usage (id, agent_run_id, agent_type, symbol, provider, model, input_tokens, output_tokens, cost_usd, created_at)
+		SELECT * FROM unnest($1::uuid[], $2::uuid[], $3::text[], $4::text[], $5::text[], $6::text[], $7::bigint[], $8::bigint[], $9::float8[], $10::timestamptz[])
+	`, ids, runIDs, agentTypes, symbols, providers, llmModels, inputTokens, outputTokens, costs, created)
+	if err != nil {
+		metrics.RecordDBError("insert", "llm_usage")
+		return fmt.Errorf("failed to save LLM usage: %w", err)
+	}
+
+	return nil
+}
+
+// GetLLMUsage returns LLM usage from since onwards totaled per agent type and model
+func (r *Repository) GetLLMUsage(ctx context.Context, since time.Time) ([]models.LLMUsageRow, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "llm_usage")
+
+	rows, err := r.db.Query(ctx, `
+		SELECT agent_type, provider, model, COUNT(*), SUM(input_tokens), SUM(output_tokens), SUM(cost_usd)
+		FROM llm_usage
+		WHERE created_at >= $1
+		GROUP BY agent_type, provider, model
+		ORDER BY agent_type, provider, model
+	`, since)
+	if err != nil {
+		metrics.RecordDBError("select", "llm_usage")
+		return nil, fmt.Errorf("failed to get LLM usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []models.LLMUsageRow
+	for rows.Next() {
+		var u models.LLMUsageRow
+		if err := rows.Scan(&u.AgentType, &u.Provider, &u.Model, &u.Calls, &u.InputTokens, &u.OutputTokens, &u.CostUSD); err != nil {
+			metrics.RecordDBError("select", "llm_usage")
+			return nil, fmt.Errorf("failed to scan LLM usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+
+	return usage, nil
+}
+
+// GetLLMCostSince returns the estimated cost of all LLM calls from since onwards, in USD
+func (r *Repository) GetLLMCostSince(ctx context.Context, since time.Time) (float64, error) {
+	if err := r.checkDB(); err != nil {
+		return 0, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "llm_usage")
+
+	var cost float64
+	err := r.db.QueryRow(ctx, `SELECT COALESCE(SUM(cost_usd), 0) FROM llm_usage WHERE created_at >= $1`, since).Scan(&cost)
+	if err != nil {
+		metrics.RecordDBError("select", "llm_usage")
+		return 0, fmt.Errorf("failed to get LLM cost: %w", err)
+	}
+
+	return cost, nil
+}
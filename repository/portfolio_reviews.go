@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SavePortfolioReview stores a portfolio review
+func (r *Repository) SavePortfolioReview(ctx context.Context, review *models.PortfolioReview) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "portfolio_reviews")
+
+	reviewJSON, err := json.Marshal(review)
+	if err != nil {
+		metrics.RecordDBError("insert", "portfolio_reviews")
+		return fmt.Errorf("failed to marshal portfolio review: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO portfolio_reviews (id, position_count, review, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, review.ID, len(review.Items), reviewJSON, review.CreatedAt)
+	if err != nil {
+		metrics.RecordDBError("insert", "portfolio_reviews")
+		return fmt.Errorf("failed to save portfolio review: %w", err)
+	}
+
+	return nil
+}
+
+// GetPortfolioReviews returns the most recent portfolio reviews, newest first
+func (r *Repository) GetPortfolioReviews(ctx context.Context, limit int) ([]models.PortfolioReview, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "portfolio_reviews")
+
+	if limit <= 0 {
+		limit = 10
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT review
+		FROM portfolio_reviews
+		ORDER BY created_at DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		metrics.RecordDBError("select", "portfolio_reviews")
+		return nil, fmt.Errorf("failed to get portfolio reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []models.PortfolioReview
+	for rows.Next() {
+		var reviewJSON []byte
+		if err := rows.Scan(&reviewJSON); err != nil {
+			metrics.RecordDBError("select", "portfolio_reviews")
+			return nil, fmt.Errorf("failed to scan portfolio review: %w", err)
+		}
+		var review models.PortfolioReview
+		if err := json.Unmarshal(reviewJSON, &review); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal portfolio review: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
+
+// GetPortfolioReview returns a single review by ID, or nil if it does not exist
+func (r *Repository) GetPortfolioReview(ctx context.Context, id uuid.UUID) (*models.PortfolioReview, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "portfolio_reviews")
+
+	var reviewJSON []byte
+	err := r.db.QueryRow(ctx, `
+		SELECT review FROM portfolio_reviews WHERE id = $1
+	`, id).Scan(&reviewJSON)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		metrics.RecordDBError("select", "portfolio_reviews")
+		return nil, fmt.Errorf("failed to get portfolio review: %w", err)
+	}
+
+	var review models.PortfolioReview
+	if err := json.Unmarshal(reviewJSON, &review); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal portfolio review: %w", err)
+	}
+	return &review, nil
+}
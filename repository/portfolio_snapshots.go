@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// portfolioSnapshotColumns are the portfolio_snapshots columns, in the order
+// scanPortfolioSnapshot reads them
+const portfolioSnapshotColumns = `date, equity, cash, cash_flow, positions, created_at`
+
+func scanPortfolioSnapshot(row pgx.Row) (*models.PortfolioSnapshot, error) {
+	var s models.PortfolioSnapshot
+	var positionsJSON []byte
+	if err := row.Scan(&s.Date, &s.Equity, &s.Cash, &s.CashFlow, &positionsJSON, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(positionsJSON, &s.Positions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot positions: %w", err)
+	}
+	return &s, nil
+}
+
+// SavePortfolioSnapshot stores a day's portfolio snapshot, replacing any taken earlier the
+// same market date
+func (r *Repository) SavePortfolioSnapshot(ctx context.Context, snapshot *models.PortfolioSnapshot) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "portfolio_snapshots")
+
+	positionsJSON, err := json.Marshal(snapshot.Positions)
+	if err != nil {
+		metrics.RecordDBError("insert", "portfolio_snapshots")
+		return fmt.Errorf("failed to marshal snapshot positions: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO portfolio_snapshots (date, equity, cash, cash_flow, positions, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (date) DO UPDATE
+		SET equity = EXCLUDED.equity, cash = EXCLUDED.cash, cash_flow = EXCLUDED.cash_flow,
+			positions = EXCLUDED.positions, created_at = EXCLUDED.created_at
+	`, snapshot.Date, snapshot.Equity, snapshot.Cash, snapshot.CashFlow, positionsJSON, snapshot.CreatedAt)
+	if err != nil {
+		metrics.RecordDBError("insert", "portfolio_snapshots")
+		return fmt.Errorf("failed to save portfolio snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// GetPortfolioSnapshots returns the snapshots taken on or after since's date, oldest first
+func (r *Repository) GetPortfolioSnapshots(ctx context.Context, since time.Time) ([]models.PortfolioSnapshot, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "portfolio_snapshots")
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+portfolioSnapshotColumns+`
+		FROM portfolio_snapshots
+		WHERE date >= $1::date
+		ORDER BY date
+	`, since)
+	if err != nil {
+		metrics.RecordDBError("select", "portfolio_snapshots")
+		return nil, fmt.Errorf("failed to get portfolio snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []models.PortfolioSnapshot
+	for rows.Next() {
+		snapshot, err := scanPortfolioSnapshot(rows)
+		if err != nil {
+			metrics.RecordDBError("select", "portfolio_snapshots")
+			return nil, fmt.Errorf("failed to scan portfolio snapshot: %w", err)
+		}
+		snapshots = append(snapshots, *snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// GetLatestPortfolioSnapshot returns the most recent snapshot, or nil if none has been taken
+func (r *Repository) GetLatestPortfolioSnapshot(ctx context.Context) (*models.PortfolioSnapshot, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "portfolio_snapshots")
+
+	snapshot, err := scanPortfolioSnapshot(r.db.QueryRow(ctx, `
+		SELECT `+portfolioSnapshotColumns+` FROM portfolio_snapshots ORDER BY date DESC LIMIT 1
+	`))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		metrics.RecordDBError("select", "portfolio_snapshots")
+		return nil, fmt.Errorf("failed to get latest portfolio snapshot: %w", err)
+	}
+	return snapshot, nil
+}
@@ -16,7 +16,7 @@ func (r *Repository) GetPositions(ctx context.Context) ([]models.Position, error
 		return nil, err
 	}
 	rows, err := r.db.Query(ctx, `
-		SELECT id, symbol, quantity, avg_entry_price, current_price, unrealized_pl, side, created_at, updated_at
+		SELECT id, symbol, quantity, avg_entry_price, current_price, unrealized_pl, side, stop_loss_price, take_profit_price, stop_loss_order_id, take_profit_order_id, version, created_at, updated_at
 		FROM positions
 		ORDER BY symbol
 	`)
@@ -28,7 +28,7 @@ func (r *Repository) GetPositions(ctx context.Context) ([]models.Position, error
 	var positions []models.Position
 	for rows.Next() {
 		var p models.Position
-		err := rows.Scan(&p.ID, &p.Symbol, &p.Quantity, &p.AvgEntryPrice, &p.CurrentPrice, &p.UnrealizedPL, &p.Side, &p.CreatedAt, &p.UpdatedAt)
+		err := rows.Scan(&p.ID, &p.Symbol, &p.Quantity, &p.AvgEntryPrice, &p.CurrentPrice, &p.UnrealizedPL, &p.Side, &p.StopLossPrice, &p.TakeProfitPrice, &p.StopLossOrderID, &p.TakeProfitOrderID, &p.Version, &p.CreatedAt, &p.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan position: %w", err)
 		}
@@ -45,9 +45,9 @@ func (r *Repository) GetPosition(ctx context.Context, id uuid.UUID) (*models.Pos
 	}
 	var p models.Position
 	err := r.db.QueryRow(ctx, `
-		SELECT id, symbol, quantity, avg_entry_price, current_price, unrealized_pl, side, created_at, updated_at
+		SELECT id, symbol, quantity, avg_entry_price, current_price, unrealized_pl, side, stop_loss_price, take_profit_price, stop_loss_order_id, take_profit_order_id, version, created_at, updated_at
 		FROM positions WHERE id = $1
-	`, id).Scan(&p.ID, &p.Symbol, &p.Quantity, &p.AvgEntryPrice, &p.CurrentPrice, &p.UnrealizedPL, &p.Side, &p.CreatedAt, &p.UpdatedAt)
+	`, id).Scan(&p.ID, &p.Symbol, &p.Quantity, &p.AvgEntryPrice, &p.CurrentPrice, &p.UnrealizedPL, &p.Side, &p.StopLossPrice, &p.TakeProfitPrice, &p.StopLossOrderID, &p.TakeProfitOrderID, &p.Version, &p.CreatedAt, &p.UpdatedAt)
 
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -66,9 +66,9 @@ func (r *Repository) GetPositionBySymbol(ctx context.Context, symbol string) (*m
 	}
 	var p models.Position
 	err := r.db.QueryRow(ctx, `
-		SELECT id, symbol, quantity, avg_entry_price, current_price, unrealized_pl, side, created_at, updated_at
+		SELECT id, symbol, quantity, avg_entry_price, current_price, unrealized_pl, side, stop_loss_price, take_profit_price, stop_loss_order_id, take_profit_order_id, version, created_at, updated_at
 		FROM positions WHERE symbol = $1
-	`, symbol).Scan(&p.ID, &p.Symbol, &p.Quantity, &p.AvgEntryPrice, &p.CurrentPrice, &p.UnrealizedPL, &p.Side, &p.CreatedAt, &p.UpdatedAt)
+	`, symbol).Scan(&p.ID, &p.Symbol, &p.Quantity, &p.AvgEntryPrice, &p.CurrentPrice, &p.UnrealizedPL, &p.Side, &p.StopLossPrice, &p.TakeProfitPrice, &p.StopLossOrderID, &p.TakeProfitOrderID, &p.Version, &p.CreatedAt, &p.UpdatedAt)
 
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -86,31 +86,49 @@ func (r *Repository) CreatePosition(ctx context.Context, pos *models.Position) e
 		return err
 	}
 	_, err := r.db.Exec(ctx, `
-		INSERT INTO positions (id, symbol, quantity, avg_entry_price, current_price, unrealized_pl, side, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	`, pos.ID, pos.Symbol, pos.Quantity, pos.AvgEntryPrice, pos.CurrentPrice, pos.UnrealizedPL, pos.Side, pos.CreatedAt, pos.UpdatedAt)
+		INSERT INTO positions (id, symbol, quantity, avg_entry_price, current_price, unrealized_pl, side, stop_loss_price, take_profit_price, stop_loss_order_id, take_profit_order_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, pos.ID, pos.Symbol, pos.Quantity, pos.AvgEntryPrice, pos.CurrentPrice, pos.UnrealizedPL, pos.Side, pos.StopLossPrice, pos.TakeProfitPrice, pos.StopLossOrderID, pos.TakeProfitOrderID, pos.CreatedAt, pos.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create position: %w", err)
 	}
+	pos.Version = 1
 
 	return nil
 }
 
-// UpdatePosition updates an existing position
+// UpdatePosition updates an existing position and increments its version. Unless
+// pos.Version is models.AnyVersion, the update only applies if the stored version still
+// matches; otherwise models.ErrVersionConflict is returned. On success pos.Version is
+// set to the new version.
 func (r *Repository) UpdatePosition(ctx context.Context, pos *models.Position) error {
 	if err := r.checkDB(); err != nil {
 		return err
 	}
-	_, err := r.db.Exec(ctx, `
-		UPDATE positions 
-		SET quantity = $2, avg_entry_price = $3, current_price = $4, unrealized_pl = $5, side = $6, updated_at = NOW()
-		WHERE id = $1
-	`, pos.ID, pos.Quantity, pos.AvgEntryPrice, pos.CurrentPrice, pos.UnrealizedPL, pos.Side)
+	var version int
+	err := r.db.QueryRow(ctx, `
+		UPDATE positions
+		SET quantity = $2, avg_entry_price = $3, current_price = $4, unrealized_pl = $5, side = $6,
+			stop_loss_price = $7, take_profit_price = $8, stop_loss_order_id = $9, take_profit_order_id = $10,
+			version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND ($11::int = 0 OR version = $11::int)
+		RETURNING version
+	`, pos.ID, pos.Quantity, pos.AvgEntryPrice, pos.CurrentPrice, pos.UnrealizedPL, pos.Side, pos.StopLossPrice, pos.TakeProfitPrice, pos.StopLossOrderID, pos.TakeProfitOrderID, pos.Version).Scan(&version)
 
+	if err == pgx.ErrNoRows {
+		if pos.Version == models.AnyVersion {
+			return nil
+		}
+		if err := r.versionConflict(ctx, "positions", pos.ID); err != nil {
+			return fmt.Errorf("failed to update position: %w", err)
+		}
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update position: %w", err)
 	}
+	pos.Version = version
 
 	return nil
 }
@@ -5,11 +5,18 @@ import (
 	"errors"
 	"fmt"
 
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// logger is the repository module's logger
+var logger = observability.Module(observability.ModuleRepository)
+
 // ErrNoDatabaseConnection is returned when a database operation is attempted
 // but the repository has no active database connection.
 var ErrNoDatabaseConnection = errors.New("no database connection available")
@@ -31,7 +38,13 @@ type Repository struct {
 
 // NewRepository creates a new Repository with a PostgreSQL connection pool
 func NewRepository(ctx context.Context, connString string) (*Repository, error) {
-	pool, err := pgxpool.New(ctx, connString)
+	config, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse connection string: %w", err)
+	}
+	config.ConnConfig.Tracer = statementTracer{}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create connection pool: %w", err)
 	}
@@ -91,3 +104,18 @@ func (r *Repository) checkDB() error {
 	}
 	return nil
 }
+
+// versionConflict is called after a version-checked update matched no rows. It returns
+// models.ErrVersionConflict if the row still exists (so the version was stale) and nil
+// if the row is gone, matching the no-op behaviour of updates on missing rows.
+func (r *Repository) versionConflict(ctx context.Context, table string, id uuid.UUID) error {
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)`, table)
+	if err := r.db.QueryRow(ctx, query, id).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check %s version: %w", table, err)
+	}
+	if exists {
+		return models.ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// SaveProviderAlert inserts a provider alert
+func (r *Repository) SaveProviderAlert(ctx context.Context, alert *models.ProviderAlert) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "provider_alerts")
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO provider_alerts (id, provider, kind, error_sample, recover_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, alert.ID, alert.Provider, alert.Kind, alert.ErrorSample, alert.RecoverAt, alert.CreatedAt)
+	if err != nil {
+		metrics.RecordDBError("insert", "provider_alerts")
+		return fmt.Errorf("failed to save provider alert: %w", err)
+	}
+
+	return nil
+}
+
+// GetProviderAlerts returns the most recent provider alerts, newest first. With activeOnly,
+// dismissed alerts and those whose provider should have recovered by now are left out.
+func (r *Repository) GetProviderAlerts(ctx context.Context, activeOnly bool, limit int) ([]models.ProviderAlert, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "provider_alerts")
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, provider, kind, error_sample, recover_at, created_at, dismissed_at
+		FROM provider_alerts
+		WHERE NOT $1 OR (dismissed_at IS NULL AND (recover_at IS NULL OR recover_at > $2))
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, activeOnly, time.Now(), limit)
+	if err != nil {
+		metrics.RecordDBError("select", "provider_alerts")
+		return nil, fmt.Errorf("failed to get provider alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []models.ProviderAlert
+	for rows.Next() {
+		var a models.ProviderAlert
+		if err := rows.Scan(&a.ID, &a.Provider, &a.Kind, &a.ErrorSample, &a.RecoverAt, &a.CreatedAt, &a.DismissedAt); err != nil {
+			metrics.RecordDBError("select", "provider_alerts")
+			return nil, fmt.Errorf("failed to scan provider alert: %w", err)
+		}
+		alerts = append(alerts, a)
+	}
+
+	return alerts, nil
+}
+
+// DismissProviderAlert marks an alert as seen by the user
+func (r *Repository) DismissProviderAlert(ctx context.Context, id uuid.UUID) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("update", "provider_alerts")
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE provider_alerts SET dismissed_at = COALESCE(dismissed_at, NOW()) WHERE id = $1
+	`, id)
+	if err != nil {
+		metrics.RecordDBError("update", "provider_alerts")
+		return fmt.Errorf("failed to dismiss provider alert: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", models.ErrProviderAlertNotFound, id)
+	}
+
+	return nil
+}
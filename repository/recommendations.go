@@ -13,6 +13,12 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+// recommendationColumns is the column list read by scanRecommendation
+const recommendationColumns = `id, symbol, action, quantity, entry_price, target_price, stop_price, risk_reward,
+	confidence, reasoning, fundamental_score, sentiment_score, technical_score, social_score, insider_score, macro_score, timeframe_scores,
+	agent_weights, data_completeness, missing_agents, weight_policy, trigger_reason, previous_recommendation_id, user_override, partial,
+	status, approved_at, rejected_at, executed_trade_id, version, created_at`
+
 // GetRecommendations returns recommendations filtered by status
 func (r *Repository) GetRecommendations(ctx context.Context, status models.RecommendationStatus, limit int) ([]models.Recommendation, error) {
 	if err := r.checkDB(); err != nil {
@@ -31,20 +37,14 @@ func (r *Repository) GetRecommendations(ctx context.Context, status models.Recom
 
 	if status == "" {
 		rows, err = r.db.Query(ctx, `
-			SELECT id, symbol, action, quantity, target_price, confidence, reasoning,
-				   fundamental_score, sentiment_score, technical_score,
-				   data_completeness, missing_agents,
-				   status, approved_at, rejected_at, executed_trade_id, created_at
+			SELECT `+recommendationColumns+`
 			FROM recommendations
 			ORDER BY created_at DESC
 			LIMIT $1
 		`, limit)
 	} else {
 		rows, err = r.db.Query(ctx, `
-			SELECT id, symbol, action, quantity, target_price, confidence, reasoning,
-				   fundamental_score, sentiment_score, technical_score,
-				   data_completeness, missing_agents,
-				   status, approved_at, rejected_at, executed_trade_id, created_at
+			SELECT `+recommendationColumns+`
 			FROM recommendations
 			WHERE status = $1
 			ORDER BY created_at DESC
@@ -74,13 +74,13 @@ func (r *Repository) GetRecommendations(ctx context.Context, status models.Recom
 // scanRecommendation scans a recommendation row into a Recommendation struct
 func scanRecommendation(row pgx.Row) (*models.Recommendation, error) {
 	var rec models.Recommendation
-	var missingAgentsJSON []byte
+	var missingAgentsJSON, overrideJSON, timeframeJSON, weightsJSON []byte
 	var dataCompleteness *float64
 
-	err := row.Scan(&rec.ID, &rec.Symbol, &rec.Action, &rec.Quantity, &rec.TargetPrice, &rec.Confidence, &rec.Reasoning,
-		&rec.FundamentalScore, &rec.SentimentScore, &rec.TechnicalScore,
-		&dataCompleteness, &missingAgentsJSON,
-		&rec.Status, &rec.ApprovedAt, &rec.RejectedAt, &rec.ExecutedTradeID, &rec.CreatedAt)
+	err := row.Scan(&rec.ID, &rec.Symbol, &rec.Action, &rec.Quantity, &rec.EntryPrice, &rec.TargetPrice, &rec.StopPrice, &rec.RiskReward,
+		&rec.Confidence, &rec.Reasoning, &rec.FundamentalScore, &rec.SentimentScore, &rec.TechnicalScore, &rec.SocialScore, &rec.InsiderScore, &rec.MacroScore, &timeframeJSON,
+		&weightsJSON, &dataCompleteness, &missingAgentsJSON, &rec.WeightPolicy, &rec.TriggerReason, &rec.PreviousID, &overrideJSON, &rec.Partial,
+		&rec.Status, &rec.ApprovedAt, &rec.RejectedAt, &rec.ExecutedTradeID, &rec.Version, &rec.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -100,19 +100,63 @@ func scanRecommendation(row pgx.Row) (*models.Recommendation, error) {
 		}
 	}
 
+	if len(overrideJSON) > 0 {
+		if err := json.Unmarshal(overrideJSON, &rec.Override); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal user_override: %w", err)
+		}
+	}
+
+	if len(timeframeJSON) > 0 {
+		if err := json.Unmarshal(timeframeJSON, &rec.TimeframeScores); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal timeframe_scores: %w", err)
+		}
+	}
+
+	if len(weightsJSON) > 0 {
+		if err := json.Unmarshal(weightsJSON, &rec.AgentWeights); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal agent_weights: %w", err)
+		}
+	}
+
 	return &rec, nil
 }
 
+// marshalTimeframeScores encodes technical sub-scores for the timeframe_scores column,
+// storing NULL when there are none and refusing scores outside -100 to 100
+func marshalTimeframeScores(scores *models.TimeframeScores) ([]byte, error) {
+	if scores == nil {
+		return nil, nil
+	}
+	if err := scores.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid timeframe_scores: %w", err)
+	}
+	data, err := json.Marshal(scores)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal timeframe_scores: %w", err)
+	}
+	return data, nil
+}
+
+// marshalAgentWeights encodes the agent weights for the agent_weights column, storing NULL
+// when there are none
+func marshalAgentWeights(weights *models.AgentWeights) ([]byte, error) {
+	if weights == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(weights)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal agent_weights: %w", err)
+	}
+	return data, nil
+}
+
 // GetRecommendation returns a single recommendation by ID
 func (r *Repository) GetRecommendation(ctx context.Context, id uuid.UUID) (*models.Recommendation, error) {
 	if err := r.checkDB(); err != nil {
 		return nil, err
 	}
 	row := r.db.QueryRow(ctx, `
-		SELECT id, symbol, action, quantity, target_price, confidence, reasoning,
-			   fundamental_score, sentiment_score, technical_score,
-			   data_completeness, missing_agents,
-			   status, approved_at, rejected_at, executed_trade_id, created_at
+		SELECT `+recommendationColumns+`
 		FROM recommendations WHERE id = $1
 	`, id)
 
@@ -127,6 +171,100 @@ func (r *Repository) GetRecommendation(ctx context.Context, id uuid.UUID) (*mode
 	return rec, nil
 }
 
+// GetRecommendationByTradeID returns the recommendation a trade executed, or nil if the trade
+// executed none
+func (r *Repository) GetRecommendationByTradeID(ctx context.Context, tradeID uuid.UUID) (*models.Recommendation, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	row := r.db.QueryRow(ctx, `
+		SELECT `+recommendationColumns+`
+		FROM recommendations WHERE executed_trade_id = $1
+	`, tradeID)
+
+	rec, err := scanRecommendation(row)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recommendation by trade: %w", err)
+	}
+
+	return rec, nil
+}
+
+// GetLatestRecommendationForSymbol returns the most recent recommendation for a symbol,
+// or nil when the symbol has never been analyzed
+func (r *Repository) GetLatestRecommendationForSymbol(ctx context.Context, symbol string) (*models.Recommendation, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "recommendations")
+
+	row := r.db.QueryRow(ctx, `
+		SELECT `+recommendationColumns+`
+		FROM recommendations WHERE symbol = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, symbol)
+
+	rec, err := scanRecommendation(row)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		metrics.RecordDBError("select", "recommendations")
+		return nil, fmt.Errorf("failed to query latest recommendation: %w", err)
+	}
+
+	return rec, nil
+}
+
+// GetRecommendationsForSymbol returns a symbol's most recent recommendations, up to limit,
+// oldest first
+func (r *Repository) GetRecommendationsForSymbol(ctx context.Context, symbol string, limit int) ([]models.Recommendation, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "recommendations")
+
+	if limit <= 0 {
+		limit = 50
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT * FROM (
+			SELECT `+recommendationColumns+`
+			FROM recommendations
+			WHERE symbol = $1
+			ORDER BY created_at DESC
+			LIMIT $2
+		) recent
+		ORDER BY created_at
+	`, symbol, limit)
+	if err != nil {
+		metrics.RecordDBError("select", "recommendations")
+		return nil, fmt.Errorf("failed to query recommendations for %s: %w", symbol, err)
+	}
+	defer rows.Close()
+
+	var recs []models.Recommendation
+	for rows.Next() {
+		rec, err := scanRecommendation(rows)
+		if err != nil {
+			metrics.RecordDBError("select", "recommendations")
+			return nil, fmt.Errorf("failed to scan recommendation: %w", err)
+		}
+		recs = append(recs, *rec)
+	}
+
+	return recs, rows.Err()
+}
+
 // CreateRecommendation creates a new recommendation
 func (r *Repository) CreateRecommendation(ctx context.Context, rec *models.Recommendation) error {
 	if err := r.checkDB(); err != nil {
@@ -142,76 +280,410 @@ func (r *Repository) CreateRecommendation(ctx context.Context, rec *models.Recom
 		metrics.RecordDBError("insert", "recommendations")
 		return fmt.Errorf("failed to marshal missing_agents: %w", err)
 	}
+	timeframeJSON, err := marshalTimeframeScores(rec.TimeframeScores)
+	if err != nil {
+		metrics.RecordDBError("insert", "recommendations")
+		return err
+	}
+	weightsJSON, err := marshalAgentWeights(rec.AgentWeights)
+	if err != nil {
+		metrics.RecordDBError("insert", "recommendations")
+		return err
+	}
 
 	_, err = r.db.Exec(ctx, `
-		INSERT INTO recommendations (id, symbol, action, quantity, target_price, confidence, reasoning,
-			fundamental_score, sentiment_score, technical_score, data_completeness, missing_agents, status, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
-	`, rec.ID, rec.Symbol, rec.Action, rec.Quantity, rec.TargetPrice, rec.Confidence, rec.Reasoning,
-		rec.FundamentalScore, rec.SentimentScore, rec.TechnicalScore, rec.DataCompleteness, missingAgentsJSON, rec.Status, rec.CreatedAt)
+		WITH inserted AS (
+			INSERT INTO recommendations (id, symbol, action, quantity, entry_price, target_price, stop_price, risk_reward,
+				confidence, reasoning, fundamental_score, sentiment_score, technical_score, data_completeness, missing_agents, weight_policy, trigger_reason, partial, status, created_at,
+				timeframe_scores, social_score, insider_score, macro_score, previous_recommendation_id, agent_weights)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $23, $24, $25, $26, $27, $28)
+			RETURNING id, created_at
+		)
+		INSERT INTO recommendation_events (recommendation_id, event_type, actor, occurred_at)
+		SELECT id, $21, $22, created_at FROM inserted
+	`, rec.ID, rec.Symbol, rec.Action, rec.Quantity, rec.EntryPrice, rec.TargetPrice, rec.StopPrice, rec.RiskReward,
+		rec.Confidence, rec.Reasoning,
+		rec.FundamentalScore, rec.SentimentScore, rec.TechnicalScore, rec.DataCompleteness, missingAgentsJSON, rec.WeightPolicy, rec.TriggerReason, rec.Partial, rec.Status, rec.CreatedAt,
+		models.RecommendationEventCreated, models.ActorSystem, timeframeJSON, rec.SocialScore, rec.InsiderScore, rec.MacroScore, rec.PreviousID, weightsJSON)
 
 	if err != nil {
 		metrics.RecordDBError("insert", "recommendations")
 		return fmt.Errorf("failed to create recommendation: %w", err)
 	}
+	rec.Version = 1
 
 	return nil
 }
 
-// ApproveRecommendation marks a recommendation as approved
-func (r *Repository) ApproveRecommendation(ctx context.Context, id uuid.UUID) error {
+// ApproveRecommendation marks a pending recommendation as approved. If expectedVersion is
+// not models.AnyVersion and the row has moved on, models.ErrVersionConflict is returned;
+// models.ErrRecommendationNotExecutable if it is no longer pending, and
+// models.ErrRecommendationNotFound if it doesn't exist.
+func (r *Repository) ApproveRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error {
+	if err := r.transitionRecommendation(ctx, id, expectedVersion, pendingStatuses, models.RecommendationStatusApproved, models.RecommendationEventApproved, models.ActorUser, nil); err != nil {
+		return fmt.Errorf("failed to approve recommendation: %w", err)
+	}
+	return nil
+}
+
+// RejectRecommendation marks a pending recommendation as rejected, with the same checks as ApproveRecommendation
+func (r *Repository) RejectRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error {
+	if err := r.transitionRecommendation(ctx, id, expectedVersion, pendingStatuses, models.RecommendationStatusRejected, models.RecommendationEventRejected, models.ActorUser, nil); err != nil {
+		return fmt.Errorf("failed to reject recommendation: %w", err)
+	}
+	return nil
+}
+
+// ExecuteRecommendation marks an executing recommendation, or an approved one whose split
+// finished, as executed with the trade ID, with the same checks as ApproveRecommendation
+func (r *Repository) ExecuteRecommendation(ctx context.Context, id uuid.UUID, tradeID uuid.UUID, expectedVersion int) error {
+	if err := r.transitionRecommendation(ctx, id, expectedVersion, executableStatuses, models.RecommendationStatusExecuted, models.RecommendationEventExecuted, models.ActorSystem, &tradeID); err != nil {
+		return fmt.Errorf("failed to execute recommendation: %w", err)
+	}
+	return nil
+}
+
+// ClaimRecommendation marks an approved recommendation as executing before its order is
+// placed, so a second execution fails here instead of placing another order. Returns
+// models.ErrVersionConflict if expectedVersion is stale and
+// models.ErrRecommendationNotExecutable if it is no longer approved.
+func (r *Repository) ClaimRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error {
+	if err := r.moveRecommendationStatus(ctx, id, expectedVersion, models.RecommendationStatusApproved, models.RecommendationStatusExecuting); err != nil {
+		return fmt.Errorf("failed to claim recommendation: %w", err)
+	}
+	return nil
+}
+
+// ReleaseRecommendation returns a claimed recommendation to approved after its order could
+// not be placed, with the same checks as ClaimRecommendation
+func (r *Repository) ReleaseRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int) error {
+	if err := r.moveRecommendationStatus(ctx, id, expectedVersion, models.RecommendationStatusExecuting, models.RecommendationStatusApproved); err != nil {
+		return fmt.Errorf("failed to release recommendation: %w", err)
+	}
+	return nil
+}
+
+// moveRecommendationStatus changes a recommendation's status from one state to another and
+// bumps its version. The executing claim is not a decision, so no event is appended.
+func (r *Repository) moveRecommendationStatus(ctx context.Context, id uuid.UUID, expectedVersion int, from, to models.RecommendationStatus) error {
 	if err := r.checkDB(); err != nil {
 		return err
 	}
-	_, err := r.db.Exec(ctx, `
-		UPDATE recommendations 
-		SET status = $2, approved_at = $3 
-		WHERE id = $1
-	`, id, models.RecommendationStatusApproved, time.Now())
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("update", "recommendations")
 
+	tag, err := r.db.Exec(ctx, `
+		UPDATE recommendations
+		SET status = $3, version = version + 1
+		WHERE id = $1 AND status = $2 AND ($4::int = 0 OR version = $4::int)
+	`, id, from, to, expectedVersion)
 	if err != nil {
-		return fmt.Errorf("failed to approve recommendation: %w", err)
+		metrics.RecordDBError("update", "recommendations")
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return r.transitionConflict(ctx, id, expectedVersion)
 	}
 
 	return nil
 }
 
-// RejectRecommendation marks a recommendation as rejected
-func (r *Repository) RejectRecommendation(ctx context.Context, id uuid.UUID) error {
+// FailRecommendation marks an executed recommendation as failed after the broker cancelled,
+// rejected or expired its order with nothing filled
+func (r *Repository) FailRecommendation(ctx context.Context, id uuid.UUID, tradeID uuid.UUID) error {
+	if err := r.transitionRecommendation(ctx, id, models.AnyVersion, executedStatuses, models.RecommendationStatusFailed, models.RecommendationEventFailed, models.ActorSystem, &tradeID); err != nil {
+		return fmt.Errorf("failed to fail recommendation: %w", err)
+	}
+	return nil
+}
+
+// UpdateRecommendationOverride stores a user's edits to a pending recommendation, bumps its
+// version and appends an edited event. Returns models.ErrVersionConflict if expectedVersion is
+// stale and models.ErrRecommendationNotExecutable if the recommendation is no longer pending.
+func (r *Repository) UpdateRecommendationOverride(ctx context.Context, id uuid.UUID, override *models.RecommendationOverride, expectedVersion int) error {
 	if err := r.checkDB(); err != nil {
 		return err
 	}
-	_, err := r.db.Exec(ctx, `
-		UPDATE recommendations 
-		SET status = $2, rejected_at = $3 
-		WHERE id = $1
-	`, id, models.RecommendationStatusRejected, time.Now())
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("update", "recommendations")
 
+	overrideJSON, err := json.Marshal(override)
 	if err != nil {
-		return fmt.Errorf("failed to reject recommendation: %w", err)
+		metrics.RecordDBError("update", "recommendations")
+		return fmt.Errorf("failed to marshal user_override: %w", err)
+	}
+
+	tag, err := r.db.Exec(ctx, `
+		WITH updated AS (
+			UPDATE recommendations
+			SET user_override = $2, version = version + 1
+			WHERE id = $1 AND status = 'pending' AND ($6::int = 0 OR version = $6::int)
+			RETURNING id
+		)
+		INSERT INTO recommendation_events (recommendation_id, event_type, actor, occurred_at)
+		SELECT id, $3, $4, $5::timestamptz FROM updated
+	`, id, overrideJSON, models.RecommendationEventEdited, models.ActorUser, time.Now(), expectedVersion)
+	if err != nil {
+		metrics.RecordDBError("update", "recommendations")
+		return fmt.Errorf("failed to update recommendation override: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		if expectedVersion != models.AnyVersion {
+			return r.versionConflict(ctx, "recommendations", id)
+		}
+		return fmt.Errorf("%w: recommendation %s is no longer pending", models.ErrRecommendationNotExecutable, id)
 	}
 
 	return nil
 }
 
-// ExecuteRecommendation marks a recommendation as executed with the trade ID
-func (r *Repository) ExecuteRecommendation(ctx context.Context, id uuid.UUID, tradeID uuid.UUID) error {
+// CompleteRecommendation replaces a partial recommendation's analysis with the result from
+// every agent, clears the partial flag, bumps its version and appends a completed event.
+// Returns models.ErrRecommendationNotExecutable if it is no longer a pending partial result,
+// so decisions already made on the partial result are never rewritten.
+func (r *Repository) CompleteRecommendation(ctx context.Context, rec *models.Recommendation) error {
 	if err := r.checkDB(); err != nil {
 		return err
 	}
-	_, err := r.db.Exec(ctx, `
-		UPDATE recommendations 
-		SET status = $2, executed_trade_id = $3 
-		WHERE id = $1
-	`, id, models.RecommendationStatusExecuted, tradeID)
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("update", "recommendations")
 
+	missingAgentsJSON, err := json.Marshal(rec.MissingAgents)
 	if err != nil {
-		return fmt.Errorf("failed to execute recommendation: %w", err)
+		metrics.RecordDBError("update", "recommendations")
+		return fmt.Errorf("failed to marshal missing_agents: %w", err)
+	}
+	timeframeJSON, err := marshalTimeframeScores(rec.TimeframeScores)
+	if err != nil {
+		metrics.RecordDBError("update", "recommendations")
+		return err
+	}
+	weightsJSON, err := marshalAgentWeights(rec.AgentWeights)
+	if err != nil {
+		metrics.RecordDBError("update", "recommendations")
+		return err
+	}
+
+	tag, err := r.db.Exec(ctx, `
+		WITH updated AS (
+			UPDATE recommendations
+			SET action = $2, quantity = $3, entry_price = $4, target_price = $5, stop_price = $6, risk_reward = $7,
+				confidence = $8, reasoning = $9, fundamental_score = $10, sentiment_score = $11, technical_score = $12,
+				data_completeness = $13, missing_agents = $14, weight_policy = $15, timeframe_scores = $19,
+				social_score = $20, insider_score = $21, macro_score = $22, agent_weights = $23, partial = FALSE, version = version + 1
+			WHERE id = $1 AND status = 'pending' AND partial
+			RETURNING id
+		)
+		INSERT INTO recommendation_events (recommendation_id, event_type, actor, occurred_at)
+		SELECT id, $16, $17, $18::timestamptz FROM updated
+	`, rec.ID, rec.Action, rec.Quantity, rec.EntryPrice, rec.TargetPrice, rec.StopPrice, rec.RiskReward,
+		rec.Confidence, rec.Reasoning, rec.FundamentalScore, rec.SentimentScore, rec.TechnicalScore,
+		rec.DataCompleteness, missingAgentsJSON, rec.WeightPolicy,
+		models.RecommendationEventCompleted, models.ActorSystem, time.Now(), timeframeJSON, rec.SocialScore, rec.InsiderScore, rec.MacroScore, weightsJSON)
+	if err != nil {
+		metrics.RecordDBError("update", "recommendations")
+		return fmt.Errorf("failed to complete recommendation: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: recommendation %s is no longer a pending partial result", models.ErrRecommendationNotExecutable, rec.ID)
+	}
+	rec.Partial = false
+
+	return nil
+}
+
+// ExpireStaleRecommendations moves pending and approved recommendations created before
+// createdBefore, or listed in ids, to expired and logs an expired event for each. A zero
+// createdBefore expires by ID only. Recommendations with a split plan are left to their
+// tranches. It returns the IDs of the recommendations expired.
+func (r *Repository) ExpireStaleRecommendations(ctx context.Context, createdBefore time.Time, ids []uuid.UUID) ([]uuid.UUID, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("update", "recommendations")
+
+	if ids == nil {
+		ids = []uuid.UUID{}
+	}
+	rows, err := r.db.Query(ctx, `
+		WITH expired AS (
+			UPDATE recommendations rec
+			SET status = $3, version = version + 1
+			WHERE rec.status IN ($4, $5)
+				AND (rec.created_at < $1 OR rec.id = ANY($2::uuid[]))
+				AND NOT EXISTS (SELECT 1 FROM recommendation_tranches t WHERE t.recommendation_id = rec.id)
+			RETURNING rec.id
+		), logged AS (
+			INSERT INTO recommendation_events (recommendation_id, event_type, actor, occurred_at)
+			SELECT id, $6, $7, $8::timestamptz FROM expired
+		)
+		SELECT id FROM expired
+	`, createdBefore, ids, models.RecommendationStatusExpired, models.RecommendationStatusPending, models.RecommendationStatusApproved,
+		models.RecommendationEventExpired, models.ActorSystem, time.Now())
+	if err != nil {
+		metrics.RecordDBError("update", "recommendations")
+		return nil, fmt.Errorf("failed to expire recommendations: %w", err)
+	}
+	defer rows.Close()
+
+	var expired []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			metrics.RecordDBError("update", "recommendations")
+			return nil, fmt.Errorf("failed to scan expired recommendation: %w", err)
+		}
+		expired = append(expired, id)
+	}
+	if err := rows.Err(); err != nil {
+		metrics.RecordDBError("update", "recommendations")
+		return nil, fmt.Errorf("failed to expire recommendations: %w", err)
+	}
+
+	return expired, nil
+}
+
+// The statuses each recommendation transition may start from
+var (
+	pendingStatuses    = []string{string(models.RecommendationStatusPending)}
+	executableStatuses = []string{string(models.RecommendationStatusExecuting), string(models.RecommendationStatusApproved)}
+	executedStatuses   = []string{string(models.RecommendationStatusExecuted)}
+)
+
+// transitionRecommendation moves a recommendation in one of the from statuses to status, bumps
+// its version, and appends the matching event in a single statement, so the log can never
+// disagree with the row. The approved_at, rejected_at, and executed_trade_id columns are kept
+// for existing readers.
+func (r *Repository) transitionRecommendation(ctx context.Context, id uuid.UUID, expectedVersion int, from []string, status models.RecommendationStatus, eventType models.RecommendationEventType, actor string, tradeID *uuid.UUID) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("update", "recommendations")
+
+	tag, err := r.db.Exec(ctx, `
+		WITH updated AS (
+			UPDATE recommendations
+			SET status = $2,
+				approved_at = CASE WHEN $2 = 'approved' THEN $6::timestamptz ELSE approved_at END,
+				rejected_at = CASE WHEN $2 = 'rejected' THEN $6::timestamptz ELSE rejected_at END,
+				executed_trade_id = COALESCE($5, executed_trade_id),
+				version = version + 1
+			WHERE id = $1 AND ($7::int = 0 OR version = $7::int) AND status = ANY($8::text[])
+			RETURNING id
+		)
+		INSERT INTO recommendation_events (recommendation_id, event_type, actor, trade_id, occurred_at)
+		SELECT id, $3, $4, $5, $6::timestamptz FROM updated
+	`, id, status, eventType, actor, tradeID, time.Now(), expectedVersion, from)
+	if err != nil {
+		metrics.RecordDBError("update", "recommendations")
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return r.transitionConflict(ctx, id, expectedVersion)
 	}
 
 	return nil
 }
 
+// transitionConflict explains a status change that matched no row: models.ErrRecommendationNotFound
+// for an unknown ID, models.ErrVersionConflict if expectedVersion is stale, and otherwise
+// models.ErrRecommendationNotExecutable, as the recommendation is in the wrong status
+func (r *Repository) transitionConflict(ctx context.Context, id uuid.UUID, expectedVersion int) error {
+	var status models.RecommendationStatus
+	var version int
+	err := r.db.QueryRow(ctx, `SELECT status, version FROM recommendations WHERE id = $1`, id).Scan(&status, &version)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("%w: %s", models.ErrRecommendationNotFound, id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check recommendation status: %w", err)
+	}
+	if expectedVersion != models.AnyVersion && version != expectedVersion {
+		return models.ErrVersionConflict
+	}
+	return fmt.Errorf("%w: recommendation %s is %s", models.ErrRecommendationNotExecutable, id, status)
+}
+
+// GetRecommendationEvents returns the state transitions of a recommendation, oldest first
+func (r *Repository) GetRecommendationEvents(ctx context.Context, id uuid.UUID) ([]models.RecommendationEvent, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "recommendation_events")
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, recommendation_id, event_type, actor, trade_id, occurred_at
+		FROM recommendation_events
+		WHERE recommendation_id = $1
+		ORDER BY occurred_at, id
+	`, id)
+	if err != nil {
+		metrics.RecordDBError("select", "recommendation_events")
+		return nil, fmt.Errorf("failed to query recommendation events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.RecommendationEvent
+	for rows.Next() {
+		var e models.RecommendationEvent
+		if err := rows.Scan(&e.ID, &e.RecommendationID, &e.Type, &e.Actor, &e.TradeID, &e.OccurredAt); err != nil {
+			metrics.RecordDBError("select", "recommendation_events")
+			return nil, fmt.Errorf("failed to scan recommendation event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// GetExecutedRecommendations returns every recommendation that was executed, with the
+// trade it executed, oldest first
+func (r *Repository) GetExecutedRecommendations(ctx context.Context) ([]models.Recommendation, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "recommendations")
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+recommendationColumns+`
+		FROM recommendations
+		WHERE executed_trade_id IS NOT NULL
+		ORDER BY created_at
+	`)
+	if err != nil {
+		metrics.RecordDBError("select", "recommendations")
+		return nil, fmt.Errorf("failed to query executed recommendations: %w", err)
+	}
+	defer rows.Close()
+
+	var recs []models.Recommendation
+	for rows.Next() {
+		rec, err := scanRecommendation(rows)
+		if err != nil {
+			metrics.RecordDBError("select", "recommendations")
+			return nil, fmt.Errorf("failed to scan recommendation: %w", err)
+		}
+		recs = append(recs, *rec)
+	}
+
+	return recs, rows.Err()
+}
+
 // GetPendingRecommendations returns all pending recommendations
 func (r *Repository) GetPendingRecommendations(ctx context.Context) ([]models.Recommendation, error) {
 	return r.GetRecommendations(ctx, models.RecommendationStatusPending, 100)
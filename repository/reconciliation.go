@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveReconciliationReport stores a report, replacing any earlier report for the same period
+func (r *Repository) SaveReconciliationReport(ctx context.Context, report *models.ReconciliationReport) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "reconciliation_reports")
+
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		metrics.RecordDBError("insert", "reconciliation_reports")
+		return fmt.Errorf("failed to marshal reconciliation report: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO reconciliation_reports (id, period_start, period_end, discrepancy_count, report, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (period_start) DO UPDATE SET
+			id = EXCLUDED.id,
+			period_end = EXCLUDED.period_end,
+			discrepancy_count = EXCLUDED.discrepancy_count,
+			report = EXCLUDED.report,
+			created_at = EXCLUDED.created_at
+	`, report.ID, report.PeriodStart, report.PeriodEnd, report.DiscrepancyCount(), reportJSON, report.CreatedAt)
+	if err != nil {
+		metrics.RecordDBError("insert", "reconciliation_reports")
+		return fmt.Errorf("failed to save reconciliation report: %w", err)
+	}
+
+	return nil
+}
+
+// GetReconciliationReports returns the most recent reports, newest period first
+func (r *Repository) GetReconciliationReports(ctx context.Context, limit int) ([]models.ReconciliationReport, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "reconciliation_reports")
+
+	if limit <= 0 {
+		limit = 12
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT report
+		FROM reconciliation_reports
+		ORDER BY period_start DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		metrics.RecordDBError("select", "reconciliation_reports")
+		return nil, fmt.Errorf("failed to get reconciliation reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []models.ReconciliationReport
+	for rows.Next() {
+		var reportJSON []byte
+		if err := rows.Scan(&reportJSON); err != nil {
+			metrics.RecordDBError("select", "reconciliation_reports")
+			return nil, fmt.Errorf("failed to scan reconciliation report: %w", err)
+		}
+		var report models.ReconciliationReport
+		if err := json.Unmarshal(reportJSON, &report); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal reconciliation report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// GetReconciliationReportForPeriod returns the report for the period starting at
+// periodStart, or nil if that period has none
+func (r *Repository) GetReconciliationReportForPeriod(ctx context.Context, periodStart time.Time) (*models.ReconciliationReport, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "reconciliation_reports")
+
+	var reportJSON []byte
+	err := r.db.QueryRow(ctx, `
+		SELECT report FROM reconciliation_reports WHERE period_start = $1
+	`, periodStart).Scan(&reportJSON)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		metrics.RecordDBError("select", "reconciliation_reports")
+		return nil, fmt.Errorf("failed to get reconciliation report: %w", err)
+	}
+
+	var report models.ReconciliationReport
+	if err := json.Unmarshal(reportJSON, &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reconciliation report: %w", err)
+	}
+	return &report, nil
+}
+
+// GetReconciliationReport returns a single report by ID, or nil if it does not exist
+func (r *Repository) GetReconciliationReport(ctx context.Context, id uuid.UUID) (*models.ReconciliationReport, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "reconciliation_reports")
+
+	var reportJSON []byte
+	err := r.db.QueryRow(ctx, `
+		SELECT report FROM reconciliation_reports WHERE id = $1
+	`, id).Scan(&reportJSON)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		metrics.RecordDBError("select", "reconciliation_reports")
+		return nil, fmt.Errorf("failed to get reconciliation report: %w", err)
+	}
+
+	var report models.ReconciliationReport
+	if err := json.Unmarshal(reportJSON, &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reconciliation report: %w", err)
+	}
+	return &report, nil
+}
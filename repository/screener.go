@@ -31,10 +31,15 @@ func (r *Repository) CreateScreenerRun(ctx context.Context, run *models.Screener
 		return fmt.Errorf("failed to marshal candidates: %w", err)
 	}
 
+	throttleJSON, err := marshalThrottle(run.Throttle)
+	if err != nil {
+		return err
+	}
+
 	_, err = r.db.Exec(ctx, `
-		INSERT INTO screener_runs (id, run_at, criteria, candidates, top_picks, duration_ms, status, error, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	`, run.ID, run.RunAt, criteriaJSON, candidatesJSON, run.TopPicks, run.DurationMs, run.Status, run.Error, run.CreatedAt)
+		INSERT INTO screener_runs (id, run_at, criteria, candidates, top_picks, duration_ms, status, error, throttle, replay_of, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, run.ID, run.RunAt, criteriaJSON, candidatesJSON, run.TopPicks, run.DurationMs, run.Status, run.Error, throttleJSON, run.ReplayOf, run.CreatedAt)
 
 	if err != nil {
 		metrics.RecordDBError("insert", "screener_runs")
@@ -58,11 +63,16 @@ func (r *Repository) UpdateScreenerRun(ctx context.Context, run *models.Screener
 		return fmt.Errorf("failed to marshal candidates: %w", err)
 	}
 
+	throttleJSON, err := marshalThrottle(run.Throttle)
+	if err != nil {
+		return err
+	}
+
 	_, err = r.db.Exec(ctx, `
 		UPDATE screener_runs
-		SET candidates = $2, top_picks = $3, duration_ms = $4, status = $5, error = $6
+		SET candidates = $2, top_picks = $3, duration_ms = $4, status = $5, error = $6, throttle = $7
 		WHERE id = $1
-	`, run.ID, candidatesJSON, run.TopPicks, run.DurationMs, run.Status, run.Error)
+	`, run.ID, candidatesJSON, run.TopPicks, run.DurationMs, run.Status, run.Error, throttleJSON)
 
 	if err != nil {
 		metrics.RecordDBError("update", "screener_runs")
@@ -82,13 +92,13 @@ func (r *Repository) GetScreenerRun(ctx context.Context, id uuid.UUID) (*models.
 	defer timer.ObserveDB("select", "screener_runs")
 
 	var run models.ScreenerRun
-	var criteriaJSON, candidatesJSON []byte
+	var criteriaJSON, candidatesJSON, throttleJSON []byte
 
 	err := r.db.QueryRow(ctx, `
-		SELECT id, run_at, criteria, candidates, top_picks, duration_ms, status, error, created_at
+		SELECT id, run_at, criteria, candidates, top_picks, duration_ms, status, error, throttle, replay_of, created_at
 		FROM screener_runs
 		WHERE id = $1
-	`, id).Scan(&run.ID, &run.RunAt, &criteriaJSON, &candidatesJSON, &run.TopPicks, &run.DurationMs, &run.Status, &run.Error, &run.CreatedAt)
+	`, id).Scan(&run.ID, &run.RunAt, &criteriaJSON, &candidatesJSON, &run.TopPicks, &run.DurationMs, &run.Status, &run.Error, &throttleJSON, &run.ReplayOf, &run.CreatedAt)
 
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -106,6 +116,12 @@ func (r *Repository) GetScreenerRun(ctx context.Context, id uuid.UUID) (*models.
 		return nil, fmt.Errorf("failed to unmarshal candidates: %w", err)
 	}
 
+	if len(throttleJSON) > 0 {
+		if err := json.Unmarshal(throttleJSON, &run.Throttle); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal throttle: %w", err)
+		}
+	}
+
 	return &run, nil
 }
 
@@ -119,14 +135,14 @@ func (r *Repository) GetLatestScreenerRun(ctx context.Context) (*models.Screener
 	defer timer.ObserveDB("select", "screener_runs")
 
 	var run models.ScreenerRun
-	var criteriaJSON, candidatesJSON []byte
+	var criteriaJSON, candidatesJSON, throttleJSON []byte
 
 	err := r.db.QueryRow(ctx, `
-		SELECT id, run_at, criteria, candidates, top_picks, duration_ms, status, error, created_at
+		SELECT id, run_at, criteria, candidates, top_picks, duration_ms, status, error, throttle, replay_of, created_at
 		FROM screener_runs
 		ORDER BY run_at DESC
 		LIMIT 1
-	`).Scan(&run.ID, &run.RunAt, &criteriaJSON, &candidatesJSON, &run.TopPicks, &run.DurationMs, &run.Status, &run.Error, &run.CreatedAt)
+	`).Scan(&run.ID, &run.RunAt, &criteriaJSON, &candidatesJSON, &run.TopPicks, &run.DurationMs, &run.Status, &run.Error, &throttleJSON, &run.ReplayOf, &run.CreatedAt)
 
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -144,6 +160,12 @@ func (r *Repository) GetLatestScreenerRun(ctx context.Context) (*models.Screener
 		return nil, fmt.Errorf("failed to unmarshal candidates: %w", err)
 	}
 
+	if len(throttleJSON) > 0 {
+		if err := json.Unmarshal(throttleJSON, &run.Throttle); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal throttle: %w", err)
+		}
+	}
+
 	return &run, nil
 }
 
@@ -161,7 +183,7 @@ func (r *Repository) GetScreenerRunHistory(ctx context.Context, limit int) ([]mo
 	}
 
 	rows, err := r.db.Query(ctx, `
-		SELECT id, run_at, criteria, candidates, top_picks, duration_ms, status, error, created_at
+		SELECT id, run_at, criteria, candidates, top_picks, duration_ms, status, error, throttle, replay_of, created_at
 		FROM screener_runs
 		ORDER BY run_at DESC
 		LIMIT $1
@@ -175,9 +197,9 @@ func (r *Repository) GetScreenerRunHistory(ctx context.Context, limit int) ([]mo
 	var runs []models.ScreenerRun
 	for rows.Next() {
 		var run models.ScreenerRun
-		var criteriaJSON, candidatesJSON []byte
+		var criteriaJSON, candidatesJSON, throttleJSON []byte
 
-		err := rows.Scan(&run.ID, &run.RunAt, &criteriaJSON, &candidatesJSON, &run.TopPicks, &run.DurationMs, &run.Status, &run.Error, &run.CreatedAt)
+		err := rows.Scan(&run.ID, &run.RunAt, &criteriaJSON, &candidatesJSON, &run.TopPicks, &run.DurationMs, &run.Status, &run.Error, &throttleJSON, &run.ReplayOf, &run.CreatedAt)
 		if err != nil {
 			metrics.RecordDBError("select", "screener_runs")
 			return nil, fmt.Errorf("failed to scan screener run: %w", err)
@@ -191,8 +213,87 @@ func (r *Repository) GetScreenerRunHistory(ctx context.Context, limit int) ([]mo
 			return nil, fmt.Errorf("failed to unmarshal candidates: %w", err)
 		}
 
+		if len(throttleJSON) > 0 {
+			if err := json.Unmarshal(throttleJSON, &run.Throttle); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal throttle: %w", err)
+			}
+		}
+
 		runs = append(runs, run)
 	}
 
 	return runs, nil
 }
+
+// SaveScreenerUniverse archives the raw provider screen results a run started from
+func (r *Repository) SaveScreenerUniverse(ctx context.Context, runID uuid.UUID, entries []models.ScreenerUniverseEntry) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "screener_universes")
+
+	if entries == nil {
+		entries = []models.ScreenerUniverseEntry{}
+	}
+	entriesJSON, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal universe: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO screener_universes (run_id, entries)
+		VALUES ($1, $2)
+		ON CONFLICT (run_id) DO UPDATE SET entries = EXCLUDED.entries
+	`, runID, entriesJSON)
+
+	if err != nil {
+		metrics.RecordDBError("insert", "screener_universes")
+		return fmt.Errorf("failed to save screener universe: %w", err)
+	}
+
+	return nil
+}
+
+// GetScreenerUniverse returns the archived universe for a run, or nil if none was archived
+func (r *Repository) GetScreenerUniverse(ctx context.Context, runID uuid.UUID) ([]models.ScreenerUniverseEntry, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "screener_universes")
+
+	var entriesJSON []byte
+	err := r.db.QueryRow(ctx, `
+		SELECT entries FROM screener_universes WHERE run_id = $1
+	`, runID).Scan(&entriesJSON)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		metrics.RecordDBError("select", "screener_universes")
+		return nil, fmt.Errorf("failed to get screener universe: %w", err)
+	}
+
+	entries := []models.ScreenerUniverseEntry{}
+	if err := json.Unmarshal(entriesJSON, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal universe: %w", err)
+	}
+
+	return entries, nil
+}
+
+// marshalThrottle encodes a throttle report, storing NULL for runs that were never throttled
+func marshalThrottle(report *models.ThrottleReport) ([]byte, error) {
+	if report == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal throttle: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// screenerPresetColumns are the screener_presets columns, in the order scanScreenerPreset reads them
+const screenerPresetColumns = `name, description, market_cap_min, market_cap_max, pe_ratio_max, pb_ratio_max,
+	dividend_yield_min, eps_min, sector, created_at, updated_at`
+
+func scanScreenerPreset(row pgx.Row) (*models.ScreenerPreset, error) {
+	var p models.ScreenerPreset
+	err := row.Scan(&p.Name, &p.Description, &p.MarketCapMin, &p.MarketCapMax, &p.PERatioMax, &p.PBRatioMax,
+		&p.DividendYieldMin, &p.EPSMin, &p.Sector, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetScreenerPresets returns every saved screener preset, ordered by name
+func (r *Repository) GetScreenerPresets(ctx context.Context) ([]models.ScreenerPreset, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "screener_presets")
+
+	rows, err := r.db.Query(ctx, `SELECT `+screenerPresetColumns+` FROM screener_presets ORDER BY name`)
+	if err != nil {
+		metrics.RecordDBError("select", "screener_presets")
+		return nil, fmt.Errorf("failed to get screener presets: %w", err)
+	}
+	defer rows.Close()
+
+	presets := []models.ScreenerPreset{}
+	for rows.Next() {
+		p, err := scanScreenerPreset(rows)
+		if err != nil {
+			metrics.RecordDBError("select", "screener_presets")
+			return nil, fmt.Errorf("failed to scan screener preset: %w", err)
+		}
+		presets = append(presets, *p)
+	}
+
+	return presets, nil
+}
+
+// GetScreenerPreset returns a saved screener preset by name, or nil if there is none
+func (r *Repository) GetScreenerPreset(ctx context.Context, name string) (*models.ScreenerPreset, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "screener_presets")
+
+	p, err := scanScreenerPreset(r.db.QueryRow(ctx, `SELECT `+screenerPresetColumns+` FROM screener_presets WHERE name = $1`, name))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		metrics.RecordDBError("select", "screener_presets")
+		return nil, fmt.Errorf("failed to get screener preset: %w", err)
+	}
+
+	return p, nil
+}
+
+// SaveScreenerPreset creates a screener preset, or replaces the criteria of the one with its name
+func (r *Repository) SaveScreenerPreset(ctx context.Context, preset *models.ScreenerPreset) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("upsert", "screener_presets")
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO screener_presets (name, description, market_cap_min, market_cap_max, pe_ratio_max, pb_ratio_max,
+			dividend_yield_min, eps_min, sector)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (name) DO UPDATE SET
+			description = EXCLUDED.description,
+			market_cap_min = EXCLUDED.market_cap_min,
+			market_cap_max = EXCLUDED.market_cap_max,
+			pe_ratio_max = EXCLUDED.pe_ratio_max,
+			pb_ratio_max = EXCLUDED.pb_ratio_max,
+			dividend_yield_min = EXCLUDED.dividend_yield_min,
+			eps_min = EXCLUDED.eps_min,
+			sector = EXCLUDED.sector,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`, preset.Name, preset.Description, preset.MarketCapMin, preset.MarketCapMax, preset.PERatioMax, preset.PBRatioMax,
+		preset.DividendYieldMin, preset.EPSMin, preset.Sector).Scan(&preset.CreatedAt, &preset.UpdatedAt)
+	if err != nil {
+		metrics.RecordDBError("upsert", "screener_presets")
+		return fmt.Errorf("failed to save screener preset: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteScreenerPreset removes a saved screener preset, reporting whether it existed
+func (r *Repository) DeleteScreenerPreset(ctx context.Context, name string) (bool, error) {
+	if err := r.checkDB(); err != nil {
+		return false, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("delete", "screener_presets")
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM screener_presets WHERE name = $1`, name)
+	if err != nil {
+		metrics.RecordDBError("delete", "screener_presets")
+		return false, fmt.Errorf("failed to delete screener preset: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
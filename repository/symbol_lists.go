@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// GetSymbolListEntries returns every blocklist and allowlist entry, ordered by list and symbol
+func (r *Repository) GetSymbolListEntries(ctx context.Context) ([]models.SymbolListEntry, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "symbol_lists")
+
+	rows, err := r.db.Query(ctx, `
+		SELECT list_type, symbol, reason, created_at
+		FROM symbol_lists
+		ORDER BY list_type, symbol
+	`)
+	if err != nil {
+		metrics.RecordDBError("select", "symbol_lists")
+		return nil, fmt.Errorf("failed to get symbol lists: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.SymbolListEntry
+	for rows.Next() {
+		var e models.SymbolListEntry
+		if err := rows.Scan(&e.List, &e.Symbol, &e.Reason, &e.CreatedAt); err != nil {
+			metrics.RecordDBError("select", "symbol_lists")
+			return nil, fmt.Errorf("failed to scan symbol list entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// AddSymbolListEntry adds a symbol to a list, updating the reason if it is already there
+func (r *Repository) AddSymbolListEntry(ctx context.Context, entry *models.SymbolListEntry) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "symbol_lists")
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO symbol_lists (list_type, symbol, reason)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (list_type, symbol) DO UPDATE SET reason = EXCLUDED.reason
+		RETURNING created_at
+	`, entry.List, entry.Symbol, entry.Reason).Scan(&entry.CreatedAt)
+	if err != nil {
+		metrics.RecordDBError("insert", "symbol_lists")
+		return fmt.Errorf("failed to add symbol list entry: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveSymbolListEntry removes a symbol from a list; removing an absent symbol is a no-op
+func (r *Repository) RemoveSymbolListEntry(ctx context.Context, list models.SymbolListType, symbol string) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("delete", "symbol_lists")
+
+	_, err := r.db.Exec(ctx, `DELETE FROM symbol_lists WHERE list_type = $1 AND symbol = $2`, list, symbol)
+	if err != nil {
+		metrics.RecordDBError("delete", "symbol_lists")
+		return fmt.Errorf("failed to remove symbol list entry: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"trade-machine/observability"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// statementCommands are the SQL commands statement metrics are labelled with; others are "other"
+var statementCommands = []string{"select", "insert", "update", "delete", "with"}
+
+// statementStartKey is the context key a statement's command and start time are kept under
+type statementStartKey struct{}
+
+type statementStart struct {
+	command string
+	at      time.Time
+}
+
+// statementTracer times every statement the pool runs, including those in transactions and
+// migrations that repository methods don't time themselves
+type statementTracer struct{}
+
+func (statementTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, statementStartKey{}, statementStart{command: statementCommand(data.SQL), at: time.Now()})
+}
+
+func (statementTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(statementStartKey{}).(statementStart)
+	if !ok {
+		return
+	}
+	status := "ok"
+	if data.Err != nil {
+		status = "error"
+	}
+	observability.GetMetrics().RecordDBStatement(start.command, status, time.Since(start.at))
+}
+
+// statementCommand returns the lowercased leading keyword of a SQL statement
+func statementCommand(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "other"
+	}
+	if command := strings.ToLower(fields[0]); slices.Contains(statementCommands, command) {
+		return command
+	}
+	return "other"
+}
@@ -3,11 +3,13 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"trade-machine/models"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
 )
 
 // GetTrades returns trades with optional limit
@@ -20,7 +22,7 @@ func (r *Repository) GetTrades(ctx context.Context, limit int) ([]models.Trade,
 	}
 
 	rows, err := r.db.Query(ctx, `
-		SELECT id, symbol, side, quantity, price, total_value, commission, status, alpaca_order_id, executed_at, created_at
+		SELECT id, symbol, side, quantity, filled_quantity, price, total_value, commission, fees, status, alpaca_order_id, broker, executed_at, created_at
 		FROM trades
 		ORDER BY created_at DESC
 		LIMIT $1
@@ -33,7 +35,7 @@ func (r *Repository) GetTrades(ctx context.Context, limit int) ([]models.Trade,
 	var trades []models.Trade
 	for rows.Next() {
 		var t models.Trade
-		err := rows.Scan(&t.ID, &t.Symbol, &t.Side, &t.Quantity, &t.Price, &t.TotalValue, &t.Commission, &t.Status, &t.AlpacaOrderID, &t.ExecutedAt, &t.CreatedAt)
+		err := rows.Scan(&t.ID, &t.Symbol, &t.Side, &t.Quantity, &t.FilledQuantity, &t.Price, &t.TotalValue, &t.Commission, &t.Fees, &t.Status, &t.AlpacaOrderID, &t.Broker, &t.ExecutedAt, &t.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan trade: %w", err)
 		}
@@ -43,6 +45,36 @@ func (r *Repository) GetTrades(ctx context.Context, limit int) ([]models.Trade,
 	return trades, nil
 }
 
+// GetExecutedTradesBetween returns executed trades with executed_at in [start, end), oldest first
+func (r *Repository) GetExecutedTradesBetween(ctx context.Context, start, end time.Time) ([]models.Trade, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, symbol, side, quantity, filled_quantity, price, total_value, commission, fees, status, alpaca_order_id, broker, executed_at, created_at
+		FROM trades
+		WHERE status = $1 AND executed_at >= $2 AND executed_at < $3
+		ORDER BY executed_at
+	`, models.TradeStatusExecuted, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trades: %w", err)
+	}
+	defer rows.Close()
+
+	var trades []models.Trade
+	for rows.Next() {
+		var t models.Trade
+		err := rows.Scan(&t.ID, &t.Symbol, &t.Side, &t.Quantity, &t.FilledQuantity, &t.Price, &t.TotalValue, &t.Commission, &t.Fees, &t.Status, &t.AlpacaOrderID, &t.Broker, &t.ExecutedAt, &t.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trade: %w", err)
+		}
+		trades = append(trades, t)
+	}
+
+	return trades, rows.Err()
+}
+
 // GetTrade returns a single trade by ID
 func (r *Repository) GetTrade(ctx context.Context, id uuid.UUID) (*models.Trade, error) {
 	if err := r.checkDB(); err != nil {
@@ -50,9 +82,9 @@ func (r *Repository) GetTrade(ctx context.Context, id uuid.UUID) (*models.Trade,
 	}
 	var t models.Trade
 	err := r.db.QueryRow(ctx, `
-		SELECT id, symbol, side, quantity, price, total_value, commission, status, alpaca_order_id, executed_at, created_at
+		SELECT id, symbol, side, quantity, filled_quantity, price, total_value, commission, fees, status, alpaca_order_id, broker, executed_at, created_at
 		FROM trades WHERE id = $1
-	`, id).Scan(&t.ID, &t.Symbol, &t.Side, &t.Quantity, &t.Price, &t.TotalValue, &t.Commission, &t.Status, &t.AlpacaOrderID, &t.ExecutedAt, &t.CreatedAt)
+	`, id).Scan(&t.ID, &t.Symbol, &t.Side, &t.Quantity, &t.FilledQuantity, &t.Price, &t.TotalValue, &t.Commission, &t.Fees, &t.Status, &t.AlpacaOrderID, &t.Broker, &t.ExecutedAt, &t.CreatedAt)
 
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -70,9 +102,9 @@ func (r *Repository) CreateTrade(ctx context.Context, trade *models.Trade) error
 		return err
 	}
 	_, err := r.db.Exec(ctx, `
-		INSERT INTO trades (id, symbol, side, quantity, price, total_value, commission, status, alpaca_order_id, executed_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-	`, trade.ID, trade.Symbol, trade.Side, trade.Quantity, trade.Price, trade.TotalValue, trade.Commission, trade.Status, trade.AlpacaOrderID, trade.ExecutedAt, trade.CreatedAt)
+		INSERT INTO trades (id, symbol, side, quantity, filled_quantity, price, total_value, commission, fees, status, alpaca_order_id, broker, executed_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`, trade.ID, trade.Symbol, trade.Side, trade.Quantity, trade.FilledQuantity, trade.Price, trade.TotalValue, trade.Commission, trade.Fees, trade.Status, trade.AlpacaOrderID, trade.BrokerName(), trade.ExecutedAt, trade.CreatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create trade: %w", err)
@@ -93,6 +125,69 @@ func (r *Repository) UpdateTradeStatus(ctx context.Context, id uuid.UUID, status
 	return nil
 }
 
+// GetUnfilledTrades returns trades submitted to the broker that are pending or partially
+// filled, oldest first
+func (r *Repository) GetUnfilledTrades(ctx context.Context) ([]models.Trade, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, symbol, side, quantity, filled_quantity, price, total_value, commission, fees, status, alpaca_order_id, broker, executed_at, created_at
+		FROM trades
+		WHERE status IN ($1, $2) AND alpaca_order_id <> ''
+		ORDER BY created_at
+	`, models.TradeStatusPending, models.TradeStatusPartiallyFilled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trades: %w", err)
+	}
+	defer rows.Close()
+
+	var trades []models.Trade
+	for rows.Next() {
+		var t models.Trade
+		err := rows.Scan(&t.ID, &t.Symbol, &t.Side, &t.Quantity, &t.FilledQuantity, &t.Price, &t.TotalValue, &t.Commission, &t.Fees, &t.Status, &t.AlpacaOrderID, &t.Broker, &t.ExecutedAt, &t.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trade: %w", err)
+		}
+		trades = append(trades, t)
+	}
+
+	return trades, nil
+}
+
+// RecordTradeFill stores a trade's filled quantity, fill price, fees, status and execution time
+func (r *Repository) RecordTradeFill(ctx context.Context, trade *models.Trade) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(ctx, `
+		UPDATE trades
+		SET filled_quantity = $2, price = $3, total_value = $4, commission = $5, fees = $6, status = $7, executed_at = $8
+		WHERE id = $1
+	`, trade.ID, trade.FilledQuantity, trade.Price, trade.TotalValue, trade.Commission, trade.Fees, trade.Status, trade.ExecutedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record trade fill: %w", err)
+	}
+	return nil
+}
+
+// GetTotalFees returns the commissions and fees paid across all executed trades and those
+// still filling
+func (r *Repository) GetTotalFees(ctx context.Context) (decimal.Decimal, error) {
+	if err := r.checkDB(); err != nil {
+		return decimal.Zero, err
+	}
+	var total decimal.Decimal
+	err := r.db.QueryRow(ctx, `
+		SELECT COALESCE(SUM(commission + fees), 0) FROM trades WHERE status IN ($1, $2)
+	`, models.TradeStatusExecuted, models.TradeStatusPartiallyFilled).Scan(&total)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to sum trade fees: %w", err)
+	}
+	return total, nil
+}
+
 // GetTradesBySymbol returns trades for a specific symbol
 func (r *Repository) GetTradesBySymbol(ctx context.Context, symbol string, limit int) ([]models.Trade, error) {
 	if err := r.checkDB(); err != nil {
@@ -103,7 +198,7 @@ func (r *Repository) GetTradesBySymbol(ctx context.Context, symbol string, limit
 	}
 
 	rows, err := r.db.Query(ctx, `
-		SELECT id, symbol, side, quantity, price, total_value, commission, status, alpaca_order_id, executed_at, created_at
+		SELECT id, symbol, side, quantity, filled_quantity, price, total_value, commission, fees, status, alpaca_order_id, broker, executed_at, created_at
 		FROM trades
 		WHERE symbol = $1
 		ORDER BY created_at DESC
@@ -117,7 +212,7 @@ func (r *Repository) GetTradesBySymbol(ctx context.Context, symbol string, limit
 	var trades []models.Trade
 	for rows.Next() {
 		var t models.Trade
-		err := rows.Scan(&t.ID, &t.Symbol, &t.Side, &t.Quantity, &t.Price, &t.TotalValue, &t.Commission, &t.Status, &t.AlpacaOrderID, &t.ExecutedAt, &t.CreatedAt)
+		err := rows.Scan(&t.ID, &t.Symbol, &t.Side, &t.Quantity, &t.FilledQuantity, &t.Price, &t.TotalValue, &t.Commission, &t.Fees, &t.Status, &t.AlpacaOrderID, &t.Broker, &t.ExecutedAt, &t.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan trade: %w", err)
 		}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// trancheColumns is the column list read by collectTranches; t is the tranche's trade, if any
+const trancheColumns = `rt.id, rt.recommendation_id, rt.sequence, rt.quantity, rt.trigger_price, rt.scheduled_at,
+	rt.status, rt.trade_id, COALESCE(rt.error, ''), rt.submitted_at, rt.created_at, t.status, t.price`
+
+// CreateRecommendationTranches stores the child orders of a split plan. Call it in the
+// same unit of work as the approval so a plan never exists without it.
+func (r *Repository) CreateRecommendationTranches(ctx context.Context, tranches []models.RecommendationTranche) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "recommendation_tranches")
+
+	for _, t := range tranches {
+		_, err := r.db.Exec(ctx, `
+			INSERT INTO recommendation_tranches (id, recommendation_id, sequence, quantity, trigger_price, scheduled_at, status, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, t.ID, t.RecommendationID, t.Sequence, t.Quantity, t.TriggerPrice, t.ScheduledAt, t.Status, t.CreatedAt)
+		if err != nil {
+			metrics.RecordDBError("insert", "recommendation_tranches")
+			return fmt.Errorf("failed to create recommendation tranche: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetRecommendationTranches returns a recommendation's split plan in sequence order, with
+// each submitted tranche's trade status and fill price. It is empty when the recommendation
+// was not split.
+func (r *Repository) GetRecommendationTranches(ctx context.Context, recID uuid.UUID) ([]models.RecommendationTranche, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "recommendation_tranches")
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+trancheColumns+`
+		FROM recommendation_tranches rt
+		LEFT JOIN trades t ON t.id = rt.trade_id
+		WHERE rt.recommendation_id = $1
+		ORDER BY rt.sequence
+	`, recID)
+	if err != nil {
+		metrics.RecordDBError("select", "recommendation_tranches")
+		return nil, fmt.Errorf("failed to query recommendation tranches: %w", err)
+	}
+	return collectTranches(rows, metrics)
+}
+
+// GetPendingTranches returns the tranches still waiting to execute across every approved
+// recommendation, grouped by recommendation in sequence order. Tranches of recommendations
+// that were since rejected are left out.
+func (r *Repository) GetPendingTranches(ctx context.Context) ([]models.RecommendationTranche, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "recommendation_tranches")
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+trancheColumns+`
+		FROM recommendation_tranches rt
+		JOIN recommendations rec ON rec.id = rt.recommendation_id
+		LEFT JOIN trades t ON t.id = rt.trade_id
+		WHERE rt.status = 'pending' AND rec.status = 'approved'
+		ORDER BY rt.recommendation_id, rt.sequence
+	`)
+	if err != nil {
+		metrics.RecordDBError("select", "recommendation_tranches")
+		return nil, fmt.Errorf("failed to query pending tranches: %w", err)
+	}
+	return collectTranches(rows, metrics)
+}
+
+// collectTranches scans and closes rows of trancheColumns
+func collectTranches(rows pgx.Rows, metrics *observability.Metrics) ([]models.RecommendationTranche, error) {
+	defer rows.Close()
+
+	var tranches []models.RecommendationTranche
+	for rows.Next() {
+		var t models.RecommendationTranche
+		var tradeStatus *models.TradeStatus
+		var fillPrice *decimal.Decimal
+		if err := rows.Scan(&t.ID, &t.RecommendationID, &t.Sequence, &t.Quantity, &t.TriggerPrice, &t.ScheduledAt,
+			&t.Status, &t.TradeID, &t.Error, &t.SubmittedAt, &t.CreatedAt, &tradeStatus, &fillPrice); err != nil {
+			metrics.RecordDBError("select", "recommendation_tranches")
+			return nil, fmt.Errorf("failed to scan recommendation tranche: %w", err)
+		}
+		if tradeStatus != nil {
+			t.TradeStatus = *tradeStatus
+		}
+		if fillPrice != nil && t.TradeStatus == models.TradeStatusExecuted {
+			t.FillPrice = *fillPrice
+		}
+		tranches = append(tranches, t)
+	}
+
+	return tranches, rows.Err()
+}
+
+// ClaimTranche marks a pending tranche as submitting before its order is placed, so a
+// tranche picked up twice fails here instead of placing another order. Returns
+// models.ErrRecommendationNotExecutable if the tranche is no longer pending.
+func (r *Repository) ClaimTranche(ctx context.Context, id uuid.UUID) error {
+	return r.moveTrancheStatus(ctx, id, models.TrancheStatusPending, models.TrancheStatusSubmitting)
+}
+
+// ReleaseTranche returns a claimed tranche to pending when its order could not be sent, with
+// the same check as ClaimTranche
+func (r *Repository) ReleaseTranche(ctx context.Context, id uuid.UUID) error {
+	return r.moveTrancheStatus(ctx, id, models.TrancheStatusSubmitting, models.TrancheStatusPending)
+}
+
+// moveTrancheStatus changes a tranche's status from one state to another
+func (r *Repository) moveTrancheStatus(ctx context.Context, id uuid.UUID, from, to models.TrancheStatus) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("update", "recommendation_tranches")
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE recommendation_tranches SET status = $3 WHERE id = $1 AND status = $2
+	`, id, from, to)
+	if err != nil {
+		metrics.RecordDBError("update", "recommendation_tranches")
+		return fmt.Errorf("failed to mark tranche %s: %w", to, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: tranche %s is no longer %s", models.ErrRecommendationNotExecutable, id, from)
+	}
+
+	return nil
+}
+
+// MarkTrancheSubmitted records the trade placed for a claimed tranche. Returns
+// models.ErrRecommendationNotExecutable if the tranche is not submitting, so a tranche
+// picked up twice can't record two orders.
+func (r *Repository) MarkTrancheSubmitted(ctx context.Context, id uuid.UUID, tradeID uuid.UUID) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("update", "recommendation_tranches")
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE recommendation_tranches
+		SET status = 'submitted', trade_id = $2, submitted_at = $3
+		WHERE id = $1 AND status = 'submitting'
+	`, id, tradeID, time.Now())
+	if err != nil {
+		metrics.RecordDBError("update", "recommendation_tranches")
+		return fmt.Errorf("failed to mark tranche submitted: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: tranche %s is not submitting", models.ErrRecommendationNotExecutable, id)
+	}
+
+	return nil
+}
+
+// MarkTrancheFailed records why a claimed tranche's order was not placed
+func (r *Repository) MarkTrancheFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("update", "recommendation_tranches")
+
+	_, err := r.db.Exec(ctx, `
+		UPDATE recommendation_tranches
+		SET status = 'failed', error = $2
+		WHERE id = $1 AND status = 'submitting'
+	`, id, reason)
+	if err != nil {
+		metrics.RecordDBError("update", "recommendation_tranches")
+		return fmt.Errorf("failed to mark tranche failed: %w", err)
+	}
+
+	return nil
+}
+
+// CancelPendingTranches cancels a recommendation's tranches that have not executed yet,
+// returning how many were cancelled
+func (r *Repository) CancelPendingTranches(ctx context.Context, recID uuid.UUID) (int64, error) {
+	if err := r.checkDB(); err != nil {
+		return 0, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("update", "recommendation_tranches")
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE recommendation_tranches
+		SET status = 'cancelled'
+		WHERE recommendation_id = $1 AND status = 'pending'
+	`, recID)
+	if err != nil {
+		metrics.RecordDBError("update", "recommendation_tranches")
+		return 0, fmt.Errorf("failed to cancel tranches: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
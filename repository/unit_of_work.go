@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// UnitOfWork runs fn against a Repository bound to a single transaction. The transaction
+// commits if fn returns nil and rolls back if it returns an error or panics.
+// Called on a Repository that is already inside a transaction, fn joins that transaction
+// and the outer caller stays responsible for committing.
+func (r *Repository) UnitOfWork(ctx context.Context, fn func(tx RepositoryInterface) error) (err error) {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	if _, inTx := r.db.(pgx.Tx); inTx {
+		return fn(r)
+	}
+
+	tx, txRepo, err := r.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil {
+				logger.Warn("failed to roll back transaction", "error", rbErr)
+			}
+		}
+	}()
+
+	if err = fn(txRepo); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// SaveWatchlist stores a watchlist
+func (r *Repository) SaveWatchlist(ctx context.Context, watchlist *models.Watchlist) error {
+	if err := r.checkDB(); err != nil {
+		return err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("insert", "watchlists")
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO watchlists (id, name, symbols, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, watchlist.ID, watchlist.Name, watchlist.Symbols, watchlist.CreatedAt)
+	if err != nil {
+		metrics.RecordDBError("insert", "watchlists")
+		return fmt.Errorf("failed to save watchlist: %w", err)
+	}
+
+	return nil
+}
+
+// GetWatchlists returns every watchlist, newest first
+func (r *Repository) GetWatchlists(ctx context.Context) ([]models.Watchlist, error) {
+	if err := r.checkDB(); err != nil {
+		return nil, err
+	}
+	metrics := observability.GetMetrics()
+	timer := metrics.NewTimer()
+	defer timer.ObserveDB("select", "watchlists")
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, name, symbols, created_at
+		FROM watchlists
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		metrics.RecordDBError("select", "watchlists")
+		return nil, fmt.Errorf("failed to get watchlists: %w", err)
+	}
+	defer rows.Close()
+
+	var watchlists []models.Watchlist
+	for rows.Next() {
+		var w models.Watchlist
+		if err := rows.Scan(&w.ID, &w.Name, &w.Symbols, &w.CreatedAt); err != nil {
+			metrics.RecordDBError("select", "watchlists")
+			return nil, fmt.Errorf("failed to scan watchlist: %w", err)
+		}
+		watchlists = append(watchlists, w)
+	}
+
+	return watchlists, nil
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+const (
+	// writeBufferFlushInterval is how often buffered writes are flushed when nothing wakes the buffer
+	writeBufferFlushInterval = 5 * time.Second
+	// writeBufferMaxBackoff caps the wait between flushes while the database keeps failing
+	writeBufferMaxBackoff = time.Minute
+	// writeBufferMaxAttempts is how many times a write is tried before it is dead-lettered.
+	// With the backoff that is about seven minutes of failures.
+	writeBufferMaxAttempts = 10
+)
+
+// dropLogger reports dropped writes, sampled since a full buffer drops one per enqueue
+var dropLogger = observability.Sampled(logger, 50)
+
+// bufferedWrite is one pending write and the kind of record it stores, for logs and
+// metrics. Attempts is only touched by Flush.
+type bufferedWrite struct {
+	kind     string
+	write    func(ctx context.Context) error
+	attempts int
+}
+
+// WriteBuffer holds non-critical writes in memory and flushes them in order in the
+// background, retrying with backoff while the database is unreachable. A write that fails
+// writeBufferMaxAttempts times, such as one the database rejects outright, is
+// dead-lettered: logged, counted and removed so the writes behind it go through. When the
+// buffer is full the oldest write is dropped. Writes whose loss would corrupt state, such
+// as recommendations and trades, must not go through it.
+type WriteBuffer struct {
+	mu       sync.Mutex
+	pending  []*bufferedWrite
+	dropped  int // Writes dropped on overflow so far, to realign a flush that overlapped drops
+	capacity int
+	wake     chan struct{}
+}
+
+// NewWriteBuffer creates a buffer holding at most capacity writes
+func NewWriteBuffer(capacity int) *WriteBuffer {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &WriteBuffer{
+		capacity: capacity,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Enqueue adds a write to the buffer, dropping the oldest pending write if it is full
+func (b *WriteBuffer) Enqueue(kind string, write func(ctx context.Context) error) {
+	metrics := observability.GetMetrics()
+
+	b.mu.Lock()
+	if len(b.pending) >= b.capacity {
+		dropped := b.pending[0]
+		b.pending = b.pending[1:]
+		b.dropped++
+		metrics.RecordWriteBufferDrop(dropped.kind)
+		dropLogger.Warn("write buffer full, dropping oldest write", "kind", dropped.kind, "capacity", b.capacity)
+	}
+	b.pending = append(b.pending, &bufferedWrite{kind: kind, write: write})
+	depth := len(b.pending)
+	b.mu.Unlock()
+
+	metrics.SetWriteBufferDepth(depth)
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Depth returns the number of writes waiting to be flushed
+func (b *WriteBuffer) Depth() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending)
+}
+
+// Flush writes pending writes in order, stopping at the first failure so the failed write
+// and everything after it are retried later. A write failing its last attempt is
+// dead-lettered instead, and the flush carries on with the next.
+func (b *WriteBuffer) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	batch := slices.Clone(b.pending)
+	droppedBefore := b.dropped
+	b.mu.Unlock()
+
+	finished := 0
+	var err error
+	for _, w := range batch {
+		if err = w.write(ctx); err != nil {
+			w.attempts++
+			if w.attempts < writeBufferMaxAttempts || ctx.Err() != nil {
+				logger.Warn("buffered write failed, will retry", "kind", w.kind, "attempt", w.attempts, "pending", len(batch)-finished, "error", err)
+				break
+			}
+			logger.Error("buffered write failed every attempt, dead-lettering it", "kind", w.kind, "attempts", w.attempts, "error", err)
+			observability.GetMetrics().RecordWriteBufferDeadLetter(w.kind)
+			err = nil
+		}
+		finished++
+	}
+
+	b.mu.Lock()
+	// Writes dropped on overflow while flushing came off the front of the batch
+	done := max(finished-(b.dropped-droppedBefore), 0)
+	b.pending = b.pending[min(done, len(b.pending)):]
+	depth := len(b.pending)
+	b.mu.Unlock()
+
+	observability.GetMetrics().SetWriteBufferDepth(depth)
+	return err
+}
+
+// Run flushes the buffer as writes arrive until ctx is cancelled, then makes a final
+// attempt to write what is left. Failed flushes back off exponentially up to a minute,
+// during which new writes wait rather than triggering another attempt.
+func (b *WriteBuffer) Run(ctx context.Context) {
+	backoff := writeBufferFlushInterval
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	failing := false
+	for {
+		select {
+		case <-b.wake:
+			if failing {
+				continue
+			}
+		case <-timer.C:
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			if err := b.Flush(shutdownCtx); err != nil {
+				logger.Warn("write buffer not flushed on shutdown", "pending", b.Depth(), "error", err)
+			}
+			cancel()
+			return
+		}
+
+		if err := b.Flush(ctx); err != nil {
+			failing = true
+			backoff = min(backoff*2, writeBufferMaxBackoff)
+		} else {
+			failing = false
+			backoff = writeBufferFlushInterval
+		}
+		timer.Reset(backoff)
+	}
+}
+
+// BufferedRepository routes non-critical writes (agent runs, LLM usage, API call ledger
+// batches and audit entries) through a WriteBuffer so a database blip delays them instead
+// of losing them. All other operations go straight to the repository.
+type BufferedRepository struct {
+	*Repository
+	buffer *WriteBuffer
+}
+
+// NewBufferedRepository wraps a repository so its non-critical writes are buffered
+func NewBufferedRepository(repo *Repository, buffer *WriteBuffer) *BufferedRepository {
+	return &BufferedRepository{Repository: repo, buffer: buffer}
+}
+
+// CreateAgentRun queues the agent run insert. The run is copied, so later changes are
+// written by UpdateAgentRun, which is queued behind it.
+func (r *BufferedRepository) CreateAgentRun(ctx context.Context, run *models.AgentRun) error {
+	snapshot := *run
+	r.buffer.Enqueue("agent_run", func(ctx context.Context) error {
+		return r.Repository.CreateAgentRun(ctx, &snapshot)
+	})
+	return nil
+}
+
+// UpdateAgentRun queues the agent run update
+func (r *BufferedRepository) UpdateAgentRun(ctx context.Context, run *models.AgentRun) error {
+	snapshot := *run
+	r.buffer.Enqueue("agent_run", func(ctx context.Context) error {
+		return r.Repository.UpdateAgentRun(ctx, &snapshot)
+	})
+	return nil
+}
+
+// SaveAPICalls queues a batch of ledger calls. The batch is copied since the ledger
+// reuses its slice.
+func (r *BufferedRepository) SaveAPICalls(ctx context.Context, calls []models.APICall) error {
+	batch := slices.Clone(calls)
+	r.buffer.Enqueue("api_calls", func(ctx context.Context) error {
+		return r.Repository.SaveAPICalls(ctx, batch)
+	})
+	return nil
+}
+
+// SaveLLMUsage queues an agent run's LLM usage behind the run itself
+func (r *BufferedRepository) SaveLLMUsage(ctx context.Context, usage []models.LLMUsage) error {
+	batch := slices.Clone(usage)
+	r.buffer.Enqueue("llm_usage", func(ctx context.Context) error {
+		return r.Repository.SaveLLMUsage(ctx, batch)
+	})
+	return nil
+}
+
+// CreateAuditEntry queues the audit entry, so an audited action isn't failed or left
+// unrecorded by a database blip
+func (r *BufferedRepository) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	snapshot := *entry
+	r.buffer.Enqueue("audit_entry", func(ctx context.Context) error {
+		return r.Repository.CreateAuditEntry(ctx, &snapshot)
+	})
+	return nil
+}
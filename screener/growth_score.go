@@ -0,0 +1,144 @@
+package screener
+
+import (
+	"context"
+	"sort"
+
+	"trade-machine/models"
+)
+
+// Growth score weights: 40% revenue growth, 40% EPS growth, 20% relative strength
+const (
+	revenueGrowthWeight    = 0.4
+	epsGrowthWeight        = 0.4
+	relativeStrengthWeight = 0.2
+)
+
+// GrowthScoreFormula describes how RankByGrowthScore scores a candidate
+const GrowthScoreFormula = "0.4 × min(100, 2×RevenueGrowth%) + 0.4 × min(100, 2×EPSGrowth%) + 0.2 × RelativeStrength"
+
+// growthComponentScore normalizes annual growth to 0-100: shrinking scores 0, 50% growth
+// or more scores 100
+func growthComponentScore(growth *float64) float64 {
+	if growth == nil {
+		return 0
+	}
+	return clampScore(*growth * 100 * 2)
+}
+
+// growthScoreComponents returns the weighted inputs that make up a candidate's growth
+// score. Relative strength is the candidate's six-month price change percentile within
+// the run, so it is passed in rather than derived from the candidate alone.
+func growthScoreComponents(c models.ScreenerCandidate, relativeStrength float64) []models.ScoreComponent {
+	revenueScore := growthComponentScore(c.RevenueGrowth)
+	epsScore := growthComponentScore(c.EPSGrowth)
+
+	var revenue, eps, priceChange float64
+	if c.RevenueGrowth != nil {
+		revenue = *c.RevenueGrowth * 100
+	}
+	if c.EPSGrowth != nil {
+		eps = *c.EPSGrowth * 100
+	}
+	if c.PriceChange6M != nil {
+		priceChange = *c.PriceChange6M
+	}
+
+	return []models.ScoreComponent{
+		{Name: "Revenue Growth %", Value: revenue, Score: revenueScore, Weight: revenueGrowthWeight, Contribution: revenueScore * revenueGrowthWeight},
+		{Name: "EPS Growth %", Value: eps, Score: epsScore, Weight: epsGrowthWeight, Contribution: epsScore * epsGrowthWeight},
+		{Name: "6M Price Change %", Value: priceChange, Score: relativeStrength, Weight: relativeStrengthWeight, Contribution: relativeStrength * relativeStrengthWeight},
+	}
+}
+
+// relativeStrengths returns each candidate's six-month price change percentile (0-100)
+// among the candidates with a known change. Candidates without one score 0.
+func relativeStrengths(candidates []models.ScreenerCandidate) []float64 {
+	strengths := make([]float64, len(candidates))
+	var known []float64
+	for _, c := range candidates {
+		if c.PriceChange6M != nil {
+			known = append(known, *c.PriceChange6M)
+		}
+	}
+	for i, c := range candidates {
+		if c.PriceChange6M == nil {
+			continue
+		}
+		if len(known) < 2 {
+			strengths[i] = 100
+			continue
+		}
+		below := 0
+		for _, other := range known {
+			if other < *c.PriceChange6M {
+				below++
+			}
+		}
+		strengths[i] = float64(below) / float64(len(known)-1) * 100
+	}
+	return strengths
+}
+
+// RankByGrowthScore sorts candidates by their growth score in descending order and
+// returns the top N candidates. The growth score is recorded as the candidate's
+// pre-filter ValueScore, so growth runs rank and display like value runs.
+func RankByGrowthScore(candidates []models.ScreenerCandidate, topN int) []models.ScreenerCandidate {
+	if len(candidates) == 0 {
+		return candidates
+	}
+
+	percentiles := marketCapPercentiles(candidates)
+	strengths := relativeStrengths(candidates)
+	for i := range candidates {
+		components := growthScoreComponents(candidates[i], strengths[i])
+		var total float64
+		for _, component := range components {
+			total += component.Contribution
+		}
+		candidates[i].ValueScore = total
+		candidates[i].ScoreBreakdown = &models.ScoreBreakdown{
+			Components:          components,
+			MarketCapPercentile: percentiles[i],
+			ValueFormula:        GrowthScoreFormula,
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].ValueScore > candidates[j].ValueScore
+	})
+
+	if topN > 0 && topN < len(candidates) {
+		return candidates[:topN]
+	}
+	return candidates
+}
+
+// loadGrowthMetrics fills in each candidate's revenue and EPS growth and six-month price
+// change. A candidate whose metrics can't be fetched is kept and scores 0 on them.
+func (s *ValueScreener) loadGrowthMetrics(ctx context.Context, candidates []models.ScreenerCandidate) {
+	for i := range candidates {
+		if ctx.Err() != nil {
+			return
+		}
+		metrics, err := s.fmpService.GetGrowthMetrics(ctx, candidates[i].Symbol)
+		if err != nil || metrics == nil {
+			logger.Warn("growth metrics unavailable",
+				"symbol", candidates[i].Symbol,
+				"error", err)
+			continue
+		}
+		candidates[i].RevenueGrowth = &metrics.RevenueGrowth
+		candidates[i].EPSGrowth = &metrics.EPSGrowth
+		candidates[i].PriceChange6M = &metrics.PriceChange6M
+	}
+}
+
+// rankForPreset pre-filter scores candidates with the preset's formula, best first
+func (s *ValueScreener) rankForPreset(ctx context.Context, filters models.ScreenerFilters, candidates []models.ScreenerCandidate) []models.ScreenerCandidate {
+	if !filters.IsGrowth() {
+		return RankByValueScore(candidates, 0)
+	}
+	s.loadGrowthMetrics(ctx, candidates)
+	return RankByGrowthScore(candidates, 0)
+}
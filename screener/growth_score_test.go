@@ -0,0 +1,76 @@
+package screener
+
+import (
+	"math"
+	"testing"
+
+	"trade-machine/models"
+)
+
+func growthCandidate(symbol string, revenue, eps, priceChange float64) models.ScreenerCandidate {
+	return models.ScreenerCandidate{Symbol: symbol, RevenueGrowth: &revenue, EPSGrowth: &eps, PriceChange6M: &priceChange}
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestGrowthComponentScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		growth *float64
+		want   float64
+	}{
+		{"missing", nil, 0},
+		{"shrinking", floatPtr(-0.1), 0},
+		{"25% growth", floatPtr(0.25), 50},
+		{"capped", floatPtr(1.5), 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := growthComponentScore(tt.growth); math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("growthComponentScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelativeStrengths(t *testing.T) {
+	candidates := []models.ScreenerCandidate{
+		growthCandidate("A", 0, 0, 10),
+		growthCandidate("B", 0, 0, 40),
+		{Symbol: "C"},
+		growthCandidate("D", 0, 0, -5),
+	}
+
+	got := relativeStrengths(candidates)
+	want := []float64{50, 100, 0, 0}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("relativeStrengths()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRankByGrowthScore(t *testing.T) {
+	candidates := []models.ScreenerCandidate{
+		growthCandidate("SLOW", 0.02, 0.01, -8),
+		growthCandidate("FAST", 0.45, 0.60, 35),
+		{Symbol: "NODATA"},
+		growthCandidate("MID", 0.20, 0.15, 12),
+	}
+
+	ranked := RankByGrowthScore(candidates, 3)
+
+	if len(ranked) != 3 {
+		t.Fatalf("got %d candidates, want the top 3", len(ranked))
+	}
+	if ranked[0].Symbol != "FAST" || ranked[1].Symbol != "MID" || ranked[2].Symbol != "SLOW" {
+		t.Errorf("order = %s, %s, %s, want FAST, MID, SLOW", ranked[0].Symbol, ranked[1].Symbol, ranked[2].Symbol)
+	}
+	// 0.4 × 90 + 0.4 × 100 + 0.2 × 100
+	if math.Abs(ranked[0].ValueScore-96) > 0.001 {
+		t.Errorf("FAST score = %v, want 96", ranked[0].ValueScore)
+	}
+	if b := ranked[0].ScoreBreakdown; b == nil || b.ValueFormula != GrowthScoreFormula || len(b.Components) != 3 {
+		t.Errorf("breakdown = %+v, want the growth formula's three components", b)
+	}
+}
@@ -0,0 +1,67 @@
+package screener
+
+import (
+	"context"
+	"time"
+
+	"trade-machine/models"
+)
+
+// ipoDateLayout is the format FMP uses for profile IPO dates
+const ipoDateLayout = "2006-01-02"
+
+// screenListingAge takes up to limit candidates from the ranked list, checking each one's
+// IPO date against the minimum listing age. Recent listings are dropped (so the next-ranked
+// candidate takes their place) or, in flag mode, kept and marked. Candidates whose IPO date
+// cannot be determined are kept.
+func (s *ValueScreener) screenListingAge(ctx context.Context, ranked []models.ScreenerCandidate, minMonths, limit int) []models.ScreenerCandidate {
+	if minMonths <= 0 {
+		if limit > 0 && limit < len(ranked) {
+			return ranked[:limit]
+		}
+		return ranked
+	}
+
+	cutoff := time.Now().AddDate(0, -minMonths, 0)
+	flagOnly := s.cfg.RecentListingMode == "flag"
+
+	kept := make([]models.ScreenerCandidate, 0, min(limit, len(ranked)))
+	for _, c := range ranked {
+		if limit > 0 && len(kept) >= limit {
+			break
+		}
+
+		ipoDate, ok := s.ipoDate(ctx, c.Symbol)
+		if ok {
+			c.IPODate = &ipoDate
+			c.RecentListing = ipoDate.After(cutoff)
+		}
+		if c.RecentListing && !flagOnly {
+			logger.Info("excluding recent listing",
+				"symbol", c.Symbol,
+				"ipo_date", ipoDate.Format(ipoDateLayout),
+				"min_listing_months", minMonths)
+			continue
+		}
+		kept = append(kept, c)
+	}
+	return kept
+}
+
+// ipoDate looks up a symbol's IPO date from its company profile
+func (s *ValueScreener) ipoDate(ctx context.Context, symbol string) (time.Time, bool) {
+	profile, err := s.fmpService.GetCompanyProfile(ctx, symbol)
+	if err != nil {
+		logger.Warn("failed to fetch profile for listing age", "symbol", symbol, "error", err)
+		return time.Time{}, false
+	}
+	if profile == nil || profile.IPODate == "" {
+		return time.Time{}, false
+	}
+	date, err := time.Parse(ipoDateLayout, profile.IPODate)
+	if err != nil {
+		logger.Warn("unparseable IPO date", "symbol", symbol, "ipo_date", profile.IPODate)
+		return time.Time{}, false
+	}
+	return date, true
+}
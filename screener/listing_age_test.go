@@ -0,0 +1,87 @@
+package screener
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+	"trade-machine/services"
+)
+
+func listingAgeScreener(mode string, ipoDates map[string]string) *ValueScreener {
+	fmp := &MockFMPService{
+		GetCompanyProfileFunc: func(ctx context.Context, symbol string) (*services.CompanyProfile, error) {
+			date, ok := ipoDates[symbol]
+			if !ok {
+				return nil, errors.New("profile unavailable")
+			}
+			return &services.CompanyProfile{Symbol: symbol, IPODate: date}, nil
+		},
+	}
+	cfg := &config.ScreenerConfig{RecentListingMode: mode}
+	return NewValueScreener(fmp, &MockAnalysisProvider{}, &MockScreenerRepository{}, cfg)
+}
+
+func TestScreenListingAge_ExcludesRecentListings(t *testing.T) {
+	recent := time.Now().AddDate(0, -3, 0).Format(ipoDateLayout)
+	s := listingAgeScreener("exclude", map[string]string{
+		"OLD":   "1999-05-20",
+		"FRESH": recent,
+		"NEXT":  "2010-01-04",
+	})
+	ranked := []models.ScreenerCandidate{{Symbol: "OLD"}, {Symbol: "FRESH"}, {Symbol: "NEXT"}, {Symbol: "LAST"}}
+
+	got := s.screenListingAge(context.Background(), ranked, 12, 2)
+	if len(got) != 2 || got[0].Symbol != "OLD" || got[1].Symbol != "NEXT" {
+		t.Fatalf("got %v, want OLD then NEXT backfilling the excluded listing", symbols(got))
+	}
+	if got[0].IPODate == nil || got[0].RecentListing {
+		t.Errorf("OLD = %+v, want IPO date set and not flagged", got[0])
+	}
+}
+
+func TestScreenListingAge_FlagMode(t *testing.T) {
+	recent := time.Now().AddDate(0, -1, 0).Format(ipoDateLayout)
+	s := listingAgeScreener("flag", map[string]string{"FRESH": recent})
+
+	got := s.screenListingAge(context.Background(), []models.ScreenerCandidate{{Symbol: "FRESH"}}, 6, 5)
+	if len(got) != 1 || !got[0].RecentListing {
+		t.Errorf("got %+v, want FRESH kept and flagged", got)
+	}
+}
+
+func TestScreenListingAge_UnknownIPODateKept(t *testing.T) {
+	s := listingAgeScreener("exclude", map[string]string{"BLANK": ""})
+	ranked := []models.ScreenerCandidate{{Symbol: "BLANK"}, {Symbol: "MISSING"}}
+
+	got := s.screenListingAge(context.Background(), ranked, 12, 5)
+	if len(got) != 2 {
+		t.Errorf("got %v, want candidates with unknown IPO dates kept", symbols(got))
+	}
+}
+
+func TestScreenListingAge_DisabledSkipsProfiles(t *testing.T) {
+	s := listingAgeScreener("exclude", nil)
+	s.fmpService = &MockFMPService{
+		GetCompanyProfileFunc: func(ctx context.Context, symbol string) (*services.CompanyProfile, error) {
+			t.Fatal("profile should not be fetched when the minimum listing age is disabled")
+			return nil, nil
+		},
+	}
+	ranked := []models.ScreenerCandidate{{Symbol: "A"}, {Symbol: "B"}, {Symbol: "C"}}
+
+	if got := s.screenListingAge(context.Background(), ranked, 0, 2); len(got) != 2 {
+		t.Errorf("got %d candidates, want 2", len(got))
+	}
+}
+
+func symbols(candidates []models.ScreenerCandidate) []string {
+	out := make([]string, len(candidates))
+	for i, c := range candidates {
+		out[i] = c.Symbol
+	}
+	return out
+}
@@ -0,0 +1,49 @@
+package screener
+
+import (
+	"sync/atomic"
+
+	"trade-machine/events"
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+)
+
+// runProgress publishes candidate progress for one analysis pass of a screener run, so
+// clients can show how far the run has got
+type runProgress struct {
+	runID     uuid.UUID
+	total     int
+	completed atomic.Int32
+}
+
+func newRunProgress(runID uuid.UUID, total int) *runProgress {
+	return &runProgress{runID: runID, total: total}
+}
+
+// started reports that a candidate's analysis has begun
+func (p *runProgress) started(c models.ScreenerCandidate) {
+	events.Publish(events.ScreenerCandidateStarted, p.progress(c, int(p.completed.Load())))
+}
+
+// scored reports that a candidate was analyzed, with its combined score
+func (p *runProgress) scored(c models.ScreenerCandidate) {
+	events.Publish(events.ScreenerCandidateScored, p.progress(c, int(p.completed.Add(1))))
+}
+
+// failed reports that a candidate's analysis failed, with its error
+func (p *runProgress) failed(c models.ScreenerCandidate) {
+	progress := p.progress(c, int(p.completed.Add(1)))
+	progress.Error = c.AnalysisError
+	events.Publish(events.ScreenerCandidateFailed, progress)
+}
+
+func (p *runProgress) progress(c models.ScreenerCandidate, completed int) events.CandidateProgress {
+	return events.CandidateProgress{
+		RunID:     p.runID,
+		Symbol:    c.Symbol,
+		Score:     c.Score,
+		Completed: completed,
+		Total:     p.total,
+	}
+}
@@ -0,0 +1,154 @@
+package screener
+
+import (
+	"math"
+	"sort"
+
+	"trade-machine/config"
+	"trade-machine/models"
+)
+
+// rankingPresets are the built-in ranking formulas, keyed by strategy
+var rankingPresets = map[string][]models.RankingWeight{
+	// Agent conviction first, nudged by data quality and valuation
+	"default": {
+		{Component: models.RankComponentScore, Weight: 0.5},
+		{Component: models.RankComponentConfidence, Weight: 0.3},
+		{Component: models.RankComponentCompleteness, Weight: 0.1},
+		{Component: models.RankComponentMarginOfSafety, Weight: 0.1},
+	},
+	// Favors well-covered, easily traded picks over raw score
+	"conservative": {
+		{Component: models.RankComponentScore, Weight: 0.3},
+		{Component: models.RankComponentConfidence, Weight: 0.3},
+		{Component: models.RankComponentCompleteness, Weight: 0.2},
+		{Component: models.RankComponentMarginOfSafety, Weight: 0.1},
+		{Component: models.RankComponentLiquidity, Weight: 0.1},
+	},
+	// Rides the strongest agent signals
+	"aggressive": {
+		{Component: models.RankComponentScore, Weight: 0.7},
+		{Component: models.RankComponentConfidence, Weight: 0.2},
+		{Component: models.RankComponentMarginOfSafety, Weight: 0.1},
+	},
+	// Puts the discount to target price first
+	"value": {
+		{Component: models.RankComponentScore, Weight: 0.3},
+		{Component: models.RankComponentConfidence, Weight: 0.2},
+		{Component: models.RankComponentCompleteness, Weight: 0.1},
+		{Component: models.RankComponentMarginOfSafety, Weight: 0.4},
+	},
+}
+
+// rankComponentOrder is the order custom weights are listed in a formula
+var rankComponentOrder = []models.RankComponent{
+	models.RankComponentScore,
+	models.RankComponentConfidence,
+	models.RankComponentCompleteness,
+	models.RankComponentMarginOfSafety,
+	models.RankComponentLiquidity,
+}
+
+// RankingFormulaFor returns the ranking formula for the configured strategy.
+// Unknown strategies fall back to the default preset.
+func RankingFormulaFor(cfg *config.ScreenerConfig) models.RankingFormula {
+	if cfg.RankingStrategy == "custom" {
+		formula := models.RankingFormula{Strategy: cfg.RankingStrategy}
+		for _, component := range rankComponentOrder {
+			if w, ok := cfg.RankingWeights[string(component)]; ok {
+				formula.Weights = append(formula.Weights, models.RankingWeight{Component: component, Weight: w})
+			}
+		}
+		return formula
+	}
+
+	if weights, ok := rankingPresets[cfg.RankingStrategy]; ok {
+		return models.RankingFormula{Strategy: cfg.RankingStrategy, Weights: weights}
+	}
+	return models.RankingFormula{Strategy: "default", Weights: rankingPresets["default"]}
+}
+
+// rankComponentScore normalizes a candidate's ranking component to 0-100
+func rankComponentScore(c models.ScreenerCandidate, component models.RankComponent) float64 {
+	switch component {
+	case models.RankComponentScore:
+		// Agent scores run from -100 to 100
+		return clampScore((*c.Score + 100) / 2)
+	case models.RankComponentConfidence:
+		return clampScore(*c.Confidence)
+	case models.RankComponentCompleteness:
+		// Runs saved before completeness was recorded count as complete
+		if c.DataCompleteness == nil {
+			return 100
+		}
+		return clampScore(*c.DataCompleteness)
+	case models.RankComponentMarginOfSafety:
+		// A 50% discount to target earns the full score
+		if c.MarginOfSafety == nil {
+			return 0
+		}
+		return clampScore(*c.MarginOfSafety * 2)
+	case models.RankComponentLiquidity:
+		// Daily dollar volume on a log scale: $1M = 0, $1B = 100
+		dollarVolume := float64(c.Volume) * c.Price
+		if dollarVolume <= 0 {
+			return 0
+		}
+		return clampScore((math.Log10(dollarVolume) - 6) / 3 * 100)
+	}
+	return 0
+}
+
+func clampScore(v float64) float64 {
+	return max(0, min(100, v))
+}
+
+// RankScore applies the ranking formula to an analyzed candidate
+func RankScore(c models.ScreenerCandidate, formula models.RankingFormula) float64 {
+	var total float64
+	for _, w := range formula.Weights {
+		total += rankComponentScore(c, w.Component) * w.Weight
+	}
+	return total
+}
+
+// RankPicks sorts analyzed candidates by the ranking formula, records each
+// candidate's rank score and returns the top N candidates.
+func RankPicks(candidates []models.ScreenerCandidate, formula models.RankingFormula, topN int) []models.ScreenerCandidate {
+	// Filter to only analyzed candidates
+	analyzed := make([]models.ScreenerCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		if c.Analyzed && c.Score != nil && c.Confidence != nil {
+			rankScore := RankScore(c, formula)
+			c.RankScore = &rankScore
+			analyzed = append(analyzed, c)
+		}
+	}
+
+	sort.SliceStable(analyzed, func(i, j int) bool {
+		return *analyzed[i].RankScore > *analyzed[j].RankScore
+	})
+
+	// Return top N
+	if topN > 0 && topN < len(analyzed) {
+		return analyzed[:topN]
+	}
+	return analyzed
+}
+
+// marginOfSafety returns the percentage discount of price to the recommendation's
+// target price, or nil when there is no target to compare against
+func marginOfSafety(price float64, rec *models.Recommendation) *float64 {
+	target, _ := rec.TargetPrice.Float64()
+	if target <= 0 {
+		return nil
+	}
+	if price <= 0 {
+		price, _ = rec.EntryPrice.Float64()
+	}
+	if price <= 0 {
+		return nil
+	}
+	margin := (target - price) / target * 100
+	return &margin
+}
@@ -0,0 +1,151 @@
+package screener
+
+import (
+	"math"
+	"testing"
+
+	"trade-machine/config"
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRankingFormulaFor(t *testing.T) {
+	t.Run("presets", func(t *testing.T) {
+		for strategy, weights := range rankingPresets {
+			var sum float64
+			for _, w := range weights {
+				sum += w.Weight
+			}
+			if math.Abs(sum-1) > 0.0001 {
+				t.Errorf("%s weights sum to %v, want 1", strategy, sum)
+			}
+			if got := RankingFormulaFor(&config.ScreenerConfig{RankingStrategy: strategy}); got.Strategy != strategy {
+				t.Errorf("RankingFormulaFor(%q).Strategy = %q", strategy, got.Strategy)
+			}
+		}
+	})
+
+	t.Run("custom weights in component order", func(t *testing.T) {
+		cfg := &config.ScreenerConfig{
+			RankingStrategy: "custom",
+			RankingWeights:  map[string]float64{"liquidity": 0.4, "score": 0.6},
+		}
+		got := RankingFormulaFor(cfg)
+		if want := "0.6 × Score + 0.4 × Liquidity"; got.String() != want {
+			t.Errorf("formula = %q, want %q", got.String(), want)
+		}
+	})
+
+	t.Run("unknown strategy falls back to default", func(t *testing.T) {
+		if got := RankingFormulaFor(&config.ScreenerConfig{}); got.Strategy != "default" {
+			t.Errorf("Strategy = %q, want default", got.Strategy)
+		}
+	})
+}
+
+func TestRankScore_Components(t *testing.T) {
+	score, conf, completeness, margin := 20.0, 70.0, 50.0, 30.0
+	c := models.ScreenerCandidate{
+		Score:            &score,
+		Confidence:       &conf,
+		DataCompleteness: &completeness,
+		MarginOfSafety:   &margin,
+		Price:            100,
+		Volume:           100_000, // $10M daily
+	}
+
+	tests := []struct {
+		component models.RankComponent
+		want      float64
+	}{
+		{models.RankComponentScore, 60},
+		{models.RankComponentConfidence, 70},
+		{models.RankComponentCompleteness, 50},
+		{models.RankComponentMarginOfSafety, 60},
+		{models.RankComponentLiquidity, 100.0 / 3},
+	}
+	for _, tt := range tests {
+		formula := models.RankingFormula{Weights: []models.RankingWeight{{Component: tt.component, Weight: 1}}}
+		if got := RankScore(c, formula); math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("%s = %v, want %v", tt.component, got, tt.want)
+		}
+	}
+}
+
+func TestRankPicks(t *testing.T) {
+	score1, conf1 := 80.0, 90.0
+	score2, conf2 := 70.0, 80.0
+	score3, conf3 := 90.0, 60.0 // High score but low confidence
+	bigMargin := 50.0
+
+	candidates := []models.ScreenerCandidate{
+		{Symbol: "CHEAP", Score: &score2, Confidence: &conf2, MarginOfSafety: &bigMargin, Analyzed: true},
+		{Symbol: "LOW", Score: &score2, Confidence: &conf2, Analyzed: true},
+		{Symbol: "HIGH", Score: &score1, Confidence: &conf1, Analyzed: true},
+		{Symbol: "RISKY", Score: &score3, Confidence: &conf3, Analyzed: true},
+		{Symbol: "UNANALYZED", Analyzed: false},
+	}
+
+	t.Run("default favors score and confidence", func(t *testing.T) {
+		ranked := RankPicks(candidates[1:], RankingFormulaFor(&config.ScreenerConfig{RankingStrategy: "default"}), 0)
+
+		if len(ranked) != 3 {
+			t.Fatalf("Should exclude unanalyzed, got %d", len(ranked))
+		}
+		if ranked[0].Symbol != "HIGH" {
+			t.Errorf("First should be HIGH, got %s", ranked[0].Symbol)
+		}
+		if ranked[2].Symbol != "RISKY" {
+			t.Errorf("Last should be RISKY (low confidence hurts), got %s", ranked[2].Symbol)
+		}
+		for _, c := range ranked {
+			if c.RankScore == nil {
+				t.Errorf("%s has no rank score", c.Symbol)
+			}
+		}
+	})
+
+	t.Run("value strategy favors margin of safety", func(t *testing.T) {
+		ranked := RankPicks(candidates, RankingFormulaFor(&config.ScreenerConfig{RankingStrategy: "value"}), 1)
+
+		if len(ranked) != 1 || ranked[0].Symbol != "CHEAP" {
+			t.Errorf("Top pick should be CHEAP, got %+v", ranked)
+		}
+	})
+
+	t.Run("no analyzed candidates", func(t *testing.T) {
+		unanalyzed := []models.ScreenerCandidate{{Symbol: "A"}, {Symbol: "B"}}
+
+		if ranked := RankPicks(unanalyzed, RankingFormulaFor(&config.ScreenerConfig{}), 5); len(ranked) != 0 {
+			t.Errorf("Should return empty for unanalyzed, got %d", len(ranked))
+		}
+	})
+
+	t.Run("missing score or confidence", func(t *testing.T) {
+		partial := []models.ScreenerCandidate{
+			{Symbol: "NO_SCORE", Confidence: &conf1, Analyzed: true},
+			{Symbol: "NO_CONF", Score: &score1, Analyzed: true},
+			{Symbol: "COMPLETE", Score: &score1, Confidence: &conf1, Analyzed: true},
+		}
+
+		ranked := RankPicks(partial, RankingFormulaFor(&config.ScreenerConfig{}), 0)
+		if len(ranked) != 1 || ranked[0].Symbol != "COMPLETE" {
+			t.Errorf("Should only include complete candidates, got %+v", ranked)
+		}
+	})
+}
+
+func TestMarginOfSafety(t *testing.T) {
+	rec := &models.Recommendation{TargetPrice: decimal.NewFromInt(125), EntryPrice: decimal.NewFromInt(110)}
+
+	if got := marginOfSafety(100, rec); got == nil || math.Abs(*got-20) > 0.0001 {
+		t.Errorf("marginOfSafety(100) = %v, want 20", got)
+	}
+	if got := marginOfSafety(0, rec); got == nil || math.Abs(*got-12) > 0.0001 {
+		t.Errorf("marginOfSafety without price should use entry, got %v", got)
+	}
+	if got := marginOfSafety(100, &models.Recommendation{}); got != nil {
+		t.Errorf("marginOfSafety without target = %v, want nil", *got)
+	}
+}
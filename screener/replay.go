@@ -0,0 +1,158 @@
+package screener
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-machine/events"
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+)
+
+// archiveUniverse stores the raw screen results a run started from. A failure only
+// costs the ability to replay the run, so it is logged rather than failing the run.
+func (s *ValueScreener) archiveUniverse(ctx context.Context, runID uuid.UUID, universe []models.ScreenerUniverseEntry) {
+	if err := s.repo.SaveScreenerUniverse(ctx, runID, universe); err != nil {
+		logger.Warn("failed to archive screener universe",
+			"run_id", runID,
+			"error", err)
+	}
+}
+
+// ReplayRun screens the archived universe of a finished run again with the request's
+// criteria and ranking strategy, saving the result as a new run linked to the original.
+// Candidates the original run already analyzed reuse that analysis; only new ones are
+// analyzed. The replay run is created and compared at once, still running, and is
+// screened and analyzed in the background until ctx is done; its progress is published
+// like any run's. Returns nil if the run does not exist, and
+// models.ErrScreenerRunInProgress while another run, retry or replay is in progress.
+func (s *ValueScreener) ReplayRun(ctx context.Context, id uuid.UUID, req models.ScreenerReplayRequest) (*models.ScreenerRunComparison, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if !s.running.TryLock() {
+		return nil, models.ErrScreenerRunInProgress
+	}
+	started := false
+	defer func() {
+		if !started {
+			s.running.Unlock()
+		}
+	}()
+
+	original, err := s.repo.GetScreenerRun(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get screener run: %w", err)
+	}
+	if original == nil {
+		return nil, nil
+	}
+	if original.IsRunning() {
+		return nil, models.ErrScreenerRunInProgress
+	}
+
+	ranking, err := s.replayRanking(original, req.RankingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	universe, err := s.repo.GetScreenerUniverse(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get screener universe: %w", err)
+	}
+	if universe == nil {
+		return nil, models.ErrScreenerUniverseMissing
+	}
+
+	entries, err := s.repo.GetSymbolListEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load symbol lists: %w", err)
+	}
+	lists := models.NewSymbolLists(entries)
+
+	startTime := time.Now()
+	criteria := req.Apply(original.Criteria)
+	criteria.Ranking = &ranking
+
+	run := models.NewScreenerRun(criteria)
+	run.ReplayOf = &original.ID
+	if err := s.repo.CreateScreenerRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to create screener run: %w", err)
+	}
+	s.archiveUniverse(ctx, run.ID, universe)
+
+	// The comparison is made before the background replay starts changing the run
+	running := *run
+	cmp := models.CompareScreenerRuns(original, &running)
+	started = true
+	go func() {
+		defer s.running.Unlock()
+		s.replay(ctx, original, run, universe, lists, startTime)
+	}()
+	return cmp, nil
+}
+
+// replay screens and analyzes a replay run created by ReplayRun and completes it
+func (s *ValueScreener) replay(ctx context.Context, original, run *models.ScreenerRun, universe []models.ScreenerUniverseEntry, lists *models.SymbolLists, startTime time.Time) {
+	criteria := run.Criteria
+	candidates := make([]models.ScreenerCandidate, 0, len(universe))
+	for _, e := range universe {
+		if criteria.Admits(e) && lists.Screenable(e.Symbol) {
+			candidates = append(candidates, e.Candidate())
+		}
+	}
+	ranked := s.rankForPreset(ctx, criteria.ScreenerFilters, candidates)
+	preFiltered := s.screenListingAge(ctx, ranked, criteria.MinListingMonths, s.cfg.PreFilterLimit)
+
+	analyzed := make(map[string]models.ScreenerCandidate, len(original.Candidates))
+	for _, c := range original.Candidates {
+		if c.Analyzed {
+			analyzed[c.Symbol] = c
+		}
+	}
+	var reused int
+	for i, c := range preFiltered {
+		if prior, ok := analyzed[c.Symbol]; ok {
+			preFiltered[i] = prior
+			reused++
+		}
+	}
+
+	throttle := s.newThrottle()
+	replayed, fresh := s.reanalyzeFailed(ctx, run.ID, preFiltered, throttle)
+	if fresh > 0 {
+		run.Throttle = throttle.Report()
+	}
+	s.completeRun(run, replayed, *criteria.Ranking, time.Since(startTime).Milliseconds())
+
+	// The outcome is saved even when ctx is done so the replay isn't left running
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := s.repo.UpdateScreenerRun(saveCtx, run); err != nil {
+		logger.Warn("failed to update screener replay", "run_id", run.ID, "error", err)
+	}
+	events.Publish(events.ScreenerCompleted, run)
+
+	logger.Info("screener replay completed",
+		"run_id", run.ID,
+		"replay_of", original.ID,
+		"candidates", len(replayed),
+		"reused", reused,
+		"analyzed", fresh)
+}
+
+// replayRanking resolves the ranking formula for a replay. An empty strategy keeps the
+// original run's formula; "custom" uses the configured weights.
+func (s *ValueScreener) replayRanking(original *models.ScreenerRun, strategy string) (models.RankingFormula, error) {
+	if strategy == "" {
+		return s.rankingFormula(original), nil
+	}
+	if _, ok := rankingPresets[strategy]; !ok && strategy != "custom" {
+		return models.RankingFormula{}, fmt.Errorf("%w: unknown ranking strategy %q", models.ErrInvalidScreenerReplay, strategy)
+	}
+	cfg := *s.cfg
+	cfg.RankingStrategy = strategy
+	return RankingFormulaFor(&cfg), nil
+}
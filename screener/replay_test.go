@@ -0,0 +1,177 @@
+package screener
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+)
+
+func TestValueScreener_ReplayRun(t *testing.T) {
+	score, conf, complete := 70.0, 80.0, 100.0
+	cheapID := uuid.New()
+	original := &models.ScreenerRun{
+		ID:       uuid.New(),
+		Status:   models.ScreenerRunStatusCompleted,
+		Criteria: models.ScreenerCriteria{PERatioMax: 15},
+		Candidates: []models.ScreenerCandidate{
+			{Symbol: "CHEAP", PERatio: 10, Score: &score, Confidence: &conf, DataCompleteness: &complete, Analyzed: true, RecommendationID: &cheapID},
+		},
+		TopPicks: []uuid.UUID{cheapID},
+	}
+	universe := []models.ScreenerUniverseEntry{
+		{Symbol: "CHEAP", PERatio: 10, PBRatio: 1, Price: 20},
+		{Symbol: "PRICEY", PERatio: 22, PBRatio: 2, Price: 40},
+		{Symbol: "BUBBLE", PERatio: 80, PBRatio: 9, Price: 90},
+	}
+	cfg := &config.ScreenerConfig{TopPicksCount: 3, AnalysisTimeoutSec: 120, MaxConcurrent: 5}
+
+	newRepo := func(saved *[]*models.ScreenerRun) *MockScreenerRepository {
+		var mu sync.Mutex
+		return &MockScreenerRepository{
+			GetScreenerRunFunc: func(ctx context.Context, id uuid.UUID) (*models.ScreenerRun, error) {
+				if id == original.ID {
+					return original, nil
+				}
+				return nil, nil
+			},
+			GetScreenerUniverseFunc: func(ctx context.Context, runID uuid.UUID) ([]models.ScreenerUniverseEntry, error) {
+				return universe, nil
+			},
+			CreateScreenerRunFunc: func(ctx context.Context, run *models.ScreenerRun) error {
+				mu.Lock()
+				defer mu.Unlock()
+				*saved = append(*saved, run)
+				return nil
+			},
+		}
+	}
+	// finished collects replay runs as the background replay saves them
+	finished := func(repo *MockScreenerRepository) chan *models.ScreenerRun {
+		done := make(chan *models.ScreenerRun, 1)
+		repo.UpdateScreenerRunFunc = func(ctx context.Context, run *models.ScreenerRun) error {
+			done <- run
+			return nil
+		}
+		return done
+	}
+
+	t.Run("reuses prior analyses and compares", func(t *testing.T) {
+		var saved []*models.ScreenerRun
+		var analyzed []string
+		analysis := &MockAnalysisProvider{
+			AnalyzeSymbolFunc: func(ctx context.Context, symbol string) (*models.Recommendation, error) {
+				analyzed = append(analyzed, symbol)
+				return models.NewRecommendation(symbol, models.RecommendationActionBuy, "ok"), nil
+			},
+		}
+
+		repo := newRepo(&saved)
+		done := finished(repo)
+		started, err := NewValueScreener(&MockFMPService{}, analysis, repo, cfg).
+			ReplayRun(context.Background(), original.ID, models.ScreenerReplayRequest{PERatioMax: 25, RankingStrategy: "conservative"})
+		if err != nil {
+			t.Fatalf("ReplayRun failed: %v", err)
+		}
+		if !started.Replay.IsRunning() {
+			t.Errorf("replay status = %s, want it returned while still running", started.Replay.Status)
+		}
+
+		cmp := models.CompareScreenerRuns(original, <-done)
+		if !slices.Equal(analyzed, []string{"PRICEY"}) {
+			t.Errorf("analyzed %v, want only the newly admitted PRICEY", analyzed)
+		}
+		if !slices.Equal(cmp.Added, []string{"PRICEY"}) || len(cmp.Removed) != 0 {
+			t.Errorf("Added = %v, Removed = %v; want [PRICEY] and none", cmp.Added, cmp.Removed)
+		}
+		if len(saved) != 1 || saved[0].ReplayOf == nil || *saved[0].ReplayOf != original.ID {
+			t.Fatalf("saved runs = %v, want one replay of %s", saved, original.ID)
+		}
+		if cmp.Replay.Criteria.PERatioMax != 25 || cmp.Replay.Criteria.Ranking.Strategy != "conservative" {
+			t.Errorf("replay criteria = %+v, want the overrides applied", cmp.Replay.Criteria)
+		}
+		if !cmp.Replay.IsCompleted() || len(cmp.ReplayPicks) != 2 {
+			t.Errorf("replay status = %s, picks = %v; want a completed run with both picks", cmp.Replay.Status, cmp.ReplayPicks)
+		}
+	})
+
+	t.Run("overlapping replay is rejected", func(t *testing.T) {
+		var saved []*models.ScreenerRun
+		release := make(chan struct{})
+		analysis := &MockAnalysisProvider{
+			AnalyzeSymbolFunc: func(ctx context.Context, symbol string) (*models.Recommendation, error) {
+				<-release
+				return models.NewRecommendation(symbol, models.RecommendationActionBuy, "ok"), nil
+			},
+		}
+		repo := newRepo(&saved)
+		done := finished(repo)
+		s := NewValueScreener(&MockFMPService{}, analysis, repo, cfg)
+
+		req := models.ScreenerReplayRequest{PERatioMax: 25}
+		if _, err := s.ReplayRun(context.Background(), original.ID, req); err != nil {
+			t.Fatalf("ReplayRun failed: %v", err)
+		}
+		if _, err := s.ReplayRun(context.Background(), original.ID, req); !errors.Is(err, models.ErrScreenerRunInProgress) {
+			t.Errorf("err = %v, want ErrScreenerRunInProgress while the first replay runs", err)
+		}
+
+		close(release)
+		<-done
+		waitUnlocked(t, &s.running)
+		if _, err := s.ReplayRun(context.Background(), original.ID, req); err != nil {
+			t.Errorf("ReplayRun after the first finished failed: %v", err)
+		}
+		<-done
+	})
+
+	t.Run("unknown strategy is rejected", func(t *testing.T) {
+		var saved []*models.ScreenerRun
+		_, err := NewValueScreener(&MockFMPService{}, &MockAnalysisProvider{}, newRepo(&saved), cfg).
+			ReplayRun(context.Background(), original.ID, models.ScreenerReplayRequest{RankingStrategy: "yolo"})
+		if !errors.Is(err, models.ErrInvalidScreenerReplay) || len(saved) != 0 {
+			t.Errorf("err = %v, saved %d runs; want ErrInvalidScreenerReplay and none", err, len(saved))
+		}
+	})
+
+	t.Run("run without archived universe", func(t *testing.T) {
+		var saved []*models.ScreenerRun
+		repo := newRepo(&saved)
+		repo.GetScreenerUniverseFunc = nil
+
+		_, err := NewValueScreener(&MockFMPService{}, &MockAnalysisProvider{}, repo, cfg).
+			ReplayRun(context.Background(), original.ID, models.ScreenerReplayRequest{})
+		if !errors.Is(err, models.ErrScreenerUniverseMissing) {
+			t.Errorf("err = %v, want ErrScreenerUniverseMissing", err)
+		}
+	})
+
+	t.Run("missing run returns nil", func(t *testing.T) {
+		var saved []*models.ScreenerRun
+		got, err := NewValueScreener(&MockFMPService{}, &MockAnalysisProvider{}, newRepo(&saved), cfg).
+			ReplayRun(context.Background(), uuid.New(), models.ScreenerReplayRequest{})
+		if err != nil || got != nil {
+			t.Errorf("ReplayRun() = %v, %v; want nil, nil", got, err)
+		}
+	})
+}
+
+// waitUnlocked waits for a background replay to release the screener's running lock
+func waitUnlocked(t *testing.T, mu *sync.Mutex) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !mu.TryLock() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the screener to finish")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	mu.Unlock()
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"trade-machine/config"
+	"trade-machine/events"
 	"trade-machine/models"
 	"trade-machine/observability"
 	"trade-machine/services"
@@ -14,6 +15,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// logger is the screener module's logger
+var logger = observability.Module(observability.ModuleScreener)
+
 // AnalysisProvider defines the interface for running stock analysis
 type AnalysisProvider interface {
 	AnalyzeSymbol(ctx context.Context, symbol string) (*models.Recommendation, error)
@@ -26,7 +30,11 @@ type ScreenerRepository interface {
 	GetScreenerRun(ctx context.Context, id uuid.UUID) (*models.ScreenerRun, error)
 	GetLatestScreenerRun(ctx context.Context) (*models.ScreenerRun, error)
 	GetScreenerRunHistory(ctx context.Context, limit int) ([]models.ScreenerRun, error)
+	SaveScreenerUniverse(ctx context.Context, runID uuid.UUID, entries []models.ScreenerUniverseEntry) error
+	GetScreenerUniverse(ctx context.Context, runID uuid.UUID) ([]models.ScreenerUniverseEntry, error)
 	CreateRecommendation(ctx context.Context, rec *models.Recommendation) error
+	GetSymbolListEntries(ctx context.Context) ([]models.SymbolListEntry, error)
+	GetScreenerPreset(ctx context.Context, name string) (*models.ScreenerPreset, error)
 }
 
 // ValueScreener orchestrates the full value screening workflow
@@ -35,6 +43,8 @@ type ValueScreener struct {
 	analysisProvider AnalysisProvider
 	repo             ScreenerRepository
 	cfg              *config.ScreenerConfig
+	// Held while a run, retry or replay analyzes candidates, so they don't overlap
+	running sync.Mutex
 }
 
 // NewValueScreener creates a new ValueScreener
@@ -53,18 +63,48 @@ func NewValueScreener(
 }
 
 // RunScreen executes a full screening workflow:
-// 1. Fetch candidates from FMP
-// 2. Pre-filter by value score
-// 3. Run full analysis on top candidates
+// 1. Fetch and archive candidates from FMP, dropping blocklisted symbols and any outside the allowlist
+// 2. Pre-filter by value score, or growth score for the growth preset, excluding or flagging recent listings
+// 3. Run full analysis on top candidates, retrying failures once, unless the run is screen-only
 // 4. Return top picks
-func (s *ValueScreener) RunScreen(ctx context.Context) (*models.ScreenerRun, error) {
+//
+// Non-zero fields in overrides replace the configured listing filters for this run;
+// pass nil to use the configuration as is. A saved preset named in overrides replaces the
+// configured valuation criteria, and an unknown one returns models.ErrScreenerPresetNotFound.
+// Returns models.ErrScreenerRunInProgress while another run or retry is in progress.
+func (s *ValueScreener) RunScreen(ctx context.Context, overrides *models.ScreenerFilters) (*models.ScreenerRun, error) {
+	if !s.running.TryLock() {
+		return nil, models.ErrScreenerRunInProgress
+	}
+	defer s.running.Unlock()
+
 	startTime := time.Now()
 
+	ranking := RankingFormulaFor(s.cfg)
 	criteria := models.ScreenerCriteria{
-		MarketCapMin: s.cfg.MarketCapMin,
-		PERatioMax:   s.cfg.PERatioMax,
-		PBRatioMax:   s.cfg.PBRatioMax,
-		Limit:        s.cfg.PreFilterLimit * 2,
+		MarketCapMin:     s.cfg.MarketCapMin,
+		PERatioMax:       s.cfg.PERatioMax,
+		PBRatioMax:       s.cfg.PBRatioMax,
+		Limit:            s.cfg.PreFilterLimit * 2,
+		MinListingMonths: s.cfg.MinListingMonths,
+		ScreenerFilters:  s.filters(overrides),
+		Ranking:          &ranking,
+	}
+	switch {
+	case criteria.IsGrowth():
+		// Growth companies trade at a premium, so the value screen's ratio caps would
+		// exclude the very candidates the preset is looking for
+		criteria.PERatioMax = 0
+		criteria.PBRatioMax = 0
+	case criteria.Preset != "" && criteria.Preset != models.ScreenerPresetValue:
+		preset, err := s.repo.GetScreenerPreset(ctx, criteria.Preset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load screener preset: %w", err)
+		}
+		if preset == nil {
+			return nil, fmt.Errorf("%w: %q", models.ErrScreenerPresetNotFound, criteria.Preset)
+		}
+		criteria = preset.Apply(criteria)
 	}
 
 	run := models.NewScreenerRun(criteria)
@@ -73,10 +113,18 @@ func (s *ValueScreener) RunScreen(ctx context.Context) (*models.ScreenerRun, err
 	}
 
 	screenCriteria := services.ScreenCriteria{
-		MarketCapMin: criteria.MarketCapMin,
-		PERatioMax:   criteria.PERatioMax,
-		PBRatioMax:   criteria.PBRatioMax,
-		Limit:        criteria.Limit,
+		MarketCapMin:     criteria.MarketCapMin,
+		MarketCapMax:     criteria.MarketCapMax,
+		PERatioMax:       criteria.PERatioMax,
+		PBRatioMax:       criteria.PBRatioMax,
+		EPSMin:           criteria.EPSMin,
+		DividendYieldMin: criteria.DividendYieldMin,
+		Sector:           criteria.Sector,
+		Exchanges:        criteria.Exchanges,
+		Country:          criteria.Country,
+		PriceMin:         criteria.PriceMin,
+		AvgVolumeMin:     criteria.AvgVolumeMin,
+		Limit:            criteria.Limit,
 	}
 
 	fmpResults, err := s.fmpService.Screen(ctx, screenCriteria)
@@ -87,108 +135,299 @@ func (s *ValueScreener) RunScreen(ctx context.Context) (*models.ScreenerRun, err
 		return run, fmt.Errorf("failed to fetch candidates from FMP: %w", err)
 	}
 
-	candidates := make([]models.ScreenerCandidate, 0, len(fmpResults))
+	entries, err := s.repo.GetSymbolListEntries(ctx)
+	if err != nil {
+		durationMs := time.Since(startTime).Milliseconds()
+		run.Fail(fmt.Sprintf("failed to load symbol lists: %v", err), durationMs)
+		_ = s.repo.UpdateScreenerRun(ctx, run)
+		return run, fmt.Errorf("failed to load symbol lists: %w", err)
+	}
+	lists := models.NewSymbolLists(entries)
+
+	universe := make([]models.ScreenerUniverseEntry, 0, len(fmpResults))
 	for _, r := range fmpResults {
-		candidates = append(candidates, models.ScreenerCandidate{
-			Symbol:        r.Symbol,
-			CompanyName:   r.CompanyName,
-			MarketCap:     r.MarketCap,
-			PERatio:       r.PERatio,
-			PBRatio:       r.PBRatio,
-			EPS:           r.EPS,
-			DividendYield: r.DividendYield,
-			Sector:        r.Sector,
-			Industry:      r.Industry,
-			Price:         r.Price,
-			Beta:          r.Beta,
-			Analyzed:      false,
-		})
-	}
-
-	preFiltered := RankByValueScore(candidates, s.cfg.PreFilterLimit)
-	observability.Info("pre-filtered candidates",
+		universe = append(universe, models.ScreenerUniverseEntry(r))
+	}
+	s.archiveUniverse(ctx, run.ID, universe)
+
+	candidates := make([]models.ScreenerCandidate, 0, len(universe))
+	var illiquid int
+	for _, e := range universe {
+		if !criteria.Liquid(e) {
+			illiquid++
+			continue
+		}
+		if lists.Screenable(e.Symbol) {
+			candidates = append(candidates, e.Candidate())
+		}
+	}
+	if illiquid > 0 {
+		logger.Info("excluded illiquid candidates",
+			"count", illiquid,
+			"dollar_volume_min", criteria.DollarVolumeMin)
+	}
+
+	ranked := s.rankForPreset(ctx, criteria.ScreenerFilters, candidates)
+	preFiltered := s.screenListingAge(ctx, ranked, criteria.MinListingMonths, s.cfg.PreFilterLimit)
+	logger.Info("pre-filtered candidates",
+		"preset", criteria.Preset,
 		"total", len(candidates),
 		"filtered", len(preFiltered))
 
-	analyzedCandidates, recommendations := s.analyzeInParallel(ctx, preFiltered)
-	run.SetCandidates(analyzedCandidates)
-
-	topCandidates := RankByAnalysisScore(analyzedCandidates, s.cfg.TopPicksCount)
-	topPicks := make([]uuid.UUID, 0, len(topCandidates))
-	for _, c := range topCandidates {
-		for _, rec := range recommendations {
-			if rec.Symbol == c.Symbol {
-				topPicks = append(topPicks, rec.ID)
-				break
+	analyzedCandidates := preFiltered
+	if !criteria.ScreenOnly {
+		throttle := s.newThrottle()
+		analyzedCandidates = s.analyzeInParallel(ctx, run.ID, preFiltered, throttle)
+
+		// Give candidates that hit transient failures (timeouts, rate limits) one more chance
+		if ctx.Err() == nil {
+			var retried int
+			analyzedCandidates, retried = s.reanalyzeFailed(ctx, run.ID, analyzedCandidates, throttle)
+			if retried > 0 {
+				logger.Info("retried failed candidates", "count", retried)
 			}
 		}
+		run.Throttle = throttle.Report()
 	}
 
 	durationMs := time.Since(startTime).Milliseconds()
-	run.Complete(durationMs, topPicks)
+	s.completeRun(run, analyzedCandidates, ranking, durationMs)
 
 	if err := s.repo.UpdateScreenerRun(ctx, run); err != nil {
-		observability.Warn("failed to update screener run", "error", err)
+		logger.Warn("failed to update screener run", "error", err)
 	}
+	events.Publish(events.ScreenerCompleted, run)
 
-	observability.Info("screener run completed",
+	logger.Info("screener run completed",
 		"duration_ms", durationMs,
 		"candidates", len(analyzedCandidates),
-		"top_picks", len(topPicks))
+		"top_picks", len(run.TopPicks))
+
+	return run, nil
+}
+
+// completeRun records the analyzed candidates on a run and completes it with the top
+// picks they rank to
+func (s *ValueScreener) completeRun(run *models.ScreenerRun, candidates []models.ScreenerCandidate, ranking models.RankingFormula, durationMs int64) {
+	run.SetCandidates(candidates)
+	run.Complete(durationMs, s.topPickIDs(candidates, ranking))
+}
+
+// RetryFailed re-analyzes only the failed candidates of a finished run and merges the
+// results into it, ranking its picks as the run did. A screen-only run has nothing to
+// retry and is returned as is. Returns nil if the run does not exist, and
+// models.ErrScreenerRunInProgress while it or another run or retry is in progress.
+func (s *ValueScreener) RetryFailed(ctx context.Context, id uuid.UUID) (*models.ScreenerRun, error) {
+	if !s.running.TryLock() {
+		return nil, models.ErrScreenerRunInProgress
+	}
+	defer s.running.Unlock()
+
+	run, err := s.repo.GetScreenerRun(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get screener run: %w", err)
+	}
+	if run == nil {
+		return nil, nil
+	}
+	if run.IsRunning() {
+		return nil, models.ErrScreenerRunInProgress
+	}
+	if run.Criteria.ScreenOnly {
+		return run, nil
+	}
+
+	startTime := time.Now()
+	throttle := s.newThrottle()
+	candidates, retried := s.reanalyzeFailed(ctx, run.ID, run.Candidates, throttle)
+	if retried == 0 {
+		return run, nil
+	}
+
+	run.Throttle = mergeThrottleReports(run.Throttle, throttle.Report())
+	ranking := s.rankingFormula(run)
+	run.Criteria.Ranking = &ranking
+	s.completeRun(run, candidates, ranking, run.DurationMs+time.Since(startTime).Milliseconds())
+
+	if err := s.repo.UpdateScreenerRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to update screener run: %w", err)
+	}
+	events.Publish(events.ScreenerCompleted, run)
+
+	logger.Info("screener run retry completed",
+		"run_id", run.ID,
+		"retried", retried,
+		"still_failed", len(run.FailedCandidates()))
 
 	return run, nil
 }
 
-func (s *ValueScreener) analyzeInParallel(ctx context.Context, candidates []models.ScreenerCandidate) ([]models.ScreenerCandidate, []*models.Recommendation) {
+// reanalyzeFailed analyzes the candidates that have not been analyzed yet and merges
+// the results back in place. Returns the merged candidates and how many were retried.
+func (s *ValueScreener) reanalyzeFailed(ctx context.Context, runID uuid.UUID, candidates []models.ScreenerCandidate, throttle *adaptiveThrottle) ([]models.ScreenerCandidate, int) {
+	var failedIdx []int
+	var failed []models.ScreenerCandidate
+	for i, c := range candidates {
+		if !c.Analyzed {
+			failedIdx = append(failedIdx, i)
+			failed = append(failed, c)
+		}
+	}
+	if len(failed) == 0 {
+		return candidates, 0
+	}
+
+	retried := s.analyzeInParallel(ctx, runID, failed, throttle)
+	merged := make([]models.ScreenerCandidate, len(candidates))
+	copy(merged, candidates)
+	for j, idx := range failedIdx {
+		merged[idx] = retried[j]
+	}
+	return merged, len(failed)
+}
+
+// topPickIDs returns the recommendation IDs of the best analyzed candidates
+func (s *ValueScreener) topPickIDs(candidates []models.ScreenerCandidate, ranking models.RankingFormula) []uuid.UUID {
+	topCandidates := RankPicks(candidates, ranking, s.cfg.TopPicksCount)
+	topPicks := make([]uuid.UUID, 0, len(topCandidates))
+	for _, c := range topCandidates {
+		if c.RecommendationID != nil {
+			topPicks = append(topPicks, *c.RecommendationID)
+		}
+	}
+	return topPicks
+}
+
+// rankingFormula returns the formula a run's picks were ranked with, falling back
+// to the configured one for runs saved before the formula was recorded
+func (s *ValueScreener) rankingFormula(run *models.ScreenerRun) models.RankingFormula {
+	if run.Criteria.Ranking != nil {
+		return *run.Criteria.Ranking
+	}
+	return RankingFormulaFor(s.cfg)
+}
+
+// filters resolves the listing filters and preset for a run from config and per-run overrides
+func (s *ValueScreener) filters(overrides *models.ScreenerFilters) models.ScreenerFilters {
+	f := models.ScreenerFilters{
+		Exchanges:       s.cfg.Exchanges,
+		Country:         s.cfg.Country,
+		PriceMin:        s.cfg.PriceMin,
+		AvgVolumeMin:    s.cfg.AvgVolumeMin,
+		DollarVolumeMin: s.cfg.DollarVolumeMin,
+	}
+	if overrides == nil {
+		return f
+	}
+	if len(overrides.Exchanges) > 0 {
+		f.Exchanges = overrides.Exchanges
+	}
+	if overrides.Country != "" {
+		f.Country = overrides.Country
+	}
+	if overrides.PriceMin > 0 {
+		f.PriceMin = overrides.PriceMin
+	}
+	if overrides.AvgVolumeMin > 0 {
+		f.AvgVolumeMin = overrides.AvgVolumeMin
+	}
+	if overrides.DollarVolumeMin > 0 {
+		f.DollarVolumeMin = overrides.DollarVolumeMin
+	}
+	f.ScreenOnly = overrides.ScreenOnly
+	f.Preset = overrides.Preset
+	return f
+}
+
+// newThrottle creates the adaptive concurrency limiter for one analysis pass
+func (s *ValueScreener) newThrottle() *adaptiveThrottle {
+	return newAdaptiveThrottle(s.cfg.MaxConcurrent, time.Duration(s.cfg.RateLimitPauseMs)*time.Millisecond)
+}
+
+// analyzeInParallel runs full analysis on the candidates, with concurrency governed by the
+// throttle. Candidates that hit a rate limit are put back in line instead of failing.
+// Each candidate's start, score or failure is published as run progress.
+func (s *ValueScreener) analyzeInParallel(ctx context.Context, runID uuid.UUID, candidates []models.ScreenerCandidate, throttle *adaptiveThrottle) []models.ScreenerCandidate {
 	analysisCtx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.AnalysisTimeoutSec)*time.Second)
 	defer cancel()
 
 	type analysisResult struct {
-		index      int
-		candidate  models.ScreenerCandidate
-		recommendation *models.Recommendation
-		err        error
+		index     int
+		candidate models.ScreenerCandidate
 	}
 
 	results := make(chan analysisResult, len(candidates))
-	sem := make(chan struct{}, s.cfg.MaxConcurrent)
 	var wg sync.WaitGroup
+	progress := newRunProgress(runID, len(candidates))
 
 	for i, candidate := range candidates {
 		wg.Add(1)
 		go func(idx int, c models.ScreenerCandidate) {
 			defer wg.Done()
 
-			select {
-			case sem <- struct{}{}:
-				defer func() { <-sem }()
-			case <-analysisCtx.Done():
-				results <- analysisResult{index: idx, candidate: c, err: analysisCtx.Err()}
-				return
+			var rec *models.Recommendation
+			var err error
+			for attempt := 0; ; attempt++ {
+				if acquireErr := throttle.acquire(analysisCtx); acquireErr != nil {
+					c.AnalysisError = acquireErr.Error()
+					progress.failed(c)
+					results <- analysisResult{index: idx, candidate: c}
+					return
+				}
+				if attempt == 0 {
+					progress.started(c)
+				}
+
+				rec, err = s.analysisProvider.AnalyzeSymbol(analysisCtx, c.Symbol)
+				rateLimited := isRateLimitError(err)
+				throttle.release(rateLimited)
+				if !rateLimited || attempt >= maxRateLimitRequeues {
+					break
+				}
+				logger.Info("candidate rate limited, requeueing",
+					"symbol", c.Symbol,
+					"attempt", attempt+1)
 			}
 
-			rec, err := s.analysisProvider.AnalyzeSymbol(analysisCtx, c.Symbol)
 			if err != nil || rec == nil {
-				observability.Warn("analysis failed for candidate",
+				logger.Warn("analysis failed for candidate",
 					"symbol", c.Symbol,
 					"error", err)
-				results <- analysisResult{index: idx, candidate: c, err: err}
+				if err != nil {
+					c.AnalysisError = err.Error()
+				} else {
+					c.AnalysisError = "analysis returned no recommendation"
+				}
+				progress.failed(c)
+				results <- analysisResult{index: idx, candidate: c}
 				return
 			}
 
-			combinedScore := (rec.FundamentalScore*0.4 + rec.SentimentScore*0.3 + rec.TechnicalScore*0.3)
+			combinedScore := AnalysisScore(rec)
 			confidence := rec.Confidence
+			completeness := rec.DataCompleteness
+			recID := rec.ID
 			c.Score = &combinedScore
 			c.Confidence = &confidence
+			c.DataCompleteness = &completeness
+			c.MarginOfSafety = marginOfSafety(c.Price, rec)
+			c.RecommendationID = &recID
+			c.AnalysisError = ""
 			c.Analyzed = true
+			if c.ScoreBreakdown != nil {
+				breakdown := *c.ScoreBreakdown
+				breakdown.AnalysisFormula = AnalysisScoreFormula
+				c.ScoreBreakdown = &breakdown
+			}
 
 			if err := s.repo.CreateRecommendation(analysisCtx, rec); err != nil {
-				observability.Warn("failed to save recommendation",
+				logger.Warn("failed to save recommendation",
 					"symbol", c.Symbol,
 					"error", err)
 			}
 
-			results <- analysisResult{index: idx, candidate: c, recommendation: rec, err: nil}
+			progress.scored(c)
+			results <- analysisResult{index: idx, candidate: c}
 		}(i, candidate)
 	}
 
@@ -198,19 +437,11 @@ func (s *ValueScreener) analyzeInParallel(ctx context.Context, candidates []mode
 	}()
 
 	analyzedCandidates := make([]models.ScreenerCandidate, len(candidates))
-	copy(analyzedCandidates, candidates)
-	recommendations := make([]*models.Recommendation, 0, len(candidates))
-
 	for result := range results {
-		if result.err == nil {
-			analyzedCandidates[result.index] = result.candidate
-			if result.recommendation != nil {
-				recommendations = append(recommendations, result.recommendation)
-			}
-		}
+		analyzedCandidates[result.index] = result.candidate
 	}
 
-	return analyzedCandidates, recommendations
+	return analyzedCandidates
 }
 
 // GetLatestPicks returns the top picks from the most recent completed screener run
@@ -226,7 +457,7 @@ func (s *ValueScreener) GetLatestPicks(ctx context.Context) ([]models.ScreenerCa
 		return nil, nil
 	}
 
-	return RankByAnalysisScore(run.Candidates, s.cfg.TopPicksCount), nil
+	return RankPicks(run.Candidates, s.rankingFormula(run), s.cfg.TopPicksCount), nil
 }
 
 // GetLatestRun returns the most recent screener run
@@ -3,6 +3,9 @@ package screener
 import (
 	"context"
 	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -17,6 +20,9 @@ import (
 type MockFMPService struct {
 	ScreenFunc          func(ctx context.Context, criteria services.ScreenCriteria) ([]services.ScreenerResult, error)
 	GetCompanyProfileFunc func(ctx context.Context, symbol string) (*services.CompanyProfile, error)
+	GetNextEarningsDateFunc func(ctx context.Context, symbol string) (*time.Time, error)
+	GetRatiosFunc func(ctx context.Context, symbol string) (*services.Ratios, error)
+	GetGrowthMetricsFunc func(ctx context.Context, symbol string) (*services.GrowthMetrics, error)
 }
 
 func (m *MockFMPService) Screen(ctx context.Context, criteria services.ScreenCriteria) ([]services.ScreenerResult, error) {
@@ -33,6 +39,35 @@ func (m *MockFMPService) GetCompanyProfile(ctx context.Context, symbol string) (
 	return nil, nil
 }
 
+func (m *MockFMPService) GetNextEarningsDate(ctx context.Context, symbol string) (*time.Time, error) {
+	if m.GetNextEarningsDateFunc != nil {
+		return m.GetNextEarningsDateFunc(ctx, symbol)
+	}
+	return nil, nil
+}
+
+func (m *MockFMPService) GetRatios(ctx context.Context, symbol string) (*services.Ratios, error) {
+	if m.GetRatiosFunc != nil {
+		return m.GetRatiosFunc(ctx, symbol)
+	}
+	return nil, nil
+}
+
+func (m *MockFMPService) GetInsiderTrades(ctx context.Context, symbol string, limit int) ([]models.InsiderTrade, error) {
+	return nil, nil
+}
+
+func (m *MockFMPService) GetGrowthMetrics(ctx context.Context, symbol string) (*services.GrowthMetrics, error) {
+	if m.GetGrowthMetricsFunc != nil {
+		return m.GetGrowthMetricsFunc(ctx, symbol)
+	}
+	return nil, errors.New("no growth data")
+}
+
+func (m *MockFMPService) GetDividendHistory(ctx context.Context, symbol string) ([]models.Dividend, error) {
+	return nil, nil
+}
+
 // MockAnalysisProvider implements AnalysisProvider for testing
 type MockAnalysisProvider struct {
 	AnalyzeSymbolFunc func(ctx context.Context, symbol string) (*models.Recommendation, error)
@@ -53,6 +88,10 @@ type MockScreenerRepository struct {
 	GetLatestScreenerRunFunc func(ctx context.Context) (*models.ScreenerRun, error)
 	GetScreenerRunHistoryFunc func(ctx context.Context, limit int) ([]models.ScreenerRun, error)
 	CreateRecommendationFunc func(ctx context.Context, rec *models.Recommendation) error
+	GetSymbolListEntriesFunc func(ctx context.Context) ([]models.SymbolListEntry, error)
+	SaveScreenerUniverseFunc func(ctx context.Context, runID uuid.UUID, entries []models.ScreenerUniverseEntry) error
+	GetScreenerUniverseFunc  func(ctx context.Context, runID uuid.UUID) ([]models.ScreenerUniverseEntry, error)
+	Presets                  map[string]models.ScreenerPreset
 }
 
 func (m *MockScreenerRepository) CreateScreenerRun(ctx context.Context, run *models.ScreenerRun) error {
@@ -97,6 +136,34 @@ func (m *MockScreenerRepository) CreateRecommendation(ctx context.Context, rec *
 	return nil
 }
 
+func (m *MockScreenerRepository) GetSymbolListEntries(ctx context.Context) ([]models.SymbolListEntry, error) {
+	if m.GetSymbolListEntriesFunc != nil {
+		return m.GetSymbolListEntriesFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *MockScreenerRepository) SaveScreenerUniverse(ctx context.Context, runID uuid.UUID, entries []models.ScreenerUniverseEntry) error {
+	if m.SaveScreenerUniverseFunc != nil {
+		return m.SaveScreenerUniverseFunc(ctx, runID, entries)
+	}
+	return nil
+}
+
+func (m *MockScreenerRepository) GetScreenerUniverse(ctx context.Context, runID uuid.UUID) ([]models.ScreenerUniverseEntry, error) {
+	if m.GetScreenerUniverseFunc != nil {
+		return m.GetScreenerUniverseFunc(ctx, runID)
+	}
+	return nil, nil
+}
+
+func (m *MockScreenerRepository) GetScreenerPreset(ctx context.Context, name string) (*models.ScreenerPreset, error) {
+	if p, ok := m.Presets[name]; ok {
+		return &p, nil
+	}
+	return nil, nil
+}
+
 func TestNewValueScreener(t *testing.T) {
 	fmp := &MockFMPService{}
 	analysis := &MockAnalysisProvider{}
@@ -182,7 +249,7 @@ func TestValueScreener_RunScreen_Success(t *testing.T) {
 	screener := NewValueScreener(fmp, analysis, repo, cfg)
 	ctx := context.Background()
 
-	run, err := screener.RunScreen(ctx)
+	run, err := screener.RunScreen(ctx, nil)
 
 	if err != nil {
 		t.Fatalf("RunScreen failed: %v", err)
@@ -205,6 +272,43 @@ func TestValueScreener_RunScreen_Success(t *testing.T) {
 	if run.DurationMs < 0 {
 		t.Error("DurationMs should not be negative")
 	}
+	if run.Criteria.Ranking == nil || run.Criteria.Ranking.Strategy != "default" {
+		t.Errorf("run should record the default ranking formula, got %+v", run.Criteria.Ranking)
+	}
+}
+
+func TestValueScreener_RunScreen_ScreenOnly(t *testing.T) {
+	fmp := &MockFMPService{
+		ScreenFunc: func(ctx context.Context, criteria services.ScreenCriteria) ([]services.ScreenerResult, error) {
+			return []services.ScreenerResult{
+				{Symbol: "JNJ", CompanyName: "Johnson & Johnson", PERatio: 10, PBRatio: 1.0, DividendYield: 3.0},
+				{Symbol: "PG", CompanyName: "Procter & Gamble", PERatio: 12, PBRatio: 1.2, DividendYield: 2.5},
+			}, nil
+		},
+	}
+	var analyzed int
+	analysis := &MockAnalysisProvider{
+		AnalyzeSymbolFunc: func(ctx context.Context, symbol string) (*models.Recommendation, error) {
+			analyzed++
+			return models.NewRecommendation(symbol, models.RecommendationActionBuy, ""), nil
+		},
+	}
+	repo := &MockScreenerRepository{
+		CreateScreenerRunFunc: func(ctx context.Context, run *models.ScreenerRun) error { return nil },
+		UpdateScreenerRunFunc: func(ctx context.Context, run *models.ScreenerRun) error { return nil },
+	}
+	cfg := &config.ScreenerConfig{PreFilterLimit: 15, TopPicksCount: 3, AnalysisTimeoutSec: 120, MaxConcurrent: 5}
+
+	run, err := NewValueScreener(fmp, analysis, repo, cfg).RunScreen(context.Background(), &models.ScreenerFilters{ScreenOnly: true})
+	if err != nil {
+		t.Fatalf("RunScreen failed: %v", err)
+	}
+	if analyzed != 0 {
+		t.Errorf("analyzed %d candidates, want none on a screen-only run", analyzed)
+	}
+	if !run.IsCompleted() || len(run.Candidates) != 2 || len(run.FailedCandidates()) != 2 || !run.Criteria.ScreenOnly {
+		t.Errorf("run = %+v, want a completed screen-only run with both candidates left to analyze", run)
+	}
 }
 
 func TestValueScreener_RunScreen_FMPError(t *testing.T) {
@@ -235,7 +339,7 @@ func TestValueScreener_RunScreen_FMPError(t *testing.T) {
 	screener := NewValueScreener(fmp, &MockAnalysisProvider{}, repo, cfg)
 	ctx := context.Background()
 
-	run, err := screener.RunScreen(ctx)
+	run, err := screener.RunScreen(ctx, nil)
 
 	if err == nil {
 		t.Error("RunScreen should return error when FMP fails")
@@ -271,7 +375,7 @@ func TestValueScreener_RunScreen_CreateRunError(t *testing.T) {
 	screener := NewValueScreener(&MockFMPService{}, &MockAnalysisProvider{}, repo, cfg)
 	ctx := context.Background()
 
-	_, err := screener.RunScreen(ctx)
+	_, err := screener.RunScreen(ctx, nil)
 
 	if err == nil {
 		t.Error("RunScreen should return error when CreateScreenerRun fails")
@@ -316,7 +420,7 @@ func TestValueScreener_RunScreen_PartialAnalysisFailure(t *testing.T) {
 	screener := NewValueScreener(fmp, analysis, repo, cfg)
 	ctx := context.Background()
 
-	run, err := screener.RunScreen(ctx)
+	run, err := screener.RunScreen(ctx, nil)
 
 	if err != nil {
 		t.Fatalf("RunScreen should succeed with partial failures: %v", err)
@@ -335,6 +439,434 @@ func TestValueScreener_RunScreen_PartialAnalysisFailure(t *testing.T) {
 	if analyzedCount != 1 {
 		t.Errorf("Should have 1 analyzed candidate, got %d", analyzedCount)
 	}
+
+	failed := run.FailedCandidates()
+	if len(failed) != 1 || failed[0].AnalysisError != "analysis failed" {
+		t.Errorf("FailedCandidates() = %+v, want FAIL with its error", failed)
+	}
+}
+
+func TestValueScreener_RunScreen_GrowthPreset(t *testing.T) {
+	var got services.ScreenCriteria
+	fmp := &MockFMPService{
+		ScreenFunc: func(ctx context.Context, criteria services.ScreenCriteria) ([]services.ScreenerResult, error) {
+			got = criteria
+			return []services.ScreenerResult{
+				{Symbol: "KO", CompanyName: "Coca-Cola", PERatio: 22, DividendYield: 3.0},
+				{Symbol: "NVDA", CompanyName: "NVIDIA", PERatio: 60},
+			}, nil
+		},
+		GetGrowthMetricsFunc: func(ctx context.Context, symbol string) (*services.GrowthMetrics, error) {
+			if symbol == "NVDA" {
+				return &services.GrowthMetrics{Symbol: symbol, RevenueGrowth: 1.2, EPSGrowth: 2.5, PriceChange6M: 40}, nil
+			}
+			return &services.GrowthMetrics{Symbol: symbol, RevenueGrowth: 0.03, EPSGrowth: 0.05, PriceChange6M: 2}, nil
+		},
+	}
+	repo := &MockScreenerRepository{
+		CreateScreenerRunFunc: func(ctx context.Context, run *models.ScreenerRun) error { return nil },
+		UpdateScreenerRunFunc: func(ctx context.Context, run *models.ScreenerRun) error { return nil },
+	}
+	cfg := &config.ScreenerConfig{PreFilterLimit: 1, TopPicksCount: 1, AnalysisTimeoutSec: 120, MaxConcurrent: 5, PERatioMax: 15, PBRatioMax: 1.5}
+
+	run, err := NewValueScreener(fmp, &MockAnalysisProvider{}, repo, cfg).RunScreen(context.Background(),
+		&models.ScreenerFilters{Preset: models.ScreenerPresetGrowth, ScreenOnly: true})
+	if err != nil {
+		t.Fatalf("RunScreen failed: %v", err)
+	}
+	if got.PERatioMax != 0 || got.PBRatioMax != 0 {
+		t.Errorf("ScreenCriteria = %+v, want no valuation caps on a growth screen", got)
+	}
+	if run.Criteria.Preset != models.ScreenerPresetGrowth {
+		t.Errorf("run.Criteria.Preset = %q, want growth recorded", run.Criteria.Preset)
+	}
+	if len(run.Candidates) != 1 || run.Candidates[0].Symbol != "NVDA" || run.Candidates[0].RevenueGrowth == nil {
+		t.Errorf("candidates = %+v, want the faster grower pre-filtered with its metrics", run.Candidates)
+	}
+}
+
+func TestValueScreener_RunScreen_SavedPreset(t *testing.T) {
+	var got services.ScreenCriteria
+	fmp := &MockFMPService{
+		ScreenFunc: func(ctx context.Context, criteria services.ScreenCriteria) ([]services.ScreenerResult, error) {
+			got = criteria
+			return nil, nil
+		},
+	}
+	repo := &MockScreenerRepository{Presets: map[string]models.ScreenerPreset{
+		"utilities": {Name: "utilities", MarketCapMin: 500_000_000, DividendYieldMin: 3, Sector: "Utilities"},
+	}}
+	cfg := &config.ScreenerConfig{PreFilterLimit: 15, TopPicksCount: 3, AnalysisTimeoutSec: 120, MaxConcurrent: 5, MarketCapMin: 1_000_000_000, PERatioMax: 15}
+	screener := NewValueScreener(fmp, &MockAnalysisProvider{}, repo, cfg)
+
+	run, err := screener.RunScreen(context.Background(), &models.ScreenerFilters{Preset: "utilities"})
+	if err != nil {
+		t.Fatalf("RunScreen failed: %v", err)
+	}
+	if got.MarketCapMin != 500_000_000 || got.PERatioMax != 0 || got.DividendYieldMin != 3 || got.Sector != "Utilities" {
+		t.Errorf("ScreenCriteria = %+v, want the preset's criteria", got)
+	}
+	if run.Criteria.Preset != "utilities" || run.Criteria.Sector != "Utilities" {
+		t.Errorf("run.Criteria = %+v, want the preset recorded", run.Criteria)
+	}
+
+	if _, err := screener.RunScreen(context.Background(), &models.ScreenerFilters{Preset: "missing"}); !errors.Is(err, models.ErrScreenerPresetNotFound) {
+		t.Errorf("RunScreen error = %v, want ErrScreenerPresetNotFound", err)
+	}
+}
+
+func TestValueScreener_RunScreen_ListingFilters(t *testing.T) {
+	var got services.ScreenCriteria
+	fmp := &MockFMPService{
+		ScreenFunc: func(ctx context.Context, criteria services.ScreenCriteria) ([]services.ScreenerResult, error) {
+			got = criteria
+			return nil, nil
+		},
+	}
+	cfg := &config.ScreenerConfig{
+		PreFilterLimit:     15,
+		TopPicksCount:      3,
+		AnalysisTimeoutSec: 120,
+		MaxConcurrent:      5,
+		Exchanges:          []string{"NYSE", "NASDAQ"},
+		Country:            "US",
+		PriceMin:           5,
+	}
+	screener := NewValueScreener(fmp, &MockAnalysisProvider{}, &MockScreenerRepository{}, cfg)
+
+	run, err := screener.RunScreen(context.Background(), &models.ScreenerFilters{Country: "CA", AvgVolumeMin: 500_000})
+	if err != nil {
+		t.Fatalf("RunScreen failed: %v", err)
+	}
+	if len(got.Exchanges) != 2 || got.Country != "CA" || got.PriceMin != 5 || got.AvgVolumeMin != 500_000 {
+		t.Errorf("ScreenCriteria = %+v, want config exchanges and price with overridden country and volume", got)
+	}
+	if run.Criteria.Country != "CA" || run.Criteria.AvgVolumeMin != 500_000 {
+		t.Errorf("run.Criteria = %+v, want overrides recorded", run.Criteria)
+	}
+}
+
+func TestValueScreener_RunScreen_SymbolLists(t *testing.T) {
+	fmp := &MockFMPService{
+		ScreenFunc: func(ctx context.Context, criteria services.ScreenCriteria) ([]services.ScreenerResult, error) {
+			return []services.ScreenerResult{
+				{Symbol: "AAPL", PERatio: 10},
+				{Symbol: "MSFT", PERatio: 11},
+				{Symbol: "GME", PERatio: 12},
+				{Symbol: "TSLA", PERatio: 13},
+			}, nil
+		},
+	}
+	repo := &MockScreenerRepository{
+		GetSymbolListEntriesFunc: func(ctx context.Context) ([]models.SymbolListEntry, error) {
+			return []models.SymbolListEntry{
+				{List: models.SymbolListAllow, Symbol: "AAPL"},
+				{List: models.SymbolListAllow, Symbol: "GME"},
+				{List: models.SymbolListBlock, Symbol: "GME"},
+			}, nil
+		},
+	}
+	var analyzed []string
+	var mu sync.Mutex
+	analysis := &MockAnalysisProvider{
+		AnalyzeSymbolFunc: func(ctx context.Context, symbol string) (*models.Recommendation, error) {
+			mu.Lock()
+			analyzed = append(analyzed, symbol)
+			mu.Unlock()
+			return models.NewRecommendation(symbol, models.RecommendationActionHold, "OK"), nil
+		},
+	}
+	cfg := &config.ScreenerConfig{
+		PreFilterLimit:     15,
+		TopPicksCount:      3,
+		AnalysisTimeoutSec: 120,
+		MaxConcurrent:      5,
+	}
+
+	run, err := NewValueScreener(fmp, analysis, repo, cfg).RunScreen(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("RunScreen failed: %v", err)
+	}
+	if len(run.Candidates) != 1 || run.Candidates[0].Symbol != "AAPL" {
+		t.Errorf("candidates = %+v, want only the allowlisted, unblocked AAPL", run.Candidates)
+	}
+	if len(analyzed) != 1 || analyzed[0] != "AAPL" {
+		t.Errorf("analyzed %v, want [AAPL]", analyzed)
+	}
+}
+
+func TestValueScreener_RunScreen_DollarVolume(t *testing.T) {
+	fmp := &MockFMPService{
+		ScreenFunc: func(ctx context.Context, criteria services.ScreenCriteria) ([]services.ScreenerResult, error) {
+			return []services.ScreenerResult{
+				{Symbol: "BIG", PERatio: 10, Price: 50, Volume: 1_000_000},
+				{Symbol: "THIN", PERatio: 11, Price: 2, Volume: 100_000},
+			}, nil
+		},
+	}
+	analysis := &MockAnalysisProvider{
+		AnalyzeSymbolFunc: func(ctx context.Context, symbol string) (*models.Recommendation, error) {
+			return models.NewRecommendation(symbol, models.RecommendationActionHold, "OK"), nil
+		},
+	}
+	cfg := &config.ScreenerConfig{
+		PreFilterLimit:     15,
+		TopPicksCount:      3,
+		AnalysisTimeoutSec: 120,
+		MaxConcurrent:      5,
+		DollarVolumeMin:    1_000_000,
+	}
+
+	run, err := NewValueScreener(fmp, analysis, &MockScreenerRepository{}, cfg).RunScreen(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("RunScreen failed: %v", err)
+	}
+	if len(run.Candidates) != 1 || run.Candidates[0].Symbol != "BIG" {
+		t.Errorf("candidates = %+v, want only BIG above the $1M dollar volume floor", run.Candidates)
+	}
+	if run.Criteria.DollarVolumeMin != 1_000_000 {
+		t.Errorf("run.Criteria.DollarVolumeMin = %v, want the configured floor recorded", run.Criteria.DollarVolumeMin)
+	}
+}
+
+func TestValueScreener_RunScreen_SymbolListsError(t *testing.T) {
+	fmp := &MockFMPService{
+		ScreenFunc: func(ctx context.Context, criteria services.ScreenCriteria) ([]services.ScreenerResult, error) {
+			return []services.ScreenerResult{{Symbol: "AAPL", PERatio: 10}}, nil
+		},
+	}
+	repo := &MockScreenerRepository{
+		GetSymbolListEntriesFunc: func(ctx context.Context) ([]models.SymbolListEntry, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+	cfg := &config.ScreenerConfig{PreFilterLimit: 15, TopPicksCount: 3, AnalysisTimeoutSec: 120, MaxConcurrent: 5}
+
+	run, err := NewValueScreener(fmp, &MockAnalysisProvider{}, repo, cfg).RunScreen(context.Background(), nil)
+	if err == nil {
+		t.Fatal("expected error when symbol lists cannot be loaded")
+	}
+	if run.Status != models.ScreenerRunStatusFailed {
+		t.Errorf("run status = %s, want failed", run.Status)
+	}
+}
+
+func TestValueScreener_RunScreen_RetriesFailedOnce(t *testing.T) {
+	fmp := &MockFMPService{
+		ScreenFunc: func(ctx context.Context, criteria services.ScreenCriteria) ([]services.ScreenerResult, error) {
+			return []services.ScreenerResult{{Symbol: "FLAKY", PERatio: 10}}, nil
+		},
+	}
+
+	var calls atomic.Int32
+	analysis := &MockAnalysisProvider{
+		AnalyzeSymbolFunc: func(ctx context.Context, symbol string) (*models.Recommendation, error) {
+			if calls.Add(1) == 1 {
+				return nil, services.ErrRateLimited
+			}
+			rec := models.NewRecommendation(symbol, models.RecommendationActionBuy, "Recovered")
+			rec.FundamentalScore = 60
+			rec.Confidence = 70
+			return rec, nil
+		},
+	}
+
+	repo := &MockScreenerRepository{}
+	cfg := &config.ScreenerConfig{
+		PreFilterLimit:     15,
+		TopPicksCount:      3,
+		AnalysisTimeoutSec: 120,
+		MaxConcurrent:      5,
+	}
+
+	run, err := NewValueScreener(fmp, analysis, repo, cfg).RunScreen(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("RunScreen failed: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("AnalyzeSymbol called %d times, want 2", calls.Load())
+	}
+	if len(run.FailedCandidates()) != 0 {
+		t.Errorf("expected no failed candidates after retry, got %d", len(run.FailedCandidates()))
+	}
+	c := run.Candidates[0]
+	if c.AnalysisError != "" || c.RecommendationID == nil {
+		t.Errorf("retried candidate = %+v, want cleared error and recommendation ID", c)
+	}
+	if len(run.TopPicks) != 1 || run.TopPicks[0] != *c.RecommendationID {
+		t.Errorf("TopPicks = %v, want [%v]", run.TopPicks, *c.RecommendationID)
+	}
+	if !run.Throttle.Throttled() || run.Throttle.RateLimitErrors != 1 {
+		t.Errorf("Throttle = %+v, want one recorded rate-limit error", run.Throttle)
+	}
+}
+
+func TestValueScreener_RunScreen_ThrottlesOnRateLimits(t *testing.T) {
+	fmp := &MockFMPService{
+		ScreenFunc: func(ctx context.Context, criteria services.ScreenCriteria) ([]services.ScreenerResult, error) {
+			return []services.ScreenerResult{
+				{Symbol: "AAA", PERatio: 10},
+				{Symbol: "BBB", PERatio: 11},
+				{Symbol: "CCC", PERatio: 12},
+				{Symbol: "DDD", PERatio: 13},
+			}, nil
+		},
+	}
+
+	var limited atomic.Int32
+	analysis := &MockAnalysisProvider{
+		AnalyzeSymbolFunc: func(ctx context.Context, symbol string) (*models.Recommendation, error) {
+			// The provider rejects the first two calls, then recovers
+			if limited.Add(1) <= 2 {
+				return nil, &services.StatusError{Provider: "OpenAI", StatusCode: http.StatusTooManyRequests}
+			}
+			rec := models.NewRecommendation(symbol, models.RecommendationActionBuy, "OK")
+			rec.FundamentalScore = 60
+			rec.Confidence = 70
+			return rec, nil
+		},
+	}
+
+	cfg := &config.ScreenerConfig{
+		PreFilterLimit:     15,
+		TopPicksCount:      4,
+		AnalysisTimeoutSec: 120,
+		MaxConcurrent:      4,
+		RateLimitPauseMs:   1,
+	}
+
+	run, err := NewValueScreener(fmp, analysis, &MockScreenerRepository{}, cfg).RunScreen(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("RunScreen failed: %v", err)
+	}
+	if failed := run.FailedCandidates(); len(failed) != 0 {
+		t.Errorf("expected rate-limited candidates to be requeued, got %d failures", len(failed))
+	}
+	if run.Throttle == nil {
+		t.Fatal("expected throttle report on run")
+	}
+	if run.Throttle.RateLimitErrors != 2 {
+		t.Errorf("RateLimitErrors = %d, want 2", run.Throttle.RateLimitErrors)
+	}
+	if run.Throttle.InitialConcurrency != 4 || run.Throttle.MinConcurrency >= 4 {
+		t.Errorf("Throttle = %+v, want concurrency reduced from 4", run.Throttle)
+	}
+}
+
+func TestValueScreener_RetryFailed(t *testing.T) {
+	score, conf := 50.0, 60.0
+	goodID := uuid.New()
+
+	newRun := func(status models.ScreenerRunStatus) *models.ScreenerRun {
+		return &models.ScreenerRun{
+			ID:         uuid.New(),
+			Status:     status,
+			DurationMs: 1000,
+			Candidates: []models.ScreenerCandidate{
+				{Symbol: "GOOD", Score: &score, Confidence: &conf, Analyzed: true, RecommendationID: &goodID},
+				{Symbol: "FAIL", AnalysisError: "timeout"},
+			},
+			TopPicks: []uuid.UUID{goodID},
+		}
+	}
+
+	cfg := &config.ScreenerConfig{TopPicksCount: 3, AnalysisTimeoutSec: 120, MaxConcurrent: 5}
+
+	t.Run("analyzes only failed candidates and merges", func(t *testing.T) {
+		run := newRun(models.ScreenerRunStatusCompleted)
+		var analyzed []string
+		var updated bool
+		analysis := &MockAnalysisProvider{
+			AnalyzeSymbolFunc: func(ctx context.Context, symbol string) (*models.Recommendation, error) {
+				analyzed = append(analyzed, symbol)
+				rec := models.NewRecommendation(symbol, models.RecommendationActionBuy, "ok")
+				rec.FundamentalScore = 90
+				rec.Confidence = 90
+				rec.DataCompleteness = 100
+				return rec, nil
+			},
+		}
+		repo := &MockScreenerRepository{
+			GetScreenerRunFunc:    func(ctx context.Context, id uuid.UUID) (*models.ScreenerRun, error) { return run, nil },
+			UpdateScreenerRunFunc: func(ctx context.Context, r *models.ScreenerRun) error { updated = true; return nil },
+		}
+
+		got, err := NewValueScreener(&MockFMPService{}, analysis, repo, cfg).RetryFailed(context.Background(), run.ID)
+		if err != nil {
+			t.Fatalf("RetryFailed failed: %v", err)
+		}
+		if len(analyzed) != 1 || analyzed[0] != "FAIL" {
+			t.Errorf("analyzed %v, want [FAIL]", analyzed)
+		}
+		if !updated {
+			t.Error("run should be persisted after retry")
+		}
+		if len(got.FailedCandidates()) != 0 {
+			t.Error("no candidates should remain failed")
+		}
+		if len(got.TopPicks) != 2 || got.TopPicks[1] != goodID {
+			t.Errorf("TopPicks = %v, want retried pick ranked ahead of %v", got.TopPicks, goodID)
+		}
+		if got.DurationMs < 1000 {
+			t.Errorf("DurationMs = %d, should include original duration", got.DurationMs)
+		}
+	})
+
+	t.Run("running run is rejected", func(t *testing.T) {
+		run := newRun(models.ScreenerRunStatusRunning)
+		repo := &MockScreenerRepository{
+			GetScreenerRunFunc: func(ctx context.Context, id uuid.UUID) (*models.ScreenerRun, error) { return run, nil },
+		}
+
+		_, err := NewValueScreener(&MockFMPService{}, &MockAnalysisProvider{}, repo, cfg).RetryFailed(context.Background(), run.ID)
+		if !errors.Is(err, models.ErrScreenerRunInProgress) {
+			t.Errorf("err = %v, want ErrScreenerRunInProgress", err)
+		}
+	})
+
+	t.Run("rejected while the screener is running", func(t *testing.T) {
+		run := newRun(models.ScreenerRunStatusCompleted)
+		repo := &MockScreenerRepository{
+			GetScreenerRunFunc: func(ctx context.Context, id uuid.UUID) (*models.ScreenerRun, error) { return run, nil },
+		}
+		s := NewValueScreener(&MockFMPService{}, &MockAnalysisProvider{}, repo, cfg)
+		s.running.Lock()
+		defer s.running.Unlock()
+
+		if _, err := s.RetryFailed(context.Background(), run.ID); !errors.Is(err, models.ErrScreenerRunInProgress) {
+			t.Errorf("err = %v, want ErrScreenerRunInProgress", err)
+		}
+		if _, err := s.RunScreen(context.Background(), nil); !errors.Is(err, models.ErrScreenerRunInProgress) {
+			t.Errorf("RunScreen err = %v, want ErrScreenerRunInProgress", err)
+		}
+	})
+
+	t.Run("screen-only run is left alone", func(t *testing.T) {
+		run := newRun(models.ScreenerRunStatusCompleted)
+		run.Criteria.ScreenOnly = true
+		analysis := &MockAnalysisProvider{
+			AnalyzeSymbolFunc: func(ctx context.Context, symbol string) (*models.Recommendation, error) {
+				t.Errorf("analyzed %s in a screen-only run", symbol)
+				return nil, nil
+			},
+		}
+		repo := &MockScreenerRepository{
+			GetScreenerRunFunc: func(ctx context.Context, id uuid.UUID) (*models.ScreenerRun, error) { return run, nil },
+		}
+
+		if _, err := NewValueScreener(&MockFMPService{}, analysis, repo, cfg).RetryFailed(context.Background(), run.ID); err != nil {
+			t.Errorf("RetryFailed failed: %v", err)
+		}
+	})
+
+	t.Run("missing run returns nil", func(t *testing.T) {
+		repo := &MockScreenerRepository{}
+
+		got, err := NewValueScreener(&MockFMPService{}, &MockAnalysisProvider{}, repo, cfg).RetryFailed(context.Background(), uuid.New())
+		if err != nil || got != nil {
+			t.Errorf("RetryFailed() = %v, %v; want nil, nil", got, err)
+		}
+	})
 }
 
 func TestValueScreener_GetLatestPicks(t *testing.T) {
@@ -538,7 +1070,7 @@ func TestValueScreener_RunScreen_Timeout(t *testing.T) {
 	screener := NewValueScreener(fmp, analysis, repo, cfg)
 	ctx := context.Background()
 
-	run, err := screener.RunScreen(ctx)
+	run, err := screener.RunScreen(ctx, nil)
 
 	// Should complete even with timeout (analysis failures are handled gracefully)
 	if err != nil {
@@ -602,7 +1134,7 @@ func TestValueScreener_RunScreen_ConcurrencyLimit(t *testing.T) {
 	screener := NewValueScreener(fmp, analysis, repo, cfg)
 	ctx := context.Background()
 
-	_, err := screener.RunScreen(ctx)
+	_, err := screener.RunScreen(ctx, nil)
 
 	if err != nil {
 		t.Fatalf("RunScreen failed: %v", err)
@@ -0,0 +1,162 @@
+package screener
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/services"
+)
+
+const (
+	// maxThrottlePause caps the pause between candidates after repeated rate limits
+	maxThrottlePause = 30 * time.Second
+	// recoverAfterSuccesses is how many analyses must succeed before concurrency grows by one
+	recoverAfterSuccesses = 3
+	// maxRateLimitRequeues is how many times a rate-limited candidate is put back in line
+	maxRateLimitRequeues = 2
+)
+
+// adaptiveThrottle limits concurrent analyses and backs off when providers report rate
+// limits: each rate-limit error halves the concurrency and pauses new analyses, and a
+// streak of successes adds one slot back, up to the configured maximum.
+type adaptiveThrottle struct {
+	mu            sync.Mutex
+	changed       chan struct{} // Closed and replaced whenever a slot frees or the limit changes
+	limit         int
+	max           int
+	inFlight      int
+	basePause     time.Duration
+	pause         time.Duration
+	pauseUntil    time.Time
+	successStreak int
+	report        models.ThrottleReport
+}
+
+func newAdaptiveThrottle(maxConcurrent int, basePause time.Duration) *adaptiveThrottle {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	return &adaptiveThrottle{
+		changed:   make(chan struct{}),
+		limit:     maxConcurrent,
+		max:       maxConcurrent,
+		basePause: basePause,
+		report: models.ThrottleReport{
+			InitialConcurrency: maxConcurrent,
+			FinalConcurrency:   maxConcurrent,
+			MinConcurrency:     maxConcurrent,
+		},
+	}
+}
+
+// acquire blocks until a slot is free and any rate-limit pause has elapsed
+func (t *adaptiveThrottle) acquire(ctx context.Context) error {
+	for {
+		t.mu.Lock()
+		wait := time.Until(t.pauseUntil)
+		if t.inFlight < t.limit && wait <= 0 {
+			t.inFlight++
+			t.mu.Unlock()
+			return nil
+		}
+		changed := t.changed
+		t.mu.Unlock()
+
+		var timer *time.Timer
+		var pauseDone <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			pauseDone = timer.C
+		}
+
+		select {
+		case <-changed:
+		case <-pauseDone:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// release frees a slot and adapts the limit to the outcome of the analysis
+func (t *adaptiveThrottle) release(rateLimited bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.inFlight--
+	now := time.Now()
+
+	if rateLimited {
+		t.report.RateLimitErrors++
+		t.successStreak = 0
+		// Analyses already in flight when the first error hit will often fail too;
+		// only back off again once the current pause is over
+		if now.After(t.pauseUntil) {
+			t.limit = max(1, t.limit/2)
+			t.pause = min(max(t.pause*2, t.basePause), maxThrottlePause)
+			t.pauseUntil = now.Add(t.pause)
+			t.record(now, "rate limited")
+		}
+	} else {
+		t.successStreak++
+		if t.successStreak >= recoverAfterSuccesses && t.limit < t.max {
+			t.limit++
+			t.successStreak = 0
+			t.pause = 0
+			t.record(now, "recovered")
+		}
+	}
+
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+func (t *adaptiveThrottle) record(at time.Time, reason string) {
+	t.report.FinalConcurrency = t.limit
+	t.report.MinConcurrency = min(t.report.MinConcurrency, t.limit)
+	t.report.Adjustments = append(t.report.Adjustments, models.ThrottleAdjustment{
+		At:          at,
+		Concurrency: t.limit,
+		PauseMs:     t.pause.Milliseconds(),
+		Reason:      reason,
+	})
+	logger.Info("screener throttle adjusted",
+		"reason", reason,
+		"concurrency", t.limit,
+		"pause_ms", t.pause.Milliseconds())
+}
+
+// Report returns a snapshot of the throttle's adaptations so far
+func (t *adaptiveThrottle) Report() *models.ThrottleReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := t.report
+	report.Adjustments = append([]models.ThrottleAdjustment(nil), t.report.Adjustments...)
+	return &report
+}
+
+// mergeThrottleReports folds a later pass (such as a retry) into an earlier report
+func mergeThrottleReports(earlier, later *models.ThrottleReport) *models.ThrottleReport {
+	if earlier == nil {
+		return later
+	}
+	merged := *earlier
+	merged.FinalConcurrency = later.FinalConcurrency
+	merged.MinConcurrency = min(earlier.MinConcurrency, later.MinConcurrency)
+	merged.RateLimitErrors += later.RateLimitErrors
+	merged.Adjustments = append(append([]models.ThrottleAdjustment(nil), earlier.Adjustments...), later.Adjustments...)
+	return &merged
+}
+
+// isRateLimitError reports whether an analysis failed because a provider is throttling us
+func isRateLimitError(err error) bool {
+	return services.IsRateLimited(err)
+}
@@ -0,0 +1,136 @@
+package screener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/services"
+)
+
+func TestAdaptiveThrottle_HalvesOnRateLimit(t *testing.T) {
+	throttle := newAdaptiveThrottle(8, 0)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := throttle.acquire(ctx); err != nil {
+			t.Fatalf("acquire failed: %v", err)
+		}
+	}
+	throttle.release(true)
+	throttle.release(true)
+
+	report := throttle.Report()
+	if report.RateLimitErrors != 2 {
+		t.Errorf("RateLimitErrors = %d, want 2", report.RateLimitErrors)
+	}
+	if report.FinalConcurrency != 2 {
+		t.Errorf("FinalConcurrency = %d, want 2", report.FinalConcurrency)
+	}
+	if report.MinConcurrency != 2 {
+		t.Errorf("MinConcurrency = %d, want 2", report.MinConcurrency)
+	}
+	if len(report.Adjustments) != 2 {
+		t.Errorf("expected 2 adjustments, got %d", len(report.Adjustments))
+	}
+}
+
+func TestAdaptiveThrottle_NeverDropsBelowOne(t *testing.T) {
+	throttle := newAdaptiveThrottle(1, 0)
+	if err := throttle.acquire(context.Background()); err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	throttle.release(true)
+
+	if got := throttle.Report().FinalConcurrency; got != 1 {
+		t.Errorf("FinalConcurrency = %d, want 1", got)
+	}
+}
+
+func TestAdaptiveThrottle_RecoversAfterSuccesses(t *testing.T) {
+	throttle := newAdaptiveThrottle(4, 0)
+	ctx := context.Background()
+
+	if err := throttle.acquire(ctx); err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	throttle.release(true)
+
+	for i := 0; i < recoverAfterSuccesses; i++ {
+		if err := throttle.acquire(ctx); err != nil {
+			t.Fatalf("acquire failed: %v", err)
+		}
+		throttle.release(false)
+	}
+
+	report := throttle.Report()
+	if report.FinalConcurrency != 3 {
+		t.Errorf("FinalConcurrency = %d, want 3", report.FinalConcurrency)
+	}
+	if report.MinConcurrency != 2 {
+		t.Errorf("MinConcurrency = %d, want 2", report.MinConcurrency)
+	}
+	if last := report.Adjustments[len(report.Adjustments)-1]; last.Reason != "recovered" {
+		t.Errorf("last adjustment reason = %q, want recovered", last.Reason)
+	}
+}
+
+func TestAdaptiveThrottle_PauseBlocksAcquire(t *testing.T) {
+	throttle := newAdaptiveThrottle(2, time.Hour)
+	if err := throttle.acquire(context.Background()); err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	throttle.release(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := throttle.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire during pause = %v, want deadline exceeded", err)
+	}
+}
+
+func TestMergeThrottleReports(t *testing.T) {
+	later := &models.ThrottleReport{InitialConcurrency: 4, FinalConcurrency: 1, MinConcurrency: 1, RateLimitErrors: 2}
+	if got := mergeThrottleReports(nil, later); got != later {
+		t.Errorf("merge with nil earlier should return later")
+	}
+
+	earlier := &models.ThrottleReport{
+		InitialConcurrency: 4, FinalConcurrency: 4, MinConcurrency: 2, RateLimitErrors: 1,
+		Adjustments: []models.ThrottleAdjustment{{Reason: "rate limited"}},
+	}
+	merged := mergeThrottleReports(earlier, later)
+	if merged.InitialConcurrency != 4 || merged.FinalConcurrency != 1 || merged.MinConcurrency != 1 {
+		t.Errorf("merged concurrency = %+v", merged)
+	}
+	if merged.RateLimitErrors != 3 {
+		t.Errorf("RateLimitErrors = %d, want 3", merged.RateLimitErrors)
+	}
+	if len(earlier.Adjustments) != 1 {
+		t.Errorf("merge should not modify the earlier report")
+	}
+}
+
+func TestIsRateLimitError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{fmt.Errorf("news agent failed: %w", &services.StatusError{Provider: "NewsAPI", StatusCode: http.StatusTooManyRequests}), true},
+		{fmt.Errorf("%w: Alpha Vantage: daily quota", services.ErrRateLimited), true},
+		{&services.StatusError{Provider: "FMP", StatusCode: http.StatusServiceUnavailable}, false},
+		// Only the status counts, not a 429 appearing in the message
+		{errors.New("order 4291 rejected"), false},
+		{errors.New("context deadline exceeded"), false},
+	}
+	for _, tt := range tests {
+		if got := isRateLimitError(tt.err); got != tt.want {
+			t.Errorf("isRateLimitError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
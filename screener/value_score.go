@@ -6,10 +6,29 @@ import (
 	"trade-machine/models"
 )
 
+// Value score weights: 50% P/E, 30% P/B, 20% dividend
+const (
+	peWeight       = 0.5
+	pbWeight       = 0.3
+	dividendWeight = 0.2
+)
+
+// ValueScoreFormula describes how ValueScore combines its components
+const ValueScoreFormula = "0.5 × max(0, 100 - 5×P/E) + 0.3 × max(0, 100 - 40×P/B) + 0.2 × min(100, 20×DivYield%)"
+
 // ValueScore calculates a composite value score for a screener candidate.
 // Lower P/E and P/B ratios indicate better value, higher dividend yields are favorable.
 // Score range: 0-100, where higher is better value.
 func ValueScore(c models.ScreenerCandidate) float64 {
+	var total float64
+	for _, component := range valueScoreComponents(c) {
+		total += component.Contribution
+	}
+	return total
+}
+
+// valueScoreComponents returns the weighted inputs that make up ValueScore
+func valueScoreComponents(c models.ScreenerCandidate) []models.ScoreComponent {
 	// P/E Score: Lower is better
 	// P/E of 0 = 100 score (max value)
 	// P/E of 20 = 0 score
@@ -28,8 +47,32 @@ func ValueScore(c models.ScreenerCandidate) float64 {
 	// > 5% yield capped at 100
 	divScore := min(100, c.DividendYield*20)
 
-	// Weighted average: 50% P/E, 30% P/B, 20% dividend
-	return peScore*0.5 + pbScore*0.3 + divScore*0.2
+	return []models.ScoreComponent{
+		{Name: "P/E", Value: c.PERatio, Score: peScore, Weight: peWeight, Contribution: peScore * peWeight},
+		{Name: "P/B", Value: c.PBRatio, Score: pbScore, Weight: pbWeight, Contribution: pbScore * pbWeight},
+		{Name: "Dividend Yield", Value: c.DividendYield, Score: divScore, Weight: dividendWeight, Contribution: divScore * dividendWeight},
+	}
+}
+
+// marketCapPercentiles returns each candidate's market cap percentile (0-100) within the set
+func marketCapPercentiles(candidates []models.ScreenerCandidate) []float64 {
+	percentiles := make([]float64, len(candidates))
+	if len(candidates) < 2 {
+		for i := range percentiles {
+			percentiles[i] = 100
+		}
+		return percentiles
+	}
+	for i, c := range candidates {
+		below := 0
+		for _, other := range candidates {
+			if other.MarketCap < c.MarketCap {
+				below++
+			}
+		}
+		percentiles[i] = float64(below) / float64(len(candidates)-1) * 100
+	}
+	return percentiles
 }
 
 // RankByValueScore sorts candidates by their value score in descending order
@@ -39,9 +82,15 @@ func RankByValueScore(candidates []models.ScreenerCandidate, topN int) []models.
 		return candidates
 	}
 
-	// Calculate value scores for all candidates
+	// Calculate value scores and record how each was derived
+	percentiles := marketCapPercentiles(candidates)
 	for i := range candidates {
 		candidates[i].ValueScore = ValueScore(candidates[i])
+		candidates[i].ScoreBreakdown = &models.ScoreBreakdown{
+			Components:          valueScoreComponents(candidates[i]),
+			MarketCapPercentile: percentiles[i],
+			ValueFormula:        ValueScoreFormula,
+		}
 	}
 
 	// Sort by value score descending
@@ -56,31 +105,10 @@ func RankByValueScore(candidates []models.ScreenerCandidate, topN int) []models.
 	return candidates
 }
 
-// RankByAnalysisScore sorts analyzed candidates by their combined score × confidence
-// and returns the top N candidates.
-func RankByAnalysisScore(candidates []models.ScreenerCandidate, topN int) []models.ScreenerCandidate {
-	// Filter to only analyzed candidates
-	analyzed := make([]models.ScreenerCandidate, 0, len(candidates))
-	for _, c := range candidates {
-		if c.Analyzed && c.Score != nil && c.Confidence != nil {
-			analyzed = append(analyzed, c)
-		}
-	}
+// AnalysisScoreFormula describes how AnalysisScore combines the agent scores
+const AnalysisScoreFormula = "0.4 × Fundamental + 0.3 × Sentiment + 0.3 × Technical"
 
-	if len(analyzed) == 0 {
-		return analyzed
-	}
-
-	// Sort by combined score (score × confidence/100) descending
-	sort.Slice(analyzed, func(i, j int) bool {
-		scoreI := *analyzed[i].Score * (*analyzed[i].Confidence / 100)
-		scoreJ := *analyzed[j].Score * (*analyzed[j].Confidence / 100)
-		return scoreI > scoreJ
-	})
-
-	// Return top N
-	if topN > 0 && topN < len(analyzed) {
-		return analyzed[:topN]
-	}
-	return analyzed
+// AnalysisScore combines a recommendation's agent scores into a single candidate score
+func AnalysisScore(rec *models.Recommendation) float64 {
+	return rec.FundamentalScore*0.4 + rec.SentimentScore*0.3 + rec.TechnicalScore*0.3
 }
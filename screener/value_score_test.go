@@ -1,6 +1,7 @@
 package screener
 
 import (
+	"math"
 	"testing"
 
 	"trade-machine/models"
@@ -178,74 +179,44 @@ func TestRankByValueScore(t *testing.T) {
 	})
 }
 
-func TestRankByAnalysisScore(t *testing.T) {
-	score1, conf1 := 80.0, 90.0
-	score2, conf2 := 70.0, 80.0
-	score3, conf3 := 90.0, 60.0 // High score but low confidence
-
+func TestRankByValueScore_Breakdown(t *testing.T) {
 	candidates := []models.ScreenerCandidate{
-		{Symbol: "LOW", Score: &score2, Confidence: &conf2, Analyzed: true},   // 70 * 0.8 = 56
-		{Symbol: "HIGH", Score: &score1, Confidence: &conf1, Analyzed: true},  // 80 * 0.9 = 72
-		{Symbol: "RISKY", Score: &score3, Confidence: &conf3, Analyzed: true}, // 90 * 0.6 = 54
-		{Symbol: "UNANALYZED", Analyzed: false},
+		{Symbol: "SMALL", MarketCap: 1_000_000_000, PERatio: 10, PBRatio: 1.0, DividendYield: 2.0},
+		{Symbol: "MID", MarketCap: 5_000_000_000, PERatio: 12, PBRatio: 1.5, DividendYield: 1.0},
+		{Symbol: "LARGE", MarketCap: 50_000_000_000, PERatio: 15, PBRatio: 2.0, DividendYield: 0},
 	}
 
-	t.Run("rank by combined score", func(t *testing.T) {
-		ranked := RankByAnalysisScore(candidates, 0)
-
-		if len(ranked) != 3 {
-			t.Errorf("Should exclude unanalyzed, got %d", len(ranked))
-		}
+	ranked := RankByValueScore(candidates, 0)
+	wantPercentiles := map[string]float64{"SMALL": 0, "MID": 50, "LARGE": 100}
 
-		if ranked[0].Symbol != "HIGH" {
-			t.Errorf("First should be HIGH (best combined), got %s", ranked[0].Symbol)
+	for _, c := range ranked {
+		b := c.ScoreBreakdown
+		if b == nil {
+			t.Fatalf("%s: ScoreBreakdown not set", c.Symbol)
 		}
-		if ranked[1].Symbol != "LOW" {
-			t.Errorf("Second should be LOW, got %s", ranked[1].Symbol)
+		if b.MarketCapPercentile != wantPercentiles[c.Symbol] {
+			t.Errorf("%s: MarketCapPercentile = %v, want %v", c.Symbol, b.MarketCapPercentile, wantPercentiles[c.Symbol])
 		}
-		if ranked[2].Symbol != "RISKY" {
-			t.Errorf("Third should be RISKY (low confidence hurts), got %s", ranked[2].Symbol)
+		if b.ValueFormula != ValueScoreFormula {
+			t.Errorf("%s: ValueFormula = %q", c.Symbol, b.ValueFormula)
 		}
-	})
-
-	t.Run("rank top 1", func(t *testing.T) {
-		ranked := RankByAnalysisScore(candidates, 1)
-
-		if len(ranked) != 1 {
-			t.Errorf("Should return 1, got %d", len(ranked))
-		}
-		if ranked[0].Symbol != "HIGH" {
-			t.Errorf("Top 1 should be HIGH, got %s", ranked[0].Symbol)
-		}
-	})
-
-	t.Run("no analyzed candidates", func(t *testing.T) {
-		unanalyzed := []models.ScreenerCandidate{
-			{Symbol: "A", Analyzed: false},
-			{Symbol: "B", Analyzed: false},
+		if len(b.Components) != 3 {
+			t.Fatalf("%s: got %d components, want 3", c.Symbol, len(b.Components))
 		}
 
-		ranked := RankByAnalysisScore(unanalyzed, 5)
-
-		if len(ranked) != 0 {
-			t.Errorf("Should return empty for unanalyzed, got %d", len(ranked))
+		var sum float64
+		for _, comp := range b.Components {
+			sum += comp.Contribution
 		}
-	})
-
-	t.Run("missing score or confidence", func(t *testing.T) {
-		partial := []models.ScreenerCandidate{
-			{Symbol: "NO_SCORE", Confidence: &conf1, Analyzed: true},
-			{Symbol: "NO_CONF", Score: &score1, Analyzed: true},
-			{Symbol: "COMPLETE", Score: &score1, Confidence: &conf1, Analyzed: true},
+		if math.Abs(sum-c.ValueScore) > 0.0001 {
+			t.Errorf("%s: components sum to %v, ValueScore = %v", c.Symbol, sum, c.ValueScore)
 		}
+	}
+}
 
-		ranked := RankByAnalysisScore(partial, 0)
-
-		if len(ranked) != 1 {
-			t.Errorf("Should only include complete candidates, got %d", len(ranked))
-		}
-		if ranked[0].Symbol != "COMPLETE" {
-			t.Errorf("Should be COMPLETE, got %s", ranked[0].Symbol)
-		}
-	})
+func TestAnalysisScore(t *testing.T) {
+	rec := &models.Recommendation{FundamentalScore: 50, SentimentScore: 20, TechnicalScore: -10}
+	if got := AnalysisScore(rec); math.Abs(got-23) > 0.0001 {
+		t.Errorf("AnalysisScore() = %v, want 23", got)
+	}
 }
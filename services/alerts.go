@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"trade-machine/events"
+	"trade-machine/models"
+)
+
+const (
+	// alertBufferSize is how many alerts can wait to be saved before new ones are dropped
+	alertBufferSize = 50
+	// alertRepeatInterval is the minimum gap between alerts of the same kind for a provider,
+	// so a breaker that keeps reopening while its provider is down alerts only once
+	alertRepeatInterval = 15 * time.Minute
+)
+
+// ProviderAlertStore persists provider alerts
+type ProviderAlertStore interface {
+	SaveProviderAlert(ctx context.Context, alert *models.ProviderAlert) error
+}
+
+// AlertNotifier raises an alert when a provider's circuit breaker opens or its API quota
+// runs out, so degraded analyses are not silent. Alerts are saved in the background so
+// raising one never blocks a request.
+type AlertNotifier struct {
+	store      ProviderAlertStore
+	alerts     chan *models.ProviderAlert
+	mu         sync.Mutex
+	quietUntil map[string]time.Time // Provider and kind to the time repeat alerts resume
+	now        func() time.Time
+}
+
+// NewAlertNotifier creates a notifier that saves alerts to store
+func NewAlertNotifier(store ProviderAlertStore) *AlertNotifier {
+	return &AlertNotifier{
+		store:      store,
+		alerts:     make(chan *models.ProviderAlert, alertBufferSize),
+		quietUntil: make(map[string]time.Time),
+		now:        time.Now,
+	}
+}
+
+// Notify queues an alert unless the provider raised one of the same kind recently
+func (n *AlertNotifier) Notify(alert *models.ProviderAlert) {
+	key := alert.Provider + "/" + string(alert.Kind)
+	now := n.now()
+
+	n.mu.Lock()
+	if now.Before(n.quietUntil[key]) {
+		n.mu.Unlock()
+		return
+	}
+	until := now.Add(alertRepeatInterval)
+	if alert.RecoverAt != nil && alert.RecoverAt.After(until) {
+		until = *alert.RecoverAt
+	}
+	n.quietUntil[key] = until
+	n.mu.Unlock()
+
+	logger.Warn("provider alert",
+		"provider", alert.Provider,
+		"kind", string(alert.Kind),
+		"error", alert.ErrorSample,
+		"recover_at", alert.RecoverAt)
+
+	select {
+	case n.alerts <- alert:
+	default:
+		logger.Warn("provider alert buffer full, dropping alert", "provider", alert.Provider)
+	}
+}
+
+// HandleEvent raises an alert for a circuit breaker that opened. Subscribe it to
+// events.BreakerOpened.
+func (n *AlertNotifier) HandleEvent(e events.Event) {
+	if b, ok := e.BreakerOpen(); ok {
+		recoverAt := b.RecoverAt
+		n.Notify(models.NewProviderAlert(b.Provider, models.ProviderAlertBreakerOpen, b.LastError, &recoverAt))
+	}
+}
+
+// Run saves queued alerts until ctx is cancelled, then saves whatever is still queued
+func (n *AlertNotifier) Run(ctx context.Context) {
+	save := func(ctx context.Context, alert *models.ProviderAlert) {
+		if err := n.store.SaveProviderAlert(ctx, alert); err != nil {
+			logger.Warn("failed to save provider alert", "provider", alert.Provider, "error", err)
+		}
+	}
+
+	for {
+		select {
+		case alert := <-n.alerts:
+			save(ctx, alert)
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			defer cancel()
+			for {
+				select {
+				case alert := <-n.alerts:
+					save(shutdownCtx, alert)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// activeNotifier receives quota alerts from every provider client; nil disables them
+var activeNotifier atomic.Pointer[AlertNotifier]
+
+// SetAlertNotifier sets the notifier provider alerts are raised on. Pass nil to stop alerting.
+func SetAlertNotifier(n *AlertNotifier) {
+	activeNotifier.Store(n)
+}
+
+// notifyProviderAlert raises an alert on the active notifier, if any
+func notifyProviderAlert(provider string, kind models.ProviderAlertKind, errorSample string, recoverAt *time.Time) {
+	n := activeNotifier.Load()
+	if n == nil {
+		return
+	}
+	n.Notify(models.NewProviderAlert(provider, kind, redactErrorSample(errorSample), recoverAt))
+}
+
+// notifyQuotaExhausted raises a quota alert for a rate-limited response
+func notifyQuotaExhausted(provider string, req *http.Request, resp *http.Response) {
+	sample := fmt.Sprintf("%s %s: %s", req.Method, ledgerEndpoint(req), resp.Status)
+	notifyProviderAlert(provider, models.ProviderAlertQuotaExhausted, sample, quotaRecovery(resp.Header, time.Now()))
+}
+
+var queryString = regexp.MustCompile(`\?[^\s"]*`)
+
+// redactErrorSample drops query strings from URLs quoted in an error, since they carry API keys
+func redactErrorSample(s string) string {
+	return queryString.ReplaceAllString(s, "")
+}
+
+// quotaRecovery returns when a rate-limited provider expects to accept requests again,
+// from Retry-After (seconds or an HTTP date) or X-RateLimit-Reset (Unix seconds). It
+// returns nil when the response gives no hint.
+func quotaRecovery(h http.Header, now time.Time) *time.Time {
+	if v := h.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil {
+			at := now.Add(time.Duration(seconds) * time.Second)
+			return &at
+		}
+		if at, err := http.ParseTime(v); err == nil {
+			return &at
+		}
+	}
+	if v := h.Get("X-RateLimit-Reset"); v != "" {
+		if unix, err := strconv.ParseInt(v, 10, 64); err == nil {
+			at := time.Unix(unix, 0)
+			return &at
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"trade-machine/events"
+	"trade-machine/models"
+)
+
+// memoryAlertStore keeps saved alerts in memory
+type memoryAlertStore struct {
+	mu     sync.Mutex
+	alerts []*models.ProviderAlert
+}
+
+func (s *memoryAlertStore) SaveProviderAlert(ctx context.Context, alert *models.ProviderAlert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alerts = append(s.alerts, alert)
+	return nil
+}
+
+// flushAlerts runs the notifier with a cancelled context so every queued alert is saved
+func flushAlerts(n *AlertNotifier) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n.Run(ctx)
+}
+
+func TestAlertNotifier_BreakerOpen(t *testing.T) {
+	store := &memoryAlertStore{}
+	notifier := NewAlertNotifier(store)
+	bus := events.NewBus()
+	bus.Subscribe("provider_alerts", notifier.HandleEvent, events.BreakerOpened)
+	events.SetDefault(bus)
+	defer events.SetDefault(nil)
+
+	registry := NewCircuitBreakerRegistry(CircuitBreakerConfig{MaxRequests: 1, Interval: time.Minute, Timeout: 30 * time.Second})
+	for i := 0; i < 5; i++ {
+		registry.Execute(context.Background(), BreakerFMP, func() (any, error) {
+			return nil, errors.New(`Get "https://example.com/api/v3/quote/AAPL?apikey=secret": connection refused`)
+		})
+	}
+	bus.Close()
+	flushAlerts(notifier)
+
+	if len(store.alerts) != 1 {
+		t.Fatalf("saved %d alerts, want 1", len(store.alerts))
+	}
+	alert := store.alerts[0]
+	if alert.Provider != BreakerFMP || alert.Kind != models.ProviderAlertBreakerOpen {
+		t.Errorf("alert = %+v, want an fmp breaker_open alert", alert)
+	}
+	if !strings.Contains(alert.ErrorSample, "connection refused") || strings.Contains(alert.ErrorSample, "secret") {
+		t.Errorf("ErrorSample = %q, want the last error without its query string", alert.ErrorSample)
+	}
+	if alert.RecoverAt == nil || time.Until(*alert.RecoverAt) < 25*time.Second {
+		t.Errorf("RecoverAt = %v, want about 30s from now", alert.RecoverAt)
+	}
+}
+
+func TestAlertNotifier_SuppressesRepeats(t *testing.T) {
+	store := &memoryAlertStore{}
+	notifier := NewAlertNotifier(store)
+	now := time.Now()
+	notifier.now = func() time.Time { return now }
+
+	notifier.Notify(models.NewProviderAlert(BreakerNewsAPI, models.ProviderAlertQuotaExhausted, "429", nil))
+	notifier.Notify(models.NewProviderAlert(BreakerNewsAPI, models.ProviderAlertQuotaExhausted, "429", nil))
+	notifier.Notify(models.NewProviderAlert(BreakerNewsAPI, models.ProviderAlertBreakerOpen, "timeout", nil))
+	now = now.Add(alertRepeatInterval)
+	notifier.Notify(models.NewProviderAlert(BreakerNewsAPI, models.ProviderAlertQuotaExhausted, "429", nil))
+	flushAlerts(notifier)
+
+	if len(store.alerts) != 3 {
+		t.Errorf("saved %d alerts, want 3: repeats within the interval are suppressed", len(store.alerts))
+	}
+}
+
+func TestLedgerTransport_QuotaAlert(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	store := &memoryAlertStore{}
+	notifier := NewAlertNotifier(store)
+	SetAlertNotifier(notifier)
+	defer SetAlertNotifier(nil)
+
+	resp, err := newLedgerHTTPClient(BreakerNewsAPI, time.Second).Get(server.URL + "/v2/everything?apiKey=secret")
+	if err != nil {
+		t.Fatalf("Get error = %v", err)
+	}
+	resp.Body.Close()
+	flushAlerts(notifier)
+
+	if len(store.alerts) != 1 {
+		t.Fatalf("saved %d alerts, want 1", len(store.alerts))
+	}
+	alert := store.alerts[0]
+	if alert.Kind != models.ProviderAlertQuotaExhausted || alert.ErrorSample != "GET /v2/everything: 429 Too Many Requests" {
+		t.Errorf("alert = %+v, want a quota alert sampling the endpoint and status", alert)
+	}
+	if alert.RecoverAt == nil || time.Until(*alert.RecoverAt) < 115*time.Second {
+		t.Errorf("RecoverAt = %v, want about 2 minutes from now", alert.RecoverAt)
+	}
+}
+
+func TestAlphaVantage_NoticeRaisesQuotaAlert(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"Note":"Thank you for using Alpha Vantage! Our standard API call frequency is 5 calls per minute."}`))
+	}))
+	defer server.Close()
+
+	store := &memoryAlertStore{}
+	notifier := NewAlertNotifier(store)
+	SetAlertNotifier(notifier)
+	defer SetAlertNotifier(nil)
+
+	service := NewAlphaVantageService("test-key")
+	service.baseURL = server.URL
+	if _, err := service.GetQuote(context.Background(), "AAPL"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("GetQuote error = %v, want ErrRateLimited", err)
+	}
+	flushAlerts(notifier)
+
+	if len(store.alerts) != 1 {
+		t.Fatalf("saved %d alerts, want 1", len(store.alerts))
+	}
+	alert := store.alerts[0]
+	if alert.Provider != BreakerAlphaVantage || alert.Kind != models.ProviderAlertQuotaExhausted {
+		t.Errorf("alert = %+v, want an Alpha Vantage quota alert", alert)
+	}
+	if alert.RecoverAt == nil || time.Until(*alert.RecoverAt) > time.Minute {
+		t.Errorf("RecoverAt = %v, want within a minute", alert.RecoverAt)
+	}
+}
+
+func TestQuotaRecovery(t *testing.T) {
+	now := time.Date(2024, 6, 3, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   *time.Time
+	}{
+		{"seconds", http.Header{"Retry-After": {"60"}}, timePtr(now.Add(time.Minute))},
+		{"http date", http.Header{"Retry-After": {"Mon, 03 Jun 2024 15:00:00 GMT"}}, timePtr(now.Add(time.Hour))},
+		{"reset", http.Header{"X-Ratelimit-Reset": {"1717426800"}}, timePtr(now.Add(time.Hour))},
+		{"none", http.Header{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := quotaRecovery(tt.header, now)
+			if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+				t.Errorf("quotaRecovery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
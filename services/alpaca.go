@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"sync"
 	"time"
 
 	"trade-machine/models"
@@ -16,8 +19,14 @@ import (
 type alpacaTradeClient interface {
 	GetAccount() (*alpaca.Account, error)
 	PlaceOrder(req alpaca.PlaceOrderRequest) (*alpaca.Order, error)
+	GetOrder(orderID string) (*alpaca.Order, error)
+	CancelOrder(orderID string) error
 	GetPositions() ([]alpaca.Position, error)
 	GetPosition(symbol string) (*alpaca.Position, error)
+	GetAsset(symbol string) (*alpaca.Asset, error)
+	GetAccountActivities(req alpaca.GetAccountActivitiesRequest) ([]alpaca.AccountActivity, error)
+	GetCalendar(req alpaca.GetCalendarRequest) ([]alpaca.CalendarDay, error)
+	GetPortfolioHistory(req alpaca.GetPortfolioHistoryRequest) (*alpaca.PortfolioHistory, error)
 }
 
 // alpacaDataClient defines the interface for Alpaca market data operations (for testing)
@@ -27,35 +36,122 @@ type alpacaDataClient interface {
 	GetBars(symbol string, req marketdata.GetBarsRequest) ([]marketdata.Bar, error)
 }
 
+// accountActivitiesPageSize is the largest page Alpaca returns for account activities
+const accountActivitiesPageSize = 100
+
+// calendarLookahead is how far past today the trading calendar is fetched, enough to find
+// the next open across a long weekend with a holiday
+const calendarLookahead = 10 * 24 * time.Hour
+
 // AlpacaService handles communication with Alpaca for trading and market data
 type AlpacaService struct {
 	tradeClient alpacaTradeClient
 	dataClient  alpacaDataClient
+	now         func() time.Time
+
+	calendarMu   sync.Mutex
+	calendarDate time.Time // Market date the cached calendar was fetched for
+	calendarDays []models.TradingDay
 }
 
 // NewAlpacaService creates a new AlpacaService instance
 func NewAlpacaService(apiKey, apiSecret, baseURL string) *AlpacaService {
 	tradeClient := alpaca.NewClient(alpaca.ClientOpts{
-		APIKey:    apiKey,
-		APISecret: apiSecret,
-		BaseURL:   baseURL,
+		APIKey:     apiKey,
+		APISecret:  apiSecret,
+		BaseURL:    baseURL,
+		HTTPClient: newLedgerHTTPClient(BreakerAlpaca, 10*time.Second),
 	})
 
 	dataClient := marketdata.NewClient(marketdata.ClientOpts{
-		APIKey:    apiKey,
-		APISecret: apiSecret,
+		APIKey:     apiKey,
+		APISecret:  apiSecret,
+		HTTPClient: newLedgerHTTPClient(BreakerAlpaca, 10*time.Second),
 	})
 
 	return &AlpacaService{
 		tradeClient: tradeClient,
 		dataClient:  dataClient,
+		now:         time.Now,
 	}
 }
 
+// alpacaRead runs an idempotent Alpaca request under the active retry policy. API errors
+// are reported as StatusErrors, so rejected requests aren't retried or counted against the
+// circuit breaker. Orders are never placed through it, since a retry could fill twice.
+func alpacaRead[T any](ctx context.Context, call func() (T, error)) (T, error) {
+	return Retry(ctx, func() (T, error) {
+		result, err := call()
+		var apiErr *alpaca.APIError
+		if errors.As(err, &apiErr) {
+			return result, &StatusError{Provider: "Alpaca", StatusCode: apiErr.StatusCode, Message: apiErr.Message}
+		}
+		return result, err
+	})
+}
+
+// clock returns the current time, defaulting to time.Now
+func (s *AlpacaService) clock() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+	return s.now()
+}
+
+// MarketStatus returns the current session using Alpaca's trading calendar, so market
+// holidays and early closes are accounted for, with the next open and close. If the
+// calendar cannot be fetched the standard hours are used instead.
+func (s *AlpacaService) MarketStatus(ctx context.Context) models.MarketStatus {
+	now := s.clock()
+	days, err := s.tradingDays(ctx, now)
+	if err != nil {
+		return models.StandardHoursStatus(now, "trading calendar unavailable")
+	}
+	return models.MarketStatusFromCalendar(now, days)
+}
+
+// tradingDays returns the calendar entries from t's date in Eastern time through
+// calendarLookahead, in date order. Days the exchange is closed are left out. The
+// calendar is fetched once per market date.
+func (s *AlpacaService) tradingDays(ctx context.Context, t time.Time) ([]models.TradingDay, error) {
+	et := t.In(models.MarketLocation())
+	date := time.Date(et.Year(), et.Month(), et.Day(), 0, 0, 0, 0, et.Location())
+
+	s.calendarMu.Lock()
+	defer s.calendarMu.Unlock()
+	if s.calendarDays != nil && s.calendarDate.Equal(date) {
+		return s.calendarDays, nil
+	}
+
+	days, err := WithCircuitBreaker(ctx, BreakerAlpaca, func() ([]alpaca.CalendarDay, error) {
+		return alpacaRead(ctx, func() ([]alpaca.CalendarDay, error) {
+			return s.tradeClient.GetCalendar(alpaca.GetCalendarRequest{Start: date, End: date.Add(calendarLookahead)})
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trading calendar: %w", err)
+	}
+
+	tradingDays := make([]models.TradingDay, 0, len(days))
+	for _, d := range days {
+		open, err := time.ParseInLocation("2006-01-02 15:04", d.Date+" "+d.Open, et.Location())
+		if err != nil {
+			return nil, fmt.Errorf("invalid calendar open %q: %w", d.Open, err)
+		}
+		closeAt, err := time.ParseInLocation("2006-01-02 15:04", d.Date+" "+d.Close, et.Location())
+		if err != nil {
+			return nil, fmt.Errorf("invalid calendar close %q: %w", d.Close, err)
+		}
+		tradingDays = append(tradingDays, models.TradingDay{Open: open, Close: closeAt})
+	}
+	s.calendarDate, s.calendarDays = date, tradingDays
+	return tradingDays, nil
+}
+
 // GetAccount returns the current account information
 func (s *AlpacaService) GetAccount(ctx context.Context) (*models.Account, error) {
 	return WithCircuitBreaker(ctx, BreakerAlpaca, func() (*models.Account, error) {
-		account, err := s.tradeClient.GetAccount()
+		account, err := alpacaRead(ctx, s.tradeClient.GetAccount)
 		if err != nil {
 			return nil, err
 		}
@@ -81,10 +177,20 @@ func (s *AlpacaService) GetAccount(ctx context.Context) (*models.Account, error)
 // GetQuote returns the latest quote for a symbol
 func (s *AlpacaService) GetQuote(ctx context.Context, symbol string) (*models.Quote, error) {
 	return WithCircuitBreaker(ctx, BreakerAlpaca, func() (*models.Quote, error) {
-		quote, err := s.dataClient.GetLatestQuote(symbol, marketdata.GetLatestQuoteRequest{})
+		quote, err := alpacaRead(ctx, func() (*marketdata.Quote, error) {
+			return s.dataClient.GetLatestQuote(symbol, marketdata.GetLatestQuoteRequest{})
+		})
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("failed to get quote for %s: %w: %w", symbol, models.ErrSymbolNotFound, err)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to get quote for %s: %w", symbol, err)
 		}
+		if quote == nil {
+			// Alpaca leaves symbols it has no quote for out of the response
+			return nil, fmt.Errorf("failed to get quote for %s: %w", symbol, models.ErrSymbolNotFound)
+		}
 
 		return &models.Quote{
 			Symbol:    symbol,
@@ -93,6 +199,7 @@ func (s *AlpacaService) GetQuote(ctx context.Context, symbol string) (*models.Qu
 			BidSize:   int64(quote.BidSize),
 			AskSize:   int64(quote.AskSize),
 			Timestamp: quote.Timestamp,
+			Session:   models.MarketSessionAt(quote.Timestamp),
 		}, nil
 	})
 }
@@ -100,7 +207,9 @@ func (s *AlpacaService) GetQuote(ctx context.Context, symbol string) (*models.Qu
 // GetLatestTrade returns the latest trade for a symbol
 func (s *AlpacaService) GetLatestTrade(ctx context.Context, symbol string) (*models.Quote, error) {
 	return WithCircuitBreaker(ctx, BreakerAlpaca, func() (*models.Quote, error) {
-		trade, err := s.dataClient.GetLatestTrade(symbol, marketdata.GetLatestTradeRequest{})
+		trade, err := alpacaRead(ctx, func() (*marketdata.Trade, error) {
+			return s.dataClient.GetLatestTrade(symbol, marketdata.GetLatestTradeRequest{})
+		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to get trade for %s: %w", symbol, err)
 		}
@@ -110,6 +219,7 @@ func (s *AlpacaService) GetLatestTrade(ctx context.Context, symbol string) (*mod
 			Last:      decimal.NewFromFloat(trade.Price),
 			Volume:    int64(trade.Size),
 			Timestamp: trade.Timestamp,
+			Session:   models.MarketSessionAt(trade.Timestamp),
 		}, nil
 	})
 }
@@ -117,10 +227,12 @@ func (s *AlpacaService) GetLatestTrade(ctx context.Context, symbol string) (*mod
 // GetBars returns historical bars for a symbol
 func (s *AlpacaService) GetBars(ctx context.Context, symbol string, start, end time.Time, timeframe marketdata.TimeFrame) ([]marketdata.Bar, error) {
 	return WithCircuitBreaker(ctx, BreakerAlpaca, func() ([]marketdata.Bar, error) {
-		bars, err := s.dataClient.GetBars(symbol, marketdata.GetBarsRequest{
-			TimeFrame: timeframe,
-			Start:     start,
-			End:       end,
+		bars, err := alpacaRead(ctx, func() ([]marketdata.Bar, error) {
+			return s.dataClient.GetBars(symbol, marketdata.GetBarsRequest{
+				TimeFrame: timeframe,
+				Start:     start,
+				End:       end,
+			})
 		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to get bars for %s: %w", symbol, err)
@@ -138,37 +250,123 @@ func (s *AlpacaService) GetDailyBars(ctx context.Context, symbol string, days in
 	return s.GetBars(ctx, symbol, start, end, marketdata.OneDay)
 }
 
-// PlaceOrder places a trade order
-func (s *AlpacaService) PlaceOrder(ctx context.Context, symbol string, quantity decimal.Decimal, side models.TradeSide, orderType string) (string, error) {
+// GetAverageDailyVolume returns the mean share volume of the completed sessions in the last
+// N days, or 0 if there are none. Today's bar is left out while the session is still
+// trading, since its partial volume would drag the average down.
+func (s *AlpacaService) GetAverageDailyVolume(ctx context.Context, symbol string, days int) (int64, error) {
+	bars, err := s.GetDailyBars(ctx, symbol, days)
+	if err != nil {
+		return 0, err
+	}
+
+	today := models.MarketDate(time.Now())
+	var total, sessions uint64
+	for _, bar := range bars {
+		if !models.MarketDate(bar.Timestamp).Before(today) {
+			continue
+		}
+		total += bar.Volume
+		sessions++
+	}
+	if sessions == 0 {
+		return 0, nil
+	}
+	return int64(total / sessions), nil
+}
+
+// GetEquityHistory returns the account's end-of-day equity for the last N days, oldest
+// first, skipping days before the account was funded
+func (s *AlpacaService) GetEquityHistory(ctx context.Context, days int) ([]models.DailyClose, error) {
+	return WithCircuitBreaker(ctx, BreakerAlpaca, func() ([]models.DailyClose, error) {
+		history, err := alpacaRead(ctx, func() (*alpaca.PortfolioHistory, error) {
+			return s.tradeClient.GetPortfolioHistory(alpaca.GetPortfolioHistoryRequest{
+				Period:    fmt.Sprintf("%dD", days),
+				TimeFrame: alpaca.TimeFrame("1D"),
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get portfolio history: %w", err)
+		}
+
+		closes := make([]models.DailyClose, 0, len(history.Equity))
+		for i, equity := range history.Equity {
+			if i >= len(history.Timestamp) || !equity.IsPositive() {
+				continue
+			}
+			closes = append(closes, models.DailyClose{
+				Date:  time.Unix(history.Timestamp[i], 0),
+				Close: equity.InexactFloat64(),
+			})
+		}
+		return closes, nil
+	})
+}
+
+// PlaceOrder places a trade order. Market orders are rejected with an OutsideSessionError
+// outside the regular session, including market holidays and after early closes; limit
+// orders placed during pre-market or after-hours are flagged for extended-hours execution.
+// Limit and stop-limit orders must carry a limit price.
+// Alpaca opens a short when a sell exceeds the long position, and covers one with a buy.
+// Orders with a bracket are placed good-til-canceled, so the stop-loss and take-profit legs
+// keep guarding the position after the day ends, and are never sent to extended hours.
+func (s *AlpacaService) PlaceOrder(ctx context.Context, req models.OrderRequest) (string, error) {
+	if err := req.Validate(); err != nil {
+		return "", err
+	}
+
+	var alpacaOrderType alpaca.OrderType
+	switch req.OrderType() {
+	case models.OrderTypeLimit:
+		alpacaOrderType = alpaca.Limit
+	case models.OrderTypeStop:
+		alpacaOrderType = alpaca.Stop
+	case models.OrderTypeStopLimit:
+		alpacaOrderType = alpaca.StopLimit
+	default:
+		alpacaOrderType = alpaca.Market
+	}
+
+	status := s.MarketStatus(ctx)
+	session := status.Session
+	if alpacaOrderType == alpaca.Market && session != models.MarketSessionRegular {
+		return "", &models.OutsideSessionError{Status: status}
+	}
+
+	var limitPrice *decimal.Decimal
+	if req.NeedsLimitPrice() {
+		price := *req.LimitPrice
+		limitPrice = &price
+	}
+
 	return WithCircuitBreaker(ctx, BreakerAlpaca, func() (string, error) {
-		qty := quantity
+		qty := req.Quantity
 
 		var alpacaSide alpaca.Side
-		if side == models.TradeSideBuy {
+		if req.Side == models.TradeSideBuy {
 			alpacaSide = alpaca.Buy
 		} else {
 			alpacaSide = alpaca.Sell
 		}
 
-		var alpacaOrderType alpaca.OrderType
-		switch orderType {
-		case "limit":
-			alpacaOrderType = alpaca.Limit
-		case "stop":
-			alpacaOrderType = alpaca.Stop
-		case "stop_limit":
-			alpacaOrderType = alpaca.StopLimit
-		default:
-			alpacaOrderType = alpaca.Market
-		}
-
-		order, err := s.tradeClient.PlaceOrder(alpaca.PlaceOrderRequest{
-			Symbol:      symbol,
-			Qty:         &qty,
-			Side:        alpacaSide,
-			Type:        alpacaOrderType,
-			TimeInForce: alpaca.Day,
-		})
+		placeReq := alpaca.PlaceOrderRequest{
+			Symbol:        req.Symbol,
+			Qty:           &qty,
+			Side:          alpacaSide,
+			Type:          alpacaOrderType,
+			TimeInForce:   alpaca.Day,
+			LimitPrice:    limitPrice,
+			ExtendedHours: alpacaOrderType == alpaca.Limit && session.IsExtendedHours(),
+		}
+		if req.Bracket != nil {
+			stopLoss, takeProfit := req.Bracket.StopLoss, req.Bracket.TakeProfit
+			placeReq.OrderClass = alpaca.Bracket
+			placeReq.TimeInForce = alpaca.GTC
+			placeReq.ExtendedHours = false
+			placeReq.StopLoss = &alpaca.StopLoss{StopPrice: &stopLoss}
+			placeReq.TakeProfit = &alpaca.TakeProfit{LimitPrice: &takeProfit}
+		}
+
+		order, err := s.tradeClient.PlaceOrder(placeReq)
 		if err != nil {
 			return "", fmt.Errorf("failed to place order: %w", err)
 		}
@@ -177,10 +375,75 @@ func (s *AlpacaService) PlaceOrder(ctx context.Context, symbol string, quantity
 	})
 }
 
+// GetOrder returns the broker's current state of a previously placed order
+func (s *AlpacaService) GetOrder(ctx context.Context, orderID string) (*models.BrokerOrder, error) {
+	return WithCircuitBreaker(ctx, BreakerAlpaca, func() (*models.BrokerOrder, error) {
+		o, err := alpacaRead(ctx, func() (*alpaca.Order, error) {
+			return s.tradeClient.GetOrder(orderID)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get order %s: %w", orderID, err)
+		}
+		order := toBrokerOrder(o)
+		return &order, nil
+	})
+}
+
+// CancelOrder asks Alpaca to cancel an open order, such as a leg of a bracket order
+func (s *AlpacaService) CancelOrder(ctx context.Context, orderID string) error {
+	_, err := WithCircuitBreaker(ctx, BreakerAlpaca, func() (struct{}, error) {
+		if err := s.tradeClient.CancelOrder(orderID); err != nil {
+			return struct{}{}, fmt.Errorf("failed to cancel order %s: %w", orderID, err)
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// toBrokerOrder maps an Alpaca order, with the legs of a bracket order, to a BrokerOrder
+func toBrokerOrder(o *alpaca.Order) models.BrokerOrder {
+	side := models.TradeSideBuy
+	if o.Side == alpaca.Sell {
+		side = models.TradeSideSell
+	}
+
+	quantity := decimal.Zero
+	if o.Qty != nil {
+		quantity = *o.Qty
+	}
+
+	canceledAt := o.CanceledAt
+	if canceledAt == nil {
+		canceledAt = o.ExpiredAt
+	}
+	if canceledAt == nil {
+		canceledAt = o.FailedAt
+	}
+
+	order := models.BrokerOrder{
+		ID:             o.ID,
+		Symbol:         o.Symbol,
+		Side:           side,
+		Type:           string(o.Type),
+		Status:         o.Status,
+		Quantity:       quantity,
+		FilledQuantity: o.FilledQty,
+		FilledAvgPrice: o.FilledAvgPrice,
+		SubmittedAt:    o.SubmittedAt,
+		UpdatedAt:      o.UpdatedAt,
+		FilledAt:       o.FilledAt,
+		CanceledAt:     canceledAt,
+	}
+	for i := range o.Legs {
+		order.Legs = append(order.Legs, toBrokerOrder(&o.Legs[i]))
+	}
+	return order
+}
+
 // GetPositions returns all current positions
 func (s *AlpacaService) GetPositions(ctx context.Context) ([]models.Position, error) {
 	return WithCircuitBreaker(ctx, BreakerAlpaca, func() ([]models.Position, error) {
-		alpacaPositions, err := s.tradeClient.GetPositions()
+		alpacaPositions, err := alpacaRead(ctx, s.tradeClient.GetPositions)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get positions: %w", err)
 		}
@@ -204,7 +467,7 @@ func (s *AlpacaService) GetPositions(ctx context.Context) ([]models.Position, er
 
 			positions = append(positions, models.Position{
 				Symbol:        ap.Symbol,
-				Quantity:      ap.Qty,
+				Quantity:      ap.Qty.Abs(), // Alpaca reports short quantities as negative
 				AvgEntryPrice: ap.AvgEntryPrice,
 				CurrentPrice:  currentPrice,
 				UnrealizedPL:  unrealizedPL,
@@ -216,10 +479,17 @@ func (s *AlpacaService) GetPositions(ctx context.Context) ([]models.Position, er
 	})
 }
 
-// GetPosition returns a specific position
+// GetPosition returns a specific position. A symbol not held is an error wrapping
+// models.ErrPositionNotFound.
 func (s *AlpacaService) GetPosition(ctx context.Context, symbol string) (*models.Position, error) {
 	return WithCircuitBreaker(ctx, BreakerAlpaca, func() (*models.Position, error) {
-		ap, err := s.tradeClient.GetPosition(symbol)
+		ap, err := alpacaRead(ctx, func() (*alpaca.Position, error) {
+			return s.tradeClient.GetPosition(symbol)
+		})
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("failed to get position for %s: %w: %w", symbol, models.ErrPositionNotFound, err)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to get position for %s: %w", symbol, err)
 		}
@@ -241,7 +511,7 @@ func (s *AlpacaService) GetPosition(ctx context.Context, symbol string) (*models
 
 		return &models.Position{
 			Symbol:        ap.Symbol,
-			Quantity:      ap.Qty,
+			Quantity:      ap.Qty.Abs(),
 			AvgEntryPrice: ap.AvgEntryPrice,
 			CurrentPrice:  currentPrice,
 			UnrealizedPL:  unrealizedPL,
@@ -249,3 +519,95 @@ func (s *AlpacaService) GetPosition(ctx context.Context, symbol string) (*models
 		}, nil
 	})
 }
+
+// GetShortAvailability returns whether Alpaca can borrow a symbol's shares for a short sale
+func (s *AlpacaService) GetShortAvailability(ctx context.Context, symbol string) (*models.ShortAvailability, error) {
+	return WithCircuitBreaker(ctx, BreakerAlpaca, func() (*models.ShortAvailability, error) {
+		asset, err := alpacaRead(ctx, func() (*alpaca.Asset, error) {
+			return s.tradeClient.GetAsset(symbol)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get asset %s: %w", symbol, err)
+		}
+
+		return &models.ShortAvailability{
+			Symbol:       asset.Symbol,
+			Shortable:    asset.Shortable,
+			EasyToBorrow: asset.EasyToBorrow,
+		}, nil
+	})
+}
+
+// GetAccountActivities returns fill and fee activities recorded by the broker in
+// [after, until), oldest first, following pagination until the range is exhausted
+func (s *AlpacaService) GetAccountActivities(ctx context.Context, after, until time.Time) ([]models.BrokerActivity, error) {
+	return WithCircuitBreaker(ctx, BreakerAlpaca, func() ([]models.BrokerActivity, error) {
+		return s.listAccountActivities(ctx, []string{models.BrokerActivityFill, models.BrokerActivityFee}, after, until)
+	})
+}
+
+// GetCashFlows returns the cash deposited less the cash withdrawn in [after, until)
+func (s *AlpacaService) GetCashFlows(ctx context.Context, after, until time.Time) (decimal.Decimal, error) {
+	return WithCircuitBreaker(ctx, BreakerAlpaca, func() (decimal.Decimal, error) {
+		activities, err := s.listAccountActivities(ctx, []string{models.BrokerActivityDeposit, models.BrokerActivityWithdrawal}, after, until)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		total := decimal.Zero
+		for _, a := range activities {
+			total = total.Add(a.NetAmount)
+		}
+		return total, nil
+	})
+}
+
+// listAccountActivities returns the activities of the given types in [after, until), oldest
+// first, following pagination until the range is exhausted
+func (s *AlpacaService) listAccountActivities(ctx context.Context, activityTypes []string, after, until time.Time) ([]models.BrokerActivity, error) {
+	var activities []models.BrokerActivity
+	pageToken := ""
+	for {
+		page, err := alpacaRead(ctx, func() ([]alpaca.AccountActivity, error) {
+			return s.tradeClient.GetAccountActivities(alpaca.GetAccountActivitiesRequest{
+				ActivityTypes: activityTypes,
+				After:         after,
+				Until:         until,
+				Direction:     "asc",
+				PageSize:      accountActivitiesPageSize,
+				PageToken:     pageToken,
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account activities: %w", err)
+		}
+
+		for _, a := range page {
+			activity := models.BrokerActivity{
+				ID:              a.ID,
+				Type:            a.ActivityType,
+				Symbol:          a.Symbol,
+				Quantity:        a.Qty,
+				Price:           a.Price,
+				NetAmount:       a.NetAmount,
+				OrderID:         a.OrderID,
+				Description:     a.Description,
+				TransactionTime: a.TransactionTime,
+			}
+			switch a.Side {
+			case "buy":
+				activity.Side = models.TradeSideBuy
+			case "sell", "sell_short":
+				activity.Side = models.TradeSideSell
+			}
+			if activity.TransactionTime.IsZero() {
+				activity.TransactionTime = a.Date.In(time.UTC)
+			}
+			activities = append(activities, activity)
+		}
+
+		if len(page) < accountActivitiesPageSize {
+			return activities, nil
+		}
+		pageToken = page[len(page)-1].ID
+	}
+}
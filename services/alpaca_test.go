@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"errors"
+	"net/http"
+	"fmt"
 	"testing"
 	"time"
 
@@ -155,6 +157,7 @@ func TestPlaceOrder_Integration(t *testing.T) {
 
 	service := NewAlpacaService("", "", "")
 	ctx := context.Background()
+	limitPrice := decimal.NewFromInt(100)
 
 	tests := []struct {
 		name      string
@@ -172,7 +175,7 @@ func TestPlaceOrder_Integration(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := service.PlaceOrder(ctx, tt.symbol, tt.quantity, tt.side, tt.orderType)
+			_, err := service.PlaceOrder(ctx, models.OrderRequest{Symbol: tt.symbol, Quantity: tt.quantity, Side: tt.side, Type: tt.orderType, LimitPrice: &limitPrice})
 			// We expect an error since we're using invalid credentials
 			if err == nil {
 				t.Error("PlaceOrder should return error with invalid credentials")
@@ -226,8 +229,14 @@ func TestPositionSide_Values(t *testing.T) {
 type mockAlpacaTradeClient struct {
 	getAccountFunc   func() (*alpaca.Account, error)
 	placeOrderFunc   func(req alpaca.PlaceOrderRequest) (*alpaca.Order, error)
+	getOrderFunc     func(orderID string) (*alpaca.Order, error)
+	cancelOrderFunc  func(orderID string) error
 	getPositionsFunc func() ([]alpaca.Position, error)
 	getPositionFunc  func(symbol string) (*alpaca.Position, error)
+	activitiesFunc   func(req alpaca.GetAccountActivitiesRequest) ([]alpaca.AccountActivity, error)
+	getAssetFunc     func(symbol string) (*alpaca.Asset, error)
+	getCalendarFunc  func(req alpaca.GetCalendarRequest) ([]alpaca.CalendarDay, error)
+	historyFunc      func(req alpaca.GetPortfolioHistoryRequest) (*alpaca.PortfolioHistory, error)
 }
 
 func (m *mockAlpacaTradeClient) GetAccount() (*alpaca.Account, error) {
@@ -238,6 +247,14 @@ func (m *mockAlpacaTradeClient) PlaceOrder(req alpaca.PlaceOrderRequest) (*alpac
 	return m.placeOrderFunc(req)
 }
 
+func (m *mockAlpacaTradeClient) GetOrder(orderID string) (*alpaca.Order, error) {
+	return m.getOrderFunc(orderID)
+}
+
+func (m *mockAlpacaTradeClient) CancelOrder(orderID string) error {
+	return m.cancelOrderFunc(orderID)
+}
+
 func (m *mockAlpacaTradeClient) GetPositions() ([]alpaca.Position, error) {
 	return m.getPositionsFunc()
 }
@@ -246,6 +263,26 @@ func (m *mockAlpacaTradeClient) GetPosition(symbol string) (*alpaca.Position, er
 	return m.getPositionFunc(symbol)
 }
 
+func (m *mockAlpacaTradeClient) GetAsset(symbol string) (*alpaca.Asset, error) {
+	return m.getAssetFunc(symbol)
+}
+
+func (m *mockAlpacaTradeClient) GetAccountActivities(req alpaca.GetAccountActivitiesRequest) ([]alpaca.AccountActivity, error) {
+	return m.activitiesFunc(req)
+}
+
+func (m *mockAlpacaTradeClient) GetPortfolioHistory(req alpaca.GetPortfolioHistoryRequest) (*alpaca.PortfolioHistory, error) {
+	return m.historyFunc(req)
+}
+
+// GetCalendar defaults to a regular 09:30-16:00 day for the requested date
+func (m *mockAlpacaTradeClient) GetCalendar(req alpaca.GetCalendarRequest) ([]alpaca.CalendarDay, error) {
+	if m.getCalendarFunc != nil {
+		return m.getCalendarFunc(req)
+	}
+	return []alpaca.CalendarDay{{Date: req.Start.Format("2006-01-02"), Open: "09:30", Close: "16:00"}}, nil
+}
+
 type mockAlpacaDataClient struct {
 	getLatestQuoteFunc func(symbol string, req marketdata.GetLatestQuoteRequest) (*marketdata.Quote, error)
 	getLatestTradeFunc func(symbol string, req marketdata.GetLatestTradeRequest) (*marketdata.Trade, error)
@@ -264,10 +301,14 @@ func (m *mockAlpacaDataClient) GetBars(symbol string, req marketdata.GetBarsRequ
 	return m.getBarsFunc(symbol, req)
 }
 
+// regularSessionTime is a Tuesday at noon Eastern, inside the regular session
+var regularSessionTime = time.Date(2024, 1, 16, 17, 0, 0, 0, time.UTC)
+
 func newTestAlpacaService(tradeClient alpacaTradeClient, dataClient alpacaDataClient) *AlpacaService {
 	return &AlpacaService{
 		tradeClient: tradeClient,
 		dataClient:  dataClient,
+		now:         func() time.Time { return regularSessionTime },
 	}
 }
 
@@ -284,7 +325,7 @@ func TestPlaceOrder_Success(t *testing.T) {
 	service := newTestAlpacaService(mockTrade, mockData)
 	ctx := context.Background()
 
-	orderID, err := service.PlaceOrder(ctx, "AAPL", decimal.NewFromInt(10), models.TradeSideBuy, "market")
+	orderID, err := service.PlaceOrder(ctx, models.OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(10), Side: models.TradeSideBuy, Type: models.OrderTypeMarket})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -309,7 +350,7 @@ func TestPlaceOrder_SellSide(t *testing.T) {
 	service := newTestAlpacaService(mockTrade, mockData)
 	ctx := context.Background()
 
-	_, err := service.PlaceOrder(ctx, "AAPL", decimal.NewFromInt(5), models.TradeSideSell, "market")
+	_, err := service.PlaceOrder(ctx, models.OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(5), Side: models.TradeSideSell, Type: models.OrderTypeMarket})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -343,8 +384,9 @@ func TestPlaceOrder_OrderTypes(t *testing.T) {
 
 			service := newTestAlpacaService(mockTrade, mockData)
 			ctx := context.Background()
+			limitPrice := decimal.NewFromInt(100)
 
-			_, err := service.PlaceOrder(ctx, "AAPL", decimal.NewFromInt(1), models.TradeSideBuy, tt.orderType)
+			_, err := service.PlaceOrder(ctx, models.OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Side: models.TradeSideBuy, Type: tt.orderType, LimitPrice: &limitPrice})
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
 			}
@@ -352,6 +394,34 @@ func TestPlaceOrder_OrderTypes(t *testing.T) {
 	}
 }
 
+func TestPlaceOrder_Bracket(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	var placed alpaca.PlaceOrderRequest
+	mockTrade := &mockAlpacaTradeClient{
+		placeOrderFunc: func(req alpaca.PlaceOrderRequest) (*alpaca.Order, error) {
+			placed = req
+			return &alpaca.Order{ID: "bracket"}, nil
+		},
+	}
+	service := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{})
+
+	bracket := &models.Bracket{StopLoss: decimal.NewFromInt(95), TakeProfit: decimal.NewFromInt(110)}
+	_, err := service.PlaceOrder(context.Background(), models.OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(10), Side: models.TradeSideBuy, Type: models.OrderTypeMarket, Bracket: bracket})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if placed.OrderClass != alpaca.Bracket || placed.TimeInForce != alpaca.GTC {
+		t.Errorf("order class %q with time in force %q, want a GTC bracket", placed.OrderClass, placed.TimeInForce)
+	}
+	if placed.StopLoss == nil || !placed.StopLoss.StopPrice.Equal(bracket.StopLoss) {
+		t.Errorf("StopLoss = %+v, want stop at 95", placed.StopLoss)
+	}
+	if placed.TakeProfit == nil || !placed.TakeProfit.LimitPrice.Equal(bracket.TakeProfit) {
+		t.Errorf("TakeProfit = %+v, want limit at 110", placed.TakeProfit)
+	}
+}
+
 func TestPlaceOrder_Error(t *testing.T) {
 	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
 
@@ -365,12 +435,153 @@ func TestPlaceOrder_Error(t *testing.T) {
 	service := newTestAlpacaService(mockTrade, mockData)
 	ctx := context.Background()
 
-	_, err := service.PlaceOrder(ctx, "AAPL", decimal.NewFromInt(10), models.TradeSideBuy, "market")
+	_, err := service.PlaceOrder(ctx, models.OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(10), Side: models.TradeSideBuy, Type: models.OrderTypeMarket})
 	if err == nil {
 		t.Error("expected error")
 	}
 }
 
+func TestGetOrder_Success(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	qty := decimal.NewFromInt(10)
+	avg := decimal.NewFromFloat(150.25)
+	canceledAt := regularSessionTime.Add(time.Hour)
+	mockTrade := &mockAlpacaTradeClient{
+		getOrderFunc: func(orderID string) (*alpaca.Order, error) {
+			return &alpaca.Order{
+				ID:             orderID,
+				Symbol:         "AAPL",
+				Side:           alpaca.Sell,
+				Type:           alpaca.Limit,
+				Status:         "canceled",
+				Qty:            &qty,
+				FilledQty:      decimal.NewFromInt(4),
+				FilledAvgPrice: &avg,
+				CanceledAt:     &canceledAt,
+			}, nil
+		},
+	}
+
+	service := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{})
+	order, err := service.GetOrder(context.Background(), "order-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if order.ID != "order-1" || order.Side != models.TradeSideSell || order.Type != "limit" {
+		t.Errorf("order = %+v", order)
+	}
+	if !order.Quantity.Equal(qty) || !order.FilledQuantity.Equal(decimal.NewFromInt(4)) || !order.FilledAvgPrice.Equal(avg) {
+		t.Errorf("quantities = %s filled %s at %s", order.Quantity, order.FilledQuantity, order.FilledAvgPrice)
+	}
+	if order.CanceledAt == nil || !order.CanceledAt.Equal(canceledAt) {
+		t.Errorf("CanceledAt = %v, want %v", order.CanceledAt, canceledAt)
+	}
+	if order.TradeStatus() != models.TradeStatusExecuted {
+		t.Errorf("TradeStatus() = %s, want executed for a partly filled cancel", order.TradeStatus())
+	}
+}
+
+func TestGetOrder_Error(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	mockTrade := &mockAlpacaTradeClient{
+		getOrderFunc: func(orderID string) (*alpaca.Order, error) {
+			return nil, errors.New("order not found")
+		},
+	}
+
+	service := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{})
+	if _, err := service.GetOrder(context.Background(), "missing"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestGetOrder_BracketLegs(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	mockTrade := &mockAlpacaTradeClient{
+		getOrderFunc: func(orderID string) (*alpaca.Order, error) {
+			return &alpaca.Order{
+				ID:     orderID,
+				Symbol: "AAPL",
+				Side:   alpaca.Buy,
+				Type:   alpaca.Market,
+				Status: "filled",
+				Legs: []alpaca.Order{
+					{ID: "take-profit", Symbol: "AAPL", Side: alpaca.Sell, Type: alpaca.Limit, Status: "new"},
+					{ID: "stop-loss", Symbol: "AAPL", Side: alpaca.Sell, Type: alpaca.Stop, Status: "held"},
+				},
+			}, nil
+		},
+	}
+
+	service := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{})
+	order, err := service.GetOrder(context.Background(), "entry")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(order.Legs) != 2 || order.Legs[1].Side != models.TradeSideSell {
+		t.Fatalf("legs = %+v, want the stop-loss and take-profit sells", order.Legs)
+	}
+	if stopLoss, takeProfit := order.BracketLegs(); stopLoss != "stop-loss" || takeProfit != "take-profit" {
+		t.Errorf("BracketLegs() = %q, %q, want stop-loss and take-profit", stopLoss, takeProfit)
+	}
+}
+
+func TestCancelOrder(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	var cancelled string
+	mockTrade := &mockAlpacaTradeClient{
+		cancelOrderFunc: func(orderID string) error {
+			if orderID == "filled" {
+				return errors.New("order is already in \"filled\" state")
+			}
+			cancelled = orderID
+			return nil
+		},
+	}
+
+	service := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{})
+	if err := service.CancelOrder(context.Background(), "stop-loss"); err != nil || cancelled != "stop-loss" {
+		t.Errorf("CancelOrder() = %v, cancelled %q, want stop-loss", err, cancelled)
+	}
+	if err := service.CancelOrder(context.Background(), "filled"); err == nil {
+		t.Error("CancelOrder(filled) error = nil, want the broker's refusal")
+	}
+}
+
+func TestGetEquityHistory(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	var gotPeriod string
+	mockTrade := &mockAlpacaTradeClient{
+		historyFunc: func(req alpaca.GetPortfolioHistoryRequest) (*alpaca.PortfolioHistory, error) {
+			gotPeriod = req.Period
+			return &alpaca.PortfolioHistory{
+				Equity:    []decimal.Decimal{decimal.Zero, decimal.NewFromInt(10000), decimal.NewFromInt(9500)},
+				Timestamp: []int64{1704153600, 1704240000, 1704326400},
+			}, nil
+		},
+	}
+
+	service := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{})
+	closes, err := service.GetEquityHistory(context.Background(), 90)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotPeriod != "90D" {
+		t.Errorf("expected period 90D, got %q", gotPeriod)
+	}
+	if len(closes) != 2 {
+		t.Fatalf("expected the unfunded day to be skipped, got %d closes", len(closes))
+	}
+	if closes[1].Close != 9500 || !closes[1].Date.Equal(time.Unix(1704326400, 0)) {
+		t.Errorf("unexpected last close %+v", closes[1])
+	}
+}
+
 func TestGetPositions_Success(t *testing.T) {
 	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
 
@@ -440,6 +651,91 @@ func TestGetPositions_ShortSide(t *testing.T) {
 	}
 }
 
+func TestGetPosition_NegativeShortQuantity(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	mockTrade := &mockAlpacaTradeClient{
+		getPositionFunc: func(symbol string) (*alpaca.Position, error) {
+			return &alpaca.Position{Symbol: symbol, Qty: decimal.NewFromInt(-5), Side: "short"}, nil
+		},
+	}
+
+	position, err := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{}).GetPosition(context.Background(), "TSLA")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !position.Quantity.Equal(decimal.NewFromInt(5)) || position.Side != models.PositionSideShort {
+		t.Errorf("position = %s %s, want 5 short", position.Quantity, position.Side)
+	}
+}
+
+func TestGetPosition_NotHeld(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	mockTrade := &mockAlpacaTradeClient{
+		getPositionFunc: func(symbol string) (*alpaca.Position, error) {
+			return nil, &alpaca.APIError{StatusCode: http.StatusNotFound, Code: 40410000, Message: "position does not exist"}
+		},
+	}
+	service := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{})
+	if _, err := service.GetPosition(context.Background(), "TSLA"); !errors.Is(err, models.ErrPositionNotFound) {
+		t.Errorf("error = %v, want ErrPositionNotFound", err)
+	}
+
+	mockTrade.getPositionFunc = func(symbol string) (*alpaca.Position, error) {
+		return nil, &alpaca.APIError{StatusCode: http.StatusForbidden, Message: "forbidden"}
+	}
+	if _, err := service.GetPosition(context.Background(), "TSLA"); err == nil || errors.Is(err, models.ErrPositionNotFound) {
+		t.Errorf("error = %v, want a failure other than ErrPositionNotFound", err)
+	}
+}
+
+func TestGetShortAvailability(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	mockTrade := &mockAlpacaTradeClient{
+		getAssetFunc: func(symbol string) (*alpaca.Asset, error) {
+			return &alpaca.Asset{Symbol: symbol, Shortable: true, EasyToBorrow: false}, nil
+		},
+	}
+
+	avail, err := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{}).GetShortAvailability(context.Background(), "GME")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if avail.Symbol != "GME" || !avail.Shortable || avail.EasyToBorrow {
+		t.Errorf("availability = %+v, want shortable but hard to borrow", avail)
+	}
+}
+
+func TestGetAverageDailyVolume(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	mockData := &mockAlpacaDataClient{
+		getBarsFunc: func(symbol string, req marketdata.GetBarsRequest) ([]marketdata.Bar, error) {
+			if req.TimeFrame != marketdata.OneDay {
+				t.Errorf("TimeFrame = %v, want daily bars", req.TimeFrame)
+			}
+			day := time.Now().AddDate(0, 0, -5)
+			return []marketdata.Bar{
+				{Timestamp: day, Volume: 1_000_000},
+				{Timestamp: day.AddDate(0, 0, 1), Volume: 2_000_000},
+				{Timestamp: day.AddDate(0, 0, 2), Volume: 3_000_000},
+				// Today's session is still trading and its partial volume is left out
+				{Timestamp: time.Now(), Volume: 10_000},
+			}, nil
+		},
+	}
+
+	adv, err := newTestAlpacaService(&mockAlpacaTradeClient{}, mockData).GetAverageDailyVolume(context.Background(), "AAPL", 30)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if adv != 2_000_000 {
+		t.Errorf("adv = %d, want 2000000", adv)
+	}
+}
+
 func TestGetPositions_NilFields(t *testing.T) {
 	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
 
@@ -608,3 +904,266 @@ func TestGetPosition_Error(t *testing.T) {
 		t.Error("expected error")
 	}
 }
+
+func TestPlaceOrder_MarketOrderOutsideRegularSession(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	called := false
+	mockTrade := &mockAlpacaTradeClient{
+		placeOrderFunc: func(req alpaca.PlaceOrderRequest) (*alpaca.Order, error) {
+			called = true
+			return &alpaca.Order{ID: "test"}, nil
+		},
+	}
+
+	service := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{})
+	// 7:00 Eastern, pre-market
+	service.now = func() time.Time { return time.Date(2024, 1, 16, 12, 0, 0, 0, time.UTC) }
+
+	_, err := service.PlaceOrder(context.Background(), models.OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Side: models.TradeSideBuy, Type: models.OrderTypeMarket})
+	if !errors.Is(err, models.ErrOutsideRegularSession) {
+		t.Errorf("expected ErrOutsideRegularSession, got %v", err)
+	}
+	if called {
+		t.Error("order should not reach the broker")
+	}
+}
+
+func TestPlaceOrder_MarketOrderOnHoliday(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	called := false
+	mockTrade := &mockAlpacaTradeClient{
+		placeOrderFunc: func(req alpaca.PlaceOrderRequest) (*alpaca.Order, error) {
+			called = true
+			return &alpaca.Order{ID: "test"}, nil
+		},
+		getCalendarFunc: func(req alpaca.GetCalendarRequest) ([]alpaca.CalendarDay, error) {
+			return nil, nil
+		},
+	}
+
+	service := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{})
+
+	_, err := service.PlaceOrder(context.Background(), models.OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Side: models.TradeSideBuy, Type: models.OrderTypeMarket})
+	var sessionErr *models.OutsideSessionError
+	if !errors.As(err, &sessionErr) {
+		t.Fatalf("expected OutsideSessionError, got %v", err)
+	}
+	if sessionErr.Status.Reason != "Closed for a market holiday" {
+		t.Errorf("Reason = %q", sessionErr.Status.Reason)
+	}
+	if called {
+		t.Error("order should not reach the broker")
+	}
+}
+
+func TestMarketStatus_EarlyClose(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	mockTrade := &mockAlpacaTradeClient{
+		getCalendarFunc: func(req alpaca.GetCalendarRequest) ([]alpaca.CalendarDay, error) {
+			return []alpaca.CalendarDay{{Date: req.Start.Format("2006-01-02"), Open: "09:30", Close: "11:00"}}, nil
+		},
+	}
+
+	service := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{})
+	if got := service.MarketStatus(context.Background()).Session; got != models.MarketSessionAfter {
+		t.Errorf("Session = %v, want after", got)
+	}
+}
+
+func TestMarketStatus_NextOpenAfterHoliday(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	// Monday 2024-01-15 is a market holiday: the calendar skips to Tuesday
+	calls := 0
+	mockTrade := &mockAlpacaTradeClient{
+		getCalendarFunc: func(req alpaca.GetCalendarRequest) ([]alpaca.CalendarDay, error) {
+			calls++
+			return []alpaca.CalendarDay{{Date: "2024-01-16", Open: "09:30", Close: "16:00"}}, nil
+		},
+	}
+	service := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{})
+	service.now = func() time.Time { return time.Date(2024, 1, 15, 17, 0, 0, 0, time.UTC) }
+
+	status := service.MarketStatus(context.Background())
+	wantOpen := time.Date(2024, 1, 16, 14, 30, 0, 0, time.UTC)
+	if status.Session != models.MarketSessionClosed || status.NextOpen == nil || !status.NextOpen.Equal(wantOpen) {
+		t.Errorf("status = %+v, want closed until Tuesday's open", status)
+	}
+
+	service.MarketStatus(context.Background())
+	if calls != 1 {
+		t.Errorf("fetched the calendar %d times, want once per market date", calls)
+	}
+}
+
+func TestPlaceOrder_LimitOrderWithoutPrice(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	called := false
+	mockTrade := &mockAlpacaTradeClient{
+		placeOrderFunc: func(req alpaca.PlaceOrderRequest) (*alpaca.Order, error) {
+			called = true
+			return &alpaca.Order{ID: "test"}, nil
+		},
+	}
+
+	service := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{})
+	_, err := service.PlaceOrder(context.Background(), models.OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Side: models.TradeSideBuy, Type: models.OrderTypeLimit})
+	if !errors.Is(err, models.ErrInvalidOrder) {
+		t.Errorf("expected ErrInvalidOrder, got %v", err)
+	}
+	if called {
+		t.Error("order should not reach the broker")
+	}
+}
+
+func TestPlaceOrder_LimitOrderExtendedHours(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	tests := []struct {
+		name         string
+		now          time.Time
+		wantExtended bool
+	}{
+		{"regular session", regularSessionTime, false},
+		{"after hours", time.Date(2024, 1, 16, 22, 0, 0, 0, time.UTC), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got alpaca.PlaceOrderRequest
+			mockTrade := &mockAlpacaTradeClient{
+				placeOrderFunc: func(req alpaca.PlaceOrderRequest) (*alpaca.Order, error) {
+					got = req
+					return &alpaca.Order{ID: "test"}, nil
+				},
+			}
+
+			service := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{})
+			service.now = func() time.Time { return tt.now }
+			limitPrice := decimal.NewFromInt(100)
+
+			if _, err := service.PlaceOrder(context.Background(), models.OrderRequest{Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Side: models.TradeSideBuy, Type: models.OrderTypeLimit, LimitPrice: &limitPrice}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.ExtendedHours != tt.wantExtended {
+				t.Errorf("ExtendedHours = %v, want %v", got.ExtendedHours, tt.wantExtended)
+			}
+			if got.LimitPrice == nil || !got.LimitPrice.Equal(limitPrice) {
+				t.Errorf("LimitPrice = %v, want %s", got.LimitPrice, limitPrice)
+			}
+		})
+	}
+}
+
+func TestGetQuote_Session(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	mockData := &mockAlpacaDataClient{
+		getLatestQuoteFunc: func(symbol string, req marketdata.GetLatestQuoteRequest) (*marketdata.Quote, error) {
+			// 18:00 Eastern
+			return &marketdata.Quote{BidPrice: 99, AskPrice: 101, Timestamp: time.Date(2024, 1, 16, 23, 0, 0, 0, time.UTC)}, nil
+		},
+	}
+
+	service := newTestAlpacaService(&mockAlpacaTradeClient{}, mockData)
+	quote, err := service.GetQuote(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if quote.Session != models.MarketSessionAfter {
+		t.Errorf("Session = %v, want after", quote.Session)
+	}
+}
+
+func TestGetQuote_UnknownSymbol(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	mockData := &mockAlpacaDataClient{
+		getLatestQuoteFunc: func(symbol string, req marketdata.GetLatestQuoteRequest) (*marketdata.Quote, error) {
+			return nil, nil
+		},
+	}
+	service := newTestAlpacaService(&mockAlpacaTradeClient{}, mockData)
+	if _, err := service.GetQuote(context.Background(), "ZZZZ"); !errors.Is(err, models.ErrSymbolNotFound) {
+		t.Errorf("error = %v, want ErrSymbolNotFound", err)
+	}
+
+	mockData.getLatestQuoteFunc = func(symbol string, req marketdata.GetLatestQuoteRequest) (*marketdata.Quote, error) {
+		return nil, &alpaca.APIError{StatusCode: http.StatusForbidden, Message: "subscription does not permit querying recent SIP data"}
+	}
+	if _, err := service.GetQuote(context.Background(), "AAPL"); err == nil || errors.Is(err, models.ErrSymbolNotFound) {
+		t.Errorf("error = %v, want a failure other than ErrSymbolNotFound", err)
+	}
+}
+
+func TestGetAccountActivities_Paginates(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	after := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	until := after.AddDate(0, 1, 0)
+	var tokens []string
+	mockTrade := &mockAlpacaTradeClient{
+		activitiesFunc: func(req alpaca.GetAccountActivitiesRequest) ([]alpaca.AccountActivity, error) {
+			tokens = append(tokens, req.PageToken)
+			if !req.After.Equal(after) || !req.Until.Equal(until) {
+				t.Errorf("unexpected range %v - %v", req.After, req.Until)
+			}
+			if req.PageToken != "" {
+				return []alpaca.AccountActivity{{ID: "fee-1", ActivityType: "FEE", Symbol: "AAPL", NetAmount: decimal.NewFromFloat(-0.02)}}, nil
+			}
+			page := make([]alpaca.AccountActivity, accountActivitiesPageSize)
+			for i := range page {
+				page[i] = alpaca.AccountActivity{ID: fmt.Sprintf("fill-%d", i), ActivityType: "FILL", Symbol: "AAPL", Side: "sell_short", Qty: decimal.NewFromInt(1), TransactionTime: after}
+			}
+			return page, nil
+		},
+	}
+	service := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{})
+
+	activities, err := service.GetAccountActivities(context.Background(), after, until)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(activities) != accountActivitiesPageSize+1 {
+		t.Fatalf("expected %d activities, got %d", accountActivitiesPageSize+1, len(activities))
+	}
+	if len(tokens) != 2 || tokens[1] != fmt.Sprintf("fill-%d", accountActivitiesPageSize-1) {
+		t.Errorf("expected second page after the last fill, got tokens %v", tokens)
+	}
+	if activities[0].Side != models.TradeSideSell {
+		t.Errorf("expected sell_short to map to sell, got %q", activities[0].Side)
+	}
+	if last := activities[len(activities)-1]; last.Type != models.BrokerActivityFee || !last.NetAmount.Equal(decimal.NewFromFloat(-0.02)) {
+		t.Errorf("unexpected fee activity %+v", last)
+	}
+}
+
+func TestGetCashFlows(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	mockTrade := &mockAlpacaTradeClient{
+		activitiesFunc: func(req alpaca.GetAccountActivitiesRequest) ([]alpaca.AccountActivity, error) {
+			if len(req.ActivityTypes) != 2 || req.ActivityTypes[0] != models.BrokerActivityDeposit || req.ActivityTypes[1] != models.BrokerActivityWithdrawal {
+				t.Errorf("unexpected activity types %v", req.ActivityTypes)
+			}
+			return []alpaca.AccountActivity{
+				{ID: "csd-1", ActivityType: "CSD", NetAmount: decimal.NewFromInt(5000)},
+				{ID: "csw-1", ActivityType: "CSW", NetAmount: decimal.NewFromInt(-1200)},
+			}, nil
+		},
+	}
+	service := newTestAlpacaService(mockTrade, &mockAlpacaDataClient{})
+
+	after := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	flow, err := service.GetCashFlows(context.Background(), after, after.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !flow.Equal(decimal.NewFromInt(3800)) {
+		t.Errorf("GetCashFlows() = %v, want deposits less withdrawals of 3800", flow)
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -10,7 +11,6 @@ import (
 	"time"
 
 	"trade-machine/models"
-	"trade-machine/observability"
 
 	"github.com/shopspring/decimal"
 )
@@ -26,13 +26,14 @@ type AlphaVantageService struct {
 func NewAlphaVantageService(apiKey string) *AlphaVantageService {
 	return &AlphaVantageService{
 		apiKey:     apiKey,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		httpClient: newLedgerHTTPClient(BreakerAlphaVantage, 30*time.Second),
 		baseURL:    "https://www.alphavantage.co/query",
 	}
 }
 
 // OverviewResponse represents the company overview response from Alpha Vantage
 type OverviewResponse struct {
+	alphaVantageNotice
 	Symbol           string `json:"Symbol"`
 	Name             string `json:"Name"`
 	Description      string `json:"Description"`
@@ -50,6 +51,9 @@ type OverviewResponse struct {
 	EPS              string `json:"EPS"`
 	RevenuePerShare  string `json:"RevenuePerShareTTM"`
 	ProfitMargin     string `json:"ProfitMargin"`
+	OperatingMargin  string `json:"OperatingMarginTTM"`
+	RevenueTTM       string `json:"RevenueTTM"`
+	GrossProfitTTM   string `json:"GrossProfitTTM"`
 	Beta             string `json:"Beta"`
 	Week52High       string `json:"52WeekHigh"`
 	Week52Low        string `json:"52WeekLow"`
@@ -61,7 +65,7 @@ func (s *AlphaVantageService) GetFundamentals(ctx context.Context, symbol string
 	return WithCircuitBreaker(ctx, BreakerAlphaVantage, func() (*models.Fundamentals, error) {
 		var fundamentals *models.Fundamentals
 
-		err := WithRetry(ctx, DefaultRetryConfig, func() error {
+		err := currentRetryPolicy().Do(ctx, func() error {
 			params := url.Values{}
 			params.Set("function", "OVERVIEW")
 			params.Set("symbol", symbol)
@@ -73,46 +77,74 @@ func (s *AlphaVantageService) GetFundamentals(ctx context.Context, symbol string
 			}
 			defer resp.Body.Close()
 
+			if resp.StatusCode != http.StatusOK {
+				return newStatusError("Alpha Vantage", resp, "")
+			}
+
 			var overview OverviewResponse
 			if err := json.NewDecoder(resp.Body).Decode(&overview); err != nil {
 				return fmt.Errorf("failed to decode overview: %w", err)
 			}
+			if err := checkAlphaVantageNotice(overview.alphaVantageNotice); err != nil {
+				return err
+			}
 
 			marketCap, _ := decimal.NewFromString(overview.MarketCap)
 			eps, _ := decimal.NewFromString(overview.EPS)
 			week52High, _ := decimal.NewFromString(overview.Week52High)
 			week52Low, _ := decimal.NewFromString(overview.Week52Low)
+			revenue, _ := decimal.NewFromString(overview.RevenueTTM)
+			grossProfit, _ := decimal.NewFromString(overview.GrossProfitTTM)
 
-			var peRatio, dividendYield, beta float64
+			var peRatio, dividendYield, beta, profitMargin, operatingMargin float64
 			if overview.PERatio != "" && overview.PERatio != "None" {
 				peRatio, err = strconv.ParseFloat(overview.PERatio, 64)
 				if err != nil {
-					observability.Warn("failed to parse P/E ratio", "value", overview.PERatio, "error", err)
+					logger.Warn("failed to parse P/E ratio", "value", overview.PERatio, "error", err)
 				}
 			}
 			if overview.DividendYield != "" && overview.DividendYield != "None" {
 				dividendYield, err = strconv.ParseFloat(overview.DividendYield, 64)
 				if err != nil {
-					observability.Warn("failed to parse dividend yield", "value", overview.DividendYield, "error", err)
+					logger.Warn("failed to parse dividend yield", "value", overview.DividendYield, "error", err)
 				}
 			}
 			if overview.Beta != "" && overview.Beta != "None" {
 				beta, err = strconv.ParseFloat(overview.Beta, 64)
 				if err != nil {
-					observability.Warn("failed to parse beta", "value", overview.Beta, "error", err)
+					logger.Warn("failed to parse beta", "value", overview.Beta, "error", err)
+				}
+			}
+			if overview.ProfitMargin != "" && overview.ProfitMargin != "None" {
+				profitMargin, err = strconv.ParseFloat(overview.ProfitMargin, 64)
+				if err != nil {
+					logger.Warn("failed to parse profit margin", "value", overview.ProfitMargin, "error", err)
+				}
+			}
+			if overview.OperatingMargin != "" && overview.OperatingMargin != "None" {
+				operatingMargin, err = strconv.ParseFloat(overview.OperatingMargin, 64)
+				if err != nil {
+					logger.Warn("failed to parse operating margin", "value", overview.OperatingMargin, "error", err)
 				}
 			}
 
+			sector, industry := models.NormalizeClassification(overview.Sector, overview.Industry)
 			fundamentals = &models.Fundamentals{
-				Symbol:        symbol,
-				MarketCap:     marketCap,
-				PERatio:       peRatio,
-				EPS:           eps,
-				DividendYield: dividendYield,
-				Week52High:    week52High,
-				Week52Low:     week52Low,
-				Beta:          beta,
-				UpdatedAt:     time.Now(),
+				Symbol:          symbol,
+				Sector:          sector,
+				Industry:        industry,
+				MarketCap:       marketCap,
+				PERatio:         peRatio,
+				EPS:             eps,
+				DividendYield:   dividendYield,
+				Week52High:      week52High,
+				Week52Low:       week52Low,
+				Beta:            beta,
+				Revenue:         revenue,
+				GrossProfit:     grossProfit,
+				ProfitMargin:    profitMargin,
+				OperatingMargin: operatingMargin,
+				UpdatedAt:       time.Now(),
 			}
 
 			return nil
@@ -126,8 +158,106 @@ func (s *AlphaVantageService) GetFundamentals(ctx context.Context, symbol string
 	})
 }
 
+// alphaVantageNotice is what Alpha Vantage answers with, under a 200 status, in place of
+// data when a request is throttled, over the daily quota or invalid
+type alphaVantageNotice struct {
+	Note         string `json:"Note"`
+	Information  string `json:"Information"`
+	ErrorMessage string `json:"Error Message"`
+}
+
+// err returns ErrRateLimited for a throttling or quota notice, a permanent error for an
+// invalid request, and nil when the response carries data
+func (n alphaVantageNotice) err() error {
+	switch {
+	case n.Note != "":
+		return fmt.Errorf("%w: Alpha Vantage: %s", ErrRateLimited, n.Note)
+	case n.Information != "":
+		return fmt.Errorf("%w: Alpha Vantage: %s", ErrRateLimited, n.Information)
+	case n.ErrorMessage != "":
+		return permanent(fmt.Errorf("Alpha Vantage: %s", n.ErrorMessage))
+	}
+	return nil
+}
+
+// alphaVantageThrottleWindow is how long a per-minute throttling notice ("Note") lasts
+const alphaVantageThrottleWindow = time.Minute
+
+// checkAlphaVantageNotice returns the notice's error, raising a quota alert for a
+// throttling or quota notice since Alpha Vantage sends those with a 200 status rather
+// than 429. A throttling note clears within a minute; the daily quota's reset time
+// isn't given.
+func checkAlphaVantageNotice(n alphaVantageNotice) error {
+	err := n.err()
+	if errors.Is(err, ErrRateLimited) {
+		var recoverAt *time.Time
+		if n.Note != "" {
+			at := time.Now().Add(alphaVantageThrottleWindow)
+			recoverAt = &at
+		}
+		notifyProviderAlert(BreakerAlphaVantage, models.ProviderAlertQuotaExhausted, err.Error(), recoverAt)
+	}
+	return err
+}
+
+// BalanceSheetResponse represents the balance sheet response from Alpha Vantage
+type BalanceSheetResponse struct {
+	alphaVantageNotice
+	Symbol           string `json:"symbol"`
+	QuarterlyReports []struct {
+		FiscalDateEnding       string `json:"fiscalDateEnding"`
+		ShortLongTermDebtTotal string `json:"shortLongTermDebtTotal"`
+	} `json:"quarterlyReports"`
+}
+
+// GetTotalDebt returns short and long-term debt from the latest quarterly balance sheet,
+// or zero if it is not reported. A throttling or quota notice in place of the balance
+// sheet is an error rather than zero debt.
+func (s *AlphaVantageService) GetTotalDebt(ctx context.Context, symbol string) (decimal.Decimal, error) {
+	return WithCircuitBreaker(ctx, BreakerAlphaVantage, func() (decimal.Decimal, error) {
+		var debt decimal.Decimal
+
+		err := currentRetryPolicy().Do(ctx, func() error {
+			params := url.Values{}
+			params.Set("function", "BALANCE_SHEET")
+			params.Set("symbol", symbol)
+			params.Set("apikey", s.apiKey)
+
+			resp, err := s.httpClient.Get(s.baseURL + "?" + params.Encode())
+			if err != nil {
+				return fmt.Errorf("failed to fetch balance sheet: %w", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return newStatusError("Alpha Vantage", resp, "")
+			}
+
+			var sheet BalanceSheetResponse
+			if err := json.NewDecoder(resp.Body).Decode(&sheet); err != nil {
+				return fmt.Errorf("failed to decode balance sheet: %w", err)
+			}
+			if err := checkAlphaVantageNotice(sheet.alphaVantageNotice); err != nil {
+				return err
+			}
+
+			if len(sheet.QuarterlyReports) > 0 {
+				// Unreported values are "None" and leave debt at zero
+				debt, _ = decimal.NewFromString(sheet.QuarterlyReports[0].ShortLongTermDebtTotal)
+			}
+			return nil
+		})
+		if err != nil {
+			return decimal.Zero, err
+		}
+
+		return debt, nil
+	})
+}
+
 // NewsResponse represents the news response from Alpha Vantage
 type NewsResponse struct {
+	alphaVantageNotice
 	Items string `json:"items"`
 	Feed  []struct {
 		Title            string   `json:"title"`
@@ -144,52 +274,62 @@ type NewsResponse struct {
 // GetNews returns recent news for a symbol
 func (s *AlphaVantageService) GetNews(ctx context.Context, symbol string) ([]models.NewsArticle, error) {
 	return WithCircuitBreaker(ctx, BreakerAlphaVantage, func() ([]models.NewsArticle, error) {
-		params := url.Values{}
-		params.Set("function", "NEWS_SENTIMENT")
-		params.Set("tickers", symbol)
-		params.Set("limit", "10")
-		params.Set("apikey", s.apiKey)
-
-		resp, err := s.httpClient.Get(s.baseURL + "?" + params.Encode())
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch news: %w", err)
-		}
-		defer resp.Body.Close()
-
-		var newsResp NewsResponse
-		if err := json.NewDecoder(resp.Body).Decode(&newsResp); err != nil {
-			return nil, fmt.Errorf("failed to decode news: %w", err)
-		}
+		return Retry(ctx, func() ([]models.NewsArticle, error) {
+			params := url.Values{}
+			params.Set("function", "NEWS_SENTIMENT")
+			params.Set("tickers", symbol)
+			params.Set("limit", "10")
+			params.Set("apikey", s.apiKey)
 
-		articles := make([]models.NewsArticle, 0, len(newsResp.Feed))
-		for _, item := range newsResp.Feed {
-			publishedAt, err := time.Parse("20060102T150405", item.TimePublished)
+			resp, err := s.httpClient.Get(s.baseURL + "?" + params.Encode())
 			if err != nil {
-				observability.Warn("failed to parse timestamp, using current time", "value", item.TimePublished, "error", err)
-				publishedAt = time.Now()
+				return nil, fmt.Errorf("failed to fetch news: %w", err)
 			}
+			defer resp.Body.Close()
 
-			author := ""
-			if len(item.Authors) > 0 {
-				author = item.Authors[0]
+			if resp.StatusCode != http.StatusOK {
+				return nil, newStatusError("Alpha Vantage", resp, "")
 			}
 
-			articles = append(articles, models.NewsArticle{
-				Title:       item.Title,
-				Description: item.Summary,
-				URL:         item.URL,
-				Source:      item.Source,
-				Author:      author,
-				PublishedAt: publishedAt,
-			})
-		}
+			var newsResp NewsResponse
+			if err := json.NewDecoder(resp.Body).Decode(&newsResp); err != nil {
+				return nil, fmt.Errorf("failed to decode news: %w", err)
+			}
+			if err := checkAlphaVantageNotice(newsResp.alphaVantageNotice); err != nil {
+				return nil, err
+			}
+
+			articles := make([]models.NewsArticle, 0, len(newsResp.Feed))
+			for _, item := range newsResp.Feed {
+				publishedAt, err := time.Parse("20060102T150405", item.TimePublished)
+				if err != nil {
+					logger.Warn("failed to parse timestamp, using current time", "value", item.TimePublished, "error", err)
+					publishedAt = time.Now()
+				}
+
+				author := ""
+				if len(item.Authors) > 0 {
+					author = item.Authors[0]
+				}
+
+				articles = append(articles, models.NewsArticle{
+					Title:       item.Title,
+					Description: item.Summary,
+					URL:         item.URL,
+					Source:      item.Source,
+					Author:      author,
+					PublishedAt: publishedAt,
+				})
+			}
 
-		return articles, nil
+			return articles, nil
+		})
 	})
 }
 
 // QuoteResponse represents a quote from Alpha Vantage
 type QuoteResponse struct {
+	alphaVantageNotice
 	GlobalQuote struct {
 		Symbol        string `json:"01. symbol"`
 		Open          string `json:"02. open"`
@@ -207,36 +347,45 @@ type QuoteResponse struct {
 // GetQuote returns the latest quote for a symbol
 func (s *AlphaVantageService) GetQuote(ctx context.Context, symbol string) (*models.Quote, error) {
 	return WithCircuitBreaker(ctx, BreakerAlphaVantage, func() (*models.Quote, error) {
-		params := url.Values{}
-		params.Set("function", "GLOBAL_QUOTE")
-		params.Set("symbol", symbol)
-		params.Set("apikey", s.apiKey)
+		return Retry(ctx, func() (*models.Quote, error) {
+			params := url.Values{}
+			params.Set("function", "GLOBAL_QUOTE")
+			params.Set("symbol", symbol)
+			params.Set("apikey", s.apiKey)
 
-		resp, err := s.httpClient.Get(s.baseURL + "?" + params.Encode())
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch quote: %w", err)
-		}
-		defer resp.Body.Close()
+			resp, err := s.httpClient.Get(s.baseURL + "?" + params.Encode())
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch quote: %w", err)
+			}
+			defer resp.Body.Close()
 
-		var quoteResp QuoteResponse
-		if err := json.NewDecoder(resp.Body).Decode(&quoteResp); err != nil {
-			return nil, fmt.Errorf("failed to decode quote: %w", err)
-		}
+			if resp.StatusCode != http.StatusOK {
+				return nil, newStatusError("Alpha Vantage", resp, "")
+			}
 
-		price, _ := decimal.NewFromString(quoteResp.GlobalQuote.Price)
-		var volume int64
-		if quoteResp.GlobalQuote.Volume != "" {
-			volume, err = strconv.ParseInt(quoteResp.GlobalQuote.Volume, 10, 64)
-			if err != nil {
-				observability.Warn("failed to parse volume", "value", quoteResp.GlobalQuote.Volume, "error", err)
+			var quoteResp QuoteResponse
+			if err := json.NewDecoder(resp.Body).Decode(&quoteResp); err != nil {
+				return nil, fmt.Errorf("failed to decode quote: %w", err)
+			}
+			if err := checkAlphaVantageNotice(quoteResp.alphaVantageNotice); err != nil {
+				return nil, err
 			}
-		}
 
-		return &models.Quote{
-			Symbol:    symbol,
-			Last:      price,
-			Volume:    volume,
-			Timestamp: time.Now(),
-		}, nil
+			price, _ := decimal.NewFromString(quoteResp.GlobalQuote.Price)
+			var volume int64
+			if quoteResp.GlobalQuote.Volume != "" {
+				volume, err = strconv.ParseInt(quoteResp.GlobalQuote.Volume, 10, 64)
+				if err != nil {
+					logger.Warn("failed to parse volume", "value", quoteResp.GlobalQuote.Volume, "error", err)
+				}
+			}
+
+			return &models.Quote{
+				Symbol:    symbol,
+				Last:      price,
+				Volume:    volume,
+				Timestamp: time.Now(),
+			}, nil
+		})
 	})
 }
@@ -3,9 +3,14 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
 )
 
 func TestNewAlphaVantageService(t *testing.T) {
@@ -151,6 +156,8 @@ func TestAlphaVantageService_GetFundamentals(t *testing.T) {
 		json.NewEncoder(w).Encode(OverviewResponse{
 			Symbol:        "AAPL",
 			Name:          "Apple Inc",
+			Sector:        "TECHNOLOGY",
+			Industry:      "ELECTRONIC COMPUTERS",
 			MarketCap:     "2500000000000",
 			PERatio:       "28.5",
 			EPS:           "6.05",
@@ -158,6 +165,8 @@ func TestAlphaVantageService_GetFundamentals(t *testing.T) {
 			Week52High:    "199.62",
 			Week52Low:     "164.08",
 			Beta:          "1.25",
+			RevenueTTM:    "385000000000",
+			ProfitMargin:  "0.253",
 		})
 	}))
 	defer server.Close()
@@ -177,6 +186,54 @@ func TestAlphaVantageService_GetFundamentals(t *testing.T) {
 	if fundamentals.PERatio != 28.5 {
 		t.Errorf("PERatio = %v, want 28.5", fundamentals.PERatio)
 	}
+	if fundamentals.Sector != models.SectorInformationTechnology {
+		t.Errorf("Sector = %v, want %q", fundamentals.Sector, models.SectorInformationTechnology)
+	}
+	if !fundamentals.Revenue.Equal(decimal.NewFromInt(385000000000)) || fundamentals.ProfitMargin != 0.253 {
+		t.Errorf("Revenue = %v, ProfitMargin = %v", fundamentals.Revenue, fundamentals.ProfitMargin)
+	}
+}
+
+func TestAlphaVantageService_GetTotalDebt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("function") != "BALANCE_SHEET" {
+			t.Errorf("function = %q, want BALANCE_SHEET", r.URL.Query().Get("function"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"symbol":"AAPL","quarterlyReports":[{"fiscalDateEnding":"2024-03-31","shortLongTermDebtTotal":"104590000000"},{"fiscalDateEnding":"2023-12-31","shortLongTermDebtTotal":"108040000000"}]}`))
+	}))
+	defer server.Close()
+
+	service := NewAlphaVantageService("test-key")
+	service.baseURL = server.URL
+
+	debt, err := service.GetTotalDebt(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("GetTotalDebt failed: %v", err)
+	}
+	if !debt.Equal(decimal.NewFromInt(104590000000)) {
+		t.Errorf("debt = %v, want the latest quarter", debt)
+	}
+}
+
+func TestAlphaVantageService_GetTotalDebt_Notice(t *testing.T) {
+	for _, body := range []string{
+		`{"Note":"Thank you for using Alpha Vantage! Our standard API call frequency is 5 calls per minute."}`,
+		`{"Information":"Thank you for using Alpha Vantage! Our standard API rate limit is 25 requests per day."}`,
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}))
+		service := NewAlphaVantageService("test-key")
+		service.baseURL = server.URL
+
+		debt, err := service.GetTotalDebt(context.Background(), "AAPL")
+		if !errors.Is(err, ErrRateLimited) || !debt.IsZero() {
+			t.Errorf("GetTotalDebt(%s) = %v, %v, want ErrRateLimited", body, debt, err)
+		}
+		server.Close()
+	}
 }
 
 func TestAlphaVantageService_GetNews(t *testing.T) {
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	appconfig "trade-machine/config"
+	"trade-machine/observability"
+)
+
+// anthropicVersion is the Messages API version requests are made against
+const anthropicVersion = "2023-06-01"
+
+// AnthropicService handles communication with the Anthropic Messages API, for Claude
+// models without going through AWS Bedrock
+type AnthropicService struct {
+	apiKey     string
+	model      string
+	maxTokens  int
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewAnthropicService creates a new AnthropicService instance
+func NewAnthropicService(cfg *appconfig.Config) (*AnthropicService, error) {
+	if cfg.Anthropic.APIKey == "" {
+		return nil, fmt.Errorf("ANTHROPIC_API_KEY is required")
+	}
+
+	return &AnthropicService{
+		apiKey:     cfg.Anthropic.APIKey,
+		model:      cfg.Anthropic.Model,
+		maxTokens:  cfg.Anthropic.MaxTokens,
+		baseURL:    strings.TrimRight(cfg.Anthropic.BaseURL, "/"),
+		httpClient: newLedgerHTTPClient(BreakerAnthropic, 120*time.Second),
+	}, nil
+}
+
+// anthropicMessage is a turn in a Messages API conversation
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	Stream    bool               `json:"stream,omitempty"`
+}
+
+// anthropicResponse is the part of a Messages API response the service reads
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int64 `json:"input_tokens"`
+		OutputTokens int64 `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// anthropicStreamEvent is the part of a streamed Messages API event the service reads.
+// Which fields are set depends on Type.
+type anthropicStreamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Usage struct {
+			InputTokens int64 `json:"input_tokens"`
+		} `json:"usage"`
+	} `json:"message"` // message_start
+	Delta struct {
+		Text string `json:"text"`
+	} `json:"delta"` // content_block_delta
+	Usage struct {
+		OutputTokens int64 `json:"output_tokens"`
+	} `json:"usage"` // message_delta
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"` // error
+}
+
+type anthropicError struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// InvokeWithPrompt sends a prompt to Anthropic and returns the response text
+func (s *AnthropicService) InvokeWithPrompt(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return s.send(ctx, "invoke", systemPrompt, []anthropicMessage{{Role: "user", Content: userPrompt}})
+}
+
+// InvokeStructured sends a prompt and parses the JSON response into the provided struct
+func (s *AnthropicService) InvokeStructured(ctx context.Context, systemPrompt, userPrompt string, result interface{}) error {
+	text, err := s.InvokeWithPrompt(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return err
+	}
+
+	return ParseStructuredOutput(text, result)
+}
+
+// Chat enables multi-turn conversation with Anthropic. Messages with roles other than
+// user and assistant are dropped, as the Messages API takes the system prompt separately.
+func (s *AnthropicService) Chat(ctx context.Context, systemPrompt string, messages []ChatMessage) (string, error) {
+	return s.send(ctx, "chat", systemPrompt, anthropicTurns(messages))
+}
+
+// ChatStream holds a conversation with Anthropic, passing the reply to onDelta as it
+// streams in. Only failures before any of the reply has been passed on are retried.
+func (s *AnthropicService) ChatStream(ctx context.Context, systemPrompt string, messages []ChatMessage, onDelta func(delta string) error) (string, error) {
+	metrics := observability.GetMetrics()
+	metrics.RecordExternalAPIRequest(BreakerAnthropic, "chat_stream")
+	timer := metrics.NewTimer()
+
+	model := ModelFromContext(ctx, s.model)
+	result, err := WithCircuitBreaker(ctx, BreakerAnthropic, func() (string, error) {
+		body, err := json.Marshal(anthropicRequest{
+			Model:     model,
+			MaxTokens: s.maxTokens,
+			System:    systemPrompt,
+			Messages:  anthropicTurns(messages),
+			Stream:    true,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to encode request: %w", err)
+		}
+
+		return Retry(ctx, func() (string, error) {
+			resp, err := s.post(ctx, body)
+			if err != nil {
+				return "", err
+			}
+			defer resp.Body.Close()
+
+			stream := &deltaStream{onDelta: onDelta}
+			var inputTokens, outputTokens int64
+			err = readSSEData(resp.Body, func(data []byte) error {
+				var event anthropicStreamEvent
+				if err := json.Unmarshal(data, &event); err != nil {
+					return fmt.Errorf("failed to decode Anthropic stream event: %w", err)
+				}
+				switch event.Type {
+				case "message_start":
+					inputTokens = event.Message.Usage.InputTokens
+				case "content_block_delta":
+					return stream.send(event.Delta.Text)
+				case "message_delta":
+					outputTokens = event.Usage.OutputTokens
+				case "error":
+					return fmt.Errorf("stream error from Anthropic: %s", event.Error.Message)
+				}
+				return nil
+			})
+			if err != nil {
+				return "", stream.fail(err)
+			}
+			metrics.RecordLLMTokens(BreakerAnthropic, model, inputTokens, outputTokens)
+			RecordLLMUsage(ctx, BreakerAnthropic, model, inputTokens, outputTokens)
+
+			if stream.text.Len() == 0 {
+				return "", fmt.Errorf("empty response from Anthropic")
+			}
+			return stream.text.String(), nil
+		})
+	})
+
+	timer.ObserveExternalAPI(BreakerAnthropic, "chat_stream")
+	if err != nil {
+		metrics.RecordExternalAPIError(BreakerAnthropic, "chat_stream", categorizeAPIError(err))
+	}
+	return result, err
+}
+
+// anthropicTurns converts a conversation to Messages API turns, dropping messages with
+// roles other than user and assistant
+func anthropicTurns(messages []ChatMessage) []anthropicMessage {
+	turns := make([]anthropicMessage, 0, len(messages))
+	for _, msg := range messages {
+		switch msg.Role {
+		case "user", "assistant":
+			turns = append(turns, anthropicMessage{Role: msg.Role, Content: msg.Content})
+		}
+	}
+	return turns
+}
+
+// send makes a Messages API call and returns the text of the reply
+func (s *AnthropicService) send(ctx context.Context, operation, systemPrompt string, messages []anthropicMessage) (string, error) {
+	metrics := observability.GetMetrics()
+	metrics.RecordExternalAPIRequest(BreakerAnthropic, operation)
+	timer := metrics.NewTimer()
+
+	model := ModelFromContext(ctx, s.model)
+	result, err := WithCircuitBreaker(ctx, BreakerAnthropic, func() (string, error) {
+		body, err := json.Marshal(anthropicRequest{
+			Model:     model,
+			MaxTokens: s.maxTokens,
+			System:    systemPrompt,
+			Messages:  messages,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to encode request: %w", err)
+		}
+
+		return Retry(ctx, func() (string, error) {
+			resp, err := s.post(ctx, body)
+			if err != nil {
+				return "", err
+			}
+			defer resp.Body.Close()
+
+			var reply anthropicResponse
+			if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+				return "", fmt.Errorf("failed to decode Anthropic response: %w", err)
+			}
+			metrics.RecordLLMTokens(BreakerAnthropic, model, reply.Usage.InputTokens, reply.Usage.OutputTokens)
+			RecordLLMUsage(ctx, BreakerAnthropic, model, reply.Usage.InputTokens, reply.Usage.OutputTokens)
+
+			var text strings.Builder
+			for _, block := range reply.Content {
+				if block.Type == "text" {
+					text.WriteString(block.Text)
+				}
+			}
+			if text.Len() == 0 {
+				return "", fmt.Errorf("empty response from Anthropic")
+			}
+			return text.String(), nil
+		})
+	})
+
+	timer.ObserveExternalAPI(BreakerAnthropic, operation)
+	if err != nil {
+		metrics.RecordExternalAPIError(BreakerAnthropic, operation, categorizeAPIError(err))
+	}
+	return result, err
+}
+
+// post makes a Messages API request, returning the response if its status is OK. The
+// caller closes the body.
+func (s *AnthropicService) post(ctx context.Context, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", s.apiKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to invoke Anthropic: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var apiErr anthropicError
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return nil, newStatusError("Anthropic", resp, apiErr.Error.Message)
+		}
+		return nil, newStatusError("Anthropic", resp, "")
+	}
+	return resp, nil
+}
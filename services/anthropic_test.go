@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	appconfig "trade-machine/config"
+)
+
+// newTestAnthropicService points an AnthropicService at handler
+func newTestAnthropicService(t *testing.T, handler http.HandlerFunc) *AnthropicService {
+	t.Helper()
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := appconfig.NewTestConfig()
+	cfg.Anthropic.APIKey = "test-key"
+	cfg.Anthropic.BaseURL = server.URL
+	service, err := NewAnthropicService(cfg)
+	if err != nil {
+		t.Fatalf("NewAnthropicService() error = %v", err)
+	}
+	return service
+}
+
+func TestNewAnthropicService_MissingAPIKey(t *testing.T) {
+	if _, err := NewAnthropicService(appconfig.NewTestConfig()); err == nil {
+		t.Error("expected error without an API key")
+	}
+}
+
+func TestAnthropicService_ImplementsLLMService(t *testing.T) {
+	var _ LLMService = (*AnthropicService)(nil)
+}
+
+func TestAnthropicInvokeWithPrompt_Success(t *testing.T) {
+	var got anthropicRequest
+	service := newTestAnthropicService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("path = %s, want /v1/messages", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("missing auth headers: %v", r.Header)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"content":[{"type":"text","text":"Hello "},{"type":"text","text":"from Claude"}],"stop_reason":"end_turn"}`))
+	})
+
+	result, err := service.InvokeWithPrompt(WithModel(context.Background(), "claude-haiku-4-5"), "system", "user")
+	if err != nil {
+		t.Fatalf("InvokeWithPrompt() error = %v", err)
+	}
+	if result != "Hello from Claude" {
+		t.Errorf("result = %q, want the joined text blocks", result)
+	}
+	if got.Model != "claude-haiku-4-5" || got.System != "system" || got.MaxTokens != 4096 {
+		t.Errorf("request = %+v, want the overridden model, system prompt and token limit", got)
+	}
+	if len(got.Messages) != 1 || got.Messages[0].Role != "user" || got.Messages[0].Content != "user" {
+		t.Errorf("messages = %+v, want the user prompt", got.Messages)
+	}
+}
+
+func TestAnthropicInvokeWithPrompt_APIError(t *testing.T) {
+	service := newTestAnthropicService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`))
+	})
+
+	_, err := service.InvokeWithPrompt(context.Background(), "system", "user")
+	if err == nil || !strings.Contains(err.Error(), "401") || !strings.Contains(err.Error(), "invalid x-api-key") {
+		t.Errorf("error = %v, want the status and API message", err)
+	}
+}
+
+func TestAnthropicInvokeWithPrompt_EmptyContent(t *testing.T) {
+	service := newTestAnthropicService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"content":[],"stop_reason":"end_turn"}`))
+	})
+
+	if _, err := service.InvokeWithPrompt(context.Background(), "system", "user"); err == nil {
+		t.Error("expected error for an empty response")
+	}
+}
+
+func TestAnthropicInvokeStructured_Success(t *testing.T) {
+	service := newTestAnthropicService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"content":[{"type":"text","text":"{\"score\": 42}"}]}`))
+	})
+
+	var result struct {
+		Score int `json:"score"`
+	}
+	if err := service.InvokeStructured(context.Background(), "system", "user", &result); err != nil {
+		t.Fatalf("InvokeStructured() error = %v", err)
+	}
+	if result.Score != 42 {
+		t.Errorf("score = %d, want 42", result.Score)
+	}
+}
+
+func TestAnthropicChat_MessageRoles(t *testing.T) {
+	var got anthropicRequest
+	service := newTestAnthropicService(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"content":[{"type":"text","text":"ok"}]}`))
+	})
+
+	messages := []ChatMessage{
+		{Role: "user", Content: "first"},
+		{Role: "assistant", Content: "reply"},
+		{Role: "system", Content: "dropped"},
+		{Role: "user", Content: "second"},
+	}
+	if _, err := service.Chat(context.Background(), "system", messages); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if len(got.Messages) != 3 || got.Messages[1].Role != "assistant" || got.Messages[2].Content != "second" {
+		t.Errorf("messages = %+v, want user and assistant turns in order", got.Messages)
+	}
+	if got.System != "system" {
+		t.Errorf("system = %q, want the system prompt", got.System)
+	}
+}
+
+func TestAnthropicInvokeWithPrompt_RecordsLLMUsage(t *testing.T) {
+	service := newTestAnthropicService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":120,"output_tokens":30}}`))
+	})
+
+	ctx, tally := WithLLMUsage(context.Background())
+	for range 2 {
+		if _, err := service.InvokeWithPrompt(ctx, "system", "user"); err != nil {
+			t.Fatalf("InvokeWithPrompt() error = %v", err)
+		}
+	}
+	want := LLMCall{Provider: BreakerAnthropic, Model: "claude-sonnet-4-5", InputTokens: 120, OutputTokens: 30}
+	if calls := tally.Calls(); len(calls) != 2 || calls[0] != want || calls[1] != want {
+		t.Errorf("calls = %+v, want two of %+v", calls, want)
+	}
+
+	// Calls without a tally aren't recorded anywhere
+	if _, err := service.InvokeWithPrompt(context.Background(), "system", "user"); err != nil {
+		t.Fatalf("InvokeWithPrompt() error = %v", err)
+	}
+	if n := len(tally.Calls()); n != 2 {
+		t.Errorf("tally has %d calls, want 2", n)
+	}
+}
+
+func TestAnthropicChatStream(t *testing.T) {
+	var got anthropicRequest
+	service := newTestAnthropicService(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`event: message_start
+data: {"type":"message_start","message":{"usage":{"input_tokens":250,"output_tokens":1}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Apple "}}
+
+event: ping
+data: {"type":"ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"looks strong"}}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":35}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`))
+	})
+
+	ctx, tally := WithLLMUsage(context.Background())
+	var deltas []string
+	result, err := service.ChatStream(ctx, "system", []ChatMessage{{Role: "user", Content: "How is AAPL?"}}, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if !got.Stream || got.System != "system" || len(got.Messages) != 1 {
+		t.Errorf("request = %+v, want a streamed request with the conversation", got)
+	}
+	if result != "Apple looks strong" || len(deltas) != 2 || deltas[1] != "looks strong" {
+		t.Errorf("result = %q, deltas = %q, want the reply passed on piece by piece", result, deltas)
+	}
+	want := LLMCall{Provider: BreakerAnthropic, Model: "claude-sonnet-4-5", InputTokens: 250, OutputTokens: 35}
+	if calls := tally.Calls(); len(calls) != 1 || calls[0] != want {
+		t.Errorf("calls = %+v, want %+v", calls, want)
+	}
+}
+
+func TestAnthropicChatStream_ErrorEvent(t *testing.T) {
+	service := newTestAnthropicService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`data: {"type":"content_block_delta","delta":{"text":"Apple "}}
+
+data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}
+
+`))
+	})
+
+	_, err := service.ChatStream(context.Background(), "system", nil, func(string) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "Overloaded") {
+		t.Errorf("ChatStream() error = %v, want the stream's error", err)
+	}
+}
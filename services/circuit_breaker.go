@@ -2,27 +2,64 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/sony/gobreaker/v2"
 
+	"trade-machine/events"
 	"trade-machine/observability"
 )
 
 // CircuitBreakerConfig holds configuration for a circuit breaker
 type CircuitBreakerConfig struct {
-	MaxRequests uint32        // max requests allowed in half-open state
-	Interval    time.Duration // cyclic period of the closed state to clear counts
-	Timeout     time.Duration // period of the open state before transitioning to half-open
+	MaxRequests   uint32        // max requests allowed in half-open state
+	Interval      time.Duration // cyclic period of the closed state to clear counts
+	Timeout       time.Duration // period of the open state before transitioning to half-open
+	DegradeRatio  float64       // failure ratio that degrades a closed breaker (0 disables soft degradation)
+	ProbeInterval time.Duration // minimum gap between requests let through while degraded
 }
 
 // DefaultCircuitBreakerConfig returns sensible defaults per the issue spec
 var DefaultCircuitBreakerConfig = CircuitBreakerConfig{
-	MaxRequests: 5,
-	Interval:    1 * time.Minute,
-	Timeout:     30 * time.Second,
+	MaxRequests:   5,
+	Interval:      1 * time.Minute,
+	Timeout:       30 * time.Second,
+	DegradeRatio:  0.2,
+	ProbeInterval: 2 * time.Second,
+}
+
+// minBreakerRequests is how many requests a breaker must see before failures degrade or trip it
+const minBreakerRequests = 5
+
+// tripRatio is the failure ratio that opens a breaker
+const tripRatio = 0.5
+
+// DegradationLevel describes how much of a provider's traffic a breaker lets through
+type DegradationLevel int
+
+const (
+	// DegradationNone means the provider is healthy and requests flow freely
+	DegradationNone DegradationLevel = iota
+	// DegradationSoft means the provider is failing often; only a trickle of probe
+	// requests is let through and agents report lower confidence
+	DegradationSoft
+	// DegradationOpen means the breaker is open and every request is rejected
+	DegradationOpen
+)
+
+// String returns the level's name as reported by health checks
+func (l DegradationLevel) String() string {
+	switch l {
+	case DegradationSoft:
+		return "degraded"
+	case DegradationOpen:
+		return "unavailable"
+	default:
+		return "healthy"
+	}
 }
 
 // CircuitBreakerRegistry manages circuit breakers for different services
@@ -30,13 +67,21 @@ type CircuitBreakerRegistry struct {
 	mu       sync.RWMutex
 	breakers map[string]*gobreaker.CircuitBreaker[any]
 	config   CircuitBreakerConfig
+
+	probeMu   sync.Mutex
+	lastProbe map[string]time.Time        // last request let through while degraded
+	levels    map[string]DegradationLevel // last reported level, for change detection
+	lastErrs  map[string]string           // last error from each provider, sampled in alerts
 }
 
 // NewCircuitBreakerRegistry creates a new registry with the given config
 func NewCircuitBreakerRegistry(config CircuitBreakerConfig) *CircuitBreakerRegistry {
 	return &CircuitBreakerRegistry{
-		breakers: make(map[string]*gobreaker.CircuitBreaker[any]),
-		config:   config,
+		breakers:  make(map[string]*gobreaker.CircuitBreaker[any]),
+		config:    config,
+		lastProbe: make(map[string]time.Time),
+		levels:    make(map[string]DegradationLevel),
+		lastErrs:  make(map[string]string),
 	}
 }
 
@@ -63,13 +108,18 @@ func (r *CircuitBreakerRegistry) GetBreaker(name string) *gobreaker.CircuitBreak
 		MaxRequests: r.config.MaxRequests,
 		Interval:    r.config.Interval,
 		Timeout:     r.config.Timeout,
+		// A provider rejecting a request, or our own quota or deadline cutting it short,
+		// says nothing about the provider's health
+		IsSuccessful: func(err error) bool {
+			return err == nil || isClientError(err) || errors.Is(err, ErrRateLimited) ||
+				errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+		},
 		ReadyToTrip: func(counts gobreaker.Counts) bool {
 			// Trip the breaker if failure ratio exceeds 50% with at least 5 requests
-			failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
-			return counts.Requests >= 5 && failureRatio >= 0.5
+			return counts.Requests >= minBreakerRequests && failureRatio(counts) >= tripRatio
 		},
 		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
-			observability.Warn("circuit breaker state change",
+			logger.Warn("circuit breaker state change",
 				"breaker", name,
 				"from", from.String(),
 				"to", to.String())
@@ -77,8 +127,17 @@ func (r *CircuitBreakerRegistry) GetBreaker(name string) *gobreaker.CircuitBreak
 			// Record metrics for circuit breaker state changes
 			metrics := observability.GetMetrics()
 			metrics.SetCircuitBreakerState(name, stateToInt(to))
+			metrics.RecordCircuitBreakerTransition(name, from.String(), to.String())
+			// Open breakers move to half-open when next checked, outside Execute, so the
+			// level is exported here too. The breaker is locked during the callback; a state
+			// change starts a new generation with empty counts, so the state alone gives the level.
+			r.setLevel(name, stateLevel(to))
 			if to == gobreaker.StateOpen {
-				metrics.RecordCircuitBreakerTrip(name)
+				events.Publish(events.BreakerOpened, events.BreakerOpen{
+					Provider:  name,
+					LastError: redactErrorSample(r.lastError(name)),
+					RecoverAt: time.Now().Add(r.config.Timeout),
+				})
 			}
 		},
 	}
@@ -89,27 +148,142 @@ func (r *CircuitBreakerRegistry) GetBreaker(name string) *gobreaker.CircuitBreak
 	return cb
 }
 
-// Execute runs the given function through the named circuit breaker
+// failureRatio returns the share of requests that failed in the current interval
+func failureRatio(counts gobreaker.Counts) float64 {
+	if counts.Requests == 0 {
+		return 0
+	}
+	return float64(counts.TotalFailures) / float64(counts.Requests)
+}
+
+// stateLevel returns the degradation level of a breaker that has just entered state,
+// before any request has been counted
+func stateLevel(state gobreaker.State) DegradationLevel {
+	switch state {
+	case gobreaker.StateOpen:
+		return DegradationOpen
+	case gobreaker.StateHalfOpen:
+		return DegradationSoft
+	default:
+		return DegradationNone
+	}
+}
+
+// levelOf derives a breaker's degradation level from its state and failure ratio.
+// Half-open breakers are degraded: gobreaker already limits them to probe requests.
+func (r *CircuitBreakerRegistry) levelOf(cb *gobreaker.CircuitBreaker[any]) DegradationLevel {
+	switch cb.State() {
+	case gobreaker.StateOpen:
+		return DegradationOpen
+	case gobreaker.StateHalfOpen:
+		return DegradationSoft
+	}
+	counts := cb.Counts()
+	if r.config.DegradeRatio > 0 && counts.Requests >= minBreakerRequests && failureRatio(counts) >= r.config.DegradeRatio {
+		return DegradationSoft
+	}
+	return DegradationNone
+}
+
+// Level returns the named breaker's degradation level. Breakers that have not
+// been used yet are healthy.
+func (r *CircuitBreakerRegistry) Level(name string) DegradationLevel {
+	r.mu.RLock()
+	cb, exists := r.breakers[name]
+	r.mu.RUnlock()
+	if !exists {
+		return DegradationNone
+	}
+	return r.levelOf(cb)
+}
+
+// admitProbe reports whether a degraded, closed breaker may let another request
+// through, allowing at most one per ProbeInterval
+func (r *CircuitBreakerRegistry) admitProbe(name string) bool {
+	r.probeMu.Lock()
+	defer r.probeMu.Unlock()
+	now := time.Now()
+	if last, ok := r.lastProbe[name]; ok && now.Sub(last) < r.config.ProbeInterval {
+		return false
+	}
+	r.lastProbe[name] = now
+	return true
+}
+
+// recordError keeps the provider's latest error as the sample for a breaker alert
+func (r *CircuitBreakerRegistry) recordError(name string, err error) {
+	r.probeMu.Lock()
+	defer r.probeMu.Unlock()
+	r.lastErrs[name] = err.Error()
+}
+
+// lastError returns the provider's latest error, or an empty string if it has not failed
+func (r *CircuitBreakerRegistry) lastError(name string) string {
+	r.probeMu.Lock()
+	defer r.probeMu.Unlock()
+	return r.lastErrs[name]
+}
+
+// recordLevel logs and exports the breaker's degradation level when it changes
+func (r *CircuitBreakerRegistry) recordLevel(name string, cb *gobreaker.CircuitBreaker[any]) {
+	r.setLevel(name, r.levelOf(cb))
+}
+
+// setLevel logs and exports level as the breaker's degradation level if it differs from
+// the last one reported
+func (r *CircuitBreakerRegistry) setLevel(name string, level DegradationLevel) {
+	r.probeMu.Lock()
+	previous := r.levels[name]
+	r.levels[name] = level
+	r.probeMu.Unlock()
+
+	if level == previous {
+		return
+	}
+	logger.Warn("circuit breaker degradation level change",
+		"breaker", name,
+		"from", previous.String(),
+		"to", level.String())
+	observability.GetMetrics().SetCircuitBreakerLevel(name, int(level))
+}
+
+// rejectionLogger logs requests turned away by a breaker, which happens on every call
+// while a provider is down
+var rejectionLogger = observability.Sampled(logger, 20)
+
+// Execute runs the given function through the named circuit breaker. While the
+// breaker is degraded only a trickle of probe requests reaches the provider.
 func (r *CircuitBreakerRegistry) Execute(ctx context.Context, name string, fn func() (any, error)) (any, error) {
 	cb := r.GetBreaker(name)
 
+	if cb.State() == gobreaker.StateClosed && r.levelOf(cb) == DegradationSoft && !r.admitProbe(name) {
+		rejectionLogger.Warn("circuit breaker degraded, deferring request to next probe",
+			"breaker", name)
+		return nil, fmt.Errorf("service %s degraded: circuit breaker admitting probe requests only", name)
+	}
+
+	defer r.recordLevel(name, cb)
 	result, err := cb.Execute(func() (any, error) {
 		// Check context before executing
 		if ctx.Err() != nil {
 			return nil, ctx.Err()
 		}
-		return fn()
+		result, err := fn()
+		if err != nil {
+			r.recordError(name, err)
+		}
+		return result, err
 	})
 
 	if err != nil {
 		// Check if it's a circuit breaker open error
 		if err == gobreaker.ErrOpenState {
-			observability.Warn("circuit breaker open, rejecting request",
+			rejectionLogger.Warn("circuit breaker open, rejecting request",
 				"breaker", name)
 			return nil, fmt.Errorf("service %s unavailable: circuit breaker open", name)
 		}
 		if err == gobreaker.ErrTooManyRequests {
-			observability.Warn("circuit breaker half-open, too many requests",
+			rejectionLogger.Warn("circuit breaker half-open, too many requests",
 				"breaker", name)
 			return nil, fmt.Errorf("service %s unavailable: too many requests in half-open state", name)
 		}
@@ -129,6 +303,7 @@ func (r *CircuitBreakerRegistry) Status() map[string]CircuitBreakerStatus {
 		status[name] = CircuitBreakerStatus{
 			Name:             name,
 			State:            cb.State().String(),
+			Level:            r.levelOf(cb).String(),
 			Requests:         counts.Requests,
 			TotalSuccesses:   counts.TotalSuccesses,
 			TotalFailures:    counts.TotalFailures,
@@ -143,6 +318,7 @@ func (r *CircuitBreakerRegistry) Status() map[string]CircuitBreakerStatus {
 type CircuitBreakerStatus struct {
 	Name             string `json:"name"`
 	State            string `json:"state"`
+	Level            string `json:"level"` // healthy, degraded or unavailable
 	Requests         uint32 `json:"requests"`
 	TotalSuccesses   uint32 `json:"total_successes"`
 	TotalFailures    uint32 `json:"total_failures"`
@@ -167,6 +343,11 @@ func SetGlobalRegistry(r *CircuitBreakerRegistry) {
 	globalRegistry = r
 }
 
+// BreakerLevel returns the degradation level of the named breaker in the global registry
+func BreakerLevel(name string) DegradationLevel {
+	return GetGlobalRegistry().Level(name)
+}
+
 // WithCircuitBreaker wraps a function call with circuit breaker protection
 func WithCircuitBreaker[T any](ctx context.Context, name string, fn func() (T, error)) (T, error) {
 	registry := GetGlobalRegistry()
@@ -189,7 +370,13 @@ const (
 	BreakerNewsAPI      = "newsapi"
 	BreakerAlpaca       = "alpaca"
 	BreakerOpenAI       = "openai"
+	BreakerAnthropic    = "anthropic"
+	BreakerOllama       = "ollama"
 	BreakerFMP          = "fmp"
+	BreakerReddit       = "reddit"
+	BreakerStockTwits   = "stocktwits"
+	BreakerFRED         = "fred"
+	BreakerIBKR         = "ibkr"
 )
 
 // stateToInt converts a circuit breaker state to an integer for metrics
@@ -7,7 +7,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/sony/gobreaker/v2"
+
+	"trade-machine/observability"
 )
 
 func TestNewCircuitBreakerRegistry(t *testing.T) {
@@ -176,6 +179,102 @@ func TestCircuitBreakerRegistry_TripsAfterFailures(t *testing.T) {
 	}
 }
 
+func TestCircuitBreakerRegistry_SoftDegradation(t *testing.T) {
+	config := CircuitBreakerConfig{
+		MaxRequests:   1,
+		Interval:      1 * time.Minute,
+		Timeout:       1 * time.Second,
+		DegradeRatio:  0.2,
+		ProbeInterval: 1 * time.Hour,
+	}
+	registry := NewCircuitBreakerRegistry(config)
+	ctx := context.Background()
+
+	if level := registry.Level("flaky-service"); level != DegradationNone {
+		t.Errorf("unused breaker level = %s, want healthy", level)
+	}
+
+	// 2 failures in 5 requests cross the degrade ratio but not the trip ratio
+	for i := 0; i < 3; i++ {
+		_, _ = registry.Execute(ctx, "flaky-service", func() (any, error) { return "ok", nil })
+	}
+	for i := 0; i < 2; i++ {
+		_, _ = registry.Execute(ctx, "flaky-service", func() (any, error) { return nil, errors.New("fail") })
+	}
+
+	if level := registry.Level("flaky-service"); level != DegradationSoft {
+		t.Fatalf("level = %s, want degraded", level)
+	}
+	if status := registry.Status()["flaky-service"]; status.State != "closed" || status.Level != "degraded" {
+		t.Errorf("status = %s/%s, want closed/degraded", status.State, status.Level)
+	}
+
+	// The first probe is let through, the next one waits for the probe interval
+	calls := 0
+	probe := func() (any, error) { calls++; return "ok", nil }
+	if _, err := registry.Execute(ctx, "flaky-service", probe); err != nil {
+		t.Errorf("first probe should be admitted, got %v", err)
+	}
+	_, err := registry.Execute(ctx, "flaky-service", probe)
+	if err == nil || err.Error() != "service flaky-service degraded: circuit breaker admitting probe requests only" {
+		t.Errorf("expected degraded rejection, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("provider called %d times, want 1", calls)
+	}
+}
+
+func TestCircuitBreakerRegistry_LevelMetricFollowsStateChanges(t *testing.T) {
+	config := CircuitBreakerConfig{
+		MaxRequests: 1,
+		Interval:    1 * time.Minute,
+		Timeout:     20 * time.Millisecond,
+	}
+	registry := NewCircuitBreakerRegistry(config)
+	ctx := context.Background()
+	level := func() float64 {
+		return testutil.ToFloat64(observability.GetMetrics().CircuitBreakerLevel.WithLabelValues("recovering-service"))
+	}
+
+	for i := 0; i < 5; i++ {
+		_, _ = registry.Execute(ctx, "recovering-service", func() (any, error) {
+			return nil, errors.New("fail")
+		})
+	}
+	if got := level(); got != float64(DegradationOpen) {
+		t.Fatalf("level metric = %v after tripping, want %d", got, DegradationOpen)
+	}
+
+	// The breaker turns half-open when its state is next read, without a request
+	time.Sleep(2 * config.Timeout)
+	if status := registry.Status()["recovering-service"]; status.State != "half-open" {
+		t.Fatalf("state = %s, want half-open", status.State)
+	}
+	if got := level(); got != float64(DegradationSoft) {
+		t.Errorf("level metric = %v after the timeout, want %d", got, DegradationSoft)
+	}
+
+	if _, err := registry.Execute(ctx, "recovering-service", func() (any, error) { return "ok", nil }); err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+	if got := level(); got != float64(DegradationNone) {
+		t.Errorf("level metric = %v after recovering, want %d", got, DegradationNone)
+	}
+}
+
+func TestDegradationLevel_String(t *testing.T) {
+	tests := map[DegradationLevel]string{
+		DegradationNone: "healthy",
+		DegradationSoft: "degraded",
+		DegradationOpen: "unavailable",
+	}
+	for level, want := range tests {
+		if got := level.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", level, got, want)
+		}
+	}
+}
+
 func TestWithCircuitBreaker_Success(t *testing.T) {
 	// Reset global registry for test isolation
 	testRegistry := NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig)
@@ -286,6 +385,9 @@ func TestDefaultCircuitBreakerConfig(t *testing.T) {
 	if DefaultCircuitBreakerConfig.Timeout != 30*time.Second {
 		t.Errorf("expected Timeout=30s, got %v", DefaultCircuitBreakerConfig.Timeout)
 	}
+	if DefaultCircuitBreakerConfig.DegradeRatio != 0.2 {
+		t.Errorf("expected DegradeRatio=0.2, got %v", DefaultCircuitBreakerConfig.DegradeRatio)
+	}
 }
 
 func TestBreakerConstants(t *testing.T) {
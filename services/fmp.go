@@ -4,28 +4,72 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
 )
 
+// ratiosTTL is how long TTM ratios are cached. They only move with quarterly reports,
+// and every screener run looks them up for each candidate.
+const ratiosTTL = time.Hour
+
 // FMPService handles communication with Financial Modeling Prep API
 type FMPService struct {
 	apiKey     string
 	httpClient *http.Client
 	baseURL    string
+	ratiosMu   sync.Mutex
+	ratios     map[string]cachedRatios
+}
+
+// cachedRatios is a ratios response and when it was fetched
+type cachedRatios struct {
+	ratios    *fmpRatiosResponse
+	fetchedAt time.Time
 }
 
 // NewFMPService creates a new FMPService instance
 func NewFMPService(apiKey string) *FMPService {
 	return &FMPService{
 		apiKey:     apiKey,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		httpClient: newLedgerHTTPClient(BreakerFMP, 30*time.Second),
 		baseURL:    "https://financialmodelingprep.com/api/v3",
+		ratios:     make(map[string]cachedRatios),
 	}
 }
 
+// fmpSectorNames maps GICS sectors to the sector names the FMP screener filter accepts
+var fmpSectorNames = map[string]string{
+	models.SectorCommunicationServices: "Communication Services",
+	models.SectorConsumerDiscretionary: "Consumer Cyclical",
+	models.SectorConsumerStaples:       "Consumer Defensive",
+	models.SectorEnergy:                "Energy",
+	models.SectorFinancials:            "Financial Services",
+	models.SectorHealthCare:            "Healthcare",
+	models.SectorIndustrials:           "Industrials",
+	models.SectorInformationTechnology: "Technology",
+	models.SectorMaterials:             "Basic Materials",
+	models.SectorRealEstate:            "Real Estate",
+	models.SectorUtilities:             "Utilities",
+}
+
+// fmpSectorName translates a sector filter (GICS or any known alias) to FMP's naming
+func fmpSectorName(sector string) string {
+	if name, ok := fmpSectorNames[models.NormalizeSector(sector)]; ok {
+		return name
+	}
+	return sector
+}
+
 // fmpScreenerResponse represents a single result from the FMP stock screener API
 type fmpScreenerResponse struct {
 	Symbol            string  `json:"symbol"`
@@ -84,6 +128,53 @@ type fmpProfileResponse struct {
 	IsAdr             bool    `json:"isAdr"`
 }
 
+// fmpEarningsResponse represents a single report from the FMP earnings calendar API
+type fmpEarningsResponse struct {
+	Date   string `json:"date"`
+	Symbol string `json:"symbol"`
+}
+
+// fmpInsiderTradeResponse represents a single Form 4 transaction from the FMP insider trading API
+type fmpInsiderTradeResponse struct {
+	Symbol               string  `json:"symbol"`
+	FilingDate           string  `json:"filingDate"`
+	TransactionDate      string  `json:"transactionDate"`
+	ReportingName        string  `json:"reportingName"`
+	TypeOfOwner          string  `json:"typeOfOwner"`
+	TransactionType      string  `json:"transactionType"`
+	SecuritiesTransacted float64 `json:"securitiesTransacted"`
+	Price                float64 `json:"price"`
+}
+
+// fmpFinancialGrowthResponse represents one period's growth from the FMP financial growth API
+type fmpFinancialGrowthResponse struct {
+	Symbol        string  `json:"symbol"`
+	Date          string  `json:"date"`
+	RevenueGrowth float64 `json:"revenueGrowth"`
+	EPSGrowth     float64 `json:"epsgrowth"`
+}
+
+// fmpPriceChangeResponse represents a symbol's percent price changes from the FMP price change API
+type fmpPriceChangeResponse struct {
+	Symbol      string  `json:"symbol"`
+	OneMonth    float64 `json:"1M"`
+	ThreeMonths float64 `json:"3M"`
+	SixMonths   float64 `json:"6M"`
+	OneYear     float64 `json:"1Y"`
+}
+
+// fmpDividendHistoryResponse represents a symbol's dividends from the FMP dividend history API
+type fmpDividendHistoryResponse struct {
+	Symbol     string `json:"symbol"`
+	Historical []struct {
+		Date        string  `json:"date"` // Ex-dividend date
+		AdjDividend float64 `json:"adjDividend"`
+		Dividend    float64 `json:"dividend"`
+		RecordDate  string  `json:"recordDate"`
+		PaymentDate string  `json:"paymentDate"`
+	} `json:"historical"`
+}
+
 // fmpRatiosResponse represents key ratios from the FMP API
 type fmpRatiosResponse struct {
 	Symbol                   string  `json:"symbol"`
@@ -99,7 +190,7 @@ func (s *FMPService) Screen(ctx context.Context, criteria ScreenCriteria) ([]Scr
 	return WithCircuitBreaker(ctx, BreakerFMP, func() ([]ScreenerResult, error) {
 		var results []ScreenerResult
 
-		err := WithRetry(ctx, DefaultRetryConfig, func() error {
+		err := currentRetryPolicy().Do(ctx, func() error {
 			params := url.Values{}
 			params.Set("apikey", s.apiKey)
 
@@ -110,7 +201,19 @@ func (s *FMPService) Screen(ctx context.Context, criteria ScreenCriteria) ([]Scr
 				params.Set("marketCapLowerThan", strconv.FormatInt(criteria.MarketCapMax, 10))
 			}
 			if criteria.Sector != "" {
-				params.Set("sector", criteria.Sector)
+				params.Set("sector", fmpSectorName(criteria.Sector))
+			}
+			if len(criteria.Exchanges) > 0 {
+				params.Set("exchange", strings.Join(criteria.Exchanges, ","))
+			}
+			if criteria.Country != "" {
+				params.Set("country", criteria.Country)
+			}
+			if criteria.PriceMin > 0 {
+				params.Set("priceMoreThan", strconv.FormatFloat(criteria.PriceMin, 'f', -1, 64))
+			}
+			if criteria.AvgVolumeMin > 0 {
+				params.Set("volumeMoreThan", strconv.FormatInt(criteria.AvgVolumeMin, 10))
 			}
 			if criteria.Limit > 0 {
 				params.Set("limit", strconv.Itoa(criteria.Limit))
@@ -131,43 +234,13 @@ func (s *FMPService) Screen(ctx context.Context, criteria ScreenCriteria) ([]Scr
 			defer resp.Body.Close()
 
 			if resp.StatusCode != http.StatusOK {
-				return fmt.Errorf("screener API returned status %d", resp.StatusCode)
-			}
-
-			var screenerResp []fmpScreenerResponse
-			if err := json.NewDecoder(resp.Body).Decode(&screenerResp); err != nil {
-				return fmt.Errorf("failed to decode screener response: %w", err)
+				return newStatusError("FMP screener API", resp, "")
 			}
 
-			// Now we need to fetch ratios for each stock to get P/E, P/B, and dividend yield
-			// For efficiency, we'll fetch them in batches or filter post-fetch
-			// The FMP screener doesn't directly support P/E and P/B filters,
-			// so we need to fetch ratios and filter client-side
-			results = make([]ScreenerResult, 0, len(screenerResp))
-
-			for _, stock := range screenerResp {
-				// Skip ETFs and inactive stocks
-				if stock.IsEtf || !stock.IsActivelyTrading {
-					continue
-				}
-
-				result := ScreenerResult{
-					Symbol:      stock.Symbol,
-					CompanyName: stock.CompanyName,
-					MarketCap:   stock.MarketCap,
-					Sector:      stock.Sector,
-					Industry:    stock.Industry,
-					Price:       stock.Price,
-					Beta:        stock.Beta,
-					Volume:      stock.Volume,
-					Exchange:    stock.ExchangeShortName,
-					Country:     stock.Country,
-				}
-
-				results = append(results, result)
-			}
-
-			return nil
+			// The FMP screener doesn't directly support P/E and P/B filters, so ratios are
+			// fetched and filtered client-side below
+			results, err = decodeScreenerResults(resp.Body, criteria)
+			return err
 		})
 
 		if err != nil {
@@ -187,12 +260,63 @@ func (s *FMPService) Screen(ctx context.Context, criteria ScreenCriteria) ([]Scr
 	})
 }
 
+// decodeScreenerResults decodes a screener response body, skipping ETFs, inactive stocks
+// and listings outside the requested exchanges and country
+func decodeScreenerResults(body io.Reader, criteria ScreenCriteria) ([]ScreenerResult, error) {
+	var screenerResp []fmpScreenerResponse
+	if err := json.NewDecoder(body).Decode(&screenerResp); err != nil {
+		return nil, fmt.Errorf("failed to decode screener response: %w", err)
+	}
+
+	results := make([]ScreenerResult, 0, len(screenerResp))
+	for _, stock := range screenerResp {
+		// Skip ETFs and inactive stocks
+		if stock.IsEtf || !stock.IsActivelyTrading {
+			continue
+		}
+		// FMP occasionally returns listings outside the requested exchanges (e.g. OTC)
+		if !matchesListing(stock, criteria) {
+			continue
+		}
+
+		sector, industry := models.NormalizeClassification(stock.Sector, stock.Industry)
+		results = append(results, ScreenerResult{
+			Symbol:      stock.Symbol,
+			CompanyName: stock.CompanyName,
+			MarketCap:   stock.MarketCap,
+			Sector:      sector,
+			Industry:    industry,
+			Price:       stock.Price,
+			Beta:        stock.Beta,
+			Volume:      stock.Volume,
+			Exchange:    stock.ExchangeShortName,
+			Country:     stock.Country,
+		})
+	}
+
+	return results, nil
+}
+
+// matchesListing reports whether a screener result is on an allowed exchange and in the
+// requested country
+func matchesListing(stock fmpScreenerResponse, criteria ScreenCriteria) bool {
+	if len(criteria.Exchanges) > 0 && !slices.ContainsFunc(criteria.Exchanges, func(e string) bool {
+		return strings.EqualFold(e, stock.ExchangeShortName)
+	}) {
+		return false
+	}
+	if criteria.Country != "" && !strings.EqualFold(criteria.Country, stock.Country) {
+		return false
+	}
+	return true
+}
+
 // enrichAndFilterResults fetches ratios for screener results and filters by P/E, P/B, etc.
 func (s *FMPService) enrichAndFilterResults(ctx context.Context, results []ScreenerResult, criteria ScreenCriteria) ([]ScreenerResult, error) {
 	filtered := make([]ScreenerResult, 0, len(results))
 
 	for _, result := range results {
-		ratios, err := s.getRatios(ctx, result.Symbol)
+		ratios, err := s.cachedRatios(ctx, result.Symbol)
 		if err != nil {
 			// Skip stocks where we can't fetch ratios, but don't fail the whole operation
 			continue
@@ -224,35 +348,83 @@ func (s *FMPService) enrichAndFilterResults(ctx context.Context, results []Scree
 	return filtered, nil
 }
 
-// getRatios fetches key ratios for a symbol
-func (s *FMPService) getRatios(ctx context.Context, symbol string) (*fmpRatiosResponse, error) {
-	reqURL := fmt.Sprintf("%s/ratios-ttm/%s?apikey=%s", s.baseURL, url.PathEscape(symbol), s.apiKey)
+// GetRatios returns TTM valuation ratios for a symbol, cached for an hour unless ctx
+// asks for fresh data
+func (s *FMPService) GetRatios(ctx context.Context, symbol string) (*Ratios, error) {
+	return WithCircuitBreaker(ctx, BreakerFMP, func() (*Ratios, error) {
+		r, err := s.cachedRatios(ctx, symbol)
+		if err != nil {
+			return nil, err
+		}
+		return &Ratios{
+			Symbol:        symbol,
+			PERatio:       r.PERatio,
+			PBRatio:       r.PriceToBookRatio,
+			DividendYield: r.DividendYieldPercentage,
+			EPS:           r.EPS,
+		}, nil
+	})
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create ratios request: %w", err)
+// cachedRatios returns ratios from the cache when fresh, fetching and caching them otherwise
+func (s *FMPService) cachedRatios(ctx context.Context, symbol string) (*fmpRatiosResponse, error) {
+	if !wantsFreshData(ctx) {
+		s.ratiosMu.Lock()
+		cached, ok := s.ratios[symbol]
+		s.ratiosMu.Unlock()
+		if ok && time.Since(cached.fetchedAt) < ratiosTTL {
+			return cached.ratios, nil
+		}
 	}
 
-	resp, err := s.httpClient.Do(req)
+	ratios, err := s.getRatios(ctx, symbol)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch ratios: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("ratios API returned status %d", resp.StatusCode)
+	now := time.Now()
+	s.ratiosMu.Lock()
+	defer s.ratiosMu.Unlock()
+	for sym, entry := range s.ratios {
+		if now.Sub(entry.fetchedAt) >= ratiosTTL {
+			delete(s.ratios, sym)
+		}
 	}
+	s.ratios[symbol] = cachedRatios{ratios: ratios, fetchedAt: now}
+	return ratios, nil
+}
 
-	var ratiosResp []fmpRatiosResponse
-	if err := json.NewDecoder(resp.Body).Decode(&ratiosResp); err != nil {
-		return nil, fmt.Errorf("failed to decode ratios response: %w", err)
-	}
+// getRatios fetches key ratios for a symbol
+func (s *FMPService) getRatios(ctx context.Context, symbol string) (*fmpRatiosResponse, error) {
+	return Retry(ctx, func() (*fmpRatiosResponse, error) {
+		reqURL := fmt.Sprintf("%s/ratios-ttm/%s?apikey=%s", s.baseURL, url.PathEscape(symbol), s.apiKey)
 
-	if len(ratiosResp) == 0 {
-		return nil, fmt.Errorf("no ratios data for symbol %s", symbol)
-	}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ratios request: %w", err)
+		}
+
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch ratios: %w", err)
+		}
+		defer resp.Body.Close()
 
-	return &ratiosResp[0], nil
+		if resp.StatusCode != http.StatusOK {
+			return nil, newStatusError("FMP ratios API", resp, "")
+		}
+
+		var ratiosResp []fmpRatiosResponse
+		if err := json.NewDecoder(resp.Body).Decode(&ratiosResp); err != nil {
+			return nil, fmt.Errorf("failed to decode ratios response: %w", err)
+		}
+
+		if len(ratiosResp) == 0 {
+			return nil, permanent(fmt.Errorf("no ratios data for symbol %s", symbol))
+		}
+
+		return &ratiosResp[0], nil
+	})
 }
 
 // GetCompanyProfile returns enriched company profile data for a symbol
@@ -260,7 +432,7 @@ func (s *FMPService) GetCompanyProfile(ctx context.Context, symbol string) (*Com
 	return WithCircuitBreaker(ctx, BreakerFMP, func() (*CompanyProfile, error) {
 		var profile *CompanyProfile
 
-		err := WithRetry(ctx, DefaultRetryConfig, func() error {
+		err := currentRetryPolicy().Do(ctx, func() error {
 			reqURL := fmt.Sprintf("%s/profile/%s?apikey=%s", s.baseURL, url.PathEscape(symbol), s.apiKey)
 
 			req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
@@ -275,39 +447,98 @@ func (s *FMPService) GetCompanyProfile(ctx context.Context, symbol string) (*Com
 			defer resp.Body.Close()
 
 			if resp.StatusCode != http.StatusOK {
-				return fmt.Errorf("profile API returned status %d", resp.StatusCode)
+				return newStatusError("FMP profile API", resp, "")
 			}
 
-			var profileResp []fmpProfileResponse
-			if err := json.NewDecoder(resp.Body).Decode(&profileResp); err != nil {
-				return fmt.Errorf("failed to decode profile response: %w", err)
+			profile, err = decodeCompanyProfile(resp.Body, symbol)
+			return err
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		return profile, nil
+	})
+}
+
+// decodeCompanyProfile decodes a profile response body, which lists at most one profile
+func decodeCompanyProfile(body io.Reader, symbol string) (*CompanyProfile, error) {
+	var profileResp []fmpProfileResponse
+	if err := json.NewDecoder(body).Decode(&profileResp); err != nil {
+		return nil, fmt.Errorf("failed to decode profile response: %w", err)
+	}
+
+	if len(profileResp) == 0 {
+		return nil, permanent(fmt.Errorf("no profile data for symbol %s", symbol))
+	}
+
+	p := profileResp[0]
+	sector, industry := models.NormalizeClassification(p.Sector, p.Industry)
+	return &CompanyProfile{
+		Symbol:            p.Symbol,
+		CompanyName:       p.CompanyName,
+		Price:             p.Price,
+		MarketCap:         p.MktCap,
+		Sector:            sector,
+		Industry:          industry,
+		Description:       p.Description,
+		CEO:               p.CEO,
+		Website:           p.Website,
+		Exchange:          p.ExchangeShortName,
+		Country:           p.Country,
+		Beta:              p.Beta,
+		VolAvg:            p.VolAvg,
+		LastDividend:      p.LastDiv,
+		Range52Week:       p.Range,
+		Changes:           p.Changes,
+		DCF:               p.DCF,
+		IPODate:           p.IPODate,
+		IsActivelyTrading: p.IsActivelyTrading,
+	}, nil
+}
+
+// GetNextEarningsDate returns the next scheduled earnings date for a symbol, or nil
+// when FMP has no upcoming report on its calendar
+func (s *FMPService) GetNextEarningsDate(ctx context.Context, symbol string) (*time.Time, error) {
+	return WithCircuitBreaker(ctx, BreakerFMP, func() (*time.Time, error) {
+		var next *time.Time
+
+		err := currentRetryPolicy().Do(ctx, func() error {
+			reqURL := fmt.Sprintf("%s/historical/earning_calendar/%s?limit=8&apikey=%s", s.baseURL, url.PathEscape(symbol), s.apiKey)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+			if err != nil {
+				return fmt.Errorf("failed to create earnings calendar request: %w", err)
 			}
 
-			if len(profileResp) == 0 {
-				return fmt.Errorf("no profile data for symbol %s", symbol)
+			resp, err := s.httpClient.Do(req)
+			if err != nil {
+				return fmt.Errorf("failed to fetch earnings calendar: %w", err)
 			}
+			defer resp.Body.Close()
 
-			p := profileResp[0]
-			profile = &CompanyProfile{
-				Symbol:            p.Symbol,
-				CompanyName:       p.CompanyName,
-				Price:             p.Price,
-				MarketCap:         p.MktCap,
-				Sector:            p.Sector,
-				Industry:          p.Industry,
-				Description:       p.Description,
-				CEO:               p.CEO,
-				Website:           p.Website,
-				Exchange:          p.ExchangeShortName,
-				Country:           p.Country,
-				Beta:              p.Beta,
-				VolAvg:            p.VolAvg,
-				LastDividend:      p.LastDiv,
-				Range52Week:       p.Range,
-				Changes:           p.Changes,
-				DCF:               p.DCF,
-				IPODate:           p.IPODate,
-				IsActivelyTrading: p.IsActivelyTrading,
+			if resp.StatusCode != http.StatusOK {
+				return newStatusError("FMP earnings calendar API", resp, "")
+			}
+
+			var reports []fmpEarningsResponse
+			if err := json.NewDecoder(resp.Body).Decode(&reports); err != nil {
+				return fmt.Errorf("failed to decode earnings calendar response: %w", err)
+			}
+
+			// The calendar lists past and scheduled reports newest first; keep the
+			// earliest one that hasn't happened yet
+			today := time.Now().UTC().Truncate(24 * time.Hour)
+			next = nil
+			for _, report := range reports {
+				date, err := time.Parse("2006-01-02", report.Date)
+				if err != nil || date.Before(today) {
+					continue
+				}
+				if next == nil || date.Before(*next) {
+					next = &date
+				}
 			}
 
 			return nil
@@ -317,9 +548,184 @@ func (s *FMPService) GetCompanyProfile(ctx context.Context, symbol string) (*Com
 			return nil, err
 		}
 
-		return profile, nil
+		return next, nil
+	})
+}
+
+// GetInsiderTrades returns up to limit of the most recently filed insider transactions in a
+// symbol. Insider trading is served by FMP's v4 API.
+func (s *FMPService) GetInsiderTrades(ctx context.Context, symbol string, limit int) ([]models.InsiderTrade, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+
+	return WithCircuitBreaker(ctx, BreakerFMP, func() ([]models.InsiderTrade, error) {
+		var trades []models.InsiderTrade
+
+		err := currentRetryPolicy().Do(ctx, func() error {
+			params := url.Values{}
+			params.Set("symbol", symbol)
+			params.Set("page", "0")
+			params.Set("apikey", s.apiKey)
+			reqURL := strings.TrimSuffix(s.baseURL, "/v3") + "/v4/insider-trading?" + params.Encode()
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+			if err != nil {
+				return fmt.Errorf("failed to create insider trading request: %w", err)
+			}
+
+			resp, err := s.httpClient.Do(req)
+			if err != nil {
+				return fmt.Errorf("failed to fetch insider trades: %w", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return newStatusError("FMP insider trading API", resp, "")
+			}
+
+			var items []fmpInsiderTradeResponse
+			if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+				return fmt.Errorf("failed to decode insider trading response: %w", err)
+			}
+
+			trades = make([]models.InsiderTrade, 0, min(len(items), limit))
+			for _, item := range items[:min(len(items), limit)] {
+				tradedAt, err := time.Parse("2006-01-02", item.TransactionDate)
+				if err != nil {
+					logger.Warn("skipping insider trade with unparseable date", "symbol", symbol, "value", item.TransactionDate)
+					continue
+				}
+				filedAt, _ := time.Parse("2006-01-02 15:04:05", item.FilingDate)
+				trades = append(trades, models.InsiderTrade{
+					Symbol:          item.Symbol,
+					Insider:         item.ReportingName,
+					Role:            item.TypeOfOwner,
+					TransactionType: item.TransactionType,
+					Shares:          item.SecuritiesTransacted,
+					Price:           item.Price,
+					TradedAt:        tradedAt,
+					FiledAt:         filedAt,
+				})
+			}
+			return nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		return trades, nil
+	})
+}
+
+// GetGrowthMetrics returns a symbol's latest annual revenue and EPS growth, from FMP's
+// financial growth statements, and its six-month share price change
+func (s *FMPService) GetGrowthMetrics(ctx context.Context, symbol string) (*GrowthMetrics, error) {
+	return WithCircuitBreaker(ctx, BreakerFMP, func() (*GrowthMetrics, error) {
+		growth, err := Retry(ctx, func() (*fmpFinancialGrowthResponse, error) {
+			reqURL := fmt.Sprintf("%s/financial-growth/%s?period=annual&limit=1&apikey=%s", s.baseURL, url.PathEscape(symbol), s.apiKey)
+
+			var items []fmpFinancialGrowthResponse
+			if err := s.getJSON(ctx, reqURL, "FMP financial growth API", &items); err != nil {
+				return nil, err
+			}
+			if len(items) == 0 {
+				return nil, permanent(fmt.Errorf("no growth data for symbol %s", symbol))
+			}
+			return &items[0], nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		change, err := Retry(ctx, func() (*fmpPriceChangeResponse, error) {
+			reqURL := fmt.Sprintf("%s/stock-price-change/%s?apikey=%s", s.baseURL, url.PathEscape(symbol), s.apiKey)
+
+			var items []fmpPriceChangeResponse
+			if err := s.getJSON(ctx, reqURL, "FMP price change API", &items); err != nil {
+				return nil, err
+			}
+			if len(items) == 0 {
+				return nil, permanent(fmt.Errorf("no price change data for symbol %s", symbol))
+			}
+			return &items[0], nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &GrowthMetrics{
+			Symbol:        symbol,
+			RevenueGrowth: growth.RevenueGrowth,
+			EPSGrowth:     growth.EPSGrowth,
+			PriceChange6M: change.SixMonths,
+		}, nil
 	})
 }
 
+// GetDividendHistory returns a symbol's cash dividends, newest ex-date first, including
+// declared ones that have not gone ex yet. Amounts are split-adjusted. A symbol that has
+// never paid a dividend has an empty history.
+func (s *FMPService) GetDividendHistory(ctx context.Context, symbol string) ([]models.Dividend, error) {
+	return WithCircuitBreaker(ctx, BreakerFMP, func() ([]models.Dividend, error) {
+		return Retry(ctx, func() ([]models.Dividend, error) {
+			reqURL := fmt.Sprintf("%s/historical-price-full/stock_dividend/%s?apikey=%s", s.baseURL, url.PathEscape(symbol), s.apiKey)
+
+			var history fmpDividendHistoryResponse
+			if err := s.getJSON(ctx, reqURL, "FMP dividend history API", &history); err != nil {
+				return nil, err
+			}
+
+			dividends := make([]models.Dividend, 0, len(history.Historical))
+			for _, item := range history.Historical {
+				exDate, err := time.Parse("2006-01-02", item.Date)
+				if err != nil {
+					logger.Warn("skipping dividend with unparseable ex-date", "symbol", symbol, "value", item.Date)
+					continue
+				}
+				amount := item.AdjDividend
+				if amount == 0 {
+					amount = item.Dividend
+				}
+				d := models.Dividend{
+					Symbol:         symbol,
+					ExDate:         exDate,
+					AmountPerShare: decimal.NewFromFloat(amount),
+				}
+				if paid, err := time.Parse("2006-01-02", item.PaymentDate); err == nil {
+					d.PaymentDate = &paid
+				}
+				dividends = append(dividends, d)
+			}
+			return dividends, nil
+		})
+	})
+}
+
+// getJSON fetches reqURL and decodes its JSON body into v, describing a non-200 response
+// as coming from provider
+func (s *FMPService) getJSON(ctx context.Context, reqURL, provider string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", provider, err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch from %s: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newStatusError(provider, resp, "")
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", provider, err)
+	}
+	return nil
+}
+
 // Compile-time interface verification
 var _ FMPServiceInterface = (*FMPService)(nil)
@@ -3,9 +3,14 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"trade-machine/models"
 )
 
 func TestNewFMPService(t *testing.T) {
@@ -104,7 +109,7 @@ func TestFMPScreenerResponse_Deserialization(t *testing.T) {
 		t.Errorf("Stock[0].MarketCap = %v, want 2500000000000", stock.MarketCap)
 	}
 	if stock.Sector != "Technology" {
-		t.Errorf("Stock[0].Sector = %v, want 'Technology'", stock.Sector)
+		t.Errorf("Stock[0].Sector = %v, want the provider's 'Technology'", stock.Sector)
 	}
 	if stock.IsEtf {
 		t.Error("Stock[0].IsEtf should be false")
@@ -176,7 +181,15 @@ func TestFMPProfileResponse_Deserialization(t *testing.T) {
 		t.Errorf("Profile.CEO = %v, want 'Tim Cook'", profile.CEO)
 	}
 	if profile.Sector != "Technology" {
-		t.Errorf("Profile.Sector = %v, want 'Technology'", profile.Sector)
+		t.Errorf("Profile.Sector = %v, want the provider's 'Technology'", profile.Sector)
+	}
+
+	decoded, err := decodeCompanyProfile(strings.NewReader(jsonResponse), "AAPL")
+	if err != nil {
+		t.Fatalf("decodeCompanyProfile() error = %v", err)
+	}
+	if decoded.Sector != models.SectorInformationTechnology {
+		t.Errorf("decoded Sector = %v, want %q", decoded.Sector, models.SectorInformationTechnology)
 	}
 	if profile.IPODate != "1980-12-12" {
 		t.Errorf("Profile.IPODate = %v, want '1980-12-12'", profile.IPODate)
@@ -318,6 +331,73 @@ func TestScreen_WithSectorFilter(t *testing.T) {
 	}
 }
 
+func TestScreen_WithListingFilters(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("exchange") != "NYSE,NASDAQ" {
+			t.Errorf("exchange = %s, want NYSE,NASDAQ", query.Get("exchange"))
+		}
+		if query.Get("country") != "US" {
+			t.Errorf("country = %s, want US", query.Get("country"))
+		}
+		if query.Get("priceMoreThan") != "5" {
+			t.Errorf("priceMoreThan = %s, want 5", query.Get("priceMoreThan"))
+		}
+		if query.Get("volumeMoreThan") != "100000" {
+			t.Errorf("volumeMoreThan = %s, want 100000", query.Get("volumeMoreThan"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[
+			{"symbol": "AAPL", "exchangeShortName": "NASDAQ", "country": "US", "isActivelyTrading": true},
+			{"symbol": "OTCX", "exchangeShortName": "OTC", "country": "US", "isActivelyTrading": true},
+			{"symbol": "SHOP", "exchangeShortName": "NYSE", "country": "CA", "isActivelyTrading": true}
+		]`))
+	}))
+	defer server.Close()
+
+	service := NewFMPService("test-key")
+	service.baseURL = server.URL
+
+	results, err := service.Screen(context.Background(), ScreenCriteria{
+		Exchanges:    []string{"NYSE", "NASDAQ"},
+		Country:      "US",
+		PriceMin:     5,
+		AvgVolumeMin: 100_000,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Symbol != "AAPL" {
+		t.Errorf("results = %+v, want only AAPL", results)
+	}
+}
+
+func TestScreen_GICSSectorFilterUsesFMPName(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("sector"); got != "Consumer Cyclical" {
+			t.Errorf("sector = %s, want Consumer Cyclical", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	service := NewFMPService("test-key")
+	service.baseURL = server.URL
+
+	_, err := service.Screen(context.Background(), ScreenCriteria{Sector: models.SectorConsumerDiscretionary})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestScreen_FiltersEtfsAndInactiveStocks(t *testing.T) {
 	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
 
@@ -576,7 +656,7 @@ func TestGetCompanyProfile_WithMockServer(t *testing.T) {
 	if profile.CEO != "Tim Cook" {
 		t.Errorf("unexpected CEO: %s", profile.CEO)
 	}
-	if profile.Sector != "Technology" {
+	if profile.Sector != models.SectorInformationTechnology {
 		t.Errorf("unexpected sector: %s", profile.Sector)
 	}
 	if profile.MarketCap != 2500000000000 {
@@ -664,6 +744,113 @@ func TestGetCompanyProfile_ContextCancellation(t *testing.T) {
 	}
 }
 
+func TestGetNextEarningsDate_WithMockServer(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	day := func(offset int) string { return today.AddDate(0, 0, offset).Format("2006-01-02") }
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/historical/earning_calendar/AAPL" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `[
+			{"date": %q, "symbol": "AAPL"},
+			{"date": %q, "symbol": "AAPL"},
+			{"date": %q, "symbol": "AAPL"}
+		]`, day(120), day(30), day(-60))
+	}))
+	defer server.Close()
+
+	service := NewFMPService("test-key")
+	service.baseURL = server.URL
+
+	next, err := service.GetNextEarningsDate(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next == nil || next.Format("2006-01-02") != day(30) {
+		t.Errorf("expected next earnings on %s, got %v", day(30), next)
+	}
+}
+
+func TestGetNextEarningsDate_NoneScheduled(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"date": "2001-01-16", "symbol": "AAPL"}]`))
+	}))
+	defer server.Close()
+
+	service := NewFMPService("test-key")
+	service.baseURL = server.URL
+
+	next, err := service.GetNextEarningsDate(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next != nil {
+		t.Errorf("expected no upcoming earnings, got %v", next)
+	}
+}
+
+func TestGetNextEarningsDate_NonOKStatus(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	service := NewFMPService("test-key")
+	service.baseURL = server.URL
+
+	if _, err := service.GetNextEarningsDate(context.Background(), "AAPL"); err == nil {
+		t.Error("expected error for non-OK status")
+	}
+}
+
+func TestGetInsiderTrades_WithMockServer(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v4/insider-trading" || r.URL.Query().Get("symbol") != "AAPL" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[
+			{"symbol": "AAPL", "filingDate": "2024-01-17 18:30:00", "transactionDate": "2024-01-15", "reportingName": "Jane Doe", "typeOfOwner": "director", "transactionType": "P-Purchase", "securitiesTransacted": 1000, "price": 185.5},
+			{"symbol": "AAPL", "filingDate": "2024-01-10 18:30:00", "transactionDate": "not-a-date", "reportingName": "John Roe", "transactionType": "S-Sale", "securitiesTransacted": 500, "price": 190}
+		]`))
+	}))
+	defer server.Close()
+
+	service := NewFMPService("test-key")
+	service.baseURL = server.URL + "/v3"
+
+	trades, err := service.GetInsiderTrades(context.Background(), "AAPL", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trades) != 1 {
+		t.Fatalf("expected the undated trade to be skipped, got %d trades", len(trades))
+	}
+	got := trades[0]
+	if got.Insider != "Jane Doe" || !got.OpenMarketPurchase() || got.Value() != 185500 {
+		t.Errorf("unexpected trade %+v", got)
+	}
+	if got.TradedAt.Format("2006-01-02") != "2024-01-15" || got.FiledAt.Format("2006-01-02") != "2024-01-17" {
+		t.Errorf("unexpected dates %v / %v", got.TradedAt, got.FiledAt)
+	}
+}
+
 func TestFMPServiceInterface_Implementation(t *testing.T) {
 	// Verify FMPService implements FMPServiceInterface
 	var _ FMPServiceInterface = (*FMPService)(nil)
@@ -771,6 +958,51 @@ func TestGetRatios_NonOKStatus(t *testing.T) {
 	}
 }
 
+func TestGetRatios_CachedUntilFreshDataRequested(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `[{"symbol": "AAPL", "peRatioTTM": %d, "priceToBookRatioTTM": 40.1}]`, 20+requests)
+	}))
+	defer server.Close()
+
+	service := NewFMPService("test-key")
+	service.baseURL = server.URL
+
+	ctx := context.Background()
+	first, err := service.GetRatios(ctx, "AAPL")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.PERatio != 21 || first.PBRatio != 40.1 {
+		t.Errorf("unexpected ratios: %+v", first)
+	}
+
+	cached, err := service.GetRatios(ctx, "AAPL")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 1 || cached.PERatio != 21 {
+		t.Errorf("expected the cached ratios, got %+v after %d requests", cached, requests)
+	}
+
+	fresh, err := service.GetRatios(WithFreshData(ctx), "AAPL")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 2 || fresh.PERatio != 22 {
+		t.Errorf("expected refetched ratios, got %+v after %d requests", fresh, requests)
+	}
+
+	if again, _ := service.GetRatios(ctx, "AAPL"); again.PERatio != 22 {
+		t.Errorf("expected the refreshed ratios to be cached, got %+v", again)
+	}
+}
+
 func TestScreen_WithDividendYieldFilter(t *testing.T) {
 	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
 
@@ -926,3 +1158,123 @@ func TestScreen_WithEPSFilter(t *testing.T) {
 		t.Errorf("expected PROFIT, got %s", results[0].Symbol)
 	}
 }
+
+// FuzzDecodeScreenerResults checks that malformed screener responses are rejected with an
+// error rather than a panic, and that listings outside the requested exchanges and country
+// never get through
+func FuzzDecodeScreenerResults(f *testing.F) {
+	f.Add(`[{"symbol":"AAPL","companyName":"Apple Inc.","marketCap":2500000000000,"price":175.5,"exchangeShortName":"NASDAQ","country":"US","isEtf":false,"isActivelyTrading":true}]`)
+	f.Add(`[{"symbol":"SPY","exchangeShortName":"AMEX","country":"US","isEtf":true,"isActivelyTrading":true}]`)
+	f.Add(`[{"symbol":"OTCX","exchangeShortName":"OTC","country":"US","isActivelyTrading":true}]`)
+	f.Add(`{"Error Message":"Invalid API KEY."}`)
+	f.Add(`[{"marketCap":1e30}]`)
+	f.Add(`[`)
+
+	criteria := ScreenCriteria{Exchanges: []string{"NASDAQ", "NYSE"}, Country: "US"}
+	f.Fuzz(func(t *testing.T, body string) {
+		results, err := decodeScreenerResults(strings.NewReader(body), criteria)
+		if err != nil {
+			return
+		}
+		for _, result := range results {
+			if !strings.EqualFold(result.Country, "US") || (!strings.EqualFold(result.Exchange, "NASDAQ") && !strings.EqualFold(result.Exchange, "NYSE")) {
+				t.Fatalf("%s on %s in %q passed the listing filter", result.Symbol, result.Exchange, result.Country)
+			}
+		}
+	})
+}
+
+// FuzzDecodeCompanyProfile checks that malformed profile responses are rejected with an
+// error rather than a panic
+func FuzzDecodeCompanyProfile(f *testing.F) {
+	f.Add(`[{"symbol":"AAPL","companyName":"Apple Inc.","price":175.5,"mktCap":2500000000000,"sector":"Technology","industry":"Consumer Electronics"}]`)
+	f.Add(`[]`)
+	f.Add(`[{"sector":null,"beta":"high"}]`)
+	f.Add(`{"symbol":"AAPL"}`)
+
+	f.Fuzz(func(t *testing.T, body string) {
+		profile, err := decodeCompanyProfile(strings.NewReader(body), "AAPL")
+		if err == nil && profile == nil {
+			t.Fatalf("decoding %q returned neither a profile nor an error", body)
+		}
+	})
+}
+
+func TestGetGrowthMetrics(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/financial-growth/NVDA":
+			if r.URL.Query().Get("period") != "annual" {
+				t.Errorf("period = %q, want annual", r.URL.Query().Get("period"))
+			}
+			w.Write([]byte(`[{"symbol":"NVDA","date":"2024-01-28","revenueGrowth":1.26,"epsgrowth":5.86}]`))
+		case "/stock-price-change/NVDA":
+			w.Write([]byte(`[{"symbol":"NVDA","1M":4.2,"3M":18.5,"6M":62.3,"1Y":210.4}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	service := NewFMPService("test-key")
+	service.baseURL = server.URL
+
+	metrics, err := service.GetGrowthMetrics(context.Background(), "NVDA")
+	if err != nil {
+		t.Fatalf("GetGrowthMetrics error = %v", err)
+	}
+	if metrics.RevenueGrowth != 1.26 || metrics.EPSGrowth != 5.86 || metrics.PriceChange6M != 62.3 {
+		t.Errorf("metrics = %+v, want revenue 1.26, EPS 5.86 and 6M change 62.3", metrics)
+	}
+
+	if _, err := service.GetGrowthMetrics(context.Background(), "UNKNOWN"); err == nil {
+		t.Error("expected error for a symbol without growth data")
+	}
+}
+
+func TestGetDividendHistory(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/historical-price-full/stock_dividend/KO":
+			w.Write([]byte(`{"symbol":"KO","historical":[
+				{"date":"2024-06-14","label":"June 14, 24","adjDividend":0.485,"dividend":0.485,"recordDate":"2024-06-14","paymentDate":"2024-07-01","declarationDate":"2024-04-25"},
+				{"date":"2024-03-14","label":"March 14, 24","adjDividend":0.485,"dividend":0.485,"recordDate":"","paymentDate":"","declarationDate":""},
+				{"date":"bad","adjDividend":0.46,"dividend":0.46}
+			]}`))
+		case "/historical-price-full/stock_dividend/NVDA":
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	service := NewFMPService("test-key")
+	service.baseURL = server.URL
+
+	dividends, err := service.GetDividendHistory(context.Background(), "KO")
+	if err != nil {
+		t.Fatalf("GetDividendHistory error = %v", err)
+	}
+	if len(dividends) != 2 {
+		t.Fatalf("got %d dividends, want 2 with the unparseable one skipped", len(dividends))
+	}
+	if got := dividends[0]; got.ExDate.Format("2006-01-02") != "2024-06-14" || got.AmountPerShare.String() != "0.485" ||
+		got.PaymentDate == nil || got.PaymentDate.Format("2006-01-02") != "2024-07-01" {
+		t.Errorf("first dividend = %+v, want the June dividend paid on July 1", got)
+	}
+	if dividends[1].PaymentDate != nil {
+		t.Errorf("second dividend payment date = %v, want none", dividends[1].PaymentDate)
+	}
+
+	none, err := service.GetDividendHistory(context.Background(), "NVDA")
+	if err != nil || len(none) != 0 {
+		t.Errorf("GetDividendHistory(NVDA) = %v, %v, want an empty history", none, err)
+	}
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"trade-machine/models"
+)
+
+// macroSnapshotTTL is how long the macro snapshot is cached. The monthly series behind it
+// change a few times a month, and every analysis reads the same snapshot.
+const macroSnapshotTTL = 6 * time.Hour
+
+// FRED series read for the macro snapshot
+const (
+	fredSeriesFedFunds     = "FEDFUNDS" // Effective federal funds rate, monthly
+	fredSeriesYieldCurve   = "T10Y2Y"   // 10-year minus 2-year Treasury yield, daily
+	fredSeriesCPI          = "CPIAUCSL" // Consumer price index, monthly
+	fredSeriesUnemployment = "UNRATE"   // Unemployment rate, monthly
+)
+
+// FREDService handles communication with the St. Louis Fed's FRED API
+type FREDService struct {
+	apiKey     string
+	httpClient *http.Client
+	baseURL    string
+	snapshotMu sync.Mutex
+	snapshot   *models.MacroSnapshot
+	fetchedAt  time.Time
+}
+
+// NewFREDService creates a new FREDService instance
+func NewFREDService(apiKey string) *FREDService {
+	return &FREDService{
+		apiKey:     apiKey,
+		httpClient: newLedgerHTTPClient(BreakerFRED, 30*time.Second),
+		baseURL:    "https://api.stlouisfed.org/fred",
+	}
+}
+
+// fredObservationsResponse is the response from the series/observations endpoint. Values
+// are strings, with "." marking a missing observation.
+type fredObservationsResponse struct {
+	Observations []struct {
+		Date  string `json:"date"`
+		Value string `json:"value"`
+	} `json:"observations"`
+}
+
+// fredErrorResponse is the body FRED returns with a non-200 status
+type fredErrorResponse struct {
+	ErrorCode    int    `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// GetObservations returns a series' observations dated at or after start, oldest first.
+// units transforms the values as FRED does ("lin" for levels, "pc1" for percent change
+// from a year ago); empty means levels. Missing observations are skipped.
+func (s *FREDService) GetObservations(ctx context.Context, seriesID, units string, start time.Time) ([]models.EconomicObservation, error) {
+	if units == "" {
+		units = "lin"
+	}
+
+	return WithCircuitBreaker(ctx, BreakerFRED, func() ([]models.EconomicObservation, error) {
+		var observations []models.EconomicObservation
+		err := currentRetryPolicy().Do(ctx, func() error {
+			params := url.Values{}
+			params.Set("series_id", seriesID)
+			params.Set("api_key", s.apiKey)
+			params.Set("file_type", "json")
+			params.Set("units", units)
+			params.Set("observation_start", start.Format("2006-01-02"))
+
+			req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+"/series/observations?"+params.Encode(), nil)
+			if err != nil {
+				return fmt.Errorf("failed to create request: %w", err)
+			}
+
+			resp, err := s.httpClient.Do(req)
+			if err != nil {
+				return fmt.Errorf("failed to fetch %s observations: %w", seriesID, err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				var apiErr fredErrorResponse
+				if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.ErrorMessage != "" {
+					return newStatusError("FRED", resp, apiErr.ErrorMessage)
+				}
+				return newStatusError("FRED", resp, "")
+			}
+
+			var result fredObservationsResponse
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+
+			observations = make([]models.EconomicObservation, 0, len(result.Observations))
+			for _, obs := range result.Observations {
+				value, err := strconv.ParseFloat(obs.Value, 64)
+				if err != nil {
+					continue
+				}
+				date, err := time.Parse("2006-01-02", obs.Date)
+				if err != nil {
+					logger.Warn("failed to parse FRED observation date", "series", seriesID, "value", obs.Date, "error", err)
+					continue
+				}
+				observations = append(observations, models.EconomicObservation{Date: date, Value: value})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return observations, nil
+	})
+}
+
+// GetMacroSnapshot returns the latest rates, inflation and unemployment readings, cached
+// for six hours unless ctx asks for fresh data
+func (s *FREDService) GetMacroSnapshot(ctx context.Context) (*models.MacroSnapshot, error) {
+	if !wantsFreshData(ctx) {
+		s.snapshotMu.Lock()
+		snapshot, fetchedAt := s.snapshot, s.fetchedAt
+		s.snapshotMu.Unlock()
+		if snapshot != nil && time.Since(fetchedAt) < macroSnapshotTTL {
+			return snapshot, nil
+		}
+	}
+
+	snapshot, err := s.fetchMacroSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.snapshotMu.Lock()
+	s.snapshot, s.fetchedAt = snapshot, time.Now()
+	s.snapshotMu.Unlock()
+	return snapshot, nil
+}
+
+// fetchMacroSnapshot reads each series far enough back to compare its latest value with
+// the year before
+func (s *FREDService) fetchMacroSnapshot(ctx context.Context) (*models.MacroSnapshot, error) {
+	now := time.Now()
+	yearAgo := now.AddDate(-1, -2, 0)
+
+	fedFunds, err := s.GetObservations(ctx, fredSeriesFedFunds, "", yearAgo)
+	if err != nil {
+		return nil, err
+	}
+	yieldCurve, err := s.GetObservations(ctx, fredSeriesYieldCurve, "", now.AddDate(0, -1, 0))
+	if err != nil {
+		return nil, err
+	}
+	inflation, err := s.GetObservations(ctx, fredSeriesCPI, "pc1", now.AddDate(0, -6, 0))
+	if err != nil {
+		return nil, err
+	}
+	unemployment, err := s.GetObservations(ctx, fredSeriesUnemployment, "", yearAgo)
+	if err != nil {
+		return nil, err
+	}
+
+	for series, observations := range map[string][]models.EconomicObservation{
+		fredSeriesFedFunds:     fedFunds,
+		fredSeriesYieldCurve:   yieldCurve,
+		fredSeriesCPI:          inflation,
+		fredSeriesUnemployment: unemployment,
+	} {
+		if len(observations) == 0 {
+			return nil, fmt.Errorf("no recent %s observations", series)
+		}
+	}
+
+	latestFedFunds := fedFunds[len(fedFunds)-1]
+	latestUnemployment := unemployment[len(unemployment)-1]
+	snapshot := &models.MacroSnapshot{
+		FedFundsRate:     latestFedFunds.Value,
+		FedFundsChange:   latestFedFunds.Value - valueYearBefore(fedFunds),
+		YieldCurve:       yieldCurve[len(yieldCurve)-1].Value,
+		Inflation:        inflation[len(inflation)-1].Value,
+		UnemploymentRate: latestUnemployment.Value,
+		AsOf:             latestFedFunds.Date,
+	}
+
+	// The Sahm rule compares unemployment with its low over the past year
+	low := latestUnemployment.Value
+	for _, obs := range unemployment {
+		if !obs.Date.Before(latestUnemployment.Date.AddDate(-1, 0, 0)) {
+			low = min(low, obs.Value)
+		}
+	}
+	snapshot.UnemploymentRise = latestUnemployment.Value - low
+
+	for _, latest := range []time.Time{latestUnemployment.Date, inflation[len(inflation)-1].Date} {
+		if latest.Before(snapshot.AsOf) {
+			snapshot.AsOf = latest
+		}
+	}
+	return snapshot, nil
+}
+
+// valueYearBefore returns the last observation dated a year or more before the newest one,
+// or the oldest observation when the series is shorter than a year
+func valueYearBefore(observations []models.EconomicObservation) float64 {
+	cutoff := observations[len(observations)-1].Date.AddDate(-1, 0, 0)
+	value := observations[0].Value
+	for _, obs := range observations {
+		if obs.Date.After(cutoff) {
+			break
+		}
+		value = obs.Value
+	}
+	return value
+}
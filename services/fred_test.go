@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var fredTestSeries = map[string]string{
+	"FEDFUNDS": `{"observations": [
+		{"date": "2023-01-01", "value": "4.33"},
+		{"date": "2023-07-01", "value": "5.12"},
+		{"date": "2024-01-01", "value": "5.33"},
+		{"date": "2024-06-01", "value": "5.33"}
+	]}`,
+	"T10Y2Y": `{"observations": [
+		{"date": "2024-06-27", "value": "-0.40"},
+		{"date": "2024-06-28", "value": "."}
+	]}`,
+	"CPIAUCSL": `{"observations": [
+		{"date": "2024-04-01", "value": "3.4"},
+		{"date": "2024-05-01", "value": "3.3"}
+	]}`,
+	"UNRATE": `{"observations": [
+		{"date": "2023-06-01", "value": "3.6"},
+		{"date": "2023-09-01", "value": "3.8"},
+		{"date": "2024-01-01", "value": "3.7"},
+		{"date": "2024-06-01", "value": "4.1"}
+	]}`,
+}
+
+func newTestFREDServer(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/series/observations" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("api_key") != "test-key" || query.Get("file_type") != "json" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		seriesID := query.Get("series_id")
+		wantUnits := "lin"
+		if seriesID == "CPIAUCSL" {
+			wantUnits = "pc1"
+		}
+		if query.Get("units") != wantUnits {
+			t.Errorf("%s units = %q, want %q", seriesID, query.Get("units"), wantUnits)
+		}
+		w.Write([]byte(fredTestSeries[seriesID]))
+	}))
+}
+
+func TestFREDService_GetMacroSnapshot(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	var requests atomic.Int32
+	server := newTestFREDServer(t, &requests)
+	defer server.Close()
+
+	service := NewFREDService("test-key")
+	service.baseURL = server.URL
+
+	snapshot, err := service.GetMacroSnapshot(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snapshot.FedFundsRate != 5.33 {
+		t.Errorf("FedFundsRate = %v, want 5.33", snapshot.FedFundsRate)
+	}
+	// Compared with January 2023, the last reading at least a year before June 2024
+	if diff := snapshot.FedFundsChange - 1.0; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("FedFundsChange = %v, want 1.0", snapshot.FedFundsChange)
+	}
+	if snapshot.YieldCurve != -0.40 {
+		t.Errorf("YieldCurve = %v, want -0.40 with the missing day skipped", snapshot.YieldCurve)
+	}
+	if snapshot.Inflation != 3.3 {
+		t.Errorf("Inflation = %v, want 3.3", snapshot.Inflation)
+	}
+	if diff := snapshot.UnemploymentRise - 0.5; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("UnemploymentRise = %v, want 0.5 above the June 2023 low", snapshot.UnemploymentRise)
+	}
+	if want := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC); !snapshot.AsOf.Equal(want) {
+		t.Errorf("AsOf = %v, want the CPI reading's %v", snapshot.AsOf, want)
+	}
+
+	// The snapshot is cached until fresh data is asked for
+	fetched := requests.Load()
+	if _, err := service.GetMacroSnapshot(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests.Load() != fetched {
+		t.Errorf("expected the cached snapshot, got %d more requests", requests.Load()-fetched)
+	}
+	if _, err := service.GetMacroSnapshot(WithFreshData(context.Background())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests.Load() != 2*fetched {
+		t.Errorf("expected fresh data to refetch every series, got %d requests", requests.Load())
+	}
+}
+
+func TestFREDService_GetObservations_Error(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error_code": 400, "error_message": "Bad Request. The value for variable api_key is not registered."}`))
+	}))
+	defer server.Close()
+
+	service := NewFREDService("bad-key")
+	service.baseURL = server.URL
+
+	_, err := service.GetObservations(context.Background(), "UNRATE", "", time.Now().AddDate(-1, 0, 0))
+	if err == nil || !strings.Contains(err.Error(), "api_key is not registered") {
+		t.Errorf("expected FRED's error message, got %v", err)
+	}
+}
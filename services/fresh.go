@@ -0,0 +1,18 @@
+package services
+
+import "context"
+
+type freshDataKey struct{}
+
+// WithFreshData returns a context that makes cached provider lookups skip their cache,
+// fetching from the provider and storing the result. Background refreshers use it to
+// renew entries before they expire.
+func WithFreshData(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshDataKey{}, true)
+}
+
+// wantsFreshData reports whether ctx asks to bypass cached provider data
+func wantsFreshData(ctx context.Context) bool {
+	fresh, _ := ctx.Value(freshDataKey{}).(bool)
+	return fresh
+}
@@ -0,0 +1,526 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// ibkrMaxConfirmations bounds how many of the gateway's order warnings are confirmed
+// before an order is given up on
+const ibkrMaxConfirmations = 5
+
+// ibkrConfirmableWarnings are the IDs of the order warnings that are confirmed without
+// asking: o354 reports that the account has no market data subscription for the symbol.
+// Any other warning, such as a price or size constraint, fails the order with its text.
+var ibkrConfirmableWarnings = map[string]bool{
+	"o354": true,
+}
+
+// ibkrPositionsPageSize is how many positions the gateway returns per page
+const ibkrPositionsPageSize = 100
+
+// ibkrOrderTimeLayout is the gateway's UTC yymmddhhmmss order timestamp
+const ibkrOrderTimeLayout = "060102150405"
+
+// IBKRService places and tracks orders through the Interactive Brokers Client Portal API.
+// The gateway runs locally and must be logged in from a browser; it keeps the session, so
+// no credentials are held here.
+type IBKRService struct {
+	httpClient *http.Client
+	baseURL    string // Gateway API root, e.g. https://localhost:5000/v1/api
+	accountID  string
+	accountMu  sync.Mutex
+	conidMu    sync.Mutex
+	conids     map[string]int64 // Contract IDs by symbol; they never change
+}
+
+// NewIBKRService creates a new IBKRService for the gateway at gatewayURL. An empty
+// accountID uses the first account the gateway reports. insecureSkipVerify accepts the
+// self-signed certificate the gateway serves by default.
+func NewIBKRService(gatewayURL, accountID string, insecureSkipVerify bool) *IBKRService {
+	transport := http.DefaultTransport
+	if insecureSkipVerify {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		transport = t
+	}
+	return &IBKRService{
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &ledgerTransport{provider: BreakerIBKR, base: transport},
+		},
+		baseURL:   strings.TrimRight(gatewayURL, "/"),
+		accountID: accountID,
+		conids:    make(map[string]int64),
+	}
+}
+
+// ibkrNumber decodes the gateway's numbers, which arrive as JSON numbers or as strings
+type ibkrNumber float64
+
+func (n *ibkrNumber) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*n = 0
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %s: %w", data, err)
+	}
+	*n = ibkrNumber(v)
+	return nil
+}
+
+func (n ibkrNumber) decimal() decimal.Decimal {
+	return decimal.NewFromFloat(float64(n))
+}
+
+// ibkrID decodes the gateway's identifiers, which arrive as JSON numbers or as strings
+type ibkrID string
+
+func (id *ibkrID) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		s = ""
+	}
+	*id = ibkrID(s)
+	return nil
+}
+
+// ibkrAmount is one entry of the portfolio account summary
+type ibkrAmount struct {
+	Amount ibkrNumber `json:"amount"`
+}
+
+// ibkrPosition is one entry of the portfolio positions list
+type ibkrPosition struct {
+	ContractDesc  string     `json:"contractDesc"`
+	Ticker        string     `json:"ticker"`
+	Position      ibkrNumber `json:"position"` // Negative for shorts
+	MktPrice      ibkrNumber `json:"mktPrice"`
+	AvgPrice      ibkrNumber `json:"avgPrice"`
+	UnrealizedPnl ibkrNumber `json:"unrealizedPnl"`
+}
+
+// ibkrOrder is one order of an order submission
+type ibkrOrder struct {
+	AcctID    string  `json:"acctId"`
+	Conid     int64   `json:"conid"`
+	COID      string  `json:"cOID,omitempty"`     // Client order ID, referenced by bracket legs
+	ParentID  string  `json:"parentId,omitempty"` // cOID of the entry a bracket leg protects
+	OrderType string  `json:"orderType"`
+	Side      string  `json:"side"`
+	TIF       string  `json:"tif"`
+	Quantity  float64 `json:"quantity"`
+	Price     float64 `json:"price,omitempty"`
+}
+
+// ibkrOrderReply is one element of the gateway's answer to an order submission: either the
+// placed order, or a warning to confirm through /iserver/reply before it is placed
+type ibkrOrderReply struct {
+	OrderID     ibkrID   `json:"order_id"`
+	OrderStatus string   `json:"order_status"`
+	ReplyID     string   `json:"id"`
+	Message     []string `json:"message"`
+	MessageIDs  []string `json:"messageIds"`
+	Error       string   `json:"error"`
+}
+
+// confirmable reports whether every warning in the reply is one that may be confirmed
+// without asking
+func (r ibkrOrderReply) confirmable() bool {
+	if len(r.MessageIDs) == 0 {
+		return false
+	}
+	for _, id := range r.MessageIDs {
+		if !ibkrConfirmableWarnings[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// ibkrOrderStatus is the gateway's view of a single order
+type ibkrOrderStatus struct {
+	OrderID      ibkrID     `json:"order_id"`
+	Symbol       string     `json:"symbol"`
+	Side         string     `json:"side"` // B or S
+	OrderType    string     `json:"order_type"`
+	OrderStatus  string     `json:"order_status"`
+	TotalSize    ibkrNumber `json:"total_size"`
+	CumFill      ibkrNumber `json:"cum_fill"`
+	AveragePrice ibkrNumber `json:"average_price"`
+	OrderTime    string     `json:"order_time"`
+}
+
+// do sends a request to the gateway and decodes a JSON response into result, which may be nil
+func (s *IBKRService) do(ctx context.Context, method, path string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach IBKR gateway: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return errors.New("IBKR gateway session is not authenticated; log in to the gateway")
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("IBKR returned status %d: %s", resp.StatusCode, apiErr.Error)
+		}
+		return fmt.Errorf("IBKR returned status %d", resp.StatusCode)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// account returns the configured account, or the first one the gateway reports
+func (s *IBKRService) account(ctx context.Context) (string, error) {
+	s.accountMu.Lock()
+	defer s.accountMu.Unlock()
+	if s.accountID != "" {
+		return s.accountID, nil
+	}
+
+	var accounts []struct {
+		AccountID string `json:"accountId"`
+	}
+	if err := s.do(ctx, http.MethodGet, "/portfolio/accounts", nil, &accounts); err != nil {
+		return "", fmt.Errorf("failed to list accounts: %w", err)
+	}
+	if len(accounts) == 0 || accounts[0].AccountID == "" {
+		return "", errors.New("IBKR gateway reports no accounts")
+	}
+	s.accountID = accounts[0].AccountID
+	return s.accountID, nil
+}
+
+// conid returns the contract ID of a US stock symbol, which IBKR orders are placed against
+func (s *IBKRService) conid(ctx context.Context, symbol string) (int64, error) {
+	s.conidMu.Lock()
+	conid, ok := s.conids[symbol]
+	s.conidMu.Unlock()
+	if ok {
+		return conid, nil
+	}
+
+	var results []struct {
+		Conid ibkrID `json:"conid"`
+	}
+	params := url.Values{"symbol": {symbol}, "secType": {"STK"}}
+	if err := s.do(ctx, http.MethodGet, "/iserver/secdef/search?"+params.Encode(), nil, &results); err != nil {
+		return 0, fmt.Errorf("failed to look up %s: %w", symbol, err)
+	}
+	if len(results) == 0 {
+		return 0, fmt.Errorf("IBKR has no contract for %s", symbol)
+	}
+	conid, err := strconv.ParseInt(string(results[0].Conid), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("IBKR returned invalid contract ID %q for %s", results[0].Conid, symbol)
+	}
+
+	s.conidMu.Lock()
+	s.conids[symbol] = conid
+	s.conidMu.Unlock()
+	return conid, nil
+}
+
+// GetAccount returns the account's net liquidation value, cash, buying power and margin
+func (s *IBKRService) GetAccount(ctx context.Context) (*models.Account, error) {
+	return WithCircuitBreaker(ctx, BreakerIBKR, func() (*models.Account, error) {
+		accountID, err := s.account(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var summary map[string]ibkrAmount
+		if err := s.do(ctx, http.MethodGet, "/portfolio/"+url.PathEscape(accountID)+"/summary", nil, &summary); err != nil {
+			return nil, fmt.Errorf("failed to get account: %w", err)
+		}
+
+		equity := summary["netliquidation"].Amount.decimal()
+		return &models.Account{
+			ID:                accountID,
+			Currency:          "USD",
+			BuyingPower:       summary["buyingpower"].Amount.decimal(),
+			Cash:              summary["totalcashvalue"].Amount.decimal(),
+			PortfolioValue:    equity,
+			Equity:            equity,
+			InitialMargin:     summary["initmarginreq"].Amount.decimal(),
+			MaintenanceMargin: summary["maintmarginreq"].Amount.decimal(),
+			ShortingEnabled:   true,
+		}, nil
+	})
+}
+
+// GetPositions returns all current positions, following the gateway's pages
+func (s *IBKRService) GetPositions(ctx context.Context) ([]models.Position, error) {
+	return WithCircuitBreaker(ctx, BreakerIBKR, func() ([]models.Position, error) {
+		accountID, err := s.account(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var positions []models.Position
+		for page := 0; ; page++ {
+			var batch []ibkrPosition
+			path := fmt.Sprintf("/portfolio/%s/positions/%d", url.PathEscape(accountID), page)
+			if err := s.do(ctx, http.MethodGet, path, nil, &batch); err != nil {
+				return nil, fmt.Errorf("failed to get positions: %w", err)
+			}
+			for _, p := range batch {
+				if p.Position == 0 {
+					continue
+				}
+				positions = append(positions, p.toPosition())
+			}
+			if len(batch) < ibkrPositionsPageSize {
+				return positions, nil
+			}
+		}
+	})
+}
+
+func (p ibkrPosition) toPosition() models.Position {
+	symbol := p.Ticker
+	if symbol == "" {
+		symbol = p.ContractDesc
+	}
+	side := models.PositionSideLong
+	if p.Position < 0 {
+		side = models.PositionSideShort
+	}
+	return models.Position{
+		Symbol:        symbol,
+		Quantity:      p.Position.decimal().Abs(),
+		AvgEntryPrice: p.AvgPrice.decimal(),
+		CurrentPrice:  p.MktPrice.decimal(),
+		UnrealizedPL:  p.UnrealizedPnl.decimal(),
+		Side:          side,
+	}
+}
+
+// GetPosition returns the position in a symbol. Like Alpaca, a symbol not held is an
+// error wrapping models.ErrPositionNotFound.
+func (s *IBKRService) GetPosition(ctx context.Context, symbol string) (*models.Position, error) {
+	positions, err := s.GetPositions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range positions {
+		if p.Symbol == symbol {
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to get position for %s: %w", symbol, models.ErrPositionNotFound)
+}
+
+// GetShortAvailability returns nil: the Client Portal API does not report borrow
+// availability, and IBKR rejects a short it cannot borrow for when the order is placed
+func (s *IBKRService) GetShortAvailability(ctx context.Context, symbol string) (*models.ShortAvailability, error) {
+	return nil, nil
+}
+
+// PlaceOrder submits a market or limit order, with its bracket legs when given, and returns
+// the entry order's ID. Warnings the gateway asks about are confirmed only when listed in
+// ibkrConfirmableWarnings; any other warning fails the order with its text.
+func (s *IBKRService) PlaceOrder(ctx context.Context, req models.OrderRequest) (string, error) {
+	if err := req.Validate(); err != nil {
+		return "", err
+	}
+	orderType := "MKT"
+	switch req.OrderType() {
+	case models.OrderTypeMarket:
+	case models.OrderTypeLimit:
+		orderType = "LMT"
+	default:
+		return "", fmt.Errorf("%w: %s orders are not supported for Interactive Brokers", models.ErrInvalidOrder, req.OrderType())
+	}
+
+	return WithCircuitBreaker(ctx, BreakerIBKR, func() (string, error) {
+		accountID, err := s.account(ctx)
+		if err != nil {
+			return "", err
+		}
+		conid, err := s.conid(ctx, req.Symbol)
+		if err != nil {
+			return "", err
+		}
+
+		side, exitSide := "BUY", "SELL"
+		if req.Side == models.TradeSideSell {
+			side, exitSide = "SELL", "BUY"
+		}
+		quantity, _ := req.Quantity.Float64()
+		entry := ibkrOrder{AcctID: accountID, Conid: conid, OrderType: orderType, Side: side, TIF: "DAY", Quantity: quantity}
+		if req.LimitPrice != nil {
+			entry.Price, _ = req.LimitPrice.Float64()
+		}
+		orders := []ibkrOrder{entry}
+		if req.Bracket != nil {
+			parent := fmt.Sprintf("tm-%d", time.Now().UnixNano())
+			orders[0].COID = parent
+			orders[0].TIF = "GTC"
+			stopLoss, _ := req.Bracket.StopLoss.Float64()
+			takeProfit, _ := req.Bracket.TakeProfit.Float64()
+			orders = append(orders,
+				ibkrOrder{AcctID: accountID, Conid: conid, ParentID: parent, OrderType: "STP", Side: exitSide, TIF: "GTC", Quantity: quantity, Price: stopLoss},
+				ibkrOrder{AcctID: accountID, Conid: conid, ParentID: parent, OrderType: "LMT", Side: exitSide, TIF: "GTC", Quantity: quantity, Price: takeProfit},
+			)
+		}
+
+		path := "/iserver/account/" + url.PathEscape(accountID) + "/orders"
+		replies, err := s.submit(ctx, path, map[string]any{"orders": orders})
+		if err != nil {
+			return "", fmt.Errorf("failed to place order: %w", err)
+		}
+		for i := 0; ; i++ {
+			if len(replies) == 0 {
+				return "", errors.New("failed to place order: IBKR returned no order")
+			}
+			reply := replies[0]
+			if reply.Error != "" {
+				return "", fmt.Errorf("failed to place order: %s", reply.Error)
+			}
+			if reply.OrderID != "" {
+				return string(reply.OrderID), nil
+			}
+			if reply.ReplyID == "" || i >= ibkrMaxConfirmations {
+				return "", fmt.Errorf("failed to place order: unconfirmed warning %q", strings.Join(reply.Message, " "))
+			}
+			if !reply.confirmable() {
+				return "", fmt.Errorf("failed to place order: IBKR warning %v requires confirmation: %q", reply.MessageIDs, strings.Join(reply.Message, " "))
+			}
+			logger.Info("confirming IBKR order warning", "symbol", req.Symbol, "message", strings.Join(reply.Message, " "))
+			replies, err = s.submit(ctx, "/iserver/reply/"+url.PathEscape(reply.ReplyID), map[string]bool{"confirmed": true})
+			if err != nil {
+				return "", fmt.Errorf("failed to confirm order: %w", err)
+			}
+		}
+	})
+}
+
+// submit posts an order or a confirmation. The gateway answers with a list of replies, or
+// with a single object when it refuses the order outright.
+func (s *IBKRService) submit(ctx context.Context, path string, body any) ([]ibkrOrderReply, error) {
+	var raw json.RawMessage
+	if err := s.do(ctx, http.MethodPost, path, body, &raw); err != nil {
+		return nil, err
+	}
+	var replies []ibkrOrderReply
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+		var reply ibkrOrderReply
+		if err := json.Unmarshal(raw, &reply); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		return append(replies, reply), nil
+	}
+	if err := json.Unmarshal(raw, &replies); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return replies, nil
+}
+
+// GetOrder returns the broker's current state of a previously placed order
+func (s *IBKRService) GetOrder(ctx context.Context, orderID string) (*models.BrokerOrder, error) {
+	return WithCircuitBreaker(ctx, BreakerIBKR, func() (*models.BrokerOrder, error) {
+		var o ibkrOrderStatus
+		if err := s.do(ctx, http.MethodGet, "/iserver/account/order/status/"+url.PathEscape(orderID), nil, &o); err != nil {
+			return nil, fmt.Errorf("failed to get order %s: %w", orderID, err)
+		}
+		return o.toBrokerOrder(orderID), nil
+	})
+}
+
+// toBrokerOrder maps the gateway's order onto the broker-neutral order, translating IBKR's
+// statuses into the ones BrokerOrder.TradeStatus understands
+func (o ibkrOrderStatus) toBrokerOrder(orderID string) *models.BrokerOrder {
+	filled := o.CumFill.decimal()
+	status := "new"
+	switch strings.ToLower(o.OrderStatus) {
+	case "filled":
+		status = models.BrokerOrderFilled
+	case "cancelled":
+		status = models.BrokerOrderCanceled
+	case "inactive":
+		status = models.BrokerOrderRejected
+	default:
+		if filled.IsPositive() {
+			status = models.BrokerOrderPartiallyFilled
+		}
+	}
+
+	side := models.TradeSideBuy
+	if strings.HasPrefix(strings.ToUpper(o.Side), "S") {
+		side = models.TradeSideSell
+	}
+
+	var submittedAt time.Time
+	if t, err := time.Parse(ibkrOrderTimeLayout, o.OrderTime); err == nil {
+		submittedAt = t
+	}
+	now := time.Now()
+	order := &models.BrokerOrder{
+		ID:             orderID,
+		Symbol:         o.Symbol,
+		Side:           side,
+		Type:           strings.ToLower(o.OrderType),
+		Status:         status,
+		Quantity:       o.TotalSize.decimal(),
+		FilledQuantity: filled,
+		SubmittedAt:    submittedAt,
+		UpdatedAt:      now, // The gateway does not report when the order last changed
+	}
+	if filled.IsPositive() && o.AveragePrice > 0 {
+		price := o.AveragePrice.decimal()
+		order.FilledAvgPrice = &price
+	}
+	switch status {
+	case models.BrokerOrderFilled:
+		order.FilledAt = &now
+	case models.BrokerOrderCanceled, models.BrokerOrderRejected:
+		order.CanceledAt = &now
+	}
+	return order
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestIBKRService_GetAccountAndPositions(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/portfolio/accounts":
+			w.Write([]byte(`[{"accountId": "U1234567"}]`))
+		case "/portfolio/U1234567/summary":
+			w.Write([]byte(`{"netliquidation": {"amount": 100000.5}, "totalcashvalue": {"amount": 25000}, "buyingpower": {"amount": 200000}}`))
+		case "/portfolio/U1234567/positions/0":
+			w.Write([]byte(`[
+				{"conid": 265598, "contractDesc": "AAPL", "ticker": "AAPL", "position": 10, "mktPrice": 190.5, "avgPrice": 180},
+				{"conid": 76792991, "contractDesc": "TSLA", "position": -5, "mktPrice": "200", "avgPrice": "210"},
+				{"conid": 4815747, "contractDesc": "NVDA", "position": 0, "mktPrice": 100}
+			]`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	service := NewIBKRService(server.URL, "", false)
+
+	account, err := service.GetAccount(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if account.ID != "U1234567" || !account.Equity.Equal(decimal.NewFromFloat(100000.5)) || !account.Cash.Equal(decimal.NewFromInt(25000)) {
+		t.Errorf("unexpected account %+v", account)
+	}
+
+	positions, err := service.GetPositions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(positions) != 2 {
+		t.Fatalf("got %d positions, want 2 with the flat NVDA position skipped", len(positions))
+	}
+	short := positions[1]
+	if short.Symbol != "TSLA" || short.Side != models.PositionSideShort || !short.Quantity.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("unexpected short position %+v", short)
+	}
+}
+
+func TestIBKRService_PlaceOrder(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	var submitted []ibkrOrder
+	var confirmed bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/iserver/secdef/search":
+			if r.URL.Query().Get("symbol") != "AAPL" {
+				t.Errorf("unexpected search %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`[{"conid": "265598"}]`))
+		case "/iserver/account/U1/orders":
+			var body struct {
+				Orders []ibkrOrder `json:"orders"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode order: %v", err)
+			}
+			submitted = body.Orders
+			w.Write([]byte(`[{"id": "reply-1", "messageIds": ["o354"], "message": ["You are submitting an order without market data."]}]`))
+		case "/iserver/reply/reply-1":
+			confirmed = true
+			w.Write([]byte(`[{"order_id": 1799796559, "order_status": "Submitted"}]`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	service := NewIBKRService(server.URL, "U1", false)
+	limit := decimal.NewFromInt(150)
+	orderID, err := service.PlaceOrder(context.Background(), models.OrderRequest{
+		Symbol:     "AAPL",
+		Quantity:   decimal.NewFromInt(10),
+		Side:       models.TradeSideBuy,
+		Type:       models.OrderTypeLimit,
+		LimitPrice: &limit,
+		Bracket:    &models.Bracket{StopLoss: decimal.NewFromInt(140), TakeProfit: decimal.NewFromInt(170)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if orderID != "1799796559" || !confirmed {
+		t.Errorf("orderID = %q, confirmed = %v; want the order placed after confirming its warning", orderID, confirmed)
+	}
+	if len(submitted) != 3 {
+		t.Fatalf("submitted %d orders, want the entry and two bracket legs", len(submitted))
+	}
+	entry, stop, target := submitted[0], submitted[1], submitted[2]
+	if entry.Conid != 265598 || entry.OrderType != "LMT" || entry.Side != "BUY" || entry.Price != 150 {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if stop.ParentID != entry.COID || stop.OrderType != "STP" || stop.Side != "SELL" || stop.Price != 140 {
+		t.Errorf("unexpected stop-loss leg %+v", stop)
+	}
+	if target.ParentID != entry.COID || target.OrderType != "LMT" || target.Price != 170 {
+		t.Errorf("unexpected take-profit leg %+v", target)
+	}
+
+	_, err = service.PlaceOrder(context.Background(), models.OrderRequest{
+		Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Side: models.TradeSideSell, Type: models.OrderTypeStop,
+	})
+	if !errors.Is(err, models.ErrInvalidOrder) {
+		t.Errorf("expected stop orders to be refused, got %v", err)
+	}
+}
+
+func TestIBKRService_PlaceOrder_UnconfirmedWarning(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	var confirmed bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/iserver/secdef/search":
+			w.Write([]byte(`[{"conid": "265598"}]`))
+		case "/iserver/account/U1/orders":
+			w.Write([]byte(`[{"id": "reply-1", "messageIds": ["o163"], "message": ["The following order exceeds the price percentage limit"]}]`))
+		case "/iserver/reply/reply-1":
+			confirmed = true
+			w.Write([]byte(`[{"order_id": 1799796559, "order_status": "Submitted"}]`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	service := NewIBKRService(server.URL, "U1", false)
+	_, err := service.PlaceOrder(context.Background(), models.OrderRequest{
+		Symbol: "AAPL", Quantity: decimal.NewFromInt(10), Side: models.TradeSideBuy, Type: models.OrderTypeMarket,
+	})
+	if err == nil || !strings.Contains(err.Error(), "price percentage limit") {
+		t.Errorf("error = %v, want the warning's text", err)
+	}
+	if confirmed {
+		t.Error("confirmed a warning that isn't on the allowlist")
+	}
+}
+
+func TestIBKRService_GetOrder(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/iserver/account/order/status/42" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"order_id": 42, "symbol": "AAPL", "side": "S", "order_type": "LIMIT", "order_status": "Cancelled",
+			"total_size": "10.0", "cum_fill": "4.0", "average_price": "151.25", "order_time": "240611143210"}`))
+	}))
+	defer server.Close()
+
+	service := NewIBKRService(server.URL, "U1", false)
+	order, err := service.GetOrder(context.Background(), "42")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if order.Side != models.TradeSideSell || order.Status != models.BrokerOrderCanceled {
+		t.Errorf("unexpected order %+v", order)
+	}
+	if order.FilledAvgPrice == nil || !order.FilledAvgPrice.Equal(decimal.NewFromFloat(151.25)) {
+		t.Errorf("FilledAvgPrice = %v, want 151.25", order.FilledAvgPrice)
+	}
+	// Canceled after a partial fill leaves an executed trade for the filled shares
+	if order.TradeStatus() != models.TradeStatusExecuted {
+		t.Errorf("TradeStatus() = %s, want executed", order.TradeStatus())
+	}
+	if order.SubmittedAt.Year() != 2024 || order.SubmittedAt.Hour() != 14 {
+		t.Errorf("SubmittedAt = %v, want 2024-06-11 14:32:10", order.SubmittedAt)
+	}
+}
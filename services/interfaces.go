@@ -5,11 +5,14 @@ import (
 	"time"
 
 	"trade-machine/models"
+	"trade-machine/observability"
 
 	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
-	"github.com/shopspring/decimal"
 )
 
+// logger is the services module's logger
+var logger = observability.Module(observability.ModuleServices)
+
 // ChatMessage represents a message in a conversation
 type ChatMessage struct {
 	Role    string `json:"role"`    // "user" or "assistant"
@@ -21,6 +24,15 @@ type LLMService interface {
 	InvokeWithPrompt(ctx context.Context, systemPrompt, userPrompt string) (string, error)
 	InvokeStructured(ctx context.Context, systemPrompt, userPrompt string, result interface{}) error
 	Chat(ctx context.Context, systemPrompt string, messages []ChatMessage) (string, error)
+	// ChatStream holds a conversation like Chat, passing the reply to onDelta piece by piece
+	// as it is generated, and returns the whole reply. An error from onDelta ends the stream.
+	ChatStream(ctx context.Context, systemPrompt string, messages []ChatMessage, onDelta func(delta string) error) (string, error)
+}
+
+// Embedder turns text into embedding vectors for similarity search
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	EmbeddingModel() string
 }
 
 // AlphaVantageServiceInterface defines the interface for fundamental data operations
@@ -33,27 +45,60 @@ type AlphaVantageServiceInterface interface {
 // NewsAPIServiceInterface defines the interface for news data operations
 type NewsAPIServiceInterface interface {
 	GetNews(ctx context.Context, query string, limit int) ([]models.NewsArticle, error)
+	GetNewsSince(ctx context.Context, query string, limit int, from time.Time) ([]models.NewsArticle, error)
 	GetHeadlines(ctx context.Context, query string, limit int) ([]models.NewsArticle, error)
 }
 
+// SocialSentimentServiceInterface defines the interface for social media sentiment data
+type SocialSentimentServiceInterface interface {
+	// GetPosts returns recent Reddit posts and StockTwits messages mentioning a symbol
+	GetPosts(ctx context.Context, symbol string, limit int) ([]models.SocialPost, error)
+}
+
+// FREDServiceInterface defines the interface for St. Louis Fed economic data
+type FREDServiceInterface interface {
+	// GetMacroSnapshot returns the latest rates, inflation and unemployment readings
+	GetMacroSnapshot(ctx context.Context) (*models.MacroSnapshot, error)
+}
+
+// OptionsDataService defines the interface for option chain data
+type OptionsDataService interface {
+	// GetOptionChain returns an underlying's contracts matching the filter with their latest quotes
+	GetOptionChain(ctx context.Context, underlying string, filter models.OptionChainFilter) ([]models.OptionContract, error)
+}
+
 // FMPServiceInterface defines the interface for Financial Modeling Prep operations
 type FMPServiceInterface interface {
 	// Screen searches for stocks matching the given criteria
 	Screen(ctx context.Context, criteria ScreenCriteria) ([]ScreenerResult, error)
 	// GetCompanyProfile returns enriched company profile data
 	GetCompanyProfile(ctx context.Context, symbol string) (*CompanyProfile, error)
+	// GetNextEarningsDate returns the next scheduled earnings date, or nil if none is known
+	GetNextEarningsDate(ctx context.Context, symbol string) (*time.Time, error)
+	// GetRatios returns cached TTM valuation ratios; WithFreshData refetches them
+	GetRatios(ctx context.Context, symbol string) (*Ratios, error)
+	// GetInsiderTrades returns the most recently filed insider transactions, newest first
+	GetInsiderTrades(ctx context.Context, symbol string, limit int) ([]models.InsiderTrade, error)
+	// GetGrowthMetrics returns the latest annual revenue and EPS growth and recent price performance
+	GetGrowthMetrics(ctx context.Context, symbol string) (*GrowthMetrics, error)
+	// GetDividendHistory returns the symbol's split-adjusted cash dividends, newest first
+	GetDividendHistory(ctx context.Context, symbol string) ([]models.Dividend, error)
 }
 
 // ScreenCriteria defines filtering criteria for stock screening
 type ScreenCriteria struct {
-	MarketCapMin     int64   // Minimum market cap (e.g., 1_000_000_000 for $1B)
-	MarketCapMax     int64   // Maximum market cap (0 = no limit)
-	PERatioMax       float64 // Maximum P/E ratio (e.g., 15)
-	PBRatioMax       float64 // Maximum P/B ratio (e.g., 1.5)
-	EPSMin           float64 // Minimum EPS (e.g., 0 for positive earnings)
-	DividendYieldMin float64 // Minimum dividend yield (optional)
-	Sector           string  // Sector filter (optional)
-	Limit            int     // Maximum results to return
+	MarketCapMin     int64    // Minimum market cap (e.g., 1_000_000_000 for $1B)
+	MarketCapMax     int64    // Maximum market cap (0 = no limit)
+	PERatioMax       float64  // Maximum P/E ratio (e.g., 15)
+	PBRatioMax       float64  // Maximum P/B ratio (e.g., 1.5)
+	EPSMin           float64  // Minimum EPS (e.g., 0 for positive earnings)
+	DividendYieldMin float64  // Minimum dividend yield (optional)
+	Sector           string   // Sector filter (optional)
+	Exchanges        []string // Exchange allowlist, e.g. NYSE, NASDAQ (optional)
+	Country          string   // ISO country code (optional)
+	PriceMin         float64  // Minimum share price (optional)
+	AvgVolumeMin     int64    // Minimum average daily volume (optional)
+	Limit            int      // Maximum results to return
 }
 
 // ScreenerResult represents a single stock from screener results
@@ -74,6 +119,23 @@ type ScreenerResult struct {
 	Country       string  `json:"country"`
 }
 
+// Ratios holds trailing-twelve-month valuation ratios from FMP
+type Ratios struct {
+	Symbol        string  `json:"symbol"`
+	PERatio       float64 `json:"peRatio"`
+	PBRatio       float64 `json:"pbRatio"`
+	DividendYield float64 `json:"dividendYield"` // Percent
+	EPS           float64 `json:"eps"`
+}
+
+// GrowthMetrics holds a company's latest annual growth and recent share price performance from FMP
+type GrowthMetrics struct {
+	Symbol        string  `json:"symbol"`
+	RevenueGrowth float64 `json:"revenueGrowth"` // Year over year, e.g. 0.25 for 25%
+	EPSGrowth     float64 `json:"epsGrowth"`     // Year over year, e.g. 0.25 for 25%
+	PriceChange6M float64 `json:"priceChange6M"` // Percent
+}
+
 // CompanyProfile represents enriched company profile data from FMP
 type CompanyProfile struct {
 	Symbol            string  `json:"symbol"`
@@ -102,14 +164,25 @@ type AlpacaServiceInterface interface {
 	// Market data operations
 	GetBars(ctx context.Context, symbol string, start, end time.Time, timeframe marketdata.TimeFrame) ([]marketdata.Bar, error)
 	GetDailyBars(ctx context.Context, symbol string, days int) ([]marketdata.Bar, error)
+	GetAverageDailyVolume(ctx context.Context, symbol string, days int) (int64, error)
 	GetQuote(ctx context.Context, symbol string) (*models.Quote, error)
 	GetLatestTrade(ctx context.Context, symbol string) (*models.Quote, error)
 
+	// Account, trading and position operations
+	BrokerService
+}
+
+// BrokerService defines the account, order and position operations of a broker that
+// trades can be routed to
+type BrokerService interface {
 	// Account operations
 	GetAccount(ctx context.Context) (*models.Account, error)
 
 	// Trading operations
-	PlaceOrder(ctx context.Context, symbol string, qty decimal.Decimal, side models.TradeSide, orderType string) (string, error)
+	PlaceOrder(ctx context.Context, req models.OrderRequest) (string, error)
+	GetOrder(ctx context.Context, orderID string) (*models.BrokerOrder, error)
+	// GetShortAvailability returns nil when the broker only checks borrow at order time
+	GetShortAvailability(ctx context.Context, symbol string) (*models.ShortAvailability, error)
 
 	// Position operations
 	GetPositions(ctx context.Context) ([]models.Position, error)
@@ -121,3 +194,5 @@ var _ LLMService = (*OpenAIService)(nil)
 var _ AlphaVantageServiceInterface = (*AlphaVantageService)(nil)
 var _ NewsAPIServiceInterface = (*NewsAPIService)(nil)
 var _ AlpacaServiceInterface = (*AlpacaService)(nil)
+var _ OptionsDataService = (*AlpacaOptionsService)(nil)
+var _ BrokerService = (*IBKRService)(nil)
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+const (
+	// ledgerBufferSize is how many calls can wait to be written before new ones are dropped
+	ledgerBufferSize = 1000
+	// ledgerBatchSize is the largest number of calls written at once
+	ledgerBatchSize = 100
+	// ledgerFlushInterval is how often buffered calls are written
+	ledgerFlushInterval = 10 * time.Second
+	// ledgerPruneInterval is how often calls older than the retention are removed
+	ledgerPruneInterval = time.Hour
+)
+
+// CachedResponseHeader marks a response served from a cache rather than the provider.
+// Caching transports wrapped around the ledger transport set it to "1".
+const CachedResponseHeader = "X-From-Cache"
+
+// CallLedgerStore persists recorded API calls and their daily aggregates
+type CallLedgerStore interface {
+	SaveAPICalls(ctx context.Context, calls []models.APICall) error
+	PruneAPICalls(ctx context.Context, before time.Time) (int64, error)
+}
+
+// CallLedger records every outbound API call, buffering them in memory and writing
+// them in batches so recording never blocks a request
+type CallLedger struct {
+	store     CallLedgerStore
+	retention time.Duration
+	calls     chan models.APICall
+	dropped   atomic.Int64
+	now       func() time.Time
+}
+
+// NewCallLedger creates a ledger that keeps individual calls for retentionDays. Daily
+// aggregates are kept indefinitely.
+func NewCallLedger(store CallLedgerStore, retentionDays int) *CallLedger {
+	return &CallLedger{
+		store:     store,
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+		calls:     make(chan models.APICall, ledgerBufferSize),
+		now:       time.Now,
+	}
+}
+
+// Record queues a call to be written, dropping it if the buffer is full
+func (l *CallLedger) Record(call models.APICall) {
+	select {
+	case l.calls <- call:
+	default:
+		if l.dropped.Add(1) == 1 {
+			logger.Warn("API call ledger buffer full, dropping calls")
+		}
+	}
+}
+
+// Run writes buffered calls and prunes old ones until ctx is cancelled, then writes
+// whatever is still buffered
+func (l *CallLedger) Run(ctx context.Context) {
+	flush := time.NewTicker(ledgerFlushInterval)
+	defer flush.Stop()
+	prune := time.NewTicker(ledgerPruneInterval)
+	defer prune.Stop()
+
+	batch := make([]models.APICall, 0, ledgerBatchSize)
+	write := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := l.store.SaveAPICalls(ctx, batch); err != nil {
+			logger.Warn("failed to write API call ledger", "calls", len(batch), "error", err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case call := <-l.calls:
+			batch = append(batch, call)
+			if len(batch) >= ledgerBatchSize {
+				write(ctx)
+			}
+		case <-flush.C:
+			write(ctx)
+			if n := l.dropped.Swap(0); n > 0 {
+				logger.Warn("API call ledger dropped calls", "count", n)
+			}
+		case <-prune.C:
+			if l.retention <= 0 {
+				continue
+			}
+			if _, err := l.store.PruneAPICalls(ctx, l.now().Add(-l.retention)); err != nil {
+				logger.Warn("failed to prune API call ledger", "error", err)
+			}
+		case <-ctx.Done():
+		drain:
+			for {
+				select {
+				case call := <-l.calls:
+					batch = append(batch, call)
+				default:
+					break drain
+				}
+			}
+			shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			write(shutdownCtx)
+			cancel()
+			return
+		}
+	}
+}
+
+// activeLedger receives calls from every provider client; nil disables recording
+var activeLedger atomic.Pointer[CallLedger]
+
+// SetCallLedger sets the ledger outbound API calls are recorded to. Pass nil to stop recording.
+func SetCallLedger(l *CallLedger) {
+	activeLedger.Store(l)
+}
+
+// newLedgerHTTPClient returns an HTTP client whose calls are recorded to the call ledger
+// under provider. Cacheable requests are served from the response cache when it holds
+// them; the rest wait on the provider's rate limit. A zero timeout leaves requests
+// bounded by their context alone.
+func newLedgerHTTPClient(provider string, timeout time.Duration) *http.Client {
+	limited := &quotaTransport{provider: provider, base: http.DefaultTransport}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &ledgerTransport{
+			provider: provider,
+			base:     &cacheTransport{provider: provider, base: limited},
+		},
+	}
+}
+
+// ledgerTransport records each round trip to the active call ledger and raises a quota
+// alert when a provider rate-limits a request
+type ledgerTransport struct {
+	provider string
+	base     http.RoundTripper
+}
+
+func (t *ledgerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	call := models.APICall{
+		Provider:   t.provider,
+		Endpoint:   ledgerEndpoint(req),
+		OccurredAt: time.Now(),
+	}
+	_, span := observability.StartClientSpan(req.Context(), t.provider+" "+call.Endpoint,
+		"provider", t.provider, "http.request.method", req.Method, "endpoint", call.Endpoint)
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		notifyQuotaExhausted(t.provider, req, resp)
+	}
+	observability.GetMetrics().RecordProviderHTTP(t.provider, providerResult(resp, err), time.Since(call.OccurredAt))
+	endProviderSpan(span, resp, err)
+
+	ledger := activeLedger.Load()
+	if ledger == nil {
+		return resp, err
+	}
+	if err != nil {
+		call.LatencyMs = time.Since(call.OccurredAt).Milliseconds()
+		ledger.Record(call)
+		return nil, err
+	}
+
+	call.Status = resp.StatusCode
+	call.Cached = resp.Header.Get(CachedResponseHeader) == "1"
+	resp.Body = &countingBody{ReadCloser: resp.Body, done: func(n int64) {
+		call.Bytes = n
+		call.LatencyMs = time.Since(call.OccurredAt).Milliseconds()
+		ledger.Record(call)
+	}}
+	return resp, nil
+}
+
+// endProviderSpan records the outcome of a provider round trip on its span. The span
+// ends once the response headers arrive, so it excludes reading the body.
+func endProviderSpan(span *observability.Span, resp *http.Response, err error) {
+	if err != nil {
+		span.RecordError(err)
+	} else {
+		span.SetAttributes("http.response.status_code", resp.StatusCode, "cached", resp.Header.Get(CachedResponseHeader) == "1")
+		if resp.StatusCode >= http.StatusBadRequest {
+			span.RecordError(fmt.Errorf("provider returned status %d", resp.StatusCode))
+		}
+	}
+	span.End()
+}
+
+// providerResult labels a provider round trip for latency metrics by its status class, so
+// fast failures and cache hits don't hide the latency of real responses
+func providerResult(resp *http.Response, err error) string {
+	switch {
+	case err != nil:
+		return "error"
+	case resp.Header.Get(CachedResponseHeader) == "1":
+		return "cached"
+	}
+	return fmt.Sprintf("%dxx", resp.StatusCode/100)
+}
+
+// countingBody counts the bytes read from a response body and reports the total once,
+// when the body is closed
+type countingBody struct {
+	io.ReadCloser
+	n    int64
+	once sync.Once
+	done func(n int64)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.n) })
+	return err
+}
+
+var (
+	symbolSegment = regexp.MustCompile(`^[A-Z][A-Z0-9.\-]{0,9}$`)
+	idSegment     = regexp.MustCompile(`^[0-9a-fA-F-]{16,}$|^[0-9]+$`)
+)
+
+// ledgerEndpoint returns the request path with symbols and IDs replaced by placeholders
+// so calls aggregate per endpoint. Query strings are dropped since they carry API keys,
+// except Alpha Vantage's function parameter, which selects the endpoint.
+func ledgerEndpoint(req *http.Request) string {
+	segments := strings.Split(req.URL.Path, "/")
+	for i, s := range segments {
+		switch {
+		case symbolSegment.MatchString(s):
+			segments[i] = "{symbol}"
+		case idSegment.MatchString(s):
+			segments[i] = "{id}"
+		}
+	}
+	endpoint := strings.Join(segments, "/")
+	if function := req.URL.Query().Get("function"); function != "" {
+		endpoint += "?function=" + function
+	}
+	return endpoint
+}
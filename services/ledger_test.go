@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+)
+
+// memoryLedgerStore keeps saved calls in memory
+type memoryLedgerStore struct {
+	mu    sync.Mutex
+	calls []models.APICall
+}
+
+func (s *memoryLedgerStore) SaveAPICalls(ctx context.Context, calls []models.APICall) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, calls...)
+	return nil
+}
+
+func (s *memoryLedgerStore) PruneAPICalls(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestLedgerTransport_RecordsCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/profile/AAPL" {
+			w.Header().Set(CachedResponseHeader, "1")
+			w.Write([]byte(`{"symbol":"AAPL"}`))
+			return
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	store := &memoryLedgerStore{}
+	ledger := NewCallLedger(store, 30)
+	SetCallLedger(ledger)
+	defer SetCallLedger(nil)
+
+	client := newLedgerHTTPClient(BreakerFMP, time.Second)
+	for _, path := range []string{"/api/v3/profile/AAPL?apikey=secret", "/api/v3/stock-screener?apikey=secret"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Get(%s) error = %v", path, err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ledger.Run(ctx)
+		close(done)
+	}()
+	cancel()
+	<-done
+
+	if len(store.calls) != 2 {
+		t.Fatalf("recorded %d calls, want 2", len(store.calls))
+	}
+	profile, screener := store.calls[0], store.calls[1]
+	if profile.Provider != BreakerFMP || profile.Endpoint != "/api/v3/profile/{symbol}" {
+		t.Errorf("profile call = %+v, want fmp /api/v3/profile/{symbol}", profile)
+	}
+	if profile.Status != http.StatusOK || profile.Bytes != int64(len(`{"symbol":"AAPL"}`)) || !profile.Cached {
+		t.Errorf("profile call = %+v, want a cached 200 with the body size", profile)
+	}
+	if screener.Status != http.StatusTooManyRequests || !screener.Failed() {
+		t.Errorf("screener call = %+v, want a failed 429", screener)
+	}
+}
+
+func TestLedgerEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"https://data.alpaca.markets/v2/stocks/BRK.B/quotes/latest":                       "/v2/stocks/{symbol}/quotes/latest",
+		"https://paper-api.alpaca.markets/v2/orders/61e69015-8549-4bfd-b9c3-01e75843f47d": "/v2/orders/{id}",
+		"https://www.alphavantage.co/query?function=OVERVIEW&symbol=AAPL&apikey=secret":   "/query?function=OVERVIEW",
+		"https://api.openai.com/v1/chat/completions":                                      "/v1/chat/completions",
+	}
+	for rawURL, want := range tests {
+		req, _ := http.NewRequest(http.MethodGet, rawURL, nil)
+		if got := ledgerEndpoint(req); got != want {
+			t.Errorf("ledgerEndpoint(%s) = %q, want %q", rawURL, got, want)
+		}
+	}
+}
+
+func TestProviderResult(t *testing.T) {
+	cached := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	cached.Header.Set(CachedResponseHeader, "1")
+
+	tests := []struct {
+		name string
+		resp *http.Response
+		err  error
+		want string
+	}{
+		{"success", &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}, nil, "2xx"},
+		{"rate limited", &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}, nil, "4xx"},
+		{"cached", cached, nil, "cached"},
+		{"transport error", nil, io.ErrUnexpectedEOF, "error"},
+	}
+	for _, tt := range tests {
+		if got := providerResult(tt.resp, tt.err); got != tt.want {
+			t.Errorf("%s: providerResult() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	appconfig "trade-machine/config"
+	"trade-machine/observability"
+)
+
+// LLMLeveler is implemented by LLM services that know which provider an agent's calls go
+// to, so the agent can report that provider's degradation rather than a fixed one's
+type LLMLeveler interface {
+	LLMLevel(agent string) DegradationLevel
+}
+
+type llmAgentKey struct{}
+
+// WithLLMAgent returns a context whose LLM calls are routed as the given agent type's
+func WithLLMAgent(ctx context.Context, agent string) context.Context {
+	return context.WithValue(ctx, llmAgentKey{}, agent)
+}
+
+// fallbackLogger logs calls sent to the fallback provider, which happens on every call
+// while the routed provider is down
+var fallbackLogger = observability.Sampled(logger, 20)
+
+// LLMRouter is an LLMService that sends each agent's calls to the provider routed for it,
+// or to the default provider, and to the fallback provider while the chosen one's circuit
+// breaker is open. A route's model applies unless the call carries a model override; calls
+// that fall back use the fallback provider's configured model.
+type LLMRouter struct {
+	providers       map[string]LLMService // By breaker name
+	defaultProvider func(ctx context.Context) string
+	routes          map[string]appconfig.LLMRoute
+	fallback        string
+}
+
+// NewLLMRouter creates a router over providers, keyed by breaker name. defaultProvider
+// picks the provider for calls without a route.
+func NewLLMRouter(cfg appconfig.LLMConfig, providers map[string]LLMService, defaultProvider func(ctx context.Context) string) *LLMRouter {
+	return &LLMRouter{
+		providers:       providers,
+		defaultProvider: defaultProvider,
+		routes:          cfg.Routes,
+		fallback:        cfg.FallbackProvider,
+	}
+}
+
+// route returns the provider and model for the agent whose call ctx carries
+func (r *LLMRouter) route(ctx context.Context) (string, string) {
+	agent, _ := ctx.Value(llmAgentKey{}).(string)
+	if route, ok := r.routes[agent]; ok {
+		return route.Provider, route.Model
+	}
+	return r.defaultProvider(ctx), ""
+}
+
+// fallsBack reports whether calls meant for provider should go to the fallback provider:
+// provider's breaker is open and the fallback's is not
+func (r *LLMRouter) fallsBack(provider string) bool {
+	if r.fallback == "" || r.fallback == provider || r.providers[r.fallback] == nil {
+		return false
+	}
+	return BreakerLevel(provider) == DegradationOpen && BreakerLevel(r.fallback) != DegradationOpen
+}
+
+// LLMLevel returns the degradation of the provider an agent's calls go to, or of the
+// fallback provider while that one's breaker is open
+func (r *LLMRouter) LLMLevel(agent string) DegradationLevel {
+	provider, _ := r.route(WithLLMAgent(context.Background(), agent))
+	if r.fallsBack(provider) {
+		return BreakerLevel(r.fallback)
+	}
+	return BreakerLevel(provider)
+}
+
+// routeLLMCall runs call against the provider routed for ctx, or against the fallback
+// provider when that one's breaker is open before the call or opens because of it. A
+// permanent failure, such as a stream that broke off partway, isn't retried on the fallback.
+func routeLLMCall[T any](r *LLMRouter, ctx context.Context, call func(context.Context, LLMService) (T, error)) (T, error) {
+	provider, model := r.route(ctx)
+	if r.fallsBack(provider) {
+		fallbackLogger.Warn("LLM provider circuit breaker open, using fallback", "provider", provider, "fallback", r.fallback)
+		return call(withoutModel(ctx), r.providers[r.fallback])
+	}
+
+	svc, ok := r.providers[provider]
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("unknown LLM provider %q", provider)
+	}
+	if model != "" && ModelFromContext(ctx, "") == "" {
+		ctx = WithModel(ctx, model)
+	}
+	result, err := call(ctx, svc)
+	if err != nil && !errors.As(err, new(permanentError)) && r.fallsBack(provider) {
+		fallbackLogger.Warn("LLM provider circuit breaker opened, retrying on fallback", "provider", provider, "fallback", r.fallback, "error", err)
+		return call(withoutModel(ctx), r.providers[r.fallback])
+	}
+	return result, err
+}
+
+// InvokeWithPrompt sends a prompt to the routed provider and returns the response text
+func (r *LLMRouter) InvokeWithPrompt(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return routeLLMCall(r, ctx, func(ctx context.Context, svc LLMService) (string, error) {
+		return svc.InvokeWithPrompt(ctx, systemPrompt, userPrompt)
+	})
+}
+
+// InvokeStructured sends a prompt to the routed provider and parses the JSON response
+// into result
+func (r *LLMRouter) InvokeStructured(ctx context.Context, systemPrompt, userPrompt string, result interface{}) error {
+	_, err := routeLLMCall(r, ctx, func(ctx context.Context, svc LLMService) (struct{}, error) {
+		return struct{}{}, svc.InvokeStructured(ctx, systemPrompt, userPrompt, result)
+	})
+	return err
+}
+
+// Chat holds a multi-turn conversation with the routed provider
+func (r *LLMRouter) Chat(ctx context.Context, systemPrompt string, messages []ChatMessage) (string, error) {
+	return routeLLMCall(r, ctx, func(ctx context.Context, svc LLMService) (string, error) {
+		return svc.Chat(ctx, systemPrompt, messages)
+	})
+}
+
+// ChatStream streams a conversation with the routed provider
+func (r *LLMRouter) ChatStream(ctx context.Context, systemPrompt string, messages []ChatMessage, onDelta func(delta string) error) (string, error) {
+	return routeLLMCall(r, ctx, func(ctx context.Context, svc LLMService) (string, error) {
+		return svc.ChatStream(ctx, systemPrompt, messages, onDelta)
+	})
+}
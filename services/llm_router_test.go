@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appconfig "trade-machine/config"
+)
+
+// routedLLM answers with its name and the model the call asked for, failing through its
+// circuit breaker when err is set
+type routedLLM struct {
+	name string
+	err  error
+}
+
+func (l *routedLLM) InvokeWithPrompt(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return WithCircuitBreaker(ctx, l.name, func() (string, error) {
+		if l.err != nil {
+			return "", l.err
+		}
+		return l.name + ":" + ModelFromContext(ctx, "default"), nil
+	})
+}
+
+func (l *routedLLM) InvokeStructured(ctx context.Context, systemPrompt, userPrompt string, result interface{}) error {
+	_, err := l.InvokeWithPrompt(ctx, systemPrompt, userPrompt)
+	return err
+}
+
+func (l *routedLLM) Chat(ctx context.Context, systemPrompt string, messages []ChatMessage) (string, error) {
+	return l.InvokeWithPrompt(ctx, systemPrompt, "")
+}
+
+func (l *routedLLM) ChatStream(ctx context.Context, systemPrompt string, messages []ChatMessage, onDelta func(delta string) error) (string, error) {
+	return l.InvokeWithPrompt(ctx, systemPrompt, "")
+}
+
+func newTestLLMRouter(t *testing.T, fallback string) (*LLMRouter, map[string]*routedLLM) {
+	t.Helper()
+	SetGlobalRegistry(NewCircuitBreakerRegistry(CircuitBreakerConfig{MaxRequests: 1, Interval: time.Minute, Timeout: time.Minute}))
+	t.Cleanup(func() { SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig)) })
+
+	llms := map[string]*routedLLM{}
+	providers := map[string]LLMService{}
+	for _, name := range []string{BreakerOpenAI, BreakerAnthropic, BreakerOllama} {
+		llms[name] = &routedLLM{name: name}
+		providers[name] = llms[name]
+	}
+	cfg := appconfig.LLMConfig{
+		Routes: map[string]appconfig.LLMRoute{
+			"news":      {Provider: BreakerAnthropic, Model: "claude-haiku-4-5"},
+			"technical": {Provider: BreakerOllama},
+		},
+		FallbackProvider: fallback,
+	}
+	return NewLLMRouter(cfg, providers, func(context.Context) string { return BreakerOpenAI }), llms
+}
+
+func TestLLMRouter_Routes(t *testing.T) {
+	router, _ := newTestLLMRouter(t, "")
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"unrouted agent", WithLLMAgent(ctx, "fundamental"), "openai:default"},
+		{"no agent", ctx, "openai:default"},
+		{"routed with a model", WithLLMAgent(ctx, "news"), "anthropic:claude-haiku-4-5"},
+		{"routed without a model", WithLLMAgent(ctx, "technical"), "ollama:default"},
+		{"model override wins", WithModel(WithLLMAgent(ctx, "news"), "claude-opus-4-1"), "anthropic:claude-opus-4-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := router.InvokeWithPrompt(tt.ctx, "system", "user")
+			if err != nil || got != tt.want {
+				t.Errorf("InvokeWithPrompt() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLLMRouter_FallsBackWhileBreakerOpen(t *testing.T) {
+	router, llms := newTestLLMRouter(t, BreakerOpenAI)
+	ctx := WithLLMAgent(context.Background(), "news")
+
+	llms[BreakerAnthropic].err = errors.New("overloaded")
+	for range 4 {
+		if _, err := router.InvokeWithPrompt(ctx, "system", "user"); err == nil {
+			t.Fatal("expected the error while the breaker is closed")
+		}
+	}
+	if level := router.LLMLevel("news"); level == DegradationOpen {
+		t.Fatalf("LLMLevel() = %v before the breaker opened", level)
+	}
+
+	// The failure that opens the breaker is retried on the fallback, without the route's
+	// Anthropic model
+	got, err := router.InvokeWithPrompt(ctx, "system", "user")
+	if err != nil || got != "openai:default" {
+		t.Fatalf("InvokeWithPrompt() = %q, %v, want the fallback's answer", got, err)
+	}
+	got, err = router.Chat(ctx, "system", nil)
+	if err != nil || got != "openai:default" {
+		t.Errorf("Chat() = %q, %v, want the fallback's answer while the breaker is open", got, err)
+	}
+	if level := router.LLMLevel("news"); level != DegradationNone {
+		t.Errorf("LLMLevel() = %v, want the fallback's level", level)
+	}
+
+	// Agents routed elsewhere are unaffected
+	if got, _ := router.InvokeWithPrompt(WithLLMAgent(context.Background(), "technical"), "system", "user"); got != "ollama:default" {
+		t.Errorf("technical InvokeWithPrompt() = %q, want ollama", got)
+	}
+}
+
+func TestLLMRouter_NoFallback(t *testing.T) {
+	router, llms := newTestLLMRouter(t, "")
+	ctx := WithLLMAgent(context.Background(), "news")
+
+	llms[BreakerAnthropic].err = errors.New("overloaded")
+	for range 6 {
+		router.InvokeWithPrompt(ctx, "system", "user")
+	}
+	if _, err := router.InvokeWithPrompt(ctx, "system", "user"); err == nil {
+		t.Error("expected an error with the breaker open and no fallback")
+	}
+	if level := router.LLMLevel("news"); level != DegradationOpen {
+		t.Errorf("LLMLevel() = %v, want open", level)
+	}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// deltaStream passes a streamed reply on to a ChatStream caller and keeps the whole of it
+type deltaStream struct {
+	onDelta func(delta string) error
+	text    strings.Builder
+}
+
+// send passes a piece of the reply on. An error from the caller is permanent, as retrying
+// wouldn't bring the caller back.
+func (s *deltaStream) send(delta string) error {
+	if delta == "" {
+		return nil
+	}
+	if err := s.onDelta(delta); err != nil {
+		return permanent(err)
+	}
+	s.text.WriteString(delta)
+	return nil
+}
+
+// fail returns err for a stream that broke off, marked permanent once part of the reply
+// has been passed on, since a retry would send the caller the reply again from the start
+func (s *deltaStream) fail(err error) error {
+	if s.text.Len() > 0 {
+		return permanent(err)
+	}
+	return err
+}
+
+// readSSEData calls fn with the data of each Server-Sent Events data line in r, until fn
+// fails or r ends
+func readSSEData(r io.Reader, fn func(data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := bytes.CutPrefix(scanner.Bytes(), []byte("data:"))
+		if !ok {
+			continue
+		}
+		if err := fn(bytes.TrimSpace(data)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
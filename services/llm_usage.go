@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// LLMCall is the token usage reported for one LLM request
+type LLMCall struct {
+	Provider     string // Circuit breaker name of the provider (openai, anthropic, ollama)
+	Model        string
+	InputTokens  int64
+	OutputTokens int64
+}
+
+// LLMUsageTally collects the LLM calls made with a context. It is safe for concurrent use.
+type LLMUsageTally struct {
+	mu    sync.Mutex
+	calls []LLMCall
+}
+
+// Calls returns the calls recorded so far, in the order they completed
+func (t *LLMUsageTally) Calls() []LLMCall {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.calls)
+}
+
+type llmUsageKey struct{}
+
+// WithLLMUsage returns a context whose LLM calls are recorded in the returned tally
+func WithLLMUsage(ctx context.Context) (context.Context, *LLMUsageTally) {
+	tally := &LLMUsageTally{}
+	return context.WithValue(ctx, llmUsageKey{}, tally), tally
+}
+
+// RecordLLMUsage adds a call's token usage to the tally carried by ctx, if any. LLM
+// services call it for every completed request.
+func RecordLLMUsage(ctx context.Context, provider, model string, inputTokens, outputTokens int64) {
+	tally, ok := ctx.Value(llmUsageKey{}).(*LLMUsageTally)
+	if !ok {
+		return
+	}
+	tally.mu.Lock()
+	defer tally.mu.Unlock()
+	tally.calls = append(tally.calls, LLMCall{Provider: provider, Model: model, InputTokens: inputTokens, OutputTokens: outputTokens})
+}
@@ -0,0 +1,31 @@
+package services
+
+import "context"
+
+type modelOverrideKey struct{}
+
+// WithModel returns a context that makes LLM services use the given model instead of
+// their configured default. An empty model leaves the context unchanged.
+func WithModel(ctx context.Context, model string) context.Context {
+	if model == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, modelOverrideKey{}, model)
+}
+
+// ModelFromContext returns the model override carried by ctx, or fallback if there is none
+func ModelFromContext(ctx context.Context, fallback string) string {
+	if model, ok := ctx.Value(modelOverrideKey{}).(string); ok && model != "" {
+		return model
+	}
+	return fallback
+}
+
+// withoutModel returns a context that drops any model override, for calls sent to a
+// provider the override wasn't meant for
+func withoutModel(ctx context.Context) context.Context {
+	if ModelFromContext(ctx, "") == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, modelOverrideKey{}, "")
+}
@@ -4,12 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"time"
 
 	"trade-machine/models"
-	"trade-machine/observability"
 )
 
 // NewsAPIService handles communication with NewsAPI.org
@@ -23,7 +23,7 @@ type NewsAPIService struct {
 func NewNewsAPIService(apiKey string) *NewsAPIService {
 	return &NewsAPIService{
 		apiKey:     apiKey,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		httpClient: newLedgerHTTPClient(BreakerNewsAPI, 30*time.Second),
 		baseURL:    "https://newsapi.org/v2",
 	}
 }
@@ -49,6 +49,12 @@ type NewsAPIResponse struct {
 
 // GetNews returns news articles for a query (typically a stock symbol or company name)
 func (s *NewsAPIService) GetNews(ctx context.Context, query string, limit int) ([]models.NewsArticle, error) {
+	return s.GetNewsSince(ctx, query, limit, time.Time{})
+}
+
+// GetNewsSince returns news articles for a query published at or after from.
+// A zero from applies no lower bound.
+func (s *NewsAPIService) GetNewsSince(ctx context.Context, query string, limit int, from time.Time) ([]models.NewsArticle, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -58,12 +64,17 @@ func (s *NewsAPIService) GetNews(ctx context.Context, query string, limit int) (
 
 	return WithCircuitBreaker(ctx, BreakerNewsAPI, func() ([]models.NewsArticle, error) {
 		var articles []models.NewsArticle
-		err := WithRetry(ctx, DefaultRetryConfig, func() error {
+		err := currentRetryPolicy().Do(ctx, func() error {
 			params := url.Values{}
 			params.Set("q", query)
 			params.Set("language", "en")
 			params.Set("sortBy", "publishedAt")
 			params.Set("pageSize", fmt.Sprintf("%d", limit))
+			if !from.IsZero() {
+				// Rounded down to the hour so repeated lookups share a cached response;
+				// callers filter articles to their exact bound
+				params.Set("from", from.UTC().Truncate(time.Hour).Format(time.RFC3339))
+			}
 
 			req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+"/everything?"+params.Encode(), nil)
 			if err != nil {
@@ -78,34 +89,11 @@ func (s *NewsAPIService) GetNews(ctx context.Context, query string, limit int) (
 			defer resp.Body.Close()
 
 			if resp.StatusCode != http.StatusOK {
-				return fmt.Errorf("NewsAPI returned status %d", resp.StatusCode)
-			}
-
-			var newsResp NewsAPIResponse
-			if err := json.NewDecoder(resp.Body).Decode(&newsResp); err != nil {
-				return fmt.Errorf("failed to decode response: %w", err)
+				return newStatusError("NewsAPI", resp, "")
 			}
 
-			articles = make([]models.NewsArticle, 0, len(newsResp.Articles))
-			for _, item := range newsResp.Articles {
-				publishedAt, err := time.Parse(time.RFC3339, item.PublishedAt)
-				if err != nil {
-					observability.Warn("failed to parse timestamp, using current time", "value", item.PublishedAt, "error", err)
-					publishedAt = time.Now()
-				}
-
-				articles = append(articles, models.NewsArticle{
-					Title:       item.Title,
-					Description: item.Description,
-					URL:         item.URL,
-					Source:      item.Source.Name,
-					Author:      item.Author,
-					ImageURL:    item.URLToImage,
-					PublishedAt: publishedAt,
-				})
-			}
-
-			return nil
+			articles, err = decodeNewsArticles(resp.Body)
+			return err
 		})
 
 		if err != nil {
@@ -126,52 +114,60 @@ func (s *NewsAPIService) GetHeadlines(ctx context.Context, query string, limit i
 	}
 
 	return WithCircuitBreaker(ctx, BreakerNewsAPI, func() ([]models.NewsArticle, error) {
-		params := url.Values{}
-		params.Set("q", query)
-		params.Set("country", "us")
-		params.Set("category", "business")
-		params.Set("pageSize", fmt.Sprintf("%d", limit))
+		return Retry(ctx, func() ([]models.NewsArticle, error) {
+			params := url.Values{}
+			params.Set("q", query)
+			params.Set("country", "us")
+			params.Set("category", "business")
+			params.Set("pageSize", fmt.Sprintf("%d", limit))
 
-		req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+"/top-headlines?"+params.Encode(), nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
-		}
-		req.Header.Set("X-Api-Key", s.apiKey)
+			req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+"/top-headlines?"+params.Encode(), nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create request: %w", err)
+			}
+			req.Header.Set("X-Api-Key", s.apiKey)
 
-		resp, err := s.httpClient.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch headlines: %w", err)
-		}
-		defer resp.Body.Close()
+			resp, err := s.httpClient.Do(req)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch headlines: %w", err)
+			}
+			defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("NewsAPI returned status %d", resp.StatusCode)
-		}
+			if resp.StatusCode != http.StatusOK {
+				return nil, newStatusError("NewsAPI", resp, "")
+			}
 
-		var newsResp NewsAPIResponse
-		if err := json.NewDecoder(resp.Body).Decode(&newsResp); err != nil {
-			return nil, fmt.Errorf("failed to decode response: %w", err)
-		}
+			return decodeNewsArticles(resp.Body)
+		})
+	})
+}
 
-		articles := make([]models.NewsArticle, 0, len(newsResp.Articles))
-		for _, item := range newsResp.Articles {
-			publishedAt, err := time.Parse(time.RFC3339, item.PublishedAt)
-			if err != nil {
-				observability.Warn("failed to parse timestamp, using current time", "value", item.PublishedAt, "error", err)
-				publishedAt = time.Now()
-			}
+// decodeNewsArticles decodes a NewsAPI response body into articles. Articles with an
+// unparseable timestamp are dated now rather than dropped.
+func decodeNewsArticles(body io.Reader) ([]models.NewsArticle, error) {
+	var newsResp NewsAPIResponse
+	if err := json.NewDecoder(body).Decode(&newsResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
 
-			articles = append(articles, models.NewsArticle{
-				Title:       item.Title,
-				Description: item.Description,
-				URL:         item.URL,
-				Source:      item.Source.Name,
-				Author:      item.Author,
-				ImageURL:    item.URLToImage,
-				PublishedAt: publishedAt,
-			})
+	articles := make([]models.NewsArticle, 0, len(newsResp.Articles))
+	for _, item := range newsResp.Articles {
+		publishedAt, err := time.Parse(time.RFC3339, item.PublishedAt)
+		if err != nil {
+			logger.Warn("failed to parse timestamp, using current time", "value", item.PublishedAt, "error", err)
+			publishedAt = time.Now()
 		}
 
-		return articles, nil
-	})
+		articles = append(articles, models.NewsArticle{
+			Title:       item.Title,
+			Description: item.Description,
+			URL:         item.URL,
+			Source:      item.Source.Name,
+			Author:      item.Author,
+			ImageURL:    item.URLToImage,
+			PublishedAt: publishedAt,
+		})
+	}
+
+	return articles, nil
 }
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewNewsAPIService(t *testing.T) {
@@ -608,3 +610,49 @@ func TestGetHeadlines_InvalidJSON(t *testing.T) {
 		t.Error("expected error for invalid JSON")
 	}
 }
+
+func TestGetNewsSince_SetsFromParam(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	from := time.Date(2024, 1, 8, 15, 30, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Rounded down to the hour so lookups within it share a cached response
+		if got := r.URL.Query().Get("from"); got != "2024-01-08T15:00:00Z" {
+			t.Errorf("from = %q, want 2024-01-08T15:00:00Z", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status": "ok", "totalResults": 0, "articles": []}`))
+	}))
+	defer server.Close()
+
+	service := NewNewsAPIService("test-key")
+	service.baseURL = server.URL
+
+	if _, err := service.GetNewsSince(context.Background(), "AAPL", 10, from); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// FuzzDecodeNewsArticles checks that malformed NewsAPI responses are rejected with an
+// error rather than a panic, and that no article in a valid response is dropped
+func FuzzDecodeNewsArticles(f *testing.F) {
+	f.Add(`{"status":"ok","totalResults":1,"articles":[{"source":{"id":null,"name":"Reuters"},"title":"Tech Stocks Rally","publishedAt":"2024-01-15T10:00:00Z"}]}`)
+	f.Add(`{"status":"ok","articles":[{"title":"Bad date","publishedAt":"yesterday"}]}`)
+	f.Add(`{"status":"error","code":"rateLimited","message":"Too many requests"}`)
+	f.Add(`{"articles":null}`)
+	f.Add(`[]`)
+	f.Add(`{"articles":[`)
+
+	f.Fuzz(func(t *testing.T, body string) {
+		articles, err := decodeNewsArticles(strings.NewReader(body))
+		if err != nil {
+			return
+		}
+		var raw struct {
+			Articles []json.RawMessage `json:"articles"`
+		}
+		if err := json.Unmarshal([]byte(body), &raw); err == nil && len(raw.Articles) != len(articles) {
+			t.Fatalf("decoded %d articles from %q, want %d", len(articles), body, len(raw.Articles))
+		}
+	})
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	appconfig "trade-machine/config"
+	"trade-machine/observability"
+)
+
+// DefaultOllamaURL is where a local Ollama server listens unless configured otherwise
+const DefaultOllamaURL = "http://localhost:11434"
+
+// OllamaService runs prompts against a local Ollama server, so agents can work offline
+// without any provider keys
+type OllamaService struct {
+	model      string
+	maxTokens  int
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewOllamaService creates a new OllamaService instance. An empty base URL uses the
+// default local server.
+func NewOllamaService(cfg *appconfig.Config) *OllamaService {
+	baseURL := cfg.Ollama.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultOllamaURL
+	}
+
+	return &OllamaService{
+		model:     cfg.Ollama.Model,
+		maxTokens: cfg.Ollama.MaxTokens,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		// Local models can take minutes on modest hardware; the request context still
+		// bounds each call
+		httpClient: newLedgerHTTPClient(BreakerOllama, 10*time.Minute),
+	}
+}
+
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Format   string          `json:"format,omitempty"`
+	Options  struct {
+		NumPredict int `json:"num_predict,omitempty"`
+	} `json:"options"`
+}
+
+type ollamaResponse struct {
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"` // Set on the last line of a streamed reply
+	Error           string        `json:"error"`
+	PromptEvalCount int64         `json:"prompt_eval_count"` // Input tokens
+	EvalCount       int64         `json:"eval_count"`        // Output tokens
+}
+
+// InvokeWithPrompt sends a prompt to Ollama and returns the response text
+func (s *OllamaService) InvokeWithPrompt(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return s.send(ctx, "invoke", systemPrompt, []ChatMessage{{Role: "user", Content: userPrompt}}, "")
+}
+
+// InvokeStructured sends a prompt and parses the JSON response into the provided struct.
+// Ollama is asked for JSON output, which keeps smaller local models from wrapping the
+// answer in prose.
+func (s *OllamaService) InvokeStructured(ctx context.Context, systemPrompt, userPrompt string, result interface{}) error {
+	text, err := s.send(ctx, "invoke", systemPrompt, []ChatMessage{{Role: "user", Content: userPrompt}}, "json")
+	if err != nil {
+		return err
+	}
+
+	return ParseStructuredOutput(text, result)
+}
+
+// Chat enables multi-turn conversation with Ollama
+func (s *OllamaService) Chat(ctx context.Context, systemPrompt string, messages []ChatMessage) (string, error) {
+	return s.send(ctx, "chat", systemPrompt, messages, "")
+}
+
+// ChatStream holds a conversation with Ollama, passing the reply to onDelta as it
+// streams in. Only failures before any of the reply has been passed on are retried.
+func (s *OllamaService) ChatStream(ctx context.Context, systemPrompt string, messages []ChatMessage, onDelta func(delta string) error) (string, error) {
+	metrics := observability.GetMetrics()
+	metrics.RecordExternalAPIRequest(BreakerOllama, "chat_stream")
+	timer := metrics.NewTimer()
+
+	result, err := WithCircuitBreaker(ctx, BreakerOllama, func() (string, error) {
+		body := s.request(ctx, systemPrompt, messages, "")
+		body.Stream = true
+		data, err := json.Marshal(body)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode request: %w", err)
+		}
+
+		return Retry(ctx, func() (string, error) {
+			resp, err := s.post(ctx, data)
+			if err != nil {
+				return "", err
+			}
+			defer resp.Body.Close()
+
+			// The reply streams as one JSON object per line, the last one marked done and
+			// carrying the token counts
+			stream := &deltaStream{onDelta: onDelta}
+			decoder := json.NewDecoder(resp.Body)
+			for {
+				var chunk ollamaResponse
+				if err := decoder.Decode(&chunk); err != nil {
+					return "", stream.fail(fmt.Errorf("failed to decode Ollama response: %w", err))
+				}
+				if chunk.Error != "" {
+					return "", stream.fail(fmt.Errorf("stream error from Ollama: %s", chunk.Error))
+				}
+				if err := stream.send(chunk.Message.Content); err != nil {
+					return "", err
+				}
+				if chunk.Done {
+					metrics.RecordLLMTokens(BreakerOllama, body.Model, chunk.PromptEvalCount, chunk.EvalCount)
+					RecordLLMUsage(ctx, BreakerOllama, body.Model, chunk.PromptEvalCount, chunk.EvalCount)
+					break
+				}
+			}
+
+			if stream.text.Len() == 0 {
+				return "", fmt.Errorf("empty response from Ollama")
+			}
+			return stream.text.String(), nil
+		})
+	})
+
+	timer.ObserveExternalAPI(BreakerOllama, "chat_stream")
+	if err != nil {
+		metrics.RecordExternalAPIError(BreakerOllama, "chat_stream", categorizeAPIError(err))
+	}
+	return result, err
+}
+
+// request builds an /api/chat request, dropping messages with roles other than user and
+// assistant as the system prompt goes first
+func (s *OllamaService) request(ctx context.Context, systemPrompt string, messages []ChatMessage, format string) ollamaRequest {
+	body := ollamaRequest{
+		Model:    ModelFromContext(ctx, s.model),
+		Messages: []ollamaMessage{{Role: "system", Content: systemPrompt}},
+		Format:   format,
+	}
+	body.Options.NumPredict = s.maxTokens
+	for _, msg := range messages {
+		switch msg.Role {
+		case "user", "assistant":
+			body.Messages = append(body.Messages, ollamaMessage{Role: msg.Role, Content: msg.Content})
+		}
+	}
+	return body
+}
+
+// post makes an /api/chat request, returning the response if its status is OK. The
+// caller closes the body.
+func (s *OllamaService) post(ctx context.Context, data []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/api/chat", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to invoke Ollama: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var reply ollamaResponse
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(raw, &reply) == nil && reply.Error != "" {
+			return nil, newStatusError("Ollama", resp, reply.Error)
+		}
+		return nil, newStatusError("Ollama", resp, "")
+	}
+	return resp, nil
+}
+
+// send makes a non-streaming /api/chat call and returns the reply
+func (s *OllamaService) send(ctx context.Context, operation, systemPrompt string, messages []ChatMessage, format string) (string, error) {
+	metrics := observability.GetMetrics()
+	metrics.RecordExternalAPIRequest(BreakerOllama, operation)
+	timer := metrics.NewTimer()
+
+	result, err := WithCircuitBreaker(ctx, BreakerOllama, func() (string, error) {
+		body := s.request(ctx, systemPrompt, messages, format)
+		data, err := json.Marshal(body)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode request: %w", err)
+		}
+
+		return Retry(ctx, func() (string, error) {
+			resp, err := s.post(ctx, data)
+			if err != nil {
+				return "", err
+			}
+			defer resp.Body.Close()
+
+			var reply ollamaResponse
+			if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+				return "", fmt.Errorf("failed to decode Ollama response: %w", err)
+			}
+			metrics.RecordLLMTokens(BreakerOllama, body.Model, reply.PromptEvalCount, reply.EvalCount)
+			RecordLLMUsage(ctx, BreakerOllama, body.Model, reply.PromptEvalCount, reply.EvalCount)
+			if reply.Message.Content == "" {
+				return "", fmt.Errorf("empty response from Ollama")
+			}
+			return reply.Message.Content, nil
+		})
+	})
+
+	timer.ObserveExternalAPI(BreakerOllama, operation)
+	if err != nil {
+		metrics.RecordExternalAPIError(BreakerOllama, operation, categorizeAPIError(err))
+	}
+	return result, err
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	appconfig "trade-machine/config"
+)
+
+// newTestOllamaService points an OllamaService at handler
+func newTestOllamaService(t *testing.T, handler http.HandlerFunc) *OllamaService {
+	t.Helper()
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := appconfig.NewTestConfig()
+	cfg.Ollama.BaseURL = server.URL + "/"
+	return NewOllamaService(cfg)
+}
+
+func TestNewOllamaService_DefaultURL(t *testing.T) {
+	service := NewOllamaService(appconfig.NewTestConfig())
+	if service.baseURL != DefaultOllamaURL {
+		t.Errorf("baseURL = %q, want %q", service.baseURL, DefaultOllamaURL)
+	}
+	var _ LLMService = service
+}
+
+func TestOllamaInvokeWithPrompt_Success(t *testing.T) {
+	var got ollamaRequest
+	service := newTestOllamaService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %s, want /api/chat", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"model":"llama3.1","message":{"role":"assistant","content":"Hello from llama"},"done":true}`))
+	})
+
+	result, err := service.InvokeWithPrompt(context.Background(), "system", "user")
+	if err != nil {
+		t.Fatalf("InvokeWithPrompt() error = %v", err)
+	}
+	if result != "Hello from llama" {
+		t.Errorf("result = %q, want the message content", result)
+	}
+	if got.Model != "llama3.1" || got.Stream || got.Format != "" || got.Options.NumPredict != 4096 {
+		t.Errorf("request = %+v, want a non-streaming text request with the token limit", got)
+	}
+	if len(got.Messages) != 2 || got.Messages[0].Role != "system" || got.Messages[1].Content != "user" {
+		t.Errorf("messages = %+v, want the system and user prompts", got.Messages)
+	}
+}
+
+func TestOllamaInvokeStructured_RequestsJSON(t *testing.T) {
+	var got ollamaRequest
+	service := newTestOllamaService(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"message":{"role":"assistant","content":"{\"score\": 7}"}}`))
+	})
+
+	var result struct {
+		Score int `json:"score"`
+	}
+	if err := service.InvokeStructured(context.Background(), "system", "user", &result); err != nil {
+		t.Fatalf("InvokeStructured() error = %v", err)
+	}
+	if got.Format != "json" || result.Score != 7 {
+		t.Errorf("format = %q, score = %d; want json and 7", got.Format, result.Score)
+	}
+}
+
+func TestOllamaInvokeWithPrompt_ModelNotFound(t *testing.T) {
+	service := newTestOllamaService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"model \"llama3.1\" not found, try pulling it first"}`))
+	})
+
+	_, err := service.InvokeWithPrompt(context.Background(), "system", "user")
+	if err == nil || !strings.Contains(err.Error(), "try pulling it first") {
+		t.Errorf("error = %v, want Ollama's message", err)
+	}
+}
+
+func TestOllamaChat_MessageRoles(t *testing.T) {
+	var got ollamaRequest
+	service := newTestOllamaService(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"message":{"role":"assistant","content":"ok"}}`))
+	})
+
+	messages := []ChatMessage{
+		{Role: "user", Content: "first"},
+		{Role: "assistant", Content: "reply"},
+		{Role: "tool", Content: "dropped"},
+	}
+	if _, err := service.Chat(context.Background(), "system", messages); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if len(got.Messages) != 3 || got.Messages[2].Role != "assistant" {
+		t.Errorf("messages = %+v, want system, user and assistant turns", got.Messages)
+	}
+}
+
+func TestOllamaChatStream(t *testing.T) {
+	var got ollamaRequest
+	service := newTestOllamaService(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"message":{"role":"assistant","content":"Apple "},"done":false}
+{"message":{"role":"assistant","content":"looks strong"},"done":false}
+{"message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":200,"eval_count":25}
+`))
+	})
+
+	ctx, tally := WithLLMUsage(context.Background())
+	var deltas []string
+	result, err := service.ChatStream(ctx, "system", []ChatMessage{{Role: "user", Content: "How is AAPL?"}}, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if !got.Stream || len(got.Messages) != 2 {
+		t.Errorf("request = %+v, want a streamed request with the conversation", got)
+	}
+	if result != "Apple looks strong" || len(deltas) != 2 {
+		t.Errorf("result = %q, deltas = %q, want the reply passed on piece by piece", result, deltas)
+	}
+	if calls := tally.Calls(); len(calls) != 1 || calls[0].InputTokens != 200 || calls[0].OutputTokens != 25 {
+		t.Errorf("calls = %+v, want the final line's token counts", calls)
+	}
+}
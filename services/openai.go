@@ -2,7 +2,7 @@ package services
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 
 	appconfig "trade-machine/config"
@@ -16,6 +16,10 @@ import (
 // openaiClient defines the interface for OpenAI API calls (for testing)
 type openaiClient interface {
 	CreateChatCompletion(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error)
+	CreateEmbedding(ctx context.Context, params openai.EmbeddingNewParams) (*openai.CreateEmbeddingResponse, error)
+	// StreamChatCompletion calls onChunk with each chunk of a streamed completion until the
+	// stream ends or onChunk fails
+	StreamChatCompletion(ctx context.Context, params openai.ChatCompletionNewParams, onChunk func(openai.ChatCompletionChunk) error) error
 }
 
 // openaiClientWrapper wraps the openai.Client to implement our interface
@@ -27,11 +31,28 @@ func (w *openaiClientWrapper) CreateChatCompletion(ctx context.Context, params o
 	return w.client.Chat.Completions.New(ctx, params)
 }
 
+func (w *openaiClientWrapper) CreateEmbedding(ctx context.Context, params openai.EmbeddingNewParams) (*openai.CreateEmbeddingResponse, error) {
+	return w.client.Embeddings.New(ctx, params)
+}
+
+func (w *openaiClientWrapper) StreamChatCompletion(ctx context.Context, params openai.ChatCompletionNewParams, onChunk func(openai.ChatCompletionChunk) error) error {
+	stream := w.client.Chat.Completions.NewStreaming(ctx, params)
+	defer stream.Close()
+	for stream.Next() {
+		if err := onChunk(stream.Current()); err != nil {
+			return err
+		}
+	}
+	return stream.Err()
+}
+
 // OpenAIService handles communication with OpenAI API
 type OpenAIService struct {
-	client    openaiClient
-	model     string
-	maxTokens int
+	client              openaiClient
+	model               string
+	maxTokens           int
+	embeddingModel      string
+	embeddingDimensions int
 }
 
 // NewOpenAIService creates a new OpenAIService instance
@@ -40,12 +61,18 @@ func NewOpenAIService(cfg *appconfig.Config) (*OpenAIService, error) {
 		return nil, fmt.Errorf("OPENAI_API_KEY is required")
 	}
 
-	client := openai.NewClient(option.WithAPIKey(cfg.OpenAI.APIKey))
+	client := openai.NewClient(
+		option.WithAPIKey(cfg.OpenAI.APIKey),
+		option.WithHTTPClient(newLedgerHTTPClient(BreakerOpenAI, 0)),
+		option.WithMaxRetries(0), // Retried under the shared RetryPolicy instead
+	)
 
 	return &OpenAIService{
-		client:    &openaiClientWrapper{client: client},
-		model:     cfg.OpenAI.Model,
-		maxTokens: cfg.OpenAI.MaxTokens,
+		client:              &openaiClientWrapper{client: client},
+		model:               cfg.OpenAI.Model,
+		maxTokens:           cfg.OpenAI.MaxTokens,
+		embeddingModel:      cfg.OpenAI.EmbeddingModel,
+		embeddingDimensions: cfg.OpenAI.EmbeddingDimensions,
 	}, nil
 }
 
@@ -58,6 +85,25 @@ func newOpenAIServiceWithClient(client openaiClient, model string, maxTokens int
 	}
 }
 
+// openaiRequest runs an OpenAI request under the active retry policy, reporting API
+// errors as StatusErrors so rejected requests aren't retried or counted against the
+// circuit breaker
+func openaiRequest[T any](ctx context.Context, call func() (T, error)) (T, error) {
+	return Retry(ctx, func() (T, error) {
+		result, err := call()
+		return result, openaiError(err)
+	})
+}
+
+// openaiError reports an OpenAI API error as a StatusError, leaving other errors as they are
+func openaiError(err error) error {
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		return &StatusError{Provider: "OpenAI", StatusCode: apiErr.StatusCode, Message: apiErr.Message}
+	}
+	return err
+}
+
 // InvokeWithPrompt sends a prompt to OpenAI and returns the response text
 func (s *OpenAIService) InvokeWithPrompt(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
 	metrics := observability.GetMetrics()
@@ -66,7 +112,7 @@ func (s *OpenAIService) InvokeWithPrompt(ctx context.Context, systemPrompt, user
 
 	result, err := WithCircuitBreaker(ctx, BreakerOpenAI, func() (string, error) {
 		params := openai.ChatCompletionNewParams{
-			Model:     shared.ChatModel(s.model),
+			Model:     shared.ChatModel(ModelFromContext(ctx, s.model)),
 			MaxTokens: openai.Int(int64(s.maxTokens)),
 			Messages: []openai.ChatCompletionMessageParamUnion{
 				openai.SystemMessage(systemPrompt),
@@ -74,10 +120,14 @@ func (s *OpenAIService) InvokeWithPrompt(ctx context.Context, systemPrompt, user
 			},
 		}
 
-		completion, err := s.client.CreateChatCompletion(ctx, params)
+		completion, err := openaiRequest(ctx, func() (*openai.ChatCompletion, error) {
+			return s.client.CreateChatCompletion(ctx, params)
+		})
 		if err != nil {
 			return "", fmt.Errorf("failed to invoke OpenAI: %w", err)
 		}
+		metrics.RecordLLMTokens(BreakerOpenAI, string(params.Model), completion.Usage.PromptTokens, completion.Usage.CompletionTokens)
+		RecordLLMUsage(ctx, BreakerOpenAI, string(params.Model), completion.Usage.PromptTokens, completion.Usage.CompletionTokens)
 
 		if len(completion.Choices) == 0 {
 			return "", fmt.Errorf("empty response from OpenAI")
@@ -100,11 +150,7 @@ func (s *OpenAIService) InvokeStructured(ctx context.Context, systemPrompt, user
 		return err
 	}
 
-	if err := json.Unmarshal([]byte(text), result); err != nil {
-		return fmt.Errorf("failed to parse response as JSON: %w", err)
-	}
-
-	return nil
+	return ParseStructuredOutput(text, result)
 }
 
 // Chat enables multi-turn conversation with OpenAI
@@ -114,28 +160,20 @@ func (s *OpenAIService) Chat(ctx context.Context, systemPrompt string, messages
 	timer := metrics.NewTimer()
 
 	result, err := WithCircuitBreaker(ctx, BreakerOpenAI, func() (string, error) {
-		openaiMessages := make([]openai.ChatCompletionMessageParamUnion, 0, len(messages)+1)
-		openaiMessages = append(openaiMessages, openai.SystemMessage(systemPrompt))
-
-		for _, msg := range messages {
-			switch msg.Role {
-			case "user":
-				openaiMessages = append(openaiMessages, openai.UserMessage(msg.Content))
-			case "assistant":
-				openaiMessages = append(openaiMessages, openai.AssistantMessage(msg.Content))
-			}
-		}
-
 		params := openai.ChatCompletionNewParams{
-			Model:     shared.ChatModel(s.model),
+			Model:     shared.ChatModel(ModelFromContext(ctx, s.model)),
 			MaxTokens: openai.Int(int64(s.maxTokens)),
-			Messages:  openaiMessages,
+			Messages:  openaiChatMessages(systemPrompt, messages),
 		}
 
-		completion, err := s.client.CreateChatCompletion(ctx, params)
+		completion, err := openaiRequest(ctx, func() (*openai.ChatCompletion, error) {
+			return s.client.CreateChatCompletion(ctx, params)
+		})
 		if err != nil {
 			return "", fmt.Errorf("failed to invoke OpenAI: %w", err)
 		}
+		metrics.RecordLLMTokens(BreakerOpenAI, string(params.Model), completion.Usage.PromptTokens, completion.Usage.CompletionTokens)
+		RecordLLMUsage(ctx, BreakerOpenAI, string(params.Model), completion.Usage.PromptTokens, completion.Usage.CompletionTokens)
 
 		if len(completion.Choices) == 0 {
 			return "", fmt.Errorf("empty response from OpenAI")
@@ -151,6 +189,126 @@ func (s *OpenAIService) Chat(ctx context.Context, systemPrompt string, messages
 	return result, err
 }
 
+// ChatStream holds a conversation with OpenAI, passing the reply to onDelta as it streams
+// in. Only failures before any of the reply has been passed on are retried.
+func (s *OpenAIService) ChatStream(ctx context.Context, systemPrompt string, messages []ChatMessage, onDelta func(delta string) error) (string, error) {
+	metrics := observability.GetMetrics()
+	metrics.RecordExternalAPIRequest(BreakerOpenAI, "chat_stream")
+	timer := metrics.NewTimer()
+
+	result, err := WithCircuitBreaker(ctx, BreakerOpenAI, func() (string, error) {
+		params := openai.ChatCompletionNewParams{
+			Model:     shared.ChatModel(ModelFromContext(ctx, s.model)),
+			MaxTokens: openai.Int(int64(s.maxTokens)),
+			Messages:  openaiChatMessages(systemPrompt, messages),
+			// The last chunk then carries the token counts
+			StreamOptions: openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)},
+		}
+
+		return Retry(ctx, func() (string, error) {
+			stream := &deltaStream{onDelta: onDelta}
+			var usage openai.CompletionUsage
+			err := s.client.StreamChatCompletion(ctx, params, func(chunk openai.ChatCompletionChunk) error {
+				if chunk.Usage.TotalTokens > 0 {
+					usage = chunk.Usage
+				}
+				for _, choice := range chunk.Choices {
+					if err := stream.send(choice.Delta.Content); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return "", stream.fail(fmt.Errorf("failed to stream from OpenAI: %w", openaiError(err)))
+			}
+			metrics.RecordLLMTokens(BreakerOpenAI, string(params.Model), usage.PromptTokens, usage.CompletionTokens)
+			RecordLLMUsage(ctx, BreakerOpenAI, string(params.Model), usage.PromptTokens, usage.CompletionTokens)
+
+			if stream.text.Len() == 0 {
+				return "", fmt.Errorf("empty response from OpenAI")
+			}
+			return stream.text.String(), nil
+		})
+	})
+
+	timer.ObserveExternalAPI(BreakerOpenAI, "chat_stream")
+	if err != nil {
+		metrics.RecordExternalAPIError(BreakerOpenAI, "chat_stream", categorizeAPIError(err))
+	}
+	return result, err
+}
+
+// openaiChatMessages converts a conversation to OpenAI messages after the system prompt,
+// dropping messages with roles other than user and assistant
+func openaiChatMessages(systemPrompt string, messages []ChatMessage) []openai.ChatCompletionMessageParamUnion {
+	openaiMessages := make([]openai.ChatCompletionMessageParamUnion, 0, len(messages)+1)
+	openaiMessages = append(openaiMessages, openai.SystemMessage(systemPrompt))
+	for _, msg := range messages {
+		switch msg.Role {
+		case "user":
+			openaiMessages = append(openaiMessages, openai.UserMessage(msg.Content))
+		case "assistant":
+			openaiMessages = append(openaiMessages, openai.AssistantMessage(msg.Content))
+		}
+	}
+	return openaiMessages
+}
+
+// Embed returns an embedding vector for each text, in order. The dimensions are only
+// requested when OPENAI_EMBEDDING_DIMENSIONS is set, since models that can't shorten
+// embeddings reject the parameter.
+func (s *OpenAIService) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	metrics := observability.GetMetrics()
+	metrics.RecordExternalAPIRequest(BreakerOpenAI, "embed")
+	timer := metrics.NewTimer()
+
+	params := openai.EmbeddingNewParams{
+		Input: openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: texts},
+		Model: openai.EmbeddingModel(s.embeddingModel),
+	}
+	if s.embeddingDimensions > 0 {
+		params.Dimensions = openai.Int(int64(s.embeddingDimensions))
+	}
+
+	result, err := WithCircuitBreaker(ctx, BreakerOpenAI, func() ([][]float32, error) {
+		resp, err := openaiRequest(ctx, func() (*openai.CreateEmbeddingResponse, error) {
+			return s.client.CreateEmbedding(ctx, params)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create embeddings: %w", err)
+		}
+		metrics.RecordLLMTokens(BreakerOpenAI, s.embeddingModel, resp.Usage.PromptTokens, 0)
+		if len(resp.Data) != len(texts) {
+			return nil, fmt.Errorf("got %d embeddings for %d texts", len(resp.Data), len(texts))
+		}
+
+		vectors := make([][]float32, len(texts))
+		for _, d := range resp.Data {
+			if d.Index < 0 || int(d.Index) >= len(texts) {
+				return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+			}
+			vector := make([]float32, len(d.Embedding))
+			for i, v := range d.Embedding {
+				vector[i] = float32(v)
+			}
+			vectors[d.Index] = vector
+		}
+		return vectors, nil
+	})
+
+	timer.ObserveExternalAPI(BreakerOpenAI, "embed")
+	if err != nil {
+		metrics.RecordExternalAPIError(BreakerOpenAI, "embed", categorizeAPIError(err))
+	}
+	return result, err
+}
+
+// EmbeddingModel returns the model embeddings are created with
+func (s *OpenAIService) EmbeddingModel() string {
+	return s.embeddingModel
+}
+
 // categorizeAPIError categorizes an error for metrics purposes
 func categorizeAPIError(err error) string {
 	if err == nil {
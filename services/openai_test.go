@@ -14,12 +14,22 @@ import (
 // mockOpenAIClient implements openaiClient for testing
 type mockOpenAIClient struct {
 	completionFunc func(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error)
+	embeddingFunc  func(ctx context.Context, params openai.EmbeddingNewParams) (*openai.CreateEmbeddingResponse, error)
+	streamFunc     func(ctx context.Context, params openai.ChatCompletionNewParams, onChunk func(openai.ChatCompletionChunk) error) error
 }
 
 func (m *mockOpenAIClient) CreateChatCompletion(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
 	return m.completionFunc(ctx, params)
 }
 
+func (m *mockOpenAIClient) CreateEmbedding(ctx context.Context, params openai.EmbeddingNewParams) (*openai.CreateEmbeddingResponse, error) {
+	return m.embeddingFunc(ctx, params)
+}
+
+func (m *mockOpenAIClient) StreamChatCompletion(ctx context.Context, params openai.ChatCompletionNewParams, onChunk func(openai.ChatCompletionChunk) error) error {
+	return m.streamFunc(ctx, params, onChunk)
+}
+
 func newTestOpenAIService(client openaiClient) *OpenAIService {
 	return &OpenAIService{
 		client:    client,
@@ -118,6 +128,32 @@ func TestOpenAIInvokeWithPrompt_Success(t *testing.T) {
 	}
 }
 
+func TestOpenAIInvokeWithPrompt_ModelOverride(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	var models []string
+	mockClient := &mockOpenAIClient{
+		completionFunc: func(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+			models = append(models, string(params.Model))
+			return &openai.ChatCompletion{
+				Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "ok"}}},
+			}, nil
+		},
+	}
+	service := newOpenAIServiceWithClient(mockClient, "gpt-4o", 1024)
+
+	if _, err := service.InvokeWithPrompt(context.Background(), "sys", "user"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.InvokeWithPrompt(WithModel(context.Background(), "gpt-4o-mini"), "sys", "user"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(models) != 2 || models[0] != "gpt-4o" || models[1] != "gpt-4o-mini" {
+		t.Errorf("models = %v, want [gpt-4o gpt-4o-mini]", models)
+	}
+}
+
 func TestOpenAIInvokeWithPrompt_APIError(t *testing.T) {
 	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
 
@@ -390,3 +426,143 @@ func TestOpenAIChat_MessageRoleConversion(t *testing.T) {
 		})
 	}
 }
+
+func TestOpenAIService_Embed(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	client := &mockOpenAIClient{
+		embeddingFunc: func(ctx context.Context, params openai.EmbeddingNewParams) (*openai.CreateEmbeddingResponse, error) {
+			if params.Model != "text-embedding-3-small" {
+				t.Errorf("Model = %q", params.Model)
+			}
+			if params.Dimensions.Valid() {
+				t.Errorf("Dimensions = %v, want none sent by default", params.Dimensions.Value)
+			}
+			// Returned out of order; vectors must follow the input order
+			return &openai.CreateEmbeddingResponse{Data: []openai.Embedding{
+				{Index: 1, Embedding: []float64{0, 1}},
+				{Index: 0, Embedding: []float64{1, 0}},
+			}}, nil
+		},
+	}
+	service := newTestOpenAIService(client)
+	service.embeddingModel = "text-embedding-3-small"
+
+	vectors, err := service.Embed(context.Background(), []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("vectors = %v, want input order", vectors)
+	}
+}
+
+func TestOpenAIService_Embed_Dimensions(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	var dimensions int64
+	client := &mockOpenAIClient{
+		embeddingFunc: func(ctx context.Context, params openai.EmbeddingNewParams) (*openai.CreateEmbeddingResponse, error) {
+			dimensions = params.Dimensions.Value
+			return &openai.CreateEmbeddingResponse{Data: []openai.Embedding{{Embedding: []float64{1}}}}, nil
+		},
+	}
+	service := newTestOpenAIService(client)
+	service.embeddingDimensions = 1536
+
+	if _, err := service.Embed(context.Background(), []string{"text"}); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if dimensions != 1536 {
+		t.Errorf("Dimensions = %d, want the configured 1536", dimensions)
+	}
+}
+
+func TestOpenAIService_Embed_CountMismatch(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	client := &mockOpenAIClient{
+		embeddingFunc: func(ctx context.Context, params openai.EmbeddingNewParams) (*openai.CreateEmbeddingResponse, error) {
+			return &openai.CreateEmbeddingResponse{}, nil
+		},
+	}
+
+	if _, err := newTestOpenAIService(client).Embed(context.Background(), []string{"text"}); err == nil {
+		t.Error("expected an error when embeddings are missing")
+	}
+}
+
+// textChunk is a streamed completion chunk carrying a piece of the reply
+func textChunk(text string) openai.ChatCompletionChunk {
+	return openai.ChatCompletionChunk{Choices: []openai.ChatCompletionChunkChoice{{Delta: openai.ChatCompletionChunkChoiceDelta{Content: text}}}}
+}
+
+func TestOpenAIChatStream(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	var params openai.ChatCompletionNewParams
+	mockClient := &mockOpenAIClient{
+		streamFunc: func(ctx context.Context, p openai.ChatCompletionNewParams, onChunk func(openai.ChatCompletionChunk) error) error {
+			params = p
+			for _, chunk := range []openai.ChatCompletionChunk{
+				textChunk("Apple "),
+				textChunk("looks strong"),
+				{Usage: openai.CompletionUsage{PromptTokens: 300, CompletionTokens: 40, TotalTokens: 340}},
+			} {
+				if err := onChunk(chunk); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	service := newTestOpenAIService(mockClient)
+
+	ctx, tally := WithLLMUsage(context.Background())
+	var deltas []string
+	result, err := service.ChatStream(ctx, "system", []ChatMessage{{Role: "user", Content: "How is AAPL?"}}, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if result != "Apple looks strong" || len(deltas) != 2 || deltas[0] != "Apple " {
+		t.Errorf("result = %q, deltas = %q, want the reply passed on piece by piece", result, deltas)
+	}
+	if len(params.Messages) != 2 || !params.StreamOptions.IncludeUsage.Value {
+		t.Errorf("params = %+v, want the system and user messages with usage included", params)
+	}
+	want := LLMCall{Provider: BreakerOpenAI, Model: "gpt-4o", InputTokens: 300, OutputTokens: 40}
+	if calls := tally.Calls(); len(calls) != 1 || calls[0] != want {
+		t.Errorf("calls = %+v, want %+v", calls, want)
+	}
+}
+
+func TestOpenAIChatStream_BrokenOffIsNotRetried(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	attempts := 0
+	mockClient := &mockOpenAIClient{
+		streamFunc: func(ctx context.Context, p openai.ChatCompletionNewParams, onChunk func(openai.ChatCompletionChunk) error) error {
+			attempts++
+			if err := onChunk(textChunk("Apple ")); err != nil {
+				return err
+			}
+			return errors.New("connection reset")
+		},
+	}
+	service := newTestOpenAIService(mockClient)
+
+	var streamed strings.Builder
+	_, err := service.ChatStream(context.Background(), "system", nil, func(delta string) error {
+		streamed.WriteString(delta)
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "connection reset") {
+		t.Errorf("ChatStream() error = %v, want the stream failure", err)
+	}
+	if attempts != 1 || streamed.String() != "Apple " {
+		t.Errorf("%d attempts streamed %q, want one attempt so the reply isn't repeated", attempts, streamed.String())
+	}
+}
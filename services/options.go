@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"trade-machine/models"
+
+	"cloud.google.com/go/civil"
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+)
+
+// alpacaOptionsClient defines the Alpaca option chain request (for testing)
+type alpacaOptionsClient interface {
+	GetOptionChain(underlyingSymbol string, req marketdata.GetOptionChainRequest) (map[string]marketdata.OptionSnapshot, error)
+}
+
+// AlpacaOptionsService reads option chains from Alpaca's options market data
+type AlpacaOptionsService struct {
+	client alpacaOptionsClient
+	feed   marketdata.OptionFeed
+}
+
+// NewAlpacaOptionsService creates a new AlpacaOptionsService. feed is indicative, free
+// but delayed with estimated greeks, or opra, which needs a market data subscription.
+func NewAlpacaOptionsService(apiKey, apiSecret, feed string) *AlpacaOptionsService {
+	return &AlpacaOptionsService{
+		client: marketdata.NewClient(marketdata.ClientOpts{
+			APIKey:     apiKey,
+			APISecret:  apiSecret,
+			HTTPClient: newLedgerHTTPClient(BreakerAlpaca, 10*time.Second),
+		}),
+		feed: feed,
+	}
+}
+
+// GetOptionChain returns the contracts of an underlying's chain that match the filter
+// with their latest quotes, by expiration and then strike. Contracts whose symbols can't
+// be parsed are skipped.
+func (s *AlpacaOptionsService) GetOptionChain(ctx context.Context, underlying string, filter models.OptionChainFilter) ([]models.OptionContract, error) {
+	req := marketdata.GetOptionChainRequest{
+		Feed:           s.feed,
+		Type:           string(filter.Type),
+		StrikePriceGte: filter.MinStrike,
+		StrikePriceLte: filter.MaxStrike,
+	}
+	if !filter.ExpiresAfter.IsZero() {
+		req.ExpirationDateGte = civil.DateOf(filter.ExpiresAfter)
+	}
+	if !filter.ExpiresBefore.IsZero() {
+		req.ExpirationDateLte = civil.DateOf(filter.ExpiresBefore)
+	}
+
+	return WithCircuitBreaker(ctx, BreakerAlpaca, func() ([]models.OptionContract, error) {
+		snapshots, err := alpacaRead(ctx, func() (map[string]marketdata.OptionSnapshot, error) {
+			return s.client.GetOptionChain(underlying, req)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get option chain for %s: %w", underlying, err)
+		}
+
+		contracts := make([]models.OptionContract, 0, len(snapshots))
+		for symbol, snapshot := range snapshots {
+			contract, err := models.ParseOptionSymbol(symbol)
+			if err != nil {
+				logger.Warn("skipping option contract", "underlying", underlying, "error", err)
+				continue
+			}
+			contract.Underlying = underlying
+			if q := snapshot.LatestQuote; q != nil {
+				contract.Bid, contract.Ask = q.BidPrice, q.AskPrice
+			}
+			if t := snapshot.LatestTrade; t != nil {
+				contract.Last = t.Price
+			}
+			contract.ImpliedVolatility = snapshot.ImpliedVolatility
+			if g := snapshot.Greeks; g != nil {
+				delta := g.Delta
+				contract.Delta = &delta
+			}
+			contracts = append(contracts, contract)
+		}
+
+		sort.Slice(contracts, func(i, j int) bool {
+			if !contracts[i].Expiration.Equal(contracts[j].Expiration) {
+				return contracts[i].Expiration.Before(contracts[j].Expiration)
+			}
+			return contracts[i].Strike < contracts[j].Strike
+		})
+		return contracts, nil
+	})
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+
+	"cloud.google.com/go/civil"
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+)
+
+type mockAlpacaOptionsClient struct {
+	req       marketdata.GetOptionChainRequest
+	snapshots map[string]marketdata.OptionSnapshot
+}
+
+func (m *mockAlpacaOptionsClient) GetOptionChain(underlyingSymbol string, req marketdata.GetOptionChainRequest) (map[string]marketdata.OptionSnapshot, error) {
+	m.req = req
+	return m.snapshots, nil
+}
+
+func TestAlpacaOptionsService_GetOptionChain(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	client := &mockAlpacaOptionsClient{snapshots: map[string]marketdata.OptionSnapshot{
+		"AAPL240621C00200000": {
+			LatestQuote:       &marketdata.OptionQuote{BidPrice: 1.10, AskPrice: 1.20},
+			ImpliedVolatility: 0.24,
+			Greeks:            &marketdata.OptionGreeks{Delta: 0.31},
+		},
+		"AAPL240517C00195000": {LatestTrade: &marketdata.OptionTrade{Price: 2.05}},
+		"AAPL240621C00190000": {},
+		"garbage":             {},
+	}}
+	service := &AlpacaOptionsService{client: client, feed: marketdata.Indicative}
+
+	filter := models.OptionChainFilter{
+		Type:          models.OptionTypeCall,
+		MinStrike:     190,
+		ExpiresAfter:  time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC),
+		ExpiresBefore: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC),
+	}
+	contracts, err := service.GetOptionChain(context.Background(), "AAPL", filter)
+	if err != nil {
+		t.Fatalf("GetOptionChain error = %v", err)
+	}
+
+	if client.req.Type != "call" || client.req.StrikePriceGte != 190 || client.req.StrikePriceLte != 0 || client.req.Feed != marketdata.Indicative {
+		t.Errorf("request = %+v, want the filter's type, strike and the configured feed", client.req)
+	}
+	if client.req.ExpirationDateGte != (civil.Date{Year: 2024, Month: 5, Day: 1}) || client.req.ExpirationDateLte != (civil.Date{Year: 2024, Month: 6, Day: 30}) {
+		t.Errorf("expiration range = %v to %v, want the filter's dates", client.req.ExpirationDateGte, client.req.ExpirationDateLte)
+	}
+
+	if len(contracts) != 3 {
+		t.Fatalf("got %d contracts, want the three with valid symbols", len(contracts))
+	}
+	if contracts[0].Symbol != "AAPL240517C00195000" || contracts[1].Strike != 190 || contracts[2].Strike != 200 {
+		t.Errorf("contracts = %+v, want them by expiration then strike", contracts)
+	}
+	if contracts[0].Last != 2.05 || contracts[0].Delta != nil {
+		t.Errorf("contracts[0] = %+v, want the last trade and no delta", contracts[0])
+	}
+	if c := contracts[2]; c.Bid != 1.10 || c.Ask != 1.20 || c.ImpliedVolatility != 0.24 || c.Delta == nil || *c.Delta != 0.31 {
+		t.Errorf("contracts[2] = %+v, want its quote, volatility and delta", c)
+	}
+}
@@ -0,0 +1,560 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	appconfig "trade-machine/config"
+	"trade-machine/models"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+// ErrNotConfigured is returned by keyed clients when no API key resolves for the request
+var ErrNotConfigured = errors.New("API key not configured")
+
+// maxCachedClients bounds the clients kept per provider; the least recently used one is
+// evicted when exceeded
+const maxCachedClients = 64
+
+// cachedClient is a client in the provider's LRU list under its credentials key
+type cachedClient struct {
+	key    string
+	client any
+}
+
+// Credentials are the API keys a client is constructed with
+type Credentials struct {
+	APIKey    string
+	APISecret string
+	BaseURL   string
+	Model     string
+}
+
+// KeyResolver returns the credentials for a service (identified by its breaker name)
+// in the given context, or false when the service has no key configured
+type KeyResolver func(ctx context.Context, service string) (Credentials, bool)
+
+// ClientProvider resolves API clients per request context instead of once at startup.
+// Clients are built lazily from the resolved credentials and reused while the
+// credentials stay the same, so keys changed in settings take effect on the next call
+// and each user's stored keys get their own client.
+type ClientProvider struct {
+	cfg     *appconfig.Config
+	resolve KeyResolver
+
+	mu      sync.Mutex
+	clients map[string]*list.Element // Elements of lru
+	lru     *list.List               // Most recently used first
+}
+
+// NewClientProvider creates a provider resolving credentials with resolve. cfg supplies
+// the non-secret client settings such as the OpenAI model and token limit.
+func NewClientProvider(cfg *appconfig.Config, resolve KeyResolver) *ClientProvider {
+	return &ClientProvider{
+		cfg:     cfg,
+		resolve: resolve,
+		clients: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Configured reports whether a service has credentials in the given context
+func (p *ClientProvider) Configured(ctx context.Context, service string) bool {
+	_, ok := p.resolve(ctx, service)
+	return ok
+}
+
+// resolveClient returns the cached client for the credentials resolved from ctx,
+// building one on first use
+func resolveClient[T any](ctx context.Context, p *ClientProvider, service string, build func(Credentials) (T, error)) (T, error) {
+	var zero T
+	creds, ok := p.resolve(ctx, service)
+	if !ok {
+		return zero, fmt.Errorf("%s: %w", service, ErrNotConfigured)
+	}
+
+	// The client type is part of the key since one service's credentials can back several clients
+	key := fmt.Sprintf("%T", zero) + "\x00" + service + "\x00" + creds.APIKey + "\x00" + creds.APISecret + "\x00" + creds.BaseURL + "\x00" + creds.Model
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if elem, ok := p.clients[key]; ok {
+		if client, ok := elem.Value.(*cachedClient).client.(T); ok {
+			p.lru.MoveToFront(elem)
+			return client, nil
+		}
+	}
+
+	client, err := build(creds)
+	if err != nil {
+		return zero, err
+	}
+	if p.lru.Len() >= maxCachedClients {
+		oldest := p.lru.Back()
+		delete(p.clients, oldest.Value.(*cachedClient).key)
+		p.lru.Remove(oldest)
+	}
+	p.clients[key] = p.lru.PushFront(&cachedClient{key: key, client: client})
+	return client, nil
+}
+
+func (p *ClientProvider) openAI(ctx context.Context) (*OpenAIService, error) {
+	return resolveClient(ctx, p, BreakerOpenAI, func(creds Credentials) (*OpenAIService, error) {
+		cfg := *p.cfg
+		cfg.OpenAI.APIKey = creds.APIKey
+		if creds.Model != "" {
+			cfg.OpenAI.Model = creds.Model
+		}
+		return NewOpenAIService(&cfg)
+	})
+}
+
+func (p *ClientProvider) anthropic(ctx context.Context) (*AnthropicService, error) {
+	return resolveClient(ctx, p, BreakerAnthropic, func(creds Credentials) (*AnthropicService, error) {
+		cfg := *p.cfg
+		cfg.Anthropic.APIKey = creds.APIKey
+		if creds.BaseURL != "" {
+			cfg.Anthropic.BaseURL = creds.BaseURL
+		}
+		if creds.Model != "" {
+			cfg.Anthropic.Model = creds.Model
+		}
+		return NewAnthropicService(&cfg)
+	})
+}
+
+func (p *ClientProvider) ollama(ctx context.Context) (*OllamaService, error) {
+	return resolveClient(ctx, p, BreakerOllama, func(creds Credentials) (*OllamaService, error) {
+		cfg := *p.cfg
+		cfg.Ollama.BaseURL = creds.BaseURL
+		if creds.Model != "" {
+			cfg.Ollama.Model = creds.Model
+		}
+		return NewOllamaService(&cfg), nil
+	})
+}
+
+// LLMProvider returns the service (identified by its breaker name) that answers LLM
+// calls in ctx: the one LLM_PROVIDER names, otherwise the first configured of OpenAI,
+// Anthropic and Ollama, falling back to OpenAI
+func (p *ClientProvider) LLMProvider(ctx context.Context) string {
+	switch p.cfg.LLM.Provider {
+	case BreakerOpenAI, BreakerAnthropic, BreakerOllama:
+		return p.cfg.LLM.Provider
+	}
+	for _, service := range []string{BreakerOpenAI, BreakerAnthropic, BreakerOllama} {
+		if p.Configured(ctx, service) {
+			return service
+		}
+	}
+	return BreakerOpenAI
+}
+
+// llm resolves the client for an LLM provider, or the one LLMProvider picks for ctx when
+// service is empty
+func (p *ClientProvider) llm(ctx context.Context, service string) (LLMService, error) {
+	if service == "" {
+		service = p.LLMProvider(ctx)
+	}
+	var svc LLMService
+	var err error
+	switch service {
+	case BreakerAnthropic:
+		svc, err = p.anthropic(ctx)
+	case BreakerOllama:
+		svc, err = p.ollama(ctx)
+	default:
+		svc, err = p.openAI(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
+
+func (p *ClientProvider) alphaVantage(ctx context.Context) (*AlphaVantageService, error) {
+	return resolveClient(ctx, p, BreakerAlphaVantage, func(creds Credentials) (*AlphaVantageService, error) {
+		return NewAlphaVantageService(creds.APIKey), nil
+	})
+}
+
+func (p *ClientProvider) newsAPI(ctx context.Context) (*NewsAPIService, error) {
+	return resolveClient(ctx, p, BreakerNewsAPI, func(creds Credentials) (*NewsAPIService, error) {
+		return NewNewsAPIService(creds.APIKey), nil
+	})
+}
+
+func (p *ClientProvider) fmp(ctx context.Context) (*FMPService, error) {
+	return resolveClient(ctx, p, BreakerFMP, func(creds Credentials) (*FMPService, error) {
+		return NewFMPService(creds.APIKey), nil
+	})
+}
+
+func (p *ClientProvider) alpaca(ctx context.Context) (*AlpacaService, error) {
+	return resolveClient(ctx, p, BreakerAlpaca, func(creds Credentials) (*AlpacaService, error) {
+		baseURL := creds.BaseURL
+		if baseURL == "" {
+			baseURL = p.cfg.Alpaca.BaseURL
+		}
+		return NewAlpacaService(creds.APIKey, creds.APISecret, baseURL), nil
+	})
+}
+
+func (p *ClientProvider) alpacaOptions(ctx context.Context) (*AlpacaOptionsService, error) {
+	return resolveClient(ctx, p, BreakerAlpaca, func(creds Credentials) (*AlpacaOptionsService, error) {
+		return NewAlpacaOptionsService(creds.APIKey, creds.APISecret, p.cfg.Options.Feed), nil
+	})
+}
+
+// LLM returns an LLMService that routes each call per LLM_ROUTES and
+// LLM_FALLBACK_PROVIDER, resolving the client of the chosen provider on every call
+func (p *ClientProvider) LLM() LLMService {
+	providers := make(map[string]LLMService)
+	for _, service := range []string{BreakerOpenAI, BreakerAnthropic, BreakerOllama} {
+		providers[service] = keyedLLM{p: p, service: service}
+	}
+	return NewLLMRouter(p.cfg.LLM, providers, p.LLMProvider)
+}
+
+// Embedder returns an Embedder that resolves the OpenAI client on every call
+func (p *ClientProvider) Embedder() Embedder { return keyedLLM{p: p} }
+
+// AlphaVantage returns an Alpha Vantage client that resolves its key on every call
+func (p *ClientProvider) AlphaVantage() AlphaVantageServiceInterface { return keyedAlphaVantage{p} }
+
+// NewsAPI returns a NewsAPI client that resolves its key on every call
+func (p *ClientProvider) NewsAPI() NewsAPIServiceInterface { return keyedNewsAPI{p} }
+
+// FMP returns an FMP client that resolves its key on every call
+func (p *ClientProvider) FMP() FMPServiceInterface { return keyedFMP{p} }
+
+// Alpaca returns an Alpaca client that resolves its keys on every call
+func (p *ClientProvider) Alpaca() *KeyedAlpaca { return &KeyedAlpaca{p} }
+
+// keyedLLM resolves the client of one LLM provider, or of the default provider when
+// service is empty
+type keyedLLM struct {
+	p       *ClientProvider
+	service string
+}
+
+func (k keyedLLM) InvokeWithPrompt(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	svc, err := k.p.llm(ctx, k.service)
+	if err != nil {
+		return "", err
+	}
+	return svc.InvokeWithPrompt(ctx, systemPrompt, userPrompt)
+}
+
+func (k keyedLLM) InvokeStructured(ctx context.Context, systemPrompt, userPrompt string, result interface{}) error {
+	svc, err := k.p.llm(ctx, k.service)
+	if err != nil {
+		return err
+	}
+	return svc.InvokeStructured(ctx, systemPrompt, userPrompt, result)
+}
+
+func (k keyedLLM) Chat(ctx context.Context, systemPrompt string, messages []ChatMessage) (string, error) {
+	svc, err := k.p.llm(ctx, k.service)
+	if err != nil {
+		return "", err
+	}
+	return svc.Chat(ctx, systemPrompt, messages)
+}
+
+func (k keyedLLM) ChatStream(ctx context.Context, systemPrompt string, messages []ChatMessage, onDelta func(delta string) error) (string, error) {
+	svc, err := k.p.llm(ctx, k.service)
+	if err != nil {
+		return "", err
+	}
+	return svc.ChatStream(ctx, systemPrompt, messages, onDelta)
+}
+
+func (k keyedLLM) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	svc, err := k.p.openAI(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.Embed(ctx, texts)
+}
+
+func (k keyedLLM) EmbeddingModel() string {
+	return k.p.cfg.OpenAI.EmbeddingModel
+}
+
+type keyedAlphaVantage struct{ p *ClientProvider }
+
+func (k keyedAlphaVantage) GetFundamentals(ctx context.Context, symbol string) (*models.Fundamentals, error) {
+	svc, err := k.p.alphaVantage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetFundamentals(ctx, symbol)
+}
+
+func (k keyedAlphaVantage) GetTotalDebt(ctx context.Context, symbol string) (decimal.Decimal, error) {
+	svc, err := k.p.alphaVantage(ctx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return svc.GetTotalDebt(ctx, symbol)
+}
+
+func (k keyedAlphaVantage) GetNews(ctx context.Context, symbol string) ([]models.NewsArticle, error) {
+	svc, err := k.p.alphaVantage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetNews(ctx, symbol)
+}
+
+func (k keyedAlphaVantage) GetQuote(ctx context.Context, symbol string) (*models.Quote, error) {
+	svc, err := k.p.alphaVantage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetQuote(ctx, symbol)
+}
+
+type keyedNewsAPI struct{ p *ClientProvider }
+
+func (k keyedNewsAPI) GetNews(ctx context.Context, query string, limit int) ([]models.NewsArticle, error) {
+	svc, err := k.p.newsAPI(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetNews(ctx, query, limit)
+}
+
+func (k keyedNewsAPI) GetNewsSince(ctx context.Context, query string, limit int, from time.Time) ([]models.NewsArticle, error) {
+	svc, err := k.p.newsAPI(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetNewsSince(ctx, query, limit, from)
+}
+
+func (k keyedNewsAPI) GetHeadlines(ctx context.Context, query string, limit int) ([]models.NewsArticle, error) {
+	svc, err := k.p.newsAPI(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetHeadlines(ctx, query, limit)
+}
+
+type keyedFMP struct{ p *ClientProvider }
+
+func (k keyedFMP) Screen(ctx context.Context, criteria ScreenCriteria) ([]ScreenerResult, error) {
+	svc, err := k.p.fmp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.Screen(ctx, criteria)
+}
+
+func (k keyedFMP) GetCompanyProfile(ctx context.Context, symbol string) (*CompanyProfile, error) {
+	svc, err := k.p.fmp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetCompanyProfile(ctx, symbol)
+}
+
+func (k keyedFMP) GetNextEarningsDate(ctx context.Context, symbol string) (*time.Time, error) {
+	svc, err := k.p.fmp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetNextEarningsDate(ctx, symbol)
+}
+
+func (k keyedFMP) GetRatios(ctx context.Context, symbol string) (*Ratios, error) {
+	svc, err := k.p.fmp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetRatios(ctx, symbol)
+}
+
+func (k keyedFMP) GetInsiderTrades(ctx context.Context, symbol string, limit int) ([]models.InsiderTrade, error) {
+	svc, err := k.p.fmp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetInsiderTrades(ctx, symbol, limit)
+}
+
+func (k keyedFMP) GetGrowthMetrics(ctx context.Context, symbol string) (*GrowthMetrics, error) {
+	svc, err := k.p.fmp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetGrowthMetrics(ctx, symbol)
+}
+
+func (k keyedFMP) GetDividendHistory(ctx context.Context, symbol string) ([]models.Dividend, error) {
+	svc, err := k.p.fmp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetDividendHistory(ctx, symbol)
+}
+
+// KeyedAlpaca is an Alpaca client that resolves its keys per request context. Besides
+// AlpacaServiceInterface it serves account activities for broker reconciliation and
+// option chains.
+type KeyedAlpaca struct{ p *ClientProvider }
+
+func (k *KeyedAlpaca) GetBars(ctx context.Context, symbol string, start, end time.Time, timeframe marketdata.TimeFrame) ([]marketdata.Bar, error) {
+	svc, err := k.p.alpaca(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetBars(ctx, symbol, start, end, timeframe)
+}
+
+func (k *KeyedAlpaca) GetDailyBars(ctx context.Context, symbol string, days int) ([]marketdata.Bar, error) {
+	svc, err := k.p.alpaca(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetDailyBars(ctx, symbol, days)
+}
+
+func (k *KeyedAlpaca) GetAverageDailyVolume(ctx context.Context, symbol string, days int) (int64, error) {
+	svc, err := k.p.alpaca(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return svc.GetAverageDailyVolume(ctx, symbol, days)
+}
+
+func (k *KeyedAlpaca) GetQuote(ctx context.Context, symbol string) (*models.Quote, error) {
+	svc, err := k.p.alpaca(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetQuote(ctx, symbol)
+}
+
+func (k *KeyedAlpaca) GetLatestTrade(ctx context.Context, symbol string) (*models.Quote, error) {
+	svc, err := k.p.alpaca(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetLatestTrade(ctx, symbol)
+}
+
+func (k *KeyedAlpaca) GetAccount(ctx context.Context) (*models.Account, error) {
+	svc, err := k.p.alpaca(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetAccount(ctx)
+}
+
+func (k *KeyedAlpaca) PlaceOrder(ctx context.Context, req models.OrderRequest) (string, error) {
+	svc, err := k.p.alpaca(ctx)
+	if err != nil {
+		return "", err
+	}
+	return svc.PlaceOrder(ctx, req)
+}
+
+func (k *KeyedAlpaca) GetShortAvailability(ctx context.Context, symbol string) (*models.ShortAvailability, error) {
+	svc, err := k.p.alpaca(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetShortAvailability(ctx, symbol)
+}
+
+func (k *KeyedAlpaca) GetOrder(ctx context.Context, orderID string) (*models.BrokerOrder, error) {
+	svc, err := k.p.alpaca(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetOrder(ctx, orderID)
+}
+
+func (k *KeyedAlpaca) CancelOrder(ctx context.Context, orderID string) error {
+	svc, err := k.p.alpaca(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.CancelOrder(ctx, orderID)
+}
+
+func (k *KeyedAlpaca) GetEquityHistory(ctx context.Context, days int) ([]models.DailyClose, error) {
+	svc, err := k.p.alpaca(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetEquityHistory(ctx, days)
+}
+
+func (k *KeyedAlpaca) GetPositions(ctx context.Context) ([]models.Position, error) {
+	svc, err := k.p.alpaca(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetPositions(ctx)
+}
+
+func (k *KeyedAlpaca) GetPosition(ctx context.Context, symbol string) (*models.Position, error) {
+	svc, err := k.p.alpaca(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetPosition(ctx, symbol)
+}
+
+func (k *KeyedAlpaca) GetAccountActivities(ctx context.Context, after, until time.Time) ([]models.BrokerActivity, error) {
+	svc, err := k.p.alpaca(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetAccountActivities(ctx, after, until)
+}
+
+// MarketStatus returns the session from the trading calendar of the context's Alpaca
+// client, or from the standard hours when no client is configured
+func (k *KeyedAlpaca) MarketStatus(ctx context.Context) models.MarketStatus {
+	svc, err := k.p.alpaca(ctx)
+	if err != nil {
+		return models.StandardHoursStatus(time.Now(), "Alpaca not configured")
+	}
+	return svc.MarketStatus(ctx)
+}
+
+func (k *KeyedAlpaca) GetCashFlows(ctx context.Context, after, until time.Time) (decimal.Decimal, error) {
+	svc, err := k.p.alpaca(ctx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return svc.GetCashFlows(ctx, after, until)
+}
+
+func (k *KeyedAlpaca) GetOptionChain(ctx context.Context, underlying string, filter models.OptionChainFilter) ([]models.OptionContract, error) {
+	svc, err := k.p.alpacaOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetOptionChain(ctx, underlying, filter)
+}
+
+// Compile-time interface verification
+var _ LLMService = keyedLLM{}
+var _ Embedder = keyedLLM{}
+var _ LLMService = (*LLMRouter)(nil)
+var _ LLMLeveler = (*LLMRouter)(nil)
+var _ AlphaVantageServiceInterface = keyedAlphaVantage{}
+var _ NewsAPIServiceInterface = keyedNewsAPI{}
+var _ FMPServiceInterface = keyedFMP{}
+var _ AlpacaServiceInterface = (*KeyedAlpaca)(nil)
+var _ OptionsDataService = (*KeyedAlpaca)(nil)
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	appconfig "trade-machine/config"
+)
+
+type userKeyContextKey struct{}
+
+// testResolver serves a per-user FMP key from the context, falling back to a shared key
+func testResolver(sharedKey *string) KeyResolver {
+	return func(ctx context.Context, service string) (Credentials, bool) {
+		if service != BreakerFMP {
+			return Credentials{}, false
+		}
+		if key, ok := ctx.Value(userKeyContextKey{}).(string); ok {
+			return Credentials{APIKey: key}, true
+		}
+		return Credentials{APIKey: *sharedKey}, *sharedKey != ""
+	}
+}
+
+func TestClientProvider_ResolvesPerContext(t *testing.T) {
+	sharedKey := "shared-key"
+	p := NewClientProvider(appconfig.NewTestConfig(), testResolver(&sharedKey))
+	ctx := context.Background()
+
+	shared, err := p.fmp(ctx)
+	if err != nil {
+		t.Fatalf("fmp() error = %v", err)
+	}
+	if shared.apiKey != "shared-key" {
+		t.Errorf("apiKey = %q, want shared-key", shared.apiKey)
+	}
+	if again, _ := p.fmp(ctx); again != shared {
+		t.Error("expected the client to be reused while the key is unchanged")
+	}
+
+	userCtx := context.WithValue(ctx, userKeyContextKey{}, "user-key")
+	user, err := p.fmp(userCtx)
+	if err != nil {
+		t.Fatalf("fmp() error = %v", err)
+	}
+	if user == shared || user.apiKey != "user-key" {
+		t.Errorf("expected a separate client for the user's key, got apiKey %q", user.apiKey)
+	}
+
+	// Hot reload: a changed key builds a new client on the next call
+	sharedKey = "rotated-key"
+	rotated, err := p.fmp(ctx)
+	if err != nil {
+		t.Fatalf("fmp() error = %v", err)
+	}
+	if rotated.apiKey != "rotated-key" {
+		t.Errorf("apiKey = %q, want rotated-key", rotated.apiKey)
+	}
+}
+
+func TestClientProvider_EvictsLeastRecentlyUsed(t *testing.T) {
+	sharedKey := "shared-key"
+	p := NewClientProvider(appconfig.NewTestConfig(), testResolver(&sharedKey))
+	ctx := context.Background()
+
+	shared, _ := p.fmp(ctx)
+	for i := 0; i < maxCachedClients; i++ {
+		if i == maxCachedClients/2 {
+			// Using the shared client keeps it cached while others come and go
+			if again, _ := p.fmp(ctx); again != shared {
+				t.Fatal("expected the shared client to still be cached")
+			}
+		}
+		p.fmp(context.WithValue(ctx, userKeyContextKey{}, fmt.Sprintf("user-%d", i)))
+	}
+	if p.lru.Len() != maxCachedClients || len(p.clients) != maxCachedClients {
+		t.Errorf("cached %d clients (%d keys), want %d", p.lru.Len(), len(p.clients), maxCachedClients)
+	}
+	if again, _ := p.fmp(ctx); again != shared {
+		t.Error("expected the recently used shared client to survive eviction")
+	}
+	first, _ := p.fmp(context.WithValue(ctx, userKeyContextKey{}, "user-0"))
+	if first.apiKey != "user-0" || p.lru.Len() != maxCachedClients {
+		t.Errorf("expected the least recently used client to have been evicted and rebuilt, got %d cached", p.lru.Len())
+	}
+}
+
+func TestClientProvider_NotConfigured(t *testing.T) {
+	sharedKey := ""
+	p := NewClientProvider(appconfig.NewTestConfig(), testResolver(&sharedKey))
+	ctx := context.Background()
+
+	if p.Configured(ctx, BreakerFMP) {
+		t.Error("Configured() = true without a key")
+	}
+	if _, err := p.FMP().Screen(ctx, ScreenCriteria{}); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("Screen() error = %v, want ErrNotConfigured", err)
+	}
+	if _, err := p.LLM().InvokeWithPrompt(ctx, "system", "user"); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("InvokeWithPrompt() error = %v, want ErrNotConfigured", err)
+	}
+	if _, err := p.Alpaca().GetAccount(ctx); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("GetAccount() error = %v, want ErrNotConfigured", err)
+	}
+}
+
+func TestClientProvider_LLMProvider(t *testing.T) {
+	keys := map[string]bool{}
+	resolve := func(ctx context.Context, service string) (Credentials, bool) {
+		return Credentials{APIKey: "key"}, keys[service]
+	}
+	cfg := appconfig.NewTestConfig()
+	p := NewClientProvider(cfg, resolve)
+	ctx := context.Background()
+
+	if got := p.LLMProvider(ctx); got != BreakerOpenAI {
+		t.Errorf("LLMProvider() without keys = %q, want openai", got)
+	}
+	keys[BreakerAnthropic] = true
+	if got := p.LLMProvider(ctx); got != BreakerAnthropic {
+		t.Errorf("LLMProvider() with only an Anthropic key = %q, want anthropic", got)
+	}
+	keys[BreakerOpenAI] = true
+	if got := p.LLMProvider(ctx); got != BreakerOpenAI {
+		t.Errorf("LLMProvider() with both keys = %q, want openai", got)
+	}
+	cfg.LLM.Provider = "anthropic"
+	if got := p.LLMProvider(ctx); got != BreakerAnthropic {
+		t.Errorf("LLMProvider() with LLM_PROVIDER=anthropic = %q, want anthropic", got)
+	}
+
+	cfg.LLM.Provider = ""
+	keys = map[string]bool{BreakerOllama: true}
+	if got := p.LLMProvider(ctx); got != BreakerOllama {
+		t.Errorf("LLMProvider() with only Ollama configured = %q, want ollama", got)
+	}
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"trade-machine/config"
+)
+
+// ErrRateLimited is returned when a provider's request quota is used up for longer than a
+// request may wait. IsRateLimited reports it to the screener's adaptive throttle.
+var ErrRateLimited = errors.New("rate limit reached")
+
+// RateLimit is a provider's request quota: Requests per Per. A zero Requests leaves the
+// provider unlimited.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// slidingWindow lets at most a quota of requests go out in any span of the quota's period.
+// It keeps the times the latest quota of requests were scheduled for, oldest first; the
+// next request goes out once the one a full quota earlier has left the window. Waiting
+// callers reserve their time ahead, so scheduled times can lie in the future. A request
+// abandoned while waiting keeps its time, since the one it displaced is already forgotten.
+type slidingWindow struct {
+	mu    sync.Mutex
+	limit int
+	per   time.Duration
+	slots []time.Time
+}
+
+func newSlidingWindow(limit RateLimit) *slidingWindow {
+	return &slidingWindow{
+		limit: limit.Requests,
+		per:   limit.Per,
+		slots: make([]time.Time, 0, limit.Requests),
+	}
+}
+
+// reserve schedules a request and returns how long it must wait to go out. A request that
+// could not go out within maxWait is not scheduled, and false is returned with the wait.
+func (w *slidingWindow) reserve(now time.Time, maxWait time.Duration) (time.Duration, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	at := now
+	full := len(w.slots) == w.limit
+	if full && w.slots[0].Add(w.per).After(now) {
+		at = w.slots[0].Add(w.per)
+	}
+	wait := at.Sub(now)
+	if wait > maxWait {
+		return wait, false
+	}
+	if full {
+		w.slots = w.slots[1:]
+	}
+	w.slots = append(w.slots, at)
+	return wait, true
+}
+
+// RateLimiterRegistry enforces each provider's request quota over a sliding window, so the
+// screener and agents stay within free-tier limits instead of being cut off mid-run
+type RateLimiterRegistry struct {
+	windows map[string]*slidingWindow
+	maxWait time.Duration
+	now     func() time.Time
+}
+
+// NewRateLimiterRegistry creates a registry limiting each named provider. Requests wait up
+// to maxWait for their quota; beyond that they fail with ErrRateLimited.
+func NewRateLimiterRegistry(limits map[string]RateLimit, maxWait time.Duration) *RateLimiterRegistry {
+	r := &RateLimiterRegistry{
+		windows: make(map[string]*slidingWindow),
+		maxWait: maxWait,
+		now:     time.Now,
+	}
+	for name, limit := range limits {
+		if limit.Requests > 0 && limit.Per > 0 {
+			r.windows[name] = newSlidingWindow(limit)
+		}
+	}
+	return r
+}
+
+// NewRateLimiterFromConfig creates a registry with the configured FMP, Alpha Vantage and
+// NewsAPI quotas
+func NewRateLimiterFromConfig(cfg config.RateLimitConfig) *RateLimiterRegistry {
+	return NewRateLimiterRegistry(map[string]RateLimit{
+		BreakerFMP:          {Requests: cfg.FMPPerMinute, Per: time.Minute},
+		BreakerAlphaVantage: {Requests: cfg.AlphaVantagePerMinute, Per: time.Minute},
+		BreakerNewsAPI:      {Requests: cfg.NewsAPIPerDay, Per: 24 * time.Hour},
+	}, time.Duration(cfg.MaxWaitSeconds)*time.Second)
+}
+
+// Wait blocks until the named provider's quota allows another request. It fails at once
+// with ErrRateLimited when the wait would exceed the registry's maximum or the context's
+// deadline. Providers without a limit never wait.
+func (r *RateLimiterRegistry) Wait(ctx context.Context, name string) error {
+	window, ok := r.windows[name]
+	if !ok {
+		return nil
+	}
+
+	now := r.now()
+	maxWait := r.maxWait
+	if deadline, ok := ctx.Deadline(); ok {
+		maxWait = min(maxWait, deadline.Sub(now))
+	}
+	wait, ok := window.reserve(now, maxWait)
+	if !ok {
+		return fmt.Errorf("%s %w: next request allowed in %s", name, ErrRateLimited, wait.Round(time.Second))
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// activeRateLimiter limits requests from every provider client; nil disables limiting
+var activeRateLimiter atomic.Pointer[RateLimiterRegistry]
+
+// SetRateLimiter sets the registry outbound API calls wait on. Pass nil to stop limiting.
+func SetRateLimiter(r *RateLimiterRegistry) {
+	activeRateLimiter.Store(r)
+}
+
+// quotaTransport waits on the active rate limiter, if any, before each request reaches
+// the provider
+type quotaTransport struct {
+	provider string
+	base     http.RoundTripper
+}
+
+func (t *quotaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if limiter := activeRateLimiter.Load(); limiter != nil {
+		if err := limiter.Wait(req.Context(), t.provider); err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(req)
+}
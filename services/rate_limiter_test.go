@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlidingWindow_Reserve(t *testing.T) {
+	start := time.Now()
+	window := newSlidingWindow(RateLimit{Requests: 5, Per: time.Minute})
+
+	for i := 0; i < 5; i++ {
+		if wait, ok := window.reserve(start.Add(time.Duration(i)*10*time.Second), 0); !ok || wait != 0 {
+			t.Fatalf("request %d: wait %s, ok %v; want the quota available", i, wait, ok)
+		}
+	}
+
+	// The quota isn't topped up while the first requests are still inside the window
+	if wait, ok := window.reserve(start.Add(50*time.Second), time.Second); ok || wait != 10*time.Second {
+		t.Errorf("wait %s, ok %v; want a 10s wait refused", wait, ok)
+	}
+
+	// Reserving ahead queues requests behind the ones a full quota earlier
+	if wait, ok := window.reserve(start.Add(50*time.Second), time.Minute); !ok || wait != 10*time.Second {
+		t.Errorf("wait %s, ok %v; want 10s", wait, ok)
+	}
+	if wait, ok := window.reserve(start.Add(50*time.Second), time.Minute); !ok || wait != 20*time.Second {
+		t.Errorf("wait %s, ok %v; want 20s", wait, ok)
+	}
+
+	// No span of a minute ever sees more than the quota, even after a long idle period
+	later := start.Add(time.Hour)
+	for i := 0; i < 5; i++ {
+		window.reserve(later, 0)
+	}
+	if _, ok := window.reserve(later.Add(59*time.Second), 0); ok {
+		t.Error("expected at most one quota of requests within a minute")
+	}
+}
+
+func TestRateLimiterRegistry_Wait(t *testing.T) {
+	limiter := NewRateLimiterRegistry(map[string]RateLimit{
+		BreakerNewsAPI: {Requests: 1, Per: 24 * time.Hour},
+		BreakerFMP:     {Requests: 0, Per: time.Minute},
+	}, 30*time.Second)
+	ctx := context.Background()
+
+	if err := limiter.Wait(ctx, BreakerNewsAPI); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := limiter.Wait(ctx, BreakerNewsAPI)
+	if !errors.Is(err, ErrRateLimited) || !strings.Contains(err.Error(), "rate limit") {
+		t.Errorf("expected the used-up daily quota to fail fast with ErrRateLimited, got %v", err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if err := limiter.Wait(ctx, BreakerFMP); err != nil {
+			t.Fatalf("expected a zero limit to leave FMP unlimited, got %v", err)
+		}
+	}
+	if err := limiter.Wait(ctx, BreakerOpenAI); err != nil {
+		t.Errorf("expected providers without a limit never to wait, got %v", err)
+	}
+}
+
+func TestRateLimiterRegistry_WaitRespectsDeadline(t *testing.T) {
+	limiter := NewRateLimiterRegistry(map[string]RateLimit{
+		BreakerAlphaVantage: {Requests: 1, Per: time.Second},
+	}, time.Minute)
+	limiter.Wait(context.Background(), BreakerAlphaVantage)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx, BreakerAlphaVantage); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected a wait past the deadline to fail at once, got %v", err)
+	}
+
+	started := time.Now()
+	if err := limiter.Wait(context.Background(), BreakerAlphaVantage); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed < 900*time.Millisecond {
+		t.Errorf("waited %s, want about a second for the next token", elapsed)
+	}
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"trade-machine/config"
+)
+
+// Response cache data types, each cached for its own TTL
+const (
+	CacheTypeFundamentals = "http_fundamentals"
+	CacheTypeRatios       = "http_ratios"
+	CacheTypeNews         = "http_news"
+	CacheTypeEarnings     = "http_earnings"
+	CacheTypeInsider      = "http_insider"
+)
+
+// responseCachePruneInterval is how often expired entries are removed from the store. News
+// searches are keyed by the hour they start from, so old keys are never overwritten.
+const responseCachePruneInterval = time.Hour
+
+// ResponseCacheStore persists provider responses, such as the market data cache table
+type ResponseCacheStore interface {
+	GetCachedData(ctx context.Context, symbol, dataType string) (map[string]interface{}, error)
+	SetCachedData(ctx context.Context, symbol, dataType string, data map[string]interface{}, ttl time.Duration) error
+	CleanExpiredCache(ctx context.Context) (int64, error)
+}
+
+// ResponseCache keeps successful FMP, Alpha Vantage and NewsAPI responses in a persistent
+// store, so repeated screener runs and analyses reuse fundamentals and news across restarts
+// instead of spending provider quota on them again
+type ResponseCache struct {
+	store     ResponseCacheStore
+	ttls      map[string]time.Duration
+	lastPrune atomic.Int64 // Unix seconds of the last prune of expired entries
+}
+
+// NewResponseCache creates a cache with the configured TTL for each data type. Types with
+// a zero TTL are not cached.
+func NewResponseCache(store ResponseCacheStore, cfg config.ResponseCacheConfig) *ResponseCache {
+	minutes := func(m int) time.Duration { return time.Duration(m) * time.Minute }
+	c := &ResponseCache{
+		store: store,
+		ttls: map[string]time.Duration{
+			CacheTypeFundamentals: minutes(cfg.FundamentalsTTLMinutes),
+			CacheTypeRatios:       minutes(cfg.RatiosTTLMinutes),
+			CacheTypeNews:         minutes(cfg.NewsTTLMinutes),
+			CacheTypeEarnings:     minutes(cfg.EarningsTTLMinutes),
+			CacheTypeInsider:      minutes(cfg.InsiderTTLMinutes),
+		},
+	}
+	c.lastPrune.Store(time.Now().Unix())
+	return c
+}
+
+// pruneExpired removes expired entries in the background, at most once per
+// responseCachePruneInterval
+func (c *ResponseCache) pruneExpired(ctx context.Context) {
+	now := time.Now()
+	last := c.lastPrune.Load()
+	if now.Sub(time.Unix(last, 0)) < responseCachePruneInterval || !c.lastPrune.CompareAndSwap(last, now.Unix()) {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+		if _, err := c.store.CleanExpiredCache(ctx); err != nil {
+			logger.Warn("failed to prune response cache", "error", err)
+		}
+	}()
+}
+
+// activeResponseCache serves cached responses to every provider client; nil disables caching
+var activeResponseCache atomic.Pointer[ResponseCache]
+
+// SetResponseCache sets the cache provider responses are served from. Pass nil to stop caching.
+func SetResponseCache(c *ResponseCache) {
+	activeResponseCache.Store(c)
+}
+
+// responseCacheType returns the data type a provider request is cached as, or "" for
+// requests that must always reach the provider, such as quotes and screens
+func responseCacheType(provider string, req *http.Request) string {
+	path := req.URL.Path
+	switch provider {
+	case BreakerFMP:
+		switch {
+		case strings.Contains(path, "/profile/"), strings.Contains(path, "/stock_dividend/"):
+			return CacheTypeFundamentals
+		case strings.Contains(path, "/ratios-ttm/"), strings.Contains(path, "/financial-growth/"):
+			return CacheTypeRatios
+		case strings.Contains(path, "/earning_calendar/"):
+			return CacheTypeEarnings
+		case strings.HasSuffix(path, "/insider-trading"):
+			return CacheTypeInsider
+		}
+	case BreakerAlphaVantage:
+		switch req.URL.Query().Get("function") {
+		case "OVERVIEW", "BALANCE_SHEET":
+			return CacheTypeFundamentals
+		case "NEWS_SENTIMENT":
+			return CacheTypeNews
+		}
+	case BreakerNewsAPI:
+		if strings.HasSuffix(path, "/everything") || strings.HasSuffix(path, "/top-headlines") {
+			return CacheTypeNews
+		}
+	}
+	return ""
+}
+
+// responseCacheKey identifies a request by provider, path and query, leaving out API keys
+func responseCacheKey(provider string, req *http.Request) string {
+	query := req.URL.Query()
+	for _, param := range []string{"apikey", "apiKey", "api_key", "token"} {
+		query.Del(param)
+	}
+	key := provider + " " + req.URL.Path
+	if encoded := query.Encode(); encoded != "" {
+		key += "?" + encoded
+	}
+	return key
+}
+
+// cacheTransport serves GET requests of cacheable types from the active response cache,
+// storing successful responses it fetches. A context from WithFreshData skips the lookup
+// but still stores the fresh response.
+type cacheTransport struct {
+	provider string
+	base     http.RoundTripper
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cache := activeResponseCache.Load()
+	if cache == nil || req.Method != http.MethodGet {
+		return t.base.RoundTrip(req)
+	}
+	dataType := responseCacheType(t.provider, req)
+	ttl := cache.ttls[dataType]
+	if ttl <= 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	key := responseCacheKey(t.provider, req)
+	if !wantsFreshData(ctx) {
+		data, err := cache.store.GetCachedData(ctx, key, dataType)
+		if err != nil {
+			logger.Warn("response cache lookup failed", "provider", t.provider, "error", err)
+		} else if body, ok := data["body"].(string); ok {
+			return cachedResponse(req, body), nil
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if !cacheableBody(body) {
+		return resp, nil
+	}
+
+	if err := cache.store.SetCachedData(ctx, key, dataType, map[string]interface{}{"body": string(body)}, ttl); err != nil {
+		logger.Warn("response cache store failed", "provider", t.provider, "error", err)
+	}
+	cache.pruneExpired(ctx)
+	return resp, nil
+}
+
+// providerNoticeKeys are the fields Alpha Vantage and FMP answer with, under a 200 status,
+// when a request was refused or throttled
+var providerNoticeKeys = []string{"Error Message", "Note", "Information"}
+
+// cacheableBody reports whether a successful response holds data rather than a provider
+// notice, which must not be served from the cache once the limit lifts
+func cacheableBody(body []byte) bool {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		return true // Arrays and other non-object bodies carry no notices
+	}
+	for _, key := range providerNoticeKeys {
+		if _, ok := fields[key]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// cachedResponse builds a 200 response carrying a cached body, marked for the call ledger
+func cachedResponse(req *http.Request, body string) *http.Response {
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	header.Set(CachedResponseHeader, "1")
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
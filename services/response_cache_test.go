@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"trade-machine/config"
+)
+
+// memoryResponseStore keeps cached responses in memory, ignoring their TTLs
+type memoryResponseStore struct {
+	mu      sync.Mutex
+	entries map[string]map[string]interface{}
+	ttls    map[string]time.Duration
+}
+
+func newMemoryResponseStore() *memoryResponseStore {
+	return &memoryResponseStore{entries: make(map[string]map[string]interface{}), ttls: make(map[string]time.Duration)}
+}
+
+func (s *memoryResponseStore) GetCachedData(ctx context.Context, symbol, dataType string) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries[dataType+"|"+symbol], nil
+}
+
+func (s *memoryResponseStore) SetCachedData(ctx context.Context, symbol, dataType string, data map[string]interface{}, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[dataType+"|"+symbol] = data
+	s.ttls[dataType] = ttl
+	return nil
+}
+
+func (s *memoryResponseStore) CleanExpiredCache(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestCacheTransport(t *testing.T) {
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	hitsFor := func(key string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return hits[key]
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path+"?"+r.URL.Query().Get("function")]++
+		mu.Unlock()
+		if r.URL.Query().Get("symbol") == "LIMITED" {
+			w.Write([]byte(`{"Note": "Thank you for using Alpha Vantage! Our standard API call frequency is 5 calls per minute."}`))
+			return
+		}
+		w.Write([]byte(`{"symbol": "AAPL"}`))
+	}))
+	defer server.Close()
+
+	store := newMemoryResponseStore()
+	SetResponseCache(NewResponseCache(store, config.ResponseCacheConfig{FundamentalsTTLMinutes: 1440, NewsTTLMinutes: 30}))
+	defer SetResponseCache(nil)
+
+	get := func(client *http.Client, ctx context.Context, path string) (string, bool) {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Get(%s) error = %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body), resp.Header.Get(CachedResponseHeader) == "1"
+	}
+	fmp := newLedgerHTTPClient(BreakerFMP, time.Second)
+	av := newLedgerHTTPClient(BreakerAlphaVantage, time.Second)
+	ctx := context.Background()
+
+	if _, cached := get(fmp, ctx, "/api/v3/profile/AAPL?apikey=first"); cached {
+		t.Error("expected the first lookup to reach the provider")
+	}
+	// API keys are left out of the cache key
+	body, cached := get(fmp, ctx, "/api/v3/profile/AAPL?apikey=second")
+	if !cached || !strings.Contains(body, "AAPL") || hitsFor("/api/v3/profile/AAPL?") != 1 {
+		t.Errorf("body %q, cached %v, %d provider hits; want the cached profile", body, cached, hitsFor("/api/v3/profile/AAPL?"))
+	}
+	if store.ttls[CacheTypeFundamentals] != 24*time.Hour {
+		t.Errorf("fundamentals TTL = %s, want 24h", store.ttls[CacheTypeFundamentals])
+	}
+
+	// Fresh lookups skip the cache
+	if _, cached := get(fmp, WithFreshData(ctx), "/api/v3/profile/AAPL"); cached || hitsFor("/api/v3/profile/AAPL?") != 2 {
+		t.Error("expected a fresh lookup to reach the provider")
+	}
+
+	// Quotes and types without a TTL always reach the provider
+	get(av, ctx, "/query?function=GLOBAL_QUOTE&symbol=AAPL")
+	if _, cached := get(av, ctx, "/query?function=GLOBAL_QUOTE&symbol=AAPL"); cached {
+		t.Error("expected quotes not to be cached")
+	}
+	get(fmp, ctx, "/api/v3/ratios-ttm/AAPL")
+	if _, cached := get(fmp, ctx, "/api/v3/ratios-ttm/AAPL"); cached {
+		t.Error("expected ratios with no TTL not to be cached")
+	}
+
+	// Throttling notices sent with a 200 are not cached
+	get(av, ctx, "/query?function=OVERVIEW&symbol=LIMITED")
+	if _, cached := get(av, ctx, "/query?function=OVERVIEW&symbol=LIMITED"); cached {
+		t.Error("expected an Alpha Vantage rate-limit note not to be cached")
+	}
+}
+
+func TestResponseCacheType(t *testing.T) {
+	tests := []struct {
+		provider string
+		url      string
+		want     string
+	}{
+		{BreakerFMP, "https://financialmodelingprep.com/api/v3/profile/AAPL", CacheTypeFundamentals},
+		{BreakerFMP, "https://financialmodelingprep.com/api/v3/ratios-ttm/AAPL", CacheTypeRatios},
+		{BreakerFMP, "https://financialmodelingprep.com/api/v3/financial-growth/AAPL?period=annual&limit=1", CacheTypeRatios},
+		{BreakerFMP, "https://financialmodelingprep.com/api/v3/stock-price-change/AAPL", ""},
+		{BreakerFMP, "https://financialmodelingprep.com/api/v3/historical-price-full/stock_dividend/KO", CacheTypeFundamentals},
+		{BreakerFMP, "https://financialmodelingprep.com/api/v3/historical/earning_calendar/AAPL", CacheTypeEarnings},
+		{BreakerFMP, "https://financialmodelingprep.com/api/v4/insider-trading?symbol=AAPL", CacheTypeInsider},
+		{BreakerFMP, "https://financialmodelingprep.com/api/v3/stock-screener", ""},
+		{BreakerAlphaVantage, "https://www.alphavantage.co/query?function=BALANCE_SHEET", CacheTypeFundamentals},
+		{BreakerAlphaVantage, "https://www.alphavantage.co/query?function=NEWS_SENTIMENT", CacheTypeNews},
+		{BreakerNewsAPI, "https://newsapi.org/v2/everything?q=AAPL", CacheTypeNews},
+		{BreakerAlpaca, "https://data.alpaca.markets/v2/stocks/AAPL/bars", ""},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+		if got := responseCacheType(tt.provider, req); got != tt.want {
+			t.Errorf("responseCacheType(%s, %s) = %q, want %q", tt.provider, tt.url, got, tt.want)
+		}
+	}
+}
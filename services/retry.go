@@ -2,55 +2,207 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync/atomic"
 	"time"
 
-	"trade-machine/observability"
+	"trade-machine/config"
 )
 
-type RetryConfig struct {
-	MaxRetries     int
-	InitialBackoff time.Duration
-	MaxBackoff     time.Duration
+// RetryPolicy controls how a failed provider call is retried: up to MaxAttempts in total,
+// waiting BaseDelay before the first retry and doubling up to MaxDelay. Jitter spreads each
+// wait so concurrent agents don't retry in lockstep.
+type RetryPolicy struct {
+	MaxAttempts int              // Attempts including the first; below 2 disables retries
+	BaseDelay   time.Duration    // Wait before the first retry
+	MaxDelay    time.Duration    // Longest wait between attempts
+	Jitter      float64          // Share of each wait that is randomized, from 0 to 1
+	Retryable   func(error) bool // Reports whether an error is worth retrying; nil uses IsRetryable
 }
 
-var DefaultRetryConfig = RetryConfig{
-	MaxRetries:     3,
-	InitialBackoff: 100 * time.Millisecond,
-	MaxBackoff:     5 * time.Second,
+// DefaultRetryPolicy makes four attempts, waiting from 250ms up to 5s with 20% jitter
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   250 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+	Jitter:      0.2,
 }
 
-func WithRetry(ctx context.Context, config RetryConfig, fn func() error) error {
-	var lastErr error
-	backoff := config.InitialBackoff
+// NewRetryPolicyFromConfig creates a policy with the configured attempts and delays
+func NewRetryPolicyFromConfig(cfg config.RetryConfig) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: cfg.MaxAttempts,
+		BaseDelay:   time.Duration(cfg.BaseDelayMs) * time.Millisecond,
+		MaxDelay:    time.Duration(cfg.MaxDelayMs) * time.Millisecond,
+		Jitter:      float64(cfg.JitterPercent) / 100,
+	}
+}
 
-	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
-		if attempt > 0 {
-			select {
-			case <-ctx.Done():
-				return fmt.Errorf("context cancelled during retry: %w", ctx.Err())
-			case <-time.After(backoff):
-			}
+// activeRetryPolicy is the policy provider clients retry with; nil uses DefaultRetryPolicy
+var activeRetryPolicy atomic.Pointer[RetryPolicy]
 
-			backoff *= 2
-			if backoff > config.MaxBackoff {
-				backoff = config.MaxBackoff
-			}
-		}
+// SetRetryPolicy sets the policy FMP, NewsAPI, Alpha Vantage, Alpaca and LLM calls retry with
+func SetRetryPolicy(p RetryPolicy) {
+	activeRetryPolicy.Store(&p)
+}
 
+// currentRetryPolicy returns the policy set by SetRetryPolicy, or DefaultRetryPolicy
+func currentRetryPolicy() RetryPolicy {
+	if p := activeRetryPolicy.Load(); p != nil {
+		return *p
+	}
+	return DefaultRetryPolicy
+}
+
+// Retry runs fn under the active retry policy, returning its last result
+func Retry[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	var result T
+	err := currentRetryPolicy().Do(ctx, func() error {
+		var err error
+		result, err = fn()
+		return err
+	})
+	return result, err
+}
+
+// Do calls fn until it succeeds, returns an error the policy doesn't retry, or runs out of
+// attempts. A provider's Retry-After is honored when it falls within MaxDelay; a longer
+// one ends the retries, since waiting would only hold up the caller.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	for attempt := 1; ; attempt++ {
 		err := fn()
 		if err == nil {
 			return nil
 		}
+		if attempt >= p.MaxAttempts || !retryable(err) {
+			if attempt > 1 {
+				return fmt.Errorf("failed after %d attempts: %w", attempt, err)
+			}
+			return err
+		}
 
-		lastErr = err
-		if attempt < config.MaxRetries {
-			observability.Warn("retry attempt failed",
-				"attempt", attempt+1,
-				"max_retries", config.MaxRetries,
-				"error", err)
+		wait := p.delay(attempt)
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+			if statusErr.RetryAfter > p.MaxDelay {
+				return err
+			}
+			wait = max(wait, statusErr.RetryAfter)
 		}
+
+		logger.Warn("retry attempt failed",
+			"attempt", attempt,
+			"max_attempts", p.MaxAttempts,
+			"retry_in", wait,
+			"error", err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("context cancelled during retry: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// delay returns the wait after the given failed attempt: BaseDelay doubled per earlier
+// retry, capped at MaxDelay, with up to Jitter of it taken off at random
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	wait := p.BaseDelay
+	for i := 1; i < attempt && wait < p.MaxDelay; i++ {
+		wait *= 2
+	}
+	wait = min(wait, p.MaxDelay)
+	if p.Jitter > 0 {
+		wait -= time.Duration(p.Jitter * rand.Float64() * float64(wait))
 	}
+	return wait
+}
+
+// StatusError is a provider response with an unexpected HTTP status. RetryAfter is the
+// wait the provider asked for, or zero when it gave none.
+type StatusError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s returned status %d: %s", e.Provider, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s returned status %d", e.Provider, e.StatusCode)
+}
+
+// newStatusError describes an unexpected response from provider, keeping any wait it
+// asked for in Retry-After or X-RateLimit-Reset
+func newStatusError(provider string, resp *http.Response, message string) *StatusError {
+	err := &StatusError{Provider: provider, StatusCode: resp.StatusCode, Message: message}
+	now := time.Now()
+	if at := quotaRecovery(resp.Header, now); at != nil && at.After(now) {
+		err.RetryAfter = at.Sub(now)
+	}
+	return err
+}
+
+// permanentError marks a failure retrying can't fix, such as a symbol the provider has
+// no data for
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// permanent marks err as not worth retrying
+func permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// IsRetryable reports whether a failed provider call may succeed if tried again. Rate
+// limits (429), server errors (5xx) and network failures are transient; other 4xx
+// statuses, missing data, exhausted local quotas and cancelled contexts are not.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, ErrRateLimited) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.As(err, new(permanentError)) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return retryableStatus(statusErr.StatusCode)
+	}
+	return true
+}
+
+// IsRateLimited reports whether a provider call failed because its quota is used up: the
+// provider answered 429, or the local rate limiter would have waited too long
+func IsRateLimited(err error) bool {
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusTooManyRequests
+}
+
+// retryableStatus reports whether a provider response status is transient
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
 
-	return fmt.Errorf("failed after %d retries: %w", config.MaxRetries, lastErr)
+// isClientError reports whether err is a provider rejecting the request itself, such as
+// an unknown symbol or a bad key. Circuit breakers don't count these as provider failures.
+func isClientError(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode >= 400 && !retryableStatus(statusErr.StatusCode)
 }
@@ -3,20 +3,23 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
 
-func TestWithRetry_Success(t *testing.T) {
-	ctx := context.Background()
-	config := RetryConfig{
-		MaxRetries:     3,
-		InitialBackoff: 10 * time.Millisecond,
-		MaxBackoff:     100 * time.Millisecond,
-	}
+// testRetryPolicy retries quickly and without jitter, so timings are predictable
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   10 * time.Millisecond,
+	MaxDelay:    100 * time.Millisecond,
+}
 
+func TestRetryPolicy_Success(t *testing.T) {
 	callCount := 0
-	err := WithRetry(ctx, config, func() error {
+	err := testRetryPolicy.Do(context.Background(), func() error {
 		callCount++
 		return nil
 	})
@@ -24,22 +27,14 @@ func TestWithRetry_Success(t *testing.T) {
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
-
 	if callCount != 1 {
 		t.Errorf("expected 1 call, got %d", callCount)
 	}
 }
 
-func TestWithRetry_EventualSuccess(t *testing.T) {
-	ctx := context.Background()
-	config := RetryConfig{
-		MaxRetries:     3,
-		InitialBackoff: 10 * time.Millisecond,
-		MaxBackoff:     100 * time.Millisecond,
-	}
-
+func TestRetryPolicy_EventualSuccess(t *testing.T) {
 	callCount := 0
-	err := WithRetry(ctx, config, func() error {
+	err := testRetryPolicy.Do(context.Background(), func() error {
 		callCount++
 		if callCount < 3 {
 			return errors.New("temporary error")
@@ -50,46 +45,36 @@ func TestWithRetry_EventualSuccess(t *testing.T) {
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
-
 	if callCount != 3 {
 		t.Errorf("expected 3 calls, got %d", callCount)
 	}
 }
 
-func TestWithRetry_AllFail(t *testing.T) {
-	ctx := context.Background()
-	config := RetryConfig{
-		MaxRetries:     2,
-		InitialBackoff: 10 * time.Millisecond,
-		MaxBackoff:     100 * time.Millisecond,
-	}
+func TestRetryPolicy_AllFail(t *testing.T) {
+	policy := testRetryPolicy
+	policy.MaxAttempts = 3
 
 	callCount := 0
 	expectedErr := errors.New("persistent error")
-	err := WithRetry(ctx, config, func() error {
+	err := policy.Do(context.Background(), func() error {
 		callCount++
 		return expectedErr
 	})
 
-	if err == nil {
-		t.Error("expected error, got nil")
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("expected the last error to be wrapped, got %v", err)
 	}
-
 	if callCount != 3 {
-		t.Errorf("expected 3 calls (initial + 2 retries), got %d", callCount)
+		t.Errorf("expected 3 attempts, got %d", callCount)
 	}
 }
 
-func TestWithRetry_ContextCancelled(t *testing.T) {
+func TestRetryPolicy_ContextCancelled(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
-	config := RetryConfig{
-		MaxRetries:     5,
-		InitialBackoff: 50 * time.Millisecond,
-		MaxBackoff:     200 * time.Millisecond,
-	}
+	policy := RetryPolicy{MaxAttempts: 6, BaseDelay: 50 * time.Millisecond, MaxDelay: 200 * time.Millisecond}
 
 	callCount := 0
-	err := WithRetry(ctx, config, func() error {
+	err := policy.Do(ctx, func() error {
 		callCount++
 		if callCount == 2 {
 			cancel()
@@ -100,52 +85,181 @@ func TestWithRetry_ContextCancelled(t *testing.T) {
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
-
 	if callCount > 3 {
 		t.Errorf("expected at most 3 calls before cancellation, got %d", callCount)
 	}
 }
 
-func TestWithRetry_ExponentialBackoff(t *testing.T) {
-	ctx := context.Background()
-	config := RetryConfig{
-		MaxRetries:     3,
-		InitialBackoff: 10 * time.Millisecond,
-		MaxBackoff:     50 * time.Millisecond,
-	}
+func TestRetryPolicy_ExponentialBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 4, BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
 
 	startTime := time.Now()
-	callCount := 0
-	WithRetry(ctx, config, func() error {
-		callCount++
+	policy.Do(context.Background(), func() error {
 		return errors.New("error")
 	})
 	duration := time.Since(startTime)
 
 	expectedMinDuration := 10*time.Millisecond + 20*time.Millisecond + 40*time.Millisecond
-
 	if duration < expectedMinDuration {
 		t.Errorf("expected duration >= %v, got %v", expectedMinDuration, duration)
 	}
 }
 
-func TestWithRetry_MaxBackoff(t *testing.T) {
-	ctx := context.Background()
-	config := RetryConfig{
-		MaxRetries:     5,
-		InitialBackoff: 10 * time.Millisecond,
-		MaxBackoff:     30 * time.Millisecond,
-	}
+func TestRetryPolicy_MaxBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 6, BaseDelay: 10 * time.Millisecond, MaxDelay: 30 * time.Millisecond}
 
 	startTime := time.Now()
-	WithRetry(ctx, config, func() error {
+	policy.Do(context.Background(), func() error {
 		return errors.New("error")
 	})
 	duration := time.Since(startTime)
 
 	expectedMaxDuration := 30*time.Millisecond*5 + 100*time.Millisecond
-
 	if duration > expectedMaxDuration {
 		t.Errorf("backoff seems too long, expected < %v, got %v", expectedMaxDuration, duration)
 	}
 }
+
+func TestRetryPolicy_Jitter(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Jitter: 0.5}
+
+	for range 20 {
+		if wait := policy.delay(2); wait < 100*time.Millisecond || wait > 200*time.Millisecond {
+			t.Fatalf("delay(2) = %v, want between 100ms and 200ms", wait)
+		}
+	}
+}
+
+func TestRetryPolicy_StopsOnClientError(t *testing.T) {
+	callCount := 0
+	err := testRetryPolicy.Do(context.Background(), func() error {
+		callCount++
+		return &StatusError{Provider: "FMP", StatusCode: http.StatusNotFound}
+	})
+
+	if callCount != 1 {
+		t.Errorf("expected a 404 not to be retried, got %d calls", callCount)
+	}
+	if err == nil || err.Error() != "FMP returned status 404" {
+		t.Errorf("err = %v, want the status error unwrapped", err)
+	}
+}
+
+func TestRetryPolicy_RetryAfter(t *testing.T) {
+	callCount := 0
+	start := time.Now()
+	err := testRetryPolicy.Do(context.Background(), func() error {
+		callCount++
+		if callCount == 1 {
+			return &StatusError{Provider: "NewsAPI", StatusCode: http.StatusTooManyRequests, RetryAfter: 60 * time.Millisecond}
+		}
+		return nil
+	})
+
+	if err != nil || callCount != 2 {
+		t.Fatalf("err = %v after %d calls, want success on the retry", err, callCount)
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("retried after %v, want the provider's Retry-After honored", elapsed)
+	}
+
+	// A wait longer than the policy allows fails the call rather than holding it up
+	callCount = 0
+	err = testRetryPolicy.Do(context.Background(), func() error {
+		callCount++
+		return &StatusError{Provider: "NewsAPI", StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour}
+	})
+	if err == nil || callCount != 1 {
+		t.Errorf("err = %v after %d calls, want a failure without retrying", err, callCount)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"network error", errors.New("connection reset by peer"), true},
+		{"rate limited", &StatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{"server error", fmt.Errorf("wrapped: %w", &StatusError{StatusCode: http.StatusServiceUnavailable}), true},
+		{"overloaded", &StatusError{StatusCode: 529}, true},
+		{"unauthorized", &StatusError{StatusCode: http.StatusUnauthorized}, false},
+		{"not found", &StatusError{StatusCode: http.StatusNotFound}, false},
+		{"local quota", fmt.Errorf("fmp %w", ErrRateLimited), false},
+		{"cancelled", context.Canceled, false},
+		{"deadline", fmt.Errorf("request: %w", context.DeadlineExceeded), false},
+		{"no data", permanent(errors.New("no ratios data for symbol XYZ")), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewStatusError_RetryAfter(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"3"}}}
+
+	err := newStatusError("NewsAPI", resp, "")
+	if err.RetryAfter < 2*time.Second || err.RetryAfter > 3*time.Second {
+		t.Errorf("RetryAfter = %v, want about 3s", err.RetryAfter)
+	}
+	if err.Error() != "NewsAPI returned status 429" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
+
+func TestCircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	registry := NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig)
+	ctx := context.Background()
+
+	for range 10 {
+		registry.Execute(ctx, "test-service", func() (any, error) {
+			return nil, &StatusError{Provider: "FMP", StatusCode: http.StatusNotFound}
+		})
+	}
+	if counts := registry.GetBreaker("test-service").Counts(); counts.TotalFailures != 0 {
+		t.Errorf("TotalFailures = %d, want rejected requests not counted against the provider", counts.TotalFailures)
+	}
+
+	for range minBreakerRequests {
+		registry.Execute(ctx, "other-service", func() (any, error) {
+			return nil, &StatusError{Provider: "FMP", StatusCode: http.StatusBadGateway}
+		})
+	}
+	if level := registry.Level("other-service"); level != DegradationOpen {
+		t.Errorf("Level = %v, want server errors to trip the breaker", level)
+	}
+}
+
+func TestNewsAPI_RetriesTransientErrors(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+	SetRetryPolicy(testRetryPolicy)
+	defer SetRetryPolicy(DefaultRetryPolicy)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"status":"ok","articles":[{"title":"Apple beats","publishedAt":"2024-01-15T10:00:00Z"}]}`))
+	}))
+	defer server.Close()
+
+	service := NewNewsAPIService("test-key")
+	service.baseURL = server.URL
+
+	articles, err := service.GetHeadlines(context.Background(), "AAPL", 5)
+	if err != nil {
+		t.Fatalf("GetHeadlines error = %v, want success after retries", err)
+	}
+	if len(articles) != 1 || requests != 3 {
+		t.Errorf("got %d articles after %d requests, want 1 after 3", len(articles), requests)
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+)
+
+// SocialSentimentService reads recent posts about a symbol from Reddit and StockTwits
+// through their public JSON endpoints. Each source has its own circuit breaker, and posts
+// from one are still returned when the other fails.
+type SocialSentimentService struct {
+	redditClient     *http.Client
+	stockTwitsClient *http.Client
+	redditURL        string
+	stockTwitsURL    string
+	subreddits       []string
+	userAgent        string
+}
+
+// NewSocialSentimentService creates a new SocialSentimentService
+func NewSocialSentimentService(cfg config.SocialConfig) *SocialSentimentService {
+	return &SocialSentimentService{
+		redditClient:     newLedgerHTTPClient(BreakerReddit, 15*time.Second),
+		stockTwitsClient: newLedgerHTTPClient(BreakerStockTwits, 15*time.Second),
+		redditURL:        "https://www.reddit.com",
+		stockTwitsURL:    "https://api.stocktwits.com/api/2",
+		subreddits:       cfg.Subreddits,
+		userAgent:        cfg.UserAgent,
+	}
+}
+
+// redditSearchResponse is the listing returned by a Reddit subreddit search
+type redditSearchResponse struct {
+	Data struct {
+		Children []struct {
+			Data struct {
+				Title      string  `json:"title"`
+				Selftext   string  `json:"selftext"`
+				Score      int     `json:"score"`
+				Permalink  string  `json:"permalink"`
+				CreatedUTC float64 `json:"created_utc"`
+			} `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+// stockTwitsStreamResponse is a StockTwits symbol stream
+type stockTwitsStreamResponse struct {
+	Messages []struct {
+		ID        int64  `json:"id"`
+		Body      string `json:"body"`
+		CreatedAt string `json:"created_at"`
+		Likes     struct {
+			Total int `json:"total"`
+		} `json:"likes"`
+		Entities struct {
+			Sentiment *struct {
+				Basic string `json:"basic"`
+			} `json:"sentiment"`
+		} `json:"entities"`
+	} `json:"messages"`
+}
+
+// redditTextLimit keeps long self posts from crowding out the others in a prompt
+const redditTextLimit = 500
+
+// GetPosts returns up to limit posts from each source, newest first. An error is returned
+// only when both sources fail.
+func (s *SocialSentimentService) GetPosts(ctx context.Context, symbol string, limit int) ([]models.SocialPost, error) {
+	if limit <= 0 {
+		limit = 25
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	reddit, redditErr := s.GetRedditPosts(ctx, symbol, limit)
+	stockTwits, stockTwitsErr := s.GetStockTwitsMessages(ctx, symbol, limit)
+	if redditErr != nil && stockTwitsErr != nil {
+		return nil, errors.Join(redditErr, stockTwitsErr)
+	}
+	if redditErr != nil {
+		logger.Warn("reddit unavailable, using StockTwits only", "symbol", symbol, "error", redditErr)
+	}
+	if stockTwitsErr != nil {
+		logger.Warn("StockTwits unavailable, using Reddit only", "symbol", symbol, "error", stockTwitsErr)
+	}
+
+	posts := append(reddit, stockTwits...)
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].CreatedAt.After(posts[j].CreatedAt)
+	})
+	return posts, nil
+}
+
+// GetRedditPosts returns the newest posts from the past week in the configured subreddits
+// that mention the symbol's cashtag
+func (s *SocialSentimentService) GetRedditPosts(ctx context.Context, symbol string, limit int) ([]models.SocialPost, error) {
+	return WithCircuitBreaker(ctx, BreakerReddit, func() ([]models.SocialPost, error) {
+		params := url.Values{}
+		params.Set("q", "$"+symbol)
+		params.Set("restrict_sr", "1")
+		params.Set("sort", "new")
+		params.Set("t", "week")
+		params.Set("limit", fmt.Sprintf("%d", limit))
+
+		subreddits := strings.ToLower(strings.Join(s.subreddits, "+"))
+		req, err := http.NewRequestWithContext(ctx, "GET", s.redditURL+"/r/"+subreddits+"/search.json?"+params.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("User-Agent", s.userAgent)
+
+		resp, err := s.redditClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch reddit posts: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("reddit returned status %d", resp.StatusCode)
+		}
+
+		var listing redditSearchResponse
+		if err := json.NewDecoder(resp.Body).Decode(&listing); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		posts := make([]models.SocialPost, 0, len(listing.Data.Children))
+		for _, child := range listing.Data.Children {
+			post := child.Data
+			text := post.Title
+			if body := strings.TrimSpace(post.Selftext); body != "" {
+				text += "\n" + truncateRunes(body, redditTextLimit)
+			}
+			posts = append(posts, models.SocialPost{
+				Source:    "reddit",
+				Text:      text,
+				URL:       s.redditURL + post.Permalink,
+				Score:     post.Score,
+				CreatedAt: time.Unix(int64(post.CreatedUTC), 0),
+			})
+		}
+		return posts, nil
+	})
+}
+
+// GetStockTwitsMessages returns the newest messages in the symbol's StockTwits stream,
+// with the bullish or bearish label their authors chose
+func (s *SocialSentimentService) GetStockTwitsMessages(ctx context.Context, symbol string, limit int) ([]models.SocialPost, error) {
+	return WithCircuitBreaker(ctx, BreakerStockTwits, func() ([]models.SocialPost, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", s.stockTwitsURL+"/streams/symbol/"+url.PathEscape(symbol)+".json", nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := s.stockTwitsClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch StockTwits messages: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("StockTwits returned status %d", resp.StatusCode)
+		}
+
+		var stream stockTwitsStreamResponse
+		if err := json.NewDecoder(resp.Body).Decode(&stream); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		posts := make([]models.SocialPost, 0, min(len(stream.Messages), limit))
+		for _, msg := range stream.Messages {
+			if len(posts) == limit {
+				break
+			}
+			createdAt, err := time.Parse(time.RFC3339, msg.CreatedAt)
+			if err != nil {
+				logger.Warn("failed to parse timestamp, using current time", "value", msg.CreatedAt, "error", err)
+				createdAt = time.Now()
+			}
+			post := models.SocialPost{
+				Source:    "stocktwits",
+				Text:      msg.Body,
+				URL:       fmt.Sprintf("https://stocktwits.com/message/%d", msg.ID),
+				Score:     msg.Likes.Total,
+				CreatedAt: createdAt,
+			}
+			if msg.Entities.Sentiment != nil {
+				switch strings.ToLower(msg.Entities.Sentiment.Basic) {
+				case "bullish":
+					post.Sentiment = models.SocialSentimentBullish
+				case "bearish":
+					post.Sentiment = models.SocialSentimentBearish
+				}
+			}
+			posts = append(posts, post)
+		}
+		return posts, nil
+	})
+}
+
+// truncateRunes shortens s to at most n runes, marking the cut with an ellipsis
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
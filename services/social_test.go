@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"trade-machine/config"
+	"trade-machine/models"
+)
+
+func newTestSocialServer(t *testing.T, redditStatus, stockTwitsStatus int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/r/wallstreetbets+stocks/search.json":
+			if r.URL.Query().Get("q") != "$AAPL" {
+				t.Errorf("unexpected reddit query: %s", r.URL.RawQuery)
+			}
+			if r.Header.Get("User-Agent") != "test-agent" {
+				t.Errorf("missing user agent, got %q", r.Header.Get("User-Agent"))
+			}
+			w.WriteHeader(redditStatus)
+			w.Write([]byte(`{"data": {"children": [
+				{"data": {"title": "AAPL to the moon", "selftext": "Earnings will crush", "score": 420, "permalink": "/r/wallstreetbets/comments/1", "created_utc": 1705312800}}
+			]}}`))
+		case "/streams/symbol/AAPL.json":
+			w.WriteHeader(stockTwitsStatus)
+			w.Write([]byte(`{"messages": [
+				{"id": 2, "body": "$AAPL loading up", "created_at": "2024-01-15T12:00:00Z", "likes": {"total": 3}, "entities": {"sentiment": {"basic": "Bullish"}}},
+				{"id": 3, "body": "$AAPL overvalued", "created_at": "2024-01-15T08:00:00Z", "entities": {"sentiment": {"basic": "Bearish"}}},
+				{"id": 4, "body": "$AAPL watching", "created_at": "2024-01-15T07:00:00Z", "entities": {"sentiment": null}}
+			]}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func newTestSocialService(server *httptest.Server) *SocialSentimentService {
+	service := NewSocialSentimentService(config.SocialConfig{Subreddits: []string{"WALLSTREETBETS", "STOCKS"}, UserAgent: "test-agent"})
+	service.redditURL = server.URL
+	service.stockTwitsURL = server.URL
+	return service
+}
+
+func TestSocialSentimentService_GetPosts(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	server := newTestSocialServer(t, http.StatusOK, http.StatusOK)
+	defer server.Close()
+
+	posts, err := newTestSocialService(server).GetPosts(context.Background(), "AAPL", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(posts) != 4 {
+		t.Fatalf("expected 4 posts, got %d", len(posts))
+	}
+	if posts[0].Source != "stocktwits" || posts[0].Sentiment != models.SocialSentimentBullish {
+		t.Errorf("expected the newest post to be the bullish StockTwits message, got %+v", posts[0])
+	}
+	if posts[1].Source != "reddit" || posts[1].Score != 420 || posts[1].Text != "AAPL to the moon\nEarnings will crush" {
+		t.Errorf("unexpected reddit post %+v", posts[1])
+	}
+	if posts[3].Sentiment != "" {
+		t.Errorf("expected an unlabeled message, got %q", posts[3].Sentiment)
+	}
+}
+
+func TestSocialSentimentService_GetPosts_OneSourceDown(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	server := newTestSocialServer(t, http.StatusTooManyRequests, http.StatusOK)
+	defer server.Close()
+
+	posts, err := newTestSocialService(server).GetPosts(context.Background(), "AAPL", 2)
+	if err != nil {
+		t.Fatalf("expected StockTwits posts while reddit is down, got %v", err)
+	}
+	if len(posts) != 2 {
+		t.Errorf("expected the limit to cap StockTwits messages at 2, got %d", len(posts))
+	}
+}
+
+func TestSocialSentimentService_GetPosts_BothDown(t *testing.T) {
+	SetGlobalRegistry(NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig))
+
+	server := newTestSocialServer(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	if _, err := newTestSocialService(server).GetPosts(context.Background(), "AAPL", 10); err == nil {
+		t.Error("expected an error when both sources fail")
+	}
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ParseStructuredOutput decodes the JSON object in an LLM response into result. Models
+// often wrap the object in a markdown code fence or add a sentence before or after it,
+// so decoding starts at the first brace and stops at the end of the object.
+func ParseStructuredOutput(text string, result interface{}) error {
+	start := strings.IndexByte(text, '{')
+	if start < 0 {
+		return fmt.Errorf("failed to parse response as JSON: no object found")
+	}
+
+	if err := json.NewDecoder(strings.NewReader(text[start:])).Decode(result); err != nil {
+		return fmt.Errorf("failed to parse response as JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseStructuredOutput(t *testing.T) {
+	type analysis struct {
+		Score      float64  `json:"score"`
+		Confidence float64  `json:"confidence"`
+		KeyFactors []string `json:"key_factors"`
+	}
+	want := analysis{Score: 42, Confidence: 80, KeyFactors: []string{"margins"}}
+
+	for _, tt := range []struct {
+		name    string
+		text    string
+		wantErr bool
+	}{
+		{"bare object", `{"score": 42, "confidence": 80, "key_factors": ["margins"]}`, false},
+		{"code fence", "```json\n{\"score\": 42, \"confidence\": 80, \"key_factors\": [\"margins\"]}\n```", false},
+		{"surrounding prose", "Here is my analysis:\n{\"score\": 42, \"confidence\": 80, \"key_factors\": [\"margins\"]}\nLet me know if you need more.", false},
+		{"no object", "I can't analyze this stock.", true},
+		{"truncated object", `{"score": 42, "confidence":`, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var got analysis
+			err := ParseStructuredOutput(tt.text, &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseStructuredOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseStructuredOutput() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// FuzzParseStructuredOutput checks that arbitrary model output never panics the parser,
+// and that whatever it accepts decodes the same once fenced and wrapped in prose
+func FuzzParseStructuredOutput(f *testing.F) {
+	f.Add(`{"score": 42, "confidence": 80}`)
+	f.Add("```json\n{\"score\": -10, \"signals\": [\"rsi\"]}\n```")
+	f.Add(`Sure! {"reasoning": "strong {growth}", "score": 1e400}`)
+	f.Add(`{"a": {"b": [1, 2, {"c": null}]}} trailing`)
+	f.Add(`{`)
+	f.Add(``)
+
+	f.Fuzz(func(t *testing.T, text string) {
+		var first map[string]interface{}
+		if err := ParseStructuredOutput(text, &first); err != nil {
+			return
+		}
+
+		encoded, err := json.Marshal(first)
+		if err != nil {
+			t.Fatalf("failed to re-encode %q: %v", text, err)
+		}
+		var second map[string]interface{}
+		if err := ParseStructuredOutput("Analysis:\n```json\n"+string(encoded)+"\n```\nDone.", &second); err != nil {
+			t.Fatalf("failed to parse the fenced re-encoding of %q: %v", text, err)
+		}
+		if !reflect.DeepEqual(first, second) {
+			t.Fatalf("fenced re-encoding decoded to %v, want %v", second, first)
+		}
+	})
+}
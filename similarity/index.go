@@ -0,0 +1,174 @@
+package similarity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// indexInterval is how often new recommendations are embedded
+	indexInterval = time.Minute
+	// indexBatchSize bounds how many recommendations are embedded per request
+	indexBatchSize = 50
+)
+
+// Repository defines the repository operations needed by Index
+type Repository interface {
+	SaveAnalysisEmbedding(ctx context.Context, embedding *models.AnalysisEmbedding) error
+	GetRecommendationsWithoutEmbedding(ctx context.Context, model string, limit int) ([]models.Recommendation, error)
+	MarkAnalysisEmbeddingFailed(ctx context.Context, recommendationID uuid.UUID, model, reason string) error
+	GetLatestAnalysisEmbedding(ctx context.Context, symbol, model string) (*models.AnalysisEmbedding, error)
+	FindSimilarAnalyses(ctx context.Context, vector []float32, model string, excludeID uuid.UUID, limit int) ([]models.SimilarAnalysis, error)
+}
+
+// Embedder turns text into embedding vectors
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	EmbeddingModel() string
+}
+
+// Index embeds the reasoning of past recommendations and finds the ones most similar to
+// a symbol's latest analysis. Embedding happens in the background so analyses never wait
+// on the embedding endpoint.
+type Index struct {
+	repo     Repository
+	embedder Embedder
+	now      func() time.Time
+}
+
+// NewIndex creates a new Index
+func NewIndex(repo Repository, embedder Embedder) *Index {
+	return &Index{repo: repo, embedder: embedder, now: time.Now}
+}
+
+// Run embeds new recommendations every minute until ctx is cancelled
+func (x *Index) Run(ctx context.Context) {
+	ticker := time.NewTicker(indexInterval)
+	defer ticker.Stop()
+
+	observability.Info("analysis similarity index started", "model", x.embedder.EmbeddingModel())
+
+	for {
+		if _, err := x.IndexPending(ctx); err != nil {
+			observability.Warn("analysis indexing failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// IndexPending embeds recommendations that have no embedding for the current model, a
+// batch at a time, and returns how many were indexed. Recommendations the model rejects,
+// or whose embedding can't be saved, are marked failed and skipped from then on. It stops
+// when the embedding endpoint fails for the whole batch; the rest are picked up on the
+// next run.
+func (x *Index) IndexPending(ctx context.Context) (int, error) {
+	model := x.embedder.EmbeddingModel()
+	indexed := 0
+	for {
+		recs, err := x.repo.GetRecommendationsWithoutEmbedding(ctx, model, indexBatchSize)
+		if err != nil {
+			return indexed, err
+		}
+		if len(recs) == 0 {
+			return indexed, nil
+		}
+
+		vectors, failures, err := x.embed(ctx, recs)
+		if err != nil {
+			return indexed, err
+		}
+
+		for i, rec := range recs {
+			if failures[i] == nil {
+				failures[i] = x.repo.SaveAnalysisEmbedding(ctx, &models.AnalysisEmbedding{
+					RecommendationID: rec.ID,
+					Symbol:           rec.Symbol,
+					Model:            model,
+					Vector:           vectors[i],
+					CreatedAt:        x.now(),
+				})
+			}
+			if failures[i] == nil {
+				indexed++
+				continue
+			}
+
+			observability.Warn("skipping analysis that failed to index",
+				"recommendation_id", rec.ID,
+				"symbol", rec.Symbol,
+				"error", failures[i])
+			if err := x.repo.MarkAnalysisEmbeddingFailed(ctx, rec.ID, model, failures[i].Error()); err != nil {
+				return indexed, err
+			}
+		}
+
+		if len(recs) < indexBatchSize {
+			return indexed, nil
+		}
+	}
+}
+
+// embed embeds the recommendations in one request. When the batch is rejected each one is
+// embedded on its own, and failures holds the error of each that still fails. An error is
+// returned instead when every one fails, as the endpoint rather than the rows is then at
+// fault, or when the model's vectors don't fit the embedding column.
+func (x *Index) embed(ctx context.Context, recs []models.Recommendation) ([][]float32, []error, error) {
+	texts := make([]string, len(recs))
+	for i := range recs {
+		texts[i] = models.EmbeddingText(&recs[i])
+	}
+	failures := make([]error, len(recs))
+
+	vectors, batchErr := x.embedder.Embed(ctx, texts)
+	if batchErr != nil {
+		if len(recs) == 1 {
+			return nil, nil, fmt.Errorf("failed to embed analyses: %w", batchErr)
+		}
+		vectors = make([][]float32, len(recs))
+		failed := 0
+		for i, text := range texts {
+			single, err := x.embedder.Embed(ctx, []string{text})
+			if err != nil {
+				failures[i] = err
+				failed++
+				continue
+			}
+			vectors[i] = single[0]
+		}
+		if failed == len(recs) || ctx.Err() != nil {
+			return nil, nil, fmt.Errorf("failed to embed analyses: %w", batchErr)
+		}
+	}
+
+	for i, v := range vectors {
+		if failures[i] == nil && len(v) != models.EmbeddingDimensions {
+			return nil, nil, fmt.Errorf("embedding model %s returned %d dimensions, want %d", x.embedder.EmbeddingModel(), len(v), models.EmbeddingDimensions)
+		}
+	}
+	return vectors, failures, nil
+}
+
+// Similar returns the past analyses, of any symbol, whose reasoning is closest to the
+// symbol's latest indexed analysis, or models.ErrAnalysisNotIndexed if it has none yet.
+func (x *Index) Similar(ctx context.Context, symbol string, limit int) ([]models.SimilarAnalysis, error) {
+	model := x.embedder.EmbeddingModel()
+	latest, err := x.repo.GetLatestAnalysisEmbedding(ctx, symbol, model)
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("%w %s", models.ErrAnalysisNotIndexed, symbol)
+	}
+
+	return x.repo.FindSimilarAnalyses(ctx, latest.Vector, model, latest.RecommendationID, limit)
+}
@@ -0,0 +1,180 @@
+package similarity
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"trade-machine/models"
+
+	"github.com/google/uuid"
+)
+
+type mockRepo struct {
+	pending  []models.Recommendation
+	saved    []models.AnalysisEmbedding
+	failed   []uuid.UUID
+	latest   *models.AnalysisEmbedding
+	similar  []models.SimilarAnalysis
+	excluded uuid.UUID
+}
+
+func (m *mockRepo) SaveAnalysisEmbedding(ctx context.Context, embedding *models.AnalysisEmbedding) error {
+	m.saved = append(m.saved, *embedding)
+	return nil
+}
+
+func (m *mockRepo) MarkAnalysisEmbeddingFailed(ctx context.Context, recommendationID uuid.UUID, model, reason string) error {
+	m.failed = append(m.failed, recommendationID)
+	return nil
+}
+
+func (m *mockRepo) GetRecommendationsWithoutEmbedding(ctx context.Context, model string, limit int) ([]models.Recommendation, error) {
+	batch := m.pending[:min(limit, len(m.pending))]
+	m.pending = m.pending[len(batch):]
+	return batch, nil
+}
+
+func (m *mockRepo) GetLatestAnalysisEmbedding(ctx context.Context, symbol, model string) (*models.AnalysisEmbedding, error) {
+	return m.latest, nil
+}
+
+func (m *mockRepo) FindSimilarAnalyses(ctx context.Context, vector []float32, model string, excludeID uuid.UUID, limit int) ([]models.SimilarAnalysis, error) {
+	m.excluded = excludeID
+	return m.similar, nil
+}
+
+type mockEmbedder struct {
+	calls      int
+	err        error
+	reject     string // Texts containing this are rejected, along with any batch holding one
+	dimensions int
+}
+
+func (m *mockEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if m.reject != "" && strings.Contains(text, m.reject) {
+			return nil, errors.New("invalid input")
+		}
+		vectors[i] = make([]float32, cmp.Or(m.dimensions, models.EmbeddingDimensions))
+		vectors[i][0] = float32(i)
+	}
+	return vectors, nil
+}
+
+func (m *mockEmbedder) EmbeddingModel() string { return "test-model" }
+
+func TestIndexPending_EmbedsInBatches(t *testing.T) {
+	repo := &mockRepo{}
+	for range indexBatchSize + 3 {
+		repo.pending = append(repo.pending, *models.NewRecommendation("AAPL", models.RecommendationActionBuy, "Margins expanding"))
+	}
+	embedder := &mockEmbedder{}
+
+	indexed, err := NewIndex(repo, embedder).IndexPending(context.Background())
+	if err != nil {
+		t.Fatalf("IndexPending failed: %v", err)
+	}
+	if indexed != indexBatchSize+3 || len(repo.saved) != indexBatchSize+3 {
+		t.Errorf("indexed %d, saved %d, want %d", indexed, len(repo.saved), indexBatchSize+3)
+	}
+	if embedder.calls != 2 {
+		t.Errorf("Embed called %d times, want 2 batches", embedder.calls)
+	}
+	if repo.saved[0].Model != "test-model" || repo.saved[0].Symbol != "AAPL" {
+		t.Errorf("saved = %+v, want model and symbol set", repo.saved[0])
+	}
+}
+
+func TestIndexPending_EmbedFailure(t *testing.T) {
+	repo := &mockRepo{pending: []models.Recommendation{*models.NewRecommendation("AAPL", models.RecommendationActionBuy, "")}}
+
+	_, err := NewIndex(repo, &mockEmbedder{err: errors.New("rate limited")}).IndexPending(context.Background())
+	if err == nil {
+		t.Fatal("expected an error when embedding fails")
+	}
+	if len(repo.saved) != 0 {
+		t.Errorf("saved %d embeddings, want none", len(repo.saved))
+	}
+}
+
+func TestIndexPending_SkipsRejectedRows(t *testing.T) {
+	bad := models.NewRecommendation("BAD", models.RecommendationActionBuy, "unembeddable")
+	repo := &mockRepo{pending: []models.Recommendation{
+		*models.NewRecommendation("AAPL", models.RecommendationActionBuy, "Margins expanding"),
+		*bad,
+		*models.NewRecommendation("MSFT", models.RecommendationActionBuy, "Cloud growth"),
+	}}
+
+	indexed, err := NewIndex(repo, &mockEmbedder{reject: "unembeddable"}).IndexPending(context.Background())
+	if err != nil {
+		t.Fatalf("IndexPending failed: %v", err)
+	}
+	if indexed != 2 || len(repo.saved) != 2 {
+		t.Errorf("indexed %d, saved %d, want the 2 good rows", indexed, len(repo.saved))
+	}
+	if len(repo.failed) != 1 || repo.failed[0] != bad.ID {
+		t.Errorf("failed = %v, want only %s", repo.failed, bad.ID)
+	}
+}
+
+func TestIndexPending_AllRowsFail(t *testing.T) {
+	repo := &mockRepo{pending: []models.Recommendation{
+		*models.NewRecommendation("AAPL", models.RecommendationActionBuy, ""),
+		*models.NewRecommendation("MSFT", models.RecommendationActionBuy, ""),
+	}}
+
+	// Every row failing on its own points at the endpoint, not the rows
+	if _, err := NewIndex(repo, &mockEmbedder{reject: "Action"}).IndexPending(context.Background()); err == nil {
+		t.Fatal("expected an error when every row fails")
+	}
+	if len(repo.failed) != 0 || len(repo.saved) != 0 {
+		t.Errorf("failed %d, saved %d, want nothing marked", len(repo.failed), len(repo.saved))
+	}
+}
+
+func TestIndexPending_WrongDimensions(t *testing.T) {
+	repo := &mockRepo{pending: []models.Recommendation{*models.NewRecommendation("AAPL", models.RecommendationActionBuy, "")}}
+
+	if _, err := NewIndex(repo, &mockEmbedder{dimensions: 3072}).IndexPending(context.Background()); err == nil {
+		t.Fatal("expected an error for vectors that don't fit the column")
+	}
+	if len(repo.failed) != 0 || len(repo.saved) != 0 {
+		t.Errorf("failed %d, saved %d, want nothing marked", len(repo.failed), len(repo.saved))
+	}
+}
+
+func TestSimilar(t *testing.T) {
+	latestID := uuid.New()
+	repo := &mockRepo{
+		latest: &models.AnalysisEmbedding{RecommendationID: latestID, Symbol: "AAPL", Vector: []float32{1}},
+		similar: []models.SimilarAnalysis{
+			{Recommendation: *models.NewRecommendation("MSFT", models.RecommendationActionBuy, ""), Similarity: 0.92},
+		},
+	}
+
+	similar, err := NewIndex(repo, &mockEmbedder{}).Similar(context.Background(), "AAPL", 5)
+	if err != nil {
+		t.Fatalf("Similar failed: %v", err)
+	}
+	if len(similar) != 1 || similar[0].Recommendation.Symbol != "MSFT" {
+		t.Errorf("Similar = %+v, want MSFT", similar)
+	}
+	if repo.excluded != latestID {
+		t.Error("the analysis being compared should be excluded from results")
+	}
+}
+
+func TestSimilar_NotIndexed(t *testing.T) {
+	_, err := NewIndex(&mockRepo{}, &mockEmbedder{}).Similar(context.Background(), "AAPL", 5)
+	if !errors.Is(err, models.ErrAnalysisNotIndexed) {
+		t.Errorf("err = %v, want ErrAnalysisNotIndexed", err)
+	}
+}
@@ -0,0 +1,195 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	marketdata "github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+)
+
+// cacheDataType is the market data cache entry the stats are stored under
+const cacheDataType = "risk_stats"
+
+// BarSource supplies daily bars
+type BarSource interface {
+	GetDailyBars(ctx context.Context, symbol string, days int) ([]marketdata.Bar, error)
+}
+
+// Cache defines the market data cache operations used to share stats across restarts
+type Cache interface {
+	GetCachedData(ctx context.Context, symbol, dataType string) (map[string]interface{}, error)
+	SetCachedData(ctx context.Context, symbol, dataType string, data map[string]interface{}, ttl time.Duration) error
+}
+
+// Service computes trailing volatility, beta and max drawdown per symbol from daily bars.
+// Stats change at most once a trading day, so each symbol is computed once per market
+// date and kept in memory and, when a cache is configured, in the database until the
+// next day.
+type Service struct {
+	bars         BarSource
+	cache        Cache
+	lookbackDays int
+	benchmark    string
+	now          func() time.Time
+
+	mu     sync.Mutex
+	day    string // Market date the memo holds stats for
+	memo   map[string]*models.RiskStats
+	closes []models.DailyClose // Benchmark closes for day
+}
+
+// NewService creates a new Service. cache may be nil to keep stats in memory only.
+func NewService(bars BarSource, cache Cache, cfg *config.RiskStatsConfig) *Service {
+	return &Service{
+		bars:         bars,
+		cache:        cache,
+		lookbackDays: cfg.LookbackDays,
+		benchmark:    cfg.Benchmark,
+		now:          time.Now,
+		memo:         make(map[string]*models.RiskStats),
+	}
+}
+
+// Get returns a symbol's risk stats for today, computing them on the first request of the
+// market day. Returns models.ErrInsufficientHistory for symbols with too few bars.
+func (s *Service) Get(ctx context.Context, symbol string) (*models.RiskStats, error) {
+	symbol = strings.ToUpper(symbol)
+	now := s.now()
+	day := now.In(models.MarketLocation()).Format("2006-01-02")
+
+	s.mu.Lock()
+	if s.day != day {
+		s.day, s.memo, s.closes = day, make(map[string]*models.RiskStats), nil
+	}
+	if stats, ok := s.memo[symbol]; ok {
+		s.mu.Unlock()
+		return stats, nil
+	}
+	s.mu.Unlock()
+
+	if stats := s.cached(ctx, symbol, day); stats != nil {
+		s.remember(day, symbol, stats)
+		return stats, nil
+	}
+
+	closes, err := s.dailyCloses(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	benchmark, err := s.benchmarkCloses(ctx, day)
+	if err != nil {
+		// Volatility and drawdown don't need the benchmark; beta is left unknown
+		observability.Warn("benchmark bars unavailable for beta", "benchmark", s.benchmark, "error", err)
+	}
+	stats, err := models.ComputeRiskStats(symbol, closes, benchmark)
+	if err != nil {
+		return nil, err
+	}
+	stats.Benchmark = s.benchmark
+	stats.LookbackDays = s.lookbackDays
+	stats.ComputedAt = now
+
+	s.remember(day, symbol, stats)
+	s.store(ctx, stats, now)
+	return stats, nil
+}
+
+// remember keeps stats for the rest of day, unless the day has already rolled over
+func (s *Service) remember(day, symbol string, stats *models.RiskStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.day == day {
+		s.memo[symbol] = stats
+	}
+}
+
+// benchmarkCloses returns the benchmark's closes, fetched once per market day
+func (s *Service) benchmarkCloses(ctx context.Context, day string) ([]models.DailyClose, error) {
+	s.mu.Lock()
+	if s.day == day && s.closes != nil {
+		closes := s.closes
+		s.mu.Unlock()
+		return closes, nil
+	}
+	s.mu.Unlock()
+
+	closes, err := s.dailyCloses(ctx, s.benchmark)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.day == day {
+		s.closes = closes
+	}
+	return closes, nil
+}
+
+func (s *Service) dailyCloses(ctx context.Context, symbol string) ([]models.DailyClose, error) {
+	bars, err := s.bars.GetDailyBars(ctx, symbol, s.lookbackDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily bars for %s: %w", symbol, err)
+	}
+	closes := make([]models.DailyClose, len(bars))
+	for i, bar := range bars {
+		closes[i] = models.DailyClose{Date: bar.Timestamp, Close: bar.Close}
+	}
+	return closes, nil
+}
+
+// cached returns the stats stored for symbol earlier on day, or nil
+func (s *Service) cached(ctx context.Context, symbol, day string) *models.RiskStats {
+	if s.cache == nil {
+		return nil
+	}
+	data, err := s.cache.GetCachedData(ctx, symbol, cacheDataType)
+	if err != nil || data == nil {
+		return nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil
+	}
+	var stats models.RiskStats
+	if err := json.Unmarshal(raw, &stats); err != nil {
+		return nil
+	}
+	// An entry computed under another benchmark or lookback is recomputed
+	if stats.ComputedAt.In(models.MarketLocation()).Format("2006-01-02") != day ||
+		stats.Benchmark != s.benchmark || stats.LookbackDays != s.lookbackDays {
+		return nil
+	}
+	return &stats
+}
+
+// store caches stats until the next market midnight. Failures are logged; the stats are
+// simply recomputed after a restart.
+func (s *Service) store(ctx context.Context, stats *models.RiskStats, now time.Time) {
+	if s.cache == nil {
+		return
+	}
+	raw, err := json.Marshal(stats)
+	if err != nil {
+		return
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return
+	}
+
+	et := now.In(models.MarketLocation())
+	midnight := time.Date(et.Year(), et.Month(), et.Day()+1, 0, 0, 0, 0, models.MarketLocation())
+	if err := s.cache.SetCachedData(ctx, stats.Symbol, cacheDataType, data, midnight.Sub(now).Round(time.Second)); err != nil {
+		observability.Debug("failed to cache risk stats", "symbol", stats.Symbol, "error", err)
+	}
+}
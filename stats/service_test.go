@@ -0,0 +1,125 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+
+	marketdata "github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+)
+
+// mockBars returns a zig-zag series for every symbol and counts requests per symbol
+type mockBars struct {
+	calls map[string]int
+	err   map[string]error
+	count int
+}
+
+func (m *mockBars) GetDailyBars(ctx context.Context, symbol string, days int) ([]marketdata.Bar, error) {
+	m.calls[symbol]++
+	if err := m.err[symbol]; err != nil {
+		return nil, err
+	}
+	start := time.Date(2026, 1, 2, 21, 0, 0, 0, time.UTC)
+	bars := make([]marketdata.Bar, m.count)
+	price := 100.0
+	for i := range bars {
+		if i%2 == 0 {
+			price *= 1.01
+		} else {
+			price *= 0.99
+		}
+		bars[i] = marketdata.Bar{Timestamp: start.AddDate(0, 0, i), Close: price}
+	}
+	return bars, nil
+}
+
+type mockCache struct {
+	data map[string]map[string]interface{}
+	ttl  time.Duration
+}
+
+func (m *mockCache) GetCachedData(ctx context.Context, symbol, dataType string) (map[string]interface{}, error) {
+	return m.data[symbol+"/"+dataType], nil
+}
+
+func (m *mockCache) SetCachedData(ctx context.Context, symbol, dataType string, data map[string]interface{}, ttl time.Duration) error {
+	m.data[symbol+"/"+dataType] = data
+	m.ttl = ttl
+	return nil
+}
+
+func newTestService(bars *mockBars, cache Cache, now time.Time) *Service {
+	s := NewService(bars, cache, &config.RiskStatsConfig{LookbackDays: 365, Benchmark: "SPY"})
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestService_GetCachesPerDay(t *testing.T) {
+	bars := &mockBars{calls: map[string]int{}, count: 60}
+	cache := &mockCache{data: map[string]map[string]interface{}{}}
+	// 10:00 Eastern
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	s := newTestService(bars, cache, now)
+
+	stats, err := s.Get(context.Background(), "aapl")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if stats.Symbol != "AAPL" || stats.Benchmark != "SPY" || stats.Beta == nil || stats.Volatility <= 0 {
+		t.Errorf("stats = %+v, want AAPL stats with beta against SPY", stats)
+	}
+	if _, err := s.Get(context.Background(), "AAPL"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, err := s.Get(context.Background(), "MSFT"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if bars.calls["AAPL"] != 1 || bars.calls["SPY"] != 1 {
+		t.Errorf("bar requests = %v, want one per symbol and one for the benchmark", bars.calls)
+	}
+	if want := 14 * time.Hour; cache.ttl != want {
+		t.Errorf("cache TTL = %v, want %v until midnight Eastern", cache.ttl, want)
+	}
+
+	// A restart picks the stats up from the cache
+	restarted := newTestService(bars, cache, now.Add(time.Hour))
+	cached, err := restarted.Get(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if bars.calls["AAPL"] != 1 || cached.Volatility != stats.Volatility || *cached.Beta != *stats.Beta {
+		t.Errorf("cached = %+v after %d requests, want the stored stats", cached, bars.calls["AAPL"])
+	}
+
+	// The next market day recomputes
+	s.now = func() time.Time { return now.AddDate(0, 0, 1) }
+	if _, err := s.Get(context.Background(), "AAPL"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if bars.calls["AAPL"] != 2 {
+		t.Errorf("AAPL bar requests = %d, want stats recomputed the next day", bars.calls["AAPL"])
+	}
+}
+
+func TestService_GetWithoutBenchmark(t *testing.T) {
+	bars := &mockBars{calls: map[string]int{}, count: 60, err: map[string]error{"SPY": errors.New("rate limited")}}
+	s := newTestService(bars, nil, time.Now())
+
+	stats, err := s.Get(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if stats.Beta != nil || stats.Volatility <= 0 {
+		t.Errorf("stats = %+v, want volatility without beta", stats)
+	}
+
+	bars.count = 5
+	if _, err := s.Get(context.Background(), "NEWCO"); !errors.Is(err, models.ErrInsufficientHistory) {
+		t.Errorf("Get() error = %v, want ErrInsufficientHistory", err)
+	}
+}
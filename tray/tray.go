@@ -0,0 +1,172 @@
+package tray
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"trade-machine/models"
+	"trade-machine/observability"
+)
+
+// Core defines the app operations behind the status menu
+type Core interface {
+	MarketSession() models.MarketSession
+	GetPendingRecommendations() ([]models.Recommendation, error)
+	RunScreener(overrides *models.ScreenerFilters) (*models.ScreenerRun, error)
+	PauseAutomation()
+	ResumeAutomation()
+	AutomationPaused() bool
+}
+
+// Backend renders the status menu natively. Render and ShowDashboard are called from
+// the tray's own goroutine, never from a click callback.
+type Backend interface {
+	Render(status Status)
+	ShowDashboard()
+}
+
+// Command is a quick action picked from the status menu
+type Command string
+
+const (
+	CommandRunScreener      Command = "run_screener"
+	CommandOpenDashboard    Command = "open_dashboard"
+	CommandPauseAutomation  Command = "pause_automation"
+	CommandResumeAutomation Command = "resume_automation"
+)
+
+// commandQueueSize bounds the clicks waiting for the app core; more are dropped
+const commandQueueSize = 8
+
+// Status is what the status menu shows
+type Status struct {
+	Session          models.MarketSession
+	Pending          int // Pending recommendations, or -1 when they couldn't be loaded
+	AutomationPaused bool
+	ScreenerRunning  bool
+	LastError        string // Why the last action failed, cleared by the next action
+}
+
+// Title is the one-line summary shown on the menu, e.g. "Regular · 3 pending"
+func (s Status) Title() string {
+	pending := "pending unavailable"
+	if s.Pending >= 0 {
+		pending = fmt.Sprintf("%d pending", s.Pending)
+	}
+	title := fmt.Sprintf("%s · %s", s.Session.Label(), pending)
+	if s.AutomationPaused {
+		title += " · paused"
+	}
+	return title
+}
+
+// Tray passes clicks from the native menu to the app core and pushes status back. Menu
+// callbacks run on the UI thread, so they only queue a Command with Send; a single
+// goroutine started by Run executes commands and refreshes the status, which keeps slow
+// work like a screener run off the UI thread.
+type Tray struct {
+	core     Core
+	interval time.Duration
+	commands chan Command
+	refresh  chan struct{}
+	running  atomic.Bool // A screener run started from the menu is in progress
+	lastErr  atomic.Pointer[string]
+}
+
+// New creates a Tray refreshing its status every interval
+func New(core Core, interval time.Duration) *Tray {
+	return &Tray{
+		core:     core,
+		interval: interval,
+		commands: make(chan Command, commandQueueSize),
+		refresh:  make(chan struct{}, 1),
+	}
+}
+
+// Send queues a command without blocking, reporting false when the queue is full
+func (t *Tray) Send(cmd Command) bool {
+	select {
+	case t.commands <- cmd:
+		return true
+	default:
+		observability.Warn("tray command dropped, queue full", "command", cmd)
+		return false
+	}
+}
+
+// Run renders the status, then executes commands and refreshes every interval until
+// ctx is cancelled
+func (t *Tray) Run(ctx context.Context, backend Backend) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	backend.Render(t.Status())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case cmd := <-t.commands:
+			t.execute(cmd, backend)
+			backend.Render(t.Status())
+		case <-t.refresh:
+			backend.Render(t.Status())
+		case <-ticker.C:
+			backend.Render(t.Status())
+		}
+	}
+}
+
+// Status reads the current status from the app core
+func (t *Tray) Status() Status {
+	status := Status{
+		Session:          t.core.MarketSession(),
+		Pending:          -1,
+		AutomationPaused: t.core.AutomationPaused(),
+		ScreenerRunning:  t.running.Load(),
+	}
+	if recs, err := t.core.GetPendingRecommendations(); err == nil {
+		status.Pending = len(recs)
+	}
+	if msg := t.lastErr.Load(); msg != nil {
+		status.LastError = *msg
+	}
+	return status
+}
+
+func (t *Tray) execute(cmd Command, backend Backend) {
+	t.lastErr.Store(nil)
+	switch cmd {
+	case CommandOpenDashboard:
+		backend.ShowDashboard()
+	case CommandPauseAutomation:
+		t.core.PauseAutomation()
+	case CommandResumeAutomation:
+		t.core.ResumeAutomation()
+	case CommandRunScreener:
+		// A run takes minutes, so it goes in the background and repeat clicks are ignored
+		if !t.running.CompareAndSwap(false, true) {
+			return
+		}
+		go func() {
+			defer t.requestRefresh()
+			defer t.running.Store(false)
+			if _, err := t.core.RunScreener(nil); err != nil {
+				msg := "Screener failed: " + err.Error()
+				t.lastErr.Store(&msg)
+				observability.Warn("screener run from tray failed", "error", err)
+			}
+		}()
+	default:
+		observability.Warn("unknown tray command", "command", cmd)
+	}
+}
+
+// requestRefresh asks Run to render again, coalescing with a pending request
+func (t *Tray) requestRefresh() {
+	select {
+	case t.refresh <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,153 @@
+package tray
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"trade-machine/models"
+)
+
+type fakeCore struct {
+	mu          sync.Mutex
+	pending     int
+	pendingErr  error
+	paused      bool
+	screenerErr error
+	screenerRun chan struct{} // Receives when a screener run starts
+	release     chan struct{} // Closed to let screener runs finish
+}
+
+func (c *fakeCore) MarketSession() models.MarketSession { return models.MarketSessionRegular }
+
+func (c *fakeCore) GetPendingRecommendations() ([]models.Recommendation, error) {
+	if c.pendingErr != nil {
+		return nil, c.pendingErr
+	}
+	return make([]models.Recommendation, c.pending), nil
+}
+
+func (c *fakeCore) RunScreener(*models.ScreenerFilters) (*models.ScreenerRun, error) {
+	c.screenerRun <- struct{}{}
+	<-c.release
+	return nil, c.screenerErr
+}
+
+func (c *fakeCore) PauseAutomation()  { c.mu.Lock(); c.paused = true; c.mu.Unlock() }
+func (c *fakeCore) ResumeAutomation() { c.mu.Lock(); c.paused = false; c.mu.Unlock() }
+
+func (c *fakeCore) AutomationPaused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+type fakeBackend struct {
+	renders   chan Status
+	dashboard chan struct{}
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{renders: make(chan Status, 16), dashboard: make(chan struct{}, 1)}
+}
+
+func (b *fakeBackend) Render(s Status) { b.renders <- s }
+func (b *fakeBackend) ShowDashboard()  { b.dashboard <- struct{}{} }
+
+// nextRender waits for the next status the tray renders
+func (b *fakeBackend) nextRender(t *testing.T) Status {
+	t.Helper()
+	select {
+	case s := <-b.renders:
+		return s
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a render")
+		return Status{}
+	}
+}
+
+func TestStatus_Title(t *testing.T) {
+	for _, tt := range []struct {
+		status Status
+		want   string
+	}{
+		{Status{Session: models.MarketSessionRegular, Pending: 3}, "Regular · 3 pending"},
+		{Status{Session: models.MarketSessionClosed, Pending: 0, AutomationPaused: true}, "Closed · 0 pending · paused"},
+		{Status{Session: models.MarketSessionPre, Pending: -1}, "Pre-market · pending unavailable"},
+	} {
+		if got := tt.status.Title(); got != tt.want {
+			t.Errorf("Title() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestTray_Run(t *testing.T) {
+	core := &fakeCore{pending: 2, screenerRun: make(chan struct{}, 1), release: make(chan struct{})}
+	backend := newFakeBackend()
+	tr := New(core, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tr.Run(ctx, backend)
+
+	if s := backend.nextRender(t); s.Pending != 2 || s.Session != models.MarketSessionRegular {
+		t.Fatalf("initial status = %+v, want 2 pending in the regular session", s)
+	}
+
+	tr.Send(CommandPauseAutomation)
+	if s := backend.nextRender(t); !s.AutomationPaused {
+		t.Error("expected automation paused after the pause command")
+	}
+	tr.Send(CommandResumeAutomation)
+	if s := backend.nextRender(t); s.AutomationPaused {
+		t.Error("expected automation resumed after the resume command")
+	}
+
+	tr.Send(CommandOpenDashboard)
+	backend.nextRender(t)
+	select {
+	case <-backend.dashboard:
+	default:
+		t.Error("expected the dashboard to be shown")
+	}
+
+	// The screener runs in the background and repeat clicks are ignored while it does
+	core.screenerErr = errors.New("FMP unavailable")
+	tr.Send(CommandRunScreener)
+	<-core.screenerRun
+	if s := backend.nextRender(t); !s.ScreenerRunning {
+		t.Error("expected the screener to be shown running")
+	}
+	tr.Send(CommandRunScreener)
+	backend.nextRender(t)
+	close(core.release)
+	s := backend.nextRender(t)
+	if s.ScreenerRunning || s.LastError == "" {
+		t.Errorf("status after a failed run = %+v, want it finished with the error", s)
+	}
+	select {
+	case <-core.screenerRun:
+		t.Error("expected the repeat click to be ignored")
+	default:
+	}
+}
+
+func TestTray_StatusWithoutPending(t *testing.T) {
+	tr := New(&fakeCore{pendingErr: errors.New("database not initialized")}, time.Hour)
+	if s := tr.Status(); s.Pending != -1 {
+		t.Errorf("Pending = %d, want -1 when recommendations can't be loaded", s.Pending)
+	}
+}
+
+func TestTray_SendDropsWhenFull(t *testing.T) {
+	tr := New(&fakeCore{}, time.Hour)
+	for range commandQueueSize {
+		if !tr.Send(CommandOpenDashboard) {
+			t.Fatal("expected commands to queue until the queue is full")
+		}
+	}
+	if tr.Send(CommandOpenDashboard) {
+		t.Error("expected a command to be dropped once the queue is full")
+	}
+}
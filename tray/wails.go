@@ -0,0 +1,114 @@
+package tray
+
+import (
+	"context"
+	goruntime "runtime"
+	"sync"
+
+	"github.com/wailsapp/wails/v2/pkg/menu"
+	"github.com/wailsapp/wails/v2/pkg/menu/keys"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// WailsMenu renders the tray as a "Status" menu in the application menu bar, which is
+// the system menu bar on macOS and the window's menu bar elsewhere. Wails v2 has no
+// tray icon API, so this is the native surface available on every platform.
+type WailsMenu struct {
+	tray *Tray
+	menu *menu.Menu
+
+	mu         sync.Mutex
+	ctx        context.Context
+	paused     bool // As last rendered, so the pause item knows which way to toggle
+	status     *menu.MenuItem
+	lastError  *menu.MenuItem
+	screener   *menu.MenuItem
+	automation *menu.MenuItem
+}
+
+// NewWailsMenu builds the application menu for t. Pass Menu() to the Wails options and
+// call Run from OnStartup.
+func NewWailsMenu(t *Tray) *WailsMenu {
+	m := &WailsMenu{tray: t, menu: menu.NewMenu()}
+	if goruntime.GOOS == "darwin" {
+		m.menu.Append(menu.AppMenu())
+		m.menu.Append(menu.EditMenu())
+	}
+
+	status := m.menu.AddSubmenu("Status")
+	m.status = status.AddText("Loading…", nil, nil)
+	m.status.Disabled = true
+	m.lastError = status.AddText("", nil, nil)
+	m.lastError.Disabled = true
+	m.lastError.Hidden = true
+	status.AddSeparator()
+	status.AddText("Open Dashboard", keys.CmdOrCtrl("d"), func(*menu.CallbackData) {
+		t.Send(CommandOpenDashboard)
+	})
+	m.screener = status.AddText("Run Screener", keys.CmdOrCtrl("r"), func(*menu.CallbackData) {
+		t.Send(CommandRunScreener)
+	})
+	m.automation = status.AddText("Pause Automated Jobs", nil, func(*menu.CallbackData) {
+		m.mu.Lock()
+		paused := m.paused
+		m.mu.Unlock()
+		if paused {
+			t.Send(CommandResumeAutomation)
+		} else {
+			t.Send(CommandPauseAutomation)
+		}
+	})
+	return m
+}
+
+// Menu returns the application menu
+func (m *WailsMenu) Menu() *menu.Menu {
+	return m.menu
+}
+
+// Run keeps the menu up to date until ctx, the context Wails passes to OnStartup, is
+// cancelled
+func (m *WailsMenu) Run(ctx context.Context) {
+	m.mu.Lock()
+	m.ctx = ctx
+	m.mu.Unlock()
+	m.tray.Run(ctx, m)
+}
+
+// Render updates the menu items from status
+func (m *WailsMenu) Render(status Status) {
+	m.mu.Lock()
+	m.paused = status.AutomationPaused
+	m.status.Label = status.Title()
+	m.lastError.Label = status.LastError
+	m.lastError.Hidden = status.LastError == ""
+	m.screener.Disabled = status.ScreenerRunning
+	if status.ScreenerRunning {
+		m.screener.Label = "Screener Running…"
+	} else {
+		m.screener.Label = "Run Screener"
+	}
+	if status.AutomationPaused {
+		m.automation.Label = "Resume Automated Jobs"
+	} else {
+		m.automation.Label = "Pause Automated Jobs"
+	}
+	ctx := m.ctx
+	m.mu.Unlock()
+
+	if ctx != nil {
+		runtime.MenuUpdateApplicationMenu(ctx)
+	}
+}
+
+// ShowDashboard brings the main window to the front
+func (m *WailsMenu) ShowDashboard() {
+	m.mu.Lock()
+	ctx := m.ctx
+	m.mu.Unlock()
+	if ctx == nil {
+		return
+	}
+	runtime.WindowUnminimise(ctx)
+	runtime.WindowShow(ctx)
+}
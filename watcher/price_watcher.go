@@ -0,0 +1,216 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+	"trade-machine/observability"
+
+	"github.com/shopspring/decimal"
+)
+
+// recommendationScanLimit bounds how many recent recommendations are scanned per check
+const recommendationScanLimit = 200
+
+// Repository defines the repository operations needed by PriceWatcher
+type Repository interface {
+	GetPositions(ctx context.Context) ([]models.Position, error)
+	GetRecommendations(ctx context.Context, status models.RecommendationStatus, limit int) ([]models.Recommendation, error)
+}
+
+// QuoteProvider supplies the latest price for a symbol
+type QuoteProvider interface {
+	GetQuote(ctx context.Context, symbol string) (*models.Quote, error)
+}
+
+// Analyzer queues a re-analysis. Implementations are expected to respect the shared
+// analysis budget and return models.ErrAnalysisQueueFull when it is exhausted. No checks
+// run while AutomationPaused reports true.
+type Analyzer interface {
+	AnalyzeTriggered(ctx context.Context, symbol, reason string) (*models.Recommendation, error)
+	AutomationPaused() bool
+}
+
+// Trigger describes a symbol whose price moved enough to warrant a re-analysis
+type Trigger struct {
+	Symbol        string
+	BaselinePrice decimal.Decimal
+	CurrentPrice  decimal.Decimal
+	MovePercent   float64
+	Reason        string
+}
+
+// PriceWatcher re-analyzes held and recently recommended symbols when their price
+// moves more than a configured percentage from the last recommendation's entry price
+type PriceWatcher struct {
+	repo     Repository
+	quotes   QuoteProvider
+	analyzer Analyzer
+	cfg      *config.PriceWatchConfig
+
+	mu            sync.Mutex
+	lastTriggered map[string]time.Time
+	now           func() time.Time
+}
+
+// NewPriceWatcher creates a new PriceWatcher
+func NewPriceWatcher(repo Repository, quotes QuoteProvider, analyzer Analyzer, cfg *config.PriceWatchConfig) *PriceWatcher {
+	return &PriceWatcher{
+		repo:          repo,
+		quotes:        quotes,
+		analyzer:      analyzer,
+		cfg:           cfg,
+		lastTriggered: make(map[string]time.Time),
+		now:           time.Now,
+	}
+}
+
+// Run checks prices every configured interval until ctx is cancelled
+func (w *PriceWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(w.cfg.IntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	observability.Info("price watcher started",
+		"interval_seconds", w.cfg.IntervalSeconds,
+		"move_percent", w.cfg.MovePercent)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := w.Check(ctx); err != nil {
+				observability.Warn("price watch check failed", "error", err)
+			}
+		}
+	}
+}
+
+// Check runs one pass: it finds symbols that moved past the threshold and re-analyzes
+// up to MaxPerCycle of them, returning the triggers that were acted on. A pass stops when
+// the analysis budget is exhausted; any other failed analysis is logged and the pass moves
+// on to the next symbol. A failed symbol is not put on cooldown, so it is retried on the
+// next pass. Nothing is checked while automation is paused.
+func (w *PriceWatcher) Check(ctx context.Context) ([]Trigger, error) {
+	if w.analyzer.AutomationPaused() {
+		return nil, nil
+	}
+
+	baselines, err := w.baselines(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var fired []Trigger
+	for _, rec := range baselines {
+		if len(fired) >= w.cfg.MaxPerCycle {
+			break
+		}
+		if w.coolingDown(rec.Symbol) {
+			continue
+		}
+
+		trigger, ok := w.evaluate(ctx, rec)
+		if !ok {
+			continue
+		}
+
+		if _, err := w.analyzer.AnalyzeTriggered(ctx, trigger.Symbol, trigger.Reason); err != nil {
+			observability.Warn("triggered re-analysis failed",
+				"symbol", trigger.Symbol,
+				"reason", trigger.Reason,
+				"error", err)
+			if errors.Is(err, models.ErrAnalysisQueueFull) {
+				return fired, nil
+			}
+			continue
+		}
+		w.markTriggered(rec.Symbol)
+		observability.Info("re-analyzed after price move",
+			"symbol", trigger.Symbol,
+			"move_percent", trigger.MovePercent)
+		fired = append(fired, trigger)
+	}
+	return fired, nil
+}
+
+// baselines returns the latest recommendation with an entry price for each watched
+// symbol: every held symbol, plus symbols recommended within RecentDays
+func (w *PriceWatcher) baselines(ctx context.Context) ([]models.Recommendation, error) {
+	positions, err := w.repo.GetPositions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load positions: %w", err)
+	}
+	held := make(map[string]bool, len(positions))
+	for _, p := range positions {
+		held[p.Symbol] = true
+	}
+
+	recs, err := w.repo.GetRecommendations(ctx, "", recommendationScanLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recommendations: %w", err)
+	}
+
+	cutoff := w.now().AddDate(0, 0, -w.cfg.RecentDays)
+	seen := make(map[string]bool)
+	var baselines []models.Recommendation
+	// Recommendations are newest first, so the first one per symbol is the latest
+	for _, rec := range recs {
+		if seen[rec.Symbol] {
+			continue
+		}
+		seen[rec.Symbol] = true
+		if !rec.EntryPrice.IsPositive() {
+			continue
+		}
+		if held[rec.Symbol] || rec.CreatedAt.After(cutoff) {
+			baselines = append(baselines, rec)
+		}
+	}
+	return baselines, nil
+}
+
+// evaluate compares the current price, the last trade or else the bid/ask midpoint, with the
+// recommendation's entry price
+func (w *PriceWatcher) evaluate(ctx context.Context, rec models.Recommendation) (Trigger, bool) {
+	quote, err := w.quotes.GetQuote(ctx, rec.Symbol)
+	if err != nil || quote == nil || !quote.Price().IsPositive() {
+		if err != nil {
+			observability.Warn("price watch quote failed", "symbol", rec.Symbol, "error", err)
+		}
+		return Trigger{}, false
+	}
+
+	price := quote.Price()
+	move := price.Sub(rec.EntryPrice).Div(rec.EntryPrice).Mul(decimal.NewFromInt(100)).InexactFloat64()
+	if move < w.cfg.MovePercent && move > -w.cfg.MovePercent {
+		return Trigger{}, false
+	}
+
+	return Trigger{
+		Symbol:        rec.Symbol,
+		BaselinePrice: rec.EntryPrice,
+		CurrentPrice:  price,
+		MovePercent:   move,
+		Reason: fmt.Sprintf("Price moved %+.1f%% since the %s recommendation ($%s → $%s)",
+			move, rec.CreatedAt.Format("Jan 2"), rec.EntryPrice.StringFixed(2), price.StringFixed(2)),
+	}, true
+}
+
+func (w *PriceWatcher) coolingDown(symbol string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	last, ok := w.lastTriggered[symbol]
+	return ok && w.now().Sub(last) < time.Duration(w.cfg.CooldownMinutes)*time.Minute
+}
+
+func (w *PriceWatcher) markTriggered(symbol string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastTriggered[symbol] = w.now()
+}
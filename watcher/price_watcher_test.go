@@ -0,0 +1,172 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"trade-machine/config"
+	"trade-machine/models"
+
+	"github.com/shopspring/decimal"
+)
+
+type mockRepo struct {
+	positions []models.Position
+	recs      []models.Recommendation
+}
+
+func (m *mockRepo) GetPositions(ctx context.Context) ([]models.Position, error) {
+	return m.positions, nil
+}
+
+func (m *mockRepo) GetRecommendations(ctx context.Context, status models.RecommendationStatus, limit int) ([]models.Recommendation, error) {
+	return m.recs, nil
+}
+
+// mockQuotes quotes a bid and ask around each price without a last trade, as Alpaca does
+type mockQuotes map[string]float64
+
+func (m mockQuotes) GetQuote(ctx context.Context, symbol string) (*models.Quote, error) {
+	price, ok := m[symbol]
+	if !ok {
+		return nil, errors.New("no quote")
+	}
+	spread := decimal.NewFromFloat(0.05)
+	mid := decimal.NewFromFloat(price)
+	return &models.Quote{Symbol: symbol, Bid: mid.Sub(spread), Ask: mid.Add(spread)}, nil
+}
+
+type mockAnalyzer struct {
+	calls   map[string]string
+	failAll bool
+	failOn  map[string]bool // Symbols whose analysis fails with an error other than a full queue
+	paused  bool
+}
+
+func (m *mockAnalyzer) AutomationPaused() bool {
+	return m.paused
+}
+
+func (m *mockAnalyzer) AnalyzeTriggered(ctx context.Context, symbol, reason string) (*models.Recommendation, error) {
+	if m.failAll {
+		return nil, models.ErrAnalysisQueueFull
+	}
+	if m.failOn[symbol] {
+		return nil, errors.New("agent failed")
+	}
+	if m.calls == nil {
+		m.calls = make(map[string]string)
+	}
+	m.calls[symbol] = reason
+	return models.NewRecommendation(symbol, models.RecommendationActionHold, ""), nil
+}
+
+func testWatchConfig() *config.PriceWatchConfig {
+	return &config.NewTestConfig().PriceWatch
+}
+
+func recommendation(symbol string, entry float64, age time.Duration, now time.Time) models.Recommendation {
+	rec := models.NewRecommendation(symbol, models.RecommendationActionBuy, "")
+	rec.EntryPrice = decimal.NewFromFloat(entry)
+	rec.CreatedAt = now.Add(-age)
+	return *rec
+}
+
+func TestPriceWatcher_Check(t *testing.T) {
+	now := time.Date(2024, 6, 10, 15, 0, 0, 0, time.UTC)
+	repo := &mockRepo{
+		positions: []models.Position{{Symbol: "OLD"}},
+		recs: []models.Recommendation{
+			recommendation("AAPL", 100, time.Hour, now),        // +6%: triggers
+			recommendation("MSFT", 100, 2*time.Hour, now),      // +2%: below threshold
+			recommendation("AAPL", 50, 48*time.Hour, now),      // older AAPL baseline is ignored
+			recommendation("OLD", 100, 30*24*time.Hour, now),   // held, -10%: triggers
+			recommendation("STALE", 100, 30*24*time.Hour, now), // not held and too old
+			recommendation("NOPRICE", 0, time.Hour, now),       // no entry price to compare
+		},
+	}
+	quotes := mockQuotes{"AAPL": 106, "MSFT": 102, "OLD": 90, "STALE": 200, "NOPRICE": 10}
+	analyzer := &mockAnalyzer{}
+
+	w := NewPriceWatcher(repo, quotes, analyzer, testWatchConfig())
+	w.now = func() time.Time { return now }
+
+	fired, err := w.Check(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fired) != 2 || len(analyzer.calls) != 2 {
+		t.Fatalf("expected AAPL and OLD to trigger, got %+v", fired)
+	}
+	if !strings.Contains(analyzer.calls["AAPL"], "+6.0%") {
+		t.Errorf("AAPL reason = %q, want the +6.0%% move", analyzer.calls["AAPL"])
+	}
+	if !strings.Contains(analyzer.calls["OLD"], "-10.0%") {
+		t.Errorf("OLD reason = %q, want the -10.0%% move", analyzer.calls["OLD"])
+	}
+
+	// Triggered symbols cool down before they can fire again
+	analyzer.calls = nil
+	if fired, _ := w.Check(context.Background()); len(fired) != 0 {
+		t.Errorf("expected no triggers during cooldown, got %+v", fired)
+	}
+	w.now = func() time.Time { return now.Add(2 * time.Hour) }
+	if fired, _ := w.Check(context.Background()); len(fired) != 2 {
+		t.Errorf("expected triggers after cooldown, got %+v", fired)
+	}
+
+	// Nothing fires while automation is paused
+	analyzer.paused = true
+	w.now = func() time.Time { return now.Add(4 * time.Hour) }
+	if fired, _ := w.Check(context.Background()); len(fired) != 0 {
+		t.Errorf("expected no triggers while paused, got %+v", fired)
+	}
+}
+
+func TestPriceWatcher_Check_Budget(t *testing.T) {
+	now := time.Now()
+	repo := &mockRepo{recs: []models.Recommendation{
+		recommendation("AAA", 100, time.Hour, now),
+		recommendation("BBB", 100, time.Hour, now),
+		recommendation("CCC", 100, time.Hour, now),
+	}}
+	quotes := mockQuotes{"AAA": 120, "BBB": 120, "CCC": 120}
+
+	t.Run("caps triggers per cycle", func(t *testing.T) {
+		cfg := testWatchConfig()
+		cfg.MaxPerCycle = 2
+		w := NewPriceWatcher(repo, quotes, &mockAnalyzer{}, cfg)
+
+		fired, _ := w.Check(context.Background())
+		if len(fired) != 2 {
+			t.Errorf("expected 2 triggers, got %d", len(fired))
+		}
+	})
+
+	t.Run("stops when analysis is refused", func(t *testing.T) {
+		w := NewPriceWatcher(repo, quotes, &mockAnalyzer{failAll: true}, testWatchConfig())
+
+		fired, err := w.Check(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(fired) != 0 {
+			t.Errorf("expected no completed triggers, got %+v", fired)
+		}
+	})
+
+	t.Run("continues past other failures", func(t *testing.T) {
+		w := NewPriceWatcher(repo, quotes, &mockAnalyzer{failOn: map[string]bool{"AAA": true}}, testWatchConfig())
+
+		fired, err := w.Check(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(fired) != 2 || w.coolingDown("AAA") {
+			t.Errorf("fired = %+v, want BBB and CCC with AAA left to retry", fired)
+		}
+	})
+}